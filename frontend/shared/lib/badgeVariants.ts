@@ -48,6 +48,7 @@ export function getTransactionStatusVariant(status: string): BadgeVariant {
   switch (status) {
     case 'completed':
       return 'success';
+    case 'pending_approval':
     case 'pending':
     case 'processing':
       return 'warning';
@@ -70,6 +70,7 @@ export const WALLET_STATUS = {
 } as const;
 
 export const TRANSACTION_STATUS = {
+  PENDING_APPROVAL: 'pending_approval',
   PENDING: 'pending',
   PROCESSING: 'processing',
   COMPLETED: 'completed',
@@ -58,7 +58,7 @@ export interface Wallet {
 export interface Transaction {
   id: string;
   type: 'deposit' | 'withdrawal' | 'transfer' | 'reversal' | 'fee' | 'refund';
-  status: 'pending' | 'processing' | 'completed' | 'failed' | 'reversed' | 'cancelled';
+  status: 'pending_approval' | 'pending' | 'processing' | 'completed' | 'failed' | 'reversed' | 'cancelled';
   source_wallet_id?: string;
   destination_wallet_id?: string;
   amount: number;
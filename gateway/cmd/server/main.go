@@ -6,15 +6,26 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/1mb-dev/nivomoney/gateway/internal/bodylimit"
+	"github.com/1mb-dev/nivomoney/gateway/internal/chaos"
+	"github.com/1mb-dev/nivomoney/gateway/internal/compress"
+	"github.com/1mb-dev/nivomoney/gateway/internal/cors"
 	"github.com/1mb-dev/nivomoney/gateway/internal/handler"
+	"github.com/1mb-dev/nivomoney/gateway/internal/mock"
 	"github.com/1mb-dev/nivomoney/gateway/internal/proxy"
+	"github.com/1mb-dev/nivomoney/gateway/internal/respcache"
 	"github.com/1mb-dev/nivomoney/gateway/internal/router"
+	"github.com/1mb-dev/nivomoney/gateway/internal/tenant"
+	"github.com/1mb-dev/nivomoney/gateway/internal/usage"
+	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	sharedMiddleware "github.com/1mb-dev/nivomoney/shared/middleware"
 )
 
 const serviceName = "gateway"
@@ -37,14 +48,48 @@ func main() {
 	// Initialize service registry
 	registry := proxy.NewServiceRegistry()
 	appLogger.Info("Service registry initialized")
-	for name, url := range registry.AllServices() {
-		appLogger.WithField("service", name).WithField("url", url).Debug("Registered service")
+	services := registry.Services()
+	for _, name := range registry.ServiceNames() {
+		for _, inst := range services[name] {
+			appLogger.WithField("service", name).WithField("url", inst.URL).Debug("Registered service")
+		}
 	}
 
+	// Probe instances so unhealthy ones leave rotation until they recover
+	healthChecker := proxy.NewHealthChecker(registry, proxy.HealthCheckConfigFromEnv(), appLogger)
+	healthChecker.Start()
+
 	// Initialize gateway with logger
 	gateway := proxy.NewGateway(registry, appLogger)
 	appLogger.Info("Gateway proxy initialized")
 
+	// Extra tenants (e.g. demo, staging-sandbox) run their own services and
+	// databases behind this gateway
+	tenants, err := tenant.ConfigFromEnv()
+	if err != nil {
+		appLogger.Fatalf("Invalid tenant config: %v", err)
+	}
+	tenantCheckers := make([]*proxy.HealthChecker, 0, len(tenants))
+	if len(tenants) > 0 {
+		registries := make(map[string]*proxy.ServiceRegistry, len(tenants))
+		for _, t := range tenants {
+			tenantRegistry := proxy.NewTenantRegistry(tenant.EnvPrefix(t.Name))
+			registries[t.Name] = tenantRegistry
+
+			checker := proxy.NewHealthChecker(tenantRegistry, proxy.HealthCheckConfigFromEnv(), appLogger)
+			checker.Start()
+			tenantCheckers = append(tenantCheckers, checker)
+			appLogger.WithField("tenant", t.Name).WithField("rate_limit_per_minute", t.RateLimit.RequestsPerMinute).Info("Tenant enabled")
+		}
+		gateway.SetTenants(registries)
+	}
+
+	// Forward the client country from a trusted edge header for risk geo rules
+	if header := os.Getenv("GATEWAY_CLIENT_COUNTRY_HEADER"); header != "" {
+		gateway.SetCountryHeader(header)
+		appLogger.WithField("header", header).Info("Client country forwarding enabled")
+	}
+
 	// Initialize SSE broker
 	broker := events.NewBroker()
 	broker.Start()
@@ -56,17 +101,121 @@ func main() {
 
 	// Initialize router
 	apiRouter := router.NewRouter(gateway, sseHandler, appLogger)
+	apiRouter.EnableWebSocket(broker)
+
+	// Browser dashboards call the API from their own origins
+	corsPolicy, err := cors.PolicyFromEnv(cfg.Environment)
+	if err != nil {
+		appLogger.Fatalf("Invalid CORS config: %v", err)
+	}
+	apiRouter.EnableCORS(corsPolicy)
+	appLogger.WithField("origins", corsPolicy.Config.AllowedOrigins).WithField("source", corsPolicy.OriginsSource).Info("CORS policy configured")
+	if len(tenants) > 0 {
+		apiRouter.EnableTenants(tenant.NewResolver(sharedMiddleware.DefaultRateLimitConfig(), tenants))
+	}
+
+	// Runtime registration and administration need the internal secret in
+	// production, where the endpoints would otherwise be open
+	internalSecret := config.Secret("INTERNAL_SERVICE_SECRET")
+	if internalSecret != "" || !cfg.IsProduction() {
+		apiRouter.EnableRegistry(handler.NewRegistryHandler(registry, appLogger), internalSecret)
+		apiRouter.EnableAdmin(registry, internalSecret)
+		appLogger.Info("Service registry and admin endpoints enabled")
+	} else {
+		appLogger.Warn("INTERNAL_SERVICE_SECRET not set; service registry and admin endpoints disabled")
+	}
+
+	// Chaos injection lets the simulation service disturb real traffic - never in production
+	if !cfg.IsProduction() {
+		chaosInjector := chaos.NewInjector()
+		if err := apiRouter.EnableChaos(handler.NewChaosHandler(chaosInjector, appLogger), config.Secret("INTERNAL_SERVICE_SECRET")); err != nil {
+			appLogger.WithError(err).Warn("Chaos injection controls disabled")
+		} else {
+			gateway.SetChaos(chaosInjector)
+			sseHandler.SetChaos(chaosInjector)
+			appLogger.Info("Chaos injection controls enabled (non-production)")
+		}
+
+		// Mock mode lets frontend work proceed against routes whose backend isn't deployed
+		mockStore := mock.NewStore(os.Getenv("GATEWAY_MOCK_FIXTURES_DIR"))
+		if count, err := mockStore.LoadFixtures(); err != nil {
+			appLogger.WithError(err).Warn("Failed to load mock fixtures")
+		} else {
+			appLogger.WithField("fixtures", count).Info("Mock mode controls enabled (non-production)")
+		}
+		gateway.SetMocks(mockStore)
+		apiRouter.EnableMocks(handler.NewMockHandler(mockStore, appLogger), config.Secret("INTERNAL_SERVICE_SECRET"))
+	}
+
+	// Response caching is opt-in and needs Redis shared across gateway replicas
+	var responseStore *cache.RedisCache
+	if os.Getenv("GATEWAY_CACHE_ENABLED") == "true" {
+		responseCache, store, err := newResponseCache(cfg.RedisURL)
+		if err != nil {
+			appLogger.WithError(err).Warn("Response cache disabled")
+		} else {
+			responseStore = store
+			responseCache.Subscribe(broker, appLogger)
+			apiRouter.EnableCache(responseCache)
+			appLogger.WithField("rules", len(responseCache.Rules())).Info("Response cache enabled")
+		}
+	}
+
+	// Per-user usage analytics are opt-in and need Redis shared across gateway replicas
+	var usageRecorder *usage.Recorder
+	var usageStore *cache.RedisCache
+	if os.Getenv("GATEWAY_USAGE_ENABLED") == "true" {
+		store, err := cache.NewRedisCache(cache.DefaultRedisConfig(cfg.RedisURL))
+		if err != nil {
+			appLogger.WithError(err).Warn("Usage analytics disabled")
+		} else {
+			usageStore = store
+			retention := time.Duration(envInt("GATEWAY_USAGE_RETENTION_DAYS", 30)) * 24 * time.Hour
+			usageRecorder = usage.NewRecorder(usage.NewRedisStore(store.Client(), retention), appLogger)
+			usageRecorder.Start(envSeconds("GATEWAY_USAGE_FLUSH_SECONDS", 10*time.Second))
+			apiRouter.EnableUsage(usageRecorder)
+			appLogger.WithField("retention", retention.String()).Info("Usage analytics enabled")
+		}
+	}
+
+	// Body limits bound memory per request and cut off clients that trickle bodies
+	bodyConfig, err := bodylimit.ConfigFromEnv()
+	if err != nil {
+		appLogger.WithError(err).Warn("Invalid GATEWAY_BODY_LIMIT_RULES, using default body limit rules")
+		bodyConfig.Rules = bodylimit.DefaultRules()
+	}
+	bodyLimiter, err := bodylimit.New(bodyConfig)
+	if err != nil {
+		appLogger.Fatalf("Invalid body limit config: %v", err)
+	}
+	apiRouter.EnableBodyLimits(bodyLimiter)
+	appLogger.WithField("max_bytes", bodyConfig.DefaultMaxBytes).Info("Request body limits enabled")
+
+	// Large transaction lists and reports shrink several times when compressed
+	if os.Getenv("GATEWAY_COMPRESSION_ENABLED") != "false" {
+		compressConfig := compress.ConfigFromEnv()
+		compressor, err := compress.New(compressConfig)
+		if err != nil {
+			appLogger.Fatalf("Invalid compression config: %v", err)
+		}
+		apiRouter.EnableCompression(compressor)
+		appLogger.WithField("min_bytes", compressConfig.MinBytes).Info("Response compression enabled")
+	}
+
 	httpHandler := apiRouter.SetupRoutes()
 	appLogger.Info("Routes configured")
 
 	// Create HTTP server
 	addr := fmt.Sprintf(":%d", cfg.ServicePort)
 	srv := &http.Server{
-		Addr:         addr,
-		Handler:      httpHandler,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		Addr:    addr,
+		Handler: httpHandler,
+		// A short header timeout stops slow-loris clients from holding connections
+		ReadHeaderTimeout: envSeconds("GATEWAY_READ_HEADER_TIMEOUT_SECONDS", 5*time.Second),
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		MaxHeaderBytes:    envInt("GATEWAY_MAX_HEADER_BYTES", 64<<10),
 	}
 
 	// Start server in a goroutine
@@ -86,6 +235,11 @@ func main() {
 	<-quit
 	appLogger.Info("Shutting down server...")
 
+	healthChecker.Stop()
+	for _, checker := range tenantCheckers {
+		checker.Stop()
+	}
+
 	// Stop SSE broker (closes all client connections)
 	broker.Stop()
 	appLogger.Info("SSE broker stopped")
@@ -99,5 +253,54 @@ func main() {
 		appLogger.WithError(err).Warn("Server forced to shutdown")
 	}
 
+	if responseStore != nil {
+		_ = responseStore.Close()
+	}
+	if usageRecorder != nil {
+		usageRecorder.Stop(ctx)
+		_ = usageStore.Close()
+	}
+
 	appLogger.Info("Server shutdown complete")
 }
+
+// newResponseCache connects to Redis and loads cache rules from
+// GATEWAY_CACHE_RULES, falling back to the default rules.
+func newResponseCache(redisURL string) (*respcache.Cache, *cache.RedisCache, error) {
+	rules := respcache.DefaultRules()
+	if raw := os.Getenv("GATEWAY_CACHE_RULES"); raw != "" {
+		parsed, err := respcache.ParseRules(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		rules = parsed
+	}
+
+	store, err := cache.NewRedisCache(cache.DefaultRedisConfig(redisURL))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responseCache, err := respcache.New(store, rules)
+	if err != nil {
+		_ = store.Close()
+		return nil, nil, err
+	}
+	return responseCache, store, nil
+}
+
+// envSeconds reads a positive number of seconds from key, or returns fallback.
+func envSeconds(key string, fallback time.Duration) time.Duration {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	return fallback
+}
+
+// envInt reads a positive integer from key, or returns fallback.
+func envInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil && v > 0 {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,173 @@
+package apispec
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const walletID = "6f1c2f0e-3b4a-4c5d-8e9f-0a1b2c3d4e5f"
+
+func TestMatch(t *testing.T) {
+	spec := Gateway()
+
+	op, params := spec.Match(http.MethodGet, "/api/v1/transaction/wallets/"+walletID+"/transactions")
+	if op == nil || op.OperationID != "listWalletTransactions" {
+		t.Fatalf("expected listWalletTransactions, got %+v", op)
+	}
+	if params["walletId"] != walletID {
+		t.Errorf("expected walletId %s, got %v", walletID, params)
+	}
+
+	if op, _ := spec.Match(http.MethodPost, "/api/v1/auth/login"); op == nil || op.OperationID != "login" {
+		t.Errorf("expected alias to match login, got %+v", op)
+	}
+	if op, _ := spec.Match(http.MethodDelete, "/api/v1/transaction/transactions/transfer"); op != nil {
+		t.Errorf("expected no match for undescribed method, got %s", op.OperationID)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Gateway().Handler()(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("expected openapi 3.0.3, got %q", doc.OpenAPI)
+	}
+
+	transfer, ok := doc.Paths["/api/v1/transaction/transactions/transfer"]["post"]
+	if !ok {
+		t.Fatal("expected transfer operation in document")
+	}
+	if _, ok := transfer["requestBody"]; !ok {
+		t.Error("expected transfer to document its request body")
+	}
+	if _, ok := doc.Paths["/api/v1/auth/login"]; ok {
+		t.Error("expected aliases to be left out of the document")
+	}
+	if security, ok := doc.Paths["/api/v1/identity/auth/login"]["post"]["security"].([]any); !ok || len(security) != 0 {
+		t.Errorf("expected login to require no security, got %v", doc.Paths["/api/v1/identity/auth/login"]["post"]["security"])
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var proxied string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		proxied = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := Gateway().Middleware(next)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name:       "valid transfer",
+			method:     http.MethodPost,
+			path:       "/api/v1/transaction/transactions/transfer",
+			body:       `{"source_wallet_id":"` + walletID + `","destination_wallet_id":"` + walletID + `","amount":1000,"currency":"INR","description":"Rent"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid transfer",
+			method:     http.MethodPost,
+			path:       "/api/v1/transaction/transactions/transfer",
+			body:       `{"source_wallet_id":"nope","amount":10.5,"currency":"inr","description":"Rent"}`,
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"body.source_wallet_id", "body.destination_wallet_id", "body.amount", "body.currency"},
+		},
+		{
+			name:       "malformed JSON",
+			method:     http.MethodPost,
+			path:       "/api/v1/auth/login",
+			body:       `{"identifier":`,
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"body"},
+		},
+		{
+			name:       "invalid path and query params",
+			method:     http.MethodGet,
+			path:       "/api/v1/transaction/wallets/abc/transactions?status=done&limit=x",
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"path.walletId", "query.status", "query.limit"},
+		},
+		{
+			name:       "undescribed route passes through",
+			method:     http.MethodPost,
+			path:       "/api/v1/ledger/entries",
+			body:       `not json`,
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxied = ""
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK {
+				if proxied != tt.body {
+					t.Errorf("expected body %q to reach the backend, got %q", tt.body, proxied)
+				}
+				return
+			}
+
+			var resp struct {
+				Error struct {
+					Code    string            `json:"code"`
+					Details map[string]string `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Error.Code != "VALIDATION_ERROR" {
+				t.Errorf("expected VALIDATION_ERROR, got %s", resp.Error.Code)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := resp.Error.Details[field]; !ok {
+					t.Errorf("expected error for %s, got %v", field, resp.Error.Details)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddleware_BodyTooLarge(t *testing.T) {
+	handler := Gateway().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("oversized body should not be proxied")
+	}))
+
+	body := `{"identifier":"` + strings.Repeat("a", MaxBodyBytes) + `","password":"x"}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body)))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
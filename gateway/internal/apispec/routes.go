@@ -0,0 +1,241 @@
+package apispec
+
+// Gateway returns the description of the routes clients call through the
+// gateway. Request schemas mirror the backend request models, so a request
+// that passes here fails backend validation only on business rules.
+func Gateway() *Spec {
+	return &Spec{
+		Title:   "Nivo Money API",
+		Version: "v1",
+		Operations: []Operation{
+			// Gateway
+			{
+				Method: "GET", Path: "/api/v1/openapi.json",
+				OperationID: "getOpenAPI", Summary: "OpenAPI description of the gateway routes",
+				Tag: "gateway", Public: true,
+			},
+			{
+				Method: "GET", Path: "/api/v1/errors",
+				OperationID: "listErrorCodes", Summary: "Error codes returned by the API",
+				Tag: "gateway", Public: true,
+			},
+
+			// Identity
+			{
+				Method: "POST", Path: "/api/v1/identity/auth/register", Aliases: []string{"/api/v1/auth/register"},
+				OperationID: "register", Summary: "Register a new user",
+				Tag: "identity", Public: true,
+				Body: object(map[string]*Schema{
+					"email":     {Type: "string", Format: "email"},
+					"phone":     {Type: "string", Description: "Indian mobile number"},
+					"full_name": str(2, 100),
+					"password":  str(8, 100),
+				}, "email", "phone", "full_name", "password"),
+			},
+			{
+				Method: "POST", Path: "/api/v1/identity/auth/login", Aliases: []string{"/api/v1/auth/login"},
+				OperationID: "login", Summary: "Log in with email or phone and password",
+				Tag: "identity", Public: true,
+				Body: object(map[string]*Schema{
+					"identifier":    {Type: "string", Description: "Email or phone number"},
+					"password":      {Type: "string"},
+					"portal":        {Type: "string", Enum: []string{"user", "admin"}},
+					"captcha_token": {Type: "string", Description: "Required once a failed login returns captcha_required"},
+				}, "identifier", "password"),
+			},
+			{
+				Method: "POST", Path: "/api/v1/auth/unlock",
+				OperationID: "unlockAccount", Summary: "Unlock an account with the link from the lockout email",
+				Tag: "identity", Public: true,
+				Body: object(map[string]*Schema{
+					"token": {Type: "string"},
+				}, "token"),
+			},
+			{
+				Method: "POST", Path: "/api/v1/auth/password/forgot",
+				OperationID: "forgotPassword", Summary: "Request a password reset",
+				Tag: "identity", Public: true,
+				Body: object(map[string]*Schema{
+					"email": {Type: "string", Format: "email"},
+				}, "email"),
+			},
+			{
+				Method: "POST", Path: "/api/v1/auth/password/reset",
+				OperationID: "resetPassword", Summary: "Reset a password with a verification token",
+				Tag: "identity", Public: true,
+				Body: object(map[string]*Schema{
+					"verification_token": {Type: "string"},
+					"new_password":       str(8, 0),
+				}, "verification_token", "new_password"),
+			},
+			{
+				Method: "GET", Path: "/api/v1/identity/users/me",
+				OperationID: "getProfile", Summary: "The caller's profile",
+				Tag: "identity",
+			},
+
+			// Wallet
+			{
+				Method: "GET", Path: "/api/v1/wallet/wallets",
+				OperationID: "listWallets", Summary: "The caller's wallets",
+				Tag: "wallet",
+			},
+			{
+				Method: "POST", Path: "/api/v1/wallet/wallets",
+				OperationID: "createWallet", Summary: "Create a wallet",
+				Tag: "wallet",
+				Body: object(map[string]*Schema{
+					"type":              {Type: "string", Enum: []string{"default"}},
+					"currency":          currency,
+					"ledger_account_id": uuidSchema,
+				}, "type", "currency"),
+			},
+			{
+				Method: "GET", Path: "/api/v1/wallet/wallets/{id}",
+				OperationID: "getWallet", Summary: "A wallet",
+				Tag: "wallet", Params: []Param{pathID("id")},
+			},
+			{
+				Method: "GET", Path: "/api/v1/wallet/wallets/{id}/balance",
+				OperationID: "getWalletBalance", Summary: "A wallet's balance",
+				Tag: "wallet", Params: []Param{pathID("id")},
+			},
+			{
+				Method: "GET", Path: "/api/v1/wallet/beneficiaries",
+				OperationID: "listBeneficiaries", Summary: "The caller's beneficiaries",
+				Tag: "wallet",
+			},
+			{
+				Method: "POST", Path: "/api/v1/wallet/beneficiaries",
+				OperationID: "addBeneficiary", Summary: "Add a beneficiary by phone number",
+				Tag: "wallet",
+				Body: object(map[string]*Schema{
+					"phone":    {Type: "string", Pattern: `^\+[1-9]\d{1,14}$`, Description: "E.164 phone number"},
+					"nickname": str(1, 100),
+				}, "phone", "nickname"),
+			},
+			{
+				Method: "GET", Path: "/api/v1/wallet/beneficiaries/{id}",
+				OperationID: "getBeneficiary", Summary: "A beneficiary",
+				Tag: "wallet", Params: []Param{pathID("id")},
+			},
+			{
+				Method: "PUT", Path: "/api/v1/wallet/beneficiaries/{id}",
+				OperationID: "updateBeneficiary", Summary: "Rename a beneficiary",
+				Tag: "wallet", Params: []Param{pathID("id")},
+				Body: object(map[string]*Schema{
+					"nickname": str(1, 100),
+				}, "nickname"),
+			},
+			{
+				Method: "DELETE", Path: "/api/v1/wallet/beneficiaries/{id}",
+				OperationID: "deleteBeneficiary", Summary: "Remove a beneficiary",
+				Tag: "wallet", Params: []Param{pathID("id")},
+			},
+
+			// Transaction
+			{
+				Method: "POST", Path: "/api/v1/transaction/transactions/transfer",
+				OperationID: "createTransfer", Summary: "Transfer between wallets",
+				Tag: "transaction",
+				Body: object(map[string]*Schema{
+					"source_wallet_id":      uuidSchema,
+					"destination_wallet_id": uuidSchema,
+					"amount":                amount,
+					"currency":              currency,
+					"description":           str(3, 500),
+					"reference":             str(0, 100),
+				}, "source_wallet_id", "destination_wallet_id", "amount", "currency", "description"),
+			},
+			{
+				Method: "POST", Path: "/api/v1/transaction/transactions/deposit",
+				OperationID: "createDeposit", Summary: "Deposit into a wallet",
+				Tag:  "transaction",
+				Body: walletMovement(),
+			},
+			{
+				Method: "POST", Path: "/api/v1/transaction/transactions/withdrawal",
+				OperationID: "createWithdrawal", Summary: "Withdraw from a wallet",
+				Tag:  "transaction",
+				Body: walletMovement(),
+			},
+			{
+				Method: "GET", Path: "/api/v1/transaction/transactions/{id}",
+				OperationID: "getTransaction", Summary: "A transaction",
+				Tag: "transaction", Params: []Param{pathID("id")},
+			},
+			{
+				Method: "POST", Path: "/api/v1/transaction/transactions/{id}/reverse",
+				OperationID: "reverseTransaction", Summary: "Reverse a completed transaction",
+				Tag: "transaction", Params: []Param{pathID("id")},
+				Body: object(map[string]*Schema{
+					"reason": str(10, 500),
+				}, "reason"),
+			},
+			{
+				Method: "GET", Path: "/api/v1/transaction/wallets/{walletId}/transactions",
+				OperationID: "listWalletTransactions", Summary: "A wallet's transactions, newest first",
+				Tag: "transaction",
+				Params: []Param{
+					pathID("walletId"),
+					query("status", &Schema{Type: "string", Enum: []string{"pending", "processing", "completed", "failed", "reversed", "cancelled"}}),
+					query("type", &Schema{Type: "string", Enum: []string{"transfer", "deposit", "withdrawal", "reversal", "fee", "refund"}}),
+					query("search", str(0, 200)),
+					query("min_amount", integer(0)),
+					query("max_amount", integer(0)),
+					query("limit", integer(1)),
+					query("offset", integer(0)),
+				},
+			},
+		},
+	}
+}
+
+var (
+	uuidSchema = &Schema{Type: "string", Format: "uuid"}
+	currency   = &Schema{Type: "string", Pattern: "^[A-Z]{3}$", Description: "ISO 4217 currency code"}
+	amount     = &Schema{Type: "integer", Minimum: float(1), Description: "Amount in the smallest currency unit (paise)"}
+)
+
+// walletMovement is the body of a deposit or withdrawal.
+func walletMovement() *Schema {
+	return object(map[string]*Schema{
+		"wallet_id":   uuidSchema,
+		"amount":      amount,
+		"currency":    currency,
+		"description": str(3, 500),
+		"reference":   str(0, 100),
+	}, "wallet_id", "amount", "currency", "description")
+}
+
+func object(properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// str is a string schema; a zero bound is left unset.
+func str(minLength, maxLength int) *Schema {
+	s := &Schema{Type: "string"}
+	if minLength > 0 {
+		s.MinLength = &minLength
+	}
+	if maxLength > 0 {
+		s.MaxLength = &maxLength
+	}
+	return s
+}
+
+func integer(minimum float64) *Schema {
+	return &Schema{Type: "integer", Minimum: &minimum}
+}
+
+func pathID(name string) Param {
+	return Param{Name: name, In: "path", Required: true, Schema: uuidSchema}
+}
+
+func query(name string, schema *Schema) Param {
+	return Param{Name: name, In: "query", Schema: schema}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
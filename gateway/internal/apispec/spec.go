@@ -0,0 +1,193 @@
+// Package apispec describes the gateway's public API: the routes clients call,
+// their parameters and their request bodies. The same description is served as
+// an OpenAPI 3 document at /api/v1/openapi.json for client generation and
+// drives request validation at the edge, so malformed requests are rejected
+// with a consistent validation error before they are proxied to a backend.
+//
+// Routes that are not described are proxied without validation.
+package apispec
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/pathmatch"
+)
+
+// Schema is the subset of JSON Schema the gateway documents and validates.
+type Schema struct {
+	Type        string             `json:"type,omitempty"` // object, string, integer, number, boolean or array
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+}
+
+// Param is a path or query parameter.
+type Param struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path or query
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Operation is one gateway route.
+type Operation struct {
+	Method      string
+	Path        string   // Gateway path; {name} segments are path parameters
+	Aliases     []string // Other gateway paths served by the same operation, validated but not documented
+	OperationID string
+	Summary     string
+	Tag         string
+	Public      bool // No bearer token required
+	Params      []Param
+	Body        *Schema // JSON request body; nil if the route takes none
+}
+
+// Spec is the gateway's API description.
+type Spec struct {
+	Title      string
+	Version    string
+	Operations []Operation
+}
+
+// Match returns the operation serving method and path, with its path
+// parameters, or nil if the route is not described.
+func (s *Spec) Match(method, path string) (*Operation, map[string]string) {
+	for i := range s.Operations {
+		op := &s.Operations[i]
+		if op.Method != method {
+			continue
+		}
+		for _, pattern := range append([]string{op.Path}, op.Aliases...) {
+			if params, ok := pathmatch.Params(pattern, path); ok {
+				return op, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Document builds the OpenAPI 3 document for the spec.
+func (s *Spec) Document() map[string]any {
+	paths := make(map[string]map[string]any)
+	tags := make(map[string]bool)
+
+	for _, op := range s.Operations {
+		item := map[string]any{
+			"operationId": op.OperationID,
+			"summary":     op.Summary,
+			"responses": map[string]any{
+				"default": map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		}
+		if op.Tag != "" {
+			item["tags"] = []string{op.Tag}
+			tags[op.Tag] = true
+		}
+		if op.Public {
+			item["security"] = []any{}
+		}
+		if len(op.Params) > 0 {
+			item["parameters"] = op.Params
+		}
+		if op.Body != nil {
+			item["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": op.Body},
+				},
+			}
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = make(map[string]any)
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = item
+	}
+
+	tagList := make([]map[string]string, 0, len(tags))
+	for _, name := range sortedKeys(tags) {
+		tagList = append(tagList, map[string]string{"name": name})
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]string{
+			"title":   s.Title,
+			"version": s.Version,
+		},
+		"tags":     tagList,
+		"security": []any{map[string][]string{"bearerAuth": {}}},
+		"paths":    paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]string{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+			"schemas": map[string]any{
+				"Error": errorSchema,
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error response; validation failures list the offending fields in error.details",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]string{"$ref": "#/components/schemas/Error"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Handler serves the OpenAPI document as JSON.
+func (s *Spec) Handler() http.HandlerFunc {
+	doc, err := json.Marshal(s.Document())
+	if err != nil {
+		panic("apispec: cannot encode OpenAPI document: " + err.Error())
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(doc)
+	}
+}
+
+// errorSchema documents the response envelope returned for errors.
+var errorSchema = &Schema{
+	Type:     "object",
+	Required: []string{"success", "error"},
+	Properties: map[string]*Schema{
+		"success": {Type: "boolean"},
+		"error": {
+			Type:     "object",
+			Required: []string{"code", "message"},
+			Properties: map[string]*Schema{
+				"code":    {Type: "string", Description: "Error code, see GET /api/v1/errors"},
+				"message": {Type: "string"},
+				"details": {Type: "object"},
+			},
+		},
+	},
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
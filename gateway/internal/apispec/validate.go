@@ -0,0 +1,295 @@
+package apispec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+	"github.com/google/uuid"
+)
+
+// MaxBodyBytes is the largest request body the gateway reads for validation.
+const MaxBodyBytes = 1 << 20
+
+// patterns caches compiled schema patterns.
+var patterns sync.Map // pattern -> *regexp.Regexp
+
+// Middleware validates requests to described routes and rejects invalid ones
+// with a VALIDATION_ERROR listing each offending field, keyed by location:
+// "path.id", "query.limit" or "body.amount". Other routes pass through.
+func (s *Spec) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, pathParams := s.Match(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := op.Validate(r, pathParams); err != nil {
+			response.Error(w, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Validate checks a request's parameters and body against the operation. The
+// body is buffered and restored so it can still be proxied.
+func (op *Operation) Validate(r *http.Request, pathParams map[string]string) *errors.Error {
+	fields := make(map[string]string)
+
+	query := r.URL.Query()
+	for _, param := range op.Params {
+		var value string
+		switch param.In {
+		case "path":
+			value = pathParams[param.Name]
+		case "query":
+			value = query.Get(param.Name)
+		default:
+			continue
+		}
+		field := param.In + "." + param.Name
+		if value == "" {
+			if param.Required {
+				fields[field] = "is required"
+			}
+			continue
+		}
+		validateParam(param.Schema, value, field, fields)
+	}
+
+	if op.Body != nil {
+		if err := validateBody(r, op.Body, fields); err != nil {
+			return err
+		}
+	}
+
+	if len(fields) > 0 {
+		return errors.ValidationWithFields("request validation failed", fields)
+	}
+	return nil
+}
+
+// validateBody reads, validates and restores the request body.
+func validateBody(r *http.Request, schema *Schema, fields map[string]string) *errors.Error {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+		_ = r.Body.Close()
+		if err != nil {
+			return errors.BadRequest("failed to read request body")
+		}
+		if len(body) > MaxBodyBytes {
+			return errors.BadRequest(fmt.Sprintf("request body exceeds %d bytes", MaxBodyBytes))
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		fields["body"] = "is required"
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		fields["body"] = "must be valid JSON"
+		return nil
+	}
+
+	validateValue(schema, value, "body", fields)
+	return nil
+}
+
+// validateParam converts a parameter to its schema type and validates it.
+func validateParam(schema *Schema, raw, field string, fields map[string]string) {
+	if schema == nil {
+		return
+	}
+
+	var value any = raw
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			fields[field] = "must be a " + schemaTypeName(schema.Type)
+			return
+		}
+		value = json.Number(raw)
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			fields[field] = "must be a boolean"
+			return
+		}
+		value = b
+	}
+	validateValue(schema, value, field, fields)
+}
+
+// validateValue records the first problem with value at field, recursing into
+// objects and arrays.
+func validateValue(schema *Schema, value any, field string, fields map[string]string) {
+	if schema == nil || value == nil {
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			fields[field] = "must be an object"
+			return
+		}
+		for _, name := range schema.Required {
+			if v, present := obj[name]; !present || v == nil {
+				fields[field+"."+name] = "is required"
+			}
+		}
+		for name, propSchema := range schema.Properties {
+			if v, present := obj[name]; present {
+				validateValue(propSchema, v, field+"."+name, fields)
+			}
+		}
+
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fields[field] = "must be an array"
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			fields[field] = fmt.Sprintf("must have at least %d items", *schema.MinItems)
+			return
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fields[field] = fmt.Sprintf("must have at most %d items", *schema.MaxItems)
+			return
+		}
+		for i, item := range items {
+			validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", field, i), fields)
+		}
+
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fields[field] = "must be a string"
+			return
+		}
+		if msg := checkString(schema, s); msg != "" {
+			fields[field] = msg
+		}
+
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			fields[field] = "must be a " + schemaTypeName(schema.Type)
+			return
+		}
+		if msg := checkNumber(schema, n); msg != "" {
+			fields[field] = msg
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fields[field] = "must be a boolean"
+		}
+	}
+}
+
+func checkString(schema *Schema, s string) string {
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Sprintf("must be at most %d characters", *schema.MaxLength)
+	}
+	if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+		return "must be one of: " + strings.Join(schema.Enum, ", ")
+	}
+	if schema.Pattern != "" && !compiled(schema.Pattern).MatchString(s) {
+		return "has an invalid format"
+	}
+
+	switch schema.Format {
+	case "uuid":
+		if _, err := uuid.Parse(s); err != nil {
+			return "must be a valid UUID"
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	}
+	return ""
+}
+
+func checkNumber(schema *Schema, n json.Number) string {
+	if schema.Type == "integer" {
+		if _, err := n.Int64(); err != nil {
+			return "must be an integer"
+		}
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return "must be a number"
+	}
+	if schema.Minimum != nil && f < *schema.Minimum {
+		return fmt.Sprintf("must be at least %s", formatNumber(*schema.Minimum))
+	}
+	if schema.Maximum != nil && f > *schema.Maximum {
+		return fmt.Sprintf("must be at most %s", formatNumber(*schema.Maximum))
+	}
+	return ""
+}
+
+func compiled(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func schemaTypeName(t string) string {
+	if t == "integer" {
+		return "an integer"
+	}
+	return "a number"
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+// Package bodylimit caps request body sizes at the gateway and gives slow
+// clients a deadline for sending them. Bodies are read in full before the
+// request is proxied, so a backend never holds a connection open for a client
+// trickling bytes. Oversized bodies are rejected with 413 and bodies not sent
+// in time with 408.
+package bodylimit
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/pathmatch"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+const (
+	// DefaultMaxBytes applies to routes without a rule.
+	DefaultMaxBytes = 1 << 20
+	// MaxRuleBytes bounds any configured limit, since bodies are buffered in memory.
+	MaxRuleBytes = 32 << 20
+	// DefaultReadTimeout is how long a client has to send its body.
+	DefaultReadTimeout = 10 * time.Second
+
+	// defaultRuleName labels rejections on routes without a rule.
+	defaultRuleName = "default"
+)
+
+var rejections = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "gateway_request_rejections_total",
+	Help: "Requests rejected at the gateway by reason (body_too_large, body_read_timeout) and body limit rule",
+}, []string{"reason", "rule"})
+
+// Rule sets the body size limit for a route.
+type Rule struct {
+	Name     string
+	Method   string // Empty matches every method
+	Pattern  string // Gateway path, {name} matches one segment, trailing /* the rest
+	MaxBytes int64
+}
+
+// ruleConfig is the JSON form of a Rule.
+type ruleConfig struct {
+	Name     string `json:"name"`
+	Method   string `json:"method,omitempty"`
+	Pattern  string `json:"pattern"`
+	MaxBytes int64  `json:"max_bytes"`
+}
+
+// Config controls body limits.
+type Config struct {
+	DefaultMaxBytes int64
+	ReadTimeout     time.Duration // Zero leaves only the server's read timeout
+	Rules           []Rule        // First match wins
+}
+
+// DefaultRules keeps credential endpoints, which never need large bodies, small.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "auth", Method: http.MethodPost, Pattern: "/api/v1/auth/*", MaxBytes: 16 << 10},
+		{Name: "identity-auth-login", Method: http.MethodPost, Pattern: "/api/v1/identity/auth/login", MaxBytes: 16 << 10},
+		{Name: "identity-auth-register", Method: http.MethodPost, Pattern: "/api/v1/identity/auth/register", MaxBytes: 16 << 10},
+	}
+}
+
+// DefaultConfig allows 1 MB bodies sent within 10 seconds.
+func DefaultConfig() Config {
+	return Config{
+		DefaultMaxBytes: DefaultMaxBytes,
+		ReadTimeout:     DefaultReadTimeout,
+		Rules:           DefaultRules(),
+	}
+}
+
+// ConfigFromEnv reads GATEWAY_MAX_BODY_BYTES, GATEWAY_BODY_READ_TIMEOUT_SECONDS
+// and GATEWAY_BODY_LIMIT_RULES, falling back to DefaultConfig for unset or
+// invalid values. Invalid rules are reported so the caller can log them.
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if v, err := strconv.ParseInt(os.Getenv("GATEWAY_MAX_BODY_BYTES"), 10, 64); err == nil && v > 0 && v <= MaxRuleBytes {
+		cfg.DefaultMaxBytes = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_BODY_READ_TIMEOUT_SECONDS")); err == nil && v >= 0 {
+		cfg.ReadTimeout = time.Duration(v) * time.Second
+	}
+	if raw := os.Getenv("GATEWAY_BODY_LIMIT_RULES"); raw != "" {
+		rules, err := ParseRules(raw)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Rules = rules
+	}
+	return cfg, nil
+}
+
+// ParseRules decodes rules from JSON, e.g. the GATEWAY_BODY_LIMIT_RULES variable.
+func ParseRules(data string) ([]Rule, error) {
+	var configs []ruleConfig
+	if err := json.Unmarshal([]byte(data), &configs); err != nil {
+		return nil, fmt.Errorf("invalid body limit rules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(configs))
+	for _, c := range configs {
+		rules = append(rules, Rule{
+			Name:     c.Name,
+			Method:   strings.ToUpper(c.Method),
+			Pattern:  c.Pattern,
+			MaxBytes: c.MaxBytes,
+		})
+	}
+	return rules, validateRules(rules)
+}
+
+func validateRules(rules []Rule) error {
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.Name == "" || rule.Name == defaultRuleName || seen[rule.Name] {
+			return fmt.Errorf("rules[%d]: name is required, must be unique and must not be %q", i, defaultRuleName)
+		}
+		seen[rule.Name] = true
+		if !strings.HasPrefix(rule.Pattern, "/") {
+			return fmt.Errorf("rules[%d]: pattern must start with /", i)
+		}
+		if rule.MaxBytes <= 0 || rule.MaxBytes > MaxRuleBytes {
+			return fmt.Errorf("rules[%d]: max_bytes must be between 1 and %d", i, MaxRuleBytes)
+		}
+	}
+	return nil
+}
+
+// Limiter enforces body limits.
+type Limiter struct {
+	defaultMaxBytes int64
+	readTimeout     time.Duration
+	rules           []Rule
+}
+
+// New creates a limiter from cfg.
+func New(cfg Config) (*Limiter, error) {
+	if cfg.DefaultMaxBytes <= 0 || cfg.DefaultMaxBytes > MaxRuleBytes {
+		return nil, fmt.Errorf("default max bytes must be between 1 and %d", MaxRuleBytes)
+	}
+	if cfg.ReadTimeout < 0 {
+		return nil, fmt.Errorf("read timeout must not be negative")
+	}
+	if err := validateRules(cfg.Rules); err != nil {
+		return nil, err
+	}
+	return &Limiter{
+		defaultMaxBytes: cfg.DefaultMaxBytes,
+		readTimeout:     cfg.ReadTimeout,
+		rules:           append([]Rule(nil), cfg.Rules...),
+	}, nil
+}
+
+// Rules returns the configured rules.
+func (l *Limiter) Rules() []Rule {
+	return append([]Rule(nil), l.rules...)
+}
+
+// limitFor returns the body limit for a request and the name of the rule that set it.
+func (l *Limiter) limitFor(r *http.Request) (int64, string) {
+	for _, rule := range l.rules {
+		if rule.Method != "" && rule.Method != r.Method {
+			continue
+		}
+		if pathmatch.Match(rule.Pattern, r.URL.Path) {
+			return rule.MaxBytes, rule.Name
+		}
+	}
+	return l.defaultMaxBytes, defaultRuleName
+}
+
+// Middleware reads each request body within the limit and read timeout before
+// passing the request on with the buffered body.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit, rule := l.limitFor(r)
+		if r.ContentLength > limit {
+			reject(w, "body_too_large", rule, tooLarge(limit))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		if l.readTimeout > 0 {
+			// Unsupported writers (e.g. in tests) just keep the server's timeout
+			_ = rc.SetReadDeadline(time.Now().Add(l.readTimeout))
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		_ = r.Body.Close()
+		if l.readTimeout > 0 {
+			_ = rc.SetReadDeadline(time.Time{})
+		}
+
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			var netErr net.Error
+			switch {
+			case stderrors.As(err, &maxBytesErr):
+				reject(w, "body_too_large", rule, tooLarge(limit))
+			case stderrors.Is(err, os.ErrDeadlineExceeded), stderrors.As(err, &netErr) && netErr.Timeout():
+				reject(w, "body_read_timeout", rule, errors.RequestTimeout("request body was not received in time"))
+			default:
+				response.Error(w, errors.BadRequest("failed to read request body"))
+			}
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.TransferEncoding = nil
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tooLarge(limit int64) *errors.Error {
+	return errors.PayloadTooLarge(fmt.Sprintf("request body exceeds the limit of %d bytes", limit)).
+		AddDetail("max_bytes", limit)
+}
+
+// reject records the rejection and responds with err. The connection is closed
+// since the rest of the body was not read.
+func reject(w http.ResponseWriter, reason, rule string, err *errors.Error) {
+	rejections.WithLabelValues(reason, rule).Inc()
+	w.Header().Set("Connection", "close")
+	response.Error(w, err)
+}
@@ -0,0 +1,166 @@
+package bodylimit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echo replies with the body it received.
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	_, _ = w.Write(body)
+})
+
+func newLimiter(t *testing.T, cfg Config) *Limiter {
+	t.Helper()
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	return l
+}
+
+func errorCode(t *testing.T, body []byte) string {
+	t.Helper()
+	var envelope struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("expected error envelope, got %s", body)
+	}
+	return envelope.Error.Code
+}
+
+func TestMiddleware_PassesBodyWithinLimit(t *testing.T) {
+	l := newLimiter(t, Config{DefaultMaxBytes: 16})
+	rec := httptest.NewRecorder()
+	l.Middleware(echo).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader(`{"a":1}`)))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"a":1}` {
+		t.Errorf("expected body to reach the handler, got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestMiddleware_RejectsDeclaredLength(t *testing.T) {
+	l := newLimiter(t, Config{DefaultMaxBytes: 4})
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	rec := httptest.NewRecorder()
+	l.Middleware(next).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/wallets", strings.NewReader("too long")))
+
+	if rec.Code != http.StatusRequestEntityTooLarge || errorCode(t, rec.Body.Bytes()) != "PAYLOAD_TOO_LARGE" {
+		t.Errorf("expected 413 PAYLOAD_TOO_LARGE, got %d %s", rec.Code, rec.Body.String())
+	}
+	if called {
+		t.Error("expected handler not to be called")
+	}
+}
+
+func TestMiddleware_RejectsUndeclaredLength(t *testing.T) {
+	l := newLimiter(t, Config{DefaultMaxBytes: 4})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallets", io.NopCloser(strings.NewReader("too long")))
+	req.ContentLength = -1 // Chunked: the size is only known once read
+
+	rec := httptest.NewRecorder()
+	l.Middleware(echo).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", rec.Code)
+	}
+}
+
+func TestMiddleware_RuleMatching(t *testing.T) {
+	l := newLimiter(t, Config{
+		DefaultMaxBytes: 64,
+		Rules: []Rule{
+			{Name: "login", Method: http.MethodPost, Pattern: "/api/v1/auth/*", MaxBytes: 4},
+			{Name: "docs", Pattern: "/api/v1/identity/kyc/{id}/documents", MaxBytes: 8},
+		},
+	})
+
+	tests := []struct {
+		method, path string
+		wantLimit    int64
+		wantRule     string
+	}{
+		{http.MethodPost, "/api/v1/auth/login", 4, "login"},
+		{http.MethodPut, "/api/v1/auth/login", 64, defaultRuleName},
+		{http.MethodPut, "/api/v1/identity/kyc/42/documents", 8, "docs"},
+		{http.MethodPost, "/api/v1/wallets", 64, defaultRuleName},
+	}
+	for _, tt := range tests {
+		limit, rule := l.limitFor(httptest.NewRequest(tt.method, tt.path, nil))
+		if limit != tt.wantLimit || rule != tt.wantRule {
+			t.Errorf("%s %s: expected %d from %s, got %d from %s", tt.method, tt.path, tt.wantLimit, tt.wantRule, limit, rule)
+		}
+	}
+}
+
+func TestMiddleware_SlowBodyTimesOut(t *testing.T) {
+	l := newLimiter(t, Config{DefaultMaxBytes: 64, ReadTimeout: 100 * time.Millisecond})
+	server := httptest.NewServer(l.Middleware(echo))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Declare ten bytes but only send two
+	_, _ = fmt.Fprint(conn, "POST /api/v1/wallets HTTP/1.1\r\nHost: test\r\nContent-Length: 10\r\n\r\nab")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("expected a response, got %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusRequestTimeout || errorCode(t, body) != "REQUEST_TIMEOUT" {
+		t.Errorf("expected 408 REQUEST_TIMEOUT, got %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(`[{"name": "upload", "method": "put", "pattern": "/api/v1/files/*", "max_bytes": 5242880}]`)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rules[0].Method != http.MethodPut || rules[0].MaxBytes != 5<<20 {
+		t.Errorf("unexpected rule: %+v", rules[0])
+	}
+
+	invalid := []string{
+		`not json`,
+		`[{"name": "", "pattern": "/api/v1/x", "max_bytes": 10}]`,
+		`[{"name": "default", "pattern": "/api/v1/x", "max_bytes": 10}]`,
+		`[{"name": "a", "pattern": "/x", "max_bytes": 10}, {"name": "a", "pattern": "/y", "max_bytes": 10}]`,
+		`[{"name": "a", "pattern": "api/v1/x", "max_bytes": 10}]`,
+		`[{"name": "a", "pattern": "/api/v1/x", "max_bytes": 0}]`,
+		`[{"name": "a", "pattern": "/api/v1/x", "max_bytes": 1073741824}]`,
+	}
+	for _, raw := range invalid {
+		if _, err := ParseRules(raw); err == nil {
+			t.Errorf("expected error for %s", raw)
+		}
+	}
+}
+
+func TestDefaultConfig_IsValid(t *testing.T) {
+	if _, err := New(DefaultConfig()); err != nil {
+		t.Fatalf("expected default config to be valid, got %v", err)
+	}
+}
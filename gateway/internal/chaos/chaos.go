@@ -0,0 +1,178 @@
+// Package chaos implements fault injection at the gateway so the simulation
+// service can exercise timeouts, server errors and slow event delivery
+// against the real backend services. It is never enabled in production.
+package chaos
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// FaultType identifies the kind of fault injected into a proxied call.
+type FaultType string
+
+const (
+	// FaultTimeout holds the request for the configured duration, then fails with 504.
+	FaultTimeout FaultType = "timeout"
+	// FaultError fails the request immediately with a 500.
+	FaultError FaultType = "error"
+	// FaultLatency delays the request, then proxies it normally.
+	FaultLatency FaultType = "latency"
+)
+
+const (
+	// DefaultDuration is how long a chaos configuration stays active when none is given.
+	DefaultDuration = 5 * time.Minute
+	// MaxDuration bounds how long a chaos configuration may stay active.
+	MaxDuration = time.Hour
+	// DefaultTimeoutDelay is how long a timeout fault holds a request.
+	DefaultTimeoutDelay = 10 * time.Second
+	// MaxDelay bounds any single injected delay.
+	MaxDelay = 25 * time.Second
+)
+
+// Rule injects one kind of fault into calls for a service.
+type Rule struct {
+	// Service is the canonical backend name (identity, wallet, ...) or "*" for all.
+	Service     string    `json:"service"`
+	Fault       FaultType `json:"fault"`
+	Probability float64   `json:"probability"`
+	DelayMs     int       `json:"delay_ms,omitempty"`
+}
+
+// Config is the active chaos configuration.
+type Config struct {
+	Rules      []Rule    `json:"rules"`
+	SSEDelayMs int       `json:"sse_delay_ms,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Decision is the fault chosen for a single request.
+type Decision struct {
+	Fault FaultType
+	Delay time.Duration
+}
+
+// Injector holds the chaos configuration and decides which requests to disturb.
+type Injector struct {
+	mu     sync.RWMutex
+	config *Config
+	now    func() time.Time
+	rand   func() float64
+}
+
+// NewInjector creates an injector with no active chaos.
+func NewInjector() *Injector {
+	return &Injector{
+		now:  time.Now,
+		rand: rand.Float64,
+	}
+}
+
+// Validate checks a rule set before it is applied.
+func Validate(rules []Rule, sseDelayMs int) error {
+	for i, rule := range rules {
+		if rule.Service == "" {
+			return fmt.Errorf("rules[%d]: service is required", i)
+		}
+		switch rule.Fault {
+		case FaultTimeout, FaultError, FaultLatency:
+		default:
+			return fmt.Errorf("rules[%d]: fault must be 'timeout', 'error' or 'latency'", i)
+		}
+		if rule.Probability <= 0 || rule.Probability > 1 {
+			return fmt.Errorf("rules[%d]: probability must be in (0, 1]", i)
+		}
+		if rule.DelayMs < 0 || time.Duration(rule.DelayMs)*time.Millisecond > MaxDelay {
+			return fmt.Errorf("rules[%d]: delay_ms must be between 0 and %d", i, MaxDelay.Milliseconds())
+		}
+		if rule.Fault == FaultLatency && rule.DelayMs == 0 {
+			return fmt.Errorf("rules[%d]: latency fault requires delay_ms", i)
+		}
+	}
+	if sseDelayMs < 0 || time.Duration(sseDelayMs)*time.Millisecond > MaxDelay {
+		return fmt.Errorf("sse_delay_ms must be between 0 and %d", MaxDelay.Milliseconds())
+	}
+	return nil
+}
+
+// Set replaces the active configuration. The duration is clamped to MaxDuration.
+func (i *Injector) Set(rules []Rule, sseDelayMs int, duration time.Duration) (*Config, error) {
+	if err := Validate(rules, sseDelayMs); err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if duration > MaxDuration {
+		duration = MaxDuration
+	}
+
+	cfg := &Config{
+		Rules:      append([]Rule(nil), rules...),
+		SSEDelayMs: sseDelayMs,
+		ExpiresAt:  i.now().Add(duration),
+	}
+
+	i.mu.Lock()
+	i.config = cfg
+	i.mu.Unlock()
+
+	return cfg, nil
+}
+
+// Clear removes any active configuration.
+func (i *Injector) Clear() {
+	i.mu.Lock()
+	i.config = nil
+	i.mu.Unlock()
+}
+
+// Active returns the active configuration, or nil if none is set or it expired.
+func (i *Injector) Active() *Config {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if i.config == nil || !i.now().Before(i.config.ExpiresAt) {
+		return nil
+	}
+	cp := *i.config
+	cp.Rules = append([]Rule(nil), i.config.Rules...)
+	return &cp
+}
+
+// Decide picks the fault (if any) to inject into a call to service.
+// Rules are evaluated in order; the first one that fires wins.
+func (i *Injector) Decide(service string) *Decision {
+	cfg := i.Active()
+	if cfg == nil {
+		return nil
+	}
+
+	for _, rule := range cfg.Rules {
+		if rule.Service != "*" && rule.Service != service {
+			continue
+		}
+		if i.rand() >= rule.Probability {
+			continue
+		}
+
+		delay := time.Duration(rule.DelayMs) * time.Millisecond
+		if rule.Fault == FaultTimeout && delay == 0 {
+			delay = DefaultTimeoutDelay
+		}
+		return &Decision{Fault: rule.Fault, Delay: delay}
+	}
+	return nil
+}
+
+// SSEDelay returns the delay to apply before delivering each SSE event.
+func (i *Injector) SSEDelay() time.Duration {
+	cfg := i.Active()
+	if cfg == nil {
+		return 0
+	}
+	return time.Duration(cfg.SSEDelayMs) * time.Millisecond
+}
@@ -0,0 +1,90 @@
+package chaos
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestInjector(roll float64) *Injector {
+	inj := NewInjector()
+	inj.rand = func() float64 { return roll }
+	return inj
+}
+
+func TestInjector_Decide(t *testing.T) {
+	t.Run("no decision without configuration", func(t *testing.T) {
+		inj := newTestInjector(0)
+		if d := inj.Decide("wallet"); d != nil {
+			t.Errorf("expected no decision, got %+v", d)
+		}
+	})
+
+	t.Run("matches service and probability", func(t *testing.T) {
+		inj := newTestInjector(0.2)
+		_, err := inj.Set([]Rule{
+			{Service: "ledger", Fault: FaultError, Probability: 1},
+			{Service: "wallet", Fault: FaultTimeout, Probability: 0.5},
+		}, 0, time.Minute)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		d := inj.Decide("wallet")
+		if d == nil || d.Fault != FaultTimeout {
+			t.Fatalf("expected timeout fault, got %+v", d)
+		}
+		if d.Delay != DefaultTimeoutDelay {
+			t.Errorf("expected default timeout delay, got %v", d.Delay)
+		}
+		if d := inj.Decide("identity"); d != nil {
+			t.Errorf("expected no decision for unmatched service, got %+v", d)
+		}
+	})
+
+	t.Run("probability roll can skip rule", func(t *testing.T) {
+		inj := newTestInjector(0.9)
+		_, _ = inj.Set([]Rule{{Service: "*", Fault: FaultError, Probability: 0.5}}, 0, time.Minute)
+		if d := inj.Decide("wallet"); d != nil {
+			t.Errorf("expected rule to be skipped, got %+v", d)
+		}
+	})
+
+	t.Run("configuration expires", func(t *testing.T) {
+		now := time.Now()
+		inj := newTestInjector(0)
+		inj.now = func() time.Time { return now }
+		_, _ = inj.Set([]Rule{{Service: "*", Fault: FaultError, Probability: 1}}, 500, time.Minute)
+
+		if inj.SSEDelay() != 500*time.Millisecond {
+			t.Errorf("expected SSE delay 500ms, got %v", inj.SSEDelay())
+		}
+
+		now = now.Add(2 * time.Minute)
+		if inj.Active() != nil || inj.Decide("wallet") != nil || inj.SSEDelay() != 0 {
+			t.Error("expected chaos to be inactive after expiry")
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		sse   int
+	}{
+		{"missing service", []Rule{{Fault: FaultError, Probability: 1}}, 0},
+		{"unknown fault", []Rule{{Service: "*", Fault: "explode", Probability: 1}}, 0},
+		{"zero probability", []Rule{{Service: "*", Fault: FaultError}}, 0},
+		{"latency without delay", []Rule{{Service: "*", Fault: FaultLatency, Probability: 1}}, 0},
+		{"delay too long", []Rule{{Service: "*", Fault: FaultLatency, Probability: 1, DelayMs: 60000}}, 0},
+		{"negative sse delay", nil, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.rules, tt.sse); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// Package compress gzip- or deflate-encodes gateway responses for clients
+// that accept it. Only allowlisted content types at or above a minimum size
+// are compressed; small bodies, streams and responses a backend already
+// encoded pass through unchanged.
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Gzip and Deflate are the supported content codings. Deflate is the
+	// zlib format, as HTTP defines it.
+	Gzip    = "gzip"
+	Deflate = "deflate"
+
+	// DefaultMinBytes is the smallest body compressed by default. Smaller
+	// bodies gain little and can grow once encoded.
+	DefaultMinBytes = 1024
+)
+
+// DefaultContentTypes are the media types compressed by default: the text
+// formats APIs and reports are served in.
+func DefaultContentTypes() []string {
+	return []string{
+		"application/json",
+		"application/problem+json",
+		"application/xml",
+		"application/javascript",
+		"text/csv",
+		"text/html",
+		"text/plain",
+		"text/xml",
+	}
+}
+
+// Config controls response compression.
+type Config struct {
+	Level        int      // flate level, 1 (fastest) to 9 (smallest); -1 is the library default
+	MinBytes     int      // Bodies smaller than this are sent uncompressed
+	ContentTypes []string // Media types, without parameters, that are compressed
+}
+
+// DefaultConfig compresses the default content types at the default level
+// from 1 KB.
+func DefaultConfig() Config {
+	return Config{
+		Level:        flate.DefaultCompression,
+		MinBytes:     DefaultMinBytes,
+		ContentTypes: DefaultContentTypes(),
+	}
+}
+
+// ConfigFromEnv reads GATEWAY_COMPRESSION_LEVEL, GATEWAY_COMPRESSION_MIN_BYTES
+// and GATEWAY_COMPRESSION_TYPES (comma-separated media types), falling back to
+// DefaultConfig for unset or invalid values.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_COMPRESSION_LEVEL")); err == nil && validLevel(v) {
+		cfg.Level = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_COMPRESSION_MIN_BYTES")); err == nil && v >= 0 {
+		cfg.MinBytes = v
+	}
+	if raw := os.Getenv("GATEWAY_COMPRESSION_TYPES"); raw != "" {
+		var types []string
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		if len(types) > 0 {
+			cfg.ContentTypes = types
+		}
+	}
+	return cfg
+}
+
+func validLevel(level int) bool {
+	return level == flate.DefaultCompression || (level >= flate.BestSpeed && level <= flate.BestCompression)
+}
+
+// Compressor encodes responses according to its config.
+type Compressor struct {
+	minBytes int
+	types    map[string]bool
+	gzip     sync.Pool
+	deflate  sync.Pool
+}
+
+// New creates a compressor.
+func New(cfg Config) (*Compressor, error) {
+	if !validLevel(cfg.Level) {
+		return nil, fmt.Errorf("invalid compression level %d", cfg.Level)
+	}
+	if cfg.MinBytes < 0 {
+		return nil, fmt.Errorf("minimum size must not be negative")
+	}
+
+	c := &Compressor{minBytes: cfg.MinBytes, types: make(map[string]bool, len(cfg.ContentTypes))}
+	for _, t := range cfg.ContentTypes {
+		c.types[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+	level := cfg.Level
+	c.gzip.New = func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, level)
+		return w
+	}
+	c.deflate.New = func() any {
+		w, _ := zlib.NewWriterLevel(io.Discard, level)
+		return w
+	}
+	return c, nil
+}
+
+// Middleware compresses responses for clients that accept gzip or deflate.
+// Every response varies by Accept-Encoding, so it is always listed in Vary.
+func (c *Compressor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Upgraded connections are not HTTP responses
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := Negotiate(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, compressor: c, encoding: encoding, status: http.StatusOK}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressible reports whether a response with these headers may be encoded.
+func (c *Compressor) compressible(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	// Backends that encode their own responses, and partial content, are left alone
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && c.types[mediaType]
+}
+
+// Negotiate picks the coding to use from an Accept-Encoding header: gzip,
+// deflate, or "" to send the body as is. The highest quality wins, gzip on
+// ties; * stands for codings not listed and q=0 refuses a coding.
+func Negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	quality := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, q := parseCoding(part)
+		switch coding {
+		case "":
+			continue
+		case "*":
+			wildcard = q
+		default:
+			quality[coding] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, coding := range []string{Gzip, Deflate} {
+		q, ok := quality[coding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// parseCoding reads one Accept-Encoding element, e.g. "gzip;q=0.8".
+// Malformed quality values count as q=0.
+func parseCoding(part string) (string, float64) {
+	coding, params, _ := strings.Cut(part, ";")
+	coding = strings.ToLower(strings.TrimSpace(coding))
+	if coding == "" {
+		return "", 0
+	}
+
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			return coding, 0
+		}
+		q = parsed
+	}
+	return coding, q
+}
+
+// compressWriter buffers the start of a response until it knows whether to
+// compress it: once MinBytes have been written, or when the handler flushes
+// or finishes.
+type compressWriter struct {
+	http.ResponseWriter
+	compressor *Compressor
+	encoding   string
+
+	status      int
+	wroteHeader bool // The handler called WriteHeader
+	decided     bool // Headers were sent and buf emptied
+	buf         []byte
+	encoder     encoder // Set once decided to compress
+}
+
+// encoder is a pooled gzip or zlib writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader || cw.decided {
+		return
+	}
+	// Informational responses go straight out and are not the final status
+	if status >= 100 && status < 200 {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.status = status
+	cw.wroteHeader = true
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.compressor.minBytes {
+		if err := cw.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what has been written so far, so streams keep flowing. A
+// response flushed before reaching MinBytes is sent uncompressed.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		_ = cw.decide()
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide sends the headers, compressed or not, and the buffered body.
+func (cw *compressWriter) decide() error {
+	cw.decided = true
+	header := cw.Header()
+	if len(cw.buf) >= cw.compressor.minBytes && cw.compressor.compressible(cw.status, header) {
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		// A strong validator names the exact bytes, which are now different
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		cw.encoder = cw.compressor.acquire(cw.encoding, cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close finishes the response once the handler returns.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		// Nothing written and no status set: let the server send its default
+		if !cw.wroteHeader && len(cw.buf) == 0 {
+			return
+		}
+		_ = cw.decide()
+	}
+	if cw.encoder != nil {
+		_ = cw.encoder.Close()
+		cw.compressor.release(cw.encoding, cw.encoder)
+		cw.encoder = nil
+	}
+}
+
+func (c *Compressor) acquire(encoding string, w io.Writer) encoder {
+	pool := &c.gzip
+	if encoding == Deflate {
+		pool = &c.deflate
+	}
+	enc := pool.Get().(encoder)
+	enc.Reset(w)
+	return enc
+}
+
+func (c *Compressor) release(encoding string, enc encoder) {
+	enc.Reset(io.Discard)
+	if encoding == Deflate {
+		c.deflate.Put(enc)
+		return
+	}
+	c.gzip.Put(enc)
+}
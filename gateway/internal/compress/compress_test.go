@@ -0,0 +1,244 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", Gzip},
+		{"deflate", Deflate},
+		{"gzip, deflate, br", Gzip},
+		{"deflate, gzip", Gzip},
+		{"gzip;q=0.5, deflate", Deflate},
+		{"gzip;q=0, deflate;q=0", ""},
+		{"br", ""},
+		{"*", Gzip},
+		{"*;q=0.1, gzip;q=0", Deflate},
+		{"identity", ""},
+		{"GZIP ; Q=0.8", Gzip},
+		{"gzip;q=abc", ""},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func newTestCompressor(t *testing.T) *Compressor {
+	t.Helper()
+	c, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return c
+}
+
+// serve runs handler behind the middleware for a request accepting encoding.
+func serve(t *testing.T, c *Compressor, encoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/transaction/transactions", nil)
+	if encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+	rec := httptest.NewRecorder()
+	c.Middleware(handler).ServeHTTP(rec, req)
+	return rec
+}
+
+func jsonHandler(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Content-Length", "999")
+		w.WriteHeader(http.StatusOK)
+		// Written in pieces, as a proxied body would be
+		for i := 0; i < len(body); i += 100 {
+			_, _ = w.Write([]byte(body[i:min(i+100, len(body))]))
+		}
+	}
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var r io.ReadCloser
+	var err error
+	switch encoding {
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(body))
+	case Deflate:
+		r, err = zlib.NewReader(bytes.NewReader(body))
+	}
+	if err != nil {
+		t.Fatalf("failed to open %s body: %v", encoding, err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read %s body: %v", encoding, err)
+	}
+	return string(decoded)
+}
+
+func TestMiddleware_CompressesLargeAllowedResponses(t *testing.T) {
+	c := newTestCompressor(t)
+	body := `{"data":[` + strings.Repeat(`{"id":"txn","amount":10000},`, 200) + `{}]}`
+
+	for _, encoding := range []string{Gzip, Deflate} {
+		rec := serve(t, c, encoding, jsonHandler(body))
+
+		if got := rec.Header().Get("Content-Encoding"); got != encoding {
+			t.Fatalf("expected %s encoding, got %q", encoding, got)
+		}
+		if rec.Header().Get("Content-Length") != "" {
+			t.Error("expected Content-Length to be dropped")
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("expected Vary: Accept-Encoding, got %q", rec.Header().Get("Vary"))
+		}
+		if rec.Body.Len() >= len(body) {
+			t.Errorf("expected a smaller body, got %d bytes for %d", rec.Body.Len(), len(body))
+		}
+		if got := decode(t, encoding, rec.Body.Bytes()); got != body {
+			t.Errorf("%s body did not round trip", encoding)
+		}
+	}
+}
+
+func TestMiddleware_PassesThrough(t *testing.T) {
+	c := newTestCompressor(t)
+	large := strings.Repeat("a", 4096)
+
+	tests := []struct {
+		name     string
+		encoding string
+		handler  http.HandlerFunc
+	}{
+		{"client without gzip", "br", jsonHandler(large)},
+		{"small body", "gzip", jsonHandler(`{"ok":true}`)},
+		{"content type not allowed", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte(large))
+		}},
+		{"already encoded", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = w.Write([]byte(large))
+		}},
+		{"no content", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNoContent)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytes.Buffer
+			expected := httptest.NewRecorder()
+			tt.handler(expected, httptest.NewRequest(http.MethodGet, "/", nil))
+			want.Write(expected.Body.Bytes())
+
+			rec := serve(t, c, tt.encoding, tt.handler)
+			if rec.Code != expected.Code {
+				t.Errorf("expected status %d, got %d", expected.Code, rec.Code)
+			}
+			if rec.Header().Get("Content-Encoding") != expected.Header().Get("Content-Encoding") {
+				t.Errorf("expected encoding %q, got %q", expected.Header().Get("Content-Encoding"), rec.Header().Get("Content-Encoding"))
+			}
+			if !bytes.Equal(rec.Body.Bytes(), want.Bytes()) {
+				t.Error("expected the body unchanged")
+			}
+		})
+	}
+}
+
+func TestMiddleware_KeepsStatusAndWeakensETag(t *testing.T) {
+	c := newTestCompressor(t)
+	rec := serve(t, c, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(strings.Repeat("id,amount\n", 200)))
+	})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") != `W/"v1"` {
+		t.Errorf("expected a weak ETag, got %q", rec.Header().Get("ETag"))
+	}
+}
+
+func TestMiddleware_FlushSendsStreamsUncompressed(t *testing.T) {
+	c := newTestCompressor(t)
+	rec := serve(t, c, "gzip", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(strings.Repeat("x", 2048)))
+	})
+
+	if !rec.Flushed {
+		t.Error("expected the flush to reach the client")
+	}
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Error("expected a response flushed below the threshold to stay uncompressed")
+	}
+	if !strings.HasPrefix(rec.Body.String(), "data: first") || rec.Body.Len() != 13+2048 {
+		t.Errorf("unexpected body of %d bytes", rec.Body.Len())
+	}
+}
+
+func TestMiddleware_SkipsUpgradesAndHead(t *testing.T) {
+	c := newTestCompressor(t)
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req := httptest.NewRequest(method, "/api/v1/ws", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		if method == http.MethodGet {
+			req.Header.Set("Upgrade", "websocket")
+		}
+
+		var wrapped bool
+		rec := httptest.NewRecorder()
+		c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, wrapped = w.(*compressWriter)
+		})).ServeHTTP(rec, req)
+		if wrapped {
+			t.Errorf("%s: expected the writer not to be wrapped", method)
+		}
+	}
+}
+
+func TestNew_RejectsInvalidConfig(t *testing.T) {
+	if _, err := New(Config{Level: 12}); err == nil {
+		t.Error("expected an invalid level to be rejected")
+	}
+	if _, err := New(Config{Level: 5, MinBytes: -1}); err == nil {
+		t.Error("expected a negative minimum size to be rejected")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("GATEWAY_COMPRESSION_LEVEL", "9")
+	t.Setenv("GATEWAY_COMPRESSION_MIN_BYTES", "256")
+	t.Setenv("GATEWAY_COMPRESSION_TYPES", "application/json, text/csv")
+
+	cfg := ConfigFromEnv()
+	if cfg.Level != 9 || cfg.MinBytes != 256 || len(cfg.ContentTypes) != 2 || cfg.ContentTypes[1] != "text/csv" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("GATEWAY_COMPRESSION_LEVEL", "42")
+	if cfg := ConfigFromEnv(); cfg.Level != DefaultConfig().Level {
+		t.Errorf("expected an invalid level to fall back to the default, got %d", cfg.Level)
+	}
+}
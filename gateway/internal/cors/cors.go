@@ -0,0 +1,170 @@
+// Package cors builds the gateway's CORS policy from the environment, so
+// browser dashboards on other origins can call the API. Allowed origins can
+// be set per environment, letting one configuration file serve development,
+// staging and production; the effective policy is served to operators by the
+// admin API.
+package cors
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+const (
+	// DefaultMaxAge is how long browsers may cache a preflight response.
+	DefaultMaxAge = 3600
+	// MaxMaxAge bounds the preflight cache; browsers cap it lower anyway.
+	MaxMaxAge = 86400
+
+	// Origin sources reported in the effective policy.
+	SourceEnvironment = "environment" // CORS_ORIGINS_<ENVIRONMENT>
+	SourceGlobal      = "global"      // CORS_ORIGINS
+	SourceDefault     = "default"     // Built-in defaults for the environment
+)
+
+// developmentOrigins are allowed outside production when no origins are
+// configured: the local frontends.
+var developmentOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:3001",
+	"http://localhost:3002",
+	"http://127.0.0.1:3000",
+	"http://127.0.0.1:3001",
+	"http://127.0.0.1:3002",
+}
+
+// Policy is the gateway's effective CORS policy.
+type Policy struct {
+	Environment   string
+	OriginsSource string // SourceEnvironment, SourceGlobal or SourceDefault
+	Config        middleware.CORSConfig
+}
+
+// PolicyView is the JSON form of a Policy served by the admin API.
+type PolicyView struct {
+	Environment      string   `json:"environment"`
+	OriginsSource    string   `json:"origins_source"`
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAgeSeconds    int      `json:"max_age_seconds"`
+}
+
+// View returns the policy as served by the admin API.
+func (p Policy) View() PolicyView {
+	return PolicyView{
+		Environment:      p.Environment,
+		OriginsSource:    p.OriginsSource,
+		AllowedOrigins:   nonNil(p.Config.AllowedOrigins),
+		AllowedMethods:   nonNil(p.Config.AllowedMethods),
+		AllowedHeaders:   nonNil(p.Config.AllowedHeaders),
+		ExposedHeaders:   nonNil(p.Config.ExposedHeaders),
+		AllowCredentials: p.Config.AllowCredentials,
+		MaxAgeSeconds:    p.Config.MaxAge,
+	}
+}
+
+// EnvName returns the variable holding the allowed origins of an
+// environment, e.g. CORS_ORIGINS_PRODUCTION for "production".
+func EnvName(environment string) string {
+	return "CORS_ORIGINS_" + strings.ToUpper(strings.ReplaceAll(environment, "-", "_"))
+}
+
+// PolicyFromEnv builds the policy for the given environment. Allowed origins
+// come from CORS_ORIGINS_<ENVIRONMENT>, then CORS_ORIGINS, then the local
+// frontends outside production, which otherwise allows none.
+// CORS_ALLOW_CREDENTIALS (default true) and CORS_MAX_AGE_SECONDS (default
+// 3600) tune the rest. A wildcard origin is rejected in production and
+// whenever credentials are allowed.
+func PolicyFromEnv(environment string) (Policy, error) {
+	policy := Policy{
+		Environment: environment,
+		Config:      middleware.DefaultCORSConfig(),
+	}
+
+	if origins := os.Getenv(EnvName(environment)); strings.TrimSpace(origins) != "" {
+		policy.Config.AllowedOrigins = splitAndTrim(origins)
+		policy.OriginsSource = SourceEnvironment
+	} else if origins := os.Getenv("CORS_ORIGINS"); strings.TrimSpace(origins) != "" {
+		policy.Config.AllowedOrigins = splitAndTrim(origins)
+		policy.OriginsSource = SourceGlobal
+	} else {
+		policy.OriginsSource = SourceDefault
+		if !isProduction(environment) {
+			policy.Config.AllowedOrigins = append([]string(nil), developmentOrigins...)
+		}
+	}
+
+	// Authenticated dashboards send cookies and the Authorization header
+	policy.Config.AllowCredentials = true
+	if raw := os.Getenv("CORS_ALLOW_CREDENTIALS"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return policy, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS %q: %w", raw, err)
+		}
+		policy.Config.AllowCredentials = v
+	}
+
+	policy.Config.MaxAge = DefaultMaxAge
+	if raw := os.Getenv("CORS_MAX_AGE_SECONDS"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 || v > MaxMaxAge {
+			return policy, fmt.Errorf("invalid CORS_MAX_AGE_SECONDS %q: must be 0 to %d", raw, MaxMaxAge)
+		}
+		policy.Config.MaxAge = v
+	}
+
+	return policy, policy.Validate()
+}
+
+// Validate rejects policies that would let any site act for a signed-in user.
+func (p Policy) Validate() error {
+	for _, origin := range p.Config.AllowedOrigins {
+		if origin != "*" {
+			if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				return fmt.Errorf("invalid CORS origin %q: must start with http:// or https://", origin)
+			}
+			continue
+		}
+		if isProduction(p.Environment) {
+			return fmt.Errorf("wildcard CORS origin is not allowed in production")
+		}
+		if p.Config.AllowCredentials {
+			return fmt.Errorf("wildcard CORS origin cannot be combined with credentials")
+		}
+		if len(p.Config.AllowedOrigins) > 1 {
+			return fmt.Errorf("wildcard CORS origin must be the only origin")
+		}
+	}
+	return nil
+}
+
+// isProduction reports whether environment names production.
+func isProduction(environment string) bool {
+	return environment == "production" || environment == "prod"
+}
+
+// splitAndTrim splits a comma-separated list, dropping empty entries.
+func splitAndTrim(s string) []string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(s, ",") {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			parts = append(parts, trimmed)
+		}
+	}
+	return parts
+}
+
+// nonNil keeps empty lists as [] rather than null in JSON.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package cors
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicyFromEnv_Origins(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		env         map[string]string
+		wantOrigins []string
+		wantSource  string
+	}{
+		{
+			name:        "development defaults to local frontends",
+			environment: "development",
+			wantOrigins: developmentOrigins,
+			wantSource:  SourceDefault,
+		},
+		{
+			name:        "production defaults to none",
+			environment: "production",
+			wantOrigins: []string{},
+			wantSource:  SourceDefault,
+		},
+		{
+			name:        "global origins",
+			environment: "production",
+			env:         map[string]string{"CORS_ORIGINS": "https://app.nivo.money, https://admin.nivo.money"},
+			wantOrigins: []string{"https://app.nivo.money", "https://admin.nivo.money"},
+			wantSource:  SourceGlobal,
+		},
+		{
+			name:        "environment origins take precedence",
+			environment: "staging",
+			env: map[string]string{
+				"CORS_ORIGINS":         "https://app.nivo.money",
+				"CORS_ORIGINS_STAGING": "https://*.staging.nivo.money",
+			},
+			wantOrigins: []string{"https://*.staging.nivo.money"},
+			wantSource:  SourceEnvironment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CORS_ORIGINS", "CORS_ORIGINS_STAGING", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE_SECONDS"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			policy, err := PolicyFromEnv(tt.environment)
+			if err != nil {
+				t.Fatalf("expected valid policy, got %v", err)
+			}
+			if !reflect.DeepEqual(policy.View().AllowedOrigins, tt.wantOrigins) {
+				t.Errorf("expected origins %v, got %v", tt.wantOrigins, policy.Config.AllowedOrigins)
+			}
+			if policy.OriginsSource != tt.wantSource {
+				t.Errorf("expected source %q, got %q", tt.wantSource, policy.OriginsSource)
+			}
+			if !policy.Config.AllowCredentials || policy.Config.MaxAge != DefaultMaxAge {
+				t.Errorf("expected credentials and default max age, got %v and %d", policy.Config.AllowCredentials, policy.Config.MaxAge)
+			}
+		})
+	}
+}
+
+func TestPolicyFromEnv_Tuning(t *testing.T) {
+	t.Setenv("CORS_ORIGINS", "")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "600")
+
+	policy, err := PolicyFromEnv("development")
+	if err != nil {
+		t.Fatalf("expected valid policy, got %v", err)
+	}
+	if policy.Config.AllowCredentials {
+		t.Error("expected credentials to be disabled")
+	}
+	if policy.Config.MaxAge != 600 {
+		t.Errorf("expected max age 600, got %d", policy.Config.MaxAge)
+	}
+}
+
+func TestPolicyFromEnv_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		environment string
+		env         map[string]string
+	}{
+		{"bad credentials flag", "development", map[string]string{"CORS_ALLOW_CREDENTIALS": "maybe"}},
+		{"negative max age", "development", map[string]string{"CORS_MAX_AGE_SECONDS": "-1"}},
+		{"max age too long", "development", map[string]string{"CORS_MAX_AGE_SECONDS": "100000"}},
+		{"origin without scheme", "development", map[string]string{"CORS_ORIGINS": "app.nivo.money"}},
+		{"wildcard in production", "production", map[string]string{"CORS_ORIGINS": "*", "CORS_ALLOW_CREDENTIALS": "false"}},
+		{"wildcard with credentials", "development", map[string]string{"CORS_ORIGINS": "*"}},
+		{"wildcard among origins", "development", map[string]string{"CORS_ORIGINS": "*,https://app.nivo.money", "CORS_ALLOW_CREDENTIALS": "false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"CORS_ORIGINS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE_SECONDS"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if _, err := PolicyFromEnv(tt.environment); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestPolicyFromEnv_WildcardWithoutCredentials(t *testing.T) {
+	t.Setenv("CORS_ORIGINS", "*")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "")
+
+	if _, err := PolicyFromEnv("development"); err != nil {
+		t.Errorf("expected wildcard without credentials to be allowed outside production, got %v", err)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("staging-eu"); got != "CORS_ORIGINS_STAGING_EU" {
+		t.Errorf("expected CORS_ORIGINS_STAGING_EU, got %s", got)
+	}
+}
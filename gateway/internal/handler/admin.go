@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/cors"
+	"github.com/1mb-dev/nivomoney/gateway/internal/maintenance"
+	"github.com/1mb-dev/nivomoney/gateway/internal/proxy"
+	"github.com/1mb-dev/nivomoney/gateway/internal/tenant"
+	"github.com/1mb-dev/nivomoney/gateway/internal/traffic"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	sharedMiddleware "github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// AdminHandler lets operators inspect and adjust the gateway's routing at
+// runtime: list routes, put routes in maintenance, change rate limits and
+// watch per-route traffic.
+type AdminHandler struct {
+	registry    *proxy.ServiceRegistry
+	maintenance *maintenance.Store
+	traffic     *traffic.Counters
+	limiters    map[string]*sharedMiddleware.RateLimiter
+	corsPolicy  cors.Policy
+	logger      *logger.Logger
+}
+
+// NewAdminHandler creates a new admin handler. limiters holds the rate
+// limiter of each tenant, keyed by tenant name.
+func NewAdminHandler(registry *proxy.ServiceRegistry, store *maintenance.Store, counters *traffic.Counters, limiters map[string]*sharedMiddleware.RateLimiter, log *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		registry:    registry,
+		maintenance: store,
+		traffic:     counters,
+		limiters:    limiters,
+		logger:      log,
+	}
+}
+
+// SetCORSPolicy sets the CORS policy served by HandleCORSPolicy.
+func (h *AdminHandler) SetCORSPolicy(policy cors.Policy) {
+	h.corsPolicy = policy
+}
+
+// DisableRouteRequest is the payload for putting a route in maintenance.
+type DisableRouteRequest struct {
+	Route             string `json:"route"`
+	Method            string `json:"method,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"`
+	DurationSeconds   int    `json:"duration_seconds,omitempty"` // Zero stays disabled until enabled
+}
+
+// RateLimit is a tenant's rate limit as shown and set through the admin API.
+type RateLimit struct {
+	Tenant            string `json:"tenant"`
+	RequestsPerMinute int    `json:"requests_per_minute"`
+	BurstSize         int    `json:"burst_size"`
+}
+
+// HandleRoutes handles GET /internal/v1/admin/routes, listing the routes the
+// gateway proxies, the instances behind each service and the routes in
+// maintenance.
+func (h *AdminHandler) HandleRoutes(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, map[string]interface{}{
+		"routes":      proxy.Routes(),
+		"services":    h.registry.Services(),
+		"maintenance": h.maintenance.Windows(),
+	})
+}
+
+// HandleListMaintenance handles GET /internal/v1/admin/maintenance.
+func (h *AdminHandler) HandleListMaintenance(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.maintenance.Windows())
+}
+
+// HandleDisableRoute handles PUT /internal/v1/admin/maintenance, answering
+// requests to the route with 503 and Retry-After until it is enabled again or
+// the window expires.
+func (h *AdminHandler) HandleDisableRoute(w http.ResponseWriter, r *http.Request) {
+	var req DisableRouteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if req.Route == "" {
+		response.Error(w, errors.BadRequest("route is required"))
+		return
+	}
+
+	window, err := h.maintenance.Disable(req.Route, req.Method, req.Reason,
+		time.Duration(req.RetryAfterSeconds)*time.Second, time.Duration(req.DurationSeconds)*time.Second)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	h.logger.WithField("route", window.Route).
+		WithField("method", window.Method).
+		WithField("reason", window.Reason).
+		WithField("expires_at", window.ExpiresAt).
+		Warn("Route disabled for maintenance")
+
+	response.OK(w, window)
+}
+
+// HandleEnableRoute handles DELETE /internal/v1/admin/maintenance?route=/api/v1/wallets/*&method=POST,
+// putting the route back in service.
+func (h *AdminHandler) HandleEnableRoute(w http.ResponseWriter, r *http.Request) {
+	route := r.URL.Query().Get("route")
+	method := r.URL.Query().Get("method")
+	if route == "" {
+		response.Error(w, errors.BadRequest("route query parameter is required"))
+		return
+	}
+
+	if !h.maintenance.Enable(route, method) {
+		response.Error(w, errors.NotFound("route "+route+" is not in maintenance"))
+		return
+	}
+
+	h.logger.WithField("route", route).WithField("method", method).Info("Route enabled after maintenance")
+	response.NoContent(w)
+}
+
+// HandleGetRateLimits handles GET /internal/v1/admin/rate-limits, listing each
+// tenant's rate limit.
+func (h *AdminHandler) HandleGetRateLimits(w http.ResponseWriter, r *http.Request) {
+	limits := make([]RateLimit, 0, len(h.limiters))
+	for name, limiter := range h.limiters {
+		limits = append(limits, rateLimitOf(name, limiter.Config()))
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Tenant < limits[j].Tenant })
+	response.OK(w, limits)
+}
+
+// HandleSetRateLimit handles PUT /internal/v1/admin/rate-limits, replacing a
+// tenant's rate limit. The tenant defaults to the default tenant.
+func (h *AdminHandler) HandleSetRateLimit(w http.ResponseWriter, r *http.Request) {
+	var req RateLimit
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if req.Tenant == "" {
+		req.Tenant = tenant.Default
+	}
+	if req.RequestsPerMinute <= 0 || req.BurstSize <= 0 {
+		response.Error(w, errors.Validation("requests_per_minute and burst_size must be positive"))
+		return
+	}
+
+	limiter, ok := h.limiters[req.Tenant]
+	if !ok {
+		response.Error(w, errors.NotFound("unknown tenant: "+req.Tenant))
+		return
+	}
+
+	config := limiter.Config()
+	previous := config.RequestsPerMinute
+	config.RequestsPerMinute = req.RequestsPerMinute
+	config.BurstSize = req.BurstSize
+	limiter.SetConfig(config)
+
+	h.logger.WithField("tenant", req.Tenant).
+		WithField("previous_per_minute", previous).
+		WithField("requests_per_minute", config.RequestsPerMinute).
+		WithField("burst_size", config.BurstSize).
+		Warn("Rate limit changed")
+
+	response.OK(w, rateLimitOf(req.Tenant, config))
+}
+
+// HandleTraffic handles GET /internal/v1/admin/traffic, showing each route's
+// live counters, busiest first.
+func (h *AdminHandler) HandleTraffic(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, map[string]interface{}{
+		"since":  h.traffic.Since(),
+		"routes": h.traffic.Snapshot(),
+	})
+}
+
+// HandleResetTraffic handles DELETE /internal/v1/admin/traffic, starting the
+// counters over.
+func (h *AdminHandler) HandleResetTraffic(w http.ResponseWriter, r *http.Request) {
+	h.traffic.Reset()
+	h.logger.Info("Traffic counters reset")
+	response.NoContent(w)
+}
+
+// HandleCORSPolicy handles GET /internal/v1/admin/cors, showing the CORS
+// policy in effect, so operators can see why a browser origin is refused.
+func (h *AdminHandler) HandleCORSPolicy(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.corsPolicy.View())
+}
+
+// rateLimitOf describes a tenant's rate limit configuration.
+func rateLimitOf(tenantName string, config sharedMiddleware.RateLimitConfig) RateLimit {
+	return RateLimit{
+		Tenant:            tenantName,
+		RequestsPerMinute: config.RequestsPerMinute,
+		BurstSize:         config.BurstSize,
+	}
+}
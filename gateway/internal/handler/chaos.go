@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/chaos"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ChaosHandler exposes the gateway's fault injection controls to the
+// simulation service. Routes are only registered outside production.
+type ChaosHandler struct {
+	injector *chaos.Injector
+	logger   *logger.Logger
+}
+
+// NewChaosHandler creates a new chaos handler.
+func NewChaosHandler(injector *chaos.Injector, log *logger.Logger) *ChaosHandler {
+	return &ChaosHandler{
+		injector: injector,
+		logger:   log,
+	}
+}
+
+// SetChaosRequest is the payload for enabling chaos.
+type SetChaosRequest struct {
+	Rules           []chaos.Rule `json:"rules"`
+	SSEDelayMs      int          `json:"sse_delay_ms,omitempty"`
+	DurationSeconds int          `json:"duration_seconds,omitempty"`
+}
+
+// chaosStatus is the response body describing the active configuration.
+type chaosStatus struct {
+	Active bool          `json:"active"`
+	Config *chaos.Config `json:"config,omitempty"`
+}
+
+// HandleGet handles GET /internal/v1/chaos.
+func (h *ChaosHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	cfg := h.injector.Active()
+	response.OK(w, chaosStatus{Active: cfg != nil, Config: cfg})
+}
+
+// HandleSet handles PUT /internal/v1/chaos, replacing the active configuration.
+func (h *ChaosHandler) HandleSet(w http.ResponseWriter, r *http.Request) {
+	var req SetChaosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if len(req.Rules) == 0 && req.SSEDelayMs == 0 {
+		response.Error(w, errors.BadRequest("at least one rule or sse_delay_ms is required"))
+		return
+	}
+
+	cfg, err := h.injector.Set(req.Rules, req.SSEDelayMs, time.Duration(req.DurationSeconds)*time.Second)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	h.logger.WithField("rules", len(cfg.Rules)).
+		WithField("sse_delay_ms", cfg.SSEDelayMs).
+		WithField("expires_at", cfg.ExpiresAt).
+		Warn("Chaos injection enabled")
+
+	response.OK(w, chaosStatus{Active: true, Config: cfg})
+}
+
+// HandleClear handles DELETE /internal/v1/chaos.
+func (h *ChaosHandler) HandleClear(w http.ResponseWriter, r *http.Request) {
+	h.injector.Clear()
+	h.logger.Info("Chaos injection cleared")
+	response.OK(w, chaosStatus{Active: false})
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// errorCatalog is the response body listing every documented error code.
+type errorCatalog struct {
+	Errors []errors.CatalogEntry `json:"errors"`
+}
+
+// HandleErrorCatalog handles GET /api/v1/errors, listing the error codes
+// clients can branch on along with the HTTP status each one maps to.
+func HandleErrorCatalog(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, errorCatalog{Errors: errors.Catalog()})
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+func TestHandleErrorCatalog(t *testing.T) {
+	rec := httptest.NewRecorder()
+	HandleErrorCatalog(rec, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Data errorCatalog `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	var found bool
+	for _, entry := range body.Data.Errors {
+		if entry.Code == errors.ErrCodeWalletInsufficientFunds {
+			found = entry.Status == http.StatusPreconditionFailed && entry.Domain == "wallet"
+		}
+	}
+	if !found {
+		t.Errorf("expected %s in catalog, got %+v", errors.ErrCodeWalletInsufficientFunds, body.Data.Errors)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/mock"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// MockHandler exposes the gateway's mock mode controls so routes can be
+// switched between mocked and proxied. Routes are only registered outside production.
+type MockHandler struct {
+	store  *mock.Store
+	logger *logger.Logger
+}
+
+// NewMockHandler creates a new mock handler.
+func NewMockHandler(store *mock.Store, log *logger.Logger) *MockHandler {
+	return &MockHandler{
+		store:  store,
+		logger: log,
+	}
+}
+
+// mockStatus is the response body describing mocked routes and available fixtures.
+type mockStatus struct {
+	Routes   []mock.Route `json:"routes"`
+	Fixtures []string     `json:"fixtures"`
+}
+
+func (h *MockHandler) status() mockStatus {
+	return mockStatus{Routes: h.store.Routes(), Fixtures: h.store.Fixtures()}
+}
+
+// HandleGet handles GET /internal/v1/mocks.
+func (h *MockHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.status())
+}
+
+// HandleEnable handles PUT /internal/v1/mocks/routes, mocking a route or
+// replacing the mock for the same method and path.
+func (h *MockHandler) HandleEnable(w http.ResponseWriter, r *http.Request) {
+	var route mock.Route
+	if err := json.NewDecoder(r.Body).Decode(&route); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	enabled, err := h.store.Enable(route)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	h.logger.WithField("route", enabled.Key()).
+		WithField("fixture", enabled.Fixture).
+		Info("Mock route enabled")
+
+	response.OK(w, h.status())
+}
+
+// HandleDisable handles DELETE /internal/v1/mocks/routes?method=GET&path=/api/v1/...,
+// sending the route back to its backend service.
+func (h *MockHandler) HandleDisable(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Query().Get("method")
+	path := r.URL.Query().Get("path")
+	if method == "" || path == "" {
+		response.Error(w, errors.BadRequest("method and path query parameters are required"))
+		return
+	}
+
+	if !h.store.Disable(method, path) {
+		response.Error(w, errors.NotFound("no mock route for "+method+" "+path))
+		return
+	}
+
+	h.logger.WithField("method", method).WithField("path", path).Info("Mock route disabled")
+	response.OK(w, h.status())
+}
+
+// HandleClear handles DELETE /internal/v1/mocks, disabling every mocked route.
+func (h *MockHandler) HandleClear(w http.ResponseWriter, r *http.Request) {
+	h.store.Clear()
+	h.logger.Info("Mock routes cleared")
+	response.OK(w, h.status())
+}
+
+// HandleReload handles POST /internal/v1/mocks/fixtures/reload, re-reading
+// fixture files after they were recorded or edited.
+func (h *MockHandler) HandleReload(w http.ResponseWriter, r *http.Request) {
+	count, err := h.store.LoadFixtures()
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to load fixtures: "+err.Error()))
+		return
+	}
+
+	h.logger.WithField("fixtures", count).Info("Mock fixtures reloaded")
+	response.OK(w, h.status())
+}
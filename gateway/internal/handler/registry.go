@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/proxy"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// RegistryHandler exposes the gateway's service registry so backend
+// instances can register and deregister themselves at runtime.
+type RegistryHandler struct {
+	registry *proxy.ServiceRegistry
+	logger   *logger.Logger
+}
+
+// NewRegistryHandler creates a new registry handler.
+func NewRegistryHandler(registry *proxy.ServiceRegistry, log *logger.Logger) *RegistryHandler {
+	return &RegistryHandler{
+		registry: registry,
+		logger:   log,
+	}
+}
+
+// RegisterInstanceRequest is the payload for registering a backend instance.
+type RegisterInstanceRequest struct {
+	Service string `json:"service"`
+	URL     string `json:"url"`
+	Variant string `json:"variant,omitempty"` // stable (default) or canary
+}
+
+// SetCanaryRequest is the payload for changing a service's traffic split.
+type SetCanaryRequest struct {
+	Service string `json:"service"`
+	Percent *int   `json:"percent"`
+}
+
+// HandleList handles GET /internal/v1/registry, listing every service's
+// instances with their health, and the canary split of services that have one.
+func (h *RegistryHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, map[string]interface{}{
+		"services": h.registry.Services(),
+		"canary":   h.registry.CanaryPercents(),
+	})
+}
+
+// HandleRegister handles POST /internal/v1/registry/instances. Registering a
+// known URL again is a no-op.
+func (h *RegistryHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	var req RegisterInstanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if req.Service == "" || req.URL == "" {
+		response.Error(w, errors.BadRequest("service and url are required"))
+		return
+	}
+
+	if req.Variant == "" {
+		req.Variant = proxy.VariantStable
+	}
+
+	inst, err := h.registry.RegisterVariant(req.Service, req.URL, req.Variant)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	h.logger.WithField("service", req.Service).
+		WithField("url", inst.URL).
+		WithField("variant", inst.Variant).
+		Info("Backend instance registered")
+
+	response.OK(w, inst)
+}
+
+// HandleDeregister handles DELETE /internal/v1/registry/instances?service=wallet&url=http://...,
+// taking the instance out of rotation.
+func (h *RegistryHandler) HandleDeregister(w http.ResponseWriter, r *http.Request) {
+	service := r.URL.Query().Get("service")
+	instanceURL := r.URL.Query().Get("url")
+	if service == "" || instanceURL == "" {
+		response.Error(w, errors.BadRequest("service and url query parameters are required"))
+		return
+	}
+
+	if !h.registry.Deregister(service, instanceURL) {
+		response.Error(w, errors.NotFound("no "+service+" instance at "+instanceURL))
+		return
+	}
+
+	h.logger.WithField("service", service).WithField("url", instanceURL).Info("Backend instance deregistered")
+	response.NoContent(w)
+}
+
+// HandleSetCanary handles PUT /internal/v1/registry/canary, setting the
+// percentage of a service's traffic sent to its canary instances. Ramp a
+// rollout up in steps, and set 0 to send only X-Canary requests there.
+func (h *RegistryHandler) HandleSetCanary(w http.ResponseWriter, r *http.Request) {
+	var req SetCanaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if req.Service == "" || req.Percent == nil {
+		response.Error(w, errors.BadRequest("service and percent are required"))
+		return
+	}
+
+	if err := h.registry.SetCanaryPercent(req.Service, *req.Percent); err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	h.logger.WithField("service", req.Service).
+		WithField("percent", *req.Percent).
+		Info("Canary traffic split updated")
+
+	response.OK(w, map[string]interface{}{
+		"service": req.Service,
+		"percent": *req.Percent,
+	})
+}
@@ -4,16 +4,23 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/1mb-dev/nivomoney/gateway/internal/chaos"
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+	"github.com/1mb-dev/nivomoney/shared/response"
 )
 
 // SSEHandler handles Server-Sent Events connections.
 type SSEHandler struct {
 	broker *events.Broker
 	logger *logger.Logger
+	chaos  *chaos.Injector
 }
 
 // NewSSEHandler creates a new SSE handler.
@@ -24,36 +31,110 @@ func NewSSEHandler(broker *events.Broker, log *logger.Logger) *SSEHandler {
 	}
 }
 
+// SetChaos enables delayed event delivery when chaos is active (non-production only).
+func (h *SSEHandler) SetChaos(injector *chaos.Injector) {
+	h.chaos = injector
+}
+
 // HandleEvents handles SSE connections from clients.
 func (h *SSEHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
-	// Set SSE headers
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
+	// Get topics from query parameters (comma-separated)
+	topics := r.URL.Query().Get("topics")
+	if topics == "" {
+		topics = "all" // Subscribe to all topics by default
+	}
+
+	client := events.NewClient(newClientID(r))
+	for _, topic := range strings.Split(topics, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			client.Subscribe(topic)
+		}
+	}
+
+	h.stream(w, r, client, map[string]interface{}{
+		"client_id": client.ID,
+		"topics":    topics,
+		"message":   "Connected to Nivo event stream",
+	})
+}
+
+// HandleWalletStream handles GET /api/v1/stream/wallets. It streams balance
+// updates and transaction status changes for the authenticated user's wallets
+// only; the broker drops events owned by other users.
+func (h *SSEHandler) HandleWalletStream(w http.ResponseWriter, r *http.Request) {
+	h.userStream(w, r, walletStreamTopics, "Connected to wallet stream")
+}
+
+// HandleNotificationStream handles GET /api/v1/stream/notifications. It
+// streams the authenticated user's in-app notifications as they are delivered.
+func (h *SSEHandler) HandleNotificationStream(w http.ResponseWriter, r *http.Request) {
+	h.userStream(w, r, notificationStreamTopics, "Connected to notification stream")
+}
+
+// userStream streams events on topics that belong to the authenticated user.
+func (h *SSEHandler) userStream(w http.ResponseWriter, r *http.Request, topics []string, message string) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	if userID == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	client := events.NewUserClient(newClientID(r), userID)
+	for _, topic := range topics {
+		client.Subscribe(topic)
+	}
+
+	h.stream(w, r, client, map[string]interface{}{
+		"client_id": client.ID,
+		"topics":    strings.Join(topics, ","),
+		"message":   message,
+	})
+}
+
+// walletStreamTopics are the topics a wallet stream subscribes to.
+var walletStreamTopics = []string{"wallets", "transactions"}
+
+// notificationStreamTopics are the topics a notification stream subscribes to.
+var notificationStreamTopics = []string{"notifications"}
 
-	// Get request ID for logging
-	requestID := r.Header.Get("X-Request-ID")
+// newClientID derives a unique client ID from the request ID.
+func newClientID(r *http.Request) string {
+	requestID := requestid.FromRequest(r)
 	if requestID == "" {
 		requestID = "unknown"
 	}
+	return fmt.Sprintf("%s-%d", requestID, time.Now().UnixNano())
+}
 
-	// Create a new client
-	clientID := fmt.Sprintf("%s-%d", requestID, time.Now().UnixNano())
-	client := events.NewClient(clientID)
-
-	// Get topics from query parameters (comma-separated)
-	topics := r.URL.Query().Get("topics")
-	if topics == "" {
-		topics = "all" // Subscribe to all topics by default
+// lastEventID returns the ID of the last event a reconnecting client saw,
+// from the Last-Event-ID header browsers send or the last_event_id query
+// parameter.
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
 	}
+	return r.URL.Query().Get("last_event_id")
+}
 
-	// Subscribe to requested topics
-	client.Subscribe(topics)
+// stream registers a client with the broker and writes its events to the
+// response until the client disconnects.
+//
+// A client resuming with a last event ID first receives the retained events
+// after it, then a stream.gap event if some were already discarded. When the
+// client falls behind and the broker drops events for it, it is sent a
+// dropped event and disconnected, so it can resume from its last ID.
+func (h *SSEHandler) stream(w http.ResponseWriter, r *http.Request, client *events.Client, connected map[string]interface{}) {
+	// Set SSE headers
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	h.logger.WithField("client_id", clientID).
-		WithField("topics", topics).
-		Info("SSE client connected")
+	clientLog := h.logger.WithField("client_id", client.ID).WithField("topics", connected["topics"])
+	if client.UserID != "" {
+		clientLog = clientLog.WithField("user_id", client.UserID)
+	}
+	clientLog.Info("SSE client connected")
 
 	// Register client with broker
 	h.broker.Register(client)
@@ -61,7 +142,7 @@ func (h *SSEHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 	// Make sure we unregister when done
 	defer func() {
 		h.broker.Unregister(client)
-		h.logger.WithField("client_id", clientID).Info("SSE client disconnected")
+		h.logger.WithField("client_id", client.ID).Info("SSE client disconnected")
 	}()
 
 	// Get the flusher interface
@@ -73,15 +154,36 @@ func (h *SSEHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 
 	// Send initial connection message
 	initialEvent := events.Event{
-		Type: "connected",
-		Data: map[string]interface{}{
-			"client_id": clientID,
-			"topics":    topics,
-			"message":   "Connected to Nivo event stream",
-		},
+		Type:      "connected",
+		Data:      connected,
 		Timestamp: time.Now(),
 	}
 	_, _ = fmt.Fprint(w, events.FormatSSE(initialEvent))
+
+	// Replay what a resuming client missed. The client is already
+	// registered, so live events it was also sent are skipped below.
+	var lastSent string
+	if resumeFrom := lastEventID(r); resumeFrom != "" {
+		replayed, complete := h.broker.Replay(client, resumeFrom)
+		if !complete {
+			gap := events.Event{
+				Type: "stream.gap",
+				Data: map[string]interface{}{
+					"last_event_id": resumeFrom,
+					"message":       "Some events after last_event_id are no longer retained",
+				},
+				Timestamp: time.Now(),
+			}
+			_, _ = fmt.Fprint(w, events.FormatSSE(gap))
+		}
+		for _, event := range replayed {
+			_, _ = fmt.Fprint(w, events.FormatSSE(event))
+			lastSent = event.ID
+		}
+		clientLog.WithField("last_event_id", resumeFrom).
+			WithField("replayed", len(replayed)).
+			Info("SSE client resumed")
+	}
 	flusher.Flush()
 
 	// Send periodic heartbeat
@@ -96,9 +198,47 @@ func (h *SSEHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 			// Client disconnected
 			return
 
-		case event := <-client.Channel:
+		case event, ok := <-client.Channel:
+			if !ok {
+				// Broker stopped
+				return
+			}
+			if lastSent != "" && !events.EventIDAfter(event.ID, lastSent) {
+				// Already replayed
+				continue
+			}
+
+			// Delay delivery while chaos is active
+			if h.chaos != nil {
+				if delay := h.chaos.SSEDelay(); delay > 0 {
+					select {
+					case <-ctx.Done():
+						return
+					case <-time.After(delay):
+					}
+				}
+			}
+
 			// Send event to client
 			_, _ = fmt.Fprint(w, events.FormatSSE(event))
+
+			// Events were dropped because the client fell behind; tell it and
+			// close the stream so it resumes from this event
+			if dropped := client.TakeDropped(); dropped > 0 {
+				notice := events.Event{
+					Type: "dropped",
+					Data: map[string]interface{}{
+						"count":         dropped,
+						"last_event_id": event.ID,
+						"message":       "Events were dropped; reconnect with Last-Event-ID to resume",
+					},
+					Timestamp: time.Now(),
+				}
+				_, _ = fmt.Fprint(w, events.FormatSSE(notice))
+				flusher.Flush()
+				clientLog.WithField("dropped", dropped).Warn("SSE client fell behind, closing stream")
+				return
+			}
 			flusher.Flush()
 
 		case <-ticker.C:
@@ -118,9 +258,10 @@ func (h *SSEHandler) HandleEvents(w http.ResponseWriter, r *http.Request) {
 
 // BroadcastRequest represents the request payload for broadcasting events.
 type BroadcastRequest struct {
-	Topic string                 `json:"topic"`
-	Type  string                 `json:"type"`
-	Data  map[string]interface{} `json:"data"`
+	Topic   string                 `json:"topic"`
+	Type    string                 `json:"type"`
+	Data    map[string]interface{} `json:"data"`
+	UserIDs []string               `json:"user_ids,omitempty"` // Owners, for per-user streams
 }
 
 // HandleBroadcast handles broadcast requests from services.
@@ -168,7 +309,7 @@ func (h *SSEHandler) HandleBroadcast(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Broadcast the event
-	h.broker.Broadcast(req.Topic, req.Type, req.Data)
+	h.broker.BroadcastToUsers(req.Topic, req.Type, req.Data, req.UserIDs)
 
 	h.logger.WithField("topic", req.Topic).
 		WithField("type", req.Type).
@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
 )
@@ -177,6 +179,149 @@ func TestSSEHandler_HandleEvents(t *testing.T) {
 	})
 }
 
+func TestSSEHandler_HandleEventsTopicsAndResume(t *testing.T) {
+	// runEvents streams /sse/events with the given query and headers while
+	// broadcast runs, and returns the response body.
+	runEvents := func(handler *SSEHandler, query string, header http.Header, broadcast func()) string {
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/sse/events"+query, nil).WithContext(ctx)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := &mockFlusherRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.HandleEvents(rec, req)
+		}()
+		time.Sleep(50 * time.Millisecond)
+		broadcast()
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		<-done
+		return rec.Body.String()
+	}
+
+	t.Run("subscribes to each comma-separated topic", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		body := runEvents(handler, "?topics=wallets,users", nil, func() {
+			handler.broker.Broadcast("wallets", "wallet.created", map[string]interface{}{"wallet_id": "w-1"})
+			handler.broker.Broadcast("users", "user.registered", map[string]interface{}{"user_id": "u-1"})
+			handler.broker.Broadcast("transactions", "transaction.completed", map[string]interface{}{"transaction_id": "tx-1"})
+		})
+
+		assert.Contains(t, body, "w-1")
+		assert.Contains(t, body, "u-1")
+		assert.NotContains(t, body, "tx-1")
+	})
+
+	t.Run("replays events after Last-Event-ID", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		handler.broker.Broadcast("wallets", "wallet.created", map[string]interface{}{"wallet_id": "w-1"})
+		handler.broker.Broadcast("wallets", "wallet.created", map[string]interface{}{"wallet_id": "w-2"})
+
+		probe := events.NewClient("probe")
+		probe.Subscribe("all")
+		retained, _ := handler.broker.Replay(probe, "")
+		require.Len(t, retained, 2)
+
+		header := http.Header{}
+		header.Set("Last-Event-ID", retained[0].ID)
+		body := runEvents(handler, "?topics=wallets", header, func() {
+			handler.broker.Broadcast("wallets", "wallet.created", map[string]interface{}{"wallet_id": "w-3"})
+		})
+
+		assert.NotContains(t, body, "w-1")
+		assert.Contains(t, body, "id: "+retained[1].ID)
+		assert.Contains(t, body, "w-3")
+		assert.Equal(t, 1, strings.Count(body, `"w-2"`))
+		assert.NotContains(t, body, "stream.gap")
+	})
+}
+
+func TestSSEHandler_HandleWalletStream(t *testing.T) {
+	t.Run("requires an authenticated user", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stream/wallets", nil)
+		rec := &mockFlusherRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		handler.HandleWalletStream(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("streams only the user's own events", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, middleware.UserIDKey, "user-1")
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stream/wallets", nil).WithContext(ctx)
+		rec := &mockFlusherRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.HandleWalletStream(rec, req)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		handler.broker.Broadcast("wallets", "wallet.balance_updated", map[string]interface{}{"user_id": "user-2", "balance": 1})
+		handler.broker.BroadcastToUsers("transactions", "transaction.completed", map[string]interface{}{"transaction_id": "tx-1"}, []string{"user-1"})
+		handler.broker.Broadcast("users", "user.registered", map[string]interface{}{"user_id": "user-1"})
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		<-done
+
+		body := rec.Body.String()
+		assert.Contains(t, body, "event: connected")
+		assert.Contains(t, body, "tx-1")
+		assert.NotContains(t, body, "user-2")
+		assert.NotContains(t, body, "user.registered")
+	})
+}
+
+func TestSSEHandler_HandleNotificationStream(t *testing.T) {
+	t.Run("requires an authenticated user", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stream/notifications", nil)
+		rec := &mockFlusherRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		handler.HandleNotificationStream(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("streams only the user's own notifications", func(t *testing.T) {
+		handler := createTestSSEHandler()
+		ctx, cancel := context.WithCancel(context.Background())
+		ctx = context.WithValue(ctx, middleware.UserIDKey, "user-1")
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stream/notifications", nil).WithContext(ctx)
+		rec := &mockFlusherRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.HandleNotificationStream(rec, req)
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		handler.broker.BroadcastToUsers("notifications", "notification.in_app", map[string]interface{}{"notification_id": "n-1"}, []string{"user-1"})
+		handler.broker.BroadcastToUsers("notifications", "notification.in_app", map[string]interface{}{"notification_id": "n-2"}, []string{"user-2"})
+		handler.broker.BroadcastToUsers("wallets", "wallet.balance_updated", map[string]interface{}{"wallet_id": "w-1"}, []string{"user-1"})
+		time.Sleep(50 * time.Millisecond)
+
+		cancel()
+		<-done
+
+		body := rec.Body.String()
+		assert.Contains(t, body, "n-1")
+		assert.NotContains(t, body, "n-2")
+		assert.NotContains(t, body, "w-1")
+	})
+}
+
 // mockFlusherRecorder is a ResponseRecorder that implements http.Flusher.
 type mockFlusherRecorder struct {
 	*httptest.ResponseRecorder
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// RFC 8693 identifiers.
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+const (
+	// DefaultExchangedTokenTTL is the lifetime of an exchanged token when none is requested.
+	DefaultExchangedTokenTTL = 5 * time.Minute
+	// MaxExchangedTokenTTL caps the lifetime a caller may request.
+	MaxExchangedTokenTTL = 15 * time.Minute
+
+	exchangedTokenIssuer = "nivo-gateway"
+)
+
+// TokenExchangeResponse is the RFC 8693 token exchange response.
+type TokenExchangeResponse struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope"`
+}
+
+// tokenExchangeError is the OAuth 2.0 error response body.
+type tokenExchangeError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// TokenExchangeHandler exchanges a user access token for a short-lived,
+// scope-narrowed token suitable for embedded widgets and third parties.
+type TokenExchangeHandler struct {
+	jwtSecret string
+	logger    *logger.Logger
+	now       func() time.Time
+}
+
+// NewTokenExchangeHandler creates a new token exchange handler.
+func NewTokenExchangeHandler(jwtSecret string, log *logger.Logger) *TokenExchangeHandler {
+	return &TokenExchangeHandler{
+		jwtSecret: jwtSecret,
+		logger:    log,
+		now:       time.Now,
+	}
+}
+
+// HandleExchange handles POST /api/v1/auth/token/exchange.
+// Accepts form-encoded (per RFC 8693) or JSON parameters:
+// grant_type, subject_token, subject_token_type, scope, and optional expires_in seconds.
+func (h *TokenExchangeHandler) HandleExchange(w http.ResponseWriter, r *http.Request) {
+	params, err := parseExchangeParams(r)
+	if err != nil {
+		writeExchangeError(w, http.StatusBadRequest, "invalid_request", "malformed request body")
+		return
+	}
+
+	if params["grant_type"] != GrantTypeTokenExchange {
+		writeExchangeError(w, http.StatusBadRequest, "unsupported_grant_type", "grant_type must be "+GrantTypeTokenExchange)
+		return
+	}
+	if params["subject_token"] == "" {
+		writeExchangeError(w, http.StatusBadRequest, "invalid_request", "subject_token is required")
+		return
+	}
+	if tokenType := params["subject_token_type"]; tokenType != "" && tokenType != TokenTypeAccessToken {
+		writeExchangeError(w, http.StatusBadRequest, "invalid_request", "unsupported subject_token_type")
+		return
+	}
+
+	requested := strings.Fields(params["scope"])
+	if len(requested) == 0 {
+		writeExchangeError(w, http.StatusBadRequest, "invalid_scope", "scope is required")
+		return
+	}
+
+	subject, err := h.parseSubjectToken(params["subject_token"])
+	if err != nil {
+		writeExchangeError(w, http.StatusBadRequest, "invalid_grant", "subject_token is invalid or expired")
+		return
+	}
+
+	// Scopes can only be narrowed, never widened
+	granted := make(map[string]bool, len(subject.Permissions))
+	for _, perm := range subject.Permissions {
+		granted[perm] = true
+	}
+	scopes := make([]string, 0, len(requested))
+	seen := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		if !granted[scope] {
+			writeExchangeError(w, http.StatusBadRequest, "invalid_scope", "scope not held by subject token: "+scope)
+			return
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	ttl := DefaultExchangedTokenTTL
+	if raw := params["expires_in"]; raw != "" {
+		seconds, convErr := strconv.Atoi(raw)
+		if convErr != nil || seconds <= 0 {
+			writeExchangeError(w, http.StatusBadRequest, "invalid_request", "expires_in must be a positive integer")
+			return
+		}
+		ttl = time.Duration(seconds) * time.Second
+		if ttl > MaxExchangedTokenTTL {
+			ttl = MaxExchangedTokenTTL
+		}
+	}
+
+	// Never outlive the subject token
+	now := h.now()
+	expiresAt := now.Add(ttl)
+	if subject.ExpiresAt != nil && subject.ExpiresAt.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt.Time
+	}
+
+	scope := strings.Join(scopes, " ")
+	claims := &middleware.JWTClaims{
+		UserID:      subject.UserID,
+		Email:       subject.Email,
+		Status:      subject.Status,
+		AccountType: subject.AccountType,
+		Tier:        subject.Tier,
+		Permissions: scopes,
+		Scope:       scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   subject.UserID,
+			Issuer:    exchangedTokenIssuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.jwtSecret))
+	if err != nil {
+		h.logger.WithError(err).Error("failed to sign exchanged token")
+		writeExchangeError(w, http.StatusInternalServerError, "server_error", "failed to issue token")
+		return
+	}
+
+	h.logger.WithField("user_id", subject.UserID).
+		WithField("scope", scope).
+		WithField("jti", claims.ID).
+		Info("Issued scope-narrowed token")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(TokenExchangeResponse{
+		AccessToken:     signed,
+		IssuedTokenType: TokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(expiresAt.Sub(now).Seconds()),
+		Scope:           scope,
+	})
+}
+
+// parseSubjectToken validates the subject token with the gateway's signing key.
+func (h *TokenExchangeHandler) parseSubjectToken(tokenString string) (*middleware.JWTClaims, error) {
+	claims := &middleware.JWTClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(h.jwtSecret), nil
+	}, jwt.WithTimeFunc(h.now))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// parseExchangeParams reads exchange parameters from a form or JSON body.
+func parseExchangeParams(r *http.Request) (map[string]string, error) {
+	params := make(map[string]string)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		for key, value := range body {
+			switch v := value.(type) {
+			case string:
+				params[key] = v
+			case float64:
+				params[key] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		return params, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	for key := range r.PostForm {
+		params[key] = r.PostForm.Get(key)
+	}
+	return params, nil
+}
+
+// writeExchangeError writes an OAuth 2.0 error response.
+func writeExchangeError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(tokenExchangeError{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// ============================================================
+// Token Exchange Handler Tests
+// ============================================================
+
+const testExchangeSecret = "test-secret"
+
+func createTestSubjectToken(t *testing.T, permissions []string, expiresIn time.Duration) string {
+	t.Helper()
+	claims := &middleware.JWTClaims{
+		UserID:      "user-123",
+		Email:       "user@example.com",
+		Status:      "active",
+		Roles:       []string{"user"},
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testExchangeSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func doExchange(handler *TokenExchangeHandler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/token/exchange", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.HandleExchange(rec, req)
+	return rec
+}
+
+func TestTokenExchangeHandler_HandleExchange(t *testing.T) {
+	handler := NewTokenExchangeHandler(testExchangeSecret, logger.NewDefault("test"))
+	subject := createTestSubjectToken(t, []string{"wallet:wallet:read", "wallet:wallet:manage"}, time.Hour)
+
+	t.Run("issues narrowed token", func(t *testing.T) {
+		rec := doExchange(handler, url.Values{
+			"grant_type":         {GrantTypeTokenExchange},
+			"subject_token":      {subject},
+			"subject_token_type": {TokenTypeAccessToken},
+			"scope":              {"wallet:wallet:read"},
+		})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+		var resp TokenExchangeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "Bearer", resp.TokenType)
+		assert.Equal(t, TokenTypeAccessToken, resp.IssuedTokenType)
+		assert.Equal(t, "wallet:wallet:read", resp.Scope)
+		assert.LessOrEqual(t, resp.ExpiresIn, int64(DefaultExchangedTokenTTL.Seconds()))
+
+		// Issued token is accepted by the existing auth middleware with narrowed permissions
+		var gotPermissions []string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotPermissions, _ = r.Context().Value(middleware.UserPermissionsKey).([]string)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil)
+		req.Header.Set("Authorization", "Bearer "+resp.AccessToken)
+		authRec := httptest.NewRecorder()
+		middleware.NewJWTValidator(testExchangeSecret).Authenticate(next).ServeHTTP(authRec, req)
+
+		assert.Equal(t, http.StatusOK, authRec.Code)
+		assert.Equal(t, []string{"wallet:wallet:read"}, gotPermissions)
+	})
+
+	t.Run("rejects widened scope", func(t *testing.T) {
+		rec := doExchange(handler, url.Values{
+			"grant_type":    {GrantTypeTokenExchange},
+			"subject_token": {subject},
+			"scope":         {"wallet:wallet:read admin:users:manage"},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_scope")
+	})
+
+	t.Run("rejects wrong grant type", func(t *testing.T) {
+		rec := doExchange(handler, url.Values{
+			"grant_type":    {"password"},
+			"subject_token": {subject},
+			"scope":         {"wallet:wallet:read"},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unsupported_grant_type")
+	})
+
+	t.Run("rejects invalid subject token", func(t *testing.T) {
+		rec := doExchange(handler, url.Values{
+			"grant_type":    {GrantTypeTokenExchange},
+			"subject_token": {"not-a-token"},
+			"scope":         {"wallet:wallet:read"},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_grant")
+	})
+
+	t.Run("does not outlive subject token", func(t *testing.T) {
+		shortLived := createTestSubjectToken(t, []string{"wallet:wallet:read"}, time.Minute)
+		rec := doExchange(handler, url.Values{
+			"grant_type":    {GrantTypeTokenExchange},
+			"subject_token": {shortLived},
+			"scope":         {"wallet:wallet:read"},
+			"expires_in":    {"900"},
+		})
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp TokenExchangeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.LessOrEqual(t, resp.ExpiresIn, int64(60))
+	})
+}
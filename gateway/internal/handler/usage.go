@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/usage"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+const (
+	defaultUsageHours = 24
+	defaultTopUsers   = 20
+	maxTopUsers       = 100
+)
+
+// UsageHandler serves per-user API usage to support and operations staff.
+type UsageHandler struct {
+	recorder *usage.Recorder
+	logger   *logger.Logger
+}
+
+// NewUsageHandler creates a new usage handler.
+func NewUsageHandler(recorder *usage.Recorder, log *logger.Logger) *UsageHandler {
+	return &UsageHandler{recorder: recorder, logger: log}
+}
+
+// HandleUserUsage handles GET /api/v1/admin/usage?user_id=...&hours=24,
+// reporting a user's request and error counts and the endpoints they called.
+func (h *UsageHandler) HandleUserUsage(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user_id query parameter is required"))
+		return
+	}
+	hours, err := intParam(r, "hours", defaultUsageHours, usage.MaxHours)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	report, reportErr := h.recorder.Report(r.Context(), userID, hours)
+	if reportErr != nil {
+		h.logger.WithError(reportErr).WithField("user_id", userID).Error("Failed to read usage")
+		response.Error(w, errors.Unavailable("usage is unavailable"))
+		return
+	}
+	response.OK(w, report)
+}
+
+// HandleTopUsers handles GET /api/v1/admin/usage/top?by=errors&hours=1&limit=20,
+// listing the users with the most requests or errors, to spot abuse.
+func (h *UsageHandler) HandleTopUsers(w http.ResponseWriter, r *http.Request) {
+	metric := usage.Metric(r.URL.Query().Get("by"))
+	switch metric {
+	case "":
+		metric = usage.MetricRequests
+	case usage.MetricRequests, usage.MetricErrors:
+	default:
+		response.Error(w, errors.Validation("by must be requests or errors"))
+		return
+	}
+	hours, err := intParam(r, "hours", 1, usage.MaxHours)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	limit, err := intParam(r, "limit", defaultTopUsers, maxTopUsers)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	top, topErr := h.recorder.Top(r.Context(), metric, hours, limit)
+	if topErr != nil {
+		h.logger.WithError(topErr).Error("Failed to read top users")
+		response.Error(w, errors.Unavailable("usage is unavailable"))
+		return
+	}
+	response.OK(w, map[string]interface{}{
+		"by":    metric,
+		"hours": hours,
+		"users": top,
+	})
+}
+
+// intParam reads a positive integer query parameter of at most maxValue, or
+// returns fallback when it is absent.
+func intParam(r *http.Request, name string, fallback, maxValue int) (int, *errors.Error) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return fallback, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > maxValue {
+		return 0, errors.Validation(name + " must be between 1 and " + strconv.Itoa(maxValue))
+	}
+	return v, nil
+}
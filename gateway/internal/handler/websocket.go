@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/gateway/internal/ws"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+)
+
+const (
+	// wsAuthTimeout is how long a connection may stay open without authenticating.
+	wsAuthTimeout = 10 * time.Second
+	// wsPingInterval is how often the server pings; the client must answer
+	// within wsPongWait or the connection is dropped.
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 60 * time.Second
+	// wsWriteTimeout drops clients that stop reading instead of blocking on them.
+	wsWriteTimeout = 10 * time.Second
+	// wsReadLimit bounds client messages, which are small control messages.
+	wsReadLimit = 4096
+	// wsMaxSubscriptions caps the subscriptions a connection may hold.
+	wsMaxSubscriptions = 50
+)
+
+// wsTopics are the topics a WebSocket client may subscribe to. Scoped
+// subscriptions ("transactions:{walletId}") are allowed on wsScopedTopics.
+var (
+	wsTopics       = map[string]bool{"transactions": true, "wallets": true, "users": true, "risk": true, "notifications": true, "all": true}
+	wsScopedTopics = map[string]bool{"transactions": true, "wallets": true}
+	// wsWalletFields are the event data keys that name the wallets it touches.
+	wsWalletFields = []string{"wallet_id", "source_wallet_id", "destination_wallet_id"}
+)
+
+// TokenValidator validates bearer tokens for connections that authenticate
+// after the handshake.
+type TokenValidator interface {
+	ValidateToken(token string) (*middleware.JWTClaims, *errors.Error)
+}
+
+// wsClientMessage is a message sent by the client.
+type wsClientMessage struct {
+	Type   string   `json:"type"` // auth, subscribe, unsubscribe or ping
+	Token  string   `json:"token,omitempty"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// wsServerMessage is a message sent to the client.
+type wsServerMessage struct {
+	Type    string        `json:"type"` // authenticated, subscribed, unsubscribed, event, dropped, pong or error
+	UserID  string        `json:"user_id,omitempty"`
+	Topics  []string      `json:"topics,omitempty"`
+	Event   *events.Event `json:"event,omitempty"`
+	Count   int64         `json:"count,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+// WebSocketHandler serves the event stream over WebSocket for clients that
+// handle it better than SSE. Every connection is authenticated and only
+// receives events owned by its user.
+type WebSocketHandler struct {
+	broker    *events.Broker
+	validator TokenValidator
+	logger    *logger.Logger
+}
+
+// NewWebSocketHandler creates a new WebSocket handler.
+func NewWebSocketHandler(broker *events.Broker, validator TokenValidator, log *logger.Logger) *WebSocketHandler {
+	return &WebSocketHandler{
+		broker:    broker,
+		validator: validator,
+		logger:    log,
+	}
+}
+
+// wsSession is the state of one WebSocket connection.
+type wsSession struct {
+	conn      *ws.Conn
+	client    *events.Client
+	userID    string
+	mu        sync.Mutex
+	expiresAt time.Time
+	// scopes maps a topic to the wallet IDs subscribed on it. A nil set means
+	// the whole topic is subscribed.
+	scopes map[string]map[string]bool
+	count  int
+}
+
+// HandleWebSocket handles GET /api/v1/ws. Clients authenticate with an
+// Authorization header on the handshake or an auth message sent within
+// wsAuthTimeout, then manage topics with subscribe and unsubscribe messages.
+func (h *WebSocketHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := ws.Upgrade(w, r)
+	if err != nil {
+		h.logger.WithError(err).Debug("WebSocket upgrade failed")
+		return
+	}
+	conn.SetReadLimit(wsReadLimit)
+	conn.SetWriteTimeout(wsWriteTimeout)
+
+	session := &wsSession{conn: conn, scopes: make(map[string]map[string]bool)}
+
+	claims, reason := h.authenticate(r, conn)
+	if claims == nil {
+		_ = conn.Close(ws.ClosePolicyViolation, reason)
+		return
+	}
+	session.userID = claims.UserID
+	session.setExpiry(claims)
+
+	requestID := requestid.FromRequest(r)
+	if requestID == "" {
+		requestID = "unknown"
+	}
+	session.client = events.NewUserClient(fmt.Sprintf("ws-%s-%d", requestID, time.Now().UnixNano()), claims.UserID)
+	h.broker.Register(session.client)
+
+	clientLog := h.logger.WithField("client_id", session.client.ID).WithField("user_id", session.userID)
+	clientLog.Info("WebSocket client connected")
+	defer func() {
+		h.broker.Unregister(session.client)
+		_ = conn.Close(ws.CloseNormal, "")
+		clientLog.Info("WebSocket client disconnected")
+	}()
+
+	_ = session.send(wsServerMessage{Type: "authenticated", UserID: session.userID})
+
+	done := make(chan struct{})
+	go h.writeLoop(session, done)
+	h.readLoop(session)
+	close(done)
+}
+
+// authenticate resolves the connection's claims from the Authorization header
+// or, failing that, from the first message. It returns the close reason on failure.
+func (h *WebSocketHandler) authenticate(r *http.Request, conn *ws.Conn) (*middleware.JWTClaims, string) {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if claims, err := h.validator.ValidateToken(token); err == nil {
+			return claims, ""
+		}
+		return nil, "invalid or expired token"
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, "authentication required"
+	}
+
+	var msg wsClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "auth" || msg.Token == "" {
+		return nil, "first message must be an auth message"
+	}
+	claims, validationErr := h.validator.ValidateToken(msg.Token)
+	if validationErr != nil {
+		return nil, "invalid or expired token"
+	}
+	return claims, ""
+}
+
+// readLoop handles client messages until the connection fails or closes.
+func (h *WebSocketHandler) readLoop(s *wsSession) {
+	extend := func() { _ = s.conn.SetReadDeadline(time.Now().Add(wsPongWait)) }
+	extend()
+	s.conn.SetPongHandler(extend)
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		extend()
+
+		var msg wsClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			_ = s.send(wsServerMessage{Type: "error", Message: "invalid JSON message"})
+			continue
+		}
+
+		switch msg.Type {
+		case "subscribe":
+			if err := s.subscribe(msg.Topics); err != nil {
+				_ = s.send(wsServerMessage{Type: "error", Message: err.Error()})
+				continue
+			}
+			_ = s.send(wsServerMessage{Type: "subscribed", Topics: msg.Topics})
+		case "unsubscribe":
+			s.unsubscribe(msg.Topics)
+			_ = s.send(wsServerMessage{Type: "unsubscribed", Topics: msg.Topics})
+		case "auth":
+			// Re-authenticating before expiry keeps the connection open
+			claims, validationErr := h.validator.ValidateToken(msg.Token)
+			if validationErr != nil || claims.UserID != s.userID {
+				_ = s.conn.Close(ws.ClosePolicyViolation, "invalid or expired token")
+				return
+			}
+			s.setExpiry(claims)
+			_ = s.send(wsServerMessage{Type: "authenticated", UserID: s.userID})
+		case "ping":
+			_ = s.send(wsServerMessage{Type: "pong"})
+		default:
+			_ = s.send(wsServerMessage{Type: "error", Message: fmt.Sprintf("unknown message type %q", msg.Type)})
+		}
+	}
+}
+
+// writeLoop delivers events and keepalive pings until the session ends.
+func (h *WebSocketHandler) writeLoop(s *wsSession, done <-chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case event, ok := <-s.client.Channel:
+			if !ok {
+				// Broker stopped
+				_ = s.conn.Close(ws.CloseGoingAway, "server shutting down")
+				return
+			}
+			if !s.matches(event) {
+				continue
+			}
+			if err := s.send(wsServerMessage{Type: "event", Event: &event}); err != nil {
+				// The client stopped reading; closing unblocks the read loop
+				_ = s.conn.Close(ws.CloseTryAgainLater, "client too slow")
+				return
+			}
+			// Tell the client events were lost so it can refetch state
+			if dropped := s.client.TakeDropped(); dropped > 0 {
+				_ = s.send(wsServerMessage{Type: "dropped", Count: dropped})
+			}
+
+		case <-ticker.C:
+			if s.expired() {
+				_ = s.conn.Close(ws.ClosePolicyViolation, "token expired")
+				return
+			}
+			if err := s.conn.WriteControl(ws.PingMessage, nil); err != nil {
+				_ = s.conn.Close(ws.CloseGoingAway, "")
+				return
+			}
+		}
+	}
+}
+
+// send writes a JSON message to the client.
+func (s *wsSession) send(msg wsServerMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(ws.TextMessage, data)
+}
+
+func (s *wsSession) setExpiry(claims *middleware.JWTClaims) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiresAt = time.Time{}
+	if claims.ExpiresAt != nil {
+		s.expiresAt = claims.ExpiresAt.Time
+	}
+}
+
+func (s *wsSession) expired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.expiresAt.IsZero() && time.Now().After(s.expiresAt)
+}
+
+// parseSubscription splits "topic" or "topic:walletId".
+func parseSubscription(subscription string) (topic, walletID string, err error) {
+	topic, walletID, _ = strings.Cut(subscription, ":")
+	if !wsTopics[topic] {
+		return "", "", fmt.Errorf("unknown topic %q", topic)
+	}
+	if walletID != "" && !wsScopedTopics[topic] {
+		return "", "", fmt.Errorf("topic %q cannot be scoped to a wallet", topic)
+	}
+	return topic, walletID, nil
+}
+
+// subscribe adds subscriptions. It validates all of them before applying any.
+func (s *wsSession) subscribe(subscriptions []string) error {
+	if len(subscriptions) == 0 {
+		return fmt.Errorf("topics are required")
+	}
+	for _, subscription := range subscriptions {
+		if _, _, err := parseSubscription(subscription); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count+len(subscriptions) > wsMaxSubscriptions {
+		return fmt.Errorf("at most %d subscriptions are allowed", wsMaxSubscriptions)
+	}
+
+	for _, subscription := range subscriptions {
+		topic, walletID, _ := parseSubscription(subscription)
+		wallets, exists := s.scopes[topic]
+		switch {
+		case walletID == "":
+			// A whole-topic subscription supersedes wallet scopes
+			s.scopes[topic] = nil
+		case !exists:
+			s.scopes[topic] = map[string]bool{walletID: true}
+		case wallets != nil:
+			wallets[walletID] = true
+		}
+		s.client.Subscribe(topic)
+	}
+	s.recount()
+	return nil
+}
+
+// unsubscribe removes subscriptions. "topic" drops the topic entirely,
+// "topic:walletId" drops one wallet scope.
+func (s *wsSession) unsubscribe(subscriptions []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscription := range subscriptions {
+		topic, walletID, err := parseSubscription(subscription)
+		if err != nil {
+			continue
+		}
+		wallets, exists := s.scopes[topic]
+		if !exists {
+			continue
+		}
+		if walletID != "" && wallets != nil {
+			delete(wallets, walletID)
+			if len(wallets) > 0 {
+				continue
+			}
+		} else if walletID != "" {
+			continue
+		}
+		delete(s.scopes, topic)
+		s.client.Unsubscribe(topic)
+	}
+	s.recount()
+}
+
+// recount refreshes the subscription count. Callers must hold s.mu.
+func (s *wsSession) recount() {
+	s.count = 0
+	for _, wallets := range s.scopes {
+		s.count += max(len(wallets), 1)
+	}
+}
+
+// matches reports whether an event falls within the session's subscriptions.
+func (s *wsSession) matches(event events.Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.scopes["all"]; ok {
+		return true
+	}
+	wallets, ok := s.scopes[event.Topic]
+	if !ok {
+		return false
+	}
+	if wallets == nil {
+		return true
+	}
+	for _, field := range wsWalletFields {
+		if id, ok := event.Data[field].(string); ok && wallets[id] {
+			return true
+		}
+	}
+	return false
+}
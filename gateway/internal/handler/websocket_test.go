@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/gateway/internal/ws"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// ============================================================
+// WebSocket Handler Tests
+// ============================================================
+
+func createTestWebSocketServer(t *testing.T) (*events.Broker, string) {
+	t.Helper()
+	broker := events.NewBroker()
+	broker.Start()
+	t.Cleanup(broker.Stop)
+
+	handler := NewWebSocketHandler(broker, middleware.NewJWTValidator(testExchangeSecret), logger.NewDefault("test"))
+	srv := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	t.Cleanup(srv.Close)
+	return broker, "ws://" + strings.TrimPrefix(srv.URL, "http://") + "/api/v1/ws"
+}
+
+func wsSend(t *testing.T, conn *ws.Conn, msg wsClientMessage) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(ws.TextMessage, data))
+}
+
+func wsReceive(t *testing.T, conn *ws.Conn) wsServerMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var msg wsServerMessage
+	require.NoError(t, json.Unmarshal(data, &msg))
+	return msg
+}
+
+func TestWebSocketHandler_Authentication(t *testing.T) {
+	_, url := createTestWebSocketServer(t)
+
+	t.Run("authenticates with the handshake header", func(t *testing.T) {
+		header := http.Header{"Authorization": {"Bearer " + createTestSubjectToken(t, nil, time.Hour)}}
+		conn, _, err := ws.Dial(url, header)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close(ws.CloseNormal, "") }()
+
+		msg := wsReceive(t, conn)
+		assert.Equal(t, "authenticated", msg.Type)
+		assert.Equal(t, "user-123", msg.UserID)
+	})
+
+	t.Run("authenticates with the first message", func(t *testing.T) {
+		conn, _, err := ws.Dial(url, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close(ws.CloseNormal, "") }()
+
+		wsSend(t, conn, wsClientMessage{Type: "auth", Token: createTestSubjectToken(t, nil, time.Hour)})
+		assert.Equal(t, "authenticated", wsReceive(t, conn).Type)
+	})
+
+	t.Run("closes connections with an invalid token", func(t *testing.T) {
+		conn, _, err := ws.Dial(url, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close(ws.CloseNormal, "") }()
+
+		wsSend(t, conn, wsClientMessage{Type: "auth", Token: "not-a-token"})
+		_, _, err = conn.ReadMessage()
+		var closeErr *ws.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, ws.ClosePolicyViolation, closeErr.Code)
+	})
+
+	t.Run("closes connections that send anything before auth", func(t *testing.T) {
+		conn, _, err := ws.Dial(url, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close(ws.CloseNormal, "") }()
+
+		wsSend(t, conn, wsClientMessage{Type: "subscribe", Topics: []string{"wallets"}})
+		_, _, err = conn.ReadMessage()
+		var closeErr *ws.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, ws.ClosePolicyViolation, closeErr.Code)
+	})
+}
+
+func TestWebSocketHandler_Subscriptions(t *testing.T) {
+	broker, url := createTestWebSocketServer(t)
+
+	header := http.Header{"Authorization": {"Bearer " + createTestSubjectToken(t, nil, time.Hour)}}
+	conn, _, err := ws.Dial(url, header)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close(ws.CloseNormal, "") }()
+	require.Equal(t, "authenticated", wsReceive(t, conn).Type)
+
+	t.Run("rejects unknown and unscopable topics", func(t *testing.T) {
+		wsSend(t, conn, wsClientMessage{Type: "subscribe", Topics: []string{"payroll"}})
+		assert.Equal(t, "error", wsReceive(t, conn).Type)
+
+		wsSend(t, conn, wsClientMessage{Type: "subscribe", Topics: []string{"users:w1"}})
+		assert.Equal(t, "error", wsReceive(t, conn).Type)
+	})
+
+	t.Run("delivers owned events for subscribed wallets only", func(t *testing.T) {
+		wsSend(t, conn, wsClientMessage{Type: "subscribe", Topics: []string{"transactions:w1"}})
+		require.Equal(t, "subscribed", wsReceive(t, conn).Type)
+
+		owned := []string{"user-123"}
+		broker.BroadcastToUsers("transactions", "transaction.created", map[string]interface{}{"transaction_id": "tx-other-wallet", "source_wallet_id": "w2"}, owned)
+		broker.BroadcastToUsers("transactions", "transaction.created", map[string]interface{}{"transaction_id": "tx-other-user", "source_wallet_id": "w1"}, []string{"user-456"})
+		broker.BroadcastToUsers("wallets", "wallet.balance_updated", map[string]interface{}{"wallet_id": "w1"}, owned)
+		broker.BroadcastToUsers("transactions", "transaction.completed", map[string]interface{}{"transaction_id": "tx-1", "destination_wallet_id": "w1"}, owned)
+
+		msg := wsReceive(t, conn)
+		require.Equal(t, "event", msg.Type)
+		assert.Equal(t, "transaction.completed", msg.Event.Type)
+		assert.Equal(t, "transactions", msg.Event.Topic)
+		assert.Equal(t, "tx-1", msg.Event.Data["transaction_id"])
+	})
+
+	t.Run("unsubscribe stops delivery", func(t *testing.T) {
+		wsSend(t, conn, wsClientMessage{Type: "unsubscribe", Topics: []string{"transactions:w1"}})
+		require.Equal(t, "unsubscribed", wsReceive(t, conn).Type)
+
+		broker.BroadcastToUsers("transactions", "transaction.completed", map[string]interface{}{"source_wallet_id": "w1"}, []string{"user-123"})
+		wsSend(t, conn, wsClientMessage{Type: "ping"})
+		assert.Equal(t, "pong", wsReceive(t, conn).Type)
+	})
+}
+
+func TestWSSession_Matches(t *testing.T) {
+	session := &wsSession{client: events.NewClient("c1"), scopes: make(map[string]map[string]bool)}
+	require.NoError(t, session.subscribe([]string{"wallets:w1", "wallets:w2"}))
+
+	walletEvent := func(id string) events.Event {
+		return events.Event{Topic: "wallets", Data: map[string]interface{}{"wallet_id": id}}
+	}
+	assert.True(t, session.matches(walletEvent("w2")))
+	assert.False(t, session.matches(walletEvent("w3")))
+
+	// A whole-topic subscription supersedes the wallet scopes
+	require.NoError(t, session.subscribe([]string{"wallets"}))
+	assert.True(t, session.matches(walletEvent("w3")))
+	assert.Equal(t, 1, session.count)
+
+	session.unsubscribe([]string{"wallets"})
+	assert.False(t, session.matches(walletEvent("w1")))
+	assert.False(t, session.client.IsSubscribed("wallets"))
+}
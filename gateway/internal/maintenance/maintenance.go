@@ -0,0 +1,206 @@
+// Package maintenance takes gateway routes out of service at runtime. A
+// disabled route answers 503 with Retry-After until it is enabled again or its
+// window expires, so a backend can be migrated or repaired without a redeploy.
+package maintenance
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/pathmatch"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+const (
+	// DefaultRetryAfter is the Retry-After sent for windows without an expiry.
+	DefaultRetryAfter = 5 * time.Minute
+	// MaxDuration bounds how long a window may stay open.
+	MaxDuration = 24 * time.Hour
+	// ExemptPrefix is never disabled, so the admin endpoints stay reachable
+	// to end a window even when every route is down.
+	ExemptPrefix = "/internal/v1/admin/"
+)
+
+// Window disables a route. Route is a pathmatch pattern such as
+// /api/v1/wallets/* or /api/v1/transactions/{id}/reverse.
+type Window struct {
+	Route             string     `json:"route"`
+	Method            string     `json:"method,omitempty"` // Empty disables every method
+	Reason            string     `json:"reason,omitempty"`
+	RetryAfterSeconds int        `json:"retry_after_seconds"`
+	StartedAt         time.Time  `json:"started_at"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"` // Nil stays open until enabled
+	Rejected          int64      `json:"rejected"`             // Requests answered 503
+}
+
+// window is an open maintenance window and its rejection count.
+type window struct {
+	Window
+	rejected atomic.Int64
+}
+
+// Store holds the open maintenance windows.
+type Store struct {
+	mu      sync.RWMutex
+	windows map[string]*window
+	now     func() time.Time
+}
+
+// NewStore creates a store with every route enabled.
+func NewStore() *Store {
+	return &Store{
+		windows: make(map[string]*window),
+		now:     time.Now,
+	}
+}
+
+// windowKey identifies a window by method and route.
+func windowKey(method, route string) string {
+	return method + " " + route
+}
+
+// Disable opens a window for the route, replacing any window for the same
+// method and route. A zero duration keeps it open until Enable; otherwise the
+// duration is clamped to MaxDuration.
+func (s *Store) Disable(route, method, reason string, retryAfter, duration time.Duration) (Window, error) {
+	if !strings.HasPrefix(route, "/") {
+		return Window{}, fmt.Errorf("route must be a path pattern starting with /")
+	}
+	if strings.HasPrefix(route, ExemptPrefix) {
+		return Window{}, fmt.Errorf("routes under %s cannot be disabled", ExemptPrefix)
+	}
+	method = strings.ToUpper(method)
+	if retryAfter < 0 || duration < 0 {
+		return Window{}, fmt.Errorf("retry_after and duration must not be negative")
+	}
+
+	now := s.now()
+	w := &window{Window: Window{
+		Route:     route,
+		Method:    method,
+		Reason:    reason,
+		StartedAt: now,
+	}}
+	if duration > 0 {
+		if duration > MaxDuration {
+			duration = MaxDuration
+		}
+		expiresAt := now.Add(duration)
+		w.ExpiresAt = &expiresAt
+		if retryAfter == 0 {
+			retryAfter = duration
+		}
+	}
+	if retryAfter == 0 {
+		retryAfter = DefaultRetryAfter
+	}
+	w.RetryAfterSeconds = int(math.Ceil(retryAfter.Seconds()))
+
+	s.mu.Lock()
+	s.windows[windowKey(method, route)] = w
+	s.mu.Unlock()
+
+	return w.snapshot(), nil
+}
+
+// Enable closes the window for the method and route. It reports whether one
+// was open.
+func (s *Store) Enable(route, method string) bool {
+	key := windowKey(strings.ToUpper(method), route)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.windows[key]
+	delete(s.windows, key)
+	return ok && !w.expired(s.now())
+}
+
+// Windows returns the open windows sorted by route.
+func (s *Store) Windows() []Window {
+	now := s.now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	windows := make([]Window, 0, len(s.windows))
+	for _, w := range s.windows {
+		if !w.expired(now) {
+			windows = append(windows, w.snapshot())
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Route != windows[j].Route {
+			return windows[i].Route < windows[j].Route
+		}
+		return windows[i].Method < windows[j].Method
+	})
+	return windows
+}
+
+// match returns the open window covering the request, if any.
+func (s *Store) match(method, path string) *window {
+	if strings.HasPrefix(path, ExemptPrefix) {
+		return nil
+	}
+	now := s.now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.windows {
+		if w.expired(now) || (w.Method != "" && w.Method != method) {
+			continue
+		}
+		if pathmatch.Match(w.Route, path) {
+			return w
+		}
+	}
+	return nil
+}
+
+// Middleware answers requests to disabled routes with 503 and Retry-After.
+func (s *Store) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w := s.match(r.Method, r.URL.Path)
+		if w == nil {
+			next.ServeHTTP(rw, r)
+			return
+		}
+
+		w.rejected.Add(1)
+		retryAfter := w.RetryAfterSeconds
+		if w.ExpiresAt != nil {
+			if remaining := int(math.Ceil(w.ExpiresAt.Sub(s.now()).Seconds())); remaining < retryAfter {
+				retryAfter = max(remaining, 1)
+			}
+		}
+
+		message := "this route is temporarily unavailable for maintenance"
+		if w.Reason != "" {
+			message += ": " + w.Reason
+		}
+		rw.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		response.Error(rw, errors.Unavailable(message))
+	})
+}
+
+// expired reports whether the window has closed by itself.
+func (w *window) expired(now time.Time) bool {
+	return w.ExpiresAt != nil && !now.Before(*w.ExpiresAt)
+}
+
+// snapshot copies the window with its current rejection count.
+func (w *window) snapshot() Window {
+	cp := w.Window
+	cp.Rejected = w.rejected.Load()
+	return cp
+}
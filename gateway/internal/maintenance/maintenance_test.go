@@ -0,0 +1,104 @@
+package maintenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestStore(now time.Time) *Store {
+	s := NewStore()
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func serve(s *Store, method, path string) *httptest.ResponseRecorder {
+	handler := s.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestStore_DisableAndEnable(t *testing.T) {
+	s := newTestStore(time.Now())
+
+	if _, err := s.Disable("/api/v1/wallets/*", "", "ledger migration", 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := serve(s, http.MethodGet, "/api/v1/wallets/abc/balance")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "300" {
+		t.Errorf("expected default Retry-After of 300, got %q", got)
+	}
+	if rec := serve(s, http.MethodGet, "/api/v1/transactions/abc"); rec.Code != http.StatusOK {
+		t.Errorf("expected other routes to be served, got %d", rec.Code)
+	}
+
+	windows := s.Windows()
+	if len(windows) != 1 || windows[0].Rejected != 1 || windows[0].Reason != "ledger migration" {
+		t.Fatalf("unexpected windows: %+v", windows)
+	}
+
+	if !s.Enable("/api/v1/wallets/*", "") {
+		t.Fatal("expected the window to be closed")
+	}
+	if rec := serve(s, http.MethodGet, "/api/v1/wallets/abc/balance"); rec.Code != http.StatusOK {
+		t.Errorf("expected the route to be served again, got %d", rec.Code)
+	}
+	if s.Enable("/api/v1/wallets/*", "") {
+		t.Error("expected no window to close twice")
+	}
+}
+
+func TestStore_MethodAndExpiry(t *testing.T) {
+	now := time.Now()
+	s := newTestStore(now)
+
+	if _, err := s.Disable("/api/v1/transactions/{id}/reverse", "post", "", 0, 90*time.Second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := serve(s, http.MethodPost, "/api/v1/transactions/tx-1/reverse")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "90" {
+		t.Errorf("expected Retry-After until the window expires, got %q", got)
+	}
+	if rec := serve(s, http.MethodGet, "/api/v1/transactions/tx-1/reverse"); rec.Code != http.StatusOK {
+		t.Errorf("expected other methods to be served, got %d", rec.Code)
+	}
+
+	s.now = func() time.Time { return now.Add(90 * time.Second) }
+	if rec := serve(s, http.MethodPost, "/api/v1/transactions/tx-1/reverse"); rec.Code != http.StatusOK {
+		t.Errorf("expected the expired window to stop rejecting, got %d", rec.Code)
+	}
+	if windows := s.Windows(); len(windows) != 0 {
+		t.Errorf("expected no open windows, got %+v", windows)
+	}
+}
+
+func TestStore_AdminEndpointsStayReachable(t *testing.T) {
+	s := newTestStore(time.Now())
+
+	if _, err := s.Disable("/*", "", "", 0, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec := serve(s, http.MethodGet, "/api/v1/wallets"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected every route to be disabled, got %d", rec.Code)
+	}
+	if rec := serve(s, http.MethodDelete, "/internal/v1/admin/maintenance"); rec.Code != http.StatusOK {
+		t.Errorf("expected admin endpoints to be exempt, got %d", rec.Code)
+	}
+
+	if _, err := s.Disable("/internal/v1/admin/traffic", "", "", 0, 0); err == nil {
+		t.Error("expected admin routes to be rejected")
+	}
+	if _, err := s.Disable("api/v1/wallets", "", "", 0, 0); err == nil {
+		t.Error("expected a relative route to be rejected")
+	}
+}
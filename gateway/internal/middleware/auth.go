@@ -29,8 +29,11 @@ type JWTClaims struct {
 	UserID      string   `json:"user_id"`
 	Email       string   `json:"email"`
 	Status      string   `json:"status"`
+	AccountType string   `json:"account_type,omitempty"`
+	Tier        string   `json:"tier,omitempty"`
 	Roles       []string `json:"roles,omitempty"`
 	Permissions []string `json:"permissions,omitempty"`
+	Scope       string   `json:"scope,omitempty"` // Set on scope-narrowed tokens issued by token exchange
 	jwt.RegisteredClaims
 }
 
@@ -63,20 +66,10 @@ func (v *JWTValidator) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
-		tokenString := parts[1]
-
 		// Parse and validate token
-		claims := &JWTClaims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(v.jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			response.Error(w, errors.Unauthorized("invalid or expired token"))
+		claims, err := v.ValidateToken(parts[1])
+		if err != nil {
+			response.Error(w, err)
 			return
 		}
 
@@ -90,12 +83,34 @@ func (v *JWTValidator) Authenticate(next http.Handler) http.Handler {
 		// Add user info to headers for backend services
 		r.Header.Set("X-User-ID", claims.UserID)
 		r.Header.Set("X-User-Email", claims.Email)
+		r.Header.Del("X-User-Tier")
+		if claims.Tier != "" {
+			r.Header.Set("X-User-Tier", claims.Tier)
+		}
 
 		// Continue to next handler
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// ValidateToken parses a bearer token and returns its claims. It is used by
+// Authenticate and by connections that authenticate after the handshake.
+func (v *JWTValidator) ValidateToken(tokenString string) (*JWTClaims, *errors.Error) {
+	claims := &JWTClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(v.jwtSecret), nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, errors.Unauthorized("invalid or expired token")
+	}
+	return claims, nil
+}
+
 // RequirePermission is a middleware that checks if user has specific permission.
 func (v *JWTValidator) RequirePermission(permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -168,6 +183,10 @@ func (v *JWTValidator) Optional(next http.Handler) http.Handler {
 
 			r.Header.Set("X-User-ID", claims.UserID)
 			r.Header.Set("X-User-Email", claims.Email)
+			r.Header.Del("X-User-Tier")
+			if claims.Tier != "" {
+				r.Header.Set("X-User-Tier", claims.Tier)
+			}
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
@@ -0,0 +1,222 @@
+// Package mock lets the gateway answer selected routes with example responses
+// instead of proxying them, so frontend work can start before a backend
+// endpoint is deployed. Responses come from recorded fixture files or are given
+// inline when a route is enabled. It is never enabled in production.
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/pathmatch"
+)
+
+// MaxDelay bounds the artificial latency of a mocked response.
+const MaxDelay = 10 * time.Second
+
+// Response is an example response served for a mocked route.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Route mocks requests matching Method and Path. Path segments written as
+// {name} match any single segment and a trailing /* matches the rest.
+type Route struct {
+	Method   string    `json:"method"`
+	Path     string    `json:"path"`
+	Fixture  string    `json:"fixture,omitempty"`  // Name of a loaded fixture
+	Response *Response `json:"response,omitempty"` // Inline response, used when Fixture is empty
+	DelayMs  int       `json:"delay_ms,omitempty"`
+}
+
+// Key identifies a route; enabling a route with the same key replaces it.
+func (r Route) Key() string {
+	return r.Method + " " + r.Path
+}
+
+// Store holds the fixtures and the routes currently served from mocks.
+type Store struct {
+	dir string
+
+	mu       sync.RWMutex
+	fixtures map[string]*Response
+	routes   []Route
+}
+
+// NewStore creates a store that loads fixtures from dir. An empty dir means
+// routes can only use inline responses.
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:      dir,
+		fixtures: make(map[string]*Response),
+	}
+}
+
+// LoadFixtures (re)reads every *.json file in the fixture directory. Each file
+// holds one Response and is named after the file without its extension.
+// Routes referencing a fixture that disappeared are disabled.
+func (s *Store) LoadFixtures() (int, error) {
+	fixtures := make(map[string]*Response)
+	if s.dir != "" {
+		paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+		if err != nil {
+			return 0, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path) //nolint:gosec // Fixture directory is operator-configured
+			if err != nil {
+				return 0, fmt.Errorf("read fixture %s: %w", filepath.Base(path), err)
+			}
+			var resp Response
+			if err := json.Unmarshal(data, &resp); err != nil {
+				return 0, fmt.Errorf("parse fixture %s: %w", filepath.Base(path), err)
+			}
+			if resp.Status == 0 {
+				resp.Status = http.StatusOK
+			}
+			fixtures[strings.TrimSuffix(filepath.Base(path), ".json")] = &resp
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fixtures = fixtures
+	kept := s.routes[:0]
+	for _, route := range s.routes {
+		if route.Fixture == "" || fixtures[route.Fixture] != nil {
+			kept = append(kept, route)
+		}
+	}
+	s.routes = kept
+	return len(fixtures), nil
+}
+
+// Fixtures returns the names of the loaded fixtures, sorted.
+func (s *Store) Fixtures() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.fixtures))
+	for name := range s.fixtures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Routes returns the enabled routes in match order.
+func (s *Store) Routes() []Route {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Route(nil), s.routes...)
+}
+
+// Enable validates route and starts serving it, replacing any route with the same key.
+func (s *Store) Enable(route Route) (Route, error) {
+	route.Method = strings.ToUpper(strings.TrimSpace(route.Method))
+	route.Path = strings.TrimSpace(route.Path)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validate(route); err != nil {
+		return Route{}, err
+	}
+
+	for i, existing := range s.routes {
+		if existing.Key() == route.Key() {
+			s.routes[i] = route
+			return route, nil
+		}
+	}
+	s.routes = append(s.routes, route)
+	return route, nil
+}
+
+// Disable stops mocking the route with the given method and path.
+// It reports whether such a route was enabled.
+func (s *Store) Disable(method, path string) bool {
+	key := Route{Method: strings.ToUpper(method), Path: path}.Key()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, route := range s.routes {
+		if route.Key() == key {
+			s.routes = append(s.routes[:i], s.routes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear disables every route.
+func (s *Store) Clear() {
+	s.mu.Lock()
+	s.routes = nil
+	s.mu.Unlock()
+}
+
+// Match returns the route and response to serve for a request, or nil if the
+// request should be proxied. Routes are checked in the order they were enabled.
+func (s *Store) Match(method, path string) (*Route, *Response) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := range s.routes {
+		route := s.routes[i]
+		if route.Method != "*" && route.Method != method {
+			continue
+		}
+		if !pathmatch.Match(route.Path, path) {
+			continue
+		}
+		resp := route.Response
+		if route.Fixture != "" {
+			resp = s.fixtures[route.Fixture]
+		}
+		return &route, resp
+	}
+	return nil, nil
+}
+
+// validate checks a route against the loaded fixtures. Callers hold s.mu.
+func (s *Store) validate(route Route) error {
+	if route.Method == "" {
+		return fmt.Errorf("method is required")
+	}
+	if !strings.HasPrefix(route.Path, "/api/v1/") {
+		return fmt.Errorf("path must start with /api/v1/")
+	}
+	if route.DelayMs < 0 || time.Duration(route.DelayMs)*time.Millisecond > MaxDelay {
+		return fmt.Errorf("delay_ms must be between 0 and %d", MaxDelay.Milliseconds())
+	}
+
+	switch {
+	case route.Fixture != "" && route.Response != nil:
+		return fmt.Errorf("set either fixture or response, not both")
+	case route.Fixture != "":
+		if s.fixtures[route.Fixture] == nil {
+			return fmt.Errorf("unknown fixture %q", route.Fixture)
+		}
+	case route.Response != nil:
+		if route.Response.Status < 100 || route.Response.Status > 599 {
+			return fmt.Errorf("response.status must be a valid HTTP status")
+		}
+		if len(route.Response.Body) > 0 && !json.Valid(route.Response.Body) {
+			return fmt.Errorf("response.body must be valid JSON")
+		}
+	default:
+		return fmt.Errorf("fixture or response is required")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package mock
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func inline(status int, body string) *Response {
+	return &Response{Status: status, Body: json.RawMessage(body)}
+}
+
+func TestStore(t *testing.T) {
+	t.Run("serves inline response for matching method and path", func(t *testing.T) {
+		s := NewStore("")
+		if _, err := s.Enable(Route{Method: "get", Path: "/api/v1/rewards/{id}", Response: inline(200, `{"points":10}`)}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		route, resp := s.Match("GET", "/api/v1/rewards/42")
+		if route == nil || resp.Status != 200 || string(resp.Body) != `{"points":10}` {
+			t.Fatalf("expected inline response, got %+v %+v", route, resp)
+		}
+		if route, _ := s.Match("POST", "/api/v1/rewards/42"); route != nil {
+			t.Errorf("expected no match for other method, got %+v", route)
+		}
+	})
+
+	t.Run("enabling the same route replaces it and disable restores proxying", func(t *testing.T) {
+		s := NewStore("")
+		_, _ = s.Enable(Route{Method: "GET", Path: "/api/v1/rewards", Response: inline(200, `[]`)})
+		_, _ = s.Enable(Route{Method: "GET", Path: "/api/v1/rewards", Response: inline(503, `{}`)})
+
+		if len(s.Routes()) != 1 {
+			t.Fatalf("expected one route, got %d", len(s.Routes()))
+		}
+		if _, resp := s.Match("GET", "/api/v1/rewards"); resp.Status != 503 {
+			t.Errorf("expected replaced response, got %d", resp.Status)
+		}
+
+		if !s.Disable("get", "/api/v1/rewards") {
+			t.Fatal("expected route to be disabled")
+		}
+		if route, _ := s.Match("GET", "/api/v1/rewards"); route != nil {
+			t.Error("expected request to be proxied after disable")
+		}
+	})
+
+	t.Run("rejects invalid routes", func(t *testing.T) {
+		s := NewStore("")
+		invalid := []Route{
+			{Path: "/api/v1/x", Response: inline(200, `{}`)},
+			{Method: "GET", Path: "/internal/v1/chaos", Response: inline(200, `{}`)},
+			{Method: "GET", Path: "/api/v1/x"},
+			{Method: "GET", Path: "/api/v1/x", Fixture: "missing"},
+			{Method: "GET", Path: "/api/v1/x", Response: inline(42, `{}`)},
+			{Method: "GET", Path: "/api/v1/x", Response: inline(200, `{not json`)},
+			{Method: "GET", Path: "/api/v1/x", Response: inline(200, `{}`), DelayMs: 60000},
+		}
+		for i, route := range invalid {
+			if _, err := s.Enable(route); err == nil {
+				t.Errorf("case %d: expected validation error for %+v", i, route)
+			}
+		}
+	})
+
+	t.Run("serves fixtures and drops routes whose fixture disappears", func(t *testing.T) {
+		dir := t.TempDir()
+		fixture := filepath.Join(dir, "rewards_list.json")
+		if err := os.WriteFile(fixture, []byte(`{"body":{"success":true,"data":[]}}`), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		s := NewStore(dir)
+		if n, err := s.LoadFixtures(); err != nil || n != 1 {
+			t.Fatalf("expected one fixture, got %d (%v)", n, err)
+		}
+		if _, err := s.Enable(Route{Method: "GET", Path: "/api/v1/rewards", Fixture: "rewards_list"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, resp := s.Match("GET", "/api/v1/rewards")
+		if resp == nil || resp.Status != 200 {
+			t.Fatalf("expected fixture with default status 200, got %+v", resp)
+		}
+
+		if err := os.Remove(fixture); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.LoadFixtures(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s.Routes()) != 0 {
+			t.Errorf("expected route to be dropped with its fixture, got %+v", s.Routes())
+		}
+	})
+}
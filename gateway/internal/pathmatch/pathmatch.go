@@ -0,0 +1,46 @@
+// Package pathmatch matches request paths against simple route patterns
+// shared by the gateway's mock and caching layers.
+package pathmatch
+
+import "strings"
+
+// Match reports whether path matches pattern segment by segment. Pattern
+// segments written as {name} match any single segment and a trailing /*
+// matches the rest of the path, including nothing.
+func Match(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return len(pathParts) >= i
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			continue
+		}
+		if part != pathParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(pathParts)
+}
+
+// Params matches path against pattern like Match and returns the values of
+// the pattern's {name} segments. Trailing /* patterns capture nothing.
+func Params(pattern, path string) (map[string]string, bool) {
+	if !Match(pattern, path) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range strings.Split(strings.Trim(pattern, "/"), "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params[part[1:len(part)-1]] = pathParts[i]
+		}
+	}
+	return params, true
+}
@@ -0,0 +1,37 @@
+package pathmatch
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/api/v1/wallet/wallets", "/api/v1/wallet/wallets", true},
+		{"/api/v1/wallet/wallets", "/api/v1/wallet/wallets/", true},
+		{"/api/v1/wallet/wallets/{id}", "/api/v1/wallet/wallets/abc", true},
+		{"/api/v1/wallet/wallets/{id}", "/api/v1/wallet/wallets/abc/limits", false},
+		{"/api/v1/rewards/*", "/api/v1/rewards/points/history", true},
+		{"/api/v1/rewards/*", "/api/v1/rewards", true},
+		{"/api/v1/rewards/*", "/api/v1/wallet", false},
+		{"/api/v1/wallet/wallets", "/api/v1/wallet", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	params, ok := Params("/api/v1/wallet/wallets/{id}/limits", "/api/v1/wallet/wallets/abc/limits")
+	if !ok || params["id"] != "abc" || len(params) != 1 {
+		t.Errorf("expected id=abc, got %v (%v)", params, ok)
+	}
+
+	if _, ok := Params("/api/v1/wallet/wallets/{id}", "/api/v1/wallet/wallets"); ok {
+		t.Error("expected no match")
+	}
+}
@@ -0,0 +1,169 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// HealthCheckConfig controls active health probing of backend instances.
+type HealthCheckConfig struct {
+	Interval           time.Duration // Time between probe rounds; zero disables probing
+	Timeout            time.Duration // Upper bound on a single probe
+	Path               string        // Probed path on each instance
+	UnhealthyThreshold int           // Consecutive failures before an instance leaves rotation
+	HealthyThreshold   int           // Consecutive successes before it returns
+}
+
+// DefaultHealthCheckConfig probes /health every 10 seconds.
+func DefaultHealthCheckConfig() HealthCheckConfig {
+	return HealthCheckConfig{
+		Interval:           10 * time.Second,
+		Timeout:            2 * time.Second,
+		Path:               "/health",
+		UnhealthyThreshold: 2,
+		HealthyThreshold:   2,
+	}
+}
+
+// HealthCheckConfigFromEnv reads the health check configuration, falling back
+// to DefaultHealthCheckConfig for unset or invalid values.
+func HealthCheckConfigFromEnv() HealthCheckConfig {
+	cfg := DefaultHealthCheckConfig()
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_HEALTH_CHECK_INTERVAL_SECONDS")); err == nil && v >= 0 {
+		cfg.Interval = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_HEALTH_CHECK_TIMEOUT_MS")); err == nil && v > 0 {
+		cfg.Timeout = time.Duration(v) * time.Millisecond
+	}
+	if v := os.Getenv("GATEWAY_HEALTH_CHECK_PATH"); v != "" {
+		cfg.Path = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_UNHEALTHY_THRESHOLD")); err == nil && v > 0 {
+		cfg.UnhealthyThreshold = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("GATEWAY_HEALTHY_THRESHOLD")); err == nil && v > 0 {
+		cfg.HealthyThreshold = v
+	}
+	return cfg
+}
+
+// HealthChecker probes every registered instance and takes failing ones out
+// of rotation until they recover.
+type HealthChecker struct {
+	registry *ServiceRegistry
+	config   HealthCheckConfig
+	client   *http.Client
+	logger   *logger.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHealthChecker creates a health checker for the registry.
+func NewHealthChecker(registry *ServiceRegistry, config HealthCheckConfig, log *logger.Logger) *HealthChecker {
+	return &HealthChecker{
+		registry: registry,
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		logger:   log,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins probing in the background. It does nothing when the interval is zero.
+func (h *HealthChecker) Start() {
+	if h.config.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	go func() {
+		defer close(h.done)
+
+		ticker := time.NewTicker(h.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			h.CheckAll(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop ends probing and waits for the current round to finish.
+func (h *HealthChecker) Stop() {
+	if h.cancel == nil {
+		return
+	}
+	h.cancel()
+	<-h.done
+}
+
+// CheckAll probes every instance once, concurrently.
+func (h *HealthChecker) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for service, instances := range h.registry.Services() {
+		for _, inst := range instances {
+			wg.Add(1)
+			go func(service, instanceURL string) {
+				defer wg.Done()
+				h.check(ctx, service, instanceURL)
+			}(service, inst.URL)
+		}
+	}
+	wg.Wait()
+}
+
+// check probes one instance and records the result.
+func (h *HealthChecker) check(ctx context.Context, service, instanceURL string) {
+	probeErr := h.probe(ctx, instanceURL)
+	if ctx.Err() != nil {
+		// Shutting down; an aborted probe says nothing about the instance
+		return
+	}
+
+	inst, changed := h.registry.recordProbe(service, instanceURL, probeErr, h.config.UnhealthyThreshold, h.config.HealthyThreshold)
+	if !changed {
+		return
+	}
+
+	log := h.logger.WithField("service", service).WithField("url", instanceURL)
+	if inst.Healthy {
+		log.Info("Backend instance healthy again, back in rotation")
+	} else {
+		log.WithError(probeErr).Warn("Backend instance unhealthy, removed from rotation")
+	}
+}
+
+// probe calls the instance's health endpoint and expects a 2xx answer.
+func (h *HealthChecker) probe(ctx context.Context, instanceURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, instanceURL+h.config.Path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
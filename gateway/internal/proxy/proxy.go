@@ -4,17 +4,47 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/chaos"
+	"github.com/1mb-dev/nivomoney/gateway/internal/mock"
+	"github.com/1mb-dev/nivomoney/gateway/internal/tenant"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
 	"github.com/1mb-dev/nivomoney/shared/response"
 )
 
+// VariantHeader tells the client which variant answered a proxied request.
+const VariantHeader = "X-Backend-Variant"
+
+var (
+	proxiedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_proxy_requests_total",
+		Help: "Requests proxied to backend services, by tenant, variant and response status",
+	}, []string{"tenant", "service", "variant", "status"})
+
+	proxiedDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "gateway_proxy_request_duration_seconds",
+		Help:    "Time to proxy a request to a backend service, by tenant and variant",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"tenant", "service", "variant"})
+)
+
 // Gateway handles proxying requests to backend services.
 type Gateway struct {
-	registry *ServiceRegistry
-	logger   *logger.Logger
+	registry      *ServiceRegistry
+	tenants       map[string]*ServiceRegistry
+	logger        *logger.Logger
+	chaos         *chaos.Injector
+	mocks         *mock.Store
+	countryHeader string
 }
 
 // NewGateway creates a new API gateway.
@@ -25,6 +55,39 @@ func NewGateway(registry *ServiceRegistry, log *logger.Logger) *Gateway {
 	}
 }
 
+// SetChaos enables fault injection for proxied calls (non-production only).
+func (g *Gateway) SetChaos(injector *chaos.Injector) {
+	g.chaos = injector
+}
+
+// SetCountryHeader forwards the client country reported by the edge (e.g.
+// CF-IPCountry) to services as X-Client-Country. Without it, no country is
+// forwarded. Clients can never set X-Client-Country themselves.
+func (g *Gateway) SetCountryHeader(name string) {
+	g.countryHeader = name
+}
+
+// SetTenants routes requests resolved to a tenant other than the default to
+// that tenant's registry. Requests for a tenant without a registry are
+// rejected rather than served by the default services.
+func (g *Gateway) SetTenants(registries map[string]*ServiceRegistry) {
+	g.tenants = registries
+}
+
+// registryFor returns the registry serving the request's tenant.
+func (g *Gateway) registryFor(name string) (*ServiceRegistry, bool) {
+	if name == tenant.Default {
+		return g.registry, true
+	}
+	registry, ok := g.tenants[name]
+	return registry, ok
+}
+
+// SetMocks enables serving example responses for mocked routes (non-production only).
+func (g *Gateway) SetMocks(store *mock.Store) {
+	g.mocks = store
+}
+
 // ProxyRequest proxies the request to the appropriate backend service.
 func (g *Gateway) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 	// Extract path without prefix: /api/v1/{service}/...
@@ -36,26 +99,51 @@ func (g *Gateway) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Mocked routes are answered before service lookup so they work for
+	// services that are not deployed yet
+	if g.mocks != nil && g.serveMock(w, r) {
+		return
+	}
+
+	tenantName := tenant.FromContext(r.Context())
+	registry, ok := g.registryFor(tenantName)
+	if !ok {
+		response.Error(w, errors.NotFound("unknown tenant: "+tenantName))
+		return
+	}
+
 	// Check for special path-based routing rules first
 	// These handle nested resources that belong to different services
-	serviceInfo := g.registry.GetServiceByPath(path)
+	serviceInfo := registry.GetServiceByPath(path)
 
 	if serviceInfo == nil {
 		// Fall back to default segment-based routing
 		serviceName := parts[0]
 
 		var err error
-		serviceInfo, err = g.registry.GetServiceInfo(serviceName)
+		serviceInfo, err = registry.GetServiceInfo(serviceName)
 		if err != nil {
 			response.Error(w, errors.NotFound(err.Error()))
 			return
 		}
 	}
 
+	if g.chaos != nil && g.injectFault(w, r, serviceInfo.Name) {
+		return
+	}
+
+	// Pick a healthy instance of the service, honoring any canary split
+	instanceURL, variant, err := registry.Route(serviceInfo.Name, r.Header.Get(CanaryHeader))
+	if err != nil {
+		g.logger.WithError(err).WithField("service", serviceInfo.Name).WithField("tenant", tenantName).Warn("No healthy backend instance")
+		response.Error(w, errors.Unavailable(serviceInfo.Name+" service unavailable"))
+		return
+	}
+
 	// Parse backend URL
-	target, err := url.Parse(serviceInfo.URL)
+	target, err := url.Parse(instanceURL)
 	if err != nil {
-		g.logger.WithError(err).WithField("url", serviceInfo.URL).Error("Failed to parse backend URL")
+		g.logger.WithError(err).WithField("url", instanceURL).Error("Failed to parse backend URL")
 		response.Error(w, errors.Internal("failed to parse backend URL"))
 		return
 	}
@@ -93,11 +181,16 @@ func (g *Gateway) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		// Set X-Forwarded headers
 		req.Header.Set("X-Forwarded-Host", r.Host)
 		req.Header.Set("X-Forwarded-Proto", getScheme(r))
-		req.Header.Set("X-Real-IP", getClientIP(r))
+		req.Header.Set(middleware.ClientIPHeader, getClientIP(r))
+		if country := g.clientCountry(r); country != "" {
+			req.Header.Set(middleware.ClientCountryHeader, country)
+		} else {
+			req.Header.Del(middleware.ClientCountryHeader)
+		}
 
-		// Add request ID if present
-		if reqID := r.Header.Get("X-Request-ID"); reqID != "" {
-			req.Header.Set("X-Request-ID", reqID)
+		// Forward the request ID assigned by the gateway middleware
+		if reqID := requestid.FromRequest(r); reqID != "" {
+			req.Header.Set(requestid.Header, reqID)
 		}
 
 		g.logger.With(map[string]interface{}{
@@ -105,6 +198,8 @@ func (g *Gateway) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 			"source_path": r.URL.Path,
 			"target_host": target.Host,
 			"target_path": req.URL.Path,
+			"variant":     variant,
+			"tenant":      tenantName,
 		}).Debug("Proxying request")
 	}
 
@@ -114,8 +209,30 @@ func (g *Gateway) ProxyRequest(w http.ResponseWriter, r *http.Request) {
 		response.Error(w, errors.Unavailable("backend service unavailable"))
 	}
 
-	// Proxy the request
-	proxy.ServeHTTP(w, r)
+	// Proxy the request, recording the outcome under the variant that served it
+	w.Header().Set(VariantHeader, variant)
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	proxy.ServeHTTP(rec, r)
+	proxiedDuration.WithLabelValues(tenantName, serviceInfo.Name, variant).Observe(time.Since(start).Seconds())
+	proxiedRequests.WithLabelValues(tenantName, serviceInfo.Name, variant, strconv.Itoa(rec.status)).Inc()
+}
+
+// statusRecorder captures the status code written by the reverse proxy.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, so
+// streamed responses still flush.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
 
 // getScheme returns the request scheme (http or https).
@@ -151,3 +268,89 @@ func getClientIP(r *http.Request) string {
 	}
 	return ip
 }
+
+// clientCountry returns the edge-reported country of the client, or "" if
+// none is configured or the value is not an ISO 3166-1 alpha-2 code.
+func (g *Gateway) clientCountry(r *http.Request) string {
+	if g.countryHeader == "" {
+		return ""
+	}
+	country := strings.ToUpper(strings.TrimSpace(r.Header.Get(g.countryHeader)))
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return ""
+	}
+	return country
+}
+
+// injectFault applies any chaos decision for the target service.
+// Returns true if the request was answered and must not be proxied.
+func (g *Gateway) injectFault(w http.ResponseWriter, r *http.Request, service string) bool {
+	decision := g.chaos.Decide(service)
+	if decision == nil {
+		return false
+	}
+
+	g.logger.WithField("service", service).
+		WithField("fault", decision.Fault).
+		WithField("delay_ms", decision.Delay.Milliseconds()).
+		Debug("Injecting chaos fault")
+
+	if decision.Delay > 0 {
+		timer := time.NewTimer(decision.Delay)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-timer.C:
+		}
+	}
+
+	switch decision.Fault {
+	case chaos.FaultTimeout:
+		response.Error(w, errors.Timeout(service+" timed out (chaos)"))
+		return true
+	case chaos.FaultError:
+		response.Error(w, errors.Internal(service+" failed (chaos)"))
+		return true
+	default:
+		return false
+	}
+}
+
+// serveMock answers the request from a mocked route, if one matches.
+// Returns true if the request was answered and must not be proxied.
+func (g *Gateway) serveMock(w http.ResponseWriter, r *http.Request) bool {
+	route, resp := g.mocks.Match(r.Method, r.URL.Path)
+	if route == nil {
+		return false
+	}
+
+	g.logger.WithField("route", route.Key()).
+		WithField("path", r.URL.Path).
+		Debug("Serving mocked response")
+
+	if route.DelayMs > 0 {
+		timer := time.NewTimer(time.Duration(route.DelayMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-r.Context().Done():
+			return true
+		case <-timer.C:
+		}
+	}
+
+	if len(resp.Body) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	for name, value := range resp.Headers {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("X-Mock-Route", route.Key())
+	w.WriteHeader(resp.Status)
+	if len(resp.Body) > 0 {
+		if _, err := w.Write(resp.Body); err != nil {
+			g.logger.WithError(err).Debug("Failed to write mocked response")
+		}
+	}
+	return true
+}
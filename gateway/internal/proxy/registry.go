@@ -1,92 +1,500 @@
 package proxy
 
 import (
+	stderrors "errors"
 	"fmt"
+	"math/rand/v2"
+	"net/url"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
-// ServiceRegistry holds the URLs of all backend services.
+// Instance sources.
+const (
+	SourceStatic  = "static"  // From the *_SERVICE_URL environment variables
+	SourceDynamic = "dynamic" // Registered at runtime through the registry API
+)
+
+// Instance variants. Canary instances run a new version and receive only the
+// share of traffic the service's split sends them.
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+// CanaryHeader pins a request to a variant: "true" routes to the canary,
+// "false" to stable, overriding the percentage split.
+const CanaryHeader = "X-Canary"
+
+// ErrNoHealthyInstance is returned when every instance of a service is unhealthy.
+var ErrNoHealthyInstance = stderrors.New("no healthy instance")
+
+// Instance is one backend target of a service.
+type Instance struct {
+	URL          string    `json:"url"`
+	Variant      string    `json:"variant"`
+	Source       string    `json:"source"`
+	Healthy      bool      `json:"healthy"`
+	Failures     int       `json:"consecutive_failures"`
+	Successes    int       `json:"consecutive_successes"`
+	LastCheck    time.Time `json:"last_check,omitempty"`
+	LastError    string    `json:"last_error,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// servicePool holds the instances of one service, a round-robin cursor per
+// variant and the percentage of traffic sent to canary instances.
+type servicePool struct {
+	instances     []*Instance
+	next          map[string]uint64
+	canaryPercent int
+}
+
+// serviceEnv lists the canonical backend services with their URL environment
+// variable and default. A variable may hold several comma-separated URLs to
+// run multiple instances. Canary instances come from the matching
+// <NAME>_CANARY_URL variable (e.g. WALLET_CANARY_URL), with the share of
+// traffic they receive in <NAME>_CANARY_PERCENT.
+var serviceEnv = []struct {
+	name, envVar, defaultURL string
+}{
+	{"identity", "IDENTITY_SERVICE_URL", "http://identity-service:8080"},
+	{"ledger", "LEDGER_SERVICE_URL", "http://ledger-service:8081"},
+	{"rbac", "RBAC_SERVICE_URL", "http://rbac-service:8082"},
+	{"transaction", "TRANSACTION_SERVICE_URL", "http://transaction-service:8084"},
+	{"wallet", "WALLET_SERVICE_URL", "http://wallet-service:8083"},
+	{"risk", "RISK_SERVICE_URL", "http://risk-service:8085"},
+	{"simulation", "SIMULATION_SERVICE_URL", "http://simulation-service:8086"},
+	{"cardnetwork", "CARD_NETWORK_SERVICE_URL", "http://cardnetwork-service:8088"},
+}
+
+// ServiceRegistry tracks the instances of every backend service and their
+// health. Requests are balanced round-robin across the healthy instances of
+// the variant they are routed to.
 type ServiceRegistry struct {
-	Identity    string
-	Ledger      string
-	RBAC        string
-	Transaction string
-	Wallet      string
-	Risk        string
-	Simulation  string
+	mu    sync.Mutex
+	pools map[string]*servicePool
+	now   func() time.Time
+	roll  func() int // Returns 0-99 for percentage splits
 }
 
 // NewServiceRegistry creates a new service registry from environment variables.
 func NewServiceRegistry() *ServiceRegistry {
-	return &ServiceRegistry{
-		Identity:    getEnvOrDefault("IDENTITY_SERVICE_URL", "http://identity-service:8080"),
-		Ledger:      getEnvOrDefault("LEDGER_SERVICE_URL", "http://ledger-service:8081"),
-		RBAC:        getEnvOrDefault("RBAC_SERVICE_URL", "http://rbac-service:8082"),
-		Transaction: getEnvOrDefault("TRANSACTION_SERVICE_URL", "http://transaction-service:8084"),
-		Wallet:      getEnvOrDefault("WALLET_SERVICE_URL", "http://wallet-service:8083"),
-		Risk:        getEnvOrDefault("RISK_SERVICE_URL", "http://risk-service:8085"),
-		Simulation:  getEnvOrDefault("SIMULATION_SERVICE_URL", "http://simulation-service:8086"),
+	return loadRegistry("", true)
+}
+
+// NewTenantRegistry creates the registry of a tenant's services from its
+// prefixed variables, e.g. TENANT_DEMO_WALLET_SERVICE_URL for envPrefix
+// "TENANT_DEMO_". There are no default URLs: a service the tenant does not
+// configure has no instances, so its requests fail rather than reach another
+// tenant's data.
+func NewTenantRegistry(envPrefix string) *ServiceRegistry {
+	return loadRegistry(envPrefix, false)
+}
+
+// loadRegistry reads every service's instances and canary split from the
+// environment variables named with envPrefix.
+func loadRegistry(envPrefix string, withDefaults bool) *ServiceRegistry {
+	r := newEmptyRegistry()
+	for _, svc := range serviceEnv {
+		rawURLs := os.Getenv(envPrefix + svc.envVar)
+		if rawURLs == "" && withDefaults {
+			rawURLs = svc.defaultURL
+		}
+		for _, rawURL := range splitURLs(rawURLs) {
+			r.add(svc.name, rawURL, VariantStable, SourceStatic)
+		}
+
+		prefix := envPrefix + strings.TrimSuffix(svc.envVar, "_SERVICE_URL")
+		for _, rawURL := range splitURLs(os.Getenv(prefix + "_CANARY_URL")) {
+			r.add(svc.name, rawURL, VariantCanary, SourceStatic)
+		}
+		if percent, err := strconv.Atoi(os.Getenv(prefix + "_CANARY_PERCENT")); err == nil && percent >= 0 && percent <= 100 {
+			r.pools[svc.name].canaryPercent = percent
+		}
+	}
+	return r
+}
+
+// splitURLs splits a comma-separated URL list, dropping blanks and trailing slashes.
+func splitURLs(raw string) []string {
+	var urls []string
+	for _, rawURL := range strings.Split(raw, ",") {
+		if rawURL = strings.TrimRight(strings.TrimSpace(rawURL), "/"); rawURL != "" {
+			urls = append(urls, rawURL)
+		}
+	}
+	return urls
+}
+
+// newEmptyRegistry creates a registry that knows every service but has no instances.
+func newEmptyRegistry() *ServiceRegistry {
+	r := &ServiceRegistry{
+		pools: make(map[string]*servicePool, len(serviceEnv)),
+		now:   time.Now,
+		roll:  func() int { return rand.IntN(100) },
 	}
+	for _, svc := range serviceEnv {
+		r.pools[svc.name] = &servicePool{next: make(map[string]uint64)}
+	}
+	return r
 }
 
-// ServiceInfo contains URL and routing configuration for a service.
+// ServiceInfo contains routing configuration for a service.
 type ServiceInfo struct {
-	URL     string
-	IsAlias bool // If true, don't strip the service name from path
+	Name    string // Canonical service name (aliases resolve to their owning service)
+	IsAlias bool   // If true, don't strip the service name from path
+}
+
+// serviceRoutes maps the first path segment after /api/v1/ to the service
+// that serves it. Aliases (auth, users, wallets, ...) keep their segment in
+// the proxied path; canonical names are stripped from it.
+var serviceRoutes = []struct {
+	segment, service string
+	alias            bool
+}{
+	{"identity", "identity", false},
+	{"auth", "identity", true},
+	{"users", "identity", true},
+	{"verifications", "identity", true},
+	{"user-admin", "identity", true},
+	{"profile", "identity", true},
+	{"oauth", "identity", true},
+	{"ledger", "ledger", false},
+	{"rbac", "rbac", false},
+	{"transaction", "transaction", false},
+	{"transactions", "transaction", true},
+	{"payment-intents", "transaction", true},
+	{"funding-sources", "transaction", true},
+	{"mandates", "transaction", true},
+	{"wallet", "wallet", false},
+	{"wallets", "wallet", true},
+	{"risk", "risk", false},
+	{"simulation", "simulation", false},
+	{"card-network", "cardnetwork", true},
 }
 
 // GetServiceInfo returns the service configuration for a given service name.
 func (r *ServiceRegistry) GetServiceInfo(serviceName string) (*ServiceInfo, error) {
-	switch serviceName {
-	case "identity":
-		return &ServiceInfo{URL: r.Identity, IsAlias: false}, nil
-	case "auth", "users", "verifications", "user-admin":
-		// "auth", "users", "verifications", "user-admin" are aliases - preserve path segment
-		return &ServiceInfo{URL: r.Identity, IsAlias: true}, nil
-	case "ledger":
-		return &ServiceInfo{URL: r.Ledger, IsAlias: false}, nil
-	case "rbac":
-		return &ServiceInfo{URL: r.RBAC, IsAlias: false}, nil
-	case "transaction":
-		return &ServiceInfo{URL: r.Transaction, IsAlias: false}, nil
-	case "transactions":
-		// "transactions" is alias - preserve path segment
-		return &ServiceInfo{URL: r.Transaction, IsAlias: true}, nil
-	case "wallet":
-		return &ServiceInfo{URL: r.Wallet, IsAlias: false}, nil
-	case "wallets":
-		// "wallets" is alias - preserve path segment
-		return &ServiceInfo{URL: r.Wallet, IsAlias: true}, nil
-	case "risk":
-		return &ServiceInfo{URL: r.Risk, IsAlias: false}, nil
-	case "simulation":
-		return &ServiceInfo{URL: r.Simulation, IsAlias: false}, nil
-	default:
+	info, ok := lookupService(serviceName)
+	if !ok {
 		return nil, fmt.Errorf("unknown service: %s", serviceName)
 	}
+	return info, nil
 }
 
-// GetServiceURL returns the URL for a given service name (for backward compatibility).
+// lookupService returns the service serving a path segment.
+func lookupService(segment string) (*ServiceInfo, bool) {
+	for _, route := range serviceRoutes {
+		if route.segment == segment {
+			return &ServiceInfo{Name: route.service, IsAlias: route.alias}, true
+		}
+	}
+	return nil, false
+}
+
+// Route is a path the gateway proxies and the service behind it.
+type Route struct {
+	// Pattern is a pathmatch pattern for segment routes (/api/v1/wallets/*)
+	// or a regular expression over the path after /api/v1/ for the special
+	// rules checked before them.
+	Pattern string `json:"pattern"`
+	Service string `json:"service"`
+	Alias   bool   `json:"alias,omitempty"`
+	Rule    bool   `json:"rule,omitempty"`
+}
+
+// Routes lists the special routing rules, in the order they are checked,
+// followed by the segment routes.
+func Routes() []Route {
+	routes := make([]Route, 0, len(pathRoutingRules)+len(serviceRoutes))
+	for _, rule := range pathRoutingRules {
+		info, _ := lookupService(rule.service)
+		routes = append(routes, Route{Pattern: rule.pattern.String(), Service: info.Name, Rule: true})
+	}
+	for _, route := range serviceRoutes {
+		routes = append(routes, Route{Pattern: RoutePattern(route.segment), Service: route.service, Alias: route.alias})
+	}
+	return routes
+}
+
+// RoutePattern returns the pattern of the segment route serving segment.
+func RoutePattern(segment string) string {
+	return "/api/v1/" + segment + "/*"
+}
+
+// RouteOf returns the pattern of the segment route that path, a full request
+// path, falls under, or false if no service serves it.
+func RouteOf(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return "", false
+	}
+	segment, _, _ := strings.Cut(rest, "/")
+	if _, ok := lookupService(segment); !ok {
+		return "", false
+	}
+	return RoutePattern(segment), true
+}
+
+// GetServiceURL returns an instance URL for a given service name (for backward compatibility).
 func (r *ServiceRegistry) GetServiceURL(serviceName string) (string, error) {
 	info, err := r.GetServiceInfo(serviceName)
 	if err != nil {
 		return "", err
 	}
-	return info.URL, nil
+	return r.Next(info.Name)
 }
 
-// AllServices returns a map of all registered services.
-func (r *ServiceRegistry) AllServices() map[string]string {
-	return map[string]string{
-		"identity":    r.Identity,
-		"ledger":      r.Ledger,
-		"rbac":        r.RBAC,
-		"transaction": r.Transaction,
-		"wallet":      r.Wallet,
-		"risk":        r.Risk,
-		"simulation":  r.Simulation,
+// Next returns the URL of the next healthy stable instance of a canonical
+// service, rotating round-robin across healthy instances.
+func (r *ServiceRegistry) Next(service string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pools[service]
+	if !ok {
+		return "", fmt.Errorf("unknown service: %s", service)
+	}
+	if u, ok := p.pick(VariantStable); ok {
+		return u, nil
+	}
+	return "", fmt.Errorf("%s: %w", service, ErrNoHealthyInstance)
+}
+
+// Route picks an instance for a request and returns its URL and variant. The
+// CanaryHeader value decides the variant when set; otherwise the service's
+// canary percentage does. A request routed to the canary falls back to stable
+// when no canary instance is healthy.
+func (r *ServiceRegistry) Route(service, canaryHeader string) (string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pools[service]
+	if !ok {
+		return "", "", fmt.Errorf("unknown service: %s", service)
+	}
+
+	wantCanary := p.canaryPercent > 0 && r.roll() < p.canaryPercent
+	if pinned, err := strconv.ParseBool(canaryHeader); err == nil {
+		wantCanary = pinned
+	}
+	if wantCanary {
+		if u, ok := p.pick(VariantCanary); ok {
+			return u, VariantCanary, nil
+		}
+	}
+	if u, ok := p.pick(VariantStable); ok {
+		return u, VariantStable, nil
+	}
+	return "", "", fmt.Errorf("%s: %w", service, ErrNoHealthyInstance)
+}
+
+// pick returns the next healthy instance of a variant. Callers hold r.mu.
+func (p *servicePool) pick(variant string) (string, bool) {
+	n := uint64(len(p.instances))
+	start := p.next[variant]
+	for i := uint64(0); i < n; i++ {
+		inst := p.instances[(start+i)%n]
+		if inst.Healthy && inst.Variant == variant {
+			p.next[variant] = (start + i + 1) % n
+			return inst.URL, true
+		}
 	}
+	return "", false
+}
+
+// Register adds a stable instance to a canonical service. Registering a URL
+// that is already known is a no-op and returns the existing instance.
+func (r *ServiceRegistry) Register(service, rawURL string) (Instance, error) {
+	return r.RegisterVariant(service, rawURL, VariantStable)
+}
+
+// RegisterVariant adds an instance of the given variant to a canonical service.
+func (r *ServiceRegistry) RegisterVariant(service, rawURL, variant string) (Instance, error) {
+	if err := validateInstanceURL(rawURL); err != nil {
+		return Instance{}, err
+	}
+	if variant != VariantStable && variant != VariantCanary {
+		return Instance{}, fmt.Errorf("invalid variant %q: must be %s or %s", variant, VariantStable, VariantCanary)
+	}
+	rawURL = strings.TrimRight(rawURL, "/")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.pools[service]; !ok {
+		return Instance{}, fmt.Errorf("unknown service: %s", service)
+	}
+	return *r.add(service, rawURL, variant, SourceDynamic), nil
+}
+
+// SetCanaryPercent sets the percentage of a service's requests, without a
+// CanaryHeader, that go to its canary instances. Zero sends only pinned
+// requests to the canary.
+func (r *ServiceRegistry) SetCanaryPercent(service string, percent int) error {
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("invalid canary percent %d: must be between 0 and 100", percent)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pools[service]
+	if !ok {
+		return fmt.Errorf("unknown service: %s", service)
+	}
+	p.canaryPercent = percent
+	return nil
+}
+
+// CanaryPercents returns the canary percentage of every service that has a
+// canary instance or a non-zero split.
+func (r *ServiceRegistry) CanaryPercents() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	splits := make(map[string]int)
+	for name, p := range r.pools {
+		if p.canaryPercent > 0 {
+			splits[name] = p.canaryPercent
+			continue
+		}
+		for _, inst := range p.instances {
+			if inst.Variant == VariantCanary {
+				splits[name] = 0
+				break
+			}
+		}
+	}
+	return splits
+}
+
+// Deregister removes an instance from a service. Static instances can be
+// removed too; they return when the gateway restarts.
+func (r *ServiceRegistry) Deregister(service, rawURL string) bool {
+	rawURL = strings.TrimRight(rawURL, "/")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pools[service]
+	if !ok {
+		return false
+	}
+	for i, inst := range p.instances {
+		if inst.URL == rawURL {
+			p.instances = append(p.instances[:i], p.instances[i+1:]...)
+			p.next = make(map[string]uint64)
+			return true
+		}
+	}
+	return false
+}
+
+// add appends an instance unless its URL is already registered. Callers
+// other than the constructor must hold r.mu.
+func (r *ServiceRegistry) add(service, rawURL, variant, source string) *Instance {
+	p := r.pools[service]
+	for _, inst := range p.instances {
+		if inst.URL == rawURL {
+			return inst
+		}
+	}
+
+	// Instances start healthy so traffic flows before the first probe
+	inst := &Instance{
+		URL:          rawURL,
+		Variant:      variant,
+		Source:       source,
+		Healthy:      true,
+		RegisteredAt: r.now(),
+	}
+	p.instances = append(p.instances, inst)
+	return inst
+}
+
+// recordProbe updates an instance with a health probe result. It flips the
+// instance to unhealthy after unhealthyAfter consecutive failures and back to
+// healthy after healthyAfter consecutive successes. Returns whether the
+// health state changed.
+func (r *ServiceRegistry) recordProbe(service, rawURL string, probeErr error, unhealthyAfter, healthyAfter int) (Instance, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.pools[service]
+	if !ok {
+		return Instance{}, false
+	}
+	for _, inst := range p.instances {
+		if inst.URL != rawURL {
+			continue
+		}
+
+		inst.LastCheck = r.now()
+		wasHealthy := inst.Healthy
+		if probeErr != nil {
+			inst.Failures++
+			inst.Successes = 0
+			inst.LastError = probeErr.Error()
+			if inst.Healthy && inst.Failures >= unhealthyAfter {
+				inst.Healthy = false
+			}
+		} else {
+			inst.Successes++
+			inst.Failures = 0
+			inst.LastError = ""
+			if !inst.Healthy && inst.Successes >= healthyAfter {
+				inst.Healthy = true
+			}
+		}
+		return *inst, inst.Healthy != wasHealthy
+	}
+	// Deregistered while the probe was in flight
+	return Instance{}, false
+}
+
+// Services returns a snapshot of every service and its instances.
+func (r *ServiceRegistry) Services() map[string][]Instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	services := make(map[string][]Instance, len(r.pools))
+	for name, p := range r.pools {
+		instances := make([]Instance, len(p.instances))
+		for i, inst := range p.instances {
+			instances[i] = *inst
+		}
+		services[name] = instances
+	}
+	return services
+}
+
+// ServiceNames returns the canonical service names in sorted order.
+func (r *ServiceRegistry) ServiceNames() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.pools))
+	for name := range r.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateInstanceURL checks that a registered URL is an absolute http(s) URL.
+func validateInstanceURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid instance url %q: must be an absolute http or https URL", rawURL)
+	}
+	return nil
 }
 
 // pathRoutingRule defines a special path pattern that routes to a specific service.
@@ -97,13 +505,21 @@ type pathRoutingRule struct {
 
 // pathRoutingRules defines paths that need special routing (checked before default segment matching).
 // These handle cases where nested resources belong to a different service than the parent.
+// Matching requests are forwarded with their full path, like aliases.
 var pathRoutingRules = []pathRoutingRule{
 	// Wallet-related transaction endpoints belong to transaction service
-	{pattern: regexp.MustCompile(`^wallets/[^/]+/transactions$`), service: "transactions"},
+	{pattern: regexp.MustCompile(`^wallets/[^/]+/transactions(/export)?$`), service: "transactions"},
 	{pattern: regexp.MustCompile(`^wallets/[^/]+/spending-summary$`), service: "transactions"},
 	{pattern: regexp.MustCompile(`^wallets/[^/]+/statements/`), service: "transactions"},
-	// Admin transaction endpoints (admin/* normally routes to identity, but transactions go to transaction service)
+	{pattern: regexp.MustCompile(`^wallets/[^/]+/payees(/|$)`), service: "transactions"},
+	{pattern: regexp.MustCompile(`^wallets/[^/]+/sweep-rules(/|$)`), service: "transactions"},
+	{pattern: regexp.MustCompile(`^wallets/[^/]+/auto-top-up(/|$)`), service: "transactions"},
+	{pattern: regexp.MustCompile(`^wallets/[^/]+/payment-intents(/|$)`), service: "transactions"},
+	// Admin transaction and wallet endpoints (admin/* normally routes to identity, but these belong to their own services)
 	{pattern: regexp.MustCompile(`^admin/transactions/`), service: "transactions"},
+	{pattern: regexp.MustCompile(`^admin/wallets(/|$)`), service: "wallets"},
+	// User risk profiles belong to risk service (users/* normally routes to identity)
+	{pattern: regexp.MustCompile(`^users/[^/]+/risk-profile$`), service: "risk"},
 }
 
 // GetServiceByPath checks if the path matches any special routing rules.
@@ -113,17 +529,10 @@ func (r *ServiceRegistry) GetServiceByPath(path string) *ServiceInfo {
 		if rule.pattern.MatchString(path) {
 			info, err := r.GetServiceInfo(rule.service)
 			if err == nil {
+				info.IsAlias = true
 				return info
 			}
 		}
 	}
 	return nil
 }
-
-// getEnvOrDefault returns the value of an environment variable or a default value.
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,334 @@
+package proxy
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceRegistry_MultipleInstancesFromEnv(t *testing.T) {
+	t.Setenv("WALLET_SERVICE_URL", "http://wallet-1:8083, http://wallet-2:8083/")
+
+	r := NewServiceRegistry()
+	instances := r.Services()["wallet"]
+
+	require.Len(t, instances, 2)
+	assert.Equal(t, "http://wallet-1:8083", instances[0].URL)
+	assert.Equal(t, "http://wallet-2:8083", instances[1].URL)
+	assert.Equal(t, SourceStatic, instances[0].Source)
+	assert.True(t, instances[0].Healthy)
+}
+
+func TestServiceRegistry_Next(t *testing.T) {
+	t.Run("round-robin across healthy instances", func(t *testing.T) {
+		r := newEmptyRegistry()
+		for _, u := range []string{"http://a:1", "http://b:1", "http://c:1"} {
+			_, err := r.Register("ledger", u)
+			require.NoError(t, err)
+		}
+
+		var got []string
+		for i := 0; i < 4; i++ {
+			u, err := r.Next("ledger")
+			require.NoError(t, err)
+			got = append(got, u)
+		}
+		assert.Equal(t, []string{"http://a:1", "http://b:1", "http://c:1", "http://a:1"}, got)
+	})
+
+	t.Run("skips unhealthy instances", func(t *testing.T) {
+		r := newEmptyRegistry()
+		_, _ = r.Register("ledger", "http://a:1")
+		_, _ = r.Register("ledger", "http://b:1")
+		r.recordProbe("ledger", "http://a:1", stderrors.New("down"), 1, 1)
+
+		for i := 0; i < 3; i++ {
+			u, err := r.Next("ledger")
+			require.NoError(t, err)
+			assert.Equal(t, "http://b:1", u)
+		}
+	})
+
+	t.Run("fails when no instance is healthy", func(t *testing.T) {
+		r := newEmptyRegistry()
+		_, _ = r.Register("ledger", "http://a:1")
+		r.recordProbe("ledger", "http://a:1", stderrors.New("down"), 1, 1)
+
+		_, err := r.Next("ledger")
+		assert.ErrorIs(t, err, ErrNoHealthyInstance)
+
+		_, err = r.Next("rbac")
+		assert.ErrorIs(t, err, ErrNoHealthyInstance)
+	})
+
+	t.Run("unknown service", func(t *testing.T) {
+		_, err := newEmptyRegistry().Next("billing")
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceRegistry_RecordProbe(t *testing.T) {
+	r := newEmptyRegistry()
+	_, _ = r.Register("risk", "http://risk:8085")
+	down := stderrors.New("connection refused")
+
+	_, changed := r.recordProbe("risk", "http://risk:8085", down, 2, 2)
+	assert.False(t, changed, "one failure is below the threshold")
+
+	inst, changed := r.recordProbe("risk", "http://risk:8085", down, 2, 2)
+	assert.True(t, changed)
+	assert.False(t, inst.Healthy)
+	assert.Equal(t, 2, inst.Failures)
+	assert.Equal(t, "connection refused", inst.LastError)
+
+	_, changed = r.recordProbe("risk", "http://risk:8085", nil, 2, 2)
+	assert.False(t, changed, "one success is below the threshold")
+
+	inst, changed = r.recordProbe("risk", "http://risk:8085", nil, 2, 2)
+	assert.True(t, changed)
+	assert.True(t, inst.Healthy)
+	assert.Empty(t, inst.LastError)
+}
+
+func TestServiceRegistry_RegisterDeregister(t *testing.T) {
+	r := newEmptyRegistry()
+
+	inst, err := r.Register("wallet", "http://wallet-3:8083/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://wallet-3:8083", inst.URL)
+	assert.Equal(t, SourceDynamic, inst.Source)
+
+	_, err = r.Register("wallet", "http://wallet-3:8083")
+	require.NoError(t, err)
+	assert.Len(t, r.Services()["wallet"], 1, "re-registering is a no-op")
+
+	_, err = r.Register("billing", "http://billing:9000")
+	assert.Error(t, err)
+	_, err = r.Register("wallet", "wallet-3:8083")
+	assert.Error(t, err)
+	_, err = r.Register("wallet", "ftp://wallet-3")
+	assert.Error(t, err)
+
+	assert.True(t, r.Deregister("wallet", "http://wallet-3:8083"))
+	assert.False(t, r.Deregister("wallet", "http://wallet-3:8083"))
+	assert.Empty(t, r.Services()["wallet"])
+}
+
+func TestNewServiceRegistry_CanaryFromEnv(t *testing.T) {
+	t.Setenv("WALLET_CANARY_URL", "http://wallet-canary:8083/")
+	t.Setenv("WALLET_CANARY_PERCENT", "10")
+
+	r := NewServiceRegistry()
+	instances := r.Services()["wallet"]
+
+	require.Len(t, instances, 2)
+	assert.Equal(t, VariantStable, instances[0].Variant)
+	assert.Equal(t, "http://wallet-canary:8083", instances[1].URL)
+	assert.Equal(t, VariantCanary, instances[1].Variant)
+	assert.Equal(t, map[string]int{"wallet": 10}, r.CanaryPercents())
+}
+
+func TestServiceRegistry_Route(t *testing.T) {
+	newCanaryRegistry := func(t *testing.T) *ServiceRegistry {
+		r := newEmptyRegistry()
+		_, err := r.Register("wallet", "http://stable:1")
+		require.NoError(t, err)
+		_, err = r.RegisterVariant("wallet", "http://canary:1", VariantCanary)
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("splits by percentage", func(t *testing.T) {
+		r := newCanaryRegistry(t)
+		require.NoError(t, r.SetCanaryPercent("wallet", 25))
+
+		counts := map[string]int{}
+		for i := 0; i < 100; i++ {
+			r.roll = func() int { return i }
+			u, variant, err := r.Route("wallet", "")
+			require.NoError(t, err)
+			counts[variant]++
+			assert.Equal(t, "http://"+variant+":1", u)
+		}
+		assert.Equal(t, map[string]int{VariantCanary: 25, VariantStable: 75}, counts)
+	})
+
+	t.Run("header pins the variant", func(t *testing.T) {
+		r := newCanaryRegistry(t)
+		r.roll = func() int { return 0 }
+
+		_, variant, err := r.Route("wallet", "true")
+		require.NoError(t, err)
+		assert.Equal(t, VariantCanary, variant, "pinned with no split configured")
+
+		require.NoError(t, r.SetCanaryPercent("wallet", 100))
+		_, variant, err = r.Route("wallet", "false")
+		require.NoError(t, err)
+		assert.Equal(t, VariantStable, variant)
+
+		_, variant, err = r.Route("wallet", "maybe")
+		require.NoError(t, err)
+		assert.Equal(t, VariantCanary, variant, "unparseable header falls back to the split")
+	})
+
+	t.Run("unhealthy canary falls back to stable", func(t *testing.T) {
+		r := newCanaryRegistry(t)
+		r.recordProbe("wallet", "http://canary:1", stderrors.New("down"), 1, 1)
+
+		u, variant, err := r.Route("wallet", "true")
+		require.NoError(t, err)
+		assert.Equal(t, VariantStable, variant)
+		assert.Equal(t, "http://stable:1", u)
+	})
+
+	t.Run("canary never serves stable traffic", func(t *testing.T) {
+		r := newCanaryRegistry(t)
+		r.recordProbe("wallet", "http://stable:1", stderrors.New("down"), 1, 1)
+
+		_, _, err := r.Route("wallet", "")
+		assert.ErrorIs(t, err, ErrNoHealthyInstance)
+		_, err = r.Next("wallet")
+		assert.ErrorIs(t, err, ErrNoHealthyInstance)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		r := newEmptyRegistry()
+		assert.Error(t, r.SetCanaryPercent("wallet", 101))
+		assert.Error(t, r.SetCanaryPercent("billing", 10))
+		_, err := r.RegisterVariant("wallet", "http://x:1", "beta")
+		assert.Error(t, err)
+		_, _, err = r.Route("billing", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestServiceRegistry_GetServiceByPath(t *testing.T) {
+	r := newEmptyRegistry()
+
+	info := r.GetServiceByPath("wallets/abc/transactions/export")
+	require.NotNil(t, info)
+	assert.Equal(t, "transaction", info.Name)
+	assert.True(t, info.IsAlias)
+
+	info = r.GetServiceByPath("wallets/abc/payment-intents/order-42")
+	require.NotNil(t, info)
+	assert.Equal(t, "transaction", info.Name)
+
+	info = r.GetServiceByPath("wallets/abc/auto-top-up/executions")
+	require.NotNil(t, info)
+	assert.Equal(t, "transaction", info.Name)
+
+	info = r.GetServiceByPath("admin/wallets")
+	require.NotNil(t, info)
+	assert.Equal(t, "wallet", info.Name)
+
+	info = r.GetServiceByPath("users/abc/risk-profile")
+	require.NotNil(t, info)
+	assert.Equal(t, "risk", info.Name)
+	assert.True(t, info.IsAlias, "rule routes keep the full path")
+
+	assert.Nil(t, r.GetServiceByPath("wallets/abc/balance"))
+	assert.Nil(t, r.GetServiceByPath("users/abc"))
+}
+
+func TestRouteOf(t *testing.T) {
+	route, ok := RouteOf("/api/v1/wallets/abc/balance")
+	assert.True(t, ok)
+	assert.Equal(t, "/api/v1/wallets/*", route)
+
+	_, ok = RouteOf("/api/v1/billing/invoices")
+	assert.False(t, ok)
+	_, ok = RouteOf("/health")
+	assert.False(t, ok)
+
+	// Every listed route resolves to a known service
+	for _, route := range Routes() {
+		assert.NotEmpty(t, route.Service, route.Pattern)
+	}
+}
+
+func TestHealthChecker_CheckAll(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/health", r.URL.Path)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer backend.Close()
+
+	r := newEmptyRegistry()
+	_, err := r.Register("identity", backend.URL)
+	require.NoError(t, err)
+
+	cfg := DefaultHealthCheckConfig()
+	cfg.UnhealthyThreshold = 1
+	cfg.HealthyThreshold = 1
+	checker := NewHealthChecker(r, cfg, logger.NewDefault("test"))
+	ctx := context.Background()
+
+	healthy.Store(false)
+	checker.CheckAll(ctx)
+	_, err = r.Next("identity")
+	assert.ErrorIs(t, err, ErrNoHealthyInstance)
+	assert.Equal(t, "health check returned status 503", r.Services()["identity"][0].LastError)
+
+	healthy.Store(true)
+	checker.CheckAll(ctx)
+	u, err := r.Next("identity")
+	require.NoError(t, err)
+	assert.Equal(t, backend.URL, u)
+}
+
+func TestHealthChecker_StartStop(t *testing.T) {
+	r := newEmptyRegistry()
+	_, _ = r.Register("identity", "http://127.0.0.1:1")
+
+	cfg := DefaultHealthCheckConfig()
+	cfg.Interval = time.Hour
+	cfg.Timeout = 100 * time.Millisecond
+	cfg.UnhealthyThreshold = 1
+	checker := NewHealthChecker(r, cfg, logger.NewDefault("test"))
+
+	checker.Start()
+	assert.Eventually(t, func() bool {
+		return !r.Services()["identity"][0].Healthy
+	}, time.Second, 10*time.Millisecond)
+	checker.Stop()
+
+	// Disabled probing never starts and stops cleanly
+	cfg.Interval = 0
+	disabled := NewHealthChecker(r, cfg, logger.NewDefault("test"))
+	disabled.Start()
+	disabled.Stop()
+}
+
+func TestNewTenantRegistry_NoDefaults(t *testing.T) {
+	t.Setenv("WALLET_SERVICE_URL", "http://wallet:8083")
+	t.Setenv("TENANT_DEMO_WALLET_SERVICE_URL", "http://demo-wallet:8083")
+	t.Setenv("TENANT_DEMO_WALLET_CANARY_URL", "http://demo-wallet-canary:8083")
+
+	r := NewTenantRegistry("TENANT_DEMO_")
+	services := r.Services()
+
+	require.Len(t, services["wallet"], 2)
+	assert.Equal(t, "http://demo-wallet:8083", services["wallet"][0].URL)
+	assert.Equal(t, VariantCanary, services["wallet"][1].Variant)
+
+	// Unconfigured services have no instances instead of falling back to the
+	// default tenant's
+	assert.Empty(t, services["ledger"])
+	_, _, err := r.Route("ledger", "")
+	assert.ErrorIs(t, err, ErrNoHealthyInstance)
+}
@@ -0,0 +1,170 @@
+package respcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/gateway/internal/tenant"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// Middleware serves cached responses for GET requests on cached routes and
+// stores cacheable backend responses. Writes through the gateway to a cached
+// route flush its entries. It must run after authentication so the caller's
+// scope is known. Cache failures fall through to the backend.
+func (c *Cache) Middleware(log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				for _, rule := range c.rulesForWrite(r.URL.Path) {
+					c.invalidate(context.WithoutCancel(r.Context()), rule.Name, log)
+				}
+				return
+			}
+
+			rule := c.match(r.URL.Path)
+			userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+			// Requests pinned to a variant skip the cache so they reach that variant
+			if rule == nil || userID == "" || r.Header.Get("Cache-Control") == "no-cache" || r.Header.Get("X-Canary") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			roles, _ := r.Context().Value(middleware.UserRolesKey).([]string)
+			permissions, _ := r.Context().Value(middleware.UserPermissionsKey).([]string)
+			scope := scopeKey(rule.Scope, userID, roles, permissions)
+			// Tenants run separate services, so their responses never share entries
+			if name := tenant.FromContext(r.Context()); name != tenant.Default {
+				scope = "t:" + name + ":" + scope
+			}
+
+			generation, err := c.generation(r.Context(), rule.Name)
+			if err != nil {
+				log.WithError(err).WithField("rule", rule.Name).Warn("Response cache unavailable")
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := requestKey(rule, generation, scope, r.URL.Path, r.URL.RawQuery)
+
+			if cached, ok := c.lookup(r.Context(), key); ok {
+				if cached.ContentType != "" {
+					w.Header().Set("Content-Type", cached.ContentType)
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(cached.Status)
+				_, _ = w.Write(cached.Body)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			w.Header().Set("X-Cache", "MISS")
+			next.ServeHTTP(rec, r)
+
+			if rec.overflow || !responseCacheable(rec.status, rec.Header(), rec.body.Len()) {
+				return
+			}
+			data, err := json.Marshal(entry{
+				Status:      rec.status,
+				ContentType: rec.Header().Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			})
+			if err != nil {
+				return
+			}
+			if err := c.store.Set(context.WithoutCancel(r.Context()), key, string(data), rule.TTL); err != nil {
+				log.WithError(err).WithField("rule", rule.Name).Warn("Failed to store cached response")
+			}
+		})
+	}
+}
+
+// generation returns the rule's current generation, creating one if needed.
+// Entries are keyed by generation, so replacing it invalidates them all at once.
+func (c *Cache) generation(ctx context.Context, rule string) (string, error) {
+	gen, ok, err := c.store.Get(ctx, generationKey(rule))
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return gen, nil
+	}
+	gen = c.nextGeneration()
+	return gen, c.store.Set(ctx, generationKey(rule), gen, generationTTL)
+}
+
+func (c *Cache) lookup(ctx context.Context, key string) (*entry, bool) {
+	data, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var cached entry
+	if err := json.Unmarshal([]byte(data), &cached); err != nil {
+		return nil, false
+	}
+	return &cached, true
+}
+
+// Invalidate flushes every entry cached under the named rule.
+func (c *Cache) Invalidate(ctx context.Context, rule string) error {
+	return c.store.Set(ctx, generationKey(rule), c.nextGeneration(), generationTTL)
+}
+
+func (c *Cache) invalidate(ctx context.Context, rule string, log *logger.Logger) {
+	if err := c.Invalidate(ctx, rule); err != nil {
+		log.WithError(err).WithField("rule", rule).Warn("Failed to invalidate cached responses")
+		return
+	}
+	log.WithField("rule", rule).Debug("Cached responses invalidated")
+}
+
+// HandleEvent flushes the rules whose InvalidateOn matches eventType.
+func (c *Cache) HandleEvent(ctx context.Context, eventType string, log *logger.Logger) {
+	for _, rule := range c.rulesForEvent(eventType) {
+		c.invalidate(ctx, rule.Name, log)
+	}
+}
+
+// Subscribe registers with the SSE broker and starts a goroutine that
+// invalidates cached responses as domain events arrive. It returns right away;
+// the goroutine runs until the broker closes the client's channel.
+func (c *Cache) Subscribe(broker *events.Broker, log *logger.Logger) {
+	client := events.NewClient("gateway-response-cache")
+	client.Subscribe("all")
+	broker.Register(client)
+
+	go func() {
+		for event := range client.Channel {
+			c.HandleEvent(context.Background(), event.Type, log)
+		}
+	}()
+}
+
+// recorder captures the backend response while passing it through.
+type recorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if !r.overflow {
+		if r.body.Len()+len(b) > MaxBodyBytes {
+			r.overflow = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
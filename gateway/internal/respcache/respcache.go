@@ -0,0 +1,228 @@
+// Package respcache caches backend responses for read-heavy GET routes at the
+// gateway. Caching is opt-in per route. Cache keys include the caller's auth
+// scope so one user's response is never served to another caller who could
+// not have fetched it, and entries are invalidated by TTL or by domain events.
+package respcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/pathmatch"
+	"github.com/1mb-dev/nivomoney/shared/cache"
+)
+
+// Scope decides whose view of a route a cached response represents.
+type Scope string
+
+const (
+	// ScopeUser caches per user. Use for responses that include the caller's own data.
+	ScopeUser Scope = "user"
+	// ScopePermissions shares entries between callers with identical roles and
+	// permissions. Use for reference data guarded only by permission checks.
+	ScopePermissions Scope = "permissions"
+)
+
+const (
+	keyPrefix = "gwcache:"
+	// generationTTL keeps a rule's generation marker around well past any entry TTL.
+	generationTTL = 7 * 24 * time.Hour
+	// MaxTTL bounds how long a response may be cached.
+	MaxTTL = time.Hour
+	// MaxBodyBytes is the largest response body that will be cached.
+	MaxBodyBytes = 1 << 20
+)
+
+// Rule opts a GET route into caching.
+type Rule struct {
+	Name    string
+	Pattern string // Gateway path, {name} matches one segment, trailing /* the rest
+	TTL     time.Duration
+	Scope   Scope
+	// InvalidateOn lists event types, or prefixes ending in ".", that flush every
+	// entry cached under this rule, e.g. "wallet." or "transaction.completed".
+	InvalidateOn []string
+}
+
+// ruleConfig is the JSON form of a Rule, with the TTL in seconds.
+type ruleConfig struct {
+	Name         string   `json:"name"`
+	Pattern      string   `json:"pattern"`
+	TTLSeconds   int      `json:"ttl_seconds"`
+	Scope        Scope    `json:"scope"`
+	InvalidateOn []string `json:"invalidate_on,omitempty"`
+}
+
+// DefaultRules caches reference data that is read far more than it changes.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "rbac-roles", Pattern: "/api/v1/rbac/roles", TTL: 5 * time.Minute, Scope: ScopePermissions},
+		{Name: "rbac-role", Pattern: "/api/v1/rbac/roles/{id}", TTL: 5 * time.Minute, Scope: ScopePermissions},
+		{Name: "rbac-permissions", Pattern: "/api/v1/rbac/permissions", TTL: 10 * time.Minute, Scope: ScopePermissions},
+		{Name: "ledger-account", Pattern: "/api/v1/ledger/accounts/{id}", TTL: 30 * time.Second, Scope: ScopeUser, InvalidateOn: []string{"transaction.", "wallet."}},
+		{Name: "notification-templates", Pattern: "/api/v1/notification/templates", TTL: 10 * time.Minute, Scope: ScopePermissions},
+		{Name: "notification-template", Pattern: "/api/v1/notification/templates/{id}", TTL: 10 * time.Minute, Scope: ScopePermissions},
+	}
+}
+
+// ParseRules decodes rules from JSON, e.g. the GATEWAY_CACHE_RULES variable.
+func ParseRules(data string) ([]Rule, error) {
+	var configs []ruleConfig
+	if err := json.Unmarshal([]byte(data), &configs); err != nil {
+		return nil, fmt.Errorf("invalid cache rules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(configs))
+	for _, c := range configs {
+		rules = append(rules, Rule{
+			Name:         c.Name,
+			Pattern:      c.Pattern,
+			TTL:          time.Duration(c.TTLSeconds) * time.Second,
+			Scope:        c.Scope,
+			InvalidateOn: c.InvalidateOn,
+		})
+	}
+	return rules, validateRules(rules)
+}
+
+func validateRules(rules []Rule) error {
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.Name == "" || seen[rule.Name] {
+			return fmt.Errorf("rules[%d]: name is required and must be unique", i)
+		}
+		seen[rule.Name] = true
+		if !strings.HasPrefix(rule.Pattern, "/api/v1/") {
+			return fmt.Errorf("rules[%d]: pattern must start with /api/v1/", i)
+		}
+		if rule.TTL <= 0 || rule.TTL > MaxTTL {
+			return fmt.Errorf("rules[%d]: ttl must be between 1s and %s", i, MaxTTL)
+		}
+		if rule.Scope != ScopeUser && rule.Scope != ScopePermissions {
+			return fmt.Errorf("rules[%d]: scope must be 'user' or 'permissions'", i)
+		}
+	}
+	return nil
+}
+
+// entry is a cached response.
+type entry struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body"`
+}
+
+// Cache serves and stores responses for the configured rules.
+type Cache struct {
+	store cache.Cache
+	rules []Rule
+	now   func() time.Time
+}
+
+// New creates a response cache backed by store.
+func New(store cache.Cache, rules []Rule) (*Cache, error) {
+	if err := validateRules(rules); err != nil {
+		return nil, err
+	}
+	return &Cache{
+		store: store,
+		rules: append([]Rule(nil), rules...),
+		now:   time.Now,
+	}, nil
+}
+
+// Rules returns the configured rules.
+func (c *Cache) Rules() []Rule {
+	return append([]Rule(nil), c.rules...)
+}
+
+// match returns the rule for a request path, or nil if it is not cached.
+func (c *Cache) match(path string) *Rule {
+	for i := range c.rules {
+		if pathmatch.Match(c.rules[i].Pattern, path) {
+			return &c.rules[i]
+		}
+	}
+	return nil
+}
+
+// rulesForWrite returns the rules whose entries a non-GET request to path may
+// change: those matching the path itself or a parent of it.
+func (c *Cache) rulesForWrite(path string) []Rule {
+	var matched []Rule
+	for _, rule := range c.rules {
+		if pathmatch.Match(rule.Pattern, path) || pathmatch.Match(strings.TrimSuffix(rule.Pattern, "/")+"/*", path) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// rulesForEvent returns the rules an event type invalidates.
+func (c *Cache) rulesForEvent(eventType string) []Rule {
+	var matched []Rule
+	for _, rule := range c.rules {
+		for _, trigger := range rule.InvalidateOn {
+			if trigger == eventType || (strings.HasSuffix(trigger, ".") && strings.HasPrefix(eventType, trigger)) {
+				matched = append(matched, rule)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// scopeKey derives the auth scope component of a cache key.
+func scopeKey(scope Scope, userID string, roles, permissions []string) string {
+	if scope == ScopeUser {
+		return "u:" + userID
+	}
+
+	roles = append([]string(nil), roles...)
+	permissions = append([]string(nil), permissions...)
+	sort.Strings(roles)
+	sort.Strings(permissions)
+	sum := sha256.Sum256([]byte(strings.Join(roles, ",") + "|" + strings.Join(permissions, ",")))
+	return "p:" + hex.EncodeToString(sum[:12])
+}
+
+// requestKey derives the key for a request under a rule generation.
+func requestKey(rule *Rule, generation, scope, path, rawQuery string) string {
+	sum := sha256.Sum256([]byte(path + "?" + normalizeQuery(rawQuery)))
+	return keyPrefix + rule.Name + ":" + generation + ":" + scope + ":" + hex.EncodeToString(sum[:16])
+}
+
+// normalizeQuery sorts query parameters so equivalent URLs share an entry.
+func normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	parts := strings.Split(rawQuery, "&")
+	sort.Strings(parts)
+	return strings.Join(parts, "&")
+}
+
+func generationKey(rule string) string {
+	return keyPrefix + "gen:" + rule
+}
+
+// nextGeneration returns a fresh generation marker.
+func (c *Cache) nextGeneration() string {
+	return strconv.FormatInt(c.now().UnixNano(), 36)
+}
+
+// responseCacheable reports whether a backend response may be stored.
+func responseCacheable(status int, header http.Header, size int) bool {
+	if status != http.StatusOK || size > MaxBodyBytes {
+		return false
+	}
+	return !strings.Contains(strings.ToLower(header.Get("Cache-Control")), "no-store") &&
+		header.Get("Set-Cookie") == ""
+}
@@ -0,0 +1,233 @@
+package respcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// memoryCache is an in-memory cache.Cache that ignores TTLs.
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: make(map[string]string)}
+}
+
+func (m *memoryCache) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.data[key]
+	return v, ok, nil
+}
+
+func (m *memoryCache) Set(_ context.Context, key, value string, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memoryCache) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok, err := m.Get(ctx, key)
+	return ok, err
+}
+
+func (m *memoryCache) Ping(context.Context) error { return nil }
+func (m *memoryCache) Close() error               { return nil }
+
+// backend counts calls and answers with the configured status and body.
+type backend struct {
+	calls   int
+	status  int
+	body    string
+	headers map[string]string
+}
+
+func (b *backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.calls++
+	w.Header().Set("Content-Type", "application/json")
+	for k, v := range b.headers {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(b.status)
+	_, _ = w.Write([]byte(b.body + strconv.Itoa(b.calls)))
+}
+
+func newTestCache(t *testing.T, rules []Rule) *Cache {
+	t.Helper()
+	c, err := New(newMemoryCache(), rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tick := int64(0)
+	c.now = func() time.Time {
+		tick++
+		return time.Unix(0, tick)
+	}
+	return c
+}
+
+func request(method, path, userID string, permissions ...string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if userID != "" {
+		ctx := context.WithValue(req.Context(), middleware.UserIDKey, userID)
+		ctx = context.WithValue(ctx, middleware.UserRolesKey, []string{"user"})
+		ctx = context.WithValue(ctx, middleware.UserPermissionsKey, permissions)
+		req = req.WithContext(ctx)
+	}
+	return req
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddleware(t *testing.T) {
+	log := logger.NewDefault("test")
+
+	t.Run("serves a hit after the first miss", func(t *testing.T) {
+		c := newTestCache(t, DefaultRules())
+		b := &backend{status: http.StatusOK, body: "roles-"}
+		h := c.Middleware(log)(b)
+
+		first := serve(h, request(http.MethodGet, "/api/v1/rbac/roles?b=2&a=1", "u1", "rbac:read"))
+		second := serve(h, request(http.MethodGet, "/api/v1/rbac/roles?a=1&b=2", "u2", "rbac:read"))
+
+		if first.Header().Get("X-Cache") != "MISS" || second.Header().Get("X-Cache") != "HIT" {
+			t.Fatalf("expected MISS then HIT, got %q then %q", first.Header().Get("X-Cache"), second.Header().Get("X-Cache"))
+		}
+		if b.calls != 1 || second.Body.String() != "roles-1" {
+			t.Errorf("expected cached body from one backend call, got %d calls and %q", b.calls, second.Body.String())
+		}
+		if second.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("expected content type to be cached, got %q", second.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("separates entries by auth scope", func(t *testing.T) {
+		c := newTestCache(t, DefaultRules())
+		b := &backend{status: http.StatusOK, body: "account-"}
+		h := c.Middleware(log)(b)
+
+		serve(h, request(http.MethodGet, "/api/v1/ledger/accounts/a1", "u1"))
+		other := serve(h, request(http.MethodGet, "/api/v1/ledger/accounts/a1", "u2"))
+		if other.Header().Get("X-Cache") != "MISS" || b.calls != 2 {
+			t.Errorf("expected user-scoped entries not to be shared, got %q after %d calls", other.Header().Get("X-Cache"), b.calls)
+		}
+
+		rb := &backend{status: http.StatusOK, body: "roles-"}
+		rh := c.Middleware(log)(rb)
+		serve(rh, request(http.MethodGet, "/api/v1/rbac/roles", "u1", "rbac:read"))
+		admin := serve(rh, request(http.MethodGet, "/api/v1/rbac/roles", "u1", "rbac:read", "rbac:write"))
+		if admin.Header().Get("X-Cache") != "MISS" {
+			t.Error("expected callers with different permissions not to share entries")
+		}
+	})
+
+	t.Run("bypasses uncached routes and anonymous requests", func(t *testing.T) {
+		c := newTestCache(t, DefaultRules())
+		b := &backend{status: http.StatusOK, body: "x-"}
+		h := c.Middleware(log)(b)
+
+		for i := 0; i < 2; i++ {
+			serve(h, request(http.MethodGet, "/api/v1/wallet/wallets", "u1"))
+			serve(h, request(http.MethodGet, "/api/v1/rbac/roles", ""))
+		}
+		if b.calls != 4 {
+			t.Errorf("expected every request to reach the backend, got %d calls", b.calls)
+		}
+	})
+
+	t.Run("does not store uncacheable responses", func(t *testing.T) {
+		cases := []*backend{
+			{status: http.StatusNotFound, body: "missing-"},
+			{status: http.StatusOK, body: "private-", headers: map[string]string{"Cache-Control": "no-store"}},
+			{status: http.StatusOK, body: "cookie-", headers: map[string]string{"Set-Cookie": "a=b"}},
+		}
+		for _, b := range cases {
+			c := newTestCache(t, DefaultRules())
+			h := c.Middleware(log)(b)
+			serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1"))
+			serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1"))
+			if b.calls != 2 {
+				t.Errorf("%s: expected response not to be cached, got %d calls", b.body, b.calls)
+			}
+		}
+	})
+
+	t.Run("writes through the gateway invalidate the route", func(t *testing.T) {
+		c := newTestCache(t, DefaultRules())
+		b := &backend{status: http.StatusOK, body: "roles-"}
+		h := c.Middleware(log)(b)
+
+		serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1"))
+		serve(h, request(http.MethodPut, "/api/v1/rbac/roles/r1", "u1"))
+		after := serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1"))
+
+		if after.Header().Get("X-Cache") != "MISS" {
+			t.Error("expected write to a child path to flush the list")
+		}
+	})
+
+	t.Run("events invalidate matching rules only", func(t *testing.T) {
+		c := newTestCache(t, DefaultRules())
+		b := &backend{status: http.StatusOK, body: "x-"}
+		h := c.Middleware(log)(b)
+
+		serve(h, request(http.MethodGet, "/api/v1/ledger/accounts/a1", "u1"))
+		serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1"))
+
+		c.HandleEvent(context.Background(), "transaction.completed", log)
+
+		if got := serve(h, request(http.MethodGet, "/api/v1/ledger/accounts/a1", "u1")); got.Header().Get("X-Cache") != "MISS" {
+			t.Error("expected transaction event to flush ledger accounts")
+		}
+		if got := serve(h, request(http.MethodGet, "/api/v1/rbac/roles", "u1")); got.Header().Get("X-Cache") != "HIT" {
+			t.Error("expected unrelated rule to stay cached")
+		}
+	})
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules(`[{"name":"fx","pattern":"/api/v1/wallet/fx-rates","ttl_seconds":60,"scope":"permissions","invalidate_on":["fx."]}]`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 1 || rules[0].TTL != time.Minute || rules[0].InvalidateOn[0] != "fx." {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	invalid := []string{
+		`{}`,
+		`[{"pattern":"/api/v1/x","ttl_seconds":60,"scope":"user"}]`,
+		`[{"name":"a","pattern":"/internal/v1/x","ttl_seconds":60,"scope":"user"}]`,
+		`[{"name":"a","pattern":"/api/v1/x","ttl_seconds":0,"scope":"user"}]`,
+		`[{"name":"a","pattern":"/api/v1/x","ttl_seconds":7200,"scope":"user"}]`,
+		`[{"name":"a","pattern":"/api/v1/x","ttl_seconds":60,"scope":"everyone"}]`,
+		`[{"name":"a","pattern":"/api/v1/x","ttl_seconds":60,"scope":"user"},{"name":"a","pattern":"/api/v1/y","ttl_seconds":60,"scope":"user"}]`,
+	}
+	for i, data := range invalid {
+		if _, err := ParseRules(data); err == nil {
+			t.Errorf("case %d: expected error for %s", i, data)
+		}
+	}
+}
@@ -2,13 +2,24 @@ package router
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
 	"os"
-	"strings"
 
+	"github.com/1mb-dev/nivomoney/gateway/internal/apispec"
+	"github.com/1mb-dev/nivomoney/gateway/internal/bodylimit"
+	"github.com/1mb-dev/nivomoney/gateway/internal/compress"
+	"github.com/1mb-dev/nivomoney/gateway/internal/cors"
 	"github.com/1mb-dev/nivomoney/gateway/internal/handler"
+	"github.com/1mb-dev/nivomoney/gateway/internal/maintenance"
 	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
 	"github.com/1mb-dev/nivomoney/gateway/internal/proxy"
+	"github.com/1mb-dev/nivomoney/gateway/internal/respcache"
+	"github.com/1mb-dev/nivomoney/gateway/internal/tenant"
+	"github.com/1mb-dev/nivomoney/gateway/internal/traffic"
+	"github.com/1mb-dev/nivomoney/gateway/internal/usage"
+	"github.com/1mb-dev/nivomoney/shared/config"
+	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
 	sharedMiddleware "github.com/1mb-dev/nivomoney/shared/middleware"
@@ -16,29 +27,127 @@ import (
 
 // Router configures HTTP routes for the API Gateway.
 type Router struct {
-	gateway    *proxy.Gateway
-	sseHandler *handler.SSEHandler
-	validator  *middleware.JWTValidator
-	logger     *logger.Logger
-	metrics    *metrics.Collector
+	gateway              *proxy.Gateway
+	sseHandler           *handler.SSEHandler
+	wsHandler            *handler.WebSocketHandler
+	tokenExchangeHandler *handler.TokenExchangeHandler
+	chaosHandler         *handler.ChaosHandler
+	mockHandler          *handler.MockHandler
+	registryHandler      *handler.RegistryHandler
+	responseCache        *respcache.Cache
+	bodyLimiter          *bodylimit.Limiter
+	compressor           *compress.Compressor
+	corsPolicy           *cors.Policy
+	tenantResolver       *tenant.Resolver
+	rateLimiter          *sharedMiddleware.RateLimiter
+	adminRegistry        *proxy.ServiceRegistry
+	maintenance          *maintenance.Store
+	traffic              *traffic.Counters
+	usage                *usage.Recorder
+	apiSpec              *apispec.Spec
+	internalSecret       string
+	validator            *middleware.JWTValidator
+	logger               *logger.Logger
+	metrics              *metrics.Collector
 }
 
 // NewRouter creates a new router with all handlers and middleware.
 func NewRouter(gateway *proxy.Gateway, sseHandler *handler.SSEHandler, log *logger.Logger) *Router {
-	jwtSecret := os.Getenv("JWT_SECRET")
+	jwtSecret := config.Secret("JWT_SECRET")
 	if jwtSecret == "" {
 		panic("JWT_SECRET environment variable is required")
 	}
 
 	return &Router{
-		gateway:    gateway,
-		sseHandler: sseHandler,
-		validator:  middleware.NewJWTValidator(jwtSecret),
-		logger:     log,
-		metrics:    metrics.NewCollector("gateway"),
+		gateway:              gateway,
+		sseHandler:           sseHandler,
+		tokenExchangeHandler: handler.NewTokenExchangeHandler(jwtSecret, log),
+		validator:            middleware.NewJWTValidator(jwtSecret),
+		rateLimiter:          sharedMiddleware.NewRateLimiter(sharedMiddleware.DefaultRateLimitConfig()),
+		apiSpec:              apispec.Gateway(),
+		logger:               log,
+		metrics:              metrics.NewCollector("gateway"),
 	}
 }
 
+// EnableChaos registers the internal chaos control endpoints, protected by the
+// internal service secret. Must only be called outside production. Without a
+// secret the endpoints would accept anyone, so they are not registered.
+func (r *Router) EnableChaos(chaosHandler *handler.ChaosHandler, internalSecret string) error {
+	if internalSecret == "" {
+		return stderrors.New("chaos controls require INTERNAL_SERVICE_SECRET")
+	}
+	r.chaosHandler = chaosHandler
+	r.internalSecret = internalSecret
+	return nil
+}
+
+// EnableMocks registers the internal mock mode control endpoints, protected by
+// the internal service secret. Must only be called outside production.
+func (r *Router) EnableMocks(mockHandler *handler.MockHandler, internalSecret string) {
+	r.mockHandler = mockHandler
+	r.internalSecret = internalSecret
+}
+
+// EnableRegistry registers the internal service registry endpoints, protected
+// by the internal service secret.
+func (r *Router) EnableRegistry(registryHandler *handler.RegistryHandler, internalSecret string) {
+	r.registryHandler = registryHandler
+	r.internalSecret = internalSecret
+}
+
+// EnableAdmin registers the internal admin endpoints, protected by the
+// internal service secret: route listing, maintenance windows, runtime rate
+// limits and per-route traffic counters.
+func (r *Router) EnableAdmin(registry *proxy.ServiceRegistry, internalSecret string) {
+	r.adminRegistry = registry
+	r.maintenance = maintenance.NewStore()
+	r.traffic = traffic.NewCounters()
+	r.internalSecret = internalSecret
+}
+
+// EnableWebSocket serves the event stream over WebSocket at /api/v1/ws.
+func (r *Router) EnableWebSocket(broker *events.Broker) {
+	r.wsHandler = handler.NewWebSocketHandler(broker, r.validator, r.logger)
+}
+
+// EnableCache serves cacheable GET routes from the response cache.
+func (r *Router) EnableCache(c *respcache.Cache) {
+	r.responseCache = c
+}
+
+// EnableUsage records per-user API usage and serves it at /api/v1/admin/usage
+// to callers with the gateway:usage:view permission.
+func (r *Router) EnableUsage(recorder *usage.Recorder) {
+	r.usage = recorder
+}
+
+// EnableBodyLimits enforces request body size limits and body read timeouts.
+func (r *Router) EnableBodyLimits(l *bodylimit.Limiter) {
+	r.bodyLimiter = l
+}
+
+// EnableCompression gzip- or deflate-encodes responses for clients that
+// accept it.
+func (r *Router) EnableCompression(c *compress.Compressor) {
+	r.compressor = c
+}
+
+// EnableCORS answers cross-origin requests from the policy's origins. Without
+// it the gateway allows no origins.
+func (r *Router) EnableCORS(policy cors.Policy) {
+	// Let browser clients opt into canary backends and see which variant answered
+	policy.Config.AllowedHeaders = append(policy.Config.AllowedHeaders, proxy.CanaryHeader, tenant.Header)
+	policy.Config.ExposedHeaders = append(policy.Config.ExposedHeaders, proxy.VariantHeader)
+	r.corsPolicy = &policy
+}
+
+// EnableTenants serves the given tenants alongside the default one, each with
+// its own rate limit. It replaces the gateway-wide rate limit.
+func (r *Router) EnableTenants(resolver *tenant.Resolver) {
+	r.tenantResolver = resolver
+}
+
 // SetupRoutes configures all HTTP routes for the gateway.
 func (r *Router) SetupRoutes() http.Handler {
 	mux := http.NewServeMux()
@@ -53,40 +162,185 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Public routes (no authentication required)
 	// Authentication endpoints - these should go directly to identity service
 	// Support both canonical paths (/api/v1/identity/auth/*) and alias paths (/api/v1/auth/*)
-	mux.HandleFunc("POST /api/v1/identity/auth/register", r.gateway.ProxyRequest)
-	mux.HandleFunc("POST /api/v1/identity/auth/login", r.gateway.ProxyRequest)
-	mux.HandleFunc("POST /api/v1/auth/register", r.gateway.ProxyRequest)
-	mux.HandleFunc("POST /api/v1/auth/login", r.gateway.ProxyRequest)
+	// Described routes are validated against the API spec before proxying
+	publicProxy := r.apiSpec.Middleware(http.HandlerFunc(r.gateway.ProxyRequest))
+	mux.Handle("POST /api/v1/identity/auth/register", publicProxy)
+	mux.Handle("POST /api/v1/identity/auth/login", publicProxy)
+	mux.Handle("POST /api/v1/auth/register", publicProxy)
+	mux.Handle("POST /api/v1/auth/login", publicProxy)
 
 	// Password reset endpoints (public - no auth required)
-	mux.HandleFunc("POST /api/v1/auth/password/forgot", r.gateway.ProxyRequest)
-	mux.HandleFunc("POST /api/v1/auth/password/reset", r.gateway.ProxyRequest)
+	mux.Handle("POST /api/v1/auth/password/forgot", publicProxy)
+	mux.Handle("POST /api/v1/auth/password/reset", publicProxy)
+
+	// Account unlock with the token from the lockout email (public)
+	mux.Handle("POST /api/v1/auth/unlock", publicProxy)
+
+	// OIDC provider endpoints used by third-party clients and browsers. The
+	// identity service authenticates them: clients with their secret and PKCE,
+	// userinfo with its own access tokens. Consent and client registration
+	// stay behind gateway authentication.
+	mux.Handle("GET /api/v1/oauth/.well-known/openid-configuration", publicProxy)
+	mux.Handle("GET /api/v1/oauth/jwks", publicProxy)
+	mux.Handle("GET /api/v1/oauth/authorize", publicProxy)
+	mux.Handle("POST /api/v1/oauth/token", publicProxy)
+	mux.Handle("GET /api/v1/oauth/userinfo", publicProxy)
+	mux.Handle("POST /api/v1/oauth/userinfo", publicProxy)
+
+	// Token exchange (RFC 8693): subject token is carried in the body
+	mux.HandleFunc("POST /api/v1/auth/token/exchange", r.tokenExchangeHandler.HandleExchange)
+
+	// Error code catalog, so clients can discover the codes they branch on
+	mux.HandleFunc("GET /api/v1/errors", handler.HandleErrorCatalog)
+
+	// OpenAPI description of the gateway routes, for client generation
+	mux.HandleFunc("GET /api/v1/openapi.json", r.apiSpec.Handler())
 
 	// SSE endpoints (authentication optional, can subscribe to public events)
 	mux.HandleFunc("GET /api/v1/events", r.sseHandler.HandleEvents)
 	mux.HandleFunc("GET /api/v1/events/stats", r.sseHandler.HandleStats)
 	mux.HandleFunc("POST /api/v1/events/broadcast", r.sseHandler.HandleBroadcast)
 
+	// Per-user wallet stream: balance updates and transaction status changes
+	// for the caller's own wallets
+	mux.Handle("GET /api/v1/stream/wallets", r.validator.Authenticate(http.HandlerFunc(r.sseHandler.HandleWalletStream)))
+
+	// Per-user notification stream: the caller's in-app notifications
+	mux.Handle("GET /api/v1/stream/notifications", r.validator.Authenticate(http.HandlerFunc(r.sseHandler.HandleNotificationStream)))
+
+	// WebSocket event stream; connections authenticate on the handshake or with
+	// their first message, since browsers cannot set headers on WebSocket requests
+	if r.wsHandler != nil {
+		mux.HandleFunc("GET /api/v1/ws", r.wsHandler.HandleWebSocket)
+	}
+
+	// Chaos controls for the simulation service (non-production only)
+	if r.chaosHandler != nil {
+		mux.HandleFunc("GET /internal/v1/chaos", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.chaosHandler.HandleGet))
+		mux.HandleFunc("PUT /internal/v1/chaos", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.chaosHandler.HandleSet))
+		mux.HandleFunc("DELETE /internal/v1/chaos", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.chaosHandler.HandleClear))
+	}
+
+	// Mock mode controls for frontend development (non-production only)
+	if r.mockHandler != nil {
+		mux.HandleFunc("GET /internal/v1/mocks", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.mockHandler.HandleGet))
+		mux.HandleFunc("DELETE /internal/v1/mocks", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.mockHandler.HandleClear))
+		mux.HandleFunc("PUT /internal/v1/mocks/routes", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.mockHandler.HandleEnable))
+		mux.HandleFunc("DELETE /internal/v1/mocks/routes", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.mockHandler.HandleDisable))
+		mux.HandleFunc("POST /internal/v1/mocks/fixtures/reload", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.mockHandler.HandleReload))
+	}
+
+	// Service registry: backend instances register and deregister at runtime
+	if r.registryHandler != nil {
+		mux.HandleFunc("GET /internal/v1/registry", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.registryHandler.HandleList))
+		mux.HandleFunc("POST /internal/v1/registry/instances", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.registryHandler.HandleRegister))
+		mux.HandleFunc("DELETE /internal/v1/registry/instances", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.registryHandler.HandleDeregister))
+		mux.HandleFunc("PUT /internal/v1/registry/canary", sharedMiddleware.InternalAuthFunc(r.internalSecret, r.registryHandler.HandleSetCanary))
+	}
+
+	// Gateway administration: routes, maintenance, rate limits and traffic
+	if r.adminRegistry != nil {
+		admin := handler.NewAdminHandler(r.adminRegistry, r.maintenance, r.traffic, r.rateLimiters(), r.logger)
+		admin.SetCORSPolicy(r.effectiveCORSPolicy())
+		mux.HandleFunc("GET /internal/v1/admin/routes", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleRoutes))
+		mux.HandleFunc("GET /internal/v1/admin/maintenance", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleListMaintenance))
+		mux.HandleFunc("PUT /internal/v1/admin/maintenance", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleDisableRoute))
+		mux.HandleFunc("DELETE /internal/v1/admin/maintenance", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleEnableRoute))
+		mux.HandleFunc("GET /internal/v1/admin/rate-limits", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleGetRateLimits))
+		mux.HandleFunc("PUT /internal/v1/admin/rate-limits", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleSetRateLimit))
+		mux.HandleFunc("GET /internal/v1/admin/traffic", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleTraffic))
+		mux.HandleFunc("DELETE /internal/v1/admin/traffic", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleResetTraffic))
+		mux.HandleFunc("GET /internal/v1/admin/cors", sharedMiddleware.InternalAuthFunc(r.internalSecret, admin.HandleCORSPolicy))
+	}
+
+	// Per-user usage for support and abuse detection
+	if r.usage != nil {
+		usageHandler := handler.NewUsageHandler(r.usage, r.logger)
+		requireUsageView := r.validator.RequirePermission(UsageViewPermission)
+		mux.Handle("GET /api/v1/admin/usage", r.validator.Authenticate(requireUsageView(http.HandlerFunc(usageHandler.HandleUserUsage))))
+		mux.Handle("GET /api/v1/admin/usage/top", r.validator.Authenticate(requireUsageView(http.HandlerFunc(usageHandler.HandleTopUsers))))
+	}
+
 	// Protected routes (authentication required)
 	// All other API routes require authentication
-	authenticatedHandler := r.validator.Authenticate(http.HandlerFunc(r.gateway.ProxyRequest))
+	var proxyHandler http.Handler = http.HandlerFunc(r.gateway.ProxyRequest)
+	if r.responseCache != nil {
+		// Cache runs after authentication so keys carry the caller's scope
+		proxyHandler = r.responseCache.Middleware(r.logger)(proxyHandler)
+	}
+	// Validation runs after authentication so unauthenticated callers learn
+	// nothing about request shapes
+	proxyHandler = r.apiSpec.Middleware(proxyHandler)
+	if r.usage != nil {
+		// Usage runs after authentication so requests are counted per user
+		proxyHandler = r.usage.Middleware(proxyHandler)
+	}
+	authenticatedHandler := r.validator.Authenticate(proxyHandler)
 	mux.Handle("/api/v1/", authenticatedHandler)
 
-	// Apply middleware chain
-	handler := r.applyMiddleware(mux)
+	// Count traffic per route, then apply the middleware chain
+	var handler http.Handler = mux
+	if r.traffic != nil {
+		handler = r.traffic.Middleware(routeLabel(mux))(handler)
+	}
+	handler = r.applyMiddleware(handler)
 
 	return handler
 }
 
+// UsageViewPermission lets support and operations staff read per-user usage.
+const UsageViewPermission = "gateway:usage:view"
+
+// unmatchedRoute labels the traffic of requests no route serves.
+const unmatchedRoute = "unmatched"
+
+// routeLabel names the route serving each request in the traffic counters:
+// its mux pattern, or for proxied requests the service route it falls under.
+func routeLabel(mux *http.ServeMux) func(*http.Request) string {
+	return func(req *http.Request) string {
+		_, pattern := mux.Handler(req)
+		switch pattern {
+		case "":
+			return unmatchedRoute
+		case "/api/v1/":
+			if route, ok := proxy.RouteOf(req.URL.Path); ok {
+				return route
+			}
+			return unmatchedRoute
+		default:
+			return pattern
+		}
+	}
+}
+
+// rateLimiters returns each tenant's rate limiter.
+func (r *Router) rateLimiters() map[string]*sharedMiddleware.RateLimiter {
+	if r.tenantResolver != nil {
+		return r.tenantResolver.Limiters()
+	}
+	return map[string]*sharedMiddleware.RateLimiter{tenant.Default: r.rateLimiter}
+}
+
 // applyMiddleware applies the middleware chain to the handler.
 func (r *Router) applyMiddleware(handler http.Handler) http.Handler {
+	// Answer routes in maintenance before any other work
+	if r.maintenance != nil {
+		handler = r.maintenance.Middleware(handler)
+	}
+
+	// Apply body limits (inside metrics and CORS so rejections are counted and readable)
+	if r.bodyLimiter != nil {
+		handler = r.bodyLimiter.Middleware(handler)
+	}
+
+	// Compress responses, including gateway errors and cached responses
+	if r.compressor != nil {
+		handler = r.compressor.Middleware(handler)
+	}
+
 	// Apply metrics (outermost layer - captures everything)
 	handler = r.metrics.Middleware("gateway")(handler)
 
-	// Apply CORS
-	corsConfig := r.getCORSConfig()
-	handler = sharedMiddleware.CORS(corsConfig)(handler)
-
 	// Apply CSRF protection (production only)
 	// In development, frontend doesn't send CSRF tokens
 	if os.Getenv("ENVIRONMENT") == "production" {
@@ -97,6 +351,8 @@ func (r *Router) applyMiddleware(handler http.Handler) http.Handler {
 				"/api/v1/auth/refresh",
 				"/api/v1/auth/password/forgot",
 				"/api/v1/auth/password/reset",
+				"/api/v1/auth/unlock",
+				"/api/v1/auth/token/exchange",
 				"/api/v1/identity/auth/login",
 				"/api/v1/identity/auth/register",
 				"/api/v1/events",
@@ -104,6 +360,8 @@ func (r *Router) applyMiddleware(handler http.Handler) http.Handler {
 				"/metrics",
 				// Internal service endpoints (auth-protected, no browser CSRF risk)
 				"/api/v1/identity/auth/kyc",
+				"/internal/v1/registry",
+				"/internal/v1/admin",
 				// Transaction endpoints (service-to-service, JWT authenticated)
 				"/api/v1/transaction/transactions/deposit",
 				"/api/v1/transaction/transactions/transfer",
@@ -116,56 +374,41 @@ func (r *Router) applyMiddleware(handler http.Handler) http.Handler {
 		handler = sharedMiddleware.CSRF(csrfConfig)(handler)
 	}
 
-	// Apply request ID generation
-	handler = sharedMiddleware.RequestID()(handler)
-
 	// Apply logging
 	handler = sharedMiddleware.Logging(r.logger)(handler)
 
+	// Apply request ID generation (outside logging so every log line is correlated)
+	handler = sharedMiddleware.RequestID()(handler)
+
 	// Apply panic recovery
 	handler = sharedMiddleware.Recovery(r.logger)(handler)
 
-	// Apply rate limiting (gateway-wide)
-	handler = sharedMiddleware.RateLimit(sharedMiddleware.DefaultRateLimitConfig())(handler)
-
-	return handler
-}
-
-// getCORSConfig returns CORS configuration based on environment.
-// In development, allows localhost origins. In production, uses CORS_ORIGINS env var.
-func (r *Router) getCORSConfig() sharedMiddleware.CORSConfig {
-	config := sharedMiddleware.DefaultCORSConfig()
-
-	// Check for explicit CORS_ORIGINS environment variable
-	if origins := os.Getenv("CORS_ORIGINS"); origins != "" {
-		config.AllowedOrigins = splitAndTrim(origins, ",")
-	} else if os.Getenv("ENVIRONMENT") != "production" {
-		// Development defaults: allow localhost origins
-		config.AllowedOrigins = []string{
-			"http://localhost:3000",
-			"http://localhost:3001",
-			"http://localhost:3002",
-			"http://127.0.0.1:3000",
-			"http://127.0.0.1:3001",
-			"http://127.0.0.1:3002",
-		}
+	// Apply rate limiting, per tenant when tenants are enabled. The resolver
+	// runs first so every inner layer sees the path without its tenant prefix
+	if r.tenantResolver != nil {
+		handler = r.tenantResolver.Middleware(handler)
+	} else {
+		handler = r.rateLimiter.Middleware(handler)
 	}
 
-	// Enable credentials for authenticated requests
-	config.AllowCredentials = true
+	// Apply CORS outside rate limiting, so preflights are answered without
+	// spending the caller's budget and 429 responses stay readable in browsers
+	handler = sharedMiddleware.CORS(r.effectiveCORSPolicy().Config)(handler)
 
-	return config
+	return handler
 }
 
-// splitAndTrim splits a string by separator and trims whitespace from each part.
-func splitAndTrim(s, sep string) []string {
-	parts := make([]string, 0)
-	for _, part := range strings.Split(s, sep) {
-		if trimmed := strings.TrimSpace(part); trimmed != "" {
-			parts = append(parts, trimmed)
-		}
+// effectiveCORSPolicy returns the policy set by EnableCORS, or one allowing
+// no origins.
+func (r *Router) effectiveCORSPolicy() cors.Policy {
+	if r.corsPolicy != nil {
+		return *r.corsPolicy
+	}
+	return cors.Policy{
+		Environment:   os.Getenv("ENVIRONMENT"),
+		OriginsSource: cors.SourceDefault,
+		Config:        sharedMiddleware.DefaultCORSConfig(),
 	}
-	return parts
 }
 
 // healthCheck is the gateway health check endpoint.
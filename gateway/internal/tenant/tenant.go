@@ -0,0 +1,155 @@
+// Package tenant lets one gateway serve several logical environments, such as
+// a public demo and a staging sandbox. A request selects its tenant with a
+// /t/{tenant} path prefix or the X-Nivo-Tenant header; requests without either
+// belong to the default tenant. Each tenant is routed to its own set of
+// backend services, and so its own databases, and is rate limited separately.
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+const (
+	// Default is the tenant of requests that select none. It is served by the
+	// services configured with the plain *_SERVICE_URL variables.
+	Default = "default"
+
+	// Header selects a tenant. The gateway sets it on every proxied request
+	// to the resolved tenant, overwriting any client value.
+	Header = "X-Nivo-Tenant"
+
+	// PathPrefix selects a tenant from the path: /t/demo/api/v1/... is served
+	// as /api/v1/... for the demo tenant. It takes precedence over Header.
+	PathPrefix = "/t/"
+)
+
+// namePattern keeps tenant names usable in paths, env variables and metrics labels.
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,30}$`)
+
+type contextKey struct{}
+
+// Tenant is a logical environment served by the gateway.
+type Tenant struct {
+	Name      string
+	RateLimit middleware.RateLimitConfig
+}
+
+// EnvPrefix returns the prefix of the tenant's environment variables, e.g.
+// TENANT_STAGING_SANDBOX_ for "staging-sandbox".
+func EnvPrefix(name string) string {
+	return "TENANT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+}
+
+// ConfigFromEnv reads the extra tenants listed in GATEWAY_TENANTS (e.g.
+// "demo,staging-sandbox"). Each tenant's rate limit comes from
+// TENANT_<NAME>_RATE_LIMIT_PER_MINUTE and TENANT_<NAME>_RATE_LIMIT_BURST,
+// falling back to the gateway default. Returns nil when no tenants are listed.
+func ConfigFromEnv() ([]Tenant, error) {
+	raw := os.Getenv("GATEWAY_TENANTS")
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var tenants []Tenant
+	seen := map[string]bool{Default: true}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid tenant name %q: use lowercase letters, digits and dashes", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate tenant %q", name)
+		}
+		seen[name] = true
+
+		limit := middleware.DefaultRateLimitConfig()
+		prefix := EnvPrefix(name)
+		if v, err := strconv.Atoi(os.Getenv(prefix + "RATE_LIMIT_PER_MINUTE")); err == nil && v > 0 {
+			limit.RequestsPerMinute = v
+		}
+		if v, err := strconv.Atoi(os.Getenv(prefix + "RATE_LIMIT_BURST")); err == nil && v > 0 {
+			limit.BurstSize = v
+		}
+		tenants = append(tenants, Tenant{Name: name, RateLimit: limit})
+	}
+	return tenants, nil
+}
+
+// FromContext returns the tenant a request was resolved to, or Default.
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextKey{}).(string); ok {
+		return name
+	}
+	return Default
+}
+
+// WithTenant returns a context carrying the tenant name.
+func WithTenant(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// Resolver resolves the tenant of each request and applies its rate limit.
+type Resolver struct {
+	limiters map[string]*middleware.RateLimiter
+}
+
+// NewResolver creates a resolver for the default tenant, limited by
+// defaultLimit, and the given extra tenants.
+func NewResolver(defaultLimit middleware.RateLimitConfig, tenants []Tenant) *Resolver {
+	limiters := map[string]*middleware.RateLimiter{Default: middleware.NewRateLimiter(defaultLimit)}
+	for _, t := range tenants {
+		limiters[t.Name] = middleware.NewRateLimiter(t.RateLimit)
+	}
+	return &Resolver{limiters: limiters}
+}
+
+// Limiters returns each tenant's rate limiter, so limits can be adjusted at
+// runtime.
+func (res *Resolver) Limiters() map[string]*middleware.RateLimiter {
+	return res.limiters
+}
+
+// Middleware resolves the tenant, strips any path prefix and rate limits the
+// request against its tenant's budget, so one environment cannot exhaust
+// another's. Unknown tenants get 404. It must be the outermost middleware so
+// routing and every other layer see the unprefixed path.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	limited := make(map[string]http.Handler, len(res.limiters))
+	for name, limiter := range res.limiters {
+		limited[name] = limiter.Middleware(next)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := Default
+		if rest, ok := strings.CutPrefix(r.URL.Path, PathPrefix); ok {
+			segment, path, _ := strings.Cut(rest, "/")
+			name = segment
+			r.URL.Path = "/" + path
+			r.URL.RawPath = ""
+		} else if header := strings.TrimSpace(r.Header.Get(Header)); header != "" {
+			name = strings.ToLower(header)
+		}
+
+		handler, ok := limited[name]
+		if !ok {
+			response.Error(w, errors.NotFound("unknown tenant: "+name))
+			return
+		}
+
+		r.Header.Set(Header, name)
+		handler.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), name)))
+	})
+}
@@ -0,0 +1,109 @@
+package tenant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("no tenants", func(t *testing.T) {
+		t.Setenv("GATEWAY_TENANTS", "")
+		tenants, err := ConfigFromEnv()
+		require.NoError(t, err)
+		assert.Nil(t, tenants)
+	})
+
+	t.Run("tenants with rate limits", func(t *testing.T) {
+		t.Setenv("GATEWAY_TENANTS", "demo, staging-sandbox")
+		t.Setenv("TENANT_STAGING_SANDBOX_RATE_LIMIT_PER_MINUTE", "600")
+		t.Setenv("TENANT_STAGING_SANDBOX_RATE_LIMIT_BURST", "100")
+
+		tenants, err := ConfigFromEnv()
+		require.NoError(t, err)
+		require.Len(t, tenants, 2)
+		assert.Equal(t, "demo", tenants[0].Name)
+		assert.Equal(t, middleware.DefaultRateLimitConfig().RequestsPerMinute, tenants[0].RateLimit.RequestsPerMinute)
+		assert.Equal(t, "staging-sandbox", tenants[1].Name)
+		assert.Equal(t, 600, tenants[1].RateLimit.RequestsPerMinute)
+		assert.Equal(t, 100, tenants[1].RateLimit.BurstSize)
+	})
+
+	t.Run("rejects invalid and reserved names", func(t *testing.T) {
+		for _, raw := range []string{"Demo", "demo/x", "default", "demo,demo"} {
+			t.Setenv("GATEWAY_TENANTS", raw)
+			_, err := ConfigFromEnv()
+			assert.Error(t, err, raw)
+		}
+	})
+}
+
+func TestResolver_Middleware(t *testing.T) {
+	resolver := NewResolver(middleware.DefaultRateLimitConfig(), []Tenant{
+		{Name: "demo", RateLimit: middleware.DefaultRateLimitConfig()},
+	})
+
+	var gotTenant, gotPath, gotHeader string
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = FromContext(r.Context())
+		gotPath = r.URL.Path
+		gotHeader = r.Header.Get(Header)
+	}))
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+		wantTenant string
+		wantPath   string
+	}{
+		{"default tenant", "/api/v1/wallets", "", http.StatusOK, Default, "/api/v1/wallets"},
+		{"path prefix", "/t/demo/api/v1/wallets", "", http.StatusOK, "demo", "/api/v1/wallets"},
+		{"header", "/api/v1/wallets", "Demo", http.StatusOK, "demo", "/api/v1/wallets"},
+		{"path prefix wins over header", "/t/demo/health", "default", http.StatusOK, "demo", "/health"},
+		{"unknown tenant in path", "/t/prod/api/v1/wallets", "", http.StatusNotFound, "", ""},
+		{"unknown tenant in header", "/api/v1/wallets", "prod", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTenant, gotPath, gotHeader = "", "", ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(Header, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantTenant, gotTenant)
+			assert.Equal(t, tt.wantPath, gotPath)
+			assert.Equal(t, tt.wantTenant, gotHeader)
+		})
+	}
+}
+
+func TestResolver_SeparateRateLimits(t *testing.T) {
+	limit := middleware.RateLimitConfig{RequestsPerMinute: 1, BurstSize: 1}
+	resolver := NewResolver(limit, []Tenant{{Name: "demo", RateLimit: limit}})
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/t/demo/health"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("/t/demo/health"))
+	// The default tenant has its own budget
+	assert.Equal(t, http.StatusOK, serve("/health"))
+}
@@ -0,0 +1,202 @@
+// Package traffic keeps live per-route request counters in the gateway, for
+// operators checking a route's load and error rate without a metrics backend.
+// Counters live in memory and restart from zero with the gateway.
+package traffic
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// window is how far back RequestsLastMinute looks, in one-second buckets.
+const window = 60
+
+// Stats are the counters of one route.
+type Stats struct {
+	Route              string    `json:"route"`
+	Requests           int64     `json:"requests"`
+	ClientErrors       int64     `json:"client_errors"` // 4xx responses
+	ServerErrors       int64     `json:"server_errors"` // 5xx responses
+	InFlight           int64     `json:"in_flight"`
+	RequestsLastMinute int64     `json:"requests_last_minute"`
+	AvgLatencyMs       float64   `json:"avg_latency_ms"`
+	MaxLatencyMs       float64   `json:"max_latency_ms"`
+	LastRequestAt      time.Time `json:"last_request_at"`
+}
+
+// route accumulates the counters of one route.
+type route struct {
+	mu           sync.Mutex
+	stats        Stats
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	buckets      [window]int64
+	bucketSecond [window]int64
+}
+
+// Counters holds the counters of every route seen since the last reset.
+type Counters struct {
+	mu     sync.RWMutex
+	routes map[string]*route
+	since  time.Time
+	now    func() time.Time
+}
+
+// NewCounters creates empty counters.
+func NewCounters() *Counters {
+	c := &Counters{now: time.Now}
+	c.Reset()
+	return c
+}
+
+// Reset drops every route's counters.
+func (c *Counters) Reset() {
+	c.mu.Lock()
+	c.routes = make(map[string]*route)
+	c.since = c.now()
+	c.mu.Unlock()
+}
+
+// Since returns when counting started.
+func (c *Counters) Since() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.since
+}
+
+// get returns the counters of a route, creating them on first use.
+func (c *Counters) get(name string) *route {
+	c.mu.RLock()
+	rt, ok := c.routes[name]
+	c.mu.RUnlock()
+	if ok {
+		return rt
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if rt, ok = c.routes[name]; !ok {
+		rt = &route{stats: Stats{Route: name}}
+		c.routes[name] = rt
+	}
+	return rt
+}
+
+// Start counts a request to a route as in flight. The returned function
+// records its response status once it completes.
+func (c *Counters) Start(name string) func(status int) {
+	rt := c.get(name)
+	start := c.now()
+
+	rt.mu.Lock()
+	rt.stats.InFlight++
+	rt.mu.Unlock()
+
+	return func(status int) {
+		now := c.now()
+		latency := now.Sub(start)
+
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+
+		rt.stats.InFlight--
+		rt.stats.Requests++
+		switch {
+		case status >= 500:
+			rt.stats.ServerErrors++
+		case status >= 400:
+			rt.stats.ClientErrors++
+		}
+		rt.totalLatency += latency
+		rt.maxLatency = max(rt.maxLatency, latency)
+		rt.stats.LastRequestAt = now
+
+		second := now.Unix()
+		i := second % window
+		if rt.bucketSecond[i] != second {
+			rt.bucketSecond[i] = second
+			rt.buckets[i] = 0
+		}
+		rt.buckets[i]++
+	}
+}
+
+// Snapshot returns the counters of every route, busiest first.
+func (c *Counters) Snapshot() []Stats {
+	now := c.now().Unix()
+
+	c.mu.RLock()
+	routes := make([]*route, 0, len(c.routes))
+	for _, rt := range c.routes {
+		routes = append(routes, rt)
+	}
+	c.mu.RUnlock()
+
+	stats := make([]Stats, 0, len(routes))
+	for _, rt := range routes {
+		rt.mu.Lock()
+		s := rt.stats
+		if s.Requests > 0 {
+			s.AvgLatencyMs = milliseconds(rt.totalLatency / time.Duration(s.Requests))
+		}
+		s.MaxLatencyMs = milliseconds(rt.maxLatency)
+		for i, second := range rt.bucketSecond {
+			if now-second < window {
+				s.RequestsLastMinute += rt.buckets[i]
+			}
+		}
+		rt.mu.Unlock()
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Requests != stats[j].Requests {
+			return stats[i].Requests > stats[j].Requests
+		}
+		return stats[i].Route < stats[j].Route
+	})
+	return stats
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Middleware counts each request under the route named by routeOf.
+func (c *Counters) Middleware(routeOf func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := c.Start(routeOf(r))
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() { done(rec.status) }()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// statusRecorder captures the response status.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher to support streaming responses like SSE.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, so
+// WebSocket upgrades can hijack the connection.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
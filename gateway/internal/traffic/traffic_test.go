@@ -0,0 +1,66 @@
+package traffic
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCounters_Middleware(t *testing.T) {
+	c := NewCounters()
+	handler := c.Middleware(func(r *http.Request) string { return r.URL.Path })(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Query().Get("status") {
+			case "404":
+				w.WriteHeader(http.StatusNotFound)
+			case "503":
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+
+	for _, target := range []string{"/wallets", "/wallets?status=404", "/wallets?status=503", "/health"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	stats := c.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 routes, got %+v", stats)
+	}
+	wallets := stats[0]
+	if wallets.Route != "/wallets" || wallets.Requests != 3 || wallets.RequestsLastMinute != 3 {
+		t.Errorf("expected the busiest route first with 3 requests, got %+v", wallets)
+	}
+	if wallets.ClientErrors != 1 || wallets.ServerErrors != 1 || wallets.InFlight != 0 {
+		t.Errorf("unexpected error counts: %+v", wallets)
+	}
+
+	c.Reset()
+	if stats := c.Snapshot(); len(stats) != 0 {
+		t.Errorf("expected no routes after reset, got %+v", stats)
+	}
+}
+
+func TestCounters_InFlightAndLastMinute(t *testing.T) {
+	now := time.Now()
+	c := NewCounters()
+	c.now = func() time.Time { return now }
+
+	done := c.Start("/api/v1/wallets/*")
+	if stats := c.Snapshot(); stats[0].InFlight != 1 || stats[0].Requests != 0 {
+		t.Fatalf("expected one request in flight, got %+v", stats[0])
+	}
+
+	now = now.Add(250 * time.Millisecond)
+	done(http.StatusOK)
+
+	stats := c.Snapshot()[0]
+	if stats.InFlight != 0 || stats.Requests != 1 || stats.AvgLatencyMs != 250 || stats.MaxLatencyMs != 250 {
+		t.Errorf("unexpected counters after completion: %+v", stats)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if stats := c.Snapshot()[0]; stats.RequestsLastMinute != 0 || stats.Requests != 1 {
+		t.Errorf("expected the request to age out of the last minute, got %+v", stats)
+	}
+}
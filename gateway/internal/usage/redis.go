@@ -0,0 +1,150 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix = "gwusage:"
+	// DefaultRetention is how long hourly buckets are kept.
+	DefaultRetention = MaxHours * time.Hour
+
+	fieldRequests     = "requests"
+	fieldClientErrors = "client_errors"
+	fieldServerErrors = "server_errors"
+	endpointPrefix    = "ep:"
+)
+
+// RedisStore keeps hourly usage in Redis: a hash per user and hour, and per
+// hour a sorted set of users by requests and one by errors for top lists.
+type RedisStore struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+// NewRedisStore creates a store that keeps buckets for retention.
+func NewRedisStore(client *redis.Client, retention time.Duration) *RedisStore {
+	return &RedisStore{client: client, retention: retention}
+}
+
+// hourKey formats an hour for keys.
+func hourKey(hour time.Time) string {
+	return hour.UTC().Format("2006010215")
+}
+
+// userKey is the hash of a user's counts in an hour.
+// Format: gwusage:{hour}:u:{user_id}
+func userKey(hour time.Time, userID string) string {
+	return keyPrefix + hourKey(hour) + ":u:" + userID
+}
+
+// rankKey is the sorted set ranking users by metric in an hour.
+// Format: gwusage:{hour}:{metric}
+func rankKey(hour time.Time, metric Metric) string {
+	return keyPrefix + hourKey(hour) + ":" + string(metric)
+}
+
+// Add implements Store.
+func (s *RedisStore) Add(ctx context.Context, hour time.Time, counts map[string]*Counts) error {
+	pipe := s.client.TxPipeline()
+	for userID, c := range counts {
+		key := userKey(hour, userID)
+		pipe.HIncrBy(ctx, key, fieldRequests, c.Requests)
+		if c.ClientErrors > 0 {
+			pipe.HIncrBy(ctx, key, fieldClientErrors, c.ClientErrors)
+		}
+		if c.ServerErrors > 0 {
+			pipe.HIncrBy(ctx, key, fieldServerErrors, c.ServerErrors)
+		}
+		for endpoint, n := range c.Endpoints {
+			pipe.HIncrBy(ctx, key, endpointPrefix+endpoint, n)
+		}
+		pipe.Expire(ctx, key, s.retention)
+
+		pipe.ZIncrBy(ctx, rankKey(hour, MetricRequests), float64(c.Requests), userID)
+		if errs := c.ClientErrors + c.ServerErrors; errs > 0 {
+			pipe.ZIncrBy(ctx, rankKey(hour, MetricErrors), float64(errs), userID)
+		}
+	}
+	pipe.Expire(ctx, rankKey(hour, MetricRequests), s.retention)
+	pipe.Expire(ctx, rankKey(hour, MetricErrors), s.retention)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Get implements Store.
+func (s *RedisStore) Get(ctx context.Context, userID string, hours []time.Time) ([]*Counts, error) {
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(hours))
+	for i, hour := range hours {
+		cmds[i] = pipe.HGetAll(ctx, userKey(hour, userID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	counts := make([]*Counts, len(hours))
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			continue
+		}
+		c := &Counts{}
+		for field, value := range fields {
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch {
+			case field == fieldRequests:
+				c.Requests = n
+			case field == fieldClientErrors:
+				c.ClientErrors = n
+			case field == fieldServerErrors:
+				c.ServerErrors = n
+			case strings.HasPrefix(field, endpointPrefix):
+				if c.Endpoints == nil {
+					c.Endpoints = make(map[string]int64)
+				}
+				c.Endpoints[strings.TrimPrefix(field, endpointPrefix)] = n
+			}
+		}
+		counts[i] = c
+	}
+	return counts, nil
+}
+
+// Top implements Store.
+func (s *RedisStore) Top(ctx context.Context, hours []time.Time, metric Metric, n int) ([]UserCount, error) {
+	keys := make([]string, len(hours))
+	for i, hour := range hours {
+		keys[i] = rankKey(hour, metric)
+	}
+
+	ranked, err := s.client.ZUnionWithScores(ctx, redis.ZStore{Keys: keys}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		a, _ := ranked[i].Member.(string)
+		b, _ := ranked[j].Member.(string)
+		return a < b
+	})
+	top := make([]UserCount, 0, min(n, len(ranked)))
+	for _, z := range ranked[:min(n, len(ranked))] {
+		userID, _ := z.Member.(string)
+		top = append(top, UserCount{UserID: userID, Count: int64(z.Score)})
+	}
+	return top, nil
+}
@@ -0,0 +1,362 @@
+// Package usage tracks per-user API usage at the gateway: request counts,
+// error counts and the endpoints each user called, in hourly buckets. Support
+// uses it to see what a user has been doing, and the busiest and most failing
+// users of an hour point at abuse. Counts are buffered in memory and flushed
+// to a Store shared by the gateway replicas.
+package usage
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxEndpoints bounds the distinct endpoints kept per user and hour; calls
+	// to further endpoints are counted under OtherEndpoint.
+	MaxEndpoints = 50
+	// OtherEndpoint counts calls past MaxEndpoints.
+	OtherEndpoint = "other"
+	// MaxHours bounds how far back a report looks.
+	MaxHours = 30 * 24
+)
+
+// Metric ranks users in a top list.
+type Metric string
+
+const (
+	MetricRequests Metric = "requests"
+	MetricErrors   Metric = "errors" // 4xx and 5xx responses
+)
+
+// Counts are one user's usage in one hour.
+type Counts struct {
+	Requests     int64            `json:"requests"`
+	ClientErrors int64            `json:"client_errors"` // 4xx responses
+	ServerErrors int64            `json:"server_errors"` // 5xx responses
+	Endpoints    map[string]int64 `json:"endpoints,omitempty"`
+}
+
+// add counts a request to endpoint that answered with status.
+func (c *Counts) add(endpoint string, status int) {
+	c.Requests++
+	switch {
+	case status >= 500:
+		c.ServerErrors++
+	case status >= 400:
+		c.ClientErrors++
+	}
+	if c.Endpoints == nil {
+		c.Endpoints = make(map[string]int64)
+	}
+	if _, ok := c.Endpoints[endpoint]; !ok && len(c.Endpoints) >= MaxEndpoints {
+		endpoint = OtherEndpoint
+	}
+	c.Endpoints[endpoint]++
+}
+
+// merge adds other's counts to c.
+func (c *Counts) merge(other *Counts) {
+	c.Requests += other.Requests
+	c.ClientErrors += other.ClientErrors
+	c.ServerErrors += other.ServerErrors
+	for endpoint, n := range other.Endpoints {
+		if c.Endpoints == nil {
+			c.Endpoints = make(map[string]int64)
+		}
+		c.Endpoints[endpoint] += n
+	}
+}
+
+// UserCount is a user's count of a metric over a period.
+type UserCount struct {
+	UserID string `json:"user_id"`
+	Count  int64  `json:"count"`
+}
+
+// Store persists hourly usage. Hours are truncated to the hour in UTC.
+type Store interface {
+	// Add adds counts to each user's bucket for hour.
+	Add(ctx context.Context, hour time.Time, counts map[string]*Counts) error
+	// Get returns a user's counts for each of hours, nil where there are none.
+	Get(ctx context.Context, userID string, hours []time.Time) ([]*Counts, error)
+	// Top returns the n users with the highest metric over hours, highest first.
+	Top(ctx context.Context, hours []time.Time, metric Metric, n int) ([]UserCount, error)
+}
+
+// bucketKey identifies a user's bucket in the pending counts.
+type bucketKey struct {
+	hour   time.Time
+	userID string
+}
+
+// Recorder counts authenticated requests and flushes them to a Store.
+type Recorder struct {
+	store   Store
+	logger  *logger.Logger
+	mu      sync.Mutex
+	pending map[bucketKey]*Counts
+	now     func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRecorder creates a recorder that flushes to store.
+func NewRecorder(store Store, log *logger.Logger) *Recorder {
+	return &Recorder{
+		store:   store,
+		logger:  log,
+		pending: make(map[bucketKey]*Counts),
+		now:     time.Now,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start flushes every interval in the background.
+func (r *Recorder) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.Flush(ctx); err != nil {
+					r.logger.WithError(err).Warn("Failed to flush usage counts")
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends background flushing and flushes what is still pending.
+func (r *Recorder) Stop(ctx context.Context) {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+	if err := r.Flush(ctx); err != nil {
+		r.logger.WithError(err).Warn("Failed to flush usage counts on shutdown")
+	}
+}
+
+// Record counts a request by userID to endpoint that answered with status.
+func (r *Recorder) Record(userID, endpoint string, status int) {
+	key := bucketKey{hour: r.now().UTC().Truncate(time.Hour), userID: userID}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	counts, ok := r.pending[key]
+	if !ok {
+		counts = &Counts{}
+		r.pending[key] = counts
+	}
+	counts.add(endpoint, status)
+}
+
+// Flush writes the pending counts to the store. Counts of a failed flush are
+// kept and retried with the next one.
+func (r *Recorder) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	pending := r.pending
+	r.pending = make(map[bucketKey]*Counts)
+	r.mu.Unlock()
+
+	byHour := make(map[time.Time]map[string]*Counts)
+	for key, counts := range pending {
+		if byHour[key.hour] == nil {
+			byHour[key.hour] = make(map[string]*Counts)
+		}
+		byHour[key.hour][key.userID] = counts
+	}
+
+	var firstErr error
+	for hour, counts := range byHour {
+		if err := r.store.Add(ctx, hour, counts); err != nil {
+			r.restore(hour, counts)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// restore puts counts that failed to flush back into the pending counts.
+func (r *Recorder) restore(hour time.Time, counts map[string]*Counts) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for userID, c := range counts {
+		key := bucketKey{hour: hour, userID: userID}
+		if existing, ok := r.pending[key]; ok {
+			existing.merge(c)
+		} else {
+			r.pending[key] = c
+		}
+	}
+}
+
+// Middleware records each request of an authenticated user. It must run
+// inside authentication, which puts the user in the request context.
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		userID, _ := req.Context().Value(middleware.UserIDKey).(string)
+		if userID == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() { r.Record(userID, Endpoint(req.Method, req.URL.Path), rec.status) }()
+		next.ServeHTTP(rec, req)
+	})
+}
+
+// Endpoint names the endpoint of a request: its method and path, with
+// segments that look like IDs replaced by {id} so calls to different
+// resources count together.
+func Endpoint(method, path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if isID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return method + " " + strings.Join(segments, "/")
+}
+
+// isID reports whether a path segment is a number or a UUID.
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+		return true
+	}
+	return len(segment) == 36 && uuid.Validate(segment) == nil
+}
+
+// Hour is one hour of a user's usage.
+type Hour struct {
+	Hour time.Time `json:"hour"`
+	Counts
+}
+
+// EndpointCount is a user's calls to one endpoint over a report's period.
+type EndpointCount struct {
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+}
+
+// Report is a user's usage over the last hours, including the current one.
+type Report struct {
+	UserID       string          `json:"user_id"`
+	From         time.Time       `json:"from"`
+	To           time.Time       `json:"to"`
+	Requests     int64           `json:"requests"`
+	ClientErrors int64           `json:"client_errors"`
+	ServerErrors int64           `json:"server_errors"`
+	ErrorRate    float64         `json:"error_rate"`
+	Endpoints    []EndpointCount `json:"endpoints"`
+	Hours        []Hour          `json:"hours"` // Hours with requests, oldest first
+}
+
+// Report returns a user's usage over the last hours. Requests made since the
+// last flush are not included yet.
+func (r *Recorder) Report(ctx context.Context, userID string, hours int) (*Report, error) {
+	window := r.window(hours)
+	counts, err := r.store.Get(ctx, userID, window)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		UserID:    userID,
+		From:      window[0],
+		To:        window[len(window)-1].Add(time.Hour),
+		Endpoints: []EndpointCount{},
+		Hours:     []Hour{},
+	}
+	var total Counts
+	for i, c := range counts {
+		if c == nil || c.Requests == 0 {
+			continue
+		}
+		total.merge(c)
+		report.Hours = append(report.Hours, Hour{Hour: window[i], Counts: *c})
+	}
+
+	report.Requests = total.Requests
+	report.ClientErrors = total.ClientErrors
+	report.ServerErrors = total.ServerErrors
+	if total.Requests > 0 {
+		report.ErrorRate = float64(total.ClientErrors+total.ServerErrors) / float64(total.Requests)
+	}
+	for endpoint, n := range total.Endpoints {
+		report.Endpoints = append(report.Endpoints, EndpointCount{Endpoint: endpoint, Requests: n})
+	}
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		if report.Endpoints[i].Requests != report.Endpoints[j].Requests {
+			return report.Endpoints[i].Requests > report.Endpoints[j].Requests
+		}
+		return report.Endpoints[i].Endpoint < report.Endpoints[j].Endpoint
+	})
+
+	return report, nil
+}
+
+// Top returns the n users with the highest metric over the last hours.
+func (r *Recorder) Top(ctx context.Context, metric Metric, hours, n int) ([]UserCount, error) {
+	return r.store.Top(ctx, r.window(hours), metric, n)
+}
+
+// window returns the last hours, oldest first and ending with the current
+// one. hours is clamped to [1, MaxHours].
+func (r *Recorder) window(hours int) []time.Time {
+	hours = min(max(hours, 1), MaxHours)
+	current := r.now().UTC().Truncate(time.Hour)
+	window := make([]time.Time, hours)
+	for i := range window {
+		window[i] = current.Add(-time.Duration(hours-1-i) * time.Hour)
+	}
+	return window
+}
+
+// statusRecorder captures the response status.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher to support streaming responses like SSE.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
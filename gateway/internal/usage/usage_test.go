@@ -0,0 +1,188 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/gateway/internal/middleware"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// memoryStore is an in-memory Store for tests.
+type memoryStore struct {
+	buckets map[bucketKey]*Counts
+	fail    bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{buckets: make(map[bucketKey]*Counts)}
+}
+
+func (s *memoryStore) Add(_ context.Context, hour time.Time, counts map[string]*Counts) error {
+	if s.fail {
+		return errors.New("store unavailable")
+	}
+	for userID, c := range counts {
+		key := bucketKey{hour: hour, userID: userID}
+		if s.buckets[key] == nil {
+			s.buckets[key] = &Counts{}
+		}
+		s.buckets[key].merge(c)
+	}
+	return nil
+}
+
+func (s *memoryStore) Get(_ context.Context, userID string, hours []time.Time) ([]*Counts, error) {
+	counts := make([]*Counts, len(hours))
+	for i, hour := range hours {
+		counts[i] = s.buckets[bucketKey{hour: hour, userID: userID}]
+	}
+	return counts, nil
+}
+
+func (s *memoryStore) Top(_ context.Context, hours []time.Time, metric Metric, n int) ([]UserCount, error) {
+	totals := make(map[string]int64)
+	for _, hour := range hours {
+		for key, c := range s.buckets {
+			if !key.hour.Equal(hour) {
+				continue
+			}
+			if metric == MetricErrors {
+				totals[key.userID] += c.ClientErrors + c.ServerErrors
+			} else {
+				totals[key.userID] += c.Requests
+			}
+		}
+	}
+	top := make([]UserCount, 0, len(totals))
+	for userID, count := range totals {
+		if count > 0 {
+			top = append(top, UserCount{UserID: userID, Count: count})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].Count > top[j].Count })
+	return top[:min(n, len(top))], nil
+}
+
+func newTestRecorder(store Store, now *time.Time) *Recorder {
+	r := NewRecorder(store, logger.NewDefault("test"))
+	r.now = func() time.Time { return *now }
+	return r
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/api/v1/wallets", "GET /api/v1/wallets"},
+		{"GET", "/api/v1/wallets/3f2a6c1e-8b7d-4e2f-9a1b-0c5d7e9f1a2b/balance", "GET /api/v1/wallets/{id}/balance"},
+		{"DELETE", "/api/v1/notification/templates/42", "DELETE /api/v1/notification/templates/{id}"},
+		{"POST", "/api/v1/transaction/transactions/transfer", "POST /api/v1/transaction/transactions/transfer"},
+	}
+	for _, tt := range tests {
+		if got := Endpoint(tt.method, tt.path); got != tt.want {
+			t.Errorf("Endpoint(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRecorder_MiddlewareAndReport(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 15, 0, 0, time.UTC)
+	store := newMemoryStore()
+	r := newTestRecorder(store, &now)
+
+	handler := r.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Query().Get("status") {
+		case "404":
+			w.WriteHeader(http.StatusNotFound)
+		case "502":
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	serve := func(userID, target string) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if userID != "" {
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve("user-1", "/api/v1/wallets")
+	serve("user-1", "/api/v1/wallets/42?status=404")
+	serve("user-2", "/api/v1/wallets")
+	serve("", "/api/v1/wallets")
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	serve("user-1", "/api/v1/wallets/43?status=502")
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	report, err := r.Report(context.Background(), "user-1", 24)
+	if err != nil {
+		t.Fatalf("report failed: %v", err)
+	}
+	if report.Requests != 3 || report.ClientErrors != 1 || report.ServerErrors != 1 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if report.ErrorRate < 0.66 || report.ErrorRate > 0.67 {
+		t.Errorf("expected an error rate of 2/3, got %v", report.ErrorRate)
+	}
+	if len(report.Hours) != 2 || !report.Hours[0].Hour.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected two hours oldest first, got %+v", report.Hours)
+	}
+	if len(report.Endpoints) != 2 || report.Endpoints[0] != (EndpointCount{Endpoint: "GET /api/v1/wallets/{id}", Requests: 2}) {
+		t.Errorf("expected the busiest endpoint first, got %+v", report.Endpoints)
+	}
+
+	top, err := r.Top(context.Background(), MetricRequests, 2, 10)
+	if err != nil {
+		t.Fatalf("top failed: %v", err)
+	}
+	if len(top) != 2 || top[0] != (UserCount{UserID: "user-1", Count: 3}) {
+		t.Errorf("expected user-1 first, got %+v", top)
+	}
+}
+
+func TestRecorder_FlushFailureKeepsCounts(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	store := newMemoryStore()
+	r := newTestRecorder(store, &now)
+
+	store.fail = true
+	r.Record("user-1", "GET /api/v1/wallets", http.StatusOK)
+	if err := r.Flush(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	store.fail = false
+	r.Record("user-1", "GET /api/v1/wallets", http.StatusOK)
+	if err := r.Flush(context.Background()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	report, _ := r.Report(context.Background(), "user-1", 1)
+	if report.Requests != 2 {
+		t.Errorf("expected both requests after the retry, got %d", report.Requests)
+	}
+}
+
+func TestCounts_CapsEndpoints(t *testing.T) {
+	var c Counts
+	for i := 0; i < MaxEndpoints+5; i++ {
+		c.add(fmt.Sprintf("GET /api/v1/route-%c%c", 'a'+i%26, 'a'+i/26), http.StatusOK)
+	}
+	if len(c.Endpoints) != MaxEndpoints+1 || c.Endpoints[OtherEndpoint] != 5 {
+		t.Errorf("expected %d endpoints plus 5 others, got %d endpoints and %d others",
+			MaxEndpoints, len(c.Endpoints), c.Endpoints[OtherEndpoint])
+	}
+}
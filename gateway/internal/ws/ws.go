@@ -0,0 +1,452 @@
+// Package ws implements the parts of the WebSocket protocol (RFC 6455) the
+// gateway needs to serve its event stream: the opening handshake, message
+// framing, and ping/pong/close control frames. Extensions and subprotocol
+// negotiation are not supported.
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by the handshake, not used for security
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MessageType is a frame opcode.
+type MessageType int
+
+// Message types.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+	CloseMessage  MessageType = 8
+	PingMessage   MessageType = 9
+	PongMessage   MessageType = 10
+
+	continuationFrame MessageType = 0
+)
+
+// Close status codes used by the gateway.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	// acceptGUID is appended to the client key to derive Sec-WebSocket-Accept.
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// maxControlPayload is the protocol limit for control frame payloads.
+	maxControlPayload = 125
+	// DefaultReadLimit bounds incoming messages unless SetReadLimit is called.
+	DefaultReadLimit = 64 * 1024
+)
+
+var (
+	// ErrMessageTooBig is returned when a message exceeds the read limit.
+	ErrMessageTooBig = errors.New("ws: message exceeds read limit")
+	// ErrProtocol is returned when the peer violates the framing rules.
+	ErrProtocol = errors.New("ws: protocol error")
+)
+
+// CloseError is returned by ReadMessage when the peer closes the connection.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("ws: connection closed (%d %s)", e.Code, e.Reason)
+}
+
+// Conn is a WebSocket connection. ReadMessage must be called from a single
+// goroutine; writes are safe to call concurrently.
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	client bool // Client connections mask their frames, servers must not
+
+	readLimit    int64
+	writeTimeout time.Duration
+	pongHandler  func()
+
+	writeMu   sync.Mutex
+	closeSent bool
+	closeOnce sync.Once
+}
+
+func newConn(conn net.Conn, reader *bufio.Reader, client bool) *Conn {
+	return &Conn{
+		conn:      conn,
+		reader:    reader,
+		client:    client,
+		readLimit: DefaultReadLimit,
+	}
+}
+
+// Upgrade performs the server side of the opening handshake. On failure it
+// writes an HTTP error response and returns an error.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "WebSocket upgrade requires GET", http.StatusMethodNotAllowed)
+		return nil, errors.New("ws: upgrade requires GET")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "WebSocket upgrade required", http.StatusBadRequest)
+		return nil, errors.New("ws: missing upgrade headers")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errors.New("ws: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("ws: invalid key")
+	}
+
+	netConn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "WebSocket upgrade unsupported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("ws: hijack failed: %w", err)
+	}
+
+	// The server's read/write timeouts were set for plain requests, clear them
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(handshake); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		_ = netConn.Close()
+		return nil, err
+	}
+
+	return newConn(netConn, rw.Reader, false), nil
+}
+
+// Dial opens a client connection to a ws:// URL. The gateway only accepts
+// connections, Dial exists for tests and tooling.
+func Dial(url string, header http.Header) (*Conn, *http.Response, error) {
+	address, path, ok := strings.Cut(strings.TrimPrefix(url, "ws://"), "/")
+	if !ok || !strings.HasPrefix(url, "ws://") {
+		return nil, nil, fmt.Errorf("ws: unsupported url %q", url)
+	}
+
+	netConn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+address+"/"+path, nil)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	if err := req.Write(netConn); err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		_ = netConn.Close()
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		_ = netConn.Close()
+		return nil, resp, fmt.Errorf("ws: handshake failed with status %d", resp.StatusCode)
+	}
+
+	return newConn(netConn, reader, true), resp, nil
+}
+
+// acceptKey derives the Sec-WebSocket-Accept value for a client key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID)) //nolint:gosec // Required by RFC 6455
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerContains reports whether a comma-separated header contains token.
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetReadLimit sets the largest message ReadMessage accepts.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// SetReadDeadline sets the deadline for the next read.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteTimeout bounds every write, so a peer that stops reading cannot
+// block its writers. Zero disables the timeout.
+func (c *Conn) SetWriteTimeout(d time.Duration) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.writeTimeout = d
+}
+
+// SetPongHandler sets a function called for every pong frame, typically to
+// extend the read deadline.
+func (c *Conn) SetPongHandler(h func()) {
+	c.pongHandler = h
+}
+
+// ReadMessage reads the next data message. Ping frames are answered and pong
+// frames passed to the pong handler while waiting. A close frame is echoed
+// and returned as a *CloseError.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		messageType MessageType
+		message     []byte
+	)
+
+	for {
+		fin, opcode, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch opcode {
+		case PingMessage:
+			if err := c.WriteControl(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case PongMessage:
+			if c.pongHandler != nil {
+				c.pongHandler()
+			}
+			continue
+		case CloseMessage:
+			closeErr := &CloseError{Code: CloseNormal}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			_ = c.writeClose(closeErr.Code, "")
+			return 0, nil, closeErr
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+			}
+			messageType = opcode
+		default:
+			return 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+		}
+
+		message = append(message, payload...)
+		if fin {
+			return messageType, message, nil
+		}
+	}
+}
+
+// readFrame reads one frame. buffered is the size of the message assembled
+// so far, used to enforce the read limit before the payload is allocated.
+func (c *Conn) readFrame(buffered int64) (bool, MessageType, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := MessageType(header[0] & 0x0f)
+	masked := header[1]&0x80 != 0
+	length := int64(header[1] & 0x7f)
+
+	// No extensions are negotiated, so the reserved bits must be clear
+	if header[0]&0x70 != 0 || masked == c.client {
+		return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+	}
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+		if length < 0 {
+			return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+		}
+	}
+
+	if opcode >= CloseMessage && (!fin || length > maxControlPayload) {
+		return false, 0, nil, c.fail(CloseProtocolError, ErrProtocol)
+	}
+	if opcode < CloseMessage && buffered+length > c.readLimit {
+		return false, 0, nil, c.fail(CloseMessageTooBig, ErrMessageTooBig)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail sends a close frame with code and returns err.
+func (c *Conn) fail(code int, err error) error {
+	_ = c.writeClose(code, err.Error())
+	return err
+}
+
+// WriteMessage writes a single-frame data message.
+func (c *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("ws: %d is not a data message type", messageType)
+	}
+	return c.writeFrame(messageType, data)
+}
+
+// WriteControl writes a ping, pong or close frame.
+func (c *Conn) WriteControl(messageType MessageType, data []byte) error {
+	if messageType < CloseMessage || len(data) > maxControlPayload {
+		return fmt.Errorf("ws: invalid control frame")
+	}
+	return c.writeFrame(messageType, data)
+}
+
+func (c *Conn) writeFrame(messageType MessageType, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.closeSent {
+		return net.ErrClosed
+	}
+	if messageType == CloseMessage {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(data)+14)
+	frame = append(frame, 0x80|byte(messageType))
+
+	maskBit := byte(0)
+	if c.client {
+		maskBit = 0x80
+	}
+	switch {
+	case len(data) <= 125:
+		frame = append(frame, maskBit|byte(len(data)))
+	case len(data) <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(data)))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(data)))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		start := len(frame)
+		frame = append(frame, data...)
+		maskBytes(mask, frame[start:])
+	} else {
+		frame = append(frame, data...)
+	}
+
+	if c.writeTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// writeClose sends a close frame unless one was already sent.
+func (c *Conn) writeClose(code int, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	return c.WriteControl(CloseMessage, append(payload, reason...))
+}
+
+// Close sends a close frame with code and reason, then closes the connection.
+func (c *Conn) Close(code int, reason string) error {
+	_ = c.writeClose(code, reason)
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// maskBytes applies the client masking key to b in place.
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
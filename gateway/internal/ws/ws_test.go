@@ -0,0 +1,191 @@
+package ws
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// echoServer upgrades every request and echoes data messages until the
+// client closes. The last server-side read error is sent on errs.
+func echoServer(t *testing.T, limit int64) (string, chan error) {
+	t.Helper()
+	errs := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close(CloseNormal, "") }()
+		if limit > 0 {
+			conn.SetReadLimit(limit)
+		}
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws://" + strings.TrimPrefix(srv.URL, "http://") + "/", errs
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey() = %q", got)
+	}
+}
+
+func TestUpgrade_RejectsInvalidHandshake(t *testing.T) {
+	tests := map[string]struct {
+		header http.Header
+		status int
+	}{
+		"plain request":   {http.Header{}, http.StatusBadRequest},
+		"old version":     {http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"8"}}, http.StatusUpgradeRequired},
+		"missing key":     {http.Header{"Connection": {"keep-alive, Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"13"}}, http.StatusBadRequest},
+		"short key":       {http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"13"}, "Sec-Websocket-Key": {"c2hvcnQ="}}, http.StatusBadRequest},
+		"not upgradeable": {http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"13"}, "Sec-Websocket-Key": {"dGhlIHNhbXBsZSBub25jZQ=="}}, http.StatusInternalServerError},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			req.Header = tt.header
+			rec := httptest.NewRecorder()
+
+			if _, err := Upgrade(rec, req); err == nil {
+				t.Fatal("expected upgrade to fail")
+			}
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestConn_RoundTrip(t *testing.T) {
+	url, _ := echoServer(t, 1<<20)
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close(CloseNormal, "") }()
+	conn.SetReadLimit(1 << 20)
+
+	// Exercise the 7-bit, 16-bit and 64-bit length encodings
+	for _, size := range []int{5, 300, 70000} {
+		payload := bytes.Repeat([]byte("a"), size)
+		if err := conn.WriteMessage(TextMessage, payload); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("read failed: %v", err)
+		}
+		if messageType != TextMessage || !bytes.Equal(data, payload) {
+			t.Errorf("size %d: got type %d and %d bytes", size, messageType, len(data))
+		}
+	}
+}
+
+func TestConn_PingPong(t *testing.T) {
+	url, _ := echoServer(t, 0)
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close(CloseNormal, "") }()
+
+	ponged := make(chan struct{}, 1)
+	conn.SetPongHandler(func() { ponged <- struct{}{} })
+
+	if err := conn.WriteControl(PingMessage, []byte("hi")); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+	// The pong arrives while waiting for the echoed message
+	if err := conn.WriteMessage(TextMessage, []byte("after")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	select {
+	case <-ponged:
+	case <-time.After(time.Second):
+		t.Error("expected pong handler to be called")
+	}
+}
+
+func TestConn_CloseHandshake(t *testing.T) {
+	url, errs := echoServer(t, 0)
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+
+	if err := conn.writeClose(CloseGoingAway, "bye"); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	var closeErr *CloseError
+	if err := <-errs; !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway || closeErr.Reason != "bye" {
+		t.Errorf("expected server to see close 1001 bye, got %v", err)
+	}
+	if _, _, err := conn.ReadMessage(); !errors.As(err, &closeErr) || closeErr.Code != CloseGoingAway {
+		t.Errorf("expected close echo, got %v", err)
+	}
+	_ = conn.Close(CloseNormal, "")
+}
+
+func TestConn_ReadLimit(t *testing.T) {
+	url, errs := echoServer(t, 16)
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close(CloseNormal, "") }()
+
+	if err := conn.WriteMessage(TextMessage, bytes.Repeat([]byte("a"), 17)); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if err := <-errs; !errors.Is(err, ErrMessageTooBig) {
+		t.Errorf("expected ErrMessageTooBig, got %v", err)
+	}
+	var closeErr *CloseError
+	if _, _, err := conn.ReadMessage(); !errors.As(err, &closeErr) || closeErr.Code != CloseMessageTooBig {
+		t.Errorf("expected close 1009, got %v", err)
+	}
+}
+
+func TestConn_RejectsUnmaskedClientFrames(t *testing.T) {
+	url, errs := echoServer(t, 0)
+	conn, _, err := Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer func() { _ = conn.Close(CloseNormal, "") }()
+
+	// Pretend to be a server so the frame goes out unmasked
+	conn.client = false
+	if err := conn.WriteMessage(TextMessage, []byte("x")); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if err := <-errs; !errors.Is(err, ErrProtocol) {
+		t.Errorf("expected ErrProtocol, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/handler"
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/repository"
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/server"
+)
+
+func main() {
+	server.Run(server.ServiceConfig{
+		Name: "cardnetwork",
+		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
+			// The wallet service is the card issuer
+			walletClient := service.NewWalletClient(
+				server.GetEnv("WALLET_SERVICE_URL", "http://wallet-service:8083"),
+				server.GetSecret("INTERNAL_SERVICE_SECRET", ""),
+			)
+
+			authRepo := repository.NewAuthorizationRepository(ctx.DB.DB)
+
+			networkConfig := service.DefaultConfig()
+			networkConfig.SettleDelay = time.Duration(server.GetEnvInt("CARD_NETWORK_SETTLE_DELAY_SECONDS", 120)) * time.Second
+			networkService := service.NewCardNetworkService(authRepo, walletClient, networkConfig)
+
+			// Clear due authorizations (settle, reverse, refund)
+			clearingInterval := time.Duration(server.GetEnvInt("CARD_NETWORK_CLEARING_INTERVAL_SECONDS", 30)) * time.Second
+			ctx.Logger.WithField("interval", clearingInterval.String()).Info("Starting card clearing worker...")
+			ctx.Lifecycle.Every("card-clearing", clearingInterval, func(workerCtx context.Context) error {
+				if _, err := networkService.RunClearing(workerCtx); err != nil {
+					return err
+				}
+				return nil
+			})
+
+			// Generate card traffic against random cards
+			if server.GetEnv("CARD_NETWORK_AUTO_TRAFFIC", "true") == "true" {
+				trafficInterval := time.Duration(server.GetEnvInt("CARD_NETWORK_TRAFFIC_INTERVAL_SECONDS", 20)) * time.Second
+				batchSize := server.GetEnvInt("CARD_NETWORK_TRAFFIC_BATCH", 3)
+				ctx.Logger.WithField("interval", trafficInterval.String()).
+					WithField("batch", batchSize).
+					Info("Starting card traffic generator...")
+				ctx.Lifecycle.Every("card-traffic", trafficInterval, func(workerCtx context.Context) error {
+					if _, err := networkService.GenerateTraffic(workerCtx, batchSize); err != nil {
+						return err
+					}
+					return nil
+				})
+			}
+
+			router := handler.NewRouter(networkService)
+
+			return router.SetupRoutes(), nil
+		},
+	})
+}
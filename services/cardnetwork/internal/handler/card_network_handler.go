@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/models"
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// CardNetworkHandler handles HTTP requests for the simulated card network.
+type CardNetworkHandler struct {
+	networkService *service.CardNetworkService
+}
+
+// NewCardNetworkHandler creates a new card network handler.
+func NewCardNetworkHandler(networkService *service.CardNetworkService) *CardNetworkHandler {
+	return &CardNetworkHandler{networkService: networkService}
+}
+
+// ListMerchants handles GET /api/v1/card-network/merchants
+func (h *CardNetworkHandler) ListMerchants(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, service.Merchants())
+}
+
+// ListAuthorizations handles GET /api/v1/card-network/authorizations
+func (h *CardNetworkHandler) ListAuthorizations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := models.AuthorizationStatus(query.Get("status"))
+	switch status {
+	case "", models.AuthorizationApproved, models.AuthorizationDeclined, models.AuthorizationSettled, models.AuthorizationReversed:
+	default:
+		response.Error(w, errors.BadRequest("invalid status filter"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	auths, err := h.networkService.ListAuthorizations(r.Context(), status, query.Get("card_id"), limit, offset)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, auths)
+}
+
+// GetAuthorization handles GET /api/v1/card-network/authorizations/{id}
+func (h *CardNetworkHandler) GetAuthorization(w http.ResponseWriter, r *http.Request) {
+	auth, err := h.networkService.GetAuthorization(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// Authorize handles POST /api/v1/card-network/authorizations
+func (h *CardNetworkHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.AuthorizeRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	auth, authErr := h.networkService.Authorize(r.Context(), &req)
+	if authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	response.Created(w, auth)
+}
+
+// Settle handles POST /api/v1/card-network/authorizations/{id}/settle
+func (h *CardNetworkHandler) Settle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req models.SettleRequest
+	if len(body) > 0 {
+		parsed, parseErr := model.ParseInto[models.SettleRequest](body)
+		if parseErr != nil {
+			response.Error(w, errors.Validation(parseErr.Error()))
+			return
+		}
+		req = parsed
+	}
+
+	auth, settleErr := h.networkService.Settle(r.Context(), r.PathValue("id"), req.Amount)
+	if settleErr != nil {
+		response.Error(w, settleErr)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// Reverse handles POST /api/v1/card-network/authorizations/{id}/reverse
+func (h *CardNetworkHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+	auth, err := h.networkService.Reverse(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// Refund handles POST /api/v1/card-network/authorizations/{id}/refund
+func (h *CardNetworkHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.RefundRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	auth, refundErr := h.networkService.Refund(r.Context(), r.PathValue("id"), req.Amount)
+	if refundErr != nil {
+		response.Error(w, refundErr)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// Simulate handles POST /api/v1/card-network/simulate
+func (h *CardNetworkHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.SimulateRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	result, simErr := h.networkService.GenerateTraffic(r.Context(), req.Count)
+	if simErr != nil {
+		response.Error(w, simErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// RunClearing handles POST /api/v1/card-network/clearing/run
+func (h *CardNetworkHandler) RunClearing(w http.ResponseWriter, r *http.Request) {
+	result, err := h.networkService.RunClearing(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
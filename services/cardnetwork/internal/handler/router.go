@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/config"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/metrics"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// Router handles HTTP routing for the Card Network Service
+type Router struct {
+	networkHandler *CardNetworkHandler
+	metrics        *metrics.Collector
+}
+
+// NewRouter creates a new router
+func NewRouter(networkService *service.CardNetworkService) *Router {
+	return &Router{
+		networkHandler: NewCardNetworkHandler(networkService),
+		metrics:        metrics.NewCollector("cardnetwork"),
+	}
+}
+
+// SetupRoutes sets up all HTTP routes
+func (r *Router) SetupRoutes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Health check endpoint
+	mux.HandleFunc("GET /health", r.healthCheck)
+
+	// Metrics endpoint
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	authConfig := middleware.AuthConfig{
+		JWTSecret: config.Secret("JWT_SECRET"),
+	}
+	jwtAuth := middleware.Auth(authConfig)
+	readPerm := middleware.RequirePermission("cardnetwork:authorization:read")
+	managePerm := middleware.RequirePermission("cardnetwork:authorization:manage")
+
+	// Read endpoints
+	mux.Handle("GET /api/v1/card-network/merchants", jwtAuth(readPerm(http.HandlerFunc(r.networkHandler.ListMerchants))))
+	mux.Handle("GET /api/v1/card-network/authorizations", jwtAuth(readPerm(http.HandlerFunc(r.networkHandler.ListAuthorizations))))
+	mux.Handle("GET /api/v1/card-network/authorizations/{id}", jwtAuth(readPerm(http.HandlerFunc(r.networkHandler.GetAuthorization))))
+
+	// Authorization and clearing endpoints
+	mux.Handle("POST /api/v1/card-network/authorizations", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.Authorize))))
+	mux.Handle("POST /api/v1/card-network/authorizations/{id}/settle", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.Settle))))
+	mux.Handle("POST /api/v1/card-network/authorizations/{id}/reverse", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.Reverse))))
+	mux.Handle("POST /api/v1/card-network/authorizations/{id}/refund", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.Refund))))
+	mux.Handle("POST /api/v1/card-network/simulate", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.Simulate))))
+	mux.Handle("POST /api/v1/card-network/clearing/run", jwtAuth(managePerm(http.HandlerFunc(r.networkHandler.RunClearing))))
+
+	// Create logger for middleware
+	log := logger.NewDefault("cardnetwork")
+
+	// Apply middleware using Chain
+	handler := middleware.Chain(metrics.RecordRoute(mux),
+		r.metrics.Middleware("cardnetwork"),
+		middleware.RequestID(),
+		middleware.Logging(log),
+	)
+
+	return handler
+}
+
+// healthCheck handles health check requests
+func (r *Router) healthCheck(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(`{"service":"cardnetwork","status":"healthy","version":"1.0.0"}`))
+}
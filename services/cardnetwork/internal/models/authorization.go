@@ -0,0 +1,101 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// AuthorizationStatus represents where a network authorization is in its lifecycle.
+type AuthorizationStatus string
+
+const (
+	AuthorizationApproved AuthorizationStatus = "approved" // Issuer approved; awaiting clearing
+	AuthorizationDeclined AuthorizationStatus = "declined" // Issuer declined
+	AuthorizationSettled  AuthorizationStatus = "settled"  // Cleared; may be partially refunded
+	AuthorizationReversed AuthorizationStatus = "reversed" // Merchant voided before clearing
+)
+
+// RefundStatus represents where a network refund is in its lifecycle.
+type RefundStatus string
+
+const (
+	RefundPending   RefundStatus = "pending"   // Recorded; not yet confirmed by the issuer
+	RefundCompleted RefundStatus = "completed" // Credited by the issuer
+	RefundFailed    RefundStatus = "failed"    // Rejected by the issuer
+)
+
+// Merchant is a simulated card acceptor.
+type Merchant struct {
+	Name      string `json:"name"`
+	MCC       string `json:"mcc"`
+	MinAmount int64  `json:"min_amount"` // paise
+	MaxAmount int64  `json:"max_amount"` // paise
+}
+
+// NetworkAuthorization is an authorization the network sent to the issuer.
+type NetworkAuthorization struct {
+	ID                    string              `json:"id"`
+	CardID                string              `json:"card_id"`
+	WalletAuthorizationID *string             `json:"wallet_authorization_id,omitempty"`
+	MerchantName          string              `json:"merchant_name"`
+	MCC                   string              `json:"mcc"`
+	Amount                int64               `json:"amount"` // paise
+	Status                AuthorizationStatus `json:"status"`
+	DeclineReason         *string             `json:"decline_reason,omitempty"`
+	SettledAmount         int64               `json:"settled_amount"`
+	RefundedAmount        int64               `json:"refunded_amount"`
+	SettleAfter           *models.Timestamp   `json:"settle_after,omitempty"`
+	CreatedAt             models.Timestamp    `json:"created_at"`
+	UpdatedAt             models.Timestamp    `json:"updated_at"`
+}
+
+// NetworkRefund is a refund the network sent to the issuer. Its ID is the
+// issuer's idempotency key, so a refund retried after an unclear outcome is
+// not credited twice.
+type NetworkRefund struct {
+	ID              string           `json:"id"`
+	AuthorizationID string           `json:"authorization_id"`
+	Amount          int64            `json:"amount"` // paise
+	Status          RefundStatus     `json:"status"`
+	CreatedAt       models.Timestamp `json:"created_at"`
+	UpdatedAt       models.Timestamp `json:"updated_at"`
+}
+
+// AuthorizeRequest submits a single authorization for a card.
+// Merchant fields are optional; a random catalog merchant is used when omitted.
+type AuthorizeRequest struct {
+	CardID       string `json:"card_id" validate:"required,uuid"`
+	Amount       int64  `json:"amount" validate:"required,gt=0"`
+	MerchantName string `json:"merchant_name,omitempty" validate:"omitempty,min=1,max=100"`
+	MCC          string `json:"mcc,omitempty" validate:"omitempty,len=4"`
+}
+
+// SettleRequest clears an approved authorization. Zero settles the full amount.
+type SettleRequest struct {
+	Amount int64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+// RefundRequest returns part or all of a settled amount to the cardholder.
+type RefundRequest struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+}
+
+// SimulateRequest generates a burst of authorizations against random cards.
+type SimulateRequest struct {
+	Count int `json:"count" validate:"required,gt=0,lte=100"`
+}
+
+// SimulateResult summarizes a burst of generated traffic.
+type SimulateResult struct {
+	Attempted int `json:"attempted"`
+	Approved  int `json:"approved"`
+	Declined  int `json:"declined"`
+	Failed    int `json:"failed"`
+}
+
+// ClearingResult summarizes one clearing run.
+type ClearingResult struct {
+	Settled  int `json:"settled"`
+	Reversed int `json:"reversed"`
+	Refunded int `json:"refunded"`
+	Failed   int `json:"failed"`
+}
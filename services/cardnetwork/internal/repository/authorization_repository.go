@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// AuthorizationRepository stores the network's view of authorizations it
+// sent to the issuer. The wallet service remains the source of truth for
+// balances; this table records what the network asked for and the outcome.
+type AuthorizationRepository struct {
+	db *sql.DB
+}
+
+// NewAuthorizationRepository creates a new authorization repository.
+func NewAuthorizationRepository(db *sql.DB) *AuthorizationRepository {
+	return &AuthorizationRepository{db: db}
+}
+
+const authorizationColumns = `
+	id, card_id, wallet_authorization_id, merchant_name, mcc, amount, status,
+	decline_reason, settled_amount, refunded_amount, settle_after, created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAuthorization(row rowScanner) (*models.NetworkAuthorization, error) {
+	auth := &models.NetworkAuthorization{}
+	err := row.Scan(
+		&auth.ID,
+		&auth.CardID,
+		&auth.WalletAuthorizationID,
+		&auth.MerchantName,
+		&auth.MCC,
+		&auth.Amount,
+		&auth.Status,
+		&auth.DeclineReason,
+		&auth.SettledAmount,
+		&auth.RefundedAmount,
+		&auth.SettleAfter,
+		&auth.CreatedAt,
+		&auth.UpdatedAt,
+	)
+	return auth, err
+}
+
+// Create records an authorization and its issuer decision.
+func (r *AuthorizationRepository) Create(ctx context.Context, auth *models.NetworkAuthorization) *errors.Error {
+	created, err := scanAuthorization(r.db.QueryRowContext(ctx, `
+		INSERT INTO network_authorizations
+			(card_id, wallet_authorization_id, merchant_name, mcc, amount, status, decline_reason, settle_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+authorizationColumns,
+		auth.CardID, auth.WalletAuthorizationID, auth.MerchantName, auth.MCC,
+		auth.Amount, auth.Status, auth.DeclineReason, auth.SettleAfter,
+	))
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create network authorization")
+	}
+
+	*auth = *created
+	return nil
+}
+
+// GetByID retrieves a network authorization.
+func (r *AuthorizationRepository) GetByID(ctx context.Context, id string) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := scanAuthorization(r.db.QueryRowContext(ctx,
+		`SELECT `+authorizationColumns+` FROM network_authorizations WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("network authorization", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get network authorization")
+	}
+	return auth, nil
+}
+
+// List returns authorizations, newest first, optionally filtered by status and card.
+func (r *AuthorizationRepository) List(ctx context.Context, status models.AuthorizationStatus, cardID string, limit, offset int) ([]*models.NetworkAuthorization, *errors.Error) {
+	query := `SELECT ` + authorizationColumns + ` FROM network_authorizations WHERE 1=1`
+	args := []interface{}{}
+
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if cardID != "" {
+		args = append(args, cardID)
+		query += fmt.Sprintf(" AND card_id = $%d", len(args))
+	}
+
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	return r.query(ctx, query, args...)
+}
+
+// ListDueForClearing returns approved authorizations whose settle_after has passed.
+func (r *AuthorizationRepository) ListDueForClearing(ctx context.Context, limit int) ([]*models.NetworkAuthorization, *errors.Error) {
+	return r.query(ctx, `
+		SELECT `+authorizationColumns+`
+		FROM network_authorizations
+		WHERE status = 'approved' AND settle_after <= NOW()
+		ORDER BY settle_after
+		LIMIT $1
+	`, limit)
+}
+
+// ListRefundable returns a random sample of settled authorizations with
+// amount left to refund.
+func (r *AuthorizationRepository) ListRefundable(ctx context.Context, limit int) ([]*models.NetworkAuthorization, *errors.Error) {
+	return r.query(ctx, `
+		SELECT `+authorizationColumns+`
+		FROM network_authorizations
+		WHERE status = 'settled' AND refunded_amount < settled_amount
+		ORDER BY random()
+		LIMIT $1
+	`, limit)
+}
+
+// ApplyIssuerState mirrors the issuer's clearing state onto an authorization.
+func (r *AuthorizationRepository) ApplyIssuerState(ctx context.Context, id string, status models.AuthorizationStatus, settledAmount, refundedAmount int64) (*models.NetworkAuthorization, *errors.Error) {
+	return r.update(ctx, `
+		UPDATE network_authorizations
+		SET status = $2, settled_amount = $3, refunded_amount = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+authorizationColumns, id, status, settledAmount, refundedAmount)
+}
+
+func (r *AuthorizationRepository) update(ctx context.Context, query string, args ...interface{}) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := scanAuthorization(r.db.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("network authorization", fmt.Sprint(args[0]))
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to update network authorization")
+	}
+	return auth, nil
+}
+
+func (r *AuthorizationRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.NetworkAuthorization, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list network authorizations")
+	}
+	defer func() { _ = rows.Close() }()
+
+	auths := make([]*models.NetworkAuthorization, 0)
+	for rows.Next() {
+		auth, err := scanAuthorization(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan network authorization")
+		}
+		auths = append(auths, auth)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating network authorizations")
+	}
+	return auths, nil
+}
+
+const refundColumns = `id, authorization_id, amount, status, created_at, updated_at`
+
+func scanRefund(row rowScanner) (*models.NetworkRefund, error) {
+	refund := &models.NetworkRefund{}
+	err := row.Scan(
+		&refund.ID,
+		&refund.AuthorizationID,
+		&refund.Amount,
+		&refund.Status,
+		&refund.CreatedAt,
+		&refund.UpdatedAt,
+	)
+	return refund, err
+}
+
+// CreateRefund records a pending refund before it is sent to the issuer.
+func (r *AuthorizationRepository) CreateRefund(ctx context.Context, authorizationID string, amount int64) (*models.NetworkRefund, *errors.Error) {
+	refund, err := scanRefund(r.db.QueryRowContext(ctx, `
+		INSERT INTO network_refunds (authorization_id, amount)
+		VALUES ($1, $2)
+		RETURNING `+refundColumns, authorizationID, amount))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to create network refund")
+	}
+	return refund, nil
+}
+
+// ListPendingRefunds returns refunds the issuer has not confirmed or
+// rejected yet, oldest first.
+func (r *AuthorizationRepository) ListPendingRefunds(ctx context.Context, limit int) ([]*models.NetworkRefund, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+refundColumns+`
+		FROM network_refunds
+		WHERE status = 'pending'
+		ORDER BY created_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list network refunds")
+	}
+	defer func() { _ = rows.Close() }()
+
+	refunds := make([]*models.NetworkRefund, 0)
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan network refund")
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating network refunds")
+	}
+	return refunds, nil
+}
+
+// SetRefundStatus records the issuer's outcome for a pending refund.
+func (r *AuthorizationRepository) SetRefundStatus(ctx context.Context, id string, status models.RefundStatus) *errors.Error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE network_refunds
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, status); err != nil {
+		return errors.DatabaseWrap(err, "failed to update network refund")
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/models"
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// clearingBatchSize caps how many authorizations one clearing run processes.
+const clearingBatchSize = 50
+
+// Config controls how the network clears the authorizations it generates.
+type Config struct {
+	SettleDelay  time.Duration // How long an approval stays open before clearing
+	ReversalRate float64       // Fraction of due approvals voided instead of settled
+	RefundRate   float64       // Chance per settlement that a past settlement is refunded
+}
+
+// DefaultConfig returns clearing settings suited to a live demo.
+func DefaultConfig() Config {
+	return Config{
+		SettleDelay:  2 * time.Minute,
+		ReversalRate: 0.05,
+		RefundRate:   0.1,
+	}
+}
+
+// CardNetworkService simulates a card network: it sends authorizations to the
+// issuer (wallet service) and later clears them.
+type CardNetworkService struct {
+	repo   *repository.AuthorizationRepository
+	wallet *WalletClient
+	config Config
+	logger *logger.Logger
+}
+
+// NewCardNetworkService creates a new card network service.
+func NewCardNetworkService(repo *repository.AuthorizationRepository, wallet *WalletClient, config Config) *CardNetworkService {
+	return &CardNetworkService{
+		repo:   repo,
+		wallet: wallet,
+		config: config,
+		logger: logger.NewDefault("cardnetwork"),
+	}
+}
+
+// Authorize sends a single authorization to the issuer and records the decision.
+func (s *CardNetworkService) Authorize(ctx context.Context, req *models.AuthorizeRequest) (*models.NetworkAuthorization, *errors.Error) {
+	merchant := randomMerchant()
+	if req.MerchantName != "" {
+		merchant.Name = req.MerchantName
+	}
+	if req.MCC != "" {
+		merchant.MCC = req.MCC
+	}
+
+	return s.authorize(ctx, req.CardID, merchant, req.Amount)
+}
+
+func (s *CardNetworkService) authorize(ctx context.Context, cardID string, merchant models.Merchant, amount int64) (*models.NetworkAuthorization, *errors.Error) {
+	decision, err := s.wallet.Authorize(ctx, cardID, IssuerAuthorizationRequest{
+		Amount:       amount,
+		MerchantName: merchant.Name,
+		MCC:          merchant.MCC,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &models.NetworkAuthorization{
+		CardID:       cardID,
+		MerchantName: merchant.Name,
+		MCC:          merchant.MCC,
+		Amount:       amount,
+		Status:       models.AuthorizationDeclined,
+	}
+	if decision.Approved {
+		settleAfter := sharedModels.NewTimestamp(time.Now().Add(s.config.SettleDelay))
+		auth.Status = models.AuthorizationApproved
+		auth.WalletAuthorizationID = &decision.AuthorizationID
+		auth.SettleAfter = &settleAfter
+	} else {
+		reason := decision.DeclineReason
+		auth.DeclineReason = &reason
+	}
+
+	if err := s.repo.Create(ctx, auth); err != nil {
+		// The issuer already holds funds for an approval; release them so the
+		// hold is not orphaned.
+		if decision.Approved {
+			if _, revErr := s.wallet.Reverse(ctx, decision.AuthorizationID); revErr != nil {
+				s.logger.WithError(revErr).WithField("authorization_id", decision.AuthorizationID).
+					Error("Failed to reverse unrecorded card authorization")
+			}
+		}
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"authorization_id": auth.ID,
+		"card_id":          cardID,
+		"merchant":         merchant.Name,
+		"mcc":              merchant.MCC,
+		"amount":           amount,
+		"status":           auth.Status,
+	}).Info("Card authorization processed")
+
+	return auth, nil
+}
+
+// GenerateTraffic sends count authorizations against randomly sampled cards
+// at random catalog merchants.
+func (s *CardNetworkService) GenerateTraffic(ctx context.Context, count int) (*models.SimulateResult, *errors.Error) {
+	cards, err := s.wallet.SampleCards(ctx, count)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SimulateResult{}
+	for _, card := range cards {
+		merchant := randomMerchant()
+		result.Attempted++
+
+		auth, authErr := s.authorize(ctx, card.ID, merchant, randomAmount(merchant))
+		if authErr != nil {
+			result.Failed++
+			s.logger.WithError(authErr).WithField("card_id", card.ID).Warn("Generated card authorization failed")
+			continue
+		}
+		if auth.Status == models.AuthorizationApproved {
+			result.Approved++
+		} else {
+			result.Declined++
+		}
+	}
+
+	return result, nil
+}
+
+// RunClearing settles or reverses approvals that are due, then refunds a
+// sample of earlier settlements.
+func (s *CardNetworkService) RunClearing(ctx context.Context) (*models.ClearingResult, *errors.Error) {
+	due, err := s.repo.ListDueForClearing(ctx, clearingBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ClearingResult{}
+
+	// Retry refunds whose earlier attempt had no clear outcome
+	pending, err := s.repo.ListPendingRefunds(ctx, clearingBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, refund := range pending {
+		auth, getErr := s.repo.GetByID(ctx, refund.AuthorizationID)
+		if getErr != nil {
+			result.Failed++
+			continue
+		}
+		if _, err := s.sendRefund(ctx, auth, refund); err != nil {
+			result.Failed++
+			continue
+		}
+		result.Refunded++
+	}
+
+	refunds := 0
+	for _, auth := range due {
+		if rand.Float64() < s.config.ReversalRate {
+			if _, err := s.Reverse(ctx, auth.ID); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Reversed++
+			continue
+		}
+
+		if _, err := s.Settle(ctx, auth.ID, 0); err != nil {
+			result.Failed++
+			continue
+		}
+		result.Settled++
+		if rand.Float64() < s.config.RefundRate {
+			refunds++
+		}
+	}
+
+	if refunds > 0 {
+		refundable, err := s.repo.ListRefundable(ctx, refunds)
+		if err != nil {
+			return result, err
+		}
+		for _, auth := range refundable {
+			// Merchants refund either the whole purchase or a single item
+			amount := auth.SettledAmount - auth.RefundedAmount
+			if rand.IntN(2) == 0 && amount >= 200 {
+				amount /= 2
+			}
+			if _, err := s.Refund(ctx, auth.ID, amount); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Refunded++
+		}
+	}
+
+	if len(due) > 0 || result.Refunded > 0 {
+		s.logger.With(map[string]interface{}{
+			"settled":  result.Settled,
+			"reversed": result.Reversed,
+			"refunded": result.Refunded,
+			"failed":   result.Failed,
+		}).Info("Card clearing run completed")
+	}
+
+	return result, nil
+}
+
+// Settle clears an approved authorization with the issuer.
+func (s *CardNetworkService) Settle(ctx context.Context, id string, amount int64) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := s.approvedAuthorization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if amount > auth.Amount {
+		return nil, errors.BadRequest("settlement amount exceeds authorized amount")
+	}
+
+	issuer, err := s.wallet.Settle(ctx, *auth.WalletAuthorizationID, amount)
+	return s.applyIssuerResult(ctx, auth, issuer, err)
+}
+
+// Reverse voids an approved authorization before clearing.
+func (s *CardNetworkService) Reverse(ctx context.Context, id string) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := s.approvedAuthorization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := s.wallet.Reverse(ctx, *auth.WalletAuthorizationID)
+	return s.applyIssuerResult(ctx, auth, issuer, err)
+}
+
+// Refund returns part of a settled authorization to the cardholder.
+func (s *CardNetworkService) Refund(ctx context.Context, id string, amount int64) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if auth.Status != models.AuthorizationSettled {
+		return nil, errors.Conflict("only settled authorizations can be refunded")
+	}
+	if amount <= 0 || auth.RefundedAmount+amount > auth.SettledAmount {
+		return nil, errors.BadRequest("refund exceeds settled amount")
+	}
+
+	refund, err := s.repo.CreateRefund(ctx, auth.ID, amount)
+	if err != nil {
+		return nil, err
+	}
+	return s.sendRefund(ctx, auth, refund)
+}
+
+// sendRefund sends a recorded refund to the issuer under its refund ID. A
+// refund the issuer rejects is marked failed; one whose outcome is unknown
+// stays pending and is retried by the next clearing run.
+func (s *CardNetworkService) sendRefund(ctx context.Context, auth *models.NetworkAuthorization, refund *models.NetworkRefund) (*models.NetworkAuthorization, *errors.Error) {
+	issuer, issuerErr := s.wallet.Refund(ctx, *auth.WalletAuthorizationID, refund.ID, refund.Amount)
+	switch {
+	case issuerErr == nil:
+		if err := s.repo.SetRefundStatus(ctx, refund.ID, models.RefundCompleted); err != nil {
+			return nil, err
+		}
+	case issuerErr.HTTPStatusCode() < http.StatusInternalServerError:
+		if err := s.repo.SetRefundStatus(ctx, refund.ID, models.RefundFailed); err != nil {
+			return nil, err
+		}
+	default:
+		s.logger.WithError(issuerErr).WithField("refund_id", refund.ID).Warn("Card refund outcome unknown; will retry")
+	}
+	return s.applyIssuerResult(ctx, auth, issuer, issuerErr)
+}
+
+// GetAuthorization retrieves a network authorization.
+func (s *CardNetworkService) GetAuthorization(ctx context.Context, id string) (*models.NetworkAuthorization, *errors.Error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListAuthorizations lists network authorizations, newest first.
+func (s *CardNetworkService) ListAuthorizations(ctx context.Context, status models.AuthorizationStatus, cardID string, limit, offset int) ([]*models.NetworkAuthorization, *errors.Error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(ctx, status, cardID, limit, offset)
+}
+
+func (s *CardNetworkService) approvedAuthorization(ctx context.Context, id string) (*models.NetworkAuthorization, *errors.Error) {
+	auth, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if auth.Status != models.AuthorizationApproved || auth.WalletAuthorizationID == nil {
+		return nil, errors.Conflict("authorization is not awaiting clearing")
+	}
+	return auth, nil
+}
+
+// applyIssuerResult mirrors the issuer's clearing state locally. When the
+// issuer rejects the operation as a conflict, its current state is fetched
+// instead so the two sides do not drift apart.
+func (s *CardNetworkService) applyIssuerResult(ctx context.Context, auth *models.NetworkAuthorization, issuer *IssuerAuthorization, issuerErr *errors.Error) (*models.NetworkAuthorization, *errors.Error) {
+	if issuerErr != nil {
+		if issuerErr.Code != errors.ErrCodeConflict {
+			return nil, issuerErr
+		}
+		current, err := s.wallet.GetAuthorization(ctx, *auth.WalletAuthorizationID)
+		if err != nil {
+			return nil, issuerErr
+		}
+		if _, err := s.repo.ApplyIssuerState(ctx, auth.ID, networkStatus(current.Status), current.SettledAmount, current.RefundedAmount); err != nil {
+			return nil, err
+		}
+		return nil, issuerErr
+	}
+
+	return s.repo.ApplyIssuerState(ctx, auth.ID, networkStatus(issuer.Status), issuer.SettledAmount, issuer.RefundedAmount)
+}
+
+// networkStatus maps an issuer clearing status to the network's status.
+func networkStatus(issuerStatus string) models.AuthorizationStatus {
+	switch issuerStatus {
+	case "settled":
+		return models.AuthorizationSettled
+	case "reversed":
+		return models.AuthorizationReversed
+	default:
+		return models.AuthorizationApproved
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"math/rand/v2"
+
+	"github.com/1mb-dev/nivomoney/services/cardnetwork/internal/models"
+)
+
+// merchantCatalog is the set of simulated card acceptors. Amount ranges are
+// in paise and roughly match typical Indian ticket sizes for each category.
+var merchantCatalog = []models.Merchant{
+	{Name: "Swiggy", MCC: "5812", MinAmount: 15000, MaxAmount: 120000},
+	{Name: "Zomato", MCC: "5812", MinAmount: 15000, MaxAmount: 100000},
+	{Name: "BigBasket", MCC: "5411", MinAmount: 50000, MaxAmount: 500000},
+	{Name: "Amazon India", MCC: "5399", MinAmount: 30000, MaxAmount: 1500000},
+	{Name: "Flipkart", MCC: "5399", MinAmount: 30000, MaxAmount: 1500000},
+	{Name: "Uber India", MCC: "4121", MinAmount: 8000, MaxAmount: 80000},
+	{Name: "IndianOil Fuel", MCC: "5541", MinAmount: 50000, MaxAmount: 400000},
+	{Name: "BookMyShow", MCC: "7832", MinAmount: 20000, MaxAmount: 150000},
+	{Name: "Apollo Pharmacy", MCC: "5912", MinAmount: 10000, MaxAmount: 300000},
+	{Name: "IRCTC", MCC: "4112", MinAmount: 30000, MaxAmount: 600000},
+	{Name: "Netflix", MCC: "4899", MinAmount: 14900, MaxAmount: 64900},
+	{Name: "Croma Electronics", MCC: "5732", MinAmount: 100000, MaxAmount: 5000000},
+}
+
+// Merchants returns the simulated merchant catalog.
+func Merchants() []models.Merchant {
+	return merchantCatalog
+}
+
+// randomMerchant picks a catalog merchant.
+func randomMerchant() models.Merchant {
+	return merchantCatalog[rand.IntN(len(merchantCatalog))]
+}
+
+// randomAmount picks an amount within the merchant's range, rounded to whole rupees.
+func randomAmount(m models.Merchant) int64 {
+	amount := m.MinAmount + rand.Int64N(m.MaxAmount-m.MinAmount+1)
+	return amount - amount%100
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// WalletClient calls the wallet service, which acts as the card issuer.
+type WalletClient struct {
+	*clients.BaseClient
+}
+
+// NewWalletClient creates a wallet client with internal service authentication.
+func NewWalletClient(baseURL, internalSecret string) *WalletClient {
+	return &WalletClient{
+		BaseClient: clients.NewInternalClient(baseURL, clients.DefaultTimeout, internalSecret),
+	}
+}
+
+// IssuerCard is a card the issuer allows the network to generate traffic for.
+type IssuerCard struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	UserID   string `json:"user_id"`
+}
+
+// IssuerAuthorizationRequest is sent to the issuer to authorize a card payment.
+type IssuerAuthorizationRequest struct {
+	Amount       int64  `json:"amount"`
+	MerchantName string `json:"merchant_name"`
+	MCC          string `json:"mcc,omitempty"`
+}
+
+// IssuerDecision is the issuer's response to an authorization request.
+type IssuerDecision struct {
+	AuthorizationID string `json:"authorization_id,omitempty"`
+	CardID          string `json:"card_id"`
+	Approved        bool   `json:"approved"`
+	Amount          int64  `json:"amount"`
+	DeclineReason   string `json:"decline_reason,omitempty"`
+	CardFrozen      bool   `json:"card_frozen"`
+}
+
+// IssuerAuthorization is the issuer's clearing state for an approved authorization.
+type IssuerAuthorization struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"` // authorized, settled, reversed
+	Amount         int64  `json:"amount"`
+	SettledAmount  int64  `json:"settled_amount"`
+	RefundedAmount int64  `json:"refunded_amount"`
+}
+
+// SampleCards returns up to limit random active cards.
+func (c *WalletClient) SampleCards(ctx context.Context, limit int) ([]IssuerCard, *errors.Error) {
+	var cards []IssuerCard
+	if err := c.Get(ctx, fmt.Sprintf("/internal/v1/cards/network?limit=%d", limit), &cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// Authorize asks the issuer to approve a card payment.
+func (c *WalletClient) Authorize(ctx context.Context, cardID string, req IssuerAuthorizationRequest) (*IssuerDecision, *errors.Error) {
+	var decision IssuerDecision
+	if err := c.Post(ctx, fmt.Sprintf("/internal/v1/cards/%s/authorize", cardID), req, &decision); err != nil {
+		return nil, err
+	}
+	return &decision, nil
+}
+
+// GetAuthorization returns the issuer's clearing state for an authorization.
+func (c *WalletClient) GetAuthorization(ctx context.Context, authorizationID string) (*IssuerAuthorization, *errors.Error) {
+	var auth IssuerAuthorization
+	if err := c.Get(ctx, fmt.Sprintf("/internal/v1/cards/authorizations/%s", authorizationID), &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// Settle clears an authorization; zero settles the full amount.
+func (c *WalletClient) Settle(ctx context.Context, authorizationID string, amount int64) (*IssuerAuthorization, *errors.Error) {
+	return c.clear(ctx, authorizationID, "settle", map[string]int64{"amount": amount})
+}
+
+// Reverse releases an authorization's hold.
+func (c *WalletClient) Reverse(ctx context.Context, authorizationID string) (*IssuerAuthorization, *errors.Error) {
+	return c.clear(ctx, authorizationID, "reverse", nil)
+}
+
+// Refund returns part of a settled amount to the cardholder. The issuer
+// credits each refund ID once, so a refund can be retried safely.
+func (c *WalletClient) Refund(ctx context.Context, authorizationID, refundID string, amount int64) (*IssuerAuthorization, *errors.Error) {
+	return c.clear(ctx, authorizationID, "refund", map[string]any{"refund_id": refundID, "amount": amount})
+}
+
+func (c *WalletClient) clear(ctx context.Context, authorizationID, action string, body any) (*IssuerAuthorization, *errors.Error) {
+	var auth IssuerAuthorization
+	path := fmt.Sprintf("/internal/v1/cards/authorizations/%s/%s", authorizationID, action)
+	if err := c.Post(ctx, path, body, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
@@ -0,0 +1,26 @@
+-- Card network simulation: authorizations sent to the issuer (wallet service)
+-- and their clearing outcome.
+CREATE TABLE IF NOT EXISTS network_authorizations (
+    id                       UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    card_id                  UUID NOT NULL,
+    wallet_authorization_id  UUID,
+    merchant_name            VARCHAR(100) NOT NULL,
+    mcc                      VARCHAR(4) NOT NULL,
+    amount                   BIGINT NOT NULL CHECK (amount > 0),
+    status                   VARCHAR(20) NOT NULL
+                             CHECK (status IN ('approved', 'declined', 'settled', 'reversed')),
+    decline_reason           VARCHAR(50),
+    settled_amount           BIGINT NOT NULL DEFAULT 0,
+    refunded_amount          BIGINT NOT NULL DEFAULT 0,
+    settle_after             TIMESTAMPTZ,
+    created_at               TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at               TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_network_authorizations_card ON network_authorizations(card_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_network_authorizations_created ON network_authorizations(created_at DESC);
+
+-- Clearing worker picks up approved authorizations once they are due
+CREATE INDEX IF NOT EXISTS idx_network_authorizations_due
+    ON network_authorizations(settle_after)
+    WHERE status = 'approved';
@@ -0,0 +1,19 @@
+-- Refunds the network sent to the issuer. The refund ID is the issuer's
+-- idempotency key: a pending refund is retried with the same ID until the
+-- issuer confirms or rejects it.
+CREATE TABLE IF NOT EXISTS network_refunds (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    authorization_id  UUID NOT NULL REFERENCES network_authorizations(id),
+    amount            BIGINT NOT NULL CHECK (amount > 0),
+    status            VARCHAR(20) NOT NULL DEFAULT 'pending'
+                      CHECK (status IN ('pending', 'completed', 'failed')),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_network_refunds_authorization ON network_refunds(authorization_id);
+
+-- Clearing worker retries refunds whose outcome is unknown
+CREATE INDEX IF NOT EXISTS idx_network_refunds_pending
+    ON network_refunds(created_at)
+    WHERE status = 'pending';
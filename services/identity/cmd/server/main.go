@@ -2,16 +2,17 @@ package main
 
 import (
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/identity/internal/handler"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/kyc"
 	"github.com/1mb-dev/nivomoney/services/identity/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 func main() {
@@ -20,6 +21,11 @@ func main() {
 
 	server.Run(server.ServiceConfig{
 		Name: "identity",
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/users": {"transaction"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repositories
 			userRepo := repository.NewUserRepository(ctx.DB)
@@ -27,11 +33,15 @@ func main() {
 			kycRepo := repository.NewKYCRepository(ctx.DB)
 			sessionRepo := repository.NewSessionRepository(ctx.DB)
 			verificationRepo := repository.NewVerificationRepository(ctx.DB)
+			tierRepo := repository.NewTierRepository(ctx.DB)
+			kycCheckRepo := repository.NewKYCCheckRepository(ctx.DB)
+			contactChangeRepo := repository.NewContactChangeRepository(ctx.DB)
 
 			// Initialize external service clients with internal auth for service-to-service calls
-			internalSecret := server.GetEnv("INTERNAL_SERVICE_SECRET", "")
+			internalSecret := server.GetSecret("INTERNAL_SERVICE_SECRET", "")
 			rbacClient := service.NewRBACClientWithSecret(server.GetEnv("RBAC_SERVICE_URL", "http://rbac-service:8082"), internalSecret)
 			walletClient := service.NewWalletClientWithSecret(server.GetEnv("WALLET_SERVICE_URL", "http://wallet-service:8083"), internalSecret)
+			transactionClient := service.NewTransactionClientWithSecret(server.GetEnv("TRANSACTION_SERVICE_URL", "http://transaction-service:8084"), internalSecret)
 			notificationClient := clients.NewNotificationClient(server.GetEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8087"))
 
 			// Initialize event publisher
@@ -40,21 +50,16 @@ func main() {
 				ServiceName: "identity",
 			})
 
-			// Initialize Redis cache (optional - graceful degradation if unavailable)
-			var sessionCache cache.Cache
-			redisURL := os.Getenv("REDIS_URL")
-			if redisURL != "" {
-				redisCfg := cache.DefaultRedisConfig(redisURL)
-				var err error
-				redisCache, err = cache.NewRedisCache(redisCfg)
-				if err != nil {
-					ctx.Logger.WithError(err).Warn("Redis connection failed, running without cache")
-				} else {
-					sessionCache = redisCache
-					ctx.Logger.Info("Redis cache initialized successfully")
-				}
-			} else {
-				ctx.Logger.Info("REDIS_URL not set, running without session cache")
+			// Session cache: memory in front of Redis, or memory alone without REDIS_URL
+			sessionCache, redis, err := cache.Open(server.GetSecret("REDIS_URL", ""), server.GetEnvInt("SESSION_CACHE_ENTRIES", cache.DefaultMemoryEntries))
+			switch {
+			case err != nil:
+				ctx.Logger.WithError(err).Warn("Redis connection failed, using in-memory session cache")
+			case redis != nil:
+				redisCache = redis
+				ctx.Logger.Info("Redis cache initialized successfully")
+			default:
+				ctx.Logger.Info("REDIS_URL not set, using in-memory session cache")
 			}
 
 			// Initialize services
@@ -62,15 +67,88 @@ func main() {
 			jwtExpiry := 24 * time.Hour
 			authService := service.NewAuthService(userRepo, userAdminRepo, kycRepo, sessionRepo, rbacClient, walletClient, notificationClient, jwtSecret, jwtExpiry, eventPublisher)
 
-			// Enable session caching if Redis is available
-			if sessionCache != nil {
-				authService.SetCache(sessionCache)
+			authService.SetCache(sessionCache)
+
+			// Email and phone changes are confirmed with a code sent to the new address
+			authService.SetContactChanges(contactChangeRepo, notificationClient)
+
+			// Email and phone are verified with a code sent to the address. With
+			// REQUIRE_VERIFIED_CONTACT=true, wallets are activated only once one of
+			// them is verified as well as KYC
+			requireVerifiedContact := server.GetEnv("REQUIRE_VERIFIED_CONTACT", "false") == "true"
+			authService.SetContactVerification(verificationRepo, notificationClient, requireVerifiedContact)
+
+			// Repeated failed logins require a CAPTCHA, then lock the account
+			lockoutPolicy := service.DefaultLockoutPolicy()
+			lockoutPolicy.CaptchaAfter = server.GetEnvInt("LOGIN_CAPTCHA_AFTER", lockoutPolicy.CaptchaAfter)
+			lockoutPolicy.LockAfter = server.GetEnvInt("LOGIN_LOCKOUT_AFTER", lockoutPolicy.LockAfter)
+			lockoutPolicy.BaseCooldown = time.Duration(server.GetEnvInt("LOGIN_LOCKOUT_BASE_MINUTES", int(lockoutPolicy.BaseCooldown.Minutes()))) * time.Minute
+			lockoutPolicy.MaxCooldown = time.Duration(server.GetEnvInt("LOGIN_LOCKOUT_MAX_MINUTES", int(lockoutPolicy.MaxCooldown.Minutes()))) * time.Minute
+			lockoutPolicy.UnlockURL = server.GetEnv("ACCOUNT_UNLOCK_URL", lockoutPolicy.UnlockURL)
+			authService.SetLoginLockout(repository.NewLoginLockoutRepository(ctx.DB), notificationClient, lockoutPolicy)
+
+			// CAPTCHA is enforced only when a verification secret is configured
+			if captchaSecret := server.GetSecret("CAPTCHA_SECRET", ""); captchaSecret != "" {
+				authService.SetCaptchaVerifier(service.NewHTTPCaptchaVerifier(server.GetEnv("CAPTCHA_VERIFY_URL", service.DefaultCaptchaVerifyURL), captchaSecret))
+			} else {
+				ctx.Logger.Info("CAPTCHA_SECRET not set, captcha_required is advisory")
 			}
 
 			verificationService := service.NewVerificationService(verificationRepo, userAdminRepo)
 
+			// Tier upgrade fees are charged as fee transactions into a platform fee
+			// wallet; without one, upgrades are approved without charging
+			var tierBilling service.TierBillingHook
+			if feeWalletID := server.GetEnv("TIER_FEE_WALLET_ID", ""); feeWalletID != "" {
+				tierBilling = service.NewTransactionBillingHook(walletClient, transactionClient, feeWalletID)
+			} else {
+				ctx.Logger.Warn("TIER_FEE_WALLET_ID not set, tier upgrade fees will not be collected")
+			}
+			tierService := service.NewTierService(tierRepo, tierBilling, eventPublisher)
+			authService.SetTierLookup(tierService)
+
+			// KYC provider checks (mock by default, see KYC_PROVIDER)
+			kycProvider, err := kyc.NewProvider(kyc.ConfigFromEnv())
+			if err != nil {
+				return nil, err
+			}
+			ctx.Logger.WithField("provider", kycProvider.Name()).Info("KYC provider configured")
+			kycCheckService := service.NewKYCCheckService(kycProvider, kycCheckRepo, kycRepo, userRepo)
+			authService.SetKYCChecker(kycCheckService)
+
+			// KYC review queue; submissions are due KYC_REVIEW_SLA_HOURS after submission
+			kycReviewSLA := time.Duration(server.GetEnvInt("KYC_REVIEW_SLA_HOURS", int(service.DefaultKYCReviewSLA.Hours()))) * time.Hour
+			kycReviewService := service.NewKYCReviewService(repository.NewKYCReviewRepository(ctx.DB), authService, kycReviewSLA)
+
+			// Profile attributes are published so other services follow user preferences
+			profileService := service.NewProfileService(repository.NewProfileAttributeRepository(ctx.DB), eventPublisher)
+
+			// OIDC provider so other tools can sign users in with their Nivo
+			// account; without OIDC_SIGNING_KEY tokens stop verifying on restart
+			var oidcKey *service.OIDCSigningKey
+			if signingKey := server.GetSecret("OIDC_SIGNING_KEY", ""); signingKey != "" {
+				oidcKey, err = service.ParseOIDCSigningKey([]byte(signingKey))
+			} else {
+				ctx.Logger.Warn("OIDC_SIGNING_KEY not set, signing OIDC tokens with a temporary key")
+				oidcKey, err = service.GenerateOIDCSigningKey()
+			}
+			if err != nil {
+				return nil, err
+			}
+			oidcService := service.NewOIDCService(repository.NewOIDCRepository(ctx.DB), userRepo, oidcKey, service.OIDCConfig{
+				Issuer:     server.GetEnv("OIDC_ISSUER", "http://localhost:8000/api/v1/oauth"),
+				ConsentURL: server.GetEnv("OIDC_CONSENT_URL", "http://localhost:3000/oauth/consent"),
+				TokenTTL:   time.Duration(server.GetEnvInt("OIDC_TOKEN_TTL_MINUTES", int(service.DefaultOIDCTokenTTL.Minutes()))) * time.Minute,
+			})
+
+			// Resource-level authorization with decision logging
+			authorizer, err := handler.NewIdentityAuthorizer(authService, ctx.Logger)
+			if err != nil {
+				return nil, err
+			}
+
 			// Initialize router
-			router := handler.NewRouter(authService, verificationService)
+			router := handler.NewRouter(authService, verificationService, tierService, kycCheckService, kycReviewService, profileService, oidcService, authorizer, internalSecret)
 
 			return router.SetupRoutes(), nil
 		},
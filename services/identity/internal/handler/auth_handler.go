@@ -13,6 +13,7 @@ import (
 	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/response"
+	_ "github.com/1mb-dev/nivomoney/shared/validator" // Registers the pan, aadhaar, pincode and other validation tags
 )
 
 // AuthHandler handles authentication HTTP requests.
@@ -75,6 +76,8 @@ type LoginRequest struct {
 	Identifier string `json:"identifier" validate:"required"`
 	Password   string `json:"password" validate:"required"`
 	Portal     string `json:"portal,omitempty"` // Portal context: "user" (default) or "admin"
+
+	CaptchaToken string `json:"captcha_token,omitempty"` // Required once a failed login sets captcha_required
 }
 
 // Login handles user authentication.
@@ -103,6 +106,8 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		Identifier: normalizeIndianPhone(req.Identifier),
 		Password:   req.Password,
 		Portal:     models.PortalType(req.Portal),
+
+		CaptchaToken: req.CaptchaToken,
 	}
 
 	// Authenticate user
@@ -134,6 +139,25 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// RefreshToken replaces the caller's token with one carrying their current
+// roles, permissions and tier.
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	token := extractBearerToken(r)
+	if token == "" {
+		response.Error(w, errors.Unauthorized("missing authorization token"))
+		return
+	}
+
+	refreshResp, svcErr := h.authService.RefreshToken(r.Context(), token, extractIPAddress(r), r.UserAgent())
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, refreshResp)
+}
+
 // LogoutAll handles termination of all sessions for a user.
 // POST /api/v1/auth/logout-all
 func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
@@ -153,6 +177,47 @@ func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// ListSessions lists the current user's active sessions.
+// GET /api/v1/profile/sessions
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	sessions, svcErr := h.authService.ListSessions(r.Context(), user.ID, extractBearerToken(r))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, sessions)
+}
+
+// RevokeSession terminates one of the current user's sessions.
+// DELETE /api/v1/profile/sessions/{id}
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	sessionID := r.PathValue("id")
+	if sessionID == "" {
+		response.Error(w, errors.BadRequest("session ID is required"))
+		return
+	}
+
+	if svcErr := h.authService.RevokeSession(r.Context(), user.ID, sessionID); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // GetProfile retrieves the current user's profile.
 // GET /api/v1/auth/me
 func (h *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
@@ -297,6 +362,30 @@ func (h *AuthHandler) LookupUser(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, foundUser)
 }
 
+// GetDisplayNames returns how users are shown to other users (internal endpoint).
+// POST /internal/v1/users/display-names
+func (h *AuthHandler) GetDisplayNames(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.DisplayNamesRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	names, svcErr := h.authService.GetDisplayNames(r.Context(), req.UserIDs)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, names)
+}
+
 // UpdateKYCRequest represents a KYC update request.
 type UpdateKYCRequest struct {
 	PAN         string         `json:"pan" validate:"required,pan"`
@@ -382,69 +471,6 @@ func (h *AuthHandler) GetKYC(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, freshUser.KYC)
 }
 
-// VerifyKYCRequest represents a KYC verification request (admin only).
-type VerifyKYCRequest struct {
-	UserID string `json:"user_id" validate:"required,uuid"`
-}
-
-// VerifyKYC approves a user's KYC (admin operation).
-// POST /api/v1/admin/kyc/verify
-func (h *AuthHandler) VerifyKYC(w http.ResponseWriter, r *http.Request) {
-	// Read request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		response.Error(w, errors.BadRequest("failed to read request body"))
-		return
-	}
-
-	// Parse and validate request using gopantic
-	req, err := model.ParseInto[VerifyKYCRequest](body)
-	if err != nil {
-		response.Error(w, errors.Validation(err.Error()))
-		return
-	}
-
-	// Verify KYC
-	if svcErr := h.authService.VerifyKYC(r.Context(), req.UserID); svcErr != nil {
-		response.Error(w, svcErr)
-		return
-	}
-
-	response.NoContent(w)
-}
-
-// RejectKYCRequest represents a KYC rejection request (admin only).
-type RejectKYCRequest struct {
-	UserID string `json:"user_id" validate:"required,uuid"`
-	Reason string `json:"reason" validate:"required,min:10,max:500"`
-}
-
-// RejectKYC rejects a user's KYC (admin operation).
-// POST /api/v1/admin/kyc/reject
-func (h *AuthHandler) RejectKYC(w http.ResponseWriter, r *http.Request) {
-	// Read request body
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		response.Error(w, errors.BadRequest("failed to read request body"))
-		return
-	}
-
-	// Parse and validate request using gopantic
-	req, err := model.ParseInto[RejectKYCRequest](body)
-	if err != nil {
-		response.Error(w, errors.Validation(err.Error()))
-		return
-	}
-
-	// Reject KYC
-	if svcErr := h.authService.RejectKYC(r.Context(), req.UserID, req.Reason); svcErr != nil {
-		response.Error(w, svcErr)
-		return
-	}
-
-	response.NoContent(w)
-}
-
 // ListPendingKYCs retrieves all pending KYC submissions (admin operation).
 // GET /api/v1/admin/kyc/pending
 func (h *AuthHandler) ListPendingKYCs(w http.ResponseWriter, r *http.Request) {
@@ -626,6 +652,73 @@ func (h *AuthHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
 	response.Success(w, http.StatusOK, map[string]string{"message": "user unsuspended successfully"})
 }
 
+// UnlockAccount handles POST /api/v1/admin/users/:id/unlock
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user ID is required"))
+		return
+	}
+
+	adminUser := getUserFromContext(r.Context())
+	if adminUser == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	if svcErr := h.authService.UnlockAccount(r.Context(), userID, adminUser.ID); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Success(w, http.StatusOK, map[string]string{"message": "account unlocked successfully"})
+}
+
+// UnlockWithToken handles POST /api/v1/auth/unlock
+// Unlocks an account with the token from the lockout email.
+func (h *AuthHandler) UnlockWithToken(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.UnlockAccountRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	if svcErr := h.authService.UnlockWithToken(r.Context(), req.Token); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Success(w, http.StatusOK, map[string]string{"message": "account unlocked, you can log in again"})
+}
+
+// ForceLogout handles POST /api/v1/admin/users/:id/force-logout
+func (h *AuthHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("id")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user ID is required"))
+		return
+	}
+
+	adminUser := getUserFromContext(r.Context())
+	if adminUser == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	if svcErr := h.authService.ForceLogout(r.Context(), userID, adminUser.ID); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Success(w, http.StatusOK, map[string]string{"message": "user sessions revoked successfully"})
+}
+
 // extractBearerToken extracts the JWT token from the Authorization header.
 func extractBearerToken(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
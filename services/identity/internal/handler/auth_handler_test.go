@@ -91,6 +91,16 @@ func (m *mockUserRepository) GetByPhone(ctx context.Context, phone string) (*mod
 	return nil, errors.NotFound("user")
 }
 
+func (m *mockUserRepository) ListByIDs(ctx context.Context, ids []string) ([]*models.User, *errors.Error) {
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := m.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (m *mockUserRepository) GetByID(ctx context.Context, id string) (*models.User, *errors.Error) {
 	if m.GetByIDFunc != nil {
 		return m.GetByIDFunc(ctx, id)
@@ -156,6 +166,10 @@ func (m *mockUserRepository) UnsuspendUser(ctx context.Context, userID string) *
 	return m.UpdateStatus(ctx, userID, models.UserStatusActive)
 }
 
+func (m *mockUserRepository) MarkContactVerified(ctx context.Context, userID string, channel models.ContactChannel, value string) *errors.Error {
+	return nil
+}
+
 // mockSessionRepository implements service.SessionRepositoryInterface.
 type mockSessionRepository struct {
 	sessions map[string]*models.Session
@@ -193,6 +207,30 @@ func (m *mockSessionRepository) DeleteByUserID(ctx context.Context, userID strin
 	return nil
 }
 
+func (m *mockSessionRepository) ListActiveByUserID(ctx context.Context, userID string) ([]*models.Session, *errors.Error) {
+	sessions := make([]*models.Session, 0)
+	for _, session := range m.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) DeleteByID(ctx context.Context, userID, sessionID string) (string, *errors.Error) {
+	for hash, session := range m.sessions {
+		if session.ID == sessionID && session.UserID == userID {
+			delete(m.sessions, hash)
+			return hash, nil
+		}
+	}
+	return "", errors.NotFound("session")
+}
+
+func (m *mockSessionRepository) TouchLastSeen(ctx context.Context, tokenHash string) *errors.Error {
+	return nil
+}
+
 // mockKYCRepository implements service.KYCRepositoryInterface.
 type mockKYCRepository struct{}
 
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/authz"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// Actions checked against the identity authorization rules.
+const (
+	ActionUserRead = "identity:user:read"
+)
+
+// adminScopes are the admin console sections, each opened by the action
+// "admin:<scope>:view".
+var adminScopes = []string{"users", "kyc", "tier_requests", "transactions", "wallets", "approvals", "ledger", "cards"}
+
+func adminScopeAction(scope string) string {
+	return "admin:" + scope + ":view"
+}
+
+// PairingChecker reports whether a User-Admin account is paired with a user.
+type PairingChecker interface {
+	ValidatePairing(ctx context.Context, adminUserID, userID string) (bool, *errors.Error)
+}
+
+// identityRules grants identity actions and admin console sections. User-Admin
+// accounts reach only the user they are paired with.
+func identityRules(pairing PairingChecker) []authz.Rule {
+	userAdmin := authz.AccountType(string(models.AccountTypeUserAdmin))
+	pairedAdmin := []authz.Condition{
+		userAdmin,
+		authz.Related("paired_admin", func(ctx context.Context, adminID, userID string) (bool, error) {
+			ok, err := pairing.ValidatePairing(ctx, adminID, userID)
+			if err != nil {
+				return false, err
+			}
+			return ok, nil
+		}),
+	}
+
+	return []authz.Rule{
+		{Name: "staff-read-user", Action: ActionUserRead, Permission: "identity:kyc:list"},
+		{Name: "paired-admin-read-user", Action: ActionUserRead, Conditions: pairedAdmin},
+
+		{Name: "ui-users-staff", Action: adminScopeAction("users"), Permission: "identity:kyc:list"},
+		{Name: "ui-users-paired-admin", Action: adminScopeAction("users"), Conditions: []authz.Condition{userAdmin}},
+		{Name: "ui-kyc-verify", Action: adminScopeAction("kyc"), Permission: "identity:kyc:verify"},
+		{Name: "ui-kyc-reject", Action: adminScopeAction("kyc"), Permission: "identity:kyc:reject"},
+		{Name: "ui-tier-requests", Action: adminScopeAction("tier_requests"), Permission: "identity:users:update"},
+		{Name: "ui-transactions", Action: adminScopeAction("transactions"), Permission: "transaction:transaction:list"},
+		{Name: "ui-wallets", Action: adminScopeAction("wallets"), Permission: "wallet:wallet:list"},
+		{Name: "ui-approvals-transaction", Action: adminScopeAction("approvals"), Permission: "transaction:approval:review"},
+		{Name: "ui-approvals-ledger", Action: adminScopeAction("approvals"), Permission: "ledger:approval:review"},
+		{Name: "ui-approvals-risk", Action: adminScopeAction("approvals"), Permission: "risk:approval:review"},
+		{Name: "ui-ledger", Action: adminScopeAction("ledger"), Permission: "ledger:entry:create"},
+		{Name: "ui-cards", Action: adminScopeAction("cards"), Permission: "cardnetwork:authorization:read"},
+	}
+}
+
+// NewIdentityAuthorizer creates the authorizer for identity's rules.
+func NewIdentityAuthorizer(pairing PairingChecker, log *logger.Logger) (*authz.Authorizer, error) {
+	return authz.New(identityRules(pairing), authz.Config{Service: "identity", Logger: log})
+}
+
+// subjectFromRequest builds the authorization subject from the authenticated
+// user and the roles and permissions in their token.
+func subjectFromRequest(r *http.Request) (authz.Subject, bool) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		return authz.Subject{}, false
+	}
+	subject := authz.Subject{UserID: user.ID, AccountType: string(user.AccountType)}
+	if token := extractBearerToken(r); token != "" {
+		// The signature was validated by Authenticate
+		subject.Roles, _ = extractRolesFromToken(token)
+		subject.Permissions, _ = extractPermissionsFromToken(token)
+	}
+	return subject, true
+}
+
+// RequireUserAccess authorizes action on the user named by the {id} path
+// value. It must be chained after Authenticate.
+func RequireUserAccess(authorizer *authz.Authorizer, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, ok := subjectFromRequest(r)
+			if !ok {
+				response.Error(w, errors.Unauthorized("user not authenticated"))
+				return
+			}
+
+			userID := r.PathValue("id")
+			resource := authz.Resource{Type: "user", ID: userID, OwnerID: userID}
+			if err := authorizer.Authorize(r.Context(), subject, action, resource); err != nil {
+				response.Error(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AdminScopesResponse lists the admin console sections the caller may open.
+type AdminScopesResponse struct {
+	Scopes []string `json:"scopes"`
+}
+
+// AdminScopesHandler serves GET /api/v1/admin/scopes. The admin console uses
+// it to show only the sections the caller's roles open; every endpoint still
+// checks access itself.
+func AdminScopesHandler(authorizer *authz.Authorizer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subject, ok := subjectFromRequest(r)
+		if !ok {
+			response.Error(w, errors.Unauthorized("user not authenticated"))
+			return
+		}
+
+		actions := make([]string, len(adminScopes))
+		for i, scope := range adminScopes {
+			actions[i] = adminScopeAction(scope)
+		}
+		allowed := authorizer.AllowedActions(r.Context(), subject, actions...)
+
+		scopes := make([]string, len(allowed))
+		for i, action := range allowed {
+			scopes[i] = strings.TrimSuffix(strings.TrimPrefix(action, "admin:"), ":view")
+		}
+		response.OK(w, AdminScopesResponse{Scopes: scopes})
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// stubPairing pairs admins with users by ID.
+type stubPairing map[string]string
+
+func (p stubPairing) ValidatePairing(ctx context.Context, adminUserID, userID string) (bool, *errors.Error) {
+	return p[adminUserID] == userID, nil
+}
+
+// authorizedRequest carries user in context and a token holding permissions,
+// as Authenticate would leave it.
+func authorizedRequest(t *testing.T, method, path string, user *models.User, permissions ...string) *http.Request {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{UserID: user.ID, Permissions: permissions}).
+		SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req.WithContext(context.WithValue(req.Context(), UserContextKey, user))
+}
+
+func TestRequireUserAccess(t *testing.T) {
+	authorizer, err := NewIdentityAuthorizer(stubPairing{"admin-1": "user-1"}, nil)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/admin/users/{id}", RequireUserAccess(authorizer, ActionUserRead)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })))
+
+	admin := &models.User{ID: "admin-1", AccountType: models.AccountTypeUserAdmin}
+	staff := &models.User{ID: "ops-1", AccountType: models.AccountTypeUser}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"paired admin", authorizedRequest(t, http.MethodGet, "/api/v1/admin/users/user-1", admin), http.StatusOK},
+		{"admin of another user", authorizedRequest(t, http.MethodGet, "/api/v1/admin/users/user-2", admin), http.StatusForbidden},
+		{"staff with permission", authorizedRequest(t, http.MethodGet, "/api/v1/admin/users/user-2", staff, "identity:kyc:list"), http.StatusOK},
+		{"staff without permission", authorizedRequest(t, http.MethodGet, "/api/v1/admin/users/user-2", staff), http.StatusForbidden},
+		{"unauthenticated", httptest.NewRequest(http.MethodGet, "/api/v1/admin/users/user-1", nil), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, tt.req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestAdminScopesHandler(t *testing.T) {
+	authorizer, err := NewIdentityAuthorizer(stubPairing{}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		user        *models.User
+		permissions []string
+		want        []string
+	}{
+		{"support agent", &models.User{ID: "ops-1"}, []string{"identity:kyc:list", "identity:kyc:verify", "wallet:wallet:list"}, []string{"users", "kyc", "wallets"}},
+		{"user admin", &models.User{ID: "admin-1", AccountType: models.AccountTypeUserAdmin}, nil, []string{"users"}},
+		{"regular user", &models.User{ID: "user-1", AccountType: models.AccountTypeUser}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			AdminScopesHandler(authorizer)(rec, authorizedRequest(t, http.MethodGet, "/api/v1/admin/scopes", tt.user, tt.permissions...))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var body struct {
+				Data AdminScopesResponse `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.want, body.Data.Scopes)
+		})
+	}
+}
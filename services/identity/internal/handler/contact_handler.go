@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ContactHandler handles verified email and phone change requests.
+type ContactHandler struct {
+	authService *service.AuthService
+}
+
+// NewContactHandler creates a new contact handler.
+func NewContactHandler(authService *service.AuthService) *ContactHandler {
+	return &ContactHandler{authService: authService}
+}
+
+// RequestEmailChange handles POST /api/v1/users/me/email/change
+// Sends a code to the new email; the change applies once it is confirmed.
+func (h *ContactHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.ChangeEmailRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	change, changeErr := h.authService.RequestContactChange(r.Context(), user.ID, models.ContactChannelEmail, req.NewEmail, req.CurrentPassword)
+	if changeErr != nil {
+		response.Error(w, changeErr)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, change)
+}
+
+// RequestPhoneChange handles POST /api/v1/users/me/phone/change
+// Sends a code to the new phone number; the change applies once it is confirmed.
+func (h *ContactHandler) RequestPhoneChange(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.ChangePhoneRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	change, changeErr := h.authService.RequestContactChange(r.Context(), user.ID, models.ContactChannelPhone, req.NewPhone, req.CurrentPassword)
+	if changeErr != nil {
+		response.Error(w, changeErr)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, change)
+}
+
+// ConfirmEmailChange handles POST /api/v1/users/me/email/confirm
+func (h *ContactHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	h.confirmChange(w, r, models.ContactChannelEmail)
+}
+
+// ConfirmPhoneChange handles POST /api/v1/users/me/phone/confirm
+func (h *ContactHandler) ConfirmPhoneChange(w http.ResponseWriter, r *http.Request) {
+	h.confirmChange(w, r, models.ContactChannelPhone)
+}
+
+func (h *ContactHandler) confirmChange(w http.ResponseWriter, r *http.Request, channel models.ContactChannel) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.ConfirmContactChangeRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	updated, confirmErr := h.authService.ConfirmContactChange(r.Context(), user.ID, channel, req.ChangeID, req.Code)
+	if confirmErr != nil {
+		response.Error(w, confirmErr)
+		return
+	}
+
+	response.OK(w, updated)
+}
+
+// SendEmailVerification handles POST /api/v1/users/me/email/verification
+// Sends a code to the user's current email.
+func (h *ContactHandler) SendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	h.sendVerification(w, r, models.ContactChannelEmail)
+}
+
+// SendPhoneVerification handles POST /api/v1/users/me/phone/verification
+// Sends a code to the user's current phone number.
+func (h *ContactHandler) SendPhoneVerification(w http.ResponseWriter, r *http.Request) {
+	h.sendVerification(w, r, models.ContactChannelPhone)
+}
+
+// VerifyEmail handles POST /api/v1/users/me/email/verify
+func (h *ContactHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	h.verify(w, r, models.ContactChannelEmail)
+}
+
+// VerifyPhone handles POST /api/v1/users/me/phone/verify
+func (h *ContactHandler) VerifyPhone(w http.ResponseWriter, r *http.Request) {
+	h.verify(w, r, models.ContactChannelPhone)
+}
+
+func (h *ContactHandler) sendVerification(w http.ResponseWriter, r *http.Request, channel models.ContactChannel) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	verification, sendErr := h.authService.SendContactVerification(r.Context(), user.ID, channel)
+	if sendErr != nil {
+		response.Error(w, sendErr)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, verification)
+}
+
+func (h *ContactHandler) verify(w http.ResponseWriter, r *http.Request, channel models.ContactChannel) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.VerifyContactRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	updated, verifyErr := h.authService.VerifyContact(r.Context(), user.ID, channel, req.VerificationID, req.Code)
+	if verifyErr != nil {
+		response.Error(w, verifyErr)
+		return
+	}
+
+	response.OK(w, updated)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// maxSelfieBytes bounds face match uploads (base64 of a ~3 MB image).
+const maxSelfieBytes = 4 << 20
+
+// KYCCheckHandler handles KYC provider check HTTP requests.
+type KYCCheckHandler struct {
+	checkService *service.KYCCheckService
+}
+
+// NewKYCCheckHandler creates a new KYC check handler.
+func NewKYCCheckHandler(checkService *service.KYCCheckService) *KYCCheckHandler {
+	return &KYCCheckHandler{checkService: checkService}
+}
+
+// ListMyChecks handles GET /api/v1/auth/kyc/checks
+func (h *KYCCheckHandler) ListMyChecks(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	checks, err := h.checkService.ListChecks(r.Context(), user.ID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, checks)
+}
+
+// RequestAadhaarOTP handles POST /api/v1/auth/kyc/aadhaar/otp
+func (h *KYCCheckHandler) RequestAadhaarOTP(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	check, err := h.checkService.RequestAadhaarOTP(r.Context(), user.ID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, check)
+}
+
+// VerifyAadhaarOTP handles POST /api/v1/auth/kyc/aadhaar/verify
+func (h *KYCCheckHandler) VerifyAadhaarOTP(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.VerifyAadhaarOTPRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	check, svcErr := h.checkService.VerifyAadhaarOTP(r.Context(), user.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, check)
+}
+
+// FaceMatch handles POST /api/v1/auth/kyc/face-match
+func (h *KYCCheckHandler) FaceMatch(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSelfieBytes))
+	if err != nil {
+		response.Error(w, errors.BadRequest("selfie image is too large or unreadable"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.FaceMatchRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	check, svcErr := h.checkService.FaceMatch(r.Context(), user.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Created(w, check)
+}
+
+// ListUserChecks handles GET /api/v1/admin/users/{id}/kyc-checks
+func (h *KYCCheckHandler) ListUserChecks(w http.ResponseWriter, r *http.Request) {
+	checks, err := h.checkService.ListChecks(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, checks)
+}
+
+// RunPANCheck handles POST /api/v1/admin/users/{id}/kyc-checks/pan, re-running
+// the PAN check, e.g. after the provider was unavailable.
+func (h *KYCCheckHandler) RunPANCheck(w http.ResponseWriter, r *http.Request) {
+	check, err := h.checkService.CheckPAN(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, check)
+}
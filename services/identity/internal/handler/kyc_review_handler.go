@@ -0,0 +1,301 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// KYCReviewHandler handles the back-office KYC review queue.
+type KYCReviewHandler struct {
+	reviewService *service.KYCReviewService
+}
+
+// NewKYCReviewHandler creates a new KYC review handler.
+func NewKYCReviewHandler(reviewService *service.KYCReviewService) *KYCReviewHandler {
+	return &KYCReviewHandler{reviewService: reviewService}
+}
+
+// ListQueue handles GET /api/v1/admin/kyc/queue
+//
+// Filters: assignee (a reviewer ID, "me" or "unassigned"), sla (overdue or
+// due_soon), submitted_after and submitted_before (RFC 3339), limit, offset.
+func (h *KYCReviewHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	reviewer := getUserFromContext(r.Context())
+	if reviewer == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.KYCQueueFilter{SLA: models.KYCSLAState(query.Get("sla"))}
+
+	switch assignee := query.Get("assignee"); assignee {
+	case "":
+	case "me":
+		filter.AssignedTo = reviewer.ID
+	case "unassigned":
+		filter.Unassigned = true
+	default:
+		filter.AssignedTo = assignee
+	}
+
+	for name, dest := range map[string]**time.Time{
+		"submitted_after":  &filter.SubmittedAfter,
+		"submitted_before": &filter.SubmittedBefore,
+	} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				response.Error(w, errors.BadRequest(name+" must be an RFC 3339 timestamp"))
+				return
+			}
+			*dest = &t
+		}
+	}
+
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+	filter.Offset, _ = strconv.Atoi(query.Get("offset"))
+
+	items, err := h.reviewService.ListQueue(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, items)
+}
+
+// GetSubmission handles GET /api/v1/admin/kyc/queue/{userId}
+func (h *KYCReviewHandler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	item, err := h.reviewService.GetSubmission(r.Context(), r.PathValue("userId"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// ListReasonCodes handles GET /api/v1/admin/kyc/reason-codes
+func (h *KYCReviewHandler) ListReasonCodes(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, models.KYCReasonCodes())
+}
+
+// Claim handles POST /api/v1/admin/kyc/queue/{userId}/claim
+func (h *KYCReviewHandler) Claim(w http.ResponseWriter, r *http.Request) {
+	h.changeAssignment(w, r, h.reviewService.Claim)
+}
+
+// Release handles POST /api/v1/admin/kyc/queue/{userId}/release
+func (h *KYCReviewHandler) Release(w http.ResponseWriter, r *http.Request) {
+	h.changeAssignment(w, r, h.reviewService.Release)
+}
+
+type kycAssignmentFunc func(ctx context.Context, userID, reviewerID string) (*models.KYCQueueItem, *errors.Error)
+
+// changeAssignment claims or releases a submission for the calling reviewer.
+func (h *KYCReviewHandler) changeAssignment(w http.ResponseWriter, r *http.Request, change kycAssignmentFunc) {
+	reviewer := getUserFromContext(r.Context())
+	if reviewer == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	item, err := change(r.Context(), r.PathValue("userId"), reviewer.ID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// Assign handles POST /api/v1/admin/kyc/queue/{userId}/assign
+func (h *KYCReviewHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.AssignKYCReviewRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	item, svcErr := h.reviewService.Assign(r.Context(), r.PathValue("userId"), req.ReviewerID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// Approve handles POST /api/v1/admin/kyc/queue/{userId}/approve
+func (h *KYCReviewHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.reviewService.Approve)
+}
+
+// Reject handles POST /api/v1/admin/kyc/queue/{userId}/reject
+func (h *KYCReviewHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.reviewService.Reject)
+}
+
+type kycDecisionFunc func(ctx context.Context, userID, reviewerID string, req *models.DecideKYCRequest) (*models.KYCReviewRecord, *errors.Error)
+
+// decide decodes a reason code and note and applies the reviewer's decision.
+func (h *KYCReviewHandler) decide(w http.ResponseWriter, r *http.Request, decide kycDecisionFunc) {
+	reviewer := getUserFromContext(r.Context())
+	if reviewer == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	var req models.DecideKYCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	if req.ReasonCode == "" {
+		response.Error(w, errors.Validation("reason_code is required"))
+		return
+	}
+
+	record, err := decide(r.Context(), r.PathValue("userId"), reviewer.ID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, record)
+}
+
+// GetMetrics handles GET /api/v1/admin/kyc/metrics?from=&to=
+//
+// from and to are RFC 3339 timestamps; the default period is the last 7 days.
+func (h *KYCReviewHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var from, to time.Time
+	for name, dest := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				response.Error(w, errors.BadRequest(name+" must be an RFC 3339 timestamp"))
+				return
+			}
+			*dest = t
+		}
+	}
+
+	metrics, err := h.reviewService.Metrics(r.Context(), from, to)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, metrics)
+}
+
+// ============================================================================
+// Deprecated endpoints, kept for existing clients
+// ============================================================================
+
+// deprecatedKYCEndpoint marks a response as coming from an endpoint replaced by
+// the review queue.
+func deprecatedKYCEndpoint(w http.ResponseWriter, successor string) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", "<"+successor+">; rel=\"successor-version\"")
+}
+
+// VerifyKYCRequest represents a KYC verification request (admin only).
+type VerifyKYCRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+}
+
+// LegacyVerify approves a user's KYC with the manual_verification reason code.
+// POST /api/v1/admin/kyc/verify
+//
+// Deprecated: use POST /api/v1/admin/kyc/queue/{userId}/approve.
+func (h *KYCReviewHandler) LegacyVerify(w http.ResponseWriter, r *http.Request) {
+	deprecatedKYCEndpoint(w, "/api/v1/admin/kyc/queue/{userId}/approve")
+
+	reviewer := getUserFromContext(r.Context())
+	if reviewer == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[VerifyKYCRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	decision := &models.DecideKYCRequest{ReasonCode: models.KYCReasonManualVerification}
+	if _, svcErr := h.reviewService.Approve(r.Context(), req.UserID, reviewer.ID, decision); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RejectKYCRequest represents a KYC rejection request (admin only).
+type RejectKYCRequest struct {
+	UserID string `json:"user_id" validate:"required,uuid"`
+	Reason string `json:"reason" validate:"required,min:10,max:500"`
+}
+
+// LegacyReject rejects a user's KYC with the other reason code, using the
+// free-text reason as the note.
+// POST /api/v1/admin/kyc/reject
+//
+// Deprecated: use POST /api/v1/admin/kyc/queue/{userId}/reject.
+func (h *KYCReviewHandler) LegacyReject(w http.ResponseWriter, r *http.Request) {
+	deprecatedKYCEndpoint(w, "/api/v1/admin/kyc/queue/{userId}/reject")
+
+	reviewer := getUserFromContext(r.Context())
+	if reviewer == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[RejectKYCRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	decision := &models.DecideKYCRequest{ReasonCode: models.KYCReasonOther, Note: req.Reason}
+	if _, svcErr := h.reviewService.Reject(r.Context(), req.UserID, reviewer.ID, decision); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
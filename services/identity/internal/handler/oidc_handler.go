@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// OIDCHandler serves the OpenID Connect provider endpoints and the
+// registration of the clients that use them.
+//
+// Discovery, JWKS, token and userinfo responses are plain JSON as the OAuth
+// and OIDC specifications require, not the API's response envelope.
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+}
+
+// NewOIDCHandler creates a new OIDC handler.
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// oauthErrorResponse is the OAuth 2.0 error response body.
+type oauthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Discovery handles GET /api/v1/oauth/.well-known/openid-configuration
+func (h *OIDCHandler) Discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	response.JSON(w, http.StatusOK, h.oidcService.Discovery())
+}
+
+// JWKS handles GET /api/v1/oauth/jwks
+func (h *OIDCHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	response.JSON(w, http.StatusOK, h.oidcService.JWKS())
+}
+
+// Authorize handles GET /api/v1/oauth/authorize, where a client sends the
+// user's browser. A valid request is passed on to the consent page; errors
+// go back to the client, unless the client or its redirect URI is unknown.
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	req := authorizeRequestFromQuery(r.URL.Query())
+
+	if _, err := h.oidcService.Prompt(r.Context(), req); err != nil {
+		if service.OAuthErrorCode(err) != "" {
+			http.Redirect(w, r, h.oidcService.ErrorRedirect(req, err), http.StatusFound)
+			return
+		}
+		response.Error(w, err)
+		return
+	}
+
+	http.Redirect(w, r, h.oidcService.ConsentURL(req), http.StatusFound)
+}
+
+// GetConsent handles GET /api/v1/oauth/consent
+//
+// Returns what the consent page shows the signed-in user for the
+// authorization request in the query string.
+func (h *OIDCHandler) GetConsent(w http.ResponseWriter, r *http.Request) {
+	prompt, err := h.oidcService.Prompt(r.Context(), authorizeRequestFromQuery(r.URL.Query()))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, prompt)
+}
+
+// Consent handles POST /api/v1/oauth/consent
+//
+// Records the signed-in user's answer and returns the client URL to send
+// the browser to.
+func (h *OIDCHandler) Consent(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.ConsentRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	result, svcErr := h.oidcService.Consent(r.Context(), user.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// Token handles POST /api/v1/oauth/token
+//
+// Takes form-encoded parameters (RFC 6749 section 4.1.3). Confidential
+// clients authenticate with HTTP Basic or client_secret in the form.
+func (h *OIDCHandler) Token(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "malformed request body")
+		return
+	}
+
+	req := &models.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		ClientID:     r.PostForm.Get("client_id"),
+		ClientSecret: r.PostForm.Get("client_secret"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+	}
+	usedBasic := false
+	if id, secret, ok := r.BasicAuth(); ok {
+		// Credentials are form-encoded before being put in the header
+		id, idErr := url.QueryUnescape(id)
+		secret, secretErr := url.QueryUnescape(secret)
+		if idErr != nil || secretErr != nil {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_request", "malformed client credentials")
+			return
+		}
+		req.ClientID, req.ClientSecret, usedBasic = id, secret, true
+	}
+
+	tokens, err := h.oidcService.Token(r.Context(), req)
+	if err != nil {
+		code := service.OAuthErrorCode(err)
+		if code == "" {
+			code = "server_error"
+		}
+		if code == "invalid_client" && usedBasic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="nivo"`)
+		}
+		writeOAuthError(w, err.HTTPStatusCode(), code, err.Message)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+// UserInfo handles GET and POST /api/v1/oauth/userinfo
+//
+// Returns the claims about the user an access token from the token endpoint
+// was issued for.
+func (h *OIDCHandler) UserInfo(w http.ResponseWriter, r *http.Request) {
+	token := extractBearerToken(r)
+	if token == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="nivo"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_request", "missing access token")
+		return
+	}
+
+	info, err := h.oidcService.UserInfo(r.Context(), token)
+	if err != nil {
+		if err.Code != errors.ErrCodeUnauthorized {
+			writeOAuthError(w, err.HTTPStatusCode(), "server_error", err.Message)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="nivo", error="invalid_token"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token", err.Message)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, info)
+}
+
+// ========================================================================
+// Client Registration
+// ========================================================================
+
+// CreateClient handles POST /api/v1/oauth/clients
+func (h *OIDCHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
+	admin := getUserFromContext(r.Context())
+	if admin == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.CreateOAuthClientRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	client, svcErr := h.oidcService.CreateClient(r.Context(), admin.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Created(w, client)
+}
+
+// ListClients handles GET /api/v1/oauth/clients
+func (h *OIDCHandler) ListClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.oidcService.ListClients(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, clients)
+}
+
+// RevokeClient handles DELETE /api/v1/oauth/clients/{id}
+func (h *OIDCHandler) RevokeClient(w http.ResponseWriter, r *http.Request) {
+	if err := h.oidcService.RevokeClient(r.Context(), r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// authorizeRequestFromQuery reads authorization request parameters.
+func authorizeRequestFromQuery(query url.Values) *models.AuthorizeRequest {
+	return &models.AuthorizeRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		Nonce:               query.Get("nonce"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+}
+
+// writeOAuthError writes an OAuth 2.0 error response.
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	response.JSON(w, status, oauthErrorResponse{Error: code, ErrorDescription: description})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ProfileHandler handles profile attribute HTTP requests.
+type ProfileHandler struct {
+	profileService *service.ProfileService
+}
+
+// NewProfileHandler creates a new profile handler.
+func NewProfileHandler(profileService *service.ProfileService) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService}
+}
+
+// GetAttributes handles GET /api/v1/users/me/attributes
+func (h *ProfileHandler) GetAttributes(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	attrs, err := h.profileService.GetAttributes(r.Context(), user.ID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, attrs)
+}
+
+// UpdateAttributes handles PATCH /api/v1/users/me/attributes
+func (h *ProfileHandler) UpdateAttributes(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.UpdateProfileAttributesRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	attrs, svcErr := h.profileService.UpdateAttributes(r.Context(), user.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, attrs)
+}
+
+// ListAttributeKeys handles GET /api/v1/users/me/attributes/keys
+func (h *ProfileHandler) ListAttributeKeys(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, models.KnownProfileAttributeKeys())
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/authz"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
 	"github.com/1mb-dev/nivomoney/shared/middleware"
 )
@@ -13,19 +14,35 @@ type Router struct {
 	authHandler         *AuthHandler
 	verificationHandler *VerificationHandler
 	passwordHandler     *PasswordHandler
+	tierHandler         *TierHandler
+	kycCheckHandler     *KYCCheckHandler
+	kycReviewHandler    *KYCReviewHandler
+	contactHandler      *ContactHandler
+	profileHandler      *ProfileHandler
+	oidcHandler         *OIDCHandler
 	authMiddleware      *AuthMiddleware
 	userAdminValidation *UserAdminValidation
+	authorizer          *authz.Authorizer
+	internalSecret      string
 	metrics             *metrics.Collector
 }
 
 // NewRouter creates a new router with all handlers and middleware.
-func NewRouter(authService *service.AuthService, verificationService *service.VerificationService) *Router {
+func NewRouter(authService *service.AuthService, verificationService *service.VerificationService, tierService *service.TierService, kycCheckService *service.KYCCheckService, kycReviewService *service.KYCReviewService, profileService *service.ProfileService, oidcService *service.OIDCService, authorizer *authz.Authorizer, internalSecret string) *Router {
 	return &Router{
 		authHandler:         NewAuthHandler(authService),
 		verificationHandler: NewVerificationHandler(verificationService),
 		passwordHandler:     NewPasswordHandler(authService, verificationService),
+		tierHandler:         NewTierHandler(tierService),
+		kycCheckHandler:     NewKYCCheckHandler(kycCheckService),
+		kycReviewHandler:    NewKYCReviewHandler(kycReviewService),
+		contactHandler:      NewContactHandler(authService),
+		profileHandler:      NewProfileHandler(profileService),
+		oidcHandler:         NewOIDCHandler(oidcService),
 		authMiddleware:      NewAuthMiddleware(authService),
 		userAdminValidation: NewUserAdminValidation(authService),
+		authorizer:          authorizer,
+		internalSecret:      internalSecret,
 		metrics:             metrics.NewCollector("identity"),
 	}
 }
@@ -54,13 +71,30 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("POST /api/v1/auth/password/reset",
 		strictRateLimit(http.HandlerFunc(r.passwordHandler.ResetPassword)))
 
+	// Unlock an account locked after failed logins (token from the lockout email)
+	mux.Handle("POST /api/v1/auth/unlock",
+		strictRateLimit(http.HandlerFunc(r.authHandler.UnlockWithToken)))
+
 	// Protected routes (authentication required)
 	mux.Handle("POST /api/v1/auth/logout",
 		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.Logout)))
 
+	mux.Handle("POST /api/v1/auth/refresh",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.RefreshToken)))
+
 	mux.Handle("POST /api/v1/auth/logout-all",
 		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.LogoutAll)))
 
+	// Session management (list and revoke own sessions)
+	mux.Handle("GET /api/v1/profile/sessions",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.ListSessions)))
+
+	mux.Handle("DELETE /api/v1/profile/sessions/{id}",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.RevokeSession)))
+
+	mux.Handle("DELETE /api/v1/profile/sessions",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.LogoutAll)))
+
 	mux.Handle("GET /api/v1/auth/me",
 		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.GetProfile)))
 
@@ -88,6 +122,59 @@ func (r *Router) SetupRoutes() http.Handler {
 		r.authMiddleware.Authenticate(
 			http.HandlerFunc(r.passwordHandler.CompletePasswordChange)))
 
+	// ========================================================================
+	// Contact Change Routes (code sent to the new address, then confirmed)
+	// ========================================================================
+
+	mux.Handle("POST /api/v1/users/me/email/change",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.RequestEmailChange))))
+
+	mux.Handle("POST /api/v1/users/me/email/confirm",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.ConfirmEmailChange))))
+
+	mux.Handle("POST /api/v1/users/me/phone/change",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.RequestPhoneChange))))
+
+	mux.Handle("POST /api/v1/users/me/phone/confirm",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.ConfirmPhoneChange))))
+
+	// ========================================================================
+	// Contact Verification Routes (code sent to the current address)
+	// ========================================================================
+
+	mux.Handle("POST /api/v1/users/me/email/verification",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.SendEmailVerification))))
+
+	mux.Handle("POST /api/v1/users/me/email/verify",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.VerifyEmail))))
+
+	mux.Handle("POST /api/v1/users/me/phone/verification",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.SendPhoneVerification))))
+
+	mux.Handle("POST /api/v1/users/me/phone/verify",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.contactHandler.VerifyPhone))))
+
+	// ========================================================================
+	// Profile Attributes (locale, timezone, currency, consent, quiet hours)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/users/me/attributes",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.profileHandler.GetAttributes)))
+
+	mux.Handle("PATCH /api/v1/users/me/attributes",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.profileHandler.UpdateAttributes)))
+
+	mux.Handle("GET /api/v1/users/me/attributes/keys",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.profileHandler.ListAttributeKeys)))
+
 	// User lookup (rate limited to prevent phone number enumeration)
 	mux.Handle("GET /api/v1/users/lookup",
 		strictRateLimit(
@@ -99,12 +186,29 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("PUT /api/v1/auth/kyc",
 		r.authMiddleware.Authenticate(http.HandlerFunc(r.authHandler.UpdateKYC)))
 
+	// KYC provider checks (results feed the admin review)
+	mux.Handle("GET /api/v1/auth/kyc/checks",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.kycCheckHandler.ListMyChecks)))
+
+	mux.Handle("POST /api/v1/auth/kyc/aadhaar/otp",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.kycCheckHandler.RequestAadhaarOTP))))
+
+	mux.Handle("POST /api/v1/auth/kyc/aadhaar/verify",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.kycCheckHandler.VerifyAadhaarOTP))))
+
+	mux.Handle("POST /api/v1/auth/kyc/face-match",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.kycCheckHandler.FaceMatch))))
+
 	// Admin routes (authentication + permission required) - with strict rate limiting
 	kycVerifyPermission := r.authMiddleware.RequirePermission("identity:kyc:verify")
 	kycRejectPermission := r.authMiddleware.RequirePermission("identity:kyc:reject")
 	kycListPermission := r.authMiddleware.RequirePermission("identity:kyc:list")
 	userSuspendPermission := r.authMiddleware.RequirePermission("identity:user:suspend")
 	userUnsuspendPermission := r.authMiddleware.RequirePermission("identity:user:unsuspend")
+	userUpdatePermission := r.authMiddleware.RequirePermission("identity:users:update")
 
 	mux.Handle("GET /api/v1/admin/kyc/pending",
 		strictRateLimit(
@@ -121,20 +225,87 @@ func (r *Router) SetupRoutes() http.Handler {
 			r.authMiddleware.Authenticate(
 				kycListPermission(http.HandlerFunc(r.authHandler.SearchUsers)))))
 
+	// Staff, and User-Admin accounts for their paired user
+	userReadAccess := RequireUserAccess(r.authorizer, ActionUserRead)
+
 	mux.Handle("GET /api/v1/admin/users/{id}",
 		strictRateLimit(
 			r.authMiddleware.Authenticate(
-				kycListPermission(http.HandlerFunc(r.authHandler.GetUserDetails)))))
+				userReadAccess(http.HandlerFunc(r.authHandler.GetUserDetails)))))
+
+	mux.Handle("GET /api/v1/admin/users/{id}/kyc-checks",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userReadAccess(http.HandlerFunc(r.kycCheckHandler.ListUserChecks)))))
+
+	// Admin console sections the caller's roles open
+	mux.Handle("GET /api/v1/admin/scopes",
+		r.authMiddleware.Authenticate(AdminScopesHandler(r.authorizer)))
+
+	mux.Handle("POST /api/v1/admin/users/{id}/kyc-checks/pan",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycVerifyPermission(http.HandlerFunc(r.kycCheckHandler.RunPANCheck)))))
+
+	// ========================================================================
+	// KYC Review Queue (claim, decide with a reason code, SLA, metrics)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/admin/kyc/queue",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycListPermission(http.HandlerFunc(r.kycReviewHandler.ListQueue)))))
+
+	mux.Handle("GET /api/v1/admin/kyc/queue/{userId}",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycListPermission(http.HandlerFunc(r.kycReviewHandler.GetSubmission)))))
+
+	mux.Handle("GET /api/v1/admin/kyc/reason-codes",
+		r.authMiddleware.Authenticate(
+			kycListPermission(http.HandlerFunc(r.kycReviewHandler.ListReasonCodes))))
+
+	mux.Handle("GET /api/v1/admin/kyc/metrics",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycListPermission(http.HandlerFunc(r.kycReviewHandler.GetMetrics)))))
 
+	mux.Handle("POST /api/v1/admin/kyc/queue/{userId}/claim",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycVerifyPermission(http.HandlerFunc(r.kycReviewHandler.Claim)))))
+
+	mux.Handle("POST /api/v1/admin/kyc/queue/{userId}/release",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycVerifyPermission(http.HandlerFunc(r.kycReviewHandler.Release)))))
+
+	// Handing work to another reviewer is a supervisor action
+	mux.Handle("POST /api/v1/admin/kyc/queue/{userId}/assign",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUpdatePermission(http.HandlerFunc(r.kycReviewHandler.Assign)))))
+
+	mux.Handle("POST /api/v1/admin/kyc/queue/{userId}/approve",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycVerifyPermission(http.HandlerFunc(r.kycReviewHandler.Approve)))))
+
+	mux.Handle("POST /api/v1/admin/kyc/queue/{userId}/reject",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				kycRejectPermission(http.HandlerFunc(r.kycReviewHandler.Reject)))))
+
+	// Deprecated: decide through the review queue above
 	mux.Handle("POST /api/v1/admin/kyc/verify",
 		strictRateLimit(
 			r.authMiddleware.Authenticate(
-				kycVerifyPermission(http.HandlerFunc(r.authHandler.VerifyKYC)))))
+				kycVerifyPermission(http.HandlerFunc(r.kycReviewHandler.LegacyVerify)))))
 
 	mux.Handle("POST /api/v1/admin/kyc/reject",
 		strictRateLimit(
 			r.authMiddleware.Authenticate(
-				kycRejectPermission(http.HandlerFunc(r.authHandler.RejectKYC)))))
+				kycRejectPermission(http.HandlerFunc(r.kycReviewHandler.LegacyReject)))))
 
 	mux.Handle("POST /api/v1/admin/users/{id}/suspend",
 		strictRateLimit(
@@ -146,6 +317,48 @@ func (r *Router) SetupRoutes() http.Handler {
 			r.authMiddleware.Authenticate(
 				userUnsuspendPermission(http.HandlerFunc(r.authHandler.UnsuspendUser)))))
 
+	mux.Handle("POST /api/v1/admin/users/{id}/unlock",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUnsuspendPermission(http.HandlerFunc(r.authHandler.UnlockAccount)))))
+
+	mux.Handle("POST /api/v1/admin/users/{id}/force-logout",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUpdatePermission(http.HandlerFunc(r.authHandler.ForceLogout)))))
+
+	// ========================================================================
+	// Account Tier Routes
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/tiers",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.tierHandler.ListTiers)))
+
+	mux.Handle("GET /api/v1/users/me/tier",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.tierHandler.GetMyTier)))
+
+	mux.Handle("POST /api/v1/users/me/tier/requests",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.tierHandler.RequestTierChange))))
+
+	mux.Handle("DELETE /api/v1/users/me/tier/requests/{id}",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.tierHandler.CancelTierChange)))
+
+	mux.Handle("GET /api/v1/admin/tier-requests",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUpdatePermission(http.HandlerFunc(r.tierHandler.ListTierChangeRequests)))))
+
+	mux.Handle("POST /api/v1/admin/tier-requests/{id}/approve",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUpdatePermission(http.HandlerFunc(r.tierHandler.ApproveTierChange)))))
+
+	mux.Handle("POST /api/v1/admin/tier-requests/{id}/reject",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				userUpdatePermission(http.HandlerFunc(r.tierHandler.RejectTierChange)))))
+
 	// ========================================================================
 	// Verification Routes (OTP-based verification for sensitive operations)
 	// ========================================================================
@@ -194,6 +407,59 @@ func (r *Router) SetupRoutes() http.Handler {
 				r.userAdminValidation.LoadPairedUserID(
 					http.HandlerFunc(r.authHandler.GetPairedUserProfile)))))
 
+	// ========================================================================
+	// OIDC Provider ("Login with Nivo": authorization code flow with PKCE)
+	// ========================================================================
+
+	mux.HandleFunc("GET /api/v1/oauth/.well-known/openid-configuration", r.oidcHandler.Discovery)
+	mux.HandleFunc("GET /api/v1/oauth/jwks", r.oidcHandler.JWKS)
+
+	// The client sends the browser here; it is passed on to the consent page
+	mux.Handle("GET /api/v1/oauth/authorize",
+		authRateLimit(http.HandlerFunc(r.oidcHandler.Authorize)))
+
+	// The consent page shows the request to the signed-in user and records the answer
+	mux.Handle("GET /api/v1/oauth/consent",
+		r.authMiddleware.Authenticate(http.HandlerFunc(r.oidcHandler.GetConsent)))
+
+	mux.Handle("POST /api/v1/oauth/consent",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(http.HandlerFunc(r.oidcHandler.Consent))))
+
+	// Clients authenticate themselves; users are identified by the code or access token
+	mux.Handle("POST /api/v1/oauth/token",
+		authRateLimit(http.HandlerFunc(r.oidcHandler.Token)))
+
+	mux.Handle("GET /api/v1/oauth/userinfo",
+		authRateLimit(http.HandlerFunc(r.oidcHandler.UserInfo)))
+
+	mux.Handle("POST /api/v1/oauth/userinfo",
+		authRateLimit(http.HandlerFunc(r.oidcHandler.UserInfo)))
+
+	oauthClientsPermission := r.authMiddleware.RequirePermission("identity:oauth_clients:manage")
+
+	mux.Handle("GET /api/v1/oauth/clients",
+		r.authMiddleware.Authenticate(
+			oauthClientsPermission(http.HandlerFunc(r.oidcHandler.ListClients))))
+
+	mux.Handle("POST /api/v1/oauth/clients",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				oauthClientsPermission(http.HandlerFunc(r.oidcHandler.CreateClient)))))
+
+	mux.Handle("DELETE /api/v1/oauth/clients/{id}",
+		strictRateLimit(
+			r.authMiddleware.Authenticate(
+				oauthClientsPermission(http.HandlerFunc(r.oidcHandler.RevokeClient)))))
+
+	// ========================================================================
+	// Internal Endpoints (service-to-service)
+	// ========================================================================
+
+	// Privacy-safe names for showing users to each other, e.g. transfer counterparties
+	mux.HandleFunc("POST /internal/v1/users/display-names",
+		middleware.InternalAuthFunc(r.internalSecret, r.authHandler.GetDisplayNames))
+
 	// Health check endpoint
 	mux.HandleFunc("GET /health", healthCheck)
 
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// TierHandler handles account tier HTTP requests.
+type TierHandler struct {
+	tierService *service.TierService
+}
+
+// NewTierHandler creates a new tier handler.
+func NewTierHandler(tierService *service.TierService) *TierHandler {
+	return &TierHandler{tierService: tierService}
+}
+
+// ListTiers handles GET /api/v1/tiers
+func (h *TierHandler) ListTiers(w http.ResponseWriter, r *http.Request) {
+	tiers, err := h.tierService.ListTiers(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, tiers)
+}
+
+// GetMyTier handles GET /api/v1/users/me/tier
+func (h *TierHandler) GetMyTier(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	tier, err := h.tierService.GetUserTier(r.Context(), user.ID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, tier)
+}
+
+// RequestTierChange handles POST /api/v1/users/me/tier/requests
+func (h *TierHandler) RequestTierChange(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, err := model.ParseInto[models.CreateTierChangeRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	change, svcErr := h.tierService.RequestTierChange(r.Context(), user.ID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	if change.Status == models.TierChangeApproved {
+		response.OK(w, change)
+		return
+	}
+	response.Created(w, change)
+}
+
+// CancelTierChange handles DELETE /api/v1/users/me/tier/requests/{id}
+func (h *TierHandler) CancelTierChange(w http.ResponseWriter, r *http.Request) {
+	user := getUserFromContext(r.Context())
+	if user == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	change, err := h.tierService.CancelTierChange(r.Context(), user.ID, r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, change)
+}
+
+// ListTierChangeRequests handles GET /api/v1/admin/tier-requests
+func (h *TierHandler) ListTierChangeRequests(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := models.TierChangeStatus(query.Get("status"))
+	switch status {
+	case "", models.TierChangePending, models.TierChangeApproved, models.TierChangeRejected, models.TierChangeCancelled:
+	default:
+		response.Error(w, errors.BadRequest("invalid status filter"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	requests, err := h.tierService.ListTierChangeRequests(r.Context(), status, limit, offset)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, requests)
+}
+
+// ApproveTierChange handles POST /api/v1/admin/tier-requests/{id}/approve
+func (h *TierHandler) ApproveTierChange(w http.ResponseWriter, r *http.Request) {
+	h.reviewTierChange(w, r, h.tierService.ApproveTierChange)
+}
+
+// RejectTierChange handles POST /api/v1/admin/tier-requests/{id}/reject
+func (h *TierHandler) RejectTierChange(w http.ResponseWriter, r *http.Request) {
+	h.reviewTierChange(w, r, h.tierService.RejectTierChange)
+}
+
+type tierReviewFunc func(ctx context.Context, requestID, reviewerID, note string) (*models.TierChangeRequest, *errors.Error)
+
+// reviewTierChange decodes an optional review note and applies an admin decision.
+func (h *TierHandler) reviewTierChange(w http.ResponseWriter, r *http.Request, review tierReviewFunc) {
+	adminUser := getUserFromContext(r.Context())
+	if adminUser == nil {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	var req models.ReviewTierChangeRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			response.Error(w, errors.BadRequest("invalid request body"))
+			return
+		}
+	}
+
+	change, svcErr := review(r.Context(), r.PathValue("id"), adminUser.ID, req.Note)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, change)
+}
@@ -0,0 +1,85 @@
+package kyc
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MockOTP is the only Aadhaar OTP the mock provider accepts.
+const MockOTP = "123456"
+
+var panPattern = regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+
+// MockProvider answers checks locally with deterministic results, for
+// development and tests.
+//
+//   - PAN passes when well formed and issued to an individual (4th letter P)
+//   - Aadhaar OTP passes with MockOTP
+//   - Face match scores 0.95 for any image, or 0.4 when the image data
+//     contains "mismatch"
+type MockProvider struct {
+	faceMatchScore float64
+}
+
+// NewMockProvider creates a mock provider.
+func NewMockProvider(faceMatchScore float64) *MockProvider {
+	return &MockProvider{faceMatchScore: faceMatchScore}
+}
+
+// Name implements Provider.
+func (p *MockProvider) Name() string { return "mock" }
+
+// VerifyPAN implements Provider.
+func (p *MockProvider) VerifyPAN(_ context.Context, req PANRequest) (*Result, error) {
+	pan := strings.ToUpper(req.PAN)
+	ref := "mock-pan-" + uuid.NewString()
+	if !panPattern.MatchString(pan) {
+		return &Result{Status: CheckStatusFailed, ReferenceID: ref, Reason: "PAN format is invalid"}, nil
+	}
+	if pan[3] != 'P' {
+		return &Result{Status: CheckStatusFailed, ReferenceID: ref, Reason: "PAN is not issued to an individual"}, nil
+	}
+	score := 1.0
+	return &Result{Status: CheckStatusPassed, ReferenceID: ref, Score: &score}, nil
+}
+
+// RequestAadhaarOTP implements Provider.
+func (p *MockProvider) RequestAadhaarOTP(_ context.Context, aadhaar string) (*Result, error) {
+	if len(aadhaar) != 12 {
+		return &Result{Status: CheckStatusFailed, Reason: "Aadhaar number must have 12 digits"}, nil
+	}
+	return &Result{Status: CheckStatusPending, ReferenceID: "mock-aadhaar-" + uuid.NewString()}, nil
+}
+
+// VerifyAadhaarOTP implements Provider.
+func (p *MockProvider) VerifyAadhaarOTP(_ context.Context, req AadhaarOTPRequest) (*Result, error) {
+	if !strings.HasPrefix(req.ReferenceID, "mock-aadhaar-") {
+		return &Result{Status: CheckStatusFailed, ReferenceID: req.ReferenceID, Reason: "unknown OTP reference"}, nil
+	}
+	if req.OTP != MockOTP {
+		return &Result{Status: CheckStatusFailed, ReferenceID: req.ReferenceID, Reason: "OTP does not match"}, nil
+	}
+	return &Result{Status: CheckStatusPassed, ReferenceID: req.ReferenceID}, nil
+}
+
+// FaceMatch implements Provider.
+func (p *MockProvider) FaceMatch(_ context.Context, req FaceMatchRequest) (*Result, error) {
+	score := 0.95
+	if strings.Contains(req.SelfieImage, "mismatch") {
+		score = 0.4
+	}
+	return faceMatchResult("mock-face-"+uuid.NewString(), score, p.faceMatchScore), nil
+}
+
+// faceMatchResult applies the pass threshold to a face match score.
+func faceMatchResult(ref string, score, threshold float64) *Result {
+	result := &Result{Status: CheckStatusPassed, ReferenceID: ref, Score: &score}
+	if score < threshold {
+		result.Status = CheckStatusFailed
+		result.Reason = "selfie does not match ID photo"
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+// Package kyc abstracts identity checks behind a provider interface so the
+// KYC approval workflow does not depend on a specific vendor.
+package kyc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CheckStatus is the outcome of a provider check.
+type CheckStatus string
+
+const (
+	CheckStatusPassed  CheckStatus = "passed"  // Provider confirmed the data
+	CheckStatusFailed  CheckStatus = "failed"  // Provider rejected the data
+	CheckStatusPending CheckStatus = "pending" // Waiting on the user (e.g. OTP sent)
+	CheckStatusError   CheckStatus = "error"   // Provider could not be reached
+)
+
+// Provider verifies identity documents with an external KYC vendor.
+type Provider interface {
+	// Name identifies the provider in stored check results.
+	Name() string
+	// VerifyPAN checks a PAN against the income tax records.
+	VerifyPAN(ctx context.Context, req PANRequest) (*Result, error)
+	// RequestAadhaarOTP asks UIDAI to send an OTP to the Aadhaar-linked mobile.
+	RequestAadhaarOTP(ctx context.Context, aadhaar string) (*Result, error)
+	// VerifyAadhaarOTP completes an Aadhaar OTP check started by RequestAadhaarOTP.
+	VerifyAadhaarOTP(ctx context.Context, req AadhaarOTPRequest) (*Result, error)
+	// FaceMatch compares a selfie with the photo on the user's ID.
+	FaceMatch(ctx context.Context, req FaceMatchRequest) (*Result, error)
+}
+
+// PANRequest is the input to a PAN check.
+type PANRequest struct {
+	PAN         string `json:"pan"`
+	FullName    string `json:"full_name"`
+	DateOfBirth string `json:"date_of_birth"` // YYYY-MM-DD
+}
+
+// AadhaarOTPRequest is the input to an Aadhaar OTP check.
+type AadhaarOTPRequest struct {
+	ReferenceID string `json:"reference_id"` // From RequestAadhaarOTP
+	OTP         string `json:"otp"`
+}
+
+// FaceMatchRequest is the input to a face match.
+type FaceMatchRequest struct {
+	UserID      string `json:"user_id"`
+	SelfieImage string `json:"selfie_image"` // Base64-encoded JPEG or PNG
+}
+
+// Result is a provider's answer to a check.
+type Result struct {
+	Status      CheckStatus `json:"status"`
+	ReferenceID string      `json:"reference_id,omitempty"` // Provider's reference for the check
+	Score       *float64    `json:"score,omitempty"`        // Name or face match score, 0 to 1
+	Reason      string      `json:"reason,omitempty"`       // Why a check failed
+}
+
+// Config selects and configures a provider.
+type Config struct {
+	Provider       string        // "mock" (default) or "sandbox"
+	SandboxURL     string        // Base URL of the sandbox API
+	SandboxAPIKey  string        // Sent as X-API-Key
+	Timeout        time.Duration // Per-request timeout for the sandbox
+	FaceMatchScore float64       // Minimum face match score to pass
+}
+
+// ConfigFromEnv reads provider configuration from KYC_* environment variables.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Provider:       os.Getenv("KYC_PROVIDER"),
+		SandboxURL:     os.Getenv("KYC_SANDBOX_URL"),
+		SandboxAPIKey:  os.Getenv("KYC_SANDBOX_API_KEY"),
+		Timeout:        10 * time.Second,
+		FaceMatchScore: 0.8,
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = "mock"
+	}
+	if v, err := time.ParseDuration(os.Getenv("KYC_SANDBOX_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("KYC_FACE_MATCH_THRESHOLD"), 64); err == nil && v > 0 && v <= 1 {
+		cfg.FaceMatchScore = v
+	}
+	return cfg
+}
+
+// NewProvider creates the provider named in the config.
+func NewProvider(cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case "", "mock":
+		return NewMockProvider(cfg.FaceMatchScore), nil
+	case "sandbox":
+		if cfg.SandboxURL == "" {
+			return nil, fmt.Errorf("KYC_SANDBOX_URL is required for the sandbox provider")
+		}
+		return NewSandboxProvider(cfg.SandboxURL, cfg.SandboxAPIKey, cfg.Timeout, cfg.FaceMatchScore), nil
+	default:
+		return nil, fmt.Errorf("unknown KYC provider %q", cfg.Provider)
+	}
+}
@@ -0,0 +1,92 @@
+package kyc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMockProvider(t *testing.T) {
+	p := NewMockProvider(0.8)
+	ctx := context.Background()
+
+	tests := []struct {
+		pan    string
+		status CheckStatus
+	}{
+		{"ABCPE1234F", CheckStatusPassed},
+		{"abcpe1234f", CheckStatusPassed},
+		{"ABCCE1234F", CheckStatusFailed},
+		{"NOTAPAN", CheckStatusFailed},
+	}
+	for _, tt := range tests {
+		result, err := p.VerifyPAN(ctx, PANRequest{PAN: tt.pan})
+		if err != nil || result.Status != tt.status {
+			t.Errorf("VerifyPAN(%s) = %+v, %v; want %s", tt.pan, result, err, tt.status)
+		}
+	}
+
+	otp, _ := p.RequestAadhaarOTP(ctx, "123412341234")
+	if otp.Status != CheckStatusPending || otp.ReferenceID == "" {
+		t.Fatalf("expected pending OTP with reference, got %+v", otp)
+	}
+	if wrong, _ := p.VerifyAadhaarOTP(ctx, AadhaarOTPRequest{ReferenceID: otp.ReferenceID, OTP: "000000"}); wrong.Status != CheckStatusFailed {
+		t.Errorf("expected wrong OTP to fail, got %s", wrong.Status)
+	}
+	if ok, _ := p.VerifyAadhaarOTP(ctx, AadhaarOTPRequest{ReferenceID: otp.ReferenceID, OTP: MockOTP}); ok.Status != CheckStatusPassed {
+		t.Errorf("expected mock OTP to pass, got %s", ok.Status)
+	}
+}
+
+func TestSandboxProvider(t *testing.T) {
+	var apiKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKeys = append(apiKeys, r.Header.Get("X-API-Key"))
+		switch r.URL.Path {
+		case "/v1/pan/verify":
+			var req PANRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			_ = json.NewEncoder(w).Encode(Result{Status: CheckStatusPassed, ReferenceID: "pan-" + req.PAN})
+		case "/v1/face/match":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"reference_id": "face-1", "score": 0.7})
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	p := NewSandboxProvider(server.URL+"/", "key-1", time.Second, 0.8)
+	ctx := context.Background()
+
+	result, err := p.VerifyPAN(ctx, PANRequest{PAN: "ABCPE1234F"})
+	if err != nil || result.Status != CheckStatusPassed || result.ReferenceID != "pan-ABCPE1234F" {
+		t.Errorf("unexpected PAN result: %+v, %v", result, err)
+	}
+
+	match, err := p.FaceMatch(ctx, FaceMatchRequest{SelfieImage: "img"})
+	if err != nil || match.Status != CheckStatusFailed || match.Score == nil || *match.Score != 0.7 {
+		t.Errorf("expected local threshold to fail score 0.7, got %+v, %v", match, err)
+	}
+
+	if _, err := p.RequestAadhaarOTP(ctx, "123412341234"); err == nil {
+		t.Error("expected provider error status to be returned")
+	}
+	if apiKeys[0] != "key-1" {
+		t.Errorf("expected API key header, got %q", apiKeys[0])
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	if p, err := NewProvider(Config{}); err != nil || p.Name() != "mock" {
+		t.Errorf("expected mock by default, got %v, %v", p, err)
+	}
+	if _, err := NewProvider(Config{Provider: "sandbox"}); err == nil {
+		t.Error("expected sandbox without URL to fail")
+	}
+	if _, err := NewProvider(Config{Provider: "acme"}); err == nil {
+		t.Error("expected unknown provider to fail")
+	}
+}
@@ -0,0 +1,108 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SandboxProvider calls a vendor sandbox over HTTP. The API shape below is the
+// contract real provider adapters are expected to follow:
+//
+//	POST /v1/pan/verify          PANRequest        -> Result
+//	POST /v1/aadhaar/otp         {"aadhaar": ...}  -> Result (pending + reference_id)
+//	POST /v1/aadhaar/otp/verify  AadhaarOTPRequest -> Result
+//	POST /v1/face/match          FaceMatchRequest  -> {"reference_id", "score"}
+type SandboxProvider struct {
+	baseURL        string
+	apiKey         string
+	httpClient     *http.Client
+	faceMatchScore float64
+}
+
+// NewSandboxProvider creates a sandbox provider.
+func NewSandboxProvider(baseURL, apiKey string, timeout time.Duration, faceMatchScore float64) *SandboxProvider {
+	return &SandboxProvider{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		apiKey:         apiKey,
+		httpClient:     &http.Client{Timeout: timeout},
+		faceMatchScore: faceMatchScore,
+	}
+}
+
+// Name implements Provider.
+func (p *SandboxProvider) Name() string { return "sandbox" }
+
+// VerifyPAN implements Provider.
+func (p *SandboxProvider) VerifyPAN(ctx context.Context, req PANRequest) (*Result, error) {
+	var result Result
+	if err := p.post(ctx, "/v1/pan/verify", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RequestAadhaarOTP implements Provider.
+func (p *SandboxProvider) RequestAadhaarOTP(ctx context.Context, aadhaar string) (*Result, error) {
+	var result Result
+	if err := p.post(ctx, "/v1/aadhaar/otp", map[string]string{"aadhaar": aadhaar}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// VerifyAadhaarOTP implements Provider.
+func (p *SandboxProvider) VerifyAadhaarOTP(ctx context.Context, req AadhaarOTPRequest) (*Result, error) {
+	var result Result
+	if err := p.post(ctx, "/v1/aadhaar/otp/verify", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FaceMatch implements Provider. The pass threshold is applied locally so it
+// is the same whichever vendor scores the images.
+func (p *SandboxProvider) FaceMatch(ctx context.Context, req FaceMatchRequest) (*Result, error) {
+	var match struct {
+		ReferenceID string  `json:"reference_id"`
+		Score       float64 `json:"score"`
+	}
+	if err := p.post(ctx, "/v1/face/match", req, &match); err != nil {
+		return nil, err
+	}
+	return faceMatchResult(match.ReferenceID, match.Score, p.faceMatchScore), nil
+}
+
+func (p *SandboxProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("X-API-Key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("KYC provider request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("KYC provider returned status %d for %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode KYC provider response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ContactChannel identifies which contact detail a change applies to.
+type ContactChannel string
+
+const (
+	ContactChannelEmail ContactChannel = "email"
+	ContactChannelPhone ContactChannel = "phone"
+)
+
+// ContactChangeStatus is the state of a contact change request.
+type ContactChangeStatus string
+
+const (
+	ContactChangePending   ContactChangeStatus = "pending"
+	ContactChangeConfirmed ContactChangeStatus = "confirmed"
+	ContactChangeExpired   ContactChangeStatus = "expired"
+	ContactChangeCancelled ContactChangeStatus = "cancelled"
+)
+
+// ContactChange is a request to move a user's email or phone to a new
+// address. The change is applied once the code sent to the new address is
+// confirmed.
+type ContactChange struct {
+	ID           string              `json:"id"`
+	UserID       string              `json:"user_id"`
+	Channel      ContactChannel      `json:"channel"`
+	NewValue     string              `json:"new_value"`
+	CodeHash     string              `json:"-"`
+	Status       ContactChangeStatus `json:"status"`
+	AttemptCount int                 `json:"attempt_count"`
+	ExpiresAt    models.Timestamp    `json:"expires_at"`
+	CreatedAt    models.Timestamp    `json:"created_at"`
+	ConfirmedAt  *models.Timestamp   `json:"confirmed_at,omitempty"`
+}
+
+// IsExpired checks if the change request has expired.
+func (c *ContactChange) IsExpired() bool {
+	return time.Now().After(c.ExpiresAt.Time)
+}
+
+// ChangeEmailRequest starts an email change.
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// ChangePhoneRequest starts a phone number change.
+type ChangePhoneRequest struct {
+	NewPhone        string `json:"new_phone" validate:"required,indian_phone"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// ConfirmContactChangeRequest confirms a change with the code sent to the new address.
+type ConfirmContactChangeRequest struct {
+	ChangeID string `json:"change_id" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// VerifyContactRequest verifies the current email or phone with the code sent to it.
+type VerifyContactRequest struct {
+	VerificationID string `json:"verification_id" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
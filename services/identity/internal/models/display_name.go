@@ -0,0 +1,19 @@
+package models
+
+// MaxDisplayNameLookup bounds how many users one display name lookup resolves.
+const MaxDisplayNameLookup = 100
+
+// UserDisplayName is how a user is shown to other users, such as the
+// counterparty of a transfer. It never carries the full name, email or full
+// phone number.
+type UserDisplayName struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`           // First name and last initial, e.g. "Priya S."
+	MaskedPhone string `json:"masked_phone,omitempty"` // Last four digits, e.g. "******3210"
+}
+
+// DisplayNamesRequest represents an internal request for the display names
+// of several users.
+type DisplayNamesRequest struct {
+	UserIDs []string `json:"user_ids" validate:"required"`
+}
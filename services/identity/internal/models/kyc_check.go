@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// KYCCheckType identifies which provider check was run.
+type KYCCheckType string
+
+const (
+	KYCCheckPAN        KYCCheckType = "pan"
+	KYCCheckAadhaarOTP KYCCheckType = "aadhaar_otp"
+	KYCCheckFaceMatch  KYCCheckType = "face_match"
+)
+
+// KYCCheckStatus is the outcome of a provider check.
+type KYCCheckStatus string
+
+const (
+	KYCCheckPassed  KYCCheckStatus = "passed"
+	KYCCheckFailed  KYCCheckStatus = "failed"
+	KYCCheckPending KYCCheckStatus = "pending" // Waiting on the user, e.g. for an OTP
+	KYCCheckError   KYCCheckStatus = "error"   // Provider was unavailable
+)
+
+// KYCCheck records one provider check. Checks inform the admin's KYC review;
+// they do not approve or reject KYC on their own.
+type KYCCheck struct {
+	ID          string            `json:"id"`
+	UserID      string            `json:"user_id"`
+	Provider    string            `json:"provider"`
+	CheckType   KYCCheckType      `json:"check_type"`
+	Status      KYCCheckStatus    `json:"status"`
+	ReferenceID *string           `json:"reference_id,omitempty"`
+	Score       *float64          `json:"score,omitempty"`
+	Reason      *string           `json:"reason,omitempty"`
+	CreatedAt   models.Timestamp  `json:"created_at"`
+	CompletedAt *models.Timestamp `json:"completed_at,omitempty"`
+}
+
+// VerifyAadhaarOTPRequest completes an Aadhaar OTP check.
+type VerifyAadhaarOTPRequest struct {
+	ReferenceID string `json:"reference_id" validate:"required"`
+	OTP         string `json:"otp" validate:"required,min=4,max=8"`
+}
+
+// FaceMatchRequest submits a selfie for face matching.
+type FaceMatchRequest struct {
+	SelfieImage string `json:"selfie_image" validate:"required"` // Base64-encoded JPEG or PNG
+}
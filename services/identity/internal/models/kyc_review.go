@@ -0,0 +1,180 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// KYCDecision is a reviewer's verdict on a KYC submission.
+type KYCDecision string
+
+const (
+	KYCDecisionApproved KYCDecision = "approved"
+	KYCDecisionRejected KYCDecision = "rejected"
+)
+
+// KYCReasonCode is the structured reason recorded with a KYC decision.
+type KYCReasonCode string
+
+// Approval reason codes.
+const (
+	KYCReasonDocumentsVerified    KYCReasonCode = "documents_verified"
+	KYCReasonProviderChecksPassed KYCReasonCode = "provider_checks_passed"
+	KYCReasonManualVerification   KYCReasonCode = "manual_verification"
+)
+
+// Rejection reason codes.
+const (
+	KYCReasonPANInvalid         KYCReasonCode = "pan_invalid"
+	KYCReasonPANNameMismatch    KYCReasonCode = "pan_name_mismatch"
+	KYCReasonAadhaarMismatch    KYCReasonCode = "aadhaar_mismatch"
+	KYCReasonFaceMismatch       KYCReasonCode = "face_mismatch"
+	KYCReasonDOBMismatch        KYCReasonCode = "dob_mismatch"
+	KYCReasonUnderage           KYCReasonCode = "underage"
+	KYCReasonAddressIncomplete  KYCReasonCode = "address_incomplete"
+	KYCReasonDocumentUnreadable KYCReasonCode = "document_unreadable"
+	KYCReasonDuplicateIdentity  KYCReasonCode = "duplicate_identity"
+	KYCReasonSuspectedFraud     KYCReasonCode = "suspected_fraud"
+	KYCReasonOther              KYCReasonCode = "other" // Requires a note
+)
+
+// KYCReasonCodeInfo describes a reason code for the back-office console.
+type KYCReasonCodeInfo struct {
+	Code         KYCReasonCode `json:"code"`
+	Decision     KYCDecision   `json:"decision"`
+	Description  string        `json:"description"`
+	NoteRequired bool          `json:"note_required"`
+}
+
+// kycReasonCodes lists every reason code in display order. The description
+// of a rejection code is what the user is told.
+var kycReasonCodes = []KYCReasonCodeInfo{
+	{Code: KYCReasonDocumentsVerified, Decision: KYCDecisionApproved, Description: "Documents verified by the reviewer"},
+	{Code: KYCReasonProviderChecksPassed, Decision: KYCDecisionApproved, Description: "Provider checks passed"},
+	{Code: KYCReasonManualVerification, Decision: KYCDecisionApproved, Description: "Verified manually"},
+	{Code: KYCReasonPANInvalid, Decision: KYCDecisionRejected, Description: "PAN could not be verified"},
+	{Code: KYCReasonPANNameMismatch, Decision: KYCDecisionRejected, Description: "Name does not match the PAN records"},
+	{Code: KYCReasonAadhaarMismatch, Decision: KYCDecisionRejected, Description: "Aadhaar details do not match"},
+	{Code: KYCReasonFaceMismatch, Decision: KYCDecisionRejected, Description: "Selfie does not match the ID photo"},
+	{Code: KYCReasonDOBMismatch, Decision: KYCDecisionRejected, Description: "Date of birth does not match the documents"},
+	{Code: KYCReasonUnderage, Decision: KYCDecisionRejected, Description: "Applicant is under 18"},
+	{Code: KYCReasonAddressIncomplete, Decision: KYCDecisionRejected, Description: "Address is incomplete or invalid"},
+	{Code: KYCReasonDocumentUnreadable, Decision: KYCDecisionRejected, Description: "Documents are unreadable"},
+	{Code: KYCReasonDuplicateIdentity, Decision: KYCDecisionRejected, Description: "Identity is already registered to another account"},
+	{Code: KYCReasonSuspectedFraud, Decision: KYCDecisionRejected, Description: "Submission could not be accepted"},
+	{Code: KYCReasonOther, Decision: KYCDecisionRejected, Description: "KYC could not be verified", NoteRequired: true},
+}
+
+// KYCReasonCodes returns every reason code in display order.
+func KYCReasonCodes() []KYCReasonCodeInfo {
+	codes := make([]KYCReasonCodeInfo, len(kycReasonCodes))
+	copy(codes, kycReasonCodes)
+	return codes
+}
+
+// LookupKYCReasonCode returns the description of a reason code valid for the
+// decision.
+func LookupKYCReasonCode(decision KYCDecision, code KYCReasonCode) (KYCReasonCodeInfo, bool) {
+	for _, info := range kycReasonCodes {
+		if info.Code == code && info.Decision == decision {
+			return info, true
+		}
+	}
+	return KYCReasonCodeInfo{}, false
+}
+
+// KYCSLAState filters the review queue by SLA countdown.
+type KYCSLAState string
+
+const (
+	KYCSLAOverdue KYCSLAState = "overdue"  // Past the review deadline
+	KYCSLADueSoon KYCSLAState = "due_soon" // Within the last quarter of the SLA, or overdue
+)
+
+// KYCQueueFilter selects pending KYC submissions for the review queue.
+type KYCQueueFilter struct {
+	AssignedTo      string // Reviewer user ID
+	Unassigned      bool
+	SLA             KYCSLAState
+	SubmittedAfter  *time.Time
+	SubmittedBefore *time.Time
+	Limit           int
+	Offset          int
+}
+
+// KYCReviewSLA is the review deadline of a submission.
+type KYCReviewSLA struct {
+	DueAt            models.Timestamp `json:"due_at"`
+	RemainingSeconds int64            `json:"remaining_seconds"` // Negative once overdue
+	Breached         bool             `json:"breached"`
+}
+
+// KYCQueueItem is a pending KYC submission in the review queue.
+type KYCQueueItem struct {
+	KYC         KYCInfo           `json:"kyc"`
+	User        User              `json:"user"`
+	SubmittedAt models.Timestamp  `json:"submitted_at"`
+	AssignedTo  *string           `json:"assigned_to,omitempty"`
+	AssignedAt  *models.Timestamp `json:"assigned_at,omitempty"`
+	SLA         KYCReviewSLA      `json:"sla"`
+}
+
+// KYCReviewRecord is a recorded KYC decision.
+type KYCReviewRecord struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"user_id"`
+	ReviewerID  string           `json:"reviewer_id"`
+	Decision    KYCDecision      `json:"decision"`
+	ReasonCode  KYCReasonCode    `json:"reason_code"`
+	Note        *string          `json:"note,omitempty"`
+	SubmittedAt models.Timestamp `json:"submitted_at"`
+	ClaimedAt   models.Timestamp `json:"claimed_at"`
+	SLADueAt    models.Timestamp `json:"sla_due_at"`
+	DecidedAt   models.Timestamp `json:"decided_at"`
+}
+
+// KYCReviewerMetrics is one reviewer's throughput over a period.
+type KYCReviewerMetrics struct {
+	ReviewerID           string  `json:"reviewer_id"`
+	ReviewerName         string  `json:"reviewer_name"`
+	Decisions            int     `json:"decisions"`
+	Approved             int     `json:"approved"`
+	Rejected             int     `json:"rejected"`
+	SLABreached          int     `json:"sla_breached"`
+	AvgHandlingSeconds   float64 `json:"avg_handling_seconds"`   // Claim to decision
+	AvgTurnaroundSeconds float64 `json:"avg_turnaround_seconds"` // Submission to decision
+	Assigned             int     `json:"assigned"`               // Submissions currently claimed
+}
+
+// KYCReviewMetrics reports reviewer throughput and the queue backlog.
+type KYCReviewMetrics struct {
+	From       models.Timestamp      `json:"from"`
+	To         models.Timestamp      `json:"to"`
+	Pending    int                   `json:"pending"`
+	Unassigned int                   `json:"unassigned"`
+	Overdue    int                   `json:"overdue"`
+	Reviewers  []*KYCReviewerMetrics `json:"reviewers"`
+}
+
+// KYCQueueStats counts the pending submissions in the review queue.
+type KYCQueueStats struct {
+	Pending    int
+	Unassigned int
+	Overdue    int
+	Assigned   map[string]int // Claimed submissions per reviewer
+}
+
+// AssignKYCReviewRequest assigns a submission to a reviewer.
+type AssignKYCReviewRequest struct {
+	ReviewerID string `json:"reviewer_id" validate:"required,uuid"`
+}
+
+// MaxKYCReviewNoteLength bounds the note recorded with a decision.
+const MaxKYCReviewNoteLength = 500
+
+// DecideKYCRequest approves or rejects a submission.
+type DecideKYCRequest struct {
+	ReasonCode KYCReasonCode `json:"reason_code"`
+	Note       string        `json:"note,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// LoginLockout tracks a user's failed logins and the current lockout, if any.
+// Failures reset on a successful login or an unlock.
+type LoginLockout struct {
+	UserID               string            `json:"user_id"`
+	FailedAttempts       int               `json:"failed_attempts"`
+	LockoutCount         int               `json:"lockout_count"` // Lockouts since the last successful login
+	LockedUntil          *models.Timestamp `json:"locked_until,omitempty"`
+	LastFailedAt         *models.Timestamp `json:"last_failed_at,omitempty"`
+	UnlockTokenHash      string            `json:"-"`
+	UnlockTokenExpiresAt *models.Timestamp `json:"-"`
+}
+
+// IsLocked reports whether the account is locked at the given time.
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(l.LockedUntil.Time)
+}
+
+// UnlockAccountRequest unlocks an account with the token from the lockout email.
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// OIDC scopes. openid is required on every authorization; the others add
+// claims to the ID token and userinfo response.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+// SupportedScopes lists the scopes a client may be registered for.
+var SupportedScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopePhone}
+
+// OAuthClient is an application allowed to sign users in through the OIDC
+// provider. Confidential clients authenticate with a secret at the token
+// endpoint; public clients (native and browser apps) rely on PKCE alone.
+type OAuthClient struct {
+	ID           string            `json:"client_id"`
+	Name         string            `json:"name"`
+	SecretHash   string            `json:"-"`
+	RedirectURIs []string          `json:"redirect_uris"`
+	Scopes       []string          `json:"scopes"`
+	Public       bool              `json:"public"`
+	CreatedBy    string            `json:"created_by"`
+	CreatedAt    models.Timestamp  `json:"created_at"`
+	RevokedAt    *models.Timestamp `json:"revoked_at,omitempty"`
+}
+
+// AllowsRedirectURI reports whether uri is one of the client's registered
+// redirect URIs. URIs are compared exactly.
+func (c *OAuthClient) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(c.RedirectURIs, uri)
+}
+
+// CreateOAuthClientRequest registers an OAuth client. Scopes default to
+// openid alone.
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"` // No secret; for native and browser apps
+}
+
+// OAuthClientWithSecret is a newly registered client with its secret, which
+// is shown only once.
+type OAuthClientWithSecret struct {
+	*OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizationCode is a single-use code issued to a client once the user
+// approved its authorization request.
+type AuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scope         string
+	Nonce         string
+	CodeChallenge string // PKCE S256 challenge
+	ExpiresAt     time.Time
+}
+
+// AuthorizeRequest holds the parameters of an authorization request.
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	Nonce               string `json:"nonce"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+// ConsentRequest is the user's answer to an authorization request.
+type ConsentRequest struct {
+	AuthorizeRequest
+	Approved bool `json:"approved"`
+}
+
+// AuthorizationPrompt is what the consent screen shows the user.
+type AuthorizationPrompt struct {
+	ClientID    string   `json:"client_id"`
+	ClientName  string   `json:"client_name"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+}
+
+// ConsentResponse is where the consent screen sends the browser next: the
+// client's redirect URI with a code, or with an error.
+type ConsentResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenRequest holds the parameters of a token request.
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+}
+
+// TokenResponse is the token endpoint's response.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// UserInfo holds the claims about a user released for the scopes granted.
+type UserInfo struct {
+	Subject             string `json:"sub"`
+	Name                string `json:"name,omitempty"`
+	Email               string `json:"email,omitempty"`
+	EmailVerified       *bool  `json:"email_verified,omitempty"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool  `json:"phone_number_verified,omitempty"`
+}
+
+// OIDCDiscovery is the OpenID Provider metadata served at
+// /.well-known/openid-configuration.
+type OIDCDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+// JSONWebKey is a public signing key in JWK form.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JSONWebKeySet is the provider's public signing keys.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ProfileAttributeKey names a user profile attribute.
+type ProfileAttributeKey string
+
+// Known profile attributes. Unknown keys are rejected.
+const (
+	AttrLocale           ProfileAttributeKey = "locale"            // Language tag, e.g. en-IN or hi-IN
+	AttrTimezone         ProfileAttributeKey = "timezone"          // IANA name, e.g. Asia/Kolkata
+	AttrDisplayCurrency  ProfileAttributeKey = "display_currency"  // Supported ISO 4217 code
+	AttrMarketingConsent ProfileAttributeKey = "marketing_consent" // Opt-in to marketing notifications
+	AttrQuietHoursStart  ProfileAttributeKey = "quiet_hours_start" // HH:MM in the user's timezone
+	AttrQuietHoursEnd    ProfileAttributeKey = "quiet_hours_end"   // HH:MM in the user's timezone
+)
+
+var (
+	localePattern    = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}))?$`)
+	clockTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+)
+
+// profileAttributeSpecs validates and normalizes each known attribute from
+// its JSON value to the stored string.
+var profileAttributeSpecs = map[ProfileAttributeKey]func(value interface{}) (string, error){
+	AttrLocale: func(value interface{}) (string, error) {
+		s, ok := value.(string)
+		m := localePattern.FindStringSubmatch(s)
+		if !ok || m == nil {
+			return "", fmt.Errorf("must be a language tag such as en-IN")
+		}
+		if m[2] == "" {
+			return strings.ToLower(m[1]), nil
+		}
+		return strings.ToLower(m[1]) + "-" + strings.ToUpper(m[2]), nil
+	},
+	AttrTimezone: func(value interface{}) (string, error) {
+		s, ok := value.(string)
+		if !ok || s == "" || s == "Local" {
+			return "", fmt.Errorf("must be an IANA timezone such as Asia/Kolkata")
+		}
+		if _, err := time.LoadLocation(s); err != nil {
+			return "", fmt.Errorf("unknown timezone %q", s)
+		}
+		return s, nil
+	},
+	AttrDisplayCurrency: func(value interface{}) (string, error) {
+		s, ok := value.(string)
+		currency := models.Currency(strings.ToUpper(s))
+		if !ok || currency.Validate() != nil {
+			return "", fmt.Errorf("must be a supported currency code")
+		}
+		return string(currency), nil
+	},
+	AttrMarketingConsent: func(value interface{}) (string, error) {
+		b, ok := value.(bool)
+		if !ok {
+			return "", fmt.Errorf("must be true or false")
+		}
+		return strconv.FormatBool(b), nil
+	},
+	AttrQuietHoursStart: validateClockTime,
+	AttrQuietHoursEnd:   validateClockTime,
+}
+
+func validateClockTime(value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok || !clockTimePattern.MatchString(s) {
+		return "", fmt.Errorf("must be a 24-hour time such as 22:00")
+	}
+	return s, nil
+}
+
+// KnownProfileAttributeKeys returns the accepted attribute keys, sorted.
+func KnownProfileAttributeKeys() []ProfileAttributeKey {
+	keys := make([]ProfileAttributeKey, 0, len(profileAttributeSpecs))
+	for key := range profileAttributeSpecs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// ProfileAttributes holds a user's attributes as normalized strings.
+type ProfileAttributes map[ProfileAttributeKey]string
+
+// JSON returns the attributes with their JSON types, e.g. marketing_consent
+// as a boolean.
+func (a ProfileAttributes) JSON() map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for key, value := range a {
+		if key == AttrMarketingConsent {
+			out[string(key)] = value == "true"
+			continue
+		}
+		out[string(key)] = value
+	}
+	return out
+}
+
+// MarketingConsent returns the consent flag, or nil if the user never set it.
+func (a ProfileAttributes) MarketingConsent() *bool {
+	value, ok := a[AttrMarketingConsent]
+	if !ok {
+		return nil
+	}
+	consent := value == "true"
+	return &consent
+}
+
+// Validate checks rules that span attributes: quiet hours need both a start
+// and an end, and a timezone to be interpreted in.
+func (a ProfileAttributes) Validate() error {
+	_, hasStart := a[AttrQuietHoursStart]
+	_, hasEnd := a[AttrQuietHoursEnd]
+	if hasStart != hasEnd {
+		return fmt.Errorf("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	if hasStart {
+		if a[AttrQuietHoursStart] == a[AttrQuietHoursEnd] {
+			return fmt.Errorf("quiet hours must not start and end at the same time")
+		}
+		if _, ok := a[AttrTimezone]; !ok {
+			return fmt.Errorf("quiet hours require a timezone")
+		}
+	}
+	return nil
+}
+
+// UpdateProfileAttributesRequest sets or clears profile attributes. A null
+// value clears the attribute; attributes not listed are left unchanged.
+type UpdateProfileAttributesRequest struct {
+	Attributes map[string]interface{} `json:"attributes" validate:"required"`
+}
+
+// Changes validates the request and splits it into attributes to set, with
+// normalized values, and attributes to clear.
+func (r *UpdateProfileAttributesRequest) Changes() (ProfileAttributes, []ProfileAttributeKey, error) {
+	if len(r.Attributes) == 0 {
+		return nil, nil, fmt.Errorf("at least one attribute is required")
+	}
+
+	set := make(ProfileAttributes)
+	var clear []ProfileAttributeKey
+	for name, value := range r.Attributes {
+		key := ProfileAttributeKey(name)
+		normalize, ok := profileAttributeSpecs[key]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown attribute %q", name)
+		}
+		if value == nil {
+			clear = append(clear, key)
+			continue
+		}
+		normalized, err := normalize(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %w", name, err)
+		}
+		set[key] = normalized
+	}
+	return set, clear, nil
+}
+
+// ProfileAttributesResponse is a user's attributes and when they last changed.
+type ProfileAttributesResponse struct {
+	Attributes map[string]interface{} `json:"attributes"`
+	UpdatedAt  *models.Timestamp      `json:"updated_at,omitempty"` // Null if none were ever set
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Built-in account tier codes.
+const (
+	TierRegular = "regular"
+	TierPremium = "premium"
+)
+
+// DefaultTier is the tier assigned to every new account.
+const DefaultTier = TierRegular
+
+// Support priority levels granted by a tier.
+const (
+	SupportPriorityStandard = "standard"
+	SupportPriorityPriority = "priority"
+)
+
+// AccountTier is a tier definition and the entitlements it grants.
+type AccountTier struct {
+	Code                 string           `json:"code"`
+	Name                 string           `json:"name"`
+	Rank                 int              `json:"rank"`
+	DailyTransferLimit   int64            `json:"daily_transfer_limit"`   // paise
+	MonthlyTransferLimit int64            `json:"monthly_transfer_limit"` // paise
+	MaxVirtualCards      int              `json:"max_virtual_cards"`
+	SupportPriority      string           `json:"support_priority"`
+	UpgradeFee           int64            `json:"upgrade_fee"` // paise, charged when moving into this tier
+	CreatedAt            models.Timestamp `json:"created_at"`
+}
+
+// TierChangeStatus represents the state of a tier change request.
+type TierChangeStatus string
+
+const (
+	TierChangePending   TierChangeStatus = "pending"
+	TierChangeApproved  TierChangeStatus = "approved"
+	TierChangeRejected  TierChangeStatus = "rejected"
+	TierChangeCancelled TierChangeStatus = "cancelled"
+)
+
+// TierChangeRequest is a user's request to move to another tier.
+// Upgrades wait for admin approval; downgrades are applied immediately.
+type TierChangeRequest struct {
+	ID           string            `json:"id"`
+	UserID       string            `json:"user_id"`
+	FromTier     string            `json:"from_tier"`
+	ToTier       string            `json:"to_tier"`
+	Status       TierChangeStatus  `json:"status"`
+	WalletID     *string           `json:"wallet_id,omitempty"` // Wallet the upgrade fee is charged to
+	FeeAmount    int64             `json:"fee_amount"`
+	FeeReference *string           `json:"fee_reference,omitempty"`
+	Reason       *string           `json:"reason,omitempty"`
+	ReviewedBy   *string           `json:"reviewed_by,omitempty"`
+	ReviewNote   *string           `json:"review_note,omitempty"`
+	CreatedAt    models.Timestamp  `json:"created_at"`
+	ReviewedAt   *models.Timestamp `json:"reviewed_at,omitempty"`
+}
+
+// UserTierResponse describes a user's current tier and any open change request.
+type UserTierResponse struct {
+	Tier           *AccountTier       `json:"tier"`
+	PendingRequest *TierChangeRequest `json:"pending_request,omitempty"`
+}
+
+// CreateTierChangeRequest is the payload for requesting a tier change.
+type CreateTierChangeRequest struct {
+	ToTier   string `json:"to_tier" validate:"required"`
+	WalletID string `json:"wallet_id,omitempty"` // Required when the target tier has an upgrade fee
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ReviewTierChangeRequest is the payload for approving or rejecting a tier change.
+type ReviewTierChangeRequest struct {
+	Note string `json:"note,omitempty"`
+}
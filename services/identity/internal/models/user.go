@@ -53,6 +53,10 @@ type User struct {
 	SuspensionReason *string           `json:"suspension_reason,omitempty" db:"suspension_reason"`
 	SuspendedBy      *string           `json:"suspended_by,omitempty" db:"suspended_by"` // Admin user ID
 
+	// Contact verification (set when the code sent to the address is confirmed)
+	EmailVerifiedAt *models.Timestamp `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	PhoneVerifiedAt *models.Timestamp `json:"phone_verified_at,omitempty" db:"phone_verified_at"`
+
 	// KYC Information (India-specific)
 	KYC KYCInfo `json:"kyc" db:"-"` // Embedded, stored separately
 }
@@ -83,13 +87,27 @@ type Address struct {
 
 // Session represents an active user session.
 type Session struct {
-	ID        string           `json:"id" db:"id"`
-	UserID    string           `json:"user_id" db:"user_id"`
-	Token     string           `json:"token" db:"token_hash"` // JWT token hash
-	IPAddress string           `json:"ip_address" db:"ip_address"`
-	UserAgent string           `json:"user_agent" db:"user_agent"`
-	ExpiresAt models.Timestamp `json:"expires_at" db:"expires_at"`
-	CreatedAt models.Timestamp `json:"created_at" db:"created_at"`
+	ID         string           `json:"id" db:"id"`
+	UserID     string           `json:"user_id" db:"user_id"`
+	Token      string           `json:"token" db:"token_hash"` // JWT token hash
+	IPAddress  string           `json:"ip_address" db:"ip_address"`
+	UserAgent  string           `json:"user_agent" db:"user_agent"`
+	ExpiresAt  models.Timestamp `json:"expires_at" db:"expires_at"`
+	CreatedAt  models.Timestamp `json:"created_at" db:"created_at"`
+	LastSeenAt models.Timestamp `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// SessionInfo represents an active session as shown to its owner.
+// The token hash is never exposed.
+type SessionInfo struct {
+	ID         string           `json:"id"`
+	Device     string           `json:"device"`
+	IPAddress  string           `json:"ip_address"`
+	UserAgent  string           `json:"user_agent"`
+	CreatedAt  models.Timestamp `json:"created_at"`
+	LastSeenAt models.Timestamp `json:"last_seen_at"`
+	ExpiresAt  models.Timestamp `json:"expires_at"`
+	Current    bool             `json:"current"` // True for the session making the request
 }
 
 // CreateUserRequest represents the request to create a new user (registration).
@@ -114,6 +132,8 @@ type LoginRequest struct {
 	Identifier string     `json:"identifier" validate:"required"` // Email or phone number
 	Password   string     `json:"password" validate:"required"`
 	Portal     PortalType `json:"portal,omitempty"` // Portal context: "user" or "admin" (defaults to "user")
+
+	CaptchaToken string `json:"captcha_token,omitempty"` // Required once the login response sets captcha_required
 }
 
 // LoginResponse contains the authentication token.
@@ -173,3 +193,8 @@ func (k *KYCInfo) IsKYCVerified() bool {
 func (u *User) IsSuspended() bool {
 	return u.Status == UserStatusSuspended
 }
+
+// HasVerifiedContact returns true if the user's email or phone is verified.
+func (u *User) HasVerifiedContact() bool {
+	return u.EmailVerifiedAt != nil || u.PhoneVerifiedAt != nil
+}
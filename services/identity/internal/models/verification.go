@@ -23,13 +23,20 @@ const (
 type OperationType string
 
 const (
-	OpPasswordChange    OperationType = "password_change"
-	OpEmailChange       OperationType = "email_change"
-	OpPhoneChange       OperationType = "phone_change"
-	OpHighValueTransfer OperationType = "high_value_transfer"
-	OpBeneficiaryAdd    OperationType = "beneficiary_add"
-	Op2FAEnable         OperationType = "2fa_enable"
-	Op2FADisable        OperationType = "2fa_disable"
+	OpPasswordChange     OperationType = "password_change"
+	OpEmailChange        OperationType = "email_change"
+	OpPhoneChange        OperationType = "phone_change"
+	OpHighValueTransfer  OperationType = "high_value_transfer"
+	OpBeneficiaryAdd     OperationType = "beneficiary_add"
+	OpCoolingOffOverride OperationType = "beneficiary_cooling_off_override" // metadata.beneficiary_id is the beneficiary
+	OpWithdrawalConfirm  OperationType = "withdrawal_confirmation"          // metadata.transaction_id is the held withdrawal
+	Op2FAEnable          OperationType = "2fa_enable"
+	Op2FADisable         OperationType = "2fa_disable"
+
+	// Contact verification codes are sent to the address itself and are
+	// created only through the contact verification endpoints.
+	OpEmailVerification OperationType = "email_verification" // metadata.value is the email
+	OpPhoneVerification OperationType = "phone_verification" // metadata.value is the phone
 )
 
 // HighValueThreshold is the amount (in paisa) above which transfers require verification.
@@ -132,13 +139,15 @@ type VerifyOTPRequest struct {
 // ValidOperationTypes returns the list of valid operation types.
 func ValidOperationTypes() map[OperationType]bool {
 	return map[OperationType]bool{
-		OpPasswordChange:    true,
-		OpEmailChange:       true,
-		OpPhoneChange:       true,
-		OpHighValueTransfer: true,
-		OpBeneficiaryAdd:    true,
-		Op2FAEnable:         true,
-		Op2FADisable:        true,
+		OpPasswordChange:     true,
+		OpEmailChange:        true,
+		OpPhoneChange:        true,
+		OpHighValueTransfer:  true,
+		OpBeneficiaryAdd:     true,
+		OpCoolingOffOverride: true,
+		OpWithdrawalConfirm:  true,
+		Op2FAEnable:          true,
+		Op2FADisable:         true,
 	}
 }
 
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// ContactChangeTTL is how long the code sent to a new email or phone stays valid.
+const ContactChangeTTL = 15 * time.Minute
+
+// ContactChangeRepository handles database operations for contact change requests.
+type ContactChangeRepository struct {
+	db *database.DB
+}
+
+// NewContactChangeRepository creates a new contact change repository.
+func NewContactChangeRepository(db *database.DB) *ContactChangeRepository {
+	return &ContactChangeRepository{db: db}
+}
+
+// Create inserts a contact change request.
+func (r *ContactChangeRepository) Create(ctx context.Context, change *models.ContactChange) *errors.Error {
+	query := `
+		INSERT INTO contact_change_requests (id, user_id, channel, new_value, code_hash, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		change.ID,
+		change.UserID,
+		change.Channel,
+		change.NewValue,
+		change.CodeHash,
+		change.Status,
+		change.ExpiresAt.Time,
+	).Scan(&change.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create contact change request")
+	}
+	return nil
+}
+
+// GetByID retrieves a contact change request by ID.
+func (r *ContactChangeRepository) GetByID(ctx context.Context, id string) (*models.ContactChange, *errors.Error) {
+	query := `
+		SELECT id, user_id, channel, new_value, code_hash, status, attempt_count,
+		       expires_at, created_at, confirmed_at
+		FROM contact_change_requests
+		WHERE id = $1
+	`
+	change := &models.ContactChange{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&change.ID,
+		&change.UserID,
+		&change.Channel,
+		&change.NewValue,
+		&change.CodeHash,
+		&change.Status,
+		&change.AttemptCount,
+		&change.ExpiresAt,
+		&change.CreatedAt,
+		&change.ConfirmedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("contact change request not found")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get contact change request")
+	}
+	return change, nil
+}
+
+// HasRecent checks if the user requested a change of this channel within the window.
+func (r *ContactChangeRepository) HasRecent(ctx context.Context, userID string, channel models.ContactChannel, window time.Duration) (bool, *errors.Error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM contact_change_requests
+			WHERE user_id = $1 AND channel = $2 AND created_at > $3
+		)
+	`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, userID, channel, time.Now().Add(-window)).Scan(&exists)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to check recent contact changes")
+	}
+	return exists, nil
+}
+
+// CancelPending cancels the user's pending changes of a channel.
+func (r *ContactChangeRepository) CancelPending(ctx context.Context, userID string, channel models.ContactChannel) *errors.Error {
+	query := `
+		UPDATE contact_change_requests
+		SET status = 'cancelled'
+		WHERE user_id = $1 AND channel = $2 AND status = 'pending'
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, channel); err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel pending contact changes")
+	}
+	return nil
+}
+
+// IncrementAttempts increments the attempt counter and returns the new count.
+func (r *ContactChangeRepository) IncrementAttempts(ctx context.Context, id string) (int, *errors.Error) {
+	query := `
+		UPDATE contact_change_requests
+		SET attempt_count = attempt_count + 1
+		WHERE id = $1
+		RETURNING attempt_count
+	`
+	var count int
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, errors.NotFound("contact change request not found")
+	}
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to increment attempts")
+	}
+	return count, nil
+}
+
+// UpdateStatus updates the status of a contact change request.
+func (r *ContactChangeRepository) UpdateStatus(ctx context.Context, id string, status models.ContactChangeStatus) *errors.Error {
+	query := `
+		UPDATE contact_change_requests
+		SET status = $2
+		WHERE id = $1
+	`
+	if status == models.ContactChangeConfirmed {
+		query = `
+			UPDATE contact_change_requests
+			SET status = $2, confirmed_at = NOW()
+			WHERE id = $1
+		`
+	}
+	result, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update contact change status")
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.NotFound("contact change request not found")
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// KYCCheckRepository handles database operations for KYC provider checks.
+type KYCCheckRepository struct {
+	db *database.DB
+}
+
+// NewKYCCheckRepository creates a new KYC check repository.
+func NewKYCCheckRepository(db *database.DB) *KYCCheckRepository {
+	return &KYCCheckRepository{db: db}
+}
+
+const kycCheckColumns = `
+	id, user_id, provider, check_type, status, reference_id, score, reason, created_at, completed_at
+`
+
+func scanKYCCheck(row rowScanner) (*models.KYCCheck, error) {
+	check := &models.KYCCheck{}
+	err := row.Scan(
+		&check.ID,
+		&check.UserID,
+		&check.Provider,
+		&check.CheckType,
+		&check.Status,
+		&check.ReferenceID,
+		&check.Score,
+		&check.Reason,
+		&check.CreatedAt,
+		&check.CompletedAt,
+	)
+	return check, err
+}
+
+// Create inserts a check. Checks that are not pending are stored as completed.
+func (r *KYCCheckRepository) Create(ctx context.Context, check *models.KYCCheck) *errors.Error {
+	query := `
+		INSERT INTO kyc_provider_checks (user_id, provider, check_type, status, reference_id, score, reason, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, completed_at
+	`
+	var completedAt *time.Time
+	if check.Status != models.KYCCheckPending {
+		now := time.Now()
+		completedAt = &now
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		check.UserID,
+		check.Provider,
+		check.CheckType,
+		check.Status,
+		check.ReferenceID,
+		check.Score,
+		check.Reason,
+		completedAt,
+	).Scan(&check.ID, &check.CreatedAt, &check.CompletedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create KYC check")
+	}
+	return nil
+}
+
+// GetByReference retrieves a user's check by its provider reference.
+func (r *KYCCheckRepository) GetByReference(ctx context.Context, userID string, checkType models.KYCCheckType, referenceID string) (*models.KYCCheck, *errors.Error) {
+	query := `SELECT ` + kycCheckColumns + ` FROM kyc_provider_checks
+		WHERE user_id = $1 AND check_type = $2 AND reference_id = $3`
+
+	check, err := scanKYCCheck(r.db.QueryRowContext(ctx, query, userID, checkType, referenceID))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("KYC check", referenceID)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get KYC check")
+	}
+	return check, nil
+}
+
+// Complete records the outcome of a pending check.
+func (r *KYCCheckRepository) Complete(ctx context.Context, check *models.KYCCheck) *errors.Error {
+	query := `
+		UPDATE kyc_provider_checks
+		SET status = $2, score = $3, reason = $4, completed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING completed_at
+	`
+	err := r.db.QueryRowContext(ctx, query, check.ID, check.Status, check.Score, check.Reason).Scan(&check.CompletedAt)
+	if err == sql.ErrNoRows {
+		return errors.Conflict("KYC check is no longer pending")
+	}
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to complete KYC check")
+	}
+	return nil
+}
+
+// ListByUser returns a user's checks, newest first.
+func (r *KYCCheckRepository) ListByUser(ctx context.Context, userID string) ([]*models.KYCCheck, *errors.Error) {
+	query := `SELECT ` + kycCheckColumns + ` FROM kyc_provider_checks
+		WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list KYC checks")
+	}
+	defer func() { _ = rows.Close() }()
+
+	checks := make([]*models.KYCCheck, 0)
+	for rows.Next() {
+		check, err := scanKYCCheck(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan KYC check")
+		}
+		checks = append(checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating KYC checks")
+	}
+
+	return checks, nil
+}
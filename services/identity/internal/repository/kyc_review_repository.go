@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// KYCReviewRepository handles database operations for the KYC review queue.
+type KYCReviewRepository struct {
+	db *database.DB
+}
+
+// NewKYCReviewRepository creates a new KYC review repository.
+func NewKYCReviewRepository(db *database.DB) *KYCReviewRepository {
+	return &KYCReviewRepository{db: db}
+}
+
+const kycQueueColumns = `
+	k.user_id, k.status, k.pan, k.aadhaar, k.date_of_birth, k.address,
+	k.verified_at, k.rejected_at, k.rejection_reason, k.created_at, k.updated_at,
+	k.submitted_at, k.assigned_to, k.assigned_at,
+	u.id, u.email, u.phone, u.full_name, u.status, u.created_at, u.updated_at
+`
+
+func scanKYCQueueItem(row rowScanner) (*models.KYCQueueItem, error) {
+	item := &models.KYCQueueItem{}
+	var addressJSON []byte
+	var rejectionReason sql.NullString
+
+	err := row.Scan(
+		&item.KYC.UserID,
+		&item.KYC.Status,
+		&item.KYC.PAN,
+		&item.KYC.Aadhaar,
+		&item.KYC.DateOfBirth,
+		&addressJSON,
+		&item.KYC.VerifiedAt,
+		&item.KYC.RejectedAt,
+		&rejectionReason,
+		&item.KYC.CreatedAt,
+		&item.KYC.UpdatedAt,
+		&item.SubmittedAt,
+		&item.AssignedTo,
+		&item.AssignedAt,
+		&item.User.ID,
+		&item.User.Email,
+		&item.User.Phone,
+		&item.User.FullName,
+		&item.User.Status,
+		&item.User.CreatedAt,
+		&item.User.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if rejectionReason.Valid {
+		item.KYC.RejectionReason = rejectionReason.String
+	}
+	if err := json.Unmarshal(addressJSON, &item.KYC.Address); err != nil {
+		return nil, fmt.Errorf("failed to parse address: %w", err)
+	}
+	return item, nil
+}
+
+// ListQueue lists pending KYC submissions matching the filter, oldest first.
+// The SLA filter is applied by the caller through SubmittedBefore.
+func (r *KYCReviewRepository) ListQueue(ctx context.Context, filter models.KYCQueueFilter) ([]*models.KYCQueueItem, *errors.Error) {
+	query := `SELECT ` + kycQueueColumns + `
+		FROM user_kyc k
+		INNER JOIN users u ON k.user_id = u.id
+		WHERE k.status = 'pending'`
+	args := []interface{}{}
+
+	if filter.AssignedTo != "" {
+		args = append(args, filter.AssignedTo)
+		query += fmt.Sprintf(" AND k.assigned_to = $%d", len(args))
+	}
+	if filter.Unassigned {
+		query += " AND k.assigned_to IS NULL"
+	}
+	if filter.SubmittedAfter != nil {
+		args = append(args, *filter.SubmittedAfter)
+		query += fmt.Sprintf(" AND k.submitted_at >= $%d", len(args))
+	}
+	if filter.SubmittedBefore != nil {
+		args = append(args, *filter.SubmittedBefore)
+		query += fmt.Sprintf(" AND k.submitted_at < $%d", len(args))
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY k.submitted_at ASC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list KYC review queue")
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*models.KYCQueueItem, 0)
+	for rows.Next() {
+		item, err := scanKYCQueueItem(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan KYC queue item")
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating KYC review queue")
+	}
+
+	return items, nil
+}
+
+// GetQueueItem retrieves a user's KYC submission with its review state,
+// whatever its status.
+func (r *KYCReviewRepository) GetQueueItem(ctx context.Context, userID string) (*models.KYCQueueItem, *errors.Error) {
+	query := `SELECT ` + kycQueueColumns + `
+		FROM user_kyc k
+		INNER JOIN users u ON k.user_id = u.id
+		WHERE k.user_id = $1`
+
+	item, err := scanKYCQueueItem(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("KYC submission", userID)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get KYC submission")
+	}
+	return item, nil
+}
+
+// Assign gives a pending submission to a reviewer. Unless reassign is set, it
+// fails with a conflict if another reviewer already holds it.
+func (r *KYCReviewRepository) Assign(ctx context.Context, userID, reviewerID string, reassign bool) *errors.Error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_kyc
+		SET assigned_to = $2,
+		    assigned_at = CASE WHEN assigned_to = $2 THEN assigned_at ELSE NOW() END
+		WHERE user_id = $1 AND status = 'pending'
+		  AND ($3 OR assigned_to IS NULL OR assigned_to = $2)
+	`, userID, reviewerID, reassign)
+	if err != nil {
+		if database.IsForeignKeyViolation(err) {
+			return errors.BadRequest("reviewer does not exist")
+		}
+		return errors.DatabaseWrap(err, "failed to assign KYC submission")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.Conflict("KYC submission is assigned to another reviewer or no longer pending")
+	}
+	return nil
+}
+
+// Release returns a pending submission held by reviewerID to the queue.
+func (r *KYCReviewRepository) Release(ctx context.Context, userID, reviewerID string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE user_kyc
+		SET assigned_to = NULL, assigned_at = NULL
+		WHERE user_id = $1 AND status = 'pending' AND assigned_to = $2
+	`, userID, reviewerID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to release KYC submission")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.Conflict("KYC submission is not assigned to you")
+	}
+	return nil
+}
+
+// RecordDecision stores a KYC decision for audit and reviewer metrics.
+func (r *KYCReviewRepository) RecordDecision(ctx context.Context, record *models.KYCReviewRecord) *errors.Error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO kyc_review_decisions
+			(user_id, reviewer_id, decision, reason_code, note, submitted_at, claimed_at, sla_due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, decided_at
+	`,
+		record.UserID,
+		record.ReviewerID,
+		record.Decision,
+		record.ReasonCode,
+		record.Note,
+		record.SubmittedAt,
+		record.ClaimedAt,
+		record.SLADueAt,
+	).Scan(&record.ID, &record.DecidedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record KYC decision")
+	}
+	return nil
+}
+
+// ReviewerThroughput aggregates the decisions made in [from, to) per reviewer,
+// busiest first.
+func (r *KYCReviewRepository) ReviewerThroughput(ctx context.Context, from, to time.Time) ([]*models.KYCReviewerMetrics, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.reviewer_id, COALESCE(u.full_name, ''),
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE d.decision = 'approved'),
+		       COUNT(*) FILTER (WHERE d.decision = 'rejected'),
+		       COUNT(*) FILTER (WHERE d.decided_at > d.sla_due_at),
+		       COALESCE(AVG(EXTRACT(EPOCH FROM d.decided_at - d.claimed_at)), 0),
+		       COALESCE(AVG(EXTRACT(EPOCH FROM d.decided_at - d.submitted_at)), 0)
+		FROM kyc_review_decisions d
+		LEFT JOIN users u ON u.id = d.reviewer_id
+		WHERE d.decided_at >= $1 AND d.decided_at < $2
+		GROUP BY d.reviewer_id, u.full_name
+		ORDER BY COUNT(*) DESC, d.reviewer_id
+	`, from, to)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to aggregate KYC reviewer throughput")
+	}
+	defer func() { _ = rows.Close() }()
+
+	reviewers := make([]*models.KYCReviewerMetrics, 0)
+	for rows.Next() {
+		m := &models.KYCReviewerMetrics{}
+		if err := rows.Scan(
+			&m.ReviewerID,
+			&m.ReviewerName,
+			&m.Decisions,
+			&m.Approved,
+			&m.Rejected,
+			&m.SLABreached,
+			&m.AvgHandlingSeconds,
+			&m.AvgTurnaroundSeconds,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan KYC reviewer throughput")
+		}
+		reviewers = append(reviewers, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating KYC reviewer throughput")
+	}
+
+	return reviewers, nil
+}
+
+// QueueStats counts pending submissions. Submissions made before overdueBefore
+// are counted as overdue.
+func (r *KYCReviewRepository) QueueStats(ctx context.Context, overdueBefore time.Time) (*models.KYCQueueStats, *errors.Error) {
+	stats := &models.KYCQueueStats{Assigned: make(map[string]int)}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT assigned_to, COUNT(*), COUNT(*) FILTER (WHERE submitted_at < $1)
+		FROM user_kyc
+		WHERE status = 'pending'
+		GROUP BY assigned_to
+	`, overdueBefore)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to count KYC review queue")
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var assignedTo sql.NullString
+		var pending, overdue int
+		if err := rows.Scan(&assignedTo, &pending, &overdue); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan KYC review queue counts")
+		}
+		stats.Pending += pending
+		stats.Overdue += overdue
+		if assignedTo.Valid {
+			stats.Assigned[assignedTo.String] = pending
+		} else {
+			stats.Unassigned = pending
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating KYC review queue counts")
+	}
+
+	return stats, nil
+}
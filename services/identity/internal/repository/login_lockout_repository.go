@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// LoginLockoutRepository handles database operations for login lockouts.
+type LoginLockoutRepository struct {
+	db *database.DB
+}
+
+// NewLoginLockoutRepository creates a new login lockout repository.
+func NewLoginLockoutRepository(db *database.DB) *LoginLockoutRepository {
+	return &LoginLockoutRepository{db: db}
+}
+
+const loginLockoutColumns = `
+	user_id, failed_attempts, lockout_count, locked_until, last_failed_at,
+	unlock_token_hash, unlock_token_expires_at
+`
+
+// Get retrieves a user's lockout state. A user without failed logins gets an
+// empty state rather than an error.
+func (r *LoginLockoutRepository) Get(ctx context.Context, userID string) (*models.LoginLockout, *errors.Error) {
+	query := `SELECT ` + loginLockoutColumns + ` FROM login_lockouts WHERE user_id = $1`
+	lockout, err := scanLoginLockout(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return &models.LoginLockout{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get login lockout")
+	}
+	return lockout, nil
+}
+
+// GetByUnlockTokenHash retrieves the lockout an unexpired unlock token belongs to.
+func (r *LoginLockoutRepository) GetByUnlockTokenHash(ctx context.Context, tokenHash string) (*models.LoginLockout, *errors.Error) {
+	query := `SELECT ` + loginLockoutColumns + `
+		FROM login_lockouts
+		WHERE unlock_token_hash = $1 AND unlock_token_expires_at > NOW()
+	`
+	lockout, err := scanLoginLockout(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("unlock link is invalid or has expired")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get login lockout")
+	}
+	return lockout, nil
+}
+
+// RecordFailure counts a failed login and returns the updated state. Failures
+// older than the window no longer count.
+func (r *LoginLockoutRepository) RecordFailure(ctx context.Context, userID string, window time.Duration) (*models.LoginLockout, *errors.Error) {
+	query := `
+		INSERT INTO login_lockouts (user_id, failed_attempts, last_failed_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET failed_attempts = CASE
+		        WHEN login_lockouts.last_failed_at < NOW() - $2 * INTERVAL '1 second' THEN 1
+		        ELSE login_lockouts.failed_attempts + 1
+		    END,
+		    last_failed_at = NOW(),
+		    updated_at = NOW()
+		RETURNING ` + loginLockoutColumns
+
+	lockout, err := scanLoginLockout(r.db.QueryRowContext(ctx, query, userID, window.Seconds()))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to record login failure")
+	}
+	return lockout, nil
+}
+
+// Lock locks the account until the given time and stores the unlock token hash.
+// Failed attempts keep counting, so a failure after the cooldown locks again.
+func (r *LoginLockoutRepository) Lock(ctx context.Context, userID string, until time.Time, unlockTokenHash string, tokenExpiresAt time.Time) (*models.LoginLockout, *errors.Error) {
+	query := `
+		UPDATE login_lockouts
+		SET locked_until = $2,
+		    lockout_count = lockout_count + 1,
+		    unlock_token_hash = $3,
+		    unlock_token_expires_at = $4,
+		    updated_at = NOW()
+		WHERE user_id = $1
+		RETURNING ` + loginLockoutColumns
+
+	lockout, err := scanLoginLockout(r.db.QueryRowContext(ctx, query, userID, until, unlockTokenHash, tokenExpiresAt))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("login lockout not found")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to lock account")
+	}
+	return lockout, nil
+}
+
+// Clear removes a user's failed logins and any lockout.
+func (r *LoginLockoutRepository) Clear(ctx context.Context, userID string) *errors.Error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_lockouts WHERE user_id = $1`, userID); err != nil {
+		return errors.DatabaseWrap(err, "failed to clear login lockout")
+	}
+	return nil
+}
+
+func scanLoginLockout(row *sql.Row) (*models.LoginLockout, error) {
+	lockout := &models.LoginLockout{}
+	var tokenHash sql.NullString
+	err := row.Scan(
+		&lockout.UserID,
+		&lockout.FailedAttempts,
+		&lockout.LockoutCount,
+		&lockout.LockedUntil,
+		&lockout.LastFailedAt,
+		&tokenHash,
+		&lockout.UnlockTokenExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	lockout.UnlockTokenHash = tokenHash.String
+	return lockout, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// OIDCRepository handles database operations for OAuth clients and
+// authorization codes.
+type OIDCRepository struct {
+	db *database.DB
+}
+
+// NewOIDCRepository creates a new OIDC repository.
+func NewOIDCRepository(db *database.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+const oauthClientColumns = `id, name, secret_hash, redirect_uris, scopes, created_by, created_at, revoked_at`
+
+// CreateClient registers an OAuth client.
+func (r *OIDCRepository) CreateClient(ctx context.Context, client *models.OAuthClient) *errors.Error {
+	query := `
+		INSERT INTO oauth_clients (name, secret_hash, redirect_uris, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	var secretHash sql.NullString
+	if !client.Public {
+		secretHash = sql.NullString{String: client.SecretHash, Valid: true}
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		client.Name,
+		secretHash,
+		pq.Array(client.RedirectURIs),
+		pq.Array(client.Scopes),
+		client.CreatedBy,
+	).Scan(&client.ID, &client.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create OAuth client")
+	}
+	return nil
+}
+
+// GetClient retrieves an OAuth client by ID, including a revoked one.
+func (r *OIDCRepository) GetClient(ctx context.Context, id string) (*models.OAuthClient, *errors.Error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients WHERE id = $1`
+	client, err := scanOAuthClient(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("OAuth client", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get OAuth client")
+	}
+	return client, nil
+}
+
+// ListClients returns all OAuth clients, newest first.
+func (r *OIDCRepository) ListClients(ctx context.Context) ([]*models.OAuthClient, *errors.Error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients ORDER BY created_at DESC`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list OAuth clients")
+	}
+	defer func() { _ = rows.Close() }()
+
+	clients := make([]*models.OAuthClient, 0)
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan OAuth client")
+		}
+		clients = append(clients, client)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list OAuth clients")
+	}
+	return clients, nil
+}
+
+// RevokeClient stops a client from signing users in. Codes it has not yet
+// exchanged are deleted.
+func (r *OIDCRepository) RevokeClient(ctx context.Context, id string) *errors.Error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE oauth_clients SET revoked_at = NOW()
+			WHERE id = $1 AND revoked_at IS NULL
+		`, id)
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to revoke OAuth client")
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return errors.NotFoundWithID("OAuth client", id)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM oauth_authorization_codes WHERE client_id = $1`, id); err != nil {
+			return errors.DatabaseWrap(err, "failed to delete authorization codes")
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *errors.Error
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return errors.DatabaseWrap(err, "failed to revoke OAuth client")
+	}
+	return nil
+}
+
+// CreateCode stores an authorization code.
+func (r *OIDCRepository) CreateCode(ctx context.Context, code *models.AuthorizationCode) *errors.Error {
+	query := `
+		INSERT INTO oauth_authorization_codes
+			(code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	var nonce sql.NullString
+	if code.Nonce != "" {
+		nonce = sql.NullString{String: code.Nonce, Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.Scope,
+		nonce,
+		code.CodeChallenge,
+		code.ExpiresAt,
+	)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create authorization code")
+	}
+	return nil
+}
+
+// ConsumeCode marks an unused, unexpired code as used and returns it, so a
+// code is exchanged at most once even under concurrent requests.
+func (r *OIDCRepository) ConsumeCode(ctx context.Context, codeHash string, now time.Time) (*models.AuthorizationCode, *errors.Error) {
+	query := `
+		UPDATE oauth_authorization_codes
+		SET used_at = $2
+		WHERE code_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, expires_at
+	`
+	code := &models.AuthorizationCode{}
+	var nonce sql.NullString
+	err := r.db.QueryRowContext(ctx, query, codeHash, now).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scope,
+		&nonce,
+		&code.CodeChallenge,
+		&code.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("authorization code is invalid, expired or already used")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to consume authorization code")
+	}
+	code.Nonce = nonce.String
+	return code, nil
+}
+
+func scanOAuthClient(row rowScanner) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+	var secretHash sql.NullString
+	err := row.Scan(
+		&client.ID,
+		&client.Name,
+		&secretHash,
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.Scopes),
+		&client.CreatedBy,
+		&client.CreatedAt,
+		&client.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	client.SecretHash = secretHash.String
+	client.Public = !secretHash.Valid
+	return client, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ProfileAttributeRepository handles database operations for user profile attributes.
+type ProfileAttributeRepository struct {
+	db *database.DB
+}
+
+// NewProfileAttributeRepository creates a new profile attribute repository.
+func NewProfileAttributeRepository(db *database.DB) *ProfileAttributeRepository {
+	return &ProfileAttributeRepository{db: db}
+}
+
+// Get returns a user's attributes and when any of them last changed. The
+// time is nil if the user has none.
+func (r *ProfileAttributeRepository) Get(ctx context.Context, userID string) (models.ProfileAttributes, *sharedModels.Timestamp, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT key, value, updated_at
+		FROM user_profile_attributes
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to get profile attributes")
+	}
+	defer func() { _ = rows.Close() }()
+
+	attrs := make(models.ProfileAttributes)
+	var updatedAt *sharedModels.Timestamp
+	for rows.Next() {
+		var key models.ProfileAttributeKey
+		var value string
+		var at sharedModels.Timestamp
+		if err := rows.Scan(&key, &value, &at); err != nil {
+			return nil, nil, errors.DatabaseWrap(err, "failed to scan profile attribute")
+		}
+		attrs[key] = value
+		if updatedAt == nil || at.After(*updatedAt) {
+			updatedAt = &at
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to iterate profile attributes")
+	}
+
+	return attrs, updatedAt, nil
+}
+
+// Update sets and clears attributes in one transaction.
+func (r *ProfileAttributeRepository) Update(ctx context.Context, userID string, set models.ProfileAttributes, clear []models.ProfileAttributeKey) *errors.Error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for key, value := range set {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO user_profile_attributes (user_id, key, value)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, key) DO UPDATE SET value = EXCLUDED.value, updated_at = NOW()
+		`, userID, key, value); err != nil {
+			return errors.DatabaseWrap(err, "failed to set profile attribute")
+		}
+	}
+
+	for _, key := range clear {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM user_profile_attributes WHERE user_id = $1 AND key = $2
+		`, userID, key); err != nil {
+			return errors.DatabaseWrap(err, "failed to clear profile attribute")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit profile attributes")
+	}
+	return nil
+}
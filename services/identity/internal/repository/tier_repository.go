@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// TierRepository handles database operations for account tiers and tier change requests.
+type TierRepository struct {
+	db *database.DB
+}
+
+// NewTierRepository creates a new tier repository.
+func NewTierRepository(db *database.DB) *TierRepository {
+	return &TierRepository{db: db}
+}
+
+const tierColumns = `
+	code, name, rank, daily_transfer_limit, monthly_transfer_limit,
+	max_virtual_cards, support_priority, upgrade_fee, created_at
+`
+
+const tierChangeColumns = `
+	id, user_id, from_tier, to_tier, status, wallet_id, fee_amount,
+	fee_reference, reason, reviewed_by, review_note, created_at, reviewed_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTier(row rowScanner) (*models.AccountTier, error) {
+	tier := &models.AccountTier{}
+	err := row.Scan(
+		&tier.Code,
+		&tier.Name,
+		&tier.Rank,
+		&tier.DailyTransferLimit,
+		&tier.MonthlyTransferLimit,
+		&tier.MaxVirtualCards,
+		&tier.SupportPriority,
+		&tier.UpgradeFee,
+		&tier.CreatedAt,
+	)
+	return tier, err
+}
+
+func scanTierChange(row rowScanner) (*models.TierChangeRequest, error) {
+	req := &models.TierChangeRequest{}
+	err := row.Scan(
+		&req.ID,
+		&req.UserID,
+		&req.FromTier,
+		&req.ToTier,
+		&req.Status,
+		&req.WalletID,
+		&req.FeeAmount,
+		&req.FeeReference,
+		&req.Reason,
+		&req.ReviewedBy,
+		&req.ReviewNote,
+		&req.CreatedAt,
+		&req.ReviewedAt,
+	)
+	return req, err
+}
+
+// ListTiers returns all tier definitions ordered by rank.
+func (r *TierRepository) ListTiers(ctx context.Context) ([]*models.AccountTier, *errors.Error) {
+	query := `SELECT ` + tierColumns + ` FROM account_tiers ORDER BY rank ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list account tiers")
+	}
+	defer func() { _ = rows.Close() }()
+
+	tiers := make([]*models.AccountTier, 0)
+	for rows.Next() {
+		tier, err := scanTier(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan account tier")
+		}
+		tiers = append(tiers, tier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating account tiers")
+	}
+
+	return tiers, nil
+}
+
+// GetTier retrieves a tier definition by code.
+func (r *TierRepository) GetTier(ctx context.Context, code string) (*models.AccountTier, *errors.Error) {
+	query := `SELECT ` + tierColumns + ` FROM account_tiers WHERE code = $1`
+
+	tier, err := scanTier(r.db.QueryRowContext(ctx, query, code))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("account tier", code)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get account tier")
+	}
+	return tier, nil
+}
+
+// GetUserTier returns the tier code assigned to a user.
+func (r *TierRepository) GetUserTier(ctx context.Context, userID string) (string, *errors.Error) {
+	var code string
+	err := r.db.QueryRowContext(ctx, `SELECT account_tier FROM users WHERE id = $1`, userID).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "", errors.NotFoundWithID("user", userID)
+	}
+	if err != nil {
+		return "", errors.DatabaseWrap(err, "failed to get user tier")
+	}
+	return code, nil
+}
+
+// CreateChangeRequest inserts a tier change request.
+func (r *TierRepository) CreateChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	query := `
+		INSERT INTO tier_change_requests (user_id, from_tier, to_tier, status, wallet_id, fee_amount, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		req.UserID,
+		req.FromTier,
+		req.ToTier,
+		req.Status,
+		req.WalletID,
+		req.FeeAmount,
+		req.Reason,
+	).Scan(&req.ID, &req.CreatedAt)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return errors.Conflict("a tier change request is already pending")
+		}
+		return errors.DatabaseWrap(err, "failed to create tier change request")
+	}
+	return nil
+}
+
+// GetChangeRequest retrieves a tier change request by ID.
+func (r *TierRepository) GetChangeRequest(ctx context.Context, id string) (*models.TierChangeRequest, *errors.Error) {
+	query := `SELECT ` + tierChangeColumns + ` FROM tier_change_requests WHERE id = $1`
+
+	req, err := scanTierChange(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("tier change request", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get tier change request")
+	}
+	return req, nil
+}
+
+// GetPendingChangeRequest returns the user's open request, or nil if there is none.
+func (r *TierRepository) GetPendingChangeRequest(ctx context.Context, userID string) (*models.TierChangeRequest, *errors.Error) {
+	query := `SELECT ` + tierChangeColumns + ` FROM tier_change_requests WHERE user_id = $1 AND status = 'pending'`
+
+	req, err := scanTierChange(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get pending tier change request")
+	}
+	return req, nil
+}
+
+// ListChangeRequests lists tier change requests, optionally filtered by status, oldest first.
+func (r *TierRepository) ListChangeRequests(ctx context.Context, status models.TierChangeStatus, limit, offset int) ([]*models.TierChangeRequest, *errors.Error) {
+	query := `SELECT ` + tierChangeColumns + ` FROM tier_change_requests`
+	args := []interface{}{}
+
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" WHERE status = $%d", len(args))
+	}
+
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(" ORDER BY created_at ASC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list tier change requests")
+	}
+	defer func() { _ = rows.Close() }()
+
+	requests := make([]*models.TierChangeRequest, 0)
+	for rows.Next() {
+		req, err := scanTierChange(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan tier change request")
+		}
+		requests = append(requests, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating tier change requests")
+	}
+
+	return requests, nil
+}
+
+// ApplyChange marks a pending request approved and moves the user to the
+// target tier in one transaction. It fails with a conflict if the request was
+// reviewed concurrently.
+func (r *TierRepository) ApplyChange(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE tier_change_requests
+		SET status = 'approved', fee_reference = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at
+	`, req.ID, req.FeeReference, req.ReviewedBy, req.ReviewNote).Scan(&req.ReviewedAt)
+	if err == sql.ErrNoRows {
+		return errors.Conflict("tier change request is no longer pending")
+	}
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to approve tier change request")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET account_tier = $2, updated_at = NOW() WHERE id = $1
+	`, req.UserID, req.ToTier)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update user tier")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.NotFoundWithID("user", req.UserID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit tier change")
+	}
+
+	req.Status = models.TierChangeApproved
+	return nil
+}
+
+// CloseChangeRequest moves a pending request to rejected or cancelled.
+func (r *TierRepository) CloseChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE tier_change_requests
+		SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at
+	`, req.ID, req.Status, req.ReviewedBy, req.ReviewNote).Scan(&req.ReviewedAt)
+	if err == sql.ErrNoRows {
+		return errors.Conflict("tier change request is no longer pending")
+	}
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to close tier change request")
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 
+	"github.com/lib/pq"
+
 	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/database"
 	"github.com/1mb-dev/nivomoney/shared/errors"
@@ -109,6 +111,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 	query := `
 		SELECT id, email, phone, full_name, password_hash, status, account_type,
 		       suspended_at, suspension_reason, suspended_by,
+		       email_verified_at, phone_verified_at,
 		       created_at, updated_at
 		FROM users
 		WHERE id = $1
@@ -125,6 +128,8 @@ func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User,
 		&user.SuspendedAt,
 		&user.SuspensionReason,
 		&user.SuspendedBy,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -152,6 +157,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	query := `
 		SELECT id, email, phone, full_name, password_hash, status, account_type,
 		       suspended_at, suspension_reason, suspended_by,
+		       email_verified_at, phone_verified_at,
 		       created_at, updated_at
 		FROM users
 		WHERE email = $1
@@ -168,6 +174,8 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 		&user.SuspendedAt,
 		&user.SuspensionReason,
 		&user.SuspendedBy,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -196,6 +204,7 @@ func (r *UserRepository) GetByEmailAndAccountType(ctx context.Context, email str
 	query := `
 		SELECT id, email, phone, full_name, password_hash, status, account_type,
 		       suspended_at, suspension_reason, suspended_by,
+		       email_verified_at, phone_verified_at,
 		       created_at, updated_at
 		FROM users
 		WHERE email = $1 AND account_type = $2
@@ -212,6 +221,8 @@ func (r *UserRepository) GetByEmailAndAccountType(ctx context.Context, email str
 		&user.SuspendedAt,
 		&user.SuspensionReason,
 		&user.SuspendedBy,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -239,6 +250,7 @@ func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*models.
 	query := `
 		SELECT id, email, phone, full_name, password_hash, status, account_type,
 		       suspended_at, suspension_reason, suspended_by,
+		       email_verified_at, phone_verified_at,
 		       created_at, updated_at
 		FROM users
 		WHERE phone = $1
@@ -255,6 +267,8 @@ func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*models.
 		&user.SuspendedAt,
 		&user.SuspensionReason,
 		&user.SuspendedBy,
+		&user.EmailVerifiedAt,
+		&user.PhoneVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -273,22 +287,26 @@ func (r *UserRepository) GetByPhone(ctx context.Context, phone string) (*models.
 	return user, nil
 }
 
-// Update updates a user's information.
+// Update updates a user's information. Changing the email or phone clears
+// its verification.
 func (r *UserRepository) Update(ctx context.Context, user *models.User) *errors.Error {
 	query := `
 		UPDATE users
-		SET email = $2, phone = $3, full_name = $4, status = $5, updated_at = NOW()
+		SET email = $2, phone = $3, full_name = $4, status = $5,
+		    email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
+		    phone_verified_at = CASE WHEN phone IS NOT DISTINCT FROM $3 THEN phone_verified_at END,
+		    updated_at = NOW()
 		WHERE id = $1
-		RETURNING updated_at
+		RETURNING email_verified_at, phone_verified_at, updated_at
 	`
 
 	err := r.db.QueryRowContext(ctx, query,
 		user.ID,
 		user.Email,
-		user.Phone,
+		nullableString(user.Phone), // User-Admin accounts have no phone
 		user.FullName,
 		user.Status,
-	).Scan(&user.UpdatedAt)
+	).Scan(&user.EmailVerifiedAt, &user.PhoneVerifiedAt, &user.UpdatedAt)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -411,6 +429,40 @@ func (r *UserRepository) UnsuspendUser(ctx context.Context, userID string) *erro
 	return nil
 }
 
+// MarkContactVerified records that the user's email or phone was verified.
+// value is the address the code was sent to; if the user's address has
+// changed since, nothing is marked and a conflict is returned.
+func (r *UserRepository) MarkContactVerified(ctx context.Context, userID string, channel models.ContactChannel, value string) *errors.Error {
+	query := `
+		UPDATE users
+		SET email_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND email = $2
+	`
+	if channel == models.ContactChannelPhone {
+		query = `
+			UPDATE users
+			SET phone_verified_at = NOW(), updated_at = NOW()
+			WHERE id = $1 AND phone = $2
+		`
+	}
+
+	result, err := r.db.ExecContext(ctx, query, userID, value)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to mark contact verified")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get rows affected")
+	}
+
+	if rows == 0 {
+		return errors.Conflict(string(channel) + " changed since the code was sent")
+	}
+
+	return nil
+}
+
 // Delete soft-deletes a user by setting status to closed.
 func (r *UserRepository) Delete(ctx context.Context, userID string) *errors.Error {
 	return r.UpdateStatus(ctx, userID, models.UserStatusClosed)
@@ -423,6 +475,7 @@ func (r *UserRepository) SearchUsers(ctx context.Context, query string, limit, o
 	sqlQuery := `
 		SELECT id, email, phone, full_name, password_hash, status, account_type,
 		       suspended_at, suspension_reason, suspended_by,
+		       email_verified_at, phone_verified_at,
 		       created_at, updated_at
 		FROM users
 		WHERE email ILIKE $1 OR phone ILIKE $1 OR full_name ILIKE $1
@@ -452,6 +505,8 @@ func (r *UserRepository) SearchUsers(ctx context.Context, query string, limit, o
 			&user.SuspendedAt,
 			&user.SuspensionReason,
 			&user.SuspendedBy,
+			&user.EmailVerifiedAt,
+			&user.PhoneVerifiedAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -518,6 +573,50 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models
 	return users, nil
 }
 
+// ListByIDs retrieves the users with the given IDs. IDs without a user are
+// skipped.
+func (r *UserRepository) ListByIDs(ctx context.Context, ids []string) ([]*models.User, *errors.Error) {
+	query := `
+		SELECT id, email, phone, full_name, status, account_type, created_at, updated_at
+		FROM users
+		WHERE id = ANY($1::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list users by ID")
+	}
+	defer func() { _ = rows.Close() }()
+
+	users := make([]*models.User, 0, len(ids))
+	for rows.Next() {
+		user := &models.User{}
+		var phone sql.NullString // Phone can be NULL for user_admin accounts
+		if err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&phone,
+			&user.FullName,
+			&user.Status,
+			&user.AccountType,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan user")
+		}
+		if phone.Valid {
+			user.Phone = phone.String
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating users")
+	}
+
+	return users, nil
+}
+
 // Count returns the total number of users.
 func (r *UserRepository) Count(ctx context.Context) (int, *errors.Error) {
 	var count int
@@ -567,7 +666,8 @@ func (r *KYCRepository) Create(ctx context.Context, kyc *models.KYCInfo) *errors
 		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (user_id) DO UPDATE
 		SET pan = $3, aadhaar = $4, date_of_birth = $5, address = $6,
-		    status = 'pending', updated_at = NOW()
+		    status = 'pending', submitted_at = NOW(), assigned_to = NULL,
+		    assigned_at = NULL, updated_at = NOW()
 		RETURNING created_at, updated_at
 	`
 
@@ -646,14 +746,16 @@ func (r *KYCRepository) UpdateStatus(ctx context.Context, userID string, status
 		query = `
 			UPDATE user_kyc
 			SET status = $2, verified_at = NOW(), rejected_at = NULL,
-			    rejection_reason = NULL, updated_at = NOW()
+			    rejection_reason = NULL, assigned_to = NULL, assigned_at = NULL,
+			    updated_at = NOW()
 			WHERE user_id = $1
 		`
 	case models.KYCStatusRejected:
 		query = `
 			UPDATE user_kyc
 			SET status = $2, rejected_at = NOW(), rejection_reason = $3,
-			    verified_at = NULL, updated_at = NOW()
+			    verified_at = NULL, assigned_to = NULL, assigned_at = NULL,
+			    updated_at = NOW()
 			WHERE user_id = $1
 		`
 	default:
@@ -909,6 +1011,74 @@ func (r *SessionRepository) DeleteByUserID(ctx context.Context, userID string) *
 	return nil
 }
 
+// ListActiveByUserID retrieves all unexpired sessions for a user, most recently seen first.
+func (r *SessionRepository) ListActiveByUserID(ctx context.Context, userID string) ([]*models.Session, *errors.Error) {
+	query := `
+		SELECT id, user_id, token_hash, COALESCE(host(ip_address), ''), COALESCE(user_agent, ''),
+		       expires_at, created_at, last_seen_at
+		FROM sessions
+		WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sessions")
+	}
+	defer func() { _ = rows.Close() }()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		session := &models.Session{}
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.Token,
+			&session.IPAddress,
+			&session.UserAgent,
+			&session.ExpiresAt,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan session")
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating sessions")
+	}
+
+	return sessions, nil
+}
+
+// DeleteByID deletes a single session owned by a user and returns its token hash.
+func (r *SessionRepository) DeleteByID(ctx context.Context, userID, sessionID string) (string, *errors.Error) {
+	query := `DELETE FROM sessions WHERE id = $1 AND user_id = $2 RETURNING token_hash`
+
+	var tokenHash string
+	err := r.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&tokenHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", errors.NotFound("session")
+		}
+		return "", errors.DatabaseWrap(err, "failed to delete session")
+	}
+
+	return tokenHash, nil
+}
+
+// TouchLastSeen records activity on a session.
+func (r *SessionRepository) TouchLastSeen(ctx context.Context, tokenHash string) *errors.Error {
+	query := `UPDATE sessions SET last_seen_at = NOW() WHERE token_hash = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash); err != nil {
+		return errors.DatabaseWrap(err, "failed to update session activity")
+	}
+
+	return nil
+}
+
 // CleanupExpired deletes all expired sessions.
 func (r *SessionRepository) CleanupExpired(ctx context.Context) (int, *errors.Error) {
 	query := `DELETE FROM sessions WHERE expires_at < NOW()`
@@ -250,6 +250,20 @@ func (r *VerificationRepository) CancelPendingForUser(ctx context.Context, userI
 	return nil
 }
 
+// CancelPendingByOperation cancels a user's pending verifications of one operation type.
+func (r *VerificationRepository) CancelPendingByOperation(ctx context.Context, userID string, operationType models.OperationType) *errors.Error {
+	query := `
+		UPDATE verification_requests
+		SET status = 'cancelled'
+		WHERE user_id = $1 AND operation_type = $2 AND status = 'pending'
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, operationType)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel pending verifications")
+	}
+	return nil
+}
+
 // HasRecentVerification checks if user has a recent verification of same type.
 func (r *VerificationRepository) HasRecentVerification(ctx context.Context, userID string, operationType models.OperationType, within time.Duration) (bool, *errors.Error) {
 	query := `
@@ -6,19 +6,22 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
 	"github.com/1mb-dev/nivomoney/services/identity/internal/repository"
 	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/validator"
 )
 
 // UserRepositoryInterface defines the interface for user repository operations.
@@ -28,6 +31,7 @@ type UserRepositoryInterface interface {
 	GetByEmailAndAccountType(ctx context.Context, email string, accountType models.AccountType) (*models.User, *errors.Error)
 	GetByPhone(ctx context.Context, phone string) (*models.User, *errors.Error)
 	GetByID(ctx context.Context, id string) (*models.User, *errors.Error)
+	ListByIDs(ctx context.Context, ids []string) ([]*models.User, *errors.Error)
 	Update(ctx context.Context, user *models.User) *errors.Error
 	UpdatePassword(ctx context.Context, userID string, passwordHash string) *errors.Error
 	UpdateStatus(ctx context.Context, userID string, status models.UserStatus) *errors.Error
@@ -37,6 +41,7 @@ type UserRepositoryInterface interface {
 	SearchUsers(ctx context.Context, query string, limit, offset int) ([]*models.User, *errors.Error)
 	SuspendUser(ctx context.Context, userID string, reason string, suspendedBy string) *errors.Error
 	UnsuspendUser(ctx context.Context, userID string) *errors.Error
+	MarkContactVerified(ctx context.Context, userID string, channel models.ContactChannel, value string) *errors.Error
 }
 
 // KYCRepositoryInterface defines the interface for KYC repository operations.
@@ -53,6 +58,9 @@ type SessionRepositoryInterface interface {
 	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Session, *errors.Error)
 	DeleteByTokenHash(ctx context.Context, tokenHash string) *errors.Error
 	DeleteByUserID(ctx context.Context, userID string) *errors.Error
+	ListActiveByUserID(ctx context.Context, userID string) ([]*models.Session, *errors.Error)
+	DeleteByID(ctx context.Context, userID, sessionID string) (string, *errors.Error)
+	TouchLastSeen(ctx context.Context, tokenHash string) *errors.Error
 }
 
 // RBACClientInterface defines the interface for RBAC client operations.
@@ -73,23 +81,58 @@ type UserAdminRepositoryInterface interface {
 
 // AuthService handles authentication and authorization.
 type AuthService struct {
-	userRepo           UserRepositoryInterface
-	userAdminRepo      UserAdminRepositoryInterface
-	kycRepo            KYCRepositoryInterface
-	sessionRepo        SessionRepositoryInterface
-	rbacClient         RBACClientInterface
-	walletClient       *WalletClient
-	notificationClient *clients.NotificationClient
-	jwtSecret          string
-	jwtExpiry          time.Duration
-	eventPublisher     *events.Publisher
-	cache              cache.Cache // Optional cache for session/user data
+	userRepo                  UserRepositoryInterface
+	userAdminRepo             UserAdminRepositoryInterface
+	kycRepo                   KYCRepositoryInterface
+	sessionRepo               SessionRepositoryInterface
+	rbacClient                RBACClientInterface
+	walletClient              *WalletClient
+	notificationClient        *clients.NotificationClient
+	jwtSecret                 string
+	jwtExpiry                 time.Duration
+	eventPublisher            *events.Publisher
+	cache                     cache.Cache   // Optional cache for session/user data
+	sessions                  *cache.Loader // Reads validated sessions through cache
+	tierLookup                TierLookup    // Optional tier source for the tier claim
+	kycChecker                KYCChecker    // Optional provider checks on KYC submission
+	contactChanges            ContactChangeRepositoryInterface
+	contactNotifier           ContactNotifier
+	contactVerifications      ContactVerificationRepositoryInterface // Optional: email and phone verification codes
+	contactVerifyNotifier     ContactNotifier
+	contactRequiredForWallets bool                            // Wallets are activated only once a contact is verified
+	lockouts                  LoginLockoutRepositoryInterface // Optional lockout after failed logins
+	lockoutNotifier           ContactNotifier
+	lockoutPolicy             LockoutPolicy
+	captcha                   CaptchaVerifier // Optional CAPTCHA enforcement for locked-out users
+}
+
+// TierLookup resolves a user's account tier code.
+type TierLookup interface {
+	GetUserTierCode(ctx context.Context, userID string) (string, *errors.Error)
+}
+
+// SetTierLookup sets the source for the tier claim issued in tokens.
+// If not set, every token carries the default tier.
+func (s *AuthService) SetTierLookup(l TierLookup) {
+	s.tierLookup = l
+}
+
+// KYCChecker runs provider checks on a submitted KYC.
+type KYCChecker interface {
+	CheckPAN(ctx context.Context, userID string) (*models.KYCCheck, *errors.Error)
+}
+
+// SetKYCChecker enables a PAN check against the KYC provider whenever a user
+// submits KYC. Results are recorded for the admin review; approval is unchanged.
+func (s *AuthService) SetKYCChecker(c KYCChecker) {
+	s.kycChecker = c
 }
 
 // SetCache sets the cache for session and user data caching.
 // This is optional - if not set, all lookups go directly to the database.
 func (s *AuthService) SetCache(c cache.Cache) {
 	s.cache = c
+	s.sessions = cache.NewLoader(c)
 }
 
 // NewAuthService creates a new authentication service.
@@ -125,6 +168,7 @@ type JWTClaims struct {
 	Email       string   `json:"email"`
 	Status      string   `json:"status"`
 	AccountType string   `json:"account_type,omitempty"` // Account type (user, user_admin, admin, super_admin)
+	Tier        string   `json:"tier,omitempty"`         // Account tier (regular, premium)
 	Roles       []string `json:"roles,omitempty"`        // User's role names
 	Permissions []string `json:"permissions,omitempty"`  // Flattened permission list
 	jwt.RegisteredClaims
@@ -202,13 +246,14 @@ func (s *AuthService) Register(ctx context.Context, req *models.CreateUserReques
 
 	// Publish user.registered event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishUserEvent("user.registered", user.ID, map[string]interface{}{
-			"email":         user.Email,
-			"phone":         user.Phone,
-			"full_name":     user.FullName,
-			"status":        string(user.Status),
-			"account_type":  string(user.AccountType),
-			"user_admin_id": userAdmin.ID,
+		s.eventPublisher.PublishAsync("users", events.UserRegistered{
+			UserID:      user.ID,
+			Email:       user.Email,
+			Phone:       user.Phone,
+			FullName:    user.FullName,
+			Status:      string(user.Status),
+			AccountType: string(user.AccountType),
+			UserAdminID: userAdmin.ID,
 		})
 	}
 
@@ -304,13 +349,21 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 
 	if err != nil {
 		// Don't reveal if user exists
+		loginFailures.WithLabelValues("unknown_user").Inc()
 		return nil, errors.Unauthorized("invalid credentials")
 	}
 
+	// Locked accounts and required CAPTCHAs are checked before the password
+	lockout, lockErr := s.checkLoginAllowed(ctx, user.ID, req.CaptchaToken, ipAddress)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+
 	// Verify password
 	if !s.verifyPassword(req.Password, user.PasswordHash) {
-		return nil, errors.Unauthorized("invalid credentials")
+		return nil, s.recordLoginFailure(ctx, user)
 	}
+	s.clearLoginFailures(ctx, lockout)
 
 	// Check if account is active
 	if user.Status == models.UserStatusClosed {
@@ -321,40 +374,9 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		return nil, errors.Forbidden("account is suspended")
 	}
 
-	// Fetch user permissions from RBAC service
-	var roles []string
-	var permissions []string
-
-	userPerms, rbacErr := s.rbacClient.GetUserPermissions(ctx, user.ID)
-	if rbacErr == nil {
-		// Extract role names
-		for _, role := range userPerms.Roles {
-			roles = append(roles, role.Name)
-		}
-		// Extract permission names
-		for _, perm := range userPerms.Permissions {
-			permissions = append(permissions, perm.Name)
-		}
-	}
-
-	// Generate JWT token with roles and permissions
-	token, expiresAt, genErr := s.generateToken(user, roles, permissions)
-	if genErr != nil {
-		return nil, errors.Internal("failed to generate token")
-	}
-
-	// Create session
-	tokenHash := s.hashToken(token)
-	session := &models.Session{
-		UserID:    user.ID,
-		Token:     tokenHash,
-		IPAddress: ipAddress,
-		UserAgent: userAgent,
-		ExpiresAt: sharedModels.NewTimestamp(time.Unix(expiresAt, 0)),
-	}
-
-	if err := s.sessionRepo.Create(ctx, session); err != nil {
-		return nil, err
+	token, expiresAt, sessionErr := s.createSession(ctx, user, ipAddress, userAgent)
+	if sessionErr != nil {
+		return nil, sessionErr
 	}
 
 	// Load KYC info if available (for regular users only)
@@ -403,22 +425,185 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 func (s *AuthService) Logout(ctx context.Context, token string) *errors.Error {
 	tokenHash := s.hashToken(token)
 
-	// Invalidate cache entry
-	if s.cache != nil {
-		_ = s.cache.Delete(ctx, cache.TokenKey(tokenHash))
+	// Invalidate cache entry, including any validation still loading it
+	if s.sessions != nil {
+		_ = s.sessions.Forget(ctx, cache.TokenKey(tokenHash))
 	}
 
 	return s.sessionRepo.DeleteByTokenHash(ctx, tokenHash)
 }
 
+// RefreshToken replaces the session of a valid token with a new one whose
+// token carries the user's current roles, permissions and tier, so changes
+// such as an approved tier upgrade take effect without logging in again.
+func (s *AuthService) RefreshToken(ctx context.Context, token, ipAddress, userAgent string) (*models.LoginResponse, *errors.Error) {
+	user, err := s.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, expiresAt, err := s.createSession(ctx, user, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	// The old token stops working once the new one is issued
+	if err := s.Logout(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:       newToken,
+		ExpiresAt:   expiresAt,
+		User:        user,
+		AccountType: user.AccountType,
+	}, nil
+}
+
 // LogoutAll invalidates all sessions for a user.
 func (s *AuthService) LogoutAll(ctx context.Context, userID string) *errors.Error {
-	// Note: Cache entries will expire naturally (TTL-based)
-	// For immediate invalidation of all user sessions, we'd need to track
-	// all token hashes per user. For now, rely on session DB check.
+	return s.revokeAllSessions(ctx, userID)
+}
+
+// ListSessions returns a user's active sessions, marking the one used by currentToken.
+func (s *AuthService) ListSessions(ctx context.Context, userID, currentToken string) ([]*models.SessionInfo, *errors.Error) {
+	sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentHash := s.hashToken(currentToken)
+	infos := make([]*models.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, &models.SessionInfo{
+			ID:         session.ID,
+			Device:     describeDevice(session.UserAgent),
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.Token == currentHash,
+		})
+	}
+
+	return infos, nil
+}
+
+// RevokeSession terminates one of a user's sessions.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID string) *errors.Error {
+	tokenHash, err := s.sessionRepo.DeleteByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	s.invalidateSessionCache(ctx, userID, tokenHash)
+	return nil
+}
+
+// ForceLogout terminates all sessions for a user on behalf of an admin.
+func (s *AuthService) ForceLogout(ctx context.Context, userID, adminUserID string) *errors.Error {
+	// Validate user exists
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.revokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.sessions_revoked", userID, map[string]interface{}{
+			"user_id":    userID,
+			"revoked_by": adminUserID,
+		})
+	}
+
+	return nil
+}
+
+// revokeAllSessions deletes every session for a user and evicts their cache entries
+// so revoked tokens stop validating immediately rather than at cache expiry.
+func (s *AuthService) revokeAllSessions(ctx context.Context, userID string) *errors.Error {
+	if s.cache != nil {
+		sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			s.invalidateSessionCache(ctx, userID, session.Token)
+		}
+		_ = s.cache.Delete(ctx, cache.UserKey(userID))
+	}
+
 	return s.sessionRepo.DeleteByUserID(ctx, userID)
 }
 
+// forgetCachedUser evicts a user's cached data and the cached validations of
+// all their sessions, after a change to the user that tokens carry.
+func (s *AuthService) forgetCachedUser(ctx context.Context, userID string) {
+	if s.cache == nil {
+		return
+	}
+	_ = s.cache.Delete(ctx, cache.UserKey(userID))
+	if sessions, err := s.sessionRepo.ListActiveByUserID(ctx, userID); err == nil {
+		for _, session := range sessions {
+			s.invalidateSessionCache(ctx, userID, session.Token)
+		}
+	}
+}
+
+// invalidateSessionCache evicts cached validation data for a session token hash.
+func (s *AuthService) invalidateSessionCache(ctx context.Context, userID, tokenHash string) {
+	if s.cache == nil {
+		return
+	}
+	_ = s.sessions.Forget(ctx, cache.TokenKey(tokenHash))
+	_ = s.cache.Delete(ctx, cache.SessionKey(userID, tokenHash))
+}
+
+// describeDevice derives a short device label from a user agent string.
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "chrome/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	platform := ""
+	switch {
+	case strings.Contains(ua, "android"):
+		platform = "Android"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		platform = "iOS"
+	case strings.Contains(ua, "windows"):
+		platform = "Windows"
+	case strings.Contains(ua, "mac os"):
+		platform = "macOS"
+	case strings.Contains(ua, "linux"):
+		platform = "Linux"
+	}
+
+	if platform == "" {
+		return browser
+	}
+	return browser + " on " + platform
+}
+
 // ValidateToken validates a JWT token and returns the user.
 func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*models.User, *errors.Error) {
 	// Parse and validate JWT
@@ -436,26 +621,55 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*m
 	}
 
 	tokenHash := s.hashToken(tokenString)
+	if s.sessions == nil {
+		return s.loadSessionUser(ctx, tokenHash)
+	}
 
-	// Try cache first (if available)
-	if s.cache != nil {
-		if user, ok := s.getUserFromCache(ctx, tokenHash); ok {
-			// Verify user status (could have changed)
-			if user.Status == models.UserStatusClosed || user.Status == models.UserStatusSuspended {
-				// Invalidate cache for suspended/closed users
-				_ = s.cache.Delete(ctx, cache.SessionKey(user.ID, tokenHash))
-				return nil, errors.Forbidden("account is not active")
-			}
-			return user, nil
+	// Concurrent requests with the same token share one database lookup
+	cacheKey := cache.TokenKey(tokenHash)
+	data, loadErr := s.sessions.GetOrLoad(ctx, cacheKey, cache.SessionTTL, func(ctx context.Context) (string, error) {
+		user, err := s.loadSessionUser(ctx, tokenHash)
+		if err != nil {
+			return "", err
 		}
+		encoded, jsonErr := json.Marshal(user)
+		if jsonErr != nil {
+			return "", jsonErr
+		}
+		return string(encoded), nil
+	})
+	if loadErr != nil {
+		var appErr *errors.Error
+		if errors.As(loadErr, &appErr) {
+			return nil, appErr
+		}
+		return nil, errors.InternalWrap(loadErr, "failed to validate session")
+	}
+
+	var user models.User
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		_ = s.sessions.Forget(ctx, cacheKey)
+		return s.loadSessionUser(ctx, tokenHash)
 	}
 
-	// Cache miss - check database
+	// Verify user status (could have changed)
+	if user.Status == models.UserStatusClosed || user.Status == models.UserStatusSuspended {
+		_ = s.sessions.Forget(ctx, cacheKey)
+		return nil, errors.Forbidden("account is not active")
+	}
+	return &user, nil
+}
+
+// loadSessionUser loads the user of an active session from the database.
+func (s *AuthService) loadSessionUser(ctx context.Context, tokenHash string) (*models.User, *errors.Error) {
 	session, err := s.sessionRepo.GetByTokenHash(ctx, tokenHash)
 	if err != nil {
 		return nil, err // Returns unauthorized error
 	}
 
+	// Record activity (best effort; with caching this is refreshed once per cache TTL)
+	_ = s.sessionRepo.TouchLastSeen(ctx, tokenHash)
+
 	// Get user
 	user, err := s.userRepo.GetByID(ctx, session.UserID)
 	if err != nil {
@@ -474,44 +688,9 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*m
 	}
 
 	user.Sanitize()
-
-	// Cache the user data for future requests
-	if s.cache != nil {
-		s.cacheUser(ctx, tokenHash, user)
-	}
-
 	return user, nil
 }
 
-// getUserFromCache retrieves user data from cache.
-func (s *AuthService) getUserFromCache(ctx context.Context, tokenHash string) (*models.User, bool) {
-	// Use token hash as part of the cache key
-	cacheKey := cache.TokenKey(tokenHash)
-	data, found, err := s.cache.Get(ctx, cacheKey)
-	if err != nil || !found {
-		return nil, false
-	}
-
-	var user models.User
-	if err := json.Unmarshal([]byte(data), &user); err != nil {
-		return nil, false
-	}
-
-	return &user, true
-}
-
-// cacheUser stores user data in cache.
-func (s *AuthService) cacheUser(ctx context.Context, tokenHash string, user *models.User) {
-	cacheKey := cache.TokenKey(tokenHash)
-	data, err := json.Marshal(user)
-	if err != nil {
-		return // Silently fail - caching is optional
-	}
-
-	// Cache for session TTL
-	_ = s.cache.Set(ctx, cacheKey, string(data), cache.SessionTTL)
-}
-
 // GetUserByID retrieves a user by ID.
 func (s *AuthService) GetUserByID(ctx context.Context, userID string) (*models.User, *errors.Error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -550,6 +729,14 @@ func (s *AuthService) LookupUserByPhone(ctx context.Context, phone string) (*mod
 
 // UpdateKYC updates or creates KYC information for a user.
 func (s *AuthService) UpdateKYC(ctx context.Context, userID string, req *models.UpdateKYCRequest) (*models.KYCInfo, *errors.Error) {
+	// Documents are checked here too, not only by request parsing
+	if !validator.IsValidPAN(req.PAN) {
+		return nil, errors.Validation("invalid PAN format (expected: ABCDE1234F in uppercase)")
+	}
+	if !validator.IsValidAadhaar(req.Aadhaar) {
+		return nil, errors.Validation("invalid Aadhaar number")
+	}
+
 	// Verify user exists
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -561,7 +748,7 @@ func (s *AuthService) UpdateKYC(ctx context.Context, userID string, req *models.
 		UserID:      userID,
 		Status:      models.KYCStatusPending,
 		PAN:         req.PAN,
-		Aadhaar:     req.Aadhaar,
+		Aadhaar:     strings.ReplaceAll(req.Aadhaar, " ", ""),
 		DateOfBirth: req.DateOfBirth,
 		Address:     req.Address,
 	}
@@ -570,6 +757,15 @@ func (s *AuthService) UpdateKYC(ctx context.Context, userID string, req *models.
 		return nil, err
 	}
 
+	// Run the provider PAN check so the reviewer sees its result. A failed
+	// check never blocks the submission.
+	panCheckStatus := "not_run"
+	if s.kycChecker != nil {
+		if check, checkErr := s.kycChecker.CheckPAN(ctx, userID); checkErr == nil {
+			panCheckStatus = string(check.Status)
+		}
+	}
+
 	// Publish user.kyc_updated event
 	if s.eventPublisher != nil {
 		s.eventPublisher.PublishUserEvent("user.kyc_updated", userID, map[string]interface{}{
@@ -597,6 +793,7 @@ func (s *AuthService) UpdateKYC(ctx context.Context, userID string, req *models.
 					"user_id":    userID,
 					"user_email": user.Email,
 					"pan":        kyc.PAN,
+					"pan_check":  panCheckStatus,
 					"action_url": fmt.Sprintf("/admin/kyc?user_id=%s", userID),
 				},
 				CorrelationID: &correlationID,
@@ -643,17 +840,10 @@ func (s *AuthService) VerifyKYC(ctx context.Context, userID string) *errors.Erro
 		})
 	}
 
-	// Activate user's wallets (KYC approval unlocks wallet functionality)
-	if s.walletClient != nil {
-		wallets, walletErr := s.walletClient.ListUserWallets(ctx, userID)
-		if walletErr == nil {
-			// Activate all inactive wallets
-			for _, wallet := range wallets {
-				if wallet.Status == "inactive" {
-					_ = s.walletClient.ActivateWallet(ctx, wallet.ID)
-				}
-			}
-		}
+	// Activate user's wallets (KYC approval unlocks wallet functionality).
+	// Without a verified contact they are activated once it is verified.
+	if s.walletsUnlocked(user) {
+		s.activateWallets(ctx, userID)
 	}
 
 	// Send KYC approved notification
@@ -768,29 +958,17 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *mod
 		return nil, err
 	}
 
-	// Track changed fields for event
-	changes := make(map[string]interface{})
-	emailChanged := user.Email != req.Email
-	phoneChanged := user.Phone != req.Phone
-
-	// Check if email is being changed and if it's already taken
-	if emailChanged {
-		existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
-		if existingUser != nil && existingUser.ID != userID {
-			return nil, errors.Conflict("email already in use")
-		}
-		changes["email"] = map[string]string{"old": user.Email, "new": req.Email}
+	// Email and phone only change through the verified contact change flow
+	if strings.TrimSpace(req.Email) != user.Email {
+		return nil, errors.BadRequest("email changes must be verified, use POST /api/v1/users/me/email/change")
 	}
-
-	// Check if phone is being changed and if it's already taken
-	if phoneChanged {
-		existingUser, _ := s.userRepo.GetByPhone(ctx, req.Phone)
-		if existingUser != nil && existingUser.ID != userID {
-			return nil, errors.Conflict("phone number already in use")
-		}
-		changes["phone"] = map[string]string{"old": user.Phone, "new": req.Phone}
+	if strings.TrimSpace(req.Phone) != user.Phone {
+		return nil, errors.BadRequest("phone changes must be verified, use POST /api/v1/users/me/phone/change")
 	}
 
+	// Track changed fields for event
+	changes := make(map[string]interface{})
+
 	// Track full name change
 	if user.FullName != req.FullName {
 		changes["full_name"] = map[string]string{"old": user.FullName, "new": req.FullName}
@@ -798,8 +976,6 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *mod
 
 	// Update user fields
 	user.FullName = req.FullName
-	user.Email = req.Email
-	user.Phone = req.Phone
 
 	// Save changes
 	if err := s.userRepo.Update(ctx, user); err != nil {
@@ -811,49 +987,6 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID string, req *mod
 		s.eventPublisher.PublishUserEvent("user.profile_updated", userID, changes)
 	}
 
-	// Send notification if email or phone changed (security notification)
-	if s.notificationClient != nil && (emailChanged || phoneChanged) {
-		correlationID := fmt.Sprintf("profile-updated-%s", userID)
-
-		// Send notification to old email if email was changed
-		if emailChanged {
-			emailReq := &clients.SendNotificationRequest{
-				UserID:     &userID,
-				Recipient:  changes["email"].(map[string]string)["old"],
-				Channel:    clients.NotificationChannelEmail,
-				Type:       "profile_change",
-				Priority:   clients.NotificationPriorityHigh,
-				TemplateID: "profile_email_changed",
-				Variables: map[string]interface{}{
-					"full_name": user.FullName,
-					"new_email": req.Email,
-				},
-				CorrelationID: &correlationID,
-				SourceService: "identity",
-			}
-			s.notificationClient.SendNotificationAsync(emailReq, "identity")
-		}
-
-		// Send SMS if phone was changed
-		if phoneChanged {
-			smsReq := &clients.SendNotificationRequest{
-				UserID:     &userID,
-				Recipient:  changes["phone"].(map[string]string)["old"],
-				Channel:    clients.NotificationChannelSMS,
-				Type:       "profile_change",
-				Priority:   clients.NotificationPriorityHigh,
-				TemplateID: "profile_phone_changed",
-				Variables: map[string]interface{}{
-					"full_name": user.FullName,
-					"new_phone": req.Phone,
-				},
-				CorrelationID: &correlationID,
-				SourceService: "identity",
-			}
-			s.notificationClient.SendNotificationAsync(smsReq, "identity")
-		}
-	}
-
 	// Sanitize before returning
 	user.Sanitize()
 
@@ -1020,8 +1153,58 @@ func (s *AuthService) verifyPassword(password, hash string) bool {
 	return err == nil
 }
 
+// createSession issues a token carrying the user's current roles,
+// permissions and tier, and records a session for it.
+func (s *AuthService) createSession(ctx context.Context, user *models.User, ipAddress, userAgent string) (string, int64, *errors.Error) {
+	// Fetch user permissions from RBAC service
+	var roles []string
+	var permissions []string
+
+	userPerms, rbacErr := s.rbacClient.GetUserPermissions(ctx, user.ID)
+	if rbacErr == nil {
+		// Extract role names
+		for _, role := range userPerms.Roles {
+			roles = append(roles, role.Name)
+		}
+		// Extract permission names
+		for _, perm := range userPerms.Permissions {
+			permissions = append(permissions, perm.Name)
+		}
+	}
+
+	// Resolve account tier for downstream limit decisions
+	tier := models.DefaultTier
+	if s.tierLookup != nil {
+		if code, tierErr := s.tierLookup.GetUserTierCode(ctx, user.ID); tierErr == nil {
+			tier = code
+		}
+	}
+
+	// Generate JWT token with roles, permissions and tier
+	token, expiresAt, genErr := s.generateToken(user, roles, permissions, tier)
+	if genErr != nil {
+		return "", 0, errors.Internal("failed to generate token")
+	}
+
+	// Create session
+	tokenHash := s.hashToken(token)
+	session := &models.Session{
+		UserID:    user.ID,
+		Token:     tokenHash,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: sharedModels.NewTimestamp(time.Unix(expiresAt, 0)),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return "", 0, err
+	}
+
+	return token, expiresAt, nil
+}
+
 // generateToken generates a JWT token for a user.
-func (s *AuthService) generateToken(user *models.User, roles []string, permissions []string) (string, int64, error) {
+func (s *AuthService) generateToken(user *models.User, roles []string, permissions []string, tier string) (string, int64, error) {
 	expiresAt := time.Now().Add(s.jwtExpiry)
 
 	claims := &JWTClaims{
@@ -1029,9 +1212,11 @@ func (s *AuthService) generateToken(user *models.User, roles []string, permissio
 		Email:       user.Email,
 		Status:      string(user.Status),
 		AccountType: string(user.AccountType),
+		Tier:        tier,
 		Roles:       roles,
 		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(), // Keeps tokens issued in the same second distinct
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "nivo-identity",
@@ -1077,7 +1262,7 @@ func (s *AuthService) SuspendUser(ctx context.Context, userID string, reason str
 	}
 
 	// Invalidate all active sessions (security measure)
-	_ = s.sessionRepo.DeleteByUserID(ctx, userID)
+	_ = s.revokeAllSessions(ctx, userID)
 
 	return nil
 }
@@ -1245,7 +1430,7 @@ func (s *AuthService) ChangePasswordWithToken(ctx context.Context, userID string
 
 // validateVerificationToken validates a verification token for password operations.
 func (s *AuthService) validateVerificationToken(tokenString string, expectedOp models.OperationType) (*models.VerificationClaims, *errors.Error) {
-	secret := os.Getenv("JWT_SECRET")
+	secret := config.Secret("JWT_SECRET")
 	if secret == "" {
 		// JWT_SECRET is validated at startup; this should never happen
 		return nil, errors.Internal("JWT_SECRET not configured")
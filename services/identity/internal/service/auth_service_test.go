@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
@@ -106,6 +107,16 @@ func (m *mockUserRepository) GetByPhone(ctx context.Context, phone string) (*mod
 	return user, nil
 }
 
+func (m *mockUserRepository) ListByIDs(ctx context.Context, ids []string) ([]*models.User, *errors.Error) {
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := m.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (m *mockUserRepository) GetByID(ctx context.Context, id string) (*models.User, *errors.Error) {
 	user, ok := m.users[id]
 	if !ok {
@@ -216,6 +227,23 @@ func (m *mockUserRepository) UnsuspendUser(ctx context.Context, userID string) *
 	return nil
 }
 
+func (m *mockUserRepository) MarkContactVerified(ctx context.Context, userID string, channel models.ContactChannel, value string) *errors.Error {
+	user, exists := m.users[userID]
+	if !exists {
+		return errors.NotFoundWithID("user", userID)
+	}
+	now := sharedModels.NewTimestamp(time.Now())
+	switch {
+	case channel == models.ContactChannelEmail && user.Email == value:
+		user.EmailVerifiedAt = &now
+	case channel == models.ContactChannelPhone && user.Phone == value:
+		user.PhoneVerifiedAt = &now
+	default:
+		return errors.Conflict(string(channel) + " changed since the code was sent")
+	}
+	return nil
+}
+
 type mockKYCRepository struct {
 	kycData         map[string]*models.KYCInfo
 	getByUserIDFunc func(ctx context.Context, userID string) (*models.KYCInfo, *errors.Error)
@@ -305,6 +333,33 @@ func (m *mockSessionRepository) DeleteByUserID(ctx context.Context, userID strin
 	return nil
 }
 
+func (m *mockSessionRepository) ListActiveByUserID(ctx context.Context, userID string) ([]*models.Session, *errors.Error) {
+	sessions := make([]*models.Session, 0)
+	for _, session := range m.sessions {
+		if session.UserID == userID && time.Now().Before(session.ExpiresAt.Time) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepository) DeleteByID(ctx context.Context, userID, sessionID string) (string, *errors.Error) {
+	session, ok := m.sessions[sessionID]
+	if !ok || session.UserID != userID {
+		return "", errors.NotFound("session")
+	}
+	delete(m.sessions, sessionID)
+	delete(m.tokenIndex, session.Token)
+	return session.Token, nil
+}
+
+func (m *mockSessionRepository) TouchLastSeen(ctx context.Context, tokenHash string) *errors.Error {
+	if session, ok := m.tokenIndex[tokenHash]; ok {
+		session.LastSeenAt = sharedModels.NewTimestamp(time.Now())
+	}
+	return nil
+}
+
 type mockRBACClient struct {
 	assignDefaultRoleFunc  func(ctx context.Context, userID string) error
 	getUserPermissionsFunc func(ctx context.Context, userID string) (*UserPermissionsResponse, error)
@@ -961,6 +1016,75 @@ func TestLogout_Error_InvalidToken(t *testing.T) {
 	}
 }
 
+// =====================================================================
+// RefreshToken Tests
+// =====================================================================
+
+func TestRefreshToken_CarriesCurrentTier(t *testing.T) {
+	service, userRepo, _, sessionRepo, _ := setupTestAuthService()
+	tierRepo := newMockTierRepository()
+	service.SetTierLookup(NewTierService(tierRepo, nil, nil))
+	ctx := context.Background()
+
+	password := "TestPassword123!"
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Email:        "test@example.com",
+		PasswordHash: hashPassword(password),
+		Status:       models.UserStatusActive,
+		AccountType:  models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+
+	loginResp, err := service.Login(ctx, &models.LoginRequest{Identifier: user.Email, Password: password}, "192.168.1.1", "Mozilla/5.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tier := tokenTier(t, loginResp.Token); tier != models.TierRegular {
+		t.Errorf("login tier = %q, want %q", tier, models.TierRegular)
+	}
+
+	// An approved upgrade shows up in the refreshed token
+	tierRepo.userTiers[user.ID] = models.TierPremium
+	refreshResp, err := service.RefreshToken(ctx, loginResp.Token, "192.168.1.1", "Mozilla/5.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tier := tokenTier(t, refreshResp.Token); tier != models.TierPremium {
+		t.Errorf("refreshed tier = %q, want %q", tier, models.TierPremium)
+	}
+
+	// The old token's session is replaced by the new one
+	if len(sessionRepo.sessions) != 1 {
+		t.Errorf("expected 1 session after refresh, got %d", len(sessionRepo.sessions))
+	}
+	if _, err := service.ValidateToken(ctx, loginResp.Token); err == nil {
+		t.Error("expected the refreshed token to be revoked")
+	}
+	if _, err := service.ValidateToken(ctx, refreshResp.Token); err != nil {
+		t.Errorf("expected the new token to be valid, got %v", err)
+	}
+}
+
+func TestRefreshToken_Error_InvalidToken(t *testing.T) {
+	service, _, _, _, _ := setupTestAuthService()
+
+	_, err := service.RefreshToken(context.Background(), "invalid-token", "192.168.1.1", "Mozilla/5.0")
+	if err == nil || err.Code != errors.ErrCodeUnauthorized {
+		t.Errorf("expected unauthorized error, got %v", err)
+	}
+}
+
+// tokenTier returns the tier claim of a token issued by the test service.
+func tokenTier(t *testing.T, token string) string {
+	t.Helper()
+	claims := &JWTClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	return claims.Tier
+}
+
 // =====================================================================
 // LogoutAll Tests
 // =====================================================================
@@ -1007,6 +1131,102 @@ func TestLogoutAll_Success(t *testing.T) {
 	}
 }
 
+// =====================================================================
+// Session Management Tests
+// =====================================================================
+
+func TestListSessions_MarksCurrent(t *testing.T) {
+	service, userRepo, _, _, _ := setupTestAuthService()
+	ctx := context.Background()
+
+	password := "TestPassword123!"
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Email:        "test@example.com",
+		PasswordHash: hashPassword(password),
+		Status:       models.UserStatusActive,
+		AccountType:  models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+
+	loginReq := &models.LoginRequest{
+		Identifier: "test@example.com",
+		Password:   password,
+	}
+	loginResp, _ := service.Login(ctx, loginReq, "192.168.1.1",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 Chrome/120.0 Safari/537.36")
+
+	sessions, err := service.ListSessions(ctx, user.ID, loginResp.Token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+	if !sessions[0].Current {
+		t.Error("expected session to be marked current")
+	}
+	if sessions[0].Device != "Chrome on macOS" {
+		t.Errorf("expected device 'Chrome on macOS', got %q", sessions[0].Device)
+	}
+}
+
+func TestRevokeSession_Success(t *testing.T) {
+	service, userRepo, _, sessionRepo, _ := setupTestAuthService()
+	ctx := context.Background()
+
+	password := "TestPassword123!"
+	user := &models.User{
+		ID:           uuid.New().String(),
+		Email:        "test@example.com",
+		PasswordHash: hashPassword(password),
+		Status:       models.UserStatusActive,
+		AccountType:  models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+
+	loginReq := &models.LoginRequest{
+		Identifier: "test@example.com",
+		Password:   password,
+	}
+	loginResp, _ := service.Login(ctx, loginReq, "192.168.1.1", "Mozilla/5.0")
+
+	sessions, _ := service.ListSessions(ctx, user.ID, loginResp.Token)
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d", len(sessions))
+	}
+
+	// Another user cannot revoke the session
+	if err := service.RevokeSession(ctx, uuid.New().String(), sessions[0].ID); err == nil {
+		t.Fatal("expected error revoking another user's session, got nil")
+	}
+
+	if err := service.RevokeSession(ctx, user.ID, sessions[0].ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(sessionRepo.sessions) != 0 {
+		t.Errorf("expected 0 sessions after revoke, got %d", len(sessionRepo.sessions))
+	}
+
+	// Revoked token no longer validates
+	if _, err := service.ValidateToken(ctx, loginResp.Token); err == nil {
+		t.Error("expected revoked token to be rejected")
+	}
+}
+
+func TestForceLogout_Error_UserNotFound(t *testing.T) {
+	service, _, _, _, _ := setupTestAuthService()
+	ctx := context.Background()
+
+	err := service.ForceLogout(ctx, uuid.New().String(), uuid.New().String())
+	if err == nil {
+		t.Fatal("expected error for unknown user, got nil")
+	}
+	if err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found error, got %s", err.Code)
+	}
+}
+
 // =====================================================================
 // ValidateToken Tests - CRITICAL PATH (100% coverage needed)
 // =====================================================================
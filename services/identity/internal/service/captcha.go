@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultCaptchaVerifyURL is reCAPTCHA's verification endpoint. hCaptcha and
+// Turnstile accept the same request at their own URLs.
+const DefaultCaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+
+// HTTPCaptchaVerifier verifies tokens with a siteverify-style endpoint.
+type HTTPCaptchaVerifier struct {
+	verifyURL  string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewHTTPCaptchaVerifier creates a verifier for the given endpoint and secret key.
+func NewHTTPCaptchaVerifier(verifyURL, secret string) *HTTPCaptchaVerifier {
+	return &HTTPCaptchaVerifier{
+		verifyURL:  verifyURL,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Verify posts the token to the endpoint and reports whether it was accepted.
+func (v *HTTPCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode captcha verification: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// contactChangeRateLimit is the minimum time between change requests for the same channel.
+const contactChangeRateLimit = time.Minute
+
+// ContactChangeRepositoryInterface defines the interface for contact change persistence.
+type ContactChangeRepositoryInterface interface {
+	Create(ctx context.Context, change *models.ContactChange) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.ContactChange, *errors.Error)
+	HasRecent(ctx context.Context, userID string, channel models.ContactChannel, window time.Duration) (bool, *errors.Error)
+	CancelPending(ctx context.Context, userID string, channel models.ContactChannel) *errors.Error
+	IncrementAttempts(ctx context.Context, id string) (int, *errors.Error)
+	UpdateStatus(ctx context.Context, id string, status models.ContactChangeStatus) *errors.Error
+}
+
+// ContactNotifier delivers contact change codes and security notices.
+// *clients.NotificationClient satisfies it.
+type ContactNotifier interface {
+	SendNotification(ctx context.Context, req *clients.SendNotificationRequest) (*clients.SendNotificationResponse, *errors.Error)
+	SendNotificationAsync(req *clients.SendNotificationRequest, serviceName string)
+}
+
+// SetContactChanges enables verified email and phone changes. Codes are
+// delivered through the notifier, so both are required.
+func (s *AuthService) SetContactChanges(repo ContactChangeRepositoryInterface, notifier ContactNotifier) {
+	s.contactChanges = repo
+	s.contactNotifier = notifier
+}
+
+// RequestContactChange starts moving a user's email or phone to a new
+// address. A code is sent to the new address; nothing changes until it is
+// confirmed with ConfirmContactChange.
+func (s *AuthService) RequestContactChange(ctx context.Context, userID string, channel models.ContactChannel, newValue, currentPassword string) (*models.ContactChange, *errors.Error) {
+	if s.contactChanges == nil || s.contactNotifier == nil {
+		return nil, errors.Unavailable("contact changes are not enabled")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.verifyPassword(currentPassword, user.PasswordHash) {
+		return nil, errors.Unauthorized("current password is incorrect")
+	}
+
+	newValue = strings.TrimSpace(newValue)
+	if newValue == currentContact(user, channel) {
+		return nil, errors.BadRequest(fmt.Sprintf("new %s is the same as the current one", channel))
+	}
+	if err := s.checkContactAvailable(ctx, user, channel, newValue); err != nil {
+		return nil, err
+	}
+
+	hasRecent, err := s.contactChanges.HasRecent(ctx, userID, channel, contactChangeRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if hasRecent {
+		return nil, errors.TooManyRequests("please wait before requesting another change")
+	}
+
+	// Only the latest request per channel can be confirmed
+	if err := s.contactChanges.CancelPending(ctx, userID, channel); err != nil {
+		return nil, err
+	}
+
+	code, codeErr := crypto.GenerateOTP6()
+	if codeErr != nil {
+		return nil, errors.Internal("failed to generate verification code")
+	}
+
+	now := time.Now()
+	change := &models.ContactChange{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Channel:   channel,
+		NewValue:  newValue,
+		Status:    models.ContactChangePending,
+		ExpiresAt: sharedModels.NewTimestamp(now.Add(repository.ContactChangeTTL)),
+		CreatedAt: sharedModels.NewTimestamp(now),
+	}
+	change.CodeHash = s.contactCodeHash(change.ID, code)
+
+	if err := s.contactChanges.Create(ctx, change); err != nil {
+		return nil, err
+	}
+
+	// Delivery is synchronous: a code that never arrives is a failed request
+	if _, sendErr := s.contactNotifier.SendNotification(ctx, contactCodeNotification(user, change, code)); sendErr != nil {
+		_ = s.contactChanges.UpdateStatus(ctx, change.ID, models.ContactChangeCancelled)
+		return nil, errors.Unavailable("failed to send verification code, please try again")
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.contact_change_requested", userID, map[string]interface{}{
+			"change_id": change.ID,
+			"channel":   channel,
+		})
+	}
+
+	return change, nil
+}
+
+// ConfirmContactChange applies a contact change once the code sent to the new
+// address is confirmed, and notifies the old address.
+func (s *AuthService) ConfirmContactChange(ctx context.Context, userID string, channel models.ContactChannel, changeID, code string) (*models.User, *errors.Error) {
+	if s.contactChanges == nil || s.contactNotifier == nil {
+		return nil, errors.Unavailable("contact changes are not enabled")
+	}
+	if !crypto.ValidateOTPFormat(code, 6) {
+		return nil, errors.BadRequest("code must be 6 digits")
+	}
+
+	change, err := s.contactChanges.GetByID(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if change.UserID != userID || change.Channel != channel {
+		return nil, errors.NotFound("contact change request not found")
+	}
+	if change.Status != models.ContactChangePending {
+		return nil, errors.BadRequest("contact change request is not pending")
+	}
+	if change.IsExpired() {
+		_ = s.contactChanges.UpdateStatus(ctx, changeID, models.ContactChangeExpired)
+		return nil, errors.BadRequest("contact change request has expired")
+	}
+
+	attempts, err := s.contactChanges.IncrementAttempts(ctx, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > repository.MaxVerificationAttempts {
+		_ = s.contactChanges.UpdateStatus(ctx, changeID, models.ContactChangeCancelled)
+		return nil, errors.TooManyRequests("too many attempts, contact change cancelled")
+	}
+	if !crypto.SecureCompare(change.CodeHash, s.contactCodeHash(changeID, code)) {
+		remaining := repository.MaxVerificationAttempts - attempts
+		if remaining <= 0 {
+			_ = s.contactChanges.UpdateStatus(ctx, changeID, models.ContactChangeCancelled)
+			return nil, errors.BadRequest("invalid code, contact change cancelled")
+		}
+		return nil, errors.BadRequest(fmt.Sprintf("invalid code (%d attempts remaining)", remaining))
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The address may have been taken since the code was sent
+	if err := s.checkContactAvailable(ctx, user, change.Channel, change.NewValue); err != nil {
+		_ = s.contactChanges.UpdateStatus(ctx, changeID, models.ContactChangeCancelled)
+		return nil, err
+	}
+
+	oldValue := currentContact(user, change.Channel)
+	if change.Channel == models.ContactChannelEmail {
+		user.Email = change.NewValue
+	} else {
+		user.Phone = change.NewValue
+	}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if err := s.contactChanges.UpdateStatus(ctx, changeID, models.ContactChangeConfirmed); err != nil {
+		return nil, err
+	}
+
+	// The confirmed code also verifies the new address
+	if err := s.userRepo.MarkContactVerified(ctx, userID, change.Channel, change.NewValue); err == nil {
+		now := sharedModels.NewTimestamp(time.Now())
+		if change.Channel == models.ContactChannelEmail {
+			user.EmailVerifiedAt = &now
+		} else {
+			user.PhoneVerifiedAt = &now
+		}
+	}
+
+	// The User-Admin account logs in with the same email as its paired user
+	if change.Channel == models.ContactChannelEmail {
+		if adminUserID, _ := s.userAdminRepo.GetAdminUserID(ctx, userID); adminUserID != "" {
+			if admin, adminErr := s.userRepo.GetByID(ctx, adminUserID); adminErr == nil {
+				admin.Email = change.NewValue
+				_ = s.userRepo.Update(ctx, admin)
+			}
+		}
+	}
+
+	// Cached token validations carry the old contact details
+	s.forgetCachedUser(ctx, userID)
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.profile_updated", userID, map[string]interface{}{
+			string(change.Channel): map[string]string{"old": oldValue, "new": change.NewValue},
+		})
+	}
+
+	if oldValue != "" {
+		s.contactNotifier.SendNotificationAsync(contactChangedNotification(user, change, oldValue), "identity")
+	}
+
+	user.Sanitize()
+	return user, nil
+}
+
+// checkContactAvailable returns a conflict if another account already uses
+// the address. Emails are unique per account type, and a regular user's email
+// is shared with its paired User-Admin account, so both are checked.
+func (s *AuthService) checkContactAvailable(ctx context.Context, user *models.User, channel models.ContactChannel, value string) *errors.Error {
+	if channel == models.ContactChannelPhone {
+		existing, _ := s.userRepo.GetByPhone(ctx, value)
+		if existing != nil && existing.ID != user.ID {
+			return errors.Conflict("phone number already in use")
+		}
+		return nil
+	}
+
+	existing, _ := s.userRepo.GetByEmailAndAccountType(ctx, value, user.AccountType)
+	if existing != nil && existing.ID != user.ID {
+		return errors.Conflict("email already in use")
+	}
+	if user.AccountType == models.AccountTypeUser {
+		adminUserID, _ := s.userAdminRepo.GetAdminUserID(ctx, user.ID)
+		existing, _ := s.userRepo.GetByEmailAndAccountType(ctx, value, models.AccountTypeUserAdmin)
+		if existing != nil && existing.ID != adminUserID {
+			return errors.Conflict("email already in use")
+		}
+	}
+	return nil
+}
+
+// contactCodeHash keys the code hash with the JWT secret and request ID, so
+// stored hashes cannot be matched against a precomputed table of codes.
+func (s *AuthService) contactCodeHash(changeID, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	mac.Write([]byte(changeID + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func currentContact(user *models.User, channel models.ContactChannel) string {
+	if channel == models.ContactChannelEmail {
+		return user.Email
+	}
+	return user.Phone
+}
+
+// contactCodeNotification builds the message carrying the code to the new address.
+func contactCodeNotification(user *models.User, change *models.ContactChange, code string) *clients.SendNotificationRequest {
+	correlationID := fmt.Sprintf("contact-change-%s", change.ID)
+	req := &clients.SendNotificationRequest{
+		UserID:     &user.ID,
+		Recipient:  change.NewValue,
+		Channel:    clients.NotificationChannelEmail,
+		Type:       clients.NotificationTypeOTP,
+		Priority:   clients.NotificationPriorityCritical,
+		TemplateID: "contact_change_code_email",
+		Variables: map[string]interface{}{
+			"full_name":        user.FullName,
+			"otp":              code,
+			"validity_minutes": int(repository.ContactChangeTTL.Minutes()),
+		},
+		CorrelationID: &correlationID,
+		SourceService: "identity",
+	}
+	if change.Channel == models.ContactChannelPhone {
+		req.Channel = clients.NotificationChannelSMS
+		req.TemplateID = "contact_change_code_sms"
+	}
+	return req
+}
+
+// contactChangedNotification builds the security notice sent to the old address.
+func contactChangedNotification(user *models.User, change *models.ContactChange, oldValue string) *clients.SendNotificationRequest {
+	correlationID := fmt.Sprintf("contact-changed-%s", change.ID)
+	req := &clients.SendNotificationRequest{
+		UserID:     &user.ID,
+		Recipient:  oldValue,
+		Channel:    clients.NotificationChannelEmail,
+		Type:       clients.NotificationTypeSecurityAlert,
+		Priority:   clients.NotificationPriorityHigh,
+		TemplateID: "profile_email_changed",
+		Variables: map[string]interface{}{
+			"full_name": user.FullName,
+			"new_email": maskEmail(change.NewValue),
+		},
+		CorrelationID: &correlationID,
+		SourceService: "identity",
+	}
+	if change.Channel == models.ContactChannelPhone {
+		req.Channel = clients.NotificationChannelSMS
+		req.TemplateID = "profile_phone_changed"
+		req.Variables = map[string]interface{}{
+			"full_name": user.FullName,
+			"new_phone": maskPhone(change.NewValue),
+		}
+	}
+	return req
+}
+
+// maskEmail hides most of the local part: "alice@example.com" -> "a***@example.com".
+func maskEmail(email string) string {
+	at := strings.IndexByte(email, '@')
+	if at <= 0 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// maskPhone keeps only the last four digits: "+919876543210" -> "******3210".
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", 6) + phone[len(phone)-4:]
+}
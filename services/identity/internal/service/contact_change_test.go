@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+type mockContactChangeRepository struct {
+	changes map[string]*models.ContactChange
+}
+
+func (m *mockContactChangeRepository) Create(ctx context.Context, change *models.ContactChange) *errors.Error {
+	m.changes[change.ID] = change
+	return nil
+}
+
+func (m *mockContactChangeRepository) GetByID(ctx context.Context, id string) (*models.ContactChange, *errors.Error) {
+	change, ok := m.changes[id]
+	if !ok {
+		return nil, errors.NotFound("contact change request not found")
+	}
+	copied := *change
+	return &copied, nil
+}
+
+func (m *mockContactChangeRepository) HasRecent(ctx context.Context, userID string, channel models.ContactChannel, window time.Duration) (bool, *errors.Error) {
+	for _, change := range m.changes {
+		if change.UserID == userID && change.Channel == channel && time.Since(change.CreatedAt.Time) < window {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockContactChangeRepository) CancelPending(ctx context.Context, userID string, channel models.ContactChannel) *errors.Error {
+	for _, change := range m.changes {
+		if change.UserID == userID && change.Channel == channel && change.Status == models.ContactChangePending {
+			change.Status = models.ContactChangeCancelled
+		}
+	}
+	return nil
+}
+
+func (m *mockContactChangeRepository) IncrementAttempts(ctx context.Context, id string) (int, *errors.Error) {
+	m.changes[id].AttemptCount++
+	return m.changes[id].AttemptCount, nil
+}
+
+func (m *mockContactChangeRepository) UpdateStatus(ctx context.Context, id string, status models.ContactChangeStatus) *errors.Error {
+	m.changes[id].Status = status
+	return nil
+}
+
+// mockContactNotifier records notifications; async sends are recorded immediately.
+type mockContactNotifier struct {
+	sent    []*clients.SendNotificationRequest
+	sendErr *errors.Error
+}
+
+func (m *mockContactNotifier) SendNotification(ctx context.Context, req *clients.SendNotificationRequest) (*clients.SendNotificationResponse, *errors.Error) {
+	if m.sendErr != nil {
+		return nil, m.sendErr
+	}
+	m.sent = append(m.sent, req)
+	return &clients.SendNotificationResponse{}, nil
+}
+
+func (m *mockContactNotifier) SendNotificationAsync(req *clients.SendNotificationRequest, serviceName string) {
+	m.sent = append(m.sent, req)
+}
+
+var _ ContactChangeRepositoryInterface = (*mockContactChangeRepository)(nil)
+var _ ContactNotifier = (*mockContactNotifier)(nil)
+
+func setupContactChangeTest(t *testing.T) (*AuthService, *mockUserRepository, *mockContactChangeRepository, *mockContactNotifier, *models.User) {
+	t.Helper()
+	service, userRepo, _, _, _ := setupTestAuthService()
+	changeRepo := &mockContactChangeRepository{changes: make(map[string]*models.ContactChange)}
+	notifier := &mockContactNotifier{}
+	service.SetContactChanges(changeRepo, notifier)
+
+	user := &models.User{
+		ID:           "user-1",
+		Email:        "old@example.com",
+		Phone:        "+919876543210",
+		FullName:     "Test User",
+		PasswordHash: hashPassword("Password123!"),
+		Status:       models.UserStatusActive,
+		AccountType:  models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+
+	return service, userRepo, changeRepo, notifier, user
+}
+
+func TestContactChange_EmailConfirmed(t *testing.T) {
+	service, userRepo, changeRepo, notifier, user := setupContactChangeTest(t)
+	admin := &models.User{ID: "admin-1", Email: "old@example.com", AccountType: models.AccountTypeUserAdmin}
+	addUserToMockRepo(userRepo, admin)
+	service.userAdminRepo.(*mockUserAdminRepository).pairings[user.ID] = admin.ID
+	ctx := context.Background()
+
+	change, err := service.RequestContactChange(ctx, user.ID, models.ContactChannelEmail, " new@example.com ", "Password123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if change.NewValue != "new@example.com" || change.Status != models.ContactChangePending {
+		t.Errorf("unexpected change: %+v", change)
+	}
+	if user.Email != "old@example.com" {
+		t.Error("expected email to stay unchanged until confirmed")
+	}
+
+	if len(notifier.sent) != 1 {
+		t.Fatalf("expected code notification, got %d", len(notifier.sent))
+	}
+	codeMsg := notifier.sent[0]
+	if codeMsg.Recipient != "new@example.com" || codeMsg.Channel != clients.NotificationChannelEmail {
+		t.Errorf("expected code sent by email to the new address, got %s via %s", codeMsg.Recipient, codeMsg.Channel)
+	}
+	code := codeMsg.Variables["otp"].(string)
+
+	updated, err := service.ConfirmContactChange(ctx, user.ID, models.ContactChannelEmail, change.ID, code)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.Email != "new@example.com" {
+		t.Errorf("expected new email, got %s", updated.Email)
+	}
+	if admin.Email != "new@example.com" {
+		t.Errorf("expected paired User-Admin email to follow, got %s", admin.Email)
+	}
+	if changeRepo.changes[change.ID].Status != models.ContactChangeConfirmed {
+		t.Errorf("expected confirmed change, got %s", changeRepo.changes[change.ID].Status)
+	}
+
+	if len(notifier.sent) != 2 {
+		t.Fatalf("expected security notice, got %d notifications", len(notifier.sent))
+	}
+	notice := notifier.sent[1]
+	if notice.Recipient != "old@example.com" || notice.TemplateID != "profile_email_changed" {
+		t.Errorf("expected security notice to the old address, got %s (%s)", notice.Recipient, notice.TemplateID)
+	}
+
+	// A confirmed change cannot be replayed
+	if _, err := service.ConfirmContactChange(ctx, user.ID, models.ContactChannelEmail, change.ID, code); err == nil {
+		t.Error("expected confirmed change to be rejected")
+	}
+}
+
+func TestContactChange_PhoneConflict(t *testing.T) {
+	service, userRepo, _, notifier, user := setupContactChangeTest(t)
+	addUserToMockRepo(userRepo, &models.User{ID: "user-2", Email: "other@example.com", Phone: "+919123456789", AccountType: models.AccountTypeUser})
+
+	_, err := service.RequestContactChange(context.Background(), user.ID, models.ContactChannelPhone, "+919123456789", "Password123!")
+	if err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if len(notifier.sent) != 0 {
+		t.Error("expected no code to be sent")
+	}
+}
+
+func TestContactChange_ConflictAtConfirm(t *testing.T) {
+	service, userRepo, changeRepo, notifier, user := setupContactChangeTest(t)
+	ctx := context.Background()
+
+	change, err := service.RequestContactChange(ctx, user.ID, models.ContactChannelPhone, "+919123456789", "Password123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if notifier.sent[0].Channel != clients.NotificationChannelSMS {
+		t.Errorf("expected code by SMS, got %s", notifier.sent[0].Channel)
+	}
+
+	// Another account takes the number before the code is confirmed
+	addUserToMockRepo(userRepo, &models.User{ID: "user-2", Email: "other@example.com", Phone: "+919123456789", AccountType: models.AccountTypeUser})
+
+	_, err = service.ConfirmContactChange(ctx, user.ID, models.ContactChannelPhone, change.ID, notifier.sent[0].Variables["otp"].(string))
+	if err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+	if user.Phone != "+919876543210" {
+		t.Errorf("expected phone unchanged, got %s", user.Phone)
+	}
+	if changeRepo.changes[change.ID].Status != models.ContactChangeCancelled {
+		t.Errorf("expected cancelled change, got %s", changeRepo.changes[change.ID].Status)
+	}
+}
+
+func TestContactChange_RequestErrors(t *testing.T) {
+	service, _, _, notifier, user := setupContactChangeTest(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		value    string
+		password string
+		wantCode errors.ErrorCode
+	}{
+		{"wrong password", "new@example.com", "wrong", errors.ErrCodeUnauthorized},
+		{"same email", "old@example.com", "Password123!", errors.ErrCodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RequestContactChange(ctx, user.ID, models.ContactChannelEmail, tt.value, tt.password)
+			if err == nil || err.Code != tt.wantCode {
+				t.Errorf("expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+
+	notifier.sendErr = errors.Unavailable("notification service down")
+	if _, err := service.RequestContactChange(ctx, user.ID, models.ContactChannelEmail, "new@example.com", "Password123!"); err == nil || err.Code != errors.ErrCodeUnavailable {
+		t.Errorf("expected unavailable when the code cannot be sent, got %v", err)
+	}
+}
+
+func TestContactChange_InvalidCode(t *testing.T) {
+	service, _, changeRepo, notifier, user := setupContactChangeTest(t)
+	ctx := context.Background()
+
+	change, err := service.RequestContactChange(ctx, user.ID, models.ContactChannelEmail, "new@example.com", "Password123!")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	code := notifier.sent[0].Variables["otp"].(string)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	// The change ID only confirms for its own channel
+	if _, err := service.ConfirmContactChange(ctx, user.ID, models.ContactChannelPhone, change.ID, code); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found for the wrong channel, got %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err := service.ConfirmContactChange(ctx, user.ID, models.ContactChannelEmail, change.ID, wrong); err == nil {
+			t.Fatal("expected invalid code to be rejected")
+		}
+	}
+	if changeRepo.changes[change.ID].Status != models.ContactChangeCancelled {
+		t.Errorf("expected change cancelled after max attempts, got %s", changeRepo.changes[change.ID].Status)
+	}
+	if _, err := service.ConfirmContactChange(ctx, user.ID, models.ContactChannelEmail, change.ID, code); err == nil {
+		t.Error("expected the correct code to be rejected once cancelled")
+	}
+	if user.Email != "old@example.com" {
+		t.Errorf("expected email unchanged, got %s", user.Email)
+	}
+}
+
+func TestUpdateProfile_RejectsUnverifiedContactChange(t *testing.T) {
+	service, _, _, _, user := setupContactChangeTest(t)
+
+	_, err := service.UpdateProfile(context.Background(), user.ID, &models.UpdateProfileRequest{
+		FullName: "New Name",
+		Email:    "new@example.com",
+		Phone:    user.Phone,
+	})
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request, got %v", err)
+	}
+
+	updated, err := service.UpdateProfile(context.Background(), user.ID, &models.UpdateProfileRequest{
+		FullName: "New Name",
+		Email:    user.Email,
+		Phone:    user.Phone,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.FullName != "New Name" {
+		t.Errorf("expected name updated, got %s", updated.FullName)
+	}
+}
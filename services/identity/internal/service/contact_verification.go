@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ContactVerificationRepositoryInterface defines the verification request
+// storage used for contact verification codes. *repository.VerificationRepository
+// satisfies it.
+type ContactVerificationRepositoryInterface interface {
+	Create(ctx context.Context, req *models.VerificationRequest) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.VerificationRequest, *errors.Error)
+	HasRecentVerification(ctx context.Context, userID string, operationType models.OperationType, within time.Duration) (bool, *errors.Error)
+	CancelPendingByOperation(ctx context.Context, userID string, operationType models.OperationType) *errors.Error
+	IncrementAttempts(ctx context.Context, id string) (int, *errors.Error)
+	UpdateStatus(ctx context.Context, id string, status models.VerificationStatus) *errors.Error
+}
+
+// SetContactVerification enables email and phone verification. With
+// requireForWallets, a user's wallets are activated only after KYC approval
+// and a verified contact, whichever comes last; otherwise KYC approval alone
+// activates them.
+func (s *AuthService) SetContactVerification(repo ContactVerificationRepositoryInterface, notifier ContactNotifier, requireForWallets bool) {
+	s.contactVerifications = repo
+	s.contactVerifyNotifier = notifier
+	s.contactRequiredForWallets = requireForWallets
+}
+
+// SendContactVerification sends a code to the user's current email or phone.
+// Requesting a new code cancels the previous one.
+func (s *AuthService) SendContactVerification(ctx context.Context, userID string, channel models.ContactChannel) (*models.VerificationRequest, *errors.Error) {
+	if s.contactVerifications == nil || s.contactVerifyNotifier == nil {
+		return nil, errors.Unavailable("contact verification is not enabled")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	value := currentContact(user, channel)
+	if value == "" {
+		return nil, errors.BadRequest(fmt.Sprintf("no %s on the account", channel))
+	}
+	if contactVerifiedAt(user, channel) != nil {
+		return nil, errors.Conflict(fmt.Sprintf("%s is already verified", channel))
+	}
+
+	operation := contactVerificationOperation(channel)
+	hasRecent, err := s.contactVerifications.HasRecentVerification(ctx, userID, operation, repository.VerificationRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if hasRecent {
+		return nil, errors.TooManyRequests("please wait before requesting another code")
+	}
+
+	// Only the latest code per channel can be verified
+	if err := s.contactVerifications.CancelPendingByOperation(ctx, userID, operation); err != nil {
+		return nil, err
+	}
+
+	code, codeErr := crypto.GenerateOTP6()
+	if codeErr != nil {
+		return nil, errors.Internal("failed to generate verification code")
+	}
+
+	now := time.Now()
+	req := &models.VerificationRequest{
+		ID:            "ver_" + uuid.New().String()[:8],
+		UserID:        userID,
+		OperationType: operation,
+		OTPCode:       code,
+		Status:        models.VerificationStatusPending,
+		Metadata:      models.VerificationMeta{"value": value},
+		ExpiresAt:     sharedModels.NewTimestamp(now.Add(repository.VerificationTTL)),
+		CreatedAt:     sharedModels.NewTimestamp(now),
+	}
+	if err := s.contactVerifications.Create(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// Delivery is synchronous: a code that never arrives is a failed request
+	if _, sendErr := s.contactVerifyNotifier.SendNotification(ctx, contactVerificationNotification(user, channel, req, code)); sendErr != nil {
+		_ = s.contactVerifications.UpdateStatus(ctx, req.ID, models.VerificationStatusCancelled)
+		return nil, errors.Unavailable("failed to send verification code, please try again")
+	}
+
+	return req.SanitizeForUser(), nil
+}
+
+// VerifyContact marks the user's email or phone verified once the code sent
+// to it is confirmed. If the user's KYC is already approved, their wallets
+// are activated.
+func (s *AuthService) VerifyContact(ctx context.Context, userID string, channel models.ContactChannel, verificationID, code string) (*models.User, *errors.Error) {
+	if s.contactVerifications == nil || s.contactVerifyNotifier == nil {
+		return nil, errors.Unavailable("contact verification is not enabled")
+	}
+	if !crypto.ValidateOTPFormat(code, 6) {
+		return nil, errors.BadRequest("code must be 6 digits")
+	}
+
+	req, err := s.contactVerifications.GetByID(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != userID || req.OperationType != contactVerificationOperation(channel) {
+		return nil, errors.NotFound("verification request not found")
+	}
+	if !req.IsPending() {
+		return nil, errors.BadRequest("verification request is not pending")
+	}
+	if req.IsExpired() {
+		_ = s.contactVerifications.UpdateStatus(ctx, verificationID, models.VerificationStatusExpired)
+		return nil, errors.BadRequest("verification request has expired")
+	}
+
+	attempts, err := s.contactVerifications.IncrementAttempts(ctx, verificationID)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > repository.MaxVerificationAttempts {
+		_ = s.contactVerifications.UpdateStatus(ctx, verificationID, models.VerificationStatusCancelled)
+		return nil, errors.TooManyRequests("too many attempts, verification cancelled")
+	}
+	if !crypto.SecureCompare(req.OTPCode, code) {
+		remaining := repository.MaxVerificationAttempts - attempts
+		if remaining <= 0 {
+			_ = s.contactVerifications.UpdateStatus(ctx, verificationID, models.VerificationStatusCancelled)
+			return nil, errors.BadRequest("invalid code, verification cancelled")
+		}
+		return nil, errors.BadRequest(fmt.Sprintf("invalid code (%d attempts remaining)", remaining))
+	}
+
+	// The code proves the address it was sent to, which must still be current
+	value, _ := req.Metadata["value"].(string)
+	if err := s.userRepo.MarkContactVerified(ctx, userID, channel, value); err != nil {
+		_ = s.contactVerifications.UpdateStatus(ctx, verificationID, models.VerificationStatusCancelled)
+		return nil, err
+	}
+	if err := s.contactVerifications.UpdateStatus(ctx, verificationID, models.VerificationStatusVerified); err != nil {
+		return nil, err
+	}
+
+	// Cached token validations carry the old verification status
+	s.forgetCachedUser(ctx, userID)
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.contact_verified", userID, map[string]interface{}{
+			"channel": channel,
+		})
+	}
+
+	if kyc, kycErr := s.kycRepo.GetByUserID(ctx, userID); kycErr == nil && kyc.IsKYCVerified() {
+		s.activateWallets(ctx, userID)
+	}
+
+	return s.GetUserByID(ctx, userID)
+}
+
+// walletsUnlocked reports whether a user's wallets may be activated on KYC
+// approval. When contact verification gates wallets, a verified contact is
+// required.
+func (s *AuthService) walletsUnlocked(user *models.User) bool {
+	return s.contactVerifications == nil || !s.contactRequiredForWallets || user.HasVerifiedContact()
+}
+
+// activateWallets activates all of a user's inactive wallets (best effort).
+func (s *AuthService) activateWallets(ctx context.Context, userID string) {
+	if s.walletClient == nil {
+		return
+	}
+	wallets, err := s.walletClient.ListUserWallets(ctx, userID)
+	if err != nil {
+		return
+	}
+	for _, wallet := range wallets {
+		if wallet.Status == "inactive" {
+			_ = s.walletClient.ActivateWallet(ctx, wallet.ID)
+		}
+	}
+}
+
+func contactVerificationOperation(channel models.ContactChannel) models.OperationType {
+	if channel == models.ContactChannelPhone {
+		return models.OpPhoneVerification
+	}
+	return models.OpEmailVerification
+}
+
+func contactVerifiedAt(user *models.User, channel models.ContactChannel) *sharedModels.Timestamp {
+	if channel == models.ContactChannelPhone {
+		return user.PhoneVerifiedAt
+	}
+	return user.EmailVerifiedAt
+}
+
+// contactVerificationNotification builds the message carrying the code to the address.
+func contactVerificationNotification(user *models.User, channel models.ContactChannel, req *models.VerificationRequest, code string) *clients.SendNotificationRequest {
+	correlationID := fmt.Sprintf("contact-verification-%s", req.ID)
+	n := &clients.SendNotificationRequest{
+		UserID:     &user.ID,
+		Recipient:  user.Email,
+		Channel:    clients.NotificationChannelEmail,
+		Type:       clients.NotificationTypeOTP,
+		Priority:   clients.NotificationPriorityCritical,
+		TemplateID: "contact_verification_code_email",
+		Variables: map[string]interface{}{
+			"full_name":        user.FullName,
+			"otp":              code,
+			"validity_minutes": int(repository.VerificationTTL.Minutes()),
+		},
+		CorrelationID: &correlationID,
+		SourceService: "identity",
+	}
+	if channel == models.ContactChannelPhone {
+		n.Recipient = user.Phone
+		n.Channel = clients.NotificationChannelSMS
+		n.TemplateID = "contact_verification_code_sms"
+	}
+	return n
+}
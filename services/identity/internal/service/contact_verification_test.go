@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+type mockContactVerificationRepository struct {
+	requests map[string]*models.VerificationRequest
+}
+
+func (m *mockContactVerificationRepository) Create(ctx context.Context, req *models.VerificationRequest) *errors.Error {
+	m.requests[req.ID] = req
+	return nil
+}
+
+func (m *mockContactVerificationRepository) GetByID(ctx context.Context, id string) (*models.VerificationRequest, *errors.Error) {
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, errors.NotFound("verification request not found")
+	}
+	copied := *req
+	return &copied, nil
+}
+
+func (m *mockContactVerificationRepository) HasRecentVerification(ctx context.Context, userID string, operationType models.OperationType, within time.Duration) (bool, *errors.Error) {
+	for _, req := range m.requests {
+		if req.UserID == userID && req.OperationType == operationType && req.IsPending() && time.Since(req.CreatedAt.Time) < within {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockContactVerificationRepository) CancelPendingByOperation(ctx context.Context, userID string, operationType models.OperationType) *errors.Error {
+	for _, req := range m.requests {
+		if req.UserID == userID && req.OperationType == operationType && req.IsPending() {
+			req.Status = models.VerificationStatusCancelled
+		}
+	}
+	return nil
+}
+
+func (m *mockContactVerificationRepository) IncrementAttempts(ctx context.Context, id string) (int, *errors.Error) {
+	m.requests[id].AttemptCount++
+	return m.requests[id].AttemptCount, nil
+}
+
+func (m *mockContactVerificationRepository) UpdateStatus(ctx context.Context, id string, status models.VerificationStatus) *errors.Error {
+	m.requests[id].Status = status
+	return nil
+}
+
+var _ ContactVerificationRepositoryInterface = (*mockContactVerificationRepository)(nil)
+
+// walletServer fakes the wallet service, recording activated wallet IDs.
+func walletServer(t *testing.T, activated *[]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/wallets":
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":"wallet-1","status":"inactive"},{"id":"wallet-2","status":"active"}]}`))
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/activate"):
+			*activated = append(*activated, strings.Split(r.URL.Path, "/")[4])
+			_, _ = w.Write([]byte(`{"success":true,"data":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func setupContactVerificationTest(t *testing.T) (*AuthService, *mockContactVerificationRepository, *mockContactNotifier, *models.User, *[]string) {
+	t.Helper()
+	service, userRepo, kycRepo, _, _ := setupTestAuthService()
+	verificationRepo := &mockContactVerificationRepository{requests: make(map[string]*models.VerificationRequest)}
+	notifier := &mockContactNotifier{}
+	service.SetContactVerification(verificationRepo, notifier, true)
+
+	activated := &[]string{}
+	service.walletClient = NewWalletClient(walletServer(t, activated).URL)
+
+	user := &models.User{
+		ID:          "user-1",
+		Email:       "user@example.com",
+		Phone:       "+919876543210",
+		FullName:    "Test User",
+		Status:      models.UserStatusPending,
+		AccountType: models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+	kycRepo.kycData[user.ID] = &models.KYCInfo{UserID: user.ID, Status: models.KYCStatusPending}
+
+	return service, verificationRepo, notifier, user, activated
+}
+
+func TestContactVerification_EmailVerified(t *testing.T) {
+	service, verificationRepo, notifier, user, _ := setupContactVerificationTest(t)
+	ctx := context.Background()
+
+	req, err := service.SendContactVerification(ctx, user.ID, models.ContactChannelEmail)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if req.OTPCode != "" {
+		t.Error("expected the code to be omitted from the response")
+	}
+	if len(notifier.sent) != 1 {
+		t.Fatalf("expected code notification, got %d", len(notifier.sent))
+	}
+	msg := notifier.sent[0]
+	if msg.Recipient != user.Email || msg.Channel != clients.NotificationChannelEmail {
+		t.Errorf("expected code sent by email to %s, got %s via %s", user.Email, msg.Recipient, msg.Channel)
+	}
+
+	if _, err := service.VerifyContact(ctx, user.ID, models.ContactChannelPhone, req.ID, msg.Variables["otp"].(string)); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected an email code to be rejected for the phone, got %v", err)
+	}
+
+	updated, err := service.VerifyContact(ctx, user.ID, models.ContactChannelEmail, req.ID, msg.Variables["otp"].(string))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.EmailVerifiedAt == nil || updated.PhoneVerifiedAt != nil {
+		t.Errorf("expected only the email to be verified, got %v / %v", updated.EmailVerifiedAt, updated.PhoneVerifiedAt)
+	}
+	if verificationRepo.requests[req.ID].Status != models.VerificationStatusVerified {
+		t.Errorf("expected verified request, got %s", verificationRepo.requests[req.ID].Status)
+	}
+
+	if _, err := service.SendContactVerification(ctx, user.ID, models.ContactChannelEmail); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected a conflict for an already verified email, got %v", err)
+	}
+}
+
+func TestContactVerification_ResendThrottled(t *testing.T) {
+	service, verificationRepo, _, user, _ := setupContactVerificationTest(t)
+	ctx := context.Background()
+
+	first, err := service.SendContactVerification(ctx, user.ID, models.ContactChannelPhone)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := service.SendContactVerification(ctx, user.ID, models.ContactChannelPhone); err == nil || err.Code != errors.ErrCodeRateLimit {
+		t.Fatalf("expected the resend to be throttled, got %v", err)
+	}
+
+	// Once the window has passed, a new code replaces the old one
+	verificationRepo.requests[first.ID].CreatedAt.Time = time.Now().Add(-2 * time.Minute)
+	second, err := service.SendContactVerification(ctx, user.ID, models.ContactChannelPhone)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if verificationRepo.requests[first.ID].Status != models.VerificationStatusCancelled {
+		t.Errorf("expected the first code to be cancelled, got %s", verificationRepo.requests[first.ID].Status)
+	}
+	if verificationRepo.requests[second.ID].Status != models.VerificationStatusPending {
+		t.Errorf("expected the new code to be pending, got %s", verificationRepo.requests[second.ID].Status)
+	}
+}
+
+func TestContactVerification_Expired(t *testing.T) {
+	service, verificationRepo, notifier, user, _ := setupContactVerificationTest(t)
+	ctx := context.Background()
+
+	req, _ := service.SendContactVerification(ctx, user.ID, models.ContactChannelEmail)
+	verificationRepo.requests[req.ID].ExpiresAt.Time = time.Now().Add(-time.Second)
+
+	if _, err := service.VerifyContact(ctx, user.ID, models.ContactChannelEmail, req.ID, notifier.sent[0].Variables["otp"].(string)); err == nil {
+		t.Fatal("expected an expired code to be rejected")
+	}
+	if verificationRepo.requests[req.ID].Status != models.VerificationStatusExpired {
+		t.Errorf("expected expired request, got %s", verificationRepo.requests[req.ID].Status)
+	}
+	if user.EmailVerifiedAt != nil {
+		t.Error("expected the email to stay unverified")
+	}
+}
+
+func TestContactVerification_AddressChanged(t *testing.T) {
+	service, verificationRepo, notifier, user, _ := setupContactVerificationTest(t)
+	ctx := context.Background()
+
+	req, _ := service.SendContactVerification(ctx, user.ID, models.ContactChannelEmail)
+	user.Email = "other@example.com"
+
+	if _, err := service.VerifyContact(ctx, user.ID, models.ContactChannelEmail, req.ID, notifier.sent[0].Variables["otp"].(string)); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Fatalf("expected a conflict after the email changed, got %v", err)
+	}
+	if verificationRepo.requests[req.ID].Status != models.VerificationStatusCancelled {
+		t.Errorf("expected cancelled request, got %s", verificationRepo.requests[req.ID].Status)
+	}
+	if user.EmailVerifiedAt != nil {
+		t.Error("expected the new email to stay unverified")
+	}
+}
+
+func TestContactVerification_GatesWalletActivation(t *testing.T) {
+	service, _, notifier, user, activated := setupContactVerificationTest(t)
+	ctx := context.Background()
+
+	// KYC approval alone leaves the wallets inactive
+	if err := service.VerifyKYC(ctx, user.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(*activated) != 0 {
+		t.Fatalf("expected no wallet activation without a verified contact, got %v", *activated)
+	}
+
+	// Verifying a contact after KYC approval activates them
+	req, _ := service.SendContactVerification(ctx, user.ID, models.ContactChannelPhone)
+	if _, err := service.VerifyContact(ctx, user.ID, models.ContactChannelPhone, req.ID, notifier.sent[0].Variables["otp"].(string)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(*activated) != 1 || (*activated)[0] != "wallet-1" {
+		t.Errorf("expected the inactive wallet to be activated, got %v", *activated)
+	}
+}
+
+func TestContactVerification_NotRequiredForWallets(t *testing.T) {
+	service, verificationRepo, notifier, user, activated := setupContactVerificationTest(t)
+	service.SetContactVerification(verificationRepo, notifier, false)
+
+	// Without the gate, KYC approval activates the wallets on its own
+	if err := service.VerifyKYC(context.Background(), user.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(*activated) != 1 || (*activated)[0] != "wallet-1" {
+		t.Errorf("expected the inactive wallet to be activated, got %v", *activated)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// GetDisplayNames returns how each of the given users is shown to other
+// users. Closed accounts and unknown IDs are left out, so callers fall back
+// to their own placeholder.
+func (s *AuthService) GetDisplayNames(ctx context.Context, userIDs []string) ([]*models.UserDisplayName, *errors.Error) {
+	if len(userIDs) > models.MaxDisplayNameLookup {
+		return nil, errors.Validation("too many user IDs").
+			AddDetail("max", models.MaxDisplayNameLookup)
+	}
+
+	ids := make([]string, 0, len(userIDs))
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		if _, err := uuid.Parse(id); err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	names := make([]*models.UserDisplayName, 0, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	users, err := s.userRepo.ListByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Status == models.UserStatusClosed {
+			continue
+		}
+		name := &models.UserDisplayName{
+			UserID:      user.ID,
+			DisplayName: displayName(user.FullName),
+		}
+		if user.Phone != "" {
+			name.MaskedPhone = maskPhone(user.Phone)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// displayName shortens a full name to the first name and the initial of the
+// last one: "Priya Sharma" -> "Priya S.", "Anil Kumar Verma" -> "Anil V.".
+func displayName(fullName string) string {
+	parts := strings.Fields(fullName)
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return parts[0]
+	}
+
+	last, _ := utf8.DecodeRuneInString(parts[len(parts)-1])
+	return parts[0] + " " + string(unicode.ToUpper(last)) + "."
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/google/uuid"
+)
+
+func TestDisplayName(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     string
+	}{
+		{"Priya Sharma", "Priya S."},
+		{"Anil Kumar Verma", "Anil V."},
+		{"  ravi   iyer ", "ravi I."},
+		{"Madonna", "Madonna"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := displayName(tt.fullName); got != tt.want {
+			t.Errorf("displayName(%q) = %q, want %q", tt.fullName, got, tt.want)
+		}
+	}
+}
+
+func TestGetDisplayNames(t *testing.T) {
+	svc, userRepo, _, _, _ := setupTestAuthService()
+
+	activeID := uuid.New().String()
+	closedID := uuid.New().String()
+	userRepo.users[activeID] = &models.User{ID: activeID, FullName: "Priya Sharma", Email: "priya@example.com", Phone: "+919876543210", Status: models.UserStatusActive}
+	userRepo.users[closedID] = &models.User{ID: closedID, FullName: "Closed User", Phone: "+919876500000", Status: models.UserStatusClosed}
+
+	names, err := svc.GetDisplayNames(context.Background(), []string{activeID, activeID, closedID, uuid.New().String(), "not-a-uuid"})
+	if err != nil {
+		t.Fatalf("GetDisplayNames() error = %v", err)
+	}
+	if len(names) != 1 {
+		t.Fatalf("expected 1 display name, got %d", len(names))
+	}
+	want := models.UserDisplayName{UserID: activeID, DisplayName: "Priya S.", MaskedPhone: "******3210"}
+	if *names[0] != want {
+		t.Errorf("display name = %+v, want %+v", *names[0], want)
+	}
+}
+
+func TestGetDisplayNames_TooMany(t *testing.T) {
+	svc, _, _, _, _ := setupTestAuthService()
+
+	ids := make([]string, models.MaxDisplayNameLookup+1)
+	for i := range ids {
+		ids[i] = uuid.New().String()
+	}
+	_, err := svc.GetDisplayNames(context.Background(), ids)
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
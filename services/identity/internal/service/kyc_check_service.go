@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/kyc"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// KYCCheckRepositoryInterface defines the interface for KYC check persistence.
+type KYCCheckRepositoryInterface interface {
+	Create(ctx context.Context, check *models.KYCCheck) *errors.Error
+	GetByReference(ctx context.Context, userID string, checkType models.KYCCheckType, referenceID string) (*models.KYCCheck, *errors.Error)
+	Complete(ctx context.Context, check *models.KYCCheck) *errors.Error
+	ListByUser(ctx context.Context, userID string) ([]*models.KYCCheck, *errors.Error)
+}
+
+// KYCCheckService runs identity checks against the configured KYC provider and
+// records the results for the admin review. Approval stays with the admin.
+type KYCCheckService struct {
+	provider  kyc.Provider
+	checkRepo KYCCheckRepositoryInterface
+	kycRepo   KYCRepositoryInterface
+	userRepo  UserRepositoryInterface
+}
+
+// NewKYCCheckService creates a new KYC check service.
+func NewKYCCheckService(provider kyc.Provider, checkRepo KYCCheckRepositoryInterface, kycRepo KYCRepositoryInterface, userRepo UserRepositoryInterface) *KYCCheckService {
+	return &KYCCheckService{
+		provider:  provider,
+		checkRepo: checkRepo,
+		kycRepo:   kycRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// CheckPAN verifies the PAN from the user's KYC submission.
+func (s *KYCCheckService) CheckPAN(ctx context.Context, userID string) (*models.KYCCheck, *errors.Error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.kycRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, providerErr := s.provider.VerifyPAN(ctx, kyc.PANRequest{
+		PAN:         info.PAN,
+		FullName:    user.FullName,
+		DateOfBirth: info.DateOfBirth,
+	})
+	return s.record(ctx, userID, models.KYCCheckPAN, result, providerErr)
+}
+
+// RequestAadhaarOTP starts an Aadhaar OTP check for the user's submitted
+// Aadhaar number. The returned check carries the reference to verify against.
+func (s *KYCCheckService) RequestAadhaarOTP(ctx context.Context, userID string) (*models.KYCCheck, *errors.Error) {
+	info, err := s.kycRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, providerErr := s.provider.RequestAadhaarOTP(ctx, info.Aadhaar)
+	if providerErr != nil {
+		return nil, errors.Unavailable("KYC provider is unavailable, please try again")
+	}
+	return s.record(ctx, userID, models.KYCCheckAadhaarOTP, result, nil)
+}
+
+// VerifyAadhaarOTP completes a pending Aadhaar OTP check.
+func (s *KYCCheckService) VerifyAadhaarOTP(ctx context.Context, userID string, req *models.VerifyAadhaarOTPRequest) (*models.KYCCheck, *errors.Error) {
+	check, err := s.checkRepo.GetByReference(ctx, userID, models.KYCCheckAadhaarOTP, req.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	if check.Status != models.KYCCheckPending {
+		return nil, errors.Conflict("Aadhaar OTP check is already complete")
+	}
+
+	result, providerErr := s.provider.VerifyAadhaarOTP(ctx, kyc.AadhaarOTPRequest{
+		ReferenceID: req.ReferenceID,
+		OTP:         req.OTP,
+	})
+	if providerErr != nil {
+		// Leave the check pending so the user can retry
+		return nil, errors.Unavailable("KYC provider is unavailable, please try again")
+	}
+
+	check.Status = models.KYCCheckStatus(result.Status)
+	check.Score = result.Score
+	check.Reason = optionalString(result.Reason)
+	if err := s.checkRepo.Complete(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// FaceMatch compares a selfie with the user's ID photo.
+func (s *KYCCheckService) FaceMatch(ctx context.Context, userID string, req *models.FaceMatchRequest) (*models.KYCCheck, *errors.Error) {
+	if _, err := s.kycRepo.GetByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	result, providerErr := s.provider.FaceMatch(ctx, kyc.FaceMatchRequest{
+		UserID:      userID,
+		SelfieImage: req.SelfieImage,
+	})
+	return s.record(ctx, userID, models.KYCCheckFaceMatch, result, providerErr)
+}
+
+// ListChecks returns a user's checks, newest first.
+func (s *KYCCheckService) ListChecks(ctx context.Context, userID string) ([]*models.KYCCheck, *errors.Error) {
+	return s.checkRepo.ListByUser(ctx, userID)
+}
+
+// record stores a provider result. Provider failures are stored as errored
+// checks so the reviewer can see the check was attempted.
+func (s *KYCCheckService) record(ctx context.Context, userID string, checkType models.KYCCheckType, result *kyc.Result, providerErr error) (*models.KYCCheck, *errors.Error) {
+	check := &models.KYCCheck{
+		UserID:    userID,
+		Provider:  s.provider.Name(),
+		CheckType: checkType,
+	}
+	if providerErr != nil {
+		check.Status = models.KYCCheckError
+		check.Reason = optionalString(providerErr.Error())
+	} else {
+		check.Status = models.KYCCheckStatus(result.Status)
+		check.ReferenceID = optionalString(result.ReferenceID)
+		check.Score = result.Score
+		check.Reason = optionalString(result.Reason)
+	}
+
+	if err := s.checkRepo.Create(ctx, check); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/kyc"
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// mockKYCCheckRepository stores checks in memory.
+type mockKYCCheckRepository struct {
+	checks []*models.KYCCheck
+}
+
+func (m *mockKYCCheckRepository) Create(ctx context.Context, check *models.KYCCheck) *errors.Error {
+	check.ID = fmt.Sprintf("check-%d", len(m.checks)+1)
+	m.checks = append(m.checks, check)
+	return nil
+}
+
+func (m *mockKYCCheckRepository) GetByReference(ctx context.Context, userID string, checkType models.KYCCheckType, referenceID string) (*models.KYCCheck, *errors.Error) {
+	for _, c := range m.checks {
+		if c.UserID == userID && c.CheckType == checkType && c.ReferenceID != nil && *c.ReferenceID == referenceID {
+			return c, nil
+		}
+	}
+	return nil, errors.NotFound("KYC check")
+}
+
+func (m *mockKYCCheckRepository) Complete(ctx context.Context, check *models.KYCCheck) *errors.Error {
+	return nil
+}
+
+func (m *mockKYCCheckRepository) ListByUser(ctx context.Context, userID string) ([]*models.KYCCheck, *errors.Error) {
+	var out []*models.KYCCheck
+	for _, c := range m.checks {
+		if c.UserID == userID {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// unavailableProvider fails every call, like a provider outage.
+type unavailableProvider struct{ *kyc.MockProvider }
+
+func (unavailableProvider) VerifyPAN(context.Context, kyc.PANRequest) (*kyc.Result, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+var _ KYCCheckRepositoryInterface = (*mockKYCCheckRepository)(nil)
+
+func setupKYCCheckService(provider kyc.Provider) (*KYCCheckService, *mockKYCCheckRepository) {
+	userRepo := &mockUserRepository{users: map[string]*models.User{
+		"user-1": {ID: "user-1", FullName: "Asha Rao"},
+	}}
+	kycRepo := &mockKYCRepository{kycData: map[string]*models.KYCInfo{
+		"user-1": {UserID: "user-1", PAN: "ABCPE1234F", Aadhaar: "123412341234", DateOfBirth: "1990-01-01"},
+	}}
+	checkRepo := &mockKYCCheckRepository{}
+	return NewKYCCheckService(provider, checkRepo, kycRepo, userRepo), checkRepo
+}
+
+func TestKYCCheckService_CheckPAN(t *testing.T) {
+	svc, checkRepo := setupKYCCheckService(kyc.NewMockProvider(0.8))
+
+	check, err := svc.CheckPAN(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check.Status != models.KYCCheckPassed || check.Provider != "mock" || check.CheckType != models.KYCCheckPAN {
+		t.Errorf("unexpected check: %+v", check)
+	}
+	if len(checkRepo.checks) != 1 {
+		t.Errorf("expected check to be stored, got %d", len(checkRepo.checks))
+	}
+
+	if _, err := svc.CheckPAN(context.Background(), "user-2"); err == nil {
+		t.Error("expected error for user without KYC")
+	}
+}
+
+func TestKYCCheckService_CheckPAN_ProviderError(t *testing.T) {
+	svc, _ := setupKYCCheckService(unavailableProvider{kyc.NewMockProvider(0.8)})
+
+	check, err := svc.CheckPAN(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("provider outage should be recorded, not returned: %v", err)
+	}
+	if check.Status != models.KYCCheckError || check.Reason == nil {
+		t.Errorf("expected errored check with reason, got %+v", check)
+	}
+}
+
+func TestKYCCheckService_AadhaarOTP(t *testing.T) {
+	svc, _ := setupKYCCheckService(kyc.NewMockProvider(0.8))
+	ctx := context.Background()
+
+	pending, err := svc.RequestAadhaarOTP(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pending.Status != models.KYCCheckPending || pending.ReferenceID == nil {
+		t.Fatalf("expected pending check with reference, got %+v", pending)
+	}
+
+	check, err := svc.VerifyAadhaarOTP(ctx, "user-1", &models.VerifyAadhaarOTPRequest{ReferenceID: *pending.ReferenceID, OTP: kyc.MockOTP})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check.Status != models.KYCCheckPassed {
+		t.Errorf("expected passed check, got %s", check.Status)
+	}
+
+	_, err = svc.VerifyAadhaarOTP(ctx, "user-1", &models.VerifyAadhaarOTPRequest{ReferenceID: *pending.ReferenceID, OTP: kyc.MockOTP})
+	if err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict when verifying twice, got %v", err)
+	}
+
+	_, err = svc.VerifyAadhaarOTP(ctx, "user-1", &models.VerifyAadhaarOTPRequest{ReferenceID: "unknown", OTP: kyc.MockOTP})
+	if err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found for unknown reference, got %v", err)
+	}
+}
+
+func TestKYCCheckService_FaceMatch(t *testing.T) {
+	svc, _ := setupKYCCheckService(kyc.NewMockProvider(0.8))
+
+	check, err := svc.FaceMatch(context.Background(), "user-1", &models.FaceMatchRequest{SelfieImage: "mismatch"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if check.Status != models.KYCCheckFailed || check.Score == nil || *check.Score >= 0.8 {
+		t.Errorf("expected failed match below threshold, got %+v", check)
+	}
+}
+
+func TestAuthService_UpdateKYC_RunsPANCheck(t *testing.T) {
+	authService, userRepo, kycRepo, _, _ := setupTestAuthService()
+	addUserToMockRepo(userRepo, &models.User{ID: "user-1", Email: "asha@example.com", FullName: "Asha Rao"})
+
+	checkRepo := &mockKYCCheckRepository{}
+	authService.SetKYCChecker(NewKYCCheckService(kyc.NewMockProvider(0.8), checkRepo, kycRepo, userRepo))
+
+	_, err := authService.UpdateKYC(context.Background(), "user-1", &models.UpdateKYCRequest{
+		PAN:         "ABCCE1234F", // Company PAN, fails the mock check
+		Aadhaar:     "234567890127",
+		DateOfBirth: "1990-01-01",
+	})
+	if err != nil {
+		t.Fatalf("failed check must not block submission: %v", err)
+	}
+	if len(checkRepo.checks) != 1 || checkRepo.checks[0].Status != models.KYCCheckFailed {
+		t.Errorf("expected a failed PAN check to be recorded, got %+v", checkRepo.checks)
+	}
+
+	stored, _ := kycRepo.GetByUserID(context.Background(), "user-1")
+	if stored.Status != models.KYCStatusPending {
+		t.Errorf("expected KYC to stay pending for admin review, got %s", stored.Status)
+	}
+}
+
+func TestAuthService_UpdateKYC_RejectsInvalidDocuments(t *testing.T) {
+	authService, userRepo, kycRepo, _, _ := setupTestAuthService()
+	addUserToMockRepo(userRepo, &models.User{ID: "user-1", Email: "asha@example.com", FullName: "Asha Rao"})
+	ctx := context.Background()
+
+	for name, req := range map[string]*models.UpdateKYCRequest{
+		"lowercase PAN":           {PAN: "abcpe1234f", Aadhaar: "234567890127"},
+		"aadhaar wrong checksum":  {PAN: "ABCPE1234F", Aadhaar: "234567890123"},
+		"aadhaar starting with 1": {PAN: "ABCPE1234F", Aadhaar: "123412341234"},
+	} {
+		if _, err := authService.UpdateKYC(ctx, "user-1", req); err == nil || err.Code != errors.ErrCodeValidation {
+			t.Errorf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+	if _, err := kycRepo.GetByUserID(ctx, "user-1"); err == nil {
+		t.Error("expected no KYC to be stored")
+	}
+
+	// Grouped Aadhaar digits are stored without spaces
+	if _, err := authService.UpdateKYC(ctx, "user-1", &models.UpdateKYCRequest{PAN: "ABCPE1234F", Aadhaar: "2345 6789 0127"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if stored, _ := kycRepo.GetByUserID(ctx, "user-1"); stored.Aadhaar != "234567890127" {
+		t.Errorf("expected normalized Aadhaar, got %q", stored.Aadhaar)
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// KYC review queue defaults.
+const (
+	DefaultKYCReviewSLA     = 24 * time.Hour
+	DefaultKYCQueuePageSize = 50
+	MaxKYCQueuePageSize     = 100
+	DefaultKYCMetricsPeriod = 7 * 24 * time.Hour
+	MaxKYCMetricsPeriod     = 90 * 24 * time.Hour
+	kycDueSoonFractionOfSLA = 4 // due_soon is the last quarter of the SLA
+)
+
+// KYCReviewRepositoryInterface defines the interface for review queue storage.
+type KYCReviewRepositoryInterface interface {
+	ListQueue(ctx context.Context, filter models.KYCQueueFilter) ([]*models.KYCQueueItem, *errors.Error)
+	GetQueueItem(ctx context.Context, userID string) (*models.KYCQueueItem, *errors.Error)
+	Assign(ctx context.Context, userID, reviewerID string, reassign bool) *errors.Error
+	Release(ctx context.Context, userID, reviewerID string) *errors.Error
+	RecordDecision(ctx context.Context, record *models.KYCReviewRecord) *errors.Error
+	ReviewerThroughput(ctx context.Context, from, to time.Time) ([]*models.KYCReviewerMetrics, *errors.Error)
+	QueueStats(ctx context.Context, overdueBefore time.Time) (*models.KYCQueueStats, *errors.Error)
+}
+
+// KYCDecider applies a KYC decision: status change, account activation,
+// events and notifications. AuthService implements it.
+type KYCDecider interface {
+	VerifyKYC(ctx context.Context, userID string) *errors.Error
+	RejectKYC(ctx context.Context, userID string, reason string) *errors.Error
+}
+
+// KYCReviewService runs the back-office KYC review queue: reviewers claim
+// pending submissions, decide them with a reason code within the SLA, and
+// their throughput is reported.
+type KYCReviewService struct {
+	reviewRepo KYCReviewRepositoryInterface
+	decider    KYCDecider
+	sla        time.Duration
+	now        func() time.Time
+}
+
+// NewKYCReviewService creates a review service. Submissions are due sla after
+// they are submitted; sla <= 0 uses DefaultKYCReviewSLA.
+func NewKYCReviewService(reviewRepo KYCReviewRepositoryInterface, decider KYCDecider, sla time.Duration) *KYCReviewService {
+	if sla <= 0 {
+		sla = DefaultKYCReviewSLA
+	}
+	return &KYCReviewService{
+		reviewRepo: reviewRepo,
+		decider:    decider,
+		sla:        sla,
+		now:        time.Now,
+	}
+}
+
+// SLA returns the review deadline measured from submission.
+func (s *KYCReviewService) SLA() time.Duration {
+	return s.sla
+}
+
+// ListQueue returns pending submissions matching the filter, oldest first,
+// with their SLA countdown.
+func (s *KYCReviewService) ListQueue(ctx context.Context, filter models.KYCQueueFilter) ([]*models.KYCQueueItem, *errors.Error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultKYCQueuePageSize
+	}
+	if filter.Limit > MaxKYCQueuePageSize {
+		filter.Limit = MaxKYCQueuePageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	if filter.AssignedTo != "" && filter.Unassigned {
+		return nil, errors.BadRequest("filter by assignee or unassigned, not both")
+	}
+
+	// An SLA state is a bound on the submission time
+	now := s.now()
+	var dueBefore time.Time
+	switch filter.SLA {
+	case "":
+	case models.KYCSLAOverdue:
+		dueBefore = now
+	case models.KYCSLADueSoon:
+		dueBefore = now.Add(s.sla / kycDueSoonFractionOfSLA)
+	default:
+		return nil, errors.BadRequest("invalid sla filter: " + string(filter.SLA))
+	}
+	if !dueBefore.IsZero() {
+		submittedBefore := dueBefore.Add(-s.sla)
+		if filter.SubmittedBefore == nil || submittedBefore.Before(*filter.SubmittedBefore) {
+			filter.SubmittedBefore = &submittedBefore
+		}
+	}
+
+	items, err := s.reviewRepo.ListQueue(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		s.applySLA(item, now)
+	}
+	return items, nil
+}
+
+// GetSubmission returns a user's KYC submission with its review state.
+func (s *KYCReviewService) GetSubmission(ctx context.Context, userID string) (*models.KYCQueueItem, *errors.Error) {
+	item, err := s.reviewRepo.GetQueueItem(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.applySLA(item, s.now())
+	return item, nil
+}
+
+// applySLA fills in the SLA countdown of a submission.
+func (s *KYCReviewService) applySLA(item *models.KYCQueueItem, now time.Time) {
+	due := item.SubmittedAt.Add(s.sla)
+	remaining := due.Sub(now)
+	item.SLA = models.KYCReviewSLA{
+		DueAt:            sharedModels.NewTimestamp(due),
+		RemainingSeconds: int64(remaining / time.Second),
+		Breached:         remaining < 0,
+	}
+}
+
+// Claim assigns a pending submission to the reviewer calling it. Claiming a
+// submission another reviewer holds is a conflict.
+func (s *KYCReviewService) Claim(ctx context.Context, userID, reviewerID string) (*models.KYCQueueItem, *errors.Error) {
+	if err := s.reviewRepo.Assign(ctx, userID, reviewerID, false); err != nil {
+		return nil, err
+	}
+	return s.GetSubmission(ctx, userID)
+}
+
+// Assign gives a pending submission to a reviewer, taking it from whoever
+// held it.
+func (s *KYCReviewService) Assign(ctx context.Context, userID, reviewerID string) (*models.KYCQueueItem, *errors.Error) {
+	if err := s.reviewRepo.Assign(ctx, userID, reviewerID, true); err != nil {
+		return nil, err
+	}
+	return s.GetSubmission(ctx, userID)
+}
+
+// Release returns a submission the reviewer holds to the queue.
+func (s *KYCReviewService) Release(ctx context.Context, userID, reviewerID string) (*models.KYCQueueItem, *errors.Error) {
+	if err := s.reviewRepo.Release(ctx, userID, reviewerID); err != nil {
+		return nil, err
+	}
+	return s.GetSubmission(ctx, userID)
+}
+
+// Approve verifies a submission with an approval reason code.
+func (s *KYCReviewService) Approve(ctx context.Context, userID, reviewerID string, req *models.DecideKYCRequest) (*models.KYCReviewRecord, *errors.Error) {
+	return s.decide(ctx, userID, reviewerID, models.KYCDecisionApproved, req)
+}
+
+// Reject rejects a submission with a rejection reason code. The user is told
+// the reason code's description, followed by the note when one is given.
+func (s *KYCReviewService) Reject(ctx context.Context, userID, reviewerID string, req *models.DecideKYCRequest) (*models.KYCReviewRecord, *errors.Error) {
+	return s.decide(ctx, userID, reviewerID, models.KYCDecisionRejected, req)
+}
+
+// decide applies a decision to a pending submission held by the reviewer. An
+// unassigned submission is claimed first, so a reviewer can decide straight
+// from the queue.
+func (s *KYCReviewService) decide(ctx context.Context, userID, reviewerID string, decision models.KYCDecision, req *models.DecideKYCRequest) (*models.KYCReviewRecord, *errors.Error) {
+	reason, ok := models.LookupKYCReasonCode(decision, req.ReasonCode)
+	if !ok {
+		return nil, errors.BadRequest("invalid reason code for " + string(decision) + ": " + string(req.ReasonCode))
+	}
+	note := strings.TrimSpace(req.Note)
+	if reason.NoteRequired && note == "" {
+		return nil, errors.BadRequest("a note is required with reason code " + string(req.ReasonCode))
+	}
+	if len(note) > models.MaxKYCReviewNoteLength {
+		return nil, errors.BadRequest("note cannot exceed 500 characters")
+	}
+
+	item, err := s.reviewRepo.GetQueueItem(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if item.KYC.Status != models.KYCStatusPending {
+		return nil, errors.Conflict("KYC submission is no longer pending")
+	}
+	if item.AssignedTo == nil {
+		if err := s.reviewRepo.Assign(ctx, userID, reviewerID, false); err != nil {
+			return nil, err
+		}
+		if item, err = s.reviewRepo.GetQueueItem(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	if item.AssignedTo == nil || *item.AssignedTo != reviewerID {
+		return nil, errors.Conflict("KYC submission is assigned to another reviewer")
+	}
+
+	if decision == models.KYCDecisionApproved {
+		err = s.decider.VerifyKYC(ctx, userID)
+	} else {
+		message := reason.Description
+		if note != "" {
+			message += ": " + note
+		}
+		err = s.decider.RejectKYC(ctx, userID, message)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := &models.KYCReviewRecord{
+		UserID:      userID,
+		ReviewerID:  reviewerID,
+		Decision:    decision,
+		ReasonCode:  req.ReasonCode,
+		SubmittedAt: item.SubmittedAt,
+		SLADueAt:    sharedModels.NewTimestamp(item.SubmittedAt.Add(s.sla)),
+	}
+	if item.AssignedAt != nil {
+		record.ClaimedAt = *item.AssignedAt
+	} else {
+		record.ClaimedAt = sharedModels.NewTimestamp(s.now())
+	}
+	if note != "" {
+		record.Note = &note
+	}
+
+	// The decision has been applied; a failure here only loses the audit row
+	// and is reported so the reviewer knows metrics are incomplete
+	if err := s.reviewRepo.RecordDecision(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Metrics reports per-reviewer throughput for decisions made in [from, to)
+// along with the current backlog. A zero to means now; a zero from means
+// DefaultKYCMetricsPeriod before to.
+func (s *KYCReviewService) Metrics(ctx context.Context, from, to time.Time) (*models.KYCReviewMetrics, *errors.Error) {
+	now := s.now()
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultKYCMetricsPeriod)
+	}
+	if !from.Before(to) {
+		return nil, errors.BadRequest("from must be before to")
+	}
+	if to.Sub(from) > MaxKYCMetricsPeriod {
+		return nil, errors.BadRequest("metrics period cannot exceed 90 days")
+	}
+
+	reviewers, err := s.reviewRepo.ReviewerThroughput(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.reviewRepo.QueueStats(ctx, now.Add(-s.sla))
+	if err != nil {
+		return nil, err
+	}
+
+	// Reviewers holding submissions show up even without decisions
+	seen := make(map[string]bool, len(reviewers))
+	for _, r := range reviewers {
+		r.Assigned = stats.Assigned[r.ReviewerID]
+		seen[r.ReviewerID] = true
+	}
+	for _, reviewerID := range slices.Sorted(maps.Keys(stats.Assigned)) {
+		if !seen[reviewerID] {
+			reviewers = append(reviewers, &models.KYCReviewerMetrics{ReviewerID: reviewerID, Assigned: stats.Assigned[reviewerID]})
+		}
+	}
+
+	return &models.KYCReviewMetrics{
+		From:       sharedModels.NewTimestamp(from),
+		To:         sharedModels.NewTimestamp(to),
+		Pending:    stats.Pending,
+		Unassigned: stats.Unassigned,
+		Overdue:    stats.Overdue,
+		Reviewers:  reviewers,
+	}, nil
+}
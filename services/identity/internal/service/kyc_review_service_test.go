@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// mockKYCReviewRepository keeps the review queue in memory.
+type mockKYCReviewRepository struct {
+	items      map[string]*models.KYCQueueItem
+	records    []*models.KYCReviewRecord
+	lastFilter models.KYCQueueFilter
+	throughput []*models.KYCReviewerMetrics
+	stats      *models.KYCQueueStats
+}
+
+func (m *mockKYCReviewRepository) ListQueue(ctx context.Context, filter models.KYCQueueFilter) ([]*models.KYCQueueItem, *errors.Error) {
+	m.lastFilter = filter
+	var out []*models.KYCQueueItem
+	for _, item := range m.items {
+		if item.KYC.Status == models.KYCStatusPending {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockKYCReviewRepository) GetQueueItem(ctx context.Context, userID string) (*models.KYCQueueItem, *errors.Error) {
+	item, ok := m.items[userID]
+	if !ok {
+		return nil, errors.NotFoundWithID("KYC submission", userID)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (m *mockKYCReviewRepository) Assign(ctx context.Context, userID, reviewerID string, reassign bool) *errors.Error {
+	item, ok := m.items[userID]
+	if !ok || item.KYC.Status != models.KYCStatusPending ||
+		(!reassign && item.AssignedTo != nil && *item.AssignedTo != reviewerID) {
+		return errors.Conflict("KYC submission is assigned to another reviewer or no longer pending")
+	}
+	now := sharedModels.Now()
+	item.AssignedTo, item.AssignedAt = &reviewerID, &now
+	return nil
+}
+
+func (m *mockKYCReviewRepository) Release(ctx context.Context, userID, reviewerID string) *errors.Error {
+	item, ok := m.items[userID]
+	if !ok || item.AssignedTo == nil || *item.AssignedTo != reviewerID {
+		return errors.Conflict("KYC submission is not assigned to you")
+	}
+	item.AssignedTo, item.AssignedAt = nil, nil
+	return nil
+}
+
+func (m *mockKYCReviewRepository) RecordDecision(ctx context.Context, record *models.KYCReviewRecord) *errors.Error {
+	record.ID = "decision-1"
+	record.DecidedAt = sharedModels.Now()
+	m.records = append(m.records, record)
+	return nil
+}
+
+func (m *mockKYCReviewRepository) ReviewerThroughput(ctx context.Context, from, to time.Time) ([]*models.KYCReviewerMetrics, *errors.Error) {
+	return m.throughput, nil
+}
+
+func (m *mockKYCReviewRepository) QueueStats(ctx context.Context, overdueBefore time.Time) (*models.KYCQueueStats, *errors.Error) {
+	return m.stats, nil
+}
+
+var _ KYCReviewRepositoryInterface = (*mockKYCReviewRepository)(nil)
+
+// recordingDecider records decisions instead of applying them.
+type recordingDecider struct {
+	verified map[string]bool
+	rejected map[string]string
+}
+
+func (d *recordingDecider) VerifyKYC(ctx context.Context, userID string) *errors.Error {
+	d.verified[userID] = true
+	return nil
+}
+
+func (d *recordingDecider) RejectKYC(ctx context.Context, userID string, reason string) *errors.Error {
+	d.rejected[userID] = reason
+	return nil
+}
+
+func setupKYCReviewService(now time.Time) (*KYCReviewService, *mockKYCReviewRepository, *recordingDecider) {
+	repo := &mockKYCReviewRepository{items: map[string]*models.KYCQueueItem{
+		"user-1": {
+			KYC:         models.KYCInfo{UserID: "user-1", Status: models.KYCStatusPending},
+			SubmittedAt: sharedModels.NewTimestamp(now.Add(-30 * time.Hour)),
+		},
+	}}
+	decider := &recordingDecider{verified: map[string]bool{}, rejected: map[string]string{}}
+
+	svc := NewKYCReviewService(repo, decider, 24*time.Hour)
+	svc.now = func() time.Time { return now }
+	return svc, repo, decider
+}
+
+func TestKYCReview_ApproveClaimsUnassignedAndRecordsDecision(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	svc, repo, decider := setupKYCReviewService(now)
+
+	record, err := svc.Approve(ctx, "user-1", "reviewer-1", &models.DecideKYCRequest{ReasonCode: models.KYCReasonDocumentsVerified})
+	if err != nil {
+		t.Fatalf("approve failed: %v", err)
+	}
+
+	if !decider.verified["user-1"] {
+		t.Error("expected KYC to be verified")
+	}
+	if record.ReviewerID != "reviewer-1" || record.ReasonCode != models.KYCReasonDocumentsVerified {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if want := now.Add(-6 * time.Hour); !record.SLADueAt.Time.Equal(want) {
+		t.Errorf("expected SLA due %v, got %v", want, record.SLADueAt.Time)
+	}
+	if len(repo.records) != 1 {
+		t.Errorf("expected 1 recorded decision, got %d", len(repo.records))
+	}
+}
+
+func TestKYCReview_OnlyTheAssignedReviewerDecides(t *testing.T) {
+	ctx := context.Background()
+	svc, _, decider := setupKYCReviewService(time.Now())
+
+	if _, err := svc.Claim(ctx, "user-1", "reviewer-1"); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	if _, err := svc.Claim(ctx, "user-1", "reviewer-2"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict claiming a held submission, got %v", err)
+	}
+	req := &models.DecideKYCRequest{ReasonCode: models.KYCReasonPANInvalid}
+	if _, err := svc.Reject(ctx, "user-1", "reviewer-2", req); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict deciding another reviewer's submission, got %v", err)
+	}
+	if len(decider.rejected) != 0 {
+		t.Error("expected no decision to be applied")
+	}
+
+	// A supervisor can hand it over
+	item, err := svc.Assign(ctx, "user-1", "reviewer-2")
+	if err != nil || item.AssignedTo == nil || *item.AssignedTo != "reviewer-2" {
+		t.Fatalf("expected reassignment to reviewer-2, got %+v %v", item, err)
+	}
+	if _, err := svc.Reject(ctx, "user-1", "reviewer-2", req); err != nil {
+		t.Errorf("expected new assignee to decide, got %v", err)
+	}
+}
+
+func TestKYCReview_ReasonCodes(t *testing.T) {
+	ctx := context.Background()
+	svc, _, decider := setupKYCReviewService(time.Now())
+
+	tests := []struct {
+		name string
+		req  models.DecideKYCRequest
+	}{
+		{"approval code on rejection", models.DecideKYCRequest{ReasonCode: models.KYCReasonDocumentsVerified}},
+		{"unknown code", models.DecideKYCRequest{ReasonCode: "looks_fine"}},
+		{"other without note", models.DecideKYCRequest{ReasonCode: models.KYCReasonOther, Note: "  "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Reject(ctx, "user-1", "reviewer-1", &tt.req); err == nil || err.Code != errors.ErrCodeBadRequest {
+				t.Errorf("expected bad request, got %v", err)
+			}
+		})
+	}
+
+	req := &models.DecideKYCRequest{ReasonCode: models.KYCReasonPANNameMismatch, Note: "Surname differs"}
+	if _, err := svc.Reject(ctx, "user-1", "reviewer-1", req); err != nil {
+		t.Fatalf("reject failed: %v", err)
+	}
+	if got, want := decider.rejected["user-1"], "Name does not match the PAN records: Surname differs"; got != want {
+		t.Errorf("expected user-facing reason %q, got %q", want, got)
+	}
+}
+
+func TestKYCReview_ListQueueAppliesSLA(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	svc, repo, _ := setupKYCReviewService(now)
+
+	items, err := svc.ListQueue(ctx, models.KYCQueueFilter{SLA: models.KYCSLAOverdue})
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+
+	if repo.lastFilter.SubmittedBefore == nil || !repo.lastFilter.SubmittedBefore.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("expected overdue filter to bound submissions at now-SLA, got %v", repo.lastFilter.SubmittedBefore)
+	}
+	if repo.lastFilter.Limit != DefaultKYCQueuePageSize {
+		t.Errorf("expected default page size, got %d", repo.lastFilter.Limit)
+	}
+	if len(items) != 1 || !items[0].SLA.Breached || items[0].SLA.RemainingSeconds != -6*3600 {
+		t.Errorf("expected breached SLA 6h overdue, got %+v", items[0].SLA)
+	}
+
+	if _, err := svc.ListQueue(ctx, models.KYCQueueFilter{SLA: "late"}); err == nil {
+		t.Error("expected invalid SLA filter to be rejected")
+	}
+}
+
+func TestKYCReview_MetricsIncludeReviewersWithOnlyOpenWork(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := setupKYCReviewService(time.Now())
+	repo.throughput = []*models.KYCReviewerMetrics{{ReviewerID: "reviewer-1", Decisions: 3, Approved: 2, Rejected: 1}}
+	repo.stats = &models.KYCQueueStats{
+		Pending:    4,
+		Unassigned: 1,
+		Overdue:    2,
+		Assigned:   map[string]int{"reviewer-1": 1, "reviewer-2": 2},
+	}
+
+	metrics, err := svc.Metrics(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("metrics failed: %v", err)
+	}
+
+	if metrics.Pending != 4 || metrics.Overdue != 2 || len(metrics.Reviewers) != 2 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+	if metrics.Reviewers[0].Assigned != 1 || metrics.Reviewers[1].ReviewerID != "reviewer-2" || metrics.Reviewers[1].Assigned != 2 {
+		t.Errorf("unexpected reviewer metrics: %+v %+v", metrics.Reviewers[0], metrics.Reviewers[1])
+	}
+	if got := metrics.To.Sub(metrics.From.Time); got != DefaultKYCMetricsPeriod {
+		t.Errorf("expected default period, got %v", got)
+	}
+
+	if _, err := svc.Metrics(ctx, time.Now(), time.Now().Add(-time.Hour)); err == nil {
+		t.Error("expected an inverted period to be rejected")
+	}
+}
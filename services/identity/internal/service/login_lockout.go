@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+var (
+	loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "identity_login_failures_total",
+		Help: "Rejected logins by reason (unknown_user, bad_password, captcha, locked)",
+	}, []string{"reason"})
+	accountLockouts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "identity_account_lockouts_total",
+		Help: "Accounts locked after repeated failed logins",
+	})
+	accountUnlocks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "identity_account_unlocks_total",
+		Help: "Locked accounts unlocked early by method (email, admin)",
+	}, []string{"method"})
+)
+
+// LoginLockoutRepositoryInterface defines the interface for login lockout persistence.
+type LoginLockoutRepositoryInterface interface {
+	Get(ctx context.Context, userID string) (*models.LoginLockout, *errors.Error)
+	GetByUnlockTokenHash(ctx context.Context, tokenHash string) (*models.LoginLockout, *errors.Error)
+	RecordFailure(ctx context.Context, userID string, window time.Duration) (*models.LoginLockout, *errors.Error)
+	Lock(ctx context.Context, userID string, until time.Time, unlockTokenHash string, tokenExpiresAt time.Time) (*models.LoginLockout, *errors.Error)
+	Clear(ctx context.Context, userID string) *errors.Error
+}
+
+// CaptchaVerifier checks a CAPTCHA response token from the login form.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// LockoutPolicy controls when failed logins require a CAPTCHA and lock the account.
+type LockoutPolicy struct {
+	CaptchaAfter   int           // Failures before a CAPTCHA is required
+	LockAfter      int           // Failures that lock the account
+	FailureWindow  time.Duration // Failures older than this no longer count
+	BaseCooldown   time.Duration // First lockout; each further lockout doubles it
+	MaxCooldown    time.Duration
+	UnlockTokenTTL time.Duration
+	UnlockURL      string // Link in the lockout email; the token is appended as ?token=
+}
+
+// DefaultLockoutPolicy returns the default lockout policy.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		CaptchaAfter:   3,
+		LockAfter:      5,
+		FailureWindow:  24 * time.Hour,
+		BaseCooldown:   5 * time.Minute,
+		MaxCooldown:    24 * time.Hour,
+		UnlockTokenTTL: 24 * time.Hour,
+		UnlockURL:      "http://localhost:3000/unlock",
+	}
+}
+
+// Cooldown returns how long the nth lockout since the last successful login lasts.
+func (p LockoutPolicy) Cooldown(lockoutCount int) time.Duration {
+	cooldown := p.BaseCooldown
+	for i := 1; i < lockoutCount && cooldown < p.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > p.MaxCooldown {
+		cooldown = p.MaxCooldown
+	}
+	return cooldown
+}
+
+// SetLoginLockout enables progressive lockout after repeated failed logins.
+// Lockout emails with the unlock link are delivered through the notifier.
+func (s *AuthService) SetLoginLockout(repo LoginLockoutRepositoryInterface, notifier ContactNotifier, policy LockoutPolicy) {
+	s.lockouts = repo
+	s.lockoutNotifier = notifier
+	s.lockoutPolicy = policy
+}
+
+// SetCaptchaVerifier enforces the CAPTCHA once a user has enough failed logins.
+// If not set, the captcha_required flag is advisory.
+func (s *AuthService) SetCaptchaVerifier(v CaptchaVerifier) {
+	s.captcha = v
+}
+
+// checkLoginAllowed rejects a login for a locked account, or one that needs a
+// CAPTCHA the request did not solve, before the password is checked.
+func (s *AuthService) checkLoginAllowed(ctx context.Context, userID, captchaToken, ipAddress string) (*models.LoginLockout, *errors.Error) {
+	if s.lockouts == nil {
+		return nil, nil
+	}
+
+	lockout, err := s.lockouts.Get(ctx, userID)
+	if err != nil {
+		// Lockout storage problems must not block every login
+		return nil, nil
+	}
+
+	if lockout.IsLocked(time.Now()) {
+		loginFailures.WithLabelValues("locked").Inc()
+		return nil, accountLockedError(lockout)
+	}
+
+	if s.captcha != nil && s.captchaRequired(lockout) {
+		ok := false
+		if captchaToken != "" {
+			ok, _ = s.captcha.Verify(ctx, captchaToken, ipAddress)
+		}
+		if !ok {
+			loginFailures.WithLabelValues("captcha").Inc()
+			return nil, errors.Unauthorized("captcha verification required").
+				WithDetails(map[string]interface{}{"captcha_required": true})
+		}
+	}
+
+	return lockout, nil
+}
+
+// recordLoginFailure counts a wrong password, locking the account once the
+// policy's limit is reached, and returns the error for the login response.
+func (s *AuthService) recordLoginFailure(ctx context.Context, user *models.User) *errors.Error {
+	loginFailures.WithLabelValues("bad_password").Inc()
+	invalid := errors.Unauthorized("invalid credentials")
+	if s.lockouts == nil {
+		return invalid
+	}
+
+	lockout, err := s.lockouts.RecordFailure(ctx, user.ID, s.lockoutPolicy.FailureWindow)
+	if err != nil {
+		return invalid
+	}
+
+	if lockout.FailedAttempts < s.lockoutPolicy.LockAfter {
+		if s.captchaRequired(lockout) {
+			invalid.WithDetails(map[string]interface{}{"captcha_required": true})
+		}
+		return invalid
+	}
+
+	locked, lockErr := s.lockAccount(ctx, user, lockout)
+	if lockErr != nil {
+		return invalid
+	}
+	return accountLockedError(locked)
+}
+
+// lockAccount locks the account for the next cooldown and emails an unlock link.
+func (s *AuthService) lockAccount(ctx context.Context, user *models.User, lockout *models.LoginLockout) (*models.LoginLockout, *errors.Error) {
+	token, tokenErr := generateUnlockToken()
+	if tokenErr != nil {
+		return nil, errors.Internal("failed to generate unlock token")
+	}
+
+	now := time.Now()
+	until := now.Add(s.lockoutPolicy.Cooldown(lockout.LockoutCount + 1))
+	locked, err := s.lockouts.Lock(ctx, user.ID, until, s.hashToken(token), now.Add(s.lockoutPolicy.UnlockTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+	accountLockouts.Inc()
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.locked_out", user.ID, map[string]interface{}{
+			"locked_until":  until,
+			"lockout_count": locked.LockoutCount,
+		})
+	}
+
+	if s.lockoutNotifier != nil && user.Email != "" {
+		s.lockoutNotifier.SendNotificationAsync(s.lockoutNotification(user, locked, token), "identity")
+	}
+
+	return locked, nil
+}
+
+// clearLoginFailures resets the failure count after a successful login.
+func (s *AuthService) clearLoginFailures(ctx context.Context, lockout *models.LoginLockout) {
+	if s.lockouts == nil || lockout == nil || (lockout.FailedAttempts == 0 && lockout.LockoutCount == 0) {
+		return
+	}
+	_ = s.lockouts.Clear(ctx, lockout.UserID)
+}
+
+// UnlockWithToken unlocks an account with the token from the lockout email.
+func (s *AuthService) UnlockWithToken(ctx context.Context, token string) *errors.Error {
+	if s.lockouts == nil {
+		return errors.Unavailable("account lockout is not enabled")
+	}
+
+	lockout, err := s.lockouts.GetByUnlockTokenHash(ctx, s.hashToken(token))
+	if err != nil {
+		return err
+	}
+
+	return s.unlockAccount(ctx, lockout.UserID, "email", "")
+}
+
+// UnlockAccount unlocks a locked account (admin operation).
+func (s *AuthService) UnlockAccount(ctx context.Context, userID, adminUserID string) *errors.Error {
+	if s.lockouts == nil {
+		return errors.Unavailable("account lockout is not enabled")
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	lockout, err := s.lockouts.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !lockout.IsLocked(time.Now()) {
+		return errors.BadRequest("account is not locked")
+	}
+
+	return s.unlockAccount(ctx, userID, "admin", adminUserID)
+}
+
+func (s *AuthService) unlockAccount(ctx context.Context, userID, method, adminUserID string) *errors.Error {
+	if err := s.lockouts.Clear(ctx, userID); err != nil {
+		return err
+	}
+	accountUnlocks.WithLabelValues(method).Inc()
+
+	if s.eventPublisher != nil {
+		data := map[string]interface{}{"method": method}
+		if adminUserID != "" {
+			data["unlocked_by"] = adminUserID
+		}
+		s.eventPublisher.PublishUserEvent("user.unlocked", userID, data)
+	}
+
+	return nil
+}
+
+func (s *AuthService) captchaRequired(lockout *models.LoginLockout) bool {
+	return s.lockoutPolicy.CaptchaAfter > 0 && lockout.FailedAttempts >= s.lockoutPolicy.CaptchaAfter
+}
+
+// lockoutNotification builds the email telling the user their account was locked.
+func (s *AuthService) lockoutNotification(user *models.User, lockout *models.LoginLockout, token string) *clients.SendNotificationRequest {
+	correlationID := fmt.Sprintf("account-locked-%s-%d", user.ID, lockout.LockoutCount)
+	lockedMinutes := 0
+	if lockout.LockedUntil != nil {
+		lockedMinutes = int(time.Until(lockout.LockedUntil.Time).Round(time.Minute).Minutes())
+	}
+	return &clients.SendNotificationRequest{
+		UserID:     &user.ID,
+		Recipient:  user.Email,
+		Channel:    clients.NotificationChannelEmail,
+		Type:       clients.NotificationTypeSecurityAlert,
+		Priority:   clients.NotificationPriorityHigh,
+		TemplateID: "account_locked_email",
+		Variables: map[string]interface{}{
+			"full_name":      user.FullName,
+			"locked_minutes": lockedMinutes,
+			"unlock_url":     s.lockoutPolicy.UnlockURL + "?token=" + token,
+		},
+		CorrelationID: &correlationID,
+		SourceService: "identity",
+	}
+}
+
+// accountLockedError tells the client when the account can be tried again.
+func accountLockedError(lockout *models.LoginLockout) *errors.Error {
+	retryAfter := int(time.Until(lockout.LockedUntil.Time).Seconds()) + 1
+	return errors.TooManyRequests("account temporarily locked after repeated failed logins").
+		WithDetails(map[string]interface{}{
+			"locked_until":        lockout.LockedUntil.Time,
+			"retry_after_seconds": retryAfter,
+			"captcha_required":    true,
+		})
+}
+
+func generateUnlockToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockLoginLockoutRepository struct {
+	lockouts map[string]*models.LoginLockout
+}
+
+func (m *mockLoginLockoutRepository) Get(ctx context.Context, userID string) (*models.LoginLockout, *errors.Error) {
+	lockout, ok := m.lockouts[userID]
+	if !ok {
+		return &models.LoginLockout{UserID: userID}, nil
+	}
+	copied := *lockout
+	return &copied, nil
+}
+
+func (m *mockLoginLockoutRepository) GetByUnlockTokenHash(ctx context.Context, tokenHash string) (*models.LoginLockout, *errors.Error) {
+	for _, lockout := range m.lockouts {
+		if lockout.UnlockTokenHash == tokenHash && time.Now().Before(lockout.UnlockTokenExpiresAt.Time) {
+			copied := *lockout
+			return &copied, nil
+		}
+	}
+	return nil, errors.NotFound("unlock link is invalid or has expired")
+}
+
+func (m *mockLoginLockoutRepository) RecordFailure(ctx context.Context, userID string, window time.Duration) (*models.LoginLockout, *errors.Error) {
+	lockout, ok := m.lockouts[userID]
+	if !ok {
+		lockout = &models.LoginLockout{UserID: userID}
+		m.lockouts[userID] = lockout
+	}
+	now := sharedModels.NewTimestamp(time.Now())
+	if lockout.LastFailedAt != nil && time.Since(lockout.LastFailedAt.Time) > window {
+		lockout.FailedAttempts = 0
+	}
+	lockout.FailedAttempts++
+	lockout.LastFailedAt = &now
+	copied := *lockout
+	return &copied, nil
+}
+
+func (m *mockLoginLockoutRepository) Lock(ctx context.Context, userID string, until time.Time, unlockTokenHash string, tokenExpiresAt time.Time) (*models.LoginLockout, *errors.Error) {
+	lockout := m.lockouts[userID]
+	lockedUntil := sharedModels.NewTimestamp(until)
+	expiresAt := sharedModels.NewTimestamp(tokenExpiresAt)
+	lockout.LockedUntil = &lockedUntil
+	lockout.LockoutCount++
+	lockout.UnlockTokenHash = unlockTokenHash
+	lockout.UnlockTokenExpiresAt = &expiresAt
+	copied := *lockout
+	return &copied, nil
+}
+
+func (m *mockLoginLockoutRepository) Clear(ctx context.Context, userID string) *errors.Error {
+	delete(m.lockouts, userID)
+	return nil
+}
+
+type mockCaptchaVerifier struct {
+	validToken string
+}
+
+func (m *mockCaptchaVerifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	return token == m.validToken, nil
+}
+
+var _ LoginLockoutRepositoryInterface = (*mockLoginLockoutRepository)(nil)
+var _ CaptchaVerifier = (*mockCaptchaVerifier)(nil)
+
+func setupLockoutTest(t *testing.T) (*AuthService, *mockLoginLockoutRepository, *mockContactNotifier, *models.User) {
+	t.Helper()
+	service, userRepo, _, _, _ := setupTestAuthService()
+	lockoutRepo := &mockLoginLockoutRepository{lockouts: make(map[string]*models.LoginLockout)}
+	notifier := &mockContactNotifier{}
+	service.SetLoginLockout(lockoutRepo, notifier, DefaultLockoutPolicy())
+
+	user := &models.User{
+		ID:           "user-1",
+		Email:        "test@example.com",
+		FullName:     "Test User",
+		PasswordHash: hashPassword("Password123!"),
+		Status:       models.UserStatusActive,
+		AccountType:  models.AccountTypeUser,
+	}
+	addUserToMockRepo(userRepo, user)
+
+	return service, lockoutRepo, notifier, user
+}
+
+func login(service *AuthService, password, captchaToken string) (*models.LoginResponse, *errors.Error) {
+	return service.Login(context.Background(), &models.LoginRequest{
+		Identifier:   "test@example.com",
+		Password:     password,
+		CaptchaToken: captchaToken,
+	}, "192.168.1.1", "Mozilla/5.0")
+}
+
+func TestLockoutPolicy_Cooldown(t *testing.T) {
+	policy := LockoutPolicy{BaseCooldown: 5 * time.Minute, MaxCooldown: time.Hour}
+	tests := []struct {
+		lockouts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Minute},
+		{2, 10 * time.Minute},
+		{3, 20 * time.Minute},
+		{4, 40 * time.Minute},
+		{5, time.Hour},
+		{50, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := policy.Cooldown(tt.lockouts); got != tt.want {
+			t.Errorf("Cooldown(%d) = %v, want %v", tt.lockouts, got, tt.want)
+		}
+	}
+}
+
+func TestLogin_CaptchaFlagAndLockout(t *testing.T) {
+	service, lockoutRepo, notifier, user := setupLockoutTest(t)
+
+	for i := 1; i <= 4; i++ {
+		_, err := login(service, "WrongPassword", "")
+		if err == nil || err.Code != errors.ErrCodeUnauthorized {
+			t.Fatalf("attempt %d: expected unauthorized, got %v", i, err)
+		}
+		_, flagged := err.Details["captcha_required"]
+		if want := i >= 3; flagged != want {
+			t.Errorf("attempt %d: captcha_required = %v, want %v", i, flagged, want)
+		}
+	}
+
+	// The fifth failure locks the account
+	_, err := login(service, "WrongPassword", "")
+	if err == nil || err.Code != errors.ErrCodeRateLimit {
+		t.Fatalf("expected account locked, got %v", err)
+	}
+	if _, ok := err.Details["retry_after_seconds"]; !ok {
+		t.Error("expected retry_after_seconds in lockout details")
+	}
+	if lockoutRepo.lockouts[user.ID].LockoutCount != 1 {
+		t.Errorf("expected one lockout, got %d", lockoutRepo.lockouts[user.ID].LockoutCount)
+	}
+	if len(notifier.sent) != 1 || notifier.sent[0].TemplateID != "account_locked_email" {
+		t.Fatalf("expected lockout email, got %+v", notifier.sent)
+	}
+
+	// The right password is rejected while locked
+	if _, err := login(service, "Password123!", ""); err == nil || err.Code != errors.ErrCodeRateLimit {
+		t.Errorf("expected locked account to reject login, got %v", err)
+	}
+}
+
+func TestLogin_RepeatedLockoutDoublesCooldown(t *testing.T) {
+	service, lockoutRepo, _, user := setupLockoutTest(t)
+
+	for i := 0; i < 5; i++ {
+		_, _ = login(service, "WrongPassword", "")
+	}
+	first := time.Until(lockoutRepo.lockouts[user.ID].LockedUntil.Time)
+
+	// Cooldown over: the next failure locks again for twice as long
+	expired := sharedModels.NewTimestamp(time.Now().Add(-time.Second))
+	lockoutRepo.lockouts[user.ID].LockedUntil = &expired
+	if _, err := login(service, "WrongPassword", ""); err == nil || err.Code != errors.ErrCodeRateLimit {
+		t.Fatalf("expected account locked again, got %v", err)
+	}
+	second := time.Until(lockoutRepo.lockouts[user.ID].LockedUntil.Time)
+
+	if second < first*2-time.Second {
+		t.Errorf("expected cooldown to double, got %v then %v", first, second)
+	}
+}
+
+func TestLogin_SuccessClearsFailures(t *testing.T) {
+	service, lockoutRepo, _, user := setupLockoutTest(t)
+
+	_, _ = login(service, "WrongPassword", "")
+	_, _ = login(service, "WrongPassword", "")
+	if _, err := login(service, "Password123!", ""); err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+	if _, ok := lockoutRepo.lockouts[user.ID]; ok {
+		t.Error("expected failures to be cleared after a successful login")
+	}
+}
+
+func TestLogin_CaptchaEnforced(t *testing.T) {
+	service, _, _, _ := setupLockoutTest(t)
+	service.SetCaptchaVerifier(&mockCaptchaVerifier{validToken: "solved"})
+
+	for i := 0; i < 3; i++ {
+		_, _ = login(service, "WrongPassword", "")
+	}
+
+	// Without a solved CAPTCHA even the right password is rejected
+	_, err := login(service, "Password123!", "")
+	if err == nil || err.Details["captcha_required"] != true {
+		t.Fatalf("expected captcha required, got %v", err)
+	}
+	if _, err := login(service, "Password123!", "wrong"); err == nil {
+		t.Fatal("expected invalid captcha to be rejected")
+	}
+
+	if _, err := login(service, "Password123!", "solved"); err != nil {
+		t.Fatalf("expected login with solved captcha to succeed, got %v", err)
+	}
+}
+
+func TestUnlockWithToken(t *testing.T) {
+	service, lockoutRepo, notifier, user := setupLockoutTest(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, _ = login(service, "WrongPassword", "")
+	}
+	unlockURL := notifier.sent[0].Variables["unlock_url"].(string)
+	token := unlockURL[len(DefaultLockoutPolicy().UnlockURL+"?token="):]
+
+	if err := service.UnlockWithToken(ctx, "not-the-token"); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected invalid token to be rejected, got %v", err)
+	}
+	if err := service.UnlockWithToken(ctx, token); err != nil {
+		t.Fatalf("expected unlock to succeed, got %v", err)
+	}
+	if _, ok := lockoutRepo.lockouts[user.ID]; ok {
+		t.Error("expected lockout to be cleared")
+	}
+	if _, err := login(service, "Password123!", ""); err != nil {
+		t.Errorf("expected login after unlock to succeed, got %v", err)
+	}
+
+	// The token works once
+	if err := service.UnlockWithToken(ctx, token); err == nil {
+		t.Error("expected used token to be rejected")
+	}
+}
+
+func TestUnlockAccount_Admin(t *testing.T) {
+	service, _, _, user := setupLockoutTest(t)
+	ctx := context.Background()
+
+	if err := service.UnlockAccount(ctx, user.ID, "admin-1"); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected unlocked account to be rejected, got %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		_, _ = login(service, "WrongPassword", "")
+	}
+	if err := service.UnlockAccount(ctx, user.ID, "admin-1"); err != nil {
+		t.Fatalf("expected unlock to succeed, got %v", err)
+	}
+	if _, err := login(service, "Password123!", ""); err != nil {
+		t.Errorf("expected login after unlock to succeed, got %v", err)
+	}
+}
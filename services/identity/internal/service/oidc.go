@@ -0,0 +1,625 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// OIDC provider defaults.
+const (
+	// DefaultOIDCTokenTTL is the lifetime of issued ID and access tokens.
+	DefaultOIDCTokenTTL = time.Hour
+	// OIDCCodeTTL is how long an authorization code may wait for exchange.
+	OIDCCodeTTL = 5 * time.Minute
+
+	grantTypeAuthorizationCode = "authorization_code"
+	responseTypeCode           = "code"
+	pkceMethodS256             = "S256"
+	accessTokenType            = "at+jwt" // RFC 9068, keeps ID tokens out of userinfo
+	maxNonceLength             = 255
+)
+
+// OAuthErrorDetail is the error detail carrying the OAuth error code
+// (RFC 6749 section 4.1.2.1 and 5.2) of errors the OIDC endpoints report to
+// clients.
+const OAuthErrorDetail = "oauth_error"
+
+// OIDCRepositoryInterface defines the interface for OAuth client and
+// authorization code storage.
+type OIDCRepositoryInterface interface {
+	CreateClient(ctx context.Context, client *models.OAuthClient) *errors.Error
+	GetClient(ctx context.Context, id string) (*models.OAuthClient, *errors.Error)
+	ListClients(ctx context.Context) ([]*models.OAuthClient, *errors.Error)
+	RevokeClient(ctx context.Context, id string) *errors.Error
+	CreateCode(ctx context.Context, code *models.AuthorizationCode) *errors.Error
+	ConsumeCode(ctx context.Context, codeHash string, now time.Time) (*models.AuthorizationCode, *errors.Error)
+}
+
+// OIDCConfig configures the OIDC provider.
+type OIDCConfig struct {
+	// Issuer is the public URL the provider's endpoints live under, e.g.
+	// https://api.nivomoney.com/api/v1/oauth.
+	Issuer string
+	// ConsentURL is the app page that signs the user in and asks them to
+	// approve an authorization request. The request parameters are passed on
+	// in its query string.
+	ConsentURL string
+	// TokenTTL is the lifetime of issued tokens; <= 0 uses DefaultOIDCTokenTTL.
+	TokenTTL time.Duration
+}
+
+// OIDCService lets registered clients sign users in with their Nivo account
+// through the OpenID Connect authorization code flow with PKCE. Clients get
+// an ID token and an access token good only for the userinfo endpoint, never
+// a session token for the rest of the API.
+type OIDCService struct {
+	repo   OIDCRepositoryInterface
+	users  UserRepositoryInterface
+	key    *OIDCSigningKey
+	config OIDCConfig
+	now    func() time.Time
+}
+
+// NewOIDCService creates an OIDC provider that signs tokens with key.
+func NewOIDCService(repo OIDCRepositoryInterface, users UserRepositoryInterface, key *OIDCSigningKey, config OIDCConfig) *OIDCService {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.TokenTTL <= 0 {
+		config.TokenTTL = DefaultOIDCTokenTTL
+	}
+	return &OIDCService{
+		repo:   repo,
+		users:  users,
+		key:    key,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// oauthError returns an error reported to the client with an OAuth error code.
+func oauthError(code, description string) *errors.Error {
+	if code == "invalid_client" {
+		return errors.Unauthorized(description).AddDetail(OAuthErrorDetail, code)
+	}
+	return errors.BadRequest(description).AddDetail(OAuthErrorDetail, code)
+}
+
+// OAuthErrorCode returns the OAuth error code of an error, or "" when the
+// error is not one to report to the client in OAuth form.
+func OAuthErrorCode(err *errors.Error) string {
+	code, _ := err.Details[OAuthErrorDetail].(string)
+	return code
+}
+
+// ========================================================================
+// Provider Metadata
+// ========================================================================
+
+// Discovery returns the provider metadata clients configure themselves from.
+func (s *OIDCService) Discovery() *models.OIDCDiscovery {
+	return &models.OIDCDiscovery{
+		Issuer:                            s.config.Issuer,
+		AuthorizationEndpoint:             s.config.Issuer + "/authorize",
+		TokenEndpoint:                     s.config.Issuer + "/token",
+		UserinfoEndpoint:                  s.config.Issuer + "/userinfo",
+		JWKSURI:                           s.config.Issuer + "/jwks",
+		ResponseTypesSupported:            []string{responseTypeCode},
+		GrantTypesSupported:               []string{grantTypeAuthorizationCode},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.SigningMethodRS256.Alg()},
+		ScopesSupported:                   models.SupportedScopes,
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "email", "email_verified", "phone_number", "phone_number_verified"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{pkceMethodS256},
+	}
+}
+
+// JWKS returns the public keys tokens are signed with.
+func (s *OIDCService) JWKS() *models.JSONWebKeySet {
+	return s.key.JWKS()
+}
+
+// ConsentURL returns the consent page an authorization request is sent to.
+func (s *OIDCService) ConsentURL(req *models.AuthorizeRequest) string {
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"response_type":         req.ResponseType,
+		"client_id":             req.ClientID,
+		"redirect_uri":          req.RedirectURI,
+		"scope":                 req.Scope,
+		"state":                 req.State,
+		"nonce":                 req.Nonce,
+		"code_challenge":        req.CodeChallenge,
+		"code_challenge_method": req.CodeChallengeMethod,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	return appendQuery(s.config.ConsentURL, query)
+}
+
+// ========================================================================
+// Client Registration
+// ========================================================================
+
+// CreateClient registers an OAuth client. A confidential client's secret is
+// returned once and only its hash is kept.
+func (s *OIDCService) CreateClient(ctx context.Context, createdBy string, req *models.CreateOAuthClientRequest) (*models.OAuthClientWithSecret, *errors.Error) {
+	if len(req.RedirectURIs) == 0 {
+		return nil, errors.Validation("at least one redirect URI is required")
+	}
+	for _, uri := range req.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return nil, err
+		}
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{models.ScopeOpenID}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.SupportedScopes, scope) {
+			return nil, errors.Validation(fmt.Sprintf("unsupported scope %q: must be one of %s", scope, strings.Join(models.SupportedScopes, ", ")))
+		}
+	}
+	if !slices.Contains(scopes, models.ScopeOpenID) {
+		return nil, errors.Validation("scopes must include openid")
+	}
+
+	client := &models.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       scopes,
+		Public:       req.Public,
+		CreatedBy:    createdBy,
+	}
+	var secret string
+	if !req.Public {
+		var err error
+		if secret, err = generateOAuthToken(); err != nil {
+			return nil, errors.Internal("failed to generate client secret")
+		}
+		client.SecretHash = hashOAuthToken(secret)
+	}
+
+	if err := s.repo.CreateClient(ctx, client); err != nil {
+		return nil, err
+	}
+	return &models.OAuthClientWithSecret{OAuthClient: client, ClientSecret: secret}, nil
+}
+
+// ListClients returns all registered clients, newest first.
+func (s *OIDCService) ListClients(ctx context.Context) ([]*models.OAuthClient, *errors.Error) {
+	return s.repo.ListClients(ctx)
+}
+
+// RevokeClient stops a client from signing users in. Tokens it already holds
+// stay valid until they expire.
+func (s *OIDCService) RevokeClient(ctx context.Context, clientID string) *errors.Error {
+	if uuid.Validate(clientID) != nil {
+		return errors.NotFoundWithID("OAuth client", clientID)
+	}
+	return s.repo.RevokeClient(ctx, clientID)
+}
+
+// validateRedirectURI accepts absolute URIs without a fragment. Plain http is
+// allowed only for loopback addresses, for clients under development and
+// native apps; other schemes are taken as a native app's own scheme.
+func validateRedirectURI(uri string) *errors.Error {
+	parsed, err := url.Parse(uri)
+	if err != nil || !parsed.IsAbs() {
+		return errors.Validation(fmt.Sprintf("redirect URI %q must be an absolute URI", uri))
+	}
+	if parsed.Fragment != "" {
+		return errors.Validation(fmt.Sprintf("redirect URI %q must not have a fragment", uri))
+	}
+	switch parsed.Scheme {
+	case "https":
+		if parsed.Host == "" {
+			return errors.Validation(fmt.Sprintf("redirect URI %q has no host", uri))
+		}
+	case "http":
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return errors.Validation(fmt.Sprintf("redirect URI %q must use https", uri))
+		}
+	}
+	return nil
+}
+
+// ========================================================================
+// Authorization
+// ========================================================================
+
+// Prompt checks an authorization request and returns what the consent
+// screen shows the user. Errors with an OAuth error code are sent back to
+// the client's redirect URI; other errors mean the client or redirect URI
+// cannot be trusted and are shown to the user instead.
+func (s *OIDCService) Prompt(ctx context.Context, req *models.AuthorizeRequest) (*models.AuthorizationPrompt, *errors.Error) {
+	client, scopes, err := s.checkAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthorizationPrompt{
+		ClientID:    client.ID,
+		ClientName:  client.Name,
+		RedirectURI: req.RedirectURI,
+		Scopes:      scopes,
+	}, nil
+}
+
+// Consent records the user's answer to an authorization request. An
+// approval issues a single-use code; the response sends the browser back to
+// the client with the code, or with an error the client can handle.
+func (s *OIDCService) Consent(ctx context.Context, userID string, req *models.ConsentRequest) (*models.ConsentResponse, *errors.Error) {
+	client, scopes, err := s.checkAuthorization(ctx, &req.AuthorizeRequest)
+	if err != nil {
+		if OAuthErrorCode(err) == "" {
+			return nil, err
+		}
+		return &models.ConsentResponse{RedirectTo: s.ErrorRedirect(&req.AuthorizeRequest, err)}, nil
+	}
+	if !req.Approved {
+		denied := oauthError("access_denied", "the user denied the request")
+		return &models.ConsentResponse{RedirectTo: s.ErrorRedirect(&req.AuthorizeRequest, denied)}, nil
+	}
+
+	code, genErr := generateOAuthToken()
+	if genErr != nil {
+		return nil, errors.Internal("failed to generate authorization code")
+	}
+	if err := s.repo.CreateCode(ctx, &models.AuthorizationCode{
+		CodeHash:      hashOAuthToken(code),
+		ClientID:      client.ID,
+		UserID:        userID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     s.now().Add(OIDCCodeTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"code": {code}, "iss": {s.config.Issuer}}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	return &models.ConsentResponse{RedirectTo: appendQuery(req.RedirectURI, query)}, nil
+}
+
+// ErrorRedirect returns the client redirect URI reporting an OAuth error for
+// an authorization request.
+func (s *OIDCService) ErrorRedirect(req *models.AuthorizeRequest, err *errors.Error) string {
+	query := url.Values{
+		"error":             {OAuthErrorCode(err)},
+		"error_description": {err.Message},
+		"iss":               {s.config.Issuer},
+	}
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	return appendQuery(req.RedirectURI, query)
+}
+
+// checkAuthorization validates an authorization request and returns its
+// client and the scopes requested. The client and redirect URI are checked
+// first: until both are trusted, errors carry no OAuth error code so they
+// are never redirected.
+func (s *OIDCService) checkAuthorization(ctx context.Context, req *models.AuthorizeRequest) (*models.OAuthClient, []string, *errors.Error) {
+	if req.ClientID == "" {
+		return nil, nil, errors.BadRequest("client_id is required")
+	}
+	client, err := s.activeClient(ctx, req.ClientID)
+	if err != nil {
+		return nil, nil, errors.BadRequest("unknown client")
+	}
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		return nil, nil, errors.BadRequest("redirect_uri is not registered for the client")
+	}
+
+	if req.ResponseType != responseTypeCode {
+		return nil, nil, oauthError("unsupported_response_type", "response_type must be code")
+	}
+	if req.CodeChallenge == "" {
+		return nil, nil, oauthError("invalid_request", "code_challenge is required")
+	}
+	if req.CodeChallengeMethod != pkceMethodS256 {
+		return nil, nil, oauthError("invalid_request", "code_challenge_method must be S256")
+	}
+	if !validPKCEChallenge(req.CodeChallenge) {
+		return nil, nil, oauthError("invalid_request", "code_challenge is not a base64url SHA-256 hash")
+	}
+	if len(req.Nonce) > maxNonceLength {
+		return nil, nil, oauthError("invalid_request", fmt.Sprintf("nonce must be at most %d characters", maxNonceLength))
+	}
+
+	scopes := make([]string, 0, 4)
+	for _, scope := range strings.Fields(req.Scope) {
+		if !slices.Contains(client.Scopes, scope) {
+			return nil, nil, oauthError("invalid_scope", fmt.Sprintf("scope %q is not allowed for the client", scope))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if !slices.Contains(scopes, models.ScopeOpenID) {
+		return nil, nil, oauthError("invalid_scope", "scope must include openid")
+	}
+	return client, scopes, nil
+}
+
+// activeClient returns a client that has not been revoked.
+func (s *OIDCService) activeClient(ctx context.Context, clientID string) (*models.OAuthClient, *errors.Error) {
+	if uuid.Validate(clientID) != nil {
+		return nil, errors.NotFoundWithID("OAuth client", clientID)
+	}
+	client, err := s.repo.GetClient(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client.RevokedAt != nil {
+		return nil, errors.NotFoundWithID("OAuth client", clientID)
+	}
+	return client, nil
+}
+
+// ========================================================================
+// Tokens
+// ========================================================================
+
+// oidcAccessClaims are the claims of an access token (RFC 9068).
+type oidcAccessClaims struct {
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+// oidcIDClaims are the claims of an ID token.
+type oidcIDClaims struct {
+	Nonce               string `json:"nonce,omitempty"`
+	AccessTokenHash     string `json:"at_hash"`
+	Name                string `json:"name,omitempty"`
+	Email               string `json:"email,omitempty"`
+	EmailVerified       *bool  `json:"email_verified,omitempty"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified *bool  `json:"phone_number_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Token exchanges an authorization code for an ID token and an access token.
+// Confidential clients authenticate with their secret; every client proves
+// with the PKCE verifier that it started the authorization. A code is used
+// up by its first exchange, even a failed one.
+func (s *OIDCService) Token(ctx context.Context, req *models.TokenRequest) (*models.TokenResponse, *errors.Error) {
+	if req.GrantType != grantTypeAuthorizationCode {
+		return nil, oauthError("unsupported_grant_type", "grant_type must be authorization_code")
+	}
+	if req.Code == "" || req.RedirectURI == "" || req.ClientID == "" || req.CodeVerifier == "" {
+		return nil, oauthError("invalid_request", "code, redirect_uri, client_id and code_verifier are required")
+	}
+
+	client, err := s.activeClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, oauthError("invalid_client", "unknown client")
+		}
+		return nil, err
+	}
+	if !client.Public && !crypto.SecureCompare(client.SecretHash, hashOAuthToken(req.ClientSecret)) {
+		return nil, oauthError("invalid_client", "client authentication failed")
+	}
+
+	now := s.now()
+	code, err := s.repo.ConsumeCode(ctx, hashOAuthToken(req.Code), now)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, oauthError("invalid_grant", "authorization code is invalid, expired or already used")
+		}
+		return nil, err
+	}
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, oauthError("invalid_grant", "authorization code was issued to another client or redirect_uri")
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, oauthError("invalid_grant", "code_verifier does not match the code_challenge")
+	}
+
+	user, err := s.users.GetByID(ctx, code.UserID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, oauthError("invalid_grant", "user no longer exists")
+		}
+		return nil, err
+	}
+	if !oidcUserActive(user) {
+		return nil, oauthError("invalid_grant", "account is not active")
+	}
+
+	expiresAt := now.Add(s.config.TokenTTL)
+	accessToken, signErr := s.sign(jwt.NewWithClaims(jwt.SigningMethodRS256, &oidcAccessClaims{
+		ClientID: client.ID,
+		Scope:    code.Scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    s.config.Issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{s.config.Issuer},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}), accessTokenType)
+	if signErr != nil {
+		return nil, errors.Internal("failed to sign access token")
+	}
+
+	info := oidcUserInfo(user, strings.Fields(code.Scope))
+	idToken, signErr := s.sign(jwt.NewWithClaims(jwt.SigningMethodRS256, &oidcIDClaims{
+		Nonce:               code.Nonce,
+		AccessTokenHash:     accessTokenHash(accessToken),
+		Name:                info.Name,
+		Email:               info.Email,
+		EmailVerified:       info.EmailVerified,
+		PhoneNumber:         info.PhoneNumber,
+		PhoneNumberVerified: info.PhoneNumberVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.config.Issuer,
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{client.ID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}), "JWT")
+	if signErr != nil {
+		return nil, errors.Internal("failed to sign ID token")
+	}
+
+	return &models.TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.TokenTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       code.Scope,
+	}, nil
+}
+
+// UserInfo returns the claims about the user an access token was issued
+// for, limited to the scopes it was granted.
+func (s *OIDCService) UserInfo(ctx context.Context, accessToken string) (*models.UserInfo, *errors.Error) {
+	claims := &oidcAccessClaims{}
+	token, parseErr := jwt.ParseWithClaims(accessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != accessTokenType {
+			return nil, fmt.Errorf("not an access token")
+		}
+		return &s.key.private.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(s.config.Issuer),
+		jwt.WithAudience(s.config.Issuer),
+		jwt.WithTimeFunc(s.now),
+	)
+	if parseErr != nil || !token.Valid {
+		return nil, errors.Unauthorized("invalid access token")
+	}
+
+	user, err := s.users.GetByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.Unauthorized("invalid access token")
+		}
+		return nil, err
+	}
+	if !oidcUserActive(user) {
+		return nil, errors.Unauthorized("account is not active")
+	}
+	return oidcUserInfo(user, strings.Fields(claims.Scope)), nil
+}
+
+// sign signs a token with the provider key.
+func (s *OIDCService) sign(token *jwt.Token, typ string) (string, error) {
+	token.Header["kid"] = s.key.KeyID()
+	token.Header["typ"] = typ
+	return token.SignedString(s.key.private)
+}
+
+// oidcUserActive reports whether a user may still sign in to clients.
+func oidcUserActive(user *models.User) bool {
+	return user.Status != models.UserStatusSuspended && user.Status != models.UserStatusClosed
+}
+
+// oidcUserInfo returns the claims about a user released for scopes.
+func oidcUserInfo(user *models.User, scopes []string) *models.UserInfo {
+	info := &models.UserInfo{Subject: user.ID}
+	if slices.Contains(scopes, models.ScopeProfile) {
+		info.Name = user.FullName
+	}
+	if slices.Contains(scopes, models.ScopeEmail) && user.Email != "" {
+		verified := user.EmailVerifiedAt != nil
+		info.Email = user.Email
+		info.EmailVerified = &verified
+	}
+	if slices.Contains(scopes, models.ScopePhone) && user.Phone != "" {
+		verified := user.PhoneVerifiedAt != nil
+		info.PhoneNumber = user.Phone
+		info.PhoneNumberVerified = &verified
+	}
+	return info
+}
+
+// ========================================================================
+// Helpers
+// ========================================================================
+
+// validPKCEChallenge reports whether a challenge is an unpadded base64url
+// SHA-256 hash.
+func validPKCEChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// verifyPKCE checks a code verifier against an S256 challenge (RFC 7636).
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.ContainsRune("-._~", c)) {
+			return false
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return crypto.SecureCompare(base64.RawURLEncoding.EncodeToString(sum[:]), challenge)
+}
+
+// accessTokenHash returns the at_hash claim for an access token: the left
+// half of its SHA-256 hash, base64url encoded.
+func accessTokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2])
+}
+
+// generateOAuthToken returns a random client secret or authorization code.
+func generateOAuthToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashOAuthToken returns the SHA-256 hash stored for a secret or code.
+func hashOAuthToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// appendQuery adds parameters to a URI's query string.
+func appendQuery(uri string, params url.Values) string {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := parsed.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
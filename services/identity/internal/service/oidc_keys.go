@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+)
+
+// oidcKeyBits is the size of generated signing keys.
+const oidcKeyBits = 2048
+
+// OIDCSigningKey is the RSA key that signs ID and access tokens. Clients
+// verify tokens with its public half, published as a JWK set.
+type OIDCSigningKey struct {
+	private *rsa.PrivateKey
+	keyID   string
+}
+
+// ParseOIDCSigningKey reads an RSA private key in PEM form, PKCS #1 or
+// PKCS #8.
+func ParseOIDCSigningKey(pemData []byte) (*OIDCSigningKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+		key = parsed
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key: %w", err)
+		}
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key must be an RSA key")
+		}
+		key = rsaKey
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %q", block.Type)
+	}
+
+	if key.N.BitLen() < oidcKeyBits {
+		return nil, fmt.Errorf("signing key must be at least %d bits", oidcKeyBits)
+	}
+	return newOIDCSigningKey(key), nil
+}
+
+// GenerateOIDCSigningKey creates a new signing key. Tokens it signs stop
+// verifying when the process restarts, so it is meant for development.
+func GenerateOIDCSigningKey() (*OIDCSigningKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, oidcKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return newOIDCSigningKey(key), nil
+}
+
+func newOIDCSigningKey(key *rsa.PrivateKey) *OIDCSigningKey {
+	jwk := publicJWK(&key.PublicKey, "")
+	return &OIDCSigningKey{private: key, keyID: jwkThumbprint(jwk)}
+}
+
+// KeyID returns the key's ID, its RFC 7638 thumbprint.
+func (k *OIDCSigningKey) KeyID() string {
+	return k.keyID
+}
+
+// JWKS returns the public key as a JWK set.
+func (k *OIDCSigningKey) JWKS() *models.JSONWebKeySet {
+	return &models.JSONWebKeySet{Keys: []models.JSONWebKey{publicJWK(&k.private.PublicKey, k.keyID)}}
+}
+
+// publicJWK returns an RSA public key as a signing JWK.
+func publicJWK(key *rsa.PublicKey, keyID string) models.JSONWebKey {
+	return models.JSONWebKey{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS256",
+		KeyID:     keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// jwkThumbprint computes the RFC 7638 thumbprint of an RSA JWK: the hash of
+// its required members in lexicographic order.
+func jwkThumbprint(jwk models.JSONWebKey) string {
+	canonical, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{jwk.Exponent, jwk.KeyType, jwk.Modulus})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
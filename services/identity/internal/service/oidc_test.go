@@ -0,0 +1,421 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockOIDCRepository struct {
+	clients map[string]*models.OAuthClient
+	codes   map[string]*models.AuthorizationCode
+	used    map[string]bool
+}
+
+func newMockOIDCRepository() *mockOIDCRepository {
+	return &mockOIDCRepository{
+		clients: make(map[string]*models.OAuthClient),
+		codes:   make(map[string]*models.AuthorizationCode),
+		used:    make(map[string]bool),
+	}
+}
+
+func (m *mockOIDCRepository) CreateClient(ctx context.Context, client *models.OAuthClient) *errors.Error {
+	client.ID = uuid.New().String()
+	client.CreatedAt = sharedModels.Now()
+	copied := *client
+	m.clients[client.ID] = &copied
+	return nil
+}
+
+func (m *mockOIDCRepository) GetClient(ctx context.Context, id string) (*models.OAuthClient, *errors.Error) {
+	client, ok := m.clients[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("OAuth client", id)
+	}
+	copied := *client
+	return &copied, nil
+}
+
+func (m *mockOIDCRepository) ListClients(ctx context.Context) ([]*models.OAuthClient, *errors.Error) {
+	clients := make([]*models.OAuthClient, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+func (m *mockOIDCRepository) RevokeClient(ctx context.Context, id string) *errors.Error {
+	client, ok := m.clients[id]
+	if !ok || client.RevokedAt != nil {
+		return errors.NotFoundWithID("OAuth client", id)
+	}
+	now := sharedModels.Now()
+	client.RevokedAt = &now
+	return nil
+}
+
+func (m *mockOIDCRepository) CreateCode(ctx context.Context, code *models.AuthorizationCode) *errors.Error {
+	copied := *code
+	m.codes[code.CodeHash] = &copied
+	return nil
+}
+
+func (m *mockOIDCRepository) ConsumeCode(ctx context.Context, codeHash string, now time.Time) (*models.AuthorizationCode, *errors.Error) {
+	code, ok := m.codes[codeHash]
+	if !ok || m.used[codeHash] || !now.Before(code.ExpiresAt) {
+		return nil, errors.NotFound("authorization code is invalid, expired or already used")
+	}
+	m.used[codeHash] = true
+	copied := *code
+	return &copied, nil
+}
+
+var _ OIDCRepositoryInterface = (*mockOIDCRepository)(nil)
+
+const (
+	testIssuer       = "https://api.nivo.test/api/v1/oauth"
+	testRedirectURI  = "https://tools.nivo.test/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mJ92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func testCodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func setupOIDCTest(t *testing.T) (*OIDCService, *mockOIDCRepository, *models.User) {
+	t.Helper()
+	key, err := GenerateOIDCSigningKey()
+	if err != nil {
+		t.Fatalf("GenerateOIDCSigningKey: %v", err)
+	}
+
+	verifiedAt := sharedModels.Now()
+	user := &models.User{
+		ID:              uuid.New().String(),
+		Email:           "asha@example.com",
+		Phone:           "+919876543210",
+		FullName:        "Asha Rao",
+		Status:          models.UserStatusActive,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	users := &mockUserRepository{users: map[string]*models.User{user.ID: user}}
+
+	repo := newMockOIDCRepository()
+	svc := NewOIDCService(repo, users, key, OIDCConfig{Issuer: testIssuer + "/", ConsentURL: "https://app.nivo.test/oauth/consent"})
+	return svc, repo, user
+}
+
+func createTestClient(t *testing.T, svc *OIDCService, public bool) *models.OAuthClientWithSecret {
+	t.Helper()
+	client, err := svc.CreateClient(context.Background(), uuid.New().String(), &models.CreateOAuthClientRequest{
+		Name:         "Support Console",
+		RedirectURIs: []string{testRedirectURI},
+		Scopes:       []string{models.ScopeOpenID, models.ScopeProfile, models.ScopeEmail},
+		Public:       public,
+	})
+	if err != nil {
+		t.Fatalf("CreateClient: %v", err)
+	}
+	return client
+}
+
+func testAuthorizeRequest(clientID string) models.AuthorizeRequest {
+	return models.AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            clientID,
+		RedirectURI:         testRedirectURI,
+		Scope:               "openid email",
+		State:               "xyz",
+		Nonce:               "n-0S6_WzA2Mj",
+		CodeChallenge:       testCodeChallenge(testCodeVerifier),
+		CodeChallengeMethod: "S256",
+	}
+}
+
+// authorize approves a request and returns the code sent to the client.
+func authorize(t *testing.T, svc *OIDCService, userID string, req models.AuthorizeRequest) string {
+	t.Helper()
+	result, err := svc.Consent(context.Background(), userID, &models.ConsentRequest{AuthorizeRequest: req, Approved: true})
+	if err != nil {
+		t.Fatalf("Consent: %v", err)
+	}
+	redirect, parseErr := url.Parse(result.RedirectTo)
+	if parseErr != nil {
+		t.Fatalf("invalid redirect %q: %v", result.RedirectTo, parseErr)
+	}
+	query := redirect.Query()
+	if query.Get("state") != req.State || query.Get("iss") != testIssuer {
+		t.Errorf("expected state and issuer in the redirect, got %s", result.RedirectTo)
+	}
+	if query.Get("code") == "" {
+		t.Fatalf("expected a code in the redirect, got %s", result.RedirectTo)
+	}
+	return query.Get("code")
+}
+
+func TestOIDCService_AuthorizationCodeFlow(t *testing.T) {
+	svc, _, user := setupOIDCTest(t)
+	ctx := context.Background()
+	client := createTestClient(t, svc, false)
+	if client.ClientSecret == "" {
+		t.Fatal("expected a confidential client to get a secret")
+	}
+
+	prompt, err := svc.Prompt(ctx, ptr(testAuthorizeRequest(client.ID)))
+	if err != nil {
+		t.Fatalf("Prompt: %v", err)
+	}
+	if prompt.ClientName != "Support Console" || strings.Join(prompt.Scopes, " ") != "openid email" {
+		t.Errorf("unexpected prompt: %+v", prompt)
+	}
+
+	code := authorize(t, svc, user.ID, testAuthorizeRequest(client.ID))
+	tokens, err := svc.Token(ctx, &models.TokenRequest{
+		GrantType:    "authorization_code",
+		Code:         code,
+		RedirectURI:  testRedirectURI,
+		ClientID:     client.ID,
+		ClientSecret: client.ClientSecret,
+		CodeVerifier: testCodeVerifier,
+	})
+	if err != nil {
+		t.Fatalf("Token: %v", err)
+	}
+	if tokens.TokenType != "Bearer" || tokens.Scope != "openid email" || tokens.ExpiresIn != int64(DefaultOIDCTokenTTL.Seconds()) {
+		t.Errorf("unexpected token response: %+v", tokens)
+	}
+
+	// The ID token verifies with the published key
+	jwks := svc.JWKS()
+	claims := &oidcIDClaims{}
+	idToken, parseErr := jwt.ParseWithClaims(tokens.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["kid"] != jwks.Keys[0].KeyID {
+			t.Errorf("expected kid %s, got %v", jwks.Keys[0].KeyID, token.Header["kid"])
+		}
+		return &svc.key.private.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithAudience(client.ID), jwt.WithIssuer(testIssuer))
+	if parseErr != nil || !idToken.Valid {
+		t.Fatalf("ID token did not verify: %v", parseErr)
+	}
+	if claims.Subject != user.ID || claims.Nonce != "n-0S6_WzA2Mj" || claims.AccessTokenHash != accessTokenHash(tokens.AccessToken) {
+		t.Errorf("unexpected ID token claims: %+v", claims)
+	}
+	if claims.Email != user.Email || claims.EmailVerified == nil || !*claims.EmailVerified || claims.Name != "" {
+		t.Errorf("expected only the email claims granted, got %+v", claims)
+	}
+
+	info, err := svc.UserInfo(ctx, tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("UserInfo: %v", err)
+	}
+	if info.Subject != user.ID || info.Email != user.Email || info.PhoneNumber != "" {
+		t.Errorf("unexpected userinfo: %+v", info)
+	}
+
+	// ID tokens are not access tokens
+	if _, err := svc.UserInfo(ctx, tokens.IDToken); err == nil {
+		t.Error("expected userinfo to reject an ID token")
+	}
+
+	// A code is exchanged once
+	_, err = svc.Token(ctx, &models.TokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: testRedirectURI,
+		ClientID: client.ID, ClientSecret: client.ClientSecret, CodeVerifier: testCodeVerifier,
+	})
+	if err == nil || OAuthErrorCode(err) != "invalid_grant" {
+		t.Errorf("expected a reused code to be rejected, got %v", err)
+	}
+}
+
+func TestOIDCService_TokenChecks(t *testing.T) {
+	svc, _, user := setupOIDCTest(t)
+	ctx := context.Background()
+	confidential := createTestClient(t, svc, false)
+	public := createTestClient(t, svc, true)
+	if public.ClientSecret != "" || !public.Public {
+		t.Fatalf("expected a public client without a secret, got %+v", public)
+	}
+
+	tests := []struct {
+		name   string
+		client *models.OAuthClientWithSecret
+		modify func(req *models.TokenRequest)
+		want   string
+	}{
+		{"wrong secret", confidential, func(req *models.TokenRequest) { req.ClientSecret = "guess" }, "invalid_client"},
+		{"unknown client", confidential, func(req *models.TokenRequest) { req.ClientID = "not-a-client" }, "invalid_client"},
+		{"wrong verifier", public, func(req *models.TokenRequest) { req.CodeVerifier = strings.Repeat("a", 43) }, "invalid_grant"},
+		{"wrong redirect", public, func(req *models.TokenRequest) { req.RedirectURI = "https://evil.test/cb" }, "invalid_grant"},
+		{"unsupported grant", public, func(req *models.TokenRequest) { req.GrantType = "password" }, "unsupported_grant_type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := authorize(t, svc, user.ID, testAuthorizeRequest(tt.client.ID))
+			req := &models.TokenRequest{
+				GrantType: "authorization_code", Code: code, RedirectURI: testRedirectURI,
+				ClientID: tt.client.ID, ClientSecret: tt.client.ClientSecret, CodeVerifier: testCodeVerifier,
+			}
+			tt.modify(req)
+
+			_, err := svc.Token(ctx, req)
+			if err == nil || OAuthErrorCode(err) != tt.want {
+				t.Errorf("expected %s, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// A public client needs only the verifier
+	code := authorize(t, svc, user.ID, testAuthorizeRequest(public.ID))
+	if _, err := svc.Token(ctx, &models.TokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: testRedirectURI,
+		ClientID: public.ID, CodeVerifier: testCodeVerifier,
+	}); err != nil {
+		t.Errorf("expected the public client to get tokens, got %v", err)
+	}
+
+	// Suspended users can no longer sign in to clients
+	code = authorize(t, svc, user.ID, testAuthorizeRequest(public.ID))
+	user.Status = models.UserStatusSuspended
+	if _, err := svc.Token(ctx, &models.TokenRequest{
+		GrantType: "authorization_code", Code: code, RedirectURI: testRedirectURI,
+		ClientID: public.ID, CodeVerifier: testCodeVerifier,
+	}); err == nil || OAuthErrorCode(err) != "invalid_grant" {
+		t.Errorf("expected a suspended user to be refused, got %v", err)
+	}
+}
+
+func TestOIDCService_AuthorizationErrors(t *testing.T) {
+	svc, repo, user := setupOIDCTest(t)
+	ctx := context.Background()
+	client := createTestClient(t, svc, true)
+
+	// Unknown clients and redirect URIs are never redirected to
+	for name, modify := range map[string]func(req *models.AuthorizeRequest){
+		"unknown client":      func(req *models.AuthorizeRequest) { req.ClientID = uuid.New().String() },
+		"unregistered uri":    func(req *models.AuthorizeRequest) { req.RedirectURI = "https://evil.test/cb" },
+		"malformed client id": func(req *models.AuthorizeRequest) { req.ClientID = "abc" },
+	} {
+		req := testAuthorizeRequest(client.ID)
+		modify(&req)
+		_, err := svc.Prompt(ctx, &req)
+		if err == nil || OAuthErrorCode(err) != "" {
+			t.Errorf("%s: expected an error shown to the user, got %v", name, err)
+		}
+	}
+
+	// Other errors go back to the client
+	for name, tc := range map[string]struct {
+		modify func(req *models.AuthorizeRequest)
+		want   string
+	}{
+		"no pkce":            {func(req *models.AuthorizeRequest) { req.CodeChallenge = "" }, "invalid_request"},
+		"plain pkce":         {func(req *models.AuthorizeRequest) { req.CodeChallengeMethod = "plain" }, "invalid_request"},
+		"token response":     {func(req *models.AuthorizeRequest) { req.ResponseType = "token" }, "unsupported_response_type"},
+		"no openid":          {func(req *models.AuthorizeRequest) { req.Scope = "email" }, "invalid_scope"},
+		"unregistered scope": {func(req *models.AuthorizeRequest) { req.Scope = "openid phone" }, "invalid_scope"},
+	} {
+		req := testAuthorizeRequest(client.ID)
+		tc.modify(&req)
+		_, err := svc.Prompt(ctx, &req)
+		if err == nil || OAuthErrorCode(err) != tc.want {
+			t.Errorf("%s: expected %s, got %v", name, tc.want, err)
+		}
+	}
+
+	// A denial is reported to the client
+	result, err := svc.Consent(ctx, user.ID, &models.ConsentRequest{AuthorizeRequest: testAuthorizeRequest(client.ID)})
+	if err != nil {
+		t.Fatalf("Consent: %v", err)
+	}
+	if !strings.HasPrefix(result.RedirectTo, testRedirectURI+"?") || !strings.Contains(result.RedirectTo, "error=access_denied") || !strings.Contains(result.RedirectTo, "state=xyz") {
+		t.Errorf("unexpected denial redirect: %s", result.RedirectTo)
+	}
+	if len(repo.codes) != 0 {
+		t.Error("expected no code to be issued on denial")
+	}
+
+	// Revoked clients can no longer ask
+	if err := svc.RevokeClient(ctx, client.ID); err != nil {
+		t.Fatalf("RevokeClient: %v", err)
+	}
+	if _, err := svc.Prompt(ctx, ptr(testAuthorizeRequest(client.ID))); err == nil {
+		t.Error("expected a revoked client to be refused")
+	}
+}
+
+func TestOIDCService_CreateClientValidation(t *testing.T) {
+	svc, _, _ := setupOIDCTest(t)
+	ctx := context.Background()
+
+	invalid := map[string]*models.CreateOAuthClientRequest{
+		"no redirect":      {Name: "x"},
+		"relative uri":     {Name: "x", RedirectURIs: []string{"/callback"}},
+		"http uri":         {Name: "x", RedirectURIs: []string{"http://tools.nivo.test/cb"}},
+		"fragment":         {Name: "x", RedirectURIs: []string{"https://tools.nivo.test/cb#frag"}},
+		"unknown scope":    {Name: "x", RedirectURIs: []string{testRedirectURI}, Scopes: []string{"openid", "wallets"}},
+		"scope not openid": {Name: "x", RedirectURIs: []string{testRedirectURI}, Scopes: []string{"email"}},
+	}
+	for name, req := range invalid {
+		if _, err := svc.CreateClient(ctx, "admin-1", req); err == nil {
+			t.Errorf("%s: expected the client to be rejected", name)
+		}
+	}
+
+	client, err := svc.CreateClient(ctx, "admin-1", &models.CreateOAuthClientRequest{
+		Name:         "CLI",
+		RedirectURIs: []string{"http://127.0.0.1:8765/callback", "com.nivo.tools:/oauth"},
+		Public:       true,
+	})
+	if err != nil {
+		t.Fatalf("expected loopback and native redirect URIs to be accepted, got %v", err)
+	}
+	if strings.Join(client.Scopes, " ") != "openid" {
+		t.Errorf("expected scopes to default to openid, got %v", client.Scopes)
+	}
+}
+
+func TestOIDCService_Discovery(t *testing.T) {
+	svc, _, _ := setupOIDCTest(t)
+
+	discovery := svc.Discovery()
+	if discovery.Issuer != testIssuer || discovery.TokenEndpoint != testIssuer+"/token" || discovery.JWKSURI != testIssuer+"/jwks" {
+		t.Errorf("unexpected endpoints: %+v", discovery)
+	}
+
+	consent := svc.ConsentURL(ptr(testAuthorizeRequest("client-1")))
+	if !strings.HasPrefix(consent, "https://app.nivo.test/oauth/consent?") || !strings.Contains(consent, "client_id=client-1") {
+		t.Errorf("unexpected consent URL: %s", consent)
+	}
+}
+
+func TestParseOIDCSigningKey(t *testing.T) {
+	if _, err := ParseOIDCSigningKey([]byte("not a key")); err == nil {
+		t.Error("expected a non-PEM key to be rejected")
+	}
+
+	key, err := GenerateOIDCSigningKey()
+	if err != nil {
+		t.Fatalf("GenerateOIDCSigningKey: %v", err)
+	}
+	// The key ID is the RFC 7638 thumbprint, stable for the same key
+	if again := newOIDCSigningKey(key.private); again.KeyID() != key.KeyID() || len(key.KeyID()) != 43 {
+		t.Errorf("unexpected key ID %q", key.KeyID())
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
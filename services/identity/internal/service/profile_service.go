@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ProfileAttributeRepositoryInterface defines the interface for profile attribute storage.
+type ProfileAttributeRepositoryInterface interface {
+	Get(ctx context.Context, userID string) (models.ProfileAttributes, *sharedModels.Timestamp, *errors.Error)
+	Update(ctx context.Context, userID string, set models.ProfileAttributes, clear []models.ProfileAttributeKey) *errors.Error
+}
+
+// ProfileService manages user profile attributes and publishes changes so
+// other services, such as notification, can follow a user's preferences.
+type ProfileService struct {
+	attrRepo       ProfileAttributeRepositoryInterface
+	eventPublisher *events.Publisher
+}
+
+// NewProfileService creates a new profile service.
+func NewProfileService(attrRepo ProfileAttributeRepositoryInterface, eventPublisher *events.Publisher) *ProfileService {
+	return &ProfileService{attrRepo: attrRepo, eventPublisher: eventPublisher}
+}
+
+// GetAttributes returns a user's profile attributes.
+func (s *ProfileService) GetAttributes(ctx context.Context, userID string) (*models.ProfileAttributesResponse, *errors.Error) {
+	attrs, updatedAt, err := s.attrRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ProfileAttributesResponse{Attributes: attrs.JSON(), UpdatedAt: updatedAt}, nil
+}
+
+// UpdateAttributes sets and clears attributes, then publishes the full set
+// as user.preferences_updated.
+func (s *ProfileService) UpdateAttributes(ctx context.Context, userID string, req *models.UpdateProfileAttributesRequest) (*models.ProfileAttributesResponse, *errors.Error) {
+	set, clear, validationErr := req.Changes()
+	if validationErr != nil {
+		return nil, errors.Validation(validationErr.Error())
+	}
+
+	current, _, err := s.attrRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(models.ProfileAttributes, len(current)+len(set))
+	for key, value := range current {
+		merged[key] = value
+	}
+	for _, key := range clear {
+		delete(merged, key)
+	}
+	for key, value := range set {
+		merged[key] = value
+	}
+	if validationErr := merged.Validate(); validationErr != nil {
+		return nil, errors.Validation(validationErr.Error())
+	}
+
+	if err := s.attrRepo.Update(ctx, userID, set, clear); err != nil {
+		return nil, err
+	}
+
+	attrs, updatedAt, err := s.attrRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[identity] Updated profile attributes for user %s (%d set, %d cleared)", userID, len(set), len(clear))
+	s.publishPreferences(userID, attrs)
+
+	return &models.ProfileAttributesResponse{Attributes: attrs.JSON(), UpdatedAt: updatedAt}, nil
+}
+
+// publishPreferences publishes a user's full attribute set.
+func (s *ProfileService) publishPreferences(userID string, attrs models.ProfileAttributes) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	s.eventPublisher.PublishAsync("users", events.UserPreferencesUpdated{
+		UserID:           userID,
+		Locale:           attrs[models.AttrLocale],
+		Timezone:         attrs[models.AttrTimezone],
+		DisplayCurrency:  attrs[models.AttrDisplayCurrency],
+		MarketingConsent: attrs.MarketingConsent(),
+		QuietHoursStart:  attrs[models.AttrQuietHoursStart],
+		QuietHoursEnd:    attrs[models.AttrQuietHoursEnd],
+		UpdatedAt:        time.Now().UTC().Format(time.RFC3339Nano),
+	}, userID)
+}
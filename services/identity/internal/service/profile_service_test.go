@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// =====================================================================
+// Mock Profile Attribute Repository
+// =====================================================================
+
+type mockProfileAttributeRepository struct {
+	attrs map[string]models.ProfileAttributes
+}
+
+func newMockProfileAttributeRepository() *mockProfileAttributeRepository {
+	return &mockProfileAttributeRepository{attrs: make(map[string]models.ProfileAttributes)}
+}
+
+func (m *mockProfileAttributeRepository) Get(ctx context.Context, userID string) (models.ProfileAttributes, *sharedModels.Timestamp, *errors.Error) {
+	attrs := make(models.ProfileAttributes)
+	for key, value := range m.attrs[userID] {
+		attrs[key] = value
+	}
+	if len(attrs) == 0 {
+		return attrs, nil, nil
+	}
+	now := sharedModels.Now()
+	return attrs, &now, nil
+}
+
+func (m *mockProfileAttributeRepository) Update(ctx context.Context, userID string, set models.ProfileAttributes, clear []models.ProfileAttributeKey) *errors.Error {
+	if m.attrs[userID] == nil {
+		m.attrs[userID] = make(models.ProfileAttributes)
+	}
+	for key, value := range set {
+		m.attrs[userID][key] = value
+	}
+	for _, key := range clear {
+		delete(m.attrs[userID], key)
+	}
+	return nil
+}
+
+// =====================================================================
+// Profile Attribute Tests
+// =====================================================================
+
+func TestUpdateAttributes_NormalizesValues(t *testing.T) {
+	repo := newMockProfileAttributeRepository()
+	svc := NewProfileService(repo, nil)
+
+	resp, err := svc.UpdateAttributes(context.Background(), "user-1", &models.UpdateProfileAttributesRequest{
+		Attributes: map[string]interface{}{
+			"locale":            "hi_in",
+			"timezone":          "Asia/Kolkata",
+			"display_currency":  "usd",
+			"marketing_consent": true,
+			"quiet_hours_start": "22:00",
+			"quiet_hours_end":   "07:30",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"locale":            "hi-IN",
+		"timezone":          "Asia/Kolkata",
+		"display_currency":  "USD",
+		"marketing_consent": true,
+		"quiet_hours_start": "22:00",
+		"quiet_hours_end":   "07:30",
+	}
+	for key, value := range want {
+		if resp.Attributes[key] != value {
+			t.Errorf("%s = %v, want %v", key, resp.Attributes[key], value)
+		}
+	}
+	if resp.UpdatedAt == nil {
+		t.Error("expected updated_at to be set")
+	}
+}
+
+func TestUpdateAttributes_RejectsInvalidValues(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"unknown key":           {"favourite_colour": "blue"},
+		"empty request":         {},
+		"bad locale":            {"locale": "english"},
+		"bad timezone":          {"timezone": "Mars/Olympus"},
+		"unsupported currency":  {"display_currency": "XYZ"},
+		"consent not boolean":   {"marketing_consent": "yes"},
+		"bad quiet hours time":  {"timezone": "Asia/Kolkata", "quiet_hours_start": "25:00", "quiet_hours_end": "07:00"},
+		"quiet hours half set":  {"timezone": "Asia/Kolkata", "quiet_hours_start": "22:00"},
+		"quiet hours no tz":     {"quiet_hours_start": "22:00", "quiet_hours_end": "07:00"},
+		"quiet hours zero span": {"timezone": "Asia/Kolkata", "quiet_hours_start": "22:00", "quiet_hours_end": "22:00"},
+	}
+
+	for name, attrs := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := NewProfileService(newMockProfileAttributeRepository(), nil)
+			_, err := svc.UpdateAttributes(context.Background(), "user-1", &models.UpdateProfileAttributesRequest{Attributes: attrs})
+			if err == nil || err.Code != errors.ErrCodeValidation {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateAttributes_NullClearsAndMergesWithExisting(t *testing.T) {
+	repo := newMockProfileAttributeRepository()
+	repo.attrs["user-1"] = models.ProfileAttributes{
+		models.AttrLocale:          "en-IN",
+		models.AttrTimezone:        "Asia/Kolkata",
+		models.AttrQuietHoursStart: "22:00",
+		models.AttrQuietHoursEnd:   "07:00",
+	}
+	svc := NewProfileService(repo, nil)
+
+	// Clearing the timezone would orphan the quiet hours
+	_, err := svc.UpdateAttributes(context.Background(), "user-1", &models.UpdateProfileAttributesRequest{
+		Attributes: map[string]interface{}{"timezone": nil},
+	})
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	resp, err := svc.UpdateAttributes(context.Background(), "user-1", &models.UpdateProfileAttributesRequest{
+		Attributes: map[string]interface{}{"locale": nil, "display_currency": "INR"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := resp.Attributes["locale"]; ok {
+		t.Error("expected locale to be cleared")
+	}
+	if resp.Attributes["timezone"] != "Asia/Kolkata" || resp.Attributes["display_currency"] != "INR" {
+		t.Errorf("unexpected attributes: %v", resp.Attributes)
+	}
+}
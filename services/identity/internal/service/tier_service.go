@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+)
+
+// Tier change request listing defaults.
+const (
+	DefaultTierRequestPageSize = 50
+	MaxTierRequestPageSize     = 100
+)
+
+// TierRepositoryInterface defines the interface for tier storage.
+type TierRepositoryInterface interface {
+	ListTiers(ctx context.Context) ([]*models.AccountTier, *errors.Error)
+	GetTier(ctx context.Context, code string) (*models.AccountTier, *errors.Error)
+	GetUserTier(ctx context.Context, userID string) (string, *errors.Error)
+	CreateChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error
+	GetChangeRequest(ctx context.Context, id string) (*models.TierChangeRequest, *errors.Error)
+	GetPendingChangeRequest(ctx context.Context, userID string) (*models.TierChangeRequest, *errors.Error)
+	ListChangeRequests(ctx context.Context, status models.TierChangeStatus, limit, offset int) ([]*models.TierChangeRequest, *errors.Error)
+	ApplyChange(ctx context.Context, req *models.TierChangeRequest) *errors.Error
+	CloseChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error
+}
+
+// TierBillingHook charges the fee for moving into a paid tier. It returns a
+// reference to the charge, recorded on the approved request.
+type TierBillingHook interface {
+	ChargeTierFee(ctx context.Context, req *models.TierChangeRequest) (string, *errors.Error)
+}
+
+// TransactionBillingHook collects tier fees as fee transactions from the
+// user's wallet into a platform fee wallet, so each fee is recorded and
+// posted to the ledger. The change request ID is the fee reference, which
+// the transaction service treats as an idempotency key, so a retried
+// approval never charges twice.
+type TransactionBillingHook struct {
+	walletClient      *WalletClient
+	transactionClient *TransactionClient
+	feeWalletID       string
+}
+
+// NewTransactionBillingHook creates a billing hook that credits feeWalletID.
+func NewTransactionBillingHook(walletClient *WalletClient, transactionClient *TransactionClient, feeWalletID string) *TransactionBillingHook {
+	return &TransactionBillingHook{walletClient: walletClient, transactionClient: transactionClient, feeWalletID: feeWalletID}
+}
+
+// ChargeTierFee debits the request's fee from the wallet chosen by the user.
+func (h *TransactionBillingHook) ChargeTierFee(ctx context.Context, req *models.TierChangeRequest) (string, *errors.Error) {
+	if req.WalletID == nil || *req.WalletID == "" {
+		return "", errors.BadRequest("a wallet is required to pay the tier fee")
+	}
+
+	wallet, err := h.walletClient.GetWalletInfo(ctx, *req.WalletID)
+	if err != nil {
+		return "", errors.Unavailable("failed to look up fee wallet: " + err.Error())
+	}
+	if wallet.UserID != req.UserID {
+		return "", errors.Forbidden("fee wallet does not belong to the user")
+	}
+
+	fee, err := h.transactionClient.ChargeFee(ctx, &FeeChargeRequest{
+		WalletID:    *req.WalletID,
+		FeeWalletID: h.feeWalletID,
+		Amount:      req.FeeAmount,
+		Currency:    "INR",
+		Description: "Tier upgrade fee: " + req.ToTier,
+		Reference:   "tier_change:" + req.ID,
+	})
+	if err != nil {
+		var chargeErr *errors.Error
+		if stderrors.As(err, &chargeErr) && chargeErr.HTTPStatusCode() >= http.StatusInternalServerError {
+			return "", errors.Unavailable("failed to collect tier fee: " + err.Error())
+		}
+		return "", errors.BadRequest("failed to collect tier fee: " + err.Error())
+	}
+
+	return "transaction:" + fee.ID, nil
+}
+
+// TierService manages account tiers and the tier change workflow.
+type TierService struct {
+	tierRepo       TierRepositoryInterface
+	billing        TierBillingHook
+	eventPublisher *events.Publisher
+}
+
+// NewTierService creates a new tier service. Without a billing hook, upgrades
+// are approved without collecting the fee.
+func NewTierService(tierRepo TierRepositoryInterface, billing TierBillingHook, eventPublisher *events.Publisher) *TierService {
+	return &TierService{
+		tierRepo:       tierRepo,
+		billing:        billing,
+		eventPublisher: eventPublisher,
+	}
+}
+
+// ListTiers returns all tier definitions with their entitlements.
+func (s *TierService) ListTiers(ctx context.Context) ([]*models.AccountTier, *errors.Error) {
+	return s.tierRepo.ListTiers(ctx)
+}
+
+// GetUserTierCode returns the user's tier code. It satisfies the lookup used
+// when issuing tokens.
+func (s *TierService) GetUserTierCode(ctx context.Context, userID string) (string, *errors.Error) {
+	return s.tierRepo.GetUserTier(ctx, userID)
+}
+
+// GetUserTier returns the user's current tier and any open change request.
+func (s *TierService) GetUserTier(ctx context.Context, userID string) (*models.UserTierResponse, *errors.Error) {
+	code, err := s.tierRepo.GetUserTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tier, err := s.tierRepo.GetTier(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := s.tierRepo.GetPendingChangeRequest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserTierResponse{Tier: tier, PendingRequest: pending}, nil
+}
+
+// RequestTierChange opens a tier change for a user. Upgrades stay pending
+// until an admin approves them; downgrades carry no fee and apply immediately.
+func (s *TierService) RequestTierChange(ctx context.Context, userID string, req *models.CreateTierChangeRequest) (*models.TierChangeRequest, *errors.Error) {
+	currentCode, err := s.tierRepo.GetUserTier(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.ToTier == currentCode {
+		return nil, errors.BadRequest("already on the requested tier")
+	}
+
+	current, err := s.tierRepo.GetTier(ctx, currentCode)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.tierRepo.GetTier(ctx, req.ToTier)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.BadRequest("unknown tier: " + req.ToTier)
+		}
+		return nil, err
+	}
+
+	upgrade := target.Rank > current.Rank
+	change := &models.TierChangeRequest{
+		UserID:   userID,
+		FromTier: current.Code,
+		ToTier:   target.Code,
+		Status:   models.TierChangePending,
+	}
+	if req.Reason != "" {
+		change.Reason = &req.Reason
+	}
+	if upgrade {
+		change.FeeAmount = target.UpgradeFee
+		if change.FeeAmount > 0 && req.WalletID == "" {
+			return nil, errors.BadRequest("wallet_id is required to pay the upgrade fee")
+		}
+	}
+	if req.WalletID != "" {
+		change.WalletID = &req.WalletID
+	}
+
+	if err := s.tierRepo.CreateChangeRequest(ctx, change); err != nil {
+		return nil, err
+	}
+
+	if !upgrade {
+		if err := s.tierRepo.ApplyChange(ctx, change); err != nil {
+			return nil, err
+		}
+		s.publishTierChanged(change)
+		return change, nil
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishUserEvent("user.tier_change_requested", userID, map[string]interface{}{
+			"request_id": change.ID,
+			"from_tier":  change.FromTier,
+			"to_tier":    change.ToTier,
+			"fee_amount": change.FeeAmount,
+		})
+	}
+
+	return change, nil
+}
+
+// CancelTierChange withdraws the user's own pending request.
+func (s *TierService) CancelTierChange(ctx context.Context, userID, requestID string) (*models.TierChangeRequest, *errors.Error) {
+	change, err := s.tierRepo.GetChangeRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if change.UserID != userID {
+		return nil, errors.NotFoundWithID("tier change request", requestID)
+	}
+
+	change.Status = models.TierChangeCancelled
+	if err := s.tierRepo.CloseChangeRequest(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ListTierChangeRequests lists change requests for admin review.
+func (s *TierService) ListTierChangeRequests(ctx context.Context, status models.TierChangeStatus, limit, offset int) ([]*models.TierChangeRequest, *errors.Error) {
+	if limit <= 0 {
+		limit = DefaultTierRequestPageSize
+	}
+	if limit > MaxTierRequestPageSize {
+		limit = MaxTierRequestPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.tierRepo.ListChangeRequests(ctx, status, limit, offset)
+}
+
+// ApproveTierChange collects the upgrade fee and moves the user to the new
+// tier. If the fee cannot be collected the request stays pending.
+func (s *TierService) ApproveTierChange(ctx context.Context, requestID, reviewerID, note string) (*models.TierChangeRequest, *errors.Error) {
+	change, err := s.tierRepo.GetChangeRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if change.Status != models.TierChangePending {
+		return nil, errors.Conflict("tier change request is no longer pending")
+	}
+
+	if change.FeeAmount > 0 && s.billing != nil {
+		reference, billErr := s.billing.ChargeTierFee(ctx, change)
+		if billErr != nil {
+			return nil, billErr
+		}
+		change.FeeReference = &reference
+	}
+
+	change.ReviewedBy = &reviewerID
+	if note != "" {
+		change.ReviewNote = &note
+	}
+	if err := s.tierRepo.ApplyChange(ctx, change); err != nil {
+		return nil, err
+	}
+
+	s.publishTierChanged(change)
+	return change, nil
+}
+
+// RejectTierChange closes a pending request without changing the user's tier.
+func (s *TierService) RejectTierChange(ctx context.Context, requestID, reviewerID, note string) (*models.TierChangeRequest, *errors.Error) {
+	change, err := s.tierRepo.GetChangeRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	change.Status = models.TierChangeRejected
+	change.ReviewedBy = &reviewerID
+	if note != "" {
+		change.ReviewNote = &note
+	}
+	if err := s.tierRepo.CloseChangeRequest(ctx, change); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// publishTierChanged announces an applied tier change so downstream services
+// can adjust limits before the user's next token refresh.
+func (s *TierService) publishTierChanged(change *models.TierChangeRequest) {
+	if s.eventPublisher == nil {
+		return
+	}
+	s.eventPublisher.PublishUserEvent("user.tier_changed", change.UserID, map[string]interface{}{
+		"request_id": change.ID,
+		"from_tier":  change.FromTier,
+		"to_tier":    change.ToTier,
+		"fee_amount": change.FeeAmount,
+	})
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/google/uuid"
+)
+
+// =====================================================================
+// Mock Tier Repository
+// =====================================================================
+
+type mockTierRepository struct {
+	tiers     map[string]*models.AccountTier
+	userTiers map[string]string
+	requests  map[string]*models.TierChangeRequest
+}
+
+func newMockTierRepository() *mockTierRepository {
+	return &mockTierRepository{
+		tiers: map[string]*models.AccountTier{
+			models.TierRegular: {Code: models.TierRegular, Rank: 1},
+			models.TierPremium: {Code: models.TierPremium, Rank: 2, UpgradeFee: 49900},
+		},
+		userTiers: make(map[string]string),
+		requests:  make(map[string]*models.TierChangeRequest),
+	}
+}
+
+func (m *mockTierRepository) ListTiers(ctx context.Context) ([]*models.AccountTier, *errors.Error) {
+	return []*models.AccountTier{m.tiers[models.TierRegular], m.tiers[models.TierPremium]}, nil
+}
+
+func (m *mockTierRepository) GetTier(ctx context.Context, code string) (*models.AccountTier, *errors.Error) {
+	tier, ok := m.tiers[code]
+	if !ok {
+		return nil, errors.NotFoundWithID("account tier", code)
+	}
+	return tier, nil
+}
+
+func (m *mockTierRepository) GetUserTier(ctx context.Context, userID string) (string, *errors.Error) {
+	if tier, ok := m.userTiers[userID]; ok {
+		return tier, nil
+	}
+	return models.DefaultTier, nil
+}
+
+func (m *mockTierRepository) CreateChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	for _, existing := range m.requests {
+		if existing.UserID == req.UserID && existing.Status == models.TierChangePending {
+			return errors.Conflict("a tier change request is already pending")
+		}
+	}
+	req.ID = uuid.New().String()
+	m.requests[req.ID] = req
+	return nil
+}
+
+func (m *mockTierRepository) GetChangeRequest(ctx context.Context, id string) (*models.TierChangeRequest, *errors.Error) {
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("tier change request", id)
+	}
+	return req, nil
+}
+
+func (m *mockTierRepository) GetPendingChangeRequest(ctx context.Context, userID string) (*models.TierChangeRequest, *errors.Error) {
+	for _, req := range m.requests {
+		if req.UserID == userID && req.Status == models.TierChangePending {
+			return req, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTierRepository) ListChangeRequests(ctx context.Context, status models.TierChangeStatus, limit, offset int) ([]*models.TierChangeRequest, *errors.Error) {
+	var result []*models.TierChangeRequest
+	for _, req := range m.requests {
+		if status == "" || req.Status == status {
+			result = append(result, req)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTierRepository) ApplyChange(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	if req.Status != models.TierChangePending {
+		return errors.Conflict("tier change request is no longer pending")
+	}
+	req.Status = models.TierChangeApproved
+	m.userTiers[req.UserID] = req.ToTier
+	return nil
+}
+
+func (m *mockTierRepository) CloseChangeRequest(ctx context.Context, req *models.TierChangeRequest) *errors.Error {
+	m.requests[req.ID] = req
+	return nil
+}
+
+type mockBillingHook struct {
+	charged []string
+	err     *errors.Error
+}
+
+func (m *mockBillingHook) ChargeTierFee(ctx context.Context, req *models.TierChangeRequest) (string, *errors.Error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	m.charged = append(m.charged, req.ID)
+	return "wallet:" + req.ID, nil
+}
+
+// =====================================================================
+// Tier Service Tests
+// =====================================================================
+
+func TestRequestTierChange_UpgradeStaysPending(t *testing.T) {
+	repo := newMockTierRepository()
+	svc := NewTierService(repo, &mockBillingHook{}, nil)
+	userID := uuid.New().String()
+
+	change, err := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{
+		ToTier:   models.TierPremium,
+		WalletID: uuid.New().String(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if change.Status != models.TierChangePending {
+		t.Errorf("expected pending, got %s", change.Status)
+	}
+	if change.FeeAmount != 49900 {
+		t.Errorf("expected fee 49900, got %d", change.FeeAmount)
+	}
+	if repo.userTiers[userID] != "" {
+		t.Error("user tier should not change before approval")
+	}
+}
+
+func TestRequestTierChange_PaidUpgradeRequiresWallet(t *testing.T) {
+	svc := NewTierService(newMockTierRepository(), &mockBillingHook{}, nil)
+
+	_, err := svc.RequestTierChange(context.Background(), uuid.New().String(), &models.CreateTierChangeRequest{
+		ToTier: models.TierPremium,
+	})
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Fatalf("expected bad request, got %v", err)
+	}
+}
+
+func TestRequestTierChange_DowngradeAppliesImmediately(t *testing.T) {
+	repo := newMockTierRepository()
+	userID := uuid.New().String()
+	repo.userTiers[userID] = models.TierPremium
+	svc := NewTierService(repo, &mockBillingHook{}, nil)
+
+	change, err := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{
+		ToTier: models.TierRegular,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if change.Status != models.TierChangeApproved || change.FeeAmount != 0 {
+		t.Errorf("expected free immediate downgrade, got %+v", change)
+	}
+	if repo.userTiers[userID] != models.TierRegular {
+		t.Errorf("expected regular tier, got %s", repo.userTiers[userID])
+	}
+}
+
+func TestRequestTierChange_RejectsSameOrUnknownTier(t *testing.T) {
+	svc := NewTierService(newMockTierRepository(), nil, nil)
+	userID := uuid.New().String()
+
+	if _, err := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{ToTier: models.TierRegular}); err == nil {
+		t.Error("expected error for current tier")
+	}
+	if _, err := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{ToTier: "platinum"}); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request for unknown tier, got %v", err)
+	}
+}
+
+func TestApproveTierChange_ChargesFeeAndAppliesTier(t *testing.T) {
+	repo := newMockTierRepository()
+	billing := &mockBillingHook{}
+	svc := NewTierService(repo, billing, nil)
+	userID := uuid.New().String()
+
+	change, _ := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{
+		ToTier:   models.TierPremium,
+		WalletID: uuid.New().String(),
+	})
+
+	approved, err := svc.ApproveTierChange(context.Background(), change.ID, uuid.New().String(), "ok")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(billing.charged) != 1 {
+		t.Errorf("expected one fee charge, got %d", len(billing.charged))
+	}
+	if approved.FeeReference == nil || *approved.FeeReference != "wallet:"+change.ID {
+		t.Errorf("expected fee reference to be recorded, got %v", approved.FeeReference)
+	}
+	if repo.userTiers[userID] != models.TierPremium {
+		t.Errorf("expected premium tier, got %s", repo.userTiers[userID])
+	}
+
+	if _, err := svc.ApproveTierChange(context.Background(), change.ID, uuid.New().String(), ""); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict on re-approval, got %v", err)
+	}
+}
+
+func TestApproveTierChange_BillingFailureKeepsPending(t *testing.T) {
+	repo := newMockTierRepository()
+	svc := NewTierService(repo, &mockBillingHook{err: errors.BadRequest("insufficient balance")}, nil)
+	userID := uuid.New().String()
+
+	change, _ := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{
+		ToTier:   models.TierPremium,
+		WalletID: uuid.New().String(),
+	})
+
+	if _, err := svc.ApproveTierChange(context.Background(), change.ID, uuid.New().String(), ""); err == nil {
+		t.Fatal("expected billing error")
+	}
+	if repo.requests[change.ID].Status != models.TierChangePending {
+		t.Errorf("expected request to stay pending, got %s", repo.requests[change.ID].Status)
+	}
+	if repo.userTiers[userID] != "" {
+		t.Error("user tier should not change when billing fails")
+	}
+}
+
+func TestCancelTierChange_OnlyOwner(t *testing.T) {
+	svc := NewTierService(newMockTierRepository(), nil, nil)
+	userID := uuid.New().String()
+
+	change, _ := svc.RequestTierChange(context.Background(), userID, &models.CreateTierChangeRequest{
+		ToTier:   models.TierPremium,
+		WalletID: uuid.New().String(),
+	})
+
+	if _, err := svc.CancelTierChange(context.Background(), uuid.New().String(), change.ID); err == nil || !errors.IsNotFound(err) {
+		t.Errorf("expected not found for another user, got %v", err)
+	}
+
+	cancelled, err := svc.CancelTierChange(context.Background(), userID, change.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cancelled.Status != models.TierChangeCancelled {
+		t.Errorf("expected cancelled, got %s", cancelled.Status)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+)
+
+// TransactionClient handles communication with the Transaction service.
+type TransactionClient struct {
+	*clients.BaseClient
+}
+
+// NewTransactionClientWithSecret creates a transaction client with internal service authentication.
+func NewTransactionClientWithSecret(baseURL, internalSecret string) *TransactionClient {
+	return &TransactionClient{
+		BaseClient: clients.NewInternalClient(baseURL, clients.DefaultTimeout, internalSecret),
+	}
+}
+
+// FeeChargeRequest mirrors the transaction service's fee charge payload.
+type FeeChargeRequest struct {
+	WalletID    string `json:"wallet_id"`
+	FeeWalletID string `json:"fee_wallet_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Description string `json:"description"`
+	Reference   string `json:"reference"`
+}
+
+// FeeTransaction is the fee transaction returned by the transaction service.
+type FeeTransaction struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// ChargeFee records a fee transaction and moves the fee into the fee wallet.
+// The transaction service treats the reference as an idempotency key.
+func (c *TransactionClient) ChargeFee(ctx context.Context, req *FeeChargeRequest) (*FeeTransaction, error) {
+	var result FeeTransaction
+	if err := c.Post(ctx, "/internal/v1/transactions/fees", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -3,11 +3,11 @@ package service
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/identity/internal/models"
 	"github.com/1mb-dev/nivomoney/services/identity/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/crypto"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/logger"
@@ -220,7 +220,7 @@ func (s *VerificationService) generateVerificationToken(req *models.Verification
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secret := os.Getenv("JWT_SECRET")
+	secret := config.Secret("JWT_SECRET")
 	if secret == "" {
 		// JWT_SECRET is validated at startup; this should never happen
 		return "", errors.Internal("JWT_SECRET not configured")
@@ -242,7 +242,7 @@ func (s *VerificationService) ValidateVerificationToken(
 	expectedOperation models.OperationType,
 	expectedUserID string,
 ) (*models.VerificationClaims, *errors.Error) {
-	secret := os.Getenv("JWT_SECRET")
+	secret := config.Secret("JWT_SECRET")
 	if secret == "" {
 		// JWT_SECRET is validated at startup; this should never happen
 		return nil, errors.Internal("JWT_SECRET not configured")
@@ -81,3 +81,21 @@ func (c *WalletClient) ActivateWallet(ctx context.Context, walletID string) erro
 	}
 	return nil
 }
+
+// WalletInfo is the minimal wallet view returned by the internal info endpoint.
+type WalletInfo struct {
+	ID              string `json:"id"`
+	UserID          string `json:"user_id"`
+	Status          string `json:"status"`
+	LedgerAccountID string `json:"ledger_account_id"`
+}
+
+// GetWalletInfo retrieves wallet ownership and status via the internal endpoint.
+func (c *WalletClient) GetWalletInfo(ctx context.Context, walletID string) (*WalletInfo, error) {
+	var result WalletInfo
+	path := fmt.Sprintf("/internal/v1/wallets/%s/info", walletID)
+	if err := c.Get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
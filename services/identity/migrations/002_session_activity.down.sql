@@ -0,0 +1,3 @@
+-- Session Activity Tracking Rollback
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS last_seen_at;
@@ -0,0 +1,7 @@
+-- Session Activity Tracking
+-- Adds last-seen timestamps so users can review and revoke active sessions
+
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+COMMENT ON COLUMN sessions.last_seen_at IS 'Last time the session token was validated against the database';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tier_change_requests;
+ALTER TABLE users DROP COLUMN IF EXISTS account_tier;
+DROP TABLE IF EXISTS account_tiers;
@@ -0,0 +1,51 @@
+-- Account Tiers
+-- Tier definitions with entitlements, per-user tier assignment, and the
+-- upgrade/downgrade request workflow
+
+CREATE TABLE IF NOT EXISTS account_tiers (
+    code                   VARCHAR(20) PRIMARY KEY,
+    name                   VARCHAR(50) NOT NULL,
+    rank                   INT NOT NULL UNIQUE,
+    daily_transfer_limit   BIGINT NOT NULL CHECK (daily_transfer_limit > 0),
+    monthly_transfer_limit BIGINT NOT NULL CHECK (monthly_transfer_limit >= daily_transfer_limit),
+    max_virtual_cards      INT NOT NULL CHECK (max_virtual_cards >= 0),
+    support_priority       VARCHAR(20) NOT NULL CHECK (support_priority IN ('standard', 'priority')),
+    upgrade_fee            BIGINT NOT NULL DEFAULT 0 CHECK (upgrade_fee >= 0),
+    created_at             TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE account_tiers IS 'Account tier definitions and their entitlements';
+COMMENT ON COLUMN account_tiers.upgrade_fee IS 'One-time fee in paise charged when upgrading into this tier';
+
+INSERT INTO account_tiers (code, name, rank, daily_transfer_limit, monthly_transfer_limit, max_virtual_cards, support_priority, upgrade_fee)
+VALUES
+    ('regular', 'Regular', 1, 20000000, 200000000, 2, 'standard', 0),
+    ('premium', 'Premium', 2, 100000000, 1000000000, 5, 'priority', 49900)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS account_tier VARCHAR(20) NOT NULL DEFAULT 'regular' REFERENCES account_tiers(code);
+
+CREATE TABLE IF NOT EXISTS tier_change_requests (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    from_tier     VARCHAR(20) NOT NULL REFERENCES account_tiers(code),
+    to_tier       VARCHAR(20) NOT NULL REFERENCES account_tiers(code),
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    wallet_id     UUID,
+    fee_amount    BIGINT NOT NULL DEFAULT 0,
+    fee_reference VARCHAR(100),
+    reason        TEXT,
+    reviewed_by   UUID REFERENCES users(id),
+    review_note   TEXT,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    reviewed_at   TIMESTAMP WITH TIME ZONE,
+    CHECK (from_tier <> to_tier)
+);
+
+-- At most one open request per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tier_change_requests_one_pending
+    ON tier_change_requests(user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_tier_change_requests_status
+    ON tier_change_requests(status, created_at);
@@ -0,0 +1,22 @@
+-- KYC Provider Checks
+-- Results of PAN, Aadhaar OTP and face match checks run against the
+-- configured KYC provider. Admins review them alongside the KYC submission.
+
+CREATE TABLE IF NOT EXISTS kyc_provider_checks (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider     VARCHAR(50) NOT NULL,
+    check_type   VARCHAR(20) NOT NULL CHECK (check_type IN ('pan', 'aadhaar_otp', 'face_match')),
+    status       VARCHAR(20) NOT NULL CHECK (status IN ('passed', 'failed', 'pending', 'error')),
+    reference_id VARCHAR(100),
+    score        NUMERIC(4, 3),
+    reason       TEXT,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_provider_checks_user ON kyc_provider_checks(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_kyc_provider_checks_reference ON kyc_provider_checks(reference_id) WHERE reference_id IS NOT NULL;
+
+COMMENT ON TABLE kyc_provider_checks IS 'Identity checks run against the KYC provider';
+COMMENT ON COLUMN kyc_provider_checks.score IS 'Name or face match score between 0 and 1';
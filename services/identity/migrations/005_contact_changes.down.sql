@@ -0,0 +1 @@
+DROP TABLE IF EXISTS contact_change_requests;
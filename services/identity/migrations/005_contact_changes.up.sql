@@ -0,0 +1,23 @@
+-- Contact Changes
+-- Email and phone changes wait here until the user confirms the code sent
+-- to the new address. Only a keyed hash of the code is stored.
+
+CREATE TABLE IF NOT EXISTS contact_change_requests (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel       VARCHAR(10) NOT NULL CHECK (channel IN ('email', 'phone')),
+    new_value     VARCHAR(255) NOT NULL,
+    code_hash     VARCHAR(64) NOT NULL,
+    status        VARCHAR(20) NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'confirmed', 'expired', 'cancelled')),
+    attempt_count INT NOT NULL DEFAULT 0,
+    expires_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    confirmed_at  TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_change_requests_user
+    ON contact_change_requests(user_id, channel, created_at DESC);
+
+COMMENT ON TABLE contact_change_requests IS 'Pending and completed email/phone changes';
+COMMENT ON COLUMN contact_change_requests.code_hash IS 'HMAC-SHA256 of the verification code';
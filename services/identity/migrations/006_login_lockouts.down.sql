@@ -0,0 +1,3 @@
+-- Rollback Login Lockouts
+
+DROP TABLE IF EXISTS login_lockouts;
@@ -0,0 +1,23 @@
+-- Login Lockouts
+-- Failed password attempts per user. Repeated failures first require a CAPTCHA,
+-- then lock the account for a cooldown that doubles with each lockout. A locked
+-- account is unlocked by the emailed link, an admin, or the cooldown expiring.
+-- Only a hash of the unlock token is stored.
+
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    user_id                 UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    failed_attempts         INT NOT NULL DEFAULT 0,
+    lockout_count           INT NOT NULL DEFAULT 0,
+    locked_until            TIMESTAMP WITH TIME ZONE,
+    last_failed_at          TIMESTAMP WITH TIME ZONE,
+    unlock_token_hash       VARCHAR(64),
+    unlock_token_expires_at TIMESTAMP WITH TIME ZONE,
+    updated_at              TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_lockouts_unlock_token
+    ON login_lockouts(unlock_token_hash) WHERE unlock_token_hash IS NOT NULL;
+
+COMMENT ON TABLE login_lockouts IS 'Failed login attempts and account lockouts';
+COMMENT ON COLUMN login_lockouts.lockout_count IS 'Lockouts since the last successful login, drives the exponential cooldown';
+COMMENT ON COLUMN login_lockouts.unlock_token_hash IS 'SHA-256 of the emailed unlock token';
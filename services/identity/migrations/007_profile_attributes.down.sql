@@ -0,0 +1,3 @@
+-- Profile Attributes Rollback
+
+DROP TABLE IF EXISTS user_profile_attributes;
@@ -0,0 +1,15 @@
+-- Profile Attributes
+-- Per-user preferences such as locale, timezone, display currency, marketing
+-- consent and notification quiet hours. One row per attribute; the service
+-- validates keys and values.
+
+CREATE TABLE IF NOT EXISTS user_profile_attributes (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key        VARCHAR(50) NOT NULL,
+    value      TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+COMMENT ON TABLE user_profile_attributes IS 'User preferences, published to other services as user.preferences_updated';
+COMMENT ON COLUMN user_profile_attributes.value IS 'Normalized value; booleans are stored as true/false';
@@ -0,0 +1,11 @@
+-- KYC Review Queue Rollback
+
+DROP TABLE IF EXISTS kyc_review_decisions;
+
+DROP INDEX IF EXISTS idx_kyc_assigned_to;
+DROP INDEX IF EXISTS idx_kyc_review_queue;
+
+ALTER TABLE user_kyc
+    DROP COLUMN IF EXISTS assigned_at,
+    DROP COLUMN IF EXISTS assigned_to,
+    DROP COLUMN IF EXISTS submitted_at;
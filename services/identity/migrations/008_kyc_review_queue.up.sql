@@ -0,0 +1,40 @@
+-- KYC Review Queue
+-- Pending KYC submissions are claimed by (or assigned to) a reviewer and
+-- decided with a structured reason code. Every decision is recorded so
+-- reviewer throughput and SLA adherence can be reported.
+
+ALTER TABLE user_kyc
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS assigned_to  UUID REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS assigned_at  TIMESTAMP WITH TIME ZONE;
+
+-- Existing submissions keep their original place in the queue
+UPDATE user_kyc SET submitted_at = updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_kyc_review_queue
+    ON user_kyc(submitted_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_kyc_assigned_to
+    ON user_kyc(assigned_to) WHERE assigned_to IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS kyc_review_decisions (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewer_id  UUID NOT NULL REFERENCES users(id),
+    decision     VARCHAR(20) NOT NULL CHECK (decision IN ('approved', 'rejected')),
+    reason_code  VARCHAR(40) NOT NULL,
+    note         TEXT,
+    submitted_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    claimed_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    sla_due_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    decided_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_kyc_review_decisions_decided
+    ON kyc_review_decisions(decided_at, reviewer_id);
+CREATE INDEX IF NOT EXISTS idx_kyc_review_decisions_user
+    ON kyc_review_decisions(user_id, decided_at DESC);
+
+COMMENT ON COLUMN user_kyc.submitted_at IS 'When the current submission entered the review queue';
+COMMENT ON COLUMN user_kyc.assigned_to IS 'Reviewer working on the submission; cleared when decided or resubmitted';
+COMMENT ON TABLE kyc_review_decisions IS 'KYC approvals and rejections with reason codes, for audit and reviewer metrics';
+COMMENT ON COLUMN kyc_review_decisions.sla_due_at IS 'Review deadline of the submission, kept to report SLA breaches';
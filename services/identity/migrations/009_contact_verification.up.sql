@@ -0,0 +1,14 @@
+-- Contact Verification
+-- A user's email and phone are verified with a code sent to the address.
+-- Wallets are activated only once at least one of them is verified.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS phone_verified_at TIMESTAMP WITH TIME ZONE;
+
+-- Accounts activated before verification existed keep their wallets working
+UPDATE users SET email_verified_at = updated_at
+WHERE status = 'active' AND account_type = 'user';
+
+COMMENT ON COLUMN users.email_verified_at IS 'When the current email was verified; NULL until verified';
+COMMENT ON COLUMN users.phone_verified_at IS 'When the current phone was verified; NULL until verified';
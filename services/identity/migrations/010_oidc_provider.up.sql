@@ -0,0 +1,34 @@
+-- OIDC Provider
+-- Registered OAuth clients sign users in with "Login with Nivo" through the
+-- authorization code flow with PKCE. Only hashes of client secrets and
+-- authorization codes are stored; codes are single use.
+
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name          VARCHAR(100) NOT NULL,
+    secret_hash   VARCHAR(64),
+    redirect_uris TEXT[] NOT NULL,
+    scopes        TEXT[] NOT NULL,
+    created_by    UUID NOT NULL REFERENCES users(id),
+    created_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at    TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    code_hash      VARCHAR(64) PRIMARY KEY,
+    client_id      UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri   TEXT NOT NULL,
+    scope          TEXT NOT NULL,
+    nonce          VARCHAR(255),
+    code_challenge VARCHAR(128) NOT NULL,
+    expires_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at        TIMESTAMP WITH TIME ZONE,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE oauth_clients IS 'Applications allowed to sign users in through the OIDC provider';
+COMMENT ON COLUMN oauth_clients.secret_hash IS 'SHA-256 of the client secret; NULL for public clients, which rely on PKCE alone';
+COMMENT ON TABLE oauth_authorization_codes IS 'Authorization codes awaiting exchange at the token endpoint';
+COMMENT ON COLUMN oauth_authorization_codes.code_hash IS 'SHA-256 of the authorization code';
+COMMENT ON COLUMN oauth_authorization_codes.code_challenge IS 'PKCE S256 challenge the token request must answer';
@@ -1,25 +1,114 @@
 package main
 
 import (
+	"context"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/handler"
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 func main() {
 	server.Run(server.ServiceConfig{
 		Name: "ledger",
+		// Wallet provisions accounts; transaction looks up and reconciles entries
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/accounts":        {"wallet"},
+				"/internal/v1/journal-entries": {"transaction"},
+				"/internal/v1/verify":          {"transaction"},
+				"/internal/v1/fx":              {"transaction"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repositories
 			accountRepo := repository.NewAccountRepository(ctx.DB)
 			journalRepo := repository.NewJournalEntryRepository(ctx.DB)
+			suspenseRepo := repository.NewSuspenseRepository(ctx.DB)
+			fxRepo := repository.NewFXRepository(ctx.DB)
+			importRepo := repository.NewJournalImportRepository(ctx.DB)
+			integrityRepo := repository.NewIntegrityRepository(ctx.DB)
 
 			// Initialize services
 			ledgerService := service.NewLedgerService(accountRepo, journalRepo)
 
+			// Publish posted, voided and reversed entries for downstream consumers
+			ledgerService.SetEventPublisher(events.NewPublisher(events.PublishConfig{
+				GatewayURL:  server.GetEnv("GATEWAY_URL", "http://gateway:8000"),
+				ServiceName: "ledger",
+			}))
+
+			// Journal entry voids need a second admin's approval
+			ledgerService.SetApprovals(approval.NewManager("ledger", approval.NewPostgresStore(ctx.DB.DB)))
+
+			// Postings that cannot be booked can be parked in suspense
+			ledgerService.SetSuspense(suspenseRepo)
+
+			// Foreign-currency accounts are revalued into the base currency at period end
+			baseCurrency := sharedModels.DefaultCurrency
+			if value := os.Getenv("LEDGER_BASE_CURRENCY"); value != "" {
+				parsed, err := sharedModels.ParseCurrency(value)
+				if err != nil {
+					return nil, err
+				}
+				baseCurrency = parsed
+			}
+			ledgerService.SetFXRevaluation(fxRepo, baseCurrency)
+
+			// Revalue at the last month end once its rates are recorded; accounts
+			// already revalued are skipped, so repeated runs are harmless
+			ctx.Lifecycle.Every("fx-revaluation", time.Hour, func(workerCtx context.Context) error {
+				run, err := ledgerService.RevalueAccounts(workerCtx, service.PreviousMonthEnd(time.Now()), service.SystemUserID)
+				if err != nil {
+					return err
+				}
+				if len(run.Revalued) > 0 {
+					ctx.Logger.WithField("period_end", run.PeriodEnd).
+						WithField("revalued", len(run.Revalued)).
+						Info("Revalued foreign-currency accounts")
+				}
+				return nil
+			})
+
+			// Bulk journal imports are validated on upload and created in batches here
+			ledgerService.SetJournalImports(importRepo)
+			ctx.Lifecycle.Every("journal-imports", 5*time.Second, func(workerCtx context.Context) error {
+				processed, err := ledgerService.ProcessJournalImports(workerCtx)
+				if err != nil {
+					return err
+				}
+				if processed > 0 {
+					ctx.Logger.WithField("entries", processed).Info("Processed journal import entries")
+				}
+				return nil
+			})
+
+			// Verify double-entry integrity hourly; drift is repaired by an admin,
+			// never automatically
+			ledgerService.SetIntegrity(integrityRepo)
+			ctx.Lifecycle.Every("integrity-check", time.Hour, func(workerCtx context.Context) error {
+				report, err := ledgerService.CheckIntegrity(workerCtx)
+				if err != nil {
+					return err
+				}
+				if !report.Healthy {
+					ctx.Logger.WithField("critical", report.Counts[models.IntegritySeverityCritical]).
+						WithField("errors", report.Counts[models.IntegritySeverityError]).
+						WithField("warnings", report.Counts[models.IntegritySeverityWarning]).
+						Warn("Ledger integrity check found issues")
+				}
+				return nil
+			})
+
 			// Get JWT secret and setup router
 			jwtSecret := server.RequireEnv("JWT_SECRET")
 			router := handler.NewRouter(ledgerService, jwtSecret)
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/pagination"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// SetFXRates records FX rates for a date.
+// PUT /api/v1/fx/rates
+func (h *LedgerHandler) SetFXRates(w http.ResponseWriter, r *http.Request) {
+	createdBy, ok := middleware.GetUserID(r.Context())
+	if !ok || createdBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.SetFXRatesRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	rates, svcErr := h.ledgerService.SetFXRates(r.Context(), &req, createdBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, rates)
+}
+
+// ListFXRates lists the rates for a date, or the latest rate of each currency.
+// GET /api/v1/fx/rates?date=2025-03-31
+func (h *LedgerHandler) ListFXRates(w http.ResponseWriter, r *http.Request) {
+	date, dateErr := optionalDate(r, "date")
+	if dateErr != nil {
+		response.Error(w, dateErr)
+		return
+	}
+
+	rates, svcErr := h.ledgerService.ListFXRates(r.Context(), date)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, rates)
+}
+
+// GetCurrentFXRates returns the latest rates against the base currency (internal endpoint).
+// GET /internal/v1/fx/rates
+func (h *LedgerHandler) GetCurrentFXRates(w http.ResponseWriter, r *http.Request) {
+	table, svcErr := h.ledgerService.CurrentFXRates(r.Context())
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, table)
+}
+
+// RevalueAccounts revalues foreign-currency accounts for a period end.
+// POST /api/v1/fx/revaluations
+func (h *LedgerHandler) RevalueAccounts(w http.ResponseWriter, r *http.Request) {
+	revaluedBy, ok := middleware.GetUserID(r.Context())
+	if !ok || revaluedBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.RevalueAccountsRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	periodEnd, dateErr := service.ParseDate(req.PeriodEnd)
+	if dateErr != nil {
+		response.Error(w, dateErr)
+		return
+	}
+
+	run, svcErr := h.ledgerService.RevalueAccounts(r.Context(), periodEnd, revaluedBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, run)
+}
+
+// ListFXRevaluations lists revaluations, newest period first.
+// GET /api/v1/fx/revaluations?period_end=2025-03-31&page=1&per_page=20
+func (h *LedgerHandler) ListFXRevaluations(w http.ResponseWriter, r *http.Request) {
+	params := pagination.FromRequest(r)
+
+	periodEnd, dateErr := optionalDate(r, "period_end")
+	if dateErr != nil {
+		response.Error(w, dateErr)
+		return
+	}
+
+	revaluations, total, svcErr := h.ledgerService.ListFXRevaluations(r.Context(), periodEnd, params.PerPage, params.Offset)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Paginated(w, revaluations, params.Page, params.PerPage, total)
+}
+
+// GetBaseCurrencyBalances reports account balances in the account and base currency.
+// GET /api/v1/reports/balances?as_of=2025-03-31
+func (h *LedgerHandler) GetBaseCurrencyBalances(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now()
+	date, dateErr := optionalDate(r, "as_of")
+	if dateErr != nil {
+		response.Error(w, dateErr)
+		return
+	}
+	if date != nil {
+		asOf = *date
+	}
+
+	report, svcErr := h.ledgerService.BaseCurrencyBalances(r.Context(), asOf)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// optionalDate parses a YYYY-MM-DD query parameter, returning nil if it is absent.
+func optionalDate(r *http.Request, param string) (*time.Time, *errors.Error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := service.ParseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	stderrors "errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// maxJournalImportBytes bounds an uploaded import, which is read into memory.
+const maxJournalImportBytes = 10 << 20
+
+// ImportJournalEntries uploads journal entries for import. A text/csv body is
+// read as CSV; anything else as a JSON array of entries.
+// POST /api/v1/journal-entries/import
+func (h *LedgerHandler) ImportJournalEntries(w http.ResponseWriter, r *http.Request) {
+	createdBy, ok := middleware.GetUserID(r.Context())
+	if !ok || createdBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxJournalImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			response.Error(w, errors.PayloadTooLarge("import must be at most 10 MB"))
+			return
+		}
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	format := models.ImportFormatJSON
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/csv" {
+		format = models.ImportFormatCSV
+	}
+
+	job, svcErr := h.ledgerService.CreateJournalImport(r.Context(), format, body, createdBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, job)
+}
+
+// GetJournalImport returns an import's progress and row errors.
+// GET /api/v1/journal-entries/imports/:id
+func (h *LedgerHandler) GetJournalImport(w http.ResponseWriter, r *http.Request) {
+	job, svcErr := h.ledgerService.GetJournalImport(r.Context(), r.PathValue("id"))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, job)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/pagination"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// GetIntegrityReport returns the latest integrity report.
+// GET /api/v1/integrity/report
+func (h *LedgerHandler) GetIntegrityReport(w http.ResponseWriter, r *http.Request) {
+	report, svcErr := h.ledgerService.LatestIntegrityReport(r.Context())
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// RunIntegrityCheck checks the ledger now and returns the report.
+// POST /api/v1/integrity/checks
+func (h *LedgerHandler) RunIntegrityCheck(w http.ResponseWriter, r *http.Request) {
+	report, svcErr := h.ledgerService.CheckIntegrity(r.Context())
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// RepairIntegrity resets drifting accounts to the sum of their lines.
+// POST /api/v1/integrity/repairs
+func (h *LedgerHandler) RepairIntegrity(w http.ResponseWriter, r *http.Request) {
+	repairedBy, ok := middleware.GetUserID(r.Context())
+	if !ok || repairedBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.IntegrityRepairRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	result, svcErr := h.ledgerService.RepairIntegrity(r.Context(), &req, repairedBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// ListIntegrityRepairs lists integrity repairs, newest first.
+// GET /api/v1/integrity/repairs?page=1&per_page=20
+func (h *LedgerHandler) ListIntegrityRepairs(w http.ResponseWriter, r *http.Request) {
+	params := pagination.FromRequest(r)
+
+	repairs, total, svcErr := h.ledgerService.ListIntegrityRepairs(r.Context(), params.PerPage, params.Offset)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Paginated(w, repairs, params.Page, params.PerPage, total)
+}
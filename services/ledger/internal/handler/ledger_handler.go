@@ -8,6 +8,7 @@ import (
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
 	"github.com/1mb-dev/nivomoney/shared/response"
 )
 
@@ -86,6 +87,23 @@ func (h *LedgerHandler) GetAccountByCode(w http.ResponseWriter, r *http.Request)
 	response.OK(w, account)
 }
 
+// DeleteUnusedAccount deletes an account nothing has been posted to.
+// DELETE /internal/v1/accounts/:id
+func (h *LedgerHandler) DeleteUnusedAccount(w http.ResponseWriter, r *http.Request) {
+	accountID := r.PathValue("id")
+	if accountID == "" {
+		response.Error(w, errors.BadRequest("account ID is required"))
+		return
+	}
+
+	if svcErr := h.ledgerService.DeleteUnusedAccount(r.Context(), accountID); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // ListAccounts retrieves accounts with optional filters.
 // GET /api/v1/accounts?type=asset&status=active&limit=50&offset=0
 func (h *LedgerHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
@@ -237,6 +255,45 @@ func (h *LedgerHandler) ListJournalEntries(w http.ResponseWriter, r *http.Reques
 	response.OK(w, entries)
 }
 
+// FindJournalEntriesByReference retrieves the journal entries for a referenced entity.
+// GET /api/v1/journal-entries/by-reference?reference_type=transaction&reference_id=...
+func (h *LedgerHandler) FindJournalEntriesByReference(w http.ResponseWriter, r *http.Request) {
+	referenceType := r.URL.Query().Get("reference_type")
+	referenceID := r.URL.Query().Get("reference_id")
+
+	entries, svcErr := h.ledgerService.FindJournalEntriesByReference(r.Context(), referenceType, referenceID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, entries)
+}
+
+// VerifyReferences checks a batch of references against posted journal entries.
+// POST /api/v1/verify/references
+func (h *LedgerHandler) VerifyReferences(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.VerifyReferencesRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	report, svcErr := h.ledgerService.VerifyReferences(r.Context(), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, report)
+}
+
 // PostJournalEntry posts a draft journal entry.
 // POST /api/v1/journal-entries/:id/post
 func (h *LedgerHandler) PostJournalEntry(w http.ResponseWriter, r *http.Request) {
@@ -276,7 +333,8 @@ func (h *LedgerHandler) PostJournalEntry(w http.ResponseWriter, r *http.Request)
 	response.OK(w, entry)
 }
 
-// VoidJournalEntry voids a posted journal entry.
+// VoidJournalEntry voids a posted journal entry. When approvals are enabled the
+// void is stored for a second admin and 202 Accepted returns the approval.
 // POST /api/v1/journal-entries/:id/void
 func (h *LedgerHandler) VoidJournalEntry(w http.ResponseWriter, r *http.Request) {
 	entryID := r.PathValue("id")
@@ -305,6 +363,17 @@ func (h *LedgerHandler) VoidJournalEntry(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if h.ledgerService.Approvals() != nil {
+		requestedBy, _ := middleware.GetUserID(r.Context())
+		pending, svcErr := h.ledgerService.RequestVoidJournalEntry(r.Context(), &req, requestedBy)
+		if svcErr != nil {
+			response.Error(w, svcErr)
+			return
+		}
+		response.JSON(w, http.StatusAccepted, pending)
+		return
+	}
+
 	// Void entry
 	entry, svcErr := h.ledgerService.VoidJournalEntry(r.Context(), entryID, req.VoidedBy, req.VoidReason)
 	if svcErr != nil {
@@ -103,6 +103,14 @@ func (m *mockAccountRepository) Update(ctx context.Context, account *models.Acco
 	return nil
 }
 
+func (m *mockAccountRepository) DeleteUnused(ctx context.Context, id string) *errors.Error {
+	if _, ok := m.accounts[id]; !ok {
+		return errors.NotFound("account not found")
+	}
+	delete(m.accounts, id)
+	return nil
+}
+
 func (m *mockAccountRepository) GetBalance(ctx context.Context, accountID string) (int64, *errors.Error) {
 	if m.GetBalanceFunc != nil {
 		return m.GetBalanceFunc(ctx, accountID)
@@ -174,6 +182,30 @@ func (m *mockJournalEntryRepository) List(ctx context.Context, status *models.En
 	return result, nil
 }
 
+func (m *mockJournalEntryRepository) ListByReference(ctx context.Context, referenceType, referenceID string) ([]*models.JournalEntry, *errors.Error) {
+	result := make([]*models.JournalEntry, 0)
+	for _, entry := range m.entries {
+		if entry.ReferenceType == referenceType && entry.ReferenceID == referenceID {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockJournalEntryRepository) ListByReferences(ctx context.Context, referenceType string, referenceIDs []string) ([]*models.JournalEntry, *errors.Error) {
+	wanted := make(map[string]bool, len(referenceIDs))
+	for _, id := range referenceIDs {
+		wanted[id] = true
+	}
+	result := make([]*models.JournalEntry, 0)
+	for _, entry := range m.entries {
+		if entry.ReferenceType == referenceType && wanted[entry.ReferenceID] {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockJournalEntryRepository) Post(ctx context.Context, entryID, postedBy string) *errors.Error {
 	if m.PostFunc != nil {
 		return m.PostFunc(ctx, entryID, postedBy)
@@ -723,6 +755,102 @@ func TestLedgerHandler_ListJournalEntries(t *testing.T) {
 	})
 }
 
+func TestLedgerHandler_FindJournalEntriesByReference(t *testing.T) {
+	ledgerService, _, journalRepo := createTestLedgerService()
+	handler := NewLedgerHandler(ledgerService)
+
+	journalRepo.AddEntry(&models.JournalEntry{
+		ID:            "je-ref-1",
+		EntryNumber:   "JE-REF-001",
+		Type:          models.EntryTypeStandard,
+		Status:        models.EntryStatusPosted,
+		ReferenceType: "transaction",
+		ReferenceID:   "tx-123",
+	})
+	journalRepo.AddEntry(&models.JournalEntry{
+		ID:            "je-ref-2",
+		EntryNumber:   "JE-REF-002",
+		Type:          models.EntryTypeStandard,
+		Status:        models.EntryStatusPosted,
+		ReferenceType: "transaction",
+		ReferenceID:   "tx-456",
+	})
+
+	t.Run("returns only entries for the reference", func(t *testing.T) {
+		rec, resp := makeRequest(t, handler.FindJournalEntriesByReference, http.MethodGet, "/api/v1/journal-entries/by-reference?reference_type=transaction&reference_id=tx-123", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, resp.Success)
+
+		var entries []map[string]interface{}
+		err := json.Unmarshal(resp.Data, &entries)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "je-ref-1", entries[0]["id"])
+	})
+
+	t.Run("unknown reference returns empty list", func(t *testing.T) {
+		rec, resp := makeRequest(t, handler.FindJournalEntriesByReference, http.MethodGet, "/api/v1/journal-entries/by-reference?reference_type=transaction&reference_id=tx-none", nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, "[]", string(resp.Data))
+	})
+
+	t.Run("missing reference_id returns 400", func(t *testing.T) {
+		rec, resp := makeRequest(t, handler.FindJournalEntriesByReference, http.MethodGet, "/api/v1/journal-entries/by-reference?reference_type=transaction", nil)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.False(t, resp.Success)
+	})
+}
+
+func TestLedgerHandler_VerifyReferences(t *testing.T) {
+	ledgerService, _, journalRepo := createTestLedgerService()
+	handler := NewLedgerHandler(ledgerService)
+
+	journalRepo.AddEntry(&models.JournalEntry{
+		ID:            "je-verify-1",
+		EntryNumber:   "JE-VER-001",
+		Type:          models.EntryTypeStandard,
+		Status:        models.EntryStatusPosted,
+		ReferenceType: "transaction",
+		ReferenceID:   "tx-verified",
+		Lines: []models.LedgerLine{
+			{DebitAmount: 2500},
+			{CreditAmount: 2500},
+		},
+	})
+
+	t.Run("reports matches and discrepancies", func(t *testing.T) {
+		body := map[string]interface{}{
+			"references": []map[string]interface{}{
+				{"reference_id": "tx-verified", "amount": 2500},
+				{"reference_id": "tx-lost", "amount": 2500},
+			},
+		}
+		rec, resp := makeRequest(t, handler.VerifyReferences, http.MethodPost, "/api/v1/verify/references", body)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, resp.Success)
+
+		var report models.VerificationReport
+		require.NoError(t, json.Unmarshal(resp.Data, &report))
+		assert.Equal(t, 2, report.Checked)
+		assert.Equal(t, 1, report.Matched)
+		assert.Equal(t, 1, report.Discrepancies)
+		assert.Equal(t, 1, report.ByStatus[models.VerificationMissing])
+	})
+
+	t.Run("empty batch returns 400", func(t *testing.T) {
+		rec, resp := makeRequest(t, handler.VerifyReferences, http.MethodPost, "/api/v1/verify/references", map[string]interface{}{
+			"references": []interface{}{},
+		})
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.False(t, resp.Success)
+	})
+}
+
 func TestLedgerHandler_PostJournalEntry(t *testing.T) {
 	ledgerService, _, journalRepo := createTestLedgerService()
 	handler := NewLedgerHandler(ledgerService)
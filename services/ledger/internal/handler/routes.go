@@ -4,24 +4,38 @@ import (
 	"net/http"
 
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
 	"github.com/1mb-dev/nivomoney/shared/middleware"
 )
 
 // Router sets up HTTP routes for the Ledger Service.
 type Router struct {
-	ledgerHandler *LedgerHandler
-	jwtSecret     string
-	metrics       *metrics.Collector
+	ledgerHandler    *LedgerHandler
+	approvalHandler  *approval.Handler // nil when voids need no approval
+	suspenseEnabled  bool
+	fxEnabled        bool
+	importsEnabled   bool
+	integrityEnabled bool
+	jwtSecret        string
+	metrics          *metrics.Collector
 }
 
 // NewRouter creates a new router with all handlers.
 func NewRouter(ledgerService *service.LedgerService, jwtSecret string) *Router {
-	return &Router{
-		ledgerHandler: NewLedgerHandler(ledgerService),
-		jwtSecret:     jwtSecret,
-		metrics:       metrics.NewCollector("ledger"),
+	r := &Router{
+		ledgerHandler:    NewLedgerHandler(ledgerService),
+		jwtSecret:        jwtSecret,
+		metrics:          metrics.NewCollector("ledger"),
+		suspenseEnabled:  ledgerService.SuspenseEnabled(),
+		fxEnabled:        ledgerService.FXEnabled(),
+		importsEnabled:   ledgerService.JournalImportsEnabled(),
+		integrityEnabled: ledgerService.IntegrityEnabled(),
 	}
+	if approvals := ledgerService.Approvals(); approvals != nil {
+		r.approvalHandler = approval.NewHandler(approvals)
+	}
+	return r
 }
 
 // SetupRoutes configures all HTTP routes for the Ledger Service.
@@ -65,12 +79,28 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("POST /api/v1/journal-entries",
 		authMiddleware(middleware.RequirePermission("ledger:entry:create")(http.HandlerFunc(r.ledgerHandler.CreateJournalEntry))))
 
+	// Bulk import (e.g. migrating from a legacy ledger), processed in the background
+	if r.importsEnabled {
+		mux.Handle("POST /api/v1/journal-entries/import",
+			authMiddleware(middleware.RequirePermission("ledger:entry:create")(http.HandlerFunc(r.ledgerHandler.ImportJournalEntries))))
+
+		mux.Handle("GET /api/v1/journal-entries/imports/{id}",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetJournalImport))))
+	}
+
+	mux.Handle("GET /api/v1/journal-entries/by-reference",
+		authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.FindJournalEntriesByReference))))
+
 	mux.Handle("GET /api/v1/journal-entries/{id}",
 		authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetJournalEntry))))
 
 	mux.Handle("GET /api/v1/journal-entries",
 		authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.ListJournalEntries))))
 
+	// Referential integrity verification (ops dashboard)
+	mux.Handle("POST /api/v1/verify/references",
+		authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.VerifyReferences))))
+
 	mux.Handle("POST /api/v1/journal-entries/{id}/post",
 		authMiddleware(middleware.RequirePermission("ledger:entry:post")(http.HandlerFunc(r.ledgerHandler.PostJournalEntry))))
 
@@ -80,6 +110,80 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("POST /api/v1/journal-entries/{id}/reverse",
 		authMiddleware(middleware.RequirePermission("ledger:entry:reverse")(http.HandlerFunc(r.ledgerHandler.ReverseJournalEntry))))
 
+	// Maker-checker approvals (voids requested by one admin, decided by another)
+	if r.approvalHandler != nil {
+		reviewPermission := middleware.RequirePermission("ledger:approval:review")
+
+		mux.Handle("GET /api/v1/approvals",
+			authMiddleware(reviewPermission(http.HandlerFunc(r.approvalHandler.List))))
+
+		mux.Handle("GET /api/v1/approvals/{id}",
+			authMiddleware(reviewPermission(http.HandlerFunc(r.approvalHandler.Get))))
+
+		mux.Handle("POST /api/v1/approvals/{id}/approve",
+			authMiddleware(reviewPermission(http.HandlerFunc(r.approvalHandler.Approve))))
+
+		mux.Handle("POST /api/v1/approvals/{id}/reject",
+			authMiddleware(reviewPermission(http.HandlerFunc(r.approvalHandler.Reject))))
+	}
+
+	// Suspense account: parked postings, clearing and the aged report
+	if r.suspenseEnabled {
+		clearPermission := middleware.RequirePermission("ledger:suspense:clear")
+
+		mux.Handle("GET /api/v1/suspense",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.ListSuspenseItems))))
+
+		mux.Handle("GET /api/v1/suspense/report",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetSuspenseReport))))
+
+		mux.Handle("POST /api/v1/suspense/auto-clear",
+			authMiddleware(clearPermission(http.HandlerFunc(r.ledgerHandler.AutoClearSuspense))))
+
+		mux.Handle("GET /api/v1/suspense/{id}",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetSuspenseItem))))
+
+		mux.Handle("POST /api/v1/suspense/{id}/clear",
+			authMiddleware(clearPermission(http.HandlerFunc(r.ledgerHandler.ClearSuspenseItem))))
+	}
+
+	// FX rates, period-end revaluation and base-currency reporting
+	if r.fxEnabled {
+		fxPermission := middleware.RequirePermission("ledger:fx:manage")
+
+		mux.Handle("PUT /api/v1/fx/rates",
+			authMiddleware(fxPermission(http.HandlerFunc(r.ledgerHandler.SetFXRates))))
+
+		mux.Handle("GET /api/v1/fx/rates",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.ListFXRates))))
+
+		mux.Handle("POST /api/v1/fx/revaluations",
+			authMiddleware(fxPermission(http.HandlerFunc(r.ledgerHandler.RevalueAccounts))))
+
+		mux.Handle("GET /api/v1/fx/revaluations",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.ListFXRevaluations))))
+
+		mux.Handle("GET /api/v1/reports/balances",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetBaseCurrencyBalances))))
+	}
+
+	// Double-entry integrity: the latest report, on-demand checks and repairs of drift
+	if r.integrityEnabled {
+		repairPermission := middleware.RequirePermission("ledger:integrity:repair")
+
+		mux.Handle("GET /api/v1/integrity/report",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.GetIntegrityReport))))
+
+		mux.Handle("POST /api/v1/integrity/checks",
+			authMiddleware(viewLedgerPermission(http.HandlerFunc(r.ledgerHandler.RunIntegrityCheck))))
+
+		mux.Handle("POST /api/v1/integrity/repairs",
+			authMiddleware(repairPermission(http.HandlerFunc(r.ledgerHandler.RepairIntegrity))))
+
+		mux.Handle("GET /api/v1/integrity/repairs",
+			authMiddleware(repairPermission(http.HandlerFunc(r.ledgerHandler.ListIntegrityRepairs))))
+	}
+
 	// ========================================================================
 	// Internal Endpoints (No Authentication - Service-to-Service Only)
 	// ========================================================================
@@ -87,6 +191,18 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Internal endpoints for wallet service
 	mux.HandleFunc("POST /internal/v1/accounts", r.ledgerHandler.CreateAccountInternal)
 	mux.HandleFunc("GET /internal/v1/accounts/by-code/{code}", r.ledgerHandler.GetAccountByCode)
+	mux.HandleFunc("GET /internal/v1/accounts/{id}", r.ledgerHandler.GetAccount)
+	// Undoes a provisioning whose wallet could not be created
+	mux.HandleFunc("DELETE /internal/v1/accounts/{id}", r.ledgerHandler.DeleteUnusedAccount)
+
+	// Internal endpoint for transaction service and reconciliation jobs
+	mux.HandleFunc("GET /internal/v1/journal-entries/by-reference", r.ledgerHandler.FindJournalEntriesByReference)
+	mux.HandleFunc("POST /internal/v1/verify/references", r.ledgerHandler.VerifyReferences)
+
+	// Internal endpoint for transfer quotes in the transaction service
+	if r.fxEnabled {
+		mux.HandleFunc("GET /internal/v1/fx/rates", r.ledgerHandler.GetCurrentFXRates)
+	}
 
 	// Apply middleware chain
 	handler := r.applyMiddleware(mux)
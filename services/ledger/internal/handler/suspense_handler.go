@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/pagination"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ListSuspenseItems lists suspense items, newest first.
+// GET /api/v1/suspense?status=open&page=1&per_page=20
+func (h *LedgerHandler) ListSuspenseItems(w http.ResponseWriter, r *http.Request) {
+	params := pagination.FromRequest(r)
+
+	var status *models.SuspenseStatus
+	if statusParam := r.URL.Query().Get("status"); statusParam != "" {
+		s := models.SuspenseStatus(statusParam)
+		if s != models.SuspenseStatusOpen && s != models.SuspenseStatusCleared {
+			response.Error(w, errors.BadRequest("invalid status"))
+			return
+		}
+		status = &s
+	}
+
+	items, total, svcErr := h.ledgerService.ListSuspenseItems(r.Context(), status, params.PerPage, params.Offset)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Paginated(w, items, params.Page, params.PerPage, total)
+}
+
+// GetSuspenseItem retrieves a suspense item.
+// GET /api/v1/suspense/:id
+func (h *LedgerHandler) GetSuspenseItem(w http.ResponseWriter, r *http.Request) {
+	item, svcErr := h.ledgerService.GetSuspenseItem(r.Context(), r.PathValue("id"))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// GetSuspenseReport lists open suspense items by age and reason.
+// GET /api/v1/suspense/report
+func (h *LedgerHandler) GetSuspenseReport(w http.ResponseWriter, r *http.Request) {
+	report, svcErr := h.ledgerService.SuspenseReport(r.Context(), time.Now())
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// ClearSuspenseItem re-posts a suspense item to the correct account.
+// POST /api/v1/suspense/:id/clear
+func (h *LedgerHandler) ClearSuspenseItem(w http.ResponseWriter, r *http.Request) {
+	clearedBy, ok := middleware.GetUserID(r.Context())
+	if !ok || clearedBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.ClearSuspenseItemRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	item, svcErr := h.ledgerService.ClearSuspenseItem(r.Context(), r.PathValue("id"), &req, clearedBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, item)
+}
+
+// AutoClearSuspense clears open items whose intended account can be booked to again.
+// POST /api/v1/suspense/auto-clear
+func (h *LedgerHandler) AutoClearSuspense(w http.ResponseWriter, r *http.Request) {
+	clearedBy, ok := middleware.GetUserID(r.Context())
+	if !ok || clearedBy == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return
+	}
+
+	result, svcErr := h.ledgerService.AutoClearSuspense(r.Context(), clearedBy)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
 )
 
 // AccountType represents the type of ledger account.
@@ -37,12 +38,18 @@ type Account struct {
 	Balance     int64             `json:"balance" db:"balance"`               // Current balance in smallest unit (paise)
 	DebitTotal  int64             `json:"debit_total" db:"debit_total"`       // Lifetime debit total
 	CreditTotal int64             `json:"credit_total" db:"credit_total"`     // Lifetime credit total
+	Version     int64             `json:"version" db:"version"`               // Incremented on every balance change
 	Status      AccountStatus     `json:"status" db:"status"`
 	Metadata    map[string]string `json:"metadata,omitempty" db:"metadata"` // Additional metadata (JSONB)
 	CreatedAt   models.Timestamp  `json:"created_at" db:"created_at"`
 	UpdatedAt   models.Timestamp  `json:"updated_at" db:"updated_at"`
 }
 
+// BalanceMoney returns the account balance in the account currency.
+func (a *Account) BalanceMoney() money.Money {
+	return money.New(a.Balance, a.Currency)
+}
+
 // IsDebitNormal returns true if this account type increases with debits.
 func (a *Account) IsDebitNormal() bool {
 	return a.Type == AccountTypeAsset || a.Type == AccountTypeExpense
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+const (
+	// FXGainLossAccountCode is the chart-of-accounts code of the unrealized FX gain/loss account.
+	FXGainLossAccountCode = "4900"
+
+	// FXAdjustmentCodeSuffix is appended to a foreign-currency account's code to
+	// name its base-currency revaluation adjustment account.
+	FXAdjustmentCodeSuffix = "-FXA"
+
+	// FXRevaluationReferenceType is the reference type of revaluation entries.
+	FXRevaluationReferenceType = "fx_revaluation"
+
+	// DateFormat is the format of rate dates and period ends.
+	DateFormat = "2006-01-02"
+)
+
+// FXRate is the base-currency value of one unit of a currency on a date.
+type FXRate struct {
+	Currency  models.Currency  `json:"currency" db:"currency"`
+	RateDate  string           `json:"rate_date" db:"rate_date"` // YYYY-MM-DD
+	Rate      string           `json:"rate" db:"rate"`           // Decimal, e.g. "83.125"
+	CreatedBy *string          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt models.Timestamp `json:"created_at" db:"created_at"`
+}
+
+// SetFXRatesRequest records the rates for one date, keyed by currency.
+type SetFXRatesRequest struct {
+	RateDate string            `json:"rate_date" validate:"required,len:10"`
+	Rates    map[string]string `json:"rates" validate:"required"`
+}
+
+// FXRateTable is the latest rate of each currency against the base currency,
+// for services pricing conversions.
+type FXRateTable struct {
+	BaseCurrency models.Currency `json:"base_currency"`
+	Rates        []*FXRate       `json:"rates"`
+}
+
+// FXRevaluation records the revaluation of one account at a period end.
+type FXRevaluation struct {
+	ID                  string           `json:"id" db:"id"`
+	AccountID           string           `json:"account_id" db:"account_id"`
+	PeriodEnd           string           `json:"period_end" db:"period_end"` // YYYY-MM-DD
+	Currency            models.Currency  `json:"currency" db:"currency"`
+	Rate                string           `json:"rate" db:"rate"`
+	Balance             int64            `json:"balance" db:"balance"`
+	BaseValue           int64            `json:"base_value" db:"base_value"`
+	PreviousBaseValue   int64            `json:"previous_base_value" db:"previous_base_value"`
+	Adjustment          int64            `json:"adjustment" db:"adjustment"`
+	AdjustmentAccountID *string          `json:"adjustment_account_id,omitempty" db:"adjustment_account_id"`
+	EntryID             *string          `json:"entry_id,omitempty" db:"entry_id"`
+	CreatedBy           *string          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt           models.Timestamp `json:"created_at" db:"created_at"`
+}
+
+// RevalueAccountsRequest runs a revaluation for a period end.
+type RevalueAccountsRequest struct {
+	PeriodEnd string `json:"period_end" validate:"required,len:10"`
+}
+
+// FXRevaluationRun summarizes one revaluation pass.
+type FXRevaluationRun struct {
+	PeriodEnd    string            `json:"period_end"`
+	BaseCurrency models.Currency   `json:"base_currency"`
+	Revalued     []*FXRevaluation  `json:"revalued"`
+	Skipped      map[string]string `json:"skipped,omitempty"` // Account code to reason
+}
+
+// AccountBaseBalance is an account's balance in its own and the base currency.
+type AccountBaseBalance struct {
+	AccountID   string          `json:"account_id"`
+	Code        string          `json:"code"`
+	Name        string          `json:"name"`
+	Type        AccountType     `json:"type"`
+	Currency    models.Currency `json:"currency"`
+	Balance     int64           `json:"balance"`                // In the account currency
+	BaseBalance *int64          `json:"base_balance,omitempty"` // Nil when no rate is available
+	Rate        string          `json:"rate,omitempty"`         // Empty for base-currency accounts
+	RateDate    string          `json:"rate_date,omitempty"`
+}
+
+// BaseCurrencyBalanceReport lists account balances in both the account and base currency.
+type BaseCurrencyBalanceReport struct {
+	AsOf         string                `json:"as_of"`
+	BaseCurrency models.Currency       `json:"base_currency"`
+	Accounts     []AccountBaseBalance  `json:"accounts"`
+	TotalsByType map[AccountType]int64 `json:"totals_by_type"` // Base currency
+	MissingRates []models.Currency     `json:"missing_rates,omitempty"`
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// IntegrityIssueLimit caps the issues of each check in one report, so a
+// badly broken ledger still produces a report of bounded size.
+const IntegrityIssueLimit = 500
+
+// IntegritySeverity ranks how serious an integrity issue is.
+type IntegritySeverity string
+
+const (
+	IntegritySeverityCritical IntegritySeverity = "critical" // Money is misstated: an entry or a balance is wrong
+	IntegritySeverityError    IntegritySeverity = "error"    // Data is inconsistent but balances are not affected
+	IntegritySeverityWarning  IntegritySeverity = "warning"  // Bookkeeping oddities worth a look
+)
+
+// IntegrityCheck identifies the kind of an integrity issue.
+type IntegrityCheck string
+
+const (
+	IntegrityCheckUnbalancedEntry IntegrityCheck = "unbalanced_entry" // A booked entry's debits and credits differ
+	IntegrityCheckEmptyEntry      IntegrityCheck = "empty_entry"      // A booked entry has no lines
+	IntegrityCheckBalanceDrift    IntegrityCheck = "balance_drift"    // An account's balance differs from its lines
+	IntegrityCheckTotalsDrift     IntegrityCheck = "totals_drift"     // An account's debit or credit total differs from its lines
+	IntegrityCheckOrphanLine      IntegrityCheck = "orphan_line"      // A line's entry or account no longer exists
+)
+
+// Severity returns how serious issues found by the check are.
+func (c IntegrityCheck) Severity() IntegritySeverity {
+	switch c {
+	case IntegrityCheckUnbalancedEntry, IntegrityCheckBalanceDrift:
+		return IntegritySeverityCritical
+	case IntegrityCheckOrphanLine:
+		return IntegritySeverityError
+	default:
+		return IntegritySeverityWarning
+	}
+}
+
+// Repairable reports whether issues found by the check can be repaired
+// automatically. Drift is repaired by recomputing the account from its lines;
+// the other checks need a correcting entry or manual investigation.
+func (c IntegrityCheck) Repairable() bool {
+	return c == IntegrityCheckBalanceDrift || c == IntegrityCheckTotalsDrift
+}
+
+// IntegrityIssue is one inconsistency found by an integrity check.
+type IntegrityIssue struct {
+	Check       IntegrityCheck    `json:"check"`
+	Severity    IntegritySeverity `json:"severity"`
+	EntryID     string            `json:"entry_id,omitempty"`
+	EntryNumber string            `json:"entry_number,omitempty"`
+	AccountID   string            `json:"account_id,omitempty"`
+	AccountCode string            `json:"account_code,omitempty"`
+	LineID      string            `json:"line_id,omitempty"`
+	Expected    int64             `json:"expected"` // Amount the lines add up to
+	Actual      int64             `json:"actual"`   // Amount found instead
+	Detail      string            `json:"detail"`
+	Repairable  bool              `json:"repairable"`
+}
+
+// IntegrityReport is the result of checking the whole ledger.
+type IntegrityReport struct {
+	CheckedAt       models.Timestamp          `json:"checked_at"`
+	EntriesChecked  int64                     `json:"entries_checked"`
+	AccountsChecked int64                     `json:"accounts_checked"`
+	LinesChecked    int64                     `json:"lines_checked"`
+	Healthy         bool                      `json:"healthy"`
+	Counts          map[IntegritySeverity]int `json:"counts"`    // Issues by severity
+	Truncated       bool                      `json:"truncated"` // A check found more than IntegrityIssueLimit issues
+	Issues          []IntegrityIssue          `json:"issues"`    // Most severe first
+}
+
+// IntegrityCounts is the size of what an integrity check covers.
+type IntegrityCounts struct {
+	Entries  int64
+	Accounts int64
+	Lines    int64
+}
+
+// EntryTotals sums the lines of a booked journal entry.
+type EntryTotals struct {
+	EntryID     string
+	EntryNumber string
+	Status      EntryStatus
+	Lines       int64
+	Debits      int64
+	Credits     int64
+}
+
+// AccountTotals compares an account's stored balance and totals with the sum
+// of its lines on booked entries.
+type AccountTotals struct {
+	AccountID   string
+	AccountCode string
+	Type        AccountType
+	Balance     int64
+	DebitTotal  int64
+	CreditTotal int64
+	LineDebits  int64
+	LineCredits int64
+}
+
+// ExpectedBalance returns the balance the account's lines add up to.
+func (t *AccountTotals) ExpectedBalance() int64 {
+	account := Account{Type: t.Type}
+	if account.IsDebitNormal() {
+		return t.LineDebits - t.LineCredits
+	}
+	return t.LineCredits - t.LineDebits
+}
+
+// OrphanLine is a ledger line whose entry or account is missing.
+type OrphanLine struct {
+	LineID         string
+	EntryID        string
+	AccountID      string
+	EntryMissing   bool
+	AccountMissing bool
+}
+
+// IntegrityRepairRequest asks for drifting accounts to be recomputed from their lines.
+type IntegrityRepairRequest struct {
+	AccountIDs []string `json:"account_ids,omitempty"` // Empty repairs every drifting account
+	Reason     string   `json:"reason" validate:"required,max:500"`
+	DryRun     bool     `json:"dry_run,omitempty"` // Report the repairs without making them
+}
+
+// AccountRepair records an account's balance and totals being reset to the
+// sum of its lines.
+type AccountRepair struct {
+	ID                  string           `json:"id,omitempty" db:"id"` // Empty on a dry run
+	AccountID           string           `json:"account_id" db:"account_id"`
+	AccountCode         string           `json:"account_code" db:"account_code"`
+	PreviousBalance     int64            `json:"previous_balance" db:"previous_balance"`
+	PreviousDebitTotal  int64            `json:"previous_debit_total" db:"previous_debit_total"`
+	PreviousCreditTotal int64            `json:"previous_credit_total" db:"previous_credit_total"`
+	Balance             int64            `json:"balance" db:"balance"`
+	DebitTotal          int64            `json:"debit_total" db:"debit_total"`
+	CreditTotal         int64            `json:"credit_total" db:"credit_total"`
+	Reason              string           `json:"reason" db:"reason"`
+	RepairedBy          string           `json:"repaired_by" db:"repaired_by"`
+	CreatedAt           models.Timestamp `json:"created_at" db:"created_at"`
+}
+
+// IntegrityRepairResult lists the repairs made and the issues that still
+// need manual correction.
+type IntegrityRepairResult struct {
+	DryRun       bool             `json:"dry_run"`
+	Repaired     []*AccountRepair `json:"repaired"`
+	Unrepairable []IntegrityIssue `json:"unrepairable"`
+}
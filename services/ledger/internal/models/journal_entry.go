@@ -120,6 +120,10 @@ type CreateJournalEntryRequest struct {
 	ReferenceID   string            `json:"reference_id,omitempty" validate:"omitempty,max:100"`
 	Lines         []LedgerLineInput `json:"lines" validate:"required,min:2,dive"`
 	MetadataRaw   json.RawMessage   `json:"metadata,omitempty" validate:"-"` // Raw JSON, parsed via GetMetadata()
+
+	// UseSuspense parks lines for unknown or inactive accounts, and any
+	// imbalance, in the suspense account instead of rejecting the entry.
+	UseSuspense bool `json:"use_suspense,omitempty"`
 }
 
 // GetMetadata parses and returns the metadata map.
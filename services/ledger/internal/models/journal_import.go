@@ -0,0 +1,62 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ImportIDMetadataKey and ImportRowMetadataKey are added to the
+// metadata of every imported entry, so it can be traced back to its upload.
+const (
+	ImportIDMetadataKey  = "import_id"
+	ImportRowMetadataKey = "import_row"
+)
+
+// ImportFormat is the format of an uploaded journal import.
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"
+	ImportFormatJSON ImportFormat = "json"
+)
+
+// ImportStatus represents the status of a journal import.
+type ImportStatus string
+
+const (
+	ImportStatusPending    ImportStatus = "pending"    // Waiting for the import job
+	ImportStatusProcessing ImportStatus = "processing" // Entries are being created in batches
+	ImportStatusCompleted  ImportStatus = "completed"  // Every entry was imported or failed
+)
+
+// JournalImport is a bulk upload of journal entries and its progress.
+type JournalImport struct {
+	ID              string            `json:"id" db:"id"`
+	Format          ImportFormat      `json:"format" db:"format"`
+	Status          ImportStatus      `json:"status" db:"status"`
+	TotalEntries    int               `json:"total_entries" db:"total_entries"`
+	ImportedEntries int               `json:"imported_entries" db:"imported_entries"`
+	FailedEntries   int               `json:"failed_entries" db:"failed_entries"` // Includes entries rejected on upload
+	NextEntry       int               `json:"-" db:"next_entry"`                  // Index of the next queued entry
+	CreatedBy       string            `json:"created_by" db:"created_by"`
+	StartedAt       *models.Timestamp `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt     *models.Timestamp `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt       models.Timestamp  `json:"created_at" db:"created_at"`
+	UpdatedAt       models.Timestamp  `json:"updated_at" db:"updated_at"`
+
+	// Rows that could not be imported (loaded separately)
+	Errors []JournalImportError `json:"errors" db:"-"`
+}
+
+// JournalImportError reports an entry that was rejected on upload or failed
+// to import.
+type JournalImportError struct {
+	Row         int    `json:"row" db:"row_number"` // CSV line of the entry's first row, or JSON array position (1-based)
+	ReferenceID string `json:"reference_id,omitempty" db:"reference_id"`
+	Message     string `json:"message" db:"message"`
+}
+
+// JournalImportEntry is a validated entry queued for import.
+type JournalImportEntry struct {
+	Row   int                       `json:"row"`
+	Entry CreateJournalEntryRequest `json:"entry"`
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// SuspenseAccountCode is the chart-of-accounts code of the suspense account.
+const SuspenseAccountCode = "2900"
+
+// SuspenseStatus is the state of a suspense item.
+type SuspenseStatus string
+
+const (
+	SuspenseStatusOpen    SuspenseStatus = "open"    // Sitting in the suspense account
+	SuspenseStatusCleared SuspenseStatus = "cleared" // Re-posted to the correct account
+)
+
+// SuspenseReason explains why an amount was parked in suspense.
+type SuspenseReason string
+
+const (
+	SuspenseReasonUnknownAccount  SuspenseReason = "unknown_account"  // The line's account does not exist
+	SuspenseReasonInactiveAccount SuspenseReason = "inactive_account" // The line's account is inactive or closed
+	SuspenseReasonUnbalanced      SuspenseReason = "unbalanced"       // Debits and credits did not match
+)
+
+// SuspenseItem is an amount booked to the suspense account instead of the
+// account it was meant for. Exactly one of DebitAmount and CreditAmount is set,
+// matching the side of the suspense account the amount sits on.
+type SuspenseItem struct {
+	ID                string            `json:"id" db:"id"`
+	EntryID           string            `json:"entry_id" db:"entry_id"` // Entry that parked the amount
+	ReferenceType     string            `json:"reference_type,omitempty" db:"reference_type"`
+	ReferenceID       string            `json:"reference_id,omitempty" db:"reference_id"`
+	IntendedAccountID *string           `json:"intended_account_id,omitempty" db:"intended_account_id"`
+	DebitAmount       int64             `json:"debit_amount" db:"debit_amount"`
+	CreditAmount      int64             `json:"credit_amount" db:"credit_amount"`
+	Reason            SuspenseReason    `json:"reason" db:"reason"`
+	Detail            string            `json:"detail" db:"detail"`
+	Status            SuspenseStatus    `json:"status" db:"status"`
+	ClearedAccountID  *string           `json:"cleared_account_id,omitempty" db:"cleared_account_id"`
+	ClearedEntryID    *string           `json:"cleared_entry_id,omitempty" db:"cleared_entry_id"`
+	ClearedBy         *string           `json:"cleared_by,omitempty" db:"cleared_by"`
+	ClearedAt         *models.Timestamp `json:"cleared_at,omitempty" db:"cleared_at"`
+	CreatedAt         models.Timestamp  `json:"created_at" db:"created_at"`
+}
+
+// Amount returns the non-zero amount (either debit or credit).
+func (i *SuspenseItem) Amount() int64 {
+	if i.DebitAmount > 0 {
+		return i.DebitAmount
+	}
+	return i.CreditAmount
+}
+
+// ClearSuspenseItemRequest re-posts a suspense item to the correct account.
+type ClearSuspenseItemRequest struct {
+	AccountID   string `json:"account_id" validate:"required,uuid"`
+	Description string `json:"description,omitempty" validate:"omitempty,max:500"`
+}
+
+// AutoClearResult summarizes a pass over open items whose intended account
+// can now be booked to.
+type AutoClearResult struct {
+	Checked int               `json:"checked"`
+	Cleared []*SuspenseItem   `json:"cleared"`
+	Failed  map[string]string `json:"failed,omitempty"` // Item ID to error
+}
+
+// SuspenseAgeBucket groups open items by how long they have been in suspense.
+type SuspenseAgeBucket struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days,omitempty"` // Exclusive; 0 for the last, open-ended bucket
+	Count   int    `json:"count"`
+	Amount  int64  `json:"amount"`
+}
+
+// SuspenseReasonSummary totals open items with one reason.
+type SuspenseReasonSummary struct {
+	Count  int   `json:"count"`
+	Amount int64 `json:"amount"`
+}
+
+// AgedSuspenseItem is an open item with its age.
+type AgedSuspenseItem struct {
+	*SuspenseItem
+	AgeDays int `json:"age_days"`
+}
+
+// SuspenseReport lists open suspense items by age and reason.
+type SuspenseReport struct {
+	AsOf         models.Timestamp                         `json:"as_of"`
+	OpenItems    int                                      `json:"open_items"`
+	TotalDebits  int64                                    `json:"total_debits"`
+	TotalCredits int64                                    `json:"total_credits"`
+	Buckets      []SuspenseAgeBucket                      `json:"buckets"`
+	ByReason     map[SuspenseReason]SuspenseReasonSummary `json:"by_reason"`
+	Items        []AgedSuspenseItem                       `json:"items"` // Oldest first
+}
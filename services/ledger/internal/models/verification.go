@@ -0,0 +1,59 @@
+package models
+
+// MaxVerificationBatch caps how many references one verification call checks.
+const MaxVerificationBatch = 500
+
+// VerificationStatus is the outcome of checking one reference against the ledger.
+type VerificationStatus string
+
+const (
+	VerificationMatched        VerificationStatus = "matched"         // One posted, balanced entry for the expected amount
+	VerificationMissing        VerificationStatus = "missing"         // No journal entry references it
+	VerificationUnposted       VerificationStatus = "unposted"        // Only draft entries exist
+	VerificationReversed       VerificationStatus = "reversed"        // Entries exist but were voided or reversed
+	VerificationUnbalanced     VerificationStatus = "unbalanced"      // A posted entry's debits and credits differ
+	VerificationAmountMismatch VerificationStatus = "amount_mismatch" // Posted amount differs from the expected amount
+	VerificationDuplicate      VerificationStatus = "duplicate"       // More than one posted entry
+)
+
+// ReferenceExpectation is a reference the caller expects to find in the ledger.
+type ReferenceExpectation struct {
+	ReferenceID string `json:"reference_id" validate:"required,max=100"`
+	Amount      int64  `json:"amount" validate:"gt=0"` // Expected posted amount in paise
+}
+
+// VerifyReferencesRequest asks the ledger to confirm a batch of references.
+type VerifyReferencesRequest struct {
+	ReferenceType     string                 `json:"reference_type,omitempty" validate:"omitempty,max=50"` // Defaults to "transaction"
+	References        []ReferenceExpectation `json:"references" validate:"required,min=1"`
+	DiscrepanciesOnly bool                   `json:"discrepancies_only,omitempty"`
+}
+
+// VerifiedEntry summarizes a journal entry found for a reference, for drill-down.
+type VerifiedEntry struct {
+	ID           string      `json:"id"`
+	EntryNumber  string      `json:"entry_number"`
+	Status       EntryStatus `json:"status"`
+	TotalDebits  int64       `json:"total_debits"`
+	TotalCredits int64       `json:"total_credits"`
+}
+
+// ReferenceVerification is the result for one reference.
+type ReferenceVerification struct {
+	ReferenceID    string             `json:"reference_id"`
+	Status         VerificationStatus `json:"status"`
+	ExpectedAmount int64              `json:"expected_amount"`
+	PostedAmount   int64              `json:"posted_amount"`
+	Detail         string             `json:"detail,omitempty"`
+	Entries        []VerifiedEntry    `json:"entries,omitempty"`
+}
+
+// VerificationReport summarizes a verification batch.
+type VerificationReport struct {
+	ReferenceType string                     `json:"reference_type"`
+	Checked       int                        `json:"checked"`
+	Matched       int                        `json:"matched"`
+	Discrepancies int                        `json:"discrepancies"`
+	ByStatus      map[VerificationStatus]int `json:"by_status"`
+	Results       []*ReferenceVerification   `json:"results"`
+}
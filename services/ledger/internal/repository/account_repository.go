@@ -31,7 +31,7 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 	query := `
 		INSERT INTO accounts (code, name, type, currency, parent_id, status, metadata)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, balance, debit_total, credit_total, created_at, updated_at
+		RETURNING id, balance, debit_total, credit_total, version, created_at, updated_at
 	`
 
 	scanErr := r.db.QueryRowContext(ctx, query,
@@ -47,6 +47,7 @@ func (r *AccountRepository) Create(ctx context.Context, account *models.Account)
 		&account.Balance,
 		&account.DebitTotal,
 		&account.CreditTotal,
+		&account.Version,
 		&account.CreatedAt,
 		&account.UpdatedAt,
 	)
@@ -68,7 +69,7 @@ func (r *AccountRepository) GetByID(ctx context.Context, id string) (*models.Acc
 
 	query := `
 		SELECT id, code, name, type, currency, parent_id, balance, debit_total,
-		       credit_total, status, metadata, created_at, updated_at
+		       credit_total, version, status, metadata, created_at, updated_at
 		FROM accounts
 		WHERE id = $1
 	`
@@ -83,6 +84,7 @@ func (r *AccountRepository) GetByID(ctx context.Context, id string) (*models.Acc
 		&account.Balance,
 		&account.DebitTotal,
 		&account.CreditTotal,
+		&account.Version,
 		&account.Status,
 		&metadataJSON,
 		&account.CreatedAt,
@@ -113,7 +115,7 @@ func (r *AccountRepository) GetByCode(ctx context.Context, code string) (*models
 
 	query := `
 		SELECT id, code, name, type, currency, parent_id, balance, debit_total,
-		       credit_total, status, metadata, created_at, updated_at
+		       credit_total, version, status, metadata, created_at, updated_at
 		FROM accounts
 		WHERE code = $1
 	`
@@ -128,6 +130,7 @@ func (r *AccountRepository) GetByCode(ctx context.Context, code string) (*models
 		&account.Balance,
 		&account.DebitTotal,
 		&account.CreditTotal,
+		&account.Version,
 		&account.Status,
 		&metadataJSON,
 		&account.CreatedAt,
@@ -155,7 +158,7 @@ func (r *AccountRepository) GetByCode(ctx context.Context, code string) (*models
 func (r *AccountRepository) List(ctx context.Context, accountType *models.AccountType, status *models.AccountStatus, limit, offset int) ([]*models.Account, *errors.Error) {
 	query := `
 		SELECT id, code, name, type, currency, parent_id, balance, debit_total,
-		       credit_total, status, metadata, created_at, updated_at
+		       credit_total, version, status, metadata, created_at, updated_at
 		FROM accounts
 		WHERE 1=1
 	`
@@ -199,6 +202,7 @@ func (r *AccountRepository) List(ctx context.Context, accountType *models.Accoun
 			&account.Balance,
 			&account.DebitTotal,
 			&account.CreditTotal,
+			&account.Version,
 			&account.Status,
 			&metadataJSON,
 			&account.CreatedAt,
@@ -257,6 +261,30 @@ func (r *AccountRepository) Update(ctx context.Context, account *models.Account)
 	return nil
 }
 
+// DeleteUnused deletes an account that has no ledger lines or other records
+// referring to it.
+func (r *AccountRepository) DeleteUnused(ctx context.Context, id string) *errors.Error {
+	query := `DELETE FROM accounts WHERE id = $1 AND debit_total = 0 AND credit_total = 0`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if database.IsForeignKeyViolation(err) {
+			return errors.Conflict("account is referenced by ledger records and cannot be deleted")
+		}
+		return errors.DatabaseWrap(err, "failed to delete account")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	if rows == 0 {
+		return errors.Conflict("account not found or has postings")
+	}
+
+	return nil
+}
+
 // GetBalance retrieves the current balance of an account.
 func (r *AccountRepository) GetBalance(ctx context.Context, accountID string) (int64, *errors.Error) {
 	var balance int64
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const fxRevaluationColumns = `
+	id, account_id, period_end, currency, rate, balance, base_value, previous_base_value,
+	adjustment, adjustment_account_id, entry_id, created_by, created_at
+`
+
+// FXRepository handles database operations for FX rates and revaluations.
+type FXRepository struct {
+	db *database.DB
+}
+
+// NewFXRepository creates a new FX repository.
+func NewFXRepository(db *database.DB) *FXRepository {
+	return &FXRepository{db: db}
+}
+
+// SetRates records the rates for a date, replacing any already recorded.
+func (r *FXRepository) SetRates(ctx context.Context, rateDate time.Time, rates []*models.FXRate, createdBy string) *errors.Error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO fx_rates (currency, rate_date, rate, created_by)
+			VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+			ON CONFLICT (currency, rate_date)
+			DO UPDATE SET rate = EXCLUDED.rate, created_by = EXCLUDED.created_by, created_at = NOW()
+			RETURNING created_at
+		`
+		for _, rate := range rates {
+			err := tx.QueryRowContext(ctx, query, rate.Currency, rateDate, rate.Rate, createdBy).Scan(&rate.CreatedAt)
+			if err != nil {
+				return errors.DatabaseWrap(err, "failed to save fx rate")
+			}
+			rate.RateDate = rateDate.Format(models.DateFormat)
+			if createdBy != "" {
+				rate.CreatedBy = &createdBy
+			}
+		}
+		return nil
+	})
+	return transactionError(err)
+}
+
+// ListRates retrieves the rates recorded for a date.
+func (r *FXRepository) ListRates(ctx context.Context, rateDate time.Time) ([]*models.FXRate, *errors.Error) {
+	query := `
+		SELECT currency, rate_date, rate, created_by, created_at
+		FROM fx_rates
+		WHERE rate_date = $1
+		ORDER BY currency
+	`
+	return r.queryRates(ctx, query, rateDate)
+}
+
+// RatesAsOf retrieves the latest rate on or before date for each currency.
+func (r *FXRepository) RatesAsOf(ctx context.Context, date time.Time) ([]*models.FXRate, *errors.Error) {
+	query := `
+		SELECT DISTINCT ON (currency) currency, rate_date, rate, created_by, created_at
+		FROM fx_rates
+		WHERE rate_date <= $1
+		ORDER BY currency, rate_date DESC
+	`
+	return r.queryRates(ctx, query, date)
+}
+
+func (r *FXRepository) queryRates(ctx context.Context, query string, args ...interface{}) ([]*models.FXRate, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list fx rates")
+	}
+	defer func() { _ = rows.Close() }()
+
+	rates := make([]*models.FXRate, 0)
+	for rows.Next() {
+		rate := &models.FXRate{}
+		var rateDate time.Time
+		if err := rows.Scan(&rate.Currency, &rateDate, &rate.Rate, &rate.CreatedBy, &rate.CreatedAt); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan fx rate")
+		}
+		rate.RateDate = rateDate.Format(models.DateFormat)
+		rate.Rate = trimDecimal(rate.Rate)
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating fx rates")
+	}
+	return rates, nil
+}
+
+func scanFXRevaluation(row rowScanner) (*models.FXRevaluation, error) {
+	rev := &models.FXRevaluation{}
+	var periodEnd time.Time
+	err := row.Scan(
+		&rev.ID, &rev.AccountID, &periodEnd, &rev.Currency, &rev.Rate, &rev.Balance, &rev.BaseValue, &rev.PreviousBaseValue,
+		&rev.Adjustment, &rev.AdjustmentAccountID, &rev.EntryID, &rev.CreatedBy, &rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	rev.PeriodEnd = periodEnd.Format(models.DateFormat)
+	rev.Rate = trimDecimal(rev.Rate)
+	return rev, nil
+}
+
+// LatestRevaluation retrieves an account's most recent revaluation.
+func (r *FXRepository) LatestRevaluation(ctx context.Context, accountID string) (*models.FXRevaluation, *errors.Error) {
+	query := `SELECT ` + fxRevaluationColumns + ` FROM fx_revaluations
+		WHERE account_id = $1 ORDER BY period_end DESC LIMIT 1`
+
+	rev, err := scanFXRevaluation(r.db.QueryRowContext(ctx, query, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("account has not been revalued")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get fx revaluation")
+	}
+	return rev, nil
+}
+
+// ClaimRevaluation records a revaluation before its entry is posted, so two
+// runs cannot revalue the same account for the same period.
+func (r *FXRepository) ClaimRevaluation(ctx context.Context, rev *models.FXRevaluation) *errors.Error {
+	query := `
+		INSERT INTO fx_revaluations (account_id, period_end, currency, rate, balance, base_value,
+		                             previous_base_value, adjustment, adjustment_account_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::uuid)
+		ON CONFLICT (account_id, period_end) DO NOTHING
+		RETURNING id, created_at
+	`
+	createdBy := ""
+	if rev.CreatedBy != nil {
+		createdBy = *rev.CreatedBy
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		rev.AccountID,
+		rev.PeriodEnd,
+		rev.Currency,
+		rev.Rate,
+		rev.Balance,
+		rev.BaseValue,
+		rev.PreviousBaseValue,
+		rev.Adjustment,
+		rev.AdjustmentAccountID,
+		createdBy,
+	).Scan(&rev.ID, &rev.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Conflict(fmt.Sprintf("account already revalued for %s", rev.PeriodEnd))
+		}
+		return errors.DatabaseWrap(err, "failed to create fx revaluation")
+	}
+	return nil
+}
+
+// SetRevaluationEntry records the adjusting entry of a claimed revaluation.
+func (r *FXRepository) SetRevaluationEntry(ctx context.Context, id, entryID string) *errors.Error {
+	_, err := r.db.ExecContext(ctx, `UPDATE fx_revaluations SET entry_id = $2 WHERE id = $1`, id, entryID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record revaluation entry")
+	}
+	return nil
+}
+
+// DeleteRevaluation removes a claimed revaluation whose entry could not be posted.
+func (r *FXRepository) DeleteRevaluation(ctx context.Context, id string) *errors.Error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM fx_revaluations WHERE id = $1 AND entry_id IS NULL`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete fx revaluation")
+	}
+	return nil
+}
+
+// ListRevaluations retrieves revaluations, newest period first, with the total count.
+func (r *FXRepository) ListRevaluations(ctx context.Context, periodEnd *time.Time, limit, offset int) ([]*models.FXRevaluation, int64, *errors.Error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if periodEnd != nil {
+		args = append(args, *periodEnd)
+		where += fmt.Sprintf(" AND period_end = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM fx_revaluations"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count fx revaluations")
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT ` + fxRevaluationColumns + ` FROM fx_revaluations` + where +
+		fmt.Sprintf(" ORDER BY period_end DESC, created_at DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list fx revaluations")
+	}
+	defer func() { _ = rows.Close() }()
+
+	revaluations := make([]*models.FXRevaluation, 0)
+	for rows.Next() {
+		rev, err := scanFXRevaluation(rows)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan fx revaluation")
+		}
+		revaluations = append(revaluations, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "error iterating fx revaluations")
+	}
+	return revaluations, total, nil
+}
+
+// trimDecimal drops the trailing zeros NUMERIC columns are padded with.
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
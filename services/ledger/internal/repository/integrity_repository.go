@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// bookedStatuses are the entry statuses whose lines have moved balances.
+// Posting applies an entry to its accounts; voiding and reversing leave those
+// balances in place, so voided and reversed entries still count.
+const bookedStatuses = `('posted', 'voided', 'reversed')`
+
+// accountLineTotals sums each account's lines on booked entries.
+const accountLineTotals = `
+	WITH line_totals AS (
+		SELECT ll.account_id,
+		       SUM(ll.debit_amount) AS debits,
+		       SUM(ll.credit_amount) AS credits
+		FROM ledger_lines ll
+		JOIN journal_entries je ON je.id = ll.entry_id
+		WHERE je.status IN ` + bookedStatuses + `
+		GROUP BY ll.account_id
+	),
+	totals AS (
+		SELECT a.id, a.code, a.type, a.balance, a.debit_total, a.credit_total,
+		       COALESCE(lt.debits, 0) AS debits,
+		       COALESCE(lt.credits, 0) AS credits
+		FROM accounts a
+		LEFT JOIN line_totals lt ON lt.account_id = a.id
+	)
+`
+
+// IntegrityRepository runs the queries behind the ledger integrity check and
+// repairs drifting accounts.
+type IntegrityRepository struct {
+	db *database.DB
+}
+
+// NewIntegrityRepository creates a new integrity repository.
+func NewIntegrityRepository(db *database.DB) *IntegrityRepository {
+	return &IntegrityRepository{db: db}
+}
+
+// Counts returns how many booked entries, accounts and lines there are.
+func (r *IntegrityRepository) Counts(ctx context.Context) (*models.IntegrityCounts, *errors.Error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM journal_entries WHERE status IN ` + bookedStatuses + `),
+			(SELECT COUNT(*) FROM accounts),
+			(SELECT COUNT(*) FROM ledger_lines)
+	`
+
+	counts := &models.IntegrityCounts{}
+	if err := r.db.QueryRowContext(ctx, query).Scan(&counts.Entries, &counts.Accounts, &counts.Lines); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to count ledger records")
+	}
+	return counts, nil
+}
+
+// UnbalancedEntries returns booked entries whose debits and credits differ or
+// that have no lines.
+func (r *IntegrityRepository) UnbalancedEntries(ctx context.Context, limit int) ([]*models.EntryTotals, *errors.Error) {
+	query := `
+		SELECT je.id, je.entry_number, je.status, COUNT(ll.id),
+		       COALESCE(SUM(ll.debit_amount), 0), COALESCE(SUM(ll.credit_amount), 0)
+		FROM journal_entries je
+		LEFT JOIN ledger_lines ll ON ll.entry_id = je.id
+		WHERE je.status IN ` + bookedStatuses + `
+		GROUP BY je.id, je.entry_number, je.status
+		HAVING COUNT(ll.id) = 0
+		    OR COALESCE(SUM(ll.debit_amount), 0) <> COALESCE(SUM(ll.credit_amount), 0)
+		ORDER BY je.entry_number
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check journal entries")
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]*models.EntryTotals, 0)
+	for rows.Next() {
+		entry := &models.EntryTotals{}
+		if err := rows.Scan(&entry.EntryID, &entry.EntryNumber, &entry.Status, &entry.Lines, &entry.Debits, &entry.Credits); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan journal entry totals")
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check journal entries")
+	}
+	return entries, nil
+}
+
+// DriftingAccounts returns accounts whose balance, debit total or credit total
+// differs from the sum of their lines on booked entries.
+func (r *IntegrityRepository) DriftingAccounts(ctx context.Context, limit int) ([]*models.AccountTotals, *errors.Error) {
+	query := accountLineTotals + `
+		SELECT id, code, type, balance, debit_total, credit_total, debits, credits
+		FROM totals
+		WHERE debit_total <> debits
+		   OR credit_total <> credits
+		   OR balance <> CASE WHEN type IN ('asset', 'expense') THEN debits - credits ELSE credits - debits END
+		ORDER BY code
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check account balances")
+	}
+	defer func() { _ = rows.Close() }()
+
+	accounts := make([]*models.AccountTotals, 0)
+	for rows.Next() {
+		account := &models.AccountTotals{}
+		err := rows.Scan(
+			&account.AccountID, &account.AccountCode, &account.Type,
+			&account.Balance, &account.DebitTotal, &account.CreditTotal,
+			&account.LineDebits, &account.LineCredits,
+		)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan account totals")
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check account balances")
+	}
+	return accounts, nil
+}
+
+// OrphanLines returns lines whose entry or account no longer exists. Foreign
+// keys normally prevent these; they appear after restores or manual edits
+// made with constraints disabled.
+func (r *IntegrityRepository) OrphanLines(ctx context.Context, limit int) ([]*models.OrphanLine, *errors.Error) {
+	query := `
+		SELECT ll.id, ll.entry_id, ll.account_id, je.id IS NULL, a.id IS NULL
+		FROM ledger_lines ll
+		LEFT JOIN journal_entries je ON je.id = ll.entry_id
+		LEFT JOIN accounts a ON a.id = ll.account_id
+		WHERE je.id IS NULL OR a.id IS NULL
+		ORDER BY ll.id
+		LIMIT $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check ledger lines")
+	}
+	defer func() { _ = rows.Close() }()
+
+	lines := make([]*models.OrphanLine, 0)
+	for rows.Next() {
+		line := &models.OrphanLine{}
+		if err := rows.Scan(&line.LineID, &line.EntryID, &line.AccountID, &line.EntryMissing, &line.AccountMissing); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan ledger line")
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to check ledger lines")
+	}
+	return lines, nil
+}
+
+// RepairAccount resets an account's balance and totals to the sum of its
+// lines and records the repair. The account is locked before its lines are
+// summed, so postings that commit meanwhile are either counted or wait. It
+// returns nil when the account no longer drifts.
+func (r *IntegrityRepository) RepairAccount(ctx context.Context, accountID, reason, repairedBy string) (*models.AccountRepair, *errors.Error) {
+	var repair *models.AccountRepair
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE`, accountID); err != nil {
+			return errors.DatabaseWrap(err, "failed to lock account")
+		}
+
+		totals := &models.AccountTotals{}
+		query := accountLineTotals + `
+			SELECT id, code, type, balance, debit_total, credit_total, debits, credits
+			FROM totals
+			WHERE id = $1
+		`
+		err := tx.QueryRowContext(ctx, query, accountID).Scan(
+			&totals.AccountID, &totals.AccountCode, &totals.Type,
+			&totals.Balance, &totals.DebitTotal, &totals.CreditTotal,
+			&totals.LineDebits, &totals.LineCredits,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return errors.NotFoundWithID("account", accountID)
+			}
+			return errors.DatabaseWrap(err, "failed to sum account lines")
+		}
+
+		balance := totals.ExpectedBalance()
+		if balance == totals.Balance && totals.LineDebits == totals.DebitTotal && totals.LineCredits == totals.CreditTotal {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE accounts
+			SET balance = $2, debit_total = $3, credit_total = $4, version = version + 1, updated_at = NOW()
+			WHERE id = $1
+		`, accountID, balance, totals.LineDebits, totals.LineCredits)
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to repair account")
+		}
+
+		repair = &models.AccountRepair{
+			AccountID:           accountID,
+			AccountCode:         totals.AccountCode,
+			PreviousBalance:     totals.Balance,
+			PreviousDebitTotal:  totals.DebitTotal,
+			PreviousCreditTotal: totals.CreditTotal,
+			Balance:             balance,
+			DebitTotal:          totals.LineDebits,
+			CreditTotal:         totals.LineCredits,
+			Reason:              reason,
+			RepairedBy:          repairedBy,
+		}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO ledger_integrity_repairs (account_id, previous_balance, previous_debit_total, previous_credit_total,
+			                                      balance, debit_total, credit_total, reason, repaired_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, created_at
+		`,
+			repair.AccountID, repair.PreviousBalance, repair.PreviousDebitTotal, repair.PreviousCreditTotal,
+			repair.Balance, repair.DebitTotal, repair.CreditTotal, repair.Reason, repair.RepairedBy,
+		).Scan(&repair.ID, &repair.CreatedAt)
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to record integrity repair")
+		}
+		return nil
+	})
+	if txErr := transactionError(err); txErr != nil {
+		return nil, txErr
+	}
+	return repair, nil
+}
+
+// ListRepairs retrieves integrity repairs, newest first, with the total count.
+func (r *IntegrityRepository) ListRepairs(ctx context.Context, limit, offset int) ([]*models.AccountRepair, int64, *errors.Error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ledger_integrity_repairs`).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count integrity repairs")
+	}
+
+	query := `
+		SELECT r.id, r.account_id, a.code, r.previous_balance, r.previous_debit_total, r.previous_credit_total,
+		       r.balance, r.debit_total, r.credit_total, r.reason, r.repaired_by, r.created_at
+		FROM ledger_integrity_repairs r
+		JOIN accounts a ON a.id = r.account_id
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list integrity repairs")
+	}
+	defer func() { _ = rows.Close() }()
+
+	repairs := make([]*models.AccountRepair, 0)
+	for rows.Next() {
+		repair := &models.AccountRepair{}
+		err := rows.Scan(
+			&repair.ID, &repair.AccountID, &repair.AccountCode,
+			&repair.PreviousBalance, &repair.PreviousDebitTotal, &repair.PreviousCreditTotal,
+			&repair.Balance, &repair.DebitTotal, &repair.CreditTotal,
+			&repair.Reason, &repair.RepairedBy, &repair.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan integrity repair")
+		}
+		repairs = append(repairs, repair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list integrity repairs")
+	}
+	return repairs, total, nil
+}
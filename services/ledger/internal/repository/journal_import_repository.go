@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const journalImportColumns = `
+	id, format, status, total_entries, imported_entries, failed_entries, next_entry,
+	created_by, started_at, completed_at, created_at, updated_at
+`
+
+// JournalImportRepository handles database operations for journal imports.
+type JournalImportRepository struct {
+	db *database.DB
+}
+
+// NewJournalImportRepository creates a new journal import repository.
+func NewJournalImportRepository(db *database.DB) *JournalImportRepository {
+	return &JournalImportRepository{db: db}
+}
+
+func scanJournalImport(row rowScanner) (*models.JournalImport, error) {
+	job := &models.JournalImport{}
+	err := row.Scan(
+		&job.ID, &job.Format, &job.Status, &job.TotalEntries, &job.ImportedEntries, &job.FailedEntries, &job.NextEntry,
+		&job.CreatedBy, &job.StartedAt, &job.CompletedAt, &job.CreatedAt, &job.UpdatedAt,
+	)
+	return job, err
+}
+
+// Create records an import with its queued entries and the entries rejected
+// on upload, in one transaction. An import with nothing queued is created
+// completed.
+func (r *JournalImportRepository) Create(ctx context.Context, job *models.JournalImport, entries []models.JournalImportEntry, rejected []models.JournalImportError) *errors.Error {
+	entriesJSON, err := json.Marshal(entries)
+	if err != nil {
+		return errors.BadRequest("invalid import entries")
+	}
+
+	txErr := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO journal_imports (format, status, total_entries, failed_entries, entries, created_by, completed_at)
+			VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $2 = 'completed' THEN NOW() END)
+			RETURNING ` + journalImportColumns
+		created, err := scanJournalImport(tx.QueryRowContext(ctx, query,
+			job.Format,
+			job.Status,
+			job.TotalEntries,
+			len(rejected),
+			entriesJSON,
+			job.CreatedBy,
+		))
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to create journal import")
+		}
+		*job = *created
+
+		for _, rowErr := range rejected {
+			if err := insertImportError(ctx, tx, job.ID, rowErr); err != nil {
+				return err
+			}
+		}
+		job.Errors = rejected
+		return nil
+	})
+	return transactionError(txErr)
+}
+
+// insertImportError records a row that could not be imported.
+func insertImportError(ctx context.Context, tx *sql.Tx, importID string, rowErr models.JournalImportError) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO journal_import_errors (import_id, row_number, reference_id, message)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+	`, importID, rowErr.Row, rowErr.ReferenceID, rowErr.Message)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record import error")
+	}
+	return nil
+}
+
+// GetByID retrieves an import with its row errors in row order.
+func (r *JournalImportRepository) GetByID(ctx context.Context, id string) (*models.JournalImport, *errors.Error) {
+	job, err := scanJournalImport(r.db.QueryRowContext(ctx,
+		`SELECT `+journalImportColumns+` FROM journal_imports WHERE id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("journal import", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get journal import")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT row_number, COALESCE(reference_id, ''), message
+		FROM journal_import_errors
+		WHERE import_id = $1
+		ORDER BY row_number, id
+	`, id)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list import errors")
+	}
+	defer func() { _ = rows.Close() }()
+
+	job.Errors = make([]models.JournalImportError, 0)
+	for rows.Next() {
+		var rowErr models.JournalImportError
+		if err := rows.Scan(&rowErr.Row, &rowErr.ReferenceID, &rowErr.Message); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan import error")
+		}
+		job.Errors = append(job.Errors, rowErr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating import errors")
+	}
+	return job, nil
+}
+
+// ClaimNext leases the oldest unfinished import whose lease has expired until
+// leaseUntil and marks it processing. Returns nil when there is none.
+func (r *JournalImportRepository) ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*models.JournalImport, *errors.Error) {
+	query := `
+		UPDATE journal_imports
+		SET status = 'processing', locked_until = $2, started_at = COALESCE(started_at, $1), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM journal_imports
+			WHERE status <> 'completed' AND (locked_until IS NULL OR locked_until < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + journalImportColumns
+
+	job, err := scanJournalImport(r.db.QueryRowContext(ctx, query, now, leaseUntil))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.DatabaseWrap(err, "failed to claim journal import")
+	}
+	return job, nil
+}
+
+// ListEntries returns up to limit queued entries of an import, starting at
+// index from.
+func (r *JournalImportRepository) ListEntries(ctx context.Context, id string, from, limit int) ([]models.JournalImportEntry, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.value
+		FROM journal_imports, jsonb_array_elements(entries) WITH ORDINALITY AS e(value, position)
+		WHERE id = $1 AND e.position > $2
+		ORDER BY e.position
+		LIMIT $3
+	`, id, from, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list import entries")
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]models.JournalImportEntry, 0, limit)
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan import entry")
+		}
+		var entry models.JournalImportEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, errors.Internal("invalid queued import entry")
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating import entries")
+	}
+	return entries, nil
+}
+
+// ImportEntry creates the queued entry at index and advances the import past
+// it, in one transaction, so an entry is never created twice. Returns a
+// conflict if the import already moved past index.
+func (r *JournalImportRepository) ImportEntry(ctx context.Context, id string, index int, entry *models.JournalEntry, lines []models.LedgerLine) *errors.Error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := advanceImport(ctx, tx, id, index, "imported_entries"); err != nil {
+			return err
+		}
+		return insertJournalEntry(ctx, tx, entry, lines)
+	})
+	return transactionError(err)
+}
+
+// FailEntry records why the queued entry at index could not be imported and
+// advances the import past it.
+func (r *JournalImportRepository) FailEntry(ctx context.Context, id string, index int, rowErr models.JournalImportError) *errors.Error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := advanceImport(ctx, tx, id, index, "failed_entries"); err != nil {
+			return err
+		}
+		return insertImportError(ctx, tx, id, rowErr)
+	})
+	return transactionError(err)
+}
+
+// advanceImport moves an import's cursor from index to the next entry and
+// increments counter, which must be imported_entries or failed_entries.
+func advanceImport(ctx context.Context, tx *sql.Tx, id string, index int, counter string) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE journal_imports
+		SET next_entry = next_entry + 1, `+counter+` = `+counter+` + 1, updated_at = NOW()
+		WHERE id = $1 AND next_entry = $2
+	`, id, index)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to advance journal import")
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return errors.Conflict("journal import entry already processed")
+	}
+	return nil
+}
+
+// Release ends the lease on an import so the next batch can be claimed.
+func (r *JournalImportRepository) Release(ctx context.Context, id string) *errors.Error {
+	_, err := r.db.ExecContext(ctx, `UPDATE journal_imports SET locked_until = NULL WHERE id = $1`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to release journal import")
+	}
+	return nil
+}
+
+// Complete marks an import completed and drops its queued entries, which are
+// now journal entries or import errors.
+func (r *JournalImportRepository) Complete(ctx context.Context, id string) *errors.Error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE journal_imports
+		SET status = 'completed', entries = '[]'::jsonb, locked_until = NULL,
+		    completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to complete journal import")
+	}
+	return nil
+}
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/database"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/retry"
+	"github.com/lib/pq"
 )
 
 // JournalEntryRepository handles database operations for journal entries.
@@ -23,94 +26,101 @@ func NewJournalEntryRepository(db *database.DB) *JournalEntryRepository {
 
 // Create creates a new journal entry with lines in a transaction.
 func (r *JournalEntryRepository) Create(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine) *errors.Error {
-	// Start transaction
 	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
-		// Generate entry number
-		var entryNumber string
-		err := tx.QueryRowContext(ctx, "SELECT generate_entry_number()").Scan(&entryNumber)
-		if err != nil {
-			return errors.DatabaseWrap(err, "failed to generate entry number")
-		}
+		return insertJournalEntry(ctx, tx, entry, lines)
+	})
+	return transactionError(err)
+}
 
-		// Serialize metadata
-		metadataJSON, err := json.Marshal(entry.Metadata)
-		if err != nil {
-			return errors.BadRequest("invalid metadata format")
-		}
+// insertJournalEntry inserts an entry and its lines within tx.
+func insertJournalEntry(ctx context.Context, tx *sql.Tx, entry *models.JournalEntry, lines []models.LedgerLine) error {
+	// Generate entry number
+	var entryNumber string
+	err := tx.QueryRowContext(ctx, "SELECT generate_entry_number()").Scan(&entryNumber)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to generate entry number")
+	}
 
-		// Insert journal entry
-		query := `
-			INSERT INTO journal_entries (entry_number, type, status, description,
-			                              reference_type, reference_id, metadata)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-			RETURNING id, created_at, updated_at
-		`
-
-		err = tx.QueryRowContext(ctx, query,
-			entryNumber,
-			entry.Type,
-			entry.Status,
-			entry.Description,
-			entry.ReferenceType,
-			entry.ReferenceID,
-			metadataJSON,
-		).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	// Serialize metadata
+	metadataJSON, err := json.Marshal(entry.Metadata)
+	if err != nil {
+		return errors.BadRequest("invalid metadata format")
+	}
 
-		if err != nil {
-			return errors.DatabaseWrap(err, "failed to create journal entry")
-		}
+	// Insert journal entry
+	query := `
+		INSERT INTO journal_entries (entry_number, type, status, description,
+		                              reference_type, reference_id, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
 
-		entry.EntryNumber = entryNumber
+	err = tx.QueryRowContext(ctx, query,
+		entryNumber,
+		entry.Type,
+		entry.Status,
+		entry.Description,
+		entry.ReferenceType,
+		entry.ReferenceID,
+		metadataJSON,
+	).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
 
-		// Insert ledger lines
-		lineQuery := `
-			INSERT INTO ledger_lines (entry_id, account_id, debit_amount, credit_amount, description, metadata)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id, created_at
-		`
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create journal entry")
+	}
 
-		for i := range lines {
-			line := &lines[i]
-			line.EntryID = entry.ID
+	entry.EntryNumber = entryNumber
 
-			// Serialize line metadata
-			lineMetadataJSON, err := json.Marshal(line.Metadata)
-			if err != nil {
-				return errors.BadRequest("invalid line metadata format")
-			}
+	// Insert ledger lines
+	lineQuery := `
+		INSERT INTO ledger_lines (entry_id, account_id, debit_amount, credit_amount, description, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
 
-			err = tx.QueryRowContext(ctx, lineQuery,
-				line.EntryID,
-				line.AccountID,
-				line.DebitAmount,
-				line.CreditAmount,
-				line.Description,
-				lineMetadataJSON,
-			).Scan(&line.ID, &line.CreatedAt)
-
-			if err != nil {
-				return errors.DatabaseWrap(err, "failed to create ledger line")
-			}
-		}
+	for i := range lines {
+		line := &lines[i]
+		line.EntryID = entry.ID
 
-		// Update entry with lines
-		entry.Lines = lines
+		// Serialize line metadata
+		lineMetadataJSON, err := json.Marshal(line.Metadata)
+		if err != nil {
+			return errors.BadRequest("invalid line metadata format")
+		}
 
-		return nil
-	})
+		err = tx.QueryRowContext(ctx, lineQuery,
+			line.EntryID,
+			line.AccountID,
+			line.DebitAmount,
+			line.CreditAmount,
+			line.Description,
+			lineMetadataJSON,
+		).Scan(&line.ID, &line.CreatedAt)
 
-	if err != nil {
-		// Check if it's already an *errors.Error
-		if e, ok := err.(*errors.Error); ok {
-			return e
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to create ledger line")
 		}
-		// Otherwise wrap it
-		return errors.DatabaseWrap(err, "transaction failed")
 	}
 
+	// Update entry with lines
+	entry.Lines = lines
+
 	return nil
 }
 
+// transactionError converts the error returned by a database transaction.
+func transactionError(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
+	// Check if it's already an *errors.Error
+	if e, ok := err.(*errors.Error); ok {
+		return e
+	}
+	// Otherwise wrap it
+	return errors.DatabaseWrap(err, "transaction failed")
+}
+
 // GetByID retrieves a journal entry with its lines.
 func (r *JournalEntryRepository) GetByID(ctx context.Context, id string) (*models.JournalEntry, *errors.Error) {
 	entry := &models.JournalEntry{}
@@ -218,17 +228,198 @@ func (r *JournalEntryRepository) GetLinesByEntryID(ctx context.Context, entryID
 	return lines, nil
 }
 
-// Post posts a draft journal entry.
-func (r *JournalEntryRepository) Post(ctx context.Context, entryID, postedBy string) *errors.Error {
+// ListByReference retrieves all journal entries for a referenced entity with
+// their lines, oldest first, so an entry and its reversal read in order.
+func (r *JournalEntryRepository) ListByReference(ctx context.Context, referenceType, referenceID string) ([]*models.JournalEntry, *errors.Error) {
 	query := `
-		UPDATE journal_entries
-		SET status = 'posted', posted_at = NOW(), posted_by = $2, updated_at = NOW()
-		WHERE id = $1 AND status = 'draft'
-		RETURNING entry_number
+		SELECT id, entry_number, type, status, description, reference_type, reference_id,
+		       posted_at, posted_by, voided_at, voided_by, void_reason, reversal_entry_id,
+		       metadata, created_at, updated_at
+		FROM journal_entries
+		WHERE reference_type = $1 AND reference_id = $2
+		ORDER BY created_at ASC
 	`
 
-	var entryNumber string
-	err := r.db.QueryRowContext(ctx, query, entryID, postedBy).Scan(&entryNumber)
+	rows, err := r.db.QueryContext(ctx, query, referenceType, referenceID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list journal entries by reference")
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]*models.JournalEntry, 0)
+	for rows.Next() {
+		entry := &models.JournalEntry{}
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntryNumber,
+			&entry.Type,
+			&entry.Status,
+			&entry.Description,
+			&entry.ReferenceType,
+			&entry.ReferenceID,
+			&entry.PostedAt,
+			&entry.PostedBy,
+			&entry.VoidedAt,
+			&entry.VoidedBy,
+			&entry.VoidReason,
+			&entry.ReversalEntryID,
+			&metadataJSON,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan journal entry")
+		}
+
+		// Deserialize metadata
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &entry.Metadata); err != nil {
+				return nil, errors.Internal("failed to parse metadata")
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating journal entries")
+	}
+
+	// Load lines after closing the result set; a reference has few entries
+	for _, entry := range entries {
+		lines, linesErr := r.GetLinesByEntryID(ctx, entry.ID)
+		if linesErr != nil {
+			return nil, linesErr
+		}
+		entry.Lines = lines
+	}
+
+	return entries, nil
+}
+
+// ListByReferences retrieves the journal entries for a batch of referenced
+// entities with their lines, using one query for entries and one for lines.
+func (r *JournalEntryRepository) ListByReferences(ctx context.Context, referenceType string, referenceIDs []string) ([]*models.JournalEntry, *errors.Error) {
+	query := `
+		SELECT id, entry_number, type, status, description, reference_type, reference_id,
+		       posted_at, posted_by, voided_at, voided_by, void_reason, reversal_entry_id,
+		       metadata, created_at, updated_at
+		FROM journal_entries
+		WHERE reference_type = $1 AND reference_id = ANY($2)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, referenceType, pq.Array(referenceIDs))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list journal entries by references")
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]*models.JournalEntry, 0)
+	byID := make(map[string]*models.JournalEntry)
+	for rows.Next() {
+		entry := &models.JournalEntry{}
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntryNumber,
+			&entry.Type,
+			&entry.Status,
+			&entry.Description,
+			&entry.ReferenceType,
+			&entry.ReferenceID,
+			&entry.PostedAt,
+			&entry.PostedBy,
+			&entry.VoidedAt,
+			&entry.VoidedBy,
+			&entry.VoidReason,
+			&entry.ReversalEntryID,
+			&metadataJSON,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan journal entry")
+		}
+
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &entry.Metadata); err != nil {
+				return nil, errors.Internal("failed to parse metadata")
+			}
+		}
+
+		entries = append(entries, entry)
+		byID[entry.ID] = entry
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating journal entries")
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	entryIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entryIDs = append(entryIDs, entry.ID)
+	}
+
+	lineRows, err := r.db.QueryContext(ctx, `
+		SELECT id, entry_id, account_id, debit_amount, credit_amount, description, created_at
+		FROM ledger_lines
+		WHERE entry_id = ANY($1)
+		ORDER BY created_at
+	`, pq.Array(entryIDs))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get ledger lines")
+	}
+	defer func() { _ = lineRows.Close() }()
+
+	for lineRows.Next() {
+		line := models.LedgerLine{}
+		if err := lineRows.Scan(
+			&line.ID,
+			&line.EntryID,
+			&line.AccountID,
+			&line.DebitAmount,
+			&line.CreditAmount,
+			&line.Description,
+			&line.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan ledger line")
+		}
+		if entry, ok := byID[line.EntryID]; ok {
+			entry.Lines = append(entry.Lines, line)
+		}
+	}
+
+	if err = lineRows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating ledger lines")
+	}
+
+	return entries, nil
+}
+
+// postRetryPolicy retries a post that lost a lock conflict, waiting about
+// 10ms and then 40ms.
+var postRetryPolicy = retry.Policy{
+	MaxAttempts:  3,
+	InitialDelay: 10 * time.Millisecond,
+	Multiplier:   4,
+	Jitter:       0.5,
+	Retryable:    database.IsRetryableConflict,
+}
+
+// Post posts a draft journal entry. Balances are updated by a database trigger
+// that locks the entry's accounts in ID order; a post that still deadlocks or
+// fails serialization is retried with backoff.
+func (r *JournalEntryRepository) Post(ctx context.Context, entryID, postedBy string) *errors.Error {
+	err := retry.Do(ctx, postRetryPolicy, func(ctx context.Context) error {
+		return r.post(ctx, entryID, postedBy)
+	})
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -238,12 +429,28 @@ func (r *JournalEntryRepository) Post(ctx context.Context, entryID, postedBy str
 		if database.IsCheckViolation(err) {
 			return errors.Validation("journal entry validation failed: " + err.Error())
 		}
+		if database.IsRetryableConflict(err) {
+			return errors.Conflict("journal entry could not be posted due to concurrent postings, retry later")
+		}
 		return errors.DatabaseWrap(err, "failed to post journal entry")
 	}
 
 	return nil
 }
 
+// post runs a single attempt at posting an entry.
+func (r *JournalEntryRepository) post(ctx context.Context, entryID, postedBy string) error {
+	query := `
+		UPDATE journal_entries
+		SET status = 'posted', posted_at = NOW(), posted_by = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'draft'
+		RETURNING entry_number
+	`
+
+	var entryNumber string
+	return r.db.QueryRowContext(ctx, query, entryID, postedBy).Scan(&entryNumber)
+}
+
 // Void voids a posted journal entry.
 func (r *JournalEntryRepository) Void(ctx context.Context, entryID, voidedBy, voidReason string) *errors.Error {
 	query := `
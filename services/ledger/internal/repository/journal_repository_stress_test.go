@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Note: This test requires a running PostgreSQL instance.
+// It is skipped if the database is not available or with -short.
+
+func getStressTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping stress test in short mode")
+	}
+
+	cfg := database.DefaultConfig()
+	cfg.ConnectTimeout = 2 * time.Second
+
+	db, err := database.Connect(cfg)
+	if err != nil {
+		t.Skipf("Skipping test: database not available: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	if err := database.NewMigrator(db.DB, "../../migrations").Up(); err != nil {
+		t.Fatalf("failed to migrate ledger schema: %v", err)
+	}
+	return db
+}
+
+// TestJournalEntryRepository_ConcurrentPosting posts many entries over a small
+// set of accounts at high parallelism, so postings constantly contend for the
+// same rows in opposite orders, and checks that no balance update was lost.
+func TestJournalEntryRepository_ConcurrentPosting(t *testing.T) {
+	db := getStressTestDB(t)
+	ctx := context.Background()
+	accountRepo := NewAccountRepository(db)
+	journalRepo := NewJournalEntryRepository(db)
+
+	const (
+		accountCount = 4
+		entryCount   = 400
+		workers      = 32
+	)
+
+	suffix := time.Now().UnixNano()
+	accounts := make([]*models.Account, accountCount)
+	for i := range accounts {
+		accountType := models.AccountTypeAsset
+		if i%2 == 1 {
+			accountType = models.AccountTypeLiability
+		}
+		accounts[i] = &models.Account{
+			Code:     fmt.Sprintf("STRESS-%d-%d", suffix, i),
+			Name:     fmt.Sprintf("Stress Test Account %d", i),
+			Type:     accountType,
+			Currency: sharedModels.INR,
+			Status:   models.AccountStatusActive,
+		}
+		if err := accountRepo.Create(ctx, accounts[i]); err != nil {
+			t.Fatalf("failed to create account: %v", err)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		entryIDs []string
+		failures []string
+		debits   = make([]int64, accountCount)
+		credits  = make([]int64, accountCount)
+		touches  = make([]int64, accountCount)
+	)
+
+	t.Cleanup(func() {
+		for _, id := range entryIDs {
+			_, _ = db.ExecContext(ctx, "DELETE FROM journal_entries WHERE id = $1", id)
+		}
+		for _, account := range accounts {
+			_, _ = db.ExecContext(ctx, "DELETE FROM accounts WHERE id = $1", account.ID)
+		}
+	})
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				from := rand.IntN(accountCount)
+				to := (from + 1 + rand.IntN(accountCount-1)) % accountCount
+				amount := int64(1 + rand.IntN(10000))
+
+				entry := &models.JournalEntry{
+					Type:        models.EntryTypeStandard,
+					Status:      models.EntryStatusDraft,
+					Description: fmt.Sprintf("stress entry %d", i),
+				}
+				lines := []models.LedgerLine{
+					{AccountID: accounts[to].ID, DebitAmount: amount},
+					{AccountID: accounts[from].ID, CreditAmount: amount},
+				}
+
+				if err := journalRepo.Create(ctx, entry, lines); err != nil {
+					mu.Lock()
+					failures = append(failures, "create: "+err.Error())
+					mu.Unlock()
+					continue
+				}
+				postErr := journalRepo.Post(ctx, entry.ID, "00000000-0000-0000-0000-000000000001")
+
+				mu.Lock()
+				entryIDs = append(entryIDs, entry.ID)
+				if postErr != nil {
+					failures = append(failures, "post: "+postErr.Error())
+				} else {
+					debits[to] += amount
+					credits[from] += amount
+					touches[to]++
+					touches[from]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < entryCount; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		t.Fatalf("%d of %d entries failed, first: %s", len(failures), entryCount, failures[0])
+	}
+
+	for i, account := range accounts {
+		got, err := accountRepo.GetByID(ctx, account.ID)
+		if err != nil {
+			t.Fatalf("failed to reload account: %v", err)
+		}
+
+		wantBalance := debits[i] - credits[i]
+		if !got.IsDebitNormal() {
+			wantBalance = -wantBalance
+		}
+		if got.Balance != wantBalance {
+			t.Errorf("account %d: balance = %d, want %d", i, got.Balance, wantBalance)
+		}
+		if got.DebitTotal != debits[i] || got.CreditTotal != credits[i] {
+			t.Errorf("account %d: totals = %d/%d, want %d/%d", i, got.DebitTotal, got.CreditTotal, debits[i], credits[i])
+		}
+		if got.Version != touches[i] {
+			t.Errorf("account %d: version = %d, want %d", i, got.Version, touches[i])
+		}
+	}
+}
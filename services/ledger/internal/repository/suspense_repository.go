@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const suspenseColumns = `
+	id, entry_id, COALESCE(reference_type, ''), COALESCE(reference_id, ''), intended_account_id,
+	debit_amount, credit_amount, reason, detail, status, cleared_account_id, cleared_entry_id,
+	cleared_by, cleared_at, created_at
+`
+
+// SuspenseRepository handles database operations for suspense items.
+type SuspenseRepository struct {
+	db *database.DB
+}
+
+// NewSuspenseRepository creates a new suspense repository.
+func NewSuspenseRepository(db *database.DB) *SuspenseRepository {
+	return &SuspenseRepository{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSuspenseItem(row rowScanner) (*models.SuspenseItem, error) {
+	item := &models.SuspenseItem{}
+	err := row.Scan(
+		&item.ID, &item.EntryID, &item.ReferenceType, &item.ReferenceID, &item.IntendedAccountID,
+		&item.DebitAmount, &item.CreditAmount, &item.Reason, &item.Detail, &item.Status, &item.ClearedAccountID, &item.ClearedEntryID,
+		&item.ClearedBy, &item.ClearedAt, &item.CreatedAt,
+	)
+	return item, err
+}
+
+// CreateEntry creates a journal entry that parks amounts in suspense together
+// with its suspense items, in one transaction.
+func (r *SuspenseRepository) CreateEntry(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine, items []*models.SuspenseItem) *errors.Error {
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := insertJournalEntry(ctx, tx, entry, lines); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO suspense_items (entry_id, reference_type, reference_id, intended_account_id,
+			                            debit_amount, credit_amount, reason, detail, status)
+			VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+			RETURNING id, created_at
+		`
+		for _, item := range items {
+			item.EntryID = entry.ID
+			item.ReferenceType = entry.ReferenceType
+			item.ReferenceID = entry.ReferenceID
+			item.Status = models.SuspenseStatusOpen
+
+			err := tx.QueryRowContext(ctx, query,
+				item.EntryID,
+				item.ReferenceType,
+				item.ReferenceID,
+				item.IntendedAccountID,
+				item.DebitAmount,
+				item.CreditAmount,
+				item.Reason,
+				item.Detail,
+				item.Status,
+			).Scan(&item.ID, &item.CreatedAt)
+			if err != nil {
+				return errors.DatabaseWrap(err, "failed to create suspense item")
+			}
+		}
+		return nil
+	})
+	return transactionError(err)
+}
+
+// GetByID retrieves a suspense item.
+func (r *SuspenseRepository) GetByID(ctx context.Context, id string) (*models.SuspenseItem, *errors.Error) {
+	query := `SELECT ` + suspenseColumns + ` FROM suspense_items WHERE id = $1`
+
+	item, err := scanSuspenseItem(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("suspense item", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get suspense item")
+	}
+	return item, nil
+}
+
+// List retrieves suspense items, newest first, with the total count.
+func (r *SuspenseRepository) List(ctx context.Context, status *models.SuspenseStatus, limit, offset int) ([]*models.SuspenseItem, int64, *errors.Error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	if status != nil {
+		args = append(args, *status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM suspense_items"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count suspense items")
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT ` + suspenseColumns + ` FROM suspense_items` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	items, listErr := r.query(ctx, query, args...)
+	if listErr != nil {
+		return nil, 0, listErr
+	}
+	return items, total, nil
+}
+
+// ListOpen retrieves open suspense items whose parking entry has been
+// posted, oldest first. Items on draft or voided entries hold no money.
+func (r *SuspenseRepository) ListOpen(ctx context.Context) ([]*models.SuspenseItem, *errors.Error) {
+	query := `
+		SELECT ` + suspenseColumns + `
+		FROM suspense_items
+		WHERE status = 'open'
+		  AND entry_id IN (SELECT id FROM journal_entries WHERE status = 'posted')
+		ORDER BY created_at ASC, id ASC
+	`
+	return r.query(ctx, query)
+}
+
+func (r *SuspenseRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.SuspenseItem, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list suspense items")
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*models.SuspenseItem, 0)
+	for rows.Next() {
+		item, err := scanSuspenseItem(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan suspense item")
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating suspense items")
+	}
+	return items, nil
+}
+
+// Claim marks an open item as cleared to accountID before its clearing entry
+// is posted, so two clerks cannot clear the same item.
+func (r *SuspenseRepository) Claim(ctx context.Context, id, accountID, clearedBy string) *errors.Error {
+	query := `
+		UPDATE suspense_items
+		SET status = 'cleared', cleared_account_id = $2, cleared_by = $3, cleared_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		RETURNING id
+	`
+	var claimed string
+	if err := r.db.QueryRowContext(ctx, query, id, accountID, clearedBy).Scan(&claimed); err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Conflict("suspense item is not open")
+		}
+		return errors.DatabaseWrap(err, "failed to claim suspense item")
+	}
+	return nil
+}
+
+// SetClearedEntry records the entry that cleared a claimed item.
+func (r *SuspenseRepository) SetClearedEntry(ctx context.Context, id, entryID string) *errors.Error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE suspense_items SET cleared_entry_id = $2 WHERE id = $1 AND status = 'cleared'`,
+		id, entryID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record clearing entry")
+	}
+	return nil
+}
+
+// Release reopens a claimed item whose clearing entry could not be posted.
+func (r *SuspenseRepository) Release(ctx context.Context, id string) *errors.Error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE suspense_items
+		SET status = 'open', cleared_account_id = NULL, cleared_by = NULL, cleared_at = NULL
+		WHERE id = $1 AND status = 'cleared' AND cleared_entry_id IS NULL
+	`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to release suspense item")
+	}
+	return nil
+}
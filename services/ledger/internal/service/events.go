@@ -0,0 +1,109 @@
+package service
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// EventTopic is the topic ledger events are published on.
+const EventTopic = "ledger"
+
+// SetEventPublisher publishes posted, voided and reversed journal entries so
+// reconciliation, analytics and the event stream see book changes as they
+// happen.
+func (s *LedgerService) SetEventPublisher(publisher *events.Publisher) {
+	s.publisher = publisher
+}
+
+// publishPosted publishes a journal entry that was just posted.
+func (s *LedgerService) publishPosted(entry *models.JournalEntry) {
+	if s.publisher == nil {
+		return
+	}
+	payload := events.LedgerEntryPosted{
+		EntryID:       entry.ID,
+		EntryNumber:   entry.EntryNumber,
+		Type:          string(entry.Type),
+		Description:   entry.Description,
+		ReferenceType: entry.ReferenceType,
+		ReferenceID:   entry.ReferenceID,
+		Amount:        entry.TotalDebits(),
+		Lines:         eventLines(entry),
+		Metadata:      entry.Metadata,
+		PostedAt:      eventTime(entry.PostedAt),
+	}
+	if entry.PostedBy != nil {
+		payload.PostedBy = *entry.PostedBy
+	}
+	s.publisher.PublishAsync(EventTopic, payload)
+}
+
+// publishVoided publishes a journal entry that was just voided.
+func (s *LedgerService) publishVoided(entry *models.JournalEntry) {
+	if s.publisher == nil {
+		return
+	}
+	payload := events.LedgerEntryVoided{
+		EntryID:       entry.ID,
+		EntryNumber:   entry.EntryNumber,
+		Type:          string(entry.Type),
+		ReferenceType: entry.ReferenceType,
+		ReferenceID:   entry.ReferenceID,
+		Amount:        entry.TotalDebits(),
+		Lines:         eventLines(entry),
+		Metadata:      entry.Metadata,
+		VoidedAt:      eventTime(entry.VoidedAt),
+	}
+	if entry.VoidedBy != nil {
+		payload.VoidedBy = *entry.VoidedBy
+	}
+	if entry.VoidReason != nil {
+		payload.VoidReason = *entry.VoidReason
+	}
+	s.publisher.PublishAsync(EventTopic, payload)
+}
+
+// publishReversed publishes an entry reversed by reversal.
+func (s *LedgerService) publishReversed(original, reversal *models.JournalEntry, reversedBy, reason string) {
+	if s.publisher == nil {
+		return
+	}
+	s.publisher.PublishAsync(EventTopic, events.LedgerEntryReversed{
+		EntryID:             original.ID,
+		EntryNumber:         original.EntryNumber,
+		ReversalEntryID:     reversal.ID,
+		ReversalEntryNumber: reversal.EntryNumber,
+		ReferenceType:       original.ReferenceType,
+		ReferenceID:         original.ReferenceID,
+		Amount:              original.TotalDebits(),
+		Metadata:            original.Metadata,
+		Reason:              reason,
+		ReversedBy:          reversedBy,
+		ReversedAt:          eventTime(reversal.PostedAt),
+	})
+}
+
+// eventLines returns an entry's lines for an event.
+func eventLines(entry *models.JournalEntry) []events.LedgerEntryLine {
+	lines := make([]events.LedgerEntryLine, len(entry.Lines))
+	for i, line := range entry.Lines {
+		lines[i] = events.LedgerEntryLine{
+			AccountID:    line.AccountID,
+			DebitAmount:  line.DebitAmount,
+			CreditAmount: line.CreditAmount,
+		}
+	}
+	return lines
+}
+
+// eventTime formats when a change was recorded, or now if the entry does
+// not say.
+func eventTime(at *sharedModels.Timestamp) string {
+	if at == nil {
+		return time.Now().UTC().Format(time.RFC3339)
+	}
+	return at.UTC().Format(time.RFC3339)
+}
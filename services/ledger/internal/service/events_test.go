@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	"github.com/google/uuid"
+)
+
+// captureEvents points the service's publisher at a fake gateway and returns
+// the broadcasts it receives.
+func captureEvents(t *testing.T, service *LedgerService) <-chan events.BroadcastPayload {
+	t.Helper()
+	received := make(chan events.BroadcastPayload, 10)
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload events.BroadcastPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid broadcast: %v", err)
+		}
+		received <- payload
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(gateway.Close)
+
+	service.SetEventPublisher(events.NewPublisher(events.PublishConfig{GatewayURL: gateway.URL, ServiceName: "ledger"}))
+	return received
+}
+
+// nextEvent waits for the next broadcast.
+func nextEvent(t *testing.T, received <-chan events.BroadcastPayload) events.BroadcastPayload {
+	t.Helper()
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an event")
+		return events.BroadcastPayload{}
+	}
+}
+
+func postedTestEntry(journalRepo *mockJournalEntryRepository, status models.EntryStatus) *models.JournalEntry {
+	entry := &models.JournalEntry{
+		ID:            uuid.New().String(),
+		EntryNumber:   "JE-2025-00042",
+		Type:          models.EntryTypeStandard,
+		Status:        status,
+		Description:   "Wallet deposit",
+		ReferenceType: "transaction",
+		ReferenceID:   "txn-001",
+		Metadata:      map[string]string{"channel": "upi"},
+		Lines: []models.LedgerLine{
+			{AccountID: "cash", DebitAmount: 25000},
+			{AccountID: "wallet", CreditAmount: 25000},
+		},
+	}
+	journalRepo.entries[entry.ID] = entry
+	return entry
+}
+
+func TestPostJournalEntry_PublishesEvent(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	received := captureEvents(t, service)
+	entry := postedTestEntry(journalRepo, models.EntryStatusDraft)
+
+	if _, err := service.PostJournalEntry(context.Background(), entry.ID, "user-123"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	event := nextEvent(t, received)
+	if event.Topic != EventTopic || event.Type != "ledger.entry.posted" {
+		t.Fatalf("expected ledger.entry.posted on %s, got %s on %s", EventTopic, event.Type, event.Topic)
+	}
+	var posted events.LedgerEntryPosted
+	if err := events.Decode(events.Event{Type: event.Type, Data: event.Data}, &posted); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if posted.EntryID != entry.ID || posted.ReferenceID != "txn-001" || posted.Amount != 25000 || posted.PostedBy != "user-123" {
+		t.Errorf("unexpected payload: %+v", posted)
+	}
+	if len(posted.Lines) != 2 || posted.Lines[0].AccountID != "cash" || posted.Metadata["channel"] != "upi" {
+		t.Errorf("expected lines and metadata in the payload, got %+v", posted)
+	}
+}
+
+func TestVoidJournalEntry_PublishesEvent(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	received := captureEvents(t, service)
+	entry := postedTestEntry(journalRepo, models.EntryStatusPosted)
+
+	if _, err := service.VoidJournalEntry(context.Background(), entry.ID, "user-123", "duplicate"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	event := nextEvent(t, received)
+	var voided events.LedgerEntryVoided
+	if err := events.Decode(events.Event{Type: event.Type, Data: event.Data}, &voided); err != nil {
+		t.Fatalf("failed to decode event: %v", err)
+	}
+	if voided.EntryID != entry.ID || voided.VoidedBy != "user-123" || voided.VoidReason != "duplicate" || voided.VoidedAt == "" {
+		t.Errorf("unexpected payload: %+v", voided)
+	}
+}
+
+func TestReverseJournalEntry_PublishesEvents(t *testing.T) {
+	service, accountRepo, journalRepo := setupTestService()
+	accountRepo.accounts["cash"] = createTestAccount("cash", "1000", "Cash", models.AccountTypeAsset)
+	accountRepo.accounts["wallet"] = createTestAccount("wallet", "2100", "Wallets", models.AccountTypeLiability)
+	received := captureEvents(t, service)
+	entry := postedTestEntry(journalRepo, models.EntryStatusPosted)
+
+	reversal, err := service.ReverseJournalEntry(context.Background(), entry.ID, "user-123", "refund")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The reversing entry is posted, then the original reported reversed;
+	// asynchronous publishing may deliver them in either order
+	byType := make(map[string]events.BroadcastPayload)
+	for range 2 {
+		event := nextEvent(t, received)
+		byType[event.Type] = event
+	}
+
+	var posted events.LedgerEntryPosted
+	if err := events.Decode(events.Event{Type: "ledger.entry.posted", Data: byType["ledger.entry.posted"].Data}, &posted); err != nil {
+		t.Fatalf("failed to decode posted event: %v", err)
+	}
+	if posted.EntryID != reversal.ID || posted.Type != string(models.EntryTypeReversing) {
+		t.Errorf("expected the reversing entry to be posted, got %+v", posted)
+	}
+
+	var reversed events.LedgerEntryReversed
+	if err := events.Decode(events.Event{Type: "ledger.entry.reversed", Data: byType["ledger.entry.reversed"].Data}, &reversed); err != nil {
+		t.Fatalf("failed to decode reversed event: %v", err)
+	}
+	if reversed.EntryID != entry.ID || reversed.ReversalEntryID != reversal.ID || reversed.Reason != "refund" || reversed.Amount != 25000 {
+		t.Errorf("unexpected payload: %+v", reversed)
+	}
+}
@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// FXRepositoryInterface defines the interface for FX rate and revaluation repository operations.
+type FXRepositoryInterface interface {
+	SetRates(ctx context.Context, rateDate time.Time, rates []*models.FXRate, createdBy string) *errors.Error
+	ListRates(ctx context.Context, rateDate time.Time) ([]*models.FXRate, *errors.Error)
+	// RatesAsOf returns the latest rate on or before date for each currency.
+	RatesAsOf(ctx context.Context, date time.Time) ([]*models.FXRate, *errors.Error)
+	LatestRevaluation(ctx context.Context, accountID string) (*models.FXRevaluation, *errors.Error)
+	// ClaimRevaluation records a revaluation, or returns a conflict if the
+	// account was already revalued for the period.
+	ClaimRevaluation(ctx context.Context, rev *models.FXRevaluation) *errors.Error
+	SetRevaluationEntry(ctx context.Context, id, entryID string) *errors.Error
+	// DeleteRevaluation removes a claimed revaluation whose entry failed.
+	DeleteRevaluation(ctx context.Context, id string) *errors.Error
+	ListRevaluations(ctx context.Context, periodEnd *time.Time, limit, offset int) ([]*models.FXRevaluation, int64, *errors.Error)
+}
+
+// rateFormat accepts the rates fx_rates can store: up to 10 integer and 10 decimal digits.
+var rateFormat = regexp.MustCompile(`^\d{1,10}(\.\d{1,10})?$`)
+
+// SystemUserID is recorded as the poster of entries made by scheduled jobs.
+const SystemUserID = "00000000-0000-0000-0000-000000000000"
+
+// accountPageSize is the page size used when walking the whole chart of accounts.
+const accountPageSize = 100
+
+// SetFXRevaluation enables FX rates, period-end revaluation of foreign-currency
+// accounts and base-currency balance reporting.
+func (s *LedgerService) SetFXRevaluation(repo FXRepositoryInterface, baseCurrency sharedModels.Currency) {
+	s.fx = repo
+	s.baseCurrency = baseCurrency
+}
+
+// FXEnabled reports whether FX revaluation is enabled.
+func (s *LedgerService) FXEnabled() bool {
+	return s.fx != nil
+}
+
+// ParseDate parses a YYYY-MM-DD rate date or period end.
+func ParseDate(value string) (time.Time, *errors.Error) {
+	date, err := time.Parse(models.DateFormat, value)
+	if err != nil {
+		return time.Time{}, errors.Validation("date must be in YYYY-MM-DD format")
+	}
+	return date, nil
+}
+
+// PreviousMonthEnd returns the last day of the month before now's month.
+func PreviousMonthEnd(now time.Time) time.Time {
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return firstOfMonth.AddDate(0, 0, -1)
+}
+
+// SetFXRates records the base-currency value of one unit of each currency on a date.
+func (s *LedgerService) SetFXRates(ctx context.Context, req *models.SetFXRatesRequest, createdBy string) ([]*models.FXRate, *errors.Error) {
+	if s.fx == nil {
+		return nil, errors.Internal("fx revaluation is not enabled")
+	}
+
+	rateDate, err := ParseDate(req.RateDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Rates) == 0 {
+		return nil, errors.Validation("at least one rate is required")
+	}
+
+	rates := make([]*models.FXRate, 0, len(req.Rates))
+	for code, value := range req.Rates {
+		currency, parseErr := sharedModels.ParseCurrency(code)
+		if parseErr != nil {
+			return nil, errors.Validation(fmt.Sprintf("unsupported currency %q", code))
+		}
+		if currency == s.baseCurrency {
+			return nil, errors.Validation(fmt.Sprintf("%s is the base currency", currency))
+		}
+		if _, rateErr := parseRate(value); rateErr != nil {
+			return nil, errors.Validation(fmt.Sprintf("invalid %s rate: %s", currency, rateErr.Error()))
+		}
+		rates = append(rates, &models.FXRate{Currency: currency, Rate: value})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].Currency < rates[j].Currency })
+
+	if setErr := s.fx.SetRates(ctx, rateDate, rates, createdBy); setErr != nil {
+		return nil, setErr
+	}
+	return rates, nil
+}
+
+// ListFXRates returns the rates recorded for a date, or the latest rate of
+// each currency when date is nil.
+func (s *LedgerService) ListFXRates(ctx context.Context, date *time.Time) ([]*models.FXRate, *errors.Error) {
+	if s.fx == nil {
+		return nil, errors.Internal("fx revaluation is not enabled")
+	}
+	if date == nil {
+		return s.fx.RatesAsOf(ctx, time.Now())
+	}
+	return s.fx.ListRates(ctx, *date)
+}
+
+// CurrentFXRates returns the latest rate of each currency against the base currency.
+func (s *LedgerService) CurrentFXRates(ctx context.Context) (*models.FXRateTable, *errors.Error) {
+	rates, err := s.ListFXRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &models.FXRateTable{BaseCurrency: s.baseCurrency, Rates: rates}, nil
+}
+
+// ListFXRevaluations retrieves revaluations, optionally for one period end.
+func (s *LedgerService) ListFXRevaluations(ctx context.Context, periodEnd *time.Time, limit, offset int) ([]*models.FXRevaluation, int64, *errors.Error) {
+	if s.fx == nil {
+		return nil, 0, errors.Internal("fx revaluation is not enabled")
+	}
+	return s.fx.ListRevaluations(ctx, periodEnd, limit, offset)
+}
+
+// RevalueAccounts revalues every active foreign-currency account at the rates
+// recorded for periodEnd. Each account's change in base-currency value since
+// its last revaluation is posted as an adjusting entry between the account's
+// FX adjustment account and the unrealized FX gain/loss account. An account's
+// first revaluation only records its base-currency value.
+//
+// Accounts without a rate for the period, or already revalued for it or a
+// later period, are skipped, so a run can be repeated safely.
+func (s *LedgerService) RevalueAccounts(ctx context.Context, periodEnd time.Time, revaluedBy string) (*models.FXRevaluationRun, *errors.Error) {
+	if s.fx == nil {
+		return nil, errors.Internal("fx revaluation is not enabled")
+	}
+
+	period := periodEnd.Format(models.DateFormat)
+	rates, err := s.fx.ListRates(ctx, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	ratesByCurrency := make(map[sharedModels.Currency]*models.FXRate, len(rates))
+	for _, rate := range rates {
+		ratesByCurrency[rate.Currency] = rate
+	}
+
+	active := models.AccountStatusActive
+	accounts, err := s.allAccounts(ctx, &active)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.FXRevaluationRun{
+		PeriodEnd:    period,
+		BaseCurrency: s.baseCurrency,
+		Revalued:     make([]*models.FXRevaluation, 0),
+		Skipped:      make(map[string]string),
+	}
+
+	var gainLoss *models.Account
+	for _, account := range accounts {
+		if account.Currency == s.baseCurrency {
+			continue
+		}
+
+		rate, ok := ratesByCurrency[account.Currency]
+		if !ok {
+			run.Skipped[account.Code] = fmt.Sprintf("no %s rate for %s", account.Currency, period)
+			continue
+		}
+
+		if gainLoss == nil {
+			if gainLoss, err = s.fxGainLossAccount(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		rev, revErr := s.revalueAccount(ctx, account, rate, period, gainLoss, revaluedBy)
+		if revErr != nil {
+			run.Skipped[account.Code] = revErr.Message
+			continue
+		}
+		run.Revalued = append(run.Revalued, rev)
+	}
+
+	return run, nil
+}
+
+// revalueAccount records one account's revaluation and posts its adjustment.
+func (s *LedgerService) revalueAccount(ctx context.Context, account *models.Account, rate *models.FXRate, period string, gainLoss *models.Account, revaluedBy string) (*models.FXRevaluation, *errors.Error) {
+	prev, err := s.fx.LatestRevaluation(ctx, account.ID)
+	if err != nil && err.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+	if prev != nil && prev.PeriodEnd >= period {
+		return nil, errors.Conflict(fmt.Sprintf("already revalued for %s", prev.PeriodEnd))
+	}
+
+	r, _ := parseRate(rate.Rate)
+	baseValue, convErr := convertToBase(account.Balance, account.Currency, r, s.baseCurrency)
+	if convErr != nil {
+		return nil, convErr
+	}
+
+	// The carrying value is the last revalued value plus movements since,
+	// converted at this period's rate; the adjustment is therefore the rate
+	// change applied to the balance at the last revaluation.
+	previousBaseValue := baseValue
+	if prev != nil {
+		movement, moveErr := convertToBase(account.Balance-prev.Balance, account.Currency, r, s.baseCurrency)
+		if moveErr != nil {
+			return nil, moveErr
+		}
+		previousBaseValue = prev.BaseValue + movement
+	}
+
+	rev := &models.FXRevaluation{
+		AccountID:         account.ID,
+		PeriodEnd:         period,
+		Currency:          account.Currency,
+		Rate:              rate.Rate,
+		Balance:           account.Balance,
+		BaseValue:         baseValue,
+		PreviousBaseValue: previousBaseValue,
+		Adjustment:        baseValue - previousBaseValue,
+	}
+	if revaluedBy != "" {
+		rev.CreatedBy = &revaluedBy
+	}
+
+	if rev.Adjustment == 0 {
+		if claimErr := s.fx.ClaimRevaluation(ctx, rev); claimErr != nil {
+			return nil, claimErr
+		}
+		return rev, nil
+	}
+
+	adjustmentAccount, err := s.fxAdjustmentAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	rev.AdjustmentAccountID = &adjustmentAccount.ID
+
+	if claimErr := s.fx.ClaimRevaluation(ctx, rev); claimErr != nil {
+		return nil, claimErr
+	}
+
+	entry, postErr := s.postRevaluationEntry(ctx, account, rev, adjustmentAccount, gainLoss, revaluedBy)
+	if postErr != nil {
+		if deleteErr := s.fx.DeleteRevaluation(ctx, rev.ID); deleteErr != nil {
+			return nil, deleteErr
+		}
+		return nil, postErr
+	}
+
+	if setErr := s.fx.SetRevaluationEntry(ctx, rev.ID, entry.ID); setErr != nil {
+		return nil, setErr
+	}
+	rev.EntryID = &entry.ID
+
+	return rev, nil
+}
+
+// postRevaluationEntry books the adjustment to the account's FX adjustment
+// account against unrealized FX gain/loss. A rise in base-currency value
+// increases the adjustment account on the account's normal side: a gain for
+// assets, a loss for liabilities.
+func (s *LedgerService) postRevaluationEntry(ctx context.Context, account *models.Account, rev *models.FXRevaluation, adjustmentAccount, gainLoss *models.Account, revaluedBy string) (*models.JournalEntry, *errors.Error) {
+	description := fmt.Sprintf("FX revaluation of %s (%s) at %s for %s", account.Code, account.Currency, rev.Rate, rev.PeriodEnd)
+
+	amount := rev.Adjustment
+	if amount < 0 {
+		amount = -amount
+	}
+	adjustment := models.LedgerLineInput{AccountID: adjustmentAccount.ID, Description: description}
+	offset := models.LedgerLineInput{AccountID: gainLoss.ID, Description: description}
+	if account.IsDebitNormal() == (rev.Adjustment > 0) {
+		adjustment.DebitAmount, offset.CreditAmount = amount, amount
+	} else {
+		adjustment.CreditAmount, offset.DebitAmount = amount, amount
+	}
+
+	entry, err := s.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+		Type:          models.EntryTypeAdjusting,
+		Description:   description,
+		ReferenceType: models.FXRevaluationReferenceType,
+		ReferenceID:   rev.ID,
+		Lines:         []models.LedgerLineInput{adjustment, offset},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(ctx, entry.ID, revaluedBy)
+}
+
+// fxAdjustmentAccount returns the base-currency account that carries a
+// foreign-currency account's revaluation adjustments, creating it on first use.
+func (s *LedgerService) fxAdjustmentAccount(ctx context.Context, account *models.Account) (*models.Account, *errors.Error) {
+	code := account.Code + models.FXAdjustmentCodeSuffix
+	existing, err := s.accountRepo.GetByCode(ctx, code)
+	if err == nil {
+		return existing, nil
+	}
+	if err.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+
+	adjustmentAccount := &models.Account{
+		Code:     code,
+		Name:     account.Name + " FX Adjustment",
+		Type:     account.Type,
+		Currency: s.baseCurrency,
+		ParentID: &account.ID,
+		Status:   models.AccountStatusActive,
+		Metadata: map[string]string{"fx_adjustment_for": account.ID},
+	}
+	if createErr := s.accountRepo.Create(ctx, adjustmentAccount); createErr != nil {
+		return nil, createErr
+	}
+	return adjustmentAccount, nil
+}
+
+// fxGainLossAccount returns the unrealized FX gain/loss account from the chart of accounts.
+func (s *LedgerService) fxGainLossAccount(ctx context.Context) (*models.Account, *errors.Error) {
+	account, err := s.accountRepo.GetByCode(ctx, models.FXGainLossAccountCode)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil, errors.Internal("fx gain/loss account is not configured")
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// BaseCurrencyBalances reports every account's balance in its own currency and
+// in the base currency, converted at the latest rate on or before asOf.
+func (s *LedgerService) BaseCurrencyBalances(ctx context.Context, asOf time.Time) (*models.BaseCurrencyBalanceReport, *errors.Error) {
+	if s.fx == nil {
+		return nil, errors.Internal("fx revaluation is not enabled")
+	}
+
+	rates, err := s.fx.RatesAsOf(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+	ratesByCurrency := make(map[sharedModels.Currency]*models.FXRate, len(rates))
+	for _, rate := range rates {
+		ratesByCurrency[rate.Currency] = rate
+	}
+
+	accounts, err := s.allAccounts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.BaseCurrencyBalanceReport{
+		AsOf:         asOf.Format(models.DateFormat),
+		BaseCurrency: s.baseCurrency,
+		Accounts:     make([]models.AccountBaseBalance, 0, len(accounts)),
+		TotalsByType: make(map[models.AccountType]int64),
+	}
+	missing := make(map[sharedModels.Currency]bool)
+
+	for _, account := range accounts {
+		line := models.AccountBaseBalance{
+			AccountID: account.ID,
+			Code:      account.Code,
+			Name:      account.Name,
+			Type:      account.Type,
+			Currency:  account.Currency,
+			Balance:   account.Balance,
+		}
+
+		if account.Currency == s.baseCurrency {
+			baseBalance := account.Balance
+			line.BaseBalance = &baseBalance
+		} else if rate, ok := ratesByCurrency[account.Currency]; ok {
+			r, _ := parseRate(rate.Rate)
+			if baseBalance, convErr := convertToBase(account.Balance, account.Currency, r, s.baseCurrency); convErr == nil {
+				line.BaseBalance = &baseBalance
+				line.Rate = rate.Rate
+				line.RateDate = rate.RateDate
+			}
+		} else if !missing[account.Currency] {
+			missing[account.Currency] = true
+			report.MissingRates = append(report.MissingRates, account.Currency)
+		}
+
+		// Adjustment accounts are left out of the totals: converting a
+		// foreign-currency balance at the current rate already includes them.
+		if line.BaseBalance != nil && account.Metadata["fx_adjustment_for"] == "" {
+			report.TotalsByType[account.Type] += *line.BaseBalance
+		}
+
+		report.Accounts = append(report.Accounts, line)
+	}
+
+	return report, nil
+}
+
+// allAccounts walks the chart of accounts page by page.
+func (s *LedgerService) allAccounts(ctx context.Context, status *models.AccountStatus) ([]*models.Account, *errors.Error) {
+	accounts := make([]*models.Account, 0)
+	for offset := 0; ; offset += accountPageSize {
+		page, err := s.accountRepo.List(ctx, nil, status, accountPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, page...)
+		if len(page) < accountPageSize {
+			return accounts, nil
+		}
+	}
+}
+
+// parseRate parses a decimal rate.
+func parseRate(value string) (*big.Rat, error) {
+	if !rateFormat.MatchString(value) {
+		return nil, fmt.Errorf("rate must be a decimal with at most 10 decimal places")
+	}
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate must be greater than zero")
+	}
+	return rate, nil
+}
+
+// convertToBase converts an amount in currency's smallest unit to the base
+// currency's smallest unit, rounding half away from zero.
+func convertToBase(amount int64, currency sharedModels.Currency, rate *big.Rat, base sharedModels.Currency) (int64, *errors.Error) {
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	scale := new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(base.GetDecimalPlaces())), nil),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(currency.GetDecimalPlaces())), nil),
+	)
+	value.Mul(value, scale)
+
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	if !quotient.IsInt64() {
+		return 0, errors.Validation(fmt.Sprintf("%s amount %d is too large to convert", currency, amount))
+	}
+	return quotient.Int64(), nil
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockFXRepository struct {
+	rates        map[string]*models.FXRate // currency|date
+	revaluations []*models.FXRevaluation
+}
+
+func (m *mockFXRepository) SetRates(ctx context.Context, rateDate time.Time, rates []*models.FXRate, createdBy string) *errors.Error {
+	for _, rate := range rates {
+		rate.RateDate = rateDate.Format(models.DateFormat)
+		copied := *rate
+		m.rates[string(rate.Currency)+"|"+rate.RateDate] = &copied
+	}
+	return nil
+}
+
+func (m *mockFXRepository) ListRates(ctx context.Context, rateDate time.Time) ([]*models.FXRate, *errors.Error) {
+	rates := make([]*models.FXRate, 0)
+	for _, rate := range m.rates {
+		if rate.RateDate == rateDate.Format(models.DateFormat) {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+func (m *mockFXRepository) RatesAsOf(ctx context.Context, date time.Time) ([]*models.FXRate, *errors.Error) {
+	latest := make(map[sharedModels.Currency]*models.FXRate)
+	for _, rate := range m.rates {
+		if rate.RateDate > date.Format(models.DateFormat) {
+			continue
+		}
+		if current, ok := latest[rate.Currency]; !ok || rate.RateDate > current.RateDate {
+			latest[rate.Currency] = rate
+		}
+	}
+	rates := make([]*models.FXRate, 0, len(latest))
+	for _, rate := range latest {
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+func (m *mockFXRepository) LatestRevaluation(ctx context.Context, accountID string) (*models.FXRevaluation, *errors.Error) {
+	var latest *models.FXRevaluation
+	for _, rev := range m.revaluations {
+		if rev.AccountID == accountID && (latest == nil || rev.PeriodEnd > latest.PeriodEnd) {
+			latest = rev
+		}
+	}
+	if latest == nil {
+		return nil, errors.NotFound("account has not been revalued")
+	}
+	return latest, nil
+}
+
+func (m *mockFXRepository) ClaimRevaluation(ctx context.Context, rev *models.FXRevaluation) *errors.Error {
+	for _, existing := range m.revaluations {
+		if existing.AccountID == rev.AccountID && existing.PeriodEnd == rev.PeriodEnd {
+			return errors.Conflict("account already revalued for " + rev.PeriodEnd)
+		}
+	}
+	rev.ID = uuid.New().String()
+	m.revaluations = append(m.revaluations, rev)
+	return nil
+}
+
+func (m *mockFXRepository) SetRevaluationEntry(ctx context.Context, id, entryID string) *errors.Error {
+	for _, rev := range m.revaluations {
+		if rev.ID == id {
+			rev.EntryID = &entryID
+		}
+	}
+	return nil
+}
+
+func (m *mockFXRepository) DeleteRevaluation(ctx context.Context, id string) *errors.Error {
+	for i, rev := range m.revaluations {
+		if rev.ID == id {
+			m.revaluations = append(m.revaluations[:i], m.revaluations[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *mockFXRepository) ListRevaluations(ctx context.Context, periodEnd *time.Time, limit, offset int) ([]*models.FXRevaluation, int64, *errors.Error) {
+	return m.revaluations, int64(len(m.revaluations)), nil
+}
+
+var _ FXRepositoryInterface = (*mockFXRepository)(nil)
+
+func setupFXTest(t *testing.T) (*LedgerService, *mockAccountRepository, *mockJournalEntryRepository, *mockFXRepository, *models.Account) {
+	t.Helper()
+	service, accountRepo, journalRepo := setupTestService()
+	fxRepo := &mockFXRepository{rates: make(map[string]*models.FXRate)}
+	service.SetFXRevaluation(fxRepo, sharedModels.INR)
+
+	gainLoss := createTestAccount(uuid.New().String(), models.FXGainLossAccountCode, "Unrealized FX Gain/Loss", models.AccountTypeRevenue)
+	accountRepo.accounts[gainLoss.ID] = gainLoss
+
+	return service, accountRepo, journalRepo, fxRepo, gainLoss
+}
+
+func setRate(t *testing.T, service *LedgerService, date, currency, rate string) {
+	t.Helper()
+	_, err := service.SetFXRates(context.Background(), &models.SetFXRatesRequest{
+		RateDate: date,
+		Rates:    map[string]string{currency: rate},
+	}, "admin-1")
+	if err != nil {
+		t.Fatalf("SetFXRates failed: %v", err)
+	}
+}
+
+func mustParseDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := ParseDate(value)
+	if err != nil {
+		t.Fatalf("ParseDate(%q) failed: %v", value, err)
+	}
+	return date
+}
+
+func TestConvertToBase(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		currency sharedModels.Currency
+		rate     string
+		want     int64
+	}{
+		{"cents to paise", 10000, sharedModels.USD, "83.125", 831250},
+		{"rounds small fractions down", 1, sharedModels.USD, "0.4", 0},
+		{"rounds half up", 1, sharedModels.USD, "0.5", 1},
+		{"negative rounds away from zero", -1, sharedModels.USD, "0.5", -1},
+		{"yen has no minor unit", 1000, sharedModels.JPY, "0.56", 56000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, _ := new(big.Rat).SetString(tt.rate)
+			got, err := convertToBase(tt.amount, tt.currency, rate, sharedModels.INR)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("convertToBase(%d %s @ %s) = %d, want %d", tt.amount, tt.currency, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFXRates_Validation(t *testing.T) {
+	service, _, _, _, _ := setupFXTest(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		req  *models.SetFXRatesRequest
+	}{
+		{"bad date", &models.SetFXRatesRequest{RateDate: "31-03-2025", Rates: map[string]string{"USD": "83"}}},
+		{"no rates", &models.SetFXRatesRequest{RateDate: "2025-03-31"}},
+		{"base currency", &models.SetFXRatesRequest{RateDate: "2025-03-31", Rates: map[string]string{"INR": "1"}}},
+		{"unsupported currency", &models.SetFXRatesRequest{RateDate: "2025-03-31", Rates: map[string]string{"XYZ": "1"}}},
+		{"zero rate", &models.SetFXRatesRequest{RateDate: "2025-03-31", Rates: map[string]string{"USD": "0"}}},
+		{"not a decimal", &models.SetFXRatesRequest{RateDate: "2025-03-31", Rates: map[string]string{"USD": "1e3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.SetFXRates(ctx, tt.req, "admin-1"); err == nil || err.Code != errors.ErrCodeValidation {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRevalueAccounts_PostsAdjustments(t *testing.T) {
+	service, accountRepo, journalRepo, fxRepo, gainLoss := setupFXTest(t)
+	ctx := context.Background()
+
+	usdCash := createTestAccount(uuid.New().String(), "1010", "USD Cash", models.AccountTypeAsset)
+	usdCash.Currency = sharedModels.USD
+	usdCash.Balance = 100000 // $1,000.00
+	usdPayable := createTestAccount(uuid.New().String(), "2010", "USD Payables", models.AccountTypeLiability)
+	usdPayable.Currency = sharedModels.USD
+	usdPayable.Balance = 50000 // $500.00
+	eurCash := createTestAccount(uuid.New().String(), "1020", "EUR Cash", models.AccountTypeAsset)
+	eurCash.Currency = sharedModels.EUR
+	eurCash.Balance = 1000
+	for _, account := range []*models.Account{usdCash, usdPayable, eurCash} {
+		accountRepo.accounts[account.ID] = account
+	}
+
+	// First period: records the baseline, no entries
+	setRate(t, service, "2025-01-31", "USD", "83")
+	run, err := service.RevalueAccounts(ctx, mustParseDate(t, "2025-01-31"), "admin-1")
+	if err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+	if len(run.Revalued) != 2 || len(journalRepo.entries) != 0 {
+		t.Fatalf("expected two baseline revaluations and no entries, got %d and %d", len(run.Revalued), len(journalRepo.entries))
+	}
+	if _, ok := run.Skipped[eurCash.Code]; !ok {
+		t.Error("expected EUR account without a rate to be skipped")
+	}
+
+	// Second period: $200 deposited, rate moves 83 -> 84
+	usdCash.Balance = 120000
+	setRate(t, service, "2025-02-28", "USD", "84")
+	run, err = service.RevalueAccounts(ctx, mustParseDate(t, "2025-02-28"), "admin-1")
+	if err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+	if len(run.Revalued) != 2 || len(journalRepo.entries) != 2 {
+		t.Fatalf("expected two revaluations with entries, got %d and %d", len(run.Revalued), len(journalRepo.entries))
+	}
+
+	for _, rev := range run.Revalued {
+		// Only the balance held at the last revaluation moves with the rate
+		var want int64
+		switch rev.AccountID {
+		case usdCash.ID:
+			want = 100000 // $1,000 x 1 INR
+		case usdPayable.ID:
+			want = 50000
+		}
+		if rev.Adjustment != want {
+			t.Errorf("account %s: adjustment = %d, want %d", rev.AccountID, rev.Adjustment, want)
+		}
+		if rev.EntryID == nil {
+			t.Fatalf("account %s: expected an adjusting entry", rev.AccountID)
+		}
+
+		entry := journalRepo.entries[*rev.EntryID]
+		if entry.Status != models.EntryStatusPosted || entry.ReferenceType != models.FXRevaluationReferenceType {
+			t.Errorf("expected posted fx_revaluation entry, got %s/%s", entry.Status, entry.ReferenceType)
+		}
+		for _, line := range entry.Lines {
+			gain := line.AccountID == gainLoss.ID
+			switch {
+			case rev.AccountID == usdCash.ID && gain && line.CreditAmount != want:
+				t.Errorf("asset gain should credit FX gain/loss, got %+v", line)
+			case rev.AccountID == usdPayable.ID && gain && line.DebitAmount != want:
+				t.Errorf("liability revalued upward should debit FX gain/loss, got %+v", line)
+			}
+		}
+	}
+
+	adjustment, getErr := accountRepo.GetByCode(ctx, "1010"+models.FXAdjustmentCodeSuffix)
+	if getErr != nil {
+		t.Fatalf("expected FX adjustment account to be created: %v", getErr)
+	}
+	if adjustment.Currency != sharedModels.INR || adjustment.Type != models.AccountTypeAsset || *adjustment.ParentID != usdCash.ID {
+		t.Errorf("unexpected adjustment account %+v", adjustment)
+	}
+
+	// Rerunning the period changes nothing
+	run, err = service.RevalueAccounts(ctx, mustParseDate(t, "2025-02-28"), "admin-1")
+	if err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+	if len(run.Revalued) != 0 || len(journalRepo.entries) != 2 || len(fxRepo.revaluations) != 4 {
+		t.Errorf("expected rerun to skip every account, got %d revalued", len(run.Revalued))
+	}
+}
+
+func TestRevalueAccounts_FailedEntryReleasesPeriod(t *testing.T) {
+	service, accountRepo, journalRepo, fxRepo, _ := setupFXTest(t)
+	ctx := context.Background()
+
+	usdCash := createTestAccount(uuid.New().String(), "1010", "USD Cash", models.AccountTypeAsset)
+	usdCash.Currency = sharedModels.USD
+	usdCash.Balance = 100000
+	accountRepo.accounts[usdCash.ID] = usdCash
+
+	setRate(t, service, "2025-01-31", "USD", "83")
+	setRate(t, service, "2025-02-28", "USD", "82")
+	if _, err := service.RevalueAccounts(ctx, mustParseDate(t, "2025-01-31"), "admin-1"); err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+
+	journalRepo.postFunc = func(ctx context.Context, entryID, postedBy string) *errors.Error {
+		return errors.Internal("database unavailable")
+	}
+	run, err := service.RevalueAccounts(ctx, mustParseDate(t, "2025-02-28"), "admin-1")
+	if err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+	if len(run.Revalued) != 0 || run.Skipped[usdCash.Code] == "" {
+		t.Fatalf("expected failed revaluation to be skipped, got %+v", run)
+	}
+	if len(fxRepo.revaluations) != 1 {
+		t.Fatalf("expected failed revaluation to be removed, got %d", len(fxRepo.revaluations))
+	}
+
+	// A later run can revalue the period
+	journalRepo.postFunc = nil
+	run, err = service.RevalueAccounts(ctx, mustParseDate(t, "2025-02-28"), "admin-1")
+	if err != nil {
+		t.Fatalf("RevalueAccounts failed: %v", err)
+	}
+	if len(run.Revalued) != 1 || run.Revalued[0].Adjustment != -100000 {
+		t.Errorf("expected a loss of 100000 paise, got %+v", run.Revalued)
+	}
+}
+
+func TestBaseCurrencyBalances(t *testing.T) {
+	service, accountRepo, _, _, gainLoss := setupFXTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	cash.Balance = 500000
+	usdCash := createTestAccount(uuid.New().String(), "1010", "USD Cash", models.AccountTypeAsset)
+	usdCash.Currency = sharedModels.USD
+	usdCash.Balance = 10000
+	eurCash := createTestAccount(uuid.New().String(), "1020", "EUR Cash", models.AccountTypeAsset)
+	eurCash.Currency = sharedModels.EUR
+	eurCash.Balance = 10000
+	adjustment := createTestAccount(uuid.New().String(), "1010-FXA", "USD Cash FX Adjustment", models.AccountTypeAsset)
+	adjustment.Balance = 10000
+	adjustment.Metadata = map[string]string{"fx_adjustment_for": usdCash.ID}
+	for _, account := range []*models.Account{cash, usdCash, eurCash, adjustment} {
+		accountRepo.accounts[account.ID] = account
+	}
+
+	setRate(t, service, "2025-01-31", "USD", "83")
+	setRate(t, service, "2025-02-28", "USD", "84")
+	setRate(t, service, "2025-04-30", "USD", "90")
+
+	report, err := service.BaseCurrencyBalances(ctx, mustParseDate(t, "2025-03-31"))
+	if err != nil {
+		t.Fatalf("BaseCurrencyBalances failed: %v", err)
+	}
+
+	for _, line := range report.Accounts {
+		switch line.AccountID {
+		case usdCash.ID:
+			if line.BaseBalance == nil || *line.BaseBalance != 840000 || line.RateDate != "2025-02-28" {
+				t.Errorf("expected USD balance at the February rate, got %+v", line)
+			}
+		case eurCash.ID:
+			if line.BaseBalance != nil {
+				t.Errorf("expected no base balance without a EUR rate, got %d", *line.BaseBalance)
+			}
+		case gainLoss.ID:
+			if line.BaseBalance == nil || *line.BaseBalance != 0 {
+				t.Errorf("expected base-currency account to report its balance, got %+v", line)
+			}
+		}
+	}
+	if len(report.MissingRates) != 1 || report.MissingRates[0] != sharedModels.EUR {
+		t.Errorf("expected EUR rate to be reported missing, got %v", report.MissingRates)
+	}
+	if got := report.TotalsByType[models.AccountTypeAsset]; got != 500000+840000 {
+		t.Errorf("asset total = %d, want %d", got, 500000+840000)
+	}
+}
+
+func TestPreviousMonthEnd(t *testing.T) {
+	got := PreviousMonthEnd(time.Date(2025, time.March, 15, 10, 0, 0, 0, time.UTC))
+	if got.Format(models.DateFormat) != "2025-02-28" {
+		t.Errorf("PreviousMonthEnd = %s, want 2025-02-28", got.Format(models.DateFormat))
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// IntegrityRepositoryInterface defines the interface for integrity check repository operations.
+type IntegrityRepositoryInterface interface {
+	Counts(ctx context.Context) (*models.IntegrityCounts, *errors.Error)
+	UnbalancedEntries(ctx context.Context, limit int) ([]*models.EntryTotals, *errors.Error)
+	DriftingAccounts(ctx context.Context, limit int) ([]*models.AccountTotals, *errors.Error)
+	OrphanLines(ctx context.Context, limit int) ([]*models.OrphanLine, *errors.Error)
+	// RepairAccount resets an account to the sum of its lines, returning nil
+	// when it no longer drifts.
+	RepairAccount(ctx context.Context, accountID, reason, repairedBy string) (*models.AccountRepair, *errors.Error)
+	ListRepairs(ctx context.Context, limit, offset int) ([]*models.AccountRepair, int64, *errors.Error)
+}
+
+// integrityIssues exposes the latest integrity check for alerting.
+var integrityIssues = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "ledger_integrity_issues",
+		Help: "Issues found by the latest ledger integrity check",
+	},
+	[]string{"check", "severity"},
+)
+
+// integrityChecks are every check, for resetting the gauge between runs.
+var integrityChecks = []models.IntegrityCheck{
+	models.IntegrityCheckUnbalancedEntry,
+	models.IntegrityCheckEmptyEntry,
+	models.IntegrityCheckBalanceDrift,
+	models.IntegrityCheckTotalsDrift,
+	models.IntegrityCheckOrphanLine,
+}
+
+// severityRank orders severities from most to least serious.
+var severityRank = map[models.IntegritySeverity]int{
+	models.IntegritySeverityCritical: 0,
+	models.IntegritySeverityError:    1,
+	models.IntegritySeverityWarning:  2,
+}
+
+// SetIntegrity enables the integrity check and the repair of drifting accounts.
+func (s *LedgerService) SetIntegrity(repo IntegrityRepositoryInterface) {
+	s.integrity = repo
+}
+
+// IntegrityEnabled reports whether the integrity check is enabled.
+func (s *LedgerService) IntegrityEnabled() bool {
+	return s.integrity != nil
+}
+
+// CheckIntegrity verifies that every booked entry balances, every account's
+// balance and totals equal the sum of its lines, and no line is orphaned.
+// The report is kept as the latest report.
+func (s *LedgerService) CheckIntegrity(ctx context.Context) (*models.IntegrityReport, *errors.Error) {
+	report, _, err := s.checkIntegrity(ctx)
+	return report, err
+}
+
+// checkIntegrity runs the checks, returning the report together with the
+// drifting accounts it found.
+func (s *LedgerService) checkIntegrity(ctx context.Context) (*models.IntegrityReport, []*models.AccountTotals, *errors.Error) {
+	if s.integrity == nil {
+		return nil, nil, errors.Internal("integrity check is not enabled")
+	}
+
+	counts, err := s.integrity.Counts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := s.integrity.UnbalancedEntries(ctx, models.IntegrityIssueLimit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts, err := s.integrity.DriftingAccounts(ctx, models.IntegrityIssueLimit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines, err := s.integrity.OrphanLines(ctx, models.IntegrityIssueLimit+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := buildIntegrityReport(counts, entries, accounts, lines)
+	recordIntegrityMetrics(report)
+	s.lastIntegrity.Store(report)
+	if len(accounts) > models.IntegrityIssueLimit {
+		accounts = accounts[:models.IntegrityIssueLimit]
+	}
+	return report, accounts, nil
+}
+
+// LatestIntegrityReport returns the latest report, checking the ledger if it
+// has not been checked yet.
+func (s *LedgerService) LatestIntegrityReport(ctx context.Context) (*models.IntegrityReport, *errors.Error) {
+	if report := s.lastIntegrity.Load(); report != nil {
+		return report, nil
+	}
+	return s.CheckIntegrity(ctx)
+}
+
+// RepairIntegrity resets drifting accounts to the sum of their lines. Only
+// drift can be repaired automatically; unbalanced entries and orphaned lines
+// are returned for manual correction. At most IntegrityIssueLimit accounts
+// are repaired per call.
+func (s *LedgerService) RepairIntegrity(ctx context.Context, req *models.IntegrityRepairRequest, repairedBy string) (*models.IntegrityRepairResult, *errors.Error) {
+	if s.integrity == nil {
+		return nil, errors.Internal("integrity check is not enabled")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, errors.Validation("reason is required")
+	}
+
+	report, accounts, err := s.checkIntegrity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drifting := make(map[string]*models.AccountTotals, len(accounts))
+	for _, account := range accounts {
+		drifting[account.AccountID] = account
+	}
+	if len(req.AccountIDs) > 0 {
+		selected := make([]*models.AccountTotals, 0, len(req.AccountIDs))
+		for _, id := range req.AccountIDs {
+			account, ok := drifting[id]
+			if !ok {
+				return nil, errors.Validation(fmt.Sprintf("account %s does not drift", id))
+			}
+			selected = append(selected, account)
+		}
+		accounts = selected
+	}
+
+	result := &models.IntegrityRepairResult{
+		DryRun:       req.DryRun,
+		Repaired:     make([]*models.AccountRepair, 0, len(accounts)),
+		Unrepairable: make([]models.IntegrityIssue, 0),
+	}
+	for _, issue := range report.Issues {
+		if !issue.Repairable {
+			result.Unrepairable = append(result.Unrepairable, issue)
+		}
+	}
+
+	for _, account := range accounts {
+		if req.DryRun {
+			result.Repaired = append(result.Repaired, plannedRepair(account, req.Reason, repairedBy))
+			continue
+		}
+
+		repair, repairErr := s.integrity.RepairAccount(ctx, account.AccountID, req.Reason, repairedBy)
+		if repairErr != nil {
+			return nil, repairErr
+		}
+		if repair != nil {
+			result.Repaired = append(result.Repaired, repair)
+		}
+	}
+
+	// Refresh the latest report so it no longer lists the repaired accounts
+	if len(result.Repaired) > 0 && !req.DryRun {
+		if _, err := s.CheckIntegrity(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ListIntegrityRepairs lists integrity repairs, newest first.
+func (s *LedgerService) ListIntegrityRepairs(ctx context.Context, limit, offset int) ([]*models.AccountRepair, int64, *errors.Error) {
+	if s.integrity == nil {
+		return nil, 0, errors.Internal("integrity check is not enabled")
+	}
+	return s.integrity.ListRepairs(ctx, limit, offset)
+}
+
+// buildIntegrityReport turns the rows found by the checks into issues, most
+// severe first.
+func buildIntegrityReport(counts *models.IntegrityCounts, entries []*models.EntryTotals, accounts []*models.AccountTotals, lines []*models.OrphanLine) *models.IntegrityReport {
+	report := &models.IntegrityReport{
+		CheckedAt:       sharedModels.Now(),
+		EntriesChecked:  counts.Entries,
+		AccountsChecked: counts.Accounts,
+		LinesChecked:    counts.Lines,
+		Counts:          make(map[models.IntegritySeverity]int),
+		Issues:          make([]models.IntegrityIssue, 0),
+	}
+
+	if len(entries) > models.IntegrityIssueLimit {
+		entries, report.Truncated = entries[:models.IntegrityIssueLimit], true
+	}
+	if len(accounts) > models.IntegrityIssueLimit {
+		accounts, report.Truncated = accounts[:models.IntegrityIssueLimit], true
+	}
+	if len(lines) > models.IntegrityIssueLimit {
+		lines, report.Truncated = lines[:models.IntegrityIssueLimit], true
+	}
+
+	for _, entry := range entries {
+		if entry.Lines == 0 {
+			report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckEmptyEntry, models.IntegrityIssue{
+				EntryID:     entry.EntryID,
+				EntryNumber: entry.EntryNumber,
+				Detail:      fmt.Sprintf("%s entry has no lines", entry.Status),
+			}))
+			continue
+		}
+		report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckUnbalancedEntry, models.IntegrityIssue{
+			EntryID:     entry.EntryID,
+			EntryNumber: entry.EntryNumber,
+			Expected:    entry.Debits,
+			Actual:      entry.Credits,
+			Detail:      fmt.Sprintf("%s entry debits %d but credits %d", entry.Status, entry.Debits, entry.Credits),
+		}))
+	}
+
+	for _, account := range accounts {
+		if expected := account.ExpectedBalance(); expected != account.Balance {
+			report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckBalanceDrift, models.IntegrityIssue{
+				AccountID:   account.AccountID,
+				AccountCode: account.AccountCode,
+				Expected:    expected,
+				Actual:      account.Balance,
+				Detail:      fmt.Sprintf("balance is %d but lines add up to %d", account.Balance, expected),
+			}))
+		}
+		if account.DebitTotal != account.LineDebits {
+			report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckTotalsDrift, models.IntegrityIssue{
+				AccountID:   account.AccountID,
+				AccountCode: account.AccountCode,
+				Expected:    account.LineDebits,
+				Actual:      account.DebitTotal,
+				Detail:      fmt.Sprintf("debit total is %d but debit lines add up to %d", account.DebitTotal, account.LineDebits),
+			}))
+		}
+		if account.CreditTotal != account.LineCredits {
+			report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckTotalsDrift, models.IntegrityIssue{
+				AccountID:   account.AccountID,
+				AccountCode: account.AccountCode,
+				Expected:    account.LineCredits,
+				Actual:      account.CreditTotal,
+				Detail:      fmt.Sprintf("credit total is %d but credit lines add up to %d", account.CreditTotal, account.LineCredits),
+			}))
+		}
+	}
+
+	for _, line := range lines {
+		var missing []string
+		if line.EntryMissing {
+			missing = append(missing, "entry "+line.EntryID)
+		}
+		if line.AccountMissing {
+			missing = append(missing, "account "+line.AccountID)
+		}
+		report.Issues = append(report.Issues, newIntegrityIssue(models.IntegrityCheckOrphanLine, models.IntegrityIssue{
+			EntryID:   line.EntryID,
+			AccountID: line.AccountID,
+			LineID:    line.LineID,
+			Detail:    "line refers to missing " + strings.Join(missing, " and "),
+		}))
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		return severityRank[report.Issues[i].Severity] < severityRank[report.Issues[j].Severity]
+	})
+	for _, issue := range report.Issues {
+		report.Counts[issue.Severity]++
+	}
+	report.Healthy = len(report.Issues) == 0
+	return report
+}
+
+// newIntegrityIssue fills in the severity and repairability of a check's issue.
+func newIntegrityIssue(check models.IntegrityCheck, issue models.IntegrityIssue) models.IntegrityIssue {
+	issue.Check = check
+	issue.Severity = check.Severity()
+	issue.Repairable = check.Repairable()
+	return issue
+}
+
+// plannedRepair describes the repair of a drifting account without making it.
+func plannedRepair(account *models.AccountTotals, reason, repairedBy string) *models.AccountRepair {
+	return &models.AccountRepair{
+		AccountID:           account.AccountID,
+		AccountCode:         account.AccountCode,
+		PreviousBalance:     account.Balance,
+		PreviousDebitTotal:  account.DebitTotal,
+		PreviousCreditTotal: account.CreditTotal,
+		Balance:             account.ExpectedBalance(),
+		DebitTotal:          account.LineDebits,
+		CreditTotal:         account.LineCredits,
+		Reason:              reason,
+		RepairedBy:          repairedBy,
+	}
+}
+
+// recordIntegrityMetrics publishes the report's issue counts.
+func recordIntegrityMetrics(report *models.IntegrityReport) {
+	counts := make(map[models.IntegrityCheck]int)
+	for _, issue := range report.Issues {
+		counts[issue.Check]++
+	}
+	for _, check := range integrityChecks {
+		integrityIssues.WithLabelValues(string(check), string(check.Severity())).Set(float64(counts[check]))
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/google/uuid"
+)
+
+type mockIntegrityRepository struct {
+	entries  []*models.EntryTotals
+	accounts []*models.AccountTotals
+	lines    []*models.OrphanLine
+	repairs  []*models.AccountRepair
+}
+
+func (m *mockIntegrityRepository) Counts(ctx context.Context) (*models.IntegrityCounts, *errors.Error) {
+	return &models.IntegrityCounts{Entries: 10, Accounts: int64(len(m.accounts)) + 5, Lines: 20}, nil
+}
+
+func (m *mockIntegrityRepository) UnbalancedEntries(ctx context.Context, limit int) ([]*models.EntryTotals, *errors.Error) {
+	return m.entries, nil
+}
+
+func (m *mockIntegrityRepository) DriftingAccounts(ctx context.Context, limit int) ([]*models.AccountTotals, *errors.Error) {
+	return append([]*models.AccountTotals(nil), m.accounts...), nil
+}
+
+func (m *mockIntegrityRepository) OrphanLines(ctx context.Context, limit int) ([]*models.OrphanLine, *errors.Error) {
+	return m.lines, nil
+}
+
+func (m *mockIntegrityRepository) RepairAccount(ctx context.Context, accountID, reason, repairedBy string) (*models.AccountRepair, *errors.Error) {
+	for i, account := range m.accounts {
+		if account.AccountID != accountID {
+			continue
+		}
+		repair := plannedRepair(account, reason, repairedBy)
+		repair.ID = uuid.New().String()
+		m.repairs = append(m.repairs, repair)
+		m.accounts = append(m.accounts[:i], m.accounts[i+1:]...)
+		return repair, nil
+	}
+	return nil, nil
+}
+
+func (m *mockIntegrityRepository) ListRepairs(ctx context.Context, limit, offset int) ([]*models.AccountRepair, int64, *errors.Error) {
+	return m.repairs, int64(len(m.repairs)), nil
+}
+
+var _ IntegrityRepositoryInterface = (*mockIntegrityRepository)(nil)
+
+func newIntegrityTestService(repo *mockIntegrityRepository) *LedgerService {
+	svc, _, _ := setupTestService()
+	svc.SetIntegrity(repo)
+	return svc
+}
+
+func TestCheckIntegrity_Healthy(t *testing.T) {
+	svc := newIntegrityTestService(&mockIntegrityRepository{})
+
+	report, err := svc.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Healthy || len(report.Issues) != 0 {
+		t.Errorf("expected a healthy report, got %+v", report.Issues)
+	}
+	if report.EntriesChecked != 10 || report.LinesChecked != 20 {
+		t.Errorf("expected counts to be reported, got %+v", report)
+	}
+}
+
+func TestCheckIntegrity_ReportsIssuesBySeverity(t *testing.T) {
+	repo := &mockIntegrityRepository{
+		entries: []*models.EntryTotals{
+			{EntryID: "e1", EntryNumber: "JE-2025-00001", Status: models.EntryStatusPosted, Lines: 2, Debits: 1000, Credits: 900},
+			{EntryID: "e2", EntryNumber: "JE-2025-00002", Status: models.EntryStatusVoided},
+		},
+		accounts: []*models.AccountTotals{
+			// Liability whose totals match its lines but whose balance does not
+			{AccountID: "a1", AccountCode: "2100", Type: models.AccountTypeLiability, Balance: 500, DebitTotal: 200, CreditTotal: 1000, LineDebits: 200, LineCredits: 1000},
+			// Asset whose debit total drifted but whose balance is right
+			{AccountID: "a2", AccountCode: "1000", Type: models.AccountTypeAsset, Balance: 300, DebitTotal: 900, CreditTotal: 700, LineDebits: 1000, LineCredits: 700},
+		},
+		lines: []*models.OrphanLine{{LineID: "l1", EntryID: "e9", AccountID: "a1", EntryMissing: true}},
+	}
+	svc := newIntegrityTestService(repo)
+
+	report, err := svc.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Healthy {
+		t.Fatal("expected an unhealthy report")
+	}
+
+	want := map[models.IntegrityCheck]models.IntegritySeverity{
+		models.IntegrityCheckUnbalancedEntry: models.IntegritySeverityCritical,
+		models.IntegrityCheckEmptyEntry:      models.IntegritySeverityWarning,
+		models.IntegrityCheckBalanceDrift:    models.IntegritySeverityCritical,
+		models.IntegrityCheckTotalsDrift:     models.IntegritySeverityWarning,
+		models.IntegrityCheckOrphanLine:      models.IntegritySeverityError,
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("expected %d issues, got %d: %+v", len(want), len(report.Issues), report.Issues)
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity != want[issue.Check] {
+			t.Errorf("%s: expected severity %s, got %s", issue.Check, want[issue.Check], issue.Severity)
+		}
+		if issue.Check == models.IntegrityCheckBalanceDrift && (issue.Expected != 800 || issue.Actual != 500) {
+			t.Errorf("expected balance 800 from lines against 500 stored, got %d and %d", issue.Expected, issue.Actual)
+		}
+	}
+
+	// Most severe first
+	for i := 1; i < len(report.Issues); i++ {
+		if severityRank[report.Issues[i-1].Severity] > severityRank[report.Issues[i].Severity] {
+			t.Errorf("issues not ordered by severity: %+v", report.Issues)
+		}
+	}
+	if report.Counts[models.IntegritySeverityCritical] != 2 || report.Counts[models.IntegritySeverityWarning] != 2 {
+		t.Errorf("unexpected counts %+v", report.Counts)
+	}
+
+	latest, err := svc.LatestIntegrityReport(context.Background())
+	if err != nil || latest != report {
+		t.Error("expected the check to be kept as the latest report")
+	}
+}
+
+func TestCheckIntegrity_TruncatesLargeResults(t *testing.T) {
+	repo := &mockIntegrityRepository{}
+	for i := 0; i <= models.IntegrityIssueLimit; i++ {
+		repo.entries = append(repo.entries, &models.EntryTotals{EntryID: uuid.New().String(), Status: models.EntryStatusPosted, Lines: 2, Debits: 1, Credits: 2})
+	}
+	svc := newIntegrityTestService(repo)
+
+	report, err := svc.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Truncated || len(report.Issues) != models.IntegrityIssueLimit {
+		t.Errorf("expected %d issues and truncated, got %d (truncated=%v)", models.IntegrityIssueLimit, len(report.Issues), report.Truncated)
+	}
+}
+
+func TestRepairIntegrity(t *testing.T) {
+	newRepo := func() *mockIntegrityRepository {
+		return &mockIntegrityRepository{
+			entries: []*models.EntryTotals{
+				{EntryID: "e1", EntryNumber: "JE-2025-00001", Status: models.EntryStatusPosted, Lines: 2, Debits: 1000, Credits: 900},
+			},
+			accounts: []*models.AccountTotals{
+				{AccountID: "a1", AccountCode: "2100", Type: models.AccountTypeLiability, Balance: 500, DebitTotal: 200, CreditTotal: 1000, LineDebits: 200, LineCredits: 1000},
+				{AccountID: "a2", AccountCode: "1000", Type: models.AccountTypeAsset, Balance: 300, DebitTotal: 900, CreditTotal: 700, LineDebits: 1000, LineCredits: 700},
+			},
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("requires a reason", func(t *testing.T) {
+		svc := newIntegrityTestService(newRepo())
+		if _, err := svc.RepairIntegrity(ctx, &models.IntegrityRepairRequest{}, "admin"); err == nil || err.Code != errors.ErrCodeValidation {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		repo := newRepo()
+		svc := newIntegrityTestService(repo)
+
+		result, err := svc.RepairIntegrity(ctx, &models.IntegrityRepairRequest{Reason: "drift", DryRun: true}, "admin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Repaired) != 2 || len(repo.repairs) != 0 {
+			t.Fatalf("expected 2 planned repairs and none made, got %d and %d", len(result.Repaired), len(repo.repairs))
+		}
+		if planned := result.Repaired[0]; planned.PreviousBalance != 500 || planned.Balance != 800 {
+			t.Errorf("expected balance 500 to become 800, got %d to %d", planned.PreviousBalance, planned.Balance)
+		}
+	})
+
+	t.Run("repairs drift and returns the rest", func(t *testing.T) {
+		repo := newRepo()
+		svc := newIntegrityTestService(repo)
+
+		result, err := svc.RepairIntegrity(ctx, &models.IntegrityRepairRequest{Reason: "drift"}, "admin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Repaired) != 2 || len(repo.repairs) != 2 {
+			t.Fatalf("expected 2 repairs, got %d", len(result.Repaired))
+		}
+		if len(result.Unrepairable) != 1 || result.Unrepairable[0].Check != models.IntegrityCheckUnbalancedEntry {
+			t.Errorf("expected the unbalanced entry to be unrepairable, got %+v", result.Unrepairable)
+		}
+
+		latest, _ := svc.LatestIntegrityReport(ctx)
+		for _, issue := range latest.Issues {
+			if issue.Repairable {
+				t.Errorf("expected the latest report to be refreshed, still lists %+v", issue)
+			}
+		}
+	})
+
+	t.Run("repairs only the selected accounts", func(t *testing.T) {
+		repo := newRepo()
+		svc := newIntegrityTestService(repo)
+
+		result, err := svc.RepairIntegrity(ctx, &models.IntegrityRepairRequest{Reason: "drift", AccountIDs: []string{"a2"}}, "admin")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Repaired) != 1 || result.Repaired[0].AccountID != "a2" {
+			t.Fatalf("expected only a2 to be repaired, got %+v", result.Repaired)
+		}
+		if repair := result.Repaired[0]; repair.PreviousDebitTotal != 900 || repair.DebitTotal != 1000 {
+			t.Errorf("expected debit total 900 to become 1000, got %d to %d", repair.PreviousDebitTotal, repair.DebitTotal)
+		}
+	})
+
+	t.Run("rejects accounts that do not drift", func(t *testing.T) {
+		svc := newIntegrityTestService(newRepo())
+		_, err := svc.RepairIntegrity(ctx, &models.IntegrityRepairRequest{Reason: "drift", AccountIDs: []string{"a9"}}, "admin")
+		if err == nil || err.Code != errors.ErrCodeValidation {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	})
+}
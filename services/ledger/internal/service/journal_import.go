@@ -0,0 +1,410 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// JournalImportRepositoryInterface defines the interface for journal import repository operations.
+type JournalImportRepositoryInterface interface {
+	Create(ctx context.Context, job *models.JournalImport, entries []models.JournalImportEntry, rejected []models.JournalImportError) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.JournalImport, *errors.Error)
+	// ClaimNext leases the oldest unfinished import, or returns nil if there is none.
+	ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*models.JournalImport, *errors.Error)
+	ListEntries(ctx context.Context, id string, from, limit int) ([]models.JournalImportEntry, *errors.Error)
+	// ImportEntry creates the entry at index and advances the import, atomically.
+	// Returns a conflict if the entry was already processed.
+	ImportEntry(ctx context.Context, id string, index int, entry *models.JournalEntry, lines []models.LedgerLine) *errors.Error
+	FailEntry(ctx context.Context, id string, index int, rowErr models.JournalImportError) *errors.Error
+	Release(ctx context.Context, id string) *errors.Error
+	Complete(ctx context.Context, id string) *errors.Error
+}
+
+const (
+	// MaxJournalImportEntries bounds the entries of one import.
+	MaxJournalImportEntries = 5000
+
+	// journalImportBatchSize is the number of entries created per lease.
+	journalImportBatchSize = 100
+
+	// journalImportLease is how long a worker holds an import while it
+	// creates a batch; a crashed worker's import is picked up after it.
+	journalImportLease = 2 * time.Minute
+)
+
+// journalImportCSVColumns are the required CSV columns. Optional columns are
+// type, reference_type, reference_id and line_description. Entry-level
+// columns are read from an entry's first row.
+var journalImportCSVColumns = []string{"entry", "description", "account_id", "debit_amount", "credit_amount"}
+
+// importRow is an uploaded entry with the row it came from and, if it cannot
+// be imported, why.
+type importRow struct {
+	row   int
+	entry models.CreateJournalEntryRequest
+	err   string
+}
+
+// SetJournalImports enables bulk journal entry imports.
+func (s *LedgerService) SetJournalImports(repo JournalImportRepositoryInterface) {
+	s.imports = repo
+}
+
+// JournalImportsEnabled reports whether bulk journal entry imports are enabled.
+func (s *LedgerService) JournalImportsEnabled() bool {
+	return s.imports != nil
+}
+
+// CreateJournalImport validates an uploaded batch of journal entries and
+// queues the valid ones for import. Entries are created as drafts by
+// ProcessJournalImports; entries rejected here are reported as row errors on
+// the import. Accounts may be given by ID or by code.
+func (s *LedgerService) CreateJournalImport(ctx context.Context, format models.ImportFormat, data []byte, createdBy string) (*models.JournalImport, *errors.Error) {
+	if s.imports == nil {
+		return nil, errors.Internal("journal imports are not enabled")
+	}
+
+	var rows []importRow
+	var parseErr *errors.Error
+	switch format {
+	case models.ImportFormatCSV:
+		rows, parseErr = parseJournalImportCSV(data)
+	case models.ImportFormatJSON:
+		rows, parseErr = parseJournalImportJSON(data)
+	default:
+		return nil, errors.Validation("import format must be csv or json")
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if len(rows) == 0 {
+		return nil, errors.Validation("import has no entries")
+	}
+	if len(rows) > MaxJournalImportEntries {
+		return nil, errors.Validation(fmt.Sprintf("import has %d entries, at most %d are allowed", len(rows), MaxJournalImportEntries))
+	}
+
+	accounts := make(map[string]*models.Account)
+	queued := make([]models.JournalImportEntry, 0, len(rows))
+	rejected := make([]models.JournalImportError, 0)
+	for i := range rows {
+		row := &rows[i]
+		if row.err == "" {
+			message, err := s.validateImportEntry(ctx, &row.entry, accounts)
+			if err != nil {
+				return nil, err
+			}
+			row.err = message
+		}
+
+		if row.err != "" {
+			rejected = append(rejected, models.JournalImportError{Row: row.row, ReferenceID: row.entry.ReferenceID, Message: row.err})
+			continue
+		}
+		queued = append(queued, models.JournalImportEntry{Row: row.row, Entry: row.entry})
+	}
+
+	job := &models.JournalImport{
+		Format:       format,
+		Status:       models.ImportStatusPending,
+		TotalEntries: len(rows),
+		CreatedBy:    createdBy,
+	}
+	if len(queued) == 0 {
+		job.Status = models.ImportStatusCompleted
+	}
+	if err := s.imports.Create(ctx, job, queued, rejected); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetJournalImport retrieves an import with its progress and row errors.
+func (s *LedgerService) GetJournalImport(ctx context.Context, id string) (*models.JournalImport, *errors.Error) {
+	if s.imports == nil {
+		return nil, errors.Internal("journal imports are not enabled")
+	}
+	return s.imports.GetByID(ctx, id)
+}
+
+// ProcessJournalImports creates queued import entries in batches until no
+// import has work left or ctx is done. Each entry is created in its own
+// transaction with the import's progress, so a failed entry is reported
+// without stopping the others and a restart never duplicates an entry.
+// Returns the number of entries processed.
+func (s *LedgerService) ProcessJournalImports(ctx context.Context) (int, *errors.Error) {
+	if s.imports == nil {
+		return 0, nil
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		now := time.Now()
+		job, err := s.imports.ClaimNext(ctx, now, now.Add(journalImportLease))
+		if err != nil {
+			return processed, err
+		}
+		if job == nil {
+			return processed, nil
+		}
+
+		entries, err := s.imports.ListEntries(ctx, job.ID, job.NextEntry, journalImportBatchSize)
+		if err != nil {
+			return processed, err
+		}
+		for i, queued := range entries {
+			// A failure here leaves the lease to expire, so the batch is retried
+			if err := s.importEntry(ctx, job.ID, job.NextEntry+i, queued); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+
+		if len(entries) < journalImportBatchSize {
+			err = s.imports.Complete(ctx, job.ID)
+		} else {
+			err = s.imports.Release(ctx, job.ID)
+		}
+		if err != nil {
+			return processed, err
+		}
+	}
+	return processed, nil
+}
+
+// importEntry creates one queued entry, or records why it cannot be created.
+// Accounts are checked again since they may have changed since the upload.
+// Only database failures are returned.
+func (s *LedgerService) importEntry(ctx context.Context, importID string, index int, queued models.JournalImportEntry) *errors.Error {
+	queued.Entry.UseSuspense = false
+
+	draft, err := s.buildJournalEntry(ctx, &queued.Entry)
+	if err == nil {
+		draft.entry.Metadata[models.ImportIDMetadataKey] = importID
+		draft.entry.Metadata[models.ImportRowMetadataKey] = strconv.Itoa(queued.Row)
+		err = s.imports.ImportEntry(ctx, importID, index, draft.entry, draft.lines)
+	}
+
+	switch {
+	case err == nil:
+		return nil
+	case err.Code == errors.ErrCodeConflict:
+		// Another worker took over the import after our lease expired
+		return nil
+	case err.Code != errors.ErrCodeValidation && err.Code != errors.ErrCodeBadRequest:
+		return err
+	}
+
+	rowErr := models.JournalImportError{Row: queued.Row, ReferenceID: queued.Entry.ReferenceID, Message: err.Message}
+	if failErr := s.imports.FailEntry(ctx, importID, index, rowErr); failErr != nil && failErr.Code != errors.ErrCodeConflict {
+		return failErr
+	}
+	return nil
+}
+
+// validateImportEntry checks an uploaded entry the way the journal entry API
+// would and resolves its accounts to IDs, caching lookups in accounts.
+// Returns why the entry cannot be imported, or "" if it can. Only lookup
+// failures are returned as errors.
+func (s *LedgerService) validateImportEntry(ctx context.Context, req *models.CreateJournalEntryRequest, accounts map[string]*models.Account) (string, *errors.Error) {
+	req.UseSuspense = false
+	if req.Type == "" {
+		req.Type = models.EntryTypeStandard
+	}
+
+	switch req.Type {
+	case models.EntryTypeStandard, models.EntryTypeOpening, models.EntryTypeClosing, models.EntryTypeAdjusting, models.EntryTypeReversing:
+	default:
+		return fmt.Sprintf("invalid entry type %q", req.Type), nil
+	}
+	if n := utf8.RuneCountInString(req.Description); n < 5 || n > 500 {
+		return "description must be between 5 and 500 characters", nil
+	}
+	if len(req.ReferenceType) > 50 || len(req.ReferenceID) > 100 {
+		return "reference_type must be at most 50 and reference_id at most 100 characters", nil
+	}
+	if _, err := req.GetMetadata(); err != nil {
+		return "invalid entry metadata format", nil
+	}
+	if len(req.Lines) < 2 {
+		return "journal entry must have at least 2 lines", nil
+	}
+
+	var totalDebits, totalCredits int64
+	for i := range req.Lines {
+		line := &req.Lines[i]
+		if line.DebitAmount < 0 || line.CreditAmount < 0 {
+			return fmt.Sprintf("line %d: amounts cannot be negative", i), nil
+		}
+		if err := line.Validate(); err != nil {
+			return fmt.Sprintf("line %d: %v", i, err), nil
+		}
+		if _, err := line.GetMetadata(); err != nil {
+			return fmt.Sprintf("line %d: invalid metadata format", i), nil
+		}
+
+		account, err := s.importAccount(ctx, line.AccountID, accounts)
+		if err != nil {
+			return "", err
+		}
+		if account == nil {
+			return fmt.Sprintf("line %d: account %s not found", i, line.AccountID), nil
+		}
+		if account.Status != models.AccountStatusActive {
+			return fmt.Sprintf("line %d: account %s is not active", i, account.Code), nil
+		}
+		line.AccountID = account.ID
+
+		totalDebits += line.DebitAmount
+		totalCredits += line.CreditAmount
+	}
+
+	if totalDebits != totalCredits {
+		return fmt.Sprintf("entry not balanced: debits=%d, credits=%d", totalDebits, totalCredits), nil
+	}
+	return "", nil
+}
+
+// importAccount looks up an account by ID, or by code when ref is not a UUID.
+// Returns nil if there is no such account.
+func (s *LedgerService) importAccount(ctx context.Context, ref string, accounts map[string]*models.Account) (*models.Account, *errors.Error) {
+	if account, ok := accounts[ref]; ok {
+		return account, nil
+	}
+
+	var account *models.Account
+	var err *errors.Error
+	if _, parseErr := uuid.Parse(ref); parseErr == nil {
+		account, err = s.accountRepo.GetByID(ctx, ref)
+	} else {
+		account, err = s.accountRepo.GetByCode(ctx, ref)
+	}
+	if err != nil {
+		if err.Code != errors.ErrCodeNotFound {
+			return nil, err
+		}
+		account = nil
+	}
+
+	accounts[ref] = account
+	return account, nil
+}
+
+// parseJournalImportJSON reads a JSON array of journal entries, in the format
+// of the create journal entry API. Rows are array positions, from 1.
+func parseJournalImportJSON(data []byte) ([]importRow, *errors.Error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Validation("import must be a JSON array of journal entries")
+	}
+
+	rows := make([]importRow, len(raw))
+	for i, item := range raw {
+		rows[i].row = i + 1
+		if err := json.Unmarshal(item, &rows[i].entry); err != nil {
+			rows[i].err = "invalid entry: " + err.Error()
+		}
+	}
+	return rows, nil
+}
+
+// parseJournalImportCSV reads journal entries from CSV with a header row,
+// one ledger line per row. Rows with the same entry value form one entry, in
+// the order entries first appear. Rows are CSV line numbers of each entry's
+// first row.
+func parseJournalImportCSV(data []byte) ([]importRow, *errors.Error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Validation("import must be CSV with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range journalImportCSVColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Validation("csv is missing column " + name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []*importRow
+	byEntry := make(map[string]*importRow)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Validation(fmt.Sprintf("invalid csv: %v", err))
+		}
+		line, _ := reader.FieldPos(0)
+
+		key := field(record, "entry")
+		row, ok := byEntry[key]
+		if !ok || key == "" {
+			row = &importRow{
+				row: line,
+				entry: models.CreateJournalEntryRequest{
+					Type:          models.EntryType(field(record, "type")),
+					Description:   field(record, "description"),
+					ReferenceType: field(record, "reference_type"),
+					ReferenceID:   field(record, "reference_id"),
+				},
+			}
+			if key == "" {
+				row.err = fmt.Sprintf("line %d: entry is required", line)
+			} else {
+				byEntry[key] = row
+			}
+			rows = append(rows, row)
+		}
+
+		debit, debitErr := parseImportAmount(field(record, "debit_amount"))
+		credit, creditErr := parseImportAmount(field(record, "credit_amount"))
+		if (debitErr != nil || creditErr != nil) && row.err == "" {
+			row.err = fmt.Sprintf("line %d: amounts must be whole numbers of the smallest currency unit", line)
+		}
+		row.entry.Lines = append(row.entry.Lines, models.LedgerLineInput{
+			AccountID:    field(record, "account_id"),
+			DebitAmount:  debit,
+			CreditAmount: credit,
+			Description:  field(record, "line_description"),
+		})
+	}
+
+	result := make([]importRow, len(rows))
+	for i, row := range rows {
+		result[i] = *row
+	}
+	return result, nil
+}
+
+// parseImportAmount parses a CSV amount in paise; blank is zero.
+func parseImportAmount(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/google/uuid"
+)
+
+// mockJournalImportRepository keeps one import at a time, creating entries
+// through the journal entry mock.
+type mockJournalImportRepository struct {
+	journalRepo *mockJournalEntryRepository
+	job         *models.JournalImport
+	entries     []models.JournalImportEntry
+	claims      int
+}
+
+var _ JournalImportRepositoryInterface = (*mockJournalImportRepository)(nil)
+
+func (m *mockJournalImportRepository) Create(ctx context.Context, job *models.JournalImport, entries []models.JournalImportEntry, rejected []models.JournalImportError) *errors.Error {
+	job.ID = uuid.New().String()
+	job.FailedEntries = len(rejected)
+	job.Errors = append([]models.JournalImportError(nil), rejected...)
+	m.job = job
+	m.entries = entries
+	return nil
+}
+
+func (m *mockJournalImportRepository) GetByID(ctx context.Context, id string) (*models.JournalImport, *errors.Error) {
+	if m.job == nil || m.job.ID != id {
+		return nil, errors.NotFoundWithID("journal import", id)
+	}
+	return m.job, nil
+}
+
+func (m *mockJournalImportRepository) ClaimNext(ctx context.Context, now, leaseUntil time.Time) (*models.JournalImport, *errors.Error) {
+	if m.job == nil || m.job.Status == models.ImportStatusCompleted {
+		return nil, nil
+	}
+	m.claims++
+	m.job.Status = models.ImportStatusProcessing
+	claimed := *m.job
+	return &claimed, nil
+}
+
+func (m *mockJournalImportRepository) ListEntries(ctx context.Context, id string, from, limit int) ([]models.JournalImportEntry, *errors.Error) {
+	end := min(from+limit, len(m.entries))
+	return m.entries[from:end], nil
+}
+
+func (m *mockJournalImportRepository) advance(index int) *errors.Error {
+	if m.job.NextEntry != index {
+		return errors.Conflict("journal import entry already processed")
+	}
+	m.job.NextEntry++
+	return nil
+}
+
+func (m *mockJournalImportRepository) ImportEntry(ctx context.Context, id string, index int, entry *models.JournalEntry, lines []models.LedgerLine) *errors.Error {
+	if err := m.advance(index); err != nil {
+		return err
+	}
+	m.job.ImportedEntries++
+	return m.journalRepo.Create(ctx, entry, lines)
+}
+
+func (m *mockJournalImportRepository) FailEntry(ctx context.Context, id string, index int, rowErr models.JournalImportError) *errors.Error {
+	if err := m.advance(index); err != nil {
+		return err
+	}
+	m.job.FailedEntries++
+	m.job.Errors = append(m.job.Errors, rowErr)
+	return nil
+}
+
+func (m *mockJournalImportRepository) Release(ctx context.Context, id string) *errors.Error {
+	return nil
+}
+
+func (m *mockJournalImportRepository) Complete(ctx context.Context, id string) *errors.Error {
+	m.job.Status = models.ImportStatusCompleted
+	m.entries = nil
+	return nil
+}
+
+func setupImportTest(t *testing.T) (*LedgerService, *mockJournalEntryRepository, *mockJournalImportRepository) {
+	t.Helper()
+	service, accountRepo, journalRepo := setupTestService()
+	importRepo := &mockJournalImportRepository{journalRepo: journalRepo}
+	service.SetJournalImports(importRepo)
+
+	for _, account := range []*models.Account{
+		createTestAccount("11111111-1111-1111-1111-111111111111", "1000", "Cash", models.AccountTypeAsset),
+		createTestAccount("22222222-2222-2222-2222-222222222222", "4000", "Revenue", models.AccountTypeRevenue),
+	} {
+		accountRepo.accounts[account.ID] = account
+	}
+	closed := createTestAccount("33333333-3333-3333-3333-333333333333", "5000", "Closed", models.AccountTypeExpense)
+	closed.Status = models.AccountStatusClosed
+	accountRepo.accounts[closed.ID] = closed
+
+	return service, journalRepo, importRepo
+}
+
+func TestCreateJournalImport_CSV(t *testing.T) {
+	service, _, importRepo := setupImportTest(t)
+
+	csvData := strings.Join([]string{
+		"entry,type,description,reference_id,account_id,debit_amount,credit_amount,line_description",
+		"A,opening,Opening cash balance,LEG-1,1000,50000,,Cash",
+		"A,,,,4000,,30000,Equity",
+		"B,,Unbalanced sale,LEG-2,1000,100,,",
+		"B,,,,4000,,90,",
+		"C,,Unknown account,LEG-3,9999,100,,",
+		"C,,,,4000,,100,",
+		"D,,Bad amount here,LEG-4,1000,1.5,,",
+		"D,,,,4000,,100,",
+		"E,,Closed account,LEG-5,33333333-3333-3333-3333-333333333333,100,,",
+		"E,,,,1000,,100,",
+		"A,,,,22222222-2222-2222-2222-222222222222,,20000,Rows of an entry need not be adjacent",
+	}, "\n")
+
+	job, err := service.CreateJournalImport(context.Background(), models.ImportFormatCSV, []byte(csvData), uuid.New().String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if job.TotalEntries != 5 || job.FailedEntries != 4 || job.Status != models.ImportStatusPending {
+		t.Fatalf("expected 5 entries with 4 rejected and a pending import, got %+v", job)
+	}
+
+	if len(importRepo.entries) != 1 || len(importRepo.entries[0].Entry.Lines) != 3 || importRepo.entries[0].Row != 2 {
+		t.Fatalf("expected entry A queued from row 2 with 3 lines, got %+v", importRepo.entries)
+	}
+
+	wantRows := map[int]string{4: "LEG-2", 6: "LEG-3", 8: "LEG-4", 10: "LEG-5"}
+	if len(job.Errors) != len(wantRows) {
+		t.Fatalf("expected %d row errors, got %+v", len(wantRows), job.Errors)
+	}
+	for _, rowErr := range job.Errors {
+		if wantRows[rowErr.Row] != rowErr.ReferenceID {
+			t.Errorf("row %d: expected reference %q, got %q (%s)", rowErr.Row, wantRows[rowErr.Row], rowErr.ReferenceID, rowErr.Message)
+		}
+	}
+}
+
+func TestCreateJournalImport_CSVResolvesAccountCodes(t *testing.T) {
+	service, _, importRepo := setupImportTest(t)
+
+	csvData := "entry,description,account_id,debit_amount,credit_amount\n" +
+		"1,Legacy sale 1,1000,2500,0\n" +
+		"1,Legacy sale 1,4000,0,2500\n" +
+		"2,Legacy sale 2,1000,700,\n" +
+		"2,,4000,,700\n"
+
+	job, err := service.CreateJournalImport(context.Background(), models.ImportFormatCSV, []byte(csvData), uuid.New().String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.FailedEntries != 0 || len(importRepo.entries) != 2 {
+		t.Fatalf("expected 2 queued entries, got %d (errors: %+v)", len(importRepo.entries), job.Errors)
+	}
+
+	queued := importRepo.entries[1]
+	if queued.Row != 4 || queued.Entry.Type != models.EntryTypeStandard {
+		t.Errorf("expected row 4 as a standard entry, got row %d type %s", queued.Row, queued.Entry.Type)
+	}
+	if queued.Entry.Lines[0].AccountID != "11111111-1111-1111-1111-111111111111" {
+		t.Errorf("expected account code resolved to ID, got %s", queued.Entry.Lines[0].AccountID)
+	}
+}
+
+func TestCreateJournalImport_RejectsMalformedUploads(t *testing.T) {
+	service, _, _ := setupImportTest(t)
+	ctx := context.Background()
+
+	cases := []struct {
+		name   string
+		format models.ImportFormat
+		data   string
+	}{
+		{"json object", models.ImportFormatJSON, `{"description": "not an array"}`},
+		{"empty json array", models.ImportFormatJSON, `[]`},
+		{"csv missing column", models.ImportFormatCSV, "entry,description,account_id,debit_amount\nA,Sale,1000,100\n"},
+		{"csv header only", models.ImportFormatCSV, "entry,description,account_id,debit_amount,credit_amount\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := service.CreateJournalImport(ctx, tc.format, []byte(tc.data), uuid.New().String())
+			if err == nil || err.Code != errors.ErrCodeValidation {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessJournalImports_CreatesEntriesInBatches(t *testing.T) {
+	service, journalRepo, importRepo := setupImportTest(t)
+	ctx := context.Background()
+
+	entries := make([]map[string]any, 0, 150)
+	for i := range 150 {
+		entries = append(entries, map[string]any{
+			"type":         "standard",
+			"description":  fmt.Sprintf("Legacy entry %d", i),
+			"reference_id": fmt.Sprintf("LEG-%d", i),
+			"lines": []map[string]any{
+				{"account_id": "1000", "debit_amount": 100},
+				{"account_id": "4000", "credit_amount": 100},
+			},
+		})
+	}
+	entries[3]["lines"] = []map[string]any{{"account_id": "1000", "debit_amount": 100}}
+	data, _ := json.Marshal(entries)
+
+	job, err := service.CreateJournalImport(ctx, models.ImportFormatJSON, data, uuid.New().String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.FailedEntries != 1 || job.Errors[0].Row != 4 {
+		t.Fatalf("expected row 4 rejected on upload, got %+v", job.Errors)
+	}
+
+	// The last queued entry now books to a closed account, so it fails on import
+	importRepo.entries[len(importRepo.entries)-1].Entry.Lines[1].AccountID = "33333333-3333-3333-3333-333333333333"
+
+	processed, err := service.ProcessJournalImports(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if processed != 149 {
+		t.Errorf("expected 149 entries processed, got %d", processed)
+	}
+	if importRepo.claims != 2 {
+		t.Errorf("expected 2 batches, got %d", importRepo.claims)
+	}
+	if job.Status != models.ImportStatusCompleted || job.ImportedEntries != 148 || job.FailedEntries != 2 {
+		t.Errorf("expected completed import with 148 imported and 2 failed, got %+v", job)
+	}
+	if len(journalRepo.entries) != 148 {
+		t.Errorf("expected 148 journal entries, got %d", len(journalRepo.entries))
+	}
+	for _, entry := range journalRepo.entries {
+		if entry.Status != models.EntryStatusDraft || entry.Metadata[models.ImportIDMetadataKey] != job.ID {
+			t.Fatalf("expected draft entries tagged with the import, got %s %v", entry.Status, entry.Metadata)
+		}
+	}
+
+	// Nothing left to do
+	processed, err = service.ProcessJournalImports(ctx)
+	if err != nil || processed != 0 {
+		t.Errorf("expected no further work, got %d, %v", processed, err)
+	}
+}
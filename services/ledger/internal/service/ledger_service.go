@@ -3,11 +3,18 @@ package service
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 )
 
+// OperationVoidJournalEntry is the approval operation for journal entry voids.
+const OperationVoidJournalEntry = "journal_entry.void"
+
 // AccountRepositoryInterface defines the interface for account repository operations.
 type AccountRepositoryInterface interface {
 	Create(ctx context.Context, account *models.Account) *errors.Error
@@ -16,6 +23,7 @@ type AccountRepositoryInterface interface {
 	List(ctx context.Context, accountType *models.AccountType, status *models.AccountStatus, limit, offset int) ([]*models.Account, *errors.Error)
 	Update(ctx context.Context, account *models.Account) *errors.Error
 	GetBalance(ctx context.Context, accountID string) (int64, *errors.Error)
+	DeleteUnused(ctx context.Context, id string) *errors.Error
 }
 
 // JournalEntryRepositoryInterface defines the interface for journal entry repository operations.
@@ -23,6 +31,8 @@ type JournalEntryRepositoryInterface interface {
 	Create(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine) *errors.Error
 	GetByID(ctx context.Context, id string) (*models.JournalEntry, *errors.Error)
 	List(ctx context.Context, status *models.EntryStatus, limit, offset int) ([]*models.JournalEntry, *errors.Error)
+	ListByReference(ctx context.Context, referenceType, referenceID string) ([]*models.JournalEntry, *errors.Error)
+	ListByReferences(ctx context.Context, referenceType string, referenceIDs []string) ([]*models.JournalEntry, *errors.Error)
 	Post(ctx context.Context, entryID, postedBy string) *errors.Error
 	Void(ctx context.Context, entryID, voidedBy, voidReason string) *errors.Error
 }
@@ -31,6 +41,17 @@ type JournalEntryRepositoryInterface interface {
 type LedgerService struct {
 	accountRepo AccountRepositoryInterface
 	journalRepo JournalEntryRepositoryInterface
+	approvals   *approval.Manager                // Optional: voids need a second admin when set
+	suspense    SuspenseRepositoryInterface      // Optional: enables the suspense account workflow
+	imports     JournalImportRepositoryInterface // Optional: enables bulk journal entry imports
+
+	fx           FXRepositoryInterface // Optional: enables FX rates and revaluation
+	baseCurrency sharedModels.Currency // Reporting currency for FX revaluation
+
+	integrity     IntegrityRepositoryInterface           // Optional: enables the integrity check and repairs
+	lastIntegrity atomic.Pointer[models.IntegrityReport] // Latest integrity check
+
+	publisher *events.Publisher // Optional: publishes posted, voided and reversed entries
 }
 
 // NewLedgerService creates a new ledger service.
@@ -44,6 +65,17 @@ func NewLedgerService(
 	}
 }
 
+// SetApprovals requires a second admin to approve journal entry voids.
+func (s *LedgerService) SetApprovals(approvals *approval.Manager) {
+	approvals.Register(OperationVoidJournalEntry, s.executeVoid)
+	s.approvals = approvals
+}
+
+// Approvals returns the approval manager, or nil if voids need no approval.
+func (s *LedgerService) Approvals() *approval.Manager {
+	return s.approvals
+}
+
 // CreateAccount creates a new ledger account.
 func (s *LedgerService) CreateAccount(ctx context.Context, req *models.CreateAccountRequest) (*models.Account, *errors.Error) {
 	// Validate parent account exists if specified
@@ -92,6 +124,20 @@ func (s *LedgerService) GetAccountByCode(ctx context.Context, code string) (*mod
 	return s.accountRepo.GetByCode(ctx, code)
 }
 
+// DeleteUnusedAccount deletes an account nothing has been posted to. Services
+// that provision accounts use it to undo a provisioning whose other half
+// failed; accounts with postings must be closed instead.
+func (s *LedgerService) DeleteUnusedAccount(ctx context.Context, accountID string) *errors.Error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if account.DebitTotal != 0 || account.CreditTotal != 0 {
+		return errors.Conflict("account has postings and cannot be deleted; close it instead")
+	}
+	return s.accountRepo.DeleteUnused(ctx, accountID)
+}
+
 // ListAccounts retrieves accounts with filters.
 func (s *LedgerService) ListAccounts(ctx context.Context, accountType *models.AccountType, status *models.AccountStatus, limit, offset int) ([]*models.Account, *errors.Error) {
 	return s.accountRepo.List(ctx, accountType, status, limit, offset)
@@ -127,12 +173,46 @@ func (s *LedgerService) UpdateAccount(ctx context.Context, accountID string, req
 // CreateJournalEntry creates a new journal entry.
 // This validates the entry follows double-entry bookkeeping rules.
 func (s *LedgerService) CreateJournalEntry(ctx context.Context, req *models.CreateJournalEntryRequest) (*models.JournalEntry, *errors.Error) {
+	draft, err := s.buildJournalEntry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(draft.parked) > 0 || draft.imbalance != nil {
+		if parkErr := s.createSuspenseEntry(ctx, draft.entry, draft.lines, draft.parked, draft.imbalance); parkErr != nil {
+			return nil, parkErr
+		}
+		return draft.entry, nil
+	}
+
+	// Create in repository (within transaction)
+	if createErr := s.journalRepo.Create(ctx, draft.entry, draft.lines); createErr != nil {
+		return nil, createErr
+	}
+
+	return draft.entry, nil
+}
+
+// draftEntry is a validated journal entry ready to be created.
+type draftEntry struct {
+	entry     *models.JournalEntry
+	lines     []models.LedgerLine
+	parked    map[int]*models.SuspenseItem // Lines to book to suspense, by index
+	imbalance *models.SuspenseItem         // Suspense line that balances the entry, if any
+}
+
+// buildJournalEntry validates a journal entry request against the chart of
+// accounts and builds the draft entry and its lines. With UseSuspense, it also
+// collects what must be parked in suspense.
+func (s *LedgerService) buildJournalEntry(ctx context.Context, req *models.CreateJournalEntryRequest) (*draftEntry, *errors.Error) {
 	// Validate lines
 	if len(req.Lines) < 2 {
 		return nil, errors.Validation("journal entry must have at least 2 lines")
 	}
 
-	// Validate each line
+	// Validate each line. With UseSuspense, lines that cannot be booked to
+	// their account are parked in suspense instead.
+	parked := make(map[int]*models.SuspenseItem)
 	for i, line := range req.Lines {
 		if err := line.Validate(); err != nil {
 			return nil, errors.Validation(fmt.Sprintf("line %d: %v", i, err))
@@ -141,11 +221,19 @@ func (s *LedgerService) CreateJournalEntry(ctx context.Context, req *models.Crea
 		// Verify account exists
 		account, accErr := s.accountRepo.GetByID(ctx, line.AccountID)
 		if accErr != nil {
+			if req.UseSuspense && s.suspense != nil && accErr.Code == errors.ErrCodeNotFound {
+				parked[i] = parkLine(line, models.SuspenseReasonUnknownAccount, fmt.Sprintf("line %d: account %s not found", i, line.AccountID))
+				continue
+			}
 			return nil, errors.Validation(fmt.Sprintf("line %d: invalid account", i))
 		}
 
 		// Verify account is active
 		if account.Status != models.AccountStatusActive {
+			if req.UseSuspense && s.suspense != nil {
+				parked[i] = parkLine(line, models.SuspenseReasonInactiveAccount, fmt.Sprintf("line %d: account %s is %s", i, account.Code, account.Status))
+				continue
+			}
 			return nil, errors.Validation(fmt.Sprintf("line %d: account %s is not active", i, account.Code))
 		}
 	}
@@ -157,8 +245,12 @@ func (s *LedgerService) CreateJournalEntry(ctx context.Context, req *models.Crea
 		totalCredits += line.CreditAmount
 	}
 
+	var imbalance *models.SuspenseItem
 	if totalDebits != totalCredits {
-		return nil, errors.Validation(fmt.Sprintf("entry not balanced: debits=%d, credits=%d", totalDebits, totalCredits))
+		if !req.UseSuspense || s.suspense == nil {
+			return nil, errors.Validation(fmt.Sprintf("entry not balanced: debits=%d, credits=%d", totalDebits, totalCredits))
+		}
+		imbalance = balanceInSuspense(totalDebits, totalCredits)
 	}
 
 	// Parse entry metadata
@@ -195,12 +287,7 @@ func (s *LedgerService) CreateJournalEntry(ctx context.Context, req *models.Crea
 		}
 	}
 
-	// Create in repository (within transaction)
-	if createErr := s.journalRepo.Create(ctx, entry, lines); createErr != nil {
-		return nil, createErr
-	}
-
-	return entry, nil
+	return &draftEntry{entry: entry, lines: lines, parked: parked, imbalance: imbalance}, nil
 }
 
 // GetJournalEntry retrieves a journal entry with its lines.
@@ -213,6 +300,129 @@ func (s *LedgerService) ListJournalEntries(ctx context.Context, status *models.E
 	return s.journalRepo.List(ctx, status, limit, offset)
 }
 
+// FindJournalEntriesByReference retrieves the journal entries recorded for a
+// referenced entity, such as the entries created for a transaction.
+func (s *LedgerService) FindJournalEntriesByReference(ctx context.Context, referenceType, referenceID string) ([]*models.JournalEntry, *errors.Error) {
+	if referenceType == "" || referenceID == "" {
+		return nil, errors.BadRequest("reference_type and reference_id are required")
+	}
+	return s.journalRepo.ListByReference(ctx, referenceType, referenceID)
+}
+
+// VerifyReferences confirms that each referenced entity, such as a completed
+// transaction, has exactly one posted, balanced journal entry for the amount
+// the caller expects. Every reference gets a status; entries found are
+// summarized so discrepancies can be drilled into.
+func (s *LedgerService) VerifyReferences(ctx context.Context, req *models.VerifyReferencesRequest) (*models.VerificationReport, *errors.Error) {
+	referenceType := req.ReferenceType
+	if referenceType == "" {
+		referenceType = "transaction"
+	}
+	if len(req.References) == 0 {
+		return nil, errors.BadRequest("at least one reference is required")
+	}
+	if len(req.References) > models.MaxVerificationBatch {
+		return nil, errors.BadRequest(fmt.Sprintf("at most %d references can be verified at once", models.MaxVerificationBatch))
+	}
+
+	ids := make([]string, 0, len(req.References))
+	for _, ref := range req.References {
+		if ref.ReferenceID == "" {
+			return nil, errors.BadRequest("reference_id is required")
+		}
+		ids = append(ids, ref.ReferenceID)
+	}
+
+	entries, err := s.journalRepo.ListByReferences(ctx, referenceType, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byReference := make(map[string][]*models.JournalEntry)
+	for _, entry := range entries {
+		byReference[entry.ReferenceID] = append(byReference[entry.ReferenceID], entry)
+	}
+
+	report := &models.VerificationReport{
+		ReferenceType: referenceType,
+		ByStatus:      make(map[models.VerificationStatus]int),
+		Results:       make([]*models.ReferenceVerification, 0, len(req.References)),
+	}
+	for _, ref := range req.References {
+		result := verifyReference(ref, byReference[ref.ReferenceID])
+
+		report.Checked++
+		report.ByStatus[result.Status]++
+		if result.Status == models.VerificationMatched {
+			report.Matched++
+			if req.DiscrepanciesOnly {
+				continue
+			}
+		} else {
+			report.Discrepancies++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// verifyReference classifies the entries found for one reference.
+func verifyReference(ref models.ReferenceExpectation, entries []*models.JournalEntry) *models.ReferenceVerification {
+	result := &models.ReferenceVerification{
+		ReferenceID:    ref.ReferenceID,
+		ExpectedAmount: ref.Amount,
+	}
+
+	var posted []models.VerifiedEntry
+	drafts := 0
+	for _, entry := range entries {
+		summary := models.VerifiedEntry{
+			ID:          entry.ID,
+			EntryNumber: entry.EntryNumber,
+			Status:      entry.Status,
+		}
+		for _, line := range entry.Lines {
+			summary.TotalDebits += line.DebitAmount
+			summary.TotalCredits += line.CreditAmount
+		}
+		result.Entries = append(result.Entries, summary)
+
+		switch entry.Status {
+		case models.EntryStatusPosted:
+			posted = append(posted, summary)
+			result.PostedAmount += summary.TotalDebits
+		case models.EntryStatusDraft:
+			drafts++
+		}
+	}
+
+	switch {
+	case len(entries) == 0:
+		result.Status = models.VerificationMissing
+		result.Detail = "no journal entry references this id"
+	case len(posted) == 0 && drafts > 0:
+		result.Status = models.VerificationUnposted
+		result.Detail = fmt.Sprintf("only draft entries found (%d)", drafts)
+	case len(posted) == 0:
+		result.Status = models.VerificationReversed
+		result.Detail = "all entries were voided or reversed"
+	case len(posted) > 1:
+		result.Status = models.VerificationDuplicate
+		result.Detail = fmt.Sprintf("%d posted entries", len(posted))
+	case posted[0].TotalDebits != posted[0].TotalCredits:
+		result.Status = models.VerificationUnbalanced
+		result.Detail = fmt.Sprintf("debits %d != credits %d", posted[0].TotalDebits, posted[0].TotalCredits)
+	case result.PostedAmount != ref.Amount:
+		result.Status = models.VerificationAmountMismatch
+		result.Detail = fmt.Sprintf("posted %d, expected %d", result.PostedAmount, ref.Amount)
+	default:
+		result.Status = models.VerificationMatched
+	}
+
+	return result
+}
+
 // PostJournalEntry posts a draft journal entry to the ledger.
 // This makes the entry permanent and updates account balances.
 func (s *LedgerService) PostJournalEntry(ctx context.Context, entryID, postedBy string) (*models.JournalEntry, *errors.Error) {
@@ -238,7 +448,12 @@ func (s *LedgerService) PostJournalEntry(ctx context.Context, entryID, postedBy
 	}
 
 	// Return updated entry
-	return s.journalRepo.GetByID(ctx, entryID)
+	posted, err := s.journalRepo.GetByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishPosted(posted)
+	return posted, nil
 }
 
 // VoidJournalEntry voids a posted journal entry.
@@ -261,7 +476,40 @@ func (s *LedgerService) VoidJournalEntry(ctx context.Context, entryID, voidedBy,
 	}
 
 	// Return updated entry
-	return s.journalRepo.GetByID(ctx, entryID)
+	voided, err := s.journalRepo.GetByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+	s.publishVoided(voided)
+	return voided, nil
+}
+
+// RequestVoidJournalEntry stores a void for a second admin to approve. The
+// entry is checked now so obviously invalid requests never reach a reviewer.
+func (s *LedgerService) RequestVoidJournalEntry(ctx context.Context, req *models.VoidJournalEntryRequest, requestedBy string) (*approval.Approval, *errors.Error) {
+	if s.approvals == nil {
+		return nil, errors.Internal("approvals are not enabled")
+	}
+
+	entry, err := s.journalRepo.GetByID(ctx, req.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != models.EntryStatusPosted {
+		return nil, errors.BadRequest("only posted entries can be voided")
+	}
+
+	summary := fmt.Sprintf("Void journal entry %s: %s", entry.EntryNumber, req.VoidReason)
+	return s.approvals.Request(ctx, OperationVoidJournalEntry, entry.ID, requestedBy, summary, req)
+}
+
+// executeVoid runs an approved journal entry void.
+func (s *LedgerService) executeVoid(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	var req models.VoidJournalEntryRequest
+	if err := a.DecodePayload(&req); err != nil {
+		return nil, err
+	}
+	return s.VoidJournalEntry(ctx, req.EntryID, req.VoidedBy, req.VoidReason)
 }
 
 // ReverseJournalEntry creates a reversing entry for a posted journal entry.
@@ -312,6 +560,7 @@ func (s *LedgerService) ReverseJournalEntry(ctx context.Context, entryID, revers
 	// TODO: Mark original entry as reversed and link to reversal
 	// This would require an UPDATE on the original entry in the repository
 
+	s.publishReversed(originalEntry, reversalEntry, reversedBy, reason)
 	return reversalEntry, nil
 }
 
@@ -2,9 +2,11 @@ package service
 
 import (
 	"context"
+	"sort"
 	"testing"
 
 	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/google/uuid"
 )
@@ -66,7 +68,29 @@ func (m *mockAccountRepository) Update(ctx context.Context, account *models.Acco
 }
 
 func (m *mockAccountRepository) List(ctx context.Context, accountType *models.AccountType, status *models.AccountStatus, limit, offset int) ([]*models.Account, *errors.Error) {
-	return nil, nil
+	result := make([]*models.Account, 0)
+	for _, account := range m.accounts {
+		if (accountType == nil || account.Type == *accountType) && (status == nil || account.Status == *status) {
+			result = append(result, account)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Code < result[j].Code })
+	if offset >= len(result) {
+		return []*models.Account{}, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockAccountRepository) DeleteUnused(ctx context.Context, id string) *errors.Error {
+	if _, ok := m.accounts[id]; !ok {
+		return errors.NotFoundWithID("account", id)
+	}
+	delete(m.accounts, id)
+	return nil
 }
 
 func (m *mockAccountRepository) GetBalance(ctx context.Context, accountID string) (int64, *errors.Error) {
@@ -146,6 +170,30 @@ func (m *mockJournalEntryRepository) List(ctx context.Context, status *models.En
 	return nil, nil
 }
 
+func (m *mockJournalEntryRepository) ListByReference(ctx context.Context, referenceType, referenceID string) ([]*models.JournalEntry, *errors.Error) {
+	result := make([]*models.JournalEntry, 0)
+	for _, entry := range m.entries {
+		if entry.ReferenceType == referenceType && entry.ReferenceID == referenceID {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockJournalEntryRepository) ListByReferences(ctx context.Context, referenceType string, referenceIDs []string) ([]*models.JournalEntry, *errors.Error) {
+	wanted := make(map[string]bool, len(referenceIDs))
+	for _, id := range referenceIDs {
+		wanted[id] = true
+	}
+	result := make([]*models.JournalEntry, 0)
+	for _, entry := range m.entries {
+		if entry.ReferenceType == referenceType && wanted[entry.ReferenceID] {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
 // =====================================================================
 // Test Helpers
 // =====================================================================
@@ -687,6 +735,66 @@ func TestVoidJournalEntry_Error_NotPosted(t *testing.T) {
 	}
 }
 
+func TestRequestVoidJournalEntry_NeedsSecondAdmin(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	approvals := approval.NewManager("ledger", approval.NewMemoryStore())
+	service.SetApprovals(approvals)
+	ctx := context.Background()
+
+	entry := &models.JournalEntry{
+		ID:          uuid.New().String(),
+		EntryNumber: "JE-2025-00001",
+		Type:        models.EntryTypeStandard,
+		Status:      models.EntryStatusPosted,
+		Description: "Test entry",
+	}
+	journalRepo.entries[entry.ID] = entry
+
+	pending, err := service.RequestVoidJournalEntry(ctx, &models.VoidJournalEntryRequest{
+		EntryID:    entry.ID,
+		VoidedBy:   "maker",
+		VoidReason: "duplicate posting",
+	}, "maker")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if entry.Status != models.EntryStatusPosted {
+		t.Fatal("expected entry to stay posted until approved")
+	}
+
+	if _, err := approvals.Approve(ctx, pending.ID, "maker", nil); err == nil {
+		t.Fatal("expected self-approval to be refused")
+	}
+
+	decided, err := approvals.Approve(ctx, pending.ID, "checker", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != approval.StatusApproved {
+		t.Errorf("expected approved, got %s (%v)", decided.Status, decided.Error)
+	}
+	if entry.Status != models.EntryStatusVoided || *entry.VoidedBy != "maker" {
+		t.Errorf("expected entry voided by the requester, got %s", entry.Status)
+	}
+}
+
+func TestRequestVoidJournalEntry_Error_NotPosted(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	service.SetApprovals(approval.NewManager("ledger", approval.NewMemoryStore()))
+
+	entry := &models.JournalEntry{ID: uuid.New().String(), Status: models.EntryStatusDraft}
+	journalRepo.entries[entry.ID] = entry
+
+	_, err := service.RequestVoidJournalEntry(context.Background(), &models.VoidJournalEntryRequest{
+		EntryID:    entry.ID,
+		VoidedBy:   "maker",
+		VoidReason: "duplicate posting",
+	}, "maker")
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request before reaching a reviewer, got %v", err)
+	}
+}
+
 // =====================================================================
 // GetAccount Tests
 // =====================================================================
@@ -754,6 +862,51 @@ func TestGetAccountBalance_NotFound(t *testing.T) {
 	}
 }
 
+// =====================================================================
+// DeleteUnusedAccount Tests
+// =====================================================================
+
+func TestDeleteUnusedAccount_Success(t *testing.T) {
+	service, accountRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	account := createTestAccount(uuid.New().String(), "WALLET-1234abcd-INR", "Wallet", models.AccountTypeLiability)
+	accountRepo.accounts[account.ID] = account
+
+	if err := service.DeleteUnusedAccount(ctx, account.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, ok := accountRepo.accounts[account.ID]; ok {
+		t.Error("expected account to be deleted")
+	}
+}
+
+func TestDeleteUnusedAccount_HasPostings(t *testing.T) {
+	service, accountRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	account := createTestAccount(uuid.New().String(), "WALLET-1234abcd-INR", "Wallet", models.AccountTypeLiability)
+	account.CreditTotal = 10000
+	accountRepo.accounts[account.ID] = account
+
+	err := service.DeleteUnusedAccount(ctx, account.ID)
+	if err == nil || err.Code != errors.ErrCodeConflict {
+		t.Fatalf("expected conflict for account with postings, got %v", err)
+	}
+	if _, ok := accountRepo.accounts[account.ID]; !ok {
+		t.Error("expected account to be kept")
+	}
+}
+
+func TestDeleteUnusedAccount_NotFound(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	err := service.DeleteUnusedAccount(context.Background(), uuid.New().String())
+	if err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
 // =====================================================================
 // UpdateAccount Tests
 // =====================================================================
@@ -796,3 +949,112 @@ func TestUpdateAccount_NotFound(t *testing.T) {
 		t.Errorf("expected not found error, got %s", err.Code)
 	}
 }
+
+// =====================================================================
+// VerifyReferences Tests
+// =====================================================================
+
+func verificationEntry(referenceID string, status models.EntryStatus, debit, credit int64) *models.JournalEntry {
+	return &models.JournalEntry{
+		ID:            uuid.New().String(),
+		Status:        status,
+		ReferenceType: "transaction",
+		ReferenceID:   referenceID,
+		Lines: []models.LedgerLine{
+			{DebitAmount: debit},
+			{CreditAmount: credit},
+		},
+	}
+}
+
+func TestVerifyReferences_ClassifiesEachReference(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	ctx := context.Background()
+
+	for _, entry := range []*models.JournalEntry{
+		verificationEntry("tx-ok", models.EntryStatusPosted, 1000, 1000),
+		verificationEntry("tx-draft", models.EntryStatusDraft, 1000, 1000),
+		verificationEntry("tx-voided", models.EntryStatusVoided, 1000, 1000),
+		verificationEntry("tx-dup", models.EntryStatusPosted, 1000, 1000),
+		verificationEntry("tx-dup", models.EntryStatusPosted, 1000, 1000),
+		verificationEntry("tx-unbalanced", models.EntryStatusPosted, 1000, 900),
+		verificationEntry("tx-amount", models.EntryStatusPosted, 900, 900),
+	} {
+		journalRepo.entries[entry.ID] = entry
+	}
+
+	report, err := service.VerifyReferences(ctx, &models.VerifyReferencesRequest{
+		References: []models.ReferenceExpectation{
+			{ReferenceID: "tx-ok", Amount: 1000},
+			{ReferenceID: "tx-missing", Amount: 1000},
+			{ReferenceID: "tx-draft", Amount: 1000},
+			{ReferenceID: "tx-voided", Amount: 1000},
+			{ReferenceID: "tx-dup", Amount: 1000},
+			{ReferenceID: "tx-unbalanced", Amount: 1000},
+			{ReferenceID: "tx-amount", Amount: 1000},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]models.VerificationStatus{
+		"tx-ok":         models.VerificationMatched,
+		"tx-missing":    models.VerificationMissing,
+		"tx-draft":      models.VerificationUnposted,
+		"tx-voided":     models.VerificationReversed,
+		"tx-dup":        models.VerificationDuplicate,
+		"tx-unbalanced": models.VerificationUnbalanced,
+		"tx-amount":     models.VerificationAmountMismatch,
+	}
+	for _, result := range report.Results {
+		if result.Status != want[result.ReferenceID] {
+			t.Errorf("%s: expected %s, got %s", result.ReferenceID, want[result.ReferenceID], result.Status)
+		}
+	}
+	if report.ReferenceType != "transaction" {
+		t.Errorf("expected default reference type, got %s", report.ReferenceType)
+	}
+	if report.Checked != 7 || report.Matched != 1 || report.Discrepancies != 6 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+}
+
+func TestVerifyReferences_DiscrepanciesOnly(t *testing.T) {
+	service, _, journalRepo := setupTestService()
+	ctx := context.Background()
+
+	entry := verificationEntry("tx-ok", models.EntryStatusPosted, 500, 500)
+	journalRepo.entries[entry.ID] = entry
+
+	report, err := service.VerifyReferences(ctx, &models.VerifyReferencesRequest{
+		References: []models.ReferenceExpectation{
+			{ReferenceID: "tx-ok", Amount: 500},
+			{ReferenceID: "tx-missing", Amount: 500},
+		},
+		DiscrepanciesOnly: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].ReferenceID != "tx-missing" {
+		t.Errorf("expected only the missing reference, got %+v", report.Results)
+	}
+	if report.Matched != 1 {
+		t.Errorf("expected matched count to include filtered results, got %d", report.Matched)
+	}
+}
+
+func TestVerifyReferences_RejectsOversizedBatch(t *testing.T) {
+	service, _, _ := setupTestService()
+
+	refs := make([]models.ReferenceExpectation, models.MaxVerificationBatch+1)
+	for i := range refs {
+		refs[i] = models.ReferenceExpectation{ReferenceID: uuid.New().String(), Amount: 1}
+	}
+
+	_, err := service.VerifyReferences(context.Background(), &models.VerifyReferencesRequest{References: refs})
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request, got %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// SuspenseRepositoryInterface defines the interface for suspense item repository operations.
+type SuspenseRepositoryInterface interface {
+	// CreateEntry creates an entry and the suspense items it parks, atomically.
+	CreateEntry(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine, items []*models.SuspenseItem) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.SuspenseItem, *errors.Error)
+	List(ctx context.Context, status *models.SuspenseStatus, limit, offset int) ([]*models.SuspenseItem, int64, *errors.Error)
+	// ListOpen returns open items whose parking entry is posted, oldest first.
+	ListOpen(ctx context.Context) ([]*models.SuspenseItem, *errors.Error)
+	// Claim marks an open item cleared, or returns a conflict if it is not open.
+	Claim(ctx context.Context, id, accountID, clearedBy string) *errors.Error
+	SetClearedEntry(ctx context.Context, id, entryID string) *errors.Error
+	// Release reopens a claimed item whose clearing entry failed.
+	Release(ctx context.Context, id string) *errors.Error
+}
+
+// suspenseAgeBuckets are the age ranges of the suspense report, in days.
+var suspenseAgeBuckets = []models.SuspenseAgeBucket{
+	{Label: "0-7 days", MinDays: 0, MaxDays: 7},
+	{Label: "7-30 days", MinDays: 7, MaxDays: 30},
+	{Label: "30-90 days", MinDays: 30, MaxDays: 90},
+	{Label: "90+ days", MinDays: 90},
+}
+
+// SetSuspense enables the suspense account workflow: entries created with
+// UseSuspense park what cannot be booked, and parked items can be cleared.
+func (s *LedgerService) SetSuspense(repo SuspenseRepositoryInterface) {
+	s.suspense = repo
+}
+
+// SuspenseEnabled reports whether the suspense account workflow is enabled.
+func (s *LedgerService) SuspenseEnabled() bool {
+	return s.suspense != nil
+}
+
+// parkLine records a line that will be booked to suspense instead of its account.
+func parkLine(line models.LedgerLineInput, reason models.SuspenseReason, detail string) *models.SuspenseItem {
+	intended := line.AccountID
+	return &models.SuspenseItem{
+		IntendedAccountID: &intended,
+		DebitAmount:       line.DebitAmount,
+		CreditAmount:      line.CreditAmount,
+		Reason:            reason,
+		Detail:            detail,
+	}
+}
+
+// balanceInSuspense records the suspense line that balances an entry.
+func balanceInSuspense(totalDebits, totalCredits int64) *models.SuspenseItem {
+	item := &models.SuspenseItem{
+		Reason: models.SuspenseReasonUnbalanced,
+		Detail: fmt.Sprintf("entry not balanced: debits=%d, credits=%d", totalDebits, totalCredits),
+	}
+	if totalDebits > totalCredits {
+		item.CreditAmount = totalDebits - totalCredits
+	} else {
+		item.DebitAmount = totalCredits - totalDebits
+	}
+	return item
+}
+
+// createSuspenseEntry redirects parked lines to the suspense account, adds the
+// balancing line if needed, and creates the entry with its suspense items.
+func (s *LedgerService) createSuspenseEntry(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine, parked map[int]*models.SuspenseItem, imbalance *models.SuspenseItem) *errors.Error {
+	suspenseAccount, err := s.suspenseAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	items := make([]*models.SuspenseItem, 0, len(parked)+1)
+	for i := range lines {
+		item, ok := parked[i]
+		if !ok {
+			continue
+		}
+		if lines[i].Metadata == nil {
+			lines[i].Metadata = make(map[string]string)
+		}
+		lines[i].Metadata["suspense_reason"] = string(item.Reason)
+		lines[i].Metadata["intended_account_id"] = lines[i].AccountID
+		lines[i].AccountID = suspenseAccount.ID
+		items = append(items, item)
+	}
+
+	if imbalance != nil {
+		lines = append(lines, models.LedgerLine{
+			AccountID:    suspenseAccount.ID,
+			DebitAmount:  imbalance.DebitAmount,
+			CreditAmount: imbalance.CreditAmount,
+			Description:  "Suspense: " + imbalance.Detail,
+			Metadata:     map[string]string{"suspense_reason": string(imbalance.Reason)},
+		})
+		items = append(items, imbalance)
+	}
+
+	return s.suspense.CreateEntry(ctx, entry, lines, items)
+}
+
+// suspenseAccount returns the suspense account from the chart of accounts.
+func (s *LedgerService) suspenseAccount(ctx context.Context) (*models.Account, *errors.Error) {
+	account, err := s.accountRepo.GetByCode(ctx, models.SuspenseAccountCode)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil, errors.Internal("suspense account is not configured")
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+// ListSuspenseItems retrieves suspense items, newest first.
+func (s *LedgerService) ListSuspenseItems(ctx context.Context, status *models.SuspenseStatus, limit, offset int) ([]*models.SuspenseItem, int64, *errors.Error) {
+	if s.suspense == nil {
+		return nil, 0, errors.Internal("suspense is not enabled")
+	}
+	return s.suspense.List(ctx, status, limit, offset)
+}
+
+// GetSuspenseItem retrieves a suspense item.
+func (s *LedgerService) GetSuspenseItem(ctx context.Context, id string) (*models.SuspenseItem, *errors.Error) {
+	if s.suspense == nil {
+		return nil, errors.Internal("suspense is not enabled")
+	}
+	return s.suspense.GetByID(ctx, id)
+}
+
+// ClearSuspenseItem re-posts a suspense item to the correct account with a
+// posted adjusting entry that moves the amount out of suspense.
+func (s *LedgerService) ClearSuspenseItem(ctx context.Context, itemID string, req *models.ClearSuspenseItemRequest, clearedBy string) (*models.SuspenseItem, *errors.Error) {
+	if s.suspense == nil {
+		return nil, errors.Internal("suspense is not enabled")
+	}
+
+	item, err := s.suspense.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.SuspenseStatusOpen {
+		return nil, errors.Conflict("suspense item is already cleared")
+	}
+
+	parkingEntry, err := s.journalRepo.GetByID(ctx, item.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	if parkingEntry.Status != models.EntryStatusPosted {
+		return nil, errors.Conflict(fmt.Sprintf("suspense entry %s is %s, not posted", parkingEntry.EntryNumber, parkingEntry.Status))
+	}
+
+	suspenseAccount, err := s.suspenseAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.AccountID == suspenseAccount.ID {
+		return nil, errors.Validation("cannot clear a suspense item to the suspense account")
+	}
+	target, err := s.accountRepo.GetByID(ctx, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != models.AccountStatusActive {
+		return nil, errors.Validation(fmt.Sprintf("account %s is not active", target.Code))
+	}
+
+	if claimErr := s.suspense.Claim(ctx, item.ID, req.AccountID, clearedBy); claimErr != nil {
+		return nil, claimErr
+	}
+
+	entry, clearErr := s.postClearingEntry(ctx, item, suspenseAccount.ID, req, clearedBy)
+	if clearErr != nil {
+		if releaseErr := s.suspense.Release(ctx, item.ID); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, clearErr
+	}
+
+	if setErr := s.suspense.SetClearedEntry(ctx, item.ID, entry.ID); setErr != nil {
+		return nil, setErr
+	}
+
+	return s.suspense.GetByID(ctx, item.ID)
+}
+
+// postClearingEntry books the item's amount out of suspense into the account
+// it belongs to, on the same side it was parked.
+func (s *LedgerService) postClearingEntry(ctx context.Context, item *models.SuspenseItem, suspenseAccountID string, req *models.ClearSuspenseItemRequest, clearedBy string) (*models.JournalEntry, *errors.Error) {
+	description := req.Description
+	if description == "" {
+		description = fmt.Sprintf("Clear suspense item %s (%s)", item.ID, item.Reason)
+	}
+
+	// Parked as a debit to suspense: credit suspense, debit the target
+	target := models.LedgerLineInput{AccountID: req.AccountID, DebitAmount: item.DebitAmount, CreditAmount: item.CreditAmount, Description: description}
+	release := models.LedgerLineInput{AccountID: suspenseAccountID, DebitAmount: item.CreditAmount, CreditAmount: item.DebitAmount, Description: description}
+
+	entry, err := s.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+		Type:          models.EntryTypeAdjusting,
+		Description:   description,
+		ReferenceType: "suspense_item",
+		ReferenceID:   item.ID,
+		Lines:         []models.LedgerLineInput{target, release},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(ctx, entry.ID, clearedBy)
+}
+
+// AutoClearSuspense clears open items to the account they were meant for once
+// that account exists and is active again. Items parked for an imbalance have
+// no intended account and always need a clerk.
+func (s *LedgerService) AutoClearSuspense(ctx context.Context, clearedBy string) (*models.AutoClearResult, *errors.Error) {
+	if s.suspense == nil {
+		return nil, errors.Internal("suspense is not enabled")
+	}
+
+	items, err := s.suspense.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AutoClearResult{
+		Cleared: make([]*models.SuspenseItem, 0),
+		Failed:  make(map[string]string),
+	}
+	for _, item := range items {
+		if item.IntendedAccountID == nil {
+			continue
+		}
+		result.Checked++
+
+		account, accErr := s.accountRepo.GetByID(ctx, *item.IntendedAccountID)
+		if accErr != nil || account.Status != models.AccountStatusActive {
+			continue
+		}
+
+		cleared, clearErr := s.ClearSuspenseItem(ctx, item.ID, &models.ClearSuspenseItemRequest{
+			AccountID:   account.ID,
+			Description: fmt.Sprintf("Auto-clear suspense item %s to %s", item.ID, account.Code),
+		}, clearedBy)
+		if clearErr != nil {
+			result.Failed[item.ID] = clearErr.Error()
+			continue
+		}
+		result.Cleared = append(result.Cleared, cleared)
+	}
+
+	return result, nil
+}
+
+// SuspenseReport lists open suspense items as of now, grouped by age and reason.
+func (s *LedgerService) SuspenseReport(ctx context.Context, now time.Time) (*models.SuspenseReport, *errors.Error) {
+	if s.suspense == nil {
+		return nil, errors.Internal("suspense is not enabled")
+	}
+
+	items, err := s.suspense.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.SuspenseReport{
+		AsOf:     sharedModels.NewTimestamp(now),
+		Buckets:  make([]models.SuspenseAgeBucket, len(suspenseAgeBuckets)),
+		ByReason: make(map[models.SuspenseReason]models.SuspenseReasonSummary),
+		Items:    make([]models.AgedSuspenseItem, 0, len(items)),
+	}
+	copy(report.Buckets, suspenseAgeBuckets)
+
+	for _, item := range items {
+		ageDays := int(now.Sub(item.CreatedAt.Time).Hours() / 24)
+		if ageDays < 0 {
+			ageDays = 0
+		}
+
+		report.OpenItems++
+		report.TotalDebits += item.DebitAmount
+		report.TotalCredits += item.CreditAmount
+
+		for i := range report.Buckets {
+			bucket := &report.Buckets[i]
+			if ageDays >= bucket.MinDays && (bucket.MaxDays == 0 || ageDays < bucket.MaxDays) {
+				bucket.Count++
+				bucket.Amount += item.Amount()
+				break
+			}
+		}
+
+		byReason := report.ByReason[item.Reason]
+		byReason.Count++
+		byReason.Amount += item.Amount()
+		report.ByReason[item.Reason] = byReason
+
+		report.Items = append(report.Items, models.AgedSuspenseItem{SuspenseItem: item, AgeDays: ageDays})
+	}
+
+	return report, nil
+}
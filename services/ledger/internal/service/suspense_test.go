@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/ledger/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockSuspenseRepository struct {
+	journalRepo *mockJournalEntryRepository
+	items       map[string]*models.SuspenseItem
+	order       []string
+}
+
+func (m *mockSuspenseRepository) CreateEntry(ctx context.Context, entry *models.JournalEntry, lines []models.LedgerLine, items []*models.SuspenseItem) *errors.Error {
+	if err := m.journalRepo.Create(ctx, entry, lines); err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.ID = uuid.New().String()
+		item.EntryID = entry.ID
+		item.ReferenceType = entry.ReferenceType
+		item.ReferenceID = entry.ReferenceID
+		item.Status = models.SuspenseStatusOpen
+		item.CreatedAt = sharedModels.Now()
+		m.items[item.ID] = item
+		m.order = append(m.order, item.ID)
+	}
+	return nil
+}
+
+func (m *mockSuspenseRepository) GetByID(ctx context.Context, id string) (*models.SuspenseItem, *errors.Error) {
+	item, ok := m.items[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("suspense item", id)
+	}
+	copied := *item
+	return &copied, nil
+}
+
+func (m *mockSuspenseRepository) List(ctx context.Context, status *models.SuspenseStatus, limit, offset int) ([]*models.SuspenseItem, int64, *errors.Error) {
+	items := make([]*models.SuspenseItem, 0)
+	for _, id := range m.order {
+		if status == nil || m.items[id].Status == *status {
+			items = append(items, m.items[id])
+		}
+	}
+	return items, int64(len(items)), nil
+}
+
+func (m *mockSuspenseRepository) ListOpen(ctx context.Context) ([]*models.SuspenseItem, *errors.Error) {
+	items := make([]*models.SuspenseItem, 0)
+	for _, id := range m.order {
+		item := m.items[id]
+		entry := m.journalRepo.entries[item.EntryID]
+		if item.Status == models.SuspenseStatusOpen && entry.Status == models.EntryStatusPosted {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (m *mockSuspenseRepository) Claim(ctx context.Context, id, accountID, clearedBy string) *errors.Error {
+	item := m.items[id]
+	if item.Status != models.SuspenseStatusOpen {
+		return errors.Conflict("suspense item is not open")
+	}
+	now := sharedModels.Now()
+	item.Status = models.SuspenseStatusCleared
+	item.ClearedAccountID = &accountID
+	item.ClearedBy = &clearedBy
+	item.ClearedAt = &now
+	return nil
+}
+
+func (m *mockSuspenseRepository) SetClearedEntry(ctx context.Context, id, entryID string) *errors.Error {
+	m.items[id].ClearedEntryID = &entryID
+	return nil
+}
+
+func (m *mockSuspenseRepository) Release(ctx context.Context, id string) *errors.Error {
+	item := m.items[id]
+	item.Status = models.SuspenseStatusOpen
+	item.ClearedAccountID = nil
+	item.ClearedBy = nil
+	item.ClearedAt = nil
+	return nil
+}
+
+var _ SuspenseRepositoryInterface = (*mockSuspenseRepository)(nil)
+
+func setupSuspenseTest(t *testing.T) (*LedgerService, *mockAccountRepository, *mockJournalEntryRepository, *mockSuspenseRepository, *models.Account) {
+	t.Helper()
+	service, accountRepo, journalRepo := setupTestService()
+	suspenseRepo := &mockSuspenseRepository{journalRepo: journalRepo, items: make(map[string]*models.SuspenseItem)}
+	service.SetSuspense(suspenseRepo)
+
+	suspense := createTestAccount(uuid.New().String(), models.SuspenseAccountCode, "Suspense", models.AccountTypeLiability)
+	accountRepo.accounts[suspense.ID] = suspense
+
+	return service, accountRepo, journalRepo, suspenseRepo, suspense
+}
+
+func TestCreateJournalEntry_ParksUnknownAccountInSuspense(t *testing.T) {
+	service, accountRepo, _, suspenseRepo, suspense := setupSuspenseTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	accountRepo.accounts[cash.ID] = cash
+	missingID := uuid.New().String()
+
+	req := &models.CreateJournalEntryRequest{
+		Type:          models.EntryTypeStandard,
+		Description:   "Deposit to unknown wallet",
+		ReferenceType: "transaction",
+		ReferenceID:   "txn-1",
+		Lines: []models.LedgerLineInput{
+			{AccountID: cash.ID, DebitAmount: 5000},
+			{AccountID: missingID, CreditAmount: 5000},
+		},
+	}
+
+	// Without UseSuspense the entry is still rejected
+	if _, err := service.CreateJournalEntry(ctx, req); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	req.UseSuspense = true
+	entry, err := service.CreateJournalEntry(ctx, req)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if entry.Lines[1].AccountID != suspense.ID || entry.Lines[1].Metadata["intended_account_id"] != missingID {
+		t.Errorf("expected credit line redirected to suspense, got %+v", entry.Lines[1])
+	}
+	if !entry.IsBalanced() {
+		t.Error("expected parked entry to stay balanced")
+	}
+
+	if len(suspenseRepo.order) != 1 {
+		t.Fatalf("expected 1 suspense item, got %d", len(suspenseRepo.order))
+	}
+	item := suspenseRepo.items[suspenseRepo.order[0]]
+	if item.Reason != models.SuspenseReasonUnknownAccount || item.CreditAmount != 5000 || *item.IntendedAccountID != missingID {
+		t.Errorf("unexpected suspense item: %+v", item)
+	}
+	if item.EntryID != entry.ID || item.ReferenceID != "txn-1" {
+		t.Errorf("expected item linked to entry and reference, got %+v", item)
+	}
+}
+
+func TestCreateJournalEntry_ParksImbalanceInSuspense(t *testing.T) {
+	service, accountRepo, _, suspenseRepo, suspense := setupSuspenseTest(t)
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	revenue := createTestAccount(uuid.New().String(), "4000", "Revenue", models.AccountTypeRevenue)
+	accountRepo.accounts[cash.ID] = cash
+	accountRepo.accounts[revenue.ID] = revenue
+
+	entry, err := service.CreateJournalEntry(context.Background(), &models.CreateJournalEntryRequest{
+		Type:        models.EntryTypeStandard,
+		Description: "Short settlement",
+		Lines: []models.LedgerLineInput{
+			{AccountID: cash.ID, DebitAmount: 10000},
+			{AccountID: revenue.ID, CreditAmount: 9000},
+		},
+		UseSuspense: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(entry.Lines) != 3 || !entry.IsBalanced() {
+		t.Fatalf("expected a balancing suspense line, got %+v", entry.Lines)
+	}
+	if last := entry.Lines[2]; last.AccountID != suspense.ID || last.CreditAmount != 1000 {
+		t.Errorf("expected suspense credit of 1000, got %+v", last)
+	}
+
+	item := suspenseRepo.items[suspenseRepo.order[0]]
+	if item.Reason != models.SuspenseReasonUnbalanced || item.IntendedAccountID != nil {
+		t.Errorf("unexpected suspense item: %+v", item)
+	}
+}
+
+func TestClearSuspenseItem(t *testing.T) {
+	service, accountRepo, journalRepo, suspenseRepo, suspense := setupSuspenseTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	deposits := createTestAccount(uuid.New().String(), "2100", "Customer Deposits", models.AccountTypeLiability)
+	accountRepo.accounts[cash.ID] = cash
+	accountRepo.accounts[deposits.ID] = deposits
+
+	parking, err := service.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+		Type:        models.EntryTypeStandard,
+		Description: "Deposit to unknown wallet",
+		Lines: []models.LedgerLineInput{
+			{AccountID: cash.ID, DebitAmount: 5000},
+			{AccountID: uuid.New().String(), CreditAmount: 5000},
+		},
+		UseSuspense: true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	itemID := suspenseRepo.order[0]
+	clearReq := &models.ClearSuspenseItemRequest{AccountID: deposits.ID}
+
+	// Draft parking entries hold no money yet
+	if _, err := service.ClearSuspenseItem(ctx, itemID, clearReq, "finance-1"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Fatalf("expected conflict for unposted parking entry, got %v", err)
+	}
+	if _, err := service.PostJournalEntry(ctx, parking.ID, "system"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := service.ClearSuspenseItem(ctx, itemID, &models.ClearSuspenseItemRequest{AccountID: suspense.ID}, "finance-1"); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected validation error clearing to suspense, got %v", err)
+	}
+
+	cleared, err := service.ClearSuspenseItem(ctx, itemID, clearReq, "finance-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cleared.Status != models.SuspenseStatusCleared || *cleared.ClearedAccountID != deposits.ID || cleared.ClearedEntryID == nil {
+		t.Fatalf("unexpected cleared item: %+v", cleared)
+	}
+
+	clearing := journalRepo.entries[*cleared.ClearedEntryID]
+	if clearing.Status != models.EntryStatusPosted || *clearing.PostedBy != "finance-1" {
+		t.Errorf("expected clearing entry posted by finance-1, got %s", clearing.Status)
+	}
+	// Parked as a credit to suspense, so suspense is debited and deposits credited
+	for _, line := range clearing.Lines {
+		switch line.AccountID {
+		case suspense.ID:
+			if line.DebitAmount != 5000 {
+				t.Errorf("expected suspense debit of 5000, got %+v", line)
+			}
+		case deposits.ID:
+			if line.CreditAmount != 5000 {
+				t.Errorf("expected deposits credit of 5000, got %+v", line)
+			}
+		default:
+			t.Errorf("unexpected clearing line %+v", line)
+		}
+	}
+
+	if _, err := service.ClearSuspenseItem(ctx, itemID, clearReq, "finance-2"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict clearing twice, got %v", err)
+	}
+}
+
+func TestClearSuspenseItem_ReleasesOnFailure(t *testing.T) {
+	service, accountRepo, journalRepo, suspenseRepo, _ := setupSuspenseTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	deposits := createTestAccount(uuid.New().String(), "2100", "Customer Deposits", models.AccountTypeLiability)
+	accountRepo.accounts[cash.ID] = cash
+	accountRepo.accounts[deposits.ID] = deposits
+
+	parking, _ := service.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+		Type:        models.EntryTypeStandard,
+		Description: "Deposit to unknown wallet",
+		Lines: []models.LedgerLineInput{
+			{AccountID: cash.ID, DebitAmount: 5000},
+			{AccountID: uuid.New().String(), CreditAmount: 5000},
+		},
+		UseSuspense: true,
+	})
+	_, _ = service.PostJournalEntry(ctx, parking.ID, "system")
+
+	journalRepo.postFunc = func(ctx context.Context, entryID, postedBy string) *errors.Error {
+		return errors.Conflict("concurrent postings")
+	}
+
+	itemID := suspenseRepo.order[0]
+	if _, err := service.ClearSuspenseItem(ctx, itemID, &models.ClearSuspenseItemRequest{AccountID: deposits.ID}, "finance-1"); err == nil {
+		t.Fatal("expected posting failure")
+	}
+	if item := suspenseRepo.items[itemID]; item.Status != models.SuspenseStatusOpen || item.ClearedAccountID != nil {
+		t.Errorf("expected item reopened, got %+v", item)
+	}
+}
+
+func TestAutoClearSuspense(t *testing.T) {
+	service, accountRepo, _, _, _ := setupSuspenseTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	wallet := createTestAccount(uuid.New().String(), "2100-W1", "Wallet", models.AccountTypeLiability)
+	wallet.Status = models.AccountStatusInactive
+	revenue := createTestAccount(uuid.New().String(), "4000", "Revenue", models.AccountTypeRevenue)
+	accountRepo.accounts[cash.ID] = cash
+	accountRepo.accounts[wallet.ID] = wallet
+	accountRepo.accounts[revenue.ID] = revenue
+
+	for _, lines := range [][]models.LedgerLineInput{
+		{{AccountID: cash.ID, DebitAmount: 5000}, {AccountID: wallet.ID, CreditAmount: 5000}},
+		{{AccountID: cash.ID, DebitAmount: 3000}, {AccountID: revenue.ID, CreditAmount: 2000}},
+	} {
+		entry, err := service.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+			Type:        models.EntryTypeStandard,
+			Description: "Parked posting",
+			Lines:       lines,
+			UseSuspense: true,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		_, _ = service.PostJournalEntry(ctx, entry.ID, "system")
+	}
+
+	// Nothing to clear while the wallet account is still inactive
+	result, err := service.AutoClearSuspense(ctx, "finance-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Checked != 1 || len(result.Cleared) != 0 {
+		t.Errorf("expected 1 checked and none cleared, got %+v", result)
+	}
+
+	wallet.Status = models.AccountStatusActive
+	result, err = service.AutoClearSuspense(ctx, "finance-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Cleared) != 1 || *result.Cleared[0].ClearedAccountID != wallet.ID {
+		t.Fatalf("expected the wallet item cleared, got %+v", result)
+	}
+
+	// The imbalance has no intended account and stays open
+	open, _, _ := service.ListSuspenseItems(ctx, ptrSuspenseStatus(models.SuspenseStatusOpen), 20, 0)
+	if len(open) != 1 || open[0].Reason != models.SuspenseReasonUnbalanced {
+		t.Errorf("expected only the imbalance left open, got %+v", open)
+	}
+}
+
+func TestSuspenseReport(t *testing.T) {
+	service, accountRepo, _, suspenseRepo, _ := setupSuspenseTest(t)
+	ctx := context.Background()
+
+	cash := createTestAccount(uuid.New().String(), "1000", "Cash", models.AccountTypeAsset)
+	accountRepo.accounts[cash.ID] = cash
+
+	for _, amount := range []int64{1000, 2000, 4000} {
+		entry, err := service.CreateJournalEntry(ctx, &models.CreateJournalEntryRequest{
+			Type:        models.EntryTypeStandard,
+			Description: "Parked posting",
+			Lines: []models.LedgerLineInput{
+				{AccountID: cash.ID, DebitAmount: amount},
+				{AccountID: uuid.New().String(), CreditAmount: amount},
+			},
+			UseSuspense: true,
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		_, _ = service.PostJournalEntry(ctx, entry.ID, "system")
+	}
+
+	now := time.Now()
+	ages := []time.Duration{120 * 24 * time.Hour, 45 * 24 * time.Hour, 2 * 24 * time.Hour}
+	for i, id := range suspenseRepo.order {
+		suspenseRepo.items[id].CreatedAt = sharedModels.NewTimestamp(now.Add(-ages[i]))
+	}
+
+	report, err := service.SuspenseReport(ctx, now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.OpenItems != 3 || report.TotalCredits != 7000 {
+		t.Errorf("expected 3 items crediting 7000, got %d / %d", report.OpenItems, report.TotalCredits)
+	}
+
+	want := map[string]int64{"0-7 days": 4000, "7-30 days": 0, "30-90 days": 2000, "90+ days": 1000}
+	for _, bucket := range report.Buckets {
+		if bucket.Amount != want[bucket.Label] {
+			t.Errorf("bucket %s: expected %d, got %d", bucket.Label, want[bucket.Label], bucket.Amount)
+		}
+	}
+	if summary := report.ByReason[models.SuspenseReasonUnknownAccount]; summary.Count != 3 || summary.Amount != 7000 {
+		t.Errorf("unexpected reason summary: %+v", summary)
+	}
+	if report.Items[0].AgeDays != 120 {
+		t.Errorf("expected oldest item first at 120 days, got %d", report.Items[0].AgeDays)
+	}
+}
+
+func ptrSuspenseStatus(s models.SuspenseStatus) *models.SuspenseStatus {
+	return &s
+}
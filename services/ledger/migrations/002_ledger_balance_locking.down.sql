@@ -0,0 +1,65 @@
+-- Restore the original balance trigger and entry number functions
+
+CREATE OR REPLACE FUNCTION generate_entry_number()
+RETURNS TEXT AS $$
+DECLARE
+    current_year TEXT;
+    next_number INTEGER;
+    new_entry_number TEXT;
+BEGIN
+    current_year := TO_CHAR(NOW(), 'YYYY');
+    SELECT COALESCE(MAX(
+        CAST(
+            SUBSTRING(entry_number FROM 'JE-' || current_year || '-(\d+)') AS INTEGER
+        )
+    ), 0) + 1
+    INTO next_number
+    FROM journal_entries
+    WHERE entry_number LIKE 'JE-' || current_year || '-%';
+    new_entry_number := 'JE-' || current_year || '-' || LPAD(next_number::TEXT, 5, '0');
+    RETURN new_entry_number;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION update_account_balances()
+RETURNS TRIGGER AS $$
+DECLARE
+    line RECORD;
+    account RECORD;
+    new_balance BIGINT;
+BEGIN
+    IF NEW.status = 'posted' AND (OLD.status IS NULL OR OLD.status != 'posted') THEN
+        FOR line IN
+            SELECT account_id, debit_amount, credit_amount
+            FROM ledger_lines
+            WHERE entry_id = NEW.id
+        LOOP
+            SELECT id, type, balance, debit_total, credit_total
+            INTO account
+            FROM accounts
+            WHERE id = line.account_id
+            FOR UPDATE;
+
+            UPDATE accounts
+            SET
+                debit_total = debit_total + line.debit_amount,
+                credit_total = credit_total + line.credit_amount
+            WHERE id = account.id;
+
+            IF account.type IN ('asset', 'expense') THEN
+                new_balance := account.balance + line.debit_amount - line.credit_amount;
+            ELSE
+                new_balance := account.balance + line.credit_amount - line.debit_amount;
+            END IF;
+
+            UPDATE accounts
+            SET balance = new_balance
+            WHERE id = account.id;
+        END LOOP;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS version;
@@ -0,0 +1,85 @@
+-- Ledger balance locking
+-- Posts lock their accounts in ID order so concurrent postings over the same
+-- accounts queue instead of deadlocking, and entry numbers are allocated
+-- one transaction at a time.
+
+-- ============================================================================
+-- Account Versions
+-- ============================================================================
+
+-- Incremented on every balance change, so readers can tell whether an account
+-- moved between two reads
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+
+-- ============================================================================
+-- Balance Updates on Post
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION update_account_balances()
+RETURNS TRIGGER AS $$
+DECLARE
+    delta RECORD;
+BEGIN
+    IF NEW.status = 'posted' AND (OLD.status IS NULL OR OLD.status != 'posted') THEN
+        -- Lock every account the entry touches up front, in a fixed order
+        PERFORM 1
+        FROM accounts
+        WHERE id IN (SELECT account_id FROM ledger_lines WHERE entry_id = NEW.id)
+        ORDER BY id
+        FOR UPDATE;
+
+        -- One update per account, however many lines it has in the entry
+        FOR delta IN
+            SELECT account_id,
+                   SUM(debit_amount) AS debits,
+                   SUM(credit_amount) AS credits
+            FROM ledger_lines
+            WHERE entry_id = NEW.id
+            GROUP BY account_id
+            ORDER BY account_id
+        LOOP
+            UPDATE accounts
+            SET
+                debit_total = debit_total + delta.debits,
+                credit_total = credit_total + delta.credits,
+                balance = balance + CASE
+                    WHEN type IN ('asset', 'expense') THEN delta.debits - delta.credits
+                    ELSE delta.credits - delta.debits
+                END,
+                version = version + 1
+            WHERE id = delta.account_id;
+        END LOOP;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- ============================================================================
+-- Entry Numbers
+-- ============================================================================
+
+CREATE OR REPLACE FUNCTION generate_entry_number()
+RETURNS TEXT AS $$
+DECLARE
+    current_year TEXT;
+    next_number INTEGER;
+    new_entry_number TEXT;
+BEGIN
+    -- Held until the caller's transaction ends; without it, concurrent entries
+    -- read the same MAX and collide on the unique entry_number
+    PERFORM pg_advisory_xact_lock(hashtext('journal_entries.entry_number'));
+
+    current_year := TO_CHAR(NOW(), 'YYYY');
+    SELECT COALESCE(MAX(
+        CAST(
+            SUBSTRING(entry_number FROM 'JE-' || current_year || '-(\d+)') AS INTEGER
+        )
+    ), 0) + 1
+    INTO next_number
+    FROM journal_entries
+    WHERE entry_number LIKE 'JE-' || current_year || '-%';
+    new_entry_number := 'JE-' || current_year || '-' || LPAD(next_number::TEXT, 5, '0');
+    RETURN new_entry_number;
+END;
+$$ LANGUAGE plpgsql;
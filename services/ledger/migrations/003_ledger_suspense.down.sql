@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS suspense_items;
+
+-- The suspense account is kept if anything was ever booked to it
+DELETE FROM accounts a
+WHERE a.code = '2900'
+  AND NOT EXISTS (SELECT 1 FROM ledger_lines l WHERE l.account_id = a.id);
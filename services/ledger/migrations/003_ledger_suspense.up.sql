@@ -0,0 +1,50 @@
+-- Ledger suspense account
+-- Postings that cannot be booked as requested (unknown or inactive accounts,
+-- unbalanced lines) are parked in a suspense account so the money is not
+-- lost. Each parked amount is tracked as a suspense item until finance clears
+-- it to the correct account.
+
+-- ============================================================================
+-- Suspense Account
+-- ============================================================================
+
+INSERT INTO accounts (code, name, type, currency, status) VALUES
+('2900', 'Suspense', 'liability', 'INR', 'active')
+ON CONFLICT (code) DO NOTHING;
+
+-- ============================================================================
+-- Suspense Items
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS suspense_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES journal_entries(id),        -- Entry that parked the amount
+    reference_type VARCHAR(50),
+    reference_id VARCHAR(100),
+    intended_account_id VARCHAR(100),                             -- Account the line was meant for, if any
+    debit_amount BIGINT NOT NULL DEFAULT 0,                       -- Amount debited to suspense
+    credit_amount BIGINT NOT NULL DEFAULT 0,                      -- Amount credited to suspense
+    reason VARCHAR(30) NOT NULL,
+    detail TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    cleared_account_id UUID REFERENCES accounts(id),
+    cleared_entry_id UUID REFERENCES journal_entries(id),
+    cleared_by UUID,
+    cleared_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT suspense_items_amount_check CHECK (
+        (debit_amount > 0 AND credit_amount = 0) OR
+        (debit_amount = 0 AND credit_amount > 0)
+    ),
+    CONSTRAINT suspense_items_reason_check CHECK (reason IN ('unknown_account', 'inactive_account', 'unbalanced')),
+    CONSTRAINT suspense_items_status_check CHECK (status IN ('open', 'cleared')),
+    CONSTRAINT suspense_items_cleared_check CHECK (
+        (status = 'cleared' AND cleared_account_id IS NOT NULL AND cleared_by IS NOT NULL AND cleared_at IS NOT NULL) OR
+        (status = 'open' AND cleared_entry_id IS NULL)
+    )
+);
+
+CREATE INDEX idx_suspense_items_open ON suspense_items(created_at) WHERE status = 'open';
+CREATE INDEX idx_suspense_items_entry ON suspense_items(entry_id);
+CREATE INDEX idx_suspense_items_reference ON suspense_items(reference_type, reference_id);
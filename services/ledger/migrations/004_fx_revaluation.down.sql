@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS fx_revaluations;
+DROP TABLE IF EXISTS fx_rates;
+
+-- The FX gain/loss account is kept if anything was ever booked to it
+DELETE FROM accounts a
+WHERE a.code = '4900'
+  AND NOT EXISTS (SELECT 1 FROM ledger_lines l WHERE l.account_id = a.id);
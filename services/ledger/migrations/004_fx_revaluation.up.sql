@@ -0,0 +1,59 @@
+-- Ledger FX revaluation
+-- Accounts keep their balance in their own currency. Period-end FX rates are
+-- stored per currency, and a revaluation job posts adjusting entries that
+-- carry each foreign-currency account's change in base-currency value to an
+-- unrealized FX gain/loss account.
+
+-- ============================================================================
+-- Unrealized FX Gain/Loss Account
+-- ============================================================================
+
+INSERT INTO accounts (code, name, type, currency, status) VALUES
+('4900', 'Unrealized FX Gain/Loss', 'revenue', 'INR', 'active')
+ON CONFLICT (code) DO NOTHING;
+
+-- ============================================================================
+-- FX Rates
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+    currency VARCHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,                 -- Base currency units per 1 unit of currency
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (currency, rate_date),
+    CONSTRAINT fx_rates_rate_check CHECK (rate > 0),
+    CONSTRAINT fx_rates_currency_check CHECK (currency ~* '^[A-Z]{3}$')
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_rates_date ON fx_rates(rate_date DESC);
+
+COMMENT ON TABLE fx_rates IS 'Period-end FX rates used for revaluation and base-currency reporting';
+
+-- ============================================================================
+-- FX Revaluations
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS fx_revaluations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    period_end DATE NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL,
+    balance BIGINT NOT NULL,                       -- Account balance in its own currency
+    base_value BIGINT NOT NULL,                    -- Balance at the period-end rate, in base currency
+    previous_base_value BIGINT NOT NULL,           -- Carrying value before this revaluation
+    adjustment BIGINT NOT NULL,                    -- base_value - previous_base_value
+    adjustment_account_id UUID REFERENCES accounts(id),
+    entry_id UUID REFERENCES journal_entries(id),  -- NULL when no adjustment was needed
+    created_by UUID,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fx_revaluations_account_period_unique UNIQUE (account_id, period_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_revaluations_period ON fx_revaluations(period_end DESC);
+
+COMMENT ON TABLE fx_revaluations IS 'Period-end revaluations of foreign-currency accounts';
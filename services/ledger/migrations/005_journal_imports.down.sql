@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS journal_import_errors;
+DROP TABLE IF EXISTS journal_imports;
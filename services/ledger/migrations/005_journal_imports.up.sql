@@ -0,0 +1,49 @@
+-- Bulk journal entry imports
+-- Entries from another system (e.g. a legacy ledger being migrated) are
+-- uploaded as CSV or JSON, validated, and created in batches by a background
+-- job. Each import records its progress and the rows it could not import.
+
+-- ============================================================================
+-- Journal Imports
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS journal_imports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    format VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    total_entries INTEGER NOT NULL,
+    imported_entries INTEGER NOT NULL DEFAULT 0,
+    failed_entries INTEGER NOT NULL DEFAULT 0,   -- Includes entries rejected on upload
+    entries JSONB NOT NULL,                      -- Entries still to import; cleared on completion
+    next_entry INTEGER NOT NULL DEFAULT 0,       -- Index into entries of the next entry to import
+    locked_until TIMESTAMPTZ,                    -- Lease held by the worker importing a batch
+    created_by UUID NOT NULL,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT journal_imports_format_check CHECK (format IN ('csv', 'json')),
+    CONSTRAINT journal_imports_status_check CHECK (status IN ('pending', 'processing', 'completed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_imports_active ON journal_imports(created_at) WHERE status <> 'completed';
+
+COMMENT ON TABLE journal_imports IS 'Bulk journal entry imports and their progress';
+
+-- ============================================================================
+-- Journal Import Errors
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS journal_import_errors (
+    id BIGSERIAL PRIMARY KEY,
+    import_id UUID NOT NULL REFERENCES journal_imports(id) ON DELETE CASCADE,
+    row_number INTEGER NOT NULL,                 -- CSV line of the entry's first row, or JSON array position (1-based)
+    reference_id VARCHAR(100),
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_import_errors_import ON journal_import_errors(import_id, row_number);
+
+COMMENT ON TABLE journal_import_errors IS 'Entries of a journal import that were rejected or failed to import';
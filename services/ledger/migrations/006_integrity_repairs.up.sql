@@ -0,0 +1,27 @@
+-- Ledger integrity repairs
+-- The integrity check compares each account's stored balance and totals with
+-- the sum of its lines. When they drift, an admin can reset the account to
+-- its lines; every reset is recorded here with the values it replaced.
+
+-- ============================================================================
+-- Integrity Repairs
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS ledger_integrity_repairs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE RESTRICT,
+    previous_balance BIGINT NOT NULL,
+    previous_debit_total BIGINT NOT NULL,
+    previous_credit_total BIGINT NOT NULL,
+    balance BIGINT NOT NULL,                     -- Recomputed from the account's lines
+    debit_total BIGINT NOT NULL,
+    credit_total BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    repaired_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_integrity_repairs_account ON ledger_integrity_repairs(account_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ledger_integrity_repairs_created ON ledger_integrity_repairs(created_at DESC);
+
+COMMENT ON TABLE ledger_integrity_repairs IS 'Account balances reset to the sum of their lines after drift';
@@ -0,0 +1,4 @@
+-- The card settlement account is kept if anything was ever booked to it
+DELETE FROM accounts a
+WHERE a.code = '2500'
+  AND NOT EXISTS (SELECT 1 FROM ledger_lines l WHERE l.account_id = a.id);
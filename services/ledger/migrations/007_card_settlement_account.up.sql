@@ -0,0 +1,8 @@
+-- Card settlement account
+-- Card settlements debit the cardholder's wallet account and credit this
+-- account, which holds what is owed to the card network until it is paid.
+-- Card refunds post the reverse.
+
+INSERT INTO accounts (code, name, type, currency, status) VALUES
+('2500', 'Card Settlement Payable', 'liability', 'INR', 'active')
+ON CONFLICT (code) DO NOTHING;
@@ -5,24 +5,35 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/notification/internal/handler"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
 	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/notification/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
+	"github.com/1mb-dev/nivomoney/shared/workerpool"
 )
 
 func main() {
-	// Track worker cancel function for cleanup
-	var workerCancel context.CancelFunc
-
 	server.Run(server.ServiceConfig{
 		Name: "notification",
+		// Delivery receipts come from providers and keep the shared secret
+		InternalPolicy: serviceauth.Policy{
+			Exempt: []string{"/internal/v1/notifications/status-callbacks"},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repositories
 			notifRepo := repository.NewNotificationRepository(ctx.DB.DB)
 			templateRepo := repository.NewTemplateRepository(ctx.DB.DB)
+			domainRepo := repository.NewDomainRepository(ctx.DB.DB)
+			versionRepo := repository.NewTemplateVersionRepository(ctx.DB.DB)
+			webhookRepo := repository.NewWebhookRepository(ctx.DB.DB)
+			preferenceRepo := repository.NewPreferenceRepository(ctx.DB.DB)
+			translationRepo := repository.NewTranslationRepository(ctx.DB.DB)
 
 			// Load simulation configuration
 			simConfig := loadSimulationConfig()
@@ -31,43 +42,90 @@ func main() {
 				WithField("max_retries", simConfig.MaxRetryAttempts).
 				Info("Simulation config loaded")
 
-			// Initialize service
-			notifService := service.NewNotificationService(notifRepo, templateRepo, simConfig)
-
-			// Start background worker for processing queued notifications
-			workerCtx, cancel := context.WithCancel(context.Background())
-			workerCancel = cancel
-
-			go func() {
-				ctx.Logger.Info("Starting background worker for notification processing...")
-				ticker := time.NewTicker(5 * time.Second)
-				defer ticker.Stop()
-
-				for {
-					select {
-					case <-ticker.C:
-						if err := notifService.ProcessQueuedNotifications(workerCtx, 10); err != nil {
-							ctx.Logger.WithError(err).Error("Worker error")
-						}
-					case <-workerCtx.Done():
-						ctx.Logger.Info("Background worker stopped")
-						return
-					}
+			// Initialize services
+			domainService := service.NewDomainService(domainRepo)
+			versionService := service.NewTemplateVersionService(templateRepo, versionRepo)
+			webhookService := service.NewWebhookService(webhookRepo, notifRepo)
+			notifService := service.NewNotificationService(notifRepo, templateRepo, domainService, versionService, simConfig)
+
+			// Deliver webhook notifications to registered endpoints instead of simulating them
+			webhookTimeout := service.DefaultWebhookTimeout
+			if ms, err := strconv.Atoi(os.Getenv("NOTIFICATION_WEBHOOK_TIMEOUT_MS")); err == nil && ms > 0 {
+				webhookTimeout = time.Duration(ms) * time.Millisecond
+			}
+			notifService.SetWebhookSender(service.NewWebhookSender(webhookRepo, webhookTimeout))
+
+			// Limit notifications per recipient and deliveries per provider
+			rateLimitConfig, err := loadRateLimitConfig()
+			if err != nil {
+				ctx.Logger.WithError(err).Warn("Invalid NOTIFICATION_RECIPIENT_RATE_LIMITS, using defaults")
+			}
+			notifService.SetRateLimiter(service.NewRateLimiter(rateLimitConfig))
+
+			// Track email opens and clicks through links served at the public tracking URL
+			if trackingURL := os.Getenv("NOTIFICATION_TRACKING_BASE_URL"); trackingURL != "" {
+				notifService.SetEngagementTracker(service.NewEngagementTracker(notifRepo, trackingURL))
+				ctx.Logger.WithField("base_url", trackingURL).Info("Email engagement tracking enabled")
+			}
+
+			// Push delivered in-app notifications to users' notification streams
+			gatewayURL := server.GetEnv("GATEWAY_URL", "http://gateway:8000")
+			notifService.SetEventPublisher(events.NewPublisher(events.PublishConfig{
+				GatewayURL:  gatewayURL,
+				ServiceName: "notification",
+			}))
+
+			// Honour user preferences replicated from identity's preference events,
+			// resuming after the last applied event across restarts
+			notifService.SetPreferences(preferenceRepo)
+			preferenceConsumer := service.NewPreferenceConsumer(gatewayURL, preferenceRepo, events.NewSQLOffsetStore(ctx.DB.DB))
+			ctx.Lifecycle.Go("preference-consumer", preferenceConsumer.Run)
+
+			// Send templates in the recipient's locale, falling back to the default
+			translationService := service.NewTranslationService(templateRepo, translationRepo,
+				server.GetEnv("NOTIFICATION_DEFAULT_LOCALE", service.DefaultLocale),
+				strings.Split(server.GetEnv("NOTIFICATION_LOCALES", "en,hi"), ","))
+			notifService.SetTranslations(translationService)
+
+			// Deliveries run on a bounded worker pool, drained after the processor stops
+			deliveryPool := workerpool.New(workerpool.Config{
+				Name:       "notification-delivery",
+				Workers:    10,
+				JobTimeout: 30 * time.Second,
+				Logger:     ctx.Logger,
+			})
+			ctx.Lifecycle.Register("notification-delivery", deliveryPool)
+			notifService.SetWorkerPool(deliveryPool)
+
+			// Start background worker for processing queued notifications.
+			// On shutdown the batch in flight finishes before the process exits.
+			ctx.Logger.Info("Starting background worker for notification processing...")
+			ctx.Lifecycle.Every("notification-processor", 5*time.Second, func(workerCtx context.Context) error {
+				if err := notifService.ProcessQueuedNotifications(workerCtx, 10); err != nil {
+					return err
 				}
-			}()
+				return nil
+			})
+
+			// Release scheduled notifications to the queue once their send time passes
+			ctx.Lifecycle.Every("notification-scheduler", 10*time.Second, func(workerCtx context.Context) error {
+				_, err := notifService.ReleaseScheduledNotifications(workerCtx, 100)
+				if err != nil {
+					return err
+				}
+				return nil
+			})
 
 			// Initialize handler and router
 			notifHandler := handler.NewNotificationHandler(notifService)
-			router := handler.NewRouter(notifHandler)
+			domainHandler := handler.NewDomainHandler(domainService)
+			versionHandler := handler.NewTemplateVersionHandler(versionService)
+			translationHandler := handler.NewTranslationHandler(translationService)
+			webhookHandler := handler.NewWebhookHandler(webhookService)
+			router := handler.NewRouter(notifHandler, domainHandler, versionHandler, translationHandler, webhookHandler, server.GetSecret("INTERNAL_SERVICE_SECRET", ""))
 
 			return router.SetupRoutes(), nil
 		},
-		Cleanup: func() error {
-			if workerCancel != nil {
-				workerCancel()
-			}
-			return nil
-		},
 	})
 }
 
@@ -107,3 +165,29 @@ func loadSimulationConfig() service.SimulationConfig {
 
 	return config
 }
+
+// loadRateLimitConfig reads recipient limits from NOTIFICATION_RECIPIENT_RATE_LIMITS
+// (JSON) and per-minute provider quotas from NOTIFICATION_<CHANNEL>_QUOTA_PER_MINUTE,
+// where 0 disables the quota. Invalid limits leave the defaults in place.
+func loadRateLimitConfig() (service.RateLimitConfig, error) {
+	config := service.DefaultRateLimitConfig()
+
+	for i, quota := range config.ProviderQuotas {
+		name := "NOTIFICATION_" + strings.ToUpper(string(quota.Channel)) + "_QUOTA_PER_MINUTE"
+		if val := os.Getenv(name); val != "" {
+			if limit, err := strconv.Atoi(val); err == nil && limit >= 0 {
+				config.ProviderQuotas[i].Limit = limit
+			}
+		}
+	}
+
+	if val := os.Getenv("NOTIFICATION_RECIPIENT_RATE_LIMITS"); val != "" {
+		limits, err := models.ParseRecipientRateLimits(val)
+		if err != nil {
+			return config, err
+		}
+		config.RecipientLimits = limits
+	}
+
+	return config, nil
+}
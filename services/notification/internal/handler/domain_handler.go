@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// DomainHandler handles sending domain HTTP requests.
+type DomainHandler struct {
+	domainService *service.DomainService
+}
+
+// NewDomainHandler creates a new domain handler.
+func NewDomainHandler(domainService *service.DomainService) *DomainHandler {
+	return &DomainHandler{
+		domainService: domainService,
+	}
+}
+
+// CreateDomain registers a new sending domain.
+// POST /admin/domains
+func (h *DomainHandler) CreateDomain(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.CreateDomainRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	domain, svcErr := h.domainService.CreateDomain(r.Context(), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Created(w, domain)
+}
+
+// ListDomains retrieves sending domains.
+// GET /admin/domains
+func (h *DomainHandler) ListDomains(w http.ResponseWriter, r *http.Request) {
+	var tenant *string
+	if t := r.URL.Query().Get("tenant"); t != "" {
+		tenant = &t
+	}
+
+	domains, svcErr := h.domainService.ListDomains(r.Context(), tenant)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, domains)
+}
+
+// GetDomain retrieves a sending domain by ID.
+// GET /admin/domains/{id}
+func (h *DomainHandler) GetDomain(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	domain, svcErr := h.domainService.GetDomain(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, domain)
+}
+
+// UpdateDomain updates sender configuration for a domain.
+// PUT /admin/domains/{id}
+func (h *DomainHandler) UpdateDomain(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.UpdateDomainRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	domain, svcErr := h.domainService.UpdateDomain(r.Context(), id, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, domain)
+}
+
+// RegisterDKIM registers a simulated DKIM key for a domain.
+// POST /admin/domains/{id}/dkim
+func (h *DomainHandler) RegisterDKIM(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.RegisterDKIMRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	domain, svcErr := h.domainService.RegisterDKIM(r.Context(), id, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, domain)
+}
+
+// VerifyDomain runs simulated verification checks for a domain.
+// POST /admin/domains/{id}/verify
+func (h *DomainHandler) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	result, svcErr := h.domainService.VerifyDomain(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetDNSRecords lists the DNS records required for a domain.
+// GET /admin/domains/{id}/dns-records
+func (h *DomainHandler) GetDNSRecords(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	records, svcErr := h.domainService.GetDNSRecords(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, records)
+}
+
+// GetDeliveryStats retrieves delivery statistics for a domain.
+// GET /admin/domains/{id}/stats
+func (h *DomainHandler) GetDeliveryStats(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("domain id is required"))
+		return
+	}
+
+	stats, svcErr := h.domainService.GetDeliveryStats(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, stats)
+}
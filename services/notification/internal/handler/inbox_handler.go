@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// GetInbox lists a user's in-app notifications with their unread count.
+// GET /v1/users/{userId}/inbox?view=unread&limit=20&offset=0
+func (h *NotificationHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user id is required"))
+		return
+	}
+
+	view := models.InboxView(r.URL.Query().Get("view"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	resp, svcErr := h.notifService.GetInbox(r.Context(), userID, view, limit, offset)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, resp)
+}
+
+// MarkInboxRead marks an in-app notification read.
+// POST /v1/users/{userId}/inbox/{id}/read
+func (h *NotificationHandler) MarkInboxRead(w http.ResponseWriter, r *http.Request) {
+	h.setInboxRead(w, r, true)
+}
+
+// MarkInboxUnread marks an in-app notification unread.
+// POST /v1/users/{userId}/inbox/{id}/unread
+func (h *NotificationHandler) MarkInboxUnread(w http.ResponseWriter, r *http.Request) {
+	h.setInboxRead(w, r, false)
+}
+
+func (h *NotificationHandler) setInboxRead(w http.ResponseWriter, r *http.Request, read bool) {
+	userID, id, ok := inboxItemPath(w, r)
+	if !ok {
+		return
+	}
+
+	notif, svcErr := h.notifService.SetInboxRead(r.Context(), userID, id, read)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, notif)
+}
+
+// MarkAllInboxRead marks all of a user's unread in-app notifications read.
+// POST /v1/users/{userId}/inbox/read-all
+func (h *NotificationHandler) MarkAllInboxRead(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user id is required"))
+		return
+	}
+
+	resp, svcErr := h.notifService.MarkAllInboxRead(r.Context(), userID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, resp)
+}
+
+// ArchiveInboxNotification archives an in-app notification.
+// POST /v1/users/{userId}/inbox/{id}/archive
+func (h *NotificationHandler) ArchiveInboxNotification(w http.ResponseWriter, r *http.Request) {
+	h.setInboxArchived(w, r, true)
+}
+
+// UnarchiveInboxNotification moves an archived notification back to the inbox.
+// POST /v1/users/{userId}/inbox/{id}/unarchive
+func (h *NotificationHandler) UnarchiveInboxNotification(w http.ResponseWriter, r *http.Request) {
+	h.setInboxArchived(w, r, false)
+}
+
+func (h *NotificationHandler) setInboxArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	userID, id, ok := inboxItemPath(w, r)
+	if !ok {
+		return
+	}
+
+	notif, svcErr := h.notifService.SetInboxArchived(r.Context(), userID, id, archived)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, notif)
+}
+
+// inboxItemPath reads the user and notification IDs of an inbox item route,
+// writing an error response if either is missing.
+func inboxItemPath(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+	userID := r.PathValue("userId")
+	id := r.PathValue("id")
+
+	if userID == "" || id == "" {
+		response.Error(w, errors.BadRequest("user id and notification id are required"))
+		return "", "", false
+	}
+
+	return userID, id, true
+}
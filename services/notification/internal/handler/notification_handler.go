@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
 	"strconv"
@@ -119,6 +120,47 @@ func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.R
 	response.OK(w, resp)
 }
 
+// CancelNotification cancels a scheduled notification before its send time.
+// POST /v1/notifications/{id}/cancel
+func (h *NotificationHandler) CancelNotification(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("notification id is required"))
+		return
+	}
+
+	notif, svcErr := h.notifService.CancelScheduledNotification(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, notif)
+}
+
+// ListScheduledNotifications lists a user's upcoming deliveries.
+// GET /v1/users/{userId}/notifications/scheduled
+func (h *NotificationHandler) ListScheduledNotifications(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user id is required"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	resp, svcErr := h.notifService.ListScheduledNotifications(r.Context(), userID, limit, offset)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, resp)
+}
+
 // CreateTemplate creates a new notification template.
 // POST /v1/templates
 func (h *NotificationHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
@@ -232,7 +274,7 @@ func (h *NotificationHandler) PreviewTemplate(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	preview, svcErr := h.notifService.PreviewTemplate(r.Context(), id, req.Variables)
+	preview, svcErr := h.notifService.PreviewTemplate(r.Context(), id, req.Variables, req.Locale)
 	if svcErr != nil {
 		response.Error(w, svcErr)
 		return
@@ -241,8 +283,67 @@ func (h *NotificationHandler) PreviewTemplate(w http.ResponseWriter, r *http.Req
 	response.OK(w, preview)
 }
 
-// GetStats retrieves notification statistics.
-// GET /admin/notifications/stats
+// ValidateTemplate lints template content against a notification type's
+// variable contract without saving it.
+// POST /v1/templates/validate
+func (h *NotificationHandler) ValidateTemplate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.ValidateTemplateRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	response.OK(w, h.notifService.ValidateTemplate(&req))
+}
+
+// ListTemplateContracts lists the variables each notification type supplies
+// to templates.
+// GET /v1/templates/contracts
+func (h *NotificationHandler) ListTemplateContracts(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, service.TemplateContracts())
+}
+
+// trackingPixel is a transparent 1x1 GIF.
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// TrackOpen records an email open and serves the tracking pixel. The pixel is
+// served even for unknown IDs, so mail clients never show a broken image.
+// GET /v1/track/open/{id}
+func (h *NotificationHandler) TrackOpen(w http.ResponseWriter, r *http.Request) {
+	_ = h.notifService.TrackOpen(r.Context(), r.PathValue("id"))
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(trackingPixel)
+}
+
+// TrackClick records a click on a tracked email link and redirects to it.
+// GET /v1/track/click/{id}
+func (h *NotificationHandler) TrackClick(w http.ResponseWriter, r *http.Request) {
+	target, svcErr := h.notifService.TrackClick(r.Context(), r.PathValue("id"))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// GetStats retrieves notification statistics, with delivery and engagement
+// broken down by template and type.
+// GET /v1/notifications/stats
 func (h *NotificationHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, svcErr := h.notifService.GetStats(r.Context())
 	if svcErr != nil {
@@ -253,6 +354,36 @@ func (h *NotificationHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, stats)
 }
 
+// GetRateLimits lists rate limits, provider quotas, overrides and live counters.
+// GET /admin/notifications/rate-limits
+func (h *NotificationHandler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	status, svcErr := h.notifService.GetRateLimitStatus()
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, status)
+}
+
+// OverrideRateLimit sets a counter or temporarily replaces a limit.
+// POST /admin/notifications/rate-limits/override
+func (h *NotificationHandler) OverrideRateLimit(w http.ResponseWriter, r *http.Request) {
+	var req models.OverrideRateLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	status, svcErr := h.notifService.OverrideRateLimit(&req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, status)
+}
+
 // ReplayNotification re-queues a notification for replay/testing.
 // POST /admin/notifications/{id}/replay
 func (h *NotificationHandler) ReplayNotification(w http.ResponseWriter, r *http.Request) {
@@ -271,6 +402,30 @@ func (h *NotificationHandler) ReplayNotification(w http.ResponseWriter, r *http.
 	response.NoContent(w)
 }
 
+// ApplyStatusCallbacks applies a batch of provider delivery receipts.
+// POST /internal/v1/notifications/status-callbacks
+func (h *NotificationHandler) ApplyStatusCallbacks(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	var req models.StatusCallbackRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	resp, svcErr := h.notifService.ApplyStatusCallbacks(r.Context(), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, resp)
+}
+
 // Health check endpoint.
 // GET /health
 func (h *NotificationHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -9,15 +9,25 @@ import (
 
 // Router handles HTTP routing for the notification service.
 type Router struct {
-	handler *NotificationHandler
-	metrics *metrics.Collector
+	handler        *NotificationHandler
+	domainHandler  *DomainHandler
+	versionHandler *TemplateVersionHandler
+	translations   *TranslationHandler
+	webhookHandler *WebhookHandler
+	metrics        *metrics.Collector
+	internalSecret string
 }
 
 // NewRouter creates a new router.
-func NewRouter(handler *NotificationHandler) *Router {
+func NewRouter(handler *NotificationHandler, domainHandler *DomainHandler, versionHandler *TemplateVersionHandler, translationHandler *TranslationHandler, webhookHandler *WebhookHandler, internalSecret string) *Router {
 	return &Router{
-		handler: handler,
-		metrics: metrics.NewCollector("notification"),
+		handler:        handler,
+		domainHandler:  domainHandler,
+		versionHandler: versionHandler,
+		translations:   translationHandler,
+		webhookHandler: webhookHandler,
+		metrics:        metrics.NewCollector("notification"),
+		internalSecret: internalSecret,
 	}
 }
 
@@ -34,19 +44,80 @@ func (ro *Router) SetupRoutes() http.Handler {
 
 	// Notification endpoints
 	mux.HandleFunc("POST /v1/notifications/send", ro.handler.SendNotification)
+	mux.HandleFunc("GET /v1/notifications/stats", ro.handler.GetStats)
 	mux.HandleFunc("GET /v1/notifications/{id}", ro.handler.GetNotification)
 	mux.HandleFunc("GET /v1/notifications", ro.handler.ListNotifications)
 
+	// Scheduled notifications
+	mux.HandleFunc("POST /v1/notifications/{id}/cancel", ro.handler.CancelNotification)
+	mux.HandleFunc("GET /v1/users/{userId}/notifications/scheduled", ro.handler.ListScheduledNotifications)
+
+	// In-app inbox
+	mux.HandleFunc("GET /v1/users/{userId}/inbox", ro.handler.GetInbox)
+	mux.HandleFunc("POST /v1/users/{userId}/inbox/read-all", ro.handler.MarkAllInboxRead)
+	mux.HandleFunc("POST /v1/users/{userId}/inbox/{id}/read", ro.handler.MarkInboxRead)
+	mux.HandleFunc("POST /v1/users/{userId}/inbox/{id}/unread", ro.handler.MarkInboxUnread)
+	mux.HandleFunc("POST /v1/users/{userId}/inbox/{id}/archive", ro.handler.ArchiveInboxNotification)
+	mux.HandleFunc("POST /v1/users/{userId}/inbox/{id}/unarchive", ro.handler.UnarchiveInboxNotification)
+
+	// Webhook endpoints and delivery attempts
+	mux.HandleFunc("POST /v1/webhook-endpoints", ro.webhookHandler.CreateEndpoint)
+	mux.HandleFunc("GET /v1/webhook-endpoints", ro.webhookHandler.ListEndpoints)
+	mux.HandleFunc("GET /v1/webhook-endpoints/{id}", ro.webhookHandler.GetEndpoint)
+	mux.HandleFunc("PUT /v1/webhook-endpoints/{id}", ro.webhookHandler.UpdateEndpoint)
+	mux.HandleFunc("DELETE /v1/webhook-endpoints/{id}", ro.webhookHandler.DeleteEndpoint)
+	mux.HandleFunc("POST /v1/webhook-endpoints/{id}/rotate-secret", ro.webhookHandler.RotateSecret)
+	mux.HandleFunc("GET /v1/notifications/{id}/webhook-deliveries", ro.webhookHandler.ListDeliveries)
+
+	// Email engagement tracking (public: loaded by mail clients and recipients)
+	mux.HandleFunc("GET /v1/track/open/{id}", ro.handler.TrackOpen)
+	mux.HandleFunc("GET /v1/track/click/{id}", ro.handler.TrackClick)
+
+	// Provider delivery receipts (service-to-service with shared secret auth)
+	mux.HandleFunc("POST /internal/v1/notifications/status-callbacks",
+		middleware.InternalAuthFunc(ro.internalSecret, ro.handler.ApplyStatusCallbacks))
+
 	// Template endpoints
 	mux.HandleFunc("POST /v1/templates", ro.handler.CreateTemplate)
 	mux.HandleFunc("GET /v1/templates/{id}", ro.handler.GetTemplate)
 	mux.HandleFunc("GET /v1/templates", ro.handler.ListTemplates)
 	mux.HandleFunc("PUT /v1/templates/{id}", ro.handler.UpdateTemplate)
 	mux.HandleFunc("POST /v1/templates/{id}/preview", ro.handler.PreviewTemplate)
+	mux.HandleFunc("POST /v1/templates/validate", ro.handler.ValidateTemplate)
+	mux.HandleFunc("GET /v1/templates/contracts", ro.handler.ListTemplateContracts)
+
+	// Template version and rollout endpoints
+	mux.HandleFunc("POST /v1/templates/{id}/versions", ro.versionHandler.CreateVersion)
+	mux.HandleFunc("GET /v1/templates/{id}/versions", ro.versionHandler.ListVersions)
+	mux.HandleFunc("GET /v1/templates/{id}/versions/{version}", ro.versionHandler.GetVersion)
+	mux.HandleFunc("POST /v1/templates/{id}/versions/{version}/publish", ro.versionHandler.PublishVersion)
+	mux.HandleFunc("GET /v1/templates/{id}/rollouts", ro.versionHandler.ListRollouts)
+	mux.HandleFunc("PUT /v1/templates/{id}/rollouts/{type}", ro.versionHandler.SetRollout)
+	mux.HandleFunc("DELETE /v1/templates/{id}/rollouts/{type}", ro.versionHandler.DeleteRollout)
+	mux.HandleFunc("GET /v1/templates/{id}/stats", ro.versionHandler.GetStats)
+
+	// Template translation endpoints
+	mux.HandleFunc("GET /v1/templates/{id}/translations", ro.translations.ListTranslations)
+	mux.HandleFunc("GET /v1/templates/{id}/translations/{locale}", ro.translations.GetTranslation)
+	mux.HandleFunc("PUT /v1/templates/{id}/translations/{locale}", ro.translations.UpsertTranslation)
+	mux.HandleFunc("DELETE /v1/templates/{id}/translations/{locale}", ro.translations.DeleteTranslation)
 
 	// Admin endpoints (protected by RBAC in gateway)
 	mux.HandleFunc("GET /admin/notifications/stats", ro.handler.GetStats)
 	mux.HandleFunc("POST /admin/notifications/{id}/replay", ro.handler.ReplayNotification)
+	mux.HandleFunc("GET /admin/notifications/rate-limits", ro.handler.GetRateLimits)
+	mux.HandleFunc("POST /admin/notifications/rate-limits/override", ro.handler.OverrideRateLimit)
+	mux.HandleFunc("GET /admin/templates/translations/missing", ro.translations.ListMissingTranslations)
+
+	// Sending domain endpoints (protected by RBAC in gateway)
+	mux.HandleFunc("POST /admin/domains", ro.domainHandler.CreateDomain)
+	mux.HandleFunc("GET /admin/domains", ro.domainHandler.ListDomains)
+	mux.HandleFunc("GET /admin/domains/{id}", ro.domainHandler.GetDomain)
+	mux.HandleFunc("PUT /admin/domains/{id}", ro.domainHandler.UpdateDomain)
+	mux.HandleFunc("POST /admin/domains/{id}/dkim", ro.domainHandler.RegisterDKIM)
+	mux.HandleFunc("POST /admin/domains/{id}/verify", ro.domainHandler.VerifyDomain)
+	mux.HandleFunc("GET /admin/domains/{id}/dns-records", ro.domainHandler.GetDNSRecords)
+	mux.HandleFunc("GET /admin/domains/{id}/stats", ro.domainHandler.GetDeliveryStats)
 
 	// Apply middleware chain
 	handler := ro.applyMiddleware(mux)
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+	"github.com/google/uuid"
+)
+
+// TemplateVersionHandler handles template version and rollout HTTP requests.
+type TemplateVersionHandler struct {
+	versionService *service.TemplateVersionService
+}
+
+// NewTemplateVersionHandler creates a new template version handler.
+func NewTemplateVersionHandler(versionService *service.TemplateVersionService) *TemplateVersionHandler {
+	return &TemplateVersionHandler{
+		versionService: versionService,
+	}
+}
+
+// CreateVersion adds a draft version to a template.
+// POST /v1/templates/{id}/versions
+func (h *TemplateVersionHandler) CreateVersion(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.CreateTemplateVersionRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	version, svcErr := h.versionService.CreateVersion(r.Context(), templateID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Created(w, version)
+}
+
+// ListVersions retrieves all versions of a template.
+// GET /v1/templates/{id}/versions
+func (h *TemplateVersionHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	versions, svcErr := h.versionService.ListVersions(r.Context(), templateID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, versions)
+}
+
+// GetVersion retrieves one version of a template.
+// GET /v1/templates/{id}/versions/{version}
+func (h *TemplateVersionHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	v, svcErr := h.versionService.GetVersion(r.Context(), templateID, version)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// PublishVersion publishes a draft version.
+// POST /v1/templates/{id}/versions/{version}/publish
+func (h *TemplateVersionHandler) PublishVersion(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+	version, ok := versionParam(w, r)
+	if !ok {
+		return
+	}
+
+	v, svcErr := h.versionService.PublishVersion(r.Context(), templateID, version)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// ListRollouts retrieves the per-type rollouts of a template.
+// GET /v1/templates/{id}/rollouts
+func (h *TemplateVersionHandler) ListRollouts(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	rollouts, svcErr := h.versionService.ListRollouts(r.Context(), templateID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, rollouts)
+}
+
+// SetRollout pins a notification type to a version, optionally with an A/B candidate.
+// PUT /v1/templates/{id}/rollouts/{type}
+func (h *TemplateVersionHandler) SetRollout(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.SetTemplateRolloutRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	notifType := models.NotificationType(r.PathValue("type"))
+	rollout, svcErr := h.versionService.SetRollout(r.Context(), templateID, notifType, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, rollout)
+}
+
+// DeleteRollout removes a type's pin.
+// DELETE /v1/templates/{id}/rollouts/{type}
+func (h *TemplateVersionHandler) DeleteRollout(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	notifType := models.NotificationType(r.PathValue("type"))
+	if svcErr := h.versionService.DeleteRollout(r.Context(), templateID, notifType); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// GetStats retrieves per-version delivery statistics of a template.
+// GET /v1/templates/{id}/stats
+func (h *TemplateVersionHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	stats, svcErr := h.versionService.GetStats(r.Context(), templateID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, stats)
+}
+
+// templateIDParam reads the template UUID from the path, writing a 400 when invalid.
+func templateIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		response.Error(w, errors.BadRequest("template id must be a UUID"))
+		return "", false
+	}
+	return id, true
+}
+
+// versionParam reads the version number from the path, writing a 400 when invalid.
+func versionParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || version < 1 {
+		response.Error(w, errors.BadRequest("version must be a positive integer"))
+		return 0, false
+	}
+	return version, true
+}
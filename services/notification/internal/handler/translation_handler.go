@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// TranslationHandler handles template translation HTTP requests.
+type TranslationHandler struct {
+	translationService *service.TranslationService
+}
+
+// NewTranslationHandler creates a new template translation handler.
+func NewTranslationHandler(translationService *service.TranslationService) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: translationService,
+	}
+}
+
+// UpsertTranslation creates or replaces a template's translation into a locale.
+// PUT /v1/templates/{id}/translations/{locale}
+func (h *TranslationHandler) UpsertTranslation(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.UpsertTranslationRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	translation, svcErr := h.translationService.UpsertTranslation(r.Context(), templateID, r.PathValue("locale"), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, translation)
+}
+
+// ListTranslations retrieves all translations of a template.
+// GET /v1/templates/{id}/translations
+func (h *TranslationHandler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	translations, svcErr := h.translationService.ListTranslations(r.Context(), templateID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, translations)
+}
+
+// GetTranslation retrieves a template's translation into a locale.
+// GET /v1/templates/{id}/translations/{locale}
+func (h *TranslationHandler) GetTranslation(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	translation, svcErr := h.translationService.GetTranslation(r.Context(), templateID, r.PathValue("locale"))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, translation)
+}
+
+// DeleteTranslation removes a template's translation into a locale.
+// DELETE /v1/templates/{id}/translations/{locale}
+func (h *TranslationHandler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	templateID, ok := templateIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if svcErr := h.translationService.DeleteTranslation(r.Context(), templateID, r.PathValue("locale")); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListMissingTranslations reports templates without an up-to-date translation,
+// for one locale or every supported locale.
+// GET /admin/templates/translations/missing?locale=hi
+func (h *TranslationHandler) ListMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	reports, svcErr := h.translationService.MissingTranslations(r.Context(), r.URL.Query().Get("locale"))
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, reports)
+}
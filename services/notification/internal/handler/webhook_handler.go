@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// WebhookHandler handles webhook endpoint HTTP requests.
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler.
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateEndpoint registers a webhook endpoint. The signing secret is only
+// returned in this response.
+// POST /v1/webhook-endpoints
+func (h *WebhookHandler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.CreateWebhookEndpointRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	endpoint, svcErr := h.webhookService.CreateEndpoint(r.Context(), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.Created(w, endpoint)
+}
+
+// ListEndpoints retrieves webhook endpoints, optionally filtered by owner.
+// GET /v1/webhook-endpoints?owner_type=user&owner_id=...
+func (h *WebhookHandler) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	var ownerType *models.WebhookOwnerType
+	if t := r.URL.Query().Get("owner_type"); t != "" {
+		ot := models.WebhookOwnerType(t)
+		ownerType = &ot
+	}
+	var ownerID *string
+	if id := r.URL.Query().Get("owner_id"); id != "" {
+		ownerID = &id
+	}
+
+	endpoints, svcErr := h.webhookService.ListEndpoints(r.Context(), ownerType, ownerID)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, endpoints)
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID.
+// GET /v1/webhook-endpoints/{id}
+func (h *WebhookHandler) GetEndpoint(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("endpoint id is required"))
+		return
+	}
+
+	endpoint, svcErr := h.webhookService.GetEndpoint(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, endpoint)
+}
+
+// UpdateEndpoint changes a webhook endpoint's URL, description or enabled flag.
+// PUT /v1/webhook-endpoints/{id}
+func (h *WebhookHandler) UpdateEndpoint(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("endpoint id is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	req, err := model.ParseInto[models.UpdateWebhookEndpointRequest](body)
+	if err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	endpoint, svcErr := h.webhookService.UpdateEndpoint(r.Context(), id, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, endpoint)
+}
+
+// RotateSecret issues a new signing secret for a webhook endpoint.
+// POST /v1/webhook-endpoints/{id}/rotate-secret
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("endpoint id is required"))
+		return
+	}
+
+	endpoint, svcErr := h.webhookService.RotateSecret(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, endpoint)
+}
+
+// DeleteEndpoint removes a webhook endpoint.
+// DELETE /v1/webhook-endpoints/{id}
+func (h *WebhookHandler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("endpoint id is required"))
+		return
+	}
+
+	if svcErr := h.webhookService.DeleteEndpoint(r.Context(), id); svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListDeliveries retrieves the webhook delivery attempts of a notification.
+// GET /v1/notifications/{id}/webhook-deliveries
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if id == "" {
+		response.Error(w, errors.BadRequest("notification id is required"))
+		return
+	}
+
+	deliveries, svcErr := h.webhookService.ListDeliveries(r.Context(), id)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	response.OK(w, deliveries)
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Attachment limits for email notifications.
+const (
+	MaxAttachments           = 5
+	MaxInlineAttachmentBytes = 2 << 20  // Decoded size of one base64 attachment
+	MaxAttachmentTotalBytes  = 10 << 20 // Decoded size of every inline attachment of a notification
+	MaxAttachmentFilename    = 255
+	MaxAttachmentStorageKey  = 1024
+)
+
+// AllowedAttachmentTypes are the content types an attachment may have:
+// statements, letters and scans.
+var AllowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"text/plain":      true,
+	"text/csv":        true,
+}
+
+// AttachmentRequest attaches a file to an email notification, either by
+// object storage key or inline as base64. Exactly one of StorageKey and
+// Content is set.
+type AttachmentRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	StorageKey  string `json:"storage_key,omitempty"` // Object storage key, e.g. statements/2025/03/<user>.pdf
+	Content     string `json:"content,omitempty"`     // Base64, at most MaxInlineAttachmentBytes decoded
+}
+
+// NotificationAttachment is a file sent with an email notification. Inline
+// content is stored with the notification; stored files are fetched by the
+// email provider adapter at delivery.
+type NotificationAttachment struct {
+	ID             string           `json:"id" db:"id"`
+	NotificationID string           `json:"notification_id" db:"notification_id"`
+	Filename       string           `json:"filename" db:"filename"`
+	ContentType    string           `json:"content_type" db:"content_type"`
+	SizeBytes      *int64           `json:"size_bytes,omitempty" db:"size_bytes"`   // Known for inline content only
+	StorageKey     *string          `json:"storage_key,omitempty" db:"storage_key"` // Nil for inline content
+	Content        []byte           `json:"-" db:"content"`                         // Inline content; loaded for delivery only
+	CreatedAt      models.Timestamp `json:"created_at" db:"created_at"`
+}
+
+// IsInline returns true if the attachment's content is stored with the notification.
+func (a *NotificationAttachment) IsInline() bool {
+	return a.StorageKey == nil
+}
+
+// ParseAttachments validates attachment requests and decodes inline content.
+func ParseAttachments(channel NotificationChannel, reqs []AttachmentRequest) ([]*NotificationAttachment, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	if channel != ChannelEmail {
+		return nil, fmt.Errorf("attachments are only supported for email notifications")
+	}
+	if len(reqs) > MaxAttachments {
+		return nil, fmt.Errorf("at most %d attachments are allowed", MaxAttachments)
+	}
+
+	attachments := make([]*NotificationAttachment, 0, len(reqs))
+	var total int64
+	for i, req := range reqs {
+		filename := strings.TrimSpace(req.Filename)
+		switch {
+		case filename == "":
+			return nil, fmt.Errorf("attachment %d: filename is required", i+1)
+		case len(filename) > MaxAttachmentFilename:
+			return nil, fmt.Errorf("attachment %d: filename must be at most %d characters", i+1, MaxAttachmentFilename)
+		case filepath.Base(filename) != filename || strings.ContainsAny(filename, "\\\r\n"):
+			return nil, fmt.Errorf("attachment %d: filename must not contain a path", i+1)
+		}
+
+		contentType := strings.ToLower(strings.TrimSpace(req.ContentType))
+		if !AllowedAttachmentTypes[contentType] {
+			return nil, fmt.Errorf("attachment %d: unsupported content type %q", i+1, req.ContentType)
+		}
+
+		attachment := &NotificationAttachment{Filename: filename, ContentType: contentType}
+		switch {
+		case req.StorageKey != "" && req.Content != "":
+			return nil, fmt.Errorf("attachment %d: set either storage_key or content, not both", i+1)
+		case req.StorageKey != "":
+			if len(req.StorageKey) > MaxAttachmentStorageKey {
+				return nil, fmt.Errorf("attachment %d: storage_key must be at most %d characters", i+1, MaxAttachmentStorageKey)
+			}
+			key := req.StorageKey
+			attachment.StorageKey = &key
+		case req.Content != "":
+			if base64.StdEncoding.DecodedLen(len(req.Content)) > MaxInlineAttachmentBytes+2 {
+				return nil, fmt.Errorf("attachment %d: content must be at most %d bytes", i+1, MaxInlineAttachmentBytes)
+			}
+			content, err := base64.StdEncoding.DecodeString(req.Content)
+			if err != nil {
+				return nil, fmt.Errorf("attachment %d: content must be base64", i+1)
+			}
+			if len(content) == 0 || len(content) > MaxInlineAttachmentBytes {
+				return nil, fmt.Errorf("attachment %d: content must be 1 to %d bytes", i+1, MaxInlineAttachmentBytes)
+			}
+			size := int64(len(content))
+			total += size
+			attachment.Content = content
+			attachment.SizeBytes = &size
+		default:
+			return nil, fmt.Errorf("attachment %d: storage_key or content is required", i+1)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	if total > MaxAttachmentTotalBytes {
+		return nil, fmt.Errorf("inline attachments must be at most %d bytes in total", MaxAttachmentTotalBytes)
+	}
+	return attachments, nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// DomainStatus represents the verification status of a sending domain.
+type DomainStatus string
+
+const (
+	DomainStatusPending  DomainStatus = "pending"  // Awaiting verification
+	DomainStatusVerified DomainStatus = "verified" // DNS checks passed
+	DomainStatusFailed   DomainStatus = "failed"   // Last verification failed
+)
+
+// DefaultTenant is the tenant used when a notification does not specify one.
+const DefaultTenant = "default"
+
+// SendingDomain represents an email sending domain and its sender configuration.
+type SendingDomain struct {
+	ID                 string                      `json:"id" db:"id"`
+	Domain             string                      `json:"domain" db:"domain"`
+	Tenant             string                      `json:"tenant" db:"tenant"`
+	DefaultFromAddress string                      `json:"default_from_address" db:"default_from_address"`
+	FromAddresses      map[NotificationType]string `json:"from_addresses,omitempty" db:"from_addresses"` // Per-type overrides
+	BounceAddress      string                      `json:"bounce_address" db:"bounce_address"`
+	DKIMSelector       *string                     `json:"dkim_selector,omitempty" db:"dkim_selector"`
+	DKIMPublicKey      *string                     `json:"dkim_public_key,omitempty" db:"dkim_public_key"`
+	Status             DomainStatus                `json:"status" db:"status"`
+	Enabled            bool                        `json:"enabled" db:"enabled"`
+	LastVerifiedAt     *models.Timestamp           `json:"last_verified_at,omitempty" db:"last_verified_at"`
+	CreatedAt          models.Timestamp            `json:"created_at" db:"created_at"`
+	UpdatedAt          models.Timestamp            `json:"updated_at" db:"updated_at"`
+}
+
+// IsVerified returns true if the domain passed verification.
+func (d *SendingDomain) IsVerified() bool {
+	return d.Status == DomainStatusVerified
+}
+
+// FromAddressFor returns the from-address for a notification type.
+func (d *SendingDomain) FromAddressFor(notifType NotificationType) string {
+	if addr, ok := d.FromAddresses[notifType]; ok && addr != "" {
+		return addr
+	}
+	return d.DefaultFromAddress
+}
+
+// DKIMRecordHost returns the DNS host where the DKIM key is published.
+func (d *SendingDomain) DKIMRecordHost() string {
+	if d.DKIMSelector == nil {
+		return ""
+	}
+	return *d.DKIMSelector + "._domainkey." + d.Domain
+}
+
+// CreateDomainRequest represents a request to register a sending domain.
+type CreateDomainRequest struct {
+	Domain             string                      `json:"domain" validate:"required,max=253"`
+	Tenant             string                      `json:"tenant,omitempty" validate:"omitempty,max=100"`
+	DefaultFromAddress string                      `json:"default_from_address" validate:"required,email"`
+	FromAddresses      map[NotificationType]string `json:"from_addresses,omitempty"`
+	BounceAddress      string                      `json:"bounce_address" validate:"required,email"`
+}
+
+// UpdateDomainRequest represents a request to update a sending domain.
+type UpdateDomainRequest struct {
+	DefaultFromAddress *string                     `json:"default_from_address,omitempty" validate:"omitempty,email"`
+	FromAddresses      map[NotificationType]string `json:"from_addresses,omitempty"`
+	BounceAddress      *string                     `json:"bounce_address,omitempty" validate:"omitempty,email"`
+	Enabled            *bool                       `json:"enabled,omitempty"`
+}
+
+// RegisterDKIMRequest represents a request to register a DKIM key for a domain.
+type RegisterDKIMRequest struct {
+	Selector string `json:"selector" validate:"required,max=63"`
+}
+
+// DNSRecord represents a DNS record the domain owner must publish.
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Host  string `json:"host"`
+	Value string `json:"value"`
+}
+
+// DomainCheck represents the outcome of a single verification check.
+type DomainCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// DomainVerificationResult represents the result of verifying a sending domain.
+type DomainVerificationResult struct {
+	DomainID   string           `json:"domain_id"`
+	Domain     string           `json:"domain"`
+	Status     DomainStatus     `json:"status"`
+	Checks     []DomainCheck    `json:"checks"`
+	DNSRecords []DNSRecord      `json:"dns_records"`
+	VerifiedAt models.Timestamp `json:"verified_at"`
+}
+
+// DomainDeliveryStats represents delivery statistics for a sending domain.
+type DomainDeliveryStats struct {
+	DomainID     string                     `json:"domain_id"`
+	Domain       string                     `json:"domain"`
+	Total        int64                      `json:"total"`
+	ByStatus     map[NotificationStatus]int `json:"by_status"`
+	ByType       map[NotificationType]int   `json:"by_type"`
+	DeliveryRate float64                    `json:"delivery_rate"` // Percentage
+}
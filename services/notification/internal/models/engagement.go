@@ -0,0 +1,106 @@
+package models
+
+import "time"
+
+// TrackingKind says what a tracking link records.
+type TrackingKind string
+
+const (
+	TrackingOpen  TrackingKind = "open"  // Pixel loaded when the email is displayed
+	TrackingClick TrackingKind = "click" // Redirect to a link in the email
+)
+
+// TrackingLink is an open pixel or click redirect embedded in an email.
+type TrackingLink struct {
+	ID             string       `json:"id" db:"id"`
+	NotificationID string       `json:"notification_id" db:"notification_id"`
+	Kind           TrackingKind `json:"kind" db:"kind"`
+	URL            *string      `json:"url,omitempty" db:"url"` // Redirect target; nil for the open pixel
+	Hits           int          `json:"hits" db:"hits"`
+	FirstHitAt     *time.Time   `json:"first_hit_at,omitempty" db:"first_hit_at"`
+	LastHitAt      *time.Time   `json:"last_hit_at,omitempty" db:"last_hit_at"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+}
+
+// NoTemplate keys engagement of notifications sent without a template.
+const NoTemplate = "none"
+
+// EngagementCounts are delivery and engagement counts for a group of
+// notifications. A click counts as an open, since images may be blocked.
+type EngagementCounts struct {
+	Sent      int64 `json:"sent"`      // Handed to a provider
+	Delivered int64 `json:"delivered"` // Delivered, by receipt or simulation
+	Failed    int64 `json:"failed"`
+	Receipts  int64 `json:"receipts"` // Status confirmed by a provider delivery receipt
+	Opened    int64 `json:"opened"`   // Email only
+	Clicked   int64 `json:"clicked"`  // Email only
+
+	DeliveryRate float64 `json:"delivery_rate"` // Percentage of finished sends that were delivered
+	OpenRate     float64 `json:"open_rate"`     // Percentage of delivered that were opened
+	ClickRate    float64 `json:"click_rate"`    // Percentage of delivered that were clicked
+}
+
+// Add adds other's counts to c. Rates are left for Finish.
+func (c *EngagementCounts) Add(other EngagementCounts) {
+	c.Sent += other.Sent
+	c.Delivered += other.Delivered
+	c.Failed += other.Failed
+	c.Receipts += other.Receipts
+	c.Opened += other.Opened
+	c.Clicked += other.Clicked
+}
+
+// Finish computes the rates from the counts.
+func (c *EngagementCounts) Finish() {
+	c.DeliveryRate, c.OpenRate, c.ClickRate = 0, 0, 0
+	if finished := c.Delivered + c.Failed; finished > 0 {
+		c.DeliveryRate = float64(c.Delivered) / float64(finished) * 100
+	}
+	if c.Delivered > 0 {
+		c.OpenRate = float64(c.Opened) / float64(c.Delivered) * 100
+		c.ClickRate = float64(c.Clicked) / float64(c.Delivered) * 100
+	}
+}
+
+// EngagementStats breaks delivery and engagement down by template and type.
+type EngagementStats struct {
+	Total      EngagementCounts                       `json:"total"`
+	ByTemplate map[string]*EngagementCounts           `json:"by_template"` // Keyed by template ID, or NoTemplate
+	ByType     map[NotificationType]*EngagementCounts `json:"by_type"`
+}
+
+// NewEngagementStats returns empty engagement stats.
+func NewEngagementStats() *EngagementStats {
+	return &EngagementStats{
+		ByTemplate: make(map[string]*EngagementCounts),
+		ByType:     make(map[NotificationType]*EngagementCounts),
+	}
+}
+
+// Add counts a group of notifications sharing a template and type.
+func (s *EngagementStats) Add(templateID *string, notifType NotificationType, counts EngagementCounts) {
+	key := NoTemplate
+	if templateID != nil {
+		key = *templateID
+	}
+	if s.ByTemplate[key] == nil {
+		s.ByTemplate[key] = &EngagementCounts{}
+	}
+	if s.ByType[notifType] == nil {
+		s.ByType[notifType] = &EngagementCounts{}
+	}
+	s.ByTemplate[key].Add(counts)
+	s.ByType[notifType].Add(counts)
+	s.Total.Add(counts)
+}
+
+// Finish computes the rates of every group.
+func (s *EngagementStats) Finish() {
+	s.Total.Finish()
+	for _, c := range s.ByTemplate {
+		c.Finish()
+	}
+	for _, c := range s.ByType {
+		c.Finish()
+	}
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/models"
 )
@@ -10,10 +12,11 @@ import (
 type NotificationChannel string
 
 const (
-	ChannelSMS   NotificationChannel = "sms"    // SMS text message
-	ChannelEmail NotificationChannel = "email"  // Email message
-	ChannelPush  NotificationChannel = "push"   // Push notification
-	ChannelInApp NotificationChannel = "in_app" // In-app notification
+	ChannelSMS     NotificationChannel = "sms"     // SMS text message
+	ChannelEmail   NotificationChannel = "email"   // Email message
+	ChannelPush    NotificationChannel = "push"    // Push notification
+	ChannelInApp   NotificationChannel = "in_app"  // In-app notification
+	ChannelWebhook NotificationChannel = "webhook" // HTTP callback to an operator endpoint
 )
 
 // NotificationType represents the type of notification.
@@ -28,16 +31,19 @@ const (
 	TypeSecurityAlert    NotificationType = "security_alert"    // Security-related alert
 	TypeWalletAlert      NotificationType = "wallet_alert"      // Wallet-related alert
 	TypeSystemAlert      NotificationType = "system_alert"      // System notification
+	TypeMarketing        NotificationType = "marketing"         // Promotional message; requires consent
 )
 
 // NotificationStatus represents the delivery status of a notification.
 type NotificationStatus string
 
 const (
+	StatusScheduled NotificationStatus = "scheduled" // Held until its send time
 	StatusQueued    NotificationStatus = "queued"    // Queued for delivery
 	StatusSent      NotificationStatus = "sent"      // Sent to provider
 	StatusDelivered NotificationStatus = "delivered" // Successfully delivered
 	StatusFailed    NotificationStatus = "failed"    // Delivery failed
+	StatusCancelled NotificationStatus = "cancelled" // Scheduled, then cancelled before sending
 )
 
 // NotificationPriority represents the priority level of a notification.
@@ -52,27 +58,39 @@ const (
 
 // Notification represents a notification in the system.
 type Notification struct {
-	ID            string                 `json:"id" db:"id"`
-	UserID        *string                `json:"user_id,omitempty" db:"user_id"` // Null for system-wide notifications
-	Channel       NotificationChannel    `json:"channel" db:"channel"`
-	Type          NotificationType       `json:"type" db:"type"`
-	Priority      NotificationPriority   `json:"priority" db:"priority"`
-	Recipient     string                 `json:"recipient" db:"recipient"`       // Email address or phone number
-	Subject       string                 `json:"subject,omitempty" db:"subject"` // For email/push
-	Body          string                 `json:"body" db:"body"`
-	TemplateID    *string                `json:"template_id,omitempty" db:"template_id"`
-	Status        NotificationStatus     `json:"status" db:"status"`
-	CorrelationID *string                `json:"correlation_id,omitempty" db:"correlation_id"` // For idempotency
-	SourceService string                 `json:"source_service" db:"source_service"`
-	Metadata      map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
-	RetryCount    int                    `json:"retry_count" db:"retry_count"`
-	FailureReason *string                `json:"failure_reason,omitempty" db:"failure_reason"`
-	QueuedAt      models.Timestamp       `json:"queued_at" db:"queued_at"`
-	SentAt        *models.Timestamp      `json:"sent_at,omitempty" db:"sent_at"`
-	DeliveredAt   *models.Timestamp      `json:"delivered_at,omitempty" db:"delivered_at"`
-	FailedAt      *models.Timestamp      `json:"failed_at,omitempty" db:"failed_at"`
-	CreatedAt     models.Timestamp       `json:"created_at" db:"created_at"`
-	UpdatedAt     models.Timestamp       `json:"updated_at" db:"updated_at"`
+	ID              string                 `json:"id" db:"id"`
+	UserID          *string                `json:"user_id,omitempty" db:"user_id"` // Null for system-wide notifications
+	Channel         NotificationChannel    `json:"channel" db:"channel"`
+	Type            NotificationType       `json:"type" db:"type"`
+	Priority        NotificationPriority   `json:"priority" db:"priority"`
+	Recipient       string                 `json:"recipient" db:"recipient"`       // Email address or phone number
+	Subject         string                 `json:"subject,omitempty" db:"subject"` // For email/push
+	Body            string                 `json:"body" db:"body"`
+	TemplateID      *string                `json:"template_id,omitempty" db:"template_id"`
+	TemplateVersion *int                   `json:"template_version,omitempty" db:"template_version"`
+	Status          NotificationStatus     `json:"status" db:"status"`
+	CorrelationID   *string                `json:"correlation_id,omitempty" db:"correlation_id"` // For idempotency
+	SourceService   string                 `json:"source_service" db:"source_service"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	RetryCount      int                    `json:"retry_count" db:"retry_count"`
+	FailureReason   *string                `json:"failure_reason,omitempty" db:"failure_reason"`
+	QueuedAt        models.Timestamp       `json:"queued_at" db:"queued_at"`
+	SentAt          *models.Timestamp      `json:"sent_at,omitempty" db:"sent_at"`
+	DeliveredAt     *models.Timestamp      `json:"delivered_at,omitempty" db:"delivered_at"`
+	FailedAt        *models.Timestamp      `json:"failed_at,omitempty" db:"failed_at"`
+	ScheduledFor    *models.Timestamp      `json:"scheduled_for,omitempty" db:"scheduled_for"` // Release time for scheduled notifications
+	Timezone        *string                `json:"timezone,omitempty" db:"timezone"`           // Timezone the send time was requested in
+	ReadAt          *models.Timestamp      `json:"read_at,omitempty" db:"read_at"`             // In-app only: when the user read it
+	ArchivedAt      *models.Timestamp      `json:"archived_at,omitempty" db:"archived_at"`     // In-app only: when the user archived it
+	CreatedAt       models.Timestamp       `json:"created_at" db:"created_at"`
+	UpdatedAt       models.Timestamp       `json:"updated_at" db:"updated_at"`
+
+	Attachments []*NotificationAttachment `json:"attachments,omitempty" db:"-"` // Email only
+}
+
+// IsScheduled returns true if the notification is waiting for its send time.
+func (n *Notification) IsScheduled() bool {
+	return n.Status == StatusScheduled
 }
 
 // IsQueued returns true if the notification is queued.
@@ -95,6 +113,16 @@ func (n *Notification) IsFailed() bool {
 	return n.Status == StatusFailed
 }
 
+// IsRead returns true if the user has read the in-app notification.
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// IsArchived returns true if the user has archived the in-app notification.
+func (n *Notification) IsArchived() bool {
+	return n.ArchivedAt != nil
+}
+
 // IsCritical returns true if the notification is critical priority.
 func (n *Notification) IsCritical() bool {
 	return n.Priority == PriorityCritical
@@ -103,7 +131,7 @@ func (n *Notification) IsCritical() bool {
 // SendNotificationRequest represents a request to send a notification.
 type SendNotificationRequest struct {
 	UserID        *string                `json:"user_id,omitempty" validate:"omitempty,uuid"`
-	Channel       NotificationChannel    `json:"channel" validate:"required,oneof=sms email push in_app"`
+	Channel       NotificationChannel    `json:"channel" validate:"required,oneof=sms email push in_app webhook"`
 	Type          NotificationType       `json:"type" validate:"required"`
 	Priority      NotificationPriority   `json:"priority,omitempty" validate:"omitempty,oneof=critical high normal low"`
 	Recipient     string                 `json:"recipient" validate:"required"`
@@ -113,6 +141,69 @@ type SendNotificationRequest struct {
 	Variables     map[string]interface{} `json:"variables,omitempty"`
 	CorrelationID *string                `json:"correlation_id,omitempty" validate:"omitempty,max=100"`
 	MetadataRaw   json.RawMessage        `json:"metadata,omitempty"`
+	// SendAt schedules delivery: RFC 3339 with an offset, or a local time
+	// ("2006-01-02T15:04[:05]") in Timezone. Empty sends immediately.
+	SendAt   string `json:"send_at,omitempty"`
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,max=64"` // IANA name, default UTC
+	// Locale overrides the user's preferred locale for template rendering
+	Locale string `json:"locale,omitempty" validate:"omitempty,max=16"`
+	// Attachments are sent with email notifications (statements, KYC letters)
+	Attachments []AttachmentRequest `json:"attachments,omitempty"`
+}
+
+// Scheduling limits for SendAt.
+const (
+	MaxScheduleAhead  = 90 * 24 * time.Hour // Furthest a notification can be scheduled
+	ScheduleClockSkew = time.Minute         // Send times this far in the past send immediately
+)
+
+// localSendAtLayouts are accepted for send times without a UTC offset.
+var localSendAtLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// ScheduledTime resolves SendAt to an instant. It returns nil when the
+// notification should be sent now: no SendAt, or one within ScheduleClockSkew
+// of now.
+func (r *SendNotificationRequest) ScheduledTime(now time.Time) (*time.Time, error) {
+	if r.SendAt == "" {
+		if r.Timezone != "" {
+			return nil, fmt.Errorf("timezone requires send_at")
+		}
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if r.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(r.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", r.Timezone)
+		}
+	}
+
+	sendAt, err := time.Parse(time.RFC3339, r.SendAt)
+	if err != nil {
+		parsed := false
+		for _, layout := range localSendAtLayouts {
+			if sendAt, err = time.ParseInLocation(layout, r.SendAt, loc); err == nil {
+				parsed = true
+				break
+			}
+		}
+		if !parsed {
+			return nil, fmt.Errorf("send_at must be RFC 3339 or a local time like 2006-01-02T15:04")
+		}
+	}
+
+	switch {
+	case sendAt.Before(now.Add(-ScheduleClockSkew)):
+		return nil, fmt.Errorf("send_at is in the past")
+	case sendAt.After(now.Add(MaxScheduleAhead)):
+		return nil, fmt.Errorf("send_at must be within %d days", int(MaxScheduleAhead.Hours()/24))
+	case !sendAt.After(now):
+		return nil, nil
+	}
+
+	utc := sendAt.UTC()
+	return &utc, nil
 }
 
 // GetMetadata parses and returns the metadata map.
@@ -132,6 +223,7 @@ type SendNotificationResponse struct {
 	NotificationID string             `json:"notification_id"`
 	Status         NotificationStatus `json:"status"`
 	QueuedAt       models.Timestamp   `json:"queued_at"`
+	ScheduledFor   *models.Timestamp  `json:"scheduled_for,omitempty"`
 }
 
 // ListNotificationsRequest represents a request to list notifications with filters.
@@ -155,6 +247,41 @@ type ListNotificationsResponse struct {
 	Offset        int             `json:"offset"`
 }
 
+// InboxView selects which in-app notifications an inbox listing returns.
+type InboxView string
+
+const (
+	InboxViewInbox    InboxView = "inbox"    // Everything not archived (default)
+	InboxViewUnread   InboxView = "unread"   // Not read and not archived
+	InboxViewArchived InboxView = "archived" // Archived only
+	InboxViewAll      InboxView = "all"      // Read, unread and archived
+)
+
+// IsValid reports whether v is a known inbox view.
+func (v InboxView) IsValid() bool {
+	switch v {
+	case InboxViewInbox, InboxViewUnread, InboxViewArchived, InboxViewAll:
+		return true
+	}
+	return false
+}
+
+// InboxResponse is a page of a user's in-app notifications with their unread count.
+type InboxResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	Total         int64           `json:"total"`
+	UnreadCount   int64           `json:"unread_count"` // Unread and not archived, across all pages
+	View          InboxView       `json:"view"`
+	Limit         int             `json:"limit"`
+	Offset        int             `json:"offset"`
+}
+
+// MarkAllReadResponse reports how many notifications a mark-all-read updated.
+type MarkAllReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
 // NotificationStats represents statistics for notifications.
 type NotificationStats struct {
 	TotalNotifications int64                       `json:"total_notifications"`
@@ -163,4 +290,5 @@ type NotificationStats struct {
 	ByType             map[NotificationType]int    `json:"by_type"`
 	SuccessRate        float64                     `json:"success_rate"` // Percentage
 	AverageRetries     float64                     `json:"average_retries"`
+	Engagement         *EngagementStats            `json:"engagement"`
 }
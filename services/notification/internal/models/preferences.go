@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// UserPreferences are a user's profile attributes as published by identity.
+type UserPreferences struct {
+	UserID           string           `json:"user_id" db:"user_id"`
+	Locale           *string          `json:"locale,omitempty" db:"locale"`
+	Timezone         *string          `json:"timezone,omitempty" db:"timezone"`
+	DisplayCurrency  *string          `json:"display_currency,omitempty" db:"display_currency"`
+	MarketingConsent bool             `json:"marketing_consent" db:"marketing_consent"`
+	QuietHoursStart  *string          `json:"quiet_hours_start,omitempty" db:"quiet_hours_start"` // HH:MM in Timezone
+	QuietHoursEnd    *string          `json:"quiet_hours_end,omitempty" db:"quiet_hours_end"`     // HH:MM in Timezone
+	UpdatedAt        models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// Location returns the user's timezone, or UTC if unset or unknown.
+func (p *UserPreferences) Location() *time.Location {
+	if p.Timezone != nil {
+		if loc, err := time.LoadLocation(*p.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// QuietUntil reports whether t falls in the user's quiet hours and, if so,
+// when they end. Windows that cross midnight, such as 22:00-07:00, are
+// supported.
+func (p *UserPreferences) QuietUntil(t time.Time) (time.Time, bool) {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil {
+		return time.Time{}, false
+	}
+	start, errStart := minuteOfDay(*p.QuietHoursStart)
+	end, errEnd := minuteOfDay(*p.QuietHoursEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(p.Location())
+	now := local.Hour()*60 + local.Minute()
+	endOn := func(dayOffset int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+dayOffset, end/60, end%60, 0, 0, local.Location())
+	}
+
+	if start < end {
+		if now >= start && now < end {
+			return endOn(0), true
+		}
+		return time.Time{}, false
+	}
+
+	// Overnight window
+	switch {
+	case now >= start:
+		return endOn(1), true
+	case now < end:
+		return endOn(0), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// minuteOfDay parses HH:MM into minutes after midnight.
+func minuteOfDay(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
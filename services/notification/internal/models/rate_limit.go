@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RateLimitScope says what a rate limit counts.
+type RateLimitScope string
+
+const (
+	ScopeRecipient RateLimitScope = "recipient" // Notifications to one recipient, checked when sending
+	ScopeProvider  RateLimitScope = "provider"  // All deliveries on a channel, checked when delivering
+)
+
+// RecipientRateLimit caps how many notifications one recipient gets on a
+// channel, optionally of one type, within a window.
+type RecipientRateLimit struct {
+	Name    string              `json:"name"`
+	Channel NotificationChannel `json:"channel"`
+	Type    NotificationType    `json:"type,omitempty"` // Empty counts every type
+	Limit   int                 `json:"limit"`
+	Window  time.Duration       `json:"window"`
+}
+
+// Matches reports whether the limit applies to a notification.
+func (l RecipientRateLimit) Matches(channel NotificationChannel, notifType NotificationType) bool {
+	return l.Channel == channel && (l.Type == "" || l.Type == notifType)
+}
+
+// ProviderQuota caps deliveries through a channel's provider within a window.
+// Deliveries over the quota are deferred to the next window.
+type ProviderQuota struct {
+	Channel NotificationChannel `json:"channel"`
+	Limit   int                 `json:"limit"`
+	Window  time.Duration       `json:"window"`
+}
+
+// rateLimitJSON is the JSON form of a limit, with the window as a duration string.
+type rateLimitJSON struct {
+	Name    string              `json:"name,omitempty"`
+	Channel NotificationChannel `json:"channel"`
+	Type    NotificationType    `json:"type,omitempty"`
+	Limit   int                 `json:"limit"`
+	Window  string              `json:"window"`
+}
+
+// MarshalJSON writes the window as a duration string such as "10m0s".
+func (l RecipientRateLimit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateLimitJSON{Name: l.Name, Channel: l.Channel, Type: l.Type, Limit: l.Limit, Window: l.Window.String()})
+}
+
+// MarshalJSON writes the window as a duration string such as "1m0s".
+func (q ProviderQuota) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rateLimitJSON{Channel: q.Channel, Limit: q.Limit, Window: q.Window.String()})
+}
+
+// ParseRecipientRateLimits decodes recipient limits from JSON, e.g. the
+// NOTIFICATION_RECIPIENT_RATE_LIMITS variable:
+//
+//	[{"name": "otp-sms", "channel": "sms", "type": "otp", "limit": 3, "window": "10m"}]
+func ParseRecipientRateLimits(data string) ([]RecipientRateLimit, error) {
+	var raw []rateLimitJSON
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid recipient rate limits: %w", err)
+	}
+
+	limits := make([]RecipientRateLimit, 0, len(raw))
+	seen := make(map[string]bool)
+	for i, r := range raw {
+		window, err := time.ParseDuration(r.Window)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("limits[%d]: window must be a positive duration such as 10m", i)
+		}
+		if r.Name == "" || seen[r.Name] {
+			return nil, fmt.Errorf("limits[%d]: name is required and must be unique", i)
+		}
+		seen[r.Name] = true
+		if r.Channel == "" || r.Limit <= 0 {
+			return nil, fmt.Errorf("limits[%d]: channel and a positive limit are required", i)
+		}
+		limits = append(limits, RecipientRateLimit{Name: r.Name, Channel: r.Channel, Type: r.Type, Limit: r.Limit, Window: window})
+	}
+	return limits, nil
+}
+
+// RateLimitCounter is the current window of one counter.
+type RateLimitCounter struct {
+	Scope     RateLimitScope      `json:"scope"`
+	Rule      string              `json:"rule,omitempty"`      // Recipient limit name
+	Channel   NotificationChannel `json:"channel"`             // Provider channel
+	Recipient string              `json:"recipient,omitempty"` // Recipient counted
+	Count     int                 `json:"count"`
+	Limit     int                 `json:"limit"` // Effective limit, including overrides
+	ResetsAt  time.Time           `json:"resets_at"`
+}
+
+// RateLimitOverride temporarily replaces the limit of a rule or provider quota.
+type RateLimitOverride struct {
+	Scope     RateLimitScope      `json:"scope"`
+	Rule      string              `json:"rule,omitempty"`
+	Channel   NotificationChannel `json:"channel,omitempty"`
+	Limit     int                 `json:"limit"`
+	ExpiresAt time.Time           `json:"expires_at"`
+}
+
+// RateLimitStatus lists the configured limits, active overrides and counters.
+type RateLimitStatus struct {
+	RecipientLimits []RecipientRateLimit `json:"recipient_limits"`
+	ProviderQuotas  []ProviderQuota      `json:"provider_quotas"`
+	Overrides       []RateLimitOverride  `json:"overrides"`
+	Counters        []RateLimitCounter   `json:"counters"`
+}
+
+// OverrideRateLimitRequest adjusts a rate limit. Count sets the current
+// window's count of a counter (0 clears it); Limit replaces the limit of a
+// rule or quota for ExpiresInSeconds (default one hour).
+type OverrideRateLimitRequest struct {
+	Scope            RateLimitScope      `json:"scope"`
+	Rule             string              `json:"rule,omitempty"`      // Recipient scope
+	Recipient        string              `json:"recipient,omitempty"` // Recipient scope, for Count
+	Channel          NotificationChannel `json:"channel,omitempty"`   // Provider scope
+	Count            *int                `json:"count,omitempty"`
+	Limit            *int                `json:"limit,omitempty"`
+	ExpiresInSeconds int                 `json:"expires_in_seconds,omitempty"`
+}
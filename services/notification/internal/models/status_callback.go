@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// MaxStatusCallbackBatch caps how many receipts one callback may carry.
+const MaxStatusCallbackBatch = 500
+
+// ReceiptOutcome describes what a delivery receipt did to its notification.
+type ReceiptOutcome string
+
+const (
+	ReceiptApplied   ReceiptOutcome = "applied"   // Notification status was updated
+	ReceiptDuplicate ReceiptOutcome = "duplicate" // Notification already reflects this receipt
+	ReceiptStale     ReceiptOutcome = "stale"     // A later receipt has already been applied
+	ReceiptNotFound  ReceiptOutcome = "not_found" // No notification matches the message ID
+	ReceiptInvalid   ReceiptOutcome = "invalid"   // Receipt is malformed and was skipped
+)
+
+// StatusReceipt is a provider's delivery receipt for one message.
+// Receipts are validated one by one so a bad receipt does not reject its batch.
+type StatusReceipt struct {
+	MessageID      string             `json:"message_id"`                // Provider message ID
+	NotificationID *string            `json:"notification_id,omitempty"` // Links a message ID not yet recorded
+	Status         NotificationStatus `json:"status"`                    // sent, delivered or failed
+	Timestamp      time.Time          `json:"timestamp"`                 // When the provider observed the status
+	Reason         string             `json:"reason,omitempty"`          // Failure reason from the provider
+}
+
+// StatusCallbackRequest is a batch of provider delivery receipts.
+type StatusCallbackRequest struct {
+	Provider string          `json:"provider,omitempty"`
+	Receipts []StatusReceipt `json:"receipts"`
+}
+
+// ReceiptResult is the outcome for one receipt in a callback batch.
+type ReceiptResult struct {
+	MessageID      string             `json:"message_id"`
+	NotificationID string             `json:"notification_id,omitempty"`
+	Outcome        ReceiptOutcome     `json:"outcome"`
+	Status         NotificationStatus `json:"status,omitempty"` // Notification status after the receipt
+	Detail         string             `json:"detail,omitempty"`
+}
+
+// StatusCallbackResponse summarizes a processed callback batch.
+type StatusCallbackResponse struct {
+	Received int                    `json:"received"`
+	Outcomes map[ReceiptOutcome]int `json:"outcomes"`
+	Results  []*ReceiptResult       `json:"results"`
+}
+
+// statusRank orders statuses so receipts never move a notification backwards.
+// Delivered and failed are both terminal and share the highest rank.
+var statusRank = map[NotificationStatus]int{
+	StatusQueued:    0,
+	StatusSent:      1,
+	StatusDelivered: 2,
+	StatusFailed:    2,
+}
+
+// ReceiptTransition returns the statuses a receipt may replace unconditionally
+// (lower rank) and those it may replace only when it is newer than the last
+// applied receipt (same rank, different status).
+func ReceiptTransition(status NotificationStatus) (lower, peers []string) {
+	rank, ok := statusRank[status]
+	if !ok {
+		return nil, nil
+	}
+	for s, r := range statusRank {
+		switch {
+		case r < rank:
+			lower = append(lower, string(s))
+		case r == rank && s != status:
+			peers = append(peers, string(s))
+		}
+	}
+	return lower, peers
+}
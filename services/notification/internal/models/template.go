@@ -22,7 +22,7 @@ type NotificationTemplate struct {
 // CreateTemplateRequest represents a request to create a notification template.
 type CreateTemplateRequest struct {
 	Name            string              `json:"name" validate:"required,min=3,max=100"`
-	Channel         NotificationChannel `json:"channel" validate:"required,oneof=sms email push in_app"`
+	Channel         NotificationChannel `json:"channel" validate:"required,oneof=sms email push in_app webhook"`
 	SubjectTemplate string              `json:"subject_template,omitempty" validate:"omitempty,max=200"`
 	BodyTemplate    string              `json:"body_template" validate:"required,max=5000"`
 	MetadataRaw     json.RawMessage     `json:"metadata,omitempty"`
@@ -62,12 +62,118 @@ func (r *UpdateTemplateRequest) GetMetadata() (map[string]string, error) {
 // PreviewTemplateRequest represents a request to preview a template with variables.
 type PreviewTemplateRequest struct {
 	Variables map[string]interface{} `json:"variables"`
+	Locale    string                 `json:"locale,omitempty"` // Preview a translation; empty uses the template's own content
 }
 
 // PreviewTemplateResponse represents the rendered template preview.
 type PreviewTemplateResponse struct {
 	Subject      string           `json:"subject,omitempty"`
 	Body         string           `json:"body"`
+	Locale       string           `json:"locale"` // Locale the content was rendered in, after fallback
 	RenderedAt   models.Timestamp `json:"rendered_at"`
 	VariableUsed []string         `json:"variables_used"` // List of variables that were substituted
 }
+
+// TemplateVersionStatus is the lifecycle state of a template version.
+type TemplateVersionStatus string
+
+const (
+	TemplateVersionDraft     TemplateVersionStatus = "draft"     // Editable by replacement only, never sent
+	TemplateVersionPublished TemplateVersionStatus = "published" // Eligible for sending, pins and rollouts
+)
+
+// TemplateVersion is an immutable snapshot of a template's content.
+type TemplateVersion struct {
+	ID              string                `json:"id" db:"id"`
+	TemplateID      string                `json:"template_id" db:"template_id"`
+	Version         int                   `json:"version" db:"version"`
+	SubjectTemplate string                `json:"subject_template,omitempty" db:"subject_template"`
+	BodyTemplate    string                `json:"body_template" db:"body_template"`
+	Status          TemplateVersionStatus `json:"status" db:"status"`
+	CreatedAt       models.Timestamp      `json:"created_at" db:"created_at"`
+	PublishedAt     *models.Timestamp     `json:"published_at,omitempty" db:"published_at"`
+}
+
+// IsPublished returns true if the version can be sent.
+func (v *TemplateVersion) IsPublished() bool {
+	return v.Status == TemplateVersionPublished
+}
+
+// CreateTemplateVersionRequest represents a request to add a draft version.
+type CreateTemplateVersionRequest struct {
+	SubjectTemplate string `json:"subject_template,omitempty" validate:"omitempty,max=200"`
+	BodyTemplate    string `json:"body_template" validate:"required,max=5000"`
+}
+
+// TemplateRollout pins a notification type to a template version. When a
+// candidate is set, CandidatePercent of recipients receive it instead.
+type TemplateRollout struct {
+	TemplateID       string           `json:"template_id" db:"template_id"`
+	NotificationType NotificationType `json:"notification_type" db:"notification_type"`
+	Version          int              `json:"version" db:"version"`
+	CandidateVersion *int             `json:"candidate_version,omitempty" db:"candidate_version"`
+	CandidatePercent int              `json:"candidate_percent" db:"candidate_percent"`
+	CreatedAt        models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt        models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// SetTemplateRolloutRequest represents a request to pin a type to a version,
+// optionally splitting traffic with a candidate version.
+type SetTemplateRolloutRequest struct {
+	Version          int  `json:"version" validate:"required,min=1"`
+	CandidateVersion *int `json:"candidate_version,omitempty" validate:"omitempty,min=1"`
+	CandidatePercent int  `json:"candidate_percent,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+// TemplateVersionStats represents delivery statistics for one template version.
+type TemplateVersionStats struct {
+	Version        int                        `json:"version"`
+	Total          int64                      `json:"total"`
+	ByStatus       map[NotificationStatus]int `json:"by_status"`
+	DeliveryRate   float64                    `json:"delivery_rate"` // Percentage of finished sends that were delivered
+	AverageRetries float64                    `json:"average_retries"`
+}
+
+// TemplateStats represents per-version statistics for a template.
+type TemplateStats struct {
+	TemplateID string                  `json:"template_id"`
+	Name       string                  `json:"name"`
+	Versions   []*TemplateVersionStats `json:"versions"`
+}
+
+// TemplateTranslation is a template's subject and body in one locale. The
+// template's own content is in the default locale.
+type TemplateTranslation struct {
+	TemplateID      string           `json:"template_id" db:"template_id"`
+	Locale          string           `json:"locale" db:"locale"`
+	SubjectTemplate string           `json:"subject_template,omitempty" db:"subject_template"`
+	BodyTemplate    string           `json:"body_template" db:"body_template"`
+	SourceVersion   int              `json:"source_version" db:"source_version"` // Template version it was translated from
+	CreatedAt       models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt       models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// UpsertTranslationRequest represents a request to set a template translation.
+type UpsertTranslationRequest struct {
+	SubjectTemplate string `json:"subject_template,omitempty" validate:"omitempty,max=200"`
+	BodyTemplate    string `json:"body_template" validate:"required,max=5000"`
+	SourceVersion   int    `json:"source_version,omitempty" validate:"omitempty,min=1"` // Defaults to the template's current version
+}
+
+// TranslationGap is a template without an up-to-date translation.
+type TranslationGap struct {
+	TemplateID    string              `json:"template_id"`
+	Name          string              `json:"name"`
+	Channel       NotificationChannel `json:"channel"`
+	Version       int                 `json:"version"`                  // Template's current version
+	SourceVersion *int                `json:"source_version,omitempty"` // Set when outdated
+}
+
+// MissingTranslations lists the templates a locale lacks, or has only an
+// outdated translation for.
+type MissingTranslations struct {
+	Locale   string            `json:"locale"`
+	Missing  []*TranslationGap `json:"missing"`
+	Outdated []*TranslationGap `json:"outdated"`
+	Coverage float64           `json:"coverage"` // Percentage of templates with an up-to-date translation
+}
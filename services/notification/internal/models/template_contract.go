@@ -0,0 +1,73 @@
+package models
+
+// TemplateMetaNotificationType is the template metadata key declaring which
+// notification type a template is written for. Templates with a type are
+// checked against that type's variable contract when saved.
+const TemplateMetaNotificationType = "notification_type"
+
+// TemplateVariable is a variable a notification type supplies to templates.
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"` // Sends fail when a template uses it and it is missing; optional ones render empty
+	Description string `json:"description,omitempty"`
+}
+
+// VariableContract lists the variables templates of one notification type
+// may use.
+type VariableContract struct {
+	NotificationType NotificationType   `json:"notification_type"`
+	Variables        []TemplateVariable `json:"variables"`
+}
+
+// Variable returns the contract's declaration of name, if any.
+func (c *VariableContract) Variable(name string) (TemplateVariable, bool) {
+	for _, v := range c.Variables {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return TemplateVariable{}, false
+}
+
+// TemplateIssueSeverity is how serious a template lint finding is.
+type TemplateIssueSeverity string
+
+const (
+	TemplateIssueError   TemplateIssueSeverity = "error"   // Blocks saving the template
+	TemplateIssueWarning TemplateIssueSeverity = "warning" // Reported, but the template can be saved
+)
+
+// Template lint finding codes.
+const (
+	TemplateIssueMalformedPlaceholder = "malformed_placeholder" // {{...}} that is neither a variable nor a plural
+	TemplateIssueUnbalancedBraces     = "unbalanced_braces"     // A stray {{ or }}
+	TemplateIssueMissingPluralOther   = "missing_plural_other"  // Plural without the other fallback form
+	TemplateIssueUnknownVariable      = "unknown_variable"      // Not in the notification type's contract
+	TemplateIssueOptionalVariable     = "optional_variable"     // Renders empty when the sender leaves it out
+	TemplateIssueUnknownType          = "unknown_notification_type"
+)
+
+// TemplateIssue is one lint finding in a template.
+type TemplateIssue struct {
+	Field    string                `json:"field"` // subject_template or body_template
+	Severity TemplateIssueSeverity `json:"severity"`
+	Code     string                `json:"code"`
+	Variable string                `json:"variable,omitempty"`
+	Message  string                `json:"message"`
+}
+
+// ValidateTemplateRequest represents a request to lint template content
+// without saving it.
+type ValidateTemplateRequest struct {
+	NotificationType NotificationType `json:"notification_type,omitempty"` // Checks variables against its contract when set
+	SubjectTemplate  string           `json:"subject_template,omitempty" validate:"omitempty,max=200"`
+	BodyTemplate     string           `json:"body_template" validate:"required,max=5000"`
+}
+
+// TemplateValidationResult is the outcome of linting template content.
+type TemplateValidationResult struct {
+	Valid            bool             `json:"valid"` // No error-severity issues
+	NotificationType NotificationType `json:"notification_type,omitempty"`
+	Variables        []string         `json:"variables"` // Distinct variables the template uses
+	Issues           []TemplateIssue  `json:"issues"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// WebhookOwnerType identifies who registered a webhook endpoint.
+type WebhookOwnerType string
+
+const (
+	WebhookOwnerUser    WebhookOwnerType = "user"    // Receives the user's webhook notifications
+	WebhookOwnerService WebhookOwnerType = "service" // Receives webhook notifications addressed to the service
+)
+
+// IsValid returns true if the owner type is known.
+func (t WebhookOwnerType) IsValid() bool {
+	return t == WebhookOwnerUser || t == WebhookOwnerService
+}
+
+// Webhook request headers sent with every delivery.
+const (
+	WebhookSignatureHeader      = crypto.WebhookSignatureHeader // t=<unix seconds>,v1=<hex HMAC-SHA256>
+	WebhookNotificationIDHeader = "X-Nivo-Notification-Id"      // Stable across retries; use it to deduplicate
+	WebhookAttemptHeader        = "X-Nivo-Delivery-Attempt"
+)
+
+// MaxWebhookResponseBody is how much of an endpoint's response is recorded.
+const MaxWebhookResponseBody = 1024
+
+// WebhookEndpoint is a callback URL registered by a user or service.
+type WebhookEndpoint struct {
+	ID          string           `json:"id" db:"id"`
+	OwnerType   WebhookOwnerType `json:"owner_type" db:"owner_type"`
+	OwnerID     string           `json:"owner_id" db:"owner_id"` // User ID or service name
+	URL         string           `json:"url" db:"url"`
+	Secret      string           `json:"-" db:"secret"` // Signing key, only returned on create and rotate
+	Description *string          `json:"description,omitempty" db:"description"`
+	Enabled     bool             `json:"enabled" db:"enabled"`
+	CreatedAt   models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt   models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookEndpointWithSecret is returned when a signing secret is issued. The
+// secret cannot be read back afterwards.
+type WebhookEndpointWithSecret struct {
+	*WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookEndpointRequest represents a request to register a webhook endpoint.
+type CreateWebhookEndpointRequest struct {
+	OwnerType   WebhookOwnerType `json:"owner_type" validate:"required"`
+	OwnerID     string           `json:"owner_id" validate:"required,max=255"`
+	URL         string           `json:"url" validate:"required,max=2048"`
+	Description *string          `json:"description,omitempty" validate:"omitempty,max=255"`
+}
+
+// UpdateWebhookEndpointRequest represents a request to update a webhook endpoint.
+type UpdateWebhookEndpointRequest struct {
+	URL         *string `json:"url,omitempty" validate:"omitempty,max=2048"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=255"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// WebhookDelivery records one HTTP attempt to deliver a webhook notification.
+type WebhookDelivery struct {
+	ID             string           `json:"id" db:"id"`
+	NotificationID string           `json:"notification_id" db:"notification_id"`
+	EndpointID     *string          `json:"endpoint_id,omitempty" db:"endpoint_id"` // Null once the endpoint is deleted
+	URL            string           `json:"url" db:"url"`
+	Attempt        int              `json:"attempt" db:"attempt"`
+	StatusCode     *int             `json:"status_code,omitempty" db:"status_code"` // Null when no response was received
+	Succeeded      bool             `json:"succeeded" db:"succeeded"`
+	Error          *string          `json:"error,omitempty" db:"error"`
+	ResponseBody   *string          `json:"response_body,omitempty" db:"response_body"`
+	DurationMs     int              `json:"duration_ms" db:"duration_ms"`
+	CreatedAt      models.Timestamp `json:"created_at" db:"created_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook endpoint.
+type WebhookPayload struct {
+	ID            string                 `json:"id"` // Notification ID
+	Type          NotificationType       `json:"type"`
+	Priority      NotificationPriority   `json:"priority"`
+	UserID        *string                `json:"user_id,omitempty"`
+	Subject       string                 `json:"subject,omitempty"`
+	Body          string                 `json:"body"`
+	CorrelationID *string                `json:"correlation_id,omitempty"`
+	SourceService string                 `json:"source_service"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt     models.Timestamp       `json:"created_at"`
+}
+
+// NewWebhookPayload builds the payload delivered for a notification.
+func NewWebhookPayload(notif *Notification) *WebhookPayload {
+	return &WebhookPayload{
+		ID:            notif.ID,
+		Type:          notif.Type,
+		Priority:      notif.Priority,
+		UserID:        notif.UserID,
+		Subject:       notif.Subject,
+		Body:          notif.Body,
+		CorrelationID: notif.CorrelationID,
+		SourceService: notif.SourceService,
+		Metadata:      notif.Metadata,
+		CreatedAt:     notif.CreatedAt,
+	}
+}
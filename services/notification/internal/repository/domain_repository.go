@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// DomainRepository handles database operations for sending domains.
+type DomainRepository struct {
+	db *sql.DB
+}
+
+// NewDomainRepository creates a new domain repository.
+func NewDomainRepository(db *sql.DB) *DomainRepository {
+	return &DomainRepository{db: db}
+}
+
+const domainColumns = `
+	id, domain, tenant, default_from_address, from_addresses, bounce_address,
+	dkim_selector, dkim_public_key, status, enabled, last_verified_at, created_at, updated_at
+`
+
+// Create creates a new sending domain.
+func (r *DomainRepository) Create(ctx context.Context, domain *models.SendingDomain) *errors.Error {
+	fromJSON, err := marshalFromAddresses(domain.FromAddresses)
+	if err != nil {
+		return errors.Internal("failed to marshal from addresses")
+	}
+
+	query := `
+		INSERT INTO sending_domains (
+			domain, tenant, default_from_address, from_addresses, bounce_address, status, enabled
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		domain.Domain,
+		domain.Tenant,
+		domain.DefaultFromAddress,
+		fromJSON,
+		domain.BounceAddress,
+		domain.Status,
+		domain.Enabled,
+	).Scan(&domain.ID, &domain.CreatedAt, &domain.UpdatedAt)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "sending_domains_domain_key") {
+			return errors.Conflict("sending domain already exists")
+		}
+		return errors.DatabaseWrap(err, "failed to create sending domain")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a sending domain by ID.
+func (r *DomainRepository) GetByID(ctx context.Context, id string) (*models.SendingDomain, *errors.Error) {
+	query := `SELECT ` + domainColumns + ` FROM sending_domains WHERE id = $1`
+
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("sending domain", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get sending domain")
+	}
+
+	return domain, nil
+}
+
+// GetForTenant retrieves the enabled sending domain for a tenant.
+// Verified domains are preferred over unverified ones.
+func (r *DomainRepository) GetForTenant(ctx context.Context, tenant string) (*models.SendingDomain, *errors.Error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM sending_domains
+		WHERE tenant = $1 AND enabled = true
+		ORDER BY (status = 'verified') DESC, created_at ASC
+		LIMIT 1
+	`
+
+	domain, err := scanDomain(r.db.QueryRowContext(ctx, query, tenant))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("sending domain for tenant " + tenant)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get sending domain")
+	}
+
+	return domain, nil
+}
+
+// List retrieves all sending domains, optionally filtered by tenant.
+func (r *DomainRepository) List(ctx context.Context, tenant *string) ([]*models.SendingDomain, *errors.Error) {
+	query := `SELECT ` + domainColumns + ` FROM sending_domains`
+	var args []interface{}
+
+	if tenant != nil {
+		query += " WHERE tenant = $1"
+		args = append(args, *tenant)
+	}
+	query += " ORDER BY tenant, domain"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sending domains")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	domains := make([]*models.SendingDomain, 0)
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan sending domain")
+		}
+		domains = append(domains, domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate sending domains")
+	}
+
+	return domains, nil
+}
+
+// Update persists sender configuration changes.
+func (r *DomainRepository) Update(ctx context.Context, domain *models.SendingDomain) *errors.Error {
+	fromJSON, err := marshalFromAddresses(domain.FromAddresses)
+	if err != nil {
+		return errors.Internal("failed to marshal from addresses")
+	}
+
+	query := `
+		UPDATE sending_domains
+		SET default_from_address = $2, from_addresses = $3, bounce_address = $4,
+		    status = $5, enabled = $6
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		domain.ID,
+		domain.DefaultFromAddress,
+		fromJSON,
+		domain.BounceAddress,
+		domain.Status,
+		domain.Enabled,
+	)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update sending domain")
+	}
+
+	return checkDomainAffected(result, domain.ID)
+}
+
+// UpdateDKIM stores a DKIM selector and public key and resets verification.
+func (r *DomainRepository) UpdateDKIM(ctx context.Context, id, selector, publicKey string) *errors.Error {
+	query := `
+		UPDATE sending_domains
+		SET dkim_selector = $2, dkim_public_key = $3, status = 'pending'
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, selector, publicKey)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update DKIM key")
+	}
+
+	return checkDomainAffected(result, id)
+}
+
+// UpdateVerification records the outcome of a verification run.
+func (r *DomainRepository) UpdateVerification(ctx context.Context, id string, status models.DomainStatus) *errors.Error {
+	query := `
+		UPDATE sending_domains
+		SET status = $2, last_verified_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, status)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update domain verification")
+	}
+
+	return checkDomainAffected(result, id)
+}
+
+// GetDeliveryStats aggregates email delivery outcomes for a sending domain.
+func (r *DomainRepository) GetDeliveryStats(ctx context.Context, domain string) (*models.DomainDeliveryStats, *errors.Error) {
+	stats := &models.DomainDeliveryStats{
+		Domain:   domain,
+		ByStatus: make(map[models.NotificationStatus]int),
+		ByType:   make(map[models.NotificationType]int),
+	}
+
+	query := `
+		SELECT status, type, COUNT(*)
+		FROM notifications
+		WHERE channel = 'email' AND metadata->>'sending_domain' = $1
+		GROUP BY status, type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get domain delivery stats")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var status models.NotificationStatus
+		var notifType models.NotificationType
+		var count int
+		if err := rows.Scan(&status, &notifType, &count); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan domain delivery stats")
+		}
+		stats.ByStatus[status] += count
+		stats.ByType[notifType] += count
+		stats.Total += int64(count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate domain delivery stats")
+	}
+
+	delivered := stats.ByStatus[models.StatusDelivered]
+	failed := stats.ByStatus[models.StatusFailed]
+	if (delivered + failed) > 0 {
+		stats.DeliveryRate = float64(delivered) / float64(delivered+failed) * 100
+	}
+
+	return stats, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanDomain scans a sending domain row.
+func scanDomain(row rowScanner) (*models.SendingDomain, error) {
+	domain := &models.SendingDomain{}
+	var fromJSON []byte
+
+	err := row.Scan(
+		&domain.ID,
+		&domain.Domain,
+		&domain.Tenant,
+		&domain.DefaultFromAddress,
+		&fromJSON,
+		&domain.BounceAddress,
+		&domain.DKIMSelector,
+		&domain.DKIMPublicKey,
+		&domain.Status,
+		&domain.Enabled,
+		&domain.LastVerifiedAt,
+		&domain.CreatedAt,
+		&domain.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fromJSON) > 0 {
+		if err := json.Unmarshal(fromJSON, &domain.FromAddresses); err != nil {
+			return nil, err
+		}
+	}
+
+	return domain, nil
+}
+
+// marshalFromAddresses encodes per-type from-addresses, returning nil for an empty map.
+func marshalFromAddresses(addrs map[models.NotificationType]string) ([]byte, error) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(addrs)
+}
+
+// checkDomainAffected returns NotFound when an update matched no rows.
+func checkDomainAffected(result sql.Result, id string) *errors.Error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	if rows == 0 {
+		return errors.NotFoundWithID("sending domain", id)
+	}
+	return nil
+}
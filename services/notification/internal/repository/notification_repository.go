@@ -6,11 +6,63 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/lib/pq"
 )
 
+const notificationColumns = `id, user_id, channel, type, priority, recipient, subject, body,
+		       template_id, template_version, status, correlation_id, source_service, metadata,
+		       retry_count, failure_reason, queued_at, sent_at, delivered_at,
+		       failed_at, scheduled_for, timezone, read_at, archived_at, created_at, updated_at`
+
+// scanNotification scans a row selected with notificationColumns.
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	notif := &models.Notification{}
+	var metadataJSON []byte
+
+	if err := row.Scan(
+		&notif.ID,
+		&notif.UserID,
+		&notif.Channel,
+		&notif.Type,
+		&notif.Priority,
+		&notif.Recipient,
+		&notif.Subject,
+		&notif.Body,
+		&notif.TemplateID,
+		&notif.TemplateVersion,
+		&notif.Status,
+		&notif.CorrelationID,
+		&notif.SourceService,
+		&metadataJSON,
+		&notif.RetryCount,
+		&notif.FailureReason,
+		&notif.QueuedAt,
+		&notif.SentAt,
+		&notif.DeliveredAt,
+		&notif.FailedAt,
+		&notif.ScheduledFor,
+		&notif.Timezone,
+		&notif.ReadAt,
+		&notif.ArchivedAt,
+		&notif.CreatedAt,
+		&notif.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &notif.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+
+	return notif, nil
+}
+
 // NotificationRepository handles database operations for notifications.
 type NotificationRepository struct {
 	db *sql.DB
@@ -36,14 +88,22 @@ func (r *NotificationRepository) Create(ctx context.Context, notif *models.Notif
 	query := `
 		INSERT INTO notifications (
 			user_id, channel, type, priority, recipient, subject, body,
-			template_id, status, correlation_id, source_service, metadata,
-			retry_count, queued_at
+			template_id, template_version, status, correlation_id, source_service, metadata,
+			retry_count, queued_at, scheduled_for, timezone
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id, created_at, updated_at
 	`
 
-	err = r.db.QueryRowContext(ctx, query,
+	// Attachments are written with the notification so the delivery worker
+	// never picks it up without them
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRowContext(ctx, query,
 		notif.UserID,
 		notif.Channel,
 		notif.Type,
@@ -52,12 +112,15 @@ func (r *NotificationRepository) Create(ctx context.Context, notif *models.Notif
 		notif.Subject,
 		notif.Body,
 		notif.TemplateID,
+		notif.TemplateVersion,
 		notif.Status,
 		notif.CorrelationID,
 		notif.SourceService,
 		metadataJSON,
 		notif.RetryCount,
 		notif.QueuedAt,
+		notif.ScheduledFor,
+		notif.Timezone,
 	).Scan(&notif.ID, &notif.CreatedAt, &notif.UpdatedAt)
 
 	if err != nil {
@@ -68,47 +131,87 @@ func (r *NotificationRepository) Create(ctx context.Context, notif *models.Notif
 		return errors.DatabaseWrap(err, "failed to create notification")
 	}
 
+	attachmentQuery := `
+		INSERT INTO notification_attachments (notification_id, position, filename, content_type, size_bytes, storage_key, content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	for i, attachment := range notif.Attachments {
+		attachment.NotificationID = notif.ID
+		err = tx.QueryRowContext(ctx, attachmentQuery,
+			attachment.NotificationID,
+			i,
+			attachment.Filename,
+			attachment.ContentType,
+			attachment.SizeBytes,
+			attachment.StorageKey,
+			attachment.Content,
+		).Scan(&attachment.ID, &attachment.CreatedAt)
+		if err != nil {
+			return errors.DatabaseWrap(err, "failed to store notification attachment")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit notification")
+	}
+
 	return nil
 }
 
+// ListAttachments retrieves a notification's attachments in the order they
+// were given. Inline content is loaded only when withContent is set.
+func (r *NotificationRepository) ListAttachments(ctx context.Context, notificationID string, withContent bool) ([]*models.NotificationAttachment, *errors.Error) {
+	content := "NULL::bytea"
+	if withContent {
+		content = "content"
+	}
+	//nolint:gosec // content is one of two fixed column expressions
+	query := `
+		SELECT id, notification_id, filename, content_type, size_bytes, storage_key, ` + content + `, created_at
+		FROM notification_attachments
+		WHERE notification_id = $1
+		ORDER BY position
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, notificationID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list notification attachments")
+	}
+	defer func() { _ = rows.Close() }()
+
+	attachments := make([]*models.NotificationAttachment, 0)
+	for rows.Next() {
+		attachment := &models.NotificationAttachment{}
+		if err := rows.Scan(
+			&attachment.ID,
+			&attachment.NotificationID,
+			&attachment.Filename,
+			&attachment.ContentType,
+			&attachment.SizeBytes,
+			&attachment.StorageKey,
+			&attachment.Content,
+			&attachment.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan notification attachment")
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list notification attachments")
+	}
+	return attachments, nil
+}
+
 // GetByID retrieves a notification by ID.
 func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*models.Notification, *errors.Error) {
-	notif := &models.Notification{}
-	var metadataJSON []byte
-
 	query := `
-		SELECT id, user_id, channel, type, priority, recipient, subject, body,
-		       template_id, status, correlation_id, source_service, metadata,
-		       retry_count, failure_reason, queued_at, sent_at, delivered_at,
-		       failed_at, created_at, updated_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
 		WHERE id = $1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&notif.ID,
-		&notif.UserID,
-		&notif.Channel,
-		&notif.Type,
-		&notif.Priority,
-		&notif.Recipient,
-		&notif.Subject,
-		&notif.Body,
-		&notif.TemplateID,
-		&notif.Status,
-		&notif.CorrelationID,
-		&notif.SourceService,
-		&metadataJSON,
-		&notif.RetryCount,
-		&notif.FailureReason,
-		&notif.QueuedAt,
-		&notif.SentAt,
-		&notif.DeliveredAt,
-		&notif.FailedAt,
-		&notif.CreatedAt,
-		&notif.UpdatedAt,
-	)
-
+	notif, err := scanNotification(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFoundWithID("notification", id)
@@ -116,55 +219,19 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id string) (*model
 		return nil, errors.DatabaseWrap(err, "failed to get notification")
 	}
 
-	// Unmarshal metadata
-	if len(metadataJSON) > 0 {
-		if err := json.Unmarshal(metadataJSON, &notif.Metadata); err != nil {
-			return nil, errors.Internal("failed to unmarshal metadata")
-		}
-	}
-
 	return notif, nil
 }
 
 // GetByCorrelationID retrieves a notification by correlation ID (for idempotency check).
 func (r *NotificationRepository) GetByCorrelationID(ctx context.Context, correlationID string) (*models.Notification, *errors.Error) {
-	notif := &models.Notification{}
-	var metadataJSON []byte
-
 	query := `
-		SELECT id, user_id, channel, type, priority, recipient, subject, body,
-		       template_id, status, correlation_id, source_service, metadata,
-		       retry_count, failure_reason, queued_at, sent_at, delivered_at,
-		       failed_at, created_at, updated_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
 		WHERE correlation_id = $1
 		LIMIT 1
 	`
 
-	err := r.db.QueryRowContext(ctx, query, correlationID).Scan(
-		&notif.ID,
-		&notif.UserID,
-		&notif.Channel,
-		&notif.Type,
-		&notif.Priority,
-		&notif.Recipient,
-		&notif.Subject,
-		&notif.Body,
-		&notif.TemplateID,
-		&notif.Status,
-		&notif.CorrelationID,
-		&notif.SourceService,
-		&metadataJSON,
-		&notif.RetryCount,
-		&notif.FailureReason,
-		&notif.QueuedAt,
-		&notif.SentAt,
-		&notif.DeliveredAt,
-		&notif.FailedAt,
-		&notif.CreatedAt,
-		&notif.UpdatedAt,
-	)
-
+	notif, err := scanNotification(r.db.QueryRowContext(ctx, query, correlationID))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.NotFound("notification")
@@ -172,13 +239,6 @@ func (r *NotificationRepository) GetByCorrelationID(ctx context.Context, correla
 		return nil, errors.DatabaseWrap(err, "failed to get notification by correlation_id")
 	}
 
-	// Unmarshal metadata
-	if len(metadataJSON) > 0 {
-		if err := json.Unmarshal(metadataJSON, &notif.Metadata); err != nil {
-			return nil, errors.Internal("failed to unmarshal metadata")
-		}
-	}
-
 	return notif, nil
 }
 
@@ -252,10 +312,7 @@ func (r *NotificationRepository) List(ctx context.Context, req *models.ListNotif
 
 	//nolint:gosec // whereClause is built from controlled filter values, not user input
 	query := fmt.Sprintf(`
-		SELECT id, user_id, channel, type, priority, recipient, subject, body,
-		       template_id, status, correlation_id, source_service, metadata,
-		       retry_count, failure_reason, queued_at, sent_at, delivered_at,
-		       failed_at, created_at, updated_at
+		SELECT `+notificationColumns+`
 		FROM notifications
 		%s
 		ORDER BY created_at DESC
@@ -274,42 +331,10 @@ func (r *NotificationRepository) List(ctx context.Context, req *models.ListNotif
 
 	notifications := make([]*models.Notification, 0)
 	for rows.Next() {
-		notif := &models.Notification{}
-		var metadataJSON []byte
-
-		if err := rows.Scan(
-			&notif.ID,
-			&notif.UserID,
-			&notif.Channel,
-			&notif.Type,
-			&notif.Priority,
-			&notif.Recipient,
-			&notif.Subject,
-			&notif.Body,
-			&notif.TemplateID,
-			&notif.Status,
-			&notif.CorrelationID,
-			&notif.SourceService,
-			&metadataJSON,
-			&notif.RetryCount,
-			&notif.FailureReason,
-			&notif.QueuedAt,
-			&notif.SentAt,
-			&notif.DeliveredAt,
-			&notif.FailedAt,
-			&notif.CreatedAt,
-			&notif.UpdatedAt,
-		); err != nil {
+		notif, err := scanNotification(rows)
+		if err != nil {
 			return nil, 0, errors.DatabaseWrap(err, "failed to scan notification")
 		}
-
-		// Unmarshal metadata
-		if len(metadataJSON) > 0 {
-			if err := json.Unmarshal(metadataJSON, &notif.Metadata); err != nil {
-				return nil, 0, errors.Internal("failed to unmarshal metadata")
-			}
-		}
-
 		notifications = append(notifications, notif)
 	}
 
@@ -383,10 +408,7 @@ func (r *NotificationRepository) GetQueuedNotifications(ctx context.Context, lim
 	}
 
 	query := `
-		SELECT id, user_id, channel, type, priority, recipient, subject, body,
-		       template_id, status, correlation_id, source_service, metadata,
-		       retry_count, failure_reason, queued_at, sent_at, delivered_at,
-		       failed_at, created_at, updated_at
+		SELECT ` + notificationColumns + `
 		FROM notifications
 		WHERE status = 'queued'
 		ORDER BY
@@ -410,50 +432,240 @@ func (r *NotificationRepository) GetQueuedNotifications(ctx context.Context, lim
 
 	notifications := make([]*models.Notification, 0)
 	for rows.Next() {
-		notif := &models.Notification{}
-		var metadataJSON []byte
-
-		if err := rows.Scan(
-			&notif.ID,
-			&notif.UserID,
-			&notif.Channel,
-			&notif.Type,
-			&notif.Priority,
-			&notif.Recipient,
-			&notif.Subject,
-			&notif.Body,
-			&notif.TemplateID,
-			&notif.Status,
-			&notif.CorrelationID,
-			&notif.SourceService,
-			&metadataJSON,
-			&notif.RetryCount,
-			&notif.FailureReason,
-			&notif.QueuedAt,
-			&notif.SentAt,
-			&notif.DeliveredAt,
-			&notif.FailedAt,
-			&notif.CreatedAt,
-			&notif.UpdatedAt,
-		); err != nil {
+		notif, err := scanNotification(rows)
+		if err != nil {
 			return nil, errors.DatabaseWrap(err, "failed to scan notification")
 		}
+		notifications = append(notifications, notif)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating notifications")
+	}
+
+	return notifications, nil
+}
+
+// ReleaseDueScheduled queues scheduled notifications whose send time has
+// passed, oldest first. Concurrent schedulers skip each other's rows.
+func (r *NotificationRepository) ReleaseDueScheduled(ctx context.Context, limit int) (int, *errors.Error) {
+	query := `
+		UPDATE notifications
+		SET status = 'queued', queued_at = NOW()
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'scheduled' AND scheduled_for <= NOW()
+			ORDER BY scheduled_for
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, limit)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to release scheduled notifications")
+	}
+
+	released, _ := result.RowsAffected()
+	return int(released), nil
+}
+
+// DeferQueued holds a queued notification until at, when the scheduler queues
+// it again. It is used when a provider quota is used up.
+func (r *NotificationRepository) DeferQueued(ctx context.Context, id string, at time.Time) *errors.Error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET status = 'scheduled', scheduled_for = $2 WHERE id = $1 AND status = 'queued'`, id, at)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to defer notification")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.Conflict("only queued notifications can be deferred")
+	}
+
+	return nil
+}
+
+// CancelScheduled cancels a notification that is still waiting for its send time.
+func (r *NotificationRepository) CancelScheduled(ctx context.Context, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET status = 'cancelled' WHERE id = $1 AND status = 'scheduled'`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel notification")
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		notif, getErr := r.GetByID(ctx, id)
+		if getErr != nil {
+			return getErr
+		}
+		return errors.Conflict(fmt.Sprintf("only scheduled notifications can be cancelled (status: %s)", notif.Status))
+	}
 
-		// Unmarshal metadata
-		if len(metadataJSON) > 0 {
-			if err := json.Unmarshal(metadataJSON, &notif.Metadata); err != nil {
-				return nil, errors.Internal("failed to unmarshal metadata")
-			}
+	return nil
+}
+
+// ListScheduledByUser returns a user's upcoming deliveries, soonest first.
+func (r *NotificationRepository) ListScheduledByUser(ctx context.Context, userID string, limit, offset int) ([]*models.Notification, int64, *errors.Error) {
+	var total int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND status = 'scheduled'`, userID,
+	).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count scheduled notifications")
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1 AND status = 'scheduled'
+		ORDER BY scheduled_for ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list scheduled notifications")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		notif, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan notification")
 		}
+		notifications = append(notifications, notif)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "error iterating notifications")
+	}
+
+	return notifications, total, nil
+}
+
+// inboxScope restricts a query to the in-app notifications delivered to user $1.
+const inboxScope = `user_id = $1 AND channel = 'in_app' AND status = 'delivered'`
+
+// inboxViewFilter returns the extra condition a view applies to inboxScope.
+func inboxViewFilter(view models.InboxView) string {
+	switch view {
+	case models.InboxViewUnread:
+		return " AND read_at IS NULL AND archived_at IS NULL"
+	case models.InboxViewArchived:
+		return " AND archived_at IS NOT NULL"
+	case models.InboxViewAll:
+		return ""
+	default:
+		return " AND archived_at IS NULL"
+	}
+}
+
+// ListInbox returns a user's delivered in-app notifications, newest first.
+func (r *NotificationRepository) ListInbox(ctx context.Context, userID string, view models.InboxView, limit, offset int) ([]*models.Notification, int64, *errors.Error) {
+	where := inboxScope + inboxViewFilter(view)
 
+	var total int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE `+where, userID,
+	).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count inbox notifications")
+	}
+
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list inbox notifications")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		notif, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan notification")
+		}
 		notifications = append(notifications, notif)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, errors.DatabaseWrap(err, "error iterating notifications")
+		return nil, 0, errors.DatabaseWrap(err, "error iterating notifications")
 	}
 
-	return notifications, nil
+	return notifications, total, nil
+}
+
+// CountUnread counts a user's unread, unarchived in-app notifications.
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID string) (int64, *errors.Error) {
+	var unread int64
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE `+inboxScope+inboxViewFilter(models.InboxViewUnread), userID,
+	).Scan(&unread); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to count unread notifications")
+	}
+	return unread, nil
+}
+
+// SetRead marks one of a user's in-app notifications read or unread. Marking
+// a read notification read again keeps its original read time.
+func (r *NotificationRepository) SetRead(ctx context.Context, userID, id string, read bool) (*models.Notification, *errors.Error) {
+	query := `
+		UPDATE notifications
+		SET read_at = CASE WHEN $3 THEN COALESCE(read_at, NOW()) ELSE NULL END,
+		    updated_at = NOW()
+		WHERE ` + inboxScope + ` AND id = $2
+		RETURNING ` + notificationColumns
+
+	return r.updateInboxItem(ctx, query, userID, id, read)
+}
+
+// SetArchived archives or restores one of a user's in-app notifications.
+func (r *NotificationRepository) SetArchived(ctx context.Context, userID, id string, archived bool) (*models.Notification, *errors.Error) {
+	query := `
+		UPDATE notifications
+		SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, NOW()) ELSE NULL END,
+		    updated_at = NOW()
+		WHERE ` + inboxScope + ` AND id = $2
+		RETURNING ` + notificationColumns
+
+	return r.updateInboxItem(ctx, query, userID, id, archived)
+}
+
+// updateInboxItem runs an inbox update and returns the updated notification.
+// Notifications of other users or channels are reported as not found.
+func (r *NotificationRepository) updateInboxItem(ctx context.Context, query, userID, id string, value bool) (*models.Notification, *errors.Error) {
+	notif, err := scanNotification(r.db.QueryRowContext(ctx, query, userID, id, value))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("notification", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to update inbox notification")
+	}
+	return notif, nil
+}
+
+// MarkAllRead marks every unread, unarchived in-app notification of a user read.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, *errors.Error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = NOW(), updated_at = NOW() WHERE `+inboxScope+inboxViewFilter(models.InboxViewUnread),
+		userID)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to mark notifications read")
+	}
+
+	updated, _ := result.RowsAffected()
+	return updated, nil
 }
 
 // GetStats retrieves notification statistics.
@@ -546,5 +758,180 @@ func (r *NotificationRepository) GetStats(ctx context.Context) (*models.Notifica
 		stats.AverageRetries = avgRetries.Float64
 	}
 
+	engagement, engagementErr := r.getEngagementStats(ctx)
+	if engagementErr != nil {
+		return nil, engagementErr
+	}
+	stats.Engagement = engagement
+
+	return stats, nil
+}
+
+// getEngagementStats counts deliveries, receipts, opens and clicks per
+// template and type.
+func (r *NotificationRepository) getEngagementStats(ctx context.Context) (*models.EngagementStats, *errors.Error) {
+	query := `
+		SELECT n.template_id, n.type,
+		       COUNT(*) FILTER (WHERE n.sent_at IS NOT NULL),
+		       COUNT(*) FILTER (WHERE n.status = 'delivered'),
+		       COUNT(*) FILTER (WHERE n.status = 'failed'),
+		       COUNT(*) FILTER (WHERE n.provider_status_at IS NOT NULL),
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM notification_tracking_links l
+		           WHERE l.notification_id = n.id AND l.hits > 0)),
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM notification_tracking_links l
+		           WHERE l.notification_id = n.id AND l.hits > 0 AND l.kind = 'click'))
+		FROM notifications n
+		WHERE n.status NOT IN ('scheduled', 'cancelled')
+		GROUP BY n.template_id, n.type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get engagement stats")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	stats := models.NewEngagementStats()
+	for rows.Next() {
+		var templateID *string
+		var notifType models.NotificationType
+		var counts models.EngagementCounts
+		if err := rows.Scan(&templateID, &notifType, &counts.Sent, &counts.Delivered, &counts.Failed,
+			&counts.Receipts, &counts.Opened, &counts.Clicked); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan engagement stats")
+		}
+		stats.Add(templateID, notifType, counts)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate engagement stats")
+	}
+
+	stats.Finish()
 	return stats, nil
 }
+
+// CreateTrackingLinks stores the tracking links embedded in an email.
+func (r *NotificationRepository) CreateTrackingLinks(ctx context.Context, links []*models.TrackingLink) *errors.Error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		INSERT INTO notification_tracking_links (id, notification_id, kind, url)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	for _, link := range links {
+		if err := tx.QueryRowContext(ctx, query, link.ID, link.NotificationID, link.Kind, link.URL).Scan(&link.CreatedAt); err != nil {
+			return errors.DatabaseWrap(err, "failed to create tracking link")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit tracking links")
+	}
+	return nil
+}
+
+// RecordTrackingHit counts a hit on a tracking link of the given kind and
+// returns the link.
+func (r *NotificationRepository) RecordTrackingHit(ctx context.Context, id string, kind models.TrackingKind) (*models.TrackingLink, *errors.Error) {
+	query := `
+		UPDATE notification_tracking_links
+		SET hits = hits + 1,
+		    first_hit_at = COALESCE(first_hit_at, NOW()),
+		    last_hit_at = NOW()
+		WHERE id::text = $1 AND kind = $2
+		RETURNING id, notification_id, kind, url, hits, first_hit_at, last_hit_at, created_at
+	`
+
+	link := &models.TrackingLink{}
+	err := r.db.QueryRowContext(ctx, query, id, kind).Scan(
+		&link.ID, &link.NotificationID, &link.Kind, &link.URL,
+		&link.Hits, &link.FirstHitAt, &link.LastHitAt, &link.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("tracking link", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to record tracking hit")
+	}
+	return link, nil
+}
+
+// ApplyStatusReceipt applies a provider delivery receipt in a single statement so
+// concurrent and repeated callbacks cannot race. A receipt only moves a
+// notification forward (queued < sent < delivered/failed); switching between
+// the terminal statuses requires a receipt newer than the last one applied.
+// It returns the outcome and the notification's ID and status afterwards.
+func (r *NotificationRepository) ApplyStatusReceipt(ctx context.Context, receipt *models.StatusReceipt) (models.ReceiptOutcome, string, models.NotificationStatus, *errors.Error) {
+	lower, peers := models.ReceiptTransition(receipt.Status)
+
+	var failureReason *string
+	if receipt.Status == models.StatusFailed && receipt.Reason != "" {
+		failureReason = &receipt.Reason
+	}
+
+	// Match on the provider message ID, or link it to the given notification
+	// when no notification carries that message ID yet
+	match := `(provider_message_id = $1 OR (
+		provider_message_id IS NULL AND id::text = $2
+		AND NOT EXISTS (SELECT 1 FROM notifications WHERE provider_message_id = $1)))`
+
+	var id string
+	var status models.NotificationStatus
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE notifications
+		SET status = $3::text,
+		    provider_message_id = $1,
+		    provider_status_at = $4,
+		    failure_reason = CASE WHEN $3::text = 'failed' THEN COALESCE($5, failure_reason) ELSE NULL END,
+		    sent_at = CASE WHEN sent_at IS NULL THEN $4 ELSE sent_at END,
+		    delivered_at = CASE WHEN $3::text = 'delivered' THEN $4 ELSE delivered_at END,
+		    failed_at = CASE WHEN $3::text = 'failed' THEN $4 ELSE failed_at END,
+		    updated_at = NOW()
+		WHERE `+match+`
+		  AND (status = ANY($6) OR (status = ANY($7) AND (provider_status_at IS NULL OR provider_status_at < $4)))
+		RETURNING id, status
+	`, receipt.MessageID, notificationIDOrEmpty(receipt.NotificationID), string(receipt.Status),
+		receipt.Timestamp, failureReason, pq.Array(lower), pq.Array(peers),
+	).Scan(&id, &status)
+	if err == nil {
+		return models.ReceiptApplied, id, status, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", "", "", errors.DatabaseWrap(err, "failed to apply status receipt")
+	}
+
+	// Nothing changed: classify against the current state
+	err = r.db.QueryRowContext(ctx, `
+		SELECT id, status FROM notifications WHERE `+match+`
+	`, receipt.MessageID, notificationIDOrEmpty(receipt.NotificationID)).Scan(&id, &status)
+	if err == sql.ErrNoRows {
+		return models.ReceiptNotFound, "", "", nil
+	}
+	if err != nil {
+		return "", "", "", errors.DatabaseWrap(err, "failed to look up notification for receipt")
+	}
+	if status == receipt.Status {
+		return models.ReceiptDuplicate, id, status, nil
+	}
+	return models.ReceiptStale, id, status, nil
+}
+
+func notificationIDOrEmpty(id *string) string {
+	if id == nil {
+		return ""
+	}
+	return *id
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// PreferenceRepository handles database operations for replicated user preferences.
+type PreferenceRepository struct {
+	db *sql.DB
+}
+
+// NewPreferenceRepository creates a new preference repository.
+func NewPreferenceRepository(db *sql.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// Get retrieves a user's preferences.
+func (r *PreferenceRepository) Get(ctx context.Context, userID string) (*models.UserPreferences, *errors.Error) {
+	query := `
+		SELECT user_id, locale, timezone, display_currency, marketing_consent,
+		       quiet_hours_start, quiet_hours_end, updated_at
+		FROM user_preferences
+		WHERE user_id = $1
+	`
+
+	prefs := &models.UserPreferences{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&prefs.UserID,
+		&prefs.Locale,
+		&prefs.Timezone,
+		&prefs.DisplayCurrency,
+		&prefs.MarketingConsent,
+		&prefs.QuietHoursStart,
+		&prefs.QuietHoursEnd,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("user preferences", userID)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get user preferences")
+	}
+
+	return prefs, nil
+}
+
+// Upsert replaces a user's preferences unless the stored ones are newer, so
+// events arriving out of order never roll preferences back. It reports
+// whether the preferences were applied.
+func (r *PreferenceRepository) Upsert(ctx context.Context, prefs *models.UserPreferences) (bool, *errors.Error) {
+	query := `
+		INSERT INTO user_preferences (
+			user_id, locale, timezone, display_currency, marketing_consent,
+			quiet_hours_start, quiet_hours_end, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			locale = EXCLUDED.locale,
+			timezone = EXCLUDED.timezone,
+			display_currency = EXCLUDED.display_currency,
+			marketing_consent = EXCLUDED.marketing_consent,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			updated_at = EXCLUDED.updated_at
+		WHERE user_preferences.updated_at < EXCLUDED.updated_at
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		prefs.UserID,
+		prefs.Locale,
+		prefs.Timezone,
+		prefs.DisplayCurrency,
+		prefs.MarketingConsent,
+		prefs.QuietHoursStart,
+		prefs.QuietHoursEnd,
+		prefs.UpdatedAt,
+	)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to save user preferences")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	return rows > 0, nil
+}
@@ -33,6 +33,12 @@ func (r *TemplateRepository) Create(ctx context.Context, template *models.Notifi
 		}
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	query := `
 		INSERT INTO notification_templates (
 			name, channel, subject_template, body_template, version, metadata
@@ -41,7 +47,7 @@ func (r *TemplateRepository) Create(ctx context.Context, template *models.Notifi
 		RETURNING id, created_at, updated_at
 	`
 
-	err = r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		template.Name,
 		template.Channel,
 		template.SubjectTemplate,
@@ -58,6 +64,15 @@ func (r *TemplateRepository) Create(ctx context.Context, template *models.Notifi
 		return errors.DatabaseWrap(err, "failed to create template")
 	}
 
+	// The initial content is the first published version
+	if err := snapshotVersion(ctx, tx, template.ID, template.Version, template.SubjectTemplate, template.BodyTemplate); err != nil {
+		return errors.DatabaseWrap(err, "failed to create template version")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit template")
+	}
+
 	return nil
 }
 
@@ -228,8 +243,16 @@ func (r *TemplateRepository) Update(ctx context.Context, id string, req *models.
 		setClauses = append(setClauses, "body_template = $"+fmt.Sprint(argIndex))
 		args = append(args, *req.BodyTemplate)
 		argIndex++
-		// Increment version when body is updated
-		setClauses = append(setClauses, "version = version + 1")
+	}
+
+	// Content changes become a new published version, numbered after any drafts
+	contentChanged := req.SubjectTemplate != nil || req.BodyTemplate != nil
+	if contentChanged {
+		setClauses = append(setClauses, `version = (
+			SELECT COALESCE(MAX(version), 0) + 1
+			FROM notification_template_versions
+			WHERE template_id = notification_templates.id
+		)`)
 	}
 
 	if len(req.MetadataRaw) > 0 {
@@ -256,25 +279,41 @@ func (r *TemplateRepository) Update(ctx context.Context, id string, req *models.
 	// Add ID to args
 	args = append(args, id)
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
 	//nolint:gosec // setClauses is built from controlled field names, not user input
 	query := fmt.Sprintf(`
 		UPDATE notification_templates
 		SET %s
 		WHERE id = $%d
+		RETURNING version, subject_template, body_template
 	`, strings.Join(setClauses, ", "), argIndex)
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	var (
+		version int
+		subject sql.NullString
+		body    string
+	)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&version, &subject, &body)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("template", id)
+		}
 		return errors.DatabaseWrap(err, "failed to update template")
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return errors.DatabaseWrap(err, "failed to get rows affected")
+	if contentChanged {
+		if err := snapshotVersion(ctx, tx, id, version, subject.String, body); err != nil {
+			return errors.DatabaseWrap(err, "failed to create template version")
+		}
 	}
 
-	if rowsAffected == 0 {
-		return errors.NotFoundWithID("template", id)
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit template")
 	}
 
 	return nil
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// TemplateVersionRepository handles database operations for template versions
+// and their per-type rollouts.
+type TemplateVersionRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateVersionRepository creates a new template version repository.
+func NewTemplateVersionRepository(db *sql.DB) *TemplateVersionRepository {
+	return &TemplateVersionRepository{db: db}
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// snapshotVersion records a template's current content as a published version.
+func snapshotVersion(ctx context.Context, db execer, templateID string, version int, subject, body string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO notification_template_versions (
+			template_id, version, subject_template, body_template, status, published_at
+		)
+		VALUES ($1, $2, $3, $4, 'published', NOW())
+	`, templateID, version, subject, body)
+	return err
+}
+
+const versionColumns = `id, template_id, version, subject_template, body_template, status, created_at, published_at`
+
+func scanVersion(row rowScanner) (*models.TemplateVersion, error) {
+	v := &models.TemplateVersion{}
+	var subject sql.NullString
+	err := row.Scan(&v.ID, &v.TemplateID, &v.Version, &subject, &v.BodyTemplate, &v.Status, &v.CreatedAt, &v.PublishedAt)
+	v.SubjectTemplate = subject.String
+	return v, err
+}
+
+// CreateDraft adds a draft version numbered after the template's latest version.
+func (r *TemplateVersionRepository) CreateDraft(ctx context.Context, v *models.TemplateVersion) *errors.Error {
+	query := `
+		INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, 'draft'
+		FROM notification_template_versions
+		WHERE template_id = $1
+		RETURNING ` + versionColumns
+
+	created, err := scanVersion(r.db.QueryRowContext(ctx, query, v.TemplateID, v.SubjectTemplate, v.BodyTemplate))
+	if err != nil {
+		// Two drafts created at once may race for the same number
+		if strings.Contains(err.Error(), "template_versions_unique") {
+			return errors.Conflict("another version was created concurrently, retry")
+		}
+		return errors.DatabaseWrap(err, "failed to create template version")
+	}
+
+	*v = *created
+	return nil
+}
+
+// Get retrieves one version of a template.
+func (r *TemplateVersionRepository) Get(ctx context.Context, templateID string, version int) (*models.TemplateVersion, *errors.Error) {
+	query := `SELECT ` + versionColumns + ` FROM notification_template_versions WHERE template_id = $1 AND version = $2`
+
+	v, err := scanVersion(r.db.QueryRowContext(ctx, query, templateID, version))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("template version")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get template version")
+	}
+	return v, nil
+}
+
+// List retrieves all versions of a template, newest first.
+func (r *TemplateVersionRepository) List(ctx context.Context, templateID string) ([]*models.TemplateVersion, *errors.Error) {
+	query := `SELECT ` + versionColumns + ` FROM notification_template_versions WHERE template_id = $1 ORDER BY version DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list template versions")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	versions := make([]*models.TemplateVersion, 0)
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan template version")
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating template versions")
+	}
+
+	return versions, nil
+}
+
+// Publish marks a draft version published. When it is newer than the
+// template's current content, the template is moved to it so unpinned sends
+// pick it up.
+func (r *TemplateVersionRepository) Publish(ctx context.Context, templateID string, version int) (*models.TemplateVersion, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE notification_template_versions
+		SET status = 'published', published_at = NOW()
+		WHERE template_id = $1 AND version = $2 AND status = 'draft'
+		RETURNING ` + versionColumns
+
+	v, err := scanVersion(tx.QueryRowContext(ctx, query, templateID, version))
+	if err == sql.ErrNoRows {
+		// Either missing or already published
+		_ = tx.Rollback()
+		if _, getErr := r.Get(ctx, templateID, version); getErr != nil {
+			return nil, getErr
+		}
+		return nil, errors.Conflict("template version is already published")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to publish template version")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE notification_templates
+		SET subject_template = $2, body_template = $3, version = $4, updated_at = NOW()
+		WHERE id = $1 AND version < $4
+	`, templateID, v.SubjectTemplate, v.BodyTemplate, v.Version)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to update template content")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit template version")
+	}
+	return v, nil
+}
+
+const rolloutColumns = `template_id, notification_type, version, candidate_version, candidate_percent, created_at, updated_at`
+
+func scanRollout(row rowScanner) (*models.TemplateRollout, error) {
+	rollout := &models.TemplateRollout{}
+	err := row.Scan(&rollout.TemplateID, &rollout.NotificationType, &rollout.Version,
+		&rollout.CandidateVersion, &rollout.CandidatePercent, &rollout.CreatedAt, &rollout.UpdatedAt)
+	return rollout, err
+}
+
+// SetRollout creates or replaces the rollout of a template for a notification type.
+func (r *TemplateVersionRepository) SetRollout(ctx context.Context, rollout *models.TemplateRollout) *errors.Error {
+	query := `
+		INSERT INTO notification_template_rollouts (
+			template_id, notification_type, version, candidate_version, candidate_percent
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (template_id, notification_type) DO UPDATE SET
+			version = EXCLUDED.version,
+			candidate_version = EXCLUDED.candidate_version,
+			candidate_percent = EXCLUDED.candidate_percent
+		RETURNING ` + rolloutColumns
+
+	saved, err := scanRollout(r.db.QueryRowContext(ctx, query,
+		rollout.TemplateID,
+		rollout.NotificationType,
+		rollout.Version,
+		rollout.CandidateVersion,
+		rollout.CandidatePercent,
+	))
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to save template rollout")
+	}
+
+	*rollout = *saved
+	return nil
+}
+
+// GetRollout retrieves the rollout of a template for a notification type, or
+// nil if the type is not pinned.
+func (r *TemplateVersionRepository) GetRollout(ctx context.Context, templateID string, notifType models.NotificationType) (*models.TemplateRollout, *errors.Error) {
+	query := `SELECT ` + rolloutColumns + ` FROM notification_template_rollouts WHERE template_id = $1 AND notification_type = $2`
+
+	rollout, err := scanRollout(r.db.QueryRowContext(ctx, query, templateID, notifType))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get template rollout")
+	}
+	return rollout, nil
+}
+
+// ListRollouts retrieves all rollouts of a template.
+func (r *TemplateVersionRepository) ListRollouts(ctx context.Context, templateID string) ([]*models.TemplateRollout, *errors.Error) {
+	query := `SELECT ` + rolloutColumns + ` FROM notification_template_rollouts WHERE template_id = $1 ORDER BY notification_type`
+
+	rows, err := r.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list template rollouts")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	rollouts := make([]*models.TemplateRollout, 0)
+	for rows.Next() {
+		rollout, err := scanRollout(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan template rollout")
+		}
+		rollouts = append(rollouts, rollout)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating template rollouts")
+	}
+
+	return rollouts, nil
+}
+
+// DeleteRollout removes the rollout of a template for a notification type.
+func (r *TemplateVersionRepository) DeleteRollout(ctx context.Context, templateID string, notifType models.NotificationType) *errors.Error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM notification_template_rollouts WHERE template_id = $1 AND notification_type = $2",
+		templateID, notifType)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete template rollout")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("template rollout")
+	}
+
+	return nil
+}
+
+// GetVersionStats returns delivery statistics per version for a template.
+// Notifications sent before versions were tracked are not included.
+func (r *TemplateVersionRepository) GetVersionStats(ctx context.Context, templateID string) ([]*models.TemplateVersionStats, *errors.Error) {
+	query := `
+		SELECT template_version, status, COUNT(*), COALESCE(SUM(retry_count), 0)
+		FROM notifications
+		WHERE template_id = $1 AND template_version IS NOT NULL
+		GROUP BY template_version, status
+		ORDER BY template_version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get template version stats")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	stats := make([]*models.TemplateVersionStats, 0)
+	byVersion := make(map[int]*models.TemplateVersionStats)
+	retries := make(map[int]int64)
+	for rows.Next() {
+		var (
+			version    int
+			status     models.NotificationStatus
+			count      int
+			retryCount int64
+		)
+		if err := rows.Scan(&version, &status, &count, &retryCount); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan template version stats")
+		}
+
+		s, ok := byVersion[version]
+		if !ok {
+			s = &models.TemplateVersionStats{Version: version, ByStatus: make(map[models.NotificationStatus]int)}
+			byVersion[version] = s
+			stats = append(stats, s)
+		}
+		s.ByStatus[status] += count
+		s.Total += int64(count)
+		retries[version] += retryCount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate template version stats")
+	}
+
+	for _, s := range stats {
+		delivered := s.ByStatus[models.StatusDelivered]
+		failed := s.ByStatus[models.StatusFailed]
+		if (delivered + failed) > 0 {
+			s.DeliveryRate = float64(delivered) / float64(delivered+failed) * 100
+		}
+		if s.Total > 0 {
+			s.AverageRetries = float64(retries[s.Version]) / float64(s.Total)
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// TranslationRepository handles database operations for template translations.
+type TranslationRepository struct {
+	db *sql.DB
+}
+
+// NewTranslationRepository creates a new template translation repository.
+func NewTranslationRepository(db *sql.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+const translationColumns = `template_id, locale, subject_template, body_template, source_version, created_at, updated_at`
+
+func scanTranslation(row rowScanner) (*models.TemplateTranslation, error) {
+	t := &models.TemplateTranslation{}
+	var subject sql.NullString
+	err := row.Scan(&t.TemplateID, &t.Locale, &subject, &t.BodyTemplate, &t.SourceVersion, &t.CreatedAt, &t.UpdatedAt)
+	t.SubjectTemplate = subject.String
+	return t, err
+}
+
+// Upsert creates or replaces the translation of a template into a locale.
+func (r *TranslationRepository) Upsert(ctx context.Context, t *models.TemplateTranslation) *errors.Error {
+	query := `
+		INSERT INTO notification_template_translations (
+			template_id, locale, subject_template, body_template, source_version
+		)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (template_id, locale) DO UPDATE SET
+			subject_template = EXCLUDED.subject_template,
+			body_template = EXCLUDED.body_template,
+			source_version = EXCLUDED.source_version
+		RETURNING ` + translationColumns
+
+	saved, err := scanTranslation(r.db.QueryRowContext(ctx, query,
+		t.TemplateID,
+		t.Locale,
+		t.SubjectTemplate,
+		t.BodyTemplate,
+		t.SourceVersion,
+	))
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to save template translation")
+	}
+
+	*t = *saved
+	return nil
+}
+
+// Get retrieves the translation of a template into a locale, or nil if there
+// is none.
+func (r *TranslationRepository) Get(ctx context.Context, templateID, locale string) (*models.TemplateTranslation, *errors.Error) {
+	query := `SELECT ` + translationColumns + ` FROM notification_template_translations WHERE template_id = $1 AND locale = $2`
+
+	t, err := scanTranslation(r.db.QueryRowContext(ctx, query, templateID, locale))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get template translation")
+	}
+	return t, nil
+}
+
+// List retrieves the translations of a template, or of every template when
+// templateID is empty, ordered by template and locale.
+func (r *TranslationRepository) List(ctx context.Context, templateID string) ([]*models.TemplateTranslation, *errors.Error) {
+	query := `SELECT ` + translationColumns + ` FROM notification_template_translations`
+	args := []interface{}{}
+	if templateID != "" {
+		query += ` WHERE template_id = $1`
+		args = append(args, templateID)
+	}
+	query += ` ORDER BY template_id, locale`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list template translations")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	translations := make([]*models.TemplateTranslation, 0)
+	for rows.Next() {
+		t, err := scanTranslation(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan template translation")
+		}
+		translations = append(translations, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating template translations")
+	}
+
+	return translations, nil
+}
+
+// Delete removes the translation of a template into a locale.
+func (r *TranslationRepository) Delete(ctx context.Context, templateID, locale string) *errors.Error {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM notification_template_translations WHERE template_id = $1 AND locale = $2",
+		templateID, locale)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete template translation")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NotFound("template translation")
+	}
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// WebhookRepository handles database operations for webhook endpoints and
+// their delivery attempts.
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository creates a new webhook repository.
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+const webhookEndpointColumns = `
+	id, owner_type, owner_id, url, secret, description, enabled, created_at, updated_at
+`
+
+// CreateEndpoint registers a new webhook endpoint.
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) *errors.Error {
+	query := `
+		INSERT INTO webhook_endpoints (owner_type, owner_id, url, secret, description, enabled)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		endpoint.OwnerType,
+		endpoint.OwnerID,
+		endpoint.URL,
+		endpoint.Secret,
+		endpoint.Description,
+		endpoint.Enabled,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "webhook_endpoints_owner_key") {
+			return errors.Conflict("a webhook endpoint is already registered for this owner")
+		}
+		return errors.DatabaseWrap(err, "failed to create webhook endpoint")
+	}
+
+	return nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID.
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id string) (*models.WebhookEndpoint, *errors.Error) {
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE id = $1`
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("webhook endpoint", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get webhook endpoint")
+	}
+
+	return endpoint, nil
+}
+
+// FindForNotification retrieves the enabled endpoint a webhook notification
+// is delivered to: the user's endpoint when the notification has a user,
+// otherwise the service endpoint whose owner or URL is the recipient.
+func (r *WebhookRepository) FindForNotification(ctx context.Context, userID *string, recipient string) (*models.WebhookEndpoint, *errors.Error) {
+	var row *sql.Row
+	if userID != nil {
+		query := `
+			SELECT ` + webhookEndpointColumns + `
+			FROM webhook_endpoints
+			WHERE owner_type = 'user' AND owner_id = $1 AND enabled = true
+		`
+		row = r.db.QueryRowContext(ctx, query, *userID)
+	} else {
+		query := `
+			SELECT ` + webhookEndpointColumns + `
+			FROM webhook_endpoints
+			WHERE owner_type = 'service' AND (owner_id = $1 OR url = $1) AND enabled = true
+			ORDER BY (owner_id = $1) DESC, created_at ASC
+			LIMIT 1
+		`
+		row = r.db.QueryRowContext(ctx, query, recipient)
+	}
+
+	endpoint, err := scanWebhookEndpoint(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("webhook endpoint")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to find webhook endpoint")
+	}
+
+	return endpoint, nil
+}
+
+// ListEndpoints retrieves webhook endpoints, optionally filtered by owner.
+func (r *WebhookRepository) ListEndpoints(ctx context.Context, ownerType *models.WebhookOwnerType, ownerID *string) ([]*models.WebhookEndpoint, *errors.Error) {
+	query := `SELECT ` + webhookEndpointColumns + ` FROM webhook_endpoints WHERE 1=1`
+	var args []interface{}
+
+	if ownerType != nil {
+		args = append(args, *ownerType)
+		query += " AND owner_type = $1"
+	}
+	if ownerID != nil {
+		args = append(args, *ownerID)
+		if len(args) == 1 {
+			query += " AND owner_id = $1"
+		} else {
+			query += " AND owner_id = $2"
+		}
+	}
+	query += " ORDER BY owner_type, owner_id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list webhook endpoints")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	endpoints := make([]*models.WebhookEndpoint, 0)
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan webhook endpoint")
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate webhook endpoints")
+	}
+
+	return endpoints, nil
+}
+
+// UpdateEndpoint persists changes to an endpoint's URL, secret, description
+// and enabled flag.
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) *errors.Error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $2, secret = $3, description = $4, enabled = $5
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		endpoint.ID,
+		endpoint.URL,
+		endpoint.Secret,
+		endpoint.Description,
+		endpoint.Enabled,
+	).Scan(&endpoint.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("webhook endpoint", endpoint.ID)
+		}
+		return errors.DatabaseWrap(err, "failed to update webhook endpoint")
+	}
+
+	return nil
+}
+
+// DeleteEndpoint removes a webhook endpoint. Its delivery history is kept.
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete webhook endpoint")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	if rows == 0 {
+		return errors.NotFoundWithID("webhook endpoint", id)
+	}
+	return nil
+}
+
+// RecordDelivery stores a delivery attempt.
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, delivery *models.WebhookDelivery) *errors.Error {
+	query := `
+		INSERT INTO webhook_deliveries (
+			notification_id, endpoint_id, url, attempt, status_code, succeeded,
+			error, response_body, duration_ms
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delivery.NotificationID,
+		delivery.EndpointID,
+		delivery.URL,
+		delivery.Attempt,
+		delivery.StatusCode,
+		delivery.Succeeded,
+		delivery.Error,
+		delivery.ResponseBody,
+		delivery.DurationMs,
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record webhook delivery")
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves a notification's delivery attempts in order.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, notificationID string) ([]*models.WebhookDelivery, *errors.Error) {
+	query := `
+		SELECT id, notification_id, endpoint_id, url, attempt, status_code, succeeded,
+		       error, response_body, duration_ms, created_at
+		FROM webhook_deliveries
+		WHERE notification_id = $1
+		ORDER BY attempt ASC, created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, notificationID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list webhook deliveries")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery := &models.WebhookDelivery{}
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.NotificationID,
+			&delivery.EndpointID,
+			&delivery.URL,
+			&delivery.Attempt,
+			&delivery.StatusCode,
+			&delivery.Succeeded,
+			&delivery.Error,
+			&delivery.ResponseBody,
+			&delivery.DurationMs,
+			&delivery.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan webhook delivery")
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// scanWebhookEndpoint scans a webhook endpoint row.
+func scanWebhookEndpoint(row rowScanner) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{}
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.OwnerType,
+		&endpoint.OwnerID,
+		&endpoint.URL,
+		&endpoint.Secret,
+		&endpoint.Description,
+		&endpoint.Enabled,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
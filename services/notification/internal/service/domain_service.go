@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// dkimKeyBits is the RSA key size used for simulated DKIM keys.
+const dkimKeyBits = 2048
+
+// simulatedDNSFailureSuffix marks domains whose DNS lookups always fail in simulation.
+const simulatedDNSFailureSuffix = ".invalid"
+
+var (
+	domainNamePattern   = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	dkimSelectorPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// DomainService manages email sending domains and simulates deliverability checks.
+type DomainService struct {
+	domainRepo *repository.DomainRepository
+}
+
+// NewDomainService creates a new domain service.
+func NewDomainService(domainRepo *repository.DomainRepository) *DomainService {
+	return &DomainService{domainRepo: domainRepo}
+}
+
+// CreateDomain registers a new sending domain in pending state.
+func (s *DomainService) CreateDomain(ctx context.Context, req *models.CreateDomainRequest) (*models.SendingDomain, *errors.Error) {
+	name := strings.ToLower(strings.TrimSpace(req.Domain))
+	if !domainNamePattern.MatchString(name) {
+		return nil, errors.Validation("invalid domain name")
+	}
+
+	tenant := req.Tenant
+	if tenant == "" {
+		tenant = models.DefaultTenant
+	}
+
+	domain := &models.SendingDomain{
+		Domain:             name,
+		Tenant:             tenant,
+		DefaultFromAddress: req.DefaultFromAddress,
+		FromAddresses:      req.FromAddresses,
+		BounceAddress:      req.BounceAddress,
+		Status:             models.DomainStatusPending,
+		Enabled:            true,
+	}
+
+	if err := validateSenderAddresses(domain); err != nil {
+		return nil, err
+	}
+
+	if err := s.domainRepo.Create(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Registered sending domain %s (tenant=%s)", domain.Domain, domain.Tenant)
+	return domain, nil
+}
+
+// GetDomain retrieves a sending domain by ID.
+func (s *DomainService) GetDomain(ctx context.Context, id string) (*models.SendingDomain, *errors.Error) {
+	return s.domainRepo.GetByID(ctx, id)
+}
+
+// ListDomains retrieves sending domains, optionally filtered by tenant.
+func (s *DomainService) ListDomains(ctx context.Context, tenant *string) ([]*models.SendingDomain, *errors.Error) {
+	return s.domainRepo.List(ctx, tenant)
+}
+
+// UpdateDomain updates sender configuration. Address changes require re-verification.
+func (s *DomainService) UpdateDomain(ctx context.Context, id string, req *models.UpdateDomainRequest) (*models.SendingDomain, *errors.Error) {
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	addressesChanged := false
+	if req.DefaultFromAddress != nil {
+		domain.DefaultFromAddress = *req.DefaultFromAddress
+		addressesChanged = true
+	}
+	if req.FromAddresses != nil {
+		domain.FromAddresses = req.FromAddresses
+		addressesChanged = true
+	}
+	if req.BounceAddress != nil {
+		domain.BounceAddress = *req.BounceAddress
+		addressesChanged = true
+	}
+	if req.Enabled != nil {
+		domain.Enabled = *req.Enabled
+	}
+
+	if addressesChanged {
+		if err := validateSenderAddresses(domain); err != nil {
+			return nil, err
+		}
+		domain.Status = models.DomainStatusPending
+	}
+
+	if err := s.domainRepo.Update(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// RegisterDKIM generates a simulated DKIM key pair for a selector and stores the public key.
+// The private key is discarded; the simulator never signs real mail.
+func (s *DomainService) RegisterDKIM(ctx context.Context, id string, req *models.RegisterDKIMRequest) (*models.SendingDomain, *errors.Error) {
+	selector := strings.ToLower(strings.TrimSpace(req.Selector))
+	if !dkimSelectorPattern.MatchString(selector) {
+		return nil, errors.Validation("invalid DKIM selector")
+	}
+
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, keyErr := rsa.GenerateKey(rand.Reader, dkimKeyBits)
+	if keyErr != nil {
+		return nil, errors.Internal("failed to generate DKIM key")
+	}
+	pubDER, keyErr := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if keyErr != nil {
+		return nil, errors.Internal("failed to encode DKIM key")
+	}
+	publicKey := base64.StdEncoding.EncodeToString(pubDER)
+
+	if err := s.domainRepo.UpdateDKIM(ctx, id, selector, publicKey); err != nil {
+		return nil, err
+	}
+
+	domain.DKIMSelector = &selector
+	domain.DKIMPublicKey = &publicKey
+	domain.Status = models.DomainStatusPending
+
+	log.Printf("[notification] Registered DKIM selector %s for domain %s", selector, domain.Domain)
+	return domain, nil
+}
+
+// VerifyDomain runs simulated DNS and sender checks and records the outcome.
+func (s *DomainService) VerifyDomain(ctx context.Context, id string) (*models.DomainVerificationResult, *errors.Error) {
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	dnsResolves := !strings.HasSuffix(domain.Domain, simulatedDNSFailureSuffix)
+	checks := []models.DomainCheck{
+		{Name: "dns", Passed: dnsResolves, Detail: dnsDetail(dnsResolves, domain.Domain)},
+		{Name: "spf", Passed: dnsResolves, Detail: "v=spf1 include:_spf.nivomoney.local ~all"},
+		dkimCheck(domain, dnsResolves),
+		senderCheck(domain),
+	}
+
+	status := models.DomainStatusVerified
+	for _, check := range checks {
+		if !check.Passed {
+			status = models.DomainStatusFailed
+			break
+		}
+	}
+
+	if err := s.domainRepo.UpdateVerification(ctx, id, status); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Verified sending domain %s: %s", domain.Domain, status)
+
+	return &models.DomainVerificationResult{
+		DomainID:   domain.ID,
+		Domain:     domain.Domain,
+		Status:     status,
+		Checks:     checks,
+		DNSRecords: requiredDNSRecords(domain),
+		VerifiedAt: sharedModels.Now(),
+	}, nil
+}
+
+// GetDNSRecords returns the DNS records required to verify a domain.
+func (s *DomainService) GetDNSRecords(ctx context.Context, id string) ([]models.DNSRecord, *errors.Error) {
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return requiredDNSRecords(domain), nil
+}
+
+// GetDeliveryStats retrieves delivery statistics for a sending domain.
+func (s *DomainService) GetDeliveryStats(ctx context.Context, id string) (*models.DomainDeliveryStats, *errors.Error) {
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.domainRepo.GetDeliveryStats(ctx, domain.Domain)
+	if err != nil {
+		return nil, err
+	}
+	stats.DomainID = domain.ID
+
+	return stats, nil
+}
+
+// ResolveSender finds the sending domain for an email notification.
+// Returns nil when no domain is configured for the tenant or the default tenant.
+func (s *DomainService) ResolveSender(ctx context.Context, tenant string) (*models.SendingDomain, *errors.Error) {
+	if tenant == "" {
+		tenant = models.DefaultTenant
+	}
+
+	domain, err := s.domainRepo.GetForTenant(ctx, tenant)
+	if err != nil && err.Code == errors.ErrCodeNotFound && tenant != models.DefaultTenant {
+		domain, err = s.domainRepo.GetForTenant(ctx, models.DefaultTenant)
+	}
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// validateSenderAddresses ensures all sender addresses are well-formed.
+func validateSenderAddresses(domain *models.SendingDomain) *errors.Error {
+	if _, err := mail.ParseAddress(domain.DefaultFromAddress); err != nil {
+		return errors.Validation("invalid default_from_address")
+	}
+	if _, err := mail.ParseAddress(domain.BounceAddress); err != nil {
+		return errors.Validation("invalid bounce_address")
+	}
+	for notifType, addr := range domain.FromAddresses {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return errors.Validation(fmt.Sprintf("invalid from address for type %s", notifType))
+		}
+	}
+	return nil
+}
+
+// addressInDomain reports whether an address belongs to the domain or one of its subdomains.
+func addressInDomain(address, domain string) bool {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return false
+	}
+	at := strings.LastIndex(parsed.Address, "@")
+	if at < 0 {
+		return false
+	}
+	host := strings.ToLower(parsed.Address[at+1:])
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// dnsDetail describes the simulated DNS lookup outcome.
+func dnsDetail(resolves bool, domain string) string {
+	if resolves {
+		return "domain resolves"
+	}
+	return fmt.Sprintf("NXDOMAIN for %s", domain)
+}
+
+// dkimCheck verifies a DKIM key is registered and published.
+func dkimCheck(domain *models.SendingDomain, dnsResolves bool) models.DomainCheck {
+	check := models.DomainCheck{Name: "dkim"}
+	switch {
+	case domain.DKIMSelector == nil || domain.DKIMPublicKey == nil:
+		check.Detail = "no DKIM key registered"
+	case !dnsResolves:
+		check.Detail = fmt.Sprintf("TXT record not found at %s", domain.DKIMRecordHost())
+	default:
+		check.Passed = true
+		check.Detail = fmt.Sprintf("key published at %s", domain.DKIMRecordHost())
+	}
+	return check
+}
+
+// senderCheck verifies from and bounce addresses are aligned with the domain.
+func senderCheck(domain *models.SendingDomain) models.DomainCheck {
+	check := models.DomainCheck{Name: "sender_alignment", Passed: true}
+
+	var misaligned []string
+	if !addressInDomain(domain.DefaultFromAddress, domain.Domain) {
+		misaligned = append(misaligned, domain.DefaultFromAddress)
+	}
+	if !addressInDomain(domain.BounceAddress, domain.Domain) {
+		misaligned = append(misaligned, domain.BounceAddress)
+	}
+	for _, addr := range domain.FromAddresses {
+		if !addressInDomain(addr, domain.Domain) {
+			misaligned = append(misaligned, addr)
+		}
+	}
+
+	if len(misaligned) > 0 {
+		check.Passed = false
+		check.Detail = fmt.Sprintf("addresses not in %s: %s", domain.Domain, strings.Join(misaligned, ", "))
+	}
+	return check
+}
+
+// requiredDNSRecords lists the records a domain owner must publish.
+func requiredDNSRecords(domain *models.SendingDomain) []models.DNSRecord {
+	records := []models.DNSRecord{
+		{Type: "TXT", Host: domain.Domain, Value: "v=spf1 include:_spf.nivomoney.local ~all"},
+		{Type: "TXT", Host: "_dmarc." + domain.Domain, Value: "v=DMARC1; p=quarantine"},
+	}
+	if domain.DKIMSelector != nil && domain.DKIMPublicKey != nil {
+		records = append(records, models.DNSRecord{
+			Type:  "TXT",
+			Host:  domain.DKIMRecordHost(),
+			Value: "v=DKIM1; k=rsa; p=" + *domain.DKIMPublicKey,
+		})
+	}
+	return records
+}
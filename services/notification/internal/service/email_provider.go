@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// EmailMessage is an email notification as handed to the email provider.
+type EmailMessage struct {
+	NotificationID string
+	From           string // Sending domain's from address; empty uses the provider default
+	To             string
+	Subject        string
+	Body           string
+	Attachments    []*models.NotificationAttachment // Inline content loaded; stored files by key
+}
+
+// EmailProvider adapts an email delivery provider. Adapters fetch
+// attachments given by storage key from object storage themselves.
+type EmailProvider interface {
+	SendEmail(ctx context.Context, msg *EmailMessage) error
+}
+
+// simulatedEmailProvider accepts every email; the simulation engine decides
+// whether delivery fails.
+type simulatedEmailProvider struct{}
+
+// SendEmail logs the email it would have sent.
+func (simulatedEmailProvider) SendEmail(ctx context.Context, msg *EmailMessage) error {
+	var inlineBytes int64
+	stored := 0
+	for _, attachment := range msg.Attachments {
+		if attachment.IsInline() {
+			inlineBytes += int64(len(attachment.Content))
+		} else {
+			stored++
+		}
+	}
+	log.Printf("[simulation] Email %s to %s with %d attachments (%d inline bytes, %d from storage)",
+		msg.NotificationID, msg.To, len(msg.Attachments), inlineBytes, stored)
+	return nil
+}
+
+// SetEmailProvider hands email notifications to provider instead of the
+// simulated provider.
+func (e *SimulationEngine) SetEmailProvider(provider EmailProvider) {
+	e.email = provider
+}
+
+// sendEmail builds the email message with its attachments and hands it to the
+// email provider. A provider error fails the notification.
+func (e *SimulationEngine) sendEmail(ctx context.Context, notif *models.Notification) (bool, *errors.Error) {
+	attachments, err := e.repo.ListAttachments(ctx, notif.ID, true)
+	if err != nil {
+		return false, err
+	}
+
+	msg := &EmailMessage{
+		NotificationID: notif.ID,
+		To:             notif.Recipient,
+		Subject:        notif.Subject,
+		Body:           notif.Body,
+		Attachments:    attachments,
+	}
+	if from, ok := notif.Metadata["from_address"].(string); ok {
+		msg.From = from
+	}
+	if e.tracker != nil {
+		body, trackErr := e.tracker.Instrument(ctx, notif.ID, msg.Body)
+		if trackErr != nil {
+			// Tracking is best effort; send the email untracked
+			log.Printf("[simulation] Failed to add tracking to notification %s: %v", notif.ID, trackErr)
+		} else {
+			msg.Body = body
+		}
+	}
+
+	if sendErr := e.email.SendEmail(ctx, msg); sendErr != nil {
+		reason := sendErr.Error()
+		log.Printf("[simulation] Email provider rejected notification %s: %s", notif.ID, reason)
+		return false, e.updateStatus(ctx, notif.ID, models.StatusFailed, &reason)
+	}
+	return true, nil
+}
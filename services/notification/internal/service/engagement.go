@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/google/uuid"
+)
+
+// Tracking endpoint paths, relative to the tracking base URL.
+const (
+	TrackOpenPath  = "/v1/track/open/"
+	TrackClickPath = "/v1/track/click/"
+)
+
+// trackedHref matches absolute http(s) links in an HTML email body.
+var trackedHref = regexp.MustCompile(`href="(https?://[^"]+)"`)
+
+// TrackingRepository stores email tracking links and their hits.
+type TrackingRepository interface {
+	CreateTrackingLinks(ctx context.Context, links []*models.TrackingLink) *errors.Error
+	RecordTrackingHit(ctx context.Context, id string, kind models.TrackingKind) (*models.TrackingLink, *errors.Error)
+}
+
+// EngagementTracker adds an open pixel and click redirects to HTML emails and
+// records their hits.
+type EngagementTracker struct {
+	repo    TrackingRepository
+	baseURL string // Public URL the tracking endpoints are served at
+}
+
+// NewEngagementTracker creates a tracker whose links point at baseURL.
+func NewEngagementTracker(repo TrackingRepository, baseURL string) *EngagementTracker {
+	return &EngagementTracker{
+		repo:    repo,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Instrument returns the email body with its links routed through click
+// redirects and an open pixel appended, after storing the tracking links.
+// Plain-text bodies are returned unchanged.
+func (t *EngagementTracker) Instrument(ctx context.Context, notificationID, body string) (string, *errors.Error) {
+	tracked, links := instrumentBody(body, t.baseURL, notificationID, func() string { return uuid.New().String() })
+	if len(links) == 0 {
+		return body, nil
+	}
+	if err := t.repo.CreateTrackingLinks(ctx, links); err != nil {
+		return "", err
+	}
+	return tracked, nil
+}
+
+// RecordOpen counts an open from the pixel with the given ID.
+func (t *EngagementTracker) RecordOpen(ctx context.Context, id string) *errors.Error {
+	_, err := t.repo.RecordTrackingHit(ctx, id, models.TrackingOpen)
+	return err
+}
+
+// RecordClick counts a click on the redirect with the given ID and returns
+// the URL to redirect to.
+func (t *EngagementTracker) RecordClick(ctx context.Context, id string) (string, *errors.Error) {
+	link, err := t.repo.RecordTrackingHit(ctx, id, models.TrackingClick)
+	if err != nil {
+		return "", err
+	}
+	if link.URL == nil {
+		return "", errors.NotFoundWithID("tracking link", id)
+	}
+	return *link.URL, nil
+}
+
+// instrumentBody rewrites an HTML body's links to click redirects and appends
+// an open pixel, before </body> when there is one. newID names each link.
+func instrumentBody(body, baseURL, notificationID string, newID func() string) (string, []*models.TrackingLink) {
+	if !isHTML(body) {
+		return body, nil
+	}
+
+	var links []*models.TrackingLink
+	tracked := trackedHref.ReplaceAllStringFunc(body, func(match string) string {
+		target := html.UnescapeString(trackedHref.FindStringSubmatch(match)[1])
+		link := &models.TrackingLink{ID: newID(), NotificationID: notificationID, Kind: models.TrackingClick, URL: &target}
+		links = append(links, link)
+		return `href="` + baseURL + TrackClickPath + link.ID + `"`
+	})
+
+	pixel := &models.TrackingLink{ID: newID(), NotificationID: notificationID, Kind: models.TrackingOpen}
+	links = append(links, pixel)
+	img := `<img src="` + baseURL + TrackOpenPath + pixel.ID + `" width="1" height="1" alt="" style="display:none">`
+	if i := strings.LastIndex(strings.ToLower(tracked), "</body>"); i >= 0 {
+		tracked = tracked[:i] + img + tracked[i:]
+	} else {
+		tracked += img
+	}
+
+	return tracked, links
+}
+
+// isHTML reports whether an email body is HTML rather than plain text.
+func isHTML(body string) bool {
+	lower := strings.ToLower(body)
+	return strings.Contains(lower, "<html") || strings.Contains(lower, "<body") ||
+		strings.Contains(lower, "<p>") || strings.Contains(lower, "<a ")
+}
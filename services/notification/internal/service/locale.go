@@ -0,0 +1,111 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultLocale is the language templates are written in when no other is
+// configured.
+const DefaultLocale = "en"
+
+// localePattern matches a language with an optional region, e.g. hi or hi-IN.
+var localePattern = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}))?$`)
+
+// NormalizeLocale returns a locale as language[-REGION], e.g. "hi_in" as
+// "hi-IN". It reports false for anything else.
+func NormalizeLocale(locale string) (string, bool) {
+	m := localePattern.FindStringSubmatch(strings.TrimSpace(locale))
+	if m == nil {
+		return "", false
+	}
+	if m[2] == "" {
+		return strings.ToLower(m[1]), true
+	}
+	return strings.ToLower(m[1]) + "-" + strings.ToUpper(m[2]), true
+}
+
+// localeLanguage returns the language of a normalized locale.
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(locale, "-")
+	return language
+}
+
+// LocaleChain returns the locales to try for a recipient, most specific
+// first: the locale, its language, then the default locale and its language.
+// An invalid or empty locale falls straight back to the default.
+func LocaleChain(locale, defaultLocale string) []string {
+	chain := make([]string, 0, 4)
+	add := func(l string) {
+		for _, existing := range chain {
+			if existing == l {
+				return
+			}
+		}
+		chain = append(chain, l)
+	}
+
+	for _, l := range []string{locale, defaultLocale} {
+		if normalized, ok := NormalizeLocale(l); ok {
+			add(normalized)
+			add(localeLanguage(normalized))
+		}
+	}
+	return chain
+}
+
+// Plural categories, following CLDR names.
+const (
+	pluralOne   = "one"
+	pluralOther = "other"
+)
+
+// pluralRules picks the plural category of a count for languages whose rule
+// differs from English. Languages not listed use the English rule.
+var pluralRules = map[string]func(n float64) string{
+	// Hindi and neighbours: 0 and anything up to 1 are singular
+	"hi": zeroToOneIsOne,
+	"bn": zeroToOneIsOne,
+	"gu": zeroToOneIsOne,
+	"kn": zeroToOneIsOne,
+	// French and Portuguese: integer part 0 or 1 is singular
+	"fr": integerZeroOrOneIsOne,
+	"pt": integerZeroOrOneIsOne,
+	// No grammatical plural
+	"ja": alwaysOther,
+	"ko": alwaysOther,
+	"zh": alwaysOther,
+	"th": alwaysOther,
+	"id": alwaysOther,
+	"vi": alwaysOther,
+}
+
+func zeroToOneIsOne(n float64) string {
+	if n >= 0 && n <= 1 {
+		return pluralOne
+	}
+	return pluralOther
+}
+
+func integerZeroOrOneIsOne(n float64) string {
+	if n >= 0 && n < 2 {
+		return pluralOne
+	}
+	return pluralOther
+}
+
+func alwaysOther(float64) string {
+	return pluralOther
+}
+
+// pluralCategory returns the plural category of n in a locale.
+func pluralCategory(locale string, n float64) string {
+	normalized, _ := NormalizeLocale(locale)
+	if rule, ok := pluralRules[localeLanguage(normalized)]; ok {
+		return rule(n)
+	}
+	if n == 1 {
+		return pluralOne
+	}
+	return pluralOther
+}
@@ -2,12 +2,17 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"math"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
 	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/workerpool"
 	"github.com/google/uuid"
 )
 
@@ -17,18 +22,30 @@ type NotificationService struct {
 	templateRepo   *repository.TemplateRepository
 	templateEngine *TemplateEngine
 	simEngine      *SimulationEngine
+	domainService  *DomainService
+	versionService *TemplateVersionService
+	pool           *workerpool.Pool
+	publisher      *events.Publisher
+	rateLimiter    *RateLimiter
+	prefRepo       *repository.PreferenceRepository
+	translations   *TranslationService
+	tracker        *EngagementTracker
 }
 
 // NewNotificationService creates a new notification service.
 func NewNotificationService(
 	notifRepo *repository.NotificationRepository,
 	templateRepo *repository.TemplateRepository,
+	domainService *DomainService,
+	versionService *TemplateVersionService,
 	simConfig SimulationConfig,
 ) *NotificationService {
 	service := &NotificationService{
 		notifRepo:      notifRepo,
 		templateRepo:   templateRepo,
 		templateEngine: NewTemplateEngine(),
+		domainService:  domainService,
+		versionService: versionService,
 	}
 
 	// Initialize simulation engine with the repository
@@ -50,13 +67,45 @@ func (s *NotificationService) SendNotification(ctx context.Context, req *models.
 				NotificationID: existing.ID,
 				Status:         existing.Status,
 				QueuedAt:       existing.QueuedAt,
+				ScheduledFor:   existing.ScheduledFor,
 			}, nil
 		}
 	}
 
+	// Resolve the send time; scheduled notifications are held until then
+	scheduledFor, schedErr := req.ScheduledTime(time.Now())
+	if schedErr != nil {
+		return nil, errors.Validation(schedErr.Error())
+	}
+	if scheduledFor != nil && req.Type == models.TypeOTP {
+		return nil, errors.Validation("otp notifications cannot be scheduled")
+	}
+
+	attachments, attachErr := models.ParseAttachments(req.Channel, req.Attachments)
+	if attachErr != nil {
+		return nil, errors.Validation(attachErr.Error())
+	}
+
+	// Apply the user's preferences; marketing needs their consent
+	prefs, prefErr := s.userPreferences(ctx, req.UserID)
+	if prefErr != nil {
+		return nil, prefErr
+	}
+	if req.Type == models.TypeMarketing {
+		if req.UserID == nil || *req.UserID == "" {
+			return nil, errors.Validation("marketing notifications require user_id")
+		}
+		if prefs == nil || !prefs.MarketingConsent {
+			return nil, errors.Forbidden("user has not consented to marketing notifications")
+		}
+	}
+	variables := preferenceVariables(req.Variables, prefs)
+	locale := recipientLocale(req.Locale, prefs)
+
 	// Prepare notification
 	var subject, body string
 	var templateID *string
+	var templateVersion *int
 
 	// If template is specified, render it
 	if req.TemplateID != nil && *req.TemplateID != "" {
@@ -76,12 +125,37 @@ func (s *NotificationService) SendNotification(ctx context.Context, req *models.
 			return nil, err
 		}
 
+		// Pick the version pinned or rolled out for this type and recipient
+		version, err := s.versionService.Resolve(ctx, template, req.Type, req.Recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		// Pick the translation for the recipient's locale, falling back to
+		// the version's own content
+		subjectTemplate, bodyTemplate := version.SubjectTemplate, version.BodyTemplate
+		contentLocale := DefaultLocale
+		if s.translations != nil {
+			subjectTemplate, bodyTemplate, contentLocale, err = s.translations.Localize(ctx, template.ID, locale, subjectTemplate, bodyTemplate)
+			if err != nil {
+				return nil, err
+			}
+		}
+		locale = contentLocale
+
+		// Fail rather than send raw placeholders for missing variables
+		variables, err = s.templateEngine.completeTemplateVariables(template.Name, req.Type, subjectTemplate, bodyTemplate, variables)
+		if err != nil {
+			return nil, err
+		}
+
 		// Render subject and body
-		if template.SubjectTemplate != "" {
-			subject, _ = s.templateEngine.Render(template.SubjectTemplate, req.Variables)
+		if subjectTemplate != "" {
+			subject, _ = s.templateEngine.RenderLocale(subjectTemplate, variables, locale)
 		}
-		body, _ = s.templateEngine.Render(template.BodyTemplate, req.Variables)
+		body, _ = s.templateEngine.RenderLocale(bodyTemplate, variables, locale)
 		templateID = &template.ID
+		templateVersion = &version.Version
 	} else {
 		// Use provided subject and body
 		subject = req.Subject
@@ -98,6 +172,19 @@ func (s *NotificationService) SendNotification(ctx context.Context, req *models.
 	if err != nil {
 		return nil, errors.Validation("invalid metadata JSON")
 	}
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	if locale != "" {
+		metadata["locale"] = locale
+	}
+
+	// Attach sender identity for email notifications
+	if req.Channel == models.ChannelEmail {
+		if err := s.applySendingDomain(ctx, req.Type, metadata); err != nil {
+			return nil, err
+		}
+	}
 
 	// Set default priority if not provided
 	priority := req.Priority
@@ -115,23 +202,39 @@ func (s *NotificationService) SendNotification(ctx context.Context, req *models.
 
 	// Create notification
 	notif := &models.Notification{
-		ID:            uuid.New().String(),
-		UserID:        req.UserID,
-		Channel:       req.Channel,
-		Type:          req.Type,
-		Priority:      priority,
-		Recipient:     req.Recipient,
-		Subject:       subject,
-		Body:          body,
-		TemplateID:    templateID,
-		Status:        models.StatusQueued,
-		CorrelationID: req.CorrelationID,
-		SourceService: sourceService,
-		Metadata:      metadata,
-		RetryCount:    0,
-		QueuedAt:      sharedModels.Now(),
-		CreatedAt:     sharedModels.Now(),
-		UpdatedAt:     sharedModels.Now(),
+		ID:              uuid.New().String(),
+		UserID:          req.UserID,
+		Channel:         req.Channel,
+		Type:            req.Type,
+		Priority:        priority,
+		Recipient:       req.Recipient,
+		Subject:         subject,
+		Body:            body,
+		TemplateID:      templateID,
+		TemplateVersion: templateVersion,
+		Status:          models.StatusQueued,
+		CorrelationID:   req.CorrelationID,
+		SourceService:   sourceService,
+		Metadata:        metadata,
+		RetryCount:      0,
+		QueuedAt:        sharedModels.Now(),
+		CreatedAt:       sharedModels.Now(),
+		UpdatedAt:       sharedModels.Now(),
+		Attachments:     attachments,
+	}
+	if scheduledFor != nil {
+		at := sharedModels.NewTimestamp(*scheduledFor)
+		notif.Status = models.StatusScheduled
+		notif.ScheduledFor = &at
+		if req.Timezone != "" {
+			notif.Timezone = &req.Timezone
+		}
+	}
+	deferForQuietHours(notif, prefs)
+
+	// Count against the recipient's limits only once the request is valid
+	if err := s.checkRecipientRateLimit(notif); err != nil {
+		return nil, err
 	}
 
 	// Save to database
@@ -139,19 +242,275 @@ func (s *NotificationService) SendNotification(ctx context.Context, req *models.
 		return nil, err
 	}
 
-	log.Printf("[notification] Created notification %s (type=%s, channel=%s, recipient=%s, priority=%s)",
-		notif.ID, notif.Type, notif.Channel, notif.Recipient, notif.Priority)
+	log.Printf("[notification] Created notification %s (type=%s, channel=%s, recipient=%s, priority=%s, attachments=%d)",
+		notif.ID, notif.Type, notif.Channel, notif.Recipient, notif.Priority, len(notif.Attachments))
 
 	return &models.SendNotificationResponse{
 		NotificationID: notif.ID,
 		Status:         notif.Status,
 		QueuedAt:       notif.QueuedAt,
+		ScheduledFor:   notif.ScheduledFor,
 	}, nil
 }
 
+// SetWorkerPool delivers queued notifications concurrently on pool. Without a
+// pool each batch is delivered one notification at a time.
+func (s *NotificationService) SetWorkerPool(pool *workerpool.Pool) {
+	s.pool = pool
+}
+
+// SetWebhookSender delivers webhook notifications by POSTing them to the
+// endpoint registered for their user or service.
+func (s *NotificationService) SetWebhookSender(sender *WebhookSender) {
+	s.simEngine.SetWebhookSender(sender)
+}
+
+// SetEmailProvider delivers email notifications, with their attachments,
+// through provider instead of simulating them.
+func (s *NotificationService) SetEmailProvider(provider EmailProvider) {
+	s.simEngine.SetEmailProvider(provider)
+}
+
+// SetEngagementTracker adds open and click tracking to HTML emails and
+// records the opens and clicks reported to TrackOpen and TrackClick.
+func (s *NotificationService) SetEngagementTracker(tracker *EngagementTracker) {
+	s.tracker = tracker
+	s.simEngine.tracker = tracker
+}
+
+// TrackOpen records that the email with the given open pixel was displayed.
+func (s *NotificationService) TrackOpen(ctx context.Context, pixelID string) *errors.Error {
+	if s.tracker == nil {
+		return errors.NotFound("engagement tracking")
+	}
+	return s.tracker.RecordOpen(ctx, pixelID)
+}
+
+// TrackClick records a click on a tracked email link and returns where the
+// link leads.
+func (s *NotificationService) TrackClick(ctx context.Context, linkID string) (string, *errors.Error) {
+	if s.tracker == nil {
+		return "", errors.NotFound("engagement tracking")
+	}
+	return s.tracker.RecordClick(ctx, linkID)
+}
+
+// SetRateLimiter enforces per-recipient rate limits when notifications are
+// sent and provider quotas when they are delivered.
+func (s *NotificationService) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// SetPreferences applies replicated user preferences when notifications are
+// sent: marketing consent, locale variables and quiet hours.
+func (s *NotificationService) SetPreferences(prefRepo *repository.PreferenceRepository) {
+	s.prefRepo = prefRepo
+}
+
+// SetTranslations sends templated notifications in the recipient's locale
+// when a translation exists.
+func (s *NotificationService) SetTranslations(translations *TranslationService) {
+	s.translations = translations
+}
+
+// recipientLocale returns the locale to send in: the request's, else the
+// user's preferred locale. Invalid locales are ignored.
+func recipientLocale(requested string, prefs *models.UserPreferences) string {
+	if locale, ok := NormalizeLocale(requested); ok {
+		return locale
+	}
+	if prefs != nil && prefs.Locale != nil {
+		if locale, ok := NormalizeLocale(*prefs.Locale); ok {
+			return locale
+		}
+	}
+	return ""
+}
+
+// userPreferences returns the preferences of the notification's user, or nil
+// if there is no user or identity has not published any.
+func (s *NotificationService) userPreferences(ctx context.Context, userID *string) (*models.UserPreferences, *errors.Error) {
+	if s.prefRepo == nil || userID == nil || *userID == "" {
+		return nil, nil
+	}
+
+	prefs, err := s.prefRepo.Get(ctx, *userID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// preferenceVariables adds the user's locale, timezone and display currency
+// to the template variables, unless the caller already set them.
+func preferenceVariables(variables map[string]interface{}, prefs *models.UserPreferences) map[string]interface{} {
+	if prefs == nil {
+		return variables
+	}
+
+	merged := make(map[string]interface{}, len(variables)+3)
+	for key, value := range variables {
+		merged[key] = value
+	}
+	for key, value := range map[string]*string{
+		"locale":           prefs.Locale,
+		"timezone":         prefs.Timezone,
+		"display_currency": prefs.DisplayCurrency,
+	} {
+		if _, set := merged[key]; !set && value != nil {
+			merged[key] = *value
+		}
+	}
+	return merged
+}
+
+// deferForQuietHours schedules a normal or low priority SMS or push
+// notification due during the user's quiet hours for when they end.
+func deferForQuietHours(notif *models.Notification, prefs *models.UserPreferences) {
+	if prefs == nil || notif.Type == models.TypeOTP {
+		return
+	}
+	if notif.Priority != models.PriorityNormal && notif.Priority != models.PriorityLow {
+		return
+	}
+	if notif.Channel != models.ChannelSMS && notif.Channel != models.ChannelPush {
+		return
+	}
+
+	due := time.Now()
+	if notif.ScheduledFor != nil {
+		due = notif.ScheduledFor.Time
+	}
+	until, quiet := prefs.QuietUntil(due)
+	if !quiet {
+		return
+	}
+
+	at := sharedModels.NewTimestamp(until)
+	notif.Status = models.StatusScheduled
+	notif.ScheduledFor = &at
+	notif.Timezone = prefs.Timezone
+	notif.Metadata["quiet_hours_deferred"] = true
+}
+
+// checkRecipientRateLimit rejects a notification whose recipient has reached
+// a rate limit.
+func (s *NotificationService) checkRecipientRateLimit(notif *models.Notification) *errors.Error {
+	if s.rateLimiter == nil {
+		return nil
+	}
+
+	allowed, rule, resetsAt := s.rateLimiter.AllowRecipient(notif.Channel, notif.Type, notif.Recipient)
+	if allowed {
+		return nil
+	}
+
+	log.Printf("[notification] Rate limit %s reached (channel=%s, type=%s, recipient=%s)",
+		rule, notif.Channel, notif.Type, notif.Recipient)
+	retryAfter := int(math.Ceil(time.Until(resetsAt).Seconds()))
+	return errors.TooManyRequests("too many notifications to this recipient, try again later").
+		AddDetail("rule", rule).
+		AddDetail("retry_after_seconds", max(retryAfter, 1))
+}
+
+// deferOverQuota takes provider quota for each notification and defers those
+// over their channel's quota to the next window. It returns the notifications
+// to deliver now.
+func (s *NotificationService) deferOverQuota(ctx context.Context, notifications []*models.Notification) []*models.Notification {
+	if s.rateLimiter == nil {
+		return notifications
+	}
+
+	deliver := notifications[:0]
+	for _, notif := range notifications {
+		allowed, nextWindow := s.rateLimiter.TakeProviderQuota(notif.Channel)
+		if allowed {
+			deliver = append(deliver, notif)
+			continue
+		}
+		if err := s.notifRepo.DeferQueued(ctx, notif.ID, nextWindow); err != nil {
+			log.Printf("[notification] Failed to defer notification %s over %s quota: %v", notif.ID, notif.Channel, err)
+			continue
+		}
+		log.Printf("[notification] Deferred notification %s to %s: %s provider quota used up",
+			notif.ID, nextWindow.Format(time.RFC3339), notif.Channel)
+	}
+	return deliver
+}
+
+// SetEventPublisher pushes in-app notifications to the user's notification
+// stream through the gateway as they are delivered.
+func (s *NotificationService) SetEventPublisher(publisher *events.Publisher) {
+	s.publisher = publisher
+	s.simEngine.OnDelivered(s.publishInApp)
+}
+
+// publishInApp publishes a delivered in-app notification to its user.
+func (s *NotificationService) publishInApp(ctx context.Context, notif *models.Notification) {
+	if notif.Channel != models.ChannelInApp || notif.UserID == nil {
+		return
+	}
+
+	// The count is informational; the event still goes out without it
+	unread, err := s.notifRepo.CountUnread(ctx, *notif.UserID)
+	if err != nil {
+		log.Printf("[notification] Failed to count unread notifications for user %s: %v", *notif.UserID, err)
+	}
+
+	s.publisher.PublishAsync("notifications", events.NotificationInApp{
+		NotificationID: notif.ID,
+		UserID:         *notif.UserID,
+		Type:           string(notif.Type),
+		Priority:       string(notif.Priority),
+		Subject:        notif.Subject,
+		Body:           notif.Body,
+		CreatedAt:      notif.CreatedAt.Format(time.RFC3339),
+		UnreadCount:    unread,
+	}, *notif.UserID)
+}
+
+// applySendingDomain resolves the sending domain for the tenant in metadata and
+// records the from, bounce and domain on the notification. Sending through an
+// unverified domain is rejected, mirroring provider deliverability checks.
+func (s *NotificationService) applySendingDomain(ctx context.Context, notifType models.NotificationType, metadata map[string]interface{}) *errors.Error {
+	if s.domainService == nil {
+		return nil
+	}
+
+	tenant, _ := metadata["tenant"].(string)
+	domain, err := s.domainService.ResolveSender(ctx, tenant)
+	if err != nil {
+		return err
+	}
+	if domain == nil {
+		return nil
+	}
+
+	if !domain.IsVerified() {
+		return errors.Validation(fmt.Sprintf("sending domain %s is not verified", domain.Domain))
+	}
+
+	metadata["sending_domain"] = domain.Domain
+	metadata["from_address"] = domain.FromAddressFor(notifType)
+	metadata["bounce_address"] = domain.BounceAddress
+	return nil
+}
+
 // GetNotification retrieves a notification by ID.
 func (s *NotificationService) GetNotification(ctx context.Context, id string) (*models.Notification, *errors.Error) {
-	return s.notifRepo.GetByID(ctx, id)
+	notif, err := s.notifRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notif.Channel == models.ChannelEmail {
+		if notif.Attachments, err = s.notifRepo.ListAttachments(ctx, id, false); err != nil {
+			return nil, err
+		}
+	}
+	return notif, nil
 }
 
 // ListNotifications retrieves notifications with optional filters.
@@ -169,6 +528,28 @@ func (s *NotificationService) ListNotifications(ctx context.Context, req *models
 	}, nil
 }
 
+// GetRateLimitStatus returns the rate limits, overrides and live counters.
+func (s *NotificationService) GetRateLimitStatus() (*models.RateLimitStatus, *errors.Error) {
+	if s.rateLimiter == nil {
+		return nil, errors.Unavailable("rate limiting is not enabled")
+	}
+	return s.rateLimiter.Status(), nil
+}
+
+// OverrideRateLimit adjusts a rate limit counter or limit.
+func (s *NotificationService) OverrideRateLimit(req *models.OverrideRateLimitRequest) (*models.RateLimitStatus, *errors.Error) {
+	if s.rateLimiter == nil {
+		return nil, errors.Unavailable("rate limiting is not enabled")
+	}
+	if err := s.rateLimiter.Override(req); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Rate limit override applied (scope=%s, rule=%s, channel=%s, recipient=%s)",
+		req.Scope, req.Rule, req.Channel, req.Recipient)
+	return s.rateLimiter.Status(), nil
+}
+
 // GetStats retrieves notification statistics.
 func (s *NotificationService) GetStats(ctx context.Context) (*models.NotificationStats, *errors.Error) {
 	return s.notifRepo.GetStats(ctx)
@@ -182,31 +563,158 @@ func (s *NotificationService) ProcessQueuedNotifications(ctx context.Context, ba
 		return err
 	}
 
+	// Higher priorities come first, so they take the quota first
+	notifications = s.deferOverQuota(ctx, notifications)
 	if len(notifications) == 0 {
 		return nil
 	}
 
 	log.Printf("[notification] Processing %d queued notifications", len(notifications))
 
-	// Process each notification
-	for _, notif := range notifications {
-		// Process in goroutine for concurrency (in production, use worker pool)
-		go func(n *models.Notification) {
-			if err := s.simEngine.ProcessNotification(ctx, n); err != nil {
-				log.Printf("[notification] Error processing notification %s: %v", n.ID, err)
+	if s.pool == nil {
+		for _, notif := range notifications {
+			if err := s.simEngine.ProcessNotification(ctx, notif); err != nil {
+				log.Printf("[notification] Error processing notification %s: %v", notif.ID, err)
 			}
-		}(notif)
+		}
+		return nil
+	}
+
+	// The batch finishes before the next poll, so a notification still being
+	// delivered is never picked up twice
+	jobs := make([]workerpool.Job, len(notifications))
+	for i, notif := range notifications {
+		jobs[i] = func(jobCtx context.Context) error {
+			if err := s.simEngine.ProcessNotification(jobCtx, notif); err != nil {
+				return fmt.Errorf("notification %s: %w", notif.ID, err)
+			}
+			return nil
+		}
+	}
+	if err := s.pool.RunAll(ctx, jobs...); err != nil {
+		// Each failure is logged by the pool; failed deliveries are retried
+		log.Printf("[notification] Batch of %d notifications finished with errors", len(notifications))
 	}
 
 	return nil
 }
 
+// ReleaseScheduledNotifications queues scheduled notifications that are due
+// (called by background worker). The regular processor then delivers them.
+func (s *NotificationService) ReleaseScheduledNotifications(ctx context.Context, batchSize int) (int, *errors.Error) {
+	released, err := s.notifRepo.ReleaseDueScheduled(ctx, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	if released > 0 {
+		log.Printf("[notification] Released %d scheduled notifications", released)
+	}
+	return released, nil
+}
+
+// CancelScheduledNotification cancels a notification before its send time.
+func (s *NotificationService) CancelScheduledNotification(ctx context.Context, id string) (*models.Notification, *errors.Error) {
+	if err := s.notifRepo.CancelScheduled(ctx, id); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Cancelled scheduled notification %s", id)
+	return s.notifRepo.GetByID(ctx, id)
+}
+
+// ListScheduledNotifications lists a user's upcoming deliveries, soonest first.
+func (s *NotificationService) ListScheduledNotifications(ctx context.Context, userID string, limit, offset int) (*models.ListNotificationsResponse, *errors.Error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, total, err := s.notifRepo.ListScheduledByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ListNotificationsResponse{
+		Notifications: notifications,
+		Total:         total,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+// GetInbox lists a user's delivered in-app notifications, newest first, with
+// their unread count.
+func (s *NotificationService) GetInbox(ctx context.Context, userID string, view models.InboxView, limit, offset int) (*models.InboxResponse, *errors.Error) {
+	if view == "" {
+		view = models.InboxViewInbox
+	}
+	if !view.IsValid() {
+		return nil, errors.Validation(fmt.Sprintf("unknown inbox view %q (use inbox, unread, archived or all)", view))
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, total, err := s.notifRepo.ListInbox(ctx, userID, view, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notifRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.InboxResponse{
+		Notifications: notifications,
+		Total:         total,
+		UnreadCount:   unread,
+		View:          view,
+		Limit:         limit,
+		Offset:        offset,
+	}, nil
+}
+
+// SetInboxRead marks one of a user's in-app notifications read or unread.
+func (s *NotificationService) SetInboxRead(ctx context.Context, userID, id string, read bool) (*models.Notification, *errors.Error) {
+	return s.notifRepo.SetRead(ctx, userID, id, read)
+}
+
+// SetInboxArchived archives or restores one of a user's in-app notifications.
+// Archived notifications leave the default inbox view and the unread count.
+func (s *NotificationService) SetInboxArchived(ctx context.Context, userID, id string, archived bool) (*models.Notification, *errors.Error) {
+	return s.notifRepo.SetArchived(ctx, userID, id, archived)
+}
+
+// MarkAllInboxRead marks all of a user's unread in-app notifications read.
+func (s *NotificationService) MarkAllInboxRead(ctx context.Context, userID string) (*models.MarkAllReadResponse, *errors.Error) {
+	updated, err := s.notifRepo.MarkAllRead(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notifRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MarkAllReadResponse{Updated: updated, UnreadCount: unread}, nil
+}
+
 // CreateTemplate creates a new notification template.
 func (s *NotificationService) CreateTemplate(ctx context.Context, req *models.CreateTemplateRequest) (*models.NotificationTemplate, *errors.Error) {
 	metadata, err := req.GetMetadata()
 	if err != nil {
 		return nil, errors.Validation("invalid metadata JSON")
 	}
+	if err := s.templateEngine.lintTemplateContent(templateNotificationType(metadata), req.SubjectTemplate, req.BodyTemplate); err != nil {
+		return nil, err
+	}
 
 	template := &models.NotificationTemplate{
 		ID:              uuid.New().String(),
@@ -242,13 +750,49 @@ func (s *NotificationService) ListTemplates(ctx context.Context, channel *models
 	return s.templateRepo.List(ctx, channel)
 }
 
-// UpdateTemplate updates an existing template.
+// UpdateTemplate updates an existing template. The resulting content is
+// linted against the template's notification type, taking a new type from
+// the request's metadata.
 func (s *NotificationService) UpdateTemplate(ctx context.Context, id string, req *models.UpdateTemplateRequest) *errors.Error {
+	metadata, parseErr := req.GetMetadata()
+	if parseErr != nil {
+		return errors.Validation("invalid metadata JSON")
+	}
+
+	if req.SubjectTemplate != nil || req.BodyTemplate != nil || metadata != nil {
+		existing, err := s.templateRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		subjectTemplate, bodyTemplate := existing.SubjectTemplate, existing.BodyTemplate
+		if req.SubjectTemplate != nil {
+			subjectTemplate = *req.SubjectTemplate
+		}
+		if req.BodyTemplate != nil {
+			bodyTemplate = *req.BodyTemplate
+		}
+		notifType := templateNotificationType(existing.Metadata)
+		if metadata != nil {
+			notifType = templateNotificationType(metadata)
+		}
+
+		if err := s.templateEngine.lintTemplateContent(notifType, subjectTemplate, bodyTemplate); err != nil {
+			return err
+		}
+	}
+
 	return s.templateRepo.Update(ctx, id, req)
 }
 
+// ValidateTemplate lints template content without saving it.
+func (s *NotificationService) ValidateTemplate(req *models.ValidateTemplateRequest) *models.TemplateValidationResult {
+	return s.templateEngine.LintTemplate(req.NotificationType, req.SubjectTemplate, req.BodyTemplate)
+}
+
 // PreviewTemplate renders a template with provided variables (for testing).
-func (s *NotificationService) PreviewTemplate(ctx context.Context, templateID string, variables map[string]interface{}) (*models.PreviewTemplateResponse, *errors.Error) {
+// With a locale, it renders the translation that locale would receive.
+func (s *NotificationService) PreviewTemplate(ctx context.Context, templateID string, variables map[string]interface{}, locale string) (*models.PreviewTemplateResponse, *errors.Error) {
 	// Look up by ID if valid UUID, otherwise by name
 	var template *models.NotificationTemplate
 	var err *errors.Error
@@ -263,14 +807,31 @@ func (s *NotificationService) PreviewTemplate(ctx context.Context, templateID st
 		return nil, err
 	}
 
+	if locale != "" {
+		normalized, ok := NormalizeLocale(locale)
+		if !ok {
+			return nil, errors.BadRequest("locale must be a language code with an optional region, e.g. hi or hi-IN")
+		}
+		locale = normalized
+	}
+
+	subjectTemplate, bodyTemplate := template.SubjectTemplate, template.BodyTemplate
+	renderedLocale := DefaultLocale
+	if s.translations != nil {
+		subjectTemplate, bodyTemplate, renderedLocale, err = s.translations.Localize(ctx, template.ID, locale, subjectTemplate, bodyTemplate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var subject string
 	var subjectVars []string
 
-	if template.SubjectTemplate != "" {
-		subject, subjectVars = s.templateEngine.Render(template.SubjectTemplate, variables)
+	if subjectTemplate != "" {
+		subject, subjectVars = s.templateEngine.RenderLocale(subjectTemplate, variables, renderedLocale)
 	}
 
-	body, bodyVars := s.templateEngine.Render(template.BodyTemplate, variables)
+	body, bodyVars := s.templateEngine.RenderLocale(bodyTemplate, variables, renderedLocale)
 
 	// Combine used variables
 	allVars := append(subjectVars, bodyVars...)
@@ -278,18 +839,89 @@ func (s *NotificationService) PreviewTemplate(ctx context.Context, templateID st
 	return &models.PreviewTemplateResponse{
 		Subject:      subject,
 		Body:         body,
+		Locale:       renderedLocale,
 		RenderedAt:   sharedModels.Now(),
 		VariableUsed: allVars,
 	}, nil
 }
 
+// ApplyStatusCallbacks applies a batch of provider delivery receipts. Receipts
+// are independent: a malformed or unmatched receipt is reported in the results
+// without failing the batch, and replaying a batch is safe.
+func (s *NotificationService) ApplyStatusCallbacks(ctx context.Context, req *models.StatusCallbackRequest) (*models.StatusCallbackResponse, *errors.Error) {
+	if len(req.Receipts) == 0 {
+		return nil, errors.BadRequest("at least one receipt is required")
+	}
+	if len(req.Receipts) > models.MaxStatusCallbackBatch {
+		return nil, errors.BadRequest(fmt.Sprintf("at most %d receipts can be sent at once", models.MaxStatusCallbackBatch))
+	}
+
+	resp := &models.StatusCallbackResponse{
+		Received: len(req.Receipts),
+		Outcomes: make(map[models.ReceiptOutcome]int),
+		Results:  make([]*models.ReceiptResult, 0, len(req.Receipts)),
+	}
+
+	for i := range req.Receipts {
+		receipt := &req.Receipts[i]
+		result := &models.ReceiptResult{MessageID: receipt.MessageID}
+
+		if detail := validateReceipt(receipt); detail != "" {
+			result.Outcome = models.ReceiptInvalid
+			result.Detail = detail
+		} else {
+			outcome, notificationID, status, err := s.notifRepo.ApplyStatusReceipt(ctx, receipt)
+			if err != nil {
+				return nil, err
+			}
+			result.Outcome = outcome
+			result.NotificationID = notificationID
+			result.Status = status
+		}
+
+		resp.Outcomes[result.Outcome]++
+		resp.Results = append(resp.Results, result)
+	}
+
+	log.Printf("[notification] Applied status callback from %q: %d receipts, %d applied",
+		req.Provider, resp.Received, resp.Outcomes[models.ReceiptApplied])
+	return resp, nil
+}
+
+// validateReceipt returns why a receipt cannot be applied, or "" if it can.
+func validateReceipt(receipt *models.StatusReceipt) string {
+	if receipt.MessageID == "" {
+		return "message_id is required"
+	}
+	if len(receipt.MessageID) > 255 {
+		return "message_id must be at most 255 characters"
+	}
+	switch receipt.Status {
+	case models.StatusSent, models.StatusDelivered, models.StatusFailed:
+	default:
+		return "status must be sent, delivered or failed"
+	}
+	if receipt.Timestamp.IsZero() {
+		return "timestamp is required"
+	}
+	if receipt.NotificationID != nil {
+		if _, err := uuid.Parse(*receipt.NotificationID); err != nil {
+			return "notification_id must be a UUID"
+		}
+	}
+	return ""
+}
+
 // ReplayNotification re-queues a failed or delivered notification for testing.
 func (s *NotificationService) ReplayNotification(ctx context.Context, id string) *errors.Error {
 	// Check if notification exists
-	_, err := s.notifRepo.GetByID(ctx, id)
+	notif, err := s.notifRepo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	if notif.IsScheduled() {
+		return errors.Conflict("scheduled notifications are sent at their send time; cancel them instead")
+	}
 
 	// Reset to queued status
 	if err := s.notifRepo.UpdateStatus(ctx, id, models.StatusQueued, nil); err != nil {
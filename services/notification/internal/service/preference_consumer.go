@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+// preferencesEventSource is the service expected to publish preference changes.
+const preferencesEventSource = "identity"
+
+// preferenceConsumerName identifies the consumer's offset.
+const preferenceConsumerName = "notification-preferences"
+
+// PreferenceConsumer keeps replicated user preferences current by following
+// user.preferences_updated events on the gateway's event stream.
+type PreferenceConsumer struct {
+	consumer *events.Consumer
+	prefRepo *repository.PreferenceRepository
+}
+
+// NewPreferenceConsumer creates a consumer reading the gateway at gatewayURL
+// and resuming from the offset kept in offsets.
+func NewPreferenceConsumer(gatewayURL string, prefRepo *repository.PreferenceRepository, offsets events.OffsetStore) *PreferenceConsumer {
+	c := &PreferenceConsumer{
+		consumer: events.NewConsumer(events.ConsumerConfig{
+			GatewayURL: gatewayURL,
+			Name:       preferenceConsumerName,
+			Topics:     []string{"users"},
+			Offsets:    offsets,
+		}),
+		prefRepo: prefRepo,
+	}
+	events.HandleTyped(c.consumer, c.handle)
+	return c
+}
+
+// Run follows the event stream until ctx ends, reconnecting after errors and
+// resuming after the last applied event. Updates are applied only when newer
+// than the stored preferences, so redelivered events are harmless.
+func (c *PreferenceConsumer) Run(ctx context.Context) {
+	c.consumer.Run(ctx)
+}
+
+// handle applies one preference event.
+func (c *PreferenceConsumer) handle(ctx context.Context, event events.Event, payload events.UserPreferencesUpdated) error {
+	if source, _ := event.Data["service"].(string); source != preferencesEventSource {
+		log.Printf("[notification] Ignoring %s event from %q", event.Type, source)
+		return nil
+	}
+
+	prefs, err := preferencesFromEvent(&payload)
+	if err != nil {
+		return retry.Permanent(err)
+	}
+
+	applied, appErr := c.prefRepo.Upsert(ctx, prefs)
+	if appErr != nil {
+		return fmt.Errorf("failed to save preferences for user %s: %w", prefs.UserID, appErr)
+	}
+	if applied {
+		log.Printf("[notification] Updated preferences for user %s", prefs.UserID)
+	}
+	return nil
+}
+
+// preferencesFromEvent converts an event payload to stored preferences.
+func preferencesFromEvent(payload *events.UserPreferencesUpdated) (*models.UserPreferences, error) {
+	if payload.UserID == "" {
+		return nil, fmt.Errorf("user_id is required")
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, payload.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid updated_at %q", payload.UpdatedAt)
+	}
+
+	optional := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	return &models.UserPreferences{
+		UserID:           payload.UserID,
+		Locale:           optional(payload.Locale),
+		Timezone:         optional(payload.Timezone),
+		DisplayCurrency:  optional(payload.DisplayCurrency),
+		MarketingConsent: payload.MarketingConsent != nil && *payload.MarketingConsent,
+		QuietHoursStart:  optional(payload.QuietHoursStart),
+		QuietHoursEnd:    optional(payload.QuietHoursEnd),
+		UpdatedAt:        sharedModels.NewTimestamp(updatedAt),
+	}, nil
+}
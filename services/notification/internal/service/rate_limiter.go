@@ -0,0 +1,327 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const (
+	// defaultOverrideDuration applies to overrides without an expiry.
+	defaultOverrideDuration = time.Hour
+	// pruneThreshold is the counter count above which expired windows are dropped.
+	pruneThreshold = 10000
+)
+
+var rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "notification_rate_limited_total",
+	Help: "Notifications rejected by a recipient rate limit or deferred by a provider quota",
+}, []string{"scope", "channel", "rule"})
+
+// DefaultRecipientRateLimits stops a runaway caller from flooding one person.
+func DefaultRecipientRateLimits() []models.RecipientRateLimit {
+	return []models.RecipientRateLimit{
+		{Name: "otp-sms", Channel: models.ChannelSMS, Type: models.TypeOTP, Limit: 3, Window: 10 * time.Minute},
+		{Name: "otp-email", Channel: models.ChannelEmail, Type: models.TypeOTP, Limit: 5, Window: 10 * time.Minute},
+		{Name: "sms", Channel: models.ChannelSMS, Limit: 20, Window: time.Hour},
+	}
+}
+
+// DefaultProviderQuotas caps deliveries per minute on each paid channel.
+func DefaultProviderQuotas() []models.ProviderQuota {
+	return []models.ProviderQuota{
+		{Channel: models.ChannelSMS, Limit: 300, Window: time.Minute},
+		{Channel: models.ChannelEmail, Limit: 1000, Window: time.Minute},
+		{Channel: models.ChannelPush, Limit: 1000, Window: time.Minute},
+	}
+}
+
+// RateLimitConfig configures a RateLimiter.
+type RateLimitConfig struct {
+	RecipientLimits []models.RecipientRateLimit
+	ProviderQuotas  []models.ProviderQuota
+}
+
+// DefaultRateLimitConfig returns the default limits and quotas.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RecipientLimits: DefaultRecipientRateLimits(),
+		ProviderQuotas:  DefaultProviderQuotas(),
+	}
+}
+
+// rateWindow counts events in one fixed window, which starts at the first event.
+type rateWindow struct {
+	counter models.RateLimitCounter
+	start   time.Time
+	window  time.Duration
+}
+
+func (w *rateWindow) expired(now time.Time) bool {
+	return !now.Before(w.start.Add(w.window))
+}
+
+// RateLimiter enforces per-recipient limits and provider quotas. Counters are
+// held in memory, so each instance enforces its own share.
+type RateLimiter struct {
+	mu              sync.Mutex
+	recipientLimits []models.RecipientRateLimit
+	quotas          map[models.NotificationChannel]models.ProviderQuota
+	counters        map[string]*rateWindow
+	overrides       map[string]models.RateLimitOverride
+	now             func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter. Quotas with a zero limit are ignored.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	quotas := make(map[models.NotificationChannel]models.ProviderQuota)
+	for _, q := range cfg.ProviderQuotas {
+		if q.Limit > 0 && q.Window > 0 {
+			quotas[q.Channel] = q
+		}
+	}
+	return &RateLimiter{
+		recipientLimits: append([]models.RecipientRateLimit(nil), cfg.RecipientLimits...),
+		quotas:          quotas,
+		counters:        make(map[string]*rateWindow),
+		overrides:       make(map[string]models.RateLimitOverride),
+		now:             time.Now,
+	}
+}
+
+func recipientKey(rule, recipient string) string {
+	return "recipient:" + rule + ":" + recipient
+}
+
+func providerKey(channel models.NotificationChannel) string {
+	return "provider:" + string(channel)
+}
+
+func overrideKey(scope models.RateLimitScope, name string) string {
+	return string(scope) + ":" + name
+}
+
+// window returns the live window for key, starting a new one if needed.
+// Callers hold mu.
+func (l *RateLimiter) window(key string, counter models.RateLimitCounter, length time.Duration, now time.Time) *rateWindow {
+	w, ok := l.counters[key]
+	if !ok || w.expired(now) {
+		if len(l.counters) >= pruneThreshold {
+			l.prune(now)
+		}
+		w = &rateWindow{counter: counter, start: now, window: length}
+		l.counters[key] = w
+	}
+	return w
+}
+
+// limitFor returns the limit in force, honoring an unexpired override.
+// Callers hold mu.
+func (l *RateLimiter) limitFor(scope models.RateLimitScope, name string, limit int, now time.Time) int {
+	key := overrideKey(scope, name)
+	o, ok := l.overrides[key]
+	if !ok {
+		return limit
+	}
+	if !now.Before(o.ExpiresAt) {
+		delete(l.overrides, key)
+		return limit
+	}
+	return o.Limit
+}
+
+// AllowRecipient counts a notification against every limit matching it. When
+// any limit is reached nothing is counted, and the rule and the time it resets
+// are returned.
+func (l *RateLimiter) AllowRecipient(channel models.NotificationChannel, notifType models.NotificationType, recipient string) (bool, string, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	windows := make([]*rateWindow, 0, len(l.recipientLimits))
+	for _, rule := range l.recipientLimits {
+		if !rule.Matches(channel, notifType) {
+			continue
+		}
+		w := l.window(recipientKey(rule.Name, recipient), models.RateLimitCounter{
+			Scope:     models.ScopeRecipient,
+			Rule:      rule.Name,
+			Channel:   channel,
+			Recipient: recipient,
+		}, rule.Window, now)
+		if w.counter.Count >= l.limitFor(models.ScopeRecipient, rule.Name, rule.Limit, now) {
+			rateLimited.WithLabelValues(string(models.ScopeRecipient), string(channel), rule.Name).Inc()
+			return false, rule.Name, w.start.Add(w.window)
+		}
+		windows = append(windows, w)
+	}
+
+	for _, w := range windows {
+		w.counter.Count++
+	}
+	return true, "", time.Time{}
+}
+
+// TakeProviderQuota counts one delivery on channel. When the quota is used up
+// it returns false and when the next window opens.
+func (l *RateLimiter) TakeProviderQuota(channel models.NotificationChannel) (bool, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	quota, ok := l.quotas[channel]
+	if !ok {
+		return true, time.Time{}
+	}
+	w := l.window(providerKey(channel), models.RateLimitCounter{
+		Scope:   models.ScopeProvider,
+		Channel: channel,
+	}, quota.Window, now)
+	if w.counter.Count >= l.limitFor(models.ScopeProvider, string(channel), quota.Limit, now) {
+		rateLimited.WithLabelValues(string(models.ScopeProvider), string(channel), "").Inc()
+		return false, w.start.Add(w.window)
+	}
+	w.counter.Count++
+	return true, time.Time{}
+}
+
+// prune drops expired windows. Callers hold mu.
+func (l *RateLimiter) prune(now time.Time) {
+	for key, w := range l.counters {
+		if w.expired(now) {
+			delete(l.counters, key)
+		}
+	}
+}
+
+// Status returns the configured limits, active overrides and live counters.
+func (l *RateLimiter) Status() *models.RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+
+	status := &models.RateLimitStatus{
+		RecipientLimits: append([]models.RecipientRateLimit(nil), l.recipientLimits...),
+		ProviderQuotas:  make([]models.ProviderQuota, 0, len(l.quotas)),
+		Overrides:       make([]models.RateLimitOverride, 0, len(l.overrides)),
+		Counters:        make([]models.RateLimitCounter, 0, len(l.counters)),
+	}
+	for _, q := range l.quotas {
+		status.ProviderQuotas = append(status.ProviderQuotas, q)
+	}
+	for key, o := range l.overrides {
+		if !now.Before(o.ExpiresAt) {
+			delete(l.overrides, key)
+			continue
+		}
+		status.Overrides = append(status.Overrides, o)
+	}
+	for _, w := range l.counters {
+		counter := w.counter
+		counter.ResetsAt = w.start.Add(w.window)
+		counter.Limit = l.effectiveLimit(counter, now)
+		status.Counters = append(status.Counters, counter)
+	}
+
+	sort.Slice(status.ProviderQuotas, func(i, j int) bool {
+		return status.ProviderQuotas[i].Channel < status.ProviderQuotas[j].Channel
+	})
+	sort.Slice(status.Counters, func(i, j int) bool {
+		if status.Counters[i].Count != status.Counters[j].Count {
+			return status.Counters[i].Count > status.Counters[j].Count // Busiest first
+		}
+		return status.Counters[i].ResetsAt.Before(status.Counters[j].ResetsAt)
+	})
+	return status
+}
+
+// effectiveLimit returns the limit in force for a counter. Callers hold mu.
+func (l *RateLimiter) effectiveLimit(counter models.RateLimitCounter, now time.Time) int {
+	if counter.Scope == models.ScopeProvider {
+		return l.limitFor(models.ScopeProvider, string(counter.Channel), l.quotas[counter.Channel].Limit, now)
+	}
+	if rule, ok := l.recipientRule(counter.Rule); ok {
+		return l.limitFor(models.ScopeRecipient, rule.Name, rule.Limit, now)
+	}
+	return 0
+}
+
+func (l *RateLimiter) recipientRule(name string) (models.RecipientRateLimit, bool) {
+	for _, rule := range l.recipientLimits {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return models.RecipientRateLimit{}, false
+}
+
+// Override sets a counter's count in its current window and/or replaces the
+// limit of a rule or quota until the override expires.
+func (l *RateLimiter) Override(req *models.OverrideRateLimitRequest) *errors.Error {
+	if req.Count == nil && req.Limit == nil {
+		return errors.Validation("count or limit is required")
+	}
+	if (req.Count != nil && *req.Count < 0) || (req.Limit != nil && *req.Limit < 0) {
+		return errors.Validation("count and limit must not be negative")
+	}
+	if req.ExpiresInSeconds < 0 {
+		return errors.Validation("expires_in_seconds must not be negative")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	var name, key string
+	var counter models.RateLimitCounter
+	var override models.RateLimitOverride
+	var length time.Duration
+
+	switch req.Scope {
+	case models.ScopeRecipient:
+		rule, ok := l.recipientRule(req.Rule)
+		if !ok {
+			return errors.NotFoundWithID("recipient rate limit", req.Rule)
+		}
+		if req.Count != nil && req.Recipient == "" {
+			return errors.Validation("recipient is required to set a recipient counter")
+		}
+		name, length = rule.Name, rule.Window
+		key = recipientKey(rule.Name, req.Recipient)
+		counter = models.RateLimitCounter{Scope: models.ScopeRecipient, Rule: rule.Name, Channel: rule.Channel, Recipient: req.Recipient}
+		override = models.RateLimitOverride{Scope: models.ScopeRecipient, Rule: rule.Name, Channel: rule.Channel}
+	case models.ScopeProvider:
+		quota, ok := l.quotas[req.Channel]
+		if !ok {
+			return errors.NotFoundWithID("provider quota", string(req.Channel))
+		}
+		name, length = string(quota.Channel), quota.Window
+		key = providerKey(quota.Channel)
+		counter = models.RateLimitCounter{Scope: models.ScopeProvider, Channel: quota.Channel}
+		override = models.RateLimitOverride{Scope: models.ScopeProvider, Channel: quota.Channel}
+	default:
+		return errors.Validation(fmt.Sprintf("scope must be %s or %s", models.ScopeRecipient, models.ScopeProvider))
+	}
+
+	if req.Count != nil {
+		l.window(key, counter, length, now).counter.Count = *req.Count
+	}
+	if req.Limit != nil {
+		expiresIn := defaultOverrideDuration
+		if req.ExpiresInSeconds > 0 {
+			expiresIn = time.Duration(req.ExpiresInSeconds) * time.Second
+		}
+		override.Limit = *req.Limit
+		override.ExpiresAt = now.Add(expiresIn)
+		l.overrides[overrideKey(req.Scope, name)] = override
+	}
+	return nil
+}
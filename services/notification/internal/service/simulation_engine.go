@@ -8,6 +8,7 @@ import (
 
 	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/retry"
 )
 
 // SimulationConfig holds configuration for the notification simulation engine.
@@ -33,18 +34,34 @@ func DefaultSimulationConfig() SimulationConfig {
 
 // SimulationEngine simulates notification delivery with realistic behavior.
 type SimulationEngine struct {
-	config SimulationConfig
-	repo   NotificationRepositoryInterface
-	rand   *rand.Rand
+	config          SimulationConfig
+	repo            NotificationRepositoryInterface
+	rand            *rand.Rand
+	deliveryBackoff retry.Policy // Backoff between delivery attempts of a failed notification
+	statusRetry     retry.Policy // Retries for status writes hitting transient database errors
+	onDelivered     func(ctx context.Context, notif *models.Notification)
+	webhooks        *WebhookSender     // Delivers webhook notifications for real when set
+	email           EmailProvider      // Receives email notifications with their attachments
+	tracker         *EngagementTracker // Adds open and click tracking to HTML emails when set
 }
 
 // NewSimulationEngine creates a new simulation engine.
 func NewSimulationEngine(config SimulationConfig, repo NotificationRepositoryInterface) *SimulationEngine {
+	statusRetry := retry.DefaultPolicy()
+	statusRetry.Retryable = isTransientRepoError
+
 	return &SimulationEngine{
 		config: config,
 		repo:   repo,
 		//nolint:gosec // Using math/rand for simulation randomness, not cryptographic security
 		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		deliveryBackoff: retry.Policy{
+			MaxAttempts:  config.MaxRetryAttempts + 1,
+			InitialDelay: time.Duration(config.RetryDelayMs) * time.Millisecond,
+			Multiplier:   2,
+		},
+		statusRetry: statusRetry,
+		email:       simulatedEmailProvider{},
 	}
 }
 
@@ -54,11 +71,22 @@ func (e *SimulationEngine) ProcessNotification(ctx context.Context, notif *model
 	log.Printf("[simulation] Processing notification %s (type=%s, channel=%s, priority=%s)",
 		notif.ID, notif.Type, notif.Channel, notif.Priority)
 
+	if notif.Channel == models.ChannelWebhook && e.webhooks != nil {
+		return e.deliverWebhook(ctx, notif)
+	}
+
 	// Step 1: Simulate network delay before sending
 	time.Sleep(time.Duration(e.config.DeliveryDelayMs) * time.Millisecond)
 
+	// Emails go through the provider with their attachments
+	if notif.Channel == models.ChannelEmail {
+		if sent, err := e.sendEmail(ctx, notif); !sent {
+			return err
+		}
+	}
+
 	// Update status to 'sent'
-	if err := e.repo.UpdateStatus(ctx, notif.ID, models.StatusSent, nil); err != nil {
+	if err := e.updateStatus(ctx, notif.ID, models.StatusSent, nil); err != nil {
 		log.Printf("[simulation] Failed to update notification %s to sent: %v", notif.ID, err)
 		return err
 	}
@@ -74,7 +102,7 @@ func (e *SimulationEngine) ProcessNotification(ctx context.Context, notif *model
 	if shouldFail {
 		// Simulate failure
 		failureReason := e.generateFailureReason(notif.Channel)
-		if err := e.repo.UpdateStatus(ctx, notif.ID, models.StatusFailed, &failureReason); err != nil {
+		if err := e.updateStatus(ctx, notif.ID, models.StatusFailed, &failureReason); err != nil {
 			log.Printf("[simulation] Failed to update notification %s to failed: %v", notif.ID, err)
 			return err
 		}
@@ -93,15 +121,29 @@ func (e *SimulationEngine) ProcessNotification(ctx context.Context, notif *model
 	}
 
 	// Simulate successful delivery
-	if err := e.repo.UpdateStatus(ctx, notif.ID, models.StatusDelivered, nil); err != nil {
+	if err := e.updateStatus(ctx, notif.ID, models.StatusDelivered, nil); err != nil {
 		log.Printf("[simulation] Failed to update notification %s to delivered: %v", notif.ID, err)
 		return err
 	}
 
 	log.Printf("[simulation] Notification %s marked as delivered successfully", notif.ID)
+	if e.onDelivered != nil {
+		e.onDelivered(ctx, notif)
+	}
 	return nil
 }
 
+// OnDelivered registers fn to run after each successful delivery.
+func (e *SimulationEngine) OnDelivered(fn func(ctx context.Context, notif *models.Notification)) {
+	e.onDelivered = fn
+}
+
+// SetWebhookSender delivers webhook notifications to their registered
+// endpoints instead of simulating them.
+func (e *SimulationEngine) SetWebhookSender(sender *WebhookSender) {
+	e.webhooks = sender
+}
+
 // shouldSimulateFailure determines if the current notification should fail.
 func (e *SimulationEngine) shouldSimulateFailure() bool {
 	if e.config.FailureRatePercent <= 0 {
@@ -144,6 +186,13 @@ func (e *SimulationEngine) generateFailureReason(channel models.NotificationChan
 			"Storage quota exceeded",
 			"Database write error",
 		},
+		models.ChannelWebhook: {
+			"Endpoint returned HTTP 500",
+			"Endpoint returned HTTP 404",
+			"Connection refused",
+			"TLS handshake failed",
+			"Request timed out",
+		},
 	}
 
 	channelReasons, ok := reasons[channel]
@@ -155,12 +204,34 @@ func (e *SimulationEngine) generateFailureReason(channel models.NotificationChan
 	return channelReasons[e.rand.Intn(len(channelReasons))]
 }
 
+// updateStatus writes a status change, retrying transient database errors so
+// a brief outage does not leave a delivered notification marked as sent.
+func (e *SimulationEngine) updateStatus(ctx context.Context, id string, status models.NotificationStatus, failureReason *string) *errors.Error {
+	err := retry.Do(ctx, e.statusRetry, func(ctx context.Context) error {
+		if err := e.repo.UpdateStatus(ctx, id, status, failureReason); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err == nil {
+		return nil
+	}
+	if appErr, ok := err.(*errors.Error); ok {
+		return appErr
+	}
+	return errors.Wrap(err, errors.ErrCodeInternal, "failed to update notification status")
+}
+
+// isTransientRepoError reports whether a repository error may succeed on retry.
+func isTransientRepoError(err error) bool {
+	code := errors.GetErrorCode(err)
+	return code == errors.ErrCodeDatabaseError || code == errors.ErrCodeTimeout
+}
+
 // CalculateRetryDelay calculates the delay before next retry using exponential backoff.
 func (e *SimulationEngine) CalculateRetryDelay(retryCount int) time.Duration {
 	// Exponential backoff: base * 2^retryCount
-	baseDelay := time.Duration(e.config.RetryDelayMs) * time.Millisecond
-	multiplier := 1 << retryCount // 2^retryCount
-	return baseDelay * time.Duration(multiplier)
+	return e.deliveryBackoff.Delay(retryCount + 1)
 }
 
 // GetProcessingDelay returns the total processing delay for a notification.
@@ -173,4 +244,5 @@ type NotificationRepositoryInterface interface {
 	UpdateStatus(ctx context.Context, id string, status models.NotificationStatus, failureReason *string) *errors.Error
 	IncrementRetryCount(ctx context.Context, id string) *errors.Error
 	GetQueuedNotifications(ctx context.Context, limit int) ([]*models.Notification, *errors.Error)
+	ListAttachments(ctx context.Context, notificationID string, withContent bool) ([]*models.NotificationAttachment, *errors.Error)
 }
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// implicitTemplateVariables are filled from the recipient's preferences, so
+// every template may use them. They render empty for users without
+// preferences.
+var implicitTemplateVariables = []models.TemplateVariable{
+	{Name: "locale", Description: "Recipient's preferred locale"},
+	{Name: "timezone", Description: "Recipient's preferred timezone"},
+	{Name: "display_currency", Description: "Recipient's preferred display currency"},
+}
+
+// required and optional declare contract variables.
+func required(name, description string) models.TemplateVariable {
+	return models.TemplateVariable{Name: name, Required: true, Description: description}
+}
+
+func optional(name, description string) models.TemplateVariable {
+	return models.TemplateVariable{Name: name, Description: description}
+}
+
+// templateContracts declares the variables each notification type's senders
+// supply. Types without a contract accept any variable, but every one a
+// template uses must be sent.
+var templateContracts = map[models.NotificationType][]models.TemplateVariable{
+	models.TypeOTP: {
+		required("otp", "One-time code"),
+		required("validity_minutes", "Minutes until the code expires"),
+		required("full_name", "Recipient's name"),
+	},
+	models.TypeTransactionAlert: {
+		required("amount", "Formatted amount"),
+		required("currency", "Currency code"),
+		required("transaction_id", "Transaction ID"),
+		required("transaction_type", "Transaction type, e.g. transfer"),
+		required("message", "Pre-composed alert text"),
+		required("wallet_id", "Wallet the alert is about"),
+		optional("balance", "Balance after the transaction"),
+		optional("date", "Transaction date"),
+		optional("description", "Transaction description"),
+		optional("user_name", "Recipient's name"),
+		optional("full_name", "Recipient's name"),
+	},
+	models.TypeAccountAlert: {
+		required("alert_type", "Kind of account alert"),
+		required("message", "Alert text"),
+		required("user_name", "Recipient's name"),
+		optional("date", "When it happened"),
+	},
+	models.TypeKYCUpdate: {
+		required("kyc_status", "New KYC status"),
+		required("status", "New KYC status"),
+		required("user_name", "Recipient's name"),
+		required("full_name", "Recipient's name"),
+		optional("reason", "Why KYC was rejected"),
+		optional("rejection_reason", "Why KYC was rejected"),
+	},
+	models.TypeWelcome: {
+		required("full_name", "Recipient's name"),
+		required("user_name", "Recipient's name"),
+		optional("admin_portal", "Admin portal URL, for admin accounts"),
+	},
+	models.TypeSecurityAlert: {
+		required("message", "Alert text"),
+		required("full_name", "Recipient's name"),
+		required("new_email", "Masked new email address"),
+		required("new_phone", "Masked new phone number"),
+		required("locked_minutes", "Minutes the account stays locked"),
+		required("unlock_url", "Link that unlocks the account"),
+		required("card_id", "Card the alert is about"),
+		required("wallet_id", "Wallet the alert is about"),
+	},
+	models.TypeWalletAlert: {
+		required("wallet_id", "Wallet ID"),
+		required("wallet_type", "Wallet type"),
+		required("currency", "Wallet currency"),
+		optional("message", "Alert text"),
+	},
+	models.TypeSystemAlert: {
+		required("message", "Alert text"),
+	},
+	models.TypeMarketing: {
+		required("message", "Promotion text"),
+		optional("full_name", "Recipient's name"),
+	},
+}
+
+// templateContract returns the variable contract of a notification type,
+// including the implicit variables.
+func templateContract(notifType models.NotificationType) (*models.VariableContract, bool) {
+	variables, ok := templateContracts[notifType]
+	if !ok {
+		return nil, false
+	}
+	all := make([]models.TemplateVariable, 0, len(variables)+len(implicitTemplateVariables))
+	all = append(all, variables...)
+	all = append(all, implicitTemplateVariables...)
+	return &models.VariableContract{NotificationType: notifType, Variables: all}, true
+}
+
+// TemplateContracts returns the variable contract of every notification
+// type, ordered by type.
+func TemplateContracts() []*models.VariableContract {
+	contracts := make([]*models.VariableContract, 0, len(templateContracts))
+	for notifType := range templateContracts {
+		contract, _ := templateContract(notifType)
+		contracts = append(contracts, contract)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].NotificationType < contracts[j].NotificationType
+	})
+	return contracts
+}
+
+// LintTemplate checks template content for malformed placeholders and, when
+// notifType is set, for variables outside its contract.
+func (e *TemplateEngine) LintTemplate(notifType models.NotificationType, subjectTemplate, bodyTemplate string) *models.TemplateValidationResult {
+	result := &models.TemplateValidationResult{
+		NotificationType: notifType,
+		Variables:        make([]string, 0),
+		Issues:           make([]models.TemplateIssue, 0),
+	}
+
+	var contract *models.VariableContract
+	if notifType != "" {
+		var ok bool
+		if contract, ok = templateContract(notifType); !ok {
+			result.Issues = append(result.Issues, models.TemplateIssue{
+				Field:    models.TemplateMetaNotificationType,
+				Severity: models.TemplateIssueError,
+				Code:     models.TemplateIssueUnknownType,
+				Message:  fmt.Sprintf("unknown notification type: %s", notifType),
+			})
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range []struct{ field, template string }{
+		{"subject_template", subjectTemplate},
+		{"body_template", bodyTemplate},
+	} {
+		result.Issues = append(result.Issues, e.lintSyntax(part.field, part.template)...)
+
+		for _, name := range e.ExtractVariables(part.template) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			result.Variables = append(result.Variables, name)
+
+			if contract == nil {
+				continue
+			}
+			declared, ok := contract.Variable(name)
+			switch {
+			case !ok:
+				result.Issues = append(result.Issues, models.TemplateIssue{
+					Field:    part.field,
+					Severity: models.TemplateIssueError,
+					Code:     models.TemplateIssueUnknownVariable,
+					Variable: name,
+					Message:  fmt.Sprintf("%s notifications do not supply {{%s}}", notifType, name),
+				})
+			case !declared.Required:
+				result.Issues = append(result.Issues, models.TemplateIssue{
+					Field:    part.field,
+					Severity: models.TemplateIssueWarning,
+					Code:     models.TemplateIssueOptionalVariable,
+					Variable: name,
+					Message:  fmt.Sprintf("{{%s}} is optional and renders empty when not sent", name),
+				})
+			}
+		}
+	}
+
+	result.Valid = true
+	for _, issue := range result.Issues {
+		if issue.Severity == models.TemplateIssueError {
+			result.Valid = false
+			break
+		}
+	}
+	return result
+}
+
+// lintSyntax reports placeholders the engine would leave unrendered.
+func (e *TemplateEngine) lintSyntax(field, template string) []models.TemplateIssue {
+	issues := make([]models.TemplateIssue, 0)
+
+	for _, match := range e.placeholderPattern.FindAllString(template, -1) {
+		if e.variablePattern.MatchString(match) {
+			continue
+		}
+		if plural := e.pluralPattern.FindStringSubmatch(match); plural != nil {
+			if _, ok := parsePluralForms(plural[2])[pluralOther]; !ok {
+				issues = append(issues, models.TemplateIssue{
+					Field:    field,
+					Severity: models.TemplateIssueError,
+					Code:     models.TemplateIssueMissingPluralOther,
+					Variable: plural[1],
+					Message:  fmt.Sprintf("plural on {{%s}} needs an other form", plural[1]),
+				})
+			}
+			continue
+		}
+		issues = append(issues, models.TemplateIssue{
+			Field:    field,
+			Severity: models.TemplateIssueError,
+			Code:     models.TemplateIssueMalformedPlaceholder,
+			Message:  fmt.Sprintf("%s is not a placeholder; use {{variable}} or {{plural:variable|...}}", match),
+		})
+	}
+
+	rest := e.placeholderPattern.ReplaceAllString(template, "")
+	if strings.Contains(rest, "{{") || strings.Contains(rest, "}}") {
+		issues = append(issues, models.TemplateIssue{
+			Field:    field,
+			Severity: models.TemplateIssueError,
+			Code:     models.TemplateIssueUnbalancedBraces,
+			Message:  "template has an unmatched {{ or }}",
+		})
+	}
+	return issues
+}
+
+// lintTemplateContent rejects template content with lint errors.
+func (e *TemplateEngine) lintTemplateContent(notifType models.NotificationType, subjectTemplate, bodyTemplate string) *errors.Error {
+	result := e.LintTemplate(notifType, subjectTemplate, bodyTemplate)
+	if result.Valid {
+		return nil
+	}
+
+	messages := make([]string, 0, len(result.Issues))
+	for _, issue := range result.Issues {
+		if issue.Severity == models.TemplateIssueError {
+			messages = append(messages, issue.Message)
+		}
+	}
+	return errors.Validation("template is invalid: "+strings.Join(messages, "; ")).
+		AddDetail("issues", result.Issues)
+}
+
+// templateNotificationType returns the notification type a template declares
+// in its metadata, if any.
+func templateNotificationType(metadata map[string]string) models.NotificationType {
+	return models.NotificationType(metadata[models.TemplateMetaNotificationType])
+}
+
+// completeTemplateVariables checks that a send supplies every variable the
+// rendered content uses. Optional contract variables that were left out
+// render empty; any other missing variable fails the send rather than
+// reaching the recipient as a raw placeholder. Plural counts are always
+// required.
+func (e *TemplateEngine) completeTemplateVariables(templateName string, notifType models.NotificationType, subjectTemplate, bodyTemplate string, variables map[string]interface{}) (map[string]interface{}, *errors.Error) {
+	contract, _ := templateContract(notifType)
+
+	plurals := make(map[string]bool)
+	for _, match := range e.pluralPattern.FindAllStringSubmatch(subjectTemplate+"\n"+bodyTemplate, -1) {
+		plurals[match[1]] = true
+	}
+
+	completed := variables
+	copied := false
+	missing := make([]string, 0)
+	for _, name := range e.Validate(subjectTemplate+"\n"+bodyTemplate, variables) {
+		if contract != nil && !plurals[name] {
+			if declared, ok := contract.Variable(name); ok && !declared.Required {
+				if !copied {
+					completed = make(map[string]interface{}, len(variables)+1)
+					for key, value := range variables {
+						completed[key] = value
+					}
+					copied = true
+				}
+				completed[name] = ""
+				continue
+			}
+		}
+		if !containsString(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.Validation(fmt.Sprintf("template %s requires variables that were not provided: %s", templateName, strings.Join(missing, ", "))).
+			AddDetail("missing_variables", missing)
+	}
+	return completed, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
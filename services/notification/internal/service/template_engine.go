@@ -3,30 +3,64 @@ package service
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 // TemplateEngine handles variable substitution in notification templates.
+//
+// Besides {{variable}} placeholders, templates may pluralize on a numeric
+// variable with {{plural:count|one:# transfer|other:# transfers}}. Each form
+// is a plural category (one, other) or an exact count (=0), and # stands for
+// the count. Forms cannot contain braces.
 type TemplateEngine struct {
 	// Regex to match {{variable_name}} patterns
 	variablePattern *regexp.Regexp
+	// Regex to match {{plural:variable_name|forms}} patterns
+	pluralPattern *regexp.Regexp
+	// Regex to match anything in double braces, for linting
+	placeholderPattern *regexp.Regexp
 }
 
 // NewTemplateEngine creates a new template engine.
 func NewTemplateEngine() *TemplateEngine {
 	return &TemplateEngine{
-		variablePattern: regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)\}\}`),
+		variablePattern:    regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)\}\}`),
+		pluralPattern:      regexp.MustCompile(`\{\{plural:([a-zA-Z0-9_]+)\|([^{}]*)\}\}`),
+		placeholderPattern: regexp.MustCompile(`\{\{[^{}]*\}\}`),
 	}
 }
 
 // Render replaces all {{variable}} placeholders in the template with actual values.
 // Returns the rendered text and a list of variables that were substituted.
+// Plurals use English rules.
 func (e *TemplateEngine) Render(template string, variables map[string]interface{}) (string, []string) {
+	return e.RenderLocale(template, variables, DefaultLocale)
+}
+
+// RenderLocale renders a template, choosing plural forms by the rules of
+// locale.
+func (e *TemplateEngine) RenderLocale(template string, variables map[string]interface{}, locale string) (string, []string) {
 	usedVariables := make([]string, 0)
-	rendered := template
+
+	// Plurals first, so their forms may not introduce placeholders
+	rendered := e.pluralPattern.ReplaceAllStringFunc(template, func(block string) string {
+		match := e.pluralPattern.FindStringSubmatch(block)
+		count, ok := numericValue(variables[match[1]])
+		if !ok {
+			// Keep the block if the count is missing or not a number
+			return block
+		}
+		form, ok := selectPluralForm(match[2], locale, count)
+		if !ok {
+			return block
+		}
+		usedVariables = append(usedVariables, match[1])
+		return strings.ReplaceAll(form, "#", strconv.FormatFloat(count, 'f', -1, 64))
+	})
 
 	// Find all variable placeholders
-	matches := e.variablePattern.FindAllStringSubmatch(template, -1)
+	matches := e.variablePattern.FindAllStringSubmatch(rendered, -1)
 
 	for _, match := range matches {
 		if len(match) < 2 {
@@ -68,11 +102,59 @@ func (e *TemplateEngine) Render(template string, variables map[string]interface{
 	return rendered, usedVariables
 }
 
-// ExtractVariables extracts all variable names from a template.
+// selectPluralForm picks the form for count from "one:...|other:...". An
+// exact match (=N) wins over the category; other is the fallback.
+func selectPluralForm(forms, locale string, count float64) (string, bool) {
+	byKey := parsePluralForms(forms)
+	for _, key := range []string{"=" + strconv.FormatFloat(count, 'f', -1, 64), pluralCategory(locale, count), pluralOther} {
+		if text, ok := byKey[key]; ok {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// parsePluralForms splits "one:...|other:..." into forms by key.
+func parsePluralForms(forms string) map[string]string {
+	byKey := make(map[string]string)
+	for _, form := range strings.Split(forms, "|") {
+		key, text, ok := strings.Cut(form, ":")
+		if !ok {
+			continue
+		}
+		byKey[strings.TrimSpace(key)] = text
+	}
+	return byKey
+}
+
+// numericValue converts a template variable to a number. JSON numbers arrive
+// as float64; numeric strings are accepted too.
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// ExtractVariables extracts all variable names from a template, including
+// the counts plurals depend on.
 func (e *TemplateEngine) ExtractVariables(template string) []string {
+	pluralMatches := e.pluralPattern.FindAllStringSubmatch(template, -1)
 	matches := e.variablePattern.FindAllStringSubmatch(template, -1)
-	variables := make([]string, 0, len(matches))
+	variables := make([]string, 0, len(pluralMatches)+len(matches))
 
+	for _, match := range pluralMatches {
+		variables = append(variables, match[1])
+	}
 	for _, match := range matches {
 		if len(match) >= 2 {
 			variables = append(variables, match[1])
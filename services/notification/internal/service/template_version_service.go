@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// knownNotificationTypes are the types a template rollout can target.
+var knownNotificationTypes = map[models.NotificationType]bool{
+	models.TypeOTP:              true,
+	models.TypeTransactionAlert: true,
+	models.TypeAccountAlert:     true,
+	models.TypeKYCUpdate:        true,
+	models.TypeWelcome:          true,
+	models.TypeSecurityAlert:    true,
+	models.TypeWalletAlert:      true,
+	models.TypeSystemAlert:      true,
+	models.TypeMarketing:        true,
+}
+
+// TemplateVersionService manages template versions, per-type version pins
+// and A/B rollouts between two published versions.
+type TemplateVersionService struct {
+	templateRepo   *repository.TemplateRepository
+	versionRepo    *repository.TemplateVersionRepository
+	templateEngine *TemplateEngine
+}
+
+// NewTemplateVersionService creates a new template version service.
+func NewTemplateVersionService(templateRepo *repository.TemplateRepository, versionRepo *repository.TemplateVersionRepository) *TemplateVersionService {
+	return &TemplateVersionService{
+		templateRepo:   templateRepo,
+		versionRepo:    versionRepo,
+		templateEngine: NewTemplateEngine(),
+	}
+}
+
+// CreateVersion adds a draft version to a template. Drafts are never sent
+// until published. The content is linted against the template's notification
+// type.
+func (s *TemplateVersionService) CreateVersion(ctx context.Context, templateID string, req *models.CreateTemplateVersionRequest) (*models.TemplateVersion, *errors.Error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.templateEngine.lintTemplateContent(templateNotificationType(template.Metadata), req.SubjectTemplate, req.BodyTemplate); err != nil {
+		return nil, err
+	}
+
+	version := &models.TemplateVersion{
+		TemplateID:      templateID,
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+	}
+	if err := s.versionRepo.CreateDraft(ctx, version); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Created draft version %d of template %s", version.Version, templateID)
+	return version, nil
+}
+
+// GetVersion retrieves one version of a template.
+func (s *TemplateVersionService) GetVersion(ctx context.Context, templateID string, version int) (*models.TemplateVersion, *errors.Error) {
+	return s.versionRepo.Get(ctx, templateID, version)
+}
+
+// ListVersions retrieves all versions of a template, newest first.
+func (s *TemplateVersionService) ListVersions(ctx context.Context, templateID string) ([]*models.TemplateVersion, *errors.Error) {
+	if _, err := s.templateRepo.GetByID(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.List(ctx, templateID)
+}
+
+// PublishVersion publishes a draft. A version newer than the template's
+// current content becomes the default for types without a pin.
+func (s *TemplateVersionService) PublishVersion(ctx context.Context, templateID string, version int) (*models.TemplateVersion, *errors.Error) {
+	published, err := s.versionRepo.Publish(ctx, templateID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Published version %d of template %s", version, templateID)
+	return published, nil
+}
+
+// SetRollout pins a notification type to a published version, optionally
+// sending a candidate version to a percentage of recipients.
+func (s *TemplateVersionService) SetRollout(ctx context.Context, templateID string, notifType models.NotificationType, req *models.SetTemplateRolloutRequest) (*models.TemplateRollout, *errors.Error) {
+	if !knownNotificationTypes[notifType] {
+		return nil, errors.Validation(fmt.Sprintf("unknown notification type: %s", notifType))
+	}
+	if req.CandidatePercent < 0 || req.CandidatePercent > 100 {
+		return nil, errors.Validation("candidate_percent must be between 0 and 100")
+	}
+	if req.CandidateVersion == nil && req.CandidatePercent > 0 {
+		return nil, errors.Validation("candidate_percent requires a candidate_version")
+	}
+	if req.CandidateVersion != nil && *req.CandidateVersion == req.Version {
+		return nil, errors.Validation("candidate_version must differ from version")
+	}
+
+	if err := s.requirePublished(ctx, templateID, req.Version); err != nil {
+		return nil, err
+	}
+	if req.CandidateVersion != nil {
+		if err := s.requirePublished(ctx, templateID, *req.CandidateVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	rollout := &models.TemplateRollout{
+		TemplateID:       templateID,
+		NotificationType: notifType,
+		Version:          req.Version,
+		CandidateVersion: req.CandidateVersion,
+		CandidatePercent: req.CandidatePercent,
+	}
+	if err := s.versionRepo.SetRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Set rollout for template %s type %s: version=%d candidate=%v percent=%d",
+		templateID, notifType, rollout.Version, rollout.CandidateVersion, rollout.CandidatePercent)
+	return rollout, nil
+}
+
+// ListRollouts retrieves all rollouts of a template.
+func (s *TemplateVersionService) ListRollouts(ctx context.Context, templateID string) ([]*models.TemplateRollout, *errors.Error) {
+	if _, err := s.templateRepo.GetByID(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.ListRollouts(ctx, templateID)
+}
+
+// DeleteRollout removes a type's pin so it follows the template's latest
+// published version again.
+func (s *TemplateVersionService) DeleteRollout(ctx context.Context, templateID string, notifType models.NotificationType) *errors.Error {
+	return s.versionRepo.DeleteRollout(ctx, templateID, notifType)
+}
+
+// GetStats returns delivery statistics per version of a template.
+func (s *TemplateVersionService) GetStats(ctx context.Context, templateID string) (*models.TemplateStats, *errors.Error) {
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetVersionStats(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TemplateStats{
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Versions:   versions,
+	}, nil
+}
+
+// Resolve returns the version content to render for a notification type and
+// recipient. Without a rollout the template's current content is used. With a
+// candidate, recipients are bucketed deterministically so each one keeps
+// seeing the same version while the split is unchanged.
+func (s *TemplateVersionService) Resolve(ctx context.Context, template *models.NotificationTemplate, notifType models.NotificationType, recipient string) (*models.TemplateVersion, *errors.Error) {
+	rollout, err := s.versionRepo.GetRollout(ctx, template.ID, notifType)
+	if err != nil {
+		return nil, err
+	}
+	if rollout == nil {
+		return &models.TemplateVersion{
+			TemplateID:      template.ID,
+			Version:         template.Version,
+			SubjectTemplate: template.SubjectTemplate,
+			BodyTemplate:    template.BodyTemplate,
+			Status:          models.TemplateVersionPublished,
+		}, nil
+	}
+
+	version := rollout.Version
+	if rollout.CandidateVersion != nil && rolloutBucket(template.ID, recipient) < rollout.CandidatePercent {
+		version = *rollout.CandidateVersion
+	}
+
+	return s.versionRepo.Get(ctx, template.ID, version)
+}
+
+// requirePublished ensures a version exists and may be sent.
+func (s *TemplateVersionService) requirePublished(ctx context.Context, templateID string, version int) *errors.Error {
+	v, err := s.versionRepo.Get(ctx, templateID, version)
+	if err != nil {
+		return err
+	}
+	if !v.IsPublished() {
+		return errors.Validation(fmt.Sprintf("template version %d is not published", version))
+	}
+	return nil
+}
+
+// rolloutBucket maps a recipient to a stable bucket in [0, 100) per template.
+func rolloutBucket(templateID, recipient string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(templateID + ":" + recipient))
+	return int(h.Sum32() % 100)
+}
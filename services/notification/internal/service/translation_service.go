@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// TranslationService manages template translations and picks the content a
+// recipient's locale is sent in.
+//
+// A template's own content is in the default locale. Translations are keyed by
+// template and locale, so a template name and channel together with a locale
+// identify one translation. A recipient in hi-IN gets the hi-IN translation,
+// else hi, else the template's own content.
+type TranslationService struct {
+	templateRepo    *repository.TemplateRepository
+	translationRepo *repository.TranslationRepository
+	templateEngine  *TemplateEngine
+	defaultLocale   string
+	locales         []string
+}
+
+// NewTranslationService creates a new translation service. locales are the
+// locales templates are expected to be translated into; the default locale
+// among them is ignored.
+func NewTranslationService(
+	templateRepo *repository.TemplateRepository,
+	translationRepo *repository.TranslationRepository,
+	defaultLocale string,
+	locales []string,
+) *TranslationService {
+	normalizedDefault, ok := NormalizeLocale(defaultLocale)
+	if !ok {
+		normalizedDefault = DefaultLocale
+	}
+
+	supported := make([]string, 0, len(locales))
+	seen := map[string]bool{normalizedDefault: true}
+	for _, l := range locales {
+		normalized, ok := NormalizeLocale(l)
+		if !ok {
+			log.Printf("[notification] Ignoring invalid locale %q", l)
+			continue
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		supported = append(supported, normalized)
+	}
+
+	return &TranslationService{
+		templateRepo:    templateRepo,
+		translationRepo: translationRepo,
+		templateEngine:  NewTemplateEngine(),
+		defaultLocale:   normalizedDefault,
+		locales:         supported,
+	}
+}
+
+// DefaultLocale returns the locale templates' own content is written in.
+func (s *TranslationService) DefaultLocale() string {
+	return s.defaultLocale
+}
+
+// normalizeTranslationLocale validates a locale a translation can be stored
+// under.
+func (s *TranslationService) normalizeTranslationLocale(locale string) (string, *errors.Error) {
+	normalized, ok := NormalizeLocale(locale)
+	if !ok {
+		return "", errors.BadRequest("locale must be a language code with an optional region, e.g. hi or hi-IN")
+	}
+	if normalized == s.defaultLocale {
+		return "", errors.BadRequest("the default locale " + s.defaultLocale + " is the template's own content; update the template instead")
+	}
+	return normalized, nil
+}
+
+// UpsertTranslation creates or replaces a template's translation into a
+// locale. The source version defaults to the template's current version.
+func (s *TranslationService) UpsertTranslation(ctx context.Context, templateID, locale string, req *models.UpsertTranslationRequest) (*models.TemplateTranslation, *errors.Error) {
+	normalized, err := s.normalizeTranslationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := s.templateRepo.GetByID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceVersion := req.SourceVersion
+	if sourceVersion == 0 {
+		sourceVersion = template.Version
+	}
+	if sourceVersion > template.Version {
+		return nil, errors.BadRequest("source_version cannot be newer than the template's current version")
+	}
+	if err := s.templateEngine.lintTemplateContent(templateNotificationType(template.Metadata), req.SubjectTemplate, req.BodyTemplate); err != nil {
+		return nil, err
+	}
+
+	translation := &models.TemplateTranslation{
+		TemplateID:      templateID,
+		Locale:          normalized,
+		SubjectTemplate: req.SubjectTemplate,
+		BodyTemplate:    req.BodyTemplate,
+		SourceVersion:   sourceVersion,
+	}
+	if err := s.translationRepo.Upsert(ctx, translation); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Saved %s translation of template %s from version %d", normalized, templateID, sourceVersion)
+	return translation, nil
+}
+
+// GetTranslation retrieves a template's translation into a locale.
+func (s *TranslationService) GetTranslation(ctx context.Context, templateID, locale string) (*models.TemplateTranslation, *errors.Error) {
+	normalized, err := s.normalizeTranslationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	translation, err := s.translationRepo.Get(ctx, templateID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if translation == nil {
+		return nil, errors.NotFound("template translation")
+	}
+	return translation, nil
+}
+
+// ListTranslations retrieves all translations of a template.
+func (s *TranslationService) ListTranslations(ctx context.Context, templateID string) ([]*models.TemplateTranslation, *errors.Error) {
+	if _, err := s.templateRepo.GetByID(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return s.translationRepo.List(ctx, templateID)
+}
+
+// DeleteTranslation removes a template's translation into a locale. Recipients
+// in that locale fall back along the chain.
+func (s *TranslationService) DeleteTranslation(ctx context.Context, templateID, locale string) *errors.Error {
+	normalized, err := s.normalizeTranslationLocale(locale)
+	if err != nil {
+		return err
+	}
+	return s.translationRepo.Delete(ctx, templateID, normalized)
+}
+
+// Localize returns the subject and body templates to render for a recipient
+// in locale, and the locale they are in. It walks the locale's fallback chain
+// and ends at the given content, which is in the default locale.
+//
+// A translation replaces whichever version was resolved for the send; it is
+// reported as outdated once the template moves past its source version.
+func (s *TranslationService) Localize(ctx context.Context, templateID, locale, subject, body string) (string, string, string, *errors.Error) {
+	for _, candidate := range LocaleChain(locale, s.defaultLocale) {
+		if candidate == s.defaultLocale || candidate == localeLanguage(s.defaultLocale) {
+			break
+		}
+
+		translation, err := s.translationRepo.Get(ctx, templateID, candidate)
+		if err != nil {
+			return "", "", "", err
+		}
+		if translation != nil {
+			return translation.SubjectTemplate, translation.BodyTemplate, candidate, nil
+		}
+	}
+
+	return subject, body, s.defaultLocale, nil
+}
+
+// MissingTranslations reports, for locale or every supported locale when it
+// is empty, the templates with no translation or only an outdated one.
+func (s *TranslationService) MissingTranslations(ctx context.Context, locale string) ([]*models.MissingTranslations, *errors.Error) {
+	locales := s.locales
+	if locale != "" {
+		normalized, err := s.normalizeTranslationLocale(locale)
+		if err != nil {
+			return nil, err
+		}
+		locales = []string{normalized}
+	}
+
+	templates, err := s.templateRepo.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	translations, err := s.translationRepo.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Source version of each translation, by locale then template
+	translated := make(map[string]map[string]int)
+	for _, t := range translations {
+		if translated[t.Locale] == nil {
+			translated[t.Locale] = make(map[string]int)
+		}
+		translated[t.Locale][t.TemplateID] = t.SourceVersion
+	}
+
+	reports := make([]*models.MissingTranslations, 0, len(locales))
+	for _, l := range locales {
+		report := &models.MissingTranslations{
+			Locale:   l,
+			Missing:  make([]*models.TranslationGap, 0),
+			Outdated: make([]*models.TranslationGap, 0),
+			Coverage: 100,
+		}
+
+		for _, template := range templates {
+			gap := &models.TranslationGap{
+				TemplateID: template.ID,
+				Name:       template.Name,
+				Channel:    template.Channel,
+				Version:    template.Version,
+			}
+
+			sourceVersion, ok := translated[l][template.ID]
+			switch {
+			case !ok:
+				report.Missing = append(report.Missing, gap)
+			case sourceVersion < template.Version:
+				gap.SourceVersion = &sourceVersion
+				report.Outdated = append(report.Outdated, gap)
+			}
+		}
+
+		if len(templates) > 0 {
+			upToDate := len(templates) - len(report.Missing) - len(report.Outdated)
+			report.Coverage = math.Round(float64(upToDate)/float64(len(templates))*1000) / 10
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+// DefaultWebhookTimeout bounds a single webhook request.
+const DefaultWebhookTimeout = 5 * time.Second
+
+// WebhookSender POSTs webhook notifications to their registered endpoint and
+// records every attempt.
+type WebhookSender struct {
+	repo   *repository.WebhookRepository
+	client *http.Client
+}
+
+// NewWebhookSender creates a webhook sender whose requests time out after timeout.
+func NewWebhookSender(repo *repository.WebhookRepository, timeout time.Duration) *WebhookSender {
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &WebhookSender{
+		repo: repo,
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect would send the signed payload somewhere unregistered
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// SignWebhookPayload returns the X-Nivo-Signature value for body sent at
+// timestamp: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	return crypto.SignWebhook(secret, timestamp, body)
+}
+
+// send makes one delivery attempt and records it. A 2xx response is success;
+// other 4xx responses except 408 and 429 are permanent failures that are not
+// retried.
+func (s *WebhookSender) send(ctx context.Context, endpoint *models.WebhookEndpoint, notificationID string, body []byte, attempt int) error {
+	delivery := &models.WebhookDelivery{
+		NotificationID: notificationID,
+		EndpointID:     &endpoint.ID,
+		URL:            endpoint.URL,
+		Attempt:        attempt,
+	}
+
+	start := time.Now()
+	statusCode, respBody, sendErr := s.post(ctx, endpoint, notificationID, body, attempt)
+	delivery.DurationMs = int(time.Since(start).Milliseconds())
+
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+		if respBody != "" {
+			delivery.ResponseBody = &respBody
+		}
+	}
+	switch {
+	case sendErr != nil:
+	case statusCode >= 200 && statusCode < 300:
+		delivery.Succeeded = true
+	default:
+		sendErr = fmt.Errorf("endpoint returned HTTP %d", statusCode)
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		delivery.Error = &msg
+	}
+
+	// Tracking is best effort; it must not turn a delivered webhook into a retry
+	if err := s.repo.RecordDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Printf("[webhook] Failed to record delivery attempt %d of notification %s: %v", attempt, notificationID, err)
+	}
+
+	if sendErr != nil && statusCode >= 400 && statusCode < 500 &&
+		statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests {
+		return retry.Permanent(sendErr)
+	}
+	return sendErr
+}
+
+// post sends the signed request and returns the status code and the start of
+// the response body. The status code is 0 if no response was received.
+func (s *WebhookSender) post(ctx context.Context, endpoint *models.WebhookEndpoint, notificationID string, body []byte, attempt int) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", retry.Permanent(fmt.Errorf("invalid endpoint URL: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NivoMoney-Webhooks/1.0")
+	req.Header.Set(models.WebhookNotificationIDHeader, notificationID)
+	req.Header.Set(models.WebhookAttemptHeader, strconv.Itoa(attempt))
+	req.Header.Set(models.WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, models.MaxWebhookResponseBody))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// deliverWebhook delivers a webhook notification to its registered endpoint,
+// retrying failed attempts with the delivery backoff.
+func (e *SimulationEngine) deliverWebhook(ctx context.Context, notif *models.Notification) *errors.Error {
+	endpoint, err := e.webhooks.repo.FindForNotification(ctx, notif.UserID, notif.Recipient)
+	if err != nil {
+		if err.Code != errors.ErrCodeNotFound {
+			return err
+		}
+		reason := "No webhook endpoint registered"
+		log.Printf("[webhook] Notification %s has no enabled endpoint (recipient=%s)", notif.ID, notif.Recipient)
+		return e.updateStatus(ctx, notif.ID, models.StatusFailed, &reason)
+	}
+
+	body, marshalErr := json.Marshal(models.NewWebhookPayload(notif))
+	if marshalErr != nil {
+		return errors.Wrap(marshalErr, errors.ErrCodeInternal, "failed to encode webhook payload")
+	}
+
+	if err := e.updateStatus(ctx, notif.ID, models.StatusSent, nil); err != nil {
+		return err
+	}
+
+	policy := e.deliveryBackoff
+	policy.OnRetry = func(attempt int, err error, delay time.Duration) {
+		log.Printf("[webhook] Notification %s attempt %d failed: %v (retrying in %s)", notif.ID, attempt, err, delay)
+		if incErr := e.repo.IncrementRetryCount(ctx, notif.ID); incErr != nil {
+			log.Printf("[webhook] Failed to increment retry count of notification %s: %v", notif.ID, incErr)
+		}
+	}
+
+	attempt := 0
+	sendErr := retry.Do(ctx, policy, func(ctx context.Context) error {
+		attempt++
+		return e.webhooks.send(ctx, endpoint, notif.ID, body, attempt)
+	})
+
+	// The outcome is recorded even if the delivery job ran out of time
+	statusCtx := context.WithoutCancel(ctx)
+	if sendErr != nil {
+		reason := sendErr.Error()
+		if err := e.updateStatus(statusCtx, notif.ID, models.StatusFailed, &reason); err != nil {
+			return err
+		}
+		log.Printf("[webhook] Notification %s failed after %d attempts: %s", notif.ID, attempt, reason)
+		return nil
+	}
+
+	if err := e.updateStatus(statusCtx, notif.ID, models.StatusDelivered, nil); err != nil {
+		return err
+	}
+
+	log.Printf("[webhook] Notification %s delivered to %s (attempt %d)", notif.ID, endpoint.URL, attempt)
+	if e.onDelivered != nil {
+		e.onDelivered(statusCtx, notif)
+	}
+	return nil
+}
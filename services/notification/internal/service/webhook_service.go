@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/notification/internal/models"
+	"github.com/1mb-dev/nivomoney/services/notification/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// webhookSecretPrefix marks signing secrets so they are recognizable in config.
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService manages webhook endpoints and exposes their delivery history.
+type WebhookService struct {
+	webhookRepo *repository.WebhookRepository
+	notifRepo   *repository.NotificationRepository
+}
+
+// NewWebhookService creates a new webhook service.
+func NewWebhookService(webhookRepo *repository.WebhookRepository, notifRepo *repository.NotificationRepository) *WebhookService {
+	return &WebhookService{webhookRepo: webhookRepo, notifRepo: notifRepo}
+}
+
+// CreateEndpoint registers a webhook endpoint and issues its signing secret.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req *models.CreateWebhookEndpointRequest) (*models.WebhookEndpointWithSecret, *errors.Error) {
+	if !req.OwnerType.IsValid() {
+		return nil, errors.Validation("owner_type must be user or service")
+	}
+	ownerID := strings.TrimSpace(req.OwnerID)
+	if ownerID == "" {
+		return nil, errors.Validation("owner_id is required")
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		OwnerType:   req.OwnerType,
+		OwnerID:     ownerID,
+		URL:         req.URL,
+		Secret:      secret,
+		Description: req.Description,
+		Enabled:     true,
+	}
+	if err := s.webhookRepo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Registered webhook endpoint %s (owner=%s:%s)", endpoint.ID, endpoint.OwnerType, endpoint.OwnerID)
+	return &models.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID.
+func (s *WebhookService) GetEndpoint(ctx context.Context, id string) (*models.WebhookEndpoint, *errors.Error) {
+	return s.webhookRepo.GetEndpoint(ctx, id)
+}
+
+// ListEndpoints retrieves webhook endpoints, optionally filtered by owner.
+func (s *WebhookService) ListEndpoints(ctx context.Context, ownerType *models.WebhookOwnerType, ownerID *string) ([]*models.WebhookEndpoint, *errors.Error) {
+	if ownerType != nil && !ownerType.IsValid() {
+		return nil, errors.Validation("owner_type must be user or service")
+	}
+	return s.webhookRepo.ListEndpoints(ctx, ownerType, ownerID)
+}
+
+// UpdateEndpoint changes an endpoint's URL, description or enabled flag.
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id string, req *models.UpdateWebhookEndpointRequest) (*models.WebhookEndpoint, *errors.Error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = req.Description
+	}
+	if req.Enabled != nil {
+		endpoint.Enabled = *req.Enabled
+	}
+
+	if err := s.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Updated webhook endpoint %s (enabled=%t)", endpoint.ID, endpoint.Enabled)
+	return endpoint, nil
+}
+
+// RotateSecret issues a new signing secret for an endpoint. Deliveries are
+// signed with the new secret from the next attempt on.
+func (s *WebhookService) RotateSecret(ctx context.Context, id string) (*models.WebhookEndpointWithSecret, *errors.Error) {
+	endpoint, err := s.webhookRepo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+
+	if err := s.webhookRepo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[notification] Rotated signing secret of webhook endpoint %s", endpoint.ID)
+	return &models.WebhookEndpointWithSecret{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint.
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id string) *errors.Error {
+	if err := s.webhookRepo.DeleteEndpoint(ctx, id); err != nil {
+		return err
+	}
+
+	log.Printf("[notification] Deleted webhook endpoint %s", id)
+	return nil
+}
+
+// ListDeliveries retrieves the delivery attempts of a notification.
+func (s *WebhookService) ListDeliveries(ctx context.Context, notificationID string) ([]*models.WebhookDelivery, *errors.Error) {
+	// Distinguish an unknown notification from one with no attempts yet
+	if _, err := s.notifRepo.GetByID(ctx, notificationID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.ListDeliveries(ctx, notificationID)
+}
+
+// validateWebhookURL requires an absolute http or https URL without credentials.
+func validateWebhookURL(raw string) *errors.Error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.Validation("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.Validation("url must not contain credentials")
+	}
+	if u.Fragment != "" {
+		return errors.Validation("url must not contain a fragment")
+	}
+	return nil
+}
+
+// generateWebhookSecret returns a new random signing secret.
+func generateWebhookSecret() (string, *errors.Error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate webhook secret")
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
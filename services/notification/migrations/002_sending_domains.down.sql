@@ -0,0 +1,4 @@
+-- Sending Domains Rollback
+
+DROP INDEX IF EXISTS idx_notifications_sending_domain;
+DROP TABLE IF EXISTS sending_domains CASCADE;
@@ -0,0 +1,35 @@
+-- Sending Domains
+-- Transactional email domain configuration with simulated DKIM/SPF verification
+
+CREATE TABLE IF NOT EXISTS sending_domains (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    domain VARCHAR(253) NOT NULL UNIQUE,
+    tenant VARCHAR(100) NOT NULL DEFAULT 'default',
+    default_from_address VARCHAR(255) NOT NULL,
+    from_addresses JSONB,
+    bounce_address VARCHAR(255) NOT NULL,
+    dkim_selector VARCHAR(63),
+    dkim_public_key TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT sending_domains_status_check CHECK (status IN ('pending', 'verified', 'failed'))
+);
+
+CREATE INDEX idx_sending_domains_tenant ON sending_domains(tenant) WHERE enabled = true;
+
+CREATE TRIGGER update_sending_domains_updated_at
+    BEFORE UPDATE ON sending_domains
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Per-domain delivery stats read the sending domain from notification metadata
+CREATE INDEX idx_notifications_sending_domain ON notifications((metadata->>'sending_domain'))
+    WHERE channel = 'email';
+
+COMMENT ON TABLE sending_domains IS 'Email sending domains with from-address routing per notification type and tenant';
+COMMENT ON COLUMN sending_domains.from_addresses IS 'Map of notification type to from-address; falls back to default_from_address';
+COMMENT ON COLUMN sending_domains.dkim_public_key IS 'Simulated DKIM public key published under <selector>._domainkey.<domain>';
@@ -0,0 +1,12 @@
+-- Webhook Channel Rollback
+
+DELETE FROM notifications WHERE channel = 'webhook';
+DELETE FROM notification_templates WHERE channel = 'webhook';
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_channel_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_channel_check
+    CHECK (channel IN ('sms', 'email', 'push', 'in_app'));
+
+ALTER TABLE notification_templates DROP CONSTRAINT IF EXISTS templates_channel_check;
+ALTER TABLE notification_templates ADD CONSTRAINT templates_channel_check
+    CHECK (channel IN ('sms', 'email', 'push', 'in_app'));
@@ -0,0 +1,10 @@
+-- Webhook Channel
+-- Allow notifications and templates to be delivered to HTTP callback endpoints
+
+ALTER TABLE notification_templates DROP CONSTRAINT IF EXISTS templates_channel_check;
+ALTER TABLE notification_templates ADD CONSTRAINT templates_channel_check
+    CHECK (channel IN ('sms', 'email', 'push', 'in_app', 'webhook'));
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_channel_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_channel_check
+    CHECK (channel IN ('sms', 'email', 'push', 'in_app', 'webhook'));
@@ -0,0 +1,5 @@
+-- Provider Delivery Receipts Rollback
+
+DROP INDEX IF EXISTS idx_notifications_provider_message_id;
+ALTER TABLE notifications DROP COLUMN IF EXISTS provider_status_at;
+ALTER TABLE notifications DROP COLUMN IF EXISTS provider_message_id;
@@ -0,0 +1,12 @@
+-- Provider Delivery Receipts
+-- Tracks the provider's message ID and the time of the last applied receipt so
+-- status callbacks can be matched and applied idempotently, in any order
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS provider_message_id VARCHAR(255);
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS provider_status_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_provider_message_id
+    ON notifications(provider_message_id) WHERE provider_message_id IS NOT NULL;
+
+COMMENT ON COLUMN notifications.provider_message_id IS 'Message ID assigned by the delivery provider';
+COMMENT ON COLUMN notifications.provider_status_at IS 'Provider timestamp of the last applied delivery receipt';
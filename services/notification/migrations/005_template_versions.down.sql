@@ -0,0 +1,8 @@
+-- Template Versions Rollback
+
+DROP INDEX IF EXISTS idx_notifications_template_version;
+ALTER TABLE notifications DROP COLUMN IF EXISTS template_version;
+
+DROP TABLE IF EXISTS notification_template_rollouts;
+DROP TABLE IF EXISTS notification_template_versions;
+DROP FUNCTION IF EXISTS prevent_template_version_changes();
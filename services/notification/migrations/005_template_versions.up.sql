@@ -0,0 +1,78 @@
+-- Template Versions
+-- Immutable template versions with draft/published state, per-type version
+-- pins with A/B rollout, and the version each notification was rendered from
+
+CREATE TABLE IF NOT EXISTS notification_template_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    template_id UUID NOT NULL REFERENCES notification_templates(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    subject_template VARCHAR(500),
+    body_template TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE,
+
+    CONSTRAINT template_versions_unique UNIQUE (template_id, version),
+    CONSTRAINT template_versions_status_check CHECK (status IN ('draft', 'published')),
+    CONSTRAINT template_versions_version_check CHECK (version > 0)
+);
+
+-- Version content never changes once written; only drafts may be published
+CREATE OR REPLACE FUNCTION prevent_template_version_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.subject_template IS DISTINCT FROM OLD.subject_template
+       OR NEW.body_template IS DISTINCT FROM OLD.body_template
+       OR NEW.version <> OLD.version
+       OR NEW.template_id <> OLD.template_id THEN
+        RAISE EXCEPTION 'template versions are immutable';
+    END IF;
+    IF OLD.status = 'published' AND NEW.status <> 'published' THEN
+        RAISE EXCEPTION 'published template versions cannot return to draft';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER template_versions_immutable
+    BEFORE UPDATE ON notification_template_versions
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_template_version_changes();
+
+-- Existing template content becomes the published version it is labelled with
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', updated_at
+FROM notification_templates
+ON CONFLICT (template_id, version) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS notification_template_rollouts (
+    template_id UUID NOT NULL REFERENCES notification_templates(id) ON DELETE CASCADE,
+    notification_type VARCHAR(50) NOT NULL,
+    version INT NOT NULL,
+    candidate_version INT,
+    candidate_percent INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (template_id, notification_type),
+    CONSTRAINT template_rollouts_percent_check CHECK (candidate_percent BETWEEN 0 AND 100),
+    CONSTRAINT template_rollouts_candidate_check CHECK (
+        (candidate_version IS NULL AND candidate_percent = 0) OR
+        (candidate_version IS NOT NULL AND candidate_version <> version)
+    )
+);
+
+CREATE TRIGGER update_notification_template_rollouts_updated_at
+    BEFORE UPDATE ON notification_template_rollouts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS template_version INT;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_template_version
+    ON notifications(template_id, template_version) WHERE template_id IS NOT NULL;
+
+COMMENT ON TABLE notification_template_versions IS 'Immutable template content; drafts are published before they can be sent';
+COMMENT ON TABLE notification_template_rollouts IS 'Pins a notification type to a template version, optionally splitting traffic with a candidate version';
+COMMENT ON COLUMN notification_template_rollouts.candidate_percent IS 'Share of recipients, 0-100, that receive candidate_version';
+COMMENT ON COLUMN notifications.template_version IS 'Template version the notification was rendered from';
@@ -0,0 +1,4 @@
+-- Contact Change Templates Rollback
+
+DELETE FROM notification_templates
+WHERE name IN ('contact_change_code_email', 'contact_change_code_sms', 'profile_email_changed', 'profile_phone_changed');
@@ -0,0 +1,57 @@
+-- Contact Change Templates
+-- Codes sent to a new email or phone during a contact change, and the security
+-- notices sent to the old address once the change is confirmed
+
+INSERT INTO notification_templates (name, channel, subject_template, body_template, version)
+VALUES
+(
+    'contact_change_code_email',
+    'email',
+    'Confirm your new email address',
+    'Dear {{full_name}},
+
+Use this code to confirm your new email address for Nivo Money:
+
+{{otp}}
+
+The code is valid for {{validity_minutes}} minutes. If you did not request this change, ignore this email.
+
+Best regards,
+The Nivo Money Team',
+    1
+),
+(
+    'contact_change_code_sms',
+    'sms',
+    '',
+    'Your Nivo Money code to confirm this phone number is {{otp}}. Valid for {{validity_minutes}} minutes. Do not share this code. - Nivo Money',
+    1
+),
+(
+    'profile_email_changed',
+    'email',
+    'Your Nivo Money email address was changed',
+    'Dear {{full_name}},
+
+The email address on your Nivo Money account was changed to {{new_email}}. This address will no longer receive account emails.
+
+If you did not make this change, contact support immediately.
+
+Best regards,
+The Nivo Money Team',
+    1
+),
+(
+    'profile_phone_changed',
+    'sms',
+    '',
+    'The phone number on your Nivo Money account was changed to {{new_phone}}. If this was not you, contact support immediately. - Nivo Money',
+    1
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', NOW()
+FROM notification_templates
+WHERE name IN ('contact_change_code_email', 'contact_change_code_sms', 'profile_email_changed', 'profile_phone_changed')
+ON CONFLICT (template_id, version) DO NOTHING;
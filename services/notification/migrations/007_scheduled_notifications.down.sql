@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_notifications_scheduled_user;
+DROP INDEX IF EXISTS idx_notifications_scheduled_due;
+
+-- Scheduled notifications that never went out are dropped with the feature
+DELETE FROM notifications WHERE status IN ('scheduled', 'cancelled');
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_status_check
+    CHECK (status IN ('queued', 'sent', 'delivered', 'failed'));
+
+ALTER TABLE notifications DROP COLUMN IF EXISTS timezone;
+ALTER TABLE notifications DROP COLUMN IF EXISTS scheduled_for;
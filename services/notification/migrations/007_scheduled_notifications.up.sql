@@ -0,0 +1,18 @@
+-- Scheduled Notifications
+-- Hold notifications until send_at, and allow cancelling them before then
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS scheduled_for TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
+
+ALTER TABLE notifications DROP CONSTRAINT IF EXISTS notifications_status_check;
+ALTER TABLE notifications ADD CONSTRAINT notifications_status_check
+    CHECK (status IN ('scheduled', 'queued', 'sent', 'delivered', 'failed', 'cancelled'));
+
+-- Scheduler scan for due notifications, and per-user upcoming deliveries
+CREATE INDEX IF NOT EXISTS idx_notifications_scheduled_due
+    ON notifications(scheduled_for) WHERE status = 'scheduled';
+CREATE INDEX IF NOT EXISTS idx_notifications_scheduled_user
+    ON notifications(user_id, scheduled_for) WHERE status = 'scheduled';
+
+COMMENT ON COLUMN notifications.scheduled_for IS 'When a scheduled notification is released for delivery (UTC)';
+COMMENT ON COLUMN notifications.timezone IS 'IANA timezone the send time was requested in, for display';
@@ -0,0 +1,3 @@
+-- Shared Wallet Templates Rollback
+
+DELETE FROM notification_templates WHERE name = 'shared_wallet_push';
@@ -0,0 +1,20 @@
+-- Shared Wallet Templates
+-- Push notifications sent to every owner of a joint wallet: invites,
+-- co-owner changes and money moving in or out
+
+INSERT INTO notification_templates (name, channel, subject_template, body_template, version)
+VALUES
+(
+    'shared_wallet_push',
+    'push',
+    'Shared wallet',
+    '{{message}}',
+    1
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', NOW()
+FROM notification_templates
+WHERE name = 'shared_wallet_push'
+ON CONFLICT (template_id, version) DO NOTHING;
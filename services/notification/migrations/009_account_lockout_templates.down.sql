@@ -0,0 +1,3 @@
+-- Account Lockout Templates Rollback
+
+DELETE FROM notification_templates WHERE name = 'account_locked_email';
@@ -0,0 +1,31 @@
+-- Account Lockout Templates
+-- Sent by identity when repeated failed logins lock an account. The link
+-- unlocks the account before the cooldown ends.
+
+INSERT INTO notification_templates (name, channel, subject_template, body_template, version)
+VALUES
+(
+    'account_locked_email',
+    'email',
+    'Your Nivo Money account has been temporarily locked',
+    'Dear {{full_name}},
+
+We locked your Nivo Money account for {{locked_minutes}} minutes after several failed login attempts.
+
+If this was you, you can unlock your account now:
+
+{{unlock_url}}
+
+If this was not you, someone may be trying to access your account. Unlock it only once you have changed your password, and contact support if this keeps happening.
+
+Best regards,
+The Nivo Money Team',
+    1
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', NOW()
+FROM notification_templates
+WHERE name = 'account_locked_email'
+ON CONFLICT (template_id, version) DO NOTHING;
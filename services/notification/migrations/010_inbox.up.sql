@@ -0,0 +1,14 @@
+-- In-App Inbox
+-- Read and archived state for in-app notifications
+
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS read_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP WITH TIME ZONE;
+
+-- A user's inbox, newest first, and their unread count
+CREATE INDEX IF NOT EXISTS idx_notifications_inbox
+    ON notifications(user_id, created_at DESC) WHERE channel = 'in_app' AND status = 'delivered';
+CREATE INDEX IF NOT EXISTS idx_notifications_inbox_unread
+    ON notifications(user_id) WHERE channel = 'in_app' AND status = 'delivered' AND read_at IS NULL AND archived_at IS NULL;
+
+COMMENT ON COLUMN notifications.read_at IS 'When the user read an in-app notification';
+COMMENT ON COLUMN notifications.archived_at IS 'When the user archived an in-app notification';
@@ -0,0 +1,5 @@
+-- Webhook Endpoints Rollback
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TRIGGER IF EXISTS update_webhook_endpoints_updated_at ON webhook_endpoints;
+DROP TABLE IF EXISTS webhook_endpoints;
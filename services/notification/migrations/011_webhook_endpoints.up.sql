@@ -0,0 +1,45 @@
+-- Webhook Endpoints
+-- Registered callback URLs for the webhook channel and a log of delivery attempts
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_type VARCHAR(20) NOT NULL,
+    owner_id VARCHAR(255) NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    description VARCHAR(255),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT webhook_endpoints_owner_type_check CHECK (owner_type IN ('user', 'service')),
+    CONSTRAINT webhook_endpoints_owner_key UNIQUE (owner_type, owner_id)
+);
+
+CREATE INDEX idx_webhook_endpoints_url ON webhook_endpoints(url) WHERE enabled = true;
+
+CREATE TRIGGER update_webhook_endpoints_updated_at
+    BEFORE UPDATE ON webhook_endpoints
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    endpoint_id UUID REFERENCES webhook_endpoints(id) ON DELETE SET NULL,
+    url VARCHAR(2048) NOT NULL,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    succeeded BOOLEAN NOT NULL,
+    error TEXT,
+    response_body VARCHAR(1024),
+    duration_ms INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_notification ON webhook_deliveries(notification_id, attempt);
+
+COMMENT ON TABLE webhook_endpoints IS 'Callback URLs for webhook notifications, one per user or service';
+COMMENT ON COLUMN webhook_endpoints.secret IS 'HMAC-SHA256 key for the X-Nivo-Signature header; shown only on create and rotate';
+COMMENT ON TABLE webhook_deliveries IS 'One row per HTTP attempt to deliver a webhook notification';
+COMMENT ON COLUMN webhook_deliveries.response_body IS 'Start of the endpoint response, truncated to 1 KiB';
@@ -0,0 +1,3 @@
+-- User Preferences Rollback
+
+DROP TABLE IF EXISTS user_preferences;
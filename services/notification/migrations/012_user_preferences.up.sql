@@ -0,0 +1,19 @@
+-- User Preferences
+-- Local copy of each user's identity profile attributes, kept current from
+-- user.preferences_updated events. Used for localization, quiet hours and
+-- marketing consent.
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id           UUID PRIMARY KEY,
+    locale            VARCHAR(16),
+    timezone          VARCHAR(64),
+    display_currency  VARCHAR(3),
+    marketing_consent BOOLEAN NOT NULL DEFAULT false,
+    quiet_hours_start VARCHAR(5),
+    quiet_hours_end   VARCHAR(5),
+    updated_at        TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+COMMENT ON TABLE user_preferences IS 'User preferences replicated from identity profile attributes';
+COMMENT ON COLUMN user_preferences.updated_at IS 'When identity published the preferences; older events are ignored';
+COMMENT ON COLUMN user_preferences.quiet_hours_start IS 'HH:MM in timezone; sms and push for normal and low priority wait until quiet_hours_end';
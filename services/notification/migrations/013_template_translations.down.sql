@@ -0,0 +1,3 @@
+-- Template Translations Rollback
+
+DROP TABLE IF EXISTS notification_template_translations;
@@ -0,0 +1,29 @@
+-- Template Translations
+-- Localized subject and body for a template, one row per locale. The
+-- template's own content is in the default locale; sends fall back from the
+-- recipient's locale (hi-IN) to its language (hi) to the default.
+
+CREATE TABLE IF NOT EXISTS notification_template_translations (
+    template_id      UUID NOT NULL REFERENCES notification_templates(id) ON DELETE CASCADE,
+    locale           VARCHAR(16) NOT NULL,
+    subject_template VARCHAR(500),
+    body_template    TEXT NOT NULL,
+    source_version   INT NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (template_id, locale),
+    CONSTRAINT template_translations_source_version_check CHECK (source_version > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_template_translations_locale
+    ON notification_template_translations(locale);
+
+CREATE TRIGGER update_notification_template_translations_updated_at
+    BEFORE UPDATE ON notification_template_translations
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE notification_template_translations IS 'Localized template content keyed by template (name and channel) and locale';
+COMMENT ON COLUMN notification_template_translations.locale IS 'Language tag, e.g. hi or hi-IN';
+COMMENT ON COLUMN notification_template_translations.source_version IS 'Template version the translation was made from; older than the template means outdated';
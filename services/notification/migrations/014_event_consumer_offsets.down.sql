@@ -0,0 +1,3 @@
+-- Event Consumer Offsets Rollback
+
+DROP TABLE IF EXISTS event_consumer_offsets;
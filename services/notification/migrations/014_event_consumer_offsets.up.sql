@@ -0,0 +1,8 @@
+-- Event Consumer Offsets
+-- Last event each event consumer acknowledged, so it resumes after restarts
+
+CREATE TABLE IF NOT EXISTS event_consumer_offsets (
+    consumer VARCHAR(100) PRIMARY KEY,
+    last_event_id VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
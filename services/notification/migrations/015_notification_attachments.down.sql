@@ -0,0 +1,3 @@
+-- Notification Attachments Rollback
+
+DROP TABLE IF EXISTS notification_attachments;
@@ -0,0 +1,23 @@
+-- Notification Attachments
+-- Files sent with email notifications (statements, KYC letters). Inline
+-- content is kept here; files in object storage are referenced by key and
+-- fetched by the email provider adapter at delivery.
+
+CREATE TABLE IF NOT EXISTS notification_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,         -- Order the attachments were given in
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT,                  -- Known for inline content only
+    storage_key VARCHAR(1024),          -- Object storage key; NULL for inline content
+    content BYTEA,                      -- Inline content; NULL for stored files
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT notification_attachments_source_check CHECK (
+        (storage_key IS NOT NULL AND content IS NULL) OR
+        (storage_key IS NULL AND content IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_attachments_notification ON notification_attachments(notification_id, position);
@@ -0,0 +1,4 @@
+-- Contact Verification Templates Rollback
+
+DELETE FROM notification_templates
+WHERE name IN ('contact_verification_code_email', 'contact_verification_code_sms');
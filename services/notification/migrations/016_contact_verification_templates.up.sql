@@ -0,0 +1,35 @@
+-- Contact Verification Templates
+-- Codes sent to a user's current email or phone to verify it
+
+INSERT INTO notification_templates (name, channel, subject_template, body_template, version)
+VALUES
+(
+    'contact_verification_code_email',
+    'email',
+    'Verify your email address',
+    'Dear {{full_name}},
+
+Use this code to verify your email address for Nivo Money:
+
+{{otp}}
+
+The code is valid for {{validity_minutes}} minutes. Your wallet is activated once your email or phone is verified and your KYC is approved.
+
+Best regards,
+The Nivo Money Team',
+    1
+),
+(
+    'contact_verification_code_sms',
+    'sms',
+    '',
+    'Your Nivo Money code to verify this phone number is {{otp}}. Valid for {{validity_minutes}} minutes. Do not share this code. - Nivo Money',
+    1
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', NOW()
+FROM notification_templates
+WHERE name IN ('contact_verification_code_email', 'contact_verification_code_sms')
+ON CONFLICT (template_id, version) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Engagement Tracking Rollback
+
+DROP TABLE IF EXISTS notification_tracking_links;
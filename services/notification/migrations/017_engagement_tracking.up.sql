@@ -0,0 +1,22 @@
+-- Engagement Tracking
+-- Email notifications carry a tracking pixel and redirect links. Each gets a
+-- random ID so tracking URLs reveal nothing about the notification; hits are
+-- counted on the link and roll up into the notification stats.
+
+CREATE TABLE IF NOT EXISTS notification_tracking_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    notification_id UUID NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('open', 'click')),
+    url TEXT,                           -- Redirect target of click links; NULL for the open pixel
+    hits INTEGER NOT NULL DEFAULT 0,
+    first_hit_at TIMESTAMPTZ,
+    last_hit_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT notification_tracking_links_url_check CHECK ((kind = 'click') = (url IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_tracking_links_notification
+    ON notification_tracking_links(notification_id) WHERE hits > 0;
+
+COMMENT ON TABLE notification_tracking_links IS 'Open pixels and click redirects embedded in email notifications';
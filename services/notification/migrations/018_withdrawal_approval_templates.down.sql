@@ -0,0 +1,3 @@
+-- Withdrawal Approval Templates Rollback
+
+DELETE FROM notification_templates WHERE name = 'withdrawal_approval_push';
@@ -0,0 +1,20 @@
+-- Withdrawal Approval Templates
+-- Push notifications for withdrawals held for confirmation or admin approval:
+-- confirmation required, awaiting approval, released and cancelled
+
+INSERT INTO notification_templates (name, channel, subject_template, body_template, version)
+VALUES
+(
+    'withdrawal_approval_push',
+    'push',
+    'Withdrawal',
+    '{{message}}',
+    1
+)
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO notification_template_versions (template_id, version, subject_template, body_template, status, published_at)
+SELECT id, version, subject_template, body_template, 'published', NOW()
+FROM notification_templates
+WHERE name = 'withdrawal_approval_push'
+ON CONFLICT (template_id, version) DO NOTHING;
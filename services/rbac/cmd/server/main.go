@@ -2,16 +2,28 @@ package main
 
 import (
 	"net/http"
+	"os"
 
 	"github.com/1mb-dev/nivomoney/services/rbac/internal/handler"
 	"github.com/1mb-dev/nivomoney/services/rbac/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/rbac/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 func main() {
+	// Track Redis cache for cleanup
+	var redisCache *cache.RedisCache
+
 	server.Run(server.ServiceConfig{
 		Name: "rbac",
+		// Identity assigns roles and loads permissions at login
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/users": {"identity"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repository layer
 			rbacRepo := repository.NewRBACRepository(ctx.DB.DB)
@@ -19,14 +31,33 @@ func main() {
 			// Initialize service layer
 			rbacService := service.NewRBACService(rbacRepo)
 
+			// Permission cache: memory in front of Redis, or memory alone without REDIS_URL
+			permissionCache, redis, err := cache.Open(os.Getenv("REDIS_URL"), 0)
+			switch {
+			case err != nil:
+				ctx.Logger.WithError(err).Warn("Redis connection failed, using in-memory permission cache")
+			case redis != nil:
+				redisCache = redis
+				ctx.Logger.Info("Redis permission cache initialized successfully")
+			default:
+				ctx.Logger.Info("REDIS_URL not set, using in-memory permission cache")
+			}
+			rbacService.SetCache(permissionCache)
+
 			// Initialize handler layer
 			rbacHandler := handler.NewRBACHandler(rbacService)
 
 			// Get JWT secret and internal service secret for setup routes
 			jwtSecret := server.RequireEnv("JWT_SECRET")
-			internalSecret := server.GetEnv("INTERNAL_SERVICE_SECRET", "")
+			internalSecret := server.GetSecret("INTERNAL_SERVICE_SECRET", "")
 
 			return handler.SetupRoutes(rbacHandler, jwtSecret, internalSecret), nil
 		},
+		Cleanup: func() error {
+			if redisCache != nil {
+				return redisCache.Close()
+			}
+			return nil
+		},
 	})
 }
@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/rbac/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 )
@@ -44,7 +46,8 @@ type RBACRepositoryInterface interface {
 
 // RBACService handles all RBAC business logic.
 type RBACService struct {
-	repo RBACRepositoryInterface
+	repo        RBACRepositoryInterface
+	permissions *cache.Loader // Optional cache of each user's effective permissions
 }
 
 // NewRBACService creates a new RBAC service.
@@ -52,6 +55,20 @@ func NewRBACService(repo RBACRepositoryInterface) *RBACService {
 	return &RBACService{repo: repo}
 }
 
+// SetCache caches each user's effective permissions for cache.PermissionsTTL.
+// Role assignments evict the user's entry immediately; changes to a role's
+// permissions or parent apply to cached users once their entry expires.
+func (s *RBACService) SetCache(c cache.Cache) {
+	s.permissions = cache.NewLoader(c)
+}
+
+// forgetUserPermissions evicts a user's cached permissions.
+func (s *RBACService) forgetUserPermissions(ctx context.Context, userID string) {
+	if s.permissions != nil {
+		_ = s.permissions.Forget(ctx, cache.PermissionsKey(userID))
+	}
+}
+
 // ============================================================================
 // Role Operations
 // ============================================================================
@@ -340,6 +357,7 @@ func (s *RBACService) AssignRoleToUser(ctx context.Context, req *models.AssignRo
 	if err := s.repo.AssignRoleToUser(ctx, userRole); err != nil {
 		return nil, err
 	}
+	s.forgetUserPermissions(ctx, req.UserID)
 
 	// Load role details
 	userRole.Role = role
@@ -349,7 +367,11 @@ func (s *RBACService) AssignRoleToUser(ctx context.Context, req *models.AssignRo
 
 // RemoveRoleFromUser removes a role from a user.
 func (s *RBACService) RemoveRoleFromUser(ctx context.Context, userID, roleID string) *errors.Error {
-	return s.repo.RemoveRoleFromUser(ctx, userID, roleID)
+	if err := s.repo.RemoveRoleFromUser(ctx, userID, roleID); err != nil {
+		return err
+	}
+	s.forgetUserPermissions(ctx, userID)
+	return nil
 }
 
 // GetUserRoles retrieves all active roles for a user.
@@ -371,7 +393,41 @@ func (s *RBACService) GetUserRoles(ctx context.Context, userID string) ([]models
 }
 
 // GetUserPermissions retrieves all permissions for a user (includes hierarchy).
+// With a cache set, concurrent lookups for the same user share one load.
 func (s *RBACService) GetUserPermissions(ctx context.Context, userID string) (*models.UserPermissionsResponse, *errors.Error) {
+	if s.permissions == nil {
+		return s.loadUserPermissions(ctx, userID)
+	}
+
+	data, loadErr := s.permissions.GetOrLoad(ctx, cache.PermissionsKey(userID), cache.PermissionsTTL, func(ctx context.Context) (string, error) {
+		perms, err := s.loadUserPermissions(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		encoded, jsonErr := json.Marshal(perms)
+		if jsonErr != nil {
+			return "", jsonErr
+		}
+		return string(encoded), nil
+	})
+	if loadErr != nil {
+		var appErr *errors.Error
+		if errors.As(loadErr, &appErr) {
+			return nil, appErr
+		}
+		return nil, errors.InternalWrap(loadErr, "failed to load user permissions")
+	}
+
+	var perms models.UserPermissionsResponse
+	if err := json.Unmarshal([]byte(data), &perms); err != nil {
+		s.forgetUserPermissions(ctx, userID)
+		return s.loadUserPermissions(ctx, userID)
+	}
+	return &perms, nil
+}
+
+// loadUserPermissions loads a user's roles and effective permissions.
+func (s *RBACService) loadUserPermissions(ctx context.Context, userID string) (*models.UserPermissionsResponse, *errors.Error) {
 	// Get user roles
 	userRoles, err := s.repo.GetUserRoles(ctx, userID)
 	if err != nil {
@@ -407,7 +463,7 @@ func (s *RBACService) GetUserPermissions(ctx context.Context, userID string) (*m
 // CheckPermission checks if a user has a specific permission.
 func (s *RBACService) CheckPermission(ctx context.Context, req *models.CheckPermissionRequest) (*models.CheckPermissionResponse, *errors.Error) {
 	// Check if user has the permission (includes hierarchy)
-	hasPermission, err := s.repo.HasPermission(ctx, req.UserID, req.Permission)
+	hasPermission, err := s.HasPermission(ctx, req.UserID, req.Permission)
 	if err != nil {
 		return nil, err
 	}
@@ -418,15 +474,8 @@ func (s *RBACService) CheckPermission(ctx context.Context, req *models.CheckPerm
 
 	// If allowed, get the roles that granted it
 	if hasPermission {
-		userRoles, err := s.repo.GetUserRoles(ctx, req.UserID)
+		roleNames, err := s.userRoleNames(ctx, req.UserID)
 		if err == nil {
-			var roleNames []string
-			for _, ur := range userRoles {
-				role, roleErr := s.repo.GetRoleByID(ctx, ur.RoleID)
-				if roleErr == nil {
-					roleNames = append(roleNames, role.Name)
-				}
-			}
 			response.Roles = roleNames
 			response.Reason = "Permission granted via roles: " + fmt.Sprint(roleNames)
 		}
@@ -443,7 +492,7 @@ func (s *RBACService) CheckPermissions(ctx context.Context, req *models.CheckPer
 
 	// Check each permission
 	for _, permission := range req.Permissions {
-		hasPermission, err := s.repo.HasPermission(ctx, req.UserID, permission)
+		hasPermission, err := s.HasPermission(ctx, req.UserID, permission)
 		if err != nil {
 			return nil, err
 		}
@@ -451,19 +500,11 @@ func (s *RBACService) CheckPermissions(ctx context.Context, req *models.CheckPer
 	}
 
 	// Get user roles
-	userRoles, err := s.repo.GetUserRoles(ctx, req.UserID)
+	roleNames, err := s.userRoleNames(ctx, req.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	var roleNames []string
-	for _, ur := range userRoles {
-		role, roleErr := s.repo.GetRoleByID(ctx, ur.RoleID)
-		if roleErr == nil {
-			roleNames = append(roleNames, role.Name)
-		}
-	}
-
 	return &models.CheckPermissionsResponse{
 		Results: results,
 		Roles:   roleNames,
@@ -471,6 +512,47 @@ func (s *RBACService) CheckPermissions(ctx context.Context, req *models.CheckPer
 }
 
 // HasPermission is a convenience method for simple permission checks.
+// With a cache set, it is answered from the user's cached permissions.
 func (s *RBACService) HasPermission(ctx context.Context, userID, permission string) (bool, *errors.Error) {
-	return s.repo.HasPermission(ctx, userID, permission)
+	if s.permissions == nil {
+		return s.repo.HasPermission(ctx, userID, permission)
+	}
+
+	perms, err := s.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range perms.Permissions {
+		if p.Name == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// userRoleNames returns the names of a user's active roles.
+func (s *RBACService) userRoleNames(ctx context.Context, userID string) ([]string, *errors.Error) {
+	var roleNames []string
+	if s.permissions != nil {
+		perms, err := s.GetUserPermissions(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range perms.Roles {
+			roleNames = append(roleNames, role.Name)
+		}
+		return roleNames, nil
+	}
+
+	userRoles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ur := range userRoles {
+		role, roleErr := s.repo.GetRoleByID(ctx, ur.RoleID)
+		if roleErr == nil {
+			roleNames = append(roleNames, role.Name)
+		}
+	}
+	return roleNames, nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/rbac/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/cache"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 )
@@ -36,6 +37,9 @@ type mockRBACRepository struct {
 	deleteRoleFunc       func(ctx context.Context, id string) *errors.Error
 	createPermissionFunc func(ctx context.Context, perm *models.Permission) *errors.Error
 	hasPermissionFunc    func(ctx context.Context, userID, permission string) (bool, *errors.Error)
+
+	// Call counts
+	getUserPermissionsCalls int
 }
 
 func newMockRBACRepository() *mockRBACRepository {
@@ -362,6 +366,8 @@ func (m *mockRBACRepository) GetUserRoles(ctx context.Context, userID string) ([
 }
 
 func (m *mockRBACRepository) GetUserPermissions(ctx context.Context, userID string) ([]models.Permission, *errors.Error) {
+	m.getUserPermissionsCalls++
+
 	// Get user roles
 	userRoles, err := m.GetUserRoles(ctx, userID)
 	if err != nil {
@@ -1380,3 +1386,55 @@ func TestHasPermission_WithHierarchy(t *testing.T) {
 		t.Error("expected user to have inherited permission from parent role")
 	}
 }
+
+func TestUserPermissions_CachedUntilRoleAssignmentChanges(t *testing.T) {
+	repo := newMockRBACRepository()
+	service := NewRBACService(repo)
+	service.SetCache(cache.NewMemoryCache(100))
+	ctx := context.Background()
+
+	role, _ := service.CreateRole(ctx, &models.CreateRoleRequest{Name: "auditor", Description: "Auditor role"}, "admin")
+	perm, _ := service.CreatePermission(ctx, &models.CreatePermissionRequest{
+		Name:        "ledger:journal:read",
+		Service:     "ledger",
+		Resource:    "journal",
+		Action:      "read",
+		Description: "Read journal entries",
+	})
+	assignedBy := "admin_123"
+	_ = service.AssignPermissionToRole(ctx, role.ID, perm.ID, &assignedBy)
+
+	// No roles yet; the empty result is cached
+	allowed, err := service.HasPermission(ctx, "auditor_001", "ledger:journal:read")
+	if err != nil || allowed {
+		t.Fatalf("expected permission denied before assignment, got %v, %v", allowed, err)
+	}
+	if _, err := service.CheckPermissions(ctx, &models.CheckPermissionsRequest{
+		UserID:      "auditor_001",
+		Permissions: []string{"ledger:journal:read", "ledger:journal:create"},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.getUserPermissionsCalls != 1 {
+		t.Errorf("expected checks served from one cached load, got %d loads", repo.getUserPermissionsCalls)
+	}
+
+	// Assigning a role evicts the user's entry
+	_, _ = service.AssignRoleToUser(ctx, &models.AssignRoleToUserRequest{UserID: "auditor_001", RoleID: role.ID}, &assignedBy)
+	result, err := service.CheckPermission(ctx, &models.CheckPermissionRequest{UserID: "auditor_001", Permission: "ledger:journal:read"})
+	if err != nil || !result.Allowed {
+		t.Fatalf("expected permission granted after assignment, got %+v, %v", result, err)
+	}
+	if len(result.Roles) != 1 || result.Roles[0] != "auditor" {
+		t.Errorf("expected granting role auditor, got %v", result.Roles)
+	}
+	if repo.getUserPermissionsCalls != 2 {
+		t.Errorf("expected a reload after assignment, got %d loads", repo.getUserPermissionsCalls)
+	}
+
+	// And so does removing it
+	_ = service.RemoveRoleFromUser(ctx, "auditor_001", role.ID)
+	if allowed, _ := service.HasPermission(ctx, "auditor_001", "ledger:journal:read"); allowed {
+		t.Error("expected permission denied after removal")
+	}
+}
@@ -0,0 +1,8 @@
+DELETE FROM role_permissions WHERE permission_id IN (
+    '70000000-0000-0000-0000-000000000001',
+    '70000000-0000-0000-0000-000000000002'
+);
+DELETE FROM permissions WHERE id IN (
+    '70000000-0000-0000-0000-000000000001',
+    '70000000-0000-0000-0000-000000000002'
+);
@@ -0,0 +1,11 @@
+-- Card Network Simulation Permissions
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('70000000-0000-0000-0000-000000000001', 'cardnetwork:authorization:read', 'cardnetwork', 'authorization', 'read', 'View simulated card network authorizations', true),
+('70000000-0000-0000-0000-000000000002', 'cardnetwork:authorization:manage', 'cardnetwork', 'authorization', 'manage', 'Send, clear and simulate card network authorizations', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '70000000-0000-0000-0000-000000000001'),
+('00000000-0000-0000-0000-000000000005', '70000000-0000-0000-0000-000000000002')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id = '40000000-0000-0000-0000-000000000008';
+DELETE FROM permissions WHERE id = '40000000-0000-0000-0000-000000000008';
@@ -0,0 +1,9 @@
+-- Transaction Category Rule Permissions
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('40000000-0000-0000-0000-000000000008', 'transaction:category:manage', 'transaction', 'category', 'manage', 'Manage category patterns and re-categorize transactions', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '40000000-0000-0000-0000-000000000008')
+ON CONFLICT DO NOTHING;
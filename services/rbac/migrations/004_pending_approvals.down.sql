@@ -0,0 +1,12 @@
+DELETE FROM role_permissions WHERE permission_id IN (
+    '30000000-0000-0000-0000-000000000030',
+    '40000000-0000-0000-0000-000000000009',
+    '80000000-0000-0000-0000-000000000001'
+);
+DELETE FROM permissions WHERE id IN (
+    '30000000-0000-0000-0000-000000000030',
+    '40000000-0000-0000-0000-000000000009',
+    '80000000-0000-0000-0000-000000000001'
+);
+
+DROP TABLE IF EXISTS pending_approvals;
@@ -0,0 +1,42 @@
+-- Maker-checker approvals for sensitive admin operations.
+-- Shared by the ledger, transaction and risk services (see shared/approval).
+CREATE TABLE IF NOT EXISTS pending_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service VARCHAR(50) NOT NULL,
+    operation VARCHAR(100) NOT NULL,
+    resource_id VARCHAR(100) NOT NULL DEFAULT '',
+    summary TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'failed', 'rejected', 'expired')),
+    requested_by UUID NOT NULL,
+    reviewed_by UUID,
+    review_note TEXT,
+    result JSONB,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    reviewed_at TIMESTAMPTZ,
+
+    -- The reviewer must be a different admin than the requester
+    CONSTRAINT pending_approvals_second_admin CHECK (reviewed_by IS NULL OR reviewed_by <> requested_by)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_service_status ON pending_approvals(service, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_pending_approvals_requested_by ON pending_approvals(requested_by);
+
+COMMENT ON TABLE pending_approvals IS 'Sensitive operations waiting for, or decided by, a second admin';
+
+-- Approval Review Permissions
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000030', 'ledger:approval:review', 'ledger', 'approval', 'review', 'Approve or reject journal entry voids', true),
+('40000000-0000-0000-0000-000000000009', 'transaction:approval:review', 'transaction', 'approval', 'review', 'Approve or reject large transaction reversals', true),
+('80000000-0000-0000-0000-000000000001', 'risk:approval:review', 'risk', 'approval', 'review', 'Approve or reject risk rule changes', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000030'),
+('00000000-0000-0000-0000-000000000005', '40000000-0000-0000-0000-000000000009'),
+('00000000-0000-0000-0000-000000000005', '80000000-0000-0000-0000-000000000001')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id = '30000000-0000-0000-0000-000000000031';
+DELETE FROM permissions WHERE id = '30000000-0000-0000-0000-000000000031';
@@ -0,0 +1,11 @@
+-- Ledger suspense clearing permission
+-- Lets finance re-post amounts parked in the suspense account.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000031', 'ledger:suspense:clear', 'ledger', 'suspense', 'clear', 'Clear suspense items to their correct accounts', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000031')
+ON CONFLICT DO NOTHING;
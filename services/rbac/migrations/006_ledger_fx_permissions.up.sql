@@ -0,0 +1,11 @@
+-- Ledger FX permission
+-- Lets finance record period-end FX rates and run revaluations.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000032', 'ledger:fx:manage', 'ledger', 'fx', 'manage', 'Record FX rates and run period-end revaluations', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000032')
+ON CONFLICT DO NOTHING;
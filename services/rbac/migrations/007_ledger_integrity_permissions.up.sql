@@ -0,0 +1,11 @@
+-- Ledger integrity permission
+-- Lets admins reset accounts whose balances drifted from their ledger lines.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000033', 'ledger:integrity:repair', 'ledger', 'integrity', 'repair', 'Repair account balances that drifted from their ledger lines', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000033')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,12 @@
+-- Gateway usage permission
+-- Lets support and admins read per-user API usage at the gateway.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000034', 'gateway:usage:view', 'gateway', 'usage', 'view', 'View per-user API usage and top users', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- SUPPORT and ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000002', '30000000-0000-0000-0000-000000000034'),
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000034')
+ON CONFLICT DO NOTHING;
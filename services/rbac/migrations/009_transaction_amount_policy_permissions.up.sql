@@ -0,0 +1,11 @@
+-- Transaction amount policy permission
+-- Lets admins set the minimum and maximum amounts per operation and currency.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('30000000-0000-0000-0000-000000000035', 'transaction:amount_policy:manage', 'transaction', 'amount_policy', 'manage', 'Manage minimum and maximum transaction amounts', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '30000000-0000-0000-0000-000000000035')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id = '80000000-0000-0000-0000-000000000002';
+DELETE FROM permissions WHERE id = '80000000-0000-0000-0000-000000000002';
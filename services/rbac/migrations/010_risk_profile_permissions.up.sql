@@ -0,0 +1,11 @@
+-- Risk profile permission
+-- Lets admins read a user's risk profile: accumulated signals and baseline score.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('80000000-0000-0000-0000-000000000002', 'risk:profile:read', 'risk', 'profile', 'read', 'View user risk profiles and signals', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '80000000-0000-0000-0000-000000000002')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id = '10000000-0000-0000-0000-000000000050';
+DELETE FROM permissions WHERE id = '10000000-0000-0000-0000-000000000050';
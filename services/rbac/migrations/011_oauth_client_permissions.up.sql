@@ -0,0 +1,12 @@
+-- OAuth client permission
+-- Lets admins register and revoke the applications that sign users in
+-- through the identity service's OIDC provider.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('10000000-0000-0000-0000-000000000050', 'identity:oauth_clients:manage', 'identity', 'oauth_clients', 'manage', 'Register and revoke OAuth clients', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '10000000-0000-0000-0000-000000000050')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+DELETE FROM role_permissions WHERE permission_id IN ('80000000-0000-0000-0000-000000000003', '80000000-0000-0000-0000-000000000004');
+DELETE FROM permissions WHERE id IN ('80000000-0000-0000-0000-000000000003', '80000000-0000-0000-0000-000000000004');
@@ -0,0 +1,14 @@
+-- Risk baseline permissions
+-- Lets admins read a user's adaptive spending baseline and run the baseline
+-- recompute on demand.
+
+INSERT INTO permissions (id, name, service, resource, action, description, is_system) VALUES
+('80000000-0000-0000-0000-000000000003', 'risk:baseline:read', 'risk', 'baseline', 'read', 'View user spending baselines', true),
+('80000000-0000-0000-0000-000000000004', 'risk:baseline:recompute', 'risk', 'baseline', 'recompute', 'Recompute user spending baselines', true)
+ON CONFLICT (name) DO NOTHING;
+
+-- ADMIN Role Permissions
+INSERT INTO role_permissions (role_id, permission_id) VALUES
+('00000000-0000-0000-0000-000000000005', '80000000-0000-0000-0000-000000000003'),
+('00000000-0000-0000-0000-000000000005', '80000000-0000-0000-0000-000000000004')
+ON CONFLICT DO NOTHING;
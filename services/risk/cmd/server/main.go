@@ -1,24 +1,120 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/risk/internal/handler"
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
 	"github.com/1mb-dev/nivomoney/services/risk/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/risk/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 func main() {
 	server.Run(server.ServiceConfig{
 		Name: "risk",
+		// Transaction screens bulk transfers
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/evaluate": {"transaction"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repositories
 			ruleRepo := repository.NewRiskRuleRepository(ctx.DB.DB)
 			eventRepo := repository.NewRiskEventRepository(ctx.DB.DB)
+			baselineRepo := repository.NewBaselineRepository(ctx.DB.DB)
 
 			// Initialize services
-			riskService := service.NewRiskService(ruleRepo, eventRepo)
+			riskService := service.NewRiskService(ruleRepo, eventRepo, baselineRepo)
+
+			// Enabled rules are cached between evaluations and reloaded on rule changes
+			riskService.SetRuleCacheTTL(time.Duration(server.GetEnvInt("RISK_RULE_CACHE_TTL_SECONDS", int(service.DefaultRuleCacheTTL.Seconds()))) * time.Second)
+
+			// Rule changes need a second admin's approval
+			riskService.SetRuleApprovals(approval.NewManager("risk", approval.NewPostgresStore(ctx.DB.DB)))
+
+			// Alert rule notification targets through the notification service
+			notificationClient := clients.NewNotificationClient(server.GetEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8087"))
+			riskService.SetNotificationClient(notificationClient)
+
+			// Optionally blend an external model score into evaluations
+			scorer, policy, err := loadScorer()
+			if err != nil {
+				return nil, err
+			}
+			if scorer != nil {
+				riskService.SetScorer(scorer, policy)
+				ctx.Logger.WithField("scorer", scorer.Name()).
+					WithField("timeout_ms", policy.Timeout.Milliseconds()).
+					WithField("fail_closed", policy.FailClosed).
+					WithField("weight", policy.Weight).
+					Info("External risk scorer enabled")
+			}
+
+			// Start nightly baseline recompute worker
+			recomputeHour := server.GetEnvInt("RISK_BASELINE_RECOMPUTE_HOUR", 2)
+			windowWeeks := server.GetEnvInt("RISK_BASELINE_WINDOW_WEEKS", models.DefaultBaselineWindowWeeks)
+			if recomputeHour < 0 || recomputeHour > 23 {
+				return nil, fmt.Errorf("RISK_BASELINE_RECOMPUTE_HOUR must be between 0 and 23, got %d", recomputeHour)
+			}
+
+			ctx.Lifecycle.Go("baseline-recompute", func(workerCtx context.Context) {
+				ctx.Logger.WithField("hour_utc", recomputeHour).
+					WithField("window_weeks", windowWeeks).
+					Info("Starting nightly baseline recompute worker...")
+
+				for {
+					timer := time.NewTimer(untilNextRun(time.Now().UTC(), recomputeHour))
+					select {
+					case <-timer.C:
+						// A recompute already running finishes even if shutdown starts
+						if _, err := riskService.RecomputeBaselines(context.WithoutCancel(workerCtx), windowWeeks); err != nil {
+							ctx.Logger.WithError(err).Error("Baseline recompute failed")
+						}
+					case <-workerCtx.Done():
+						timer.Stop()
+						ctx.Logger.Info("Baseline recompute worker stopped")
+						return
+					}
+				}
+			})
+
+			// Prune per-user activity rollups older than velocity and daily limit checks need
+			ctx.Lifecycle.Every("activity-prune", time.Hour, func(workerCtx context.Context) error {
+				deleted, err := eventRepo.PruneActivity(workerCtx, time.Now().Add(-models.ActivityRetention))
+				if err != nil {
+					return err
+				}
+				if deleted > 0 {
+					ctx.Logger.WithField("deleted", deleted).Info("Pruned user activity rollups")
+				}
+				return nil
+			})
+
+			// Geo rules resolve client IPs from an optional CIDR table and
+			// compare against each user's last location for impossible travel
+			var geoResolver service.GeoResolver
+			if path := os.Getenv("RISK_GEOIP_DATABASE"); path != "" {
+				resolver, err := service.NewCIDRResolverFromFile(path)
+				if err != nil {
+					return nil, err
+				}
+				geoResolver = resolver
+				ctx.Logger.WithField("path", path).Info("Geo IP database loaded")
+			}
+			riskService.SetGeo(geoResolver, repository.NewLocationRepository(ctx.DB.DB))
+
+			// Per-user risk profiles feed a baseline score into every evaluation
+			riskService.SetProfiles(repository.NewProfileRepository(ctx.DB.DB))
 
 			// Initialize router
 			router := handler.NewRouter(riskService)
@@ -27,3 +123,59 @@ func main() {
 		},
 	})
 }
+
+// loadScorer configures the external scorer from RISK_SCORER ("http", "stub"
+// or empty for none) and the RISK_SCORER_* policy variables.
+func loadScorer() (service.Scorer, service.ScorerPolicy, error) {
+	policy := service.DefaultScorerPolicy()
+	policy.Timeout = time.Duration(server.GetEnvInt("RISK_SCORER_TIMEOUT_MS", int(policy.Timeout.Milliseconds()))) * time.Millisecond
+	policy.FlagScore = server.GetEnvInt("RISK_SCORER_FLAG_SCORE", policy.FlagScore)
+	policy.BlockScore = server.GetEnvInt("RISK_SCORER_BLOCK_SCORE", policy.BlockScore)
+	if val := os.Getenv("RISK_SCORER_WEIGHT"); val != "" {
+		weight, err := strconv.ParseFloat(val, 64)
+		if err != nil || weight < 0 || weight > 1 {
+			return nil, policy, fmt.Errorf("RISK_SCORER_WEIGHT must be between 0 and 1, got %q", val)
+		}
+		policy.Weight = weight
+	}
+
+	switch failure := server.GetEnv("RISK_SCORER_FAILURE_POLICY", "open"); failure {
+	case "open":
+	case "closed":
+		policy.FailClosed = true
+	default:
+		return nil, policy, fmt.Errorf("RISK_SCORER_FAILURE_POLICY must be open or closed, got %q", failure)
+	}
+
+	if policy.Timeout <= 0 {
+		return nil, policy, fmt.Errorf("RISK_SCORER_TIMEOUT_MS must be positive")
+	}
+	if policy.FlagScore > policy.BlockScore {
+		return nil, policy, fmt.Errorf("RISK_SCORER_FLAG_SCORE must not exceed RISK_SCORER_BLOCK_SCORE")
+	}
+
+	switch kind := os.Getenv("RISK_SCORER"); kind {
+	case "":
+		return nil, policy, nil
+	case "stub":
+		latency := time.Duration(server.GetEnvInt("RISK_SCORER_STUB_LATENCY_MS", 20)) * time.Millisecond
+		return service.NewStubScorer(latency), policy, nil
+	case "http":
+		url := os.Getenv("RISK_SCORER_URL")
+		if url == "" {
+			return nil, policy, fmt.Errorf("RISK_SCORER_URL is required when RISK_SCORER=http")
+		}
+		return service.NewHTTPScorer(url, policy.Timeout), policy, nil
+	default:
+		return nil, policy, fmt.Errorf("unknown RISK_SCORER %q (use http or stub)", kind)
+	}
+}
+
+// untilNextRun returns the duration until the next occurrence of hour:00 UTC.
+func untilNextRun(now time.Time, hour int) time.Duration {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next.Sub(now)
+}
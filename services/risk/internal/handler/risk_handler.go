@@ -8,7 +8,9 @@ import (
 
 	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
 	"github.com/1mb-dev/nivomoney/services/risk/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
 	"github.com/1mb-dev/nivomoney/shared/response"
 )
 
@@ -41,31 +43,93 @@ func (h *RiskHandler) EvaluateTransaction(w http.ResponseWriter, r *http.Request
 	}
 
 	// Validate request
-	if req.TransactionID == "" {
-		response.Error(w, errors.Validation("transaction_id is required"))
+	if valErr := validateEvaluationRequest(&req); valErr != nil {
+		response.Error(w, valErr)
 		return
 	}
-	if req.UserID == "" {
-		response.Error(w, errors.Validation("user_id is required"))
+
+	// Evaluate transaction
+	result, svcErr := h.riskService.EvaluateTransaction(r.Context(), &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
 		return
 	}
-	if req.Amount <= 0 {
-		response.Error(w, errors.Validation("amount must be greater than 0"))
+
+	response.OK(w, result)
+}
+
+// EvaluateBatch handles POST /internal/v1/evaluate/batch
+// Invalid transactions are reported per item; the rest of the batch is still evaluated.
+func (h *RiskHandler) EvaluateBatch(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
 		return
 	}
-	if req.Currency == "" {
-		response.Error(w, errors.Validation("currency is required"))
+
+	var req models.BatchEvaluationRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.Validation(err.Error()))
 		return
 	}
 
-	// Evaluate transaction
-	result, svcErr := h.riskService.EvaluateTransaction(r.Context(), &req)
-	if svcErr != nil {
-		response.Error(w, svcErr)
+	if len(req.Evaluations) == 0 {
+		response.Error(w, errors.Validation("evaluations is required"))
+		return
+	}
+	if len(req.Evaluations) > models.MaxBatchEvaluations {
+		response.Error(w, errors.Validation(fmt.Sprintf("at most %d evaluations per batch", models.MaxBatchEvaluations)))
 		return
 	}
 
-	response.OK(w, result)
+	// Evaluate the valid transactions, keeping request order in the results
+	valid := make([]models.EvaluationRequest, 0, len(req.Evaluations))
+	invalid := make(map[int]*errors.Error)
+	for i := range req.Evaluations {
+		if valErr := validateEvaluationRequest(&req.Evaluations[i]); valErr != nil {
+			invalid[i] = valErr
+			continue
+		}
+		valid = append(valid, req.Evaluations[i])
+	}
+
+	batch := h.riskService.EvaluateBatch(r.Context(), valid)
+	if len(invalid) > 0 {
+		results := make([]models.BatchEvaluationItem, 0, len(req.Evaluations))
+		next := 0
+		for i := range req.Evaluations {
+			if valErr, ok := invalid[i]; ok {
+				results = append(results, models.BatchEvaluationItem{
+					TransactionID: req.Evaluations[i].TransactionID,
+					Error:         &models.BatchItemError{Code: string(valErr.Code), Message: valErr.Message},
+				})
+				continue
+			}
+			results = append(results, batch.Results[next])
+			next++
+		}
+		batch.Results = results
+		batch.Failed += len(invalid)
+	}
+
+	response.OK(w, batch)
+}
+
+// validateEvaluationRequest checks the fields every evaluation needs
+func validateEvaluationRequest(req *models.EvaluationRequest) *errors.Error {
+	if req.TransactionID == "" {
+		return errors.Validation("transaction_id is required")
+	}
+	if req.UserID == "" {
+		return errors.Validation("user_id is required")
+	}
+	if req.Amount <= 0 {
+		return errors.Validation("amount must be greater than 0")
+	}
+	if req.Currency == "" {
+		return errors.Validation("currency is required")
+	}
+	return nil
 }
 
 // GetRuleByID handles GET /api/v1/risk/rules/:id
@@ -99,6 +163,7 @@ func (h *RiskHandler) GetAllRules(w http.ResponseWriter, r *http.Request) {
 }
 
 // CreateRule handles POST /api/v1/risk/rules
+// With approvals enabled it returns 202 and the pending approval instead.
 func (h *RiskHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	// Read request body
 	body, err := io.ReadAll(r.Body)
@@ -128,6 +193,14 @@ func (h *RiskHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Rule changes wait for a second admin when approvals are enabled
+	if h.riskService.Approvals() != nil {
+		requestedBy, _ := middleware.GetUserID(r.Context())
+		pending, svcErr := h.riskService.RequestCreateRule(r.Context(), &rule, requestedBy)
+		respondPending(w, pending, svcErr)
+		return
+	}
+
 	// Create rule
 	if svcErr := h.riskService.CreateRule(r.Context(), &rule); svcErr != nil {
 		response.Error(w, svcErr)
@@ -161,6 +234,13 @@ func (h *RiskHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 
 	rule.ID = id
 
+	if h.riskService.Approvals() != nil {
+		requestedBy, _ := middleware.GetUserID(r.Context())
+		pending, svcErr := h.riskService.RequestUpdateRule(r.Context(), &rule, requestedBy)
+		respondPending(w, pending, svcErr)
+		return
+	}
+
 	// Update rule
 	if svcErr := h.riskService.UpdateRule(r.Context(), &rule); svcErr != nil {
 		response.Error(w, svcErr)
@@ -178,6 +258,13 @@ func (h *RiskHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.riskService.Approvals() != nil {
+		requestedBy, _ := middleware.GetUserID(r.Context())
+		pending, svcErr := h.riskService.RequestDeleteRule(r.Context(), id, requestedBy)
+		respondPending(w, pending, svcErr)
+		return
+	}
+
 	if err := h.riskService.DeleteRule(r.Context(), id); err != nil {
 		response.Error(w, err)
 		return
@@ -186,6 +273,15 @@ func (h *RiskHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	response.NoContent(w)
 }
 
+// respondPending writes 202 Accepted with an approval waiting for a second admin.
+func respondPending(w http.ResponseWriter, pending *approval.Approval, err *errors.Error) {
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	response.JSON(w, http.StatusAccepted, pending)
+}
+
 // GetEventByID handles GET /api/v1/risk/events/:id
 func (h *RiskHandler) GetEventByID(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -245,3 +341,95 @@ func (h *RiskHandler) GetEventsByUserID(w http.ResponseWriter, r *http.Request)
 
 	response.OK(w, events)
 }
+
+// GetUserBaseline handles GET /api/v1/risk/users/:userId/baseline
+func (h *RiskHandler) GetUserBaseline(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user ID is required"))
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		currency = "INR"
+	}
+
+	baseline, err := h.riskService.GetUserBaseline(r.Context(), userID, currency)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, baseline)
+}
+
+// RecomputeBaselines handles POST /api/v1/risk/baselines/recompute
+func (h *RiskHandler) RecomputeBaselines(w http.ResponseWriter, r *http.Request) {
+	windowWeeks := 0
+	if weeksStr := r.URL.Query().Get("window_weeks"); weeksStr != "" {
+		if _, err := fmt.Sscanf(weeksStr, "%d", &windowWeeks); err != nil || windowWeeks <= 0 {
+			response.Error(w, errors.Validation("window_weeks must be a positive integer"))
+			return
+		}
+	}
+
+	result, err := h.riskService.RecomputeBaselines(r.Context(), windowWeeks)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetRiskProfile handles GET /api/v1/users/:userId/risk-profile
+func (h *RiskHandler) GetRiskProfile(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user ID is required"))
+		return
+	}
+
+	profile, err := h.riskService.GetRiskProfile(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, profile)
+}
+
+// RecordRiskSignal handles POST /internal/v1/users/:userId/risk-signals.
+// A newly recorded signal returns 201; one already recorded returns 200.
+func (h *RiskHandler) RecordRiskSignal(w http.ResponseWriter, r *http.Request) {
+	userID := r.PathValue("userId")
+	if userID == "" {
+		response.Error(w, errors.BadRequest("user ID is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+
+	var req models.RecordSignalRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.Validation(err.Error()))
+		return
+	}
+
+	signal, created, svcErr := h.riskService.RecordSignal(r.Context(), userID, &req)
+	if svcErr != nil {
+		response.Error(w, svcErr)
+		return
+	}
+
+	if created {
+		response.Created(w, signal)
+		return
+	}
+	response.OK(w, signal)
+}
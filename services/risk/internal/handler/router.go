@@ -2,9 +2,10 @@ package handler
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/1mb-dev/nivomoney/services/risk/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/logger"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
 	"github.com/1mb-dev/nivomoney/shared/middleware"
@@ -12,16 +13,21 @@ import (
 
 // Router handles HTTP routing for the Risk Service
 type Router struct {
-	riskHandler *RiskHandler
-	metrics     *metrics.Collector
+	riskHandler     *RiskHandler
+	approvalHandler *approval.Handler // nil when rule changes need no approval
+	metrics         *metrics.Collector
 }
 
 // NewRouter creates a new router
 func NewRouter(riskService *service.RiskService) *Router {
-	return &Router{
+	r := &Router{
 		riskHandler: NewRiskHandler(riskService),
 		metrics:     metrics.NewCollector("risk"),
 	}
+	if approvals := riskService.Approvals(); approvals != nil {
+		r.approvalHandler = approval.NewHandler(approvals)
+	}
+	return r
 }
 
 // SetupRoutes sets up all HTTP routes
@@ -37,9 +43,17 @@ func (r *Router) SetupRoutes() http.Handler {
 	// Risk evaluation endpoint (called by transaction service - internal only)
 	mux.HandleFunc("POST /api/v1/risk/evaluate", r.riskHandler.EvaluateTransaction)
 
+	// Batch risk evaluation (called by the transaction service for bulk transfers)
+	mux.HandleFunc("POST /internal/v1/evaluate/batch",
+		middleware.InternalAuthFunc(config.Secret("INTERNAL_SERVICE_SECRET"), r.riskHandler.EvaluateBatch))
+
+	// Risk signals reported by other services (chargebacks, KYC flags, device changes)
+	mux.HandleFunc("POST /internal/v1/users/{userId}/risk-signals",
+		middleware.InternalAuthFunc(config.Secret("INTERNAL_SERVICE_SECRET"), r.riskHandler.RecordRiskSignal))
+
 	// Create JWT auth middleware for admin endpoints
 	authConfig := middleware.AuthConfig{
-		JWTSecret: os.Getenv("JWT_SECRET"),
+		JWTSecret: config.Secret("JWT_SECRET"),
 	}
 	jwtAuth := middleware.Auth(authConfig)
 
@@ -50,16 +64,35 @@ func (r *Router) SetupRoutes() http.Handler {
 	mux.Handle("PUT /api/v1/risk/rules/{id}", jwtAuth(http.HandlerFunc(r.riskHandler.UpdateRule)))
 	mux.Handle("DELETE /api/v1/risk/rules/{id}", jwtAuth(http.HandlerFunc(r.riskHandler.DeleteRule)))
 
+	// Rule change approvals (requested by one admin, decided by another)
+	if r.approvalHandler != nil {
+		reviewPermission := middleware.RequirePermission("risk:approval:review")
+		mux.Handle("GET /api/v1/risk/approvals", jwtAuth(reviewPermission(http.HandlerFunc(r.approvalHandler.List))))
+		mux.Handle("GET /api/v1/risk/approvals/{id}", jwtAuth(reviewPermission(http.HandlerFunc(r.approvalHandler.Get))))
+		mux.Handle("POST /api/v1/risk/approvals/{id}/approve", jwtAuth(reviewPermission(http.HandlerFunc(r.approvalHandler.Approve))))
+		mux.Handle("POST /api/v1/risk/approvals/{id}/reject", jwtAuth(reviewPermission(http.HandlerFunc(r.approvalHandler.Reject))))
+	}
+
 	// Risk events endpoints (require authentication)
 	mux.Handle("GET /api/v1/risk/events/{id}", jwtAuth(http.HandlerFunc(r.riskHandler.GetEventByID)))
 	mux.Handle("GET /api/v1/risk/transactions/{transactionId}/events", jwtAuth(http.HandlerFunc(r.riskHandler.GetEventsByTransactionID)))
 	mux.Handle("GET /api/v1/risk/users/{userId}/events", jwtAuth(http.HandlerFunc(r.riskHandler.GetEventsByUserID)))
 
+	// Adaptive baseline endpoints
+	baselineReadPermission := middleware.RequirePermission("risk:baseline:read")
+	baselineRecomputePermission := middleware.RequirePermission("risk:baseline:recompute")
+	mux.Handle("GET /api/v1/risk/users/{userId}/baseline", jwtAuth(baselineReadPermission(http.HandlerFunc(r.riskHandler.GetUserBaseline))))
+	mux.Handle("POST /api/v1/risk/baselines/recompute", jwtAuth(baselineRecomputePermission(http.HandlerFunc(r.riskHandler.RecomputeBaselines))))
+
+	// User risk profiles (the gateway routes /api/v1/users/{id}/risk-profile here)
+	profilePermission := middleware.RequirePermission("risk:profile:read")
+	mux.Handle("GET /api/v1/users/{userId}/risk-profile", jwtAuth(profilePermission(http.HandlerFunc(r.riskHandler.GetRiskProfile))))
+
 	// Create logger for middleware
 	log := logger.NewDefault("risk")
 
 	// Apply middleware using Chain
-	handler := middleware.Chain(mux,
+	handler := middleware.Chain(metrics.RecordRoute(mux),
 		r.metrics.Middleware("risk"),
 		middleware.RequestID(),
 		middleware.Logging(log),
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+// NetworkType classifies the network an IP address belongs to
+type NetworkType string
+
+const (
+	NetworkResidential NetworkType = "residential" // Consumer ISP or mobile carrier
+	NetworkDatacenter  NetworkType = "datacenter"  // Hosting or cloud provider
+	NetworkVPN         NetworkType = "vpn"         // VPN or anonymizing proxy exit
+	NetworkTor         NetworkType = "tor"         // Tor exit node
+)
+
+// GeoLocation is what a resolver knows about a client IP
+type GeoLocation struct {
+	Country   string      `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Latitude  *float64    `json:"latitude,omitempty"`
+	Longitude *float64    `json:"longitude,omitempty"`
+	Network   NetworkType `json:"network,omitempty"`
+}
+
+// HasCoordinates reports whether the location can be used for distance checks
+func (l *GeoLocation) HasCoordinates() bool {
+	return l != nil && l.Latitude != nil && l.Longitude != nil
+}
+
+// UserLocation is the last location a user transacted from
+type UserLocation struct {
+	UserID    string    `json:"user_id" db:"user_id"`
+	IP        string    `json:"ip" db:"ip"`
+	Country   string    `json:"country,omitempty" db:"country"`
+	Latitude  *float64  `json:"latitude,omitempty" db:"latitude"`
+	Longitude *float64  `json:"longitude,omitempty" db:"longitude"`
+	SeenAt    time.Time `json:"seen_at" db:"seen_at"`
+}
+
+// DefaultMaxTravelKmh is a little over airliner cruising speed
+const DefaultMaxTravelKmh = 1000
+
+// DefaultMinTravelKm ignores jumps within the precision of IP geolocation
+const DefaultMinTravelKm = 300
+
+// GeoRuleParams represents parameters for a geo/IP rule. Each configured check
+// can trigger the rule on its own.
+type GeoRuleParams struct {
+	BlockedCountries []string      `json:"blocked_countries,omitempty"` // Trigger for these countries
+	AllowedCountries []string      `json:"allowed_countries,omitempty"` // Trigger for any other known country
+	BlockedNetworks  []NetworkType `json:"blocked_networks,omitempty"`  // e.g. datacenter, vpn, tor
+	BlockedCIDRs     []string      `json:"blocked_cidrs,omitempty"`     // Explicit IP ranges
+	ImpossibleTravel bool          `json:"impossible_travel,omitempty"` // Compare with the user's last location
+	MaxTravelKmh     float64       `json:"max_travel_kmh,omitempty"`    // Fastest plausible travel (default 1000)
+	MinTravelKm      float64       `json:"min_travel_km,omitempty"`     // Shorter jumps are ignored (default 300)
+}
+
+// Validate checks the parameters can be evaluated
+func (p *GeoRuleParams) Validate() error {
+	for _, list := range [][]string{p.BlockedCountries, p.AllowedCountries} {
+		for _, country := range list {
+			if len(country) != 2 {
+				return fmt.Errorf("country %q must be an ISO 3166-1 alpha-2 code", country)
+			}
+		}
+	}
+	for _, network := range p.BlockedNetworks {
+		switch network {
+		case NetworkResidential, NetworkDatacenter, NetworkVPN, NetworkTor:
+		default:
+			return fmt.Errorf("unknown network type %q", network)
+		}
+	}
+	for _, cidr := range p.BlockedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR %q", cidr)
+		}
+	}
+	if p.MaxTravelKmh < 0 || p.MinTravelKm < 0 {
+		return fmt.Errorf("travel limits must not be negative")
+	}
+	if len(p.BlockedCountries) == 0 && len(p.AllowedCountries) == 0 && len(p.BlockedNetworks) == 0 &&
+		len(p.BlockedCIDRs) == 0 && !p.ImpossibleTravel {
+		return fmt.Errorf("geo rule must configure at least one check")
+	}
+	return nil
+}
+
+// TravelLimits returns the travel thresholds with defaults applied
+func (p *GeoRuleParams) TravelLimits() (maxKmh, minKm float64) {
+	maxKmh, minKm = p.MaxTravelKmh, p.MinTravelKm
+	if maxKmh == 0 {
+		maxKmh = DefaultMaxTravelKmh
+	}
+	if minKm == 0 {
+		minKm = DefaultMinTravelKm
+	}
+	return maxKmh, minKm
+}
+
+// ContainsCountry reports whether a country code is in the list, ignoring case
+func ContainsCountry(list []string, country string) bool {
+	for _, c := range list {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+// DistanceKm returns the great-circle distance between two points
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -27,14 +27,140 @@ type EvaluationRequest struct {
 	TransactionType string `json:"transaction_type"` // transfer, deposit, withdrawal
 	FromWalletID    string `json:"from_wallet_id,omitempty"`
 	ToWalletID      string `json:"to_wallet_id,omitempty"`
+	ClientIP        string `json:"client_ip,omitempty"`      // IP the request reached the gateway from
+	ClientCountry   string `json:"client_country,omitempty"` // Country reported by the edge, if any
 }
 
 // EvaluationResult represents the result of a risk evaluation
 type EvaluationResult struct {
-	Allowed        bool       `json:"allowed"`         // Whether transaction is allowed
-	Action         RiskAction `json:"action"`          // Action to take
-	RiskScore      int        `json:"risk_score"`      // Risk score (0-100)
-	Reason         string     `json:"reason"`          // Human-readable reason
-	TriggeredRules []string   `json:"triggered_rules"` // IDs of rules that were triggered
-	EventID        string     `json:"event_id"`        // ID of the risk event created
+	Allowed        bool          `json:"allowed"`          // Whether transaction is allowed
+	Action         RiskAction    `json:"action"`           // Action to take
+	RiskScore      int           `json:"risk_score"`       // Risk score (0-100)
+	BaselineScore  int           `json:"baseline_score"`   // User's risk profile score, included in RiskScore
+	Reason         string        `json:"reason"`           // Human-readable reason
+	TriggeredRules []string      `json:"triggered_rules"`  // IDs of rules that were triggered
+	EventID        string        `json:"event_id"`         // ID of the risk event created
+	Model          *ModelResult  `json:"model,omitempty"`  // External scorer contribution, when configured
+	Shadow         *ShadowResult `json:"shadow,omitempty"` // Shadow rules that triggered; never part of the outcome
+}
+
+// ShadowMatch is a shadow rule that triggered during an evaluation
+type ShadowMatch struct {
+	RuleID string     `json:"rule_id"`
+	Name   string     `json:"name"`
+	Action RiskAction `json:"action"` // Action the rule would have taken
+	Score  int        `json:"score"`
+	Reason string     `json:"reason"`
+}
+
+// ShadowResult is what shadow rules would have done to an evaluation had
+// they been enforced
+type ShadowResult struct {
+	Triggered     []ShadowMatch `json:"triggered"`
+	WouldBeAction RiskAction    `json:"would_be_action"` // Outcome with shadow rules enforced
+	WouldBeScore  int           `json:"would_be_score"`
+	Changed       bool          `json:"changed"` // Whether enforcing them would change the action
+}
+
+// ModelScore is the answer of an external scoring model
+type ModelScore struct {
+	Score        int      `json:"score"`                   // Risk score (0-100)
+	ModelVersion string   `json:"model_version,omitempty"` // Version of the model that scored
+	Reasons      []string `json:"reasons,omitempty"`       // Model-specific reason codes
+}
+
+// ScorerStatus records how the external scorer contributed to an evaluation
+type ScorerStatus string
+
+const (
+	ScorerStatusScored       ScorerStatus = "scored"        // Score blended into the result
+	ScorerStatusFailedOpen   ScorerStatus = "failed_open"   // Scorer failed, rule result kept
+	ScorerStatusFailedClosed ScorerStatus = "failed_closed" // Scorer failed, transaction blocked
+)
+
+// ModelResult describes the external scorer's part in an evaluation
+type ModelResult struct {
+	Scorer       string       `json:"scorer"`
+	Status       ScorerStatus `json:"status"`
+	Score        *int         `json:"score,omitempty"`
+	ModelVersion string       `json:"model_version,omitempty"`
+	Reasons      []string     `json:"reasons,omitempty"`
+	RuleScore    int          `json:"rule_score"` // Rule-based score before blending
+	Weight       float64      `json:"weight"`     // Share of the model score in the blend
+	LatencyMs    float64      `json:"latency_ms"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// ActivityRetention is how long per-user activity rollups are kept. Velocity
+// windows longer than this only see the retained activity.
+const ActivityRetention = 7 * 24 * time.Hour
+
+// ActivityBucket is a user's evaluated transactions in one minute
+type ActivityBucket struct {
+	Start        time.Time `json:"start"`
+	Transactions int       `json:"transactions"`
+	Amount       int64     `json:"amount"` // Transactions that were not blocked
+}
+
+// UserActivity is a user's recent activity rollups, loaded once per evaluation
+type UserActivity struct {
+	Now      time.Time        // Database time the activity was read at
+	DayStart time.Time        // Start of the database's current day
+	Buckets  []ActivityBucket // Oldest first
+}
+
+// TransactionsSince returns the transactions evaluated in the last windowMins
+// minutes, to the minute.
+func (a *UserActivity) TransactionsSince(windowMins int) int {
+	cutoff := a.Now.Add(-time.Duration(windowMins) * time.Minute).Truncate(time.Minute)
+	count := 0
+	for _, bucket := range a.Buckets {
+		if !bucket.Start.Before(cutoff) {
+			count += bucket.Transactions
+		}
+	}
+	return count
+}
+
+// AmountToday returns the amount of today's transactions that were not blocked.
+func (a *UserActivity) AmountToday() int64 {
+	var total int64
+	for _, bucket := range a.Buckets {
+		if !bucket.Start.Before(a.DayStart) {
+			total += bucket.Amount
+		}
+	}
+	return total
+}
+
+// MaxBatchEvaluations is the most transactions one batch evaluation accepts
+const MaxBatchEvaluations = 100
+
+// BatchEvaluationRequest represents a request to evaluate several transactions.
+// Transactions are evaluated in order, so each one's velocity and daily limit
+// checks include the ones before it.
+type BatchEvaluationRequest struct {
+	Evaluations []EvaluationRequest `json:"evaluations"`
+}
+
+// BatchEvaluationItem is the outcome of one transaction in a batch
+type BatchEvaluationItem struct {
+	TransactionID string            `json:"transaction_id"`
+	Result        *EvaluationResult `json:"result,omitempty"`
+	Error         *BatchItemError   `json:"error,omitempty"` // Set when the transaction could not be evaluated
+}
+
+// BatchItemError explains why a transaction in a batch was not evaluated
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchEvaluationResult represents the results of a batch evaluation, in request order
+type BatchEvaluationResult struct {
+	Results []BatchEvaluationItem `json:"results"`
+	Allowed int                   `json:"allowed"` // Evaluated and allowed without a flag
+	Flagged int                   `json:"flagged"`
+	Blocked int                   `json:"blocked"`
+	Failed  int                   `json:"failed"` // Not evaluated
 }
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// SignalType identifies something that counts against a user's risk profile
+type SignalType string
+
+const (
+	SignalBlock        SignalType = "block"         // Evaluation blocked by a rule or the model
+	SignalFlag         SignalType = "flag"          // Evaluation flagged for review
+	SignalChargeback   SignalType = "chargeback"    // Payment disputed and reversed
+	SignalKYCFlag      SignalType = "kyc_flag"      // KYC rejected or marked suspicious
+	SignalDeviceChange SignalType = "device_change" // Login or transaction from a new device
+)
+
+// signalWeights is how many baseline score points each recent signal adds
+var signalWeights = map[SignalType]int{
+	SignalBlock:        10,
+	SignalFlag:         4,
+	SignalChargeback:   25,
+	SignalKYCFlag:      20,
+	SignalDeviceChange: 5,
+}
+
+// ProfileLookback is how long a signal counts towards the baseline score.
+// Older signals stay in the profile's totals.
+const ProfileLookback = 180 * 24 * time.Hour
+
+// MaxBaselineScore caps the baseline score, so a user's history alone
+// raises their evaluations' risk scores without reaching block territory.
+const MaxBaselineScore = 60
+
+// Weight returns the baseline score points a recent signal adds
+func (t SignalType) Weight() int {
+	return signalWeights[t]
+}
+
+// ReportableSignals are the signal types other services report. Blocks and
+// flags are recorded by the risk service itself.
+var ReportableSignals = []SignalType{SignalChargeback, SignalKYCFlag, SignalDeviceChange}
+
+// RiskSignal is one signal recorded against a user
+type RiskSignal struct {
+	ID         string                 `json:"id" db:"id"`
+	UserID     string                 `json:"user_id" db:"user_id"`
+	Type       SignalType             `json:"signal_type" db:"signal_type"`
+	Source     string                 `json:"source" db:"source"`       // Service that reported it
+	Reference  string                 `json:"reference" db:"reference"` // Reporter's ID, recording it again is a no-op
+	Details    map[string]interface{} `json:"details,omitempty" db:"details"`
+	OccurredAt time.Time              `json:"occurred_at" db:"occurred_at"`
+	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
+}
+
+// RecordSignalRequest reports a signal against a user
+type RecordSignalRequest struct {
+	Type       SignalType             `json:"signal_type"`
+	Source     string                 `json:"source"`
+	Reference  string                 `json:"reference"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	OccurredAt *time.Time             `json:"occurred_at,omitempty"` // Defaults to now
+}
+
+// Validate checks a reported signal
+func (r *RecordSignalRequest) Validate() error {
+	reportable := false
+	for _, t := range ReportableSignals {
+		if r.Type == t {
+			reportable = true
+			break
+		}
+	}
+	if !reportable {
+		return fmt.Errorf("signal_type must be one of %v", ReportableSignals)
+	}
+	if r.Source == "" {
+		return fmt.Errorf("source is required")
+	}
+	if r.Reference == "" {
+		return fmt.Errorf("reference is required")
+	}
+	return nil
+}
+
+// SignalSummary is a user's signals of one type
+type SignalSummary struct {
+	Type         SignalType `json:"signal_type"`
+	Total        int        `json:"total"`  // All time
+	Recent       int        `json:"recent"` // Within the lookback, counted in the score
+	Weight       int        `json:"weight"` // Score points per recent signal
+	LastSignalAt time.Time  `json:"last_signal_at"`
+}
+
+// UserRiskProfile is what a user's history says about their risk
+type UserRiskProfile struct {
+	UserID        string          `json:"user_id"`
+	BaselineScore int             `json:"baseline_score"` // Fed into every evaluation (0-MaxBaselineScore)
+	Signals       []SignalSummary `json:"signals"`
+	LookbackDays  int             `json:"lookback_days"`
+	LastSignalAt  *time.Time      `json:"last_signal_at,omitempty"`
+	RecentSignals []*RiskSignal   `json:"recent_signals,omitempty"` // Latest signals, newest first
+}
+
+// ProfileRecentSignals is how many of a user's latest signals their profile lists
+const ProfileRecentSignals = 20
+
+// NewUserRiskProfile builds a user's profile from their signal summaries
+func NewUserRiskProfile(userID string, signals []SignalSummary) *UserRiskProfile {
+	profile := &UserRiskProfile{
+		UserID:       userID,
+		Signals:      signals,
+		LookbackDays: int(ProfileLookback / (24 * time.Hour)),
+	}
+	if profile.Signals == nil {
+		profile.Signals = []SignalSummary{}
+	}
+
+	score := 0
+	for i := range profile.Signals {
+		summary := &profile.Signals[i]
+		summary.Weight = summary.Type.Weight()
+		score += summary.Recent * summary.Weight
+		if profile.LastSignalAt == nil || summary.LastSignalAt.After(*profile.LastSignalAt) {
+			last := summary.LastSignalAt
+			profile.LastSignalAt = &last
+		}
+	}
+	profile.BaselineScore = min(score, MaxBaselineScore)
+
+	return profile
+}
+
+// CombineScores adds a baseline score to a rule-based score. Each covers the
+// other's remaining headroom, so the result grows with both and stays within
+// 0-100.
+func CombineScores(ruleScore, baselineScore int) int {
+	if baselineScore <= 0 {
+		return ruleScore
+	}
+	return ruleScore + baselineScore*(100-ruleScore)/100
+}
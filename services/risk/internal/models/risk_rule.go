@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -12,6 +15,7 @@ const (
 	RuleTypeVelocity   RuleType = "velocity"    // Max transactions per time window
 	RuleTypeDailyLimit RuleType = "daily_limit" // Max amount per day per user
 	RuleTypeThreshold  RuleType = "threshold"   // Transaction amount threshold
+	RuleTypeGeo        RuleType = "geo"         // Client country, network and travel checks
 )
 
 // RiskAction represents the action to take when a rule is triggered
@@ -31,8 +35,65 @@ type RiskRule struct {
 	Parameters map[string]interface{} `json:"parameters" db:"parameters"` // JSONB parameters specific to rule type
 	Action     RiskAction             `json:"action" db:"action"`         // Action to take when triggered
 	Enabled    bool                   `json:"enabled" db:"enabled"`
-	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
+	// Shadow rules are evaluated and recorded but never change the outcome,
+	// so thresholds can be tuned against live traffic before enforcement
+	Shadow bool `json:"shadow" db:"shadow"`
+	// NotificationTargets are alerted when the rule triggers a block or flag
+	NotificationTargets []NotificationTarget `json:"notification_targets" db:"notification_targets"`
+	CreatedAt           time.Time            `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time            `json:"updated_at" db:"updated_at"`
+}
+
+// TargetChannel is the notification channel used to reach a rule's target
+type TargetChannel string
+
+const (
+	TargetChannelEmail   TargetChannel = "email"   // Mailbox or group address, e.g. compliance@
+	TargetChannelWebhook TargetChannel = "webhook" // Chat or incident tool incoming webhook URL
+)
+
+// MaxNotificationTargets caps how many targets a single rule may alert
+const MaxNotificationTargets = 10
+
+// NotificationTarget routes a triggered rule to a human-facing destination
+type NotificationTarget struct {
+	Channel   TargetChannel `json:"channel"`
+	Recipient string        `json:"recipient"`           // Email address or webhook URL
+	MinScore  int           `json:"min_score,omitempty"` // Only alert when the rule scores at least this (0-100)
+}
+
+// Matches reports whether a trigger with the given score should reach this target
+func (t NotificationTarget) Matches(score int) bool {
+	return score >= t.MinScore
+}
+
+// ValidateNotificationTargets checks that every target is deliverable
+func (r *RiskRule) ValidateNotificationTargets() error {
+	if len(r.NotificationTargets) > MaxNotificationTargets {
+		return fmt.Errorf("at most %d notification targets are allowed", MaxNotificationTargets)
+	}
+
+	for i, target := range r.NotificationTargets {
+		if target.MinScore < 0 || target.MinScore > 100 {
+			return fmt.Errorf("notification_targets[%d]: min_score must be between 0 and 100", i)
+		}
+
+		switch target.Channel {
+		case TargetChannelEmail:
+			if !strings.Contains(target.Recipient, "@") {
+				return fmt.Errorf("notification_targets[%d]: recipient must be an email address", i)
+			}
+		case TargetChannelWebhook:
+			u, err := url.Parse(target.Recipient)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				return fmt.Errorf("notification_targets[%d]: recipient must be an http(s) URL", i)
+			}
+		default:
+			return fmt.Errorf("notification_targets[%d]: channel must be email or webhook", i)
+		}
+	}
+
+	return nil
 }
 
 // VelocityRuleParams represents parameters for velocity check rule
@@ -44,16 +105,31 @@ type VelocityRuleParams struct {
 
 // DailyLimitParams represents parameters for daily limit rule
 type DailyLimitParams struct {
-	MaxAmount int64  `json:"max_amount"` // Max amount in smallest currency unit
-	Currency  string `json:"currency"`   // Currency code
-	PerUser   bool   `json:"per_user"`   // Apply per user vs globally
+	MaxAmount int64           `json:"max_amount"`         // Max amount in smallest currency unit
+	Currency  string          `json:"currency"`           // Currency code
+	PerUser   bool            `json:"per_user"`           // Apply per user vs globally
+	Adaptive  *AdaptiveParams `json:"adaptive,omitempty"` // Optional baseline-relative limit
 }
 
 // ThresholdParams represents parameters for threshold rule
 type ThresholdParams struct {
-	MinAmount int64  `json:"min_amount"` // Minimum amount to trigger (0 = no min)
-	MaxAmount int64  `json:"max_amount"` // Maximum amount to trigger
-	Currency  string `json:"currency"`   // Currency code
+	MinAmount int64           `json:"min_amount"`         // Minimum amount to trigger (0 = no min)
+	MaxAmount int64           `json:"max_amount"`         // Maximum amount to trigger
+	Currency  string          `json:"currency"`           // Currency code
+	Adaptive  *AdaptiveParams `json:"adaptive,omitempty"` // Optional baseline-relative threshold
+}
+
+// ValidateParameters checks rule parameters that can be rejected up front
+func (r *RiskRule) ValidateParameters() error {
+	if r.RuleType != RuleTypeGeo {
+		return nil
+	}
+
+	var params GeoRuleParams
+	if err := r.UnmarshalParameters(&params); err != nil {
+		return fmt.Errorf("invalid geo parameters: %w", err)
+	}
+	return params.Validate()
 }
 
 // UnmarshalParameters unmarshals the parameters into a specific struct
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// BaselineMetric identifies which baseline statistic an adaptive threshold scales
+type BaselineMetric string
+
+const (
+	BaselineMetricWeeklyOutflow  BaselineMetric = "weekly_outflow"  // Average outflow per week
+	BaselineMetricDailyOutflow   BaselineMetric = "daily_outflow"   // Average outflow per active day
+	BaselineMetricAvgTransaction BaselineMetric = "avg_transaction" // Average transaction amount
+)
+
+// DefaultBaselineWindowWeeks is the trailing window used when recomputing baselines
+const DefaultBaselineWindowWeeks = 4
+
+// UserBaseline represents a user's trailing outflow statistics for a currency
+type UserBaseline struct {
+	UserID               string    `json:"user_id" db:"user_id"`
+	Currency             string    `json:"currency" db:"currency"`
+	AvgWeeklyOutflow     int64     `json:"avg_weekly_outflow" db:"avg_weekly_outflow"`
+	AvgDailyOutflow      int64     `json:"avg_daily_outflow" db:"avg_daily_outflow"`
+	AvgTransactionAmount int64     `json:"avg_transaction_amount" db:"avg_transaction_amount"`
+	TransactionCount     int       `json:"transaction_count" db:"transaction_count"`
+	WindowWeeks          int       `json:"window_weeks" db:"window_weeks"`
+	ComputedAt           time.Time `json:"computed_at" db:"computed_at"`
+}
+
+// Value returns the baseline statistic for the given metric
+func (b *UserBaseline) Value(metric BaselineMetric) int64 {
+	switch metric {
+	case BaselineMetricDailyOutflow:
+		return b.AvgDailyOutflow
+	case BaselineMetricAvgTransaction:
+		return b.AvgTransactionAmount
+	default:
+		return b.AvgWeeklyOutflow
+	}
+}
+
+// AdaptiveParams makes a rule's static limit relative to the user's baseline.
+// The effective limit is max(static limit, multiplier × baseline metric), so
+// high-volume users get headroom while new users keep the static limit.
+type AdaptiveParams struct {
+	Multiplier          float64        `json:"multiplier"`                      // e.g. 3 = 3× baseline
+	Metric              BaselineMetric `json:"metric"`                          // Baseline statistic to scale
+	MinTransactionCount int            `json:"min_transaction_count,omitempty"` // Minimum history before adapting
+}
+
+// EffectiveLimit returns the adapted limit for a static limit and an optional baseline
+func (a *AdaptiveParams) EffectiveLimit(staticLimit int64, baseline *UserBaseline) int64 {
+	if a == nil || a.Multiplier <= 0 || baseline == nil {
+		return staticLimit
+	}
+	if baseline.TransactionCount < a.MinTransactionCount {
+		return staticLimit
+	}
+
+	adaptive := int64(float64(baseline.Value(a.Metric)) * a.Multiplier)
+	if adaptive > staticLimit {
+		return adaptive
+	}
+	return staticLimit
+}
+
+// RecomputeBaselinesResult summarizes a baseline recomputation run
+type RecomputeBaselinesResult struct {
+	UsersUpdated int64     `json:"users_updated"`
+	WindowWeeks  int       `json:"window_weeks"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
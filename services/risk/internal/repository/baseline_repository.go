@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// BaselineRepository handles database operations for user risk baselines
+type BaselineRepository struct {
+	db *sql.DB
+}
+
+// NewBaselineRepository creates a new baseline repository
+func NewBaselineRepository(db *sql.DB) *BaselineRepository {
+	return &BaselineRepository{db: db}
+}
+
+// GetByUserID retrieves a user's baseline for a currency
+func (r *BaselineRepository) GetByUserID(ctx context.Context, userID, currency string) (*models.UserBaseline, *errors.Error) {
+	baseline := &models.UserBaseline{}
+
+	query := `
+		SELECT user_id, currency, avg_weekly_outflow, avg_daily_outflow, avg_transaction_amount,
+		       transaction_count, window_weeks, computed_at
+		FROM user_risk_baselines
+		WHERE user_id = $1 AND currency = $2
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID, currency).Scan(
+		&baseline.UserID,
+		&baseline.Currency,
+		&baseline.AvgWeeklyOutflow,
+		&baseline.AvgDailyOutflow,
+		&baseline.AvgTransactionAmount,
+		&baseline.TransactionCount,
+		&baseline.WindowWeeks,
+		&baseline.ComputedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("user baseline not found")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get user baseline")
+	}
+
+	return baseline, nil
+}
+
+// RecomputeAll rebuilds every user's baseline from outflows in the trailing window.
+// Users without outflows in the window lose their baseline and fall back to static limits.
+func (r *BaselineRepository) RecomputeAll(ctx context.Context, windowWeeks int) (int64, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_risk_baselines`); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to clear user baselines")
+	}
+
+	// Each transaction may have several events; count it once using its first evaluation
+	query := `
+		INSERT INTO user_risk_baselines (
+			user_id, currency, avg_weekly_outflow, avg_daily_outflow, avg_transaction_amount,
+			transaction_count, window_weeks, computed_at
+		)
+		SELECT
+			user_id,
+			currency,
+			SUM(amount) / $1,
+			SUM(amount) / GREATEST(COUNT(DISTINCT created_at::date), 1),
+			AVG(amount)::bigint,
+			COUNT(*),
+			$1,
+			$2
+		FROM (
+			SELECT DISTINCT ON (transaction_id)
+				transaction_id,
+				user_id,
+				metadata->>'currency' AS currency,
+				(metadata->>'amount')::bigint AS amount,
+				created_at
+			FROM risk_events
+			WHERE created_at >= NOW() - INTERVAL '1 week' * $1
+			  AND action != 'block'
+			  AND metadata->>'amount' IS NOT NULL
+			  AND metadata->>'currency' IS NOT NULL
+			  AND metadata->>'transaction_type' IN ('transfer', 'withdrawal')
+			ORDER BY transaction_id, created_at
+		) outflows
+		GROUP BY user_id, currency
+	`
+
+	result, err := tx.ExecContext(ctx, query, windowWeeks, time.Now())
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to recompute user baselines")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to commit baseline recompute")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+
+	return rows, nil
+}
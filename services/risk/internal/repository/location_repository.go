@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// LocationRepository handles database operations for users' last known locations
+type LocationRepository struct {
+	db *sql.DB
+}
+
+// NewLocationRepository creates a new location repository
+func NewLocationRepository(db *sql.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// GetLast retrieves the location a user last transacted from
+func (r *LocationRepository) GetLast(ctx context.Context, userID string) (*models.UserLocation, *errors.Error) {
+	location := &models.UserLocation{}
+	var country sql.NullString
+
+	query := `
+		SELECT user_id, ip, country, latitude, longitude, seen_at
+		FROM risk_user_locations
+		WHERE user_id = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&location.UserID,
+		&location.IP,
+		&country,
+		&location.Latitude,
+		&location.Longitude,
+		&location.SeenAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("user location not found")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get user location")
+	}
+	location.Country = country.String
+
+	return location, nil
+}
+
+// Record stores a user's latest location
+func (r *LocationRepository) Record(ctx context.Context, location *models.UserLocation) *errors.Error {
+	query := `
+		INSERT INTO risk_user_locations (user_id, ip, country, latitude, longitude, seen_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET ip = EXCLUDED.ip, country = EXCLUDED.country, latitude = EXCLUDED.latitude,
+		    longitude = EXCLUDED.longitude, seen_at = EXCLUDED.seen_at
+		WHERE risk_user_locations.seen_at <= EXCLUDED.seen_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		location.UserID, location.IP, location.Country, location.Latitude, location.Longitude, location.SeenAt,
+	)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record user location")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// ProfileRepository handles database operations for user risk profile signals
+type ProfileRepository struct {
+	db *sql.DB
+}
+
+// NewProfileRepository creates a new profile repository
+func NewProfileRepository(db *sql.DB) *ProfileRepository {
+	return &ProfileRepository{db: db}
+}
+
+// RecordSignal stores a signal against a user. A signal already recorded
+// with the same type and reference is left as it was; created reports
+// whether this call stored it.
+func (r *ProfileRepository) RecordSignal(ctx context.Context, signal *models.RiskSignal) (created bool, appErr *errors.Error) {
+	var detailsJSON []byte
+	if signal.Details != nil {
+		var err error
+		detailsJSON, err = json.Marshal(signal.Details)
+		if err != nil {
+			return false, errors.Internal("failed to marshal signal details")
+		}
+	}
+
+	query := `
+		INSERT INTO risk_profile_signals (user_id, signal_type, source, reference, details, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, signal_type, reference) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		signal.UserID,
+		signal.Type,
+		signal.Source,
+		signal.Reference,
+		detailsJSON,
+		signal.OccurredAt,
+	).Scan(&signal.ID, &signal.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to record risk signal")
+	}
+
+	return true, nil
+}
+
+// Summarize counts a user's signals by type, all time and since the given
+// time. Types the user has no signals of are left out.
+func (r *ProfileRepository) Summarize(ctx context.Context, userID string, since time.Time) ([]models.SignalSummary, *errors.Error) {
+	query := `
+		SELECT signal_type,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE occurred_at >= $2),
+		       MAX(occurred_at)
+		FROM risk_profile_signals
+		WHERE user_id = $1
+		GROUP BY signal_type
+		ORDER BY signal_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, since)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to summarize risk signals")
+	}
+	defer func() { _ = rows.Close() }()
+
+	var summaries []models.SignalSummary
+	for rows.Next() {
+		var summary models.SignalSummary
+		if err := rows.Scan(&summary.Type, &summary.Total, &summary.Recent, &summary.LastSignalAt); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan risk signal summary")
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate risk signal summaries")
+	}
+
+	return summaries, nil
+}
+
+// ListRecent retrieves a user's latest signals, newest first
+func (r *ProfileRepository) ListRecent(ctx context.Context, userID string, limit int) ([]*models.RiskSignal, *errors.Error) {
+	query := `
+		SELECT id, user_id, signal_type, source, reference, details, occurred_at, created_at
+		FROM risk_profile_signals
+		WHERE user_id = $1
+		ORDER BY occurred_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list risk signals")
+	}
+	defer func() { _ = rows.Close() }()
+
+	signals := make([]*models.RiskSignal, 0)
+	for rows.Next() {
+		signal := &models.RiskSignal{}
+		var detailsJSON []byte
+		if err := rows.Scan(
+			&signal.ID,
+			&signal.UserID,
+			&signal.Type,
+			&signal.Source,
+			&signal.Reference,
+			&detailsJSON,
+			&signal.OccurredAt,
+			&signal.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan risk signal")
+		}
+		if detailsJSON != nil {
+			if err := json.Unmarshal(detailsJSON, &signal.Details); err != nil {
+				return nil, errors.Internal("failed to unmarshal signal details")
+			}
+		}
+		signals = append(signals, signal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate risk signals")
+	}
+
+	return signals, nil
+}
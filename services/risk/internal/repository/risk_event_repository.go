@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
@@ -31,10 +32,32 @@ func (r *RiskEventRepository) Create(ctx context.Context, event *models.RiskEven
 		}
 	}
 
+	// The user's activity rollup is updated in the same statement so velocity
+	// and daily limit checks always agree with the recorded events. Blocks and
+	// flags also count against the user's risk profile, except blocks caused
+	// by the external scorer being unavailable.
 	query := `
-		INSERT INTO risk_events (transaction_id, user_id, rule_id, rule_type, risk_score, action, reason, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at
+		WITH event AS (
+			INSERT INTO risk_events (transaction_id, user_id, rule_id, rule_type, risk_score, action, reason, metadata)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, user_id, action, metadata, created_at
+		), activity AS (
+			INSERT INTO risk_user_activity (user_id, bucket_start, transactions, amount)
+			SELECT user_id, date_trunc('minute', created_at), 1,
+			       CASE WHEN action = 'block' THEN 0 ELSE COALESCE((metadata->>'amount')::bigint, 0) END
+			FROM event
+			ON CONFLICT (user_id, bucket_start) DO UPDATE
+			SET transactions = risk_user_activity.transactions + EXCLUDED.transactions,
+			    amount = risk_user_activity.amount + EXCLUDED.amount
+		), signal AS (
+			INSERT INTO risk_profile_signals (user_id, signal_type, source, reference, occurred_at)
+			SELECT user_id, action, 'risk', id::text, created_at
+			FROM event
+			WHERE action IN ('block', 'flag')
+			  AND COALESCE(metadata->'model'->>'status', '') != 'failed_closed'
+			ON CONFLICT (user_id, signal_type, reference) DO NOTHING
+		)
+		SELECT id, created_at FROM event
 	`
 
 	err = r.db.QueryRowContext(ctx, query,
@@ -197,40 +220,54 @@ func (r *RiskEventRepository) GetByUserID(ctx context.Context, userID string, li
 	return events, nil
 }
 
-// CountUserTransactions counts user transactions in a time window
-func (r *RiskEventRepository) CountUserTransactions(ctx context.Context, userID string, minutesAgo int) (int, *errors.Error) {
+// GetUserActivity retrieves a user's activity rollups for today and at least
+// the last windowMins minutes.
+func (r *RiskEventRepository) GetUserActivity(ctx context.Context, userID string, windowMins int) (*models.UserActivity, *errors.Error) {
 	query := `
-		SELECT COUNT(DISTINCT transaction_id)
-		FROM risk_events
-		WHERE user_id = $1 AND created_at >= NOW() - INTERVAL '1 minute' * $2
+		SELECT NOW(), CURRENT_DATE::timestamptz, a.bucket_start, a.transactions, a.amount
+		FROM (SELECT 1) AS now
+		LEFT JOIN risk_user_activity a
+		       ON a.user_id = $1
+		      AND a.bucket_start >= LEAST(CURRENT_DATE::timestamptz, date_trunc('minute', NOW() - INTERVAL '1 minute' * $2))
+		ORDER BY a.bucket_start
 	`
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, userID, minutesAgo).Scan(&count)
+	rows, err := r.db.QueryContext(ctx, query, userID, windowMins)
 	if err != nil {
-		return 0, errors.DatabaseWrap(err, "failed to count user transactions")
+		return nil, errors.DatabaseWrap(err, "failed to get user activity")
 	}
+	defer func() { _ = rows.Close() }()
 
-	return count, nil
-}
+	activity := &models.UserActivity{}
+	for rows.Next() {
+		var start sql.NullTime
+		var transactions sql.NullInt64
+		var amount sql.NullInt64
+		if err := rows.Scan(&activity.Now, &activity.DayStart, &start, &transactions, &amount); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan user activity")
+		}
+		if start.Valid {
+			activity.Buckets = append(activity.Buckets, models.ActivityBucket{
+				Start:        start.Time,
+				Transactions: int(transactions.Int64),
+				Amount:       amount.Int64,
+			})
+		}
+	}
 
-// GetUserDailyTotal calculates total amount for user today
-// Note: This requires metadata to contain amount information
-func (r *RiskEventRepository) GetUserDailyTotal(ctx context.Context, userID string) (int64, *errors.Error) {
-	query := `
-		SELECT COALESCE(SUM((metadata->>'amount')::bigint), 0)
-		FROM risk_events
-		WHERE user_id = $1
-		  AND created_at >= CURRENT_DATE
-		  AND action != 'block'
-		  AND metadata->>'amount' IS NOT NULL
-	`
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating user activity")
+	}
+
+	return activity, nil
+}
 
-	var total int64
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&total)
+// PruneActivity deletes activity rollups that started before the cutoff
+func (r *RiskEventRepository) PruneActivity(ctx context.Context, before time.Time) (int64, *errors.Error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM risk_user_activity WHERE bucket_start < $1`, before)
 	if err != nil {
-		return 0, errors.DatabaseWrap(err, "failed to get user daily total")
+		return 0, errors.DatabaseWrap(err, "failed to prune user activity")
 	}
-
-	return total, nil
+	deleted, _ := result.RowsAffected()
+	return deleted, nil
 }
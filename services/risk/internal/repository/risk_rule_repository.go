@@ -25,10 +25,14 @@ func (r *RiskRuleRepository) Create(ctx context.Context, rule *models.RiskRule)
 	if err != nil {
 		return errors.Internal("failed to marshal parameters")
 	}
+	targetsJSON, err := marshalTargets(rule.NotificationTargets)
+	if err != nil {
+		return errors.Internal("failed to marshal notification targets")
+	}
 
 	query := `
-		INSERT INTO risk_rules (rule_type, name, parameters, action, enabled)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO risk_rules (rule_type, name, parameters, action, enabled, shadow, notification_targets)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -38,6 +42,8 @@ func (r *RiskRuleRepository) Create(ctx context.Context, rule *models.RiskRule)
 		paramsJSON,
 		rule.Action,
 		rule.Enabled,
+		rule.Shadow,
+		targetsJSON,
 	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
 
 	if err != nil {
@@ -53,10 +59,10 @@ func (r *RiskRuleRepository) Create(ctx context.Context, rule *models.RiskRule)
 // GetByID retrieves a risk rule by ID
 func (r *RiskRuleRepository) GetByID(ctx context.Context, id string) (*models.RiskRule, *errors.Error) {
 	rule := &models.RiskRule{}
-	var paramsJSON []byte
+	var paramsJSON, targetsJSON []byte
 
 	query := `
-		SELECT id, rule_type, name, parameters, action, enabled, created_at, updated_at
+		SELECT id, rule_type, name, parameters, action, enabled, shadow, notification_targets, created_at, updated_at
 		FROM risk_rules
 		WHERE id = $1
 	`
@@ -68,6 +74,8 @@ func (r *RiskRuleRepository) GetByID(ctx context.Context, id string) (*models.Ri
 		&paramsJSON,
 		&rule.Action,
 		&rule.Enabled,
+		&rule.Shadow,
+		&targetsJSON,
 		&rule.CreatedAt,
 		&rule.UpdatedAt,
 	)
@@ -83,6 +91,9 @@ func (r *RiskRuleRepository) GetByID(ctx context.Context, id string) (*models.Ri
 	if err := json.Unmarshal(paramsJSON, &rule.Parameters); err != nil {
 		return nil, errors.Internal("failed to unmarshal parameters")
 	}
+	if err := json.Unmarshal(targetsJSON, &rule.NotificationTargets); err != nil {
+		return nil, errors.Internal("failed to unmarshal notification targets")
+	}
 
 	return rule, nil
 }
@@ -90,7 +101,7 @@ func (r *RiskRuleRepository) GetByID(ctx context.Context, id string) (*models.Ri
 // GetAll retrieves all risk rules
 func (r *RiskRuleRepository) GetAll(ctx context.Context, enabledOnly bool) ([]*models.RiskRule, *errors.Error) {
 	query := `
-		SELECT id, rule_type, name, parameters, action, enabled, created_at, updated_at
+		SELECT id, rule_type, name, parameters, action, enabled, shadow, notification_targets, created_at, updated_at
 		FROM risk_rules
 	`
 
@@ -111,7 +122,7 @@ func (r *RiskRuleRepository) GetAll(ctx context.Context, enabledOnly bool) ([]*m
 	var rules []*models.RiskRule
 	for rows.Next() {
 		rule := &models.RiskRule{}
-		var paramsJSON []byte
+		var paramsJSON, targetsJSON []byte
 
 		err := rows.Scan(
 			&rule.ID,
@@ -120,6 +131,8 @@ func (r *RiskRuleRepository) GetAll(ctx context.Context, enabledOnly bool) ([]*m
 			&paramsJSON,
 			&rule.Action,
 			&rule.Enabled,
+			&rule.Shadow,
+			&targetsJSON,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
 		)
@@ -132,6 +145,9 @@ func (r *RiskRuleRepository) GetAll(ctx context.Context, enabledOnly bool) ([]*m
 		if err := json.Unmarshal(paramsJSON, &rule.Parameters); err != nil {
 			return nil, errors.Internal("failed to unmarshal parameters")
 		}
+		if err := json.Unmarshal(targetsJSON, &rule.NotificationTargets); err != nil {
+			return nil, errors.Internal("failed to unmarshal notification targets")
+		}
 
 		rules = append(rules, rule)
 	}
@@ -142,7 +158,7 @@ func (r *RiskRuleRepository) GetAll(ctx context.Context, enabledOnly bool) ([]*m
 // GetByType retrieves all enabled risk rules of a specific type
 func (r *RiskRuleRepository) GetByType(ctx context.Context, ruleType models.RuleType) ([]*models.RiskRule, *errors.Error) {
 	query := `
-		SELECT id, rule_type, name, parameters, action, enabled, created_at, updated_at
+		SELECT id, rule_type, name, parameters, action, enabled, shadow, notification_targets, created_at, updated_at
 		FROM risk_rules
 		WHERE rule_type = $1 AND enabled = true
 		ORDER BY created_at DESC
@@ -157,7 +173,7 @@ func (r *RiskRuleRepository) GetByType(ctx context.Context, ruleType models.Rule
 	var rules []*models.RiskRule
 	for rows.Next() {
 		rule := &models.RiskRule{}
-		var paramsJSON []byte
+		var paramsJSON, targetsJSON []byte
 
 		err := rows.Scan(
 			&rule.ID,
@@ -166,6 +182,8 @@ func (r *RiskRuleRepository) GetByType(ctx context.Context, ruleType models.Rule
 			&paramsJSON,
 			&rule.Action,
 			&rule.Enabled,
+			&rule.Shadow,
+			&targetsJSON,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
 		)
@@ -178,6 +196,9 @@ func (r *RiskRuleRepository) GetByType(ctx context.Context, ruleType models.Rule
 		if err := json.Unmarshal(paramsJSON, &rule.Parameters); err != nil {
 			return nil, errors.Internal("failed to unmarshal parameters")
 		}
+		if err := json.Unmarshal(targetsJSON, &rule.NotificationTargets); err != nil {
+			return nil, errors.Internal("failed to unmarshal notification targets")
+		}
 
 		rules = append(rules, rule)
 	}
@@ -191,11 +212,15 @@ func (r *RiskRuleRepository) Update(ctx context.Context, rule *models.RiskRule)
 	if err != nil {
 		return errors.Internal("failed to marshal parameters")
 	}
+	targetsJSON, err := marshalTargets(rule.NotificationTargets)
+	if err != nil {
+		return errors.Internal("failed to marshal notification targets")
+	}
 
 	query := `
 		UPDATE risk_rules
-		SET rule_type = $1, name = $2, parameters = $3, action = $4, enabled = $5
-		WHERE id = $6
+		SET rule_type = $1, name = $2, parameters = $3, action = $4, enabled = $5, shadow = $6, notification_targets = $7
+		WHERE id = $8
 		RETURNING updated_at
 	`
 
@@ -205,6 +230,8 @@ func (r *RiskRuleRepository) Update(ctx context.Context, rule *models.RiskRule)
 		paramsJSON,
 		rule.Action,
 		rule.Enabled,
+		rule.Shadow,
+		targetsJSON,
 		rule.ID,
 	).Scan(&rule.UpdatedAt)
 
@@ -242,6 +269,14 @@ func (r *RiskRuleRepository) Delete(ctx context.Context, id string) *errors.Erro
 	return nil
 }
 
+// marshalTargets encodes notification targets, storing none as an empty array
+func marshalTargets(targets []models.NotificationTarget) ([]byte, error) {
+	if targets == nil {
+		targets = []models.NotificationTarget{}
+	}
+	return json.Marshal(targets)
+}
+
 // isUniqueViolation checks if the error is a unique constraint violation
 func isUniqueViolation(err error) bool {
 	// PostgreSQL unique violation error code is 23505
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/services/risk/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// GeoResolver maps a client IP to a location. Implementations return nil
+// without an error for addresses they know nothing about.
+type GeoResolver interface {
+	Resolve(ctx context.Context, ip net.IP) (*models.GeoLocation, error)
+}
+
+// CIDRResolver resolves IPs from a table of network ranges, preferring the
+// most specific range that contains the address.
+type CIDRResolver struct {
+	ranges []cidrRange
+}
+
+type cidrRange struct {
+	network  *net.IPNet
+	location models.GeoLocation
+}
+
+// NewCIDRResolverFromFile loads a resolver from a CSV file with the columns
+// cidr,country,latitude,longitude,network. Every column after cidr may be
+// empty, and lines starting with # are ignored.
+func NewCIDRResolverFromFile(path string) (*CIDRResolver, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geo database: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	return NewCIDRResolver(f)
+}
+
+// NewCIDRResolver loads a resolver from CSV data in the format read by
+// NewCIDRResolverFromFile.
+func NewCIDRResolver(r io.Reader) (*CIDRResolver, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	resolver := &CIDRResolver{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geo database line %d: %w", line, err)
+		}
+
+		entry, err := parseCIDRRange(record)
+		if err != nil {
+			return nil, fmt.Errorf("geo database line %d: %w", line, err)
+		}
+		resolver.ranges = append(resolver.ranges, entry)
+	}
+
+	// Most specific ranges first so the first match wins
+	sort.SliceStable(resolver.ranges, func(i, j int) bool {
+		oi, _ := resolver.ranges[i].network.Mask.Size()
+		oj, _ := resolver.ranges[j].network.Mask.Size()
+		return oi > oj
+	})
+
+	return resolver, nil
+}
+
+func parseCIDRRange(record []string) (cidrRange, error) {
+	field := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	_, network, err := net.ParseCIDR(field(0))
+	if err != nil {
+		return cidrRange{}, fmt.Errorf("invalid CIDR %q", field(0))
+	}
+
+	entry := cidrRange{network: network}
+	entry.location.Country = strings.ToUpper(field(1))
+	entry.location.Network = models.NetworkType(strings.ToLower(field(4)))
+
+	if field(2) != "" || field(3) != "" {
+		lat, latErr := strconv.ParseFloat(field(2), 64)
+		lon, lonErr := strconv.ParseFloat(field(3), 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			return cidrRange{}, fmt.Errorf("invalid coordinates %q,%q", field(2), field(3))
+		}
+		entry.location.Latitude = &lat
+		entry.location.Longitude = &lon
+	}
+
+	return entry, nil
+}
+
+// Resolve implements GeoResolver.
+func (r *CIDRResolver) Resolve(_ context.Context, ip net.IP) (*models.GeoLocation, error) {
+	for i := range r.ranges {
+		if r.ranges[i].network.Contains(ip) {
+			location := r.ranges[i].location
+			return &location, nil
+		}
+	}
+	return nil, nil
+}
+
+// geoContext is what geo rules know about where a transaction came from
+type geoContext struct {
+	ip       net.IP
+	location *models.GeoLocation  // Never nil; empty when nothing is known
+	previous *models.UserLocation // Last location of the user, if tracked
+	now      time.Time
+}
+
+// SetGeo enables resolving client IPs for geo rules and tracking users' last
+// locations for impossible-travel checks. Either may be nil: without a
+// resolver, geo rules see only the country reported by the edge; without
+// location tracking, impossible-travel checks never trigger.
+func (s *RiskService) SetGeo(resolver GeoResolver, locations *repository.LocationRepository) {
+	s.geoResolver = resolver
+	s.locationRepo = locations
+}
+
+// loadGeo resolves the client's location when a geo rule needs it.
+func (s *RiskService) loadGeo(ctx context.Context, rules []*models.RiskRule, req *models.EvaluationRequest) *geoContext {
+	needed, travel := false, false
+	for _, rule := range rules {
+		if rule.RuleType != models.RuleTypeGeo {
+			continue
+		}
+		needed = true
+		var params models.GeoRuleParams
+		if err := rule.UnmarshalParameters(&params); err == nil && params.ImpossibleTravel {
+			travel = true
+		}
+	}
+	if !needed && s.locationRepo == nil {
+		return nil
+	}
+
+	geo := &geoContext{
+		ip:       net.ParseIP(req.ClientIP),
+		location: &models.GeoLocation{},
+		now:      time.Now(),
+	}
+
+	if geo.ip != nil && s.geoResolver != nil {
+		location, err := s.geoResolver.Resolve(ctx, geo.ip)
+		if err != nil {
+			log.Printf("[risk] Failed to resolve location of %s: %v", req.ClientIP, err)
+		} else if location != nil {
+			geo.location = location
+		}
+	}
+	// The resolver's answer wins; the edge's country fills the gap
+	if geo.location.Country == "" {
+		geo.location.Country = strings.ToUpper(req.ClientCountry)
+	}
+
+	if travel && s.locationRepo != nil {
+		previous, err := s.locationRepo.GetLast(ctx, req.UserID)
+		if err != nil && err.Code != errors.ErrCodeNotFound {
+			log.Printf("[risk] Failed to load last location for user %s: %v", req.UserID, err)
+		}
+		geo.previous = previous
+	}
+
+	return geo
+}
+
+// recordLocation remembers where the user transacted from. Blocked attempts
+// do not move the user's last known location.
+func (s *RiskService) recordLocation(ctx context.Context, req *models.EvaluationRequest, geo *geoContext, result *models.EvaluationResult) {
+	if s.locationRepo == nil || geo == nil || geo.ip == nil || !result.Allowed {
+		return
+	}
+
+	location := &models.UserLocation{
+		UserID:    req.UserID,
+		IP:        geo.ip.String(),
+		Country:   geo.location.Country,
+		Latitude:  geo.location.Latitude,
+		Longitude: geo.location.Longitude,
+		SeenAt:    geo.now,
+	}
+	if err := s.locationRepo.Record(ctx, location); err != nil {
+		log.Printf("[risk] Failed to record location for user %s: %v", req.UserID, err)
+	}
+}
+
+// evaluateGeoRule checks where the transaction came from. When several checks
+// trigger, the highest scoring one is reported.
+func (s *RiskService) evaluateGeoRule(rule *models.RiskRule, geo *geoContext) (bool, int, string, *errors.Error) {
+	var params models.GeoRuleParams
+	if err := rule.UnmarshalParameters(&params); err != nil {
+		return false, 0, "", errors.Internal("failed to unmarshal geo params")
+	}
+
+	if geo == nil {
+		return false, 0, "", errors.Unavailable("client location not loaded")
+	}
+
+	triggered, score, reason := false, 0, ""
+	trigger := func(checkScore int, checkReason string) {
+		if !triggered || checkScore > score {
+			triggered, score, reason = true, checkScore, checkReason
+		}
+	}
+
+	if geo.ip != nil {
+		for _, cidr := range params.BlockedCIDRs {
+			if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(geo.ip) {
+				trigger(90, fmt.Sprintf("Client IP %s is in blocked range %s", geo.ip, cidr))
+				break
+			}
+		}
+	}
+
+	if network := geo.location.Network; network != "" {
+		for _, blocked := range params.BlockedNetworks {
+			if network == blocked {
+				trigger(75, fmt.Sprintf("Client IP %s belongs to a %s network", geo.ip, network))
+				break
+			}
+		}
+	}
+
+	if country := geo.location.Country; country != "" {
+		if models.ContainsCountry(params.BlockedCountries, country) {
+			trigger(90, fmt.Sprintf("Transaction from blocked country %s", country))
+		} else if len(params.AllowedCountries) > 0 && !models.ContainsCountry(params.AllowedCountries, country) {
+			trigger(80, fmt.Sprintf("Transaction from country %s outside the allowed list", country))
+		}
+	}
+
+	if params.ImpossibleTravel && geo.previous != nil && geo.location.HasCoordinates() &&
+		geo.previous.Latitude != nil && geo.previous.Longitude != nil {
+		maxKmh, minKm := params.TravelLimits()
+		distance := models.DistanceKm(*geo.previous.Latitude, *geo.previous.Longitude, *geo.location.Latitude, *geo.location.Longitude)
+
+		// Floor the elapsed time so near-simultaneous requests do not divide by zero
+		elapsed := geo.now.Sub(geo.previous.SeenAt)
+		if elapsed < time.Minute {
+			elapsed = time.Minute
+		}
+		speed := distance / elapsed.Hours()
+
+		if distance >= minKm && speed > maxKmh {
+			trigger(85, fmt.Sprintf("Impossible travel: %.0f km from last location in %s (%.0f km/h, max: %.0f km/h)",
+				distance, elapsed.Round(time.Minute), speed, maxKmh))
+		}
+	}
+
+	return triggered, score, reason, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/services/risk/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// SetProfiles enables per-user risk profiles. Every evaluation then includes
+// the user's baseline score, and other services can report signals against
+// users. If not set, evaluations use the rules and scorer alone.
+func (s *RiskService) SetProfiles(profiles *repository.ProfileRepository) {
+	s.profileRepo = profiles
+}
+
+// GetRiskProfile builds a user's risk profile from their signals
+func (s *RiskService) GetRiskProfile(ctx context.Context, userID string) (*models.UserRiskProfile, *errors.Error) {
+	if s.profileRepo == nil {
+		return nil, errors.Unavailable("risk profiles are not enabled")
+	}
+
+	summaries, err := s.profileRepo.Summarize(ctx, userID, time.Now().Add(-models.ProfileLookback))
+	if err != nil {
+		return nil, err
+	}
+	profile := models.NewUserRiskProfile(userID, summaries)
+
+	profile.RecentSignals, err = s.profileRepo.ListRecent(ctx, userID, models.ProfileRecentSignals)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// RecordSignal records a signal reported by another service against a user.
+// Reporting the same signal again returns the original without changing the
+// profile.
+func (s *RiskService) RecordSignal(ctx context.Context, userID string, req *models.RecordSignalRequest) (*models.RiskSignal, bool, *errors.Error) {
+	if s.profileRepo == nil {
+		return nil, false, errors.Unavailable("risk profiles are not enabled")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, false, errors.Validation(err.Error())
+	}
+
+	signal := &models.RiskSignal{
+		UserID:     userID,
+		Type:       req.Type,
+		Source:     req.Source,
+		Reference:  req.Reference,
+		Details:    req.Details,
+		OccurredAt: time.Now(),
+	}
+	if req.OccurredAt != nil {
+		signal.OccurredAt = *req.OccurredAt
+	}
+
+	created, err := s.profileRepo.RecordSignal(ctx, signal)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		log.Printf("[risk] Recorded %s signal for user %s from %s (%s)", signal.Type, userID, signal.Source, signal.Reference)
+	}
+
+	return signal, created, nil
+}
+
+// baselineScore returns the user's baseline score for an evaluation. Lookup
+// errors count as no history so evaluations are never held up by profiles.
+func (s *RiskService) baselineScore(ctx context.Context, userID string) int {
+	if s.profileRepo == nil {
+		return 0
+	}
+
+	summaries, err := s.profileRepo.Summarize(ctx, userID, time.Now().Add(-models.ProfileLookback))
+	if err != nil {
+		log.Printf("[risk] Failed to load risk profile for user %s: %v", userID, err)
+		return 0
+	}
+
+	return models.NewUserRiskProfile(userID, summaries).BaselineScore
+}
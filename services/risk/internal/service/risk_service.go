@@ -4,34 +4,76 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
 	"github.com/1mb-dev/nivomoney/services/risk/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 )
 
 // RiskService handles risk evaluation logic
 type RiskService struct {
-	ruleRepo  *repository.RiskRuleRepository
-	eventRepo *repository.RiskEventRepository
+	ruleRepo     *repository.RiskRuleRepository
+	eventRepo    *repository.RiskEventRepository
+	baselineRepo *repository.BaselineRepository
+	notifier     *clients.NotificationClient
+	scorer       Scorer
+	scorerPolicy ScorerPolicy
+	approvals    *approval.Manager
+	rules        *ruleCache
+	geoResolver  GeoResolver
+	locationRepo *repository.LocationRepository
+	profileRepo  *repository.ProfileRepository
 }
 
 // NewRiskService creates a new risk service
-func NewRiskService(ruleRepo *repository.RiskRuleRepository, eventRepo *repository.RiskEventRepository) *RiskService {
+func NewRiskService(ruleRepo *repository.RiskRuleRepository, eventRepo *repository.RiskEventRepository, baselineRepo *repository.BaselineRepository) *RiskService {
 	return &RiskService{
-		ruleRepo:  ruleRepo,
-		eventRepo: eventRepo,
+		ruleRepo:     ruleRepo,
+		eventRepo:    eventRepo,
+		baselineRepo: baselineRepo,
+		rules:        newRuleCache(ruleRepo, DefaultRuleCacheTTL),
 	}
 }
 
+// SetRuleCacheTTL changes how long enabled rules are cached between
+// evaluations. A zero TTL loads the rules for every evaluation.
+func (s *RiskService) SetRuleCacheTTL(ttl time.Duration) {
+	s.rules = newRuleCache(s.ruleRepo, ttl)
+}
+
+// SetNotificationClient enables alerting of rule notification targets.
+// If not set, triggered rules are only recorded as risk events.
+func (s *RiskService) SetNotificationClient(c *clients.NotificationClient) {
+	s.notifier = c
+}
+
+// SetScorer blends an external model score into every evaluation.
+// If not set, evaluations use the rules alone.
+func (s *RiskService) SetScorer(scorer Scorer, policy ScorerPolicy) {
+	s.scorer = scorer
+	s.scorerPolicy = policy
+}
+
 // EvaluateTransaction evaluates a transaction against all enabled risk rules
 func (s *RiskService) EvaluateTransaction(ctx context.Context, req *models.EvaluationRequest) (*models.EvaluationResult, *errors.Error) {
 	// Get all enabled rules
-	rules, err := s.ruleRepo.GetAll(ctx, true)
+	rules, err := s.rules.enabled(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	// Velocity and daily limit rules share one activity lookup
+	activity, activityErr := s.loadActivity(ctx, rules, req.UserID)
+	if activityErr != nil {
+		log.Printf("[risk] Failed to load activity for user %s: %v", req.UserID, activityErr)
+	}
+
+	// Geo rules share one resolution of the client's location
+	geo := s.loadGeo(ctx, rules, req)
+
 	// Initialize result
 	result := &models.EvaluationResult{
 		Allowed:        true,
@@ -41,16 +83,34 @@ func (s *RiskService) EvaluateTransaction(ctx context.Context, req *models.Evalu
 		TriggeredRules: []string{},
 	}
 
+	var alerts []ruleAlert
+	var shadowMatches []models.ShadowMatch
+
 	// Evaluate each rule
 	for _, rule := range rules {
-		triggered, score, reason, evalErr := s.evaluateRule(ctx, rule, req)
+		triggered, score, reason, evalErr := s.evaluateRule(ctx, rule, req, activity, geo)
 		if evalErr != nil {
 			log.Printf("[risk] Error evaluating rule %s: %v", rule.ID, evalErr)
 			continue
 		}
 
+		// Shadow rules are only recorded, never enforced or alerted on
+		if triggered && rule.Shadow {
+			shadowMatches = append(shadowMatches, models.ShadowMatch{
+				RuleID: rule.ID,
+				Name:   rule.Name,
+				Action: rule.Action,
+				Score:  score,
+				Reason: reason,
+			})
+			continue
+		}
+
 		if triggered {
 			result.TriggeredRules = append(result.TriggeredRules, rule.ID)
+			if len(rule.NotificationTargets) > 0 {
+				alerts = append(alerts, ruleAlert{rule: rule, score: score, reason: reason})
+			}
 
 			// Update risk score (use highest score)
 			if score > result.RiskScore {
@@ -69,6 +129,18 @@ func (s *RiskService) EvaluateTransaction(ctx context.Context, req *models.Evalu
 		}
 	}
 
+	// The user's history raises the rule-based score before any blending
+	result.BaselineScore = s.baselineScore(ctx, req.UserID)
+	result.RiskScore = models.CombineScores(result.RiskScore, result.BaselineScore)
+
+	if s.scorer != nil {
+		s.applyScorer(ctx, req, result)
+	}
+
+	if len(shadowMatches) > 0 {
+		result.Shadow = shadowOutcome(result, shadowMatches)
+	}
+
 	// Create risk event for audit trail
 	event := &models.RiskEvent{
 		TransactionID: req.TransactionID,
@@ -84,14 +156,31 @@ func (s *RiskService) EvaluateTransaction(ctx context.Context, req *models.Evalu
 			"to_wallet_id":     req.ToWalletID,
 		},
 	}
+	if result.BaselineScore > 0 {
+		event.Metadata["baseline_score"] = result.BaselineScore
+	}
+	if result.Model != nil {
+		event.Metadata["model"] = result.Model
+	}
+	if result.Shadow != nil {
+		event.Metadata["shadow"] = result.Shadow
+	}
+	if req.ClientIP != "" {
+		event.Metadata["client_ip"] = req.ClientIP
+	}
+	if geo != nil && geo.location.Country != "" {
+		event.Metadata["client_country"] = geo.location.Country
+	}
 
 	// If rules were triggered, set rule ID and type
 	if len(result.TriggeredRules) > 0 {
 		// Use the first triggered rule for event
-		firstRule, ruleErr := s.ruleRepo.GetByID(ctx, result.TriggeredRules[0])
-		if ruleErr == nil {
-			event.RuleID = &firstRule.ID
-			event.RuleType = &firstRule.RuleType
+		for _, rule := range rules {
+			if rule.ID == result.TriggeredRules[0] {
+				event.RuleID = &rule.ID
+				event.RuleType = &rule.RuleType
+				break
+			}
 		}
 	}
 
@@ -102,36 +191,163 @@ func (s *RiskService) EvaluateTransaction(ctx context.Context, req *models.Evalu
 		result.EventID = event.ID
 	}
 
+	for _, alert := range alerts {
+		s.notifyTargets(alert, req, result.EventID)
+	}
+
+	s.recordLocation(ctx, req, geo, result)
+
 	return result, nil
 }
 
+// ruleAlert is a triggered rule whose notification targets may need alerting
+type ruleAlert struct {
+	rule   *models.RiskRule
+	score  int
+	reason string
+}
+
+// notifyTargets alerts a triggered rule's targets whose min_score the rule met.
+// Delivery is asynchronous so evaluation latency is unaffected.
+func (s *RiskService) notifyTargets(alert ruleAlert, req *models.EvaluationRequest, eventID string) {
+	if s.notifier == nil {
+		return
+	}
+
+	rule := alert.rule
+	var priority clients.NotificationPriority
+	switch rule.Action {
+	case models.RiskActionBlock:
+		priority = clients.NotificationPriorityCritical
+	case models.RiskActionFlag:
+		priority = clients.NotificationPriorityHigh
+	default:
+		return
+	}
+
+	// Deduplicate on the risk event when there is one so retried sends are idempotent
+	reference := eventID
+	if reference == "" {
+		reference = req.TransactionID
+	}
+
+	subject := fmt.Sprintf("Risk alert: %s (%s)", rule.Name, rule.Action)
+	body := fmt.Sprintf("Rule %q triggered %s with risk score %d for user %s, transaction %s: %s",
+		rule.Name, rule.Action, alert.score, req.UserID, req.TransactionID, alert.reason)
+
+	for i, target := range rule.NotificationTargets {
+		if !target.Matches(alert.score) {
+			continue
+		}
+
+		correlationID := fmt.Sprintf("risk-%s-%s-%d", reference, rule.ID, i)
+		s.notifier.SendNotificationAsync(&clients.SendNotificationRequest{
+			Recipient:     target.Recipient,
+			Channel:       clients.NotificationChannel(target.Channel),
+			Type:          clients.NotificationTypeSecurityAlert,
+			Priority:      priority,
+			Subject:       subject,
+			Body:          body,
+			CorrelationID: &correlationID,
+			SourceService: "risk",
+			Metadata: map[string]any{
+				"rule_id":        rule.ID,
+				"rule_name":      rule.Name,
+				"action":         rule.Action,
+				"risk_score":     alert.score,
+				"transaction_id": req.TransactionID,
+				"user_id":        req.UserID,
+				"event_id":       eventID,
+			},
+		}, "risk")
+	}
+}
+
+// EvaluateBatch evaluates several transactions in order. A transaction that
+// cannot be evaluated is reported in its item and does not stop the batch.
+func (s *RiskService) EvaluateBatch(ctx context.Context, reqs []models.EvaluationRequest) *models.BatchEvaluationResult {
+	batch := &models.BatchEvaluationResult{
+		Results: make([]models.BatchEvaluationItem, 0, len(reqs)),
+	}
+
+	for i := range reqs {
+		req := &reqs[i]
+		item := models.BatchEvaluationItem{TransactionID: req.TransactionID}
+
+		result, err := s.EvaluateTransaction(ctx, req)
+		switch {
+		case err != nil:
+			item.Error = &models.BatchItemError{Code: string(err.Code), Message: err.Message}
+			batch.Failed++
+		case result.Action == models.RiskActionBlock:
+			batch.Blocked++
+		case result.Action == models.RiskActionFlag:
+			batch.Flagged++
+		default:
+			batch.Allowed++
+		}
+		item.Result = result
+
+		batch.Results = append(batch.Results, item)
+	}
+
+	return batch
+}
+
+// loadActivity loads the user's activity rollups when a velocity or daily
+// limit rule needs them, covering the longest velocity window.
+func (s *RiskService) loadActivity(ctx context.Context, rules []*models.RiskRule, userID string) (*models.UserActivity, *errors.Error) {
+	needed := false
+	windowMins := 0
+	for _, rule := range rules {
+		switch rule.RuleType {
+		case models.RuleTypeVelocity:
+			needed = true
+			var params models.VelocityRuleParams
+			if err := rule.UnmarshalParameters(&params); err == nil && params.TimeWindowMins > windowMins {
+				windowMins = params.TimeWindowMins
+			}
+		case models.RuleTypeDailyLimit:
+			needed = true
+		}
+	}
+	if !needed {
+		return nil, nil
+	}
+
+	return s.eventRepo.GetUserActivity(ctx, userID, windowMins)
+}
+
 // evaluateRule evaluates a single rule
-func (s *RiskService) evaluateRule(ctx context.Context, rule *models.RiskRule, req *models.EvaluationRequest) (triggered bool, score int, reason string, err *errors.Error) {
+func (s *RiskService) evaluateRule(ctx context.Context, rule *models.RiskRule, req *models.EvaluationRequest, activity *models.UserActivity, geo *geoContext) (triggered bool, score int, reason string, err *errors.Error) {
 	switch rule.RuleType {
 	case models.RuleTypeVelocity:
-		return s.evaluateVelocityRule(ctx, rule, req)
+		return s.evaluateVelocityRule(rule, activity)
 	case models.RuleTypeDailyLimit:
-		return s.evaluateDailyLimitRule(ctx, rule, req)
+		return s.evaluateDailyLimitRule(ctx, rule, req, activity)
 	case models.RuleTypeThreshold:
 		return s.evaluateThresholdRule(ctx, rule, req)
+	case models.RuleTypeGeo:
+		return s.evaluateGeoRule(rule, geo)
 	default:
 		return false, 0, "", errors.Internal(fmt.Sprintf("unknown rule type: %s", rule.RuleType))
 	}
 }
 
 // evaluateVelocityRule checks transaction velocity
-func (s *RiskService) evaluateVelocityRule(ctx context.Context, rule *models.RiskRule, req *models.EvaluationRequest) (bool, int, string, *errors.Error) {
+func (s *RiskService) evaluateVelocityRule(rule *models.RiskRule, activity *models.UserActivity) (bool, int, string, *errors.Error) {
 	var params models.VelocityRuleParams
 	if err := rule.UnmarshalParameters(&params); err != nil {
 		return false, 0, "", errors.Internal("failed to unmarshal velocity params")
 	}
 
-	// Count recent transactions
-	count, err := s.eventRepo.CountUserTransactions(ctx, req.UserID, params.TimeWindowMins)
-	if err != nil {
-		return false, 0, "", err
+	if activity == nil {
+		return false, 0, "", errors.Unavailable("user activity not loaded")
 	}
 
+	// Count recent transactions
+	count := activity.TransactionsSince(params.TimeWindowMins)
+
 	// Check if velocity limit exceeded
 	if count >= params.MaxTransactions {
 		score := 70 + (count-params.MaxTransactions)*5 // Increase score with excess
@@ -149,7 +365,7 @@ func (s *RiskService) evaluateVelocityRule(ctx context.Context, rule *models.Ris
 }
 
 // evaluateDailyLimitRule checks daily transaction limit
-func (s *RiskService) evaluateDailyLimitRule(ctx context.Context, rule *models.RiskRule, req *models.EvaluationRequest) (bool, int, string, *errors.Error) {
+func (s *RiskService) evaluateDailyLimitRule(ctx context.Context, rule *models.RiskRule, req *models.EvaluationRequest, activity *models.UserActivity) (bool, int, string, *errors.Error) {
 	var params models.DailyLimitParams
 	if err := rule.UnmarshalParameters(&params); err != nil {
 		return false, 0, "", errors.Internal("failed to unmarshal daily limit params")
@@ -160,24 +376,27 @@ func (s *RiskService) evaluateDailyLimitRule(ctx context.Context, rule *models.R
 		return false, 0, "", nil
 	}
 
-	// Get user's daily total
-	dailyTotal, err := s.eventRepo.GetUserDailyTotal(ctx, req.UserID)
-	if err != nil {
-		return false, 0, "", err
+	if activity == nil {
+		return false, 0, "", errors.Unavailable("user activity not loaded")
 	}
 
+	// Get user's daily total
+	dailyTotal := activity.AmountToday()
+
+	maxAmount := s.effectiveLimit(ctx, params.Adaptive, params.MaxAmount, req)
+
 	// Check if adding this transaction would exceed limit
 	newTotal := dailyTotal + req.Amount
-	if newTotal > params.MaxAmount {
+	if newTotal > maxAmount {
 		score := 80
-		percentOver := float64(newTotal-params.MaxAmount) / float64(params.MaxAmount) * 100
+		percentOver := float64(newTotal-maxAmount) / float64(maxAmount) * 100
 		score += int(percentOver / 10)
 		if score > 100 {
 			score = 100
 		}
 
 		reason := fmt.Sprintf("Daily limit exceeded: %d %s today + %d %s = %d %s (max: %d %s)",
-			dailyTotal, req.Currency, req.Amount, req.Currency, newTotal, req.Currency, params.MaxAmount, req.Currency)
+			dailyTotal, req.Currency, req.Amount, req.Currency, newTotal, req.Currency, maxAmount, req.Currency)
 
 		return true, score, reason, nil
 	}
@@ -197,17 +416,19 @@ func (s *RiskService) evaluateThresholdRule(ctx context.Context, rule *models.Ri
 		return false, 0, "", nil
 	}
 
+	maxAmount := s.effectiveLimit(ctx, params.Adaptive, params.MaxAmount, req)
+
 	// Check if amount is above threshold
-	if req.Amount > params.MaxAmount {
+	if req.Amount > maxAmount {
 		score := 60
-		percentOver := float64(req.Amount-params.MaxAmount) / float64(params.MaxAmount) * 100
+		percentOver := float64(req.Amount-maxAmount) / float64(maxAmount) * 100
 		score += int(percentOver / 20)
 		if score > 100 {
 			score = 100
 		}
 
 		reason := fmt.Sprintf("Large transaction: %d %s exceeds threshold of %d %s",
-			req.Amount, req.Currency, params.MaxAmount, req.Currency)
+			req.Amount, req.Currency, maxAmount, req.Currency)
 
 		return true, score, reason, nil
 	}
@@ -215,6 +436,49 @@ func (s *RiskService) evaluateThresholdRule(ctx context.Context, rule *models.Ri
 	return false, 0, "", nil
 }
 
+// effectiveLimit applies a rule's adaptive parameters to its static limit.
+// Missing baselines or lookup errors fall back to the static limit.
+func (s *RiskService) effectiveLimit(ctx context.Context, adaptive *models.AdaptiveParams, staticLimit int64, req *models.EvaluationRequest) int64 {
+	if adaptive == nil || adaptive.Multiplier <= 0 || s.baselineRepo == nil {
+		return staticLimit
+	}
+
+	baseline, err := s.baselineRepo.GetByUserID(ctx, req.UserID, req.Currency)
+	if err != nil {
+		if err.Code != errors.ErrCodeNotFound {
+			log.Printf("[risk] Failed to load baseline for user %s: %v", req.UserID, err)
+		}
+		return staticLimit
+	}
+
+	return adaptive.EffectiveLimit(staticLimit, baseline)
+}
+
+// GetUserBaseline retrieves a user's baseline for a currency
+func (s *RiskService) GetUserBaseline(ctx context.Context, userID, currency string) (*models.UserBaseline, *errors.Error) {
+	return s.baselineRepo.GetByUserID(ctx, userID, currency)
+}
+
+// RecomputeBaselines rebuilds all user baselines from the trailing window
+func (s *RiskService) RecomputeBaselines(ctx context.Context, windowWeeks int) (*models.RecomputeBaselinesResult, *errors.Error) {
+	if windowWeeks <= 0 {
+		windowWeeks = models.DefaultBaselineWindowWeeks
+	}
+
+	updated, err := s.baselineRepo.RecomputeAll(ctx, windowWeeks)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[risk] Recomputed baselines for %d user/currency pairs (window: %d weeks)", updated, windowWeeks)
+
+	return &models.RecomputeBaselinesResult{
+		UsersUpdated: updated,
+		WindowWeeks:  windowWeeks,
+		ComputedAt:   time.Now(),
+	}, nil
+}
+
 // GetRuleByID retrieves a risk rule by ID
 func (s *RiskService) GetRuleByID(ctx context.Context, id string) (*models.RiskRule, *errors.Error) {
 	return s.ruleRepo.GetByID(ctx, id)
@@ -225,19 +489,48 @@ func (s *RiskService) GetAllRules(ctx context.Context, enabledOnly bool) ([]*mod
 	return s.ruleRepo.GetAll(ctx, enabledOnly)
 }
 
+// validateRule rejects rules that could not be evaluated or alerted on
+func validateRule(rule *models.RiskRule) *errors.Error {
+	if err := rule.ValidateParameters(); err != nil {
+		return errors.New(errors.ErrCodeRiskRuleInvalid, err.Error())
+	}
+	if err := rule.ValidateNotificationTargets(); err != nil {
+		return errors.New(errors.ErrCodeRiskRuleInvalid, err.Error())
+	}
+	return nil
+}
+
 // CreateRule creates a new risk rule
 func (s *RiskService) CreateRule(ctx context.Context, rule *models.RiskRule) *errors.Error {
-	return s.ruleRepo.Create(ctx, rule)
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return err
+	}
+	s.rules.invalidate()
+	return nil
 }
 
 // UpdateRule updates a risk rule
 func (s *RiskService) UpdateRule(ctx context.Context, rule *models.RiskRule) *errors.Error {
-	return s.ruleRepo.Update(ctx, rule)
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return err
+	}
+	s.rules.invalidate()
+	return nil
 }
 
 // DeleteRule deletes a risk rule
 func (s *RiskService) DeleteRule(ctx context.Context, id string) *errors.Error {
-	return s.ruleRepo.Delete(ctx, id)
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.rules.invalidate()
+	return nil
 }
 
 // GetEventByID retrieves a risk event by ID
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// Approval operations for risk rule changes
+const (
+	OperationCreateRule = "risk_rule.create"
+	OperationUpdateRule = "risk_rule.update"
+	OperationDeleteRule = "risk_rule.delete"
+)
+
+// SetRuleApprovals requires a second admin to approve every rule change.
+// If not set, rule changes apply immediately.
+func (s *RiskService) SetRuleApprovals(approvals *approval.Manager) {
+	approvals.Register(OperationCreateRule, s.executeCreateRule)
+	approvals.Register(OperationUpdateRule, s.executeUpdateRule)
+	approvals.Register(OperationDeleteRule, s.executeDeleteRule)
+	s.approvals = approvals
+}
+
+// Approvals returns the approval manager, or nil if rule changes need no approval.
+func (s *RiskService) Approvals() *approval.Manager {
+	return s.approvals
+}
+
+// RequestCreateRule stores a new rule for a second admin to approve.
+func (s *RiskService) RequestCreateRule(ctx context.Context, rule *models.RiskRule, requestedBy string) (*approval.Approval, *errors.Error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Create %s rule %q (%s)", rule.RuleType, rule.Name, rule.Action)
+	return s.requestRuleChange(ctx, OperationCreateRule, "", requestedBy, summary, rule)
+}
+
+// RequestUpdateRule stores a rule update for a second admin to approve.
+func (s *RiskService) RequestUpdateRule(ctx context.Context, rule *models.RiskRule, requestedBy string) (*approval.Approval, *errors.Error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+	current, err := s.ruleRepo.GetByID(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Update rule %q", current.Name)
+	return s.requestRuleChange(ctx, OperationUpdateRule, rule.ID, requestedBy, summary, rule)
+}
+
+// RequestDeleteRule stores a rule deletion for a second admin to approve.
+func (s *RiskService) RequestDeleteRule(ctx context.Context, id, requestedBy string) (*approval.Approval, *errors.Error) {
+	current, err := s.ruleRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Delete rule %q", current.Name)
+	return s.requestRuleChange(ctx, OperationDeleteRule, id, requestedBy, summary, current)
+}
+
+func (s *RiskService) requestRuleChange(ctx context.Context, operation, ruleID, requestedBy, summary string, rule *models.RiskRule) (*approval.Approval, *errors.Error) {
+	if s.approvals == nil {
+		return nil, errors.Internal("rule approvals are not enabled")
+	}
+	return s.approvals.Request(ctx, operation, ruleID, requestedBy, summary, rule)
+}
+
+func (s *RiskService) executeCreateRule(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	var rule models.RiskRule
+	if err := a.DecodePayload(&rule); err != nil {
+		return nil, err
+	}
+	if err := s.CreateRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RiskService) executeUpdateRule(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	var rule models.RiskRule
+	if err := a.DecodePayload(&rule); err != nil {
+		return nil, err
+	}
+	rule.ID = a.ResourceID
+	if err := s.UpdateRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RiskService) executeDeleteRule(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	return nil, s.DeleteRule(ctx, a.ResourceID)
+}
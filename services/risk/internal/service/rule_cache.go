@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/1mb-dev/nivomoney/services/risk/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultRuleCacheTTL bounds how stale cached rules can be. Rule changes made
+// through this instance invalidate the cache at once; the TTL covers changes
+// made through other instances or directly in the database.
+const DefaultRuleCacheTTL = 30 * time.Second
+
+var ruleCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "risk_rule_cache_lookups_total",
+	Help: "Enabled rule lookups by result (hit, miss)",
+}, []string{"result"})
+
+// ruleCache holds the enabled rules between evaluations.
+type ruleCache struct {
+	repo *repository.RiskRuleRepository
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	rules    []*models.RiskRule
+	loadedAt time.Time
+	version  uint64 // Bumped on invalidation so a load racing a change is not kept
+}
+
+func newRuleCache(repo *repository.RiskRuleRepository, ttl time.Duration) *ruleCache {
+	return &ruleCache{repo: repo, ttl: ttl}
+}
+
+// enabled returns the enabled rules, loading them if the cache is empty,
+// expired or disabled (zero TTL). Callers must not modify the rules.
+func (c *ruleCache) enabled(ctx context.Context) ([]*models.RiskRule, *errors.Error) {
+	c.mu.Lock()
+	if c.rules != nil && c.ttl > 0 && time.Since(c.loadedAt) < c.ttl {
+		rules := c.rules
+		c.mu.Unlock()
+		ruleCacheLookups.WithLabelValues("hit").Inc()
+		return rules, nil
+	}
+	version := c.version
+	c.mu.Unlock()
+
+	ruleCacheLookups.WithLabelValues("miss").Inc()
+	rules, err := c.repo.GetAll(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.version == version {
+		c.rules, c.loadedAt = rules, time.Now()
+	}
+	c.mu.Unlock()
+	return rules, nil
+}
+
+// invalidate drops the cached rules so the next evaluation reloads them.
+func (c *ruleCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules = nil
+	c.version++
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	scorerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "risk_scorer_duration_seconds",
+		Help:    "Latency of external risk scorer calls",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"scorer", "outcome"})
+
+	scorerCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "risk_scorer_calls_total",
+		Help: "External risk scorer calls by outcome (ok, error, timeout)",
+	}, []string{"scorer", "outcome"})
+)
+
+// Scorer scores a transaction with a model outside the rule engine.
+type Scorer interface {
+	// Name identifies the scorer in metrics and evaluation results.
+	Name() string
+	// Score returns a risk score for the transaction.
+	Score(ctx context.Context, req *models.EvaluationRequest) (*models.ModelScore, error)
+}
+
+// HTTPScorer calls an external scoring endpoint. The endpoint receives the
+// evaluation request as JSON and answers with {"score": 0-100, ...}.
+type HTTPScorer struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPScorer creates a scorer for the given endpoint.
+func NewHTTPScorer(url string, timeout time.Duration) *HTTPScorer {
+	return &HTTPScorer{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements Scorer.
+func (s *HTTPScorer) Name() string {
+	return "http"
+}
+
+// Score implements Scorer.
+func (s *HTTPScorer) Score(ctx context.Context, req *models.EvaluationRequest) (*models.ModelScore, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode scoring request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scoring request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scoring response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scorer returned status %d", resp.StatusCode)
+	}
+
+	var score models.ModelScore
+	if err := json.Unmarshal(respBody, &score); err != nil {
+		return nil, fmt.Errorf("invalid scoring response: %w", err)
+	}
+	if score.Score < 0 || score.Score > 100 {
+		return nil, fmt.Errorf("scorer returned score %d outside 0-100", score.Score)
+	}
+	return &score, nil
+}
+
+// StubScorer is a local stand-in for a scoring model. It scores larger
+// amounts higher and adds a stable per-user offset, so results vary across
+// users but are repeatable.
+type StubScorer struct {
+	latency time.Duration
+}
+
+// NewStubScorer creates a stub scorer that answers after latency.
+func NewStubScorer(latency time.Duration) *StubScorer {
+	return &StubScorer{latency: latency}
+}
+
+// Name implements Scorer.
+func (s *StubScorer) Name() string {
+	return "stub"
+}
+
+// Score implements Scorer.
+func (s *StubScorer) Score(ctx context.Context, req *models.EvaluationRequest) (*models.ModelScore, error) {
+	if s.latency > 0 {
+		timer := time.NewTimer(s.latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// ₹1,000 scores about 30, ₹1,00,000 about 70 (amounts are in paise)
+	score := 0
+	if req.Amount > 0 {
+		score = int(math.Log10(float64(req.Amount)) * 10)
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(req.UserID))
+	score += int(h.Sum32()%21) - 10
+
+	return &models.ModelScore{
+		Score:        min(max(score, 0), 100),
+		ModelVersion: "stub-v1",
+		Reasons:      []string{"amount_magnitude", "user_offset"},
+	}, nil
+}
+
+// scoreWithMetrics calls the scorer within the policy timeout and records its
+// latency and outcome.
+func scoreWithMetrics(ctx context.Context, scorer Scorer, timeout time.Duration, req *models.EvaluationRequest) (*models.ModelScore, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	score, err := scorer.Score(ctx, req)
+	elapsed := time.Since(started)
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		if ctx.Err() == context.DeadlineExceeded {
+			outcome = "timeout"
+		}
+	}
+	scorerDuration.WithLabelValues(scorer.Name(), outcome).Observe(elapsed.Seconds())
+	scorerCalls.WithLabelValues(scorer.Name(), outcome).Inc()
+
+	return score, elapsed, err
+}
+
+// ScorerPolicy controls how an external score is blended into an evaluation.
+type ScorerPolicy struct {
+	Timeout    time.Duration // Upper bound on a scorer call
+	FailClosed bool          // Block when the scorer fails instead of keeping the rule result
+	Weight     float64       // Share of the model score in the blended score (0-1)
+	FlagScore  int           // Blended score at which an allowed transaction is flagged
+	BlockScore int           // Blended score at which a transaction is blocked
+}
+
+// DefaultScorerPolicy fails open with an even blend.
+func DefaultScorerPolicy() ScorerPolicy {
+	return ScorerPolicy{
+		Timeout:    200 * time.Millisecond,
+		Weight:     0.5,
+		FlagScore:  70,
+		BlockScore: 90,
+	}
+}
+
+// applyScorer blends the external score into a rule-based result. The model
+// can escalate an action but never relaxes one a rule decided. A failing
+// scorer keeps the rule result when failing open and blocks when failing
+// closed.
+func (s *RiskService) applyScorer(ctx context.Context, req *models.EvaluationRequest, result *models.EvaluationResult) {
+	policy := s.scorerPolicy
+	score, elapsed, err := scoreWithMetrics(ctx, s.scorer, policy.Timeout, req)
+
+	model := &models.ModelResult{
+		Scorer:    s.scorer.Name(),
+		RuleScore: result.RiskScore,
+		Weight:    policy.Weight,
+		LatencyMs: math.Round(float64(elapsed)/float64(time.Millisecond)*100) / 100,
+	}
+	result.Model = model
+
+	if err != nil {
+		model.Error = err.Error()
+		if policy.FailClosed {
+			model.Status = models.ScorerStatusFailedClosed
+			result.Allowed = false
+			result.Action = models.RiskActionBlock
+			result.Reason = "External risk scorer unavailable"
+			return
+		}
+		model.Status = models.ScorerStatusFailedOpen
+		return
+	}
+
+	model.Status = models.ScorerStatusScored
+	model.Score = &score.Score
+	model.ModelVersion = score.ModelVersion
+	model.Reasons = score.Reasons
+
+	blended := int(math.Round((1-policy.Weight)*float64(result.RiskScore) + policy.Weight*float64(score.Score)))
+	result.RiskScore = blended
+
+	switch {
+	case blended >= policy.BlockScore && result.Action != models.RiskActionBlock:
+		result.Allowed = false
+		result.Action = models.RiskActionBlock
+		result.Reason = fmt.Sprintf("Model risk score %d (blended %d) at or above block threshold %d", score.Score, blended, policy.BlockScore)
+	case blended >= policy.FlagScore && result.Action == models.RiskActionAllow:
+		result.Action = models.RiskActionFlag
+		result.Reason = fmt.Sprintf("Model risk score %d (blended %d) at or above flag threshold %d", score.Score, blended, policy.FlagScore)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"github.com/1mb-dev/nivomoney/services/risk/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	shadowTriggers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "risk_shadow_rule_triggers_total",
+		Help: "Shadow rule triggers by rule name and the action the rule would have taken",
+	}, []string{"rule", "action"})
+
+	shadowOutcomeChanges = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "risk_shadow_outcome_changes_total",
+		Help: "Evaluations whose action enforcing shadow rules would have changed, by would-be action",
+	}, []string{"action"})
+)
+
+// actionRank orders actions by severity so the strictest one wins.
+func actionRank(action models.RiskAction) int {
+	switch action {
+	case models.RiskActionBlock:
+		return 2
+	case models.RiskActionFlag:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// shadowOutcome works out what the triggered shadow rules would have done to
+// the enforced result, and records it in metrics. The result itself is left
+// untouched.
+func shadowOutcome(result *models.EvaluationResult, triggered []models.ShadowMatch) *models.ShadowResult {
+	shadow := &models.ShadowResult{
+		Triggered:     triggered,
+		WouldBeAction: result.Action,
+		WouldBeScore:  result.RiskScore,
+	}
+
+	for _, match := range triggered {
+		shadowTriggers.WithLabelValues(match.Name, string(match.Action)).Inc()
+		if match.Score > shadow.WouldBeScore {
+			shadow.WouldBeScore = match.Score
+		}
+		if actionRank(match.Action) > actionRank(shadow.WouldBeAction) {
+			shadow.WouldBeAction = match.Action
+		}
+	}
+
+	if shadow.WouldBeAction != result.Action {
+		shadow.Changed = true
+		shadowOutcomeChanges.WithLabelValues(string(shadow.WouldBeAction)).Inc()
+	}
+	return shadow
+}
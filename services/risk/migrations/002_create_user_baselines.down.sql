@@ -0,0 +1,2 @@
+-- Drop user_risk_baselines table
+DROP TABLE IF EXISTS user_risk_baselines;
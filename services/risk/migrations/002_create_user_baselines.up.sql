@@ -0,0 +1,18 @@
+-- Create user_risk_baselines table
+-- Stores per-user trailing outflow statistics used by adaptive rule thresholds.
+-- Rows are recomputed nightly from risk_events.
+CREATE TABLE IF NOT EXISTS user_risk_baselines (
+    user_id UUID NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    avg_weekly_outflow BIGINT NOT NULL DEFAULT 0,   -- Average outflow per week over the window
+    avg_daily_outflow BIGINT NOT NULL DEFAULT 0,    -- Average outflow per active day over the window
+    avg_transaction_amount BIGINT NOT NULL DEFAULT 0,
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    window_weeks INTEGER NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, currency),
+    CONSTRAINT user_risk_baselines_window_check CHECK (window_weeks > 0)
+);
+
+CREATE INDEX idx_user_risk_baselines_computed_at ON user_risk_baselines(computed_at);
@@ -0,0 +1,4 @@
+-- Per-rule notification targets (email groups, chat webhooks) alerted when a
+-- rule triggers a block or flag
+ALTER TABLE risk_rules
+    ADD COLUMN IF NOT EXISTS notification_targets JSONB NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,2 @@
+-- Drop risk_user_activity table
+DROP TABLE IF EXISTS risk_user_activity;
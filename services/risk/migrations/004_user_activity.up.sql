@@ -0,0 +1,25 @@
+-- Create risk_user_activity table
+-- Per-user, per-minute rollups of evaluated transactions, kept up to date as
+-- risk events are recorded. Velocity and daily limit rules read these
+-- instead of scanning risk_events on every evaluation.
+CREATE TABLE IF NOT EXISTS risk_user_activity (
+    user_id UUID NOT NULL,
+    bucket_start TIMESTAMP WITH TIME ZONE NOT NULL, -- Start of the minute
+    transactions INTEGER NOT NULL DEFAULT 0,        -- Transactions evaluated
+    amount BIGINT NOT NULL DEFAULT 0,               -- Amount of transactions that were not blocked
+
+    PRIMARY KEY (user_id, bucket_start)
+);
+
+CREATE INDEX idx_risk_user_activity_bucket ON risk_user_activity(bucket_start);
+
+-- Backfill from recent events so checks stay accurate across the deploy
+INSERT INTO risk_user_activity (user_id, bucket_start, transactions, amount)
+SELECT user_id,
+       date_trunc('minute', created_at),
+       COUNT(DISTINCT transaction_id),
+       COALESCE(SUM((metadata->>'amount')::bigint) FILTER (WHERE action != 'block'), 0)
+FROM risk_events
+WHERE created_at >= NOW() - INTERVAL '7 days'
+GROUP BY user_id, date_trunc('minute', created_at)
+ON CONFLICT (user_id, bucket_start) DO NOTHING;
@@ -0,0 +1,7 @@
+-- Geo/IP rules rollback
+DROP TABLE IF EXISTS risk_user_locations;
+
+DELETE FROM risk_rules WHERE rule_type = 'geo';
+ALTER TABLE risk_rules DROP CONSTRAINT IF EXISTS risk_rules_type_check;
+ALTER TABLE risk_rules ADD CONSTRAINT risk_rules_type_check
+    CHECK (rule_type IN ('velocity', 'daily_limit', 'threshold'));
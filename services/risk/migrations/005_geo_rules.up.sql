@@ -0,0 +1,17 @@
+-- Geo/IP rules
+-- Allow the geo rule type and remember where each user last transacted from,
+-- so impossible-travel checks can compare against it.
+ALTER TABLE risk_rules DROP CONSTRAINT IF EXISTS risk_rules_type_check;
+ALTER TABLE risk_rules ADD CONSTRAINT risk_rules_type_check
+    CHECK (rule_type IN ('velocity', 'daily_limit', 'threshold', 'geo'));
+
+CREATE TABLE IF NOT EXISTS risk_user_locations (
+    user_id UUID PRIMARY KEY,
+    ip VARCHAR(45) NOT NULL,             -- Last client IP (IPv4 or IPv6)
+    country CHAR(2),                     -- ISO 3166-1 alpha-2, if resolved
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE risk_user_locations IS 'Last location each user transacted from, for impossible-travel checks';
@@ -0,0 +1 @@
+ALTER TABLE risk_rules DROP COLUMN IF EXISTS shadow;
@@ -0,0 +1,4 @@
+-- Shadow rules: evaluated and recorded in risk events, but never change the
+-- outcome of an evaluation
+ALTER TABLE risk_rules
+    ADD COLUMN IF NOT EXISTS shadow BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,2 @@
+-- User risk profiles rollback
+DROP TABLE IF EXISTS risk_profile_signals;
@@ -0,0 +1,32 @@
+-- User risk profiles
+-- Signals that accumulate against a user over time: blocked and flagged
+-- evaluations, recorded as they happen, and chargebacks, KYC flags and device
+-- changes reported by other services. A user's profile and baseline score are
+-- aggregated from their recent signals.
+CREATE TABLE IF NOT EXISTS risk_profile_signals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    signal_type VARCHAR(30) NOT NULL,
+    source VARCHAR(50) NOT NULL,          -- Service that reported the signal
+    reference VARCHAR(255) NOT NULL,      -- Reporter's ID for it, e.g. a risk event or dispute ID
+    details JSONB,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT risk_profile_signals_type_check
+        CHECK (signal_type IN ('block', 'flag', 'chargeback', 'kyc_flag', 'device_change')),
+    -- Reporting a signal again is a no-op
+    CONSTRAINT risk_profile_signals_reference_unique UNIQUE (user_id, signal_type, reference)
+);
+
+CREATE INDEX idx_risk_profile_signals_user ON risk_profile_signals(user_id, occurred_at DESC);
+
+COMMENT ON TABLE risk_profile_signals IS 'Risk signals accumulated per user, aggregated into risk profiles';
+
+-- Backfill past blocks and flags so existing users keep their history
+INSERT INTO risk_profile_signals (user_id, signal_type, source, reference, occurred_at)
+SELECT user_id, action, 'risk', id::text, created_at
+FROM risk_events
+WHERE action IN ('block', 'flag')
+  AND COALESCE(metadata->'model'->>'status', '') != 'failed_closed'
+ON CONFLICT (user_id, signal_type, reference) DO NOTHING;
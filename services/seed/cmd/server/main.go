@@ -15,7 +15,6 @@ import (
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
-	"gopkg.in/yaml.v3"
 
 	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/database"
@@ -33,16 +32,40 @@ type SeedData struct {
 
 // SeedUser represents a user in the seed data
 type SeedUser struct {
-	ID             string  `yaml:"id"`
-	FullName       string  `yaml:"full_name"`
-	Email          string  `yaml:"email"`
-	Password       string  `yaml:"password"`
-	Phone          string  `yaml:"phone"`
-	PAN            string  `yaml:"pan"`
-	Aadhaar        string  `yaml:"aadhaar"`
-	DateOfBirth    string  `yaml:"date_of_birth"`
-	Address        Address `yaml:"address"`
-	InitialBalance int64   `yaml:"initial_balance"` // In paise
+	ID             string       `yaml:"id"`
+	FullName       string       `yaml:"full_name"`
+	Email          string       `yaml:"email"`
+	Password       string       `yaml:"password"`
+	Phone          string       `yaml:"phone"`
+	PAN            string       `yaml:"pan"`
+	Aadhaar        string       `yaml:"aadhaar"`
+	DateOfBirth    string       `yaml:"date_of_birth"`
+	Address        Address      `yaml:"address"`
+	InitialBalance int64        `yaml:"initial_balance"` // In paise
+	Tier           string       `yaml:"tier"`            // Account tier code; unchanged when empty
+	Wallets        []WalletSpec `yaml:"wallets"`         // Defaults to one INR wallet holding InitialBalance
+}
+
+// wallets returns the wallets to create for the user.
+func (u SeedUser) wallets() []WalletSpec {
+	if len(u.Wallets) > 0 {
+		return u.Wallets
+	}
+	return []WalletSpec{{Type: "default", Currency: "INR", Balance: u.InitialBalance}}
+}
+
+// openingBalance is the total opening balance of the user's wallets, in paise.
+func (u SeedUser) openingBalance() int64 {
+	var total int64
+	for _, w := range u.wallets() {
+		total += w.Balance
+	}
+	return total
+}
+
+// isAdmin reports whether the user is the platform admin account.
+func (u SeedUser) isAdmin() bool {
+	return u.Email == "admin@vnykmshr.com" || u.Email == "admin@nivo.local"
 }
 
 // Address represents user address
@@ -57,12 +80,13 @@ type Address struct {
 func main() {
 	// Parse command line flags
 	cleanFlag := flag.Bool("clean", false, "Clean database before seeding")
+	profileFlag := flag.String("profile", DefaultProfile, "Seed profile: a built-in profile name or a path to a YAML/JSON file")
+	serveFlag := flag.Bool("serve", false, "Serve the reset-to-profile API instead of seeding once")
 	flag.Parse()
 
 	log.Printf("[%s] ========================================", serviceName)
 	log.Printf("[%s]   Nivo Money - Database Seed Script", serviceName)
 	log.Printf("[%s] ========================================", serviceName)
-	log.Printf("[%s] Clean mode: %v", serviceName, *cleanFlag)
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -79,71 +103,39 @@ func main() {
 
 	log.Printf("[%s] Connected to database successfully", serviceName)
 
-	// Clean database if requested
-	if *cleanFlag {
-		if err := cleanDatabase(db); err != nil {
-			log.Fatalf("[%s] Failed to clean database: %v", serviceName, err)
+	if *serveFlag {
+		if err := serve(db); err != nil {
+			log.Fatalf("[%s] Seed API stopped: %v", serviceName, err)
 		}
-		log.Printf("[%s] Database cleaned successfully", serviceName)
+		return
 	}
 
-	// Parse seed data
-	var seedData SeedData
-	if err := yaml.Unmarshal(usersData, &seedData); err != nil {
-		log.Fatalf("[%s] Failed to parse seed data: %v", serviceName, err)
-	}
-
-	log.Printf("[%s] Loaded %d users from seed data", serviceName, len(seedData.Users))
-
-	// Generate secure admin password (override static YAML value)
-	adminPassword := generateSecurePassword(16)
-	for i := range seedData.Users {
-		if seedData.Users[i].Email == "admin@nivo.local" || seedData.Users[i].Email == "admin@vnykmshr.com" {
-			seedData.Users[i].Password = adminPassword
-			log.Printf("[%s] Generated secure admin password (not using static YAML value)", serviceName)
-		}
-	}
-
-	log.Printf("[%s] ", serviceName)
-
-	ctx := context.Background()
-
-	// Setup role permissions (ensure 'user' role has required permissions)
-	if err := setupRolePermissions(ctx, db); err != nil {
-		log.Fatalf("[%s] Failed to setup role permissions: %v", serviceName, err)
-	}
-
-	// Seed users with complete setup
-	if err := seedCompleteUsers(ctx, db, seedData.Users); err != nil {
-		log.Fatalf("[%s] Failed to seed users: %v", serviceName, err)
-	}
-
-	// Ensure all wallets have limits (for existing wallets that may not have limits)
-	if err := ensureWalletLimits(ctx, db); err != nil {
-		log.Printf("[%s] Warning: Failed to ensure wallet limits: %v", serviceName, err)
-	}
-
-	// Seed demo data (beneficiaries, virtual cards)
-	if err := seedDemoData(ctx, db); err != nil {
-		log.Printf("[%s] Warning: Failed to seed demo data: %v", serviceName, err)
+	profile, err := LoadProfile(*profileFlag)
+	if err != nil {
+		log.Fatalf("[%s] Failed to load profile: %v", serviceName, err)
 	}
 
-	// Write credentials to .secrets/credentials.txt
-	if err := writeCredentialsFile(seedData.Users, adminPassword); err != nil {
-		log.Printf("[%s] Warning: Failed to write credentials file: %v", serviceName, err)
+	summary, err := seedProfile(context.Background(), db, profile, *cleanFlag)
+	if err != nil {
+		log.Fatalf("[%s] Failed to seed profile %s: %v", serviceName, profile.Name, err)
 	}
 
 	log.Printf("[%s] ", serviceName)
 	log.Printf("[%s] ========================================", serviceName)
 	log.Printf("[%s] Seed completed successfully!", serviceName)
-	log.Printf("[%s] Created/verified %d ready-to-use accounts", serviceName, len(seedData.Users))
+	log.Printf("[%s] Profile %s: %d accounts, %d wallets, %d history transfers, %d risk rules",
+		serviceName, summary.Profile, summary.Users, summary.Wallets, summary.Transfers, summary.RiskRules)
 	log.Printf("[%s] ========================================", serviceName)
+
+	if summary.AdminPassword == "" {
+		return
+	}
 	log.Printf("[%s] ", serviceName)
 	log.Printf("[%s] ┌─────────────────────────────────────────────────────┐", serviceName)
 	log.Printf("[%s] │            ADMIN CREDENTIALS (Generated)            │", serviceName)
 	log.Printf("[%s] ├─────────────────────────────────────────────────────┤", serviceName)
 	log.Printf("[%s] │  Email:    admin@nivo.local                         │", serviceName)
-	log.Printf("[%s] │  Password: %-40s │", serviceName, adminPassword)
+	log.Printf("[%s] │  Password: %-40s │", serviceName, summary.AdminPassword)
 	log.Printf("[%s] ├─────────────────────────────────────────────────────┤", serviceName)
 	log.Printf("[%s] │  Credentials saved to: .secrets/credentials.txt    │", serviceName)
 	log.Printf("[%s] └─────────────────────────────────────────────────────┘", serviceName)
@@ -151,10 +143,21 @@ func main() {
 	log.Printf("[%s] Demo user credentials are in README.md (public)", serviceName)
 }
 
+// seededWallet is a wallet created or found while seeding users.
+type seededWallet struct {
+	UserID          string
+	WalletID        string
+	LedgerAccountID string
+	Type            string
+	Currency        string
+}
+
 // seedCompleteUsers creates complete user accounts with KYC, wallets, and initial balance
-func seedCompleteUsers(ctx context.Context, db *database.DB, users []SeedUser) error {
+func seedCompleteUsers(ctx context.Context, db *database.DB, users []SeedUser) ([]seededWallet, error) {
 	log.Printf("[%s] ========== Seeding Complete User Accounts ==========", serviceName)
 
+	var wallets []seededWallet
+
 	for i, seedUser := range users {
 		log.Printf("[%s] ", serviceName)
 		log.Printf("[%s] [%d/%d] Processing: %s (%s)", serviceName, i+1, len(users), seedUser.FullName, seedUser.Email)
@@ -167,7 +170,7 @@ func seedCompleteUsers(ctx context.Context, db *database.DB, users []SeedUser) e
 		}
 
 		// Step 1b: Create User-Admin account (skip for admin users)
-		isAdmin := seedUser.Email == "admin@vnykmshr.com" || seedUser.Email == "admin@nivo.local"
+		isAdmin := seedUser.isAdmin()
 		var userAdminID string
 		if !isAdmin {
 			userAdminID, err = createUserAdmin(ctx, db, userID, seedUser)
@@ -226,25 +229,25 @@ func seedCompleteUsers(ctx context.Context, db *database.DB, users []SeedUser) e
 			}
 		}
 
-		// Step 5: Create ledger account for wallet
-		ledgerAccountID, err := createLedgerAccount(ctx, db, userID, seedUser)
-		if err != nil {
-			log.Printf("[%s]   ERROR: Failed to create ledger account: %v", serviceName, err)
-			continue
+		// Steps 5-7: Create a ledger account and wallet for each wallet spec,
+		// funding wallets with their opening balance when first created
+		walletIDs := make([]string, 0, len(seedUser.wallets()))
+		for _, spec := range seedUser.wallets() {
+			walletID, err := seedWallet(ctx, db, userID, seedUser, spec, &wallets)
+			if err != nil {
+				log.Printf("[%s]   ERROR: Failed to set up %s %s wallet: %v", serviceName, spec.Type, spec.Currency, err)
+				continue
+			}
+			walletIDs = append(walletIDs, walletID)
 		}
-
-		// Step 6: Create wallet
-		walletID, err := createWallet(ctx, db, userID, ledgerAccountID)
-		if err != nil {
-			log.Printf("[%s]   ERROR: Failed to create wallet: %v", serviceName, err)
+		if len(walletIDs) == 0 {
 			continue
 		}
 
-		// Step 7: Add initial balance if specified
-		if seedUser.InitialBalance > 0 {
-			if err := addInitialBalance(ctx, db, userID, walletID, ledgerAccountID, seedUser.InitialBalance); err != nil {
-				log.Printf("[%s]   ERROR: Failed to add initial balance: %v", serviceName, err)
-				continue
+		// Step 8: Move the user to their tier, with its transfer limits
+		if seedUser.Tier != "" {
+			if err := setUserTier(ctx, db, userID, seedUser.Tier); err != nil {
+				log.Printf("[%s]   ERROR: Failed to set tier %s: %v", serviceName, seedUser.Tier, err)
 			}
 		}
 
@@ -252,12 +255,41 @@ func seedCompleteUsers(ctx context.Context, db *database.DB, users []SeedUser) e
 		if userAdminID != "" {
 			adminInfo = ", UserAdmin=paired"
 		}
-		log.Printf("[%s]   ✓ Complete account ready: UserID=%s, WalletID=%s, Balance=₹%.2f%s",
-			serviceName, userID, walletID, float64(seedUser.InitialBalance)/100.0, adminInfo)
+		log.Printf("[%s]   ✓ Complete account ready: UserID=%s, Wallets=%s%s",
+			serviceName, userID, strings.Join(walletIDs, ","), adminInfo)
 	}
 
 	log.Printf("[%s] ", serviceName)
-	return nil
+	return wallets, nil
+}
+
+// seedWallet creates or finds one of a user's wallets and its ledger account.
+// Opening balances are only added to new wallets, so re-runs don't add them twice.
+func seedWallet(ctx context.Context, db *database.DB, userID string, user SeedUser, spec WalletSpec, wallets *[]seededWallet) (string, error) {
+	ledgerAccountID, err := createLedgerAccount(ctx, db, userID, user, spec)
+	if err != nil {
+		return "", fmt.Errorf("ledger account: %w", err)
+	}
+
+	walletID, created, err := createWallet(ctx, db, userID, ledgerAccountID, spec)
+	if err != nil {
+		return "", fmt.Errorf("wallet: %w", err)
+	}
+
+	if created && spec.Balance > 0 {
+		if err := addInitialBalance(ctx, db, userID, walletID, ledgerAccountID, spec.Balance); err != nil {
+			return "", fmt.Errorf("initial balance: %w", err)
+		}
+	}
+
+	*wallets = append(*wallets, seededWallet{
+		UserID:          userID,
+		WalletID:        walletID,
+		LedgerAccountID: ledgerAccountID,
+		Type:            spec.Type,
+		Currency:        spec.Currency,
+	})
+	return walletID, nil
 }
 
 // createUser creates a user or returns existing user ID
@@ -412,17 +444,20 @@ func activateUser(ctx context.Context, db *database.DB, userID string) error {
 	return nil
 }
 
-// createLedgerAccount creates a ledger account for the user's wallet
-func createLedgerAccount(ctx context.Context, db *database.DB, userID string, user SeedUser) (string, error) {
-	// Check if ledger account already exists for this user
+// createLedgerAccount creates a ledger account for one of the user's wallets.
+// The default INR wallet's account is WALLET-{user_id}; other wallets add
+// their type and currency to the code.
+func createLedgerAccount(ctx context.Context, db *database.DB, userID string, user SeedUser, wallet WalletSpec) (string, error) {
+	code := "WALLET-" + userID
+	name := user.FullName + " - Wallet"
+	if wallet.Type != "default" || wallet.Currency != "INR" {
+		code = fmt.Sprintf("%s-%s-%s", code, strings.ToUpper(wallet.Type), wallet.Currency)
+		name = fmt.Sprintf("%s - %s Wallet (%s)", user.FullName, strings.ToUpper(wallet.Type[:1])+wallet.Type[1:], wallet.Currency)
+	}
+
+	// Check if ledger account already exists for this wallet
 	var existingID string
-	checkQuery := `
-		SELECT id FROM accounts
-		WHERE code LIKE $1 AND status = 'active'
-		LIMIT 1
-	`
-	codePattern := "WALLET-" + userID[:8] + "%"
-	err := db.QueryRowContext(ctx, checkQuery, codePattern).Scan(&existingID)
+	err := db.QueryRowContext(ctx, "SELECT id FROM accounts WHERE code = $1 AND status = 'active'", code).Scan(&existingID)
 	if err == nil {
 		log.Printf("[%s]   → Ledger account already exists: %s", serviceName, existingID)
 		return existingID, nil
@@ -432,11 +467,9 @@ func createLedgerAccount(ctx context.Context, db *database.DB, userID string, us
 	var accountID string
 	query := `
 		INSERT INTO accounts (code, name, type, currency, status, metadata)
-		VALUES ($1, $2, 'liability', 'INR', 'active', $3)
+		VALUES ($1, $2, 'liability', $3, 'active', $4)
 		RETURNING id
 	`
-	code := "WALLET-" + userID
-	name := user.FullName + " - Wallet"
 	metadata := map[string]interface{}{
 		"user_id":  userID,
 		"purpose":  "customer_wallet",
@@ -444,7 +477,7 @@ func createLedgerAccount(ctx context.Context, db *database.DB, userID string, us
 	}
 	metadataJSON, _ := json.Marshal(metadata)
 
-	err = db.QueryRowContext(ctx, query, code, name, metadataJSON).Scan(&accountID)
+	err = db.QueryRowContext(ctx, query, code, name, wallet.Currency, metadataJSON).Scan(&accountID)
 	if err != nil {
 		return "", err
 	}
@@ -453,28 +486,28 @@ func createLedgerAccount(ctx context.Context, db *database.DB, userID string, us
 	return accountID, nil
 }
 
-// createWallet creates a wallet for the user
-func createWallet(ctx context.Context, db *database.DB, userID, ledgerAccountID string) (string, error) {
+// createWallet creates a wallet for the user, reporting whether it is new
+func createWallet(ctx context.Context, db *database.DB, userID, ledgerAccountID string, wallet WalletSpec) (string, bool, error) {
 	// Check if wallet already exists
 	var existingID string
 	err := db.QueryRowContext(ctx,
-		"SELECT id FROM wallets WHERE user_id = $1 AND type = 'default' AND status != 'closed'",
-		userID).Scan(&existingID)
+		"SELECT id FROM wallets WHERE user_id = $1 AND type = $2 AND currency = $3 AND status != 'closed'",
+		userID, wallet.Type, wallet.Currency).Scan(&existingID)
 	if err == nil {
 		log.Printf("[%s]   → Wallet already exists: %s", serviceName, existingID)
-		return existingID, nil
+		return existingID, false, nil
 	}
 
 	// Create new wallet
 	var walletID string
 	query := `
 		INSERT INTO wallets (user_id, type, currency, balance, available_balance, status, ledger_account_id)
-		VALUES ($1, 'default', 'INR', 0, 0, 'active', $2)
+		VALUES ($1, $2, $3, 0, 0, 'active', $4)
 		RETURNING id
 	`
-	err = db.QueryRowContext(ctx, query, userID, ledgerAccountID).Scan(&walletID)
+	err = db.QueryRowContext(ctx, query, userID, wallet.Type, wallet.Currency, ledgerAccountID).Scan(&walletID)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	// Create default wallet limits (₹10,000/day = 1000000 paise, ₹100,000/month = 10000000 paise)
@@ -489,7 +522,7 @@ func createWallet(ctx context.Context, db *database.DB, userID, ledgerAccountID
 	}
 
 	log.Printf("[%s]   → Wallet created: %s (with default limits)", serviceName, walletID)
-	return walletID, nil
+	return walletID, true, nil
 }
 
 // addInitialBalance adds initial balance to wallet using proper double-entry bookkeeping
@@ -501,9 +534,9 @@ func addInitialBalance(ctx context.Context, db *database.DB, userID, walletID, l
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	// Create journal entry with unique entry number (includes user ID for uniqueness)
+	// Create journal entry with unique entry number (includes wallet ID for uniqueness)
 	var journalEntryID string
-	entryNumber := fmt.Sprintf("SEED-%s-%s", time.Now().Format("20060102-150405"), userID[:8])
+	entryNumber := fmt.Sprintf("SEED-%s-%s", time.Now().Format("20060102-150405"), walletID[:8])
 	journalQuery := `
 		INSERT INTO journal_entries (entry_number, type, status, description, reference_type, reference_id, posted_at, posted_by)
 		VALUES ($1, 'opening', 'posted', $2, 'seed', $3, NOW(), $4::uuid)
@@ -1044,25 +1077,27 @@ func writeCredentialsFile(users []SeedUser, adminPassword string) error {
 	sb.WriteString("# Admin credentials are generated per-instance for security.\n")
 	sb.WriteString("\n")
 
-	sb.WriteString("================================================================================\n")
-	sb.WriteString("ADMIN CREDENTIALS (Generated - Not Public)\n")
-	sb.WriteString("================================================================================\n")
-	sb.WriteString("Email:    admin@nivo.local\n")
-	sb.WriteString(fmt.Sprintf("Password: %s\n", adminPassword))
-	sb.WriteString("Role:     admin\n")
-	sb.WriteString("\n")
+	if adminPassword != "" {
+		sb.WriteString("================================================================================\n")
+		sb.WriteString("ADMIN CREDENTIALS (Generated - Not Public)\n")
+		sb.WriteString("================================================================================\n")
+		sb.WriteString("Email:    admin@nivo.local\n")
+		sb.WriteString(fmt.Sprintf("Password: %s\n", adminPassword))
+		sb.WriteString("Role:     admin\n")
+		sb.WriteString("\n")
+	}
 
 	sb.WriteString("================================================================================\n")
 	sb.WriteString("DEMO USER CREDENTIALS (Public - Documented in README)\n")
 	sb.WriteString("================================================================================\n")
 	for _, user := range users {
-		if user.Email == "admin@nivo.local" || user.Email == "admin@vnykmshr.com" {
+		if user.isAdmin() {
 			continue // Skip admin, already shown above
 		}
 		sb.WriteString(fmt.Sprintf("\n%s\n", user.FullName))
 		sb.WriteString(fmt.Sprintf("  Email:    %s\n", user.Email))
 		sb.WriteString(fmt.Sprintf("  Password: %s\n", user.Password))
-		sb.WriteString(fmt.Sprintf("  Balance:  ₹%.2f\n", float64(user.InitialBalance)/100.0))
+		sb.WriteString(fmt.Sprintf("  Balance:  ₹%.2f\n", float64(user.openingBalance())/100.0))
 	}
 
 	// Write file with restricted permissions
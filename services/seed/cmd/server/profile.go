@@ -0,0 +1,308 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"math/rand"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1mb-dev/nivomoney/shared/validator"
+)
+
+//go:embed data/profiles/*.yaml
+var profilesFS embed.FS
+
+// DefaultProfile is seeded when no profile is given.
+const DefaultProfile = "default"
+
+const (
+	defaultEmailDomain   = "seed.nivo.local"
+	defaultGroupPassword = "demo1234"
+	maxGroupUsers        = 9999 // Generated PAN and phone numbers are unique up to this many per group
+	maxUserGroups        = 26
+)
+
+// Profile declares a dataset to seed: the built-in demo accounts, generated
+// users grouped by tier, days of transfer history between them, and risk
+// rules. Profiles are YAML or JSON; seeding the same profile twice leaves the
+// database as one run would.
+type Profile struct {
+	Name        string         `yaml:"name" json:"name"`
+	Description string         `yaml:"description" json:"description"`
+	RandomSeed  int64          `yaml:"random_seed" json:"random_seed"` // Makes generated data repeatable
+	DemoUsers   bool           `yaml:"demo_users" json:"demo_users"`   // Include the accounts in data/users.yaml
+	DemoData    bool           `yaml:"demo_data" json:"demo_data"`     // Beneficiaries and virtual cards for every user
+	Users       []UserGroup    `yaml:"users" json:"users"`
+	History     HistorySpec    `yaml:"history" json:"history"`
+	RiskRules   []RiskRuleSpec `yaml:"risk_rules" json:"risk_rules"`
+}
+
+// UserGroup generates Count users on one tier, each with the same wallets.
+type UserGroup struct {
+	Prefix      string       `yaml:"prefix" json:"prefix"` // Emails are <prefix>.<n>@<email_domain>
+	Count       int          `yaml:"count" json:"count"`
+	Tier        string       `yaml:"tier" json:"tier"` // Account tier code, e.g. regular or premium
+	Password    string       `yaml:"password" json:"password"`
+	EmailDomain string       `yaml:"email_domain" json:"email_domain"`
+	Wallets     []WalletSpec `yaml:"wallets" json:"wallets"`
+}
+
+// WalletSpec is a wallet and its opening balance.
+type WalletSpec struct {
+	Type     string `yaml:"type" json:"type"`
+	Currency string `yaml:"currency" json:"currency"`
+	Balance  int64  `yaml:"balance" json:"balance"` // Smallest currency unit
+}
+
+// HistorySpec generates completed transfers between seeded wallets over the
+// days before the seed runs.
+type HistorySpec struct {
+	Days            int   `yaml:"days" json:"days"`
+	TransfersPerDay int   `yaml:"transfers_per_day" json:"transfers_per_day"`
+	MinAmount       int64 `yaml:"min_amount" json:"min_amount"`
+	MaxAmount       int64 `yaml:"max_amount" json:"max_amount"`
+}
+
+// RiskRuleSpec is a risk rule, matched to existing rules by name.
+type RiskRuleSpec struct {
+	Name       string         `yaml:"name" json:"name"`
+	Type       string         `yaml:"type" json:"type"` // velocity, daily_limit or threshold
+	Action     string         `yaml:"action" json:"action"`
+	Enabled    *bool          `yaml:"enabled" json:"enabled"` // Defaults to true
+	Parameters map[string]any `yaml:"parameters" json:"parameters"`
+}
+
+var (
+	walletTypes     = map[string]bool{"default": true, "savings": true, "current": true, "fixed": true}
+	riskRuleTypes   = map[string]bool{"velocity": true, "daily_limit": true, "threshold": true}
+	riskRuleActions = map[string]bool{"allow": true, "block": true, "flag": true}
+)
+
+// Validate checks the profile and fills in defaults.
+func (p *Profile) Validate() error {
+	if !isProfileName(p.Name) {
+		return fmt.Errorf("profile name must be 1-40 lowercase letters, digits or hyphens")
+	}
+	if len(p.Users) > maxUserGroups {
+		return fmt.Errorf("at most %d user groups are supported", maxUserGroups)
+	}
+
+	prefixes := make(map[string]bool)
+	for i := range p.Users {
+		g := &p.Users[i]
+		if g.Prefix == "" {
+			return fmt.Errorf("users[%d]: prefix is required", i)
+		}
+		if prefixes[g.Prefix] {
+			return fmt.Errorf("users[%d]: duplicate prefix %q", i, g.Prefix)
+		}
+		prefixes[g.Prefix] = true
+		if g.Count < 1 || g.Count > maxGroupUsers {
+			return fmt.Errorf("users[%d]: count must be between 1 and %d", i, maxGroupUsers)
+		}
+		if g.Password == "" {
+			g.Password = defaultGroupPassword
+		}
+		if g.EmailDomain == "" {
+			g.EmailDomain = defaultEmailDomain
+		}
+		if len(g.Wallets) == 0 {
+			g.Wallets = []WalletSpec{{Type: "default", Currency: "INR"}}
+		}
+
+		seen := make(map[string]bool)
+		for j := range g.Wallets {
+			w := &g.Wallets[j]
+			if w.Type == "" {
+				w.Type = "default"
+			}
+			if w.Currency == "" {
+				w.Currency = "INR"
+			}
+			if !walletTypes[w.Type] {
+				return fmt.Errorf("users[%d].wallets[%d]: unknown wallet type %q", i, j, w.Type)
+			}
+			if len(w.Currency) != 3 {
+				return fmt.Errorf("users[%d].wallets[%d]: invalid currency %q", i, j, w.Currency)
+			}
+			if w.Balance < 0 {
+				return fmt.Errorf("users[%d].wallets[%d]: balance cannot be negative", i, j)
+			}
+			// Opening balances are funded from the INR cash account
+			if w.Balance > 0 && w.Currency != "INR" {
+				return fmt.Errorf("users[%d].wallets[%d]: opening balances are only supported for INR wallets", i, j)
+			}
+			key := w.Type + "/" + w.Currency
+			if seen[key] {
+				return fmt.Errorf("users[%d]: duplicate %s wallet in %s", i, w.Type, w.Currency)
+			}
+			seen[key] = true
+		}
+	}
+
+	h := &p.History
+	if h.Days < 0 || h.TransfersPerDay < 0 {
+		return fmt.Errorf("history days and transfers_per_day cannot be negative")
+	}
+	if h.Days > 0 && h.TransfersPerDay > 0 {
+		if h.MinAmount <= 0 || h.MaxAmount < h.MinAmount {
+			return fmt.Errorf("history needs 0 < min_amount <= max_amount")
+		}
+	}
+
+	names := make(map[string]bool)
+	for i := range p.RiskRules {
+		r := &p.RiskRules[i]
+		if r.Name == "" {
+			return fmt.Errorf("risk_rules[%d]: name is required", i)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("risk_rules[%d]: duplicate name %q", i, r.Name)
+		}
+		names[r.Name] = true
+		if !riskRuleTypes[r.Type] {
+			return fmt.Errorf("risk_rules[%d]: unknown type %q", i, r.Type)
+		}
+		if r.Action == "" {
+			r.Action = "flag"
+		}
+		if !riskRuleActions[r.Action] {
+			return fmt.Errorf("risk_rules[%d]: unknown action %q", i, r.Action)
+		}
+		if r.Parameters == nil {
+			r.Parameters = map[string]any{}
+		}
+	}
+
+	return nil
+}
+
+// isProfileName reports whether name is a valid profile name. Names are
+// used in file names and transaction references.
+func isProfileName(name string) bool {
+	if name == "" || len(name) > 40 {
+		return false
+	}
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// HasHistory reports whether the profile generates transfer history.
+func (p *Profile) HasHistory() bool {
+	return p.History.Days > 0 && p.History.TransfersPerDay > 0
+}
+
+// ParseProfile parses a YAML or JSON profile and validates it.
+func ParseProfile(data []byte) (*Profile, error) {
+	var profile Profile
+	// JSON is a subset of YAML, so one decoder reads both
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, fmt.Errorf("invalid profile: %w", err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid profile %q: %w", profile.Name, err)
+	}
+	return &profile, nil
+}
+
+// LoadProfile loads a profile from a file path, or by name from the profiles
+// built into the seed tool.
+func LoadProfile(nameOrPath string) (*Profile, error) {
+	if nameOrPath == "" {
+		nameOrPath = DefaultProfile
+	}
+
+	if strings.ContainsAny(nameOrPath, `/\`) || strings.HasSuffix(nameOrPath, ".yaml") ||
+		strings.HasSuffix(nameOrPath, ".yml") || strings.HasSuffix(nameOrPath, ".json") {
+		data, err := os.ReadFile(nameOrPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read profile: %w", err)
+		}
+		return ParseProfile(data)
+	}
+
+	data, err := profilesFS.ReadFile(path.Join("data/profiles", nameOrPath+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown profile %q", nameOrPath)
+	}
+	return ParseProfile(data)
+}
+
+// BuiltinProfiles returns the profiles built into the seed tool, by name.
+func BuiltinProfiles() ([]*Profile, error) {
+	entries, err := profilesFS.ReadDir("data/profiles")
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*Profile, 0, len(entries))
+	for _, entry := range entries {
+		profile, err := LoadProfile(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+var (
+	firstNames = []string{"Aarav", "Ananya", "Rohan", "Isha", "Kabir", "Meera", "Vivaan", "Diya", "Aditya", "Saanvi", "Karan", "Pooja", "Nikhil", "Riya", "Siddharth", "Tara"}
+	lastNames  = []string{"Sharma", "Iyer", "Reddy", "Nair", "Gupta", "Mehta", "Das", "Joshi", "Kapoor", "Menon", "Bose", "Rao"}
+	cities     = []Address{
+		{Street: "MG Road", City: "Bengaluru", State: "Karnataka", PIN: "560001", Country: "India"},
+		{Street: "Linking Road", City: "Mumbai", State: "Maharashtra", PIN: "400050", Country: "India"},
+		{Street: "Park Street", City: "Kolkata", State: "West Bengal", PIN: "700016", Country: "India"},
+		{Street: "Anna Salai", City: "Chennai", State: "Tamil Nadu", PIN: "600002", Country: "India"},
+		{Street: "Connaught Place", City: "New Delhi", State: "Delhi", PIN: "110001", Country: "India"},
+		{Street: "FC Road", City: "Pune", State: "Maharashtra", PIN: "411004", Country: "India"},
+	}
+)
+
+// GenerateUsers expands the profile's user groups into seed users. The same
+// profile always generates the same users, so re-runs find them again by
+// email. PAN, Aadhaar and phone numbers are derived from the group and index
+// to stay unique; Aadhaar numbers carry a valid checksum.
+func (p *Profile) GenerateUsers() []SeedUser {
+	//nolint:gosec // Using math/rand for repeatable demo data, not cryptographic security
+	rng := rand.New(rand.NewSource(p.RandomSeed))
+
+	var users []SeedUser
+	for g, group := range p.Users {
+		for n := 1; n <= group.Count; n++ {
+			first := firstNames[rng.Intn(len(firstNames))]
+			last := lastNames[rng.Intn(len(lastNames))]
+			address := cities[rng.Intn(len(cities))]
+			address.Street = fmt.Sprintf("%d, %s", 1+rng.Intn(200), address.Street)
+			dob := fmt.Sprintf("%d-%02d-%02d", 1970+rng.Intn(34), 1+rng.Intn(12), 1+rng.Intn(28))
+
+			users = append(users, SeedUser{
+				ID:          fmt.Sprintf("%s-%d", group.Prefix, n),
+				FullName:    first + " " + last,
+				Email:       fmt.Sprintf("%s.%d@%s", group.Prefix, n, group.EmailDomain),
+				Password:    group.Password,
+				Phone:       fmt.Sprintf("+917%02d%07d", g, n),
+				PAN:         fmt.Sprintf("SEED%c%04dZ", 'A'+g, n),
+				Aadhaar:     validator.AppendVerhoeffCheckDigit(fmt.Sprintf("9%02d%08d", g, n)),
+				DateOfBirth: dob,
+				Address:     address,
+				Tier:        group.Tier,
+				Wallets:     group.Wallets,
+			})
+		}
+	}
+	return users
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinProfiles_AreValid(t *testing.T) {
+	profiles, err := BuiltinProfiles()
+	if err != nil {
+		t.Fatalf("expected built-in profiles to load, got %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range profiles {
+		names[p.Name] = true
+	}
+	if !names[DefaultProfile] || !names["demo"] {
+		t.Errorf("expected default and demo profiles, got %v", names)
+	}
+}
+
+func TestParseProfile_JSONWithDefaults(t *testing.T) {
+	profile, err := ParseProfile([]byte(`{
+		"name": "qa",
+		"users": [{"prefix": "qa", "count": 2, "tier": "premium"}],
+		"risk_rules": [{"name": "QA threshold", "type": "threshold", "parameters": {"max_amount": 100}}]
+	}`))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	group := profile.Users[0]
+	if group.Password != defaultGroupPassword || group.EmailDomain != defaultEmailDomain {
+		t.Errorf("expected default password and domain, got %q %q", group.Password, group.EmailDomain)
+	}
+	if len(group.Wallets) != 1 || group.Wallets[0].Type != "default" || group.Wallets[0].Currency != "INR" {
+		t.Errorf("expected one default INR wallet, got %+v", group.Wallets)
+	}
+	if profile.RiskRules[0].Action != "flag" {
+		t.Errorf("expected rules to flag by default, got %s", profile.RiskRules[0].Action)
+	}
+}
+
+func TestParseProfile_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		wantErr string
+	}{
+		{"unknown field", "name: x\nusres: []", "usres"},
+		{"bad name", "name: ../etc", "profile name"},
+		{"duplicate prefix", "name: x\nusers:\n  - {prefix: a, count: 1}\n  - {prefix: a, count: 1}", "duplicate prefix"},
+		{"too many users", "name: x\nusers:\n  - {prefix: a, count: 10000}", "count"},
+		{"foreign opening balance", "name: x\nusers:\n  - {prefix: a, count: 1, wallets: [{currency: USD, balance: 100}]}", "only supported for INR"},
+		{"duplicate wallet", "name: x\nusers:\n  - {prefix: a, count: 1, wallets: [{type: savings}, {type: savings}]}", "duplicate savings wallet"},
+		{"history without amounts", "name: x\nhistory: {days: 3, transfers_per_day: 2}", "min_amount"},
+		{"unknown rule type", "name: x\nrisk_rules:\n  - {name: r, type: magic}", "unknown type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseProfile([]byte(tt.profile))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadProfile_UnknownName(t *testing.T) {
+	if _, err := LoadProfile("no-such-profile"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestGenerateUsers_RepeatableAndValid(t *testing.T) {
+	profile, err := LoadProfile("demo")
+	if err != nil {
+		t.Fatalf("expected demo profile to load, got %v", err)
+	}
+
+	first := profile.GenerateUsers()
+	second := profile.GenerateUsers()
+	if len(first) != 24 {
+		t.Fatalf("expected 24 generated users, got %d", len(first))
+	}
+
+	// Formats match the identity schema's constraints
+	phone := regexp.MustCompile(`^\+91[6-9][0-9]{9}$`)
+	pan := regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	aadhaar := regexp.MustCompile(`^[2-9][0-9]{11}$`)
+
+	seen := make(map[string]bool)
+	for i, u := range first {
+		if !reflect.DeepEqual(u, second[i]) {
+			t.Fatalf("expected user %d to be the same on every run: %+v vs %+v", i, u, second[i])
+		}
+		if !phone.MatchString(u.Phone) || !pan.MatchString(u.PAN) || !aadhaar.MatchString(u.Aadhaar) {
+			t.Errorf("invalid identity fields for %s: %s %s %s", u.Email, u.Phone, u.PAN, u.Aadhaar)
+		}
+		for _, key := range []string{u.Email, u.Phone, u.PAN, u.Aadhaar} {
+			if seen[key] {
+				t.Errorf("duplicate generated value %s", key)
+			}
+			seen[key] = true
+		}
+	}
+
+	if first[0].Email != "regular.1@seed.nivo.local" || first[0].Tier != "regular" {
+		t.Errorf("unexpected first user: %s on %s", first[0].Email, first[0].Tier)
+	}
+	if last := first[len(first)-1]; last.Tier != "premium" || last.openingBalance() != 70000000 {
+		t.Errorf("expected premium user with ₹7,00,000 across wallets, got %s with %d", last.Tier, last.openingBalance())
+	}
+}
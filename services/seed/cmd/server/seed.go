@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1mb-dev/nivomoney/shared/database"
+)
+
+// SeedSummary reports what a profile seed created or verified.
+type SeedSummary struct {
+	Profile       string `json:"profile"`
+	Cleaned       bool   `json:"cleaned"`
+	Users         int    `json:"users"`
+	Wallets       int    `json:"wallets"`
+	Transfers     int    `json:"transfers"` // History transfers created by this run
+	RiskRules     int    `json:"risk_rules"`
+	AdminPassword string `json:"-"`
+}
+
+// seedProfile seeds the dataset a profile describes, cleaning the database
+// first when clean is set. Without clean, existing records are reused, so
+// re-running a profile only fills in what is missing.
+func seedProfile(ctx context.Context, db *database.DB, profile *Profile, clean bool) (*SeedSummary, error) {
+	log.Printf("[%s] Profile: %s (clean mode: %v)", serviceName, profile.Name, clean)
+
+	// Clean database if requested
+	if clean {
+		if err := cleanDatabase(db); err != nil {
+			return nil, fmt.Errorf("failed to clean database: %w", err)
+		}
+		log.Printf("[%s] Database cleaned successfully", serviceName)
+	}
+
+	var users []SeedUser
+	if profile.DemoUsers {
+		var seedData SeedData
+		if err := yaml.Unmarshal(usersData, &seedData); err != nil {
+			return nil, fmt.Errorf("failed to parse seed data: %w", err)
+		}
+		users = append(users, seedData.Users...)
+	}
+	users = append(users, profile.GenerateUsers()...)
+
+	log.Printf("[%s] Loaded %d users from profile %s", serviceName, len(users), profile.Name)
+
+	// Generate secure admin password (override static YAML value)
+	var adminPassword string
+	for i := range users {
+		if users[i].isAdmin() {
+			if adminPassword == "" {
+				adminPassword = generateSecurePassword(16)
+			}
+			users[i].Password = adminPassword
+			log.Printf("[%s] Generated secure admin password (not using static YAML value)", serviceName)
+		}
+	}
+
+	log.Printf("[%s] ", serviceName)
+
+	// Setup role permissions (ensure 'user' role has required permissions)
+	if err := setupRolePermissions(ctx, db); err != nil {
+		return nil, fmt.Errorf("failed to setup role permissions: %w", err)
+	}
+
+	// Seed users with complete setup
+	wallets, err := seedCompleteUsers(ctx, db, users)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed users: %w", err)
+	}
+
+	// Ensure all wallets have limits (for existing wallets that may not have limits)
+	if err := ensureWalletLimits(ctx, db); err != nil {
+		log.Printf("[%s] Warning: Failed to ensure wallet limits: %v", serviceName, err)
+	}
+
+	rules, err := seedRiskRules(ctx, db, profile.RiskRules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed risk rules: %w", err)
+	}
+
+	transfers, err := seedTransferHistory(ctx, db, profile, wallets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed transfer history: %w", err)
+	}
+
+	// Seed demo data (beneficiaries, virtual cards)
+	if profile.DemoData {
+		if err := seedDemoData(ctx, db); err != nil {
+			log.Printf("[%s] Warning: Failed to seed demo data: %v", serviceName, err)
+		}
+	}
+
+	// Write credentials to .secrets/credentials.txt
+	if err := writeCredentialsFile(users, adminPassword); err != nil {
+		log.Printf("[%s] Warning: Failed to write credentials file: %v", serviceName, err)
+	}
+
+	return &SeedSummary{
+		Profile:       profile.Name,
+		Cleaned:       clean,
+		Users:         len(users),
+		Wallets:       len(wallets),
+		Transfers:     transfers,
+		RiskRules:     rules,
+		AdminPassword: adminPassword,
+	}, nil
+}
+
+// setUserTier moves a user to an account tier and sets their wallets' limits
+// to the tier's transfer limits.
+func setUserTier(ctx context.Context, db *database.DB, userID, tier string) error {
+	result, err := db.ExecContext(ctx, `
+		UPDATE users SET account_tier = $2, updated_at = NOW()
+		WHERE id = $1 AND EXISTS (SELECT 1 FROM account_tiers WHERE code = $2)
+	`, userID, tier)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("account tier %q not found", tier)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		UPDATE wallet_limits wl
+		SET daily_limit = t.daily_transfer_limit,
+		    monthly_limit = t.monthly_transfer_limit,
+		    updated_at = NOW()
+		FROM wallets w, account_tiers t
+		WHERE wl.wallet_id = w.id AND w.user_id = $1 AND t.code = $2
+	`, userID, tier)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[%s]   → Tier set: %s", serviceName, tier)
+	return nil
+}
+
+// seedRiskRules creates the profile's risk rules, updating rules that
+// already exist with the same name.
+func seedRiskRules(ctx context.Context, db *database.DB, rules []RiskRuleSpec) (int, error) {
+	if len(rules) == 0 {
+		return 0, nil
+	}
+
+	log.Printf("[%s] ========== Seeding Risk Rules ==========", serviceName)
+
+	query := `
+		INSERT INTO risk_rules (rule_type, name, parameters, action, enabled)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE
+		SET rule_type = EXCLUDED.rule_type,
+		    parameters = EXCLUDED.parameters,
+		    action = EXCLUDED.action,
+		    enabled = EXCLUDED.enabled,
+		    updated_at = NOW()
+	`
+	for _, rule := range rules {
+		params, err := json.Marshal(rule.Parameters)
+		if err != nil {
+			return 0, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		enabled := rule.Enabled == nil || *rule.Enabled
+
+		if _, err := db.ExecContext(ctx, query, rule.Type, rule.Name, params, rule.Action, enabled); err != nil {
+			return 0, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		log.Printf("[%s]   → Risk rule ready: %s (%s, %s)", serviceName, rule.Name, rule.Type, rule.Action)
+	}
+
+	log.Printf("[%s] ", serviceName)
+	return len(rules), nil
+}
+
+// historyDescriptions are the purposes generated transfers are made for.
+var historyDescriptions = []struct {
+	Description string
+	Category    string
+}{
+	{"Dinner split", "food"},
+	{"Groceries", "food"},
+	{"Cab share", "transport"},
+	{"Electricity bill share", "utilities"},
+	{"Movie tickets", "entertainment"},
+	{"Birthday gift", "shopping"},
+	{"Pharmacy run", "health"},
+	{"Course fee share", "education"},
+	{"Rent share", "transfer"},
+	{"Paying you back", "transfer"},
+}
+
+// historyReferencePrefix marks the transfers generated for a profile.
+func historyReferencePrefix(profile *Profile) string {
+	return "SEED-HIST-" + profile.Name + "-"
+}
+
+// seedTransferHistory records completed transfers between the seeded users'
+// default INR wallets over the profile's history window. History is generated
+// once per profile: a re-run finds the earlier transfers and adds none.
+func seedTransferHistory(ctx context.Context, db *database.DB, profile *Profile, wallets []seededWallet) (int, error) {
+	if !profile.HasHistory() {
+		return 0, nil
+	}
+
+	log.Printf("[%s] ========== Seeding Transfer History ==========", serviceName)
+
+	prefix := historyReferencePrefix(profile)
+	var existing int
+	if err := db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM transactions WHERE reference LIKE $1", prefix+"%").Scan(&existing); err != nil {
+		return 0, err
+	}
+	if existing > 0 {
+		log.Printf("[%s]   → History already seeded (%d transfers), skipping", serviceName, existing)
+		return 0, nil
+	}
+
+	var candidates []seededWallet
+	balances := make(map[string]int64)
+	for _, w := range wallets {
+		if w.Type != "default" || w.Currency != "INR" {
+			continue
+		}
+		var balance int64
+		if err := db.QueryRowContext(ctx, "SELECT available_balance FROM wallets WHERE id = $1", w.WalletID).Scan(&balance); err != nil {
+			return 0, err
+		}
+		candidates = append(candidates, w)
+		balances[w.WalletID] = balance
+	}
+	if len(candidates) < 2 {
+		log.Printf("[%s]   → Fewer than two INR wallets, no history to generate", serviceName)
+		return 0, nil
+	}
+
+	// Offset the seed so history doesn't repeat the user generator's sequence
+	//nolint:gosec // Using math/rand for repeatable demo data, not cryptographic security
+	rng := rand.New(rand.NewSource(profile.RandomSeed + 1))
+	spec := profile.History
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	created := 0
+	for day := spec.Days; day >= 1; day-- {
+		date := today.AddDate(0, 0, -day)
+		for n := 0; n < spec.TransfersPerDay; n++ {
+			src := candidates[rng.Intn(len(candidates))]
+			dst := candidates[rng.Intn(len(candidates))]
+			amount := spec.MinAmount + rng.Int63n(spec.MaxAmount-spec.MinAmount+1)
+			purpose := historyDescriptions[rng.Intn(len(historyDescriptions))]
+			at := date.Add(8*time.Hour + time.Duration(rng.Intn(14*60))*time.Minute)
+
+			// Round to whole rupees, as people do
+			if rounded := amount / 100 * 100; rounded >= spec.MinAmount {
+				amount = rounded
+			}
+			if src.UserID == dst.UserID || balances[src.WalletID] < amount {
+				continue
+			}
+
+			reference := fmt.Sprintf("%s%03d-%02d", prefix, day, n)
+			if err := recordHistoricTransfer(ctx, db, src, dst, amount, purpose.Description, purpose.Category, reference, at); err != nil {
+				return created, fmt.Errorf("transfer %s: %w", reference, err)
+			}
+			balances[src.WalletID] -= amount
+			balances[dst.WalletID] += amount
+			created++
+		}
+	}
+
+	log.Printf("[%s]   → %d transfers over %d days", serviceName, created, spec.Days)
+	log.Printf("[%s] ", serviceName)
+	return created, nil
+}
+
+// recordHistoricTransfer records a completed transfer at a past time with its
+// posted journal entry, moving the wallet and ledger balances.
+func recordHistoricTransfer(ctx context.Context, db *database.DB, src, dst seededWallet, amount int64, description, category, reference string, at time.Time) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var transactionID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO transactions (
+			type, status, source_wallet_id, destination_wallet_id, amount, currency,
+			description, reference, category, processed_at, completed_at, created_at, updated_at
+		)
+		VALUES ('transfer', 'completed', $1, $2, $3, 'INR', $4, $5, $6, $7, $7, $7, $7)
+		RETURNING id
+	`, src.WalletID, dst.WalletID, amount, description, reference, category, at).Scan(&transactionID)
+	if err != nil {
+		return err
+	}
+
+	var journalEntryID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO journal_entries (entry_number, type, status, description, reference_type, reference_id, posted_at, posted_by, created_at, updated_at)
+		VALUES ($1, 'standard', 'posted', $2, 'transaction', $3, $4, $5::uuid, $4, $4)
+		RETURNING id
+	`, "SEED-"+transactionID, description, transactionID, at, src.UserID).Scan(&journalEntryID)
+	if err != nil {
+		return err
+	}
+
+	// Debit the sender's wallet account (liability decreases), credit the receiver's
+	lineQuery := `
+		INSERT INTO ledger_lines (entry_id, account_id, debit_amount, credit_amount, description, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.ExecContext(ctx, lineQuery, journalEntryID, src.LedgerAccountID, amount, 0, description, at); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, lineQuery, journalEntryID, dst.LedgerAccountID, 0, amount, description, at); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET balance = balance - $1, debit_total = debit_total + $1, updated_at = NOW()
+		WHERE id = $2
+	`, amount, src.LedgerAccountID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE accounts
+		SET balance = balance + $1, credit_total = credit_total + $1, updated_at = NOW()
+		WHERE id = $2
+	`, amount, dst.LedgerAccountID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET balance = balance - $1, available_balance = available_balance - $1, updated_at = NOW()
+		WHERE id = $2
+	`, amount, src.WalletID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET balance = balance + $1, available_balance = available_balance + $1, updated_at = NOW()
+		WHERE id = $2
+	`, amount, dst.WalletID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE transactions SET ledger_entry_id = $1 WHERE id = $2", journalEntryID, transactionID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+	"github.com/1mb-dev/nivomoney/shared/server"
+)
+
+// resetTimeout bounds one reset-to-profile run.
+const resetTimeout = 10 * time.Minute
+
+// seedAPI serves the reset-to-profile endpoint for demo environments.
+type seedAPI struct {
+	db    *database.DB
+	token string
+	mu    sync.Mutex // One reset at a time
+}
+
+// ResetRequest selects the built-in profile to reset to.
+type ResetRequest struct {
+	Profile string `json:"profile"`
+}
+
+// ProfileInfo describes a built-in profile.
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// serve runs the seed API until the process stops. It refuses to start
+// without SEED_API_TOKEN, since a reset wipes user data.
+func serve(db *database.DB) error {
+	token := server.GetEnv("SEED_API_TOKEN", "")
+	if token == "" {
+		return errors.Validation("SEED_API_TOKEN must be set to serve the seed API")
+	}
+
+	api := &seedAPI{db: db, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, map[string]string{"status": "ok"})
+	})
+	mux.Handle("GET /v1/profiles", api.authorize(http.HandlerFunc(api.listProfiles)))
+	mux.Handle("POST /v1/reset", api.authorize(http.HandlerFunc(api.reset)))
+
+	addr := ":" + server.GetEnv("SEED_API_PORT", "8089")
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      resetTimeout + time.Minute,
+	}
+
+	log.Printf("[%s] Seed API listening on %s", serviceName, addr)
+	return srv.ListenAndServe()
+}
+
+// authorize requires the API token as a bearer token.
+func (a *seedAPI) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			response.Error(w, errors.Unauthorized("invalid seed API token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listProfiles handles GET /v1/profiles
+func (a *seedAPI) listProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := BuiltinProfiles()
+	if err != nil {
+		response.Error(w, errors.Internal("failed to load profiles"))
+		return
+	}
+
+	infos := make([]ProfileInfo, 0, len(profiles))
+	for _, p := range profiles {
+		infos = append(infos, ProfileInfo{Name: p.Name, Description: p.Description})
+	}
+	response.OK(w, infos)
+}
+
+// reset handles POST /v1/reset: it cleans the database and seeds a built-in
+// profile. Only built-in profiles can be chosen, never files on the server.
+func (a *seedAPI) reset(w http.ResponseWriter, r *http.Request) {
+	var req ResetRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+			response.Error(w, errors.BadRequest("invalid request body"))
+			return
+		}
+	}
+	if req.Profile == "" {
+		req.Profile = DefaultProfile
+	}
+	if !isProfileName(req.Profile) {
+		response.Error(w, errors.Validation("profile must be the name of a built-in profile"))
+		return
+	}
+
+	profile, err := LoadProfile(req.Profile)
+	if err != nil {
+		response.Error(w, errors.NotFoundWithID("profile", req.Profile))
+		return
+	}
+
+	if !a.mu.TryLock() {
+		response.Error(w, errors.Conflict("a reset is already running"))
+		return
+	}
+	defer a.mu.Unlock()
+
+	// A reset that has started finishes even if the caller disconnects
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), resetTimeout)
+	defer cancel()
+
+	log.Printf("[%s] Resetting to profile %s", serviceName, profile.Name)
+	summary, err := seedProfile(ctx, a.db, profile, true)
+	if err != nil {
+		log.Printf("[%s] Reset to profile %s failed: %v", serviceName, profile.Name, err)
+		response.Error(w, errors.Internal("reset failed"))
+		return
+	}
+
+	response.OK(w, summary)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -17,6 +18,8 @@ import (
 	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/database"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
+	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -43,12 +46,28 @@ func main() {
 
 	log.Printf("[%s] Connected to database successfully", serviceName)
 
+	// Run migrations (metrics history)
+	migrationsDir := getEnvOrDefault("MIGRATIONS_DIR", "./migrations")
+	if _, err := os.Stat(migrationsDir); err == nil {
+		if err := database.NewMigrator(db.DB, migrationsDir).Up(); err != nil {
+			log.Fatalf("[%s] Failed to run migrations: %v", serviceName, err)
+		}
+		log.Printf("[%s] Database migrations completed", serviceName)
+	} else {
+		log.Printf("[%s] Migrations directory %s not found, skipping migrations", serviceName, migrationsDir)
+	}
+
+	// Internal clients sign their requests as the simulation service
+	if serviceTokenSecret := config.Secret("SERVICE_TOKEN_SECRET"); serviceTokenSecret != "" {
+		serviceauth.SetDefaultSigner(serviceauth.NewSigner(serviceName, serviceTokenSecret, serviceauth.DefaultTTL))
+	}
+
 	// Get Gateway URL and admin token
 	gatewayURL := getEnvOrDefault("GATEWAY_URL", "http://gateway:8000")
 	adminToken := os.Getenv("ADMIN_TOKEN")
 	if adminToken == "" {
 		// Generate a service token using JWT_SECRET
-		jwtSecret := os.Getenv("JWT_SECRET")
+		jwtSecret := config.Secret("JWT_SECRET")
 		if jwtSecret == "" {
 			log.Fatalf("[%s] Neither ADMIN_TOKEN nor JWT_SECRET set - cannot authenticate", serviceName)
 		}
@@ -87,6 +106,32 @@ func main() {
 	// Initialize handler with config and metrics
 	simulationHandler := handler.NewSimulationHandler(simulationEngine, simulationConfig, simulationMetrics)
 
+	// Persist metrics samples so trends survive restarts
+	metricsRecorder := service.NewMetricsRecorder(db.DB, simulationMetrics, simulationEngine.IsRunning)
+	simulationHandler.SetMetricsRecorder(metricsRecorder)
+
+	// Anomaly injection corrupts wallet and transaction data on purpose - never allow
+	// it in production. Those services only enable the endpoints it uses outside
+	// production too.
+	anomalyClient := service.NewAnomalyClient(
+		getEnvOrDefault("WALLET_SERVICE_URL", "http://wallet-service:8083"),
+		getEnvOrDefault("TRANSACTION_SERVICE_URL", "http://transaction-service:8084"),
+		config.Secret("INTERNAL_SERVICE_SECRET"),
+	)
+	anomalyInjector := service.NewAnomalyInjector(anomalyClient, gatewayClient)
+	anomalyHandler := handler.NewAnomalyHandler(anomalyInjector, !cfg.IsProduction())
+
+	// Chaos injection is applied by the gateway's internal chaos endpoints
+	chaosClient := service.NewChaosClient(gatewayURL, config.Secret("INTERNAL_SERVICE_SECRET"))
+	chaosHandler := handler.NewChaosHandler(chaosClient, !cfg.IsProduction())
+
+	// Load runs drive a target TPS through the gateway
+	loadRunner := service.NewLoadRunner(db.DB, gatewayClient)
+	loadHandler := handler.NewLoadHandler(loadRunner, !cfg.IsProduction())
+	if cfg.IsProduction() {
+		log.Printf("[%s] Anomaly injection, chaos injection and load testing disabled (production)", serviceName)
+	}
+
 	// Setup routes
 	mux := http.NewServeMux()
 
@@ -109,8 +154,33 @@ func main() {
 
 	// Metrics endpoints (JSON)
 	mux.HandleFunc("GET /api/v1/simulation/metrics", simulationHandler.GetMetrics)
+	mux.HandleFunc("GET /api/v1/simulation/metrics/history", simulationHandler.GetMetricsHistory)
 	mux.HandleFunc("POST /api/v1/simulation/metrics/reset", simulationHandler.ResetMetrics)
 
+	// Money conservation reports, one per finished run
+	mux.HandleFunc("GET /api/v1/simulation/conservation", simulationHandler.ListConservationReports)
+	mux.HandleFunc("GET /api/v1/simulation/conservation/{runId}", simulationHandler.GetConservationReport)
+
+	// Run manifests: seed, config and operations, for replaying a run
+	mux.HandleFunc("GET /api/v1/simulation/manifest", simulationHandler.GetCurrentManifest)
+	mux.HandleFunc("GET /api/v1/simulation/manifest/{runId}", simulationHandler.GetRunManifest)
+
+	// Anomaly injection endpoints (non-production only)
+	mux.HandleFunc("POST /api/v1/simulation/anomalies", anomalyHandler.InjectAnomaly)
+	mux.HandleFunc("GET /api/v1/simulation/anomalies", anomalyHandler.ListAnomalies)
+	mux.HandleFunc("GET /api/v1/simulation/anomalies/{id}", anomalyHandler.GetAnomaly)
+
+	// Chaos injection endpoints (non-production only)
+	mux.HandleFunc("GET /api/v1/simulation/chaos", chaosHandler.GetChaos)
+	mux.HandleFunc("POST /api/v1/simulation/chaos", chaosHandler.SetChaos)
+	mux.HandleFunc("DELETE /api/v1/simulation/chaos", chaosHandler.ClearChaos)
+
+	// Load-test endpoints (non-production only)
+	mux.HandleFunc("POST /api/v1/simulation/load", loadHandler.StartLoad)
+	mux.HandleFunc("DELETE /api/v1/simulation/load", loadHandler.StopLoad)
+	mux.HandleFunc("GET /api/v1/simulation/load", loadHandler.ListLoadReports)
+	mux.HandleFunc("GET /api/v1/simulation/load/{id}", loadHandler.GetLoadReport)
+
 	// Prometheus metrics endpoint
 	// Updates simulation-specific gauges before returning standard Prometheus format
 	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
@@ -124,14 +194,42 @@ func main() {
 	// Create a cancellable context for the simulation engine
 	simCtx, simCancel := context.WithCancel(context.Background())
 
-	// Auto-start simulation if enabled
+	// On shutdown the engine stops scheduling cycles and the cycle in flight
+	// finishes; its context is only cancelled once the deadline expires
+	lifecycle := server.NewLifecycle(nil)
+	lifecycle.Register("simulation-engine", server.StopFunc(func(ctx context.Context) error {
+		defer simCancel()
+		simulationEngine.Stop()
+		return simulationEngine.Wait(ctx)
+	}))
+	metricsRecorder.Start(simCtx)
+	lifecycle.Register("metrics-recorder", server.StopFunc(func(ctx context.Context) error {
+		metricsRecorder.Stop()
+		return metricsRecorder.Wait(ctx)
+	}))
+	lifecycle.Register("load-runner", server.StopFunc(func(ctx context.Context) error {
+		loadRunner.Stop()
+		return loadRunner.Wait(ctx)
+	}))
+
+	// Auto-start simulation if enabled; SIMULATION_SEED pins its seed for reproducible runs
 	autoStart := getEnvOrDefault("AUTO_START_SIMULATION", "true")
 	if autoStart == "true" {
-		log.Printf("[%s] Auto-starting simulation...", serviceName)
+		seed := service.NewSeed()
+		if raw := os.Getenv("SIMULATION_SEED"); raw != "" {
+			seed, err = strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				log.Fatalf("[%s] Invalid SIMULATION_SEED %q: %v", serviceName, raw, err)
+			}
+		}
+		log.Printf("[%s] Auto-starting simulation (seed: %d)...", serviceName, seed)
 		go func() {
 			// Wait a bit for services to be ready
-			time.Sleep(10 * time.Second)
-			simulationEngine.Start(simCtx)
+			select {
+			case <-time.After(10 * time.Second):
+				simulationEngine.Start(simCtx, seed)
+			case <-simCtx.Done():
+			}
 		}()
 	}
 
@@ -164,12 +262,8 @@ func main() {
 	<-quit
 	log.Printf("[%s] Shutting down server...", serviceName)
 
-	// Stop simulation - cancel context and call Stop()
-	simCancel()
-	simulationEngine.Stop()
-
 	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), server.ShutdownTimeout)
 	defer cancel()
 
 	// Shutdown server
@@ -177,6 +271,11 @@ func main() {
 		log.Printf("[%s] Server forced to shutdown: %v", serviceName, err)
 	}
 
+	// Drain the simulation engine
+	if err := lifecycle.Shutdown(ctx); err != nil {
+		log.Printf("[%s] Simulation engine forced to stop: %v", serviceName, err)
+	}
+
 	log.Printf("[%s] Server stopped gracefully", serviceName)
 }
 
@@ -71,6 +71,13 @@ func (v *AutoVerifier) IsSimulatedUser(userID string) bool {
 	return v.simulatedUser[userID]
 }
 
+// Reseed restarts the verification jitter from seed.
+func (v *AutoVerifier) Reseed(seed int64) {
+	v.rngMu.Lock()
+	defer v.rngMu.Unlock()
+	v.rng = rand.New(rand.NewSource(seed))
+}
+
 // randFloat64 returns a random float64 in [0.0, 1.0) with thread safety.
 func (v *AutoVerifier) randFloat64() float64 {
 	v.rngMu.Lock()
@@ -188,8 +195,8 @@ func (v *AutoVerifier) ProcessPendingVerifications(ctx context.Context) error {
 	return nil
 }
 
-// RunAutoVerificationLoop starts a background loop to process verifications.
-func (v *AutoVerifier) RunAutoVerificationLoop(ctx context.Context) {
+// RunAutoVerificationLoop processes verifications until ctx is cancelled or stop is closed.
+func (v *AutoVerifier) RunAutoVerificationLoop(ctx context.Context, stop <-chan struct{}) {
 	ticker := time.NewTicker(5 * time.Second) // Check every 5 seconds
 	defer ticker.Stop()
 
@@ -200,6 +207,9 @@ func (v *AutoVerifier) RunAutoVerificationLoop(ctx context.Context) {
 		case <-ctx.Done():
 			log.Printf("[simulation] Auto-verification loop stopped")
 			return
+		case <-stop:
+			log.Printf("[simulation] Auto-verification loop stopped")
+			return
 		case <-ticker.C:
 			if err := v.ProcessPendingVerifications(ctx); err != nil {
 				log.Printf("[simulation] Error processing verifications: %v", err)
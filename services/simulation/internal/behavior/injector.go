@@ -26,6 +26,14 @@ func NewBehaviorInjector(cfg *config.SimulationConfig) *BehaviorInjector {
 	}
 }
 
+// Reseed restarts the injector's delays and failures from seed, so a run
+// started with the same seed injects them in the same order.
+func (b *BehaviorInjector) Reseed(seed int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rng = rand.New(rand.NewSource(seed))
+}
+
 // randIntn returns a random int in [0, n) with thread safety.
 func (b *BehaviorInjector) randIntn(n int) int {
 	b.mu.Lock()
@@ -32,6 +32,12 @@ type SimulationConfig struct {
 
 	// Persona activity configuration.
 	Personas PersonaConfig `json:"personas"`
+
+	// Churn configuration for simulated users.
+	Churn ChurnConfig `json:"churn"`
+
+	// Assertions configuration for end-to-end checks after operations.
+	Assertions AssertionConfig `json:"assertions"`
 }
 
 // DelayConfig configures operation delays in milliseconds.
@@ -77,6 +83,36 @@ type PersonaConfig struct {
 	TransactionsPerHour int `json:"transactions_per_hour"`
 }
 
+// ChurnConfig configures how simulated users leave and return. Rates are the
+// daily probability (0.0-1.0) of each change for an eligible user.
+type ChurnConfig struct {
+	// Enabled controls whether churn is simulated.
+	Enabled bool `json:"enabled"`
+	// DormancyRate is the rate at which active users stop transacting.
+	DormancyRate float64 `json:"dormancy_rate"`
+	// WalletClosureRate is the rate at which active users close their wallet.
+	WalletClosureRate float64 `json:"wallet_closure_rate"`
+	// SuspensionRate is the rate at which active users are suspended.
+	SuspensionRate float64 `json:"suspension_rate"`
+	// ReactivationRate is the rate at which dormant users become active again.
+	ReactivationRate float64 `json:"reactivation_rate"`
+	// DayMinutes is the length of a simulated day, so rates can play out
+	// faster than real time.
+	DayMinutes int `json:"day_minutes"`
+}
+
+// AssertionConfig configures the checks run against the platform after
+// simulated operations, such as balances moving by the amount transferred.
+type AssertionConfig struct {
+	// Enabled controls whether operations are checked.
+	Enabled bool `json:"enabled"`
+	// SampleRate is the share of operations checked (0.0-1.0).
+	SampleRate float64 `json:"sample_rate"`
+	// SettleMs is how long a failing check is retried while the platform
+	// catches up, e.g. on notifications sent asynchronously.
+	SettleMs int `json:"settle_ms"`
+}
+
 // NewDefaultConfig returns realistic mode configuration.
 func NewDefaultConfig() *SimulationConfig {
 	return &SimulationConfig{
@@ -103,6 +139,19 @@ func NewDefaultConfig() *SimulationConfig {
 			IntervalSeconds:     60,
 			TransactionsPerHour: 10,
 		},
+		Churn: ChurnConfig{
+			Enabled:           true,
+			DormancyRate:      0.05,  // 5%
+			WalletClosureRate: 0.01,  // 1%
+			SuspensionRate:    0.005, // 0.5%
+			ReactivationRate:  0.10,  // 10%
+			DayMinutes:        24 * 60,
+		},
+		Assertions: AssertionConfig{
+			Enabled:    false,
+			SampleRate: 0.25,
+			SettleMs:   5000,
+		},
 	}
 }
 
@@ -132,6 +181,19 @@ func NewDemoConfig() *SimulationConfig {
 			IntervalSeconds:     30,
 			TransactionsPerHour: 20,
 		},
+		Churn: ChurnConfig{
+			Enabled:           true,
+			DormancyRate:      0.05,
+			WalletClosureRate: 0.01,
+			SuspensionRate:    0.005,
+			ReactivationRate:  0.10,
+			DayMinutes:        60, // An hour per simulated day
+		},
+		Assertions: AssertionConfig{
+			Enabled:    false,
+			SampleRate: 1.0,
+			SettleMs:   2000,
+		},
 	}
 }
 
@@ -142,6 +204,8 @@ type ConfigView struct {
 	Failures         FailureConfig          `json:"failures"`
 	AutoVerification AutoVerificationConfig `json:"auto_verification"`
 	Personas         PersonaConfig          `json:"personas"`
+	Churn            ChurnConfig            `json:"churn"`
+	Assertions       AssertionConfig        `json:"assertions"`
 }
 
 // GetView returns a JSON-safe view of the current configuration.
@@ -155,6 +219,8 @@ func (c *SimulationConfig) GetView() ConfigView {
 		Failures:         c.Failures,
 		AutoVerification: c.AutoVerification,
 		Personas:         c.Personas,
+		Churn:            c.Churn,
+		Assertions:       c.Assertions,
 	}
 }
 
@@ -170,9 +236,26 @@ func (c *SimulationConfig) Get() SimulationConfig {
 		Failures:         c.Failures,
 		AutoVerification: c.AutoVerification,
 		Personas:         c.Personas,
+		Churn:            c.Churn,
+		Assertions:       c.Assertions,
 	}
 }
 
+// Apply replaces the whole configuration with view, e.g. the configuration
+// recorded in a run manifest.
+func (c *SimulationConfig) Apply(view ConfigView) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Mode = view.Mode
+	c.Delays = view.Delays
+	c.Failures = view.Failures
+	c.AutoVerification = view.AutoVerification
+	c.Personas = view.Personas
+	c.Churn = view.Churn
+	c.Assertions = view.Assertions
+}
+
 // Update applies changes to the configuration in a thread-safe manner.
 func (c *SimulationConfig) Update(update func(*SimulationConfig)) {
 	c.mu.Lock()
@@ -193,6 +276,8 @@ func (c *SimulationConfig) SetMode(mode SimulationMode) {
 		c.Failures = demo.Failures
 		c.AutoVerification = demo.AutoVerification
 		c.Personas = demo.Personas
+		c.Churn = demo.Churn
+		c.Assertions = demo.Assertions
 	} else {
 		realistic := NewDefaultConfig()
 		c.Mode = realistic.Mode
@@ -200,6 +285,8 @@ func (c *SimulationConfig) SetMode(mode SimulationMode) {
 		c.Failures = realistic.Failures
 		c.AutoVerification = realistic.AutoVerification
 		c.Personas = realistic.Personas
+		c.Churn = realistic.Churn
+		c.Assertions = realistic.Assertions
 	}
 }
 
@@ -299,3 +386,21 @@ func (c *SimulationConfig) GetAutoVerificationDelayMs() int {
 	defer c.mu.RUnlock()
 	return c.AutoVerification.DelayMs
 }
+
+// Churn getters.
+
+// GetChurn returns the churn configuration.
+func (c *SimulationConfig) GetChurn() ChurnConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Churn
+}
+
+// Assertion getters.
+
+// GetAssertions returns the assertion configuration.
+func (c *SimulationConfig) GetAssertions() AssertionConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Assertions
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// AnomalyHandler handles HTTP requests for anomaly injection
+type AnomalyHandler struct {
+	injector *service.AnomalyInjector
+	enabled  bool
+}
+
+// NewAnomalyHandler creates a new anomaly handler.
+// When enabled is false (production), every endpoint responds with 403.
+func NewAnomalyHandler(injector *service.AnomalyInjector, enabled bool) *AnomalyHandler {
+	return &AnomalyHandler{
+		injector: injector,
+		enabled:  enabled,
+	}
+}
+
+// InjectAnomalyRequest represents an anomaly injection request.
+type InjectAnomalyRequest struct {
+	Type       string `json:"type"`
+	SLASeconds int    `json:"sla_seconds,omitempty"`
+	Amount     int64  `json:"amount,omitempty"`
+	Rollback   *bool  `json:"rollback,omitempty"`
+}
+
+// InjectAnomaly handles POST /api/v1/simulation/anomalies
+// Corrupts data deliberately and verifies detection asynchronously.
+func (h *AnomalyHandler) InjectAnomaly(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("anomaly injection is disabled in production"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req InjectAnomalyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	anomalyType := service.AnomalyType(req.Type)
+	if anomalyType != service.AnomalyBalanceMismatch && anomalyType != service.AnomalyStuckPending {
+		response.Error(w, errors.BadRequest("type must be 'balance_mismatch' or 'stuck_pending'"))
+		return
+	}
+	if req.SLASeconds < 0 {
+		response.Error(w, errors.BadRequest("sla_seconds must be non-negative"))
+		return
+	}
+	if req.Amount < 0 {
+		response.Error(w, errors.BadRequest("amount must be non-negative"))
+		return
+	}
+
+	// Roll back by default so a verification run leaves no lasting damage
+	rollback := true
+	if req.Rollback != nil {
+		rollback = *req.Rollback
+	}
+
+	report, injectErr := h.injector.Inject(r.Context(), service.AnomalyRequest{
+		Type:     anomalyType,
+		SLA:      time.Duration(req.SLASeconds) * time.Second,
+		Amount:   req.Amount,
+		Rollback: rollback,
+	})
+	if injectErr != nil {
+		response.Error(w, errors.Internal(injectErr.Error()))
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, report)
+}
+
+// ListAnomalies handles GET /api/v1/simulation/anomalies
+// Returns all anomaly reports, newest first.
+func (h *AnomalyHandler) ListAnomalies(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("anomaly injection is disabled in production"))
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"anomalies": h.injector.ListReports(),
+	})
+}
+
+// GetAnomaly handles GET /api/v1/simulation/anomalies/{id}
+// Returns a single anomaly report.
+func (h *AnomalyHandler) GetAnomaly(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("anomaly injection is disabled in production"))
+		return
+	}
+
+	id := r.PathValue("id")
+	report, ok := h.injector.GetReport(id)
+	if !ok {
+		response.Error(w, errors.NotFoundWithID("anomaly", id))
+		return
+	}
+
+	response.OK(w, report)
+}
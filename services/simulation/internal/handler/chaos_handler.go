@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ChaosHandler handles HTTP requests for platform chaos injection
+type ChaosHandler struct {
+	client  *service.ChaosClient
+	enabled bool
+}
+
+// NewChaosHandler creates a new chaos handler.
+// When enabled is false (production), every endpoint responds with 403.
+func NewChaosHandler(client *service.ChaosClient, enabled bool) *ChaosHandler {
+	return &ChaosHandler{
+		client:  client,
+		enabled: enabled,
+	}
+}
+
+// GetChaos handles GET /api/v1/simulation/chaos
+// Returns the chaos configuration active on the gateway.
+func (h *ChaosHandler) GetChaos(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("chaos injection is disabled in production"))
+		return
+	}
+
+	status, err := h.client.Get(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, status)
+}
+
+// SetChaos handles POST /api/v1/simulation/chaos
+// Makes downstream calls time out, fail or slow down, and delays SSE events.
+func (h *ChaosHandler) SetChaos(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("chaos injection is disabled in production"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req service.ChaosSettings
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	if len(req.Rules) == 0 && req.SSEDelayMs == 0 {
+		response.Error(w, errors.BadRequest("at least one rule or sse_delay_ms is required"))
+		return
+	}
+	for i, rule := range req.Rules {
+		if rule.Fault != service.ChaosFaultTimeout && rule.Fault != service.ChaosFaultError && rule.Fault != service.ChaosFaultLatency {
+			response.Error(w, errors.BadRequest(fmt.Sprintf("rules[%d]: fault must be 'timeout', 'error' or 'latency'", i)))
+			return
+		}
+		if rule.Probability <= 0 || rule.Probability > 1.0 {
+			response.Error(w, errors.BadRequest(fmt.Sprintf("rules[%d]: probability must be in (0, 1]", i)))
+			return
+		}
+	}
+	if req.DurationSeconds < 0 {
+		response.Error(w, errors.BadRequest("duration_seconds must be non-negative"))
+		return
+	}
+
+	status, setErr := h.client.Set(r.Context(), req)
+	if setErr != nil {
+		response.Error(w, setErr)
+		return
+	}
+
+	response.OK(w, status)
+}
+
+// ClearChaos handles DELETE /api/v1/simulation/chaos
+// Stops all chaos injection immediately.
+func (h *ChaosHandler) ClearChaos(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("chaos injection is disabled in production"))
+		return
+	}
+
+	status, err := h.client.Clear(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, status)
+}
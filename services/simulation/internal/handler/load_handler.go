@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// LoadHandler handles HTTP requests for load-test runs
+type LoadHandler struct {
+	runner  *service.LoadRunner
+	enabled bool
+}
+
+// NewLoadHandler creates a new load handler.
+// When enabled is false (production), every endpoint responds with 403.
+func NewLoadHandler(runner *service.LoadRunner, enabled bool) *LoadHandler {
+	return &LoadHandler{
+		runner:  runner,
+		enabled: enabled,
+	}
+}
+
+// StartLoadRequest represents a load run request. Omitted fields take the
+// defaults of service.DefaultLoadProfile.
+type StartLoadRequest struct {
+	TargetTPS       float64  `json:"target_tps"`
+	WarmupSeconds   *int     `json:"warmup_seconds,omitempty"`
+	SteadySeconds   *int     `json:"steady_seconds,omitempty"`
+	SpikeSeconds    *int     `json:"spike_seconds,omitempty"`
+	SpikeMultiplier *float64 `json:"spike_multiplier,omitempty"`
+	Workers         *int     `json:"workers,omitempty"`
+	TransferPercent *int     `json:"transfer_percent,omitempty"`
+}
+
+// StartLoad handles POST /api/v1/simulation/load
+// Starts a load run against the gateway.
+func (h *LoadHandler) StartLoad(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("load testing is disabled in production"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req StartLoadRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+
+	profile := service.DefaultLoadProfile(req.TargetTPS)
+	if req.WarmupSeconds != nil {
+		profile.WarmupSeconds = *req.WarmupSeconds
+	}
+	if req.SteadySeconds != nil {
+		profile.SteadySeconds = *req.SteadySeconds
+	}
+	if req.SpikeSeconds != nil {
+		profile.SpikeSeconds = *req.SpikeSeconds
+	}
+	if req.SpikeMultiplier != nil {
+		profile.SpikeMultiplier = *req.SpikeMultiplier
+	}
+	if req.Workers != nil {
+		profile.Workers = *req.Workers
+	}
+	if req.TransferPercent != nil {
+		profile.TransferPercent = *req.TransferPercent
+	}
+	if err := profile.Validate(); err != nil {
+		response.Error(w, errors.BadRequest(err.Error()))
+		return
+	}
+
+	report, startErr := h.runner.Start(r.Context(), profile)
+	if stderrors.Is(startErr, service.ErrLoadRunning) {
+		response.Error(w, errors.Conflict(startErr.Error()))
+		return
+	}
+	if startErr != nil {
+		response.Error(w, errors.Internal(startErr.Error()))
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, report)
+}
+
+// StopLoad handles DELETE /api/v1/simulation/load
+// Stops the active load run; its report is kept with status "stopped".
+func (h *LoadHandler) StopLoad(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("load testing is disabled in production"))
+		return
+	}
+
+	if !h.runner.Stop() {
+		response.Error(w, errors.Conflict("no load run in progress"))
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"message": "load run stopping",
+	})
+}
+
+// ListLoadReports handles GET /api/v1/simulation/load
+// Returns the active run, if any, and finished runs, newest first.
+func (h *LoadHandler) ListLoadReports(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("load testing is disabled in production"))
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"reports": h.runner.Reports(),
+	})
+}
+
+// GetLoadReport handles GET /api/v1/simulation/load/{id}
+// Returns the report of one load run.
+func (h *LoadHandler) GetLoadReport(w http.ResponseWriter, r *http.Request) {
+	if !h.enabled {
+		response.Error(w, errors.Forbidden("load testing is disabled in production"))
+		return
+	}
+
+	id := r.PathValue("id")
+	report, ok := h.runner.Report(id)
+	if !ok {
+		response.Error(w, errors.NotFoundWithID("load run", id))
+		return
+	}
+
+	response.OK(w, report)
+}
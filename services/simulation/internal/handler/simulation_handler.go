@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/simulation/internal/config"
 	"github.com/1mb-dev/nivomoney/services/simulation/internal/metrics"
@@ -18,6 +21,7 @@ type SimulationHandler struct {
 	engine  *service.SimulationEngine
 	config  *config.SimulationConfig
 	metrics *metrics.SimulationMetrics
+	history *service.MetricsRecorder
 }
 
 // NewSimulationHandler creates a new simulation handler
@@ -33,6 +37,11 @@ func NewSimulationHandler(
 	}
 }
 
+// SetMetricsRecorder enables the persisted metrics history endpoint.
+func (h *SimulationHandler) SetMetricsRecorder(recorder *service.MetricsRecorder) {
+	h.history = recorder
+}
+
 // StatusResponse represents the status response
 type StatusResponse struct {
 	Running bool   `json:"running"`
@@ -54,6 +63,13 @@ func (h *SimulationHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, status)
 }
 
+// StartRequest represents an optional simulation start request. Replaying a
+// run means starting with the seed and config from its manifest.
+type StartRequest struct {
+	Seed   *int64             `json:"seed,omitempty"`
+	Config *config.ConfigView `json:"config,omitempty"`
+}
+
 // StartSimulation starts the simulation engine
 func (h *SimulationHandler) StartSimulation(w http.ResponseWriter, r *http.Request) {
 	if h.engine.IsRunning() {
@@ -61,16 +77,78 @@ func (h *SimulationHandler) StartSimulation(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req StartRequest
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			response.Error(w, errors.BadRequest("invalid request body"))
+			return
+		}
+	}
+
+	if req.Config != nil {
+		if msg := validateConfigView(*req.Config); msg != "" {
+			response.Error(w, errors.BadRequest(msg))
+			return
+		}
+		h.config.Apply(*req.Config)
+		h.metrics.SetMode(string(req.Config.Mode))
+	}
+
+	seed := service.NewSeed()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
 	// Use background context for the simulation engine lifecycle.
 	// The HTTP request context would cancel when the response is sent,
 	// but we want the simulation to run independently.
-	h.engine.Start(context.Background())
+	runID := h.engine.Start(context.Background(), seed)
+	if runID == "" {
+		response.Error(w, errors.Unavailable("simulation failed to start"))
+		return
+	}
 
-	response.OK(w, map[string]string{
+	response.OK(w, map[string]interface{}{
 		"message": "simulation started",
+		"run_id":  runID,
+		"seed":    seed,
 	})
 }
 
+// validateConfigView returns why a full configuration is invalid, or "".
+func validateConfigView(cfg config.ConfigView) string {
+	switch {
+	case cfg.Mode != config.ModeRealistic && cfg.Mode != config.ModeDemo:
+		return "mode must be 'realistic' or 'demo'"
+	case cfg.Failures.FailureRate < 0 || cfg.Failures.FailureRate > 1.0,
+		cfg.Failures.TransferFailureRate < 0 || cfg.Failures.TransferFailureRate > 1.0,
+		cfg.Failures.KYCRejectRate < 0 || cfg.Failures.KYCRejectRate > 1.0:
+		return "failure rates must be between 0.0 and 1.0"
+	case cfg.Delays.MinDelayMs < 0 || cfg.Delays.MaxDelayMs < 0 || cfg.Delays.TransferDelayMs < 0 ||
+		cfg.Delays.KYCReviewDelayMs < 0 || cfg.Delays.VerificationDelayMs < 0:
+		return "delays must be non-negative"
+	case cfg.Churn.DormancyRate < 0 || cfg.Churn.DormancyRate > 1.0,
+		cfg.Churn.WalletClosureRate < 0 || cfg.Churn.WalletClosureRate > 1.0,
+		cfg.Churn.SuspensionRate < 0 || cfg.Churn.SuspensionRate > 1.0,
+		cfg.Churn.ReactivationRate < 0 || cfg.Churn.ReactivationRate > 1.0:
+		return "churn rates must be between 0.0 and 1.0"
+	case cfg.Churn.Enabled && cfg.Churn.DayMinutes <= 0:
+		return "churn day_minutes must be positive"
+	case cfg.Assertions.SampleRate < 0 || cfg.Assertions.SampleRate > 1.0:
+		return "assertion sample_rate must be between 0.0 and 1.0"
+	case cfg.Assertions.SettleMs < 0:
+		return "assertion settle_ms must be non-negative"
+	}
+	return ""
+}
+
 // StopSimulation stops the simulation engine
 func (h *SimulationHandler) StopSimulation(w http.ResponseWriter, r *http.Request) {
 	if !h.engine.IsRunning() {
@@ -102,6 +180,10 @@ type UpdateConfigRequest struct {
 	MinDelayMs      *int     `json:"min_delay_ms,omitempty"`
 	MaxDelayMs      *int     `json:"max_delay_ms,omitempty"`
 	FailuresEnabled *bool    `json:"failures_enabled,omitempty"`
+	ChurnEnabled    *bool    `json:"churn_enabled,omitempty"`
+
+	AssertionsEnabled   *bool    `json:"assertions_enabled,omitempty"`
+	AssertionSampleRate *float64 `json:"assertion_sample_rate,omitempty"`
 }
 
 // UpdateConfig handles PUT /api/v1/simulation/config
@@ -133,6 +215,10 @@ func (h *SimulationHandler) UpdateConfig(w http.ResponseWriter, r *http.Request)
 		response.Error(w, errors.BadRequest("max_delay_ms must be non-negative"))
 		return
 	}
+	if req.AssertionSampleRate != nil && (*req.AssertionSampleRate < 0 || *req.AssertionSampleRate > 1.0) {
+		response.Error(w, errors.BadRequest("assertion_sample_rate must be between 0.0 and 1.0"))
+		return
+	}
 	if req.Mode != nil && *req.Mode != "realistic" && *req.Mode != "demo" {
 		response.Error(w, errors.BadRequest("mode must be 'realistic' or 'demo'"))
 		return
@@ -158,9 +244,19 @@ func (h *SimulationHandler) UpdateConfig(w http.ResponseWriter, r *http.Request)
 		if req.FailuresEnabled != nil {
 			cfg.Failures.Enabled = *req.FailuresEnabled
 		}
+		if req.ChurnEnabled != nil {
+			cfg.Churn.Enabled = *req.ChurnEnabled
+		}
+		if req.AssertionsEnabled != nil {
+			cfg.Assertions.Enabled = *req.AssertionsEnabled
+		}
+		if req.AssertionSampleRate != nil {
+			cfg.Assertions.SampleRate = *req.AssertionSampleRate
+		}
 	})
 
 	cfg := h.config.GetView()
+	h.engine.RecordConfigChange(cfg)
 	response.OK(w, map[string]interface{}{
 		"message": "configuration updated",
 		"config":  cfg,
@@ -197,6 +293,7 @@ func (h *SimulationHandler) SetMode(w http.ResponseWriter, r *http.Request) {
 	h.metrics.SetMode(req.Mode)
 
 	cfg := h.config.GetView()
+	h.engine.RecordConfigChange(cfg)
 	response.OK(w, map[string]interface{}{
 		"message": "mode switched to " + req.Mode,
 		"config":  cfg,
@@ -215,6 +312,47 @@ func (h *SimulationHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetMetricsHistory handles GET /api/v1/simulation/metrics/history?window=24h
+// Returns persisted success-rate and throughput trends, across restarts.
+// The window accepts Go durations ("90m", "24h") or days ("7d"); default 24h.
+func (h *SimulationHandler) GetMetricsHistory(w http.ResponseWriter, r *http.Request) {
+	if h.history == nil {
+		response.Error(w, errors.Unavailable("metrics history is not enabled"))
+		return
+	}
+
+	window := 24 * time.Hour
+	if param := r.URL.Query().Get("window"); param != "" {
+		parsed, err := parseWindow(param)
+		if err != nil || parsed < service.MetricsSampleInterval || parsed > service.MetricsRetention {
+			response.Error(w, errors.BadRequest("window must be a duration between "+
+				service.MetricsSampleInterval.String()+" and "+strconv.Itoa(int(service.MetricsRetention.Hours()/24))+"d"))
+			return
+		}
+		window = parsed
+	}
+
+	history, err := h.history.History(r.Context(), window, time.Now().UTC())
+	if err != nil {
+		response.Error(w, errors.InternalWrap(err, "failed to load metrics history"))
+		return
+	}
+
+	response.OK(w, history)
+}
+
+// parseWindow parses a Go duration, or a whole number of days such as "7d".
+func parseWindow(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 // ResetMetrics handles POST /api/v1/simulation/metrics/reset
 // Resets simulation metrics.
 func (h *SimulationHandler) ResetMetrics(w http.ResponseWriter, r *http.Request) {
@@ -224,3 +362,49 @@ func (h *SimulationHandler) ResetMetrics(w http.ResponseWriter, r *http.Request)
 		"message": "metrics reset",
 	})
 }
+
+// ListConservationReports handles GET /api/v1/simulation/conservation
+// Returns the money conservation reports of finished runs, newest first.
+func (h *SimulationHandler) ListConservationReports(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, map[string]interface{}{
+		"reports": h.engine.ConservationReports(),
+	})
+}
+
+// GetConservationReport handles GET /api/v1/simulation/conservation/{runId}
+// Returns the money conservation report of one finished run.
+func (h *SimulationHandler) GetConservationReport(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runId")
+	report, ok := h.engine.ConservationReport(runID)
+	if !ok {
+		response.Error(w, errors.NotFoundWithID("conservation report", runID))
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// GetCurrentManifest handles GET /api/v1/simulation/manifest
+// Returns the manifest of the running run, or of the last finished run.
+func (h *SimulationHandler) GetCurrentManifest(w http.ResponseWriter, r *http.Request) {
+	manifest, ok := h.engine.CurrentManifest()
+	if !ok {
+		response.Error(w, errors.NotFound("no simulation run yet"))
+		return
+	}
+
+	response.OK(w, manifest)
+}
+
+// GetRunManifest handles GET /api/v1/simulation/manifest/{runId}
+// Returns the seed, configuration and operations of one run.
+func (h *SimulationHandler) GetRunManifest(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("runId")
+	manifest, ok := h.engine.RunManifest(runID)
+	if !ok {
+		response.Error(w, errors.NotFoundWithID("run manifest", runID))
+		return
+	}
+
+	response.OK(w, manifest)
+}
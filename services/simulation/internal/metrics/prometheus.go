@@ -45,11 +45,45 @@ var (
 		Name: "simulation_users_activated",
 		Help: "Number of activated simulated users",
 	})
+	simUsersDormant = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_users_dormant",
+		Help: "Number of times simulated users went dormant",
+	})
+	simUsersReactivated = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_users_reactivated",
+		Help: "Number of times dormant simulated users became active again",
+	})
+	simUsersSuspended = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_users_suspended",
+		Help: "Number of simulated users suspended",
+	})
+	simWalletsClosed = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_wallets_closed",
+		Help: "Number of wallets closed by simulated users",
+	})
 	simActivePersonas = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "simulation_active_personas",
 		Help: "Number of active persona types in simulation",
 	})
 
+	// Assertion metrics
+	simAssertionsPassed = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_assertions_passed",
+		Help: "Number of end-to-end assertions that held after simulated operations",
+	})
+	simAssertionsFailed = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_assertions_failed",
+		Help: "Number of end-to-end assertions that failed after simulated operations",
+	})
+	simAssertionsInconclusive = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "simulation_assertions_inconclusive",
+		Help: "Number of end-to-end assertions skipped or not checked because the platform could not be queried",
+	})
+	simAssertionFailures = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "simulation_assertion_failures",
+		Help: "Number of failed end-to-end assertions by check",
+	}, []string{"check"})
+
 	// Verification metrics
 	simVerificationsCreated = promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "simulation_verifications_created",
@@ -91,8 +125,21 @@ func (m *SimulationMetrics) UpdatePrometheusMetrics() {
 	simUsersCreated.Set(float64(snapshot.UsersCreated))
 	simUsersKYCVerified.Set(float64(snapshot.UsersKYCVerified))
 	simUsersActivated.Set(float64(snapshot.UsersActivated))
+	simUsersDormant.Set(float64(snapshot.UsersDormant))
+	simUsersReactivated.Set(float64(snapshot.UsersReactivated))
+	simUsersSuspended.Set(float64(snapshot.UsersSuspended))
+	simWalletsClosed.Set(float64(snapshot.WalletsClosed))
 	simActivePersonas.Set(float64(snapshot.ActivePersonas))
 
+	// Assertion metrics
+	simAssertionsPassed.Set(float64(snapshot.AssertionsPassed))
+	simAssertionsFailed.Set(float64(snapshot.AssertionsFailed))
+	simAssertionsInconclusive.Set(float64(snapshot.AssertionsInconclusive))
+	simAssertionFailures.Reset()
+	for check, count := range snapshot.AssertionFailures {
+		simAssertionFailures.WithLabelValues(check).Set(float64(count))
+	}
+
 	// Verification metrics
 	simVerificationsCreated.Set(float64(snapshot.VerificationsCreated))
 	simVerificationsAutoApproved.Set(float64(snapshot.VerificationsAutoApproved))
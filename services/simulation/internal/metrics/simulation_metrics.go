@@ -28,6 +28,17 @@ type SimulationMetrics struct {
 	UsersKYCVerified int64 `json:"users_kyc_verified"`
 	UsersActivated   int64 `json:"users_activated"`
 
+	// User churn.
+	UsersDormant     int64 `json:"users_dormant"`
+	UsersReactivated int64 `json:"users_reactivated"`
+	UsersSuspended   int64 `json:"users_suspended"`
+	WalletsClosed    int64 `json:"wallets_closed"`
+
+	// End-to-end assertions.
+	AssertionsPassed       int64 `json:"assertions_passed"`
+	AssertionsFailed       int64 `json:"assertions_failed"`
+	AssertionsInconclusive int64 `json:"assertions_inconclusive"` // Skipped, or the platform could not be queried
+
 	// Timing metrics.
 	AverageDelayMs float64   `json:"average_delay_ms"`
 	StartedAt      time.Time `json:"started_at"`
@@ -37,8 +48,9 @@ type SimulationMetrics struct {
 	CurrentMode string `json:"current_mode"`
 
 	// Internal tracking.
-	totalDelayMs int64
-	delayCount   int64
+	totalDelayMs      int64
+	delayCount        int64
+	assertionFailures map[string]int64 // By check name
 }
 
 // NewSimulationMetrics creates a new metrics tracker.
@@ -115,6 +127,66 @@ func (m *SimulationMetrics) RecordUserActivated() {
 	m.UsersActivated++
 }
 
+// RecordUserDormant records an active user going dormant.
+func (m *SimulationMetrics) RecordUserDormant() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.UsersDormant++
+}
+
+// RecordUserReactivated records a dormant user becoming active again.
+func (m *SimulationMetrics) RecordUserReactivated() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.UsersReactivated++
+}
+
+// RecordUserSuspended records a user suspension.
+func (m *SimulationMetrics) RecordUserSuspended() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.UsersSuspended++
+}
+
+// RecordWalletClosed records a user closing their wallet.
+func (m *SimulationMetrics) RecordWalletClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.WalletsClosed++
+}
+
+// AssertionResult is the outcome of one end-to-end assertion.
+type AssertionResult string
+
+const (
+	AssertionPassed       AssertionResult = "passed"
+	AssertionFailed       AssertionResult = "failed"
+	AssertionInconclusive AssertionResult = "inconclusive"
+)
+
+// RecordAssertion records the result of an end-to-end assertion.
+func (m *SimulationMetrics) RecordAssertion(check string, result AssertionResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch result {
+	case AssertionPassed:
+		m.AssertionsPassed++
+	case AssertionFailed:
+		m.AssertionsFailed++
+		if m.assertionFailures == nil {
+			m.assertionFailures = make(map[string]int64)
+		}
+		m.assertionFailures[check]++
+	default:
+		m.AssertionsInconclusive++
+	}
+}
+
 // SetActivePersonas updates the active persona count.
 func (m *SimulationMetrics) SetActivePersonas(count int) {
 	m.mu.Lock()
@@ -133,21 +205,29 @@ func (m *SimulationMetrics) SetMode(mode string) {
 
 // MetricsView is a JSON-safe representation of metrics (no mutex).
 type MetricsView struct {
-	OperationsTotal           int64     `json:"operations_total"`
-	OperationsDelayed         int64     `json:"operations_delayed"`
-	OperationsFailed          int64     `json:"operations_failed"`
-	OperationsSucceeded       int64     `json:"operations_succeeded"`
-	VerificationsCreated      int64     `json:"verifications_created"`
-	VerificationsAutoApproved int64     `json:"verifications_auto_approved"`
-	ActivePersonas            int       `json:"active_personas"`
-	TransactionsGenerated     int64     `json:"transactions_generated"`
-	UsersCreated              int64     `json:"users_created"`
-	UsersKYCVerified          int64     `json:"users_kyc_verified"`
-	UsersActivated            int64     `json:"users_activated"`
-	AverageDelayMs            float64   `json:"average_delay_ms"`
-	StartedAt                 time.Time `json:"started_at"`
-	LastActivityAt            time.Time `json:"last_activity_at"`
-	CurrentMode               string    `json:"current_mode"`
+	OperationsTotal           int64            `json:"operations_total"`
+	OperationsDelayed         int64            `json:"operations_delayed"`
+	OperationsFailed          int64            `json:"operations_failed"`
+	OperationsSucceeded       int64            `json:"operations_succeeded"`
+	VerificationsCreated      int64            `json:"verifications_created"`
+	VerificationsAutoApproved int64            `json:"verifications_auto_approved"`
+	ActivePersonas            int              `json:"active_personas"`
+	TransactionsGenerated     int64            `json:"transactions_generated"`
+	UsersCreated              int64            `json:"users_created"`
+	UsersKYCVerified          int64            `json:"users_kyc_verified"`
+	UsersActivated            int64            `json:"users_activated"`
+	UsersDormant              int64            `json:"users_dormant"`
+	UsersReactivated          int64            `json:"users_reactivated"`
+	UsersSuspended            int64            `json:"users_suspended"`
+	WalletsClosed             int64            `json:"wallets_closed"`
+	AssertionsPassed          int64            `json:"assertions_passed"`
+	AssertionsFailed          int64            `json:"assertions_failed"`
+	AssertionsInconclusive    int64            `json:"assertions_inconclusive"`
+	AssertionFailures         map[string]int64 `json:"assertion_failures,omitempty"` // By check name
+	AverageDelayMs            float64          `json:"average_delay_ms"`
+	StartedAt                 time.Time        `json:"started_at"`
+	LastActivityAt            time.Time        `json:"last_activity_at"`
+	CurrentMode               string           `json:"current_mode"`
 }
 
 // GetSnapshot returns a JSON-safe view of current metrics.
@@ -155,6 +235,14 @@ func (m *SimulationMetrics) GetSnapshot() MetricsView {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
+	var failures map[string]int64
+	if len(m.assertionFailures) > 0 {
+		failures = make(map[string]int64, len(m.assertionFailures))
+		for check, count := range m.assertionFailures {
+			failures[check] = count
+		}
+	}
+
 	return MetricsView{
 		OperationsTotal:           m.OperationsTotal,
 		OperationsDelayed:         m.OperationsDelayed,
@@ -167,6 +255,14 @@ func (m *SimulationMetrics) GetSnapshot() MetricsView {
 		UsersCreated:              m.UsersCreated,
 		UsersKYCVerified:          m.UsersKYCVerified,
 		UsersActivated:            m.UsersActivated,
+		UsersDormant:              m.UsersDormant,
+		UsersReactivated:          m.UsersReactivated,
+		UsersSuspended:            m.UsersSuspended,
+		WalletsClosed:             m.WalletsClosed,
+		AssertionsPassed:          m.AssertionsPassed,
+		AssertionsFailed:          m.AssertionsFailed,
+		AssertionsInconclusive:    m.AssertionsInconclusive,
+		AssertionFailures:         failures,
 		AverageDelayMs:            m.AverageDelayMs,
 		StartedAt:                 m.StartedAt,
 		LastActivityAt:            m.LastActivityAt,
@@ -189,6 +285,14 @@ func (m *SimulationMetrics) Reset() {
 	m.UsersCreated = 0
 	m.UsersKYCVerified = 0
 	m.UsersActivated = 0
+	m.UsersDormant = 0
+	m.UsersReactivated = 0
+	m.UsersSuspended = 0
+	m.WalletsClosed = 0
+	m.AssertionsPassed = 0
+	m.AssertionsFailed = 0
+	m.AssertionsInconclusive = 0
+	m.assertionFailures = nil
 	m.AverageDelayMs = 0
 	m.totalDelayMs = 0
 	m.delayCount = 0
@@ -1,7 +1,9 @@
 package personas
 
 import (
+	"maps"
 	"math/rand"
+	"slices"
 	"time"
 )
 
@@ -126,22 +128,23 @@ func AllPersonaTypes() []PersonaType {
 }
 
 // RandomAmount generates a random amount within the persona's range
-func (p *Persona) RandomAmount() int64 {
-	return p.AmountRange.MinPaise + rand.Int63n(p.AmountRange.MaxPaise-p.AmountRange.MinPaise+1) //nolint:gosec // G404: weak random acceptable for test data
+func (p *Persona) RandomAmount(rng *rand.Rand) int64 {
+	return p.AmountRange.MinPaise + rng.Int63n(p.AmountRange.MaxPaise-p.AmountRange.MinPaise+1)
 }
 
-// SelectTransactionType randomly selects a transaction type based on weights
-func (p *Persona) SelectTransactionType() string {
+// SelectTransactionType randomly selects a transaction type based on weights.
+// Types are walked in sorted order so a seeded rng always picks the same one.
+func (p *Persona) SelectTransactionType(rng *rand.Rand) string {
 	total := 0
 	for _, weight := range p.TransactionTypes {
 		total += weight
 	}
 
-	r := rand.Intn(total) //nolint:gosec // G404: weak random acceptable for test data
+	r := rng.Intn(total)
 	cumulative := 0
 
-	for txType, weight := range p.TransactionTypes {
-		cumulative += weight
+	for _, txType := range slices.Sorted(maps.Keys(p.TransactionTypes)) {
+		cumulative += p.TransactionTypes[txType]
 		if r < cumulative {
 			return txType
 		}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// BalanceSkew is a wallet balance skewed away from its ledger account.
+type BalanceSkew struct {
+	WalletID string `json:"wallet_id"`
+	Amount   int64  `json:"amount"`
+}
+
+// StuckTransfer is a backdated pending transfer.
+type StuckTransfer struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// AnomalyClient injects and rolls back anomalies through the wallet and
+// transaction services' internal simulation endpoints, which those services
+// only enable outside production.
+type AnomalyClient struct {
+	wallet      *clients.BaseClient
+	transaction *clients.BaseClient
+}
+
+// NewAnomalyClient creates an anomaly client authenticated with the internal service secret.
+func NewAnomalyClient(walletURL, transactionURL, internalSecret string) *AnomalyClient {
+	return &AnomalyClient{
+		wallet:      clients.NewInternalClient(walletURL, clients.ShortTimeout, internalSecret),
+		transaction: clients.NewInternalClient(transactionURL, clients.ShortTimeout, internalSecret),
+	}
+}
+
+// SkewBalance raises the balance of a wallet in agreement with its ledger
+// account by amount, without a ledger posting.
+func (c *AnomalyClient) SkewBalance(ctx context.Context, amount int64) (*BalanceSkew, *errors.Error) {
+	var skew BalanceSkew
+	if err := c.wallet.Post(ctx, "/internal/v1/simulation/balance-skews", map[string]int64{"amount": amount}, &skew); err != nil {
+		return nil, err
+	}
+	return &skew, nil
+}
+
+// RestoreBalance takes a skew back off the wallet's balance.
+func (c *AnomalyClient) RestoreBalance(ctx context.Context, skew *BalanceSkew) *errors.Error {
+	return c.wallet.Post(ctx, "/internal/v1/simulation/balance-skews/restore", skew, nil)
+}
+
+// CreateStuckTransfer records a pending transfer backdated past the
+// transaction service's stuck pending threshold.
+func (c *AnomalyClient) CreateStuckTransfer(ctx context.Context, amount int64) (*StuckTransfer, *errors.Error) {
+	var transfer StuckTransfer
+	if err := c.transaction.Post(ctx, "/internal/v1/simulation/stuck-transfers", map[string]int64{"amount": amount}, &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// DeleteStuckTransfer removes a transfer created by CreateStuckTransfer.
+func (c *AnomalyClient) DeleteStuckTransfer(ctx context.Context, id string) *errors.Error {
+	return c.transaction.Delete(ctx, "/internal/v1/simulation/stuck-transfers/"+id, nil)
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// AnomalyType identifies a kind of deliberate data corruption.
+type AnomalyType string
+
+const (
+	// AnomalyBalanceMismatch skews a wallet balance away from its ledger account.
+	AnomalyBalanceMismatch AnomalyType = "balance_mismatch"
+	// AnomalyStuckPending inserts a transfer that never leaves the pending state.
+	AnomalyStuckPending AnomalyType = "stuck_pending"
+)
+
+// AnomalyStatus tracks the lifecycle of an injected anomaly.
+type AnomalyStatus string
+
+const (
+	AnomalyStatusInjected   AnomalyStatus = "injected"
+	AnomalyStatusDetected   AnomalyStatus = "detected"
+	AnomalyStatusMissed     AnomalyStatus = "missed"
+	AnomalyStatusFailed     AnomalyStatus = "failed"
+	AnomalyStatusRolledBack AnomalyStatus = "rolled_back"
+
+	// AnomalyStatusInconclusive means the detector's results were truncated
+	// without listing the anomaly, so it may have been found but left out.
+	AnomalyStatusInconclusive AnomalyStatus = "inconclusive"
+)
+
+const (
+	// DefaultAnomalySLA is how long detection may take before the run is
+	// reported as missed. The detectors run every minute by default.
+	DefaultAnomalySLA = 3 * time.Minute
+	// MaxAnomalySLA bounds how long a single run may poll.
+	MaxAnomalySLA = 10 * time.Minute
+	// DefaultMismatchAmount is the skew applied to a wallet balance (₹1.00 in paise).
+	DefaultMismatchAmount int64 = 100
+	// StuckPendingThreshold is the age after which the transaction service's
+	// reaper fails a pending transfer (STUCK_PENDING_THRESHOLD_SECONDS).
+	StuckPendingThreshold = 15 * time.Minute
+
+	// Detectors the injected anomalies are verified against.
+	detectorLedgerReconciliation = "wallet_ledger_reconciliation"
+	detectorStuckPendingReaper   = "transaction_stuck_pending_reaper"
+
+	anomalyPollInterval = 5 * time.Second
+	maxAnomalyReports   = 100
+)
+
+// AnomalyRequest describes an anomaly to inject.
+type AnomalyRequest struct {
+	Type     AnomalyType   `json:"type"`
+	SLA      time.Duration `json:"-"`
+	Amount   int64         `json:"amount,omitempty"`
+	Rollback bool          `json:"rollback"`
+}
+
+// AnomalyReport records an injected anomaly and whether it was detected within SLA.
+type AnomalyReport struct {
+	ID               string        `json:"id"`
+	Type             AnomalyType   `json:"type"`
+	Status           AnomalyStatus `json:"status"`
+	TargetID         string        `json:"target_id,omitempty"`
+	Detector         string        `json:"detector"`
+	Detail           string        `json:"detail,omitempty"`
+	SLASeconds       float64       `json:"sla_seconds"`
+	InjectedAt       time.Time     `json:"injected_at"`
+	DetectedAt       *time.Time    `json:"detected_at,omitempty"`
+	DetectionSeconds *float64      `json:"detection_seconds,omitempty"`
+	WithinSLA        bool          `json:"within_sla"`
+	RolledBack       bool          `json:"rolled_back"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// anomalyTarget holds what an injection changed so it can be detected and undone.
+type anomalyTarget struct {
+	walletID   string
+	amount     int64
+	txnID      string
+	injectedAt time.Time
+}
+
+// AnomalyInjector deliberately corrupts wallet/transaction data and verifies
+// that the wallet service's ledger reconciliation and the transaction
+// service's stuck-pending reaper pick it up within SLA, by polling their
+// results through the gateway. The corruption is applied through those
+// services' internal simulation endpoints, which they disable in production.
+type AnomalyInjector struct {
+	client  *AnomalyClient
+	gateway *GatewayClient
+	mu      sync.RWMutex
+	reports map[string]*AnomalyReport
+}
+
+// NewAnomalyInjector creates a new anomaly injector.
+func NewAnomalyInjector(client *AnomalyClient, gateway *GatewayClient) *AnomalyInjector {
+	return &AnomalyInjector{
+		client:  client,
+		gateway: gateway,
+		reports: make(map[string]*AnomalyReport),
+	}
+}
+
+// Inject applies the requested anomaly and starts verifying detection in the background.
+// The returned report is a snapshot; use GetReport to follow progress.
+func (a *AnomalyInjector) Inject(ctx context.Context, req AnomalyRequest) (*AnomalyReport, error) {
+	if req.SLA <= 0 {
+		req.SLA = DefaultAnomalySLA
+	}
+	if req.SLA > MaxAnomalySLA {
+		req.SLA = MaxAnomalySLA
+	}
+	if req.Amount <= 0 {
+		req.Amount = DefaultMismatchAmount
+	}
+
+	report := &AnomalyReport{
+		ID:         newAnomalyID(),
+		Type:       req.Type,
+		Status:     AnomalyStatusInjected,
+		Detector:   detectorFor(req.Type),
+		SLASeconds: req.SLA.Seconds(),
+	}
+
+	var target *anomalyTarget
+	var err error
+	switch req.Type {
+	case AnomalyBalanceMismatch:
+		target, err = a.injectBalanceMismatch(ctx, req.Amount)
+		if target != nil {
+			report.TargetID = target.walletID
+			report.Detail = fmt.Sprintf("wallet balance skewed by %d paise", target.amount)
+		}
+	case AnomalyStuckPending:
+		target, err = a.injectStuckPending(ctx, req.Amount)
+		if target != nil {
+			report.TargetID = target.txnID
+			report.Detail = fmt.Sprintf("pending transfer backdated beyond %s", StuckPendingThreshold)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported anomaly type: %s", req.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.InjectedAt = time.Now()
+	target.injectedAt = report.InjectedAt
+	a.store(report)
+	log.Printf("[simulation] Injected anomaly %s (%s) on %s", report.ID, report.Type, report.TargetID)
+
+	// Verification outlives the HTTP request, so it runs on a background context
+	go a.verify(context.Background(), report.ID, req, target)
+
+	return a.snapshot(report.ID), nil
+}
+
+// GetReport returns a snapshot of the report with the given ID.
+func (a *AnomalyInjector) GetReport(id string) (*AnomalyReport, bool) {
+	report := a.snapshot(id)
+	return report, report != nil
+}
+
+// ListReports returns snapshots of all reports, newest first.
+func (a *AnomalyInjector) ListReports() []AnomalyReport {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	reports := make([]AnomalyReport, 0, len(a.reports))
+	for _, r := range a.reports {
+		reports = append(reports, *r)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].InjectedAt.After(reports[j].InjectedAt)
+	})
+	return reports
+}
+
+// verify polls the detector until the anomaly is seen or the SLA elapses,
+// then optionally rolls the corruption back.
+func (a *AnomalyInjector) verify(ctx context.Context, id string, req AnomalyRequest, target *anomalyTarget) {
+	deadline := time.Now().Add(req.SLA)
+	ticker := time.NewTicker(anomalyPollInterval)
+	defer ticker.Stop()
+
+	inconclusive := ""
+	for {
+		status, detail, err := a.detect(ctx, req.Type, target)
+		if err != nil {
+			a.update(id, func(r *AnomalyReport) {
+				r.Status = AnomalyStatusFailed
+				r.Error = err.Error()
+			})
+			break
+		}
+		if status == AnomalyStatusInconclusive {
+			inconclusive = detail
+		}
+		if status == AnomalyStatusDetected {
+			now := time.Now()
+			a.update(id, func(r *AnomalyReport) {
+				latency := now.Sub(r.InjectedAt).Seconds()
+				r.Status = AnomalyStatusDetected
+				r.DetectedAt = &now
+				r.DetectionSeconds = &latency
+				r.WithinSLA = latency <= r.SLASeconds
+				r.Detail += "; " + detail
+			})
+			log.Printf("[simulation] Anomaly %s detected by %s", id, detectorFor(req.Type))
+			break
+		}
+		if time.Now().After(deadline) && inconclusive != "" {
+			a.update(id, func(r *AnomalyReport) {
+				r.Status = AnomalyStatusInconclusive
+				r.Detail += "; " + inconclusive
+			})
+			log.Printf("[simulation] Anomaly %s detection inconclusive within SLA %s: %s", id, req.SLA, inconclusive)
+			break
+		}
+		if time.Now().After(deadline) {
+			a.update(id, func(r *AnomalyReport) {
+				r.Status = AnomalyStatusMissed
+			})
+			log.Printf("[simulation] Anomaly %s NOT detected within SLA %s", id, req.SLA)
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	if !req.Rollback {
+		return
+	}
+	if err := a.rollback(ctx, req.Type, target); err != nil {
+		log.Printf("[simulation] Failed to roll back anomaly %s: %v", id, err)
+		a.update(id, func(r *AnomalyReport) {
+			r.Error = fmt.Sprintf("rollback failed: %v", err)
+		})
+		return
+	}
+	a.update(id, func(r *AnomalyReport) {
+		r.RolledBack = true
+		if r.Status == AnomalyStatusInjected {
+			r.Status = AnomalyStatusRolledBack
+		}
+	})
+}
+
+// injectBalanceMismatch has the wallet service raise the balance of a wallet
+// in agreement with its ledger account, without a matching ledger posting, so
+// any mismatch found afterwards is attributable to the injection.
+func (a *AnomalyInjector) injectBalanceMismatch(ctx context.Context, amount int64) (*anomalyTarget, error) {
+	skew, err := a.client.SkewBalance(ctx, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to skew wallet balance: %w", err)
+	}
+	return &anomalyTarget{walletID: skew.WalletID, amount: skew.Amount}, nil
+}
+
+// injectStuckPending has the transaction service record a pending transfer
+// backdated past its stuck pending threshold.
+func (a *AnomalyInjector) injectStuckPending(ctx context.Context, amount int64) (*anomalyTarget, error) {
+	transfer, err := a.client.CreateStuckTransfer(ctx, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stuck transfer: %w", err)
+	}
+	return &anomalyTarget{txnID: transfer.ID, amount: amount}, nil
+}
+
+// detect reports whether the anomaly's detector has picked it up, and what
+// it found. A balance mismatch is detected once a ledger reconciliation that
+// completed after the injection lists the wallet; a stuck transfer once the
+// reaper has failed it. A truncated reconciliation that doesn't list the
+// wallet is inconclusive rather than a miss. Until then the status is
+// injected.
+func (a *AnomalyInjector) detect(ctx context.Context, anomaly AnomalyType, target *anomalyTarget) (AnomalyStatus, string, error) {
+	switch anomaly {
+	case AnomalyBalanceMismatch:
+		report, err := a.gateway.GetReconciliationReport(ctx)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return AnomalyStatusInjected, "", nil // No reconciliation has completed yet
+			}
+			return "", "", fmt.Errorf("failed to fetch reconciliation report: %w", err)
+		}
+		// Report times have second precision
+		if report.CompletedAt.Before(target.injectedAt.Truncate(time.Second)) {
+			return AnomalyStatusInjected, "", nil
+		}
+		for _, mismatch := range report.Mismatches {
+			if mismatch.WalletID == target.walletID {
+				return AnomalyStatusDetected, fmt.Sprintf("reconciliation found wallet balance %d against ledger balance %d",
+					mismatch.WalletBalance, mismatch.LedgerBalance), nil
+			}
+		}
+		if report.Truncated {
+			return AnomalyStatusInconclusive, fmt.Sprintf("reconciliation report truncated at %d mismatches without listing the wallet",
+				len(report.Mismatches)), nil
+		}
+		return AnomalyStatusInjected, "", nil
+	case AnomalyStuckPending:
+		tx, err := a.gateway.GetTransaction(ctx, target.txnID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to fetch transaction: %w", err)
+		}
+		if tx.Status != "failed" {
+			return AnomalyStatusInjected, "", nil
+		}
+		return AnomalyStatusDetected, fmt.Sprintf("reaper failed the transfer: %s", tx.FailureReason), nil
+	}
+	return "", "", fmt.Errorf("unsupported anomaly type: %s", anomaly)
+}
+
+// detectorFor names the subsystem expected to detect an anomaly type.
+func detectorFor(anomaly AnomalyType) string {
+	if anomaly == AnomalyStuckPending {
+		return detectorStuckPendingReaper
+	}
+	return detectorLedgerReconciliation
+}
+
+// rollback undoes an injected anomaly. It fails when nothing was undone, such
+// as when the wallet has since spent the skewed amount.
+func (a *AnomalyInjector) rollback(ctx context.Context, anomaly AnomalyType, target *anomalyTarget) error {
+	switch anomaly {
+	case AnomalyBalanceMismatch:
+		if err := a.client.RestoreBalance(ctx, &BalanceSkew{WalletID: target.walletID, Amount: target.amount}); err != nil {
+			return fmt.Errorf("nothing rolled back: %w", err)
+		}
+	case AnomalyStuckPending:
+		if err := a.client.DeleteStuckTransfer(ctx, target.txnID); err != nil {
+			return fmt.Errorf("nothing rolled back: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported anomaly type: %s", anomaly)
+	}
+	return nil
+}
+
+// store saves a report, evicting the oldest once the history is full.
+func (a *AnomalyInjector) store(report *AnomalyReport) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.reports) >= maxAnomalyReports {
+		var oldest *AnomalyReport
+		for _, r := range a.reports {
+			if oldest == nil || r.InjectedAt.Before(oldest.InjectedAt) {
+				oldest = r
+			}
+		}
+		delete(a.reports, oldest.ID)
+	}
+	a.reports[report.ID] = report
+}
+
+// update mutates a stored report under the lock.
+func (a *AnomalyInjector) update(id string, fn func(*AnomalyReport)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if r, ok := a.reports[id]; ok {
+		fn(r)
+	}
+}
+
+// snapshot returns a copy of a stored report, or nil if unknown.
+func (a *AnomalyInjector) snapshot(id string) *AnomalyReport {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	r, ok := a.reports[id]
+	if !ok {
+		return nil
+	}
+	cp := *r
+	return &cp
+}
+
+// newAnomalyID generates a short random identifier for an anomaly run.
+func newAnomalyID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("anom-%d", time.Now().UnixNano())
+	}
+	return "anom-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/metrics"
+)
+
+// assertionRetryInterval is how often a failing check is retried while the
+// platform settles.
+const assertionRetryInterval = 250 * time.Millisecond
+
+// errAssertionSkipped is returned by a check that does not apply to the
+// operation as it turned out, e.g. a transfer still pending.
+var errAssertionSkipped = errors.New("assertion skipped")
+
+// AssertionFailure is an invariant that did not hold after an operation.
+type AssertionFailure struct {
+	Message string
+}
+
+// Error implements error.
+func (f *AssertionFailure) Error() string {
+	return f.Message
+}
+
+// assertionFailed returns an AssertionFailure.
+func assertionFailed(format string, args ...interface{}) error {
+	return &AssertionFailure{Message: fmt.Sprintf(format, args...)}
+}
+
+// AssertedOperation is a simulated operation checked by the assertion hooks.
+type AssertedOperation struct {
+	Kind          OperationKind
+	User          string // Email
+	UserID        string
+	WalletID      string
+	Recipient     string // Destination wallet of a transfer
+	Amount        int64  // Paise
+	TransactionID string // Set once the operation succeeded
+	StartedAt     time.Time
+
+	balances map[string]int64 // Wallet balances before the operation
+}
+
+// AssertionHook checks an invariant of the platform around a simulated
+// operation. Before runs ahead of the operation to capture state; Check runs
+// after it succeeded and returns an *AssertionFailure when the invariant does
+// not hold, errAssertionSkipped when it does not apply, or another error when
+// the platform could not be queried. A failing Check is retried until the
+// configured settle time runs out.
+type AssertionHook interface {
+	Name() string
+	Applies(op *AssertedOperation) bool
+	Before(ctx context.Context, op *AssertedOperation) error
+	Check(ctx context.Context, op *AssertedOperation) error
+}
+
+// defaultAssertionHooks returns the hooks the engine runs.
+func defaultAssertionHooks(gateway *GatewayClient, db *sql.DB) []AssertionHook {
+	return []AssertionHook{
+		&balanceAssertion{gateway: gateway},
+		&ledgerAssertion{gateway: gateway},
+		&notificationAssertion{db: db},
+	}
+}
+
+// AddAssertionHook registers an extra check run after simulated operations.
+// Call it before the engine starts.
+func (s *SimulationEngine) AddAssertionHook(hook AssertionHook) {
+	s.assertionHooks = append(s.assertionHooks, hook)
+}
+
+// pendingAssertions are the hooks checking one operation, with the state
+// they captured before it.
+type pendingAssertions struct {
+	op    *AssertedOperation
+	hooks []AssertionHook
+}
+
+// assertedTransaction returns the operation checked for a transaction.
+func assertedTransaction(op RunOperation, userID, walletID string) *AssertedOperation {
+	return &AssertedOperation{
+		Kind:      op.Kind,
+		User:      op.User,
+		UserID:    userID,
+		WalletID:  walletID,
+		Recipient: op.Recipient,
+		Amount:    op.Amount,
+	}
+}
+
+// setUserID records the user an operation created, once it is known.
+func (p *pendingAssertions) setUserID(userID string) {
+	if p != nil {
+		p.op.UserID = userID
+	}
+}
+
+// beginAssertions samples an operation for checking and runs the Before step
+// of the hooks that apply to it. It returns nil when the operation is not
+// checked.
+func (s *SimulationEngine) beginAssertions(ctx context.Context, op *AssertedOperation) *pendingAssertions {
+	cfg := s.config.GetAssertions()
+	if !cfg.Enabled || len(s.assertionHooks) == 0 {
+		return nil
+	}
+	if cfg.SampleRate < 1 && s.randFloat64() >= cfg.SampleRate {
+		return nil
+	}
+
+	op.StartedAt = time.Now()
+	pending := &pendingAssertions{op: op}
+	for _, hook := range s.assertionHooks {
+		if !hook.Applies(op) {
+			continue
+		}
+		if err := hook.Before(ctx, op); err != nil {
+			log.Printf("[simulation] Assertion %s could not capture state for %s: %v", hook.Name(), op.Kind, err)
+			s.metrics.RecordAssertion(hook.Name(), metrics.AssertionInconclusive)
+			continue
+		}
+		pending.hooks = append(pending.hooks, hook)
+	}
+	return pending
+}
+
+// finishAssertions checks an operation that was begun with beginAssertions.
+// Operations that failed are not checked.
+func (s *SimulationEngine) finishAssertions(ctx context.Context, pending *pendingAssertions, transactionID string, opErr error) {
+	if pending == nil || opErr != nil {
+		return
+	}
+	pending.op.TransactionID = transactionID
+
+	settle := time.Duration(s.config.GetAssertions().SettleMs) * time.Millisecond
+	for _, hook := range pending.hooks {
+		err := runAssertion(ctx, hook, pending.op, settle)
+
+		var failure *AssertionFailure
+		switch {
+		case err == nil:
+			s.metrics.RecordAssertion(hook.Name(), metrics.AssertionPassed)
+		case errors.As(err, &failure):
+			log.Printf("[simulation] ❌ Assertion %s failed after %s for %s: %s", hook.Name(), pending.op.Kind, pending.op.User, failure.Message)
+			s.metrics.RecordAssertion(hook.Name(), metrics.AssertionFailed)
+			s.recordOperation(RunOperation{
+				Kind: OpAssertionFailed, Outcome: OutcomeFailed, Check: hook.Name(),
+				User: pending.op.User, Amount: pending.op.Amount, Recipient: pending.op.Recipient,
+				TransactionID: transactionID, Error: failure.Message,
+			})
+		case errors.Is(err, errAssertionSkipped):
+			s.metrics.RecordAssertion(hook.Name(), metrics.AssertionInconclusive)
+		default:
+			log.Printf("[simulation] Assertion %s could not check %s: %v", hook.Name(), pending.op.Kind, err)
+			s.metrics.RecordAssertion(hook.Name(), metrics.AssertionInconclusive)
+		}
+	}
+}
+
+// runAssertion runs a hook's check, retrying while it fails until settle has
+// passed.
+func runAssertion(ctx context.Context, hook AssertionHook, op *AssertedOperation, settle time.Duration) error {
+	deadline := time.Now().Add(settle)
+	for {
+		err := hook.Check(ctx, op)
+		if err == nil || errors.Is(err, errAssertionSkipped) || time.Now().Add(assertionRetryInterval).After(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(assertionRetryInterval):
+		}
+	}
+}
+
+// isTransactionOperation reports whether an operation moves money.
+func isTransactionOperation(kind OperationKind) bool {
+	return kind == OpDeposit || kind == OpTransfer || kind == OpWithdrawal
+}
+
+// completedTransaction skips a check unless the operation's transaction has
+// completed; deposits and withdrawals may still be pending.
+func completedTransaction(ctx context.Context, gateway *GatewayClient, op *AssertedOperation) error {
+	if op.TransactionID == "" {
+		return errAssertionSkipped
+	}
+	tx, err := gateway.GetTransaction(ctx, op.TransactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != "completed" {
+		return errAssertionSkipped
+	}
+	return nil
+}
+
+// ========================================================================
+// Balance
+// ========================================================================
+
+// balanceAssertion checks that a completed transaction moved its wallets'
+// balances by exactly its amount. Concurrent activity on the same wallets,
+// such as a load test, makes it fail.
+type balanceAssertion struct {
+	gateway *GatewayClient
+}
+
+// Name implements AssertionHook.
+func (a *balanceAssertion) Name() string { return "balance" }
+
+// Applies implements AssertionHook.
+func (a *balanceAssertion) Applies(op *AssertedOperation) bool {
+	return isTransactionOperation(op.Kind) && op.WalletID != ""
+}
+
+// expectedChanges returns how the operation should move each wallet's balance.
+func (a *balanceAssertion) expectedChanges(op *AssertedOperation) map[string]int64 {
+	switch op.Kind {
+	case OpDeposit:
+		return map[string]int64{op.WalletID: op.Amount}
+	case OpWithdrawal:
+		return map[string]int64{op.WalletID: -op.Amount}
+	default:
+		return map[string]int64{op.WalletID: -op.Amount, op.Recipient: op.Amount}
+	}
+}
+
+// Before implements AssertionHook.
+func (a *balanceAssertion) Before(ctx context.Context, op *AssertedOperation) error {
+	op.balances = make(map[string]int64)
+	for walletID := range a.expectedChanges(op) {
+		balance, err := a.gateway.GetWalletBalance(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		op.balances[walletID] = balance.Balance
+	}
+	return nil
+}
+
+// Check implements AssertionHook.
+func (a *balanceAssertion) Check(ctx context.Context, op *AssertedOperation) error {
+	if err := completedTransaction(ctx, a.gateway, op); err != nil {
+		return err
+	}
+
+	for walletID, change := range a.expectedChanges(op) {
+		balance, err := a.gateway.GetWalletBalance(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		if got := balance.Balance - op.balances[walletID]; got != change {
+			return assertionFailed("wallet %s balance changed by %d, expected %d", walletID, got, change)
+		}
+	}
+	return nil
+}
+
+// ========================================================================
+// Ledger
+// ========================================================================
+
+// ledgerAssertion checks that a completed transaction has exactly one posted,
+// balanced journal entry for its amount.
+type ledgerAssertion struct {
+	gateway *GatewayClient
+}
+
+// Name implements AssertionHook.
+func (a *ledgerAssertion) Name() string { return "ledger_entry" }
+
+// Applies implements AssertionHook.
+func (a *ledgerAssertion) Applies(op *AssertedOperation) bool {
+	return isTransactionOperation(op.Kind)
+}
+
+// Before implements AssertionHook.
+func (a *ledgerAssertion) Before(ctx context.Context, op *AssertedOperation) error {
+	return nil
+}
+
+// Check implements AssertionHook.
+func (a *ledgerAssertion) Check(ctx context.Context, op *AssertedOperation) error {
+	if err := completedTransaction(ctx, a.gateway, op); err != nil {
+		return err
+	}
+
+	results, err := a.gateway.VerifyLedgerReferences(ctx, []LedgerReference{{ReferenceID: op.TransactionID, Amount: op.Amount}})
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("ledger returned %d results for one reference", len(results))
+	}
+	if result := results[0]; result.Status != "matched" {
+		return assertionFailed("journal entry for transaction %s is %s (posted %d, expected %d) %s",
+			op.TransactionID, result.Status, result.PostedAmount, op.Amount, result.Detail)
+	}
+	return nil
+}
+
+// ========================================================================
+// Notification
+// ========================================================================
+
+// notificationAssertion checks that registering a user and verifying their
+// KYC notify the user. The notification service is not exposed through the
+// gateway, so it reads the shared database like the conservation report.
+type notificationAssertion struct {
+	db *sql.DB
+}
+
+// notificationTypes are the notification types each operation sends.
+var notificationTypes = map[OperationKind]string{
+	OpUserRegistered: "welcome",
+	OpKYCVerified:    "kyc_status",
+}
+
+// Name implements AssertionHook.
+func (a *notificationAssertion) Name() string { return "notification" }
+
+// Applies implements AssertionHook.
+func (a *notificationAssertion) Applies(op *AssertedOperation) bool {
+	_, ok := notificationTypes[op.Kind]
+	return ok && a.db != nil
+}
+
+// Before implements AssertionHook.
+func (a *notificationAssertion) Before(ctx context.Context, op *AssertedOperation) error {
+	return nil
+}
+
+// Check implements AssertionHook.
+func (a *notificationAssertion) Check(ctx context.Context, op *AssertedOperation) error {
+	if op.UserID == "" {
+		return errAssertionSkipped
+	}
+	notificationType := notificationTypes[op.Kind]
+
+	var count int
+	err := a.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND type = $2 AND created_at >= $3
+	`, op.UserID, notificationType, op.StartedAt.Add(-time.Second)).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to query notifications: %w", err)
+	}
+	if count == 0 {
+		return assertionFailed("no %s notification created for user %s", notificationType, op.UserID)
+	}
+	return nil
+}
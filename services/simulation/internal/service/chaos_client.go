@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// Chaos fault types understood by the gateway.
+const (
+	ChaosFaultTimeout = "timeout"
+	ChaosFaultError   = "error"
+	ChaosFaultLatency = "latency"
+)
+
+// ChaosRule injects one kind of fault into gateway calls for a service.
+type ChaosRule struct {
+	Service     string  `json:"service"`
+	Fault       string  `json:"fault"`
+	Probability float64 `json:"probability"`
+	DelayMs     int     `json:"delay_ms,omitempty"`
+}
+
+// ChaosSettings is the chaos configuration pushed to the gateway.
+type ChaosSettings struct {
+	Rules           []ChaosRule `json:"rules"`
+	SSEDelayMs      int         `json:"sse_delay_ms,omitempty"`
+	DurationSeconds int         `json:"duration_seconds,omitempty"`
+}
+
+// ChaosStatus is the gateway's view of the active chaos configuration.
+type ChaosStatus struct {
+	Active bool `json:"active"`
+	Config *struct {
+		Rules      []ChaosRule `json:"rules"`
+		SSEDelayMs int         `json:"sse_delay_ms,omitempty"`
+		ExpiresAt  string      `json:"expires_at"`
+	} `json:"config,omitempty"`
+}
+
+// ChaosClient controls fault injection on the gateway's internal chaos endpoints.
+type ChaosClient struct {
+	*clients.BaseClient
+}
+
+// NewChaosClient creates a chaos client authenticated with the internal service secret.
+func NewChaosClient(gatewayURL, internalSecret string) *ChaosClient {
+	return &ChaosClient{
+		BaseClient: clients.NewInternalClient(gatewayURL, clients.ShortTimeout, internalSecret),
+	}
+}
+
+// Get returns the active chaos configuration.
+func (c *ChaosClient) Get(ctx context.Context) (*ChaosStatus, *errors.Error) {
+	var status ChaosStatus
+	if err := c.BaseClient.Get(ctx, "/internal/v1/chaos", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Set replaces the active chaos configuration.
+func (c *ChaosClient) Set(ctx context.Context, settings ChaosSettings) (*ChaosStatus, *errors.Error) {
+	var status ChaosStatus
+	if err := c.Put(ctx, "/internal/v1/chaos", settings, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Clear disables chaos injection.
+func (c *ChaosClient) Clear(ctx context.Context) (*ChaosStatus, *errors.Error) {
+	var status ChaosStatus
+	if err := c.Delete(ctx, "/internal/v1/chaos", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+)
+
+// churnInterval is how often simulated users may churn.
+const churnInterval = 10 * time.Minute
+
+// churnProbability converts a daily rate into the probability of the change
+// happening within one interval, for a day of the given length.
+func churnProbability(dailyRate float64, interval, day time.Duration) float64 {
+	if dailyRate <= 0 || day <= 0 {
+		return 0
+	}
+	if dailyRate >= 1 {
+		return 1
+	}
+	return 1 - math.Pow(1-dailyRate, float64(interval)/float64(day))
+}
+
+// runChurnCycle moves simulated users in and out of activity: active users
+// may go dormant, close their wallet or be suspended, and dormant users may
+// come back. Users loaded from the database are left alone.
+func (s *SimulationEngine) runChurnCycle(ctx context.Context) {
+	churn := s.config.GetChurn()
+	if !churn.Enabled {
+		return
+	}
+
+	day := time.Duration(churn.DayMinutes) * time.Minute
+	suspend := churnProbability(churn.SuspensionRate, churnInterval, day)
+	closeWallet := suspend + churnProbability(churn.WalletClosureRate, churnInterval, day)
+	dormant := closeWallet + churnProbability(churn.DormancyRate, churnInterval, day)
+	reactivate := churnProbability(churn.ReactivationRate, churnInterval, day)
+
+	log.Printf("[simulation] 📉 Running churn cycle")
+
+	for _, user := range s.simulatedUsers {
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch user.Stage {
+		case StageActive:
+			// One draw per user keeps replays making the same choices
+			roll := s.randFloat64()
+			switch {
+			case roll < suspend:
+				s.suspendUser(ctx, user)
+			case roll < closeWallet:
+				s.closeUserWallet(ctx, user)
+			case roll < dormant:
+				err := s.lifecycleManager.MarkDormant(ctx, user)
+				s.recordOutcome(RunOperation{Kind: OpUserDormant, User: user.Email}, err)
+				if err == nil {
+					s.metrics.RecordUserDormant()
+				}
+			}
+
+		case StageDormant:
+			if s.randFloat64() >= reactivate {
+				continue
+			}
+			err := s.lifecycleManager.Reactivate(ctx, user)
+			s.recordOutcome(RunOperation{Kind: OpUserReactivated, User: user.Email}, err)
+			if err != nil {
+				log.Printf("[simulation] Failed to reactivate user %s: %v", user.Email, err)
+				continue
+			}
+			s.metrics.RecordUserReactivated()
+		}
+	}
+}
+
+// suspendUser suspends a simulated user.
+func (s *SimulationEngine) suspendUser(ctx context.Context, user *SimulatedUser) {
+	err := s.lifecycleManager.Suspend(ctx, user)
+	s.recordOutcome(RunOperation{Kind: OpUserSuspended, User: user.Email}, err)
+	if err != nil {
+		log.Printf("[simulation] Failed to suspend user %s: %v", user.Email, err)
+		return
+	}
+	s.metrics.RecordUserSuspended()
+}
+
+// closeUserWallet withdraws a simulated user's balance and closes their wallet.
+func (s *SimulationEngine) closeUserWallet(ctx context.Context, user *SimulatedUser) {
+	balance := user.Balance
+	withdrawalID, err := s.lifecycleManager.CloseWallet(ctx, user)
+	if withdrawalID != "" {
+		s.recordOperation(RunOperation{
+			Kind: OpWithdrawal, Outcome: OutcomeSucceeded,
+			User: user.Email, Amount: balance, TransactionID: withdrawalID,
+		})
+		s.metrics.RecordTransaction()
+		s.recordExecuted(withdrawalID)
+	}
+	s.recordOutcome(RunOperation{Kind: OpWalletClosed, User: user.Email}, err)
+	if err != nil {
+		log.Printf("[simulation] Failed to close wallet for %s: %v", user.Email, err)
+		return
+	}
+	s.metrics.RecordWalletClosed()
+}
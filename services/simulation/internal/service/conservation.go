@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// maxConservationReports is how many finished runs keep their report.
+	maxConservationReports = 20
+	// maxConservationContributors caps the transactions listed in one report.
+	maxConservationContributors = 200
+	// conservationReportTimeout bounds the queries that build a report.
+	conservationReportTimeout = 30 * time.Second
+)
+
+// ContributorKind says why a transaction or ledger entry is listed in a report.
+type ContributorKind string
+
+const (
+	// ContributorMissingEntry is a settled transaction with no posted control-account movement.
+	ContributorMissingEntry ContributorKind = "missing_ledger_entry"
+	// ContributorAmountMismatch is a transaction whose posted movement differs from its amount.
+	ContributorAmountMismatch ContributorKind = "amount_mismatch"
+	// ContributorUnsettled is a pending or failed transaction that nonetheless moved money.
+	ContributorUnsettled ContributorKind = "unsettled_with_entry"
+	// ContributorUnattributed is a ledger entry in the window that no engine transaction explains.
+	ContributorUnattributed ContributorKind = "unattributed_entry"
+)
+
+// FlowTotal sums the engine's settled transactions of one type.
+type FlowTotal struct {
+	Count  int   `json:"count"`
+	Amount int64 `json:"amount"` // Paise
+}
+
+// ConservationContributor is a transaction or ledger entry that explains part of a delta.
+type ConservationContributor struct {
+	Kind          ContributorKind `json:"kind"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	EntryIDs      []string        `json:"entry_ids,omitempty"`
+	Type          string          `json:"type,omitempty"`
+	Status        string          `json:"status,omitempty"`
+	Amount        int64           `json:"amount,omitempty"`
+	Expected      int64           `json:"expected"` // Expected control-account movement
+	Posted        int64           `json:"posted"`   // Posted control-account movement
+	Delta         int64           `json:"delta"`
+}
+
+// ConservationReport compares the money a simulation run moved with the
+// movement on ledger control accounts over the same window. Control accounts
+// are every ledger account except customer wallet accounts; movement is
+// debits minus credits, so money entering customer wallets is positive.
+type ConservationReport struct {
+	RunID       string    `json:"run_id"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Executed  map[string]*FlowTotal `json:"executed"`   // Settled engine transactions by type
+	Unsettled int                   `json:"unsettled"`  // Engine transactions still pending or failed
+	Expected  int64                 `json:"expected"`   // Net control movement the run should cause
+	LedgerNet int64                 `json:"ledger_net"` // Net control movement posted in the window
+	Delta     int64                 `json:"delta"`      // LedgerNet - Expected
+	Conserved bool                  `json:"conserved"`
+
+	Contributors          []*ConservationContributor `json:"contributors"`
+	ContributorsTruncated bool                       `json:"contributors_truncated,omitempty"`
+	Error                 string                     `json:"error,omitempty"`
+}
+
+// engineTransaction is a transaction the engine created, or a fee, reversal
+// or refund raised against one.
+type engineTransaction struct {
+	id       string
+	txType   string
+	status   string
+	amount   int64
+	parentID string
+}
+
+// settled reports whether the transaction's money should have moved.
+func (t *engineTransaction) settled() bool {
+	return t.status == "completed" || t.status == "reversed"
+}
+
+// controlEffect returns the control-account movement a settled transaction
+// causes. Deposits and refunds bring money in, withdrawals and fees take it
+// out, transfers stay between customers and reversals undo their parent.
+func controlEffect(t *engineTransaction, byID map[string]*engineTransaction) int64 {
+	switch t.txType {
+	case "deposit", "refund":
+		return t.amount
+	case "withdrawal", "fee":
+		return -t.amount
+	case "reversal":
+		parent, ok := byID[t.parentID]
+		if !ok || parent.txType == "reversal" {
+			return 0
+		}
+		return -controlEffect(&engineTransaction{txType: parent.txType, amount: t.amount}, byID)
+	default:
+		return 0
+	}
+}
+
+// postedMovement is the control-account movement of one posted journal entry.
+type postedMovement struct {
+	entryID     string
+	referenceID string
+	net         int64
+}
+
+// ConservationReporter builds money conservation reports from the shared database.
+type ConservationReporter struct {
+	db *sql.DB
+}
+
+// NewConservationReporter creates a new conservation reporter.
+func NewConservationReporter(db *sql.DB) *ConservationReporter {
+	return &ConservationReporter{db: db}
+}
+
+// Build reports on a run that executed txIDs between start and end.
+func (c *ConservationReporter) Build(ctx context.Context, runID string, start, end time.Time, txIDs []string) *ConservationReport {
+	report := &ConservationReport{
+		RunID:        runID,
+		StartedAt:    start,
+		EndedAt:      end,
+		GeneratedAt:  time.Now(),
+		Executed:     make(map[string]*FlowTotal),
+		Contributors: make([]*ConservationContributor, 0),
+	}
+
+	txns, err := c.loadTransactions(ctx, txIDs)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	movements, err := c.loadMovements(ctx, start, end)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+
+	reconcile(report, txns, movements)
+	return report
+}
+
+// loadTransactions loads the engine's transactions and anything raised against them.
+func (c *ConservationReporter) loadTransactions(ctx context.Context, txIDs []string) ([]*engineTransaction, error) {
+	if len(txIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT id::text, type, status, amount, COALESCE(parent_transaction_id::text, '')
+		FROM transactions
+		WHERE id::text = ANY($1) OR parent_transaction_id::text = ANY($1)
+	`, pq.Array(txIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load run transactions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var txns []*engineTransaction
+	for rows.Next() {
+		t := &engineTransaction{}
+		if err := rows.Scan(&t.id, &t.txType, &t.status, &t.amount, &t.parentID); err != nil {
+			return nil, fmt.Errorf("failed to scan run transaction: %w", err)
+		}
+		txns = append(txns, t)
+	}
+	return txns, rows.Err()
+}
+
+// loadMovements sums control-account lines per journal entry posted in the window.
+func (c *ConservationReporter) loadMovements(ctx context.Context, start, end time.Time) ([]*postedMovement, error) {
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT je.id::text,
+		       CASE WHEN je.reference_type = 'transaction' THEN COALESCE(je.reference_id, '') ELSE '' END,
+		       SUM(ll.debit_amount - ll.credit_amount)
+		FROM journal_entries je
+		JOIN ledger_lines ll ON ll.entry_id = je.id
+		JOIN accounts a ON a.id = ll.account_id
+		WHERE je.status = 'posted'
+		  AND je.posted_at >= $1 AND je.posted_at <= $2
+		  AND a.code NOT LIKE 'WALLET-%'
+		GROUP BY je.id, je.reference_type, je.reference_id
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ledger movements: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var movements []*postedMovement
+	for rows.Next() {
+		m := &postedMovement{}
+		if err := rows.Scan(&m.entryID, &m.referenceID, &m.net); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger movement: %w", err)
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+// reconcile fills in totals and contributors from the loaded data.
+func reconcile(report *ConservationReport, txns []*engineTransaction, movements []*postedMovement) {
+	byID := make(map[string]*engineTransaction, len(txns))
+	for _, t := range txns {
+		byID[t.id] = t
+	}
+
+	posted := make(map[string]int64)
+	entries := make(map[string][]string)
+	var unattributed []*ConservationContributor
+	for _, m := range movements {
+		report.LedgerNet += m.net
+		if _, ok := byID[m.referenceID]; ok {
+			posted[m.referenceID] += m.net
+			entries[m.referenceID] = append(entries[m.referenceID], m.entryID)
+			continue
+		}
+		if m.net != 0 {
+			unattributed = append(unattributed, &ConservationContributor{
+				Kind:     ContributorUnattributed,
+				EntryIDs: []string{m.entryID},
+				Posted:   m.net,
+				Delta:    m.net,
+			})
+		}
+	}
+
+	var contributors []*ConservationContributor
+	for _, t := range txns {
+		var expected int64
+		if t.settled() {
+			total := report.Executed[t.txType]
+			if total == nil {
+				total = &FlowTotal{}
+				report.Executed[t.txType] = total
+			}
+			total.Count++
+			total.Amount += t.amount
+			expected = controlEffect(t, byID)
+			report.Expected += expected
+		} else {
+			report.Unsettled++
+		}
+
+		if posted[t.id] == expected {
+			continue
+		}
+		kind := ContributorAmountMismatch
+		switch {
+		case !t.settled():
+			kind = ContributorUnsettled
+		case len(entries[t.id]) == 0:
+			kind = ContributorMissingEntry
+		}
+		contributors = append(contributors, &ConservationContributor{
+			Kind:          kind,
+			TransactionID: t.id,
+			EntryIDs:      entries[t.id],
+			Type:          t.txType,
+			Status:        t.status,
+			Amount:        t.amount,
+			Expected:      expected,
+			Posted:        posted[t.id],
+			Delta:         posted[t.id] - expected,
+		})
+	}
+	contributors = append(contributors, unattributed...)
+
+	report.Delta = report.LedgerNet - report.Expected
+	report.Conserved = report.Delta == 0
+
+	// Largest deltas first so the cap keeps the transactions that matter most
+	sort.SliceStable(contributors, func(i, j int) bool {
+		return abs(contributors[i].Delta) > abs(contributors[j].Delta)
+	})
+	if len(contributors) > maxConservationContributors {
+		contributors = contributors[:maxConservationContributors]
+		report.ContributorsTruncated = true
+	}
+	report.Contributors = append(report.Contributors, contributors...)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// simulationRun tracks what one Start..Stop run of the engine executed.
+type simulationRun struct {
+	id        string
+	startedAt time.Time
+	txIDs     []string
+	manifest  *RunManifest
+}
+
+// startRun begins tracking a new run seeded with seed and returns its ID.
+func (s *SimulationEngine) startRun(seed int64) string {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	now := time.Now()
+	id := fmt.Sprintf("run-%d", now.UnixNano())
+	s.run = &simulationRun{
+		id:        id,
+		startedAt: now,
+		manifest: &RunManifest{
+			RunID:      id,
+			Seed:       seed,
+			Config:     s.config.GetView(),
+			StartedAt:  now,
+			Running:    true,
+			Operations: make([]RunOperation, 0),
+		},
+	}
+	return id
+}
+
+// recordExecuted adds a transaction created by the engine to the current run.
+func (s *SimulationEngine) recordExecuted(txID string) {
+	if txID == "" {
+		return
+	}
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.run != nil {
+		s.run.txIDs = append(s.run.txIDs, txID)
+	}
+}
+
+// finishRun ends the current run and stores its conservation report.
+func (s *SimulationEngine) finishRun(ctx context.Context) {
+	s.runMu.Lock()
+	run := s.run
+	s.run = nil
+	s.runMu.Unlock()
+	if run == nil {
+		return
+	}
+
+	endedAt := time.Now()
+	run.manifest.EndedAt = &endedAt
+	run.manifest.Running = false
+	s.storeManifest(run.manifest)
+
+	// The run may be ending because ctx was cancelled; the report still gets built
+	reportCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), conservationReportTimeout)
+	defer cancel()
+
+	report := s.conservation.Build(reportCtx, run.id, run.startedAt, time.Now(), run.txIDs)
+	switch {
+	case report.Error != "":
+		log.Printf("[simulation] Conservation report for %s failed: %s", run.id, report.Error)
+	case report.Conserved:
+		log.Printf("[simulation] ⚖️ Run %s conserved money (%d transactions, net %d paise)", run.id, len(run.txIDs), report.Expected)
+	default:
+		log.Printf("[simulation] ⚠️ Run %s has unexplained delta: expected %d, ledger %d, delta %d paise (%d contributors)",
+			run.id, report.Expected, report.LedgerNet, report.Delta, len(report.Contributors))
+	}
+
+	s.reportsMu.Lock()
+	defer s.reportsMu.Unlock()
+	s.reports = append(s.reports, report)
+	if len(s.reports) > maxConservationReports {
+		s.reports = s.reports[len(s.reports)-maxConservationReports:]
+	}
+}
+
+// ConservationReports returns the reports of finished runs, newest first.
+func (s *SimulationEngine) ConservationReports() []*ConservationReport {
+	s.reportsMu.RLock()
+	defer s.reportsMu.RUnlock()
+
+	reports := make([]*ConservationReport, 0, len(s.reports))
+	for i := len(s.reports) - 1; i >= 0; i-- {
+		reports = append(reports, s.reports[i])
+	}
+	return reports
+}
+
+// ConservationReport returns the report for a finished run.
+func (s *SimulationEngine) ConservationReport(runID string) (*ConservationReport, bool) {
+	s.reportsMu.RLock()
+	defer s.reportsMu.RUnlock()
+
+	for _, r := range s.reports {
+		if r.RunID == runID {
+			return r, true
+		}
+	}
+	return nil, false
+}
@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/retry"
 )
 
 // GatewayClient makes API calls to the Nivo Gateway
 type GatewayClient struct {
 	*clients.BaseClient
+	retry retry.Policy // Applied to calls that are safe to repeat
 }
 
 // NewGatewayClient creates a new gateway client with admin auth token
@@ -22,11 +26,31 @@ func NewGatewayClient(baseURL, authToken string) *GatewayClient {
 			"Authorization": "Bearer " + authToken,
 		}
 	}
+	retryPolicy := retry.DefaultPolicy()
+	retryPolicy.Retryable = clients.IsRetryable
+	retryPolicy.OnRetry = func(attempt int, err error, delay time.Duration) {
+		log.Printf("[simulation] Gateway call failed (attempt %d), retrying in %v: %v", attempt, delay, err)
+	}
+
 	return &GatewayClient{
 		BaseClient: clients.NewBaseClientWithHeaders(baseURL, clients.DefaultTimeout, headers),
+		retry:      retryPolicy,
 	}
 }
 
+// withRetry runs an idempotent gateway call, retrying while the gateway or
+// the service behind it is unreachable. Calls that create resources are not
+// retried, since a lost response could otherwise create them twice.
+func (c *GatewayClient) withRetry(ctx context.Context, call func(ctx context.Context) *errors.Error) error {
+	return retry.Do(ctx, c.retry, func(ctx context.Context) error {
+		// Avoid returning a typed nil *errors.Error as a non-nil error
+		if err := call(ctx); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
 // DepositRequest represents a deposit transaction request
 type DepositRequest struct {
 	WalletID    string `json:"wallet_id"`
@@ -52,6 +76,13 @@ type WithdrawalRequest struct {
 	Description string `json:"description"`
 }
 
+// TransactionResponse is the part of a transaction the engine keeps.
+type TransactionResponse struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	FailureReason string `json:"failure_reason,omitempty"`
+}
+
 // RegisterRequest represents a user registration request
 type RegisterRequest struct {
 	Email    string `json:"email"`
@@ -117,9 +148,9 @@ func bearerToken(token string) map[string]string {
 	return map[string]string{"Authorization": "Bearer " + token}
 }
 
-// CreateDeposit creates a deposit transaction.
+// CreateDeposit creates a deposit transaction and returns its ID.
 // If token is provided, it's used for auth. Otherwise, falls back to client's default auth headers.
-func (c *GatewayClient) CreateDeposit(ctx context.Context, token, walletID string, amountPaise int64, description string) error {
+func (c *GatewayClient) CreateDeposit(ctx context.Context, token, walletID string, amountPaise int64, description string) (string, error) {
 	req := DepositRequest{
 		WalletID:    walletID,
 		Amount:      amountPaise,
@@ -127,22 +158,24 @@ func (c *GatewayClient) CreateDeposit(ctx context.Context, token, walletID strin
 		Description: description,
 	}
 
+	var created TransactionResponse
+
 	// Use typed error to avoid nil interface gotcha
 	if token != "" {
-		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/deposit", req, nil, bearerToken(token)); err != nil {
-			return err
+		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/deposit", req, &created, bearerToken(token)); err != nil {
+			return "", err
 		}
 	} else {
-		if err := c.Post(ctx, "/api/v1/transaction/transactions/deposit", req, nil); err != nil {
-			return err
+		if err := c.Post(ctx, "/api/v1/transaction/transactions/deposit", req, &created); err != nil {
+			return "", err
 		}
 	}
-	return nil
+	return created.ID, nil
 }
 
-// CreateTransfer creates a transfer transaction.
+// CreateTransfer creates a transfer transaction and returns its ID.
 // If token is provided, it's used for auth. Otherwise, falls back to client's default auth headers.
-func (c *GatewayClient) CreateTransfer(ctx context.Context, token, sourceWalletID, destWalletID string, amountPaise int64, description string) error {
+func (c *GatewayClient) CreateTransfer(ctx context.Context, token, sourceWalletID, destWalletID string, amountPaise int64, description string) (string, error) {
 	req := TransferRequest{
 		SourceWalletID:      sourceWalletID,
 		DestinationWalletID: destWalletID,
@@ -151,22 +184,24 @@ func (c *GatewayClient) CreateTransfer(ctx context.Context, token, sourceWalletI
 		Description:         description,
 	}
 
+	var created TransactionResponse
+
 	// Use typed error to avoid nil interface gotcha
 	if token != "" {
-		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/transfer", req, nil, bearerToken(token)); err != nil {
-			return err
+		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/transfer", req, &created, bearerToken(token)); err != nil {
+			return "", err
 		}
 	} else {
-		if err := c.Post(ctx, "/api/v1/transaction/transactions/transfer", req, nil); err != nil {
-			return err
+		if err := c.Post(ctx, "/api/v1/transaction/transactions/transfer", req, &created); err != nil {
+			return "", err
 		}
 	}
-	return nil
+	return created.ID, nil
 }
 
-// CreateWithdrawal creates a withdrawal transaction.
+// CreateWithdrawal creates a withdrawal transaction and returns its ID.
 // If token is provided, it's used for auth. Otherwise, falls back to client's default auth headers.
-func (c *GatewayClient) CreateWithdrawal(ctx context.Context, token, walletID string, amountPaise int64, description string) error {
+func (c *GatewayClient) CreateWithdrawal(ctx context.Context, token, walletID string, amountPaise int64, description string) (string, error) {
 	req := WithdrawalRequest{
 		WalletID:    walletID,
 		Amount:      amountPaise,
@@ -174,17 +209,127 @@ func (c *GatewayClient) CreateWithdrawal(ctx context.Context, token, walletID st
 		Description: description,
 	}
 
+	var created TransactionResponse
+
 	// Use typed error to avoid nil interface gotcha
 	if token != "" {
-		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/withdrawal", req, nil, bearerToken(token)); err != nil {
-			return err
+		if err := c.PostWithHeaders(ctx, "/api/v1/transaction/transactions/withdrawal", req, &created, bearerToken(token)); err != nil {
+			return "", err
 		}
 	} else {
-		if err := c.Post(ctx, "/api/v1/transaction/transactions/withdrawal", req, nil); err != nil {
-			return err
+		if err := c.Post(ctx, "/api/v1/transaction/transactions/withdrawal", req, &created); err != nil {
+			return "", err
 		}
 	}
-	return nil
+	return created.ID, nil
+}
+
+// GetTransaction fetches a transaction with the client's admin auth.
+func (c *GatewayClient) GetTransaction(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	path := fmt.Sprintf("/api/v1/transaction/transactions/%s", transactionID)
+
+	var tx TransactionResponse
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Get(ctx, path, &tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// WalletBalanceResponse is a wallet's balance.
+type WalletBalanceResponse struct {
+	WalletID         string `json:"wallet_id"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+}
+
+// GetWalletBalance fetches a wallet's balance with the client's admin auth.
+func (c *GatewayClient) GetWalletBalance(ctx context.Context, walletID string) (*WalletBalanceResponse, error) {
+	// Route: /api/v1/wallet/wallets/:id/balance -> wallet service's /api/v1/wallets/:id/balance
+	path := fmt.Sprintf("/api/v1/wallet/wallets/%s/balance", walletID)
+
+	var balance WalletBalanceResponse
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Get(ctx, path, &balance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// BalanceMismatch is a wallet whose balance differs from its ledger account.
+type BalanceMismatch struct {
+	WalletID      string `json:"wallet_id"`
+	WalletBalance int64  `json:"wallet_balance"`
+	LedgerBalance int64  `json:"ledger_balance"`
+	Difference    int64  `json:"difference"`
+}
+
+// ReconciliationReport is the wallet service's latest wallet to ledger
+// balance reconciliation.
+type ReconciliationReport struct {
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt time.Time         `json:"completed_at"`
+	Checked     int               `json:"checked"`
+	Healthy     bool              `json:"healthy"`
+	Mismatches  []BalanceMismatch `json:"mismatches"`
+	Truncated   bool              `json:"truncated"` // Mismatches is capped; some were left out
+}
+
+// GetReconciliationReport fetches the latest wallet to ledger reconciliation
+// with the client's admin auth. It returns a not found error until the first
+// reconciliation has completed.
+func (c *GatewayClient) GetReconciliationReport(ctx context.Context) (*ReconciliationReport, error) {
+	// Route: /api/v1/wallet/admin/wallets/reconciliation -> wallet service's /api/v1/admin/wallets/reconciliation
+	var report ReconciliationReport
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Get(ctx, "/api/v1/wallet/admin/wallets/reconciliation", &report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// LedgerReference is a transaction the ledger should have posted, for the
+// amount expected.
+type LedgerReference struct {
+	ReferenceID string `json:"reference_id"`
+	Amount      int64  `json:"amount"`
+}
+
+// LedgerVerification is the ledger's finding for one reference: "matched"
+// when exactly one posted, balanced entry has the expected amount.
+type LedgerVerification struct {
+	ReferenceID  string `json:"reference_id"`
+	Status       string `json:"status"`
+	PostedAmount int64  `json:"posted_amount"`
+	Detail       string `json:"detail,omitempty"`
+}
+
+// VerifyLedgerReferences asks the ledger whether transactions were posted
+// for the amounts expected.
+func (c *GatewayClient) VerifyLedgerReferences(ctx context.Context, references []LedgerReference) ([]LedgerVerification, error) {
+	// Route: /api/v1/ledger/verify/references -> ledger service's /api/v1/verify/references
+	req := struct {
+		ReferenceType string            `json:"reference_type"`
+		References    []LedgerReference `json:"references"`
+	}{ReferenceType: "transaction", References: references}
+
+	var report struct {
+		Results []LedgerVerification `json:"results"`
+	}
+	// Verification only reads the ledger, so it is safe to repeat
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Post(ctx, "/api/v1/ledger/verify/references", req, &report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report.Results, nil
 }
 
 // RegisterUser creates a new user account
@@ -213,7 +358,10 @@ func (c *GatewayClient) Login(ctx context.Context, identifier, password string)
 	}
 
 	var resp LoginResponse
-	if err := c.Post(ctx, "/api/v1/auth/login", req, &resp); err != nil {
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Post(ctx, "/api/v1/auth/login", req, &resp)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -234,7 +382,10 @@ func (c *GatewayClient) Logout(ctx context.Context, token string) error {
 func (c *GatewayClient) SubmitKYC(ctx context.Context, token string, kycReq KYCSubmitRequest) error {
 	// Route: /api/v1/identity/auth/kyc -> identity service's /api/v1/auth/kyc
 	// Uses PUT method per identity service API
-	if err := c.PutWithHeaders(ctx, "/api/v1/identity/auth/kyc", kycReq, nil, bearerToken(token)); err != nil {
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.PutWithHeaders(ctx, "/api/v1/identity/auth/kyc", kycReq, nil, bearerToken(token))
+	})
+	if err != nil {
 		return err
 	}
 	log.Printf("[simulation] ✓ KYC submitted")
@@ -244,13 +395,42 @@ func (c *GatewayClient) SubmitKYC(ctx context.Context, token string, kycReq KYCS
 // VerifyKYC admin endpoint to verify KYC (requires admin token)
 func (c *GatewayClient) VerifyKYC(ctx context.Context, userID string) error {
 	path := fmt.Sprintf("/api/v1/admin/kyc/%s/verify", userID)
-	if err := c.Post(ctx, path, nil, nil); err != nil {
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.Post(ctx, path, nil, nil)
+	})
+	if err != nil {
 		return err
 	}
 	log.Printf("[simulation] ✓ KYC verified for user %s", userID)
 	return nil
 }
 
+// reasonRequest is the body of admin actions that require a reason.
+type reasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SuspendUser admin endpoint to suspend a user (requires admin token)
+func (c *GatewayClient) SuspendUser(ctx context.Context, userID, reason string) error {
+	path := fmt.Sprintf("/api/v1/identity/admin/users/%s/suspend", userID)
+	if err := c.Post(ctx, path, reasonRequest{Reason: reason}, nil); err != nil {
+		return err
+	}
+	log.Printf("[simulation] ✓ User suspended: %s", userID)
+	return nil
+}
+
+// CloseWallet admin endpoint to close an empty wallet (requires admin token)
+func (c *GatewayClient) CloseWallet(ctx context.Context, walletID, reason string) error {
+	// Route: /api/v1/wallet/wallets/:id/close -> wallet service's /api/v1/wallets/:id/close
+	path := fmt.Sprintf("/api/v1/wallet/wallets/%s/close", walletID)
+	if err := c.Post(ctx, path, reasonRequest{Reason: reason}, nil); err != nil {
+		return err
+	}
+	log.Printf("[simulation] ✓ Wallet closed: %s", walletID)
+	return nil
+}
+
 // GetUserWallet fetches the wallet for a given user
 func (c *GatewayClient) GetUserWallet(ctx context.Context, token, userID string) (*WalletResponse, error) {
 	// Route: /api/v1/wallet/users/:userID/wallets -> wallet service's /api/v1/users/:userID/wallets
@@ -258,7 +438,10 @@ func (c *GatewayClient) GetUserWallet(ctx context.Context, token, userID string)
 
 	// This endpoint can return array or single wallet, so we parse as raw JSON first
 	var rawResponse json.RawMessage
-	if err := c.GetWithHeaders(ctx, path, &rawResponse, bearerToken(token)); err != nil {
+	err := c.withRetry(ctx, func(ctx context.Context) *errors.Error {
+		return c.GetWithHeaders(ctx, path, &rawResponse, bearerToken(token))
+	})
+	if err != nil {
 		return nil, err
 	}
 
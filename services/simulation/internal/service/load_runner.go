@@ -0,0 +1,664 @@
+//nolint:gosec // G404: math/rand acceptable for picking wallets and sampling latencies
+package service
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// LoadStatus tracks the lifecycle of a load run.
+type LoadStatus string
+
+const (
+	LoadStatusRunning   LoadStatus = "running"
+	LoadStatusCompleted LoadStatus = "completed"
+	LoadStatusStopped   LoadStatus = "stopped"
+)
+
+const (
+	// MaxLoadTPS bounds the rate any stage may target.
+	MaxLoadTPS = 500
+	// MaxLoadDuration bounds the total length of a load run.
+	MaxLoadDuration = 30 * time.Minute
+	// MaxLoadWorkers bounds the worker pool size.
+	MaxLoadWorkers = 200
+
+	// loadTickInterval is how often the scheduler releases due requests.
+	loadTickInterval = 10 * time.Millisecond
+	// latencySampleSize is the per-stage reservoir used for percentiles.
+	latencySampleSize = 10000
+	// loadAmountPaise is the amount of every load transaction (₹1.00).
+	loadAmountPaise int64 = 100
+	maxLoadReports        = 20
+)
+
+// ErrLoadRunning is returned when a load run is started while one is active.
+var ErrLoadRunning = stderrors.New("a load run is already in progress")
+
+// LoadProfile describes a load run: a linear warmup from zero to the target
+// rate, a steady phase at the target, then a spike at a multiple of it.
+// Phases with zero duration are skipped.
+type LoadProfile struct {
+	TargetTPS       float64 `json:"target_tps"`
+	WarmupSeconds   int     `json:"warmup_seconds"`
+	SteadySeconds   int     `json:"steady_seconds"`
+	SpikeSeconds    int     `json:"spike_seconds"`
+	SpikeMultiplier float64 `json:"spike_multiplier"`
+	Workers         int     `json:"workers"`
+	TransferPercent int     `json:"transfer_percent"` // Share of transfers, the rest are deposits
+}
+
+// loadStage is one phase of a profile, ramping linearly from startTPS to endTPS.
+type loadStage struct {
+	name     string
+	duration time.Duration
+	startTPS float64
+	endTPS   float64
+}
+
+// DefaultLoadProfile returns a short profile around the given target rate.
+func DefaultLoadProfile(targetTPS float64) LoadProfile {
+	return LoadProfile{
+		TargetTPS:       targetTPS,
+		WarmupSeconds:   30,
+		SteadySeconds:   60,
+		SpikeSeconds:    15,
+		SpikeMultiplier: 3,
+		Workers:         20,
+		TransferPercent: 50,
+	}
+}
+
+// Validate checks the profile against the load limits.
+func (p LoadProfile) Validate() error {
+	if p.TargetTPS <= 0 {
+		return fmt.Errorf("target_tps must be positive")
+	}
+	if p.WarmupSeconds < 0 || p.SteadySeconds < 0 || p.SpikeSeconds < 0 {
+		return fmt.Errorf("phase durations must be non-negative")
+	}
+	if p.WarmupSeconds+p.SteadySeconds+p.SpikeSeconds == 0 {
+		return fmt.Errorf("at least one phase must have a duration")
+	}
+	if p.SpikeSeconds > 0 && p.SpikeMultiplier < 1 {
+		return fmt.Errorf("spike_multiplier must be at least 1")
+	}
+	if peak := p.peakTPS(); peak > MaxLoadTPS {
+		return fmt.Errorf("peak rate %.0f TPS exceeds the %d TPS limit", peak, MaxLoadTPS)
+	}
+	if p.duration() > MaxLoadDuration {
+		return fmt.Errorf("run length exceeds %s", MaxLoadDuration)
+	}
+	if p.Workers < 1 || p.Workers > MaxLoadWorkers {
+		return fmt.Errorf("workers must be between 1 and %d", MaxLoadWorkers)
+	}
+	if p.TransferPercent < 0 || p.TransferPercent > 100 {
+		return fmt.Errorf("transfer_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+func (p LoadProfile) peakTPS() float64 {
+	if p.SpikeSeconds > 0 {
+		return p.TargetTPS * p.SpikeMultiplier
+	}
+	return p.TargetTPS
+}
+
+func (p LoadProfile) duration() time.Duration {
+	return time.Duration(p.WarmupSeconds+p.SteadySeconds+p.SpikeSeconds) * time.Second
+}
+
+// stages expands the profile into its non-empty phases.
+func (p LoadProfile) stages() []loadStage {
+	all := []loadStage{
+		{"warmup", time.Duration(p.WarmupSeconds) * time.Second, 0, p.TargetTPS},
+		{"steady", time.Duration(p.SteadySeconds) * time.Second, p.TargetTPS, p.TargetTPS},
+		{"spike", time.Duration(p.SpikeSeconds) * time.Second, p.TargetTPS * p.SpikeMultiplier, p.TargetTPS * p.SpikeMultiplier},
+	}
+	stages := make([]loadStage, 0, len(all))
+	for _, s := range all {
+		if s.duration > 0 {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
+
+// scheduledBy returns how many requests the stage has scheduled after t,
+// the integral of its linear rate.
+func (s loadStage) scheduledBy(t time.Duration) float64 {
+	if t > s.duration {
+		t = s.duration
+	}
+	secs := t.Seconds()
+	return s.startTPS*secs + (s.endTPS-s.startTPS)*secs*secs/(2*s.duration.Seconds())
+}
+
+// LatencySummary reports client-observed request latencies in milliseconds.
+type LatencySummary struct {
+	P50  float64 `json:"p50_ms"`
+	P90  float64 `json:"p90_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+	Mean float64 `json:"mean_ms"`
+}
+
+// LoadStageReport summarises one phase of a load run.
+type LoadStageReport struct {
+	Name            string           `json:"name"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	TargetTPS       float64          `json:"target_tps"` // Average rate the phase scheduled
+	AchievedTPS     float64          `json:"achieved_tps"`
+	Sent            int64            `json:"sent"`
+	Succeeded       int64            `json:"succeeded"`
+	Failed          int64            `json:"failed"`
+	Skipped         int64            `json:"skipped"` // Due while every worker was busy
+	Latency         LatencySummary   `json:"latency"`
+	Errors          map[string]int64 `json:"errors"`
+}
+
+// LoadReport is the result of a load run; while running it is a live snapshot.
+type LoadReport struct {
+	ID          string            `json:"id"`
+	Status      LoadStatus        `json:"status"`
+	Profile     LoadProfile       `json:"profile"`
+	Wallets     int               `json:"wallets"`
+	StartedAt   time.Time         `json:"started_at"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	Sent        int64             `json:"sent"`
+	Succeeded   int64             `json:"succeeded"`
+	Failed      int64             `json:"failed"`
+	Skipped     int64             `json:"skipped"`
+	AchievedTPS float64           `json:"achieved_tps"`
+	Latency     LatencySummary    `json:"latency"`
+	Errors      map[string]int64  `json:"errors"` // Failures by error code
+	Stages      []LoadStageReport `json:"stages"`
+}
+
+// stageStats accumulates results for one stage.
+type stageStats struct {
+	started   time.Time
+	ended     time.Time
+	sent      int64
+	succeeded int64
+	failed    int64
+	skipped   int64
+	errors    map[string]int64
+	latency   latencyReservoir
+}
+
+// latencyReservoir keeps a uniform sample of latencies plus exact max and mean.
+type latencyReservoir struct {
+	samples []time.Duration
+	count   int64
+	total   time.Duration
+	max     time.Duration
+}
+
+func (l *latencyReservoir) add(d time.Duration, rng *rand.Rand) {
+	l.count++
+	l.total += d
+	if d > l.max {
+		l.max = d
+	}
+	if len(l.samples) < latencySampleSize {
+		l.samples = append(l.samples, d)
+		return
+	}
+	if i := rng.Int63n(l.count); i < latencySampleSize {
+		l.samples[i] = d
+	}
+}
+
+func (l *latencyReservoir) summary() LatencySummary {
+	if l.count == 0 {
+		return LatencySummary{}
+	}
+	sorted := make([]time.Duration, len(l.samples))
+	copy(sorted, l.samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return LatencySummary{
+		P50:  ms(percentile(sorted, 50)),
+		P90:  ms(percentile(sorted, 90)),
+		P95:  ms(percentile(sorted, 95)),
+		P99:  ms(percentile(sorted, 99)),
+		Max:  ms(l.max),
+		Mean: ms(l.total / time.Duration(l.count)),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func ms(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
+
+// loadRun is the mutable state of one load run.
+type loadRun struct {
+	mu      sync.Mutex
+	report  LoadReport
+	stages  []loadStage
+	stats   []*stageStats
+	overall latencyReservoir
+	rng     *rand.Rand
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// loadJob is one scheduled request.
+type loadJob struct {
+	stage int
+}
+
+// LoadRunner drives a target transaction rate against the gateway using a
+// worker pool, following a warmup/steady/spike profile. One run may be active
+// at a time; finished reports are kept in memory.
+type LoadRunner struct {
+	db            *sql.DB
+	gatewayClient *GatewayClient
+
+	mu      sync.RWMutex
+	current *loadRun
+	reports []*LoadReport
+}
+
+// NewLoadRunner creates a new load runner.
+func NewLoadRunner(db *sql.DB, gatewayClient *GatewayClient) *LoadRunner {
+	return &LoadRunner{
+		db:            db,
+		gatewayClient: gatewayClient,
+	}
+}
+
+// Start begins a load run in the background and returns its initial report.
+func (l *LoadRunner) Start(ctx context.Context, profile LoadProfile) (*LoadReport, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	if l.current != nil {
+		l.mu.Unlock()
+		return nil, ErrLoadRunning
+	}
+	// Reserve the slot before the wallet query so concurrent starts conflict
+	run := &loadRun{done: make(chan struct{})}
+	l.current = run
+	l.mu.Unlock()
+
+	wallets, err := l.loadWallets(ctx)
+	if err == nil && len(wallets) == 0 {
+		err = fmt.Errorf("no active wallets to run load against")
+	}
+	if err != nil {
+		l.mu.Lock()
+		l.current = nil
+		l.mu.Unlock()
+		close(run.done)
+		return nil, err
+	}
+
+	stages := profile.stages()
+	run.stages = stages
+	run.stats = make([]*stageStats, len(stages))
+	for i := range stages {
+		run.stats[i] = &stageStats{errors: make(map[string]int64)}
+	}
+	run.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	run.report = LoadReport{
+		ID:        fmt.Sprintf("load-%d", time.Now().UnixNano()),
+		Status:    LoadStatusRunning,
+		Profile:   profile,
+		Wallets:   len(wallets),
+		StartedAt: time.Now(),
+	}
+
+	// The run outlives the HTTP request that started it
+	runCtx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	run.cancel = cancel
+	l.mu.Unlock()
+
+	log.Printf("[simulation] Starting load run %s (target=%.1f TPS, peak=%.1f TPS, workers=%d, wallets=%d)",
+		run.report.ID, profile.TargetTPS, profile.peakTPS(), profile.Workers, len(wallets))
+
+	go l.execute(runCtx, run, profile, wallets)
+
+	return run.snapshot(), nil
+}
+
+// Stop cancels the active run. It reports false if no run is active.
+func (l *LoadRunner) Stop() bool {
+	l.mu.RLock()
+	run := l.current
+	l.mu.RUnlock()
+	if run == nil || run.cancel == nil {
+		return false
+	}
+	run.cancel()
+	return true
+}
+
+// Wait blocks until the active run has finished or ctx expires.
+func (l *LoadRunner) Wait(ctx context.Context) error {
+	l.mu.RLock()
+	run := l.current
+	l.mu.RUnlock()
+	if run == nil {
+		return nil
+	}
+
+	select {
+	case <-run.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Current returns a live snapshot of the active run, if any.
+func (l *LoadRunner) Current() (*LoadReport, bool) {
+	l.mu.RLock()
+	run := l.current
+	l.mu.RUnlock()
+	if run == nil || run.cancel == nil {
+		return nil, false
+	}
+	return run.snapshot(), true
+}
+
+// Reports returns the active run followed by finished runs, newest first.
+func (l *LoadRunner) Reports() []*LoadReport {
+	reports := make([]*LoadReport, 0, maxLoadReports+1)
+	if current, ok := l.Current(); ok {
+		reports = append(reports, current)
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for i := len(l.reports) - 1; i >= 0; i-- {
+		reports = append(reports, l.reports[i])
+	}
+	return reports
+}
+
+// Report returns the report of the run with the given ID.
+func (l *LoadRunner) Report(id string) (*LoadReport, bool) {
+	for _, report := range l.Reports() {
+		if report.ID == id {
+			return report, true
+		}
+	}
+	return nil, false
+}
+
+// execute runs the scheduler and worker pool, then files the final report.
+func (l *LoadRunner) execute(ctx context.Context, run *loadRun, profile LoadProfile, wallets []string) {
+	defer close(run.done)
+	defer run.cancel()
+
+	jobs := make(chan loadJob, profile.Workers)
+	var workers sync.WaitGroup
+	for i := 0; i < profile.Workers; i++ {
+		workers.Add(1)
+		go func(seed int64) {
+			defer workers.Done()
+			l.work(ctx, run, profile.TransferPercent, wallets, jobs, rand.New(rand.NewSource(seed)))
+		}(time.Now().UnixNano() + int64(i))
+	}
+
+	stopped := l.schedule(ctx, run, jobs)
+	close(jobs)
+	workers.Wait()
+
+	run.finish(stopped)
+
+	l.mu.Lock()
+	l.reports = append(l.reports, run.snapshot())
+	if len(l.reports) > maxLoadReports {
+		l.reports = l.reports[len(l.reports)-maxLoadReports:]
+	}
+	l.current = nil
+	l.mu.Unlock()
+
+	report := run.snapshot()
+	log.Printf("[simulation] Load run %s %s: sent=%d failed=%d skipped=%d achieved=%.1f TPS p95=%.1fms",
+		report.ID, report.Status, report.Sent, report.Failed, report.Skipped, report.AchievedTPS, report.Latency.P95)
+}
+
+// schedule releases requests at the profile's rate until the last stage ends
+// or ctx is cancelled, which it reports as stopped. Requests due while every
+// worker is busy are skipped rather than queued, so a slow gateway shows up
+// as a shortfall instead of a burst later on.
+func (l *LoadRunner) schedule(ctx context.Context, run *loadRun, jobs chan<- loadJob) bool {
+	ticker := time.NewTicker(loadTickInterval)
+	defer ticker.Stop()
+
+	for i, stage := range run.stages {
+		start := time.Now()
+		run.startStage(i, start)
+		released := 0
+
+		for {
+			elapsed := time.Since(start)
+			due := int(stage.scheduledBy(elapsed)) - released
+			for ; due > 0; due-- {
+				select {
+				case jobs <- loadJob{stage: i}:
+				default:
+					run.skip(i)
+				}
+				released++
+			}
+
+			if elapsed >= stage.duration {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+				run.endStage(i, time.Now())
+				return true
+			case <-ticker.C:
+			}
+		}
+		run.endStage(i, time.Now())
+	}
+	return false
+}
+
+// work executes jobs until the channel is closed.
+func (l *LoadRunner) work(ctx context.Context, run *loadRun, transferPercent int, wallets []string, jobs <-chan loadJob, rng *rand.Rand) {
+	for job := range jobs {
+		if ctx.Err() != nil {
+			continue // Drain without sending once stopped
+		}
+
+		started := time.Now()
+		var err error
+		source := wallets[rng.Intn(len(wallets))]
+		if len(wallets) > 1 && rng.Intn(100) < transferPercent {
+			dest := wallets[rng.Intn(len(wallets))]
+			for dest == source {
+				dest = wallets[rng.Intn(len(wallets))]
+			}
+			_, err = l.gatewayClient.CreateTransfer(ctx, "", source, dest, loadAmountPaise, "Load test transfer")
+		} else {
+			_, err = l.gatewayClient.CreateDeposit(ctx, "", source, loadAmountPaise, "Load test deposit")
+		}
+
+		run.record(job.stage, time.Since(started), err)
+	}
+}
+
+// loadWallets returns the IDs of active wallets owned by active users.
+func (l *LoadRunner) loadWallets(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT w.id
+		FROM wallets w
+		INNER JOIN users u ON u.id = w.user_id
+		WHERE u.status = 'active' AND w.status = 'active'
+		ORDER BY w.created_at DESC
+		LIMIT 100
+	`
+
+	rows, err := l.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query wallets: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var wallets []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet: %w", err)
+		}
+		wallets = append(wallets, id)
+	}
+	return wallets, rows.Err()
+}
+
+// loadErrorCode classifies a failed request for the error breakdown.
+func loadErrorCode(err error) string {
+	var apiErr *errors.Error
+	switch {
+	case stderrors.As(err, &apiErr):
+		return string(apiErr.Code)
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return string(errors.ErrCodeTimeout)
+	case stderrors.Is(err, context.Canceled):
+		return "CANCELED"
+	default:
+		return "NETWORK_ERROR"
+	}
+}
+
+func (r *loadRun) startStage(i int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[i].started = at
+}
+
+func (r *loadRun) endStage(i int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[i].ended = at
+}
+
+func (r *loadRun) skip(i int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats[i].skipped++
+}
+
+func (r *loadRun) record(i int, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats[i]
+	stats.sent++
+	stats.latency.add(latency, r.rng)
+	r.overall.add(latency, r.rng)
+	if err != nil {
+		stats.failed++
+		stats.errors[loadErrorCode(err)]++
+		return
+	}
+	stats.succeeded++
+}
+
+func (r *loadRun) finish(stopped bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.report.FinishedAt = &now
+	r.report.Status = LoadStatusCompleted
+	if stopped {
+		r.report.Status = LoadStatusStopped
+	}
+}
+
+// snapshot builds the report from the stats gathered so far.
+func (r *loadRun) snapshot() *LoadReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := r.report
+	report.Errors = make(map[string]int64)
+	report.Stages = make([]LoadStageReport, 0, len(r.stages))
+	report.Sent, report.Succeeded, report.Failed, report.Skipped = 0, 0, 0, 0
+
+	now := time.Now()
+	for i, stage := range r.stages {
+		stats := r.stats[i]
+		if stats.started.IsZero() {
+			continue // Not reached yet
+		}
+		end := stats.ended
+		if end.IsZero() {
+			end = now
+		}
+		elapsed := end.Sub(stats.started).Seconds()
+
+		stageReport := LoadStageReport{
+			Name:            stage.name,
+			DurationSeconds: math.Round(elapsed*100) / 100,
+			TargetTPS:       (stage.startTPS + stage.endTPS) / 2,
+			Sent:            stats.sent,
+			Succeeded:       stats.succeeded,
+			Failed:          stats.failed,
+			Skipped:         stats.skipped,
+			Latency:         stats.latency.summary(),
+			Errors:          make(map[string]int64, len(stats.errors)),
+		}
+		if elapsed > 0 {
+			stageReport.AchievedTPS = math.Round(float64(stats.sent)/elapsed*100) / 100
+		}
+		for code, n := range stats.errors {
+			stageReport.Errors[code] = n
+			report.Errors[code] += n
+		}
+		report.Stages = append(report.Stages, stageReport)
+
+		report.Sent += stats.sent
+		report.Succeeded += stats.succeeded
+		report.Failed += stats.failed
+		report.Skipped += stats.skipped
+	}
+
+	end := now
+	if report.FinishedAt != nil {
+		end = *report.FinishedAt
+	}
+	if elapsed := end.Sub(report.StartedAt).Seconds(); elapsed > 0 {
+		report.AchievedTPS = math.Round(float64(report.Sent)/elapsed*100) / 100
+	}
+	report.Latency = r.overall.summary()
+
+	return &report
+}
@@ -0,0 +1,212 @@
+//nolint:gosec // G404: math/rand acceptable for simulation seeds
+package service
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/config"
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/personas"
+)
+
+const (
+	// maxRunManifests is how many finished runs keep their manifest.
+	maxRunManifests = 20
+	// maxManifestOperations caps the operations listed in one manifest.
+	maxManifestOperations = 10000
+	// maxSeed keeps generated seeds exact in JSON clients that read numbers as doubles.
+	maxSeed = 1 << 53
+)
+
+// OperationKind is what the engine did in one recorded operation.
+type OperationKind string
+
+const (
+	OpUserLoaded      OperationKind = "user_loaded"    // Existing user picked up from the database
+	OpUserGenerated   OperationKind = "user_generated" // New simulated user generated
+	OpUserRegistered  OperationKind = "user_registered"
+	OpKYCSubmitted    OperationKind = "kyc_submitted"
+	OpKYCVerified     OperationKind = "kyc_verified"
+	OpUserLoggedIn    OperationKind = "user_logged_in"
+	OpDeposit         OperationKind = "deposit"
+	OpTransfer        OperationKind = "transfer"
+	OpWithdrawal      OperationKind = "withdrawal"
+	OpUserDormant     OperationKind = "user_dormant"
+	OpUserReactivated OperationKind = "user_reactivated"
+	OpUserSuspended   OperationKind = "user_suspended"
+	OpWalletClosed    OperationKind = "wallet_closed"
+	OpConfigChanged   OperationKind = "config_changed"   // Configuration updated during the run
+	OpAssertionFailed OperationKind = "assertion_failed" // An end-to-end check after an operation did not hold
+)
+
+// OperationOutcome is how a recorded operation ended.
+type OperationOutcome string
+
+const (
+	OutcomeSucceeded       OperationOutcome = "succeeded"
+	OutcomeFailed          OperationOutcome = "failed"
+	OutcomeInjectedFailure OperationOutcome = "injected_failure" // Failed by the behavior injector, never sent
+	OutcomeSkipped         OperationOutcome = "skipped"
+)
+
+// RunOperation is one operation the engine performed during a run.
+type RunOperation struct {
+	Seq           int                  `json:"seq"`
+	At            time.Time            `json:"at"`
+	Kind          OperationKind        `json:"kind"`
+	Outcome       OperationOutcome     `json:"outcome"`
+	User          string               `json:"user,omitempty"` // Email
+	Persona       personas.PersonaType `json:"persona,omitempty"`
+	Amount        int64                `json:"amount,omitempty"`    // Paise
+	Recipient     string               `json:"recipient,omitempty"` // Wallet ID
+	TransactionID string               `json:"transaction_id,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	Config        *config.ConfigView   `json:"config,omitempty"` // Set for config_changed
+	Check         string               `json:"check,omitempty"`  // Set for assertion_failed
+}
+
+// RunManifest describes a simulation run well enough to replay it: starting
+// the engine with the same seed and configuration makes the same random
+// choices. Outcomes that depend on the wall clock or on the services, such
+// as which personas are active this hour or whether a transfer succeeds, are
+// recorded in Operations so a replay can be compared against them.
+type RunManifest struct {
+	RunID     string            `json:"run_id"`
+	Seed      int64             `json:"seed"`
+	Config    config.ConfigView `json:"config"` // Configuration at start
+	StartedAt time.Time         `json:"started_at"`
+	EndedAt   *time.Time        `json:"ended_at,omitempty"`
+	Running   bool              `json:"running"`
+
+	OperationCount      int            `json:"operation_count"`
+	Operations          []RunOperation `json:"operations"`
+	OperationsTruncated bool           `json:"operations_truncated,omitempty"`
+}
+
+// NewSeed returns a random run seed.
+func NewSeed() int64 {
+	return rand.New(rand.NewSource(time.Now().UnixNano())).Int63n(maxSeed)
+}
+
+// streamSeed derives the seed of one random stream from a run seed. Each
+// component gets its own stream so goroutines drawing concurrently cannot
+// change each other's sequence.
+func streamSeed(seed int64, stream string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(stream))
+	return seed ^ int64(h.Sum64()>>1)
+}
+
+// transactionOperation maps a transaction type to its operation kind.
+func transactionOperation(txType string) OperationKind {
+	switch txType {
+	case "deposit":
+		return OpDeposit
+	case "transfer":
+		return OpTransfer
+	case "withdrawal":
+		return OpWithdrawal
+	default:
+		return OperationKind(txType)
+	}
+}
+
+// reseed restarts every random stream of the engine from seed.
+func (s *SimulationEngine) reseed(seed int64) {
+	s.rngMu.Lock()
+	s.rng = rand.New(rand.NewSource(streamSeed(seed, "engine")))
+	s.rngMu.Unlock()
+
+	s.lifecycleManager.Reseed(streamSeed(seed, "users"))
+	s.injector.Reseed(streamSeed(seed, "behavior"))
+	s.autoVerifier.Reseed(streamSeed(seed, "verification"))
+}
+
+// recordOperation appends an operation to the current run's manifest.
+func (s *SimulationEngine) recordOperation(op RunOperation) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.run == nil {
+		return
+	}
+
+	m := s.run.manifest
+	m.OperationCount++
+	if len(m.Operations) >= maxManifestOperations {
+		m.OperationsTruncated = true
+		return
+	}
+	op.Seq = m.OperationCount
+	op.At = time.Now()
+	m.Operations = append(m.Operations, op)
+}
+
+// recordOutcome records an operation that succeeded unless err is set.
+func (s *SimulationEngine) recordOutcome(op RunOperation, err error) {
+	op.Outcome = OutcomeSucceeded
+	if err != nil {
+		op.Outcome = OutcomeFailed
+		op.Error = err.Error()
+	}
+	s.recordOperation(op)
+}
+
+// recordSkipped records an operation the engine chose not to send.
+func (s *SimulationEngine) recordSkipped(op RunOperation, reason string) {
+	op.Outcome = OutcomeSkipped
+	op.Error = reason
+	s.recordOperation(op)
+}
+
+// RecordConfigChange adds a configuration change to the current run's
+// manifest, so a replay can apply it at the same point.
+func (s *SimulationEngine) RecordConfigChange(view config.ConfigView) {
+	s.recordOperation(RunOperation{Kind: OpConfigChanged, Outcome: OutcomeSucceeded, Config: &view})
+}
+
+// storeManifest keeps a finished run's manifest.
+func (s *SimulationEngine) storeManifest(m *RunManifest) {
+	s.manifestsMu.Lock()
+	defer s.manifestsMu.Unlock()
+	s.manifests = append(s.manifests, m)
+	if len(s.manifests) > maxRunManifests {
+		s.manifests = s.manifests[len(s.manifests)-maxRunManifests:]
+	}
+}
+
+// CurrentManifest returns a copy of the running run's manifest or, when the
+// engine is idle, the most recent finished one.
+func (s *SimulationEngine) CurrentManifest() (*RunManifest, bool) {
+	s.runMu.Lock()
+	if s.run != nil {
+		m := *s.run.manifest
+		m.Operations = append([]RunOperation(nil), m.Operations...)
+		s.runMu.Unlock()
+		return &m, true
+	}
+	s.runMu.Unlock()
+
+	s.manifestsMu.RLock()
+	defer s.manifestsMu.RUnlock()
+	if len(s.manifests) == 0 {
+		return nil, false
+	}
+	return s.manifests[len(s.manifests)-1], true
+}
+
+// RunManifest returns the manifest of a run, running or finished.
+func (s *SimulationEngine) RunManifest(runID string) (*RunManifest, bool) {
+	if m, ok := s.CurrentManifest(); ok && m.RunID == runID {
+		return m, true
+	}
+
+	s.manifestsMu.RLock()
+	defer s.manifestsMu.RUnlock()
+	for _, m := range s.manifests {
+		if m.RunID == runID {
+			return m, true
+		}
+	}
+	return nil, false
+}
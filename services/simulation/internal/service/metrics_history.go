@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/simulation/internal/metrics"
+)
+
+const (
+	// MetricsSampleInterval is how often metrics are persisted.
+	MetricsSampleInterval = time.Minute
+	// MetricsRetention is how long samples are kept, and the longest window
+	// the history can be queried for.
+	MetricsRetention = 30 * 24 * time.Hour
+	// metricsHistoryPoints is the number of points a history window is split into.
+	metricsHistoryPoints = 60
+	// metricsSampleTimeout bounds one sample write.
+	metricsSampleTimeout = 10 * time.Second
+)
+
+// MetricsPoint is one bucket of a metrics history.
+type MetricsPoint struct {
+	Start                 time.Time `json:"start"`
+	Operations            int64     `json:"operations"`
+	OperationsSucceeded   int64     `json:"operations_succeeded"`
+	OperationsFailed      int64     `json:"operations_failed"`
+	TransactionsGenerated int64     `json:"transactions_generated"`
+	UsersCreated          int64     `json:"users_created"`
+	ActivePersonas        int       `json:"active_personas"` // Highest in the bucket
+	SuccessRate           *float64  `json:"success_rate"`    // Percent; null when no operations ran
+	ThroughputPerMinute   float64   `json:"throughput_per_minute"`
+	SampledSeconds        float64   `json:"sampled_seconds"` // Less than the bucket width when the service was down
+}
+
+// MetricsHistory is the persisted metrics of a time window, bucketed for trends.
+type MetricsHistory struct {
+	Window              string         `json:"window"`
+	Bucket              string         `json:"bucket"`
+	From                time.Time      `json:"from"`
+	To                  time.Time      `json:"to"`
+	Operations          int64          `json:"operations"`
+	OperationsSucceeded int64          `json:"operations_succeeded"`
+	OperationsFailed    int64          `json:"operations_failed"`
+	SuccessRate         *float64       `json:"success_rate"`
+	ThroughputPerMinute float64        `json:"throughput_per_minute"`
+	Points              []MetricsPoint `json:"points"` // Oldest first; buckets without samples are omitted
+}
+
+// MetricsRecorder persists simulation metrics once per interval so their
+// trends survive restarts. Each sample stores the change since the previous
+// one, so totals across restarts and metric resets simply add up.
+type MetricsRecorder struct {
+	db       *sql.DB
+	metrics  *metrics.SimulationMetrics
+	running  func() bool
+	interval time.Duration
+
+	prev   metrics.MetricsView
+	prevAt time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewMetricsRecorder creates a recorder for met; running reports whether the
+// simulation is running when a sample is taken.
+func NewMetricsRecorder(db *sql.DB, met *metrics.SimulationMetrics, running func() bool) *MetricsRecorder {
+	return &MetricsRecorder{
+		db:       db,
+		metrics:  met,
+		running:  running,
+		interval: MetricsSampleInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start samples metrics in the background until Stop is called or ctx ends.
+func (r *MetricsRecorder) Start(ctx context.Context) {
+	r.prev = r.metrics.GetSnapshot()
+	r.prevAt = time.Now()
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.sample(ctx, time.Now())
+			case <-r.stop:
+				// Keep the partial interval before shutting down
+				r.sample(ctx, time.Now())
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends sampling after a final sample.
+func (r *MetricsRecorder) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+}
+
+// Wait blocks until sampling has stopped or ctx expires.
+func (r *MetricsRecorder) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sample stores the change since the previous sample and prunes expired samples.
+func (r *MetricsRecorder) sample(ctx context.Context, now time.Time) {
+	current := r.metrics.GetSnapshot()
+	prev := r.prev
+	// Counters restart from zero after a reset
+	if !current.StartedAt.Equal(prev.StartedAt) || current.OperationsTotal < prev.OperationsTotal {
+		prev = metrics.MetricsView{}
+	}
+	seconds := now.Sub(r.prevAt).Seconds()
+	r.prev, r.prevAt = current, now
+	if seconds <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), metricsSampleTimeout)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO simulation_metric_samples (
+			sampled_at, interval_seconds, mode, running, operations, operations_succeeded,
+			operations_failed, transactions_generated, users_created, active_personas, average_delay_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		now, seconds, current.CurrentMode, r.running(),
+		current.OperationsTotal-prev.OperationsTotal,
+		current.OperationsSucceeded-prev.OperationsSucceeded,
+		current.OperationsFailed-prev.OperationsFailed,
+		current.TransactionsGenerated-prev.TransactionsGenerated,
+		current.UsersCreated-prev.UsersCreated,
+		current.ActivePersonas,
+		current.AverageDelayMs,
+	)
+	if err != nil {
+		log.Printf("[simulation] Failed to persist metrics sample: %v", err)
+		return
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM simulation_metric_samples WHERE sampled_at < $1`, now.Add(-MetricsRetention)); err != nil {
+		log.Printf("[simulation] Failed to prune metrics samples: %v", err)
+	}
+}
+
+// History returns the persisted metrics of the window ending at now, split
+// into buckets of at least one sample interval.
+func (r *MetricsRecorder) History(ctx context.Context, window time.Duration, now time.Time) (*MetricsHistory, error) {
+	if window <= 0 || window > MetricsRetention {
+		return nil, fmt.Errorf("window must be between %s and %s", r.interval, MetricsRetention)
+	}
+
+	bucket := (window / metricsHistoryPoints).Truncate(r.interval)
+	if bucket < r.interval {
+		bucket = r.interval
+	}
+	from := now.Add(-window)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT to_timestamp(floor(extract(epoch FROM sampled_at) / $1) * $1) AS bucket_start,
+		       SUM(interval_seconds), SUM(operations), SUM(operations_succeeded), SUM(operations_failed),
+		       SUM(transactions_generated), SUM(users_created), MAX(active_personas)
+		FROM simulation_metric_samples
+		WHERE sampled_at > $2 AND sampled_at <= $3
+		GROUP BY bucket_start
+		ORDER BY bucket_start`,
+		bucket.Seconds(), from, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics history: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	history := &MetricsHistory{
+		Window: window.String(),
+		Bucket: bucket.String(),
+		From:   from,
+		To:     now,
+		Points: make([]MetricsPoint, 0, metricsHistoryPoints+1),
+	}
+	var sampledSeconds float64
+	for rows.Next() {
+		var p MetricsPoint
+		if err := rows.Scan(&p.Start, &p.SampledSeconds, &p.Operations, &p.OperationsSucceeded, &p.OperationsFailed,
+			&p.TransactionsGenerated, &p.UsersCreated, &p.ActivePersonas); err != nil {
+			return nil, fmt.Errorf("failed to scan metrics history: %w", err)
+		}
+		p.Start = p.Start.UTC()
+		p.SuccessRate = successRate(p.OperationsSucceeded, p.Operations)
+		p.ThroughputPerMinute = perMinute(p.Operations, p.SampledSeconds)
+		history.Points = append(history.Points, p)
+
+		history.Operations += p.Operations
+		history.OperationsSucceeded += p.OperationsSucceeded
+		history.OperationsFailed += p.OperationsFailed
+		sampledSeconds += p.SampledSeconds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read metrics history: %w", err)
+	}
+
+	history.SuccessRate = successRate(history.OperationsSucceeded, history.Operations)
+	history.ThroughputPerMinute = perMinute(history.Operations, sampledSeconds)
+	return history, nil
+}
+
+// successRate returns succeeded as a percentage of total, or nil for no operations.
+func successRate(succeeded, total int64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	rate := math.Round(float64(succeeded)/float64(total)*10000) / 100
+	return &rate
+}
+
+// perMinute returns the rate of count over seconds, per minute.
+func perMinute(count int64, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return math.Round(float64(count)/seconds*60*100) / 100
+}
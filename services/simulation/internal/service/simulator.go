@@ -36,6 +36,8 @@ type SimulationEngine struct {
 	// Thread-safe running state
 	runningMu sync.RWMutex
 	running   bool
+	stopCh    chan struct{} // closed by Stop to end the background loops
+	loops     sync.WaitGroup
 
 	// Thread-safe random number generator
 	rngMu sync.Mutex
@@ -46,6 +48,20 @@ type SimulationEngine struct {
 	metrics      *metrics.SimulationMetrics
 	injector     *behavior.BehaviorInjector
 	autoVerifier *behavior.AutoVerifier
+
+	// Checks run against the platform after operations, when enabled
+	assertionHooks []AssertionHook
+
+	// Each run ends with a money conservation report
+	conservation *ConservationReporter
+	runMu        sync.Mutex
+	run          *simulationRun
+	reportsMu    sync.RWMutex
+	reports      []*ConservationReport
+
+	// Each run records a manifest of its seed and operations for replay
+	manifestsMu sync.RWMutex
+	manifests   []*RunManifest
 }
 
 // NewSimulationEngine creates a new simulation engine
@@ -70,6 +86,8 @@ func NewSimulationEngine(
 		metrics:          met,
 		injector:         injector,
 		autoVerifier:     autoVerifier,
+		conservation:     NewConservationReporter(db),
+		assertionHooks:   defaultAssertionHooks(gatewayClient, db),
 	}
 }
 
@@ -87,6 +105,13 @@ func (s *SimulationEngine) randFloat64() float64 {
 	return s.rng.Float64()
 }
 
+// randomTransaction picks a persona's next transaction type and amount.
+func (s *SimulationEngine) randomTransaction(persona *personas.Persona) (string, int64) {
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	return persona.SelectTransactionType(s.rng), persona.RandomAmount(s.rng)
+}
+
 // setRunning sets the running state thread-safely.
 func (s *SimulationEngine) setRunning(running bool) {
 	s.runningMu.Lock()
@@ -144,57 +169,116 @@ func (s *SimulationEngine) LoadUsers(ctx context.Context) error {
 	return nil
 }
 
-// Start starts the simulation engine
-func (s *SimulationEngine) Start(ctx context.Context) {
-	if s.IsRunning() {
+// begin marks the engine running and returns the channel that Stop closes.
+// It reports false if the engine is already running.
+func (s *SimulationEngine) begin() (chan struct{}, bool) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if s.running {
+		return nil, false
+	}
+	s.running = true
+	s.stopCh = make(chan struct{})
+	return s.stopCh, true
+}
+
+// Start starts the simulation engine and returns the ID of the new run, or ""
+// if it did not start. Every random choice of the run (personas, amounts,
+// delays, injected failures) is drawn from seed, so starting again with the
+// same seed and configuration repeats them.
+func (s *SimulationEngine) Start(ctx context.Context, seed int64) string {
+	stop, ok := s.begin()
+	if !ok {
 		log.Printf("[simulation] Engine already running")
-		return
+		return ""
 	}
 
-	s.setRunning(true)
 	mode := "realistic"
 	if s.config.IsDemo() {
 		mode = "demo"
 	}
-	log.Printf("[simulation] Starting simulation engine (mode: %s)...", mode)
+	log.Printf("[simulation] Starting simulation engine (mode: %s, seed: %d)...", mode, seed)
+	s.reseed(seed)
 
 	// Load users first
 	if err := s.LoadUsers(ctx); err != nil {
 		log.Printf("[simulation] Failed to load users: %v", err)
-		return
+		s.Stop()
+		return ""
 	}
 
 	if len(s.users) == 0 {
 		log.Printf("[simulation] No existing users found - will create simulated users")
 	}
 
+	// The run's conservation report is built once the simulation loop exits
+	runID := s.startRun(seed)
+	for _, user := range s.users {
+		s.recordOperation(RunOperation{Kind: OpUserLoaded, Outcome: OutcomeSucceeded, User: user.Email, Persona: user.Persona})
+	}
+
 	// Create a few initial simulated users
 	s.runUserCreationCycle(ctx)
 
 	// Start auto-verification loop for simulated users
 	if s.config.IsAutoVerificationEnabled() {
-		go s.autoVerifier.RunAutoVerificationLoop(ctx)
+		s.loops.Add(1)
+		go func() {
+			defer s.loops.Done()
+			s.autoVerifier.RunAutoVerificationLoop(ctx, stop)
+		}()
 	}
 
 	// Start simulation loop
-	go s.simulationLoop(ctx)
+	s.loops.Add(1)
+	go func() {
+		defer s.loops.Done()
+		s.simulationLoop(ctx, stop)
+		s.finishRun(ctx)
+	}()
+	return runID
 }
 
-// Stop stops the simulation engine
+// Stop stops the simulation engine. Cycles already in progress run to
+// completion; use Wait to block until they have.
 func (s *SimulationEngine) Stop() {
 	log.Printf("[simulation] Stopping simulation engine...")
-	s.setRunning(false)
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	s.running = false
+	if s.stopCh != nil {
+		close(s.stopCh)
+		s.stopCh = nil
+	}
+}
+
+// Wait blocks until the background loops have exited or ctx expires.
+func (s *SimulationEngine) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// simulationLoop runs the main simulation loop
-func (s *SimulationEngine) simulationLoop(ctx context.Context) {
+// simulationLoop runs the main simulation loop until ctx is cancelled or stop is closed.
+func (s *SimulationEngine) simulationLoop(ctx context.Context, stop <-chan struct{}) {
 	txTicker := time.NewTicker(1 * time.Minute)        // Transaction cycle every minute
 	userTicker := time.NewTicker(5 * time.Minute)      // User creation cycle every 5 minutes
 	lifecycleTicker := time.NewTicker(2 * time.Minute) // Lifecycle progression every 2 minutes
+	churnTicker := time.NewTicker(churnInterval)       // Users leaving and returning
 
 	defer txTicker.Stop()
 	defer userTicker.Stop()
 	defer lifecycleTicker.Stop()
+	defer churnTicker.Stop()
 
 	log.Printf("[simulation] Simulation loop started")
 
@@ -204,6 +288,9 @@ func (s *SimulationEngine) simulationLoop(ctx context.Context) {
 			s.setRunning(false)
 			return
 
+		case <-stop:
+			return
+
 		case <-txTicker.C:
 			if !s.IsRunning() {
 				return
@@ -221,6 +308,12 @@ func (s *SimulationEngine) simulationLoop(ctx context.Context) {
 				return
 			}
 			s.runLifecycleCycle(ctx)
+
+		case <-churnTicker.C:
+			if !s.IsRunning() {
+				return
+			}
+			s.runChurnCycle(ctx)
 		}
 	}
 }
@@ -235,6 +328,7 @@ func (s *SimulationEngine) runUserCreationCycle(ctx context.Context) {
 		user := s.lifecycleManager.GenerateNewUser()
 		s.simulatedUsers = append(s.simulatedUsers, user)
 		s.metrics.RecordUserCreated()
+		s.recordOperation(RunOperation{Kind: OpUserGenerated, Outcome: OutcomeSucceeded, User: user.Email, Persona: user.Persona})
 	}
 }
 
@@ -261,7 +355,12 @@ func (s *SimulationEngine) runLifecycleCycle(ctx context.Context) {
 		switch user.Stage {
 		case StageNew:
 			// Register the user
-			if err := s.lifecycleManager.RegisterUser(ctx, user); err != nil {
+			checks := s.beginAssertions(ctx, &AssertedOperation{Kind: OpUserRegistered, User: user.Email})
+			err := s.lifecycleManager.RegisterUser(ctx, user)
+			s.recordOutcome(RunOperation{Kind: OpUserRegistered, User: user.Email}, err)
+			checks.setUserID(user.UserID)
+			s.finishAssertions(ctx, checks, "", err)
+			if err != nil {
 				log.Printf("[simulation] Failed to register user %s: %v", user.Email, err)
 				continue
 			}
@@ -272,14 +371,20 @@ func (s *SimulationEngine) runLifecycleCycle(ctx context.Context) {
 
 		case StageRegistered:
 			// Submit KYC
-			if err := s.lifecycleManager.SubmitKYC(ctx, user); err != nil {
+			err := s.lifecycleManager.SubmitKYC(ctx, user)
+			s.recordOutcome(RunOperation{Kind: OpKYCSubmitted, User: user.Email}, err)
+			if err != nil {
 				log.Printf("[simulation] Failed to submit KYC for %s: %v", user.Email, err)
 				continue
 			}
 
 		case StageKYCSubmitted:
 			// Auto-verify KYC (using admin privileges)
-			if err := s.lifecycleManager.VerifyKYC(ctx, user); err != nil {
+			checks := s.beginAssertions(ctx, &AssertedOperation{Kind: OpKYCVerified, User: user.Email, UserID: user.UserID})
+			err := s.lifecycleManager.VerifyKYC(ctx, user)
+			s.recordOutcome(RunOperation{Kind: OpKYCVerified, User: user.Email}, err)
+			s.finishAssertions(ctx, checks, "", err)
+			if err != nil {
 				log.Printf("[simulation] Failed to verify KYC for %s: %v", user.Email, err)
 				continue
 			}
@@ -288,7 +393,9 @@ func (s *SimulationEngine) runLifecycleCycle(ctx context.Context) {
 		case StageKYCVerified:
 			// Login and mark as active
 			if user.SessionToken == "" {
-				if err := s.lifecycleManager.LoginUser(ctx, user); err != nil {
+				err := s.lifecycleManager.LoginUser(ctx, user)
+				s.recordOutcome(RunOperation{Kind: OpUserLoggedIn, User: user.Email}, err)
+				if err != nil {
 					log.Printf("[simulation] Failed to login user %s: %v", user.Email, err)
 					continue
 				}
@@ -299,7 +406,9 @@ func (s *SimulationEngine) runLifecycleCycle(ctx context.Context) {
 		case StageActive:
 			// Periodically re-login if session might be expired (every 12 hours)
 			if time.Since(user.LastLogin) > 12*time.Hour {
-				if err := s.lifecycleManager.LoginUser(ctx, user); err != nil {
+				err := s.lifecycleManager.LoginUser(ctx, user)
+				s.recordOutcome(RunOperation{Kind: OpUserLoggedIn, User: user.Email}, err)
+				if err != nil {
 					log.Printf("[simulation] Failed to re-login user %s: %v", user.Email, err)
 				}
 			}
@@ -390,8 +499,7 @@ func (s *SimulationEngine) shouldTransact(freq time.Duration) bool {
 
 // generateTransaction generates a single transaction based on persona
 func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWallet, persona *personas.Persona) error {
-	txType := persona.SelectTransactionType()
-	amount := persona.RandomAmount()
+	txType, amount := s.randomTransaction(persona)
 	description := fmt.Sprintf("Simulated %s by %s", txType, user.Persona)
 
 	// Check if we should inject a failure
@@ -399,6 +507,10 @@ func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWal
 		failErr := s.injector.GetFailureError(txType)
 		log.Printf("[simulation] 💥 Injected failure for %s: %s", txType, failErr.Error())
 		s.metrics.RecordOperation(false, true, 0)
+		s.recordOperation(RunOperation{
+			Kind: transactionOperation(txType), Outcome: OutcomeInjectedFailure,
+			User: user.Email, Amount: amount, Error: failErr.Error(),
+		})
 		return failErr
 	}
 
@@ -419,11 +531,16 @@ func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWal
 		}
 	}
 
+	op := RunOperation{Kind: transactionOperation(txType), User: user.Email, Amount: amount}
+
 	// Database users don't have session tokens - use empty token to rely on admin token from default headers
+	var txID string
 	var err error
+	var checks *pendingAssertions
 	switch txType {
 	case "deposit":
-		err = s.gatewayClient.CreateDeposit(ctx, "", user.WalletID, amount, description)
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateDeposit(ctx, "", user.WalletID, amount, description)
 		if err == nil {
 			// Update local balance on successful deposit
 			s.updateUserBalance(user.UserID, user.Balance+amount)
@@ -434,10 +551,13 @@ func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWal
 		recipient := s.selectRandomUser(user.UserID)
 		if recipient == nil {
 			log.Printf("[simulation] No recipient available for transfer")
+			s.recordSkipped(op, "no recipient available")
 			return nil // Skip this transaction
 		}
 
-		err = s.gatewayClient.CreateTransfer(ctx, "", user.WalletID, recipient.WalletID, amount, description)
+		op.Recipient = recipient.WalletID
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateTransfer(ctx, "", user.WalletID, recipient.WalletID, amount, description)
 		if err == nil {
 			// Update local balances on successful transfer
 			s.updateUserBalance(user.UserID, user.Balance-amount)
@@ -445,7 +565,8 @@ func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWal
 		}
 
 	case "withdrawal":
-		err = s.gatewayClient.CreateWithdrawal(ctx, "", user.WalletID, amount, description)
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateWithdrawal(ctx, "", user.WalletID, amount, description)
 		if err == nil {
 			// Update local balance on successful withdrawal
 			s.updateUserBalance(user.UserID, user.Balance-amount)
@@ -456,11 +577,15 @@ func (s *SimulationEngine) generateTransaction(ctx context.Context, user UserWal
 	}
 
 	// Record metrics
+	op.TransactionID = txID
+	s.recordOutcome(op, err)
 	failed := err != nil
 	s.metrics.RecordOperation(true, failed, delayDuration.Milliseconds())
 	if !failed {
 		s.metrics.RecordTransaction()
+		s.recordExecuted(txID)
 	}
+	s.finishAssertions(ctx, checks, txID, err)
 
 	if err != nil {
 		log.Printf("[simulation] Transaction failed for %s: %v", user.Email, err)
@@ -483,8 +608,7 @@ func (s *SimulationEngine) updateUserBalance(userID string, newBalance int64) {
 
 // generateSimulatedUserTransaction generates a transaction for a simulated user
 func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context, user *SimulatedUser, persona *personas.Persona) error {
-	txType := persona.SelectTransactionType()
-	amount := persona.RandomAmount()
+	txType, amount := s.randomTransaction(persona)
 	description := fmt.Sprintf("Simulated %s by %s", txType, user.Persona)
 
 	// Check if we should inject a failure
@@ -492,6 +616,10 @@ func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context,
 		failErr := s.injector.GetFailureError(txType)
 		log.Printf("[simulation] 💥 Injected failure for %s: %s", txType, failErr.Error())
 		s.metrics.RecordOperation(false, true, 0)
+		s.recordOperation(RunOperation{
+			Kind: transactionOperation(txType), Outcome: OutcomeInjectedFailure,
+			User: user.Email, Amount: amount, Error: failErr.Error(),
+		})
 		return failErr
 	}
 
@@ -510,24 +638,31 @@ func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context,
 		}
 	}
 
+	op := RunOperation{Kind: transactionOperation(txType), User: user.Email, Amount: amount}
+
 	// Ensure user is logged in (has session token)
 	if user.SessionToken == "" {
 		log.Printf("[simulation] User %s has no session token, attempting login", user.Email)
 		if loginErr := s.lifecycleManager.LoginUser(ctx, user); loginErr != nil {
 			log.Printf("[simulation] Failed to login user %s: %v", user.Email, loginErr)
+			s.recordSkipped(op, "login failed: "+loginErr.Error())
 			return nil
 		}
 	}
 
+	var txID string
 	var err error
+	var checks *pendingAssertions
 	switch txType {
 	case "deposit":
 		if user.WalletID == "" {
 			log.Printf("[simulation] User %s doesn't have wallet ID yet, skipping transaction", user.Email)
+			s.recordSkipped(op, "no wallet yet")
 			return nil
 		}
 
-		err = s.gatewayClient.CreateDeposit(ctx, user.SessionToken, user.WalletID, amount, description)
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateDeposit(ctx, user.SessionToken, user.WalletID, amount, description)
 		if err == nil {
 			user.Balance += amount
 		}
@@ -535,6 +670,7 @@ func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context,
 	case "transfer":
 		if user.WalletID == "" {
 			log.Printf("[simulation] User %s doesn't have wallet ID yet, skipping transaction", user.Email)
+			s.recordSkipped(op, "no wallet yet")
 			return nil
 		}
 
@@ -542,10 +678,13 @@ func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context,
 		recipient := s.selectRandomRecipient(user.UserID)
 		if recipient == nil {
 			log.Printf("[simulation] No recipient available for transfer")
+			s.recordSkipped(op, "no recipient available")
 			return nil
 		}
 
-		err = s.gatewayClient.CreateTransfer(ctx, user.SessionToken, user.WalletID, *recipient, amount, description)
+		op.Recipient = *recipient
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateTransfer(ctx, user.SessionToken, user.WalletID, *recipient, amount, description)
 		if err == nil {
 			user.Balance -= amount
 		}
@@ -553,21 +692,27 @@ func (s *SimulationEngine) generateSimulatedUserTransaction(ctx context.Context,
 	case "withdrawal":
 		if user.WalletID == "" {
 			log.Printf("[simulation] User %s doesn't have wallet ID yet, skipping transaction", user.Email)
+			s.recordSkipped(op, "no wallet yet")
 			return nil
 		}
 
-		err = s.gatewayClient.CreateWithdrawal(ctx, user.SessionToken, user.WalletID, amount, description)
+		checks = s.beginAssertions(ctx, assertedTransaction(op, user.UserID, user.WalletID))
+		txID, err = s.gatewayClient.CreateWithdrawal(ctx, user.SessionToken, user.WalletID, amount, description)
 		if err == nil {
 			user.Balance -= amount
 		}
 	}
 
 	// Record metrics
+	op.TransactionID = txID
+	s.recordOutcome(op, err)
 	failed := err != nil
 	s.metrics.RecordOperation(true, failed, delayDuration.Milliseconds())
 	if !failed {
 		s.metrics.RecordTransaction()
+		s.recordExecuted(txID)
 	}
+	s.finishAssertions(ctx, checks, txID, err)
 
 	if err != nil {
 		log.Printf("[simulation] Transaction failed for %s: %v", user.Email, err)
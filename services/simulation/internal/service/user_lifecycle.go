@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/simulation/internal/personas"
+	"github.com/1mb-dev/nivomoney/shared/validator"
 )
 
 // UserStage represents the lifecycle stage of a simulated user
@@ -21,6 +23,9 @@ const (
 	StageKYCSubmitted UserStage = "KYC_SUBMITTED" // KYC submitted, awaiting verification
 	StageKYCVerified  UserStage = "KYC_VERIFIED"  // KYC verified, can transact
 	StageActive       UserStage = "ACTIVE"        // Fully active user
+	StageDormant      UserStage = "DORMANT"       // Stopped transacting, may come back
+	StageSuspended    UserStage = "SUSPENDED"     // Suspended by an admin
+	StageClosed       UserStage = "CLOSED"        // Emptied and closed their wallet
 )
 
 // SimulatedUser represents a user created and managed by the simulation engine
@@ -50,6 +55,8 @@ type UserLifecycleManager struct {
 	gatewayClient *GatewayClient
 	db            *sql.DB // Direct DB access for admin bypasses
 	users         []*SimulatedUser
+	rngMu         sync.Mutex // Protects rng
+	rng           *rand.Rand // Names, personas and KYC data
 }
 
 // NewUserLifecycleManager creates a new user lifecycle manager
@@ -58,27 +65,39 @@ func NewUserLifecycleManager(gatewayClient *GatewayClient, db *sql.DB) *UserLife
 		gatewayClient: gatewayClient,
 		db:            db,
 		users:         make([]*SimulatedUser, 0),
+		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
+// Reseed restarts user generation from seed, so a run started with the same
+// seed generates the same names, personas and KYC data in the same order.
+func (m *UserLifecycleManager) Reseed(seed int64) {
+	m.rngMu.Lock()
+	defer m.rngMu.Unlock()
+	m.rng = rand.New(rand.NewSource(seed))
+}
+
 // GenerateNewUser creates a new simulated user with random persona
 func (m *UserLifecycleManager) GenerateNewUser() *SimulatedUser {
+	m.rngMu.Lock()
+	defer m.rngMu.Unlock()
+
 	timestamp := time.Now().Unix()
 	personaTypes := personas.AllPersonaTypes()
-	persona := personaTypes[rand.Intn(len(personaTypes))]
+	persona := personaTypes[m.rng.Intn(len(personaTypes))]
 
 	// Generate realistic Indian names
 	firstNames := []string{"Amit", "Priya", "Rahul", "Sneha", "Vikram", "Anjali", "Arjun", "Kavya", "Rohan", "Diya"}
 	lastNames := []string{"Sharma", "Patel", "Kumar", "Singh", "Reddy", "Nair", "Gupta", "Verma", "Iyer", "Mehta"}
 
-	firstName := firstNames[rand.Intn(len(firstNames))]
-	lastName := lastNames[rand.Intn(len(lastNames))]
+	firstName := firstNames[m.rng.Intn(len(firstNames))]
+	lastName := lastNames[m.rng.Intn(len(lastNames))]
 
 	user := &SimulatedUser{
 		Email:       fmt.Sprintf("%s.%s.%d@example.com", firstName, lastName, timestamp),
-		Password:    generateRandomPassword(),
+		Password:    generateRandomPassword(m.rng),
 		FullName:    fmt.Sprintf("%s %s", firstName, lastName),
-		PhoneNumber: generateIndianPhone(),
+		PhoneNumber: generateIndianPhone(m.rng),
 		Persona:     persona,
 		Stage:       StageNew,
 		CreatedAt:   time.Now(),
@@ -158,7 +177,9 @@ func (m *UserLifecycleManager) SubmitKYC(ctx context.Context, user *SimulatedUse
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		kycReq := generateKYCData(user.FullName)
+		m.rngMu.Lock()
+		kycReq := generateKYCData(m.rng)
+		m.rngMu.Unlock()
 		if err := m.gatewayClient.SubmitKYC(ctx, user.SessionToken, kycReq); err != nil {
 			lastErr = err
 			if attempt < maxRetries {
@@ -279,6 +300,94 @@ func (m *UserLifecycleManager) LogoutUser(ctx context.Context, user *SimulatedUs
 	return nil
 }
 
+// MarkDormant makes an active user stop transacting until reactivated.
+func (m *UserLifecycleManager) MarkDormant(ctx context.Context, user *SimulatedUser) error {
+	if user.Stage != StageActive {
+		return fmt.Errorf("user must be in ACTIVE stage to go dormant (current: %s)", user.Stage)
+	}
+
+	// A dormant user's session is left to lapse; log out when possible
+	if user.SessionToken != "" {
+		if err := m.LogoutUser(ctx, user); err != nil {
+			user.SessionToken = ""
+		}
+	}
+
+	user.Stage = StageDormant
+	log.Printf("[simulation] 💤 User went dormant: %s", user.Email)
+	return nil
+}
+
+// Reactivate logs a dormant user back in so they transact again.
+func (m *UserLifecycleManager) Reactivate(ctx context.Context, user *SimulatedUser) error {
+	if user.Stage != StageDormant {
+		return fmt.Errorf("user must be in DORMANT stage to reactivate (current: %s)", user.Stage)
+	}
+
+	if err := m.LoginUser(ctx, user); err != nil {
+		return fmt.Errorf("failed to login user: %w", err)
+	}
+
+	user.Stage = StageActive
+	log.Printf("[simulation] 🔁 User reactivated: %s", user.Email)
+	return nil
+}
+
+// CloseWallet withdraws an active user's balance and closes their wallet.
+// It returns the ID of the withdrawal, or "" if the wallet was already empty.
+func (m *UserLifecycleManager) CloseWallet(ctx context.Context, user *SimulatedUser) (string, error) {
+	if user.Stage != StageActive {
+		return "", fmt.Errorf("user must be in ACTIVE stage to close their wallet (current: %s)", user.Stage)
+	}
+	if user.WalletID == "" {
+		return "", fmt.Errorf("user has no wallet")
+	}
+
+	// Wallets can only be closed once empty
+	var withdrawalID string
+	if user.Balance > 0 {
+		if user.SessionToken == "" {
+			if err := m.LoginUser(ctx, user); err != nil {
+				return "", fmt.Errorf("failed to login before withdrawal: %w", err)
+			}
+		}
+		txID, err := m.gatewayClient.CreateWithdrawal(ctx, user.SessionToken, user.WalletID, user.Balance, "Withdrawal before closing wallet")
+		if err != nil {
+			return "", fmt.Errorf("failed to withdraw balance: %w", err)
+		}
+		withdrawalID = txID
+		user.Balance = 0
+	}
+
+	if err := m.gatewayClient.CloseWallet(ctx, user.WalletID, "Closed by simulated user churn"); err != nil {
+		return withdrawalID, fmt.Errorf("failed to close wallet: %w", err)
+	}
+
+	user.Stage = StageClosed
+	user.SessionToken = ""
+	user.LastActive = time.Now()
+
+	log.Printf("[simulation] 🔒 Wallet closed: %s", user.Email)
+	return withdrawalID, nil
+}
+
+// Suspend suspends an active user's account.
+func (m *UserLifecycleManager) Suspend(ctx context.Context, user *SimulatedUser) error {
+	if user.Stage != StageActive {
+		return fmt.Errorf("user must be in ACTIVE stage to be suspended (current: %s)", user.Stage)
+	}
+
+	if err := m.gatewayClient.SuspendUser(ctx, user.UserID, "Suspended by simulated user churn"); err != nil {
+		return fmt.Errorf("failed to suspend user: %w", err)
+	}
+
+	user.Stage = StageSuspended
+	user.SessionToken = ""
+
+	log.Printf("[simulation] ⛔ User suspended: %s", user.Email)
+	return nil
+}
+
 // GetUsers returns all simulated users
 func (m *UserLifecycleManager) GetUsers() []*SimulatedUser {
 	return m.users
@@ -297,58 +406,58 @@ func (m *UserLifecycleManager) GetActiveUsers() []*SimulatedUser {
 
 // Helper functions
 
-func generateRandomPassword() string {
+func generateRandomPassword(rng *rand.Rand) string {
 	// Generate a simple but valid password
-	return fmt.Sprintf("Pass%d!@#", rand.Intn(100000)+10000)
+	return fmt.Sprintf("Pass%d!@#", rng.Intn(100000)+10000)
 }
 
-func generateIndianPhone() string {
+func generateIndianPhone(rng *rand.Rand) string {
 	// Generate a valid Indian mobile number (starts with 6-9)
-	firstDigit := rand.Intn(4) + 6 // 6, 7, 8, or 9
-	remaining := rand.Intn(900000000) + 100000000
+	firstDigit := rng.Intn(4) + 6 // 6, 7, 8, or 9
+	remaining := rng.Intn(900000000) + 100000000
 	return fmt.Sprintf("%d%09d", firstDigit, remaining)
 }
 
-func generateKYCData(_ string) KYCSubmitRequest {
+func generateKYCData(rng *rand.Rand) KYCSubmitRequest {
 	// Generate realistic Indian addresses
 	streets := []string{"MG Road", "Brigade Road", "Residency Road", "Church Street", "Indiranagar"}
 	cities := []string{"Bangalore", "Mumbai", "Delhi", "Chennai", "Hyderabad", "Pune", "Kolkata"}
 	states := []string{"Karnataka", "Maharashtra", "Delhi", "Tamil Nadu", "Telangana", "Maharashtra", "West Bengal"}
 
-	cityIdx := rand.Intn(len(cities))
+	cityIdx := rng.Intn(len(cities))
 
 	// Generate valid DOB (1980-2000 range, valid month 1-12, valid day 1-28)
-	year := 1980 + rand.Intn(21) // 1980-2000
-	month := rand.Intn(12) + 1   // 1-12
-	day := rand.Intn(28) + 1     // 1-28 (safe for all months)
+	year := 1980 + rng.Intn(21) // 1980-2000
+	month := rng.Intn(12) + 1   // 1-12
+	day := rng.Intn(28) + 1     // 1-28 (safe for all months)
 
 	return KYCSubmitRequest{
-		PAN:         generatePAN(),
-		Aadhaar:     generateAadhar(),
+		PAN:         generatePAN(rng),
+		Aadhaar:     generateAadhar(rng),
 		DateOfBirth: fmt.Sprintf("%04d-%02d-%02d", year, month, day),
 		Address: KYCAddressRequest{
-			Street:  fmt.Sprintf("%d, %s", rand.Intn(500)+1, streets[rand.Intn(len(streets))]),
+			Street:  fmt.Sprintf("%d, %s", rng.Intn(500)+1, streets[rng.Intn(len(streets))]),
 			City:    cities[cityIdx],
 			State:   states[cityIdx],
-			PIN:     fmt.Sprintf("%06d", rand.Intn(900000)+100000),
+			PIN:     fmt.Sprintf("%06d", rng.Intn(900000)+100000),
 			Country: "IN",
 		},
 	}
 }
 
-func generatePAN() string {
+func generatePAN(rng *rand.Rand) string {
 	// PAN format: AAAAA9999A
 	letters := "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	pan := ""
 	for i := 0; i < 5; i++ {
-		pan += string(letters[rand.Intn(len(letters))])
+		pan += string(letters[rng.Intn(len(letters))])
 	}
-	pan += fmt.Sprintf("%04d", rand.Intn(10000))
-	pan += string(letters[rand.Intn(len(letters))])
+	pan += fmt.Sprintf("%04d", rng.Intn(10000))
+	pan += string(letters[rng.Intn(len(letters))])
 	return pan
 }
 
-func generateAadhar() string {
-	// Aadhar format: 12 digits
-	return fmt.Sprintf("%012d", rand.Intn(900000000000)+100000000000)
+func generateAadhar(rng *rand.Rand) string {
+	// Aadhar format: 11 digits starting with 2-9, then a Verhoeff check digit
+	return validator.AppendVerhoeffCheckDigit(fmt.Sprintf("%011d", rng.Intn(80000000000)+20000000000))
 }
@@ -0,0 +1,19 @@
+-- Simulation metrics history: one row per sampling interval, so success-rate
+-- and throughput trends survive restarts of the simulation service.
+-- Counts are deltas over the interval, not the in-memory running totals.
+CREATE TABLE IF NOT EXISTS simulation_metric_samples (
+    id                     BIGSERIAL PRIMARY KEY,
+    sampled_at             TIMESTAMPTZ NOT NULL,
+    interval_seconds       DOUBLE PRECISION NOT NULL CHECK (interval_seconds > 0),
+    mode                   VARCHAR(20) NOT NULL,
+    running                BOOLEAN NOT NULL,
+    operations             BIGINT NOT NULL DEFAULT 0 CHECK (operations >= 0),
+    operations_succeeded   BIGINT NOT NULL DEFAULT 0 CHECK (operations_succeeded >= 0),
+    operations_failed      BIGINT NOT NULL DEFAULT 0 CHECK (operations_failed >= 0),
+    transactions_generated BIGINT NOT NULL DEFAULT 0 CHECK (transactions_generated >= 0),
+    users_created          BIGINT NOT NULL DEFAULT 0 CHECK (users_created >= 0),
+    active_personas        INTEGER NOT NULL DEFAULT 0,
+    average_delay_ms       DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_simulation_metric_samples_sampled_at ON simulation_metric_samples(sampled_at);
@@ -1,28 +1,58 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/handler"
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/repository"
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/router"
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
+	"github.com/1mb-dev/nivomoney/shared/workerpool"
 )
 
 func main() {
 	server.Run(server.ServiceConfig{
 		Name: "transaction",
+		// Wallet sweeps the balances of wallets being closed and checks UPI
+		// deposit amounts; identity charges tier upgrade fees; simulation injects
+		// stuck transfers
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/transactions/closure-sweeps": {"wallet"},
+				"/internal/v1/transactions/fees":           {"identity"},
+				"/internal/v1/amount-policies":             {"wallet"},
+				"/internal/v1/simulation":                  {"simulation"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repository layer
 			transactionRepo := repository.NewTransactionRepository(ctx.DB.DB)
+			payeeRepo := repository.NewPayeeRepository(ctx.DB.DB)
+			riskBypassRepo := repository.NewRiskBypassRepository(ctx.DB.DB)
+			categoryRuleRepo := repository.NewCategoryRuleRepository(ctx.DB.DB)
+			quoteRepo := repository.NewQuoteRepository(ctx.DB.DB)
+			sweepRepo := repository.NewSweepRepository(ctx.DB.DB)
+			fundingRepo := repository.NewFundingRepository(ctx.DB.DB)
+			merchantWebhookRepo := repository.NewMerchantWebhookRepository(ctx.DB.DB)
+			paymentIntentRepo := repository.NewPaymentIntentRepository(ctx.DB.DB)
+			timelineRepo := repository.NewTimelineRepository(ctx.DB.DB)
+			amountPolicyRepo := repository.NewAmountPolicyRepository(ctx.DB.DB)
+			withdrawalApprovalRepo := repository.NewWithdrawalApprovalRepository(ctx.DB.DB)
 
 			// Initialize external service clients with internal auth for service-to-service calls
-			internalSecret := server.GetEnv("INTERNAL_SERVICE_SECRET", "")
-			riskClient := service.NewRiskClient(server.GetEnv("RISK_SERVICE_URL", "http://risk-service:8085"))
+			internalSecret := server.GetSecret("INTERNAL_SERVICE_SECRET", "")
+			riskClient := service.NewRiskClientWithSecret(server.GetEnv("RISK_SERVICE_URL", "http://risk-service:8085"), internalSecret)
 			walletClient := service.NewWalletClientWithSecret(server.GetEnv("WALLET_SERVICE_URL", "http://wallet-service:8083"), internalSecret)
 			ledgerClient := service.NewLedgerClient(server.GetEnv("LEDGER_SERVICE_URL", "http://ledger-service:8084"))
+			notificationClient := clients.NewNotificationClient(server.GetEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8087"))
 
 			// Initialize event publisher
 			eventPublisher := events.NewPublisher(events.PublishConfig{
@@ -32,14 +62,227 @@ func main() {
 
 			// Initialize service layer
 			transactionService := service.NewTransactionService(transactionRepo, riskClient, walletClient, ledgerClient, eventPublisher)
+			payeeService := service.NewPayeeService(payeeRepo, walletClient)
+
+			// Risk bypass accounting: transfers up to RISK_FAIL_OPEN_MAX_AMOUNT (paise)
+			// proceed when risk is unavailable and are re-scored once it recovers
+			riskPolicy := service.DefaultRiskBypassPolicy()
+			riskPolicy.FailOpenMaxAmount = int64(server.GetEnvInt("RISK_FAIL_OPEN_MAX_AMOUNT", 0))
+			transactionService.SetRiskBypass(riskBypassRepo, riskPolicy)
+			transactionService.SetCategoryRules(categoryRuleRepo)
+
+			// Minimum and maximum amounts per operation and currency, managed by admins
+			transactionService.SetAmountPolicies(amountPolicyRepo)
+
+			// Status history per transaction, for support tooling and progress displays
+			transactionService.SetTimeline(timelineRepo)
+
+			// Transfer quotes hold their price for TRANSFER_QUOTE_TTL_SECONDS; amounts in
+			// another currency are converted at the ledger's rate plus TRANSFER_FX_MARKUP_BPS
+			quotePolicy := service.DefaultQuotePolicy()
+			quotePolicy.TTL = time.Duration(server.GetEnvInt("TRANSFER_QUOTE_TTL_SECONDS", int(service.DefaultQuoteTTL/time.Second))) * time.Second
+			quotePolicy.FXMarkupBps = int64(server.GetEnvInt("TRANSFER_FX_MARKUP_BPS", service.DefaultFXMarkupBps))
+			transactionService.SetQuotes(quoteRepo, ledgerClient, quotePolicy)
+			ctx.Lifecycle.Every("quote-purge", time.Hour, func(workerCtx context.Context) error {
+				purged, err := transactionService.PurgeExpiredQuotes(workerCtx)
+				if err != nil {
+					return err
+				}
+				if purged > 0 {
+					ctx.Logger.WithField("purged", purged).Info("Purged expired transfer quotes")
+				}
+				return nil
+			})
+
+			// Transfers in wallet listings name their counterparty, resolved through
+			// identity and cached for COUNTERPARTY_CACHE_TTL_SECONDS
+			identityClient := service.NewIdentityClientWithSecret(server.GetEnv("IDENTITY_SERVICE_URL", "http://identity-service:8080"), internalSecret)
+			counterpartyTTL := time.Duration(server.GetEnvInt("COUNTERPARTY_CACHE_TTL_SECONDS", int(service.DefaultCounterpartyCacheTTL/time.Second))) * time.Second
+			transactionService.SetCounterpartyEnrichment(identityClient, counterpartyTTL, server.GetEnvInt("COUNTERPARTY_CACHE_ENTRIES", service.DefaultCounterpartyCacheEntries))
+
+			// Transaction events are published by a bounded worker pool, drained on shutdown
+			eventPool := workerpool.New(workerpool.Config{
+				Name:       "transaction-events",
+				Workers:    4,
+				QueueSize:  1000,
+				JobTimeout: service.EventPublishTimeout,
+				Logger:     ctx.Logger,
+			})
+			ctx.Lifecycle.Register("transaction-events", eventPool)
+			transactionService.SetEventPool(eventPool)
+
+			// Reversals of at least REVERSAL_APPROVAL_THRESHOLD (paise) need a second admin's approval
+			approvals := approval.NewManager("transaction", approval.NewPostgresStore(ctx.DB.DB))
+			reversalThreshold := int64(server.GetEnvInt("REVERSAL_APPROVAL_THRESHOLD", int(service.DefaultReversalApprovalThreshold)))
+			transactionService.SetReversalApprovals(approvals, reversalThreshold)
+
+			// Withdrawals of at least WITHDRAWAL_STEP_UP_THRESHOLD (paise) are held until
+			// the requester confirms them with identity's step-up token, signed with
+			// the JWT secret, within WITHDRAWAL_CONFIRMATION_TTL_SECONDS. From
+			// WITHDRAWAL_ADMIN_APPROVAL_THRESHOLD an admin must also approve them.
+			// Unconfirmed, rejected and unapproved withdrawals are cancelled every
+			// WITHDRAWAL_EXPIRY_INTERVAL_SECONDS.
+			jwtSecret := server.RequireEnv("JWT_SECRET")
+			withdrawalPolicy := service.DefaultWithdrawalApprovalPolicy()
+			withdrawalPolicy.StepUpThreshold = int64(server.GetEnvInt("WITHDRAWAL_STEP_UP_THRESHOLD", int(withdrawalPolicy.StepUpThreshold)))
+			withdrawalPolicy.AdminThreshold = int64(server.GetEnvInt("WITHDRAWAL_ADMIN_APPROVAL_THRESHOLD", int(withdrawalPolicy.AdminThreshold)))
+			withdrawalPolicy.ConfirmationTTL = time.Duration(server.GetEnvInt("WITHDRAWAL_CONFIRMATION_TTL_SECONDS", int(withdrawalPolicy.ConfirmationTTL/time.Second))) * time.Second
+			transactionService.SetWithdrawalApprovals(withdrawalApprovalRepo, approvals, withdrawalPolicy, jwtSecret)
+			transactionService.SetNotificationClient(notificationClient)
+			withdrawalExpiryInterval := time.Duration(server.GetEnvInt("WITHDRAWAL_EXPIRY_INTERVAL_SECONDS", 60)) * time.Second
+			ctx.Lifecycle.Every("withdrawal-expiry", withdrawalExpiryInterval, func(workerCtx context.Context) error {
+				cancelled, err := transactionService.ExpireWithdrawalApprovals(workerCtx)
+				if err != nil {
+					return err
+				}
+				if cancelled > 0 {
+					ctx.Logger.WithField("cancelled", cancelled).Info("Cancelled unconfirmed or unapproved withdrawals")
+				}
+				return nil
+			})
+
+			// Auto-sweep rules run after each balance change this service makes, and
+			// every SWEEP_SCAN_INTERVAL_SECONDS for changes made elsewhere. One rule
+			// runs at most once per SWEEP_COOLDOWN_SECONDS.
+			sweepCooldown := time.Duration(server.GetEnvInt("SWEEP_COOLDOWN_SECONDS", int(service.DefaultSweepCooldown/time.Second))) * time.Second
+			transactionService.SetSweepRules(sweepRepo, sweepCooldown)
+			ctx.Lifecycle.Go("sweep-worker", transactionService.RunSweepWorker)
+			sweepScanInterval := time.Duration(server.GetEnvInt("SWEEP_SCAN_INTERVAL_SECONDS", 300)) * time.Second
+			ctx.Lifecycle.Every("sweep-scan", sweepScanInterval, func(workerCtx context.Context) error {
+				executed, err := transactionService.SweepAllWallets(workerCtx)
+				if err != nil {
+					return err
+				}
+				if executed > 0 {
+					ctx.Logger.WithField("executed", executed).Info("Ran auto-sweep rules")
+				}
+				return nil
+			})
+
+			// Auto top-ups deposit from a linked bank account under a mandate when a
+			// wallet this service debits falls below its threshold, and every
+			// AUTO_TOP_UP_SCAN_INTERVAL_SECONDS for debits made elsewhere. A wallet is
+			// topped up at most once per AUTO_TOP_UP_COOLDOWN_SECONDS.
+			topUpCooldown := time.Duration(server.GetEnvInt("AUTO_TOP_UP_COOLDOWN_SECONDS", int(service.DefaultAutoTopUpCooldown/time.Second))) * time.Second
+			transactionService.SetAutoTopUp(fundingRepo, topUpCooldown)
+			ctx.Lifecycle.Go("auto-top-up-worker", transactionService.RunAutoTopUpWorker)
+			topUpScanInterval := time.Duration(server.GetEnvInt("AUTO_TOP_UP_SCAN_INTERVAL_SECONDS", 300)) * time.Second
+			ctx.Lifecycle.Every("auto-top-up-scan", topUpScanInterval, func(workerCtx context.Context) error {
+				attempted, err := transactionService.TopUpAllWallets(workerCtx)
+				if err != nil {
+					return err
+				}
+				if attempted > 0 {
+					ctx.Logger.WithField("attempted", attempted).Info("Ran auto top-ups")
+				}
+				return nil
+			})
+
+			// Completed transfers into a wallet with a merchant webhook are POSTed
+			// to it, signed; failed deliveries are retried with backoff
+			transactionService.SetMerchantWebhooks(merchantWebhookRepo, service.DefaultMerchantWebhookPolicy())
+			webhookInterval := time.Duration(server.GetEnvInt("MERCHANT_WEBHOOK_INTERVAL_SECONDS", 10)) * time.Second
+			ctx.Lifecycle.Every("merchant-webhooks", webhookInterval, func(workerCtx context.Context) error {
+				if _, err := transactionService.DeliverMerchantWebhooks(workerCtx); err != nil {
+					return err
+				}
+				return nil
+			})
+
+			// Merchants request payments against their order IDs; payers confirm
+			// them with a transfer that the merchant webhook reports
+			transactionService.SetPaymentIntents(paymentIntentRepo)
+
+			// Other services charge platform fees, such as tier upgrade fees
+			transactionService.SetFeeCharges(repository.NewFeeChargeRepository(ctx.DB.DB))
+
+			// Transfers still pending STUCK_PENDING_THRESHOLD_SECONDS after creation
+			// are completed when their funds moved and failed otherwise
+			stuckThreshold := time.Duration(server.GetEnvInt("STUCK_PENDING_THRESHOLD_SECONDS", int(service.DefaultStuckPendingThreshold/time.Second))) * time.Second
+			transactionService.SetStuckPendingReaper(repository.NewStuckPendingRepository(ctx.DB.DB), stuckThreshold)
+			// Stuck transfers exercise the reaper, so only simulations outside production get them
+			if !ctx.Config.IsProduction() {
+				transactionService.SetStuckTransfers(repository.NewStuckTransferRepository(ctx.DB.DB))
+			}
+			reapInterval := time.Duration(server.GetEnvInt("STUCK_PENDING_REAP_INTERVAL_SECONDS", 60)) * time.Second
+			ctx.Lifecycle.Every("stuck-pending-reaper", reapInterval, func(workerCtx context.Context) error {
+				result, err := transactionService.ReapStuckPending(workerCtx)
+				if err != nil {
+					return err
+				}
+				if result.Completed+result.Failed+result.Skipped > 0 {
+					ctx.Logger.With(map[string]interface{}{
+						"completed": result.Completed,
+						"failed":    result.Failed,
+						"skipped":   result.Skipped,
+					}).Warn("Settled stuck pending transfers")
+				}
+				return nil
+			})
+
+			// Start post-hoc risk re-score worker
+			rescoreInterval := time.Duration(server.GetEnvInt("RISK_RESCORE_INTERVAL_SECONDS", 60)) * time.Second
+			ctx.Logger.WithField("interval", rescoreInterval.String()).Info("Starting risk re-score worker...")
+			ctx.Lifecycle.Every("risk-rescore", rescoreInterval, func(workerCtx context.Context) error {
+				rescored, err := transactionService.RescoreBypassedTransactions(workerCtx, service.DefaultRescoreBatch)
+				if err != nil {
+					return err
+				}
+				if rescored > 0 {
+					ctx.Logger.WithField("rescored", rescored).Info("Re-scored risk-bypassed transactions")
+				}
+				return nil
+			})
+
+			// Start nightly ledger audit: verify the previous UTC day's transfers
+			// have matching posted journal entries
+			auditHour := server.GetEnvInt("LEDGER_AUDIT_HOUR", 3)
+			if auditHour < 0 || auditHour > 23 {
+				return nil, fmt.Errorf("LEDGER_AUDIT_HOUR must be between 0 and 23, got %d", auditHour)
+			}
+			ctx.Lifecycle.Go("ledger-audit", func(workerCtx context.Context) {
+				ctx.Logger.WithField("hour_utc", auditHour).Info("Starting nightly ledger audit worker...")
+
+				for {
+					timer := time.NewTimer(untilNextRun(time.Now().UTC(), auditHour))
+					select {
+					case <-timer.C:
+						to := time.Now().UTC().Truncate(24 * time.Hour)
+						from := to.Add(-24 * time.Hour)
+						// An audit already running finishes even if shutdown starts
+						if _, err := transactionService.AuditLedgerReferences(context.WithoutCancel(workerCtx), from, to); err != nil {
+							ctx.Logger.WithError(err).Error("Ledger audit failed")
+						}
+					case <-workerCtx.Done():
+						timer.Stop()
+						ctx.Logger.Info("Ledger audit worker stopped")
+						return
+					}
+				}
+			})
 
 			// Initialize handler layer
 			transactionHandler := handler.NewTransactionHandler(transactionService, walletClient)
+			payeeHandler := handler.NewPayeeHandler(payeeService, walletClient)
+			categoryHandler := handler.NewCategoryHandler(transactionService)
+			amountPolicyHandler := handler.NewAmountPolicyHandler(transactionService)
+			sweepHandler := handler.NewSweepHandler(transactionService, walletClient)
+			fundingHandler := handler.NewFundingHandler(transactionService, walletClient)
+			merchantWebhookHandler := handler.NewMerchantWebhookHandler(transactionService, walletClient)
+			paymentIntentHandler := handler.NewPaymentIntentHandler(transactionService, walletClient)
+			approvalHandler := approval.NewHandler(approvals)
 
 			// Setup routes
-			jwtSecret := server.RequireEnv("JWT_SECRET")
-
-			return router.SetupRoutes(transactionHandler, jwtSecret), nil
+			return router.SetupRoutes(transactionHandler, payeeHandler, categoryHandler, sweepHandler, fundingHandler, merchantWebhookHandler, paymentIntentHandler, amountPolicyHandler, approvalHandler, jwtSecret), nil
 		},
 	})
 }
+
+// untilNextRun returns the duration until the next occurrence of hour:00 UTC.
+func untilNextRun(now time.Time, hour int) time.Duration {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next.Sub(now)
+}
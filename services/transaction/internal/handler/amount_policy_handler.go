@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// AmountPolicyHandler handles HTTP requests for amount policy management
+// (admin operations) and amount checks by other services.
+type AmountPolicyHandler struct {
+	transactionService *service.TransactionService
+}
+
+// NewAmountPolicyHandler creates a new amount policy handler.
+func NewAmountPolicyHandler(transactionService *service.TransactionService) *AmountPolicyHandler {
+	return &AmountPolicyHandler{
+		transactionService: transactionService,
+	}
+}
+
+// ListPolicies handles GET /api/v1/admin/transactions/amount-policies
+func (h *AmountPolicyHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.transactionService.ListAmountPolicies(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, policies)
+}
+
+// SetPolicy handles PUT /api/v1/admin/transactions/amount-policies
+func (h *AmountPolicyHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.AmountPolicyRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	policy, err := h.transactionService.SetAmountPolicy(r.Context(), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, policy)
+}
+
+// DeletePolicy handles DELETE /api/v1/admin/transactions/amount-policies/:id
+func (h *AmountPolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.transactionService.DeleteAmountPolicy(r.Context(), r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// CheckAmount handles POST /internal/v1/amount-policies/check, answering 204
+// when the amount is within the policy of its operation and currency.
+func (h *AmountPolicyHandler) CheckAmount(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.AmountCheckRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if err := h.transactionService.CheckAmount(r.Context(), req.Operation, req.Currency, req.Amount); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// CategoryHandler handles HTTP requests for category pattern management and
+// bulk re-categorization (admin operations).
+type CategoryHandler struct {
+	transactionService *service.TransactionService
+}
+
+// NewCategoryHandler creates a new category handler.
+func NewCategoryHandler(transactionService *service.TransactionService) *CategoryHandler {
+	return &CategoryHandler{
+		transactionService: transactionService,
+	}
+}
+
+// ListPatterns handles GET /api/v1/admin/transactions/category-patterns
+func (h *CategoryHandler) ListPatterns(w http.ResponseWriter, r *http.Request) {
+	patterns, err := h.transactionService.ListCategoryPatterns(r.Context())
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, patterns)
+}
+
+// GetPattern handles GET /api/v1/admin/transactions/category-patterns/:id
+func (h *CategoryHandler) GetPattern(w http.ResponseWriter, r *http.Request) {
+	pattern, err := h.transactionService.GetCategoryPattern(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, pattern)
+}
+
+// CreatePattern handles POST /api/v1/admin/transactions/category-patterns
+func (h *CategoryHandler) CreatePattern(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.CategoryPatternRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	pattern, err := h.transactionService.CreateCategoryPattern(r.Context(), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, pattern)
+}
+
+// UpdatePattern handles PUT /api/v1/admin/transactions/category-patterns/:id
+func (h *CategoryHandler) UpdatePattern(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.CategoryPatternRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	pattern, err := h.transactionService.UpdateCategoryPattern(r.Context(), r.PathValue("id"), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, pattern)
+}
+
+// DeletePattern handles DELETE /api/v1/admin/transactions/category-patterns/:id
+func (h *CategoryHandler) DeletePattern(w http.ResponseWriter, r *http.Request) {
+	if err := h.transactionService.DeleteCategoryPattern(r.Context(), r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// StartRecategorization handles POST /api/v1/admin/transactions/recategorize.
+// The body is optional; without it every transaction is re-categorized.
+// Responds 202 with the job to poll.
+func (h *CategoryHandler) StartRecategorization(w http.ResponseWriter, r *http.Request) {
+	var req models.RecategorizeRequest
+	if r.ContentLength != 0 {
+		var bindErr *errors.Error
+		if req, bindErr = handler.BindRequest[models.RecategorizeRequest](r); bindErr != nil {
+			response.Error(w, bindErr)
+			return
+		}
+	}
+
+	job, err := h.transactionService.StartRecategorization(r.Context(), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, job)
+}
+
+// GetRecategorization handles GET /api/v1/admin/transactions/recategorize/:jobId
+func (h *CategoryHandler) GetRecategorization(w http.ResponseWriter, r *http.Request) {
+	job, err := h.transactionService.GetRecategorizationJob(r.PathValue("jobId"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, job)
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// FundingHandler handles HTTP requests for linked bank accounts, their
+// mandates, and wallet auto top-up rules.
+type FundingHandler struct {
+	transactionService *service.TransactionService
+	walletClient       *service.WalletClient
+}
+
+// NewFundingHandler creates a new funding handler.
+func NewFundingHandler(transactionService *service.TransactionService, walletClient *service.WalletClient) *FundingHandler {
+	return &FundingHandler{
+		transactionService: transactionService,
+		walletClient:       walletClient,
+	}
+}
+
+// ========================================================================
+// Funding Sources
+// ========================================================================
+
+// LinkSource handles POST /api/v1/funding-sources
+func (h *FundingHandler) LinkSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	req, bindErr := handler.BindRequest[models.LinkFundingSourceRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	source, err := h.transactionService.LinkFundingSource(r.Context(), userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, source)
+}
+
+// ListSources handles GET /api/v1/funding-sources
+func (h *FundingHandler) ListSources(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	sources, err := h.transactionService.ListFundingSources(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, sources)
+}
+
+// RemoveSource handles DELETE /api/v1/funding-sources/:id
+// Mandates on the account are cancelled with it.
+func (h *FundingHandler) RemoveSource(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	if err := h.transactionService.RemoveFundingSource(r.Context(), userID, r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ========================================================================
+// Mandates
+// ========================================================================
+
+// CreateMandate handles POST /api/v1/mandates
+// The caller must be able to transact on the wallet the mandate funds.
+func (h *FundingHandler) CreateMandate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	req, bindErr := handler.BindRequest[models.CreateMandateRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if authErr := checkWalletAccess(r, h.walletClient, req.WalletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	mandate, err := h.transactionService.CreateMandate(r.Context(), userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, mandate)
+}
+
+// ListMandates handles GET /api/v1/mandates
+func (h *FundingHandler) ListMandates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	mandates, err := h.transactionService.ListMandates(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, mandates)
+}
+
+// CancelMandate handles POST /api/v1/mandates/:id/cancel
+func (h *FundingHandler) CancelMandate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	if err := h.transactionService.CancelMandate(r.Context(), userID, r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ========================================================================
+// Auto Top-Up Rules
+// ========================================================================
+
+// GetRule handles GET /api/v1/wallets/:walletId/auto-top-up
+func (h *FundingHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	rule, err := h.transactionService.GetAutoTopUpRule(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, rule)
+}
+
+// SetRule handles PUT /api/v1/wallets/:walletId/auto-top-up
+func (h *FundingHandler) SetRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	req, bindErr := handler.BindRequest[models.AutoTopUpRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	rule, err := h.transactionService.SetAutoTopUpRule(r.Context(), walletID, userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, rule)
+}
+
+// DeleteRule handles DELETE /api/v1/wallets/:walletId/auto-top-up
+func (h *FundingHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	if err := h.transactionService.DeleteAutoTopUpRule(r.Context(), walletID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListExecutions handles GET /api/v1/wallets/:walletId/auto-top-up/executions
+// Query params: limit (default 50, max 200).
+func (h *FundingHandler) ListExecutions(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			response.Error(w, errors.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	executions, err := h.transactionService.ListAutoTopUpExecutions(r.Context(), walletID, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, executions)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// MerchantWebhookHandler handles HTTP requests for a wallet's merchant
+// payment webhook.
+type MerchantWebhookHandler struct {
+	transactionService *service.TransactionService
+	walletClient       *service.WalletClient
+}
+
+// NewMerchantWebhookHandler creates a new merchant webhook handler.
+func NewMerchantWebhookHandler(transactionService *service.TransactionService, walletClient *service.WalletClient) *MerchantWebhookHandler {
+	return &MerchantWebhookHandler{
+		transactionService: transactionService,
+		walletClient:       walletClient,
+	}
+}
+
+// GetWebhook handles GET /api/v1/wallets/:walletId/merchant-webhook
+func (h *MerchantWebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	hook, err := h.transactionService.GetMerchantWebhook(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, hook)
+}
+
+// CreateWebhook handles POST /api/v1/wallets/:walletId/merchant-webhook
+// The response holds the signing secret; it is not shown again.
+func (h *MerchantWebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	req, bindErr := handler.BindRequest[models.MerchantWebhookRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	userID, authErr := h.authorize(r, walletID)
+	if authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	hook, err := h.transactionService.CreateMerchantWebhook(r.Context(), walletID, userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, hook)
+}
+
+// UpdateWebhook handles PUT /api/v1/wallets/:walletId/merchant-webhook
+func (h *MerchantWebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	req, bindErr := handler.BindRequest[models.MerchantWebhookRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if _, authErr := h.authorize(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	hook, err := h.transactionService.UpdateMerchantWebhook(r.Context(), walletID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, hook)
+}
+
+// DeleteWebhook handles DELETE /api/v1/wallets/:walletId/merchant-webhook
+func (h *MerchantWebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if _, authErr := h.authorize(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	if err := h.transactionService.DeleteMerchantWebhook(r.Context(), walletID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// RotateSecret handles POST /api/v1/wallets/:walletId/merchant-webhook/rotate-secret
+func (h *MerchantWebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if _, authErr := h.authorize(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	hook, err := h.transactionService.RotateMerchantWebhookSecret(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, hook)
+}
+
+// ListDeliveries handles GET /api/v1/wallets/:walletId/merchant-webhook/deliveries
+// Query params: status (pending, delivered or failed), limit (default 50, max 200).
+func (h *MerchantWebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			response.Error(w, errors.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	status := models.MerchantWebhookDeliveryStatus(r.URL.Query().Get("status"))
+	deliveries, err := h.transactionService.ListMerchantWebhookDeliveries(r.Context(), walletID, status, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, deliveries)
+}
+
+// Redeliver handles POST /api/v1/wallets/:walletId/merchant-webhook/deliveries/:id/redeliver
+func (h *MerchantWebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if _, authErr := h.authorize(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	delivery, err := h.transactionService.RedeliverMerchantWebhook(r.Context(), walletID, r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, delivery)
+}
+
+// authorize checks the caller may manage the wallet and returns their user ID.
+// Webhooks expose every incoming payment, so they need admin access.
+func (h *MerchantWebhookHandler) authorize(r *http.Request, walletID string) (string, *errors.Error) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		return "", errors.Unauthorized("user not authenticated")
+	}
+	if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionAdmin); authErr != nil {
+		return "", authErr
+	}
+	return userID, nil
+}
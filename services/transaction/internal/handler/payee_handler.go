@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// PayeeHandler handles HTTP requests for recent and favorite payees.
+type PayeeHandler struct {
+	payeeService *service.PayeeService
+	walletClient *service.WalletClient
+}
+
+// NewPayeeHandler creates a new payee handler.
+func NewPayeeHandler(payeeService *service.PayeeService, walletClient *service.WalletClient) *PayeeHandler {
+	return &PayeeHandler{
+		payeeService: payeeService,
+		walletClient: walletClient,
+	}
+}
+
+// ListPayees handles GET /api/v1/wallets/:walletId/payees
+// Query params: limit (recent payees to return, default 10, max 50).
+func (h *PayeeHandler) ListPayees(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 {
+			response.Error(w, errors.BadRequest("limit must be a positive integer"))
+			return
+		}
+		limit = parsed
+	}
+
+	payees, err := h.payeeService.GetPayees(r.Context(), walletID, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, payees)
+}
+
+// PinPayee handles PUT /api/v1/wallets/:walletId/payees/:payeeWalletId/favorite
+func (h *PayeeHandler) PinPayee(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	payeeWalletID := r.PathValue("payeeWalletId")
+
+	if walletID == "" || payeeWalletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID and payee wallet ID are required"))
+		return
+	}
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	if err := h.payeeService.PinPayee(r.Context(), walletID, payeeWalletID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// UnpinPayee handles DELETE /api/v1/wallets/:walletId/payees/:payeeWalletId/favorite
+func (h *PayeeHandler) UnpinPayee(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	payeeWalletID := r.PathValue("payeeWalletId")
+
+	if walletID == "" || payeeWalletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID and payee wallet ID are required"))
+		return
+	}
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	if err := h.payeeService.UnpinPayee(r.Context(), walletID, payeeWalletID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
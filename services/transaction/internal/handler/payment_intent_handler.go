@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// PaymentIntentHandler handles HTTP requests for merchant payment intents:
+// merchants create and track them by order reference, payers confirm them.
+type PaymentIntentHandler struct {
+	transactionService *service.TransactionService
+	walletClient       *service.WalletClient
+}
+
+// NewPaymentIntentHandler creates a new payment intent handler.
+func NewPaymentIntentHandler(transactionService *service.TransactionService, walletClient *service.WalletClient) *PaymentIntentHandler {
+	return &PaymentIntentHandler{
+		transactionService: transactionService,
+		walletClient:       walletClient,
+	}
+}
+
+// CreateIntent handles POST /api/v1/wallets/:walletId/payment-intents
+// The response holds the deeplink and QR payload to show the payer.
+func (h *PaymentIntentHandler) CreateIntent(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	req, bindErr := handler.BindRequest[models.PaymentIntentRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+	if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	intent, err := h.transactionService.CreatePaymentIntent(r.Context(), walletID, userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, intent)
+}
+
+// ListIntents handles GET /api/v1/wallets/:walletId/payment-intents
+// Query params: status (pending, processing, paid or cancelled), limit (default 50, max 200).
+func (h *PaymentIntentHandler) ListIntents(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			response.Error(w, errors.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	status := models.PaymentIntentStatus(r.URL.Query().Get("status"))
+	intents, err := h.transactionService.ListPaymentIntents(r.Context(), walletID, status, limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, intents)
+}
+
+// GetIntent handles GET /api/v1/wallets/:walletId/payment-intents/:orderReference
+func (h *PaymentIntentHandler) GetIntent(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	intent, err := h.transactionService.GetPaymentIntentByOrder(r.Context(), walletID, r.PathValue("orderReference"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, intent)
+}
+
+// CancelIntent handles POST /api/v1/wallets/:walletId/payment-intents/:orderReference/cancel
+func (h *PaymentIntentHandler) CancelIntent(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	intent, err := h.transactionService.CancelPaymentIntent(r.Context(), walletID, r.PathValue("orderReference"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, intent)
+}
+
+// GetPayerView handles GET /api/v1/payment-intents/:id
+// Shows a payer what they are about to pay.
+func (h *PaymentIntentHandler) GetPayerView(w http.ResponseWriter, r *http.Request) {
+	intent, err := h.transactionService.GetPaymentIntent(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, intent)
+}
+
+// ConfirmIntent handles POST /api/v1/payment-intents/:id/confirm
+// The caller must be able to transact on the source wallet.
+func (h *PaymentIntentHandler) ConfirmIntent(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.ConfirmPaymentIntentRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if authErr := checkWalletAccess(r, h.walletClient, req.SourceWalletID, service.WalletPermissionTransact); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	result, err := h.transactionService.ConfirmPaymentIntent(r.Context(), r.PathValue("id"), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// SweepHandler handles HTTP requests for a wallet's auto-sweep rules.
+type SweepHandler struct {
+	transactionService *service.TransactionService
+	walletClient       *service.WalletClient
+}
+
+// NewSweepHandler creates a new sweep handler.
+func NewSweepHandler(transactionService *service.TransactionService, walletClient *service.WalletClient) *SweepHandler {
+	return &SweepHandler{
+		transactionService: transactionService,
+		walletClient:       walletClient,
+	}
+}
+
+// ListRules handles GET /api/v1/wallets/:walletId/sweep-rules
+func (h *SweepHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	rules, err := h.transactionService.ListSweepRules(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, rules)
+}
+
+// CreateRule handles POST /api/v1/wallets/:walletId/sweep-rules
+// The caller must be able to transact on both the wallet and the counterpart.
+func (h *SweepHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	req, bindErr := handler.BindRequest[models.SweepRuleRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	userID, authErr := h.authorize(r, walletID, req.CounterpartWalletID)
+	if authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	rule, err := h.transactionService.CreateSweepRule(r.Context(), walletID, userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Created(w, rule)
+}
+
+// UpdateRule handles PUT /api/v1/wallets/:walletId/sweep-rules/:id
+func (h *SweepHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	req, bindErr := handler.BindRequest[models.SweepRuleRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if _, authErr := h.authorize(r, walletID, req.CounterpartWalletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	rule, err := h.transactionService.UpdateSweepRule(r.Context(), walletID, r.PathValue("id"), &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, rule)
+}
+
+// DeleteRule handles DELETE /api/v1/wallets/:walletId/sweep-rules/:id
+func (h *SweepHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if _, authErr := h.authorize(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	if err := h.transactionService.DeleteSweepRule(r.Context(), walletID, r.PathValue("id")); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListExecutions handles GET /api/v1/wallets/:walletId/sweep-rules/executions
+// Query params: rule_id (one rule's history), limit (default 50, max 200).
+func (h *SweepHandler) ListExecutions(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if authErr := checkWalletOwnership(r, h.walletClient, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			response.Error(w, errors.BadRequest("limit must be between 1 and 200"))
+			return
+		}
+		limit = parsed
+	}
+
+	executions, err := h.transactionService.ListSweepExecutions(r.Context(), walletID, r.URL.Query().Get("rule_id"), limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, executions)
+}
+
+// authorize checks the caller may move money in every given wallet and
+// returns their user ID.
+func (h *SweepHandler) authorize(r *http.Request, walletIDs ...string) (string, *errors.Error) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		return "", errors.Unauthorized("user not authenticated")
+	}
+	for _, walletID := range walletIDs {
+		if authErr := checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact); authErr != nil {
+			return "", authErr
+		}
+	}
+	return userID, nil
+}
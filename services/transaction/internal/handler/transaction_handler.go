@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
@@ -30,18 +31,42 @@ func NewTransactionHandler(transactionService *service.TransactionService, walle
 
 // verifyWalletOwnership checks if the authenticated user owns the wallet.
 func (h *TransactionHandler) verifyWalletOwnership(r *http.Request, walletID string) *errors.Error {
+	return checkWalletOwnership(r, h.walletClient, walletID)
+}
+
+// checkWalletOwnership checks if the authenticated user owns the wallet.
+func checkWalletOwnership(r *http.Request, walletClient *service.WalletClient, walletID string) *errors.Error {
+	return checkWalletAccess(r, walletClient, walletID, service.WalletPermissionView)
+}
+
+// staffWalletPermission lets back-office staff and internal tooling move
+// money in any wallet.
+const staffWalletPermission = "wallet:wallet:list"
+
+// checkWalletAccess checks if the authenticated user owns the wallet or
+// co-owns it with at least the required permission.
+func checkWalletAccess(r *http.Request, walletClient *service.WalletClient, walletID, required string) *errors.Error {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
 		return errors.Unauthorized("user not authenticated")
 	}
 
-	if err := h.walletClient.VerifyWalletOwnership(r.Context(), walletID, userID); err != nil {
+	if err := walletClient.VerifyWalletAccess(r.Context(), walletID, userID, required); err != nil {
 		return errors.Forbidden("wallet does not belong to user")
 	}
 
 	return nil
 }
 
+// verifyWalletTransact checks if the authenticated user may move money out
+// of or into the wallet. Staff may act on any wallet.
+func (h *TransactionHandler) verifyWalletTransact(r *http.Request, walletID string) *errors.Error {
+	if middleware.HasPermission(r.Context(), staffWalletPermission) {
+		return nil
+	}
+	return checkWalletAccess(r, h.walletClient, walletID, service.WalletPermissionTransact)
+}
+
 // verifyTransactionOwnership checks if the transaction belongs to a wallet owned by the user.
 func (h *TransactionHandler) verifyTransactionOwnership(r *http.Request, transactionID string) *errors.Error {
 	userID, ok := middleware.GetUserID(r.Context())
@@ -80,6 +105,11 @@ func (h *TransactionHandler) CreateTransfer(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if authErr := h.verifyWalletTransact(r, req.SourceWalletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
 	transaction, createErr := h.transactionService.CreateTransfer(r.Context(), &req)
 	if createErr != nil {
 		response.Error(w, createErr)
@@ -89,6 +119,29 @@ func (h *TransactionHandler) CreateTransfer(w http.ResponseWriter, r *http.Reque
 	response.Created(w, transaction)
 }
 
+// QuoteTransfer handles POST /api/v1/transactions/quote
+func (h *TransactionHandler) QuoteTransfer(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.QuoteTransferRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	if authErr := h.verifyWalletTransact(r, req.SourceWalletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	userID, _ := middleware.GetUserID(r.Context())
+	quote, quoteErr := h.transactionService.QuoteTransfer(r.Context(), &req, userID)
+	if quoteErr != nil {
+		response.Error(w, quoteErr)
+		return
+	}
+
+	response.Created(w, quote)
+}
+
 // CreateDeposit handles POST /api/v1/transactions/deposit
 func (h *TransactionHandler) CreateDeposit(w http.ResponseWriter, r *http.Request) {
 	req, bindErr := handler.BindRequest[models.CreateDepositRequest](r)
@@ -97,6 +150,11 @@ func (h *TransactionHandler) CreateDeposit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if authErr := h.verifyWalletTransact(r, req.WalletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
 	transaction, createErr := h.transactionService.CreateDeposit(r.Context(), &req)
 	if createErr != nil {
 		response.Error(w, createErr)
@@ -148,13 +206,92 @@ func (h *TransactionHandler) CreateWithdrawal(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if authErr := h.verifyWalletTransact(r, req.WalletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
 	transaction, createErr := h.transactionService.CreateWithdrawal(r.Context(), &req)
 	if createErr != nil {
 		response.Error(w, createErr)
 		return
 	}
 
-	response.Created(w, transaction)
+	withdrawal := &models.WithdrawalResponse{Transaction: transaction}
+	if transaction.Status == models.TransactionStatusPendingApproval {
+		approval, err := h.transactionService.GetWithdrawalApproval(r.Context(), transaction.ID)
+		if err != nil {
+			response.Error(w, err)
+			return
+		}
+		withdrawal.Approval = approval
+	}
+
+	response.Created(w, withdrawal)
+}
+
+// GetWithdrawalApproval handles GET /api/v1/transactions/withdrawal/{id}/approval
+// Users see the approval of withdrawals from their own wallets; support
+// staff see any.
+func (h *TransactionHandler) GetWithdrawalApproval(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("id")
+
+	if !middleware.HasPermission(r.Context(), supportTransactionPermission) {
+		if authErr := h.verifyTransactionOwnership(r, transactionID); authErr != nil {
+			response.Error(w, authErr)
+			return
+		}
+	}
+
+	approval, err := h.transactionService.GetWithdrawalApproval(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, approval)
+}
+
+// ConfirmWithdrawal handles POST /api/v1/transactions/withdrawal/{id}/confirm
+// The body carries an identity verification token for the
+// withdrawal_confirmation operation.
+func (h *TransactionHandler) ConfirmWithdrawal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	req, bindErr := handler.BindRequest[models.ConfirmWithdrawalRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	withdrawal, err := h.transactionService.ConfirmWithdrawal(r.Context(), r.PathValue("id"), userID, &req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, withdrawal)
+}
+
+// CancelWithdrawal handles POST /api/v1/transactions/withdrawal/{id}/cancel
+func (h *TransactionHandler) CancelWithdrawal(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	withdrawal, err := h.transactionService.CancelWithdrawal(r.Context(), r.PathValue("id"), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, withdrawal)
 }
 
 // GetTransaction handles GET /api/v1/transactions/:id
@@ -172,7 +309,103 @@ func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	response.OK(w, transaction)
+	response.OKWithLinks(w, transaction, transactionLinks(r, transaction))
+}
+
+// supportTransactionPermission lets support staff read any transaction's
+// history.
+const supportTransactionPermission = "transaction:transaction:search"
+
+// GetTimeline handles GET /api/v1/transactions/{id}/timeline. Users see the
+// timeline of their own wallets' transactions; support staff see any.
+func (h *TransactionHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("id")
+
+	if transactionID == "" {
+		response.Error(w, errors.BadRequest("transaction ID is required"))
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), supportTransactionPermission) {
+		if authErr := h.verifyTransactionOwnership(r, transactionID); authErr != nil {
+			response.Error(w, authErr)
+			return
+		}
+	}
+
+	timeline, err := h.transactionService.GetTimeline(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, timeline)
+}
+
+// GetReceipt handles GET /api/v1/transactions/{id}/receipt. Users get
+// receipts for their own wallets' transactions; support staff for any.
+func (h *TransactionHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("id")
+
+	if transactionID == "" {
+		response.Error(w, errors.BadRequest("transaction ID is required"))
+		return
+	}
+
+	if !middleware.HasPermission(r.Context(), supportTransactionPermission) {
+		if authErr := h.verifyTransactionOwnership(r, transactionID); authErr != nil {
+			response.Error(w, authErr)
+			return
+		}
+	}
+
+	receipt, err := h.transactionService.GetReceipt(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, receipt)
+}
+
+// transactionLinks builds the actions available to the caller for a transaction,
+// based on their permissions and the transaction's current status.
+func transactionLinks(r *http.Request, tx *models.Transaction) response.Links {
+	ctx := r.Context()
+	self := "/api/v1/transactions/" + tx.ID
+	canUpdate := middleware.HasPermission(ctx, "transaction:transaction:update")
+
+	links := response.NewLinks().
+		Add("self", http.MethodGet, self).
+		Add("timeline", http.MethodGet, self+"/timeline").
+		AddIf(canUpdate, "update_category", http.MethodPatch, self+"/category").
+		AddIf(canUpdate, "auto_categorize", http.MethodPost, self+"/auto-categorize")
+
+	switch tx.Status {
+	case models.TransactionStatusPendingApproval:
+		links.AddIf(tx.Type == models.TransactionTypeWithdrawal && middleware.HasPermission(ctx, "transaction:withdrawal:create"),
+			"cancel", http.MethodPost, "/api/v1/transactions/withdrawal/"+tx.ID+"/cancel")
+	case models.TransactionStatusCompleted:
+		links.Add("receipt", http.MethodGet, self+"/receipt").
+			AddIf(tx.IsReversible() && middleware.HasPermission(ctx, "transaction:transaction:reverse"),
+				"reverse", http.MethodPost, self+"/reverse")
+	}
+	return links
+}
+
+// linkedTransaction is a transaction list item with its action links.
+type linkedTransaction struct {
+	*models.Transaction
+	Links response.Links `json:"links"`
+}
+
+// withLinks attaches each transaction's action links for list responses.
+func withLinks(r *http.Request, transactions []*models.Transaction) []linkedTransaction {
+	items := make([]linkedTransaction, len(transactions))
+	for i, tx := range transactions {
+		items[i] = linkedTransaction{Transaction: tx, Links: transactionLinks(r, tx)}
+	}
+	return items
 }
 
 // ListWalletTransactions handles GET /api/v1/wallets/:walletId/transactions
@@ -193,6 +426,7 @@ func (h *TransactionHandler) ListWalletTransactions(w http.ResponseWriter, r *ht
 		// Validate status is a known value
 		validStatuses := []models.TransactionStatus{
 			models.TransactionStatusPending,
+			models.TransactionStatusPendingApproval,
 			models.TransactionStatusProcessing,
 			models.TransactionStatusCompleted,
 			models.TransactionStatusFailed,
@@ -303,7 +537,7 @@ func (h *TransactionHandler) ListWalletTransactions(w http.ResponseWriter, r *ht
 		return
 	}
 
-	response.OK(w, transactions)
+	response.OK(w, withLinks(r, transactions))
 }
 
 // SearchAllTransactions handles GET /api/v1/admin/transactions/search (admin operation)
@@ -392,10 +626,41 @@ func (h *TransactionHandler) SearchAllTransactions(w http.ResponseWriter, r *htt
 		return
 	}
 
-	response.OK(w, transactions)
+	response.OK(w, withLinks(r, transactions))
+}
+
+// ListRiskBypassedTransactions handles GET /api/v1/admin/transactions/risk-bypassed
+// Lists transactions that proceeded without a risk decision. Pass
+// unresolved=true to show only those not yet re-scored.
+func (h *TransactionHandler) ListRiskBypassedTransactions(w http.ResponseWriter, r *http.Request) {
+	filter := &models.RiskBypassFilter{
+		Unresolved: r.URL.Query().Get("unresolved") == "true",
+		Limit:      config.DefaultPageLimit,
+	}
+
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		if limit, err := strconv.Atoi(limitParam); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+
+	if offsetParam := r.URL.Query().Get("offset"); offsetParam != "" {
+		if offset, err := strconv.Atoi(offsetParam); err == nil && offset >= 0 {
+			filter.Offset = offset
+		}
+	}
+
+	transactions, err := h.transactionService.ListRiskBypassedTransactions(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, withLinks(r, transactions))
 }
 
 // ReverseTransaction handles POST /api/v1/transactions/:id/reverse
+// Reversals at or above the approval threshold return 202 with a pending approval.
 func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
 	transactionID := r.PathValue("id")
 
@@ -410,12 +675,19 @@ func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	reversalTx, reverseErr := h.transactionService.ReverseTransaction(r.Context(), transactionID, req.Reason)
+	requestedBy, _ := middleware.GetUserID(r.Context())
+	reversalTx, pending, reverseErr := h.transactionService.RequestReversal(r.Context(), transactionID, req.Reason, requestedBy)
 	if reverseErr != nil {
 		response.Error(w, reverseErr)
 		return
 	}
 
+	// Large reversals wait for a second admin
+	if pending != nil {
+		response.JSON(w, http.StatusAccepted, pending)
+		return
+	}
+
 	response.Created(w, reversalTx)
 }
 
@@ -442,6 +714,79 @@ func (h *TransactionHandler) ProcessTransfer(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// CreateClosureSweep handles POST /internal/v1/transactions/closure-sweeps (internal endpoint)
+// This endpoint is called by the wallet service to sweep the balance of a wallet being closed.
+func (h *TransactionHandler) CreateClosureSweep(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.ClosureSweepRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	transaction, sweepErr := h.transactionService.CreateClosureSweep(r.Context(), &req)
+	if sweepErr != nil {
+		response.Error(w, sweepErr)
+		return
+	}
+
+	response.Created(w, transaction)
+}
+
+// CreateFeeCharge handles POST /internal/v1/transactions/fees (internal endpoint)
+// This endpoint is called by other services to charge a platform fee, such as
+// the identity service for tier upgrade fees.
+func (h *TransactionHandler) CreateFeeCharge(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.FeeChargeRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	transaction, feeErr := h.transactionService.CreateFeeCharge(r.Context(), &req)
+	if feeErr != nil {
+		response.Error(w, feeErr)
+		return
+	}
+
+	response.Created(w, transaction)
+}
+
+// CreateStuckTransfer handles POST /internal/v1/simulation/stuck-transfers
+// This endpoint is called by the simulation service to inject a stuck pending transfer.
+func (h *TransactionHandler) CreateStuckTransfer(w http.ResponseWriter, r *http.Request) {
+	req, bindErr := handler.BindRequest[models.StuckTransferRequest](r)
+	if bindErr != nil {
+		response.Error(w, bindErr)
+		return
+	}
+
+	transaction, createErr := h.transactionService.CreateStuckTransfer(r.Context(), req.Amount)
+	if createErr != nil {
+		response.Error(w, createErr)
+		return
+	}
+
+	response.Created(w, transaction)
+}
+
+// DeleteStuckTransfer handles DELETE /internal/v1/simulation/stuck-transfers/:id
+// This endpoint is called by the simulation service to roll back a stuck transfer.
+func (h *TransactionHandler) DeleteStuckTransfer(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("id")
+
+	if transactionID == "" {
+		response.Error(w, errors.BadRequest("transaction ID is required"))
+		return
+	}
+
+	if err := h.transactionService.DeleteStuckTransfer(r.Context(), transactionID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
 // ========================================================================
 // Spending Category Endpoints
 // ========================================================================
@@ -672,6 +1017,83 @@ func (h *TransactionHandler) ExportStatementPDF(w http.ResponseWriter, r *http.R
 	_, _ = w.Write(pdfContent)
 }
 
+// ExportTransactions handles GET /api/v1/wallets/:walletId/transactions/export
+// Exports the wallet's completed transactions as CSV, OFX or QIF (?format=, default csv)
+// for import into personal finance and accounting tools.
+func (h *TransactionHandler) ExportTransactions(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = service.ExportFormatCSV
+	}
+	if format != service.ExportFormatCSV && format != service.ExportFormatOFX && format != service.ExportFormatQIF {
+		response.Error(w, errors.BadRequest("format must be one of: csv, ofx, qif"))
+		return
+	}
+
+	// Verify wallet ownership
+	if authErr := h.verifyWalletOwnership(r, walletID); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	// Parse date range from query params
+	startDate := r.URL.Query().Get("start_date")
+	endDate := r.URL.Query().Get("end_date")
+
+	// Default to current month if not provided
+	if startDate == "" {
+		startDate = firstDayOfMonth()
+	}
+	if endDate == "" {
+		endDate = lastDayOfMonth()
+	}
+
+	// Validate date range
+	if dateErr := validateDateRange(startDate, endDate); dateErr != nil {
+		response.Error(w, dateErr)
+		return
+	}
+
+	data, err := h.transactionService.GetStatementData(r.Context(), walletID, startDate, endDate)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	var content []byte
+	var contentType string
+	switch format {
+	case service.ExportFormatOFX:
+		// Ledger balance is optional in the export; skip it if the wallet service is unavailable
+		if balance, balanceErr := h.walletClient.GetBalance(r.Context(), walletID); balanceErr == nil {
+			data.ClosingBalance = &balance.Balance
+		}
+		content = h.transactionService.GenerateOFX(data)
+		contentType = "application/x-ofx"
+	case service.ExportFormatQIF:
+		content = h.transactionService.GenerateQIF(data)
+		contentType = "application/qif"
+	default:
+		content = h.transactionService.GenerateCSV(data)
+		contentType = "text/csv"
+	}
+
+	// Set response headers for file download
+	filename := "transactions_" + safeIDPrefix(walletID) + "_" + startDate + "_" + endDate + "." + format
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+filename)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+
+	_, _ = w.Write(content)
+}
+
 // GetStatementJSON handles GET /api/v1/wallets/:walletId/statements/json
 // Returns statement data in JSON format for frontend rendering
 func (h *TransactionHandler) GetStatementJSON(w http.ResponseWriter, r *http.Request) {
@@ -15,6 +15,7 @@ import (
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 )
 
@@ -33,7 +34,7 @@ type mockTransactionRepository struct {
 	UpdateMetadataFunc      func(ctx context.Context, id string, metadata map[string]string) *errors.Error
 	CompleteFunc            func(ctx context.Context, id string, metadata map[string]string) *errors.Error
 	UpdateStatusFunc        func(ctx context.Context, id string, status models.TransactionStatus, failureReason *string) *errors.Error
-	UpdateCategoryFunc      func(ctx context.Context, id string, category models.SpendingCategory) *errors.Error
+	UpdateCategoryFunc      func(ctx context.Context, id string, category models.SpendingCategory, overridden bool) *errors.Error
 	GetCategoryPatternsFunc func(ctx context.Context) ([]*models.CategoryPattern, *errors.Error)
 	GetCategorySummaryFunc  func(ctx context.Context, walletID string, startDate, endDate string) ([]models.CategorySummary, *errors.Error)
 }
@@ -130,9 +131,9 @@ func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, id string,
 	return errors.NotFound("transaction not found")
 }
 
-func (m *mockTransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory) *errors.Error {
+func (m *mockTransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory, overridden bool) *errors.Error {
 	if m.UpdateCategoryFunc != nil {
-		return m.UpdateCategoryFunc(ctx, id, category)
+		return m.UpdateCategoryFunc(ctx, id, category, overridden)
 	}
 	if tx, ok := m.transactions[id]; ok {
 		tx.Category = category
@@ -166,9 +167,10 @@ func (m *mockTransactionRepository) AddTransaction(tx *models.Transaction) {
 
 // apiResponse represents the standard API response.
 type apiResponse struct {
-	Success bool            `json:"success"`
-	Data    json.RawMessage `json:"data,omitempty"`
-	Error   *apiError       `json:"error,omitempty"`
+	Success bool                         `json:"success"`
+	Data    json.RawMessage              `json:"data,omitempty"`
+	Error   *apiError                    `json:"error,omitempty"`
+	Links   map[string]map[string]string `json:"links,omitempty"`
 }
 
 type apiError struct {
@@ -303,6 +305,100 @@ func TestTransactionHandler_GetTransaction(t *testing.T) {
 
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	})
+
+	t.Run("links reflect caller permissions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/tx-get-test", nil)
+		req.SetPathValue("id", "tx-get-test")
+		ctx := context.WithValue(req.Context(), middleware.UserPermissionsKey, []string{
+			"transaction:transaction:read",
+			"transaction:transaction:reverse",
+		})
+		rec := httptest.NewRecorder()
+		handler.GetTransaction(rec, req.WithContext(ctx))
+
+		var resp apiResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "/api/v1/transactions/tx-get-test", resp.Links["self"]["href"])
+		assert.Equal(t, http.MethodPost, resp.Links["reverse"]["method"])
+		assert.Equal(t, "/api/v1/transactions/tx-get-test/receipt", resp.Links["receipt"]["href"])
+		assert.NotContains(t, resp.Links, "update_category")
+		assert.NotContains(t, resp.Links, "cancel")
+	})
+
+	t.Run("reverse link omitted without permission", func(t *testing.T) {
+		_, resp := makeRequestWithPathValue(t, handler.GetTransaction, http.MethodGet, "/api/v1/transactions/tx-get-test", "id", "tx-get-test", nil)
+
+		assert.Contains(t, resp.Links, "self")
+		assert.NotContains(t, resp.Links, "reverse")
+	})
+
+	t.Run("held withdrawal links to cancel instead of receipt", func(t *testing.T) {
+		txRepo.AddTransaction(&models.Transaction{
+			ID:             "tx-held-withdrawal",
+			Type:           models.TransactionTypeWithdrawal,
+			Status:         models.TransactionStatusPendingApproval,
+			SourceWalletID: &sourceWallet,
+			Amount:         5000000,
+			Currency:       "INR",
+		})
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/tx-held-withdrawal", nil)
+		req.SetPathValue("id", "tx-held-withdrawal")
+		ctx := context.WithValue(req.Context(), middleware.UserPermissionsKey, []string{
+			"transaction:transaction:read",
+			"transaction:transaction:reverse",
+			"transaction:withdrawal:create",
+		})
+		rec := httptest.NewRecorder()
+		handler.GetTransaction(rec, req.WithContext(ctx))
+
+		var resp apiResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "/api/v1/transactions/withdrawal/tx-held-withdrawal/cancel", resp.Links["cancel"]["href"])
+		assert.NotContains(t, resp.Links, "receipt")
+		assert.NotContains(t, resp.Links, "reverse")
+	})
+}
+
+func TestTransactionHandler_GetReceipt(t *testing.T) {
+	txService, txRepo := createTestTransactionService()
+	handler := NewTransactionHandler(txService, nil)
+
+	walletID := "wallet-receipt-test"
+	txRepo.AddTransaction(&models.Transaction{
+		ID: "tx-receipt-completed", Type: models.TransactionTypeDeposit, Status: models.TransactionStatusCompleted,
+		DestinationWalletID: &walletID, Amount: 250000, Currency: "INR", Description: "Test deposit",
+	})
+	txRepo.AddTransaction(&models.Transaction{
+		ID: "tx-receipt-pending", Type: models.TransactionTypeDeposit, Status: models.TransactionStatusPending,
+		DestinationWalletID: &walletID, Amount: 250000, Currency: "INR", Description: "Test deposit",
+	})
+
+	// Support staff skip the ownership check, which needs the wallet service
+	getReceipt := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions/"+id+"/receipt", nil)
+		req.SetPathValue("id", id)
+		ctx := context.WithValue(req.Context(), middleware.UserPermissionsKey, []string{supportTransactionPermission})
+		rec := httptest.NewRecorder()
+		handler.GetReceipt(rec, req.WithContext(ctx))
+		return rec
+	}
+
+	t.Run("completed transaction has a receipt", func(t *testing.T) {
+		rec := getReceipt("tx-receipt-completed")
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var resp apiResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		var receipt models.TransactionReceipt
+		require.NoError(t, json.Unmarshal(resp.Data, &receipt))
+		assert.Equal(t, "tx-receipt-completed", receipt.TransactionID)
+		assert.Equal(t, int64(250000), receipt.Amount)
+	})
+
+	t.Run("pending transaction has no receipt", func(t *testing.T) {
+		rec := getReceipt("tx-receipt-pending")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
 }
 
 func TestTransactionHandler_ListWalletTransactions(t *testing.T) {
@@ -343,6 +439,14 @@ func TestTransactionHandler_ListWalletTransactions(t *testing.T) {
 		err := json.Unmarshal(resp.Data, &transactions)
 		require.NoError(t, err)
 		assert.Len(t, transactions, 2)
+
+		// Each item carries the links for its own status
+		for _, tx := range transactions {
+			links, ok := tx["links"].(map[string]interface{})
+			require.True(t, ok, "transaction %v has no links", tx["id"])
+			assert.Contains(t, links, "self")
+			assert.Contains(t, links, "receipt")
+		}
 	})
 
 	t.Run("list wallet transactions without wallet ID returns 400", func(t *testing.T) {
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// AmountOperation is what an amount policy applies to: a transaction type,
+// or a channel of one such as UPI deposits.
+type AmountOperation string
+
+const (
+	AmountOperationTransfer   AmountOperation = "transfer"
+	AmountOperationDeposit    AmountOperation = "deposit"
+	AmountOperationUPIDeposit AmountOperation = "upi_deposit"
+	AmountOperationWithdrawal AmountOperation = "withdrawal"
+)
+
+// AmountPolicy bounds the amount of an operation in one currency. Amounts are
+// in the currency's minor units; a nil MaxAmount means no maximum.
+type AmountPolicy struct {
+	ID        string           `json:"id" db:"id"`
+	Operation AmountOperation  `json:"operation" db:"operation"`
+	Currency  models.Currency  `json:"currency" db:"currency"`
+	MinAmount int64            `json:"min_amount" db:"min_amount"`
+	MaxAmount *int64           `json:"max_amount,omitempty" db:"max_amount"`
+	UpdatedBy *string          `json:"updated_by,omitempty" db:"updated_by"`
+	CreatedAt models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// AmountPolicyRequest creates or replaces the policy of an operation and currency.
+type AmountPolicyRequest struct {
+	Operation AmountOperation `json:"operation" validate:"required,oneof=transfer deposit upi_deposit withdrawal"`
+	Currency  models.Currency `json:"currency" validate:"required,len=3"`
+	MinAmount int64           `json:"min_amount" validate:"gte=0"`
+	MaxAmount *int64          `json:"max_amount,omitempty"`
+}
+
+// AmountCheckRequest is an internal request from another service to check an
+// amount against the policy of its operation, e.g. wallet's UPI deposits.
+type AmountCheckRequest struct {
+	Operation AmountOperation `json:"operation" validate:"required,oneof=transfer deposit upi_deposit withdrawal"`
+	Currency  models.Currency `json:"currency" validate:"required,len=3"`
+	Amount    int64           `json:"amount" validate:"required,gt=0"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// CounterpartyKind is who the other side of a transfer is, from the viewing
+// wallet's point of view.
+type CounterpartyKind string
+
+const (
+	CounterpartyOwnWallet CounterpartyKind = "own_wallet" // Another wallet of the viewing wallet's owners
+	CounterpartyUser      CounterpartyKind = "user"       // A wallet of another user
+	CounterpartyUnknown   CounterpartyKind = "unknown"    // Could not be resolved; only a masked wallet ID is shown
+)
+
+// Counterparty directions.
+const (
+	CounterpartyDirectionTo   = "to"   // Money went to the counterparty
+	CounterpartyDirectionFrom = "from" // Money came from the counterparty
+)
+
+// Counterparty describes the other side of a transfer so clients can render
+// "To: Priya S." without looking up the wallet. It never carries another
+// user's ID, full name or contact details.
+type Counterparty struct {
+	WalletID         string           `json:"wallet_id"`
+	Direction        string           `json:"direction"` // to or from
+	Kind             CounterpartyKind `json:"kind"`
+	DisplayName      string           `json:"display_name"`                // "Priya S.", the wallet's nickname, "Your wallet" or "Wallet ••••1a2b"
+	MaskedIdentifier string           `json:"masked_identifier,omitempty"` // Other users' masked phone, e.g. "******3210"
+}
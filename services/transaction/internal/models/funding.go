@@ -0,0 +1,123 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// FundingSourceStatus is the state of a linked bank account.
+type FundingSourceStatus string
+
+const (
+	FundingSourceActive  FundingSourceStatus = "active"
+	FundingSourceRemoved FundingSourceStatus = "removed" // Unlinked by the user; kept for history
+)
+
+// FundingSource is a bank account a user linked to fund their wallets. Bank
+// accounts are simulated: no money leaves a real bank.
+type FundingSource struct {
+	ID                string              `json:"id" db:"id"`
+	UserID            string              `json:"user_id" db:"user_id"`
+	BankName          string              `json:"bank_name" db:"bank_name"`
+	AccountHolderName string              `json:"account_holder_name" db:"account_holder_name"`
+	AccountLast4      string              `json:"account_last4" db:"account_last4"` // The full account number is not stored
+	IFSC              string              `json:"ifsc" db:"ifsc"`
+	Status            FundingSourceStatus `json:"status" db:"status"`
+	CreatedAt         models.Timestamp    `json:"created_at" db:"created_at"`
+	RemovedAt         *models.Timestamp   `json:"removed_at,omitempty" db:"removed_at"`
+}
+
+// MaskedAccount returns the account number as shown to users, e.g. "••1234".
+func (f *FundingSource) MaskedAccount() string {
+	return "••" + f.AccountLast4
+}
+
+// LinkFundingSourceRequest links a bank account.
+type LinkFundingSourceRequest struct {
+	BankName          string `json:"bank_name" validate:"required,min=2,max=100"`
+	AccountHolderName string `json:"account_holder_name" validate:"required,min=2,max=100"`
+	AccountNumber     string `json:"account_number" validate:"required,numeric,min=9,max=18"`
+	IFSC              string `json:"ifsc" validate:"required,ifsc"`
+}
+
+// MandateStatus is the state of a debit mandate.
+type MandateStatus string
+
+const (
+	MandateActive    MandateStatus = "active"
+	MandateCancelled MandateStatus = "cancelled" // By the user, or by removing its funding source
+)
+
+// Mandate authorizes debits from a funding source into one wallet, within a
+// per-debit maximum and a calendar-month limit, until it expires or is
+// cancelled.
+type Mandate struct {
+	ID              string            `json:"id" db:"id"`
+	UserID          string            `json:"user_id" db:"user_id"`
+	FundingSourceID string            `json:"funding_source_id" db:"funding_source_id"`
+	WalletID        string            `json:"wallet_id" db:"wallet_id"`
+	MaxAmount       int64             `json:"max_amount" db:"max_amount"`       // Largest single debit
+	MonthlyLimit    int64             `json:"monthly_limit" db:"monthly_limit"` // Total debits per calendar month (UTC)
+	ValidUntil      *models.Timestamp `json:"valid_until,omitempty" db:"valid_until"`
+	Status          MandateStatus     `json:"status" db:"status"`
+	CreatedAt       models.Timestamp  `json:"created_at" db:"created_at"`
+	CancelledAt     *models.Timestamp `json:"cancelled_at,omitempty" db:"cancelled_at"`
+}
+
+// Usable reports whether the mandate allows debits at now.
+func (m *Mandate) Usable(now time.Time) bool {
+	return m.Status == MandateActive && (m.ValidUntil == nil || now.Before(m.ValidUntil.Time))
+}
+
+// CreateMandateRequest authorizes debits from a funding source into a wallet.
+type CreateMandateRequest struct {
+	FundingSourceID string     `json:"funding_source_id" validate:"required,uuid"`
+	WalletID        string     `json:"wallet_id" validate:"required,uuid"`
+	MaxAmount       int64      `json:"max_amount" validate:"required,gt=0"`
+	MonthlyLimit    int64      `json:"monthly_limit" validate:"required,gt=0"`
+	ValidUntil      *time.Time `json:"valid_until,omitempty"`
+}
+
+// MetaAutoTopUpRuleID is the metadata key marking a deposit made by an auto
+// top-up rule.
+const MetaAutoTopUpRuleID = "auto_top_up_rule_id"
+
+// AutoTopUpRule deposits Amount into a wallet from its mandate's funding
+// source whenever the wallet's available balance falls below Threshold. A
+// wallet has at most one rule.
+type AutoTopUpRule struct {
+	ID             string            `json:"id" db:"id"`
+	WalletID       string            `json:"wallet_id" db:"wallet_id"`
+	MandateID      string            `json:"mandate_id" db:"mandate_id"`
+	Threshold      int64             `json:"threshold" db:"threshold"`
+	Amount         int64             `json:"amount" db:"amount"`
+	Enabled        bool              `json:"enabled" db:"enabled"`
+	CreatedBy      string            `json:"created_by" db:"created_by"`
+	LastExecutedAt *models.Timestamp `json:"last_executed_at,omitempty" db:"last_executed_at"`
+	CreatedAt      models.Timestamp  `json:"created_at" db:"created_at"`
+	UpdatedAt      models.Timestamp  `json:"updated_at" db:"updated_at"`
+}
+
+// AutoTopUpRequest creates or replaces a wallet's auto top-up rule.
+type AutoTopUpRequest struct {
+	MandateID string `json:"mandate_id" validate:"required,uuid"`
+	Threshold int64  `json:"threshold" validate:"gte=0"`
+	Amount    int64  `json:"amount" validate:"required,gt=0"`
+	Enabled   *bool  `json:"enabled,omitempty"` // Default true
+}
+
+// AutoTopUpExecution records one deposit an auto top-up rule attempted. The
+// executions that completed count toward their mandate's monthly limit.
+type AutoTopUpExecution struct {
+	ID            string               `json:"id" db:"id"`
+	RuleID        string               `json:"rule_id" db:"rule_id"`
+	WalletID      string               `json:"wallet_id" db:"wallet_id"`
+	MandateID     string               `json:"mandate_id" db:"mandate_id"`
+	BalanceBefore int64                `json:"balance_before" db:"balance_before"`
+	Amount        int64                `json:"amount" db:"amount"`
+	Status        SweepExecutionStatus `json:"status" db:"status"`
+	TransactionID *string              `json:"transaction_id,omitempty" db:"transaction_id"`
+	FailureReason *string              `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     models.Timestamp     `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// LedgerDiscrepancy is a completed transaction the ledger could not confirm.
+type LedgerDiscrepancy struct {
+	TransactionID  string   `json:"transaction_id"`
+	Status         string   `json:"status"` // Ledger verification status, e.g. missing, amount_mismatch
+	ExpectedAmount int64    `json:"expected_amount"`
+	PostedAmount   int64    `json:"posted_amount"`
+	Detail         string   `json:"detail,omitempty"`
+	EntryIDs       []string `json:"entry_ids,omitempty"`
+}
+
+// LedgerAuditReport summarizes a ledger audit over a window of completed transactions.
+type LedgerAuditReport struct {
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Checked       int                 `json:"checked"`
+	Matched       int                 `json:"matched"`
+	Discrepancies []LedgerDiscrepancy `json:"discrepancies"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Merchant webhook request headers sent with every delivery, alongside
+// X-Nivo-Signature.
+const (
+	MerchantWebhookDeliveryIDHeader = "X-Nivo-Delivery-Id" // Stable across retries; use it to deduplicate
+	MerchantWebhookAttemptHeader    = "X-Nivo-Delivery-Attempt"
+)
+
+// MerchantPaymentReceivedEvent is the event type of a payment notification.
+const MerchantPaymentReceivedEvent = "payment.received"
+
+// MerchantWebhook is a wallet's callback URL for incoming payments.
+type MerchantWebhook struct {
+	ID        string           `json:"id" db:"id"`
+	WalletID  string           `json:"wallet_id" db:"wallet_id"`
+	URL       string           `json:"url" db:"url"`
+	Secret    string           `json:"-" db:"secret"` // Signing key, only returned on create and rotate
+	Enabled   bool             `json:"enabled" db:"enabled"`
+	CreatedBy string           `json:"created_by" db:"created_by"`
+	CreatedAt models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantWebhookWithSecret is returned when a signing secret is issued. The
+// secret cannot be read back afterwards.
+type MerchantWebhookWithSecret struct {
+	*MerchantWebhook
+	Secret string `json:"secret"`
+}
+
+// MerchantWebhookRequest registers or replaces a wallet's callback URL.
+type MerchantWebhookRequest struct {
+	URL     string `json:"url" validate:"required,max=2048"`
+	Enabled *bool  `json:"enabled,omitempty"` // Default true
+}
+
+// MerchantWebhookDeliveryStatus is the state of a payment notification.
+type MerchantWebhookDeliveryStatus string
+
+const (
+	MerchantWebhookDeliveryPending   MerchantWebhookDeliveryStatus = "pending"   // Waiting for its next attempt
+	MerchantWebhookDeliveryDelivered MerchantWebhookDeliveryStatus = "delivered" // Accepted with a 2xx response
+	MerchantWebhookDeliveryFailed    MerchantWebhookDeliveryStatus = "failed"    // Rejected, or out of attempts
+)
+
+// MerchantWebhookDelivery is a payment notification queued for a wallet's
+// webhook, with the outcome of its latest attempt.
+type MerchantWebhookDelivery struct {
+	ID             string                        `json:"id" db:"id"`
+	WebhookID      string                        `json:"webhook_id" db:"webhook_id"`
+	WalletID       string                        `json:"wallet_id" db:"wallet_id"`
+	TransactionID  string                        `json:"transaction_id" db:"transaction_id"`
+	Payload        json.RawMessage               `json:"payload" db:"payload"`
+	Status         MerchantWebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                           `json:"attempts" db:"attempts"`
+	NextAttemptAt  models.Timestamp              `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int                          `json:"last_status_code,omitempty" db:"last_status_code"` // Null when no response was received
+	LastError      *string                       `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *models.Timestamp             `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      models.Timestamp              `json:"created_at" db:"created_at"`
+	UpdatedAt      models.Timestamp              `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantPaymentPayload is the JSON body POSTed to a merchant webhook when a
+// transfer into the wallet completes.
+type MerchantPaymentPayload struct {
+	Event           string           `json:"event"`
+	TransactionID   string           `json:"transaction_id"`
+	WalletID        string           `json:"wallet_id"`
+	PayerWalletID   *string          `json:"payer_wallet_id,omitempty"`
+	PayerReference  *string          `json:"payer_reference,omitempty"`   // Reference the payer gave the transfer, e.g. an order ID
+	PaymentIntentID *string          `json:"payment_intent_id,omitempty"` // Set when the transfer paid one of the merchant's payment intents
+	OrderReference  *string          `json:"order_reference,omitempty"`   // The paid intent's order reference
+	Amount          int64            `json:"amount"`                      // In smallest unit (paise)
+	Currency        models.Currency  `json:"currency"`
+	Description     string           `json:"description"`
+	CompletedAt     models.Timestamp `json:"completed_at"`
+}
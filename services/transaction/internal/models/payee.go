@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Payee limits for the quick-pay list.
+const (
+	DefaultRecentPayees = 10
+	MaxRecentPayees     = 50
+)
+
+// Payee is a wallet this wallet has paid, aggregated from completed transfers.
+type Payee struct {
+	WalletID          string            `json:"wallet_id"`
+	IsFavorite        bool              `json:"is_favorite"`
+	PinnedAt          *models.Timestamp `json:"pinned_at,omitempty"`
+	TransferCount     int               `json:"transfer_count"`
+	TotalAmount       int64             `json:"total_amount"`
+	LastAmount        int64             `json:"last_amount,omitempty"`
+	LastDescription   string            `json:"last_description,omitempty"`
+	LastTransferredAt *models.Timestamp `json:"last_transferred_at,omitempty"`
+}
+
+// PayeeList is the quick-pay list for a wallet.
+// Favorites are listed by pin time; recent payees exclude favorites.
+type PayeeList struct {
+	WalletID  string   `json:"wallet_id"`
+	Favorites []*Payee `json:"favorites"`
+	Recent    []*Payee `json:"recent"`
+}
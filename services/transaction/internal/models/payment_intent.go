@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// PaymentIntentStatus is the state of a merchant payment intent.
+type PaymentIntentStatus string
+
+const (
+	PaymentIntentPending    PaymentIntentStatus = "pending"    // Waiting for the payer
+	PaymentIntentProcessing PaymentIntentStatus = "processing" // A payer's transfer is under way
+	PaymentIntentPaid       PaymentIntentStatus = "paid"
+	PaymentIntentCancelled  PaymentIntentStatus = "cancelled" // Withdrawn by the merchant
+	PaymentIntentExpired    PaymentIntentStatus = "expired"   // Not paid in time; never stored, derived from ExpiresAt
+)
+
+// MetaPaymentIntentID is the metadata key marking a transfer that paid a
+// merchant payment intent.
+const MetaPaymentIntentID = "payment_intent_id"
+
+// Payment intent lifetimes.
+const (
+	DefaultPaymentIntentTTL = 30 * time.Minute
+	MaxPaymentIntentTTL     = 7 * 24 * time.Hour
+)
+
+// PaymentIntent is a payment a merchant requests into its wallet for one of
+// its orders. The order reference is unique per wallet and is the transfer's
+// reference once paid.
+type PaymentIntent struct {
+	ID             string              `json:"id" db:"id"`
+	WalletID       string              `json:"wallet_id" db:"wallet_id"` // Merchant wallet paid into
+	OrderReference string              `json:"order_reference" db:"order_reference"`
+	Amount         int64               `json:"amount" db:"amount"` // In smallest unit (paise)
+	Currency       models.Currency     `json:"currency" db:"currency"`
+	Description    string              `json:"description" db:"description"`
+	Status         PaymentIntentStatus `json:"status" db:"status"`
+	PayerWalletID  *string             `json:"payer_wallet_id,omitempty" db:"payer_wallet_id"`
+	TransactionID  *string             `json:"transaction_id,omitempty" db:"transaction_id"` // Latest payment attempt
+	FailureReason  *string             `json:"failure_reason,omitempty" db:"failure_reason"` // Why the latest attempt failed
+	ExpiresAt      models.Timestamp    `json:"expires_at" db:"expires_at"`
+	PaidAt         *models.Timestamp   `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedBy      string              `json:"created_by" db:"created_by"`
+	CreatedAt      models.Timestamp    `json:"created_at" db:"created_at"`
+	UpdatedAt      models.Timestamp    `json:"updated_at" db:"updated_at"`
+}
+
+// Expired reports whether the intent can no longer be paid because its time
+// ran out.
+func (p *PaymentIntent) Expired(now time.Time) bool {
+	return p.Status == PaymentIntentPending && !now.Before(p.ExpiresAt.Time)
+}
+
+// ResolveStatus reports a pending intent past its expiry as expired.
+func (p *PaymentIntent) ResolveStatus(now time.Time) {
+	if p.Expired(now) {
+		p.Status = PaymentIntentExpired
+	}
+}
+
+// PaymentIntentRequest creates a payment intent for a merchant order.
+type PaymentIntentRequest struct {
+	OrderReference   string `json:"order_reference" validate:"required,max=100"`
+	Amount           int64  `json:"amount" validate:"required,gt=0"`
+	Description      string `json:"description,omitempty" validate:"max=255"`
+	ExpiresInSeconds int    `json:"expires_in_seconds,omitempty" validate:"gte=0"` // Default DefaultPaymentIntentTTL
+}
+
+// PaymentIntentWithPayload is a new payment intent with what the payer needs
+// to pay it.
+type PaymentIntentWithPayload struct {
+	*PaymentIntent
+	Deeplink  string `json:"deeplink"`   // Opens the payment in the app
+	QRPayload string `json:"qr_payload"` // Text to encode in the QR code shown to the payer
+}
+
+// PaymentIntentSummary is what a payer sees of a payment intent before
+// confirming it.
+type PaymentIntentSummary struct {
+	ID             string              `json:"id"`
+	WalletID       string              `json:"wallet_id"`
+	OrderReference string              `json:"order_reference"`
+	Amount         int64               `json:"amount"`
+	Currency       models.Currency     `json:"currency"`
+	Description    string              `json:"description"`
+	Status         PaymentIntentStatus `json:"status"`
+	ExpiresAt      models.Timestamp    `json:"expires_at"`
+}
+
+// Summary returns the payer's view of the intent.
+func (p *PaymentIntent) Summary() *PaymentIntentSummary {
+	return &PaymentIntentSummary{
+		ID:             p.ID,
+		WalletID:       p.WalletID,
+		OrderReference: p.OrderReference,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		Description:    p.Description,
+		Status:         p.Status,
+		ExpiresAt:      p.ExpiresAt,
+	}
+}
+
+// ConfirmPaymentIntentRequest pays a payment intent from the payer's wallet.
+type ConfirmPaymentIntentRequest struct {
+	SourceWalletID string `json:"source_wallet_id" validate:"required,uuid"`
+}
+
+// ConfirmPaymentIntentResponse is a paid intent with the transfer that paid it.
+type ConfirmPaymentIntentResponse struct {
+	Intent      *PaymentIntentSummary `json:"intent"`
+	Transaction *Transaction          `json:"transaction"`
+}
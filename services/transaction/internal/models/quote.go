@@ -0,0 +1,87 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Metadata keys recorded on a transfer made with a quote.
+const (
+	MetaQuoteID       = "quote_id"
+	MetaQuoteAmount   = "quoted_amount"
+	MetaQuoteCurrency = "quoted_currency"
+	MetaQuoteFXRate   = "quote_fx_rate"
+	MetaQuoteFee      = "quote_fee"
+)
+
+// Fee types reported on a quote.
+const (
+	FeeTypeFXMarkup = "fx_markup" // Spread over the mid-market rate, included in the debit
+)
+
+// SettlementInstant means the transfer settles when it is accepted.
+const SettlementInstant = "instant"
+
+// QuoteTransferRequest asks for the price of a prospective transfer. Amount is
+// in Currency, which may differ from the source wallet's currency.
+type QuoteTransferRequest struct {
+	SourceWalletID      string          `json:"source_wallet_id" validate:"required,uuid"`
+	DestinationWalletID string          `json:"destination_wallet_id" validate:"required,uuid"`
+	Amount              int64           `json:"amount" validate:"required,gt=0"`
+	Currency            models.Currency `json:"currency" validate:"required,len=3"`
+}
+
+// TransferQuote locks the price of a transfer until it expires. The debit is
+// what leaves the source wallet, in its currency, with fees included.
+type TransferQuote struct {
+	ID                  string            `json:"id" db:"id"`
+	SourceWalletID      string            `json:"source_wallet_id" db:"source_wallet_id"`
+	DestinationWalletID string            `json:"destination_wallet_id" db:"destination_wallet_id"`
+	Amount              int64             `json:"amount" db:"amount"`
+	Currency            models.Currency   `json:"currency" db:"currency"`
+	DebitAmount         int64             `json:"debit_amount" db:"debit_amount"`
+	DebitCurrency       models.Currency   `json:"debit_currency" db:"debit_currency"`
+	FXRate              *string           `json:"fx_rate,omitempty" db:"fx_rate"`   // Debit currency per unit of Currency, markup included
+	MidRate             *string           `json:"mid_rate,omitempty" db:"mid_rate"` // Rate before markup
+	FeeAmount           int64             `json:"fee_amount" db:"fee_amount"`       // In DebitCurrency
+	ExpiresAt           models.Timestamp  `json:"expires_at" db:"expires_at"`
+	UsedAt              *models.Timestamp `json:"used_at,omitempty" db:"used_at"`
+	TransactionID       *string           `json:"transaction_id,omitempty" db:"transaction_id"`
+	CreatedBy           *string           `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt           models.Timestamp  `json:"created_at" db:"created_at"`
+}
+
+// QuoteFee is one fee in a quote's breakdown.
+type QuoteFee struct {
+	Type     string          `json:"type"`
+	Amount   int64           `json:"amount"`
+	Currency models.Currency `json:"currency"`
+}
+
+// QuoteLimitsImpact shows how a transfer would draw on the source wallet's
+// balance and transfer limits.
+type QuoteLimitsImpact struct {
+	AvailableBalance      int64 `json:"available_balance"`
+	SufficientFunds       bool  `json:"sufficient_funds"`
+	DailyLimit            int64 `json:"daily_limit"`
+	DailyRemaining        int64 `json:"daily_remaining"`
+	DailyRemainingAfter   int64 `json:"daily_remaining_after"`
+	MonthlyLimit          int64 `json:"monthly_limit"`
+	MonthlyRemaining      int64 `json:"monthly_remaining"`
+	MonthlyRemainingAfter int64 `json:"monthly_remaining_after"`
+	WithinLimits          bool  `json:"within_limits"`
+}
+
+// QuoteSettlement is when a transfer is expected to settle.
+type QuoteSettlement struct {
+	Method      string           `json:"method"`
+	EstimatedAt models.Timestamp `json:"estimated_at"`
+}
+
+// TransferQuoteResponse is a quote with its fee breakdown, limits impact and
+// expected settlement.
+type TransferQuoteResponse struct {
+	*TransferQuote
+	Fees       []QuoteFee        `json:"fees"`
+	Limits     QuoteLimitsImpact `json:"limits"`
+	Settlement QuoteSettlement   `json:"settlement"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Metadata keys recorded when a transaction proceeds without a risk decision
+// and when the re-score worker later evaluates it.
+const (
+	MetaRiskBypassed       = "risk_bypassed"
+	MetaRiskBypassReason   = "risk_bypass_reason"
+	MetaRiskBypassedAt     = "risk_bypassed_at"
+	MetaRiskRescoredAt     = "risk_rescored_at"
+	MetaRiskRescoreScore   = "risk_rescore_score"
+	MetaRiskRescoreAction  = "risk_rescore_action"
+	MetaRiskRescoreEventID = "risk_rescore_event_id"
+	MetaRiskRescoreAllowed = "risk_rescore_allowed"
+)
+
+// Reasons a risk evaluation was bypassed.
+const (
+	RiskBypassTimeout       = "timeout"
+	RiskBypassUnavailable   = "unavailable"
+	RiskBypassNotConfigured = "not_configured"
+)
+
+// RiskBypassFilter represents filters for listing risk-bypassed transactions.
+type RiskBypassFilter struct {
+	Unresolved  bool // only transactions not yet re-scored
+	OldestFirst bool
+	Limit       int
+	Offset      int
+}
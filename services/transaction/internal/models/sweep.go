@@ -0,0 +1,96 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// SweepDirection says which way an auto-sweep rule moves money.
+type SweepDirection string
+
+const (
+	SweepOut   SweepDirection = "sweep_out" // Moves the balance above the threshold to the counterpart wallet
+	SweepTopUp SweepDirection = "top_up"    // Tops the wallet up from the counterpart wallet below the threshold
+)
+
+// MetaSweepRuleID is the metadata key marking a transfer made by a sweep rule.
+const MetaSweepRuleID = "sweep_rule_id"
+
+// SweepRule is a standing instruction on a wallet: when its balance crosses
+// Threshold, transfer to or from the counterpart wallet until the balance is
+// back at TargetBalance.
+type SweepRule struct {
+	ID                  string            `json:"id" db:"id"`
+	WalletID            string            `json:"wallet_id" db:"wallet_id"`
+	CounterpartWalletID string            `json:"counterpart_wallet_id" db:"counterpart_wallet_id"`
+	Direction           SweepDirection    `json:"direction" db:"direction"`
+	Threshold           int64             `json:"threshold" db:"threshold"`
+	TargetBalance       int64             `json:"target_balance" db:"target_balance"` // Balance a sweep leaves the wallet at
+	MinAmount           int64             `json:"min_amount" db:"min_amount"`         // Smaller moves are skipped
+	Enabled             bool              `json:"enabled" db:"enabled"`
+	CreatedBy           string            `json:"created_by" db:"created_by"`
+	LastExecutedAt      *models.Timestamp `json:"last_executed_at,omitempty" db:"last_executed_at"`
+	CreatedAt           models.Timestamp  `json:"created_at" db:"created_at"`
+	UpdatedAt           models.Timestamp  `json:"updated_at" db:"updated_at"`
+}
+
+// SweepAmount returns how much the rule moves at the given balance, or 0 when
+// the balance has not crossed the threshold or the move is below MinAmount.
+func (r *SweepRule) SweepAmount(balance int64) int64 {
+	var amount int64
+	switch r.Direction {
+	case SweepOut:
+		if balance > r.Threshold {
+			amount = balance - r.TargetBalance
+		}
+	case SweepTopUp:
+		if balance < r.Threshold {
+			amount = r.TargetBalance - balance
+		}
+	}
+	if amount <= 0 || amount < r.MinAmount {
+		return 0
+	}
+	return amount
+}
+
+// Endpoints returns the source and destination wallets of the rule's transfers.
+func (r *SweepRule) Endpoints() (source, destination string) {
+	if r.Direction == SweepTopUp {
+		return r.CounterpartWalletID, r.WalletID
+	}
+	return r.WalletID, r.CounterpartWalletID
+}
+
+// SweepRuleRequest creates or replaces a sweep rule. TargetBalance defaults to
+// Threshold; a sweep out may leave less than the threshold and a top-up may
+// fill above it.
+type SweepRuleRequest struct {
+	CounterpartWalletID string         `json:"counterpart_wallet_id" validate:"required,uuid"`
+	Direction           SweepDirection `json:"direction" validate:"required,oneof=sweep_out top_up"`
+	Threshold           int64          `json:"threshold" validate:"gte=0"`
+	TargetBalance       *int64         `json:"target_balance,omitempty"`
+	MinAmount           int64          `json:"min_amount,omitempty" validate:"gte=0"`
+	Enabled             *bool          `json:"enabled,omitempty"` // Default true
+}
+
+// SweepExecutionStatus is the outcome of a sweep.
+type SweepExecutionStatus string
+
+const (
+	SweepExecutionCompleted SweepExecutionStatus = "completed"
+	SweepExecutionFailed    SweepExecutionStatus = "failed"
+)
+
+// SweepExecution records one transfer a sweep rule attempted.
+type SweepExecution struct {
+	ID            string               `json:"id" db:"id"`
+	RuleID        string               `json:"rule_id" db:"rule_id"`
+	WalletID      string               `json:"wallet_id" db:"wallet_id"`
+	Direction     SweepDirection       `json:"direction" db:"direction"`
+	BalanceBefore int64                `json:"balance_before" db:"balance_before"`
+	Amount        int64                `json:"amount" db:"amount"`
+	Status        SweepExecutionStatus `json:"status" db:"status"`
+	TransactionID *string              `json:"transaction_id,omitempty" db:"transaction_id"`
+	FailureReason *string              `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     models.Timestamp     `json:"created_at" db:"created_at"`
+}
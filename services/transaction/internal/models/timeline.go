@@ -0,0 +1,60 @@
+package models
+
+import "github.com/1mb-dev/nivomoney/shared/models"
+
+// TimelineEventType is a step in a transaction's processing.
+type TimelineEventType string
+
+const (
+	TimelineCreated           TimelineEventType = "created"            // Transaction recorded as pending
+	TimelineRiskEvaluated     TimelineEventType = "risk_evaluated"     // Risk service returned a decision
+	TimelineRiskBypassed      TimelineEventType = "risk_bypassed"      // Proceeded without a risk decision
+	TimelineRiskRescored      TimelineEventType = "risk_rescored"      // Bypassed transaction evaluated after the fact
+	TimelineFundsMoved        TimelineEventType = "funds_moved"        // Wallet balances updated
+	TimelineLedgerPosted      TimelineEventType = "ledger_posted"      // Journal entry posted to the ledger
+	TimelineLedgerPostFailed  TimelineEventType = "ledger_post_failed" // Journal entry could not be posted; needs reconciliation
+	TimelineCompleted         TimelineEventType = "completed"          // Transaction completed
+	TimelineFailed            TimelineEventType = "failed"             // Transaction failed
+	TimelineReversalCreated   TimelineEventType = "reversal_created"   // A reversal of this transaction was created
+	TimelineConfirmed         TimelineEventType = "confirmed"          // Owner confirmed a withdrawal with step-up verification
+	TimelineApprovalRequested TimelineEventType = "approval_requested" // Withdrawal sent to an admin for approval
+	TimelineApproved          TimelineEventType = "approved"           // Withdrawal released for processing
+	TimelineCancelled         TimelineEventType = "cancelled"          // Withdrawal cancelled, rejected or expired before release
+)
+
+// Actor types of timeline events.
+const (
+	ActorUser    = "user"    // An authenticated user, e.g. the customer or a support agent
+	ActorService = "service" // A platform service, named by the actor ID
+)
+
+// TimelineActor is who caused a timeline event.
+type TimelineActor struct {
+	Type string
+	ID   string
+}
+
+// ServiceActor returns the actor for a step performed by a platform service.
+func ServiceActor(service string) TimelineActor {
+	return TimelineActor{Type: ActorService, ID: service}
+}
+
+// TransactionEvent is one recorded step in a transaction's lifecycle.
+type TransactionEvent struct {
+	ID            string            `json:"id" db:"id"`
+	TransactionID string            `json:"transaction_id" db:"transaction_id"`
+	EventType     TimelineEventType `json:"event_type" db:"event_type"`
+	Status        TransactionStatus `json:"status" db:"status"` // Transaction status after the event
+	ActorType     string            `json:"actor_type" db:"actor_type"`
+	ActorID       string            `json:"actor_id" db:"actor_id"`
+	Details       map[string]string `json:"details,omitempty" db:"details"`
+	CreatedAt     models.Timestamp  `json:"created_at" db:"created_at"`
+}
+
+// TransactionTimeline is a transaction's status history, oldest event first.
+type TransactionTimeline struct {
+	TransactionID string              `json:"transaction_id"`
+	Type          TransactionType     `json:"type"`
+	Status        TransactionStatus   `json:"status"`
+	Events        []*TransactionEvent `json:"events"`
+}
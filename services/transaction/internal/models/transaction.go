@@ -2,8 +2,10 @@ package models
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
 )
 
 // TransactionType represents the type of transaction.
@@ -22,12 +24,13 @@ const (
 type TransactionStatus string
 
 const (
-	TransactionStatusPending    TransactionStatus = "pending"    // Transaction initiated
-	TransactionStatusProcessing TransactionStatus = "processing" // Transaction being processed
-	TransactionStatusCompleted  TransactionStatus = "completed"  // Transaction completed successfully
-	TransactionStatusFailed     TransactionStatus = "failed"     // Transaction failed
-	TransactionStatusReversed   TransactionStatus = "reversed"   // Transaction reversed
-	TransactionStatusCancelled  TransactionStatus = "cancelled"  // Transaction cancelled
+	TransactionStatusPending         TransactionStatus = "pending"          // Transaction initiated
+	TransactionStatusPendingApproval TransactionStatus = "pending_approval" // Large withdrawal awaiting confirmation or admin approval
+	TransactionStatusProcessing      TransactionStatus = "processing"       // Transaction being processed
+	TransactionStatusCompleted       TransactionStatus = "completed"        // Transaction completed successfully
+	TransactionStatusFailed          TransactionStatus = "failed"           // Transaction failed
+	TransactionStatusReversed        TransactionStatus = "reversed"         // Transaction reversed
+	TransactionStatusCancelled       TransactionStatus = "cancelled"        // Transaction cancelled
 )
 
 // SpendingCategory represents a spending category for transactions.
@@ -68,8 +71,9 @@ type Transaction struct {
 	Amount              int64             `json:"amount" db:"amount"` // In smallest unit (paise)
 	Currency            models.Currency   `json:"currency" db:"currency"`
 	Description         string            `json:"description" db:"description"`
-	Category            SpendingCategory  `json:"category" db:"category"`             // Spending category
-	Reference           *string           `json:"reference,omitempty" db:"reference"` // External reference
+	Category            SpendingCategory  `json:"category" db:"category"`                       // Spending category
+	CategoryOverridden  bool              `json:"category_overridden" db:"category_overridden"` // Set by the user; re-categorization leaves it alone
+	Reference           *string           `json:"reference,omitempty" db:"reference"`           // External reference
 	LedgerEntryID       *string           `json:"ledger_entry_id,omitempty" db:"ledger_entry_id"`
 	ParentTransactionID *string           `json:"parent_transaction_id,omitempty" db:"parent_transaction_id"` // For reversals/refunds
 	Metadata            map[string]string `json:"metadata,omitempty" db:"metadata"`
@@ -78,6 +82,14 @@ type Transaction struct {
 	CompletedAt         *models.Timestamp `json:"completed_at,omitempty" db:"completed_at"`
 	CreatedAt           models.Timestamp  `json:"created_at" db:"created_at"`
 	UpdatedAt           models.Timestamp  `json:"updated_at" db:"updated_at"`
+
+	// Counterparty is set on transfers in wallet listings; see Counterparty.
+	Counterparty *Counterparty `json:"counterparty,omitempty" db:"-"`
+}
+
+// Money returns the transaction amount in its currency.
+func (t *Transaction) Money() money.Money {
+	return money.New(t.Amount, t.Currency)
 }
 
 // IsCompleted returns true if the transaction is completed.
@@ -95,6 +107,26 @@ func (t *Transaction) IsPending() bool {
 	return t.Status == TransactionStatusPending || t.Status == TransactionStatusProcessing
 }
 
+// IsReversible returns true if the transaction can be reversed.
+func (t *Transaction) IsReversible() bool {
+	return t.IsCompleted() && t.Type != TransactionTypeReversal
+}
+
+// TransactionReceipt is proof of payment for a completed transaction.
+type TransactionReceipt struct {
+	TransactionID       string            `json:"transaction_id"`
+	Type                TransactionType   `json:"type"`
+	SourceWalletID      *string           `json:"source_wallet_id,omitempty"`
+	DestinationWalletID *string           `json:"destination_wallet_id,omitempty"`
+	Amount              int64             `json:"amount"`
+	Currency            models.Currency   `json:"currency"`
+	Description         string            `json:"description"`
+	Reference           *string           `json:"reference,omitempty"`
+	LedgerEntryID       *string           `json:"ledger_entry_id,omitempty"`
+	CompletedAt         *models.Timestamp `json:"completed_at"`
+	IssuedAt            models.Timestamp  `json:"issued_at"`
+}
+
 // CreateTransferRequest represents a request to create a transfer transaction.
 type CreateTransferRequest struct {
 	SourceWalletID      string          `json:"source_wallet_id" validate:"required,uuid"`
@@ -104,6 +136,7 @@ type CreateTransferRequest struct {
 	Description         string          `json:"description" validate:"required,min=3,max=500"`
 	Reference           string          `json:"reference,omitempty" validate:"omitempty,max=100"`
 	MetadataRaw         json.RawMessage `json:"metadata,omitempty"`
+	QuoteID             string          `json:"quote_id,omitempty" validate:"omitempty,uuid"` // Locks pricing from POST /transactions/quote
 }
 
 // GetMetadata parses and returns the metadata map.
@@ -142,6 +175,37 @@ func (r *CreateDepositRequest) GetMetadata() (map[string]string, error) {
 	return metadata, nil
 }
 
+// ClosureSweepRequest is an internal request from the wallet service to sweep
+// the balance of a wallet being closed, as a transfer to another wallet or,
+// without a destination, as a withdrawal.
+type ClosureSweepRequest struct {
+	ClosureID           string          `json:"closure_id" validate:"required,uuid"`
+	WalletID            string          `json:"wallet_id" validate:"required,uuid"`
+	DestinationWalletID string          `json:"destination_wallet_id,omitempty" validate:"omitempty,uuid"`
+	Amount              int64           `json:"amount" validate:"required,gt=0"`
+	Currency            models.Currency `json:"currency" validate:"required,len=3"`
+}
+
+// FeeChargeRequest is an internal request from another service to charge a
+// fee from a user's wallet into a platform fee wallet. Reference identifies
+// what the fee is for, e.g. a tier change request, and makes the charge
+// idempotent.
+type FeeChargeRequest struct {
+	WalletID    string          `json:"wallet_id" validate:"required,uuid"`
+	FeeWalletID string          `json:"fee_wallet_id" validate:"required,uuid"`
+	Amount      int64           `json:"amount" validate:"required,gt=0"`
+	Currency    models.Currency `json:"currency" validate:"required,len=3"`
+	Description string          `json:"description" validate:"required,min=3,max=500"`
+	Reference   string          `json:"reference" validate:"required,max=100"`
+}
+
+// StuckTransferRequest asks for a backdated pending transfer, so a
+// simulation can check that the stuck pending reaper fails it. Only
+// available outside production.
+type StuckTransferRequest struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+}
+
 // CreateWithdrawalRequest represents a request to create a withdrawal transaction.
 type CreateWithdrawalRequest struct {
 	WalletID    string          `json:"wallet_id" validate:"required,uuid"`
@@ -215,13 +279,75 @@ type UpdateCategoryRequest struct {
 	Category string `json:"category" validate:"required"`
 }
 
+// Category pattern match types.
+const (
+	MatchTypeKeyword = "keyword" // Case-insensitive substring of the description
+	MatchTypeRegex   = "regex"   // Case-insensitive regular expression over the description
+)
+
 // CategoryPattern represents a pattern for auto-categorizing transactions.
 type CategoryPattern struct {
 	ID        string           `json:"id" db:"id"`
 	Pattern   string           `json:"pattern" db:"pattern"`
+	MatchType string           `json:"match_type" db:"match_type"`
 	Category  SpendingCategory `json:"category" db:"category"`
 	Priority  int              `json:"priority" db:"priority"`
 	CreatedAt models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// CategoryPatternRequest represents a request to create or replace a category pattern.
+type CategoryPatternRequest struct {
+	Pattern   string `json:"pattern" validate:"required,max=100"`
+	MatchType string `json:"match_type" validate:"omitempty,oneof=keyword regex"`
+	Category  string `json:"category" validate:"required"`
+	Priority  int    `json:"priority"`
+}
+
+// RecategorizeRequest selects the transactions a re-categorization job covers.
+// Transactions whose category the user overrode are never changed.
+type RecategorizeRequest struct {
+	WalletID  *string `json:"wallet_id,omitempty" validate:"omitempty,uuid"`
+	StartDate string  `json:"start_date,omitempty"` // YYYY-MM-DD, inclusive
+	EndDate   string  `json:"end_date,omitempty"`   // YYYY-MM-DD, inclusive
+	DryRun    bool    `json:"dry_run,omitempty"`    // Count changes without writing them
+}
+
+// RecategorizeFilter pages through transactions for re-categorization in
+// (created_at, id) order.
+type RecategorizeFilter struct {
+	WalletID       *string
+	From           *time.Time
+	To             *time.Time // Exclusive
+	AfterCreatedAt *time.Time
+	AfterID        string
+	Limit          int
+}
+
+// CategoryUpdate is a new category for one transaction.
+type CategoryUpdate struct {
+	TransactionID string
+	Category      SpendingCategory
+}
+
+// Re-categorization job statuses.
+const (
+	RecategorizeRunning   = "running"
+	RecategorizeCompleted = "completed"
+	RecategorizeFailed    = "failed"
+)
+
+// RecategorizeJob reports the progress of a bulk re-categorization.
+type RecategorizeJob struct {
+	ID          string                   `json:"id"`
+	Status      string                   `json:"status"`
+	Request     RecategorizeRequest      `json:"request"`
+	Scanned     int                      `json:"scanned"`
+	Changed     int                      `json:"changed"`
+	ByCategory  map[SpendingCategory]int `json:"changed_by_category"`
+	Error       string                   `json:"error,omitempty"`
+	StartedAt   time.Time                `json:"started_at"`
+	CompletedAt *time.Time               `json:"completed_at,omitempty"`
 }
 
 // CategorySummary represents spending summary for a category.
@@ -0,0 +1,50 @@
+package models
+
+import "github.com/1mb-dev/nivomoney/shared/models"
+
+// WithdrawalApprovalStage is where a large withdrawal is in its approval.
+type WithdrawalApprovalStage string
+
+const (
+	WithdrawalAwaitingConfirmation WithdrawalApprovalStage = "awaiting_confirmation" // Owner must confirm with step-up verification
+	WithdrawalAwaitingApproval     WithdrawalApprovalStage = "awaiting_approval"     // Confirmed; an admin must approve
+	WithdrawalReleased             WithdrawalApprovalStage = "released"              // Withdrawal proceeds as pending
+	WithdrawalCancelled            WithdrawalApprovalStage = "cancelled"             // By the owner
+	WithdrawalRejected             WithdrawalApprovalStage = "rejected"              // By an admin
+	WithdrawalExpired              WithdrawalApprovalStage = "expired"               // Not confirmed or approved in time
+)
+
+// IsOpen returns true if the withdrawal still waits for a decision.
+func (s WithdrawalApprovalStage) IsOpen() bool {
+	return s == WithdrawalAwaitingConfirmation || s == WithdrawalAwaitingApproval
+}
+
+// WithdrawalApproval tracks a withdrawal held in pending_approval because its
+// amount reached the step-up threshold. The withdrawal is released once the
+// owner confirms it and, above the admin threshold, an admin approves it.
+type WithdrawalApproval struct {
+	TransactionID  string                  `json:"transaction_id" db:"transaction_id"`
+	UserID         string                  `json:"user_id" db:"user_id"`
+	WalletID       string                  `json:"wallet_id" db:"wallet_id"`
+	Amount         int64                   `json:"amount" db:"amount"`
+	Stage          WithdrawalApprovalStage `json:"stage" db:"stage"`
+	RequiresAdmin  bool                    `json:"requires_admin" db:"requires_admin"`
+	VerificationID *string                 `json:"verification_id,omitempty" db:"verification_id"` // Identity verification that confirmed it
+	ApprovalID     *string                 `json:"approval_id,omitempty" db:"approval_id"`         // Pending approval for the admin
+	ExpiresAt      models.Timestamp        `json:"expires_at" db:"expires_at"`                     // Deadline of the current stage
+	ConfirmedAt    *models.Timestamp       `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	DecidedAt      *models.Timestamp       `json:"decided_at,omitempty" db:"decided_at"` // When it was released, cancelled, rejected or expired
+	CreatedAt      models.Timestamp        `json:"created_at" db:"created_at"`
+}
+
+// ConfirmWithdrawalRequest confirms a withdrawal with an identity
+// verification token for the withdrawal_confirmation operation.
+type ConfirmWithdrawalRequest struct {
+	VerificationToken string `json:"verification_token" validate:"required"`
+}
+
+// WithdrawalResponse is a withdrawal with its approval, if it needed one.
+type WithdrawalResponse struct {
+	*Transaction
+	Approval *WithdrawalApproval `json:"approval,omitempty"`
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+const amountPolicyColumns = `id, operation, currency, min_amount, max_amount, updated_by, created_at, updated_at`
+
+// AmountPolicyRepository handles database operations for amount policies.
+type AmountPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewAmountPolicyRepository creates a new amount policy repository.
+func NewAmountPolicyRepository(db *sql.DB) *AmountPolicyRepository {
+	return &AmountPolicyRepository{db: db}
+}
+
+// scanAmountPolicy scans a row selected with amountPolicyColumns.
+func scanAmountPolicy(row interface{ Scan(...interface{}) error }) (*models.AmountPolicy, error) {
+	p := &models.AmountPolicy{}
+	err := row.Scan(
+		&p.ID,
+		&p.Operation,
+		&p.Currency,
+		&p.MinAmount,
+		&p.MaxAmount,
+		&p.UpdatedBy,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	return p, err
+}
+
+// List returns every amount policy, by operation and currency.
+func (r *AmountPolicyRepository) List(ctx context.Context) ([]*models.AmountPolicy, *errors.Error) {
+	query := `SELECT ` + amountPolicyColumns + ` FROM amount_policies ORDER BY operation, currency`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list amount policies")
+	}
+	defer func() { _ = rows.Close() }()
+
+	policies := make([]*models.AmountPolicy, 0)
+	for rows.Next() {
+		p, err := scanAmountPolicy(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan amount policy")
+		}
+		policies = append(policies, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to iterate amount policies")
+	}
+
+	return policies, nil
+}
+
+// Get retrieves the policy of an operation and currency.
+func (r *AmountPolicyRepository) Get(ctx context.Context, operation models.AmountOperation, currency sharedModels.Currency) (*models.AmountPolicy, *errors.Error) {
+	query := `SELECT ` + amountPolicyColumns + ` FROM amount_policies WHERE operation = $1 AND currency = $2`
+
+	p, err := scanAmountPolicy(r.db.QueryRowContext(ctx, query, operation, currency))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("amount policy not found")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get amount policy")
+	}
+
+	return p, nil
+}
+
+// Upsert creates the policy of its operation and currency, or replaces its bounds.
+func (r *AmountPolicyRepository) Upsert(ctx context.Context, p *models.AmountPolicy) *errors.Error {
+	query := `
+		INSERT INTO amount_policies (operation, currency, min_amount, max_amount, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (operation, currency) DO UPDATE
+		SET min_amount = EXCLUDED.min_amount,
+		    max_amount = EXCLUDED.max_amount,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, p.Operation, p.Currency, p.MinAmount, p.MaxAmount, p.UpdatedBy).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to save amount policy")
+	}
+
+	return nil
+}
+
+// Delete deletes an amount policy.
+func (r *AmountPolicyRepository) Delete(ctx context.Context, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM amount_policies WHERE id = $1", id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete amount policy")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete amount policy")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("amount policy", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/lib/pq"
+)
+
+// CategoryRuleRepository manages category patterns and bulk category updates.
+type CategoryRuleRepository struct {
+	db *sql.DB
+}
+
+// NewCategoryRuleRepository creates a new category rule repository.
+func NewCategoryRuleRepository(db *sql.DB) *CategoryRuleRepository {
+	return &CategoryRuleRepository{db: db}
+}
+
+// GetPattern retrieves a category pattern by ID.
+func (r *CategoryRuleRepository) GetPattern(ctx context.Context, id string) (*models.CategoryPattern, *errors.Error) {
+	query := `
+		SELECT id, pattern, match_type, category, priority, created_at, updated_at
+		FROM category_patterns
+		WHERE id = $1
+	`
+
+	p := &models.CategoryPattern{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&p.ID, &p.Pattern, &p.MatchType, &p.Category, &p.Priority, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("category pattern", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get category pattern")
+	}
+
+	return p, nil
+}
+
+// CreatePattern creates a category pattern.
+func (r *CategoryRuleRepository) CreatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error {
+	query := `
+		INSERT INTO category_patterns (pattern, match_type, category, priority)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, p.Pattern, p.MatchType, p.Category, p.Priority).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create category pattern")
+	}
+
+	return nil
+}
+
+// UpdatePattern replaces a category pattern's rule.
+func (r *CategoryRuleRepository) UpdatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error {
+	query := `
+		UPDATE category_patterns
+		SET pattern = $1, match_type = $2, category = $3, priority = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, p.Pattern, p.MatchType, p.Category, p.Priority, p.ID).
+		Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("category pattern", p.ID)
+		}
+		return errors.DatabaseWrap(err, "failed to update category pattern")
+	}
+
+	return nil
+}
+
+// DeletePattern deletes a category pattern.
+func (r *CategoryRuleRepository) DeletePattern(ctx context.Context, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM category_patterns WHERE id = $1", id)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete category pattern")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete category pattern")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("category pattern", id)
+	}
+
+	return nil
+}
+
+// ListForRecategorization returns the next page of transactions whose
+// category was not overridden by the user, in (created_at, id) order. Only
+// the fields categorization reads are loaded.
+func (r *CategoryRuleRepository) ListForRecategorization(ctx context.Context, filter *models.RecategorizeFilter) ([]*models.Transaction, *errors.Error) {
+	query := `
+		SELECT id, description, category, created_at
+		FROM transactions
+		WHERE NOT category_overridden
+	`
+
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.WalletID != nil {
+		argCount++
+		query += fmt.Sprintf(" AND (source_wallet_id = $%d OR destination_wallet_id = $%d)", argCount, argCount)
+		args = append(args, *filter.WalletID)
+	}
+	if filter.From != nil {
+		argCount++
+		query += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		argCount++
+		query += fmt.Sprintf(" AND created_at < $%d", argCount)
+		args = append(args, *filter.To)
+	}
+	if filter.AfterCreatedAt != nil {
+		query += fmt.Sprintf(" AND (created_at, id) > ($%d, $%d)", argCount+1, argCount+2)
+		argCount += 2
+		args = append(args, *filter.AfterCreatedAt, filter.AfterID)
+	}
+
+	argCount++
+	query += fmt.Sprintf(" ORDER BY created_at, id LIMIT $%d", argCount)
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list transactions for re-categorization")
+	}
+	defer func() { _ = rows.Close() }()
+
+	transactions := make([]*models.Transaction, 0)
+	for rows.Next() {
+		tx := &models.Transaction{}
+		if err := rows.Scan(&tx.ID, &tx.Description, &tx.Category, &tx.CreatedAt); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan transaction")
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating transactions")
+	}
+
+	return transactions, nil
+}
+
+// BulkUpdateCategories applies category changes in one statement. Rows the
+// user overrode in the meantime are skipped. Returns the number of rows changed.
+func (r *CategoryRuleRepository) BulkUpdateCategories(ctx context.Context, updates []models.CategoryUpdate) (int, *errors.Error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, len(updates))
+	categories := make([]string, len(updates))
+	for i, u := range updates {
+		ids[i] = u.TransactionID
+		categories[i] = string(u.Category)
+	}
+
+	query := `
+		UPDATE transactions t
+		SET category = u.category::spending_category, updated_at = NOW()
+		FROM unnest($1::uuid[], $2::text[]) AS u(id, category)
+		WHERE t.id = u.id AND NOT t.category_overridden
+	`
+
+	result, err := r.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(categories))
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to update transaction categories")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to update transaction categories")
+	}
+
+	return int(affected), nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// FeeChargeRepository looks up fee transactions by the caller's reference.
+type FeeChargeRepository struct {
+	db *sql.DB
+}
+
+// NewFeeChargeRepository creates a new fee charge repository.
+func NewFeeChargeRepository(db *sql.DB) *FeeChargeRepository {
+	return &FeeChargeRepository{db: db}
+}
+
+// GetActiveFeeID returns the ID of the pending or completed fee transaction
+// for reference, or an empty string if there is none.
+func (r *FeeChargeRepository) GetActiveFeeID(ctx context.Context, reference string) (string, *errors.Error) {
+	query := `
+		SELECT id FROM transactions
+		WHERE type = 'fee' AND reference = $1 AND status <> 'failed'
+	`
+
+	var id string
+	err := r.db.QueryRowContext(ctx, query, reference).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.DatabaseWrap(err, "failed to look up fee charge")
+	}
+	return id, nil
+}
@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const (
+	fundingSourceColumns = `id, user_id, bank_name, account_holder_name, account_last4, ifsc, status, created_at, removed_at`
+	mandateColumns       = `id, user_id, funding_source_id, wallet_id, max_amount, monthly_limit, valid_until,
+		       status, created_at, cancelled_at`
+	autoTopUpRuleColumns = `id, wallet_id, mandate_id, threshold, amount, enabled, created_by,
+		       last_executed_at, created_at, updated_at`
+)
+
+// FundingRepository handles database operations for linked funding sources,
+// their mandates, and the auto top-up rules that debit them.
+type FundingRepository struct {
+	db *sql.DB
+}
+
+// NewFundingRepository creates a new funding repository.
+func NewFundingRepository(db *sql.DB) *FundingRepository {
+	return &FundingRepository{db: db}
+}
+
+// ========================================================================
+// Funding Sources
+// ========================================================================
+
+// scanFundingSource scans a row selected with fundingSourceColumns.
+func scanFundingSource(row interface{ Scan(...interface{}) error }) (*models.FundingSource, error) {
+	source := &models.FundingSource{}
+	err := row.Scan(
+		&source.ID,
+		&source.UserID,
+		&source.BankName,
+		&source.AccountHolderName,
+		&source.AccountLast4,
+		&source.IFSC,
+		&source.Status,
+		&source.CreatedAt,
+		&source.RemovedAt,
+	)
+	return source, err
+}
+
+// CreateSource stores a newly linked funding source.
+func (r *FundingRepository) CreateSource(ctx context.Context, source *models.FundingSource) *errors.Error {
+	query := `
+		INSERT INTO funding_sources (user_id, bank_name, account_holder_name, account_last4, ifsc, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		source.UserID, source.BankName, source.AccountHolderName, source.AccountLast4, source.IFSC, source.Status,
+	).Scan(&source.ID, &source.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create funding source")
+	}
+
+	return nil
+}
+
+// GetSource retrieves a user's funding source.
+func (r *FundingRepository) GetSource(ctx context.Context, userID, id string) (*models.FundingSource, *errors.Error) {
+	query := `SELECT ` + fundingSourceColumns + ` FROM funding_sources WHERE id = $1 AND user_id = $2`
+
+	source, err := scanFundingSource(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("funding source", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get funding source")
+	}
+
+	return source, nil
+}
+
+// ListSources returns a user's active funding sources, oldest first.
+func (r *FundingRepository) ListSources(ctx context.Context, userID string) ([]*models.FundingSource, *errors.Error) {
+	query := `SELECT ` + fundingSourceColumns + ` FROM funding_sources
+		WHERE user_id = $1 AND status = 'active' ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list funding sources")
+	}
+	defer func() { _ = rows.Close() }()
+
+	sources := make([]*models.FundingSource, 0)
+	for rows.Next() {
+		source, err := scanFundingSource(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan funding source")
+		}
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list funding sources")
+	}
+
+	return sources, nil
+}
+
+// RemoveSource unlinks a user's active funding source and cancels its active
+// mandates in one transaction, so no debit can follow the removal.
+func (r *FundingRepository) RemoveSource(ctx context.Context, userID, id string) *errors.Error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE funding_sources SET status = 'removed', removed_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status = 'active'
+	`, id, userID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to remove funding source")
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to remove funding source")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("funding source", id)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE mandates SET status = 'cancelled', cancelled_at = NOW()
+		WHERE funding_source_id = $1 AND status = 'active'
+	`, id); err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel mandates of funding source")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// ========================================================================
+// Mandates
+// ========================================================================
+
+// scanMandate scans a row selected with mandateColumns.
+func scanMandate(row interface{ Scan(...interface{}) error }) (*models.Mandate, error) {
+	mandate := &models.Mandate{}
+	err := row.Scan(
+		&mandate.ID,
+		&mandate.UserID,
+		&mandate.FundingSourceID,
+		&mandate.WalletID,
+		&mandate.MaxAmount,
+		&mandate.MonthlyLimit,
+		&mandate.ValidUntil,
+		&mandate.Status,
+		&mandate.CreatedAt,
+		&mandate.CancelledAt,
+	)
+	return mandate, err
+}
+
+// CreateMandate stores a new mandate.
+func (r *FundingRepository) CreateMandate(ctx context.Context, mandate *models.Mandate) *errors.Error {
+	query := `
+		INSERT INTO mandates (user_id, funding_source_id, wallet_id, max_amount, monthly_limit, valid_until, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		mandate.UserID, mandate.FundingSourceID, mandate.WalletID, mandate.MaxAmount, mandate.MonthlyLimit,
+		mandate.ValidUntil, mandate.Status,
+	).Scan(&mandate.ID, &mandate.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create mandate")
+	}
+
+	return nil
+}
+
+// GetMandate retrieves a mandate by ID.
+func (r *FundingRepository) GetMandate(ctx context.Context, id string) (*models.Mandate, *errors.Error) {
+	query := `SELECT ` + mandateColumns + ` FROM mandates WHERE id = $1`
+
+	mandate, err := scanMandate(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("mandate", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get mandate")
+	}
+
+	return mandate, nil
+}
+
+// ListMandates returns a user's mandates, newest first.
+func (r *FundingRepository) ListMandates(ctx context.Context, userID string) ([]*models.Mandate, *errors.Error) {
+	query := `SELECT ` + mandateColumns + ` FROM mandates WHERE user_id = $1 ORDER BY created_at DESC, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list mandates")
+	}
+	defer func() { _ = rows.Close() }()
+
+	mandates := make([]*models.Mandate, 0)
+	for rows.Next() {
+		mandate, err := scanMandate(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan mandate")
+		}
+		mandates = append(mandates, mandate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list mandates")
+	}
+
+	return mandates, nil
+}
+
+// CancelMandate cancels a user's active mandate.
+func (r *FundingRepository) CancelMandate(ctx context.Context, userID, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE mandates SET status = 'cancelled', cancelled_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND status = 'active'
+	`, id, userID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel mandate")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to cancel mandate")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("active mandate", id)
+	}
+
+	return nil
+}
+
+// MandateUsage sums a mandate's completed debits since the given time.
+func (r *FundingRepository) MandateUsage(ctx context.Context, mandateID string, since time.Time) (int64, *errors.Error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0) FROM auto_top_up_executions
+		WHERE mandate_id = $1 AND status = 'completed' AND created_at >= $2
+	`
+
+	var used int64
+	if err := r.db.QueryRowContext(ctx, query, mandateID, since).Scan(&used); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to sum mandate usage")
+	}
+
+	return used, nil
+}
+
+// ========================================================================
+// Auto Top-Up Rules
+// ========================================================================
+
+// scanAutoTopUpRule scans a row selected with autoTopUpRuleColumns.
+func scanAutoTopUpRule(row interface{ Scan(...interface{}) error }) (*models.AutoTopUpRule, error) {
+	rule := &models.AutoTopUpRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.WalletID,
+		&rule.MandateID,
+		&rule.Threshold,
+		&rule.Amount,
+		&rule.Enabled,
+		&rule.CreatedBy,
+		&rule.LastExecutedAt,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+// UpsertRule creates a wallet's auto top-up rule or replaces its settings.
+func (r *FundingRepository) UpsertRule(ctx context.Context, rule *models.AutoTopUpRule) *errors.Error {
+	query := `
+		INSERT INTO auto_top_up_rules (wallet_id, mandate_id, threshold, amount, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (wallet_id) DO UPDATE
+		SET mandate_id = EXCLUDED.mandate_id, threshold = EXCLUDED.threshold, amount = EXCLUDED.amount,
+		    enabled = EXCLUDED.enabled, updated_at = NOW()
+		RETURNING ` + autoTopUpRuleColumns
+
+	saved, err := scanAutoTopUpRule(r.db.QueryRowContext(ctx, query,
+		rule.WalletID, rule.MandateID, rule.Threshold, rule.Amount, rule.Enabled, rule.CreatedBy,
+	))
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to save auto top-up rule")
+	}
+
+	*rule = *saved
+	return nil
+}
+
+// GetRule retrieves a wallet's auto top-up rule.
+func (r *FundingRepository) GetRule(ctx context.Context, walletID string) (*models.AutoTopUpRule, *errors.Error) {
+	query := `SELECT ` + autoTopUpRuleColumns + ` FROM auto_top_up_rules WHERE wallet_id = $1`
+
+	rule, err := scanAutoTopUpRule(r.db.QueryRowContext(ctx, query, walletID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("auto top-up rule")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get auto top-up rule")
+	}
+
+	return rule, nil
+}
+
+// DeleteRule deletes a wallet's auto top-up rule and its execution history.
+func (r *FundingRepository) DeleteRule(ctx context.Context, walletID string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM auto_top_up_rules WHERE wallet_id = $1", walletID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete auto top-up rule")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete auto top-up rule")
+	}
+	if affected == 0 {
+		return errors.NotFound("auto top-up rule")
+	}
+
+	return nil
+}
+
+// ListWalletsWithEnabledRules returns the wallets that have an enabled rule.
+func (r *FundingRepository) ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT wallet_id FROM auto_top_up_rules WHERE enabled`)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list auto top-up wallets")
+	}
+	defer func() { _ = rows.Close() }()
+
+	var walletIDs []string
+	for rows.Next() {
+		var walletID string
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan auto top-up wallet")
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list auto top-up wallets")
+	}
+
+	return walletIDs, nil
+}
+
+// Claim marks an enabled rule as executing at now unless it already ran after
+// notBefore, so concurrent evaluations cannot debit the mandate twice.
+func (r *FundingRepository) Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error) {
+	query := `
+		UPDATE auto_top_up_rules
+		SET last_executed_at = $2
+		WHERE id = $1 AND enabled AND (last_executed_at IS NULL OR last_executed_at <= $3)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, now, notBefore)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim auto top-up rule")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim auto top-up rule")
+	}
+	return affected == 1, nil
+}
+
+// RecordExecution stores the outcome of an auto top-up.
+func (r *FundingRepository) RecordExecution(ctx context.Context, exec *models.AutoTopUpExecution) *errors.Error {
+	query := `
+		INSERT INTO auto_top_up_executions (
+			rule_id, wallet_id, mandate_id, balance_before, amount, status, transaction_id, failure_reason
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		exec.RuleID, exec.WalletID, exec.MandateID, exec.BalanceBefore, exec.Amount, exec.Status,
+		exec.TransactionID, exec.FailureReason,
+	).Scan(&exec.ID, &exec.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record auto top-up execution")
+	}
+
+	return nil
+}
+
+// ListExecutions returns a wallet's most recent auto top-ups, newest first.
+func (r *FundingRepository) ListExecutions(ctx context.Context, walletID string, limit int) ([]*models.AutoTopUpExecution, *errors.Error) {
+	query := `
+		SELECT id, rule_id, wallet_id, mandate_id, balance_before, amount, status,
+		       transaction_id, failure_reason, created_at
+		FROM auto_top_up_executions
+		WHERE wallet_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list auto top-up executions")
+	}
+	defer func() { _ = rows.Close() }()
+
+	executions := make([]*models.AutoTopUpExecution, 0)
+	for rows.Next() {
+		exec := &models.AutoTopUpExecution{}
+		if err := rows.Scan(
+			&exec.ID, &exec.RuleID, &exec.WalletID, &exec.MandateID, &exec.BalanceBefore, &exec.Amount,
+			&exec.Status, &exec.TransactionID, &exec.FailureReason, &exec.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan auto top-up execution")
+		}
+		executions = append(executions, exec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list auto top-up executions")
+	}
+
+	return executions, nil
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const merchantWebhookColumns = `id, wallet_id, url, secret, enabled, created_by, created_at, updated_at`
+
+const merchantWebhookDeliveryColumns = `id, webhook_id, wallet_id, transaction_id, payload, status, attempts,
+		       next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at`
+
+// MerchantWebhookRepository handles database operations for merchant payment
+// webhooks and their queued deliveries.
+type MerchantWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewMerchantWebhookRepository creates a new merchant webhook repository.
+func NewMerchantWebhookRepository(db *sql.DB) *MerchantWebhookRepository {
+	return &MerchantWebhookRepository{db: db}
+}
+
+// scanMerchantWebhook scans a row selected with merchantWebhookColumns.
+func scanMerchantWebhook(row interface{ Scan(...interface{}) error }) (*models.MerchantWebhook, error) {
+	hook := &models.MerchantWebhook{}
+	err := row.Scan(
+		&hook.ID,
+		&hook.WalletID,
+		&hook.URL,
+		&hook.Secret,
+		&hook.Enabled,
+		&hook.CreatedBy,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	return hook, err
+}
+
+// scanMerchantWebhookDelivery scans a row selected with merchantWebhookDeliveryColumns.
+func scanMerchantWebhookDelivery(row interface{ Scan(...interface{}) error }) (*models.MerchantWebhookDelivery, error) {
+	delivery := &models.MerchantWebhookDelivery{}
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.WalletID,
+		&delivery.TransactionID,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+	delivery.Payload = payload
+	return delivery, err
+}
+
+// Create stores a wallet's webhook. A wallet has at most one.
+func (r *MerchantWebhookRepository) Create(ctx context.Context, hook *models.MerchantWebhook) *errors.Error {
+	query := `
+		INSERT INTO merchant_webhooks (wallet_id, url, secret, enabled, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		hook.WalletID, hook.URL, hook.Secret, hook.Enabled, hook.CreatedBy,
+	).Scan(&hook.ID, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Conflict("wallet already has a merchant webhook")
+		}
+		return errors.DatabaseWrap(err, "failed to create merchant webhook")
+	}
+
+	return nil
+}
+
+// GetByWallet retrieves a wallet's webhook.
+func (r *MerchantWebhookRepository) GetByWallet(ctx context.Context, walletID string) (*models.MerchantWebhook, *errors.Error) {
+	query := `SELECT ` + merchantWebhookColumns + ` FROM merchant_webhooks WHERE wallet_id = $1`
+
+	hook, err := scanMerchantWebhook(r.db.QueryRowContext(ctx, query, walletID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("merchant webhook")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get merchant webhook")
+	}
+
+	return hook, nil
+}
+
+// GetByID retrieves a webhook by ID.
+func (r *MerchantWebhookRepository) GetByID(ctx context.Context, id string) (*models.MerchantWebhook, *errors.Error) {
+	query := `SELECT ` + merchantWebhookColumns + ` FROM merchant_webhooks WHERE id = $1`
+
+	hook, err := scanMerchantWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("merchant webhook", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get merchant webhook")
+	}
+
+	return hook, nil
+}
+
+// Update replaces a webhook's URL, secret and enabled flag.
+func (r *MerchantWebhookRepository) Update(ctx context.Context, hook *models.MerchantWebhook) *errors.Error {
+	query := `
+		UPDATE merchant_webhooks
+		SET url = $1, secret = $2, enabled = $3, updated_at = NOW()
+		WHERE id = $4
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, hook.URL, hook.Secret, hook.Enabled, hook.ID).
+		Scan(&hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("merchant webhook", hook.ID)
+		}
+		return errors.DatabaseWrap(err, "failed to update merchant webhook")
+	}
+
+	return nil
+}
+
+// Delete removes a wallet's webhook with its deliveries.
+func (r *MerchantWebhookRepository) Delete(ctx context.Context, walletID string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM merchant_webhooks WHERE wallet_id = $1", walletID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete merchant webhook")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete merchant webhook")
+	}
+	if affected == 0 {
+		return errors.NotFound("merchant webhook")
+	}
+
+	return nil
+}
+
+// Enqueue queues a payment notification. A transaction is queued at most once
+// per webhook; a repeat is ignored and returns false.
+func (r *MerchantWebhookRepository) Enqueue(ctx context.Context, delivery *models.MerchantWebhookDelivery) (bool, *errors.Error) {
+	query := `
+		INSERT INTO merchant_webhook_deliveries (webhook_id, wallet_id, transaction_id, payload)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (webhook_id, transaction_id) DO NOTHING
+		RETURNING ` + merchantWebhookDeliveryColumns
+
+	stored, err := scanMerchantWebhookDelivery(r.db.QueryRowContext(ctx, query,
+		delivery.WebhookID, delivery.WalletID, delivery.TransactionID, []byte(delivery.Payload),
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, errors.DatabaseWrap(err, "failed to queue merchant webhook delivery")
+	}
+
+	*delivery = *stored
+	return true, nil
+}
+
+// ClaimDue takes up to limit pending deliveries whose next attempt is due and
+// pushes their next attempt to leaseUntil, so other workers and instances
+// leave them alone while they are sent. A delivery whose worker dies is taken
+// again once the lease runs out.
+func (r *MerchantWebhookRepository) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.MerchantWebhookDelivery, *errors.Error) {
+	query := `
+		UPDATE merchant_webhook_deliveries
+		SET next_attempt_at = $2, updated_at = NOW()
+		WHERE id IN (
+			SELECT id FROM merchant_webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + merchantWebhookDeliveryColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now, leaseUntil, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to claim merchant webhook deliveries")
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := make([]*models.MerchantWebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanMerchantWebhookDelivery(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan merchant webhook delivery")
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to claim merchant webhook deliveries")
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt: its status, attempt
+// count, next attempt time and the endpoint's response.
+func (r *MerchantWebhookRepository) RecordAttempt(ctx context.Context, delivery *models.MerchantWebhookDelivery) *errors.Error {
+	query := `
+		UPDATE merchant_webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6,
+		    delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING delivered_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt,
+		delivery.LastStatusCode, delivery.LastError,
+	).Scan(&delivery.DeliveredAt, &delivery.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("merchant webhook delivery", delivery.ID)
+		}
+		return errors.DatabaseWrap(err, "failed to record merchant webhook attempt")
+	}
+
+	return nil
+}
+
+// Redeliver queues a failed delivery of a wallet again with a fresh set of
+// attempts.
+func (r *MerchantWebhookRepository) Redeliver(ctx context.Context, walletID, id string) (*models.MerchantWebhookDelivery, *errors.Error) {
+	query := `
+		UPDATE merchant_webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND wallet_id = $2 AND status = 'failed'
+		RETURNING ` + merchantWebhookDeliveryColumns
+
+	delivery, err := scanMerchantWebhookDelivery(r.db.QueryRowContext(ctx, query, id, walletID))
+	if err == nil {
+		return delivery, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, errors.DatabaseWrap(err, "failed to redeliver merchant webhook delivery")
+	}
+
+	// Tell an unknown delivery apart from one that has not failed
+	var status models.MerchantWebhookDeliveryStatus
+	err = r.db.QueryRowContext(ctx,
+		"SELECT status FROM merchant_webhook_deliveries WHERE id = $1 AND wallet_id = $2", id, walletID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("merchant webhook delivery", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to redeliver merchant webhook delivery")
+	}
+	return nil, errors.Conflict("only failed deliveries can be redelivered, this one is " + string(status))
+}
+
+// ListDeliveries returns a wallet's most recent deliveries, newest first,
+// optionally with one status.
+func (r *MerchantWebhookRepository) ListDeliveries(ctx context.Context, walletID string, status models.MerchantWebhookDeliveryStatus, limit int) ([]*models.MerchantWebhookDelivery, *errors.Error) {
+	query := `
+		SELECT ` + merchantWebhookDeliveryColumns + `
+		FROM merchant_webhook_deliveries
+		WHERE wallet_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, status, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list merchant webhook deliveries")
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := make([]*models.MerchantWebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanMerchantWebhookDelivery(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan merchant webhook delivery")
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list merchant webhook deliveries")
+	}
+
+	return deliveries, nil
+}
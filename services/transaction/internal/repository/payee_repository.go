@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// PayeeRepository handles database operations for payees and pinned favorites.
+type PayeeRepository struct {
+	db *sql.DB
+}
+
+// NewPayeeRepository creates a new payee repository.
+func NewPayeeRepository(db *sql.DB) *PayeeRepository {
+	return &PayeeRepository{db: db}
+}
+
+// payeeStatsQuery aggregates completed transfers from a wallet by destination.
+const payeeStatsQuery = `
+	SELECT
+		destination_wallet_id,
+		COUNT(*) AS transfer_count,
+		SUM(amount) AS total_amount,
+		(ARRAY_AGG(amount ORDER BY created_at DESC))[1] AS last_amount,
+		(ARRAY_AGG(description ORDER BY created_at DESC))[1] AS last_description,
+		MAX(created_at) AS last_transferred_at
+	FROM transactions
+	WHERE source_wallet_id = $1
+	  AND type = 'transfer'
+	  AND status = 'completed'
+	  AND destination_wallet_id IS NOT NULL
+	GROUP BY destination_wallet_id
+`
+
+// ListRecent returns payees from transfer history, most recently paid first.
+// Pinned favorites are excluded since they are listed separately.
+func (r *PayeeRepository) ListRecent(ctx context.Context, walletID string, limit int) ([]*models.Payee, *errors.Error) {
+	query := `
+		SELECT s.destination_wallet_id, s.transfer_count, s.total_amount,
+		       s.last_amount, s.last_description, s.last_transferred_at
+		FROM (` + payeeStatsQuery + `) s
+		WHERE NOT EXISTS (
+			SELECT 1 FROM payee_favorites f
+			WHERE f.wallet_id = $1 AND f.payee_wallet_id = s.destination_wallet_id
+		)
+		ORDER BY s.last_transferred_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list recent payees")
+	}
+	defer func() { _ = rows.Close() }()
+
+	payees := make([]*models.Payee, 0)
+	for rows.Next() {
+		p := &models.Payee{}
+		var lastDescription sql.NullString
+		if err := rows.Scan(&p.WalletID, &p.TransferCount, &p.TotalAmount,
+			&p.LastAmount, &lastDescription, &p.LastTransferredAt); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan payee")
+		}
+		p.LastDescription = lastDescription.String
+		payees = append(payees, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating payees")
+	}
+
+	return payees, nil
+}
+
+// ListFavorites returns pinned payees with their transfer stats, most recently pinned first.
+func (r *PayeeRepository) ListFavorites(ctx context.Context, walletID string) ([]*models.Payee, *errors.Error) {
+	query := `
+		SELECT f.payee_wallet_id, f.pinned_at,
+		       COALESCE(s.transfer_count, 0), COALESCE(s.total_amount, 0),
+		       COALESCE(s.last_amount, 0), s.last_description, s.last_transferred_at
+		FROM payee_favorites f
+		LEFT JOIN (` + payeeStatsQuery + `) s ON s.destination_wallet_id = f.payee_wallet_id
+		WHERE f.wallet_id = $1
+		ORDER BY f.pinned_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list favorite payees")
+	}
+	defer func() { _ = rows.Close() }()
+
+	payees := make([]*models.Payee, 0)
+	for rows.Next() {
+		p := &models.Payee{IsFavorite: true}
+		var lastDescription sql.NullString
+		if err := rows.Scan(&p.WalletID, &p.PinnedAt, &p.TransferCount, &p.TotalAmount,
+			&p.LastAmount, &lastDescription, &p.LastTransferredAt); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan favorite payee")
+		}
+		p.LastDescription = lastDescription.String
+		payees = append(payees, p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating favorite payees")
+	}
+
+	return payees, nil
+}
+
+// Pin marks a payee as a favorite. Pinning an existing favorite is a no-op.
+func (r *PayeeRepository) Pin(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	query := `
+		INSERT INTO payee_favorites (wallet_id, payee_wallet_id)
+		VALUES ($1, $2)
+		ON CONFLICT (wallet_id, payee_wallet_id) DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, walletID, payeeWalletID); err != nil {
+		return errors.DatabaseWrap(err, "failed to pin payee")
+	}
+	return nil
+}
+
+// Unpin removes a payee from favorites.
+func (r *PayeeRepository) Unpin(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	query := `DELETE FROM payee_favorites WHERE wallet_id = $1 AND payee_wallet_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, payeeWalletID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to unpin payee")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to check rows affected")
+	}
+	if rows == 0 {
+		return errors.NotFound("favorite payee")
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const paymentIntentColumns = `id, wallet_id, order_reference, amount, currency, description, status,
+		       payer_wallet_id, transaction_id, failure_reason, expires_at, paid_at,
+		       created_by, created_at, updated_at`
+
+// PaymentIntentRepository handles database operations for merchant payment
+// intents.
+type PaymentIntentRepository struct {
+	db *sql.DB
+}
+
+// NewPaymentIntentRepository creates a new payment intent repository.
+func NewPaymentIntentRepository(db *sql.DB) *PaymentIntentRepository {
+	return &PaymentIntentRepository{db: db}
+}
+
+// scanPaymentIntent scans a row selected with paymentIntentColumns.
+func scanPaymentIntent(row interface{ Scan(...interface{}) error }) (*models.PaymentIntent, error) {
+	intent := &models.PaymentIntent{}
+	err := row.Scan(
+		&intent.ID,
+		&intent.WalletID,
+		&intent.OrderReference,
+		&intent.Amount,
+		&intent.Currency,
+		&intent.Description,
+		&intent.Status,
+		&intent.PayerWalletID,
+		&intent.TransactionID,
+		&intent.FailureReason,
+		&intent.ExpiresAt,
+		&intent.PaidAt,
+		&intent.CreatedBy,
+		&intent.CreatedAt,
+		&intent.UpdatedAt,
+	)
+	return intent, err
+}
+
+// Create stores a new payment intent. Order references are unique per wallet.
+func (r *PaymentIntentRepository) Create(ctx context.Context, intent *models.PaymentIntent) *errors.Error {
+	query := `
+		INSERT INTO merchant_payment_intents (
+			wallet_id, order_reference, amount, currency, description, status, expires_at, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (wallet_id, order_reference) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		intent.WalletID, intent.OrderReference, intent.Amount, intent.Currency, intent.Description,
+		intent.Status, intent.ExpiresAt, intent.CreatedBy,
+	).Scan(&intent.ID, &intent.CreatedAt, &intent.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Conflict("a payment intent already exists for this order reference")
+		}
+		return errors.DatabaseWrap(err, "failed to create payment intent")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a payment intent.
+func (r *PaymentIntentRepository) GetByID(ctx context.Context, id string) (*models.PaymentIntent, *errors.Error) {
+	query := `SELECT ` + paymentIntentColumns + ` FROM merchant_payment_intents WHERE id = $1`
+
+	intent, err := scanPaymentIntent(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("payment intent", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get payment intent")
+	}
+
+	return intent, nil
+}
+
+// GetByOrderReference retrieves a wallet's payment intent for an order.
+func (r *PaymentIntentRepository) GetByOrderReference(ctx context.Context, walletID, orderReference string) (*models.PaymentIntent, *errors.Error) {
+	query := `SELECT ` + paymentIntentColumns + ` FROM merchant_payment_intents WHERE wallet_id = $1 AND order_reference = $2`
+
+	intent, err := scanPaymentIntent(r.db.QueryRowContext(ctx, query, walletID, orderReference))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("payment intent", orderReference)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get payment intent")
+	}
+
+	return intent, nil
+}
+
+// ListByWallet returns a wallet's payment intents, newest first, optionally
+// with one status.
+func (r *PaymentIntentRepository) ListByWallet(ctx context.Context, walletID string, status models.PaymentIntentStatus, limit int) ([]*models.PaymentIntent, *errors.Error) {
+	query := `
+		SELECT ` + paymentIntentColumns + `
+		FROM merchant_payment_intents
+		WHERE wallet_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, status, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list payment intents")
+	}
+	defer func() { _ = rows.Close() }()
+
+	intents := make([]*models.PaymentIntent, 0)
+	for rows.Next() {
+		intent, err := scanPaymentIntent(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan payment intent")
+		}
+		intents = append(intents, intent)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list payment intents")
+	}
+
+	return intents, nil
+}
+
+// Claim holds a pending, unexpired intent for a payer while their transfer
+// runs. Returns false if the intent is not payable, so only one payer wins.
+func (r *PaymentIntentRepository) Claim(ctx context.Context, id, payerWalletID string, now time.Time) (bool, *errors.Error) {
+	query := `
+		UPDATE merchant_payment_intents
+		SET status = 'processing', payer_wallet_id = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND expires_at > $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, payerWalletID, now)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim payment intent")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim payment intent")
+	}
+	return affected > 0, nil
+}
+
+// MarkPaid records the transfer that paid a claimed intent.
+func (r *PaymentIntentRepository) MarkPaid(ctx context.Context, id, transactionID string) *errors.Error {
+	query := `
+		UPDATE merchant_payment_intents
+		SET status = 'paid', transaction_id = $2, failure_reason = NULL, paid_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, transactionID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to mark payment intent paid")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to mark payment intent paid")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("payment intent", id)
+	}
+
+	return nil
+}
+
+// Release returns a claimed intent to pending after its transfer failed,
+// recording why.
+func (r *PaymentIntentRepository) Release(ctx context.Context, id string, transactionID *string, failureReason string) *errors.Error {
+	query := `
+		UPDATE merchant_payment_intents
+		SET status = 'pending', transaction_id = COALESCE($2, transaction_id), failure_reason = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, transactionID, failureReason); err != nil {
+		return errors.DatabaseWrap(err, "failed to release payment intent")
+	}
+
+	return nil
+}
+
+// Cancel withdraws a wallet's pending, unexpired intent for an order. Returns
+// false if the intent exists but can no longer be cancelled.
+func (r *PaymentIntentRepository) Cancel(ctx context.Context, walletID, orderReference string, now time.Time) (bool, *errors.Error) {
+	query := `
+		UPDATE merchant_payment_intents
+		SET status = 'cancelled', updated_at = NOW()
+		WHERE wallet_id = $1 AND order_reference = $2 AND status = 'pending' AND expires_at > $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, orderReference, now)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to cancel payment intent")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to cancel payment intent")
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const quoteColumns = `id, source_wallet_id, destination_wallet_id, amount, currency,
+		       debit_amount, debit_currency, fx_rate, mid_rate, fee_amount,
+		       expires_at, used_at, transaction_id, created_by, created_at`
+
+// QuoteRepository handles database operations for transfer quotes.
+type QuoteRepository struct {
+	db *sql.DB
+}
+
+// NewQuoteRepository creates a new quote repository.
+func NewQuoteRepository(db *sql.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+// scanQuote scans a row selected with quoteColumns.
+func scanQuote(row interface{ Scan(...interface{}) error }) (*models.TransferQuote, error) {
+	quote := &models.TransferQuote{}
+	var fxRate, midRate sql.NullString
+
+	if err := row.Scan(
+		&quote.ID,
+		&quote.SourceWalletID,
+		&quote.DestinationWalletID,
+		&quote.Amount,
+		&quote.Currency,
+		&quote.DebitAmount,
+		&quote.DebitCurrency,
+		&fxRate,
+		&midRate,
+		&quote.FeeAmount,
+		&quote.ExpiresAt,
+		&quote.UsedAt,
+		&quote.TransactionID,
+		&quote.CreatedBy,
+		&quote.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	quote.FXRate = trimRate(fxRate)
+	quote.MidRate = trimRate(midRate)
+	return quote, nil
+}
+
+// trimRate drops the trailing zeros NUMERIC columns are returned with.
+func trimRate(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	rate := value.String
+	if strings.Contains(rate, ".") {
+		rate = strings.TrimRight(strings.TrimRight(rate, "0"), ".")
+	}
+	return &rate
+}
+
+// Create stores a new quote.
+func (r *QuoteRepository) Create(ctx context.Context, quote *models.TransferQuote) *errors.Error {
+	query := `
+		INSERT INTO transfer_quotes (
+			source_wallet_id, destination_wallet_id, amount, currency,
+			debit_amount, debit_currency, fx_rate, mid_rate, fee_amount,
+			expires_at, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		quote.SourceWalletID,
+		quote.DestinationWalletID,
+		quote.Amount,
+		quote.Currency,
+		quote.DebitAmount,
+		quote.DebitCurrency,
+		quote.FXRate,
+		quote.MidRate,
+		quote.FeeAmount,
+		quote.ExpiresAt.Time,
+		quote.CreatedBy,
+	).Scan(&quote.ID, &quote.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create transfer quote")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a quote by ID.
+func (r *QuoteRepository) GetByID(ctx context.Context, id string) (*models.TransferQuote, *errors.Error) {
+	query := `SELECT ` + quoteColumns + ` FROM transfer_quotes WHERE id = $1`
+
+	quote, err := scanQuote(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("transfer quote", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get transfer quote")
+	}
+
+	return quote, nil
+}
+
+// Claim marks an unexpired quote used so no other transfer can use it.
+func (r *QuoteRepository) Claim(ctx context.Context, id string) (*models.TransferQuote, *errors.Error) {
+	query := `
+		UPDATE transfer_quotes
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING ` + quoteColumns
+
+	quote, err := scanQuote(r.db.QueryRowContext(ctx, query, id))
+	if err == nil {
+		return quote, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, errors.DatabaseWrap(err, "failed to claim transfer quote")
+	}
+
+	existing, getErr := r.GetByID(ctx, id)
+	if getErr != nil {
+		return nil, getErr
+	}
+	if existing.UsedAt != nil {
+		return nil, errors.New(errors.ErrCodeQuoteUsed, "transfer quote has already been used")
+	}
+	return nil, errors.New(errors.ErrCodeQuoteExpired, "transfer quote has expired")
+}
+
+// Release returns a claimed quote whose transfer was never recorded.
+func (r *QuoteRepository) Release(ctx context.Context, id string) *errors.Error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE transfer_quotes SET used_at = NULL WHERE id = $1 AND transaction_id IS NULL`, id); err != nil {
+		return errors.DatabaseWrap(err, "failed to release transfer quote")
+	}
+	return nil
+}
+
+// SetTransaction records the transfer made with a claimed quote.
+func (r *QuoteRepository) SetTransaction(ctx context.Context, id, transactionID string) *errors.Error {
+	if _, err := r.db.ExecContext(ctx,
+		`UPDATE transfer_quotes SET transaction_id = $1 WHERE id = $2`, transactionID, id); err != nil {
+		return errors.DatabaseWrap(err, "failed to link transfer quote")
+	}
+	return nil
+}
+
+// PurgeExpired deletes unused quotes that expired before cutoff.
+func (r *QuoteRepository) PurgeExpired(ctx context.Context, cutoff time.Time) (int64, *errors.Error) {
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM transfer_quotes WHERE used_at IS NULL AND expires_at < $1`, cutoff)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to purge expired transfer quotes")
+	}
+
+	purged, _ := result.RowsAffected()
+	return purged, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// RiskBypassRepository handles queries over transactions that skipped risk evaluation.
+type RiskBypassRepository struct {
+	db *sql.DB
+}
+
+// NewRiskBypassRepository creates a new risk bypass repository.
+func NewRiskBypassRepository(db *sql.DB) *RiskBypassRepository {
+	return &RiskBypassRepository{db: db}
+}
+
+// ListBypassed returns transactions carrying the risk_bypassed marker.
+func (r *RiskBypassRepository) ListBypassed(ctx context.Context, filter *models.RiskBypassFilter) ([]*models.Transaction, *errors.Error) {
+	query := `
+		SELECT id, type, status, source_wallet_id, destination_wallet_id,
+		       amount, currency, description, category, category_overridden, reference, ledger_entry_id,
+		       parent_transaction_id, metadata, failure_reason,
+		       processed_at, completed_at, created_at, updated_at
+		FROM transactions
+		WHERE metadata->>'risk_bypassed' = 'true'
+	`
+
+	args := []interface{}{}
+	argCount := 0
+
+	if filter.Unresolved {
+		query += " AND NOT (metadata ? 'risk_rescored_at')"
+	}
+
+	if filter.OldestFirst {
+		query += " ORDER BY created_at ASC"
+	} else {
+		query += " ORDER BY created_at DESC"
+	}
+
+	if filter.Limit > 0 {
+		argCount++
+		query += fmt.Sprintf(" LIMIT $%d", argCount)
+		args = append(args, filter.Limit)
+	}
+	if filter.Offset > 0 {
+		argCount++
+		query += fmt.Sprintf(" OFFSET $%d", argCount)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list risk-bypassed transactions")
+	}
+	defer func() { _ = rows.Close() }()
+
+	transactions := make([]*models.Transaction, 0)
+	for rows.Next() {
+		tx := &models.Transaction{}
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&tx.ID,
+			&tx.Type,
+			&tx.Status,
+			&tx.SourceWalletID,
+			&tx.DestinationWalletID,
+			&tx.Amount,
+			&tx.Currency,
+			&tx.Description,
+			&tx.Category,
+			&tx.CategoryOverridden,
+			&tx.Reference,
+			&tx.LedgerEntryID,
+			&tx.ParentTransactionID,
+			&metadataJSON,
+			&tx.FailureReason,
+			&tx.ProcessedAt,
+			&tx.CompletedAt,
+			&tx.CreatedAt,
+			&tx.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan transaction")
+		}
+
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &tx.Metadata); err != nil {
+				return nil, errors.Internal("failed to parse metadata")
+			}
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating transactions")
+	}
+
+	return transactions, nil
+}
+
+// MergeMetadata merges keys into a transaction's metadata without replacing
+// keys written concurrently by the transfer path.
+func (r *RiskBypassRepository) MergeMetadata(ctx context.Context, id string, metadata map[string]string) *errors.Error {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.Validation("invalid metadata format")
+	}
+
+	query := `
+		UPDATE transactions
+		SET metadata = COALESCE(metadata, '{}'::jsonb) || $1::jsonb, updated_at = NOW()
+		WHERE id = $2
+		RETURNING id
+	`
+
+	var txID string
+	dbErr := r.db.QueryRowContext(ctx, query, metadataJSON, id).Scan(&txID)
+	if dbErr != nil {
+		if dbErr == sql.ErrNoRows {
+			return errors.NotFoundWithID("transaction", id)
+		}
+		return errors.DatabaseWrap(dbErr, "failed to merge transaction metadata")
+	}
+
+	return nil
+}
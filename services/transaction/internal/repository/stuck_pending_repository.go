@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// StuckPendingRepository finds and fails transfers left pending past a threshold.
+type StuckPendingRepository struct {
+	db *sql.DB
+}
+
+// NewStuckPendingRepository creates a new stuck pending transfer repository.
+func NewStuckPendingRepository(db *sql.DB) *StuckPendingRepository {
+	return &StuckPendingRepository{db: db}
+}
+
+// ListStuckPending returns the IDs of up to limit transfers still pending
+// since before createdBefore, oldest first.
+func (r *StuckPendingRepository) ListStuckPending(ctx context.Context, createdBefore time.Time, limit int) ([]string, *errors.Error) {
+	query := `
+		SELECT id FROM transactions
+		WHERE type = 'transfer' AND status = 'pending' AND created_at < $1
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, createdBefore, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list stuck pending transfers")
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan stuck pending transfer")
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating stuck pending transfers")
+	}
+	return ids, nil
+}
+
+// FailPending marks a transfer failed with reason, provided it is still
+// pending, and reports whether it did.
+func (r *StuckPendingRepository) FailPending(ctx context.Context, id, reason string) (bool, *errors.Error) {
+	query := `
+		UPDATE transactions
+		SET status = 'failed', failure_reason = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, reason)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to fail stuck pending transfer")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	return rows > 0, nil
+}
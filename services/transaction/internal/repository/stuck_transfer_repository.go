@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// StuckTransferRepository creates and removes backdated pending transfers for
+// simulation anomalies. It must never be wired up in production.
+type StuckTransferRepository struct {
+	db *sql.DB
+}
+
+// NewStuckTransferRepository creates a new stuck transfer repository.
+func NewStuckTransferRepository(db *sql.DB) *StuckTransferRepository {
+	return &StuckTransferRepository{db: db}
+}
+
+// Create inserts a pending transfer of amount created at createdAt, between
+// the wallets of the most recent completed transfer, and returns its ID. The
+// transfer is flagged with simulation_anomaly metadata.
+func (r *StuckTransferRepository) Create(ctx context.Context, amount int64, createdAt time.Time) (string, *errors.Error) {
+	query := `
+		INSERT INTO transactions (type, status, source_wallet_id, destination_wallet_id,
+		                          amount, currency, description, metadata, created_at, updated_at)
+		SELECT 'transfer', 'pending', source_wallet_id, destination_wallet_id,
+		       $1, currency, 'Simulation anomaly: stuck pending transfer',
+		       '{"simulation_anomaly": "true"}'::jsonb, $2, $2
+		FROM transactions
+		WHERE type = 'transfer' AND status = 'completed'
+		  AND source_wallet_id IS NOT NULL AND destination_wallet_id IS NOT NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+		RETURNING id
+	`
+
+	var id string
+	err := r.db.QueryRowContext(ctx, query, amount, createdAt).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", errors.NotFound("completed transfer to take a wallet pair from")
+	}
+	if err != nil {
+		return "", errors.DatabaseWrap(err, "failed to create stuck transfer")
+	}
+	return id, nil
+}
+
+// Delete removes a transfer created by Create and reports whether it did.
+// Transfers without the simulation_anomaly flag are never touched.
+func (r *StuckTransferRepository) Delete(ctx context.Context, id string) (bool, *errors.Error) {
+	query := `DELETE FROM transactions WHERE id = $1 AND metadata->>'simulation_anomaly' = 'true'`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to delete stuck transfer")
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const sweepRuleColumns = `id, wallet_id, counterpart_wallet_id, direction, threshold, target_balance,
+		       min_amount, enabled, created_by, last_executed_at, created_at, updated_at`
+
+// SweepRepository handles database operations for auto-sweep rules and their
+// execution history.
+type SweepRepository struct {
+	db *sql.DB
+}
+
+// NewSweepRepository creates a new sweep repository.
+func NewSweepRepository(db *sql.DB) *SweepRepository {
+	return &SweepRepository{db: db}
+}
+
+// scanSweepRule scans a row selected with sweepRuleColumns.
+func scanSweepRule(row interface{ Scan(...interface{}) error }) (*models.SweepRule, error) {
+	rule := &models.SweepRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.WalletID,
+		&rule.CounterpartWalletID,
+		&rule.Direction,
+		&rule.Threshold,
+		&rule.TargetBalance,
+		&rule.MinAmount,
+		&rule.Enabled,
+		&rule.CreatedBy,
+		&rule.LastExecutedAt,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	return rule, err
+}
+
+// Create stores a new sweep rule.
+func (r *SweepRepository) Create(ctx context.Context, rule *models.SweepRule) *errors.Error {
+	query := `
+		INSERT INTO sweep_rules (
+			wallet_id, counterpart_wallet_id, direction, threshold, target_balance,
+			min_amount, enabled, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.WalletID, rule.CounterpartWalletID, rule.Direction, rule.Threshold, rule.TargetBalance,
+		rule.MinAmount, rule.Enabled, rule.CreatedBy,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create sweep rule")
+	}
+
+	return nil
+}
+
+// GetByID retrieves a sweep rule of a wallet.
+func (r *SweepRepository) GetByID(ctx context.Context, walletID, id string) (*models.SweepRule, *errors.Error) {
+	query := `SELECT ` + sweepRuleColumns + ` FROM sweep_rules WHERE id = $1 AND wallet_id = $2`
+
+	rule, err := scanSweepRule(r.db.QueryRowContext(ctx, query, id, walletID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("sweep rule", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get sweep rule")
+	}
+
+	return rule, nil
+}
+
+// Update replaces a sweep rule's settings.
+func (r *SweepRepository) Update(ctx context.Context, rule *models.SweepRule) *errors.Error {
+	query := `
+		UPDATE sweep_rules
+		SET counterpart_wallet_id = $1, direction = $2, threshold = $3, target_balance = $4,
+		    min_amount = $5, enabled = $6, updated_at = NOW()
+		WHERE id = $7 AND wallet_id = $8
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.CounterpartWalletID, rule.Direction, rule.Threshold, rule.TargetBalance,
+		rule.MinAmount, rule.Enabled, rule.ID, rule.WalletID,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("sweep rule", rule.ID)
+		}
+		return errors.DatabaseWrap(err, "failed to update sweep rule")
+	}
+
+	return nil
+}
+
+// Delete deletes a sweep rule and its execution history.
+func (r *SweepRepository) Delete(ctx context.Context, walletID, id string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM sweep_rules WHERE id = $1 AND wallet_id = $2", id, walletID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete sweep rule")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete sweep rule")
+	}
+	if affected == 0 {
+		return errors.NotFoundWithID("sweep rule", id)
+	}
+
+	return nil
+}
+
+// ListByWallet returns a wallet's sweep rules, oldest first. With enabledOnly
+// set, disabled rules are left out.
+func (r *SweepRepository) ListByWallet(ctx context.Context, walletID string, enabledOnly bool) ([]*models.SweepRule, *errors.Error) {
+	query := `SELECT ` + sweepRuleColumns + ` FROM sweep_rules WHERE wallet_id = $1`
+	if enabledOnly {
+		query += ` AND enabled`
+	}
+	query += ` ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep rules")
+	}
+	defer func() { _ = rows.Close() }()
+
+	rules := make([]*models.SweepRule, 0)
+	for rows.Next() {
+		rule, err := scanSweepRule(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan sweep rule")
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep rules")
+	}
+
+	return rules, nil
+}
+
+// ListWalletsWithEnabledRules returns the wallets that have an enabled rule.
+func (r *SweepRepository) ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT wallet_id FROM sweep_rules WHERE enabled`)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep wallets")
+	}
+	defer func() { _ = rows.Close() }()
+
+	var walletIDs []string
+	for rows.Next() {
+		var walletID string
+		if err := rows.Scan(&walletID); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan sweep wallet")
+		}
+		walletIDs = append(walletIDs, walletID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep wallets")
+	}
+
+	return walletIDs, nil
+}
+
+// Claim marks an enabled rule as executing at now unless it already ran after
+// notBefore. Only one caller wins a claim, so concurrent evaluations and
+// service instances cannot run the same sweep twice.
+func (r *SweepRepository) Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error) {
+	query := `
+		UPDATE sweep_rules
+		SET last_executed_at = $2
+		WHERE id = $1 AND enabled AND (last_executed_at IS NULL OR last_executed_at <= $3)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, now, notBefore)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim sweep rule")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to claim sweep rule")
+	}
+	return affected == 1, nil
+}
+
+// RecordExecution stores the outcome of a sweep.
+func (r *SweepRepository) RecordExecution(ctx context.Context, exec *models.SweepExecution) *errors.Error {
+	query := `
+		INSERT INTO sweep_executions (
+			rule_id, wallet_id, direction, balance_before, amount, status, transaction_id, failure_reason
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		exec.RuleID, exec.WalletID, exec.Direction, exec.BalanceBefore, exec.Amount, exec.Status,
+		exec.TransactionID, exec.FailureReason,
+	).Scan(&exec.ID, &exec.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record sweep execution")
+	}
+
+	return nil
+}
+
+// ListExecutions returns a wallet's most recent sweeps, newest first,
+// optionally for one rule.
+func (r *SweepRepository) ListExecutions(ctx context.Context, walletID, ruleID string, limit int) ([]*models.SweepExecution, *errors.Error) {
+	query := `
+		SELECT id, rule_id, wallet_id, direction, balance_before, amount, status,
+		       transaction_id, failure_reason, created_at
+		FROM sweep_executions
+		WHERE wallet_id = $1 AND ($2 = '' OR rule_id::text = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, walletID, ruleID, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep executions")
+	}
+	defer func() { _ = rows.Close() }()
+
+	executions := make([]*models.SweepExecution, 0)
+	for rows.Next() {
+		exec := &models.SweepExecution{}
+		if err := rows.Scan(
+			&exec.ID, &exec.RuleID, &exec.WalletID, &exec.Direction, &exec.BalanceBefore, &exec.Amount,
+			&exec.Status, &exec.TransactionID, &exec.FailureReason, &exec.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan sweep execution")
+		}
+		executions = append(executions, exec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list sweep executions")
+	}
+
+	return executions, nil
+}
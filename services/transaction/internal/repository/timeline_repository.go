@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// TimelineRepository handles database operations for transaction timelines.
+type TimelineRepository struct {
+	db *sql.DB
+}
+
+// NewTimelineRepository creates a new timeline repository.
+func NewTimelineRepository(db *sql.DB) *TimelineRepository {
+	return &TimelineRepository{db: db}
+}
+
+// Record appends an event to a transaction's timeline.
+func (r *TimelineRepository) Record(ctx context.Context, event *models.TransactionEvent) *errors.Error {
+	var detailsJSON []byte
+	if len(event.Details) > 0 {
+		var err error
+		detailsJSON, err = json.Marshal(event.Details)
+		if err != nil {
+			return errors.Internal("failed to marshal timeline details")
+		}
+	}
+
+	query := `
+		INSERT INTO transaction_events (transaction_id, event_type, status, actor_type, actor_id, details)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		event.TransactionID,
+		event.EventType,
+		event.Status,
+		event.ActorType,
+		event.ActorID,
+		detailsJSON,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to record transaction event")
+	}
+
+	return nil
+}
+
+// ListByTransaction returns a transaction's timeline, oldest event first.
+func (r *TimelineRepository) ListByTransaction(ctx context.Context, transactionID string) ([]*models.TransactionEvent, *errors.Error) {
+	query := `
+		SELECT id, transaction_id, event_type, status, actor_type, actor_id, details, created_at
+		FROM transaction_events
+		WHERE transaction_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list transaction events")
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]*models.TransactionEvent, 0)
+	for rows.Next() {
+		event := &models.TransactionEvent{}
+		var detailsJSON []byte
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.TransactionID,
+			&event.EventType,
+			&event.Status,
+			&event.ActorType,
+			&event.ActorID,
+			&detailsJSON,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan transaction event")
+		}
+
+		if len(detailsJSON) > 0 {
+			if err := json.Unmarshal(detailsJSON, &event.Details); err != nil {
+				return nil, errors.Internal("failed to unmarshal timeline details")
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list transaction events")
+	}
+
+	return events, nil
+}
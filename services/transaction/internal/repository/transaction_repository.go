@@ -79,7 +79,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*models
 
 	query := `
 		SELECT id, type, status, source_wallet_id, destination_wallet_id,
-		       amount, currency, description, category, reference, ledger_entry_id,
+		       amount, currency, description, category, category_overridden, reference, ledger_entry_id,
 		       parent_transaction_id, metadata, failure_reason,
 		       processed_at, completed_at, created_at, updated_at
 		FROM transactions
@@ -96,6 +96,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*models
 		&tx.Currency,
 		&tx.Description,
 		&tx.Category,
+		&tx.CategoryOverridden,
 		&tx.Reference,
 		&tx.LedgerEntryID,
 		&tx.ParentTransactionID,
@@ -128,7 +129,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*models
 func (r *TransactionRepository) ListByWallet(ctx context.Context, walletID string, filter *models.TransactionFilter) ([]*models.Transaction, *errors.Error) {
 	query := `
 		SELECT id, type, status, source_wallet_id, destination_wallet_id,
-		       amount, currency, description, category, reference, ledger_entry_id,
+		       amount, currency, description, category, category_overridden, reference, ledger_entry_id,
 		       parent_transaction_id, metadata, failure_reason,
 		       processed_at, completed_at, created_at, updated_at
 		FROM transactions
@@ -222,6 +223,7 @@ func (r *TransactionRepository) ListByWallet(ctx context.Context, walletID strin
 			&tx.Currency,
 			&tx.Description,
 			&tx.Category,
+			&tx.CategoryOverridden,
 			&tx.Reference,
 			&tx.LedgerEntryID,
 			&tx.ParentTransactionID,
@@ -260,7 +262,7 @@ func (r *TransactionRepository) SearchAll(ctx context.Context, filter *models.Tr
 	var cteClause string
 	baseQuery := `
 		SELECT id, type, status, source_wallet_id, destination_wallet_id,
-		       amount, currency, description, category, reference, ledger_entry_id,
+		       amount, currency, description, category, category_overridden, reference, ledger_entry_id,
 		       parent_transaction_id, metadata, failure_reason,
 		       processed_at, completed_at, created_at, updated_at
 		FROM transactions
@@ -377,6 +379,7 @@ func (r *TransactionRepository) SearchAll(ctx context.Context, filter *models.Tr
 			&tx.Currency,
 			&tx.Description,
 			&tx.Category,
+			&tx.CategoryOverridden,
 			&tx.Reference,
 			&tx.LedgerEntryID,
 			&tx.ParentTransactionID,
@@ -551,17 +554,18 @@ func (r *TransactionRepository) CompleteWithMetadata(ctx context.Context, id str
 	return nil
 }
 
-// UpdateCategory updates the category of a transaction.
-func (r *TransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory) *errors.Error {
+// UpdateCategory updates the category of a transaction. overridden marks a
+// category chosen by the user, which re-categorization leaves alone.
+func (r *TransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory, overridden bool) *errors.Error {
 	query := `
 		UPDATE transactions
-		SET category = $1, updated_at = NOW()
-		WHERE id = $2
+		SET category = $1, category_overridden = $2, updated_at = NOW()
+		WHERE id = $3
 		RETURNING id
 	`
 
 	var txID string
-	err := r.db.QueryRowContext(ctx, query, category, id).Scan(&txID)
+	err := r.db.QueryRowContext(ctx, query, category, overridden, id).Scan(&txID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -576,9 +580,9 @@ func (r *TransactionRepository) UpdateCategory(ctx context.Context, id string, c
 // GetCategoryPatterns retrieves all category patterns ordered by priority.
 func (r *TransactionRepository) GetCategoryPatterns(ctx context.Context) ([]*models.CategoryPattern, *errors.Error) {
 	query := `
-		SELECT id, pattern, category, priority, created_at
+		SELECT id, pattern, match_type, category, priority, created_at, updated_at
 		FROM category_patterns
-		ORDER BY priority DESC
+		ORDER BY priority DESC, created_at ASC
 	`
 
 	rows, err := r.db.QueryContext(ctx, query)
@@ -590,7 +594,7 @@ func (r *TransactionRepository) GetCategoryPatterns(ctx context.Context) ([]*mod
 	patterns := make([]*models.CategoryPattern, 0)
 	for rows.Next() {
 		p := &models.CategoryPattern{}
-		err := rows.Scan(&p.ID, &p.Pattern, &p.Category, &p.Priority, &p.CreatedAt)
+		err := rows.Scan(&p.ID, &p.Pattern, &p.MatchType, &p.Category, &p.Priority, &p.CreatedAt, &p.UpdatedAt)
 		if err != nil {
 			return nil, errors.DatabaseWrap(err, "failed to scan category pattern")
 		}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const withdrawalApprovalColumns = `transaction_id, user_id, wallet_id, amount, stage, requires_admin,
+	       verification_id, approval_id, expires_at, confirmed_at, decided_at, created_at`
+
+// WithdrawalApprovalRepository handles database operations for the approval
+// state of withdrawals held as pending_approval.
+type WithdrawalApprovalRepository struct {
+	db *sql.DB
+}
+
+// NewWithdrawalApprovalRepository creates a new withdrawal approval repository.
+func NewWithdrawalApprovalRepository(db *sql.DB) *WithdrawalApprovalRepository {
+	return &WithdrawalApprovalRepository{db: db}
+}
+
+// scanWithdrawalApproval scans a row selected with withdrawalApprovalColumns.
+func scanWithdrawalApproval(row interface{ Scan(...interface{}) error }) (*models.WithdrawalApproval, error) {
+	a := &models.WithdrawalApproval{}
+	err := row.Scan(
+		&a.TransactionID,
+		&a.UserID,
+		&a.WalletID,
+		&a.Amount,
+		&a.Stage,
+		&a.RequiresAdmin,
+		&a.VerificationID,
+		&a.ApprovalID,
+		&a.ExpiresAt,
+		&a.ConfirmedAt,
+		&a.DecidedAt,
+		&a.CreatedAt,
+	)
+	return a, err
+}
+
+// Create stores the approval state of a new withdrawal.
+func (r *WithdrawalApprovalRepository) Create(ctx context.Context, a *models.WithdrawalApproval) *errors.Error {
+	query := `
+		INSERT INTO withdrawal_approvals (transaction_id, user_id, wallet_id, amount, stage, requires_admin, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		a.TransactionID, a.UserID, a.WalletID, a.Amount, a.Stage, a.RequiresAdmin, a.ExpiresAt.Time,
+	).Scan(&a.CreatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create withdrawal approval")
+	}
+
+	return nil
+}
+
+// Get retrieves the approval state of a withdrawal.
+func (r *WithdrawalApprovalRepository) Get(ctx context.Context, transactionID string) (*models.WithdrawalApproval, *errors.Error) {
+	query := `SELECT ` + withdrawalApprovalColumns + ` FROM withdrawal_approvals WHERE transaction_id = $1`
+
+	a, err := scanWithdrawalApproval(r.db.QueryRowContext(ctx, query, transactionID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("withdrawal approval", transactionID)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get withdrawal approval")
+	}
+
+	return a, nil
+}
+
+// Confirm records the owner's confirmation of a withdrawal awaiting it and
+// moves it to stage, with the admin approval it waits for, if any, and the
+// deadline of that stage. It returns a conflict if the withdrawal no longer
+// awaits confirmation or its confirmation expired.
+func (r *WithdrawalApprovalRepository) Confirm(ctx context.Context, transactionID, verificationID string, stage models.WithdrawalApprovalStage, approvalID *string, expiresAt time.Time) (*models.WithdrawalApproval, *errors.Error) {
+	query := `
+		UPDATE withdrawal_approvals
+		SET stage = $2, verification_id = $3, approval_id = $4, expires_at = $5, confirmed_at = NOW(),
+		    decided_at = CASE WHEN $2::text = 'released' THEN NOW() ELSE NULL END
+		WHERE transaction_id = $1 AND stage = 'awaiting_confirmation' AND expires_at > NOW()
+		RETURNING ` + withdrawalApprovalColumns
+
+	a, err := scanWithdrawalApproval(r.db.QueryRowContext(ctx, query, transactionID, stage, verificationID, approvalID, expiresAt))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Conflict("withdrawal is no longer awaiting confirmation")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to confirm withdrawal")
+	}
+
+	return a, nil
+}
+
+// Decide moves a withdrawal from one of the open stages to a final stage. It
+// returns a conflict if the withdrawal is not in from, so a withdrawal is
+// decided once.
+func (r *WithdrawalApprovalRepository) Decide(ctx context.Context, transactionID string, from, to models.WithdrawalApprovalStage) (*models.WithdrawalApproval, *errors.Error) {
+	query := `
+		UPDATE withdrawal_approvals
+		SET stage = $3, decided_at = NOW()
+		WHERE transaction_id = $1 AND stage = $2
+		RETURNING ` + withdrawalApprovalColumns
+
+	a, err := scanWithdrawalApproval(r.db.QueryRowContext(ctx, query, transactionID, from, to))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Conflict("withdrawal approval was already decided")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to decide withdrawal approval")
+	}
+
+	return a, nil
+}
+
+// ListOpen returns withdrawals in stage whose deadline is before cutoff,
+// oldest deadline first. A zero cutoff lists them all.
+func (r *WithdrawalApprovalRepository) ListOpen(ctx context.Context, stage models.WithdrawalApprovalStage, cutoff time.Time, limit int) ([]*models.WithdrawalApproval, *errors.Error) {
+	query := `SELECT ` + withdrawalApprovalColumns + ` FROM withdrawal_approvals
+		WHERE stage = $1 AND ($2::timestamptz IS NULL OR expires_at < $2)
+		ORDER BY expires_at, transaction_id
+		LIMIT $3`
+
+	var before interface{}
+	if !cutoff.IsZero() {
+		before = cutoff
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, stage, before, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list withdrawal approvals")
+	}
+	defer func() { _ = rows.Close() }()
+
+	approvals := make([]*models.WithdrawalApproval, 0)
+	for rows.Next() {
+		a, err := scanWithdrawalApproval(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan withdrawal approval")
+		}
+		approvals = append(approvals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list withdrawal approvals")
+	}
+
+	return approvals, nil
+}
@@ -4,12 +4,13 @@ import (
 	"net/http"
 
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/handler"
+	"github.com/1mb-dev/nivomoney/shared/approval"
 	"github.com/1mb-dev/nivomoney/shared/metrics"
 	"github.com/1mb-dev/nivomoney/shared/middleware"
 )
 
 // SetupRoutes configures all routes for the transaction service using Go 1.22+ stdlib router.
-func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret string) http.Handler {
+func SetupRoutes(transactionHandler *handler.TransactionHandler, payeeHandler *handler.PayeeHandler, categoryHandler *handler.CategoryHandler, sweepHandler *handler.SweepHandler, fundingHandler *handler.FundingHandler, merchantWebhookHandler *handler.MerchantWebhookHandler, paymentIntentHandler *handler.PaymentIntentHandler, amountPolicyHandler *handler.AmountPolicyHandler, approvalHandler *approval.Handler, jwtSecret string) http.Handler {
 	mux := http.NewServeMux()
 
 	// Health check endpoint (public)
@@ -40,24 +41,89 @@ func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret strin
 	listTransactionsPerm := middleware.RequirePermission("transaction:transaction:list")
 	searchAllTransactionsPerm := middleware.RequirePermission("transaction:transaction:search")
 	reverseTransactionPerm := middleware.RequirePermission("transaction:transaction:reverse")
+	manageCategoriesPerm := middleware.RequirePermission("transaction:category:manage")
+	reviewApprovalsPerm := middleware.RequirePermission("transaction:approval:review")
+	manageAmountPoliciesPerm := middleware.RequirePermission("transaction:amount_policy:manage")
 
 	// ========================================================================
 	// Transaction Creation Endpoints (with strict rate limiting)
 	// ========================================================================
 
 	mux.Handle("POST /api/v1/transactions/transfer", moneyRateLimit(authMiddleware(createTransferPerm(http.HandlerFunc(transactionHandler.CreateTransfer)))))
+	mux.Handle("POST /api/v1/transactions/quote", authMiddleware(createTransferPerm(http.HandlerFunc(transactionHandler.QuoteTransfer))))
 	mux.Handle("POST /api/v1/transactions/deposit", moneyRateLimit(authMiddleware(createDepositPerm(http.HandlerFunc(transactionHandler.CreateDeposit)))))
 	mux.Handle("POST /api/v1/transactions/deposit/upi", moneyRateLimit(authMiddleware(createDepositPerm(http.HandlerFunc(transactionHandler.InitiateUPIDeposit)))))
 	mux.Handle("POST /api/v1/transactions/deposit/upi/complete", authMiddleware(http.HandlerFunc(transactionHandler.CompleteUPIDeposit))) // Webhook endpoint (no rate limit)
 	mux.Handle("POST /api/v1/transactions/withdrawal", moneyRateLimit(authMiddleware(createWithdrawalPerm(http.HandlerFunc(transactionHandler.CreateWithdrawal)))))
+	mux.Handle("GET /api/v1/transactions/withdrawal/{id}/approval", authMiddleware(readTransactionPerm(http.HandlerFunc(transactionHandler.GetWithdrawalApproval))))
+	mux.Handle("POST /api/v1/transactions/withdrawal/{id}/confirm", moneyRateLimit(authMiddleware(createWithdrawalPerm(http.HandlerFunc(transactionHandler.ConfirmWithdrawal)))))
+	mux.Handle("POST /api/v1/transactions/withdrawal/{id}/cancel", authMiddleware(createWithdrawalPerm(http.HandlerFunc(transactionHandler.CancelWithdrawal))))
 
 	// ========================================================================
 	// Transaction Retrieval Endpoints
 	// ========================================================================
 
 	mux.Handle("GET /api/v1/transactions/{id}", authMiddleware(readTransactionPerm(http.HandlerFunc(transactionHandler.GetTransaction))))
+	mux.Handle("GET /api/v1/transactions/{id}/timeline", authMiddleware(readTransactionPerm(http.HandlerFunc(transactionHandler.GetTimeline))))
+	mux.Handle("GET /api/v1/transactions/{id}/receipt", authMiddleware(readTransactionPerm(http.HandlerFunc(transactionHandler.GetReceipt))))
 	mux.Handle("GET /api/v1/wallets/{walletId}/transactions", authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.ListWalletTransactions))))
 
+	// ========================================================================
+	// Payee Endpoints (quick-pay list from transfer history)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/wallets/{walletId}/payees", authMiddleware(listTransactionsPerm(http.HandlerFunc(payeeHandler.ListPayees))))
+	mux.Handle("PUT /api/v1/wallets/{walletId}/payees/{payeeWalletId}/favorite", authMiddleware(createTransferPerm(http.HandlerFunc(payeeHandler.PinPayee))))
+	mux.Handle("DELETE /api/v1/wallets/{walletId}/payees/{payeeWalletId}/favorite", authMiddleware(createTransferPerm(http.HandlerFunc(payeeHandler.UnpinPayee))))
+
+	// ========================================================================
+	// Auto-Sweep Rule Endpoints (standing instructions between a user's wallets)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/wallets/{walletId}/sweep-rules", authMiddleware(listTransactionsPerm(http.HandlerFunc(sweepHandler.ListRules))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/sweep-rules", authMiddleware(createTransferPerm(http.HandlerFunc(sweepHandler.CreateRule))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/sweep-rules/executions", authMiddleware(listTransactionsPerm(http.HandlerFunc(sweepHandler.ListExecutions))))
+	mux.Handle("PUT /api/v1/wallets/{walletId}/sweep-rules/{id}", authMiddleware(createTransferPerm(http.HandlerFunc(sweepHandler.UpdateRule))))
+	mux.Handle("DELETE /api/v1/wallets/{walletId}/sweep-rules/{id}", authMiddleware(createTransferPerm(http.HandlerFunc(sweepHandler.DeleteRule))))
+
+	// ========================================================================
+	// Auto Top-Up Endpoints (deposits from linked bank accounts under a mandate)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/funding-sources", authMiddleware(listTransactionsPerm(http.HandlerFunc(fundingHandler.ListSources))))
+	mux.Handle("POST /api/v1/funding-sources", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.LinkSource))))
+	mux.Handle("DELETE /api/v1/funding-sources/{id}", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.RemoveSource))))
+	mux.Handle("GET /api/v1/mandates", authMiddleware(listTransactionsPerm(http.HandlerFunc(fundingHandler.ListMandates))))
+	mux.Handle("POST /api/v1/mandates", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.CreateMandate))))
+	mux.Handle("POST /api/v1/mandates/{id}/cancel", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.CancelMandate))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/auto-top-up", authMiddleware(listTransactionsPerm(http.HandlerFunc(fundingHandler.GetRule))))
+	mux.Handle("PUT /api/v1/wallets/{walletId}/auto-top-up", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.SetRule))))
+	mux.Handle("DELETE /api/v1/wallets/{walletId}/auto-top-up", authMiddleware(createDepositPerm(http.HandlerFunc(fundingHandler.DeleteRule))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/auto-top-up/executions", authMiddleware(listTransactionsPerm(http.HandlerFunc(fundingHandler.ListExecutions))))
+
+	// ========================================================================
+	// Merchant Webhook Endpoints (signed notifications of incoming payments)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/wallets/{walletId}/merchant-webhook", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.GetWebhook))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/merchant-webhook", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.CreateWebhook))))
+	mux.Handle("PUT /api/v1/wallets/{walletId}/merchant-webhook", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.UpdateWebhook))))
+	mux.Handle("DELETE /api/v1/wallets/{walletId}/merchant-webhook", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.DeleteWebhook))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/merchant-webhook/rotate-secret", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.RotateSecret))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/merchant-webhook/deliveries", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.ListDeliveries))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/merchant-webhook/deliveries/{id}/redeliver", authMiddleware(listTransactionsPerm(http.HandlerFunc(merchantWebhookHandler.Redeliver))))
+
+	// ========================================================================
+	// Merchant Payment Intent Endpoints (order payments by QR code or deeplink)
+	// ========================================================================
+
+	mux.Handle("GET /api/v1/wallets/{walletId}/payment-intents", authMiddleware(listTransactionsPerm(http.HandlerFunc(paymentIntentHandler.ListIntents))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/payment-intents", authMiddleware(createTransferPerm(http.HandlerFunc(paymentIntentHandler.CreateIntent))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/payment-intents/{orderReference}", authMiddleware(listTransactionsPerm(http.HandlerFunc(paymentIntentHandler.GetIntent))))
+	mux.Handle("POST /api/v1/wallets/{walletId}/payment-intents/{orderReference}/cancel", authMiddleware(createTransferPerm(http.HandlerFunc(paymentIntentHandler.CancelIntent))))
+	mux.Handle("GET /api/v1/payment-intents/{id}", authMiddleware(createTransferPerm(http.HandlerFunc(paymentIntentHandler.GetPayerView))))
+	mux.Handle("POST /api/v1/payment-intents/{id}/confirm", moneyRateLimit(authMiddleware(createTransferPerm(http.HandlerFunc(paymentIntentHandler.ConfirmIntent)))))
+
 	// ========================================================================
 	// Spending Category Endpoints
 	// ========================================================================
@@ -67,6 +133,20 @@ func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret strin
 	mux.Handle("POST /api/v1/transactions/{id}/auto-categorize", authMiddleware(updateCategoryPerm(http.HandlerFunc(transactionHandler.AutoCategorizeTransaction))))
 	mux.Handle("GET /api/v1/wallets/{walletId}/spending-summary", authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.GetSpendingSummary))))
 
+	// Category rules management and bulk re-categorization (admin)
+	mux.Handle("GET /api/v1/admin/transactions/category-patterns", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.ListPatterns))))
+	mux.Handle("POST /api/v1/admin/transactions/category-patterns", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.CreatePattern))))
+	mux.Handle("GET /api/v1/admin/transactions/category-patterns/{id}", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.GetPattern))))
+	mux.Handle("PUT /api/v1/admin/transactions/category-patterns/{id}", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.UpdatePattern))))
+	mux.Handle("DELETE /api/v1/admin/transactions/category-patterns/{id}", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.DeletePattern))))
+	mux.Handle("POST /api/v1/admin/transactions/recategorize", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.StartRecategorization))))
+	mux.Handle("GET /api/v1/admin/transactions/recategorize/{jobId}", authMiddleware(manageCategoriesPerm(http.HandlerFunc(categoryHandler.GetRecategorization))))
+
+	// Minimum and maximum amounts per operation and currency (admin)
+	mux.Handle("GET /api/v1/admin/transactions/amount-policies", authMiddleware(manageAmountPoliciesPerm(http.HandlerFunc(amountPolicyHandler.ListPolicies))))
+	mux.Handle("PUT /api/v1/admin/transactions/amount-policies", authMiddleware(manageAmountPoliciesPerm(http.HandlerFunc(amountPolicyHandler.SetPolicy))))
+	mux.Handle("DELETE /api/v1/admin/transactions/amount-policies/{id}", authMiddleware(manageAmountPoliciesPerm(http.HandlerFunc(amountPolicyHandler.DeletePolicy))))
+
 	// ========================================================================
 	// Statement Export Endpoints (with rate limiting to prevent resource abuse)
 	// ========================================================================
@@ -79,12 +159,14 @@ func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret strin
 	mux.Handle("GET /api/v1/wallets/{walletId}/statements/csv", exportRateLimit(authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.ExportStatementCSV)))))
 	mux.Handle("GET /api/v1/wallets/{walletId}/statements/pdf", exportRateLimit(authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.ExportStatementPDF)))))
 	mux.Handle("GET /api/v1/wallets/{walletId}/statements/json", exportRateLimit(authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.GetStatementJSON)))))
+	mux.Handle("GET /api/v1/wallets/{walletId}/transactions/export", exportRateLimit(authMiddleware(listTransactionsPerm(http.HandlerFunc(transactionHandler.ExportTransactions)))))
 
 	// ========================================================================
 	// Admin Transaction Search Endpoint
 	// ========================================================================
 
 	mux.Handle("GET /api/v1/admin/transactions/search", moneyRateLimit(authMiddleware(searchAllTransactionsPerm(http.HandlerFunc(transactionHandler.SearchAllTransactions)))))
+	mux.Handle("GET /api/v1/admin/transactions/risk-bypassed", authMiddleware(searchAllTransactionsPerm(http.HandlerFunc(transactionHandler.ListRiskBypassedTransactions))))
 
 	// ========================================================================
 	// Transaction Reversal Endpoint (Admin Operation - with strict rate limiting)
@@ -92,6 +174,14 @@ func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret strin
 
 	mux.Handle("POST /api/v1/transactions/{id}/reverse", moneyRateLimit(authMiddleware(reverseTransactionPerm(http.HandlerFunc(transactionHandler.ReverseTransaction)))))
 
+	// Maker-checker approvals for reversals above the threshold
+	if approvalHandler != nil {
+		mux.Handle("GET /api/v1/admin/transactions/approvals", authMiddleware(reviewApprovalsPerm(http.HandlerFunc(approvalHandler.List))))
+		mux.Handle("GET /api/v1/admin/transactions/approvals/{id}", authMiddleware(reviewApprovalsPerm(http.HandlerFunc(approvalHandler.Get))))
+		mux.Handle("POST /api/v1/admin/transactions/approvals/{id}/approve", moneyRateLimit(authMiddleware(reviewApprovalsPerm(http.HandlerFunc(approvalHandler.Approve)))))
+		mux.Handle("POST /api/v1/admin/transactions/approvals/{id}/reject", authMiddleware(reviewApprovalsPerm(http.HandlerFunc(approvalHandler.Reject))))
+	}
+
 	// ========================================================================
 	// Internal Endpoints (no authentication - service-to-service)
 	// ========================================================================
@@ -99,10 +189,26 @@ func SetupRoutes(transactionHandler *handler.TransactionHandler, jwtSecret strin
 	// Process transfer (executes wallet transfer with limit checking)
 	mux.HandleFunc("POST /internal/v1/transactions/{id}/process", transactionHandler.ProcessTransfer)
 
+	// Sweep the balance of a wallet being closed (called by wallet service)
+	mux.HandleFunc("POST /internal/v1/transactions/closure-sweeps", transactionHandler.CreateClosureSweep)
+
+	// Charge a platform fee, e.g. a tier upgrade fee (called by identity service)
+	mux.HandleFunc("POST /internal/v1/transactions/fees", transactionHandler.CreateFeeCharge)
+
+	// Stuck transfers for simulation anomalies (called by simulation service; disabled in production)
+	mux.HandleFunc("POST /internal/v1/simulation/stuck-transfers", transactionHandler.CreateStuckTransfer)
+	mux.HandleFunc("DELETE /internal/v1/simulation/stuck-transfers/{id}", transactionHandler.DeleteStuckTransfer)
+
+	// Check an amount against its amount policy (called by wallet service for UPI deposits)
+	mux.HandleFunc("POST /internal/v1/amount-policies/check", amountPolicyHandler.CheckAmount)
+
 	// Apply middleware chain
 	metricsCollector := metrics.NewCollector("transaction")
 	handler := metricsCollector.Middleware("transaction")(mux)
 
+	// Keep the client origin forwarded by the gateway for risk evaluation
+	handler = middleware.ClientContext()(handler)
+
 	// Apply request ID
 	handler = middleware.RequestID()(handler)
 
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+)
+
+// AmountPolicyRepositoryInterface defines amount policy storage.
+type AmountPolicyRepositoryInterface interface {
+	List(ctx context.Context) ([]*models.AmountPolicy, *errors.Error)
+	Get(ctx context.Context, operation models.AmountOperation, currency sharedModels.Currency) (*models.AmountPolicy, *errors.Error)
+	Upsert(ctx context.Context, p *models.AmountPolicy) *errors.Error
+	Delete(ctx context.Context, id string) *errors.Error
+}
+
+// SetAmountPolicies enables minimum and maximum amounts per operation and
+// currency, checked when transactions are created.
+func (s *TransactionService) SetAmountPolicies(repo AmountPolicyRepositoryInterface) {
+	s.amountPolicyRepo = repo
+}
+
+// ListAmountPolicies returns every amount policy.
+func (s *TransactionService) ListAmountPolicies(ctx context.Context) ([]*models.AmountPolicy, *errors.Error) {
+	if s.amountPolicyRepo == nil {
+		return nil, errors.Unavailable("amount policies are not enabled")
+	}
+	return s.amountPolicyRepo.List(ctx)
+}
+
+// SetAmountPolicy creates or replaces the policy of an operation and currency.
+func (s *TransactionService) SetAmountPolicy(ctx context.Context, req *models.AmountPolicyRequest) (*models.AmountPolicy, *errors.Error) {
+	if s.amountPolicyRepo == nil {
+		return nil, errors.Unavailable("amount policies are not enabled")
+	}
+	if !req.Currency.IsSupported() {
+		return nil, errors.Validation(fmt.Sprintf("unsupported currency %s", req.Currency))
+	}
+	if req.MaxAmount != nil {
+		if *req.MaxAmount <= 0 {
+			return nil, errors.Validation("max_amount must be positive")
+		}
+		if *req.MaxAmount < req.MinAmount {
+			return nil, errors.Validation("max_amount must not be below min_amount")
+		}
+	}
+
+	policy := &models.AmountPolicy{
+		Operation: req.Operation,
+		Currency:  req.Currency,
+		MinAmount: req.MinAmount,
+		MaxAmount: req.MaxAmount,
+	}
+	if userID, ok := middleware.GetUserID(ctx); ok && userID != "" {
+		policy.UpdatedBy = &userID
+	}
+	if err := s.amountPolicyRepo.Upsert(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("operation", policy.Operation).
+		WithField("currency", policy.Currency).
+		WithField("min_amount", policy.MinAmount).
+		WithField("max_amount", policy.MaxAmount).
+		Info("Amount policy saved")
+
+	return policy, nil
+}
+
+// DeleteAmountPolicy removes an amount policy, leaving its operation and
+// currency unbounded.
+func (s *TransactionService) DeleteAmountPolicy(ctx context.Context, id string) *errors.Error {
+	if s.amountPolicyRepo == nil {
+		return errors.Unavailable("amount policies are not enabled")
+	}
+	return s.amountPolicyRepo.Delete(ctx, id)
+}
+
+// CheckAmount checks an amount against the policy of its operation and
+// currency. Operations without a policy are not bounded.
+func (s *TransactionService) CheckAmount(ctx context.Context, operation models.AmountOperation, currency sharedModels.Currency, amount int64) *errors.Error {
+	if s.amountPolicyRepo == nil {
+		return nil
+	}
+
+	policy, err := s.amountPolicyRepo.Get(ctx, operation, currency)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+
+	switch {
+	case amount < policy.MinAmount:
+		return errors.New(errors.ErrCodeAmountBelowMinimum, fmt.Sprintf("minimum %s amount is %s",
+			operationLabel(operation), money.New(policy.MinAmount, currency))).
+			AddDetail("operation", operation).
+			AddDetail("currency", currency).
+			AddDetail("minimum", policy.MinAmount)
+	case policy.MaxAmount != nil && amount > *policy.MaxAmount:
+		return errors.New(errors.ErrCodeAmountAboveMaximum, fmt.Sprintf("maximum %s amount is %s",
+			operationLabel(operation), money.New(*policy.MaxAmount, currency))).
+			AddDetail("operation", operation).
+			AddDetail("currency", currency).
+			AddDetail("maximum", *policy.MaxAmount)
+	}
+
+	return nil
+}
+
+// operationLabel names an operation in error messages.
+func operationLabel(operation models.AmountOperation) string {
+	if operation == models.AmountOperationUPIDeposit {
+		return "UPI deposit"
+	}
+	return string(operation)
+}
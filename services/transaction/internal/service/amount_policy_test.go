@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+// =====================================================================
+// Mock Amount Policy Repository
+// =====================================================================
+
+type mockAmountPolicyRepository struct {
+	policies map[string]*models.AmountPolicy
+}
+
+func newMockAmountPolicyRepository(policies ...*models.AmountPolicy) *mockAmountPolicyRepository {
+	m := &mockAmountPolicyRepository{policies: make(map[string]*models.AmountPolicy)}
+	for _, p := range policies {
+		_ = m.Upsert(context.Background(), p)
+	}
+	return m
+}
+
+func amountPolicyKey(operation models.AmountOperation, currency sharedModels.Currency) string {
+	return string(operation) + ":" + string(currency)
+}
+
+func (m *mockAmountPolicyRepository) List(ctx context.Context) ([]*models.AmountPolicy, *errors.Error) {
+	policies := make([]*models.AmountPolicy, 0, len(m.policies))
+	for _, p := range m.policies {
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func (m *mockAmountPolicyRepository) Get(ctx context.Context, operation models.AmountOperation, currency sharedModels.Currency) (*models.AmountPolicy, *errors.Error) {
+	p, ok := m.policies[amountPolicyKey(operation, currency)]
+	if !ok {
+		return nil, errors.NotFound("amount policy not found")
+	}
+	return p, nil
+}
+
+func (m *mockAmountPolicyRepository) Upsert(ctx context.Context, p *models.AmountPolicy) *errors.Error {
+	if existing, ok := m.policies[amountPolicyKey(p.Operation, p.Currency)]; ok {
+		p.ID = existing.ID
+	} else {
+		p.ID = uuid.New().String()
+	}
+	m.policies[amountPolicyKey(p.Operation, p.Currency)] = p
+	return nil
+}
+
+func (m *mockAmountPolicyRepository) Delete(ctx context.Context, id string) *errors.Error {
+	for key, p := range m.policies {
+		if p.ID == id {
+			delete(m.policies, key)
+			return nil
+		}
+	}
+	return errors.NotFoundWithID("amount policy", id)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// =====================================================================
+// Tests
+// =====================================================================
+
+func TestCheckAmount(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: map[string]*models.Transaction{}}, nil, nil, nil, nil)
+	svc.SetAmountPolicies(newMockAmountPolicyRepository(
+		&models.AmountPolicy{Operation: models.AmountOperationWithdrawal, Currency: sharedModels.INR, MinAmount: 10000},
+		&models.AmountPolicy{Operation: models.AmountOperationUPIDeposit, Currency: sharedModels.INR, MinAmount: 100, MaxAmount: int64Ptr(10000000)},
+	))
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		operation models.AmountOperation
+		currency  sharedModels.Currency
+		amount    int64
+		wantCode  errors.ErrorCode
+	}{
+		{"withdrawal below minimum", models.AmountOperationWithdrawal, sharedModels.INR, 9999, errors.ErrCodeAmountBelowMinimum},
+		{"withdrawal at minimum", models.AmountOperationWithdrawal, sharedModels.INR, 10000, ""},
+		{"withdrawal without maximum", models.AmountOperationWithdrawal, sharedModels.INR, 1 << 40, ""},
+		{"upi deposit above maximum", models.AmountOperationUPIDeposit, sharedModels.INR, 10000001, errors.ErrCodeAmountAboveMaximum},
+		{"other currency is not bounded", models.AmountOperationWithdrawal, sharedModels.USD, 1, ""},
+		{"operation without policy", models.AmountOperationTransfer, sharedModels.INR, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.CheckAmount(ctx, tt.operation, tt.currency, tt.amount)
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case tt.wantCode != "" && (err == nil || err.Code != tt.wantCode):
+				t.Errorf("expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+
+	err := svc.CheckAmount(ctx, models.AmountOperationWithdrawal, sharedModels.INR, 5000)
+	if err.Message != "minimum withdrawal amount is ₹100.00" || err.Details["minimum"] != int64(10000) {
+		t.Errorf("unexpected error message or details: %q %v", err.Message, err.Details)
+	}
+}
+
+func TestSetAmountPolicy_Validation(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: map[string]*models.Transaction{}}, nil, nil, nil, nil)
+	svc.SetAmountPolicies(newMockAmountPolicyRepository())
+	ctx := context.Background()
+
+	if _, err := svc.SetAmountPolicy(ctx, &models.AmountPolicyRequest{
+		Operation: models.AmountOperationTransfer, Currency: sharedModels.INR, MinAmount: 500, MaxAmount: int64Ptr(100),
+	}); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected a validation error for max below min, got %v", err)
+	}
+	if _, err := svc.SetAmountPolicy(ctx, &models.AmountPolicyRequest{
+		Operation: models.AmountOperationTransfer, Currency: "XYZ", MinAmount: 100,
+	}); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected a validation error for an unsupported currency, got %v", err)
+	}
+
+	policy, err := svc.SetAmountPolicy(ctx, &models.AmountPolicyRequest{
+		Operation: models.AmountOperationTransfer, Currency: sharedModels.INR, MinAmount: 100,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.CreateTransfer(ctx, newTransferRequest(50)); err == nil || err.Code != errors.ErrCodeAmountBelowMinimum {
+		t.Errorf("expected the transfer to be rejected below the minimum, got %v", err)
+	}
+
+	if err := svc.DeleteAmountPolicy(ctx, policy.ID); err != nil {
+		t.Fatalf("unexpected error deleting policy: %v", err)
+	}
+	if err := svc.CheckAmount(ctx, models.AmountOperationTransfer, sharedModels.INR, 50); err != nil {
+		t.Errorf("expected no bound after deleting the policy, got %v", err)
+	}
+}
@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Auto top-up defaults.
+const (
+	// DefaultAutoTopUpCooldown is the least time between two top-ups of one
+	// wallet, so a balance that keeps dropping is not debited on every change.
+	DefaultAutoTopUpCooldown = 15 * time.Minute
+	// DefaultAutoTopUpHistoryLimit is the number of executions listed by default.
+	DefaultAutoTopUpHistoryLimit = 50
+	// MaxFundingSourcesPerUser caps the bank accounts one user can link.
+	MaxFundingSourcesPerUser = 5
+
+	// fundingCurrency is the currency of the simulated bank accounts.
+	fundingCurrency = "INR"
+	// declinedAccountSuffix marks simulated accounts whose debits are declined,
+	// so failed top-ups can be exercised end to end.
+	declinedAccountSuffix = "0000"
+)
+
+// FundingRepositoryInterface defines the interface for funding source,
+// mandate and auto top-up storage.
+type FundingRepositoryInterface interface {
+	CreateSource(ctx context.Context, source *models.FundingSource) *errors.Error
+	GetSource(ctx context.Context, userID, id string) (*models.FundingSource, *errors.Error)
+	ListSources(ctx context.Context, userID string) ([]*models.FundingSource, *errors.Error)
+	RemoveSource(ctx context.Context, userID, id string) *errors.Error
+	CreateMandate(ctx context.Context, mandate *models.Mandate) *errors.Error
+	GetMandate(ctx context.Context, id string) (*models.Mandate, *errors.Error)
+	ListMandates(ctx context.Context, userID string) ([]*models.Mandate, *errors.Error)
+	CancelMandate(ctx context.Context, userID, id string) *errors.Error
+	MandateUsage(ctx context.Context, mandateID string, since time.Time) (int64, *errors.Error)
+	UpsertRule(ctx context.Context, rule *models.AutoTopUpRule) *errors.Error
+	GetRule(ctx context.Context, walletID string) (*models.AutoTopUpRule, *errors.Error)
+	DeleteRule(ctx context.Context, walletID string) *errors.Error
+	ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error)
+	Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error)
+	RecordExecution(ctx context.Context, exec *models.AutoTopUpExecution) *errors.Error
+	ListExecutions(ctx context.Context, walletID string, limit int) ([]*models.AutoTopUpExecution, *errors.Error)
+}
+
+// SetAutoTopUp enables funding sources, mandates and auto top-up rules.
+// Cooldown is the least time between two top-ups of one wallet; zero uses
+// DefaultAutoTopUpCooldown.
+func (s *TransactionService) SetAutoTopUp(repo FundingRepositoryInterface, cooldown time.Duration) {
+	if cooldown <= 0 {
+		cooldown = DefaultAutoTopUpCooldown
+	}
+	s.fundingRepo = repo
+	s.topUpCooldown = cooldown
+	s.topUpQueue = newSweepQueue(sweepQueueSize)
+}
+
+// ========================================================================
+// Funding Sources
+// ========================================================================
+
+// LinkFundingSource links a simulated bank account to the user. Only the
+// last four digits of the account number are kept.
+func (s *TransactionService) LinkFundingSource(ctx context.Context, userID string, req *models.LinkFundingSourceRequest) (*models.FundingSource, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+
+	existing, err := s.fundingRepo.ListSources(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxFundingSourcesPerUser {
+		return nil, errors.Validation(fmt.Sprintf("at most %d bank accounts can be linked", MaxFundingSourcesPerUser))
+	}
+
+	source := &models.FundingSource{
+		UserID:            userID,
+		BankName:          strings.TrimSpace(req.BankName),
+		AccountHolderName: strings.TrimSpace(req.AccountHolderName),
+		AccountLast4:      req.AccountNumber[len(req.AccountNumber)-4:],
+		IFSC:              strings.ToUpper(req.IFSC),
+		Status:            models.FundingSourceActive,
+	}
+	if err := s.fundingRepo.CreateSource(ctx, source); err != nil {
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"funding_source_id": source.ID,
+		"user_id":           userID,
+	}).Info("Funding source linked")
+	return source, nil
+}
+
+// ListFundingSources returns the user's linked bank accounts.
+func (s *TransactionService) ListFundingSources(ctx context.Context, userID string) ([]*models.FundingSource, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+	return s.fundingRepo.ListSources(ctx, userID)
+}
+
+// RemoveFundingSource unlinks a bank account and cancels its mandates, which
+// stops the auto top-ups drawing on it.
+func (s *TransactionService) RemoveFundingSource(ctx context.Context, userID, id string) *errors.Error {
+	if s.fundingRepo == nil {
+		return errors.Unavailable("auto top-up is not enabled")
+	}
+	return s.fundingRepo.RemoveSource(ctx, userID, id)
+}
+
+// ========================================================================
+// Mandates
+// ========================================================================
+
+// CreateMandate authorizes debits from one of the user's funding sources into
+// a wallet. The caller must already be allowed to move money in the wallet.
+func (s *TransactionService) CreateMandate(ctx context.Context, userID string, req *models.CreateMandateRequest) (*models.Mandate, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+
+	switch {
+	case req.MonthlyLimit < req.MaxAmount:
+		return nil, errors.Validation("monthly_limit must not be below max_amount")
+	case req.ValidUntil != nil && !req.ValidUntil.After(time.Now()):
+		return nil, errors.Validation("valid_until must be in the future")
+	}
+
+	source, err := s.fundingRepo.GetSource(ctx, userID, req.FundingSourceID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Status != models.FundingSourceActive {
+		return nil, errors.Validation("funding source has been removed")
+	}
+
+	// The linked accounts are Indian bank accounts
+	if s.walletClient != nil {
+		wallet, infoErr := s.walletClient.GetWalletInfo(ctx, req.WalletID)
+		if infoErr != nil {
+			return nil, infoErr
+		}
+		if wallet.Currency != fundingCurrency {
+			return nil, errors.Validation("mandates can only fund " + fundingCurrency + " wallets")
+		}
+	}
+
+	mandate := &models.Mandate{
+		UserID:          userID,
+		FundingSourceID: source.ID,
+		WalletID:        req.WalletID,
+		MaxAmount:       req.MaxAmount,
+		MonthlyLimit:    req.MonthlyLimit,
+		Status:          models.MandateActive,
+	}
+	if req.ValidUntil != nil {
+		validUntil := sharedModels.NewTimestamp(*req.ValidUntil)
+		mandate.ValidUntil = &validUntil
+	}
+	if err := s.fundingRepo.CreateMandate(ctx, mandate); err != nil {
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"mandate_id": mandate.ID,
+		"wallet_id":  mandate.WalletID,
+	}).Info("Mandate created")
+	return mandate, nil
+}
+
+// ListMandates returns the user's mandates, cancelled ones included.
+func (s *TransactionService) ListMandates(ctx context.Context, userID string) ([]*models.Mandate, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+	return s.fundingRepo.ListMandates(ctx, userID)
+}
+
+// CancelMandate revokes a mandate. Auto top-ups using it stop at once; their
+// rules stay in place until changed to another mandate or deleted.
+func (s *TransactionService) CancelMandate(ctx context.Context, userID, id string) *errors.Error {
+	if s.fundingRepo == nil {
+		return errors.Unavailable("auto top-up is not enabled")
+	}
+	if err := s.fundingRepo.CancelMandate(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.logger.WithField("mandate_id", id).Info("Mandate cancelled")
+	return nil
+}
+
+// ========================================================================
+// Rule Management
+// ========================================================================
+
+// SetAutoTopUpRule creates or replaces a wallet's auto top-up rule. The
+// mandate must be the user's, usable, and for this wallet.
+func (s *TransactionService) SetAutoTopUpRule(ctx context.Context, walletID, userID string, req *models.AutoTopUpRequest) (*models.AutoTopUpRule, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+
+	mandate, err := s.fundingRepo.GetMandate(ctx, req.MandateID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case mandate.UserID != userID:
+		return nil, errors.NotFoundWithID("mandate", req.MandateID)
+	case mandate.WalletID != walletID:
+		return nil, errors.Validation("mandate does not fund this wallet")
+	case !mandate.Usable(time.Now()):
+		return nil, errors.Validation("mandate is cancelled or expired")
+	case req.Amount > mandate.MaxAmount:
+		return nil, errors.Validation("amount must not exceed the mandate's max_amount")
+	}
+
+	rule := &models.AutoTopUpRule{
+		WalletID:  walletID,
+		MandateID: mandate.ID,
+		Threshold: req.Threshold,
+		Amount:    req.Amount,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedBy: userID,
+	}
+	if err := s.fundingRepo.UpsertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	// The balance may already be below the new threshold
+	s.queueAutoTopUp(walletID)
+	return rule, nil
+}
+
+// GetAutoTopUpRule returns a wallet's auto top-up rule.
+func (s *TransactionService) GetAutoTopUpRule(ctx context.Context, walletID string) (*models.AutoTopUpRule, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+	return s.fundingRepo.GetRule(ctx, walletID)
+}
+
+// DeleteAutoTopUpRule removes a wallet's auto top-up rule with its history.
+func (s *TransactionService) DeleteAutoTopUpRule(ctx context.Context, walletID string) *errors.Error {
+	if s.fundingRepo == nil {
+		return errors.Unavailable("auto top-up is not enabled")
+	}
+	return s.fundingRepo.DeleteRule(ctx, walletID)
+}
+
+// ListAutoTopUpExecutions returns a wallet's recent auto top-ups, newest first.
+func (s *TransactionService) ListAutoTopUpExecutions(ctx context.Context, walletID string, limit int) ([]*models.AutoTopUpExecution, *errors.Error) {
+	if s.fundingRepo == nil {
+		return nil, errors.Unavailable("auto top-up is not enabled")
+	}
+	if limit <= 0 {
+		limit = DefaultAutoTopUpHistoryLimit
+	}
+	return s.fundingRepo.ListExecutions(ctx, walletID, limit)
+}
+
+// ========================================================================
+// Evaluation
+// ========================================================================
+
+// queueAutoTopUp asks the auto top-up worker to evaluate a wallet.
+func (s *TransactionService) queueAutoTopUp(walletID string) {
+	if s.topUpQueue != nil && walletID != "" {
+		s.topUpQueue.push(walletID)
+	}
+}
+
+// queueAutoTopUpAfter queues the wallet a completed transaction debited.
+func (s *TransactionService) queueAutoTopUpAfter(transaction *models.Transaction) {
+	if transaction.SourceWalletID != nil {
+		s.queueAutoTopUp(*transaction.SourceWalletID)
+	}
+}
+
+// RunAutoTopUpWorker evaluates each debited wallet's auto top-up rule until
+// ctx is cancelled.
+func (s *TransactionService) RunAutoTopUpWorker(ctx context.Context) {
+	if s.topUpQueue == nil {
+		return
+	}
+	for {
+		select {
+		case walletID := <-s.topUpQueue.ch:
+			s.topUpQueue.done(walletID)
+			if _, err := s.EvaluateAutoTopUp(ctx, walletID); err != nil {
+				s.logger.WithError(err).WithField("wallet_id", walletID).Warn("Auto top-up evaluation failed")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// TopUpAllWallets evaluates every wallet with an enabled rule and returns how
+// many top-ups it attempted. It catches debits made outside this service.
+func (s *TransactionService) TopUpAllWallets(ctx context.Context) (int, *errors.Error) {
+	if s.fundingRepo == nil {
+		return 0, nil
+	}
+
+	walletIDs, err := s.fundingRepo.ListWalletsWithEnabledRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	attempted := 0
+	for _, walletID := range walletIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		ran, evalErr := s.EvaluateAutoTopUp(ctx, walletID)
+		if evalErr != nil {
+			s.logger.WithError(evalErr).WithField("wallet_id", walletID).Warn("Auto top-up evaluation failed")
+			continue
+		}
+		if ran {
+			attempted++
+		}
+	}
+	return attempted, nil
+}
+
+// EvaluateAutoTopUp tops a wallet up when its available balance is below its
+// rule's threshold, and reports whether it attempted a top-up. The deposit is
+// the rule's amount, capped by the mandate's per-debit maximum and what is
+// left of its monthly limit; a cancelled or expired mandate debits nothing.
+func (s *TransactionService) EvaluateAutoTopUp(ctx context.Context, walletID string) (bool, *errors.Error) {
+	if s.fundingRepo == nil || s.walletClient == nil {
+		return false, nil
+	}
+
+	rule, err := s.fundingRepo.GetRule(ctx, walletID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	if !rule.Enabled {
+		return false, nil
+	}
+
+	wallet, err := s.walletClient.GetWalletInfo(ctx, walletID)
+	if err != nil {
+		return false, err
+	}
+	if wallet.Status != "active" || wallet.AvailableBalance >= rule.Threshold {
+		return false, nil
+	}
+
+	now := time.Now()
+	mandate, err := s.fundingRepo.GetMandate(ctx, rule.MandateID)
+	if err != nil {
+		return false, err
+	}
+	if !mandate.Usable(now) {
+		return false, nil
+	}
+
+	used, err := s.fundingRepo.MandateUsage(ctx, mandate.ID, monthStart(now))
+	if err != nil {
+		return false, err
+	}
+	amount := min(rule.Amount, mandate.MaxAmount, mandate.MonthlyLimit-used)
+	if amount <= 0 {
+		s.logger.WithField("mandate_id", mandate.ID).Debug("Mandate monthly limit reached, auto top-up skipped")
+		return false, nil
+	}
+
+	source, err := s.fundingRepo.GetSource(ctx, mandate.UserID, mandate.FundingSourceID)
+	if err != nil {
+		return false, err
+	}
+	if source.Status != models.FundingSourceActive {
+		return false, nil
+	}
+
+	claimed, err := s.fundingRepo.Claim(ctx, rule.ID, now, now.Add(-s.topUpCooldown))
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	s.executeAutoTopUp(ctx, rule, source, wallet.Currency, wallet.AvailableBalance, amount)
+	return true, nil
+}
+
+// monthStart returns the start of the calendar month (UTC) containing t.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// executeAutoTopUp deposits amount into the rule's wallet from the funding
+// source, and records the outcome. Returns whether the deposit completed.
+func (s *TransactionService) executeAutoTopUp(ctx context.Context, rule *models.AutoTopUpRule, source *models.FundingSource, currency string, balance, amount int64) bool {
+	exec := &models.AutoTopUpExecution{
+		RuleID:        rule.ID,
+		WalletID:      rule.WalletID,
+		MandateID:     rule.MandateID,
+		BalanceBefore: balance,
+		Amount:        amount,
+		Status:        models.SweepExecutionCompleted,
+	}
+
+	metadata, _ := json.Marshal(map[string]string{
+		"payment_method":           "mandate",
+		models.MetaAutoTopUpRuleID: rule.ID,
+		"mandate_id":               rule.MandateID,
+		"funding_source_id":        source.ID,
+	})
+	transaction, err := s.CreateDeposit(ctx, &models.CreateDepositRequest{
+		WalletID:    rule.WalletID,
+		Amount:      amount,
+		Currency:    sharedModels.Currency(currency),
+		Description: fmt.Sprintf("Auto top-up from %s %s", source.BankName, source.MaskedAccount()),
+		MetadataRaw: metadata,
+	})
+	if err != nil {
+		reason := err.Message
+		exec.Status, exec.FailureReason = models.SweepExecutionFailed, &reason
+	} else {
+		exec.TransactionID = &transaction.ID
+		if reason, failed := s.settleMandateDebit(ctx, transaction, source); failed {
+			exec.Status, exec.FailureReason = models.SweepExecutionFailed, &reason
+		}
+	}
+
+	if recordErr := s.fundingRepo.RecordExecution(ctx, exec); recordErr != nil {
+		s.logger.WithError(recordErr).WithField("rule_id", rule.ID).Error("Failed to record auto top-up execution")
+	}
+
+	s.logger.With(map[string]interface{}{
+		"rule_id":   rule.ID,
+		"wallet_id": rule.WalletID,
+		"amount":    amount,
+		"status":    string(exec.Status),
+	}).Info("Auto top-up executed")
+	return exec.Status == models.SweepExecutionCompleted
+}
+
+// settleMandateDebit debits the simulated bank account for a pending
+// deposit, then completes the deposit and credits the wallet, or fails it.
+// Returns the failure reason and whether the deposit failed.
+func (s *TransactionService) settleMandateDebit(ctx context.Context, transaction *models.Transaction, source *models.FundingSource) (string, bool) {
+	if strings.HasSuffix(source.AccountLast4, declinedAccountSuffix) {
+		reason := "debit declined by bank"
+		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &reason); updateErr != nil {
+			s.logger.WithError(updateErr).Error("Failed to update failed transaction status")
+		}
+		s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorBank), reason)
+
+		s.publishTransactionEvent(events.TransactionFailed{
+			TransactionID:       transaction.ID,
+			Type:                string(transaction.Type),
+			Status:              string(models.TransactionStatusFailed),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			DestinationWalletID: transaction.DestinationWalletID,
+			FailureReason:       reason,
+		}, transaction.DestinationWalletID)
+		return reason, true
+	}
+
+	bankReference := fmt.Sprintf("MND%d", time.Now().UnixNano())
+	metadata := make(map[string]string, len(transaction.Metadata)+1)
+	for k, v := range transaction.Metadata {
+		metadata[k] = v
+	}
+	metadata["bank_reference"] = bankReference
+
+	if completeErr := s.transactionRepo.CompleteWithMetadata(ctx, transaction.ID, metadata); completeErr != nil {
+		return completeErr.Message, true
+	}
+	transaction.Status = models.TransactionStatusCompleted
+	transaction.Metadata = metadata
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCompleted, transaction.Status, models.ServiceActor(actorBank),
+		map[string]string{"bank_reference": bankReference})
+
+	// The bank has been debited, so the deposit stands even if the credit
+	// fails; that needs reconciling by hand, as for UPI deposits
+	creditErr := s.walletClient.CreditDeposit(ctx, &DepositRequest{
+		WalletID:      *transaction.DestinationWalletID,
+		Amount:        transaction.Amount,
+		TransactionID: transaction.ID,
+		Description:   transaction.Description,
+	})
+	if creditErr != nil {
+		s.logger.WithError(creditErr).WithField("transaction_id", transaction.ID).Error("Failed to credit auto top-up to wallet - reconciliation needed")
+	} else {
+		s.recordTimeline(ctx, transaction.ID, models.TimelineFundsMoved, transaction.Status, models.ServiceActor(actorWallet),
+			map[string]string{"credited_wallet_id": *transaction.DestinationWalletID})
+	}
+
+	s.publishTransactionEvent(events.TransactionCompleted{
+		TransactionID:       transaction.ID,
+		Type:                string(transaction.Type),
+		Status:              string(transaction.Status),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		DestinationWalletID: transaction.DestinationWalletID,
+	}, transaction.DestinationWalletID)
+	return "", false
+}
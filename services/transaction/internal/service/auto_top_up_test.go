@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockFundingRepository struct {
+	sources    map[string]*models.FundingSource
+	mandates   map[string]*models.Mandate
+	rules      map[string]*models.AutoTopUpRule // By wallet
+	executions []*models.AutoTopUpExecution
+}
+
+func newMockFundingRepository() *mockFundingRepository {
+	return &mockFundingRepository{
+		sources:  make(map[string]*models.FundingSource),
+		mandates: make(map[string]*models.Mandate),
+		rules:    make(map[string]*models.AutoTopUpRule),
+	}
+}
+
+func (m *mockFundingRepository) CreateSource(ctx context.Context, source *models.FundingSource) *errors.Error {
+	source.ID = uuid.New().String()
+	source.CreatedAt = sharedModels.Now()
+	m.sources[source.ID] = source
+	return nil
+}
+
+func (m *mockFundingRepository) GetSource(ctx context.Context, userID, id string) (*models.FundingSource, *errors.Error) {
+	if source, ok := m.sources[id]; ok && source.UserID == userID {
+		return source, nil
+	}
+	return nil, errors.NotFoundWithID("funding source", id)
+}
+
+func (m *mockFundingRepository) ListSources(ctx context.Context, userID string) ([]*models.FundingSource, *errors.Error) {
+	var sources []*models.FundingSource
+	for _, source := range m.sources {
+		if source.UserID == userID && source.Status == models.FundingSourceActive {
+			sources = append(sources, source)
+		}
+	}
+	return sources, nil
+}
+
+func (m *mockFundingRepository) RemoveSource(ctx context.Context, userID, id string) *errors.Error {
+	source, ok := m.sources[id]
+	if !ok || source.UserID != userID || source.Status != models.FundingSourceActive {
+		return errors.NotFoundWithID("funding source", id)
+	}
+	source.Status = models.FundingSourceRemoved
+	for _, mandate := range m.mandates {
+		if mandate.FundingSourceID == id {
+			mandate.Status = models.MandateCancelled
+		}
+	}
+	return nil
+}
+
+func (m *mockFundingRepository) CreateMandate(ctx context.Context, mandate *models.Mandate) *errors.Error {
+	mandate.ID = uuid.New().String()
+	mandate.CreatedAt = sharedModels.Now()
+	m.mandates[mandate.ID] = mandate
+	return nil
+}
+
+func (m *mockFundingRepository) GetMandate(ctx context.Context, id string) (*models.Mandate, *errors.Error) {
+	if mandate, ok := m.mandates[id]; ok {
+		return mandate, nil
+	}
+	return nil, errors.NotFoundWithID("mandate", id)
+}
+
+func (m *mockFundingRepository) ListMandates(ctx context.Context, userID string) ([]*models.Mandate, *errors.Error) {
+	var mandates []*models.Mandate
+	for _, mandate := range m.mandates {
+		if mandate.UserID == userID {
+			mandates = append(mandates, mandate)
+		}
+	}
+	return mandates, nil
+}
+
+func (m *mockFundingRepository) CancelMandate(ctx context.Context, userID, id string) *errors.Error {
+	mandate, ok := m.mandates[id]
+	if !ok || mandate.UserID != userID || mandate.Status != models.MandateActive {
+		return errors.NotFoundWithID("active mandate", id)
+	}
+	mandate.Status = models.MandateCancelled
+	return nil
+}
+
+func (m *mockFundingRepository) MandateUsage(ctx context.Context, mandateID string, since time.Time) (int64, *errors.Error) {
+	var used int64
+	for _, exec := range m.executions {
+		if exec.MandateID == mandateID && exec.Status == models.SweepExecutionCompleted && !exec.CreatedAt.Time.Before(since) {
+			used += exec.Amount
+		}
+	}
+	return used, nil
+}
+
+func (m *mockFundingRepository) UpsertRule(ctx context.Context, rule *models.AutoTopUpRule) *errors.Error {
+	if existing, ok := m.rules[rule.WalletID]; ok {
+		rule.ID, rule.CreatedAt, rule.LastExecutedAt = existing.ID, existing.CreatedAt, existing.LastExecutedAt
+	} else {
+		rule.ID = uuid.New().String()
+		rule.CreatedAt = sharedModels.Now()
+	}
+	m.rules[rule.WalletID] = rule
+	return nil
+}
+
+func (m *mockFundingRepository) GetRule(ctx context.Context, walletID string) (*models.AutoTopUpRule, *errors.Error) {
+	if rule, ok := m.rules[walletID]; ok {
+		return rule, nil
+	}
+	return nil, errors.NotFound("auto top-up rule")
+}
+
+func (m *mockFundingRepository) DeleteRule(ctx context.Context, walletID string) *errors.Error {
+	delete(m.rules, walletID)
+	return nil
+}
+
+func (m *mockFundingRepository) ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error) {
+	var walletIDs []string
+	for walletID, rule := range m.rules {
+		if rule.Enabled {
+			walletIDs = append(walletIDs, walletID)
+		}
+	}
+	return walletIDs, nil
+}
+
+func (m *mockFundingRepository) Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error) {
+	for _, rule := range m.rules {
+		if rule.ID == id {
+			if rule.LastExecutedAt != nil && rule.LastExecutedAt.After(sharedModels.NewTimestamp(notBefore)) {
+				return false, nil
+			}
+			at := sharedModels.NewTimestamp(now)
+			rule.LastExecutedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockFundingRepository) RecordExecution(ctx context.Context, exec *models.AutoTopUpExecution) *errors.Error {
+	exec.ID = uuid.New().String()
+	exec.CreatedAt = sharedModels.Now()
+	m.executions = append(m.executions, exec)
+	return nil
+}
+
+func (m *mockFundingRepository) ListExecutions(ctx context.Context, walletID string, limit int) ([]*models.AutoTopUpExecution, *errors.Error) {
+	return m.executions, nil
+}
+
+const topUpUser = "user-1"
+
+// setupAutoTopUp returns a service with a linked account ending in last4 and
+// a mandate of 5000 per debit and 12000 a month into wallet.
+func setupAutoTopUp(t *testing.T, wallet, last4 string, balance int64) (*TransactionService, *mockFundingRepository, *mockTransactionRepository, *fakeWallets, *models.Mandate) {
+	t.Helper()
+
+	wallets := &fakeWallets{balances: map[string]int64{wallet: balance}}
+	server := httptest.NewServer(wallets)
+	t.Cleanup(server.Close)
+
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	fundingRepo := newMockFundingRepository()
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), nil, nil)
+	svc.SetAutoTopUp(fundingRepo, time.Minute)
+
+	ctx := context.Background()
+	source, err := svc.LinkFundingSource(ctx, topUpUser, &models.LinkFundingSourceRequest{
+		BankName:          "HDFC Bank",
+		AccountHolderName: "Asha Rao",
+		AccountNumber:     "50100123" + last4,
+		IFSC:              "hdfc0001234",
+	})
+	if err != nil {
+		t.Fatalf("failed to link funding source: %v", err)
+	}
+	mandate, err := svc.CreateMandate(ctx, topUpUser, &models.CreateMandateRequest{
+		FundingSourceID: source.ID,
+		WalletID:        wallet,
+		MaxAmount:       5000,
+		MonthlyLimit:    12000,
+	})
+	if err != nil {
+		t.Fatalf("failed to create mandate: %v", err)
+	}
+
+	return svc, fundingRepo, txRepo, wallets, mandate
+}
+
+func TestLinkFundingSource_KeepsLastFourDigits(t *testing.T) {
+	wallet := uuid.New().String()
+	_, fundingRepo, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+
+	source := fundingRepo.sources[mandate.FundingSourceID]
+	if source.AccountLast4 != "4321" || source.MaskedAccount() != "••4321" {
+		t.Errorf("expected only the last four digits, got %q", source.AccountLast4)
+	}
+	if source.IFSC != "HDFC0001234" {
+		t.Errorf("expected the IFSC upper-cased, got %q", source.IFSC)
+	}
+}
+
+func TestEvaluateAutoTopUp_DepositsBelowThreshold(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, fundingRepo, txRepo, wallets, mandate := setupAutoTopUp(t, wallet, "4321", 500)
+	ctx := context.Background()
+
+	if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+		MandateID: mandate.ID, Threshold: 1000, Amount: 4000,
+	}); err != nil {
+		t.Fatalf("failed to set rule: %v", err)
+	}
+
+	ran, err := svc.EvaluateAutoTopUp(ctx, wallet)
+	if err != nil || !ran {
+		t.Fatalf("expected a top-up, got ran=%v err=%v", ran, err)
+	}
+	if wallets.balances[wallet] != 4500 {
+		t.Errorf("expected balance 4500, got %d", wallets.balances[wallet])
+	}
+
+	if len(fundingRepo.executions) != 1 {
+		t.Fatalf("expected 1 execution, got %d", len(fundingRepo.executions))
+	}
+	exec := fundingRepo.executions[0]
+	if exec.Status != models.SweepExecutionCompleted || exec.BalanceBefore != 500 || exec.Amount != 4000 {
+		t.Errorf("unexpected execution: %+v", exec)
+	}
+	transaction := txRepo.transactions[*exec.TransactionID]
+	if transaction.Type != models.TransactionTypeDeposit || transaction.Status != models.TransactionStatusCompleted {
+		t.Errorf("expected a completed deposit, got %s %s", transaction.Type, transaction.Status)
+	}
+	if transaction.Metadata[models.MetaAutoTopUpRuleID] == "" || transaction.Metadata["bank_reference"] == "" {
+		t.Errorf("expected rule and bank reference in metadata, got %v", transaction.Metadata)
+	}
+
+	// Already topped up, and within the cooldown
+	wallets.balances[wallet] = 0
+	if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran {
+		t.Error("expected no second top-up within the cooldown")
+	}
+}
+
+func TestEvaluateAutoTopUp_SkipsAboveThresholdAndDisabled(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, fundingRepo, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 1000)
+	ctx := context.Background()
+
+	if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+		MandateID: mandate.ID, Threshold: 1000, Amount: 4000,
+	}); err != nil {
+		t.Fatalf("failed to set rule: %v", err)
+	}
+	if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran {
+		t.Error("expected no top-up at the threshold")
+	}
+
+	disabled := false
+	if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+		MandateID: mandate.ID, Threshold: 5000, Amount: 4000, Enabled: &disabled,
+	}); err != nil {
+		t.Fatalf("failed to update rule: %v", err)
+	}
+	if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran {
+		t.Error("expected no top-up by a disabled rule")
+	}
+	if len(fundingRepo.executions) != 0 {
+		t.Errorf("expected no executions, got %d", len(fundingRepo.executions))
+	}
+}
+
+func TestEvaluateAutoTopUp_MonthlyLimit(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, fundingRepo, _, wallets, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+	ctx := context.Background()
+
+	if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+		MandateID: mandate.ID, Threshold: 1000, Amount: 5000,
+	}); err != nil {
+		t.Fatalf("failed to set rule: %v", err)
+	}
+
+	// 5000 + 5000 + the 2000 left of the 12000 monthly limit
+	for i, want := range []int64{5000, 5000, 2000} {
+		wallets.balances[wallet] = 0
+		fundingRepo.rules[wallet].LastExecutedAt = nil
+		if ran, err := svc.EvaluateAutoTopUp(ctx, wallet); err != nil || !ran {
+			t.Fatalf("top-up %d: expected to run, got ran=%v err=%v", i, ran, err)
+		}
+		if got := fundingRepo.executions[i].Amount; got != want {
+			t.Errorf("top-up %d: expected %d, got %d", i, want, got)
+		}
+	}
+
+	wallets.balances[wallet] = 0
+	fundingRepo.rules[wallet].LastExecutedAt = nil
+	if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran {
+		t.Error("expected no top-up once the monthly limit is used up")
+	}
+}
+
+func TestEvaluateAutoTopUp_StopsWhenMandateEnds(t *testing.T) {
+	wallet := uuid.New().String()
+	ctx := context.Background()
+
+	t.Run("cancelled", func(t *testing.T) {
+		svc, fundingRepo, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+		if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+			MandateID: mandate.ID, Threshold: 1000, Amount: 1000,
+		}); err != nil {
+			t.Fatalf("failed to set rule: %v", err)
+		}
+		if err := svc.CancelMandate(ctx, topUpUser, mandate.ID); err != nil {
+			t.Fatalf("failed to cancel mandate: %v", err)
+		}
+		if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran || len(fundingRepo.executions) != 0 {
+			t.Error("expected no top-up under a cancelled mandate")
+		}
+		if err := svc.CancelMandate(ctx, topUpUser, mandate.ID); err == nil {
+			t.Error("expected cancelling twice to fail")
+		}
+	})
+
+	t.Run("funding source removed", func(t *testing.T) {
+		svc, fundingRepo, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+		if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+			MandateID: mandate.ID, Threshold: 1000, Amount: 1000,
+		}); err != nil {
+			t.Fatalf("failed to set rule: %v", err)
+		}
+		if err := svc.RemoveFundingSource(ctx, topUpUser, mandate.FundingSourceID); err != nil {
+			t.Fatalf("failed to remove funding source: %v", err)
+		}
+		if mandate.Status != models.MandateCancelled {
+			t.Error("expected removing the source to cancel its mandate")
+		}
+		if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran || len(fundingRepo.executions) != 0 {
+			t.Error("expected no top-up from a removed funding source")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		svc, fundingRepo, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+		if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+			MandateID: mandate.ID, Threshold: 1000, Amount: 1000,
+		}); err != nil {
+			t.Fatalf("failed to set rule: %v", err)
+		}
+		expired := sharedModels.NewTimestamp(time.Now().Add(-time.Minute))
+		mandate.ValidUntil = &expired
+		if ran, _ := svc.EvaluateAutoTopUp(ctx, wallet); ran || len(fundingRepo.executions) != 0 {
+			t.Error("expected no top-up under an expired mandate")
+		}
+	})
+}
+
+func TestEvaluateAutoTopUp_DeclinedDebitFailsDeposit(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, fundingRepo, txRepo, wallets, mandate := setupAutoTopUp(t, wallet, "0000", 0)
+	ctx := context.Background()
+
+	if _, err := svc.SetAutoTopUpRule(ctx, wallet, topUpUser, &models.AutoTopUpRequest{
+		MandateID: mandate.ID, Threshold: 1000, Amount: 1000,
+	}); err != nil {
+		t.Fatalf("failed to set rule: %v", err)
+	}
+
+	if ran, err := svc.EvaluateAutoTopUp(ctx, wallet); err != nil || !ran {
+		t.Fatalf("expected a top-up attempt, got ran=%v err=%v", ran, err)
+	}
+	if wallets.balances[wallet] != 0 {
+		t.Errorf("expected the balance unchanged, got %d", wallets.balances[wallet])
+	}
+	exec := fundingRepo.executions[0]
+	if exec.Status != models.SweepExecutionFailed || exec.FailureReason == nil {
+		t.Errorf("expected a failed execution, got %+v", exec)
+	}
+	if status := txRepo.transactions[*exec.TransactionID].Status; status != models.TransactionStatusFailed {
+		t.Errorf("expected the deposit failed, got %s", status)
+	}
+
+	// Failed debits do not count toward the monthly limit
+	used, _ := fundingRepo.MandateUsage(ctx, mandate.ID, monthStart(time.Now()))
+	if used != 0 {
+		t.Errorf("expected no mandate usage, got %d", used)
+	}
+}
+
+func TestSetAutoTopUpRule_Validation(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, _, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		walletID string
+		userID   string
+		req      models.AutoTopUpRequest
+		code     errors.ErrorCode
+	}{
+		{"another user's mandate", wallet, "user-2", models.AutoTopUpRequest{MandateID: mandate.ID, Amount: 1000}, errors.ErrCodeNotFound},
+		{"mandate for another wallet", uuid.New().String(), topUpUser, models.AutoTopUpRequest{MandateID: mandate.ID, Amount: 1000}, errors.ErrCodeValidation},
+		{"amount above mandate maximum", wallet, topUpUser, models.AutoTopUpRequest{MandateID: mandate.ID, Amount: 5001}, errors.ErrCodeValidation},
+		{"unknown mandate", wallet, topUpUser, models.AutoTopUpRequest{MandateID: uuid.New().String(), Amount: 1000}, errors.ErrCodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.SetAutoTopUpRule(ctx, tt.walletID, tt.userID, &tt.req)
+			if err == nil || err.Code != tt.code {
+				t.Errorf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestCreateMandate_Validation(t *testing.T) {
+	wallet := uuid.New().String()
+	svc, _, _, _, mandate := setupAutoTopUp(t, wallet, "4321", 0)
+	ctx := context.Background()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  models.CreateMandateRequest
+	}{
+		{"monthly limit below maximum", models.CreateMandateRequest{FundingSourceID: mandate.FundingSourceID, WalletID: wallet, MaxAmount: 5000, MonthlyLimit: 4000}},
+		{"already expired", models.CreateMandateRequest{FundingSourceID: mandate.FundingSourceID, WalletID: wallet, MaxAmount: 5000, MonthlyLimit: 5000, ValidUntil: &past}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateMandate(ctx, topUpUser, &tt.req); err == nil || err.Code != errors.ErrCodeValidation {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := svc.CreateMandate(ctx, "user-2", &models.CreateMandateRequest{
+		FundingSourceID: mandate.FundingSourceID, WalletID: wallet, MaxAmount: 5000, MonthlyLimit: 5000,
+	}); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected another user's funding source to be not found, got %v", err)
+	}
+}
+
+func TestAutoTopUp_NotEnabled(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: make(map[string]*models.Transaction)}, nil, nil, nil, nil)
+
+	if _, err := svc.ListFundingSources(context.Background(), topUpUser); err == nil || err.Code != errors.ErrCodeUnavailable {
+		t.Errorf("expected unavailable, got %v", err)
+	}
+	if ran, err := svc.EvaluateAutoTopUp(context.Background(), uuid.New().String()); ran || err != nil {
+		t.Errorf("expected evaluation to be a no-op, got ran=%v err=%v", ran, err)
+	}
+}
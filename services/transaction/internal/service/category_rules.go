@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/google/uuid"
+)
+
+// Re-categorization defaults.
+const (
+	DefaultRecategorizeBatch = 500
+	maxRecategorizeJobs      = 20 // Finished jobs kept for status queries
+)
+
+// CategoryRuleRepositoryInterface defines category pattern management and
+// bulk category updates.
+type CategoryRuleRepositoryInterface interface {
+	GetPattern(ctx context.Context, id string) (*models.CategoryPattern, *errors.Error)
+	CreatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error
+	UpdatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error
+	DeletePattern(ctx context.Context, id string) *errors.Error
+	ListForRecategorization(ctx context.Context, filter *models.RecategorizeFilter) ([]*models.Transaction, *errors.Error)
+	BulkUpdateCategories(ctx context.Context, updates []models.CategoryUpdate) (int, *errors.Error)
+}
+
+// SetCategoryRules enables category pattern management and bulk
+// re-categorization.
+func (s *TransactionService) SetCategoryRules(repo CategoryRuleRepositoryInterface) {
+	s.categoryRepo = repo
+	s.recategorize = &recategorizeJobs{jobs: make(map[string]*models.RecategorizeJob)}
+}
+
+// ========================================================================
+// Rule Matching
+// ========================================================================
+
+// categoryRule is a compiled category pattern.
+type categoryRule struct {
+	keyword  string         // Lowercased, for keyword patterns
+	regex    *regexp.Regexp // For regex patterns
+	category models.SpendingCategory
+}
+
+// categoryRules matches descriptions against patterns in priority order.
+type categoryRules []categoryRule
+
+// compileCategoryRules compiles patterns, which must already be in priority
+// order. Regex patterns that no longer compile are skipped.
+func compileCategoryRules(patterns []*models.CategoryPattern, log *logger.Logger) categoryRules {
+	rules := make(categoryRules, 0, len(patterns))
+	for _, p := range patterns {
+		rule := categoryRule{category: p.Category}
+		if p.MatchType == models.MatchTypeRegex {
+			re, err := compilePatternRegex(p.Pattern)
+			if err != nil {
+				log.WithError(err).WithField("pattern_id", p.ID).Warn("Skipping invalid category regex")
+				continue
+			}
+			rule.regex = re
+		} else {
+			rule.keyword = strings.ToLower(p.Pattern)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// compilePatternRegex compiles a regex pattern case-insensitively.
+func compilePatternRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// categorize returns the category of the first matching rule, or other.
+func (rules categoryRules) categorize(description string) models.SpendingCategory {
+	descLower := strings.ToLower(description)
+	for _, rule := range rules {
+		if rule.regex != nil {
+			if rule.regex.MatchString(description) {
+				return rule.category
+			}
+		} else if strings.Contains(descLower, rule.keyword) {
+			return rule.category
+		}
+	}
+	return models.CategoryOther
+}
+
+// ========================================================================
+// Pattern Management
+// ========================================================================
+
+// ListCategoryPatterns returns all category patterns in priority order.
+func (s *TransactionService) ListCategoryPatterns(ctx context.Context) ([]*models.CategoryPattern, *errors.Error) {
+	return s.transactionRepo.GetCategoryPatterns(ctx)
+}
+
+// GetCategoryPattern returns a category pattern.
+func (s *TransactionService) GetCategoryPattern(ctx context.Context, id string) (*models.CategoryPattern, *errors.Error) {
+	if s.categoryRepo == nil {
+		return nil, errors.Unavailable("category rules are not configured")
+	}
+	return s.categoryRepo.GetPattern(ctx, id)
+}
+
+// CreateCategoryPattern validates and creates a category pattern.
+func (s *TransactionService) CreateCategoryPattern(ctx context.Context, req *models.CategoryPatternRequest) (*models.CategoryPattern, *errors.Error) {
+	if s.categoryRepo == nil {
+		return nil, errors.Unavailable("category rules are not configured")
+	}
+
+	pattern, err := buildCategoryPattern(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.CreatePattern(ctx, pattern); err != nil {
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"pattern_id": pattern.ID,
+		"pattern":    pattern.Pattern,
+		"category":   pattern.Category,
+	}).Info("Category pattern created")
+	return pattern, nil
+}
+
+// UpdateCategoryPattern validates and replaces a category pattern.
+func (s *TransactionService) UpdateCategoryPattern(ctx context.Context, id string, req *models.CategoryPatternRequest) (*models.CategoryPattern, *errors.Error) {
+	if s.categoryRepo == nil {
+		return nil, errors.Unavailable("category rules are not configured")
+	}
+
+	pattern, err := buildCategoryPattern(req)
+	if err != nil {
+		return nil, err
+	}
+	pattern.ID = id
+	if err := s.categoryRepo.UpdatePattern(ctx, pattern); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("pattern_id", id).Info("Category pattern updated")
+	return pattern, nil
+}
+
+// DeleteCategoryPattern deletes a category pattern.
+func (s *TransactionService) DeleteCategoryPattern(ctx context.Context, id string) *errors.Error {
+	if s.categoryRepo == nil {
+		return errors.Unavailable("category rules are not configured")
+	}
+	if err := s.categoryRepo.DeletePattern(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.WithField("pattern_id", id).Info("Category pattern deleted")
+	return nil
+}
+
+// buildCategoryPattern validates a pattern request.
+func buildCategoryPattern(req *models.CategoryPatternRequest) (*models.CategoryPattern, *errors.Error) {
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern == "" {
+		return nil, errors.Validation("pattern is required")
+	}
+
+	category := models.SpendingCategory(req.Category)
+	if !models.ValidCategories[category] {
+		return nil, errors.Validation("invalid spending category")
+	}
+
+	matchType := req.MatchType
+	switch matchType {
+	case "":
+		matchType = models.MatchTypeKeyword
+	case models.MatchTypeKeyword:
+	case models.MatchTypeRegex:
+		if _, err := compilePatternRegex(pattern); err != nil {
+			return nil, errors.Validation("invalid regular expression: " + err.Error())
+		}
+	default:
+		return nil, errors.Validation("match_type must be keyword or regex")
+	}
+
+	return &models.CategoryPattern{
+		Pattern:   pattern,
+		MatchType: matchType,
+		Category:  category,
+		Priority:  req.Priority,
+	}, nil
+}
+
+// ========================================================================
+// Bulk Re-categorization
+// ========================================================================
+
+// recategorizeJobs tracks re-categorization jobs. Only one runs at a time.
+type recategorizeJobs struct {
+	mu      sync.Mutex
+	jobs    map[string]*models.RecategorizeJob
+	order   []string
+	running string
+}
+
+// StartRecategorization starts a background job that re-applies the current
+// rules to historical transactions. Transactions the user categorized by hand
+// are left alone.
+func (s *TransactionService) StartRecategorization(ctx context.Context, req *models.RecategorizeRequest) (*models.RecategorizeJob, *errors.Error) {
+	if s.categoryRepo == nil {
+		return nil, errors.Unavailable("category rules are not configured")
+	}
+	filter, err := recategorizeFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := s.recategorize
+	jobs.mu.Lock()
+	if jobs.running != "" {
+		jobs.mu.Unlock()
+		return nil, errors.Conflict("a re-categorization job is already running: " + jobs.running)
+	}
+	job := &models.RecategorizeJob{
+		ID:         uuid.New().String(),
+		Status:     models.RecategorizeRunning,
+		Request:    *req,
+		ByCategory: make(map[models.SpendingCategory]int),
+		StartedAt:  time.Now().UTC(),
+	}
+	jobs.add(job)
+	jobs.running = job.ID
+	snapshot := jobs.snapshot(job)
+	jobs.mu.Unlock()
+
+	s.logger.WithField("job_id", job.ID).Info("Re-categorization started")
+
+	// The job outlives the request that started it
+	go s.runRecategorization(context.WithoutCancel(ctx), job, filter)
+
+	return snapshot, nil
+}
+
+// GetRecategorizationJob returns a re-categorization job's progress.
+func (s *TransactionService) GetRecategorizationJob(id string) (*models.RecategorizeJob, *errors.Error) {
+	if s.recategorize == nil {
+		return nil, errors.Unavailable("category rules are not configured")
+	}
+
+	jobs := s.recategorize
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+
+	job, ok := jobs.jobs[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("re-categorization job", id)
+	}
+	return jobs.snapshot(job), nil
+}
+
+// runRecategorization runs a job to completion and records its outcome.
+func (s *TransactionService) runRecategorization(ctx context.Context, job *models.RecategorizeJob, filter *models.RecategorizeFilter) {
+	jobs := s.recategorize
+	err := s.RecategorizeTransactions(ctx, filter, job.Request.DryRun, func(scanned int, changes []models.CategoryUpdate, applied int) {
+		jobs.mu.Lock()
+		defer jobs.mu.Unlock()
+		job.Scanned += scanned
+		job.Changed += applied
+		for _, c := range changes {
+			job.ByCategory[c.Category]++
+		}
+	})
+
+	jobs.mu.Lock()
+	now := time.Now().UTC()
+	job.CompletedAt = &now
+	job.Status = models.RecategorizeCompleted
+	if err != nil {
+		job.Status = models.RecategorizeFailed
+		job.Error = err.Error()
+	}
+	jobs.running = ""
+	jobs.mu.Unlock()
+
+	log := s.logger.With(map[string]interface{}{
+		"job_id":  job.ID,
+		"scanned": job.Scanned,
+		"changed": job.Changed,
+		"dry_run": job.Request.DryRun,
+	})
+	if err != nil {
+		log.WithError(err).Error("Re-categorization failed")
+		return
+	}
+	log.Info("Re-categorization completed")
+}
+
+// RecategorizeTransactions re-applies the current rules to every transaction
+// matching the filter, one batch at a time. progress is called after each
+// batch with the number scanned, the changes found and how many were written.
+func (s *TransactionService) RecategorizeTransactions(ctx context.Context, filter *models.RecategorizeFilter, dryRun bool, progress func(scanned int, changes []models.CategoryUpdate, applied int)) *errors.Error {
+	patterns, err := s.transactionRepo.GetCategoryPatterns(ctx)
+	if err != nil {
+		return err
+	}
+	rules := compileCategoryRules(patterns, s.logger)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultRecategorizeBatch
+	}
+
+	for {
+		batch, err := s.categoryRepo.ListForRecategorization(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		changes := make([]models.CategoryUpdate, 0)
+		for _, tx := range batch {
+			if category := rules.categorize(tx.Description); category != tx.Category {
+				changes = append(changes, models.CategoryUpdate{TransactionID: tx.ID, Category: category})
+			}
+		}
+
+		applied := len(changes)
+		if !dryRun {
+			if applied, err = s.categoryRepo.BulkUpdateCategories(ctx, changes); err != nil {
+				return err
+			}
+		}
+		if progress != nil {
+			progress(len(batch), changes, applied)
+		}
+
+		if len(batch) < filter.Limit {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		filter.AfterCreatedAt = &last.CreatedAt.Time
+		filter.AfterID = last.ID
+	}
+}
+
+// recategorizeFilter validates a re-categorization request.
+func recategorizeFilter(req *models.RecategorizeRequest) (*models.RecategorizeFilter, *errors.Error) {
+	filter := &models.RecategorizeFilter{WalletID: req.WalletID}
+
+	if req.StartDate != "" {
+		from, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return nil, errors.Validation("start_date must be YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if req.EndDate != "" {
+		end, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, errors.Validation("end_date must be YYYY-MM-DD")
+		}
+		to := end.AddDate(0, 0, 1) // End date is inclusive
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.Validation("start_date must not be after end_date")
+	}
+
+	return filter, nil
+}
+
+// add records a job, dropping the oldest finished jobs beyond the limit.
+// Callers must hold j.mu.
+func (j *recategorizeJobs) add(job *models.RecategorizeJob) {
+	j.jobs[job.ID] = job
+	j.order = append(j.order, job.ID)
+	for len(j.order) > maxRecategorizeJobs {
+		oldest := j.order[0]
+		if oldest == j.running {
+			break
+		}
+		delete(j.jobs, oldest)
+		j.order = j.order[1:]
+	}
+}
+
+// snapshot copies a job so it can be returned while the job keeps running.
+// Callers must hold j.mu.
+func (j *recategorizeJobs) snapshot(job *models.RecategorizeJob) *models.RecategorizeJob {
+	copied := *job
+	copied.ByCategory = make(map[models.SpendingCategory]int, len(job.ByCategory))
+	for category, n := range job.ByCategory {
+		copied.ByCategory[category] = n
+	}
+	return &copied
+}
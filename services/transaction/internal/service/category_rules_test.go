@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// mockCategoryRuleRepository keeps patterns and transactions in memory.
+type mockCategoryRuleRepository struct {
+	patterns     map[string]*models.CategoryPattern
+	transactions []*models.Transaction // Sorted by (created_at, id)
+	pages        int
+}
+
+func (m *mockCategoryRuleRepository) GetPattern(ctx context.Context, id string) (*models.CategoryPattern, *errors.Error) {
+	p, ok := m.patterns[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("category pattern", id)
+	}
+	return p, nil
+}
+
+func (m *mockCategoryRuleRepository) CreatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error {
+	p.ID = "pattern-new"
+	m.patterns[p.ID] = p
+	return nil
+}
+
+func (m *mockCategoryRuleRepository) UpdatePattern(ctx context.Context, p *models.CategoryPattern) *errors.Error {
+	if _, ok := m.patterns[p.ID]; !ok {
+		return errors.NotFoundWithID("category pattern", p.ID)
+	}
+	m.patterns[p.ID] = p
+	return nil
+}
+
+func (m *mockCategoryRuleRepository) DeletePattern(ctx context.Context, id string) *errors.Error {
+	if _, ok := m.patterns[id]; !ok {
+		return errors.NotFoundWithID("category pattern", id)
+	}
+	delete(m.patterns, id)
+	return nil
+}
+
+func (m *mockCategoryRuleRepository) ListForRecategorization(ctx context.Context, filter *models.RecategorizeFilter) ([]*models.Transaction, *errors.Error) {
+	m.pages++
+	result := make([]*models.Transaction, 0)
+	for _, tx := range m.transactions {
+		if tx.CategoryOverridden {
+			continue
+		}
+		if filter.AfterCreatedAt != nil {
+			after := tx.CreatedAt.Time.After(*filter.AfterCreatedAt) ||
+				(tx.CreatedAt.Time.Equal(*filter.AfterCreatedAt) && tx.ID > filter.AfterID)
+			if !after {
+				continue
+			}
+		}
+		copied := *tx
+		result = append(result, &copied)
+		if len(result) == filter.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCategoryRuleRepository) BulkUpdateCategories(ctx context.Context, updates []models.CategoryUpdate) (int, *errors.Error) {
+	for _, u := range updates {
+		for _, tx := range m.transactions {
+			if tx.ID == u.TransactionID {
+				tx.Category = u.Category
+			}
+		}
+	}
+	return len(updates), nil
+}
+
+var _ CategoryRuleRepositoryInterface = (*mockCategoryRuleRepository)(nil)
+
+func TestCategoryRules_Categorize(t *testing.T) {
+	rules := compileCategoryRules([]*models.CategoryPattern{
+		{ID: "1", Pattern: `^UPI/\d+/IRCTC`, MatchType: models.MatchTypeRegex, Category: models.CategoryTransport},
+		{ID: "2", Pattern: "Swiggy", MatchType: models.MatchTypeKeyword, Category: models.CategoryFood},
+		{ID: "3", Pattern: "([", MatchType: models.MatchTypeRegex, Category: models.CategoryHealth},
+		{ID: "4", Pattern: "upi", Category: models.CategoryTransfer}, // Seeded rows default to keyword
+	}, logger.NewDefault("test"))
+
+	tests := []struct {
+		description string
+		want        models.SpendingCategory
+	}{
+		{"upi/12345/irctc ticket", models.CategoryTransport},
+		{"Order at SWIGGY instamart", models.CategoryFood},
+		{"UPI to friend", models.CategoryTransfer},
+		{"Cash", models.CategoryOther},
+	}
+	for _, tt := range tests {
+		if got := rules.categorize(tt.description); got != tt.want {
+			t.Errorf("categorize(%q) = %s, want %s", tt.description, got, tt.want)
+		}
+	}
+
+	if len(rules) != 3 {
+		t.Errorf("expected the invalid regex to be skipped, got %d rules", len(rules))
+	}
+}
+
+func TestCreateCategoryPattern_Validation(t *testing.T) {
+	svc, _ := setupTestService()
+	svc.SetCategoryRules(&mockCategoryRuleRepository{patterns: make(map[string]*models.CategoryPattern)})
+	ctx := context.Background()
+
+	pattern, err := svc.CreateCategoryPattern(ctx, &models.CategoryPatternRequest{Pattern: "  netflix ", Category: "entertainment", Priority: 9})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pattern.Pattern != "netflix" || pattern.MatchType != models.MatchTypeKeyword {
+		t.Errorf("expected trimmed keyword pattern, got %+v", pattern)
+	}
+
+	invalid := []*models.CategoryPatternRequest{
+		{Pattern: " ", Category: "food"},
+		{Pattern: "netflix", Category: "streaming"},
+		{Pattern: "netflix", Category: "food", MatchType: "glob"},
+		{Pattern: "(unclosed", Category: "food", MatchType: models.MatchTypeRegex},
+	}
+	for _, req := range invalid {
+		if _, err := svc.CreateCategoryPattern(ctx, req); err == nil || err.Code != errors.ErrCodeValidation {
+			t.Errorf("expected validation error for %+v, got %v", req, err)
+		}
+	}
+}
+
+func TestCategoryPatterns_NotConfigured(t *testing.T) {
+	svc, _ := setupTestService()
+
+	_, err := svc.CreateCategoryPattern(context.Background(), &models.CategoryPatternRequest{Pattern: "x", Category: "food"})
+	if err == nil || err.Code != errors.ErrCodeUnavailable {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+}
+
+func TestUpdateTransactionCategory_MarksOverride(t *testing.T) {
+	svc, repo := setupTestService()
+	ctx := context.Background()
+	repo.transactions["tx-1"] = &models.Transaction{ID: "tx-1", Description: "Swiggy order", Category: models.CategoryFood}
+
+	tx, err := svc.UpdateTransactionCategory(ctx, "tx-1", models.CategoryHealth)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !tx.CategoryOverridden {
+		t.Error("expected a manual category to be marked as an override")
+	}
+
+	tx, err = svc.AutoCategorizeTransaction(ctx, "tx-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if tx.Category != models.CategoryFood || tx.CategoryOverridden {
+		t.Errorf("expected auto-categorize to restore the rule category, got %s (overridden=%v)", tx.Category, tx.CategoryOverridden)
+	}
+}
+
+func newRecategorizeFixture() *mockCategoryRuleRepository {
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) sharedModels.Timestamp {
+		return sharedModels.Timestamp{Time: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	return &mockCategoryRuleRepository{
+		patterns: make(map[string]*models.CategoryPattern),
+		transactions: []*models.Transaction{
+			{ID: "a", Description: "Swiggy dinner", Category: models.CategoryOther, CreatedAt: at(0)},
+			{ID: "b", Description: "Uber ride", Category: models.CategoryOther, CreatedAt: at(0)},
+			{ID: "c", Description: "Amazon order", Category: models.CategoryShopping, CreatedAt: at(1)},
+			{ID: "d", Description: "Uber to airport", Category: models.CategoryHealth, CategoryOverridden: true, CreatedAt: at(2)},
+			{ID: "e", Description: "Rent", Category: models.CategoryFood, CreatedAt: at(3)},
+		},
+	}
+}
+
+func TestRecategorizeTransactions(t *testing.T) {
+	svc, _ := setupTestService()
+	categoryRepo := newRecategorizeFixture()
+	svc.SetCategoryRules(categoryRepo)
+
+	var scanned, applied int
+	changedIDs := []string{}
+	err := svc.RecategorizeTransactions(context.Background(), &models.RecategorizeFilter{Limit: 2}, false,
+		func(n int, changes []models.CategoryUpdate, written int) {
+			scanned += n
+			applied += written
+			for _, c := range changes {
+				changedIDs = append(changedIDs, c.TransactionID)
+			}
+		})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	sort.Strings(changedIDs)
+	if scanned != 4 || applied != 3 {
+		t.Errorf("expected 4 scanned and 3 changed, got %d and %d", scanned, applied)
+	}
+	if len(changedIDs) != 3 || changedIDs[0] != "a" || changedIDs[1] != "b" || changedIDs[2] != "e" {
+		t.Errorf("expected a, b and e to change, got %v", changedIDs)
+	}
+	if categoryRepo.pages != 3 {
+		t.Errorf("expected 3 pages for 4 rows at limit 2 (the last one empty), got %d", categoryRepo.pages)
+	}
+
+	want := map[string]models.SpendingCategory{
+		"a": models.CategoryFood,
+		"b": models.CategoryTransport,
+		"c": models.CategoryShopping,
+		"d": models.CategoryHealth, // Overridden by the user, left alone
+		"e": models.CategoryOther,
+	}
+	for _, tx := range categoryRepo.transactions {
+		if tx.Category != want[tx.ID] {
+			t.Errorf("transaction %s: category = %s, want %s", tx.ID, tx.Category, want[tx.ID])
+		}
+	}
+}
+
+func TestRecategorizeTransactions_DryRun(t *testing.T) {
+	svc, _ := setupTestService()
+	categoryRepo := newRecategorizeFixture()
+	svc.SetCategoryRules(categoryRepo)
+
+	changed := 0
+	err := svc.RecategorizeTransactions(context.Background(), &models.RecategorizeFilter{}, true,
+		func(n int, changes []models.CategoryUpdate, written int) { changed += written })
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if changed != 3 {
+		t.Errorf("expected 3 changes counted, got %d", changed)
+	}
+	if categoryRepo.transactions[0].Category != models.CategoryOther {
+		t.Error("expected dry run not to write categories")
+	}
+}
+
+func TestStartRecategorization(t *testing.T) {
+	svc, _ := setupTestService()
+	svc.SetCategoryRules(newRecategorizeFixture())
+
+	if _, err := svc.StartRecategorization(context.Background(), &models.RecategorizeRequest{StartDate: "2026-03-05", EndDate: "2026-03-01"}); err == nil {
+		t.Error("expected start_date after end_date to be rejected")
+	}
+	if _, err := svc.StartRecategorization(context.Background(), &models.RecategorizeRequest{StartDate: "March"}); err == nil {
+		t.Error("expected malformed start_date to be rejected")
+	}
+
+	job, err := svc.StartRecategorization(context.Background(), &models.RecategorizeRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.Status != models.RecategorizeRunning {
+		t.Errorf("expected running job, got %s", job.Status)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, getErr := svc.GetRecategorizationJob(job.ID)
+		if getErr != nil {
+			t.Fatalf("expected job, got %v", getErr)
+		}
+		if got.Status == models.RecategorizeCompleted {
+			if got.Changed != 3 || got.ByCategory[models.CategoryFood] != 1 || got.CompletedAt == nil {
+				t.Errorf("unexpected job result: %+v", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not complete, last status %s", got.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := svc.GetRecategorizationJob("missing"); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+)
+
+// ClosureSweepMetadataKey links a sweep transaction to its wallet closure.
+const ClosureSweepMetadataKey = "wallet_closure_id"
+
+// CreateClosureSweep records the sweep of a closing wallet's balance as a
+// transfer to DestinationWalletID, or a withdrawal without one, and has the
+// wallet service move the money. Sweeps are requested by the wallet service
+// for a closure the owner scheduled, so they skip risk evaluation and
+// transfer limits.
+func (s *TransactionService) CreateClosureSweep(ctx context.Context, req *models.ClosureSweepRequest) (*models.Transaction, *errors.Error) {
+	if s.walletClient == nil {
+		return nil, errors.Internal("wallet client not configured")
+	}
+	if req.DestinationWalletID == req.WalletID {
+		return nil, errors.New(errors.ErrCodeTransferSameWallet, "cannot sweep a wallet into itself")
+	}
+
+	sourceWalletID := req.WalletID
+	transaction := &models.Transaction{
+		Type:           models.TransactionTypeWithdrawal,
+		Status:         models.TransactionStatusPending,
+		SourceWalletID: &sourceWalletID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Description:    "Wallet closure: remaining balance withdrawn",
+		Metadata:       map[string]string{ClosureSweepMetadataKey: req.ClosureID},
+	}
+	if req.DestinationWalletID != "" {
+		destinationWalletID := req.DestinationWalletID
+		transaction.Type = models.TransactionTypeTransfer
+		transaction.DestinationWalletID = &destinationWalletID
+		transaction.Description = "Wallet closure: remaining balance transferred"
+	}
+
+	if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
+		return nil, createErr
+	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, models.ServiceActor(actorWallet),
+		map[string]string{ClosureSweepMetadataKey: req.ClosureID})
+
+	s.publishTransactionEvent(events.TransactionCreated{
+		TransactionID:       transaction.ID,
+		Type:                string(transaction.Type),
+		Status:              string(transaction.Status),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		SourceWalletID:      transaction.SourceWalletID,
+		DestinationWalletID: transaction.DestinationWalletID,
+		Description:         transaction.Description,
+	}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+	sweepErr := s.walletClient.ExecuteClosureSweep(ctx, &ClosureSweepExecution{
+		ClosureID:           req.ClosureID,
+		WalletID:            req.WalletID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+		TransactionID:       transaction.ID,
+	})
+	if sweepErr != nil {
+		failureReason := sweepErr.Error()
+		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason); updateErr != nil {
+			s.logger.WithError(updateErr).Error("Failed to update failed transaction status")
+		}
+		s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorWallet), failureReason)
+
+		s.publishTransactionEvent(events.TransactionFailed{
+			TransactionID:       transaction.ID,
+			Type:                string(transaction.Type),
+			Status:              string(models.TransactionStatusFailed),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			SourceWalletID:      transaction.SourceWalletID,
+			DestinationWalletID: transaction.DestinationWalletID,
+			FailureReason:       sweepErr.Message,
+			ErrorCode:           string(sweepErr.Code),
+		}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+		return nil, sweepErr
+	}
+
+	s.recordTimeline(ctx, transaction.ID, models.TimelineFundsMoved, transaction.Status, models.ServiceActor(actorWallet), nil)
+
+	// Withdrawals have no ledger entry yet, like other withdrawals
+	if transaction.Type == models.TransactionTypeTransfer && s.ledgerClient != nil {
+		if ledgerErr := s.createTransferLedgerEntry(ctx, transaction); ledgerErr != nil {
+			s.logger.WithError(ledgerErr).WithField("transaction_id", transaction.ID).Error("Failed to create ledger entry - reconciliation needed")
+			s.recordTimeline(ctx, transaction.ID, models.TimelineLedgerPostFailed, transaction.Status, models.ServiceActor(actorLedger),
+				map[string]string{"reason": ledgerErr.Error()})
+		}
+	}
+
+	if completeErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusCompleted, nil); completeErr != nil {
+		s.logger.WithError(completeErr).Error("Failed to mark transaction as completed")
+		return nil, completeErr
+	}
+	transaction.Status = models.TransactionStatusCompleted
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCompleted, transaction.Status, models.ServiceActor(actorTransaction), nil)
+
+	s.publishTransactionEvent(events.TransactionCompleted{
+		TransactionID:       transaction.ID,
+		Type:                string(transaction.Type),
+		Status:              string(transaction.Status),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		SourceWalletID:      transaction.SourceWalletID,
+		DestinationWalletID: transaction.DestinationWalletID,
+	}, transaction.SourceWalletID, transaction.DestinationWalletID)
+	s.queueMerchantPayment(ctx, transaction)
+
+	s.logger.With(map[string]interface{}{
+		"transaction_id": transaction.ID,
+		"closure_id":     req.ClosureID,
+		"type":           transaction.Type,
+		"amount":         transaction.Amount,
+	}).Info("Wallet closure sweep completed")
+	return transaction, nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/cache"
+)
+
+// Counterparty enrichment defaults.
+const (
+	DefaultCounterpartyCacheTTL     = 5 * time.Minute
+	DefaultCounterpartyCacheEntries = 10000
+
+	// counterpartyLookupTimeout bounds enriching one listing, so a slow
+	// wallet or identity service delays it by at most this long.
+	counterpartyLookupTimeout = 2 * time.Second
+
+	// counterpartyWalletLookups bounds concurrent wallet lookups per listing.
+	counterpartyWalletLookups = 8
+
+	// maxDisplayNameLookup is how many users identity resolves per request.
+	maxDisplayNameLookup = 100
+
+	// ownWalletDisplayName is shown for the viewer's own wallets without a nickname.
+	ownWalletDisplayName = "Your wallet"
+)
+
+// counterparties resolves transfer counterparties, caching wallet owners and
+// user display names.
+type counterparties struct {
+	identity *IdentityClient
+	wallets  *cache.Loader // Trimmed wallet info by wallet ID
+	names    cache.Cache   // Display names by user ID, with entries for unknown users
+	ttl      time.Duration
+}
+
+// counterpartyWallet is the part of a wallet's info enrichment needs. The
+// balance is left out so it is never cached.
+type counterpartyWallet struct {
+	ID       string   `json:"id"`
+	UserID   string   `json:"user_id"`
+	Nickname string   `json:"nickname,omitempty"`
+	Members  []string `json:"members,omitempty"`
+}
+
+// owners returns the owner and co-owners of the wallet.
+func (w *counterpartyWallet) owners() []string {
+	ids := make([]string, 0, len(w.Members)+1)
+	if w.UserID != "" {
+		ids = append(ids, w.UserID)
+	}
+	return append(ids, w.Members...)
+}
+
+// sharesOwner reports whether any owner of w also owns other.
+func (w *counterpartyWallet) sharesOwner(other *counterpartyWallet) bool {
+	for _, a := range w.owners() {
+		for _, b := range other.owners() {
+			if a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetCounterpartyEnrichment resolves the other side of transfers in wallet
+// listings to a display name through identity. Wallets and names are cached
+// for ttl in a memory cache of up to maxEntries entries.
+func (s *TransactionService) SetCounterpartyEnrichment(identity *IdentityClient, ttl time.Duration, maxEntries int) {
+	if ttl <= 0 {
+		ttl = DefaultCounterpartyCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCounterpartyCacheEntries
+	}
+	s.counterparties = &counterparties{
+		identity: identity,
+		wallets:  cache.NewLoader(cache.NewMemoryCache(maxEntries)),
+		names:    cache.NewMemoryCache(maxEntries),
+		ttl:      ttl,
+	}
+}
+
+// EnrichCounterparties sets the Counterparty of each transfer in a listing of
+// walletID's transactions. Enrichment is best effort: a counterparty that
+// cannot be resolved is shown as a masked wallet ID, and a listing is never
+// failed over it.
+//
+// Privacy rules: another wallet of the viewing wallet's owners is shown by
+// its nickname; another user's wallet by the user's first name and last
+// initial and masked phone, never their ID, full name or wallet nickname.
+func (s *TransactionService) EnrichCounterparties(ctx context.Context, walletID string, transactions []*models.Transaction) {
+	if s.counterparties == nil || len(transactions) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, counterpartyLookupTimeout)
+	defer cancel()
+
+	walletIDs := []string{walletID}
+	seen := map[string]bool{walletID: true}
+	for _, tx := range transactions {
+		if id, _ := counterpartyOf(tx, walletID); id != "" && !seen[id] {
+			seen[id] = true
+			walletIDs = append(walletIDs, id)
+		}
+	}
+	if len(walletIDs) == 1 {
+		return
+	}
+
+	wallets := s.counterpartyWallets(ctx, walletIDs)
+	viewer := wallets[walletID]
+
+	var userIDs []string
+	for _, wallet := range wallets {
+		if wallet.ID != walletID && wallet.UserID != "" && (viewer == nil || !viewer.sharesOwner(wallet)) {
+			userIDs = append(userIDs, wallet.UserID)
+		}
+	}
+	names := s.counterpartyNames(ctx, userIDs)
+
+	for _, tx := range transactions {
+		id, direction := counterpartyOf(tx, walletID)
+		if id == "" {
+			continue
+		}
+		counterparty := &models.Counterparty{
+			WalletID:    id,
+			Direction:   direction,
+			Kind:        models.CounterpartyUnknown,
+			DisplayName: maskedWalletName(id),
+		}
+
+		wallet := wallets[id]
+		switch {
+		case wallet == nil:
+		case viewer != nil && viewer.sharesOwner(wallet):
+			counterparty.Kind = models.CounterpartyOwnWallet
+			counterparty.DisplayName = ownWalletDisplayName
+			if wallet.Nickname != "" {
+				counterparty.DisplayName = wallet.Nickname
+			}
+		default:
+			if name, ok := names[wallet.UserID]; ok {
+				counterparty.Kind = models.CounterpartyUser
+				counterparty.DisplayName = name.DisplayName
+				counterparty.MaskedIdentifier = name.MaskedPhone
+			}
+		}
+		tx.Counterparty = counterparty
+	}
+}
+
+// counterpartyOf returns the other wallet of a transfer listed for walletID
+// and which way the money went, or "" when the transaction has none.
+func counterpartyOf(tx *models.Transaction, walletID string) (string, string) {
+	if tx.SourceWalletID == nil || tx.DestinationWalletID == nil {
+		return "", ""
+	}
+	switch walletID {
+	case *tx.SourceWalletID:
+		return *tx.DestinationWalletID, models.CounterpartyDirectionTo
+	case *tx.DestinationWalletID:
+		return *tx.SourceWalletID, models.CounterpartyDirectionFrom
+	}
+	return "", ""
+}
+
+// maskedWalletName shows a wallet by the last four characters of its ID.
+func maskedWalletName(walletID string) string {
+	suffix := walletID
+	if len(suffix) > 4 {
+		suffix = suffix[len(suffix)-4:]
+	}
+	return "Wallet ••••" + suffix
+}
+
+// counterpartyWallets looks up wallets through the cache, concurrently.
+// Wallets that cannot be looked up are left out.
+func (s *TransactionService) counterpartyWallets(ctx context.Context, walletIDs []string) map[string]*counterpartyWallet {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		wallets = make(map[string]*counterpartyWallet, len(walletIDs))
+		slots   = make(chan struct{}, counterpartyWalletLookups)
+	)
+	for _, id := range walletIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(id string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			wallet, err := s.counterpartyWallet(ctx, id)
+			if err != nil {
+				s.logger.WithError(err).WithField("wallet_id", id).Warn("Counterparty wallet lookup failed")
+				return
+			}
+			mu.Lock()
+			wallets[id] = wallet
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+	return wallets
+}
+
+// counterpartyWallet returns a wallet's owners and nickname, from the cache
+// when it holds them.
+func (s *TransactionService) counterpartyWallet(ctx context.Context, walletID string) (*counterpartyWallet, error) {
+	resolver := s.counterparties
+	data, err := resolver.wallets.GetOrLoad(ctx, "counterparty:wallet:"+walletID, resolver.ttl, func(ctx context.Context) (string, error) {
+		info, infoErr := s.walletClient.GetWalletInfo(ctx, walletID)
+		if infoErr != nil {
+			return "", infoErr
+		}
+		wallet := counterpartyWallet{ID: info.ID, UserID: info.UserID, Nickname: info.Nickname}
+		for _, member := range info.Members {
+			wallet.Members = append(wallet.Members, member.UserID)
+		}
+		encoded, jsonErr := json.Marshal(wallet)
+		if jsonErr != nil {
+			return "", jsonErr
+		}
+		return string(encoded), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var wallet counterpartyWallet
+	if err := json.Unmarshal([]byte(data), &wallet); err != nil {
+		return nil, err
+	}
+	if wallet.ID == "" {
+		wallet.ID = walletID
+	}
+	return &wallet, nil
+}
+
+// counterpartyNames returns the display names of users, from the cache where
+// it holds them and in one identity request for the rest. Users identity does
+// not know, such as closed accounts, are cached as unknown and left out.
+func (s *TransactionService) counterpartyNames(ctx context.Context, userIDs []string) map[string]UserDisplayName {
+	resolver := s.counterparties
+	names := make(map[string]UserDisplayName, len(userIDs))
+
+	var missing []string
+	for _, id := range userIDs {
+		data, found, err := resolver.names.Get(ctx, "counterparty:user:"+id)
+		if err != nil || !found {
+			missing = append(missing, id)
+			continue
+		}
+		var name UserDisplayName
+		if json.Unmarshal([]byte(data), &name) == nil && name.DisplayName != "" {
+			names[id] = name
+		}
+	}
+	if len(missing) == 0 {
+		return names
+	}
+
+	for start := 0; start < len(missing); start += maxDisplayNameLookup {
+		batch := missing[start:min(start+maxDisplayNameLookup, len(missing))]
+		resolved, err := resolver.identity.GetDisplayNames(ctx, batch)
+		if err != nil {
+			s.logger.WithError(err).WithField("users", len(batch)).Warn("Counterparty display name lookup failed")
+			continue
+		}
+		resolver.cacheNames(ctx, batch, resolved, names)
+	}
+	return names
+}
+
+// cacheNames caches the display names identity resolved for a batch of users
+// and adds them to names.
+func (c *counterparties) cacheNames(ctx context.Context, batch []string, resolved []UserDisplayName, names map[string]UserDisplayName) {
+	found := make(map[string]UserDisplayName, len(resolved))
+	for _, name := range resolved {
+		found[name.UserID] = name
+	}
+	for _, id := range batch {
+		name, ok := found[id]
+		if ok {
+			names[id] = name
+		}
+		// Unknown users are cached too, so they are not asked for on every listing
+		encoded, _ := json.Marshal(name)
+		_ = c.names.Set(ctx, "counterparty:user:"+id, string(encoded), c.ttl)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+)
+
+const (
+	viewerWalletID  = "11111111-1111-1111-1111-11111111aaaa"
+	savingsWalletID = "22222222-2222-2222-2222-22222222bbbb"
+	friendWalletID  = "33333333-3333-3333-3333-33333333cccc"
+	closedWalletID  = "44444444-4444-4444-4444-44444444dddd"
+	missingWalletID = "55555555-5555-5555-5555-55555555eeee"
+)
+
+// setupCounterpartyService returns a service with counterparty enrichment
+// whose wallet and identity services answer from one test server, and
+// counters of the wallet and identity requests it served.
+func setupCounterpartyService(t *testing.T) (*TransactionService, *int32, *int32) {
+	t.Helper()
+
+	wallets := map[string]string{
+		viewerWalletID:  `{"id":"` + viewerWalletID + `","user_id":"viewer","nickname":"Main"}`,
+		savingsWalletID: `{"id":"` + savingsWalletID + `","user_id":"partner","nickname":"Holiday fund","members":[{"user_id":"viewer","permission":"admin"}]}`,
+		friendWalletID:  `{"id":"` + friendWalletID + `","user_id":"friend","nickname":"Secret stash"}`,
+		closedWalletID:  `{"id":"` + closedWalletID + `","user_id":"closed"}`,
+	}
+
+	var walletCalls, identityCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/internal/v1/users/display-names":
+			atomic.AddInt32(&identityCalls, 1)
+			var req displayNamesRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			var names []UserDisplayName
+			for _, id := range req.UserIDs {
+				if id == "friend" {
+					names = append(names, UserDisplayName{UserID: id, DisplayName: "Priya S.", MaskedPhone: "******3210"})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"success": true, "data": names})
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			atomic.AddInt32(&walletCalls, 1)
+			id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/internal/v1/wallets/"), "/info")
+			info, ok := wallets[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"success":false,"error":{"code":"NOT_FOUND","message":"wallet not found"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"success":true,"data":` + info + `}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), nil, nil)
+	svc.SetCounterpartyEnrichment(NewIdentityClient(server.URL), time.Minute, 100)
+	return svc, &walletCalls, &identityCalls
+}
+
+func transfer(source, destination string) *models.Transaction {
+	return &models.Transaction{
+		Type:                models.TransactionTypeTransfer,
+		SourceWalletID:      &source,
+		DestinationWalletID: &destination,
+	}
+}
+
+func TestEnrichCounterparties(t *testing.T) {
+	svc, _, _ := setupCounterpartyService(t)
+
+	deposit := viewerWalletID
+	txs := []*models.Transaction{
+		transfer(viewerWalletID, friendWalletID),
+		transfer(savingsWalletID, viewerWalletID),
+		transfer(viewerWalletID, closedWalletID),
+		transfer(missingWalletID, viewerWalletID),
+		{Type: models.TransactionTypeDeposit, DestinationWalletID: &deposit},
+	}
+	svc.EnrichCounterparties(context.Background(), viewerWalletID, txs)
+
+	tests := []struct {
+		name string
+		got  *models.Counterparty
+		want models.Counterparty
+	}{
+		{"other user", txs[0].Counterparty, models.Counterparty{
+			WalletID: friendWalletID, Direction: "to", Kind: models.CounterpartyUser, DisplayName: "Priya S.", MaskedIdentifier: "******3210",
+		}},
+		{"joint wallet of the viewer", txs[1].Counterparty, models.Counterparty{
+			WalletID: savingsWalletID, Direction: "from", Kind: models.CounterpartyOwnWallet, DisplayName: "Holiday fund",
+		}},
+		{"closed account", txs[2].Counterparty, models.Counterparty{
+			WalletID: closedWalletID, Direction: "to", Kind: models.CounterpartyUnknown, DisplayName: "Wallet ••••dddd",
+		}},
+		{"unknown wallet", txs[3].Counterparty, models.Counterparty{
+			WalletID: missingWalletID, Direction: "from", Kind: models.CounterpartyUnknown, DisplayName: "Wallet ••••eeee",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil {
+				t.Fatal("expected counterparty to be set")
+			}
+			if *tt.got != tt.want {
+				t.Errorf("counterparty = %+v, want %+v", *tt.got, tt.want)
+			}
+		})
+	}
+
+	if txs[4].Counterparty != nil {
+		t.Errorf("expected no counterparty on a deposit, got %+v", txs[4].Counterparty)
+	}
+}
+
+func TestEnrichCounterparties_NeverExposesOtherUsers(t *testing.T) {
+	svc, _, _ := setupCounterpartyService(t)
+
+	txs := []*models.Transaction{transfer(viewerWalletID, friendWalletID)}
+	svc.EnrichCounterparties(context.Background(), viewerWalletID, txs)
+
+	encoded, err := json.Marshal(txs[0].Counterparty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"friend", "Secret stash"} {
+		if strings.Contains(string(encoded), leaked) {
+			t.Errorf("counterparty %s exposes %q", encoded, leaked)
+		}
+	}
+}
+
+func TestEnrichCounterparties_CachesLookups(t *testing.T) {
+	svc, walletCalls, identityCalls := setupCounterpartyService(t)
+
+	for i := 0; i < 3; i++ {
+		txs := []*models.Transaction{
+			transfer(viewerWalletID, friendWalletID),
+			transfer(viewerWalletID, closedWalletID),
+		}
+		svc.EnrichCounterparties(context.Background(), viewerWalletID, txs)
+		if txs[0].Counterparty == nil || txs[0].Counterparty.DisplayName != "Priya S." {
+			t.Fatalf("listing %d: counterparty = %+v", i, txs[0].Counterparty)
+		}
+	}
+
+	if got := atomic.LoadInt32(walletCalls); got != 3 {
+		t.Errorf("wallet lookups = %d, want 3 (one per wallet)", got)
+	}
+	if got := atomic.LoadInt32(identityCalls); got != 1 {
+		t.Errorf("identity lookups = %d, want 1 (unknown users cached too)", got)
+	}
+}
+
+func TestListWalletTransactions_WithoutEnrichment(t *testing.T) {
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	txRepo.transactions["t1"] = transfer(viewerWalletID, friendWalletID)
+	svc := NewTransactionService(txRepo, nil, nil, nil, nil)
+
+	txs, err := svc.ListWalletTransactions(context.Background(), viewerWalletID, &models.TransactionFilter{})
+	if err != nil {
+		t.Fatalf("ListWalletTransactions() error = %v", err)
+	}
+	if len(txs) != 1 || txs[0].Counterparty != nil {
+		t.Errorf("expected the listing unenriched, got %+v", txs)
+	}
+}
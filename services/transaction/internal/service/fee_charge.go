@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+)
+
+// FeeChargeRepositoryInterface looks up fee transactions by reference.
+type FeeChargeRepositoryInterface interface {
+	GetActiveFeeID(ctx context.Context, reference string) (string, *errors.Error)
+}
+
+// SetFeeCharges enables fee charges requested by other services.
+func (s *TransactionService) SetFeeCharges(repo FeeChargeRepositoryInterface) {
+	s.feeCharges = repo
+}
+
+// CreateFeeCharge records a fee as a fee transaction from the user's wallet
+// to the fee wallet, has the wallet service move the money and posts the
+// ledger entry. A fee the wallet service rejects is failed; one whose outcome
+// is unknown stays pending. Charging a reference that already has a completed
+// fee returns that fee, and a pending one is retried under the same
+// transaction ID, which the wallet service treats as its idempotency key.
+// Fees are requested by the platform, so they skip risk evaluation and
+// transfer limits.
+func (s *TransactionService) CreateFeeCharge(ctx context.Context, req *models.FeeChargeRequest) (*models.Transaction, *errors.Error) {
+	if s.feeCharges == nil || s.walletClient == nil {
+		return nil, errors.Unavailable("fee charges are not configured")
+	}
+	if req.FeeWalletID == req.WalletID {
+		return nil, errors.New(errors.ErrCodeTransferSameWallet, "cannot charge a fee into the same wallet")
+	}
+
+	existingID, err := s.feeCharges.GetActiveFeeID(ctx, req.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	if existingID != "" {
+		transaction, err = s.transactionRepo.GetByID(ctx, existingID)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.Status == models.TransactionStatusCompleted {
+			return transaction, nil
+		}
+		if transaction.Amount != req.Amount || *transaction.SourceWalletID != req.WalletID {
+			return nil, errors.Conflict("reference already used for a different fee")
+		}
+	} else {
+		sourceWalletID, feeWalletID, reference := req.WalletID, req.FeeWalletID, req.Reference
+		transaction = &models.Transaction{
+			Type:                models.TransactionTypeFee,
+			Status:              models.TransactionStatusPending,
+			SourceWalletID:      &sourceWalletID,
+			DestinationWalletID: &feeWalletID,
+			Amount:              req.Amount,
+			Currency:            req.Currency,
+			Description:         req.Description,
+			Reference:           &reference,
+		}
+		if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
+			return nil, createErr
+		}
+		s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, models.ServiceActor(actorTransaction),
+			map[string]string{"reference": req.Reference})
+
+		s.publishTransactionEvent(events.TransactionCreated{
+			TransactionID:       transaction.ID,
+			Type:                string(transaction.Type),
+			Status:              string(transaction.Status),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			SourceWalletID:      transaction.SourceWalletID,
+			DestinationWalletID: transaction.DestinationWalletID,
+			Description:         transaction.Description,
+		}, transaction.SourceWalletID, transaction.DestinationWalletID)
+	}
+
+	transferErr := s.walletClient.ExecuteTransfer(ctx, &TransferRequest{
+		SourceWalletID:      req.WalletID,
+		DestinationWalletID: req.FeeWalletID,
+		Amount:              transaction.Amount,
+		TransactionID:       transaction.ID,
+		Description:         transaction.Description,
+	})
+	if transferErr != nil && transferErr.HTTPStatusCode() >= http.StatusInternalServerError {
+		// The wallet may have moved the money; leave the fee pending so a
+		// retry under the same reference settles it without charging twice
+		s.logger.WithError(transferErr).WithField("transaction_id", transaction.ID).Warn("Fee charge outcome unknown; left pending")
+		return nil, transferErr
+	}
+	if transferErr != nil {
+		failureReason := transferErr.Error()
+		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason); updateErr != nil {
+			s.logger.WithError(updateErr).Error("Failed to update failed transaction status")
+		}
+		s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorWallet), failureReason)
+
+		s.publishTransactionEvent(events.TransactionFailed{
+			TransactionID:       transaction.ID,
+			Type:                string(transaction.Type),
+			Status:              string(models.TransactionStatusFailed),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			SourceWalletID:      transaction.SourceWalletID,
+			DestinationWalletID: transaction.DestinationWalletID,
+			FailureReason:       transferErr.Message,
+			ErrorCode:           string(transferErr.Code),
+		}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+		return nil, transferErr
+	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineFundsMoved, transaction.Status, models.ServiceActor(actorWallet), nil)
+
+	if completeErr := s.completeTransfer(ctx, transaction, true); completeErr != nil {
+		return nil, completeErr
+	}
+	transaction.Status = models.TransactionStatusCompleted
+	return transaction, nil
+}
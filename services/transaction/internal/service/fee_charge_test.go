@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockFeeChargeRepository struct {
+	txRepo *mockTransactionRepository
+}
+
+func (m *mockFeeChargeRepository) GetActiveFeeID(ctx context.Context, reference string) (string, *errors.Error) {
+	for _, tx := range m.txRepo.transactions {
+		if tx.Type == models.TransactionTypeFee && tx.Reference != nil && *tx.Reference == reference && tx.Status != models.TransactionStatusFailed {
+			return tx.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// feeServer answers the wallet service's transfer and info endpoints and the
+// ledger's journal entry endpoints. status is returned for wallet transfers.
+type feeServer struct {
+	mu        sync.Mutex
+	status    int
+	transfers []TransferRequest
+	entries   int
+}
+
+func (s *feeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.URL.Path == "/internal/v1/wallets/transfer":
+		var req TransferRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.transfers = append(s.transfers, req)
+		if s.status != http.StatusOK {
+			w.WriteHeader(s.status)
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":"INSUFFICIENT_FUNDS","message":"insufficient balance"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{}}`))
+	case strings.HasSuffix(r.URL.Path, "/info"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/internal/v1/wallets/"), "/info")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"` + id + `","user_id":"u-` + id + `","ledger_account_id":"acct-` + id + `"}}`))
+	case r.URL.Path == "/api/v1/journal-entries":
+		s.entries++
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"je-1","status":"draft"}}`))
+	case r.URL.Path == "/api/v1/journal-entries/je-1/post":
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"je-1","status":"posted"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newFeeChargeTest(t *testing.T) (*TransactionService, *mockTransactionRepository, *feeServer) {
+	t.Helper()
+	fake := &feeServer{status: http.StatusOK}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), NewLedgerClient(server.URL), nil)
+	svc.SetFeeCharges(&mockFeeChargeRepository{txRepo: txRepo})
+	return svc, txRepo, fake
+}
+
+func newFeeChargeRequest() *models.FeeChargeRequest {
+	return &models.FeeChargeRequest{
+		WalletID:    "11111111-1111-1111-1111-111111111111",
+		FeeWalletID: "22222222-2222-2222-2222-222222222222",
+		Amount:      19900,
+		Currency:    sharedModels.INR,
+		Description: "Tier upgrade fee: premium",
+		Reference:   "tier_change:req-1",
+	}
+}
+
+func TestCreateFeeCharge(t *testing.T) {
+	svc, _, fake := newFeeChargeTest(t)
+	req := newFeeChargeRequest()
+
+	fee, err := svc.CreateFeeCharge(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateFeeCharge() error = %v", err)
+	}
+	if fee.Type != models.TransactionTypeFee || fee.Status != models.TransactionStatusCompleted {
+		t.Errorf("fee = %s %s, want a completed fee transaction", fee.Type, fee.Status)
+	}
+	if len(fake.transfers) != 1 || fake.transfers[0].TransactionID != fee.ID || fake.transfers[0].DestinationWalletID != req.FeeWalletID {
+		t.Errorf("wallet transfers = %+v, want one into the fee wallet keyed by the fee transaction", fake.transfers)
+	}
+	if fake.entries != 1 {
+		t.Errorf("posted %d ledger entries, want 1", fake.entries)
+	}
+
+	// Charging the same reference again returns the completed fee
+	again, err := svc.CreateFeeCharge(context.Background(), req)
+	if err != nil || again.ID != fee.ID {
+		t.Errorf("repeated charge = %v, %v, want fee %s", again, err, fee.ID)
+	}
+	if len(fake.transfers) != 1 || fake.entries != 1 {
+		t.Errorf("repeated charge moved money again: %d transfers, %d entries", len(fake.transfers), fake.entries)
+	}
+}
+
+func TestCreateFeeCharge_Rejected(t *testing.T) {
+	svc, txRepo, fake := newFeeChargeTest(t)
+	req := newFeeChargeRequest()
+
+	fake.status = http.StatusPreconditionFailed
+	if _, err := svc.CreateFeeCharge(context.Background(), req); err == nil {
+		t.Fatal("expected the wallet rejection to be returned")
+	}
+	for _, tx := range txRepo.transactions {
+		if tx.Status != models.TransactionStatusFailed {
+			t.Errorf("rejected fee status = %s, want failed", tx.Status)
+		}
+	}
+
+	// A failed fee can be charged again under the same reference
+	fake.status = http.StatusOK
+	fee, err := svc.CreateFeeCharge(context.Background(), req)
+	if err != nil || fee.Status != models.TransactionStatusCompleted {
+		t.Fatalf("retried charge = %v, %v, want a completed fee", fee, err)
+	}
+	if len(txRepo.transactions) != 2 {
+		t.Errorf("got %d fee transactions, want the failed one and its retry", len(txRepo.transactions))
+	}
+}
+
+func TestCreateFeeCharge_UnknownOutcomeRetriesSameTransaction(t *testing.T) {
+	svc, txRepo, fake := newFeeChargeTest(t)
+	req := newFeeChargeRequest()
+
+	fake.status = http.StatusServiceUnavailable
+	if _, err := svc.CreateFeeCharge(context.Background(), req); err == nil {
+		t.Fatal("expected the wallet error to be returned")
+	}
+
+	fake.status = http.StatusOK
+	fee, err := svc.CreateFeeCharge(context.Background(), req)
+	if err != nil {
+		t.Fatalf("retried charge error = %v", err)
+	}
+	if len(txRepo.transactions) != 1 {
+		t.Errorf("got %d fee transactions, want the pending one reused", len(txRepo.transactions))
+	}
+	if len(fake.transfers) != 2 || fake.transfers[0].TransactionID != fee.ID || fake.transfers[1].TransactionID != fee.ID {
+		t.Errorf("wallet transfers = %+v, want both attempts keyed by %s", fake.transfers, fee.ID)
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// IdentityClient handles communication with the Identity service.
+type IdentityClient struct {
+	*clients.BaseClient
+}
+
+// NewIdentityClient creates a new Identity service client.
+func NewIdentityClient(baseURL string) *IdentityClient {
+	return &IdentityClient{
+		BaseClient: clients.NewBaseClient(baseURL, clients.ShortTimeout),
+	}
+}
+
+// NewIdentityClientWithSecret creates an Identity client with internal service authentication.
+func NewIdentityClientWithSecret(baseURL, internalSecret string) *IdentityClient {
+	return &IdentityClient{
+		BaseClient: clients.NewInternalClient(baseURL, clients.ShortTimeout, internalSecret),
+	}
+}
+
+// UserDisplayName is how identity shows a user to other users.
+type UserDisplayName struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`           // First name and last initial, e.g. "Priya S."
+	MaskedPhone string `json:"masked_phone,omitempty"` // e.g. "******3210"
+}
+
+// displayNamesRequest asks identity for the display names of several users.
+type displayNamesRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// GetDisplayNames returns the display names of users (internal endpoint).
+// Closed accounts and unknown IDs are left out.
+func (c *IdentityClient) GetDisplayNames(ctx context.Context, userIDs []string) ([]UserDisplayName, *errors.Error) {
+	var result []UserDisplayName
+	if err := c.Post(ctx, "/internal/v1/users/display-names", &displayNamesRequest{UserIDs: userIDs}, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
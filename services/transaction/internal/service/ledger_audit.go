@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// LedgerAuditBatch is how many transactions are verified per ledger call.
+const LedgerAuditBatch = 200
+
+// AuditLedgerReferences verifies that every completed transfer created in [from, to)
+// has a matching posted journal entry in the ledger. Transfers are the only
+// transactions that post journal entries today. Discrepancies are logged and
+// published as transaction.ledger_discrepancy events for follow-up.
+func (s *TransactionService) AuditLedgerReferences(ctx context.Context, from, to time.Time) (*models.LedgerAuditReport, *errors.Error) {
+	if s.ledgerClient == nil {
+		return nil, errors.Unavailable("ledger client is not configured")
+	}
+
+	report := &models.LedgerAuditReport{
+		From:          from,
+		To:            to,
+		Discrepancies: make([]models.LedgerDiscrepancy, 0),
+	}
+
+	status := models.TransactionStatusCompleted
+	txType := models.TransactionTypeTransfer
+	start := sharedModels.NewTimestamp(from)
+	end := sharedModels.NewTimestamp(to)
+	filter := &models.TransactionFilter{
+		Status:    &status,
+		Type:      &txType,
+		StartDate: &start,
+		EndDate:   &end,
+		Limit:     LedgerAuditBatch,
+	}
+
+	for {
+		page, err := s.transactionRepo.SearchAll(ctx, filter)
+		if err != nil {
+			return report, err
+		}
+
+		refs := make([]LedgerReference, 0, len(page))
+		for _, tx := range page {
+			// EndDate is inclusive in the filter; keep the window half-open
+			if !tx.CreatedAt.Before(end) {
+				continue
+			}
+			refs = append(refs, LedgerReference{ReferenceID: tx.ID, Amount: tx.Amount})
+		}
+
+		if len(refs) > 0 {
+			verification, verifyErr := s.ledgerClient.VerifyReferences(ctx, &LedgerVerifyRequest{
+				ReferenceType:     "transaction",
+				References:        refs,
+				DiscrepanciesOnly: true,
+			})
+			if verifyErr != nil {
+				return report, verifyErr
+			}
+
+			report.Checked += verification.Checked
+			report.Matched += verification.Matched
+			for _, result := range verification.Results {
+				report.Discrepancies = append(report.Discrepancies, s.recordLedgerDiscrepancy(result))
+			}
+		}
+
+		if len(page) < LedgerAuditBatch {
+			break
+		}
+		filter.Offset += LedgerAuditBatch
+	}
+
+	s.logger.With(map[string]interface{}{
+		"from":          from.Format(time.RFC3339),
+		"to":            to.Format(time.RFC3339),
+		"checked":       report.Checked,
+		"matched":       report.Matched,
+		"discrepancies": len(report.Discrepancies),
+	}).Info("Ledger audit completed")
+
+	return report, nil
+}
+
+func (s *TransactionService) recordLedgerDiscrepancy(result LedgerReferenceResult) models.LedgerDiscrepancy {
+	discrepancy := models.LedgerDiscrepancy{
+		TransactionID:  result.ReferenceID,
+		Status:         result.Status,
+		ExpectedAmount: result.ExpectedAmount,
+		PostedAmount:   result.PostedAmount,
+		Detail:         result.Detail,
+	}
+	for _, entry := range result.Entries {
+		discrepancy.EntryIDs = append(discrepancy.EntryIDs, entry.ID)
+	}
+
+	s.logger.With(map[string]interface{}{
+		"transaction_id":  discrepancy.TransactionID,
+		"ledger_status":   discrepancy.Status,
+		"expected_amount": discrepancy.ExpectedAmount,
+		"posted_amount":   discrepancy.PostedAmount,
+		"detail":          discrepancy.Detail,
+	}).Warn("Ledger discrepancy found for completed transaction")
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishTransactionEvent("transaction.ledger_discrepancy", discrepancy.TransactionID, map[string]interface{}{
+			"ledger_status":   discrepancy.Status,
+			"expected_amount": discrepancy.ExpectedAmount,
+			"posted_amount":   discrepancy.PostedAmount,
+			"detail":          discrepancy.Detail,
+			"entry_ids":       discrepancy.EntryIDs,
+		})
+	}
+
+	return discrepancy
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+)
+
+func TestAuditLedgerReferences_CollectsDiscrepancies(t *testing.T) {
+	okTx := &models.Transaction{ID: "tx-ok", Type: models.TransactionTypeTransfer, Status: models.TransactionStatusCompleted, Amount: 1000}
+	lostTx := &models.Transaction{ID: "tx-lost", Type: models.TransactionTypeTransfer, Status: models.TransactionStatusCompleted, Amount: 2500}
+	repo := &mockTransactionRepository{transactions: map[string]*models.Transaction{okTx.ID: okTx, lostTx.ID: lostTx}}
+
+	var received LedgerVerifyRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/v1/verify/references" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"checked":2,"matched":1,"discrepancies":1,"results":[
+			{"reference_id":"tx-lost","status":"missing","expected_amount":2500,"posted_amount":0,"detail":"no journal entry references this id"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	svc := NewTransactionService(repo, nil, nil, NewLedgerClient(server.URL), nil)
+
+	to := time.Now().UTC()
+	report, err := svc.AuditLedgerReferences(context.Background(), to.Add(-24*time.Hour), to)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if received.ReferenceType != "transaction" || !received.DiscrepanciesOnly || len(received.References) != 2 {
+		t.Errorf("unexpected verify request: %+v", received)
+	}
+	if report.Checked != 2 || report.Matched != 1 {
+		t.Errorf("unexpected totals: checked=%d matched=%d", report.Checked, report.Matched)
+	}
+	if len(report.Discrepancies) != 1 || report.Discrepancies[0].TransactionID != "tx-lost" || report.Discrepancies[0].Status != "missing" {
+		t.Errorf("unexpected discrepancies: %+v", report.Discrepancies)
+	}
+}
+
+func TestAuditLedgerReferences_RequiresLedgerClient(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: map[string]*models.Transaction{}}, nil, nil, nil, nil)
+
+	if _, err := svc.AuditLedgerReferences(context.Background(), time.Now().Add(-time.Hour), time.Now()); err == nil {
+		t.Error("expected error without ledger client")
+	}
+}
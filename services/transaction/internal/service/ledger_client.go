@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/clients"
@@ -17,7 +18,7 @@ type LedgerClient struct {
 // NewLedgerClient creates a new Ledger service client.
 func NewLedgerClient(baseURL string) *LedgerClient {
 	return &LedgerClient{
-		BaseClient: clients.NewBaseClient(baseURL, clients.DefaultTimeout),
+		BaseClient: clients.NewInternalClient(baseURL, clients.DefaultTimeout, ""),
 	}
 }
 
@@ -37,6 +38,7 @@ type CreateJournalEntryRequest struct {
 	ReferenceID   string         `json:"reference_id"`
 	Lines         []LedgerLine   `json:"lines"`
 	Metadata      map[string]any `json:"metadata,omitempty"`
+	UseSuspense   bool           `json:"use_suspense,omitempty"` // Park unbookable lines in suspense instead of failing
 }
 
 // JournalEntry represents a ledger journal entry.
@@ -73,6 +75,87 @@ func (c *LedgerClient) PostJournalEntry(ctx context.Context, entryID string) (*J
 	return &result, nil
 }
 
+// GetJournalEntriesByReference retrieves the journal entries recorded for a referenced entity.
+func (c *LedgerClient) GetJournalEntriesByReference(ctx context.Context, referenceType, referenceID string) ([]JournalEntry, *errors.Error) {
+	var result []JournalEntry
+	path := fmt.Sprintf("/internal/v1/journal-entries/by-reference?reference_type=%s&reference_id=%s",
+		url.QueryEscape(referenceType), url.QueryEscape(referenceID))
+	if err := c.Get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// LedgerReference is a reference the ledger should hold a posted entry for.
+type LedgerReference struct {
+	ReferenceID string `json:"reference_id"`
+	Amount      int64  `json:"amount"`
+}
+
+// LedgerVerifyRequest asks the ledger to verify a batch of references.
+type LedgerVerifyRequest struct {
+	ReferenceType     string            `json:"reference_type"`
+	References        []LedgerReference `json:"references"`
+	DiscrepanciesOnly bool              `json:"discrepancies_only"`
+}
+
+// LedgerVerifiedEntry summarizes a journal entry found for a reference.
+type LedgerVerifiedEntry struct {
+	ID          string `json:"id"`
+	EntryNumber string `json:"entry_number"`
+	Status      string `json:"status"`
+}
+
+// LedgerReferenceResult is the ledger's verdict for one reference.
+type LedgerReferenceResult struct {
+	ReferenceID    string                `json:"reference_id"`
+	Status         string                `json:"status"`
+	ExpectedAmount int64                 `json:"expected_amount"`
+	PostedAmount   int64                 `json:"posted_amount"`
+	Detail         string                `json:"detail"`
+	Entries        []LedgerVerifiedEntry `json:"entries"`
+}
+
+// LedgerVerificationReport is the ledger's response to a verification batch.
+type LedgerVerificationReport struct {
+	Checked       int                     `json:"checked"`
+	Matched       int                     `json:"matched"`
+	Discrepancies int                     `json:"discrepancies"`
+	Results       []LedgerReferenceResult `json:"results"`
+}
+
+// VerifyReferences checks that each reference has a posted journal entry for
+// the expected amount.
+func (c *LedgerClient) VerifyReferences(ctx context.Context, req *LedgerVerifyRequest) (*LedgerVerificationReport, *errors.Error) {
+	var result LedgerVerificationReport
+	if err := c.Post(ctx, "/internal/v1/verify/references", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// FXRate is the rate of one currency against the ledger's base currency.
+type FXRate struct {
+	Currency string `json:"currency"`
+	RateDate string `json:"rate_date"`
+	Rate     string `json:"rate"` // Base currency per unit, e.g. "83.125"
+}
+
+// FXRateTable is the latest rate of each currency against the base currency.
+type FXRateTable struct {
+	BaseCurrency string   `json:"base_currency"`
+	Rates        []FXRate `json:"rates"`
+}
+
+// GetFXRates retrieves the ledger's latest FX rates (internal endpoint).
+func (c *LedgerClient) GetFXRates(ctx context.Context) (*FXRateTable, *errors.Error) {
+	var result FXRateTable
+	if err := c.Get(ctx, "/internal/v1/fx/rates", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // CreateAndPostJournalEntry creates a journal entry and posts it in one operation.
 func (c *LedgerClient) CreateAndPostJournalEntry(ctx context.Context, req *CreateJournalEntryRequest) (*JournalEntry, *errors.Error) {
 	// Create the draft entry
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/crypto"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+// Merchant webhook defaults.
+const (
+	// DefaultMerchantWebhookHistoryLimit is the number of deliveries listed by default.
+	DefaultMerchantWebhookHistoryLimit = 50
+	// merchantWebhookSecretPrefix marks signing secrets so they are recognizable in config.
+	merchantWebhookSecretPrefix = "whsec_"
+	// maxMerchantWebhookError is how much of an endpoint's response is kept as the error.
+	maxMerchantWebhookError = 1024
+)
+
+// MerchantWebhookRepositoryInterface defines the interface for merchant
+// webhook storage.
+type MerchantWebhookRepositoryInterface interface {
+	Create(ctx context.Context, hook *models.MerchantWebhook) *errors.Error
+	GetByWallet(ctx context.Context, walletID string) (*models.MerchantWebhook, *errors.Error)
+	GetByID(ctx context.Context, id string) (*models.MerchantWebhook, *errors.Error)
+	Update(ctx context.Context, hook *models.MerchantWebhook) *errors.Error
+	Delete(ctx context.Context, walletID string) *errors.Error
+	Enqueue(ctx context.Context, delivery *models.MerchantWebhookDelivery) (bool, *errors.Error)
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]*models.MerchantWebhookDelivery, *errors.Error)
+	RecordAttempt(ctx context.Context, delivery *models.MerchantWebhookDelivery) *errors.Error
+	Redeliver(ctx context.Context, walletID, id string) (*models.MerchantWebhookDelivery, *errors.Error)
+	ListDeliveries(ctx context.Context, walletID string, status models.MerchantWebhookDeliveryStatus, limit int) ([]*models.MerchantWebhookDelivery, *errors.Error)
+}
+
+// MerchantWebhookPolicy configures payment notification delivery.
+type MerchantWebhookPolicy struct {
+	Backoff   retry.Policy  // MaxAttempts and the waits between attempts
+	Timeout   time.Duration // Bounds a single request
+	BatchSize int           // Deliveries sent per run
+	Lease     time.Duration // How long a claimed delivery is held before another worker may take it
+}
+
+// DefaultMerchantWebhookPolicy tries a delivery 8 times over about a day:
+// waits start at 30s and double up to 6h.
+func DefaultMerchantWebhookPolicy() MerchantWebhookPolicy {
+	return MerchantWebhookPolicy{
+		Backoff: retry.Policy{
+			MaxAttempts:  8,
+			InitialDelay: 30 * time.Second,
+			MaxDelay:     6 * time.Hour,
+			Multiplier:   2,
+		},
+		Timeout:   5 * time.Second,
+		BatchSize: 50,
+		Lease:     2 * time.Minute,
+	}
+}
+
+// merchantWebhooks holds the merchant webhook state of a TransactionService.
+type merchantWebhooks struct {
+	repo   MerchantWebhookRepositoryInterface
+	policy MerchantWebhookPolicy
+	client *http.Client
+}
+
+// SetMerchantWebhooks enables payment notifications to merchant webhooks.
+func (s *TransactionService) SetMerchantWebhooks(repo MerchantWebhookRepositoryInterface, policy MerchantWebhookPolicy) {
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultMerchantWebhookPolicy().Timeout
+	}
+	s.merchantWebhooks = &merchantWebhooks{
+		repo:   repo,
+		policy: policy,
+		client: &http.Client{
+			Timeout: policy.Timeout,
+			// A redirect would send the signed payload somewhere unregistered
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// ========================================================================
+// Webhook Management
+// ========================================================================
+
+// CreateMerchantWebhook registers a wallet's callback URL and issues its
+// signing secret. The caller must already be allowed to manage the wallet.
+func (s *TransactionService) CreateMerchantWebhook(ctx context.Context, walletID, userID string, req *models.MerchantWebhookRequest) (*models.MerchantWebhookWithSecret, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+	if err := validateMerchantWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateMerchantWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	hook := &models.MerchantWebhook{
+		WalletID:  walletID,
+		URL:       req.URL,
+		Secret:    secret,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		CreatedBy: userID,
+	}
+	if err := s.merchantWebhooks.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"wallet_id":  walletID,
+		"webhook_id": hook.ID,
+	}).Info("Merchant webhook registered")
+	return &models.MerchantWebhookWithSecret{MerchantWebhook: hook, Secret: secret}, nil
+}
+
+// GetMerchantWebhook returns a wallet's webhook.
+func (s *TransactionService) GetMerchantWebhook(ctx context.Context, walletID string) (*models.MerchantWebhook, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+	return s.merchantWebhooks.repo.GetByWallet(ctx, walletID)
+}
+
+// UpdateMerchantWebhook changes a wallet's callback URL or enabled flag. The
+// signing secret is kept.
+func (s *TransactionService) UpdateMerchantWebhook(ctx context.Context, walletID string, req *models.MerchantWebhookRequest) (*models.MerchantWebhook, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+	if err := validateMerchantWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	hook, err := s.merchantWebhooks.repo.GetByWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	hook.URL = req.URL
+	hook.Enabled = req.Enabled == nil || *req.Enabled
+	if err := s.merchantWebhooks.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// RotateMerchantWebhookSecret issues a new signing secret for a wallet's
+// webhook. Deliveries are signed with the new secret from the next attempt on.
+func (s *TransactionService) RotateMerchantWebhookSecret(ctx context.Context, walletID string) (*models.MerchantWebhookWithSecret, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+
+	hook, err := s.merchantWebhooks.repo.GetByWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := generateMerchantWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	hook.Secret = secret
+	if err := s.merchantWebhooks.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithField("webhook_id", hook.ID).Info("Merchant webhook secret rotated")
+	return &models.MerchantWebhookWithSecret{MerchantWebhook: hook, Secret: secret}, nil
+}
+
+// DeleteMerchantWebhook removes a wallet's webhook with its delivery history.
+func (s *TransactionService) DeleteMerchantWebhook(ctx context.Context, walletID string) *errors.Error {
+	if s.merchantWebhooks == nil {
+		return errors.Unavailable("merchant webhooks are not enabled")
+	}
+	return s.merchantWebhooks.repo.Delete(ctx, walletID)
+}
+
+// ListMerchantWebhookDeliveries returns a wallet's recent payment
+// notifications, newest first, optionally with one status.
+func (s *TransactionService) ListMerchantWebhookDeliveries(ctx context.Context, walletID string, status models.MerchantWebhookDeliveryStatus, limit int) ([]*models.MerchantWebhookDelivery, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+	switch status {
+	case "", models.MerchantWebhookDeliveryPending, models.MerchantWebhookDeliveryDelivered, models.MerchantWebhookDeliveryFailed:
+	default:
+		return nil, errors.Validation("status must be pending, delivered or failed")
+	}
+	if limit <= 0 {
+		limit = DefaultMerchantWebhookHistoryLimit
+	}
+	return s.merchantWebhooks.repo.ListDeliveries(ctx, walletID, status, limit)
+}
+
+// RedeliverMerchantWebhook queues a failed payment notification again.
+func (s *TransactionService) RedeliverMerchantWebhook(ctx context.Context, walletID, deliveryID string) (*models.MerchantWebhookDelivery, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return nil, errors.Unavailable("merchant webhooks are not enabled")
+	}
+	return s.merchantWebhooks.repo.Redeliver(ctx, walletID, deliveryID)
+}
+
+// ========================================================================
+// Delivery
+// ========================================================================
+
+// queueMerchantPayment queues a payment notification for the webhook of the
+// wallet a completed transfer paid into. Notifications are best effort; a
+// failure to queue one does not fail the transfer.
+func (s *TransactionService) queueMerchantPayment(ctx context.Context, transaction *models.Transaction) {
+	if s.merchantWebhooks == nil || transaction.Type != models.TransactionTypeTransfer || transaction.DestinationWalletID == nil {
+		return
+	}
+	// The transfer is done; queue the notification even if the request ends
+	ctx = context.WithoutCancel(ctx)
+	walletID := *transaction.DestinationWalletID
+
+	hook, err := s.merchantWebhooks.repo.GetByWallet(ctx, walletID)
+	if err != nil {
+		if err.Code != errors.ErrCodeNotFound {
+			s.logger.WithError(err).WithField("wallet_id", walletID).Warn("Failed to look up merchant webhook")
+		}
+		return
+	}
+	if !hook.Enabled {
+		return
+	}
+
+	completedAt := sharedModels.Now()
+	if transaction.CompletedAt != nil {
+		completedAt = *transaction.CompletedAt
+	}
+	payment := models.MerchantPaymentPayload{
+		Event:          models.MerchantPaymentReceivedEvent,
+		TransactionID:  transaction.ID,
+		WalletID:       walletID,
+		PayerWalletID:  transaction.SourceWalletID,
+		PayerReference: transaction.Reference,
+		Amount:         transaction.Amount,
+		Currency:       transaction.Currency,
+		Description:    transaction.Description,
+		CompletedAt:    completedAt,
+	}
+	// Payments of an intent carry its order reference as the transfer reference
+	if intentID := transaction.Metadata[models.MetaPaymentIntentID]; intentID != "" {
+		payment.PaymentIntentID = &intentID
+		payment.OrderReference = transaction.Reference
+	}
+	payload, marshalErr := json.Marshal(payment)
+	if marshalErr != nil {
+		s.logger.WithError(marshalErr).WithField("transaction_id", transaction.ID).Error("Failed to encode merchant payment notification")
+		return
+	}
+
+	delivery := &models.MerchantWebhookDelivery{
+		WebhookID:     hook.ID,
+		WalletID:      walletID,
+		TransactionID: transaction.ID,
+		Payload:       payload,
+	}
+	if _, err := s.merchantWebhooks.repo.Enqueue(ctx, delivery); err != nil {
+		s.logger.WithError(err).WithField("transaction_id", transaction.ID).Error("Failed to queue merchant payment notification")
+	}
+}
+
+// DeliverMerchantWebhooks sends the payment notifications that are due and
+// returns how many were delivered.
+func (s *TransactionService) DeliverMerchantWebhooks(ctx context.Context) (int, *errors.Error) {
+	if s.merchantWebhooks == nil {
+		return 0, nil
+	}
+	policy := s.merchantWebhooks.policy
+
+	now := time.Now()
+	deliveries, err := s.merchantWebhooks.repo.ClaimDue(ctx, now, now.Add(policy.Lease), policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks := make(map[string]*models.MerchantWebhook)
+	delivered := 0
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		hook, ok := hooks[delivery.WebhookID]
+		if !ok {
+			hook, err = s.merchantWebhooks.repo.GetByID(ctx, delivery.WebhookID)
+			if err != nil {
+				s.logger.WithError(err).WithField("delivery_id", delivery.ID).Warn("Failed to load merchant webhook")
+				continue
+			}
+			hooks[delivery.WebhookID] = hook
+		}
+
+		if s.deliverMerchantWebhook(ctx, hook, delivery) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// deliverMerchantWebhook makes one attempt at a delivery and records the
+// outcome. A 2xx response delivers it; other 4xx responses except 408 and 429
+// fail it for good, and anything else is retried until the attempts run out.
+// Returns whether the delivery succeeded.
+func (s *TransactionService) deliverMerchantWebhook(ctx context.Context, hook *models.MerchantWebhook, delivery *models.MerchantWebhookDelivery) bool {
+	policy := s.merchantWebhooks.policy
+	delivery.Attempts++
+	delivery.LastStatusCode = nil
+	delivery.LastError = nil
+
+	var sendErr error
+	statusCode := 0
+	if hook.Enabled {
+		statusCode, sendErr = s.postMerchantWebhook(ctx, hook, delivery)
+	} else {
+		sendErr = fmt.Errorf("webhook is disabled")
+	}
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	permanent := !hook.Enabled ||
+		(statusCode >= 400 && statusCode < 500 && statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests)
+	switch {
+	case sendErr == nil:
+		delivery.Status = models.MerchantWebhookDeliveryDelivered
+	case permanent || delivery.Attempts >= max(policy.Backoff.MaxAttempts, 1):
+		delivery.Status = models.MerchantWebhookDeliveryFailed
+	default:
+		delivery.Status = models.MerchantWebhookDeliveryPending
+		delivery.NextAttemptAt = sharedModels.NewTimestamp(time.Now().Add(policy.Backoff.Delay(delivery.Attempts)))
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		delivery.LastError = &msg
+	}
+
+	// The outcome is recorded even if the worker is shutting down
+	if err := s.merchantWebhooks.repo.RecordAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+		s.logger.WithError(err).WithField("delivery_id", delivery.ID).Error("Failed to record merchant webhook attempt")
+	}
+
+	fields := map[string]interface{}{
+		"delivery_id":    delivery.ID,
+		"wallet_id":      delivery.WalletID,
+		"transaction_id": delivery.TransactionID,
+		"attempt":        delivery.Attempts,
+		"status":         string(delivery.Status),
+	}
+	if sendErr != nil {
+		s.logger.With(fields).WithField("error", sendErr.Error()).Warn("Merchant webhook attempt failed")
+		return false
+	}
+	s.logger.With(fields).Info("Merchant webhook delivered")
+	return true
+}
+
+// postMerchantWebhook sends a signed delivery and returns the response status
+// code, which is 0 if no response was received. Non-2xx responses are errors
+// carrying the start of the response body.
+func (s *TransactionService) postMerchantWebhook(ctx context.Context, hook *models.MerchantWebhook, delivery *models.MerchantWebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NivoMoney-Webhooks/1.0")
+	req.Header.Set(models.MerchantWebhookDeliveryIDHeader, delivery.ID)
+	req.Header.Set(models.MerchantWebhookAttemptHeader, strconv.Itoa(delivery.Attempts))
+	req.Header.Set(crypto.WebhookSignatureHeader, crypto.SignWebhook(hook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.merchantWebhooks.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxMerchantWebhookError))
+	if len(body) > 0 {
+		return resp.StatusCode, fmt.Errorf("webhook returned HTTP %d: %s", resp.StatusCode, body)
+	}
+	return resp.StatusCode, fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+}
+
+// validateMerchantWebhookURL requires an absolute http or https URL without
+// credentials.
+func validateMerchantWebhookURL(raw string) *errors.Error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.Validation("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.Validation("url must not contain credentials")
+	}
+	if u.Fragment != "" {
+		return errors.Validation("url must not contain a fragment")
+	}
+	return nil
+}
+
+// generateMerchantWebhookSecret returns a new random signing secret.
+func generateMerchantWebhookSecret() (string, *errors.Error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate webhook secret")
+	}
+	return merchantWebhookSecretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// PayeeRepositoryInterface defines the interface for payee repository operations.
+type PayeeRepositoryInterface interface {
+	ListRecent(ctx context.Context, walletID string, limit int) ([]*models.Payee, *errors.Error)
+	ListFavorites(ctx context.Context, walletID string) ([]*models.Payee, *errors.Error)
+	Pin(ctx context.Context, walletID, payeeWalletID string) *errors.Error
+	Unpin(ctx context.Context, walletID, payeeWalletID string) *errors.Error
+}
+
+// PayeeService builds quick-pay payee lists from transfer history and manages favorites.
+type PayeeService struct {
+	payeeRepo    PayeeRepositoryInterface
+	walletClient *WalletClient
+}
+
+// NewPayeeService creates a new payee service.
+func NewPayeeService(payeeRepo PayeeRepositoryInterface, walletClient *WalletClient) *PayeeService {
+	return &PayeeService{
+		payeeRepo:    payeeRepo,
+		walletClient: walletClient,
+	}
+}
+
+// GetPayees returns favorite and recent payees for a wallet.
+// limit bounds the recent list and is clamped to [1, MaxRecentPayees].
+func (s *PayeeService) GetPayees(ctx context.Context, walletID string, limit int) (*models.PayeeList, *errors.Error) {
+	if limit <= 0 {
+		limit = models.DefaultRecentPayees
+	}
+	if limit > models.MaxRecentPayees {
+		limit = models.MaxRecentPayees
+	}
+
+	favorites, err := s.payeeRepo.ListFavorites(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	recent, err := s.payeeRepo.ListRecent(ctx, walletID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PayeeList{
+		WalletID:  walletID,
+		Favorites: favorites,
+		Recent:    recent,
+	}, nil
+}
+
+// PinPayee marks a payee wallet as a favorite for the wallet.
+func (s *PayeeService) PinPayee(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	if walletID == payeeWalletID {
+		return errors.BadRequest("a wallet cannot be its own payee")
+	}
+
+	// Make sure the payee exists and can still receive transfers
+	if s.walletClient != nil {
+		info, err := s.walletClient.GetWalletInfo(ctx, payeeWalletID)
+		if err != nil {
+			return err
+		}
+		if info.Status == "closed" {
+			return errors.BadRequest("cannot pin a closed wallet")
+		}
+	}
+
+	return s.payeeRepo.Pin(ctx, walletID, payeeWalletID)
+}
+
+// UnpinPayee removes a payee wallet from the wallet's favorites.
+func (s *PayeeService) UnpinPayee(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	return s.payeeRepo.Unpin(ctx, walletID, payeeWalletID)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// =====================================================================
+// Mock Payee Repository
+// =====================================================================
+
+type mockPayeeRepository struct {
+	favorites map[string]map[string]bool
+	recent    []*models.Payee
+	lastLimit int
+}
+
+func newMockPayeeRepository() *mockPayeeRepository {
+	return &mockPayeeRepository{favorites: make(map[string]map[string]bool)}
+}
+
+func (m *mockPayeeRepository) ListRecent(ctx context.Context, walletID string, limit int) ([]*models.Payee, *errors.Error) {
+	m.lastLimit = limit
+	return m.recent, nil
+}
+
+func (m *mockPayeeRepository) ListFavorites(ctx context.Context, walletID string) ([]*models.Payee, *errors.Error) {
+	payees := make([]*models.Payee, 0)
+	for payeeID := range m.favorites[walletID] {
+		payees = append(payees, &models.Payee{WalletID: payeeID, IsFavorite: true})
+	}
+	return payees, nil
+}
+
+func (m *mockPayeeRepository) Pin(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	if m.favorites[walletID] == nil {
+		m.favorites[walletID] = make(map[string]bool)
+	}
+	m.favorites[walletID][payeeWalletID] = true
+	return nil
+}
+
+func (m *mockPayeeRepository) Unpin(ctx context.Context, walletID, payeeWalletID string) *errors.Error {
+	if !m.favorites[walletID][payeeWalletID] {
+		return errors.NotFound("favorite payee")
+	}
+	delete(m.favorites[walletID], payeeWalletID)
+	return nil
+}
+
+// =====================================================================
+// Payee Service Tests
+// =====================================================================
+
+func TestGetPayees_ClampsLimit(t *testing.T) {
+	repo := newMockPayeeRepository()
+	svc := NewPayeeService(repo, nil)
+	ctx := context.Background()
+
+	if _, err := svc.GetPayees(ctx, "wallet-1", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastLimit != models.DefaultRecentPayees {
+		t.Errorf("expected default limit %d, got %d", models.DefaultRecentPayees, repo.lastLimit)
+	}
+
+	if _, err := svc.GetPayees(ctx, "wallet-1", 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.lastLimit != models.MaxRecentPayees {
+		t.Errorf("expected max limit %d, got %d", models.MaxRecentPayees, repo.lastLimit)
+	}
+}
+
+func TestPinPayee_ThenList(t *testing.T) {
+	repo := newMockPayeeRepository()
+	repo.recent = []*models.Payee{{WalletID: "wallet-3", TransferCount: 2}}
+	svc := NewPayeeService(repo, nil)
+	ctx := context.Background()
+
+	if err := svc.PinPayee(ctx, "wallet-1", "wallet-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	list, err := svc.GetPayees(ctx, "wallet-1", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list.Favorites) != 1 || list.Favorites[0].WalletID != "wallet-2" {
+		t.Errorf("expected wallet-2 as favorite, got %+v", list.Favorites)
+	}
+	if len(list.Recent) != 1 || list.Recent[0].WalletID != "wallet-3" {
+		t.Errorf("expected wallet-3 as recent, got %+v", list.Recent)
+	}
+}
+
+func TestPinPayee_Error_SelfPayee(t *testing.T) {
+	svc := NewPayeeService(newMockPayeeRepository(), nil)
+
+	err := svc.PinPayee(context.Background(), "wallet-1", "wallet-1")
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request error, got %v", err)
+	}
+}
+
+func TestUnpinPayee_Error_NotPinned(t *testing.T) {
+	svc := NewPayeeService(newMockPayeeRepository(), nil)
+
+	err := svc.UnpinPayee(context.Background(), "wallet-1", "wallet-2")
+	if err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
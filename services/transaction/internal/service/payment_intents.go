@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+)
+
+// Payment intent defaults.
+const (
+	// DefaultPaymentIntentListLimit is the number of intents listed by default.
+	DefaultPaymentIntentListLimit = 50
+	// paymentIntentDeeplinkBase is opened by the app to show a payment intent.
+	paymentIntentDeeplinkBase = "nivo://pay"
+)
+
+// PaymentIntentRepositoryInterface defines the interface for merchant payment
+// intent storage.
+type PaymentIntentRepositoryInterface interface {
+	Create(ctx context.Context, intent *models.PaymentIntent) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.PaymentIntent, *errors.Error)
+	GetByOrderReference(ctx context.Context, walletID, orderReference string) (*models.PaymentIntent, *errors.Error)
+	ListByWallet(ctx context.Context, walletID string, status models.PaymentIntentStatus, limit int) ([]*models.PaymentIntent, *errors.Error)
+	Claim(ctx context.Context, id, payerWalletID string, now time.Time) (bool, *errors.Error)
+	MarkPaid(ctx context.Context, id, transactionID string) *errors.Error
+	Release(ctx context.Context, id string, transactionID *string, failureReason string) *errors.Error
+	Cancel(ctx context.Context, walletID, orderReference string, now time.Time) (bool, *errors.Error)
+}
+
+// SetPaymentIntents enables merchant payment intents.
+func (s *TransactionService) SetPaymentIntents(repo PaymentIntentRepositoryInterface) {
+	s.paymentIntentRepo = repo
+}
+
+// ========================================================================
+// Merchant Side
+// ========================================================================
+
+// CreatePaymentIntent requests a payment into a merchant wallet for one of
+// the merchant's orders and returns the deeplink and QR payload the payer
+// uses to pay it. The caller must already be allowed to transact on the
+// wallet.
+func (s *TransactionService) CreatePaymentIntent(ctx context.Context, walletID, userID string, req *models.PaymentIntentRequest) (*models.PaymentIntentWithPayload, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+
+	ttl := models.DefaultPaymentIntentTTL
+	if req.ExpiresInSeconds > 0 {
+		ttl = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+	if ttl > models.MaxPaymentIntentTTL {
+		return nil, errors.Validation(fmt.Sprintf("expires_in_seconds must be at most %d", int(models.MaxPaymentIntentTTL/time.Second)))
+	}
+
+	// The payment is a plain transfer, so it is made in the wallet's currency
+	currency := sharedModels.INR
+	if s.walletClient != nil {
+		wallet, err := s.walletClient.GetWalletInfo(ctx, walletID)
+		if err != nil {
+			return nil, err
+		}
+		if wallet.Status != "active" {
+			return nil, errors.Validation("wallet must be active to receive payments")
+		}
+		currency = sharedModels.Currency(wallet.Currency)
+	}
+
+	if amountErr := s.CheckAmount(ctx, models.AmountOperationTransfer, currency, req.Amount); amountErr != nil {
+		return nil, amountErr
+	}
+
+	intent := &models.PaymentIntent{
+		WalletID:       walletID,
+		OrderReference: req.OrderReference,
+		Amount:         req.Amount,
+		Currency:       currency,
+		Description:    req.Description,
+		Status:         models.PaymentIntentPending,
+		ExpiresAt:      sharedModels.NewTimestamp(time.Now().Add(ttl)),
+		CreatedBy:      userID,
+	}
+	if err := s.paymentIntentRepo.Create(ctx, intent); err != nil {
+		return nil, err
+	}
+
+	s.logger.With(map[string]interface{}{
+		"wallet_id":       walletID,
+		"intent_id":       intent.ID,
+		"order_reference": intent.OrderReference,
+	}).Info("Payment intent created")
+
+	deeplink := paymentIntentDeeplink(intent)
+	return &models.PaymentIntentWithPayload{PaymentIntent: intent, Deeplink: deeplink, QRPayload: deeplink}, nil
+}
+
+// GetPaymentIntentByOrder returns a merchant wallet's payment intent for one
+// of its orders.
+func (s *TransactionService) GetPaymentIntentByOrder(ctx context.Context, walletID, orderReference string) (*models.PaymentIntent, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+
+	intent, err := s.paymentIntentRepo.GetByOrderReference(ctx, walletID, orderReference)
+	if err != nil {
+		return nil, err
+	}
+	intent.ResolveStatus(time.Now())
+	return intent, nil
+}
+
+// ListPaymentIntents returns a merchant wallet's payment intents, newest
+// first, optionally with one stored status.
+func (s *TransactionService) ListPaymentIntents(ctx context.Context, walletID string, status models.PaymentIntentStatus, limit int) ([]*models.PaymentIntent, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+	switch status {
+	case "", models.PaymentIntentPending, models.PaymentIntentProcessing, models.PaymentIntentPaid, models.PaymentIntentCancelled:
+	default:
+		return nil, errors.Validation("status must be pending, processing, paid or cancelled")
+	}
+	if limit <= 0 {
+		limit = DefaultPaymentIntentListLimit
+	}
+
+	intents, err := s.paymentIntentRepo.ListByWallet(ctx, walletID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, intent := range intents {
+		intent.ResolveStatus(now)
+	}
+	return intents, nil
+}
+
+// CancelPaymentIntent withdraws a merchant's unpaid payment intent so it can
+// no longer be paid.
+func (s *TransactionService) CancelPaymentIntent(ctx context.Context, walletID, orderReference string) (*models.PaymentIntent, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+
+	cancelled, err := s.paymentIntentRepo.Cancel(ctx, walletID, orderReference, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	intent, err := s.GetPaymentIntentByOrder(ctx, walletID, orderReference)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, errors.New(errors.ErrCodeTransactionInvalidState, fmt.Sprintf("payment intent is %s and cannot be cancelled", intent.Status))
+	}
+	return intent, nil
+}
+
+// ========================================================================
+// Payer Side
+// ========================================================================
+
+// GetPaymentIntent returns what a payer sees of a payment intent.
+func (s *TransactionService) GetPaymentIntent(ctx context.Context, id string) (*models.PaymentIntentSummary, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+
+	intent, err := s.paymentIntentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	intent.ResolveStatus(time.Now())
+	return intent.Summary(), nil
+}
+
+// ConfirmPaymentIntent pays a payment intent from the payer's wallet with a
+// transfer into the merchant's wallet, referenced by the order. The intent is
+// held while the transfer runs so it cannot be paid twice. If the transfer
+// fails it is released and may be paid again; if it is left pending the
+// intent stays held as processing and is settled when the transfer completes
+// or fails. The caller must already be allowed to transact on the source
+// wallet.
+func (s *TransactionService) ConfirmPaymentIntent(ctx context.Context, id string, req *models.ConfirmPaymentIntentRequest) (*models.ConfirmPaymentIntentResponse, *errors.Error) {
+	if s.paymentIntentRepo == nil {
+		return nil, errors.Unavailable("merchant payments are not enabled")
+	}
+
+	intent, err := s.paymentIntentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.SourceWalletID == intent.WalletID {
+		return nil, errors.New(errors.ErrCodeTransferSameWallet, "a merchant cannot pay its own payment intent")
+	}
+
+	claimed, err := s.paymentIntentRepo.Claim(ctx, id, req.SourceWalletID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// Report the state that stopped it; another payer may have just won
+		if current, getErr := s.paymentIntentRepo.GetByID(ctx, id); getErr == nil {
+			intent = current
+		}
+		intent.ResolveStatus(time.Now())
+		return nil, errors.New(errors.ErrCodeTransactionInvalidState, fmt.Sprintf("payment intent is %s and cannot be paid", intent.Status))
+	}
+
+	description := intent.Description
+	if description == "" {
+		description = "Payment for order " + intent.OrderReference
+	}
+	metadata, _ := json.Marshal(map[string]string{models.MetaPaymentIntentID: intent.ID})
+
+	transaction, transferErr := s.CreateTransfer(ctx, &models.CreateTransferRequest{
+		SourceWalletID:      req.SourceWalletID,
+		DestinationWalletID: intent.WalletID,
+		Amount:              intent.Amount,
+		Currency:            intent.Currency,
+		Description:         description,
+		Reference:           intent.OrderReference,
+		MetadataRaw:         metadata,
+	})
+
+	// The intent is settled even if the payer's request ends now
+	ctx = context.WithoutCancel(ctx)
+	if transferErr != nil || transaction.Status == models.TransactionStatusFailed {
+		var transactionID *string
+		reason := "transfer failed"
+		if transaction != nil {
+			transactionID = &transaction.ID
+			if transaction.FailureReason != nil {
+				reason = *transaction.FailureReason
+			}
+		}
+		if transferErr == nil {
+			transferErr = errors.New(errors.ErrCodeTransactionFailed, "payment failed: "+reason)
+		}
+		if releaseErr := s.paymentIntentRepo.Release(ctx, id, transactionID, transferErr.Message); releaseErr != nil {
+			s.logger.WithError(releaseErr).WithField("intent_id", id).Error("Failed to release payment intent after failed payment")
+		}
+		return nil, transferErr
+	}
+
+	log := s.logger.With(map[string]interface{}{
+		"intent_id":       id,
+		"wallet_id":       intent.WalletID,
+		"order_reference": intent.OrderReference,
+		"transaction_id":  transaction.ID,
+	})
+	intent.PayerWalletID = &req.SourceWalletID
+	intent.TransactionID = &transaction.ID
+
+	// Completing the transfer marked the intent paid
+	if transaction.Status == models.TransactionStatusCompleted {
+		log.Info("Payment intent paid")
+		intent.Status = models.PaymentIntentPaid
+	} else {
+		log.Warn("Payment intent transfer left pending; intent held until it settles")
+		intent.Status = models.PaymentIntentProcessing
+	}
+	return &models.ConfirmPaymentIntentResponse{Intent: intent.Summary(), Transaction: transaction}, nil
+}
+
+// settlePaymentIntent settles the payment intent a transfer was paying, if
+// any, once the transfer has completed or failed. A completed transfer marks
+// the intent paid; a failed one releases it so it may be paid again.
+func (s *TransactionService) settlePaymentIntent(ctx context.Context, transaction *models.Transaction, status models.TransactionStatus, failureReason string) {
+	intentID := transaction.Metadata[models.MetaPaymentIntentID]
+	if s.paymentIntentRepo == nil || intentID == "" {
+		return
+	}
+	// The transfer is settled; settle the intent even if the request ends
+	ctx = context.WithoutCancel(ctx)
+	log := s.logger.With(map[string]interface{}{
+		"intent_id":      intentID,
+		"transaction_id": transaction.ID,
+	})
+
+	switch status {
+	case models.TransactionStatusCompleted:
+		if err := s.paymentIntentRepo.MarkPaid(ctx, intentID, transaction.ID); err != nil {
+			// The money moved; the intent is reconciled from the transaction
+			log.WithError(err).Error("Failed to mark payment intent paid - reconciliation needed")
+		}
+	case models.TransactionStatusFailed:
+		if err := s.paymentIntentRepo.Release(ctx, intentID, &transaction.ID, failureReason); err != nil {
+			log.WithError(err).Error("Failed to release payment intent after failed payment")
+		}
+	}
+}
+
+// paymentIntentDeeplink returns the link that opens a payment intent in the
+// app. It names the intent, payee, amount and order so the app can show the
+// payment before it loads the intent.
+func paymentIntentDeeplink(intent *models.PaymentIntent) string {
+	query := url.Values{}
+	query.Set("intent", intent.ID)
+	query.Set("pa", intent.WalletID)
+	query.Set("am", money.New(intent.Amount, intent.Currency).Decimal())
+	query.Set("cu", string(intent.Currency))
+	query.Set("tr", intent.OrderReference)
+	return paymentIntentDeeplinkBase + "?" + query.Encode()
+}
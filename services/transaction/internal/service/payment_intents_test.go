@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockPaymentIntentRepository struct {
+	intents []*models.PaymentIntent
+}
+
+func (m *mockPaymentIntentRepository) Create(ctx context.Context, intent *models.PaymentIntent) *errors.Error {
+	for _, existing := range m.intents {
+		if existing.WalletID == intent.WalletID && existing.OrderReference == intent.OrderReference {
+			return errors.Conflict("a payment intent already exists for this order reference")
+		}
+	}
+	intent.ID = uuid.New().String()
+	intent.CreatedAt = sharedModels.Now()
+	m.intents = append(m.intents, intent)
+	return nil
+}
+
+func (m *mockPaymentIntentRepository) GetByID(ctx context.Context, id string) (*models.PaymentIntent, *errors.Error) {
+	for _, intent := range m.intents {
+		if intent.ID == id {
+			copied := *intent
+			return &copied, nil
+		}
+	}
+	return nil, errors.NotFoundWithID("payment intent", id)
+}
+
+func (m *mockPaymentIntentRepository) GetByOrderReference(ctx context.Context, walletID, orderReference string) (*models.PaymentIntent, *errors.Error) {
+	for _, intent := range m.intents {
+		if intent.WalletID == walletID && intent.OrderReference == orderReference {
+			copied := *intent
+			return &copied, nil
+		}
+	}
+	return nil, errors.NotFoundWithID("payment intent", orderReference)
+}
+
+func (m *mockPaymentIntentRepository) ListByWallet(ctx context.Context, walletID string, status models.PaymentIntentStatus, limit int) ([]*models.PaymentIntent, *errors.Error) {
+	return m.intents, nil
+}
+
+func (m *mockPaymentIntentRepository) Claim(ctx context.Context, id, payerWalletID string, now time.Time) (bool, *errors.Error) {
+	for _, intent := range m.intents {
+		if intent.ID == id && intent.Status == models.PaymentIntentPending && now.Before(intent.ExpiresAt.Time) {
+			intent.Status = models.PaymentIntentProcessing
+			intent.PayerWalletID = &payerWalletID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockPaymentIntentRepository) MarkPaid(ctx context.Context, id, transactionID string) *errors.Error {
+	for _, intent := range m.intents {
+		if intent.ID == id {
+			intent.Status = models.PaymentIntentPaid
+			intent.TransactionID = &transactionID
+		}
+	}
+	return nil
+}
+
+func (m *mockPaymentIntentRepository) Release(ctx context.Context, id string, transactionID *string, failureReason string) *errors.Error {
+	for _, intent := range m.intents {
+		if intent.ID == id {
+			intent.Status = models.PaymentIntentPending
+			intent.FailureReason = &failureReason
+		}
+	}
+	return nil
+}
+
+func (m *mockPaymentIntentRepository) Cancel(ctx context.Context, walletID, orderReference string, now time.Time) (bool, *errors.Error) {
+	for _, intent := range m.intents {
+		if intent.WalletID == walletID && intent.OrderReference == orderReference && intent.Status == models.PaymentIntentPending && now.Before(intent.ExpiresAt.Time) {
+			intent.Status = models.PaymentIntentCancelled
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func setupPaymentIntentService(t *testing.T, balances map[string]int64) (*TransactionService, *mockPaymentIntentRepository, *mockMerchantWebhookRepository) {
+	t.Helper()
+
+	svc, webhooks := setupMerchantWebhookService(t, balances)
+	repo := &mockPaymentIntentRepository{}
+	svc.SetPaymentIntents(repo)
+	return svc, repo, webhooks
+}
+
+func TestCreatePaymentIntent_ReturnsDeeplink(t *testing.T) {
+	merchant := uuid.New().String()
+	svc, _, _ := setupPaymentIntentService(t, map[string]int64{})
+	ctx := context.Background()
+
+	intent, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-42", Amount: 24950})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if intent.Status != models.PaymentIntentPending || intent.Currency != "INR" {
+		t.Errorf("unexpected intent: %+v", intent.PaymentIntent)
+	}
+	if ttl := time.Until(intent.ExpiresAt.Time); ttl < 29*time.Minute || ttl > models.DefaultPaymentIntentTTL {
+		t.Errorf("expected the default expiry, got %v", ttl)
+	}
+
+	link, parseErr := url.Parse(intent.Deeplink)
+	if parseErr != nil || link.Scheme != "nivo" || intent.QRPayload != intent.Deeplink {
+		t.Fatalf("unexpected payload: deeplink=%s qr=%s", intent.Deeplink, intent.QRPayload)
+	}
+	query := link.Query()
+	if query.Get("intent") != intent.ID || query.Get("pa") != merchant || query.Get("am") != "249.50" || query.Get("tr") != "order-42" {
+		t.Errorf("unexpected deeplink parameters: %v", query)
+	}
+
+	if _, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-42", Amount: 100}); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict for a reused order reference, got %v", err)
+	}
+	tooLong := int(models.MaxPaymentIntentTTL/time.Second) + 1
+	if _, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-43", Amount: 100, ExpiresInSeconds: tooLong}); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected validation error for a long expiry, got %v", err)
+	}
+}
+
+func TestConfirmPaymentIntent_PaysOnce(t *testing.T) {
+	payer, other, merchant := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	svc, repo, webhooks := setupPaymentIntentService(t, map[string]int64{payer: 100000, other: 100000})
+	ctx := context.Background()
+
+	if _, err := svc.CreateMerchantWebhook(ctx, merchant, "user-1", &models.MerchantWebhookRequest{URL: "https://shop.example.com/payments"}); err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	intent, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-42", Amount: 2500})
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+
+	result, err := svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: payer})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Intent.Status != models.PaymentIntentPaid || result.Transaction.Status != models.TransactionStatusCompleted {
+		t.Fatalf("unexpected result: intent %s, transaction %s", result.Intent.Status, result.Transaction.Status)
+	}
+	if ref := result.Transaction.Reference; ref == nil || *ref != "order-42" || *result.Transaction.DestinationWalletID != merchant {
+		t.Errorf("expected a transfer to the merchant referencing the order, got %+v", result.Transaction)
+	}
+
+	// The merchant looks the payment up by order reference
+	stored, err := svc.GetPaymentIntentByOrder(ctx, merchant, "order-42")
+	if err != nil || stored.Status != models.PaymentIntentPaid || stored.TransactionID == nil || *stored.TransactionID != result.Transaction.ID {
+		t.Errorf("expected the intent to be paid by the transfer, got %+v (%v)", stored, err)
+	}
+
+	// And is notified with it
+	if len(webhooks.deliveries) != 1 {
+		t.Fatalf("expected one queued delivery, got %d", len(webhooks.deliveries))
+	}
+	var payload models.MerchantPaymentPayload
+	if err := json.Unmarshal(webhooks.deliveries[0].Payload, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.PaymentIntentID == nil || *payload.PaymentIntentID != intent.ID || payload.OrderReference == nil || *payload.OrderReference != "order-42" {
+		t.Errorf("expected the intent in the payload, got %+v", payload)
+	}
+
+	// A second confirmation is refused
+	_, err = svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: other})
+	if err == nil || err.Code != errors.ErrCodeTransactionInvalidState {
+		t.Errorf("expected a paid intent to be refused, got %v", err)
+	}
+	if len(repo.intents) != 1 || *repo.intents[0].PayerWalletID != payer {
+		t.Errorf("expected the first payer to be kept, got %+v", repo.intents[0])
+	}
+}
+
+func TestConfirmPaymentIntent_ReleasesAfterFailedTransfer(t *testing.T) {
+	payer, merchant := uuid.New().String(), uuid.New().String()
+	svc, repo, _ := setupPaymentIntentService(t, map[string]int64{payer: 1000})
+	ctx := context.Background()
+
+	intent, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-42", Amount: 2500})
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+
+	if _, err := svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: payer}); err == nil {
+		t.Fatal("expected the payment to fail")
+	}
+	stored := repo.intents[0]
+	if stored.Status != models.PaymentIntentPending || stored.FailureReason == nil {
+		t.Fatalf("expected the intent to be payable again with the failure recorded, got %+v", stored)
+	}
+
+	if _, err := svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: merchant}); err == nil || err.Code != errors.ErrCodeTransferSameWallet {
+		t.Errorf("expected a merchant paying itself to be refused, got %v", err)
+	}
+}
+
+func TestConfirmPaymentIntent_HoldsWhileTransferPending(t *testing.T) {
+	payer, other, merchant := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	svc, repo, _ := setupPaymentIntentService(t, map[string]int64{payer: 100000, other: 100000})
+	ctx := context.Background()
+
+	intent, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-42", Amount: 2500})
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+
+	// The funds move but the transfer cannot be marked completed
+	txRepo := svc.transactionRepo.(*mockTransactionRepository)
+	txRepo.updateStatusFunc = func(ctx context.Context, id string, status models.TransactionStatus, failureReason *string) *errors.Error {
+		return errors.Internal("database unavailable")
+	}
+
+	result, err := svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: payer})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Intent.Status != models.PaymentIntentProcessing || result.Transaction.Status != models.TransactionStatusPending {
+		t.Fatalf("unexpected result: intent %s, transaction %s", result.Intent.Status, result.Transaction.Status)
+	}
+	if stored := repo.intents[0]; stored.Status != models.PaymentIntentProcessing {
+		t.Fatalf("expected the intent to stay held, got %s", stored.Status)
+	}
+
+	// Nobody else can pay it while the transfer is pending
+	if _, err := svc.ConfirmPaymentIntent(ctx, intent.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: other}); err == nil || err.Code != errors.ErrCodeTransactionInvalidState {
+		t.Errorf("expected a held intent to be refused, got %v", err)
+	}
+
+	// Completing the transfer, as the stuck pending reaper does, pays the intent
+	txRepo.updateStatusFunc = nil
+	if err := svc.completeTransfer(ctx, txRepo.transactions[result.Transaction.ID], false); err != nil {
+		t.Fatalf("complete transfer: %v", err)
+	}
+	if stored := repo.intents[0]; stored.Status != models.PaymentIntentPaid || stored.TransactionID == nil || *stored.TransactionID != result.Transaction.ID {
+		t.Errorf("expected the intent to be paid by the transfer, got %+v", stored)
+	}
+}
+
+func TestPaymentIntent_ExpiryAndCancel(t *testing.T) {
+	payer, merchant := uuid.New().String(), uuid.New().String()
+	svc, repo, _ := setupPaymentIntentService(t, map[string]int64{payer: 100000})
+	ctx := context.Background()
+
+	expiring, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-1", Amount: 2500})
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	repo.intents[0].ExpiresAt = sharedModels.NewTimestamp(time.Now().Add(-time.Second))
+
+	view, err := svc.GetPaymentIntent(ctx, expiring.ID)
+	if err != nil || view.Status != models.PaymentIntentExpired {
+		t.Errorf("expected an expired intent, got %+v (%v)", view, err)
+	}
+	if _, err := svc.ConfirmPaymentIntent(ctx, expiring.ID, &models.ConfirmPaymentIntentRequest{SourceWalletID: payer}); err == nil || err.Code != errors.ErrCodeTransactionInvalidState {
+		t.Errorf("expected an expired intent to be refused, got %v", err)
+	}
+
+	if _, err := svc.CreatePaymentIntent(ctx, merchant, "user-1", &models.PaymentIntentRequest{OrderReference: "order-2", Amount: 2500}); err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	cancelled, err := svc.CancelPaymentIntent(ctx, merchant, "order-2")
+	if err != nil || cancelled.Status != models.PaymentIntentCancelled {
+		t.Fatalf("expected a cancelled intent, got %+v (%v)", cancelled, err)
+	}
+	if _, err := svc.CancelPaymentIntent(ctx, merchant, "order-2"); err == nil || err.Code != errors.ErrCodeTransactionInvalidState {
+		t.Errorf("expected a second cancel to be refused, got %v", err)
+	}
+	if _, err := svc.CancelPaymentIntent(ctx, merchant, "order-3"); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found for an unknown order, got %v", err)
+	}
+}
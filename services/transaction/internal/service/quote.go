@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Transfer quote defaults.
+const (
+	DefaultQuoteTTL         = 2 * time.Minute
+	DefaultFXMarkupBps      = 50 // 0.5% over the mid rate
+	DefaultQuotePurgeAfter  = 24 * time.Hour
+	quoteRateDecimalPlaces  = 10
+	basisPointsDenomination = 10000
+)
+
+// QuoteRepositoryInterface defines the interface for transfer quote storage.
+type QuoteRepositoryInterface interface {
+	Create(ctx context.Context, quote *models.TransferQuote) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.TransferQuote, *errors.Error)
+	Claim(ctx context.Context, id string) (*models.TransferQuote, *errors.Error)
+	Release(ctx context.Context, id string) *errors.Error
+	SetTransaction(ctx context.Context, id, transactionID string) *errors.Error
+	PurgeExpired(ctx context.Context, cutoff time.Time) (int64, *errors.Error)
+}
+
+// FXRateSource provides the rates quotes convert with.
+type FXRateSource interface {
+	GetFXRates(ctx context.Context) (*FXRateTable, *errors.Error)
+}
+
+// QuotePolicy controls how transfers are priced.
+type QuotePolicy struct {
+	// TTL is how long a quote's price is held.
+	TTL time.Duration
+	// FXMarkupBps is the spread over the mid rate, in basis points, charged
+	// when the transfer amount is in another currency than the source wallet.
+	FXMarkupBps int64
+}
+
+// DefaultQuotePolicy returns the default quote lifetime and FX markup.
+func DefaultQuotePolicy() QuotePolicy {
+	return QuotePolicy{
+		TTL:         DefaultQuoteTTL,
+		FXMarkupBps: DefaultFXMarkupBps,
+	}
+}
+
+// SetQuotes enables transfer quotes, priced with rates from the ledger.
+func (s *TransactionService) SetQuotes(repo QuoteRepositoryInterface, rates FXRateSource, policy QuotePolicy) {
+	if policy.TTL <= 0 {
+		policy.TTL = DefaultQuoteTTL
+	}
+	s.quoteRepo = repo
+	s.fxRates = rates
+	s.quotePolicy = policy
+}
+
+// QuotesEnabled reports whether transfer quotes are configured.
+func (s *TransactionService) QuotesEnabled() bool {
+	return s.quoteRepo != nil
+}
+
+// QuoteTransfer prices a prospective transfer without creating it: the debit
+// from the source wallet with fees, the FX rate when the amount is in another
+// currency, the impact on the wallet's balance and limits, and when it would
+// settle. The returned quote ID locks the price for a transfer made before it
+// expires.
+func (s *TransactionService) QuoteTransfer(ctx context.Context, req *models.QuoteTransferRequest, userID string) (*models.TransferQuoteResponse, *errors.Error) {
+	if s.quoteRepo == nil {
+		return nil, errors.Internal("transfer quotes are not enabled")
+	}
+	if s.walletClient == nil {
+		return nil, errors.Internal("wallet client not configured")
+	}
+	if req.SourceWalletID == req.DestinationWalletID {
+		return nil, errors.New(errors.ErrCodeTransferSameWallet, "source and destination wallets must be different")
+	}
+	if err := req.Currency.Validate(); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
+
+	source, err := s.walletClient.GetWalletInfo(ctx, req.SourceWalletID)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.walletClient.GetWalletInfo(ctx, req.DestinationWalletID)
+	if err != nil {
+		return nil, err
+	}
+	if stateErr := quotableWallets(source, dest); stateErr != nil {
+		return nil, stateErr
+	}
+
+	debitCurrency := sharedModels.Currency(source.Currency)
+	price, err := s.priceTransfer(ctx, req.Amount, req.Currency, debitCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.walletClient.GetLimits(ctx, req.SourceWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	quote := &models.TransferQuote{
+		SourceWalletID:      req.SourceWalletID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+		Currency:            req.Currency,
+		DebitAmount:         price.debit,
+		DebitCurrency:       debitCurrency,
+		FXRate:              price.rate,
+		MidRate:             price.midRate,
+		FeeAmount:           price.fee,
+		ExpiresAt:           sharedModels.NewTimestamp(now.Add(s.quotePolicy.TTL)),
+	}
+	if userID != "" {
+		quote.CreatedBy = &userID
+	}
+
+	if createErr := s.quoteRepo.Create(ctx, quote); createErr != nil {
+		return nil, createErr
+	}
+
+	fees := make([]models.QuoteFee, 0, 1)
+	if price.fee > 0 {
+		fees = append(fees, models.QuoteFee{Type: models.FeeTypeFXMarkup, Amount: price.fee, Currency: debitCurrency})
+	}
+
+	return &models.TransferQuoteResponse{
+		TransferQuote: quote,
+		Fees:          fees,
+		Limits:        limitsImpact(source.AvailableBalance, limits, price.debit, now),
+		Settlement: models.QuoteSettlement{
+			// Wallet-to-wallet transfers settle as soon as they are accepted
+			Method:      models.SettlementInstant,
+			EstimatedAt: sharedModels.NewTimestamp(now),
+		},
+	}, nil
+}
+
+// quotableWallets rejects wallets a transfer would be refused for.
+func quotableWallets(source, dest *WalletInfo) *errors.Error {
+	if source.Status == "inactive" {
+		return errors.New(errors.ErrCodeKYCRequired, "source wallet is awaiting KYC verification")
+	}
+	if source.Status != "active" {
+		return errors.New(errors.ErrCodeWalletNotActive, "source wallet is not active").AddDetail("status", source.Status)
+	}
+	if dest.Status != "active" {
+		return errors.New(errors.ErrCodeWalletNotActive, "destination wallet is not active").AddDetail("status", dest.Status)
+	}
+	if source.Currency != dest.Currency {
+		return errors.New(errors.ErrCodeWalletCurrencyMismatch,
+			fmt.Sprintf("currency mismatch: source is %s, destination is %s", source.Currency, dest.Currency))
+	}
+	return nil
+}
+
+// transferPrice is the debit for a transfer amount and how it was converted.
+type transferPrice struct {
+	debit   int64
+	fee     int64
+	rate    *string
+	midRate *string
+}
+
+// priceTransfer converts amount in currency into the debit currency, charging
+// the FX markup. Amounts already in the debit currency are free.
+func (s *TransactionService) priceTransfer(ctx context.Context, amount int64, currency, debitCurrency sharedModels.Currency) (*transferPrice, *errors.Error) {
+	if currency == debitCurrency {
+		return &transferPrice{debit: amount}, nil
+	}
+	if s.fxRates == nil {
+		return nil, errors.Validation(fmt.Sprintf("cannot convert %s to %s: FX rates are not configured", currency, debitCurrency))
+	}
+
+	table, err := s.fxRates.GetFXRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return priceConversion(amount, currency, debitCurrency, table, s.quotePolicy.FXMarkupBps)
+}
+
+// priceConversion prices a conversion at the cross rate of two currencies'
+// rates against the base currency, plus markupBps.
+func priceConversion(amount int64, from, to sharedModels.Currency, table *FXRateTable, markupBps int64) (*transferPrice, *errors.Error) {
+	fromRate, err := baseRate(table, from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := baseRate(table, to)
+	if err != nil {
+		return nil, err
+	}
+
+	mid := new(big.Rat).Quo(fromRate, toRate)
+	rate := new(big.Rat).Mul(mid, big.NewRat(basisPointsDenomination+markupBps, basisPointsDenomination))
+
+	midDebit, err := convertAmount(amount, from, to, mid)
+	if err != nil {
+		return nil, err
+	}
+	debit, err := convertAmount(amount, from, to, rate)
+	if err != nil {
+		return nil, err
+	}
+	if debit <= 0 {
+		return nil, errors.Validation(fmt.Sprintf("amount is too small to convert from %s to %s", from, to))
+	}
+
+	fee := debit - midDebit
+	if fee < 0 {
+		fee = 0
+	}
+	return &transferPrice{
+		debit:   debit,
+		fee:     fee,
+		rate:    formatRate(rate),
+		midRate: formatRate(mid),
+	}, nil
+}
+
+// baseRate returns a currency's rate against the table's base currency.
+func baseRate(table *FXRateTable, currency sharedModels.Currency) (*big.Rat, *errors.Error) {
+	if string(currency) == table.BaseCurrency {
+		return big.NewRat(1, 1), nil
+	}
+	for _, r := range table.Rates {
+		if r.Currency != string(currency) {
+			continue
+		}
+		rate, ok := new(big.Rat).SetString(r.Rate)
+		if !ok || rate.Sign() <= 0 {
+			return nil, errors.Internal(fmt.Sprintf("invalid FX rate %q for %s", r.Rate, currency))
+		}
+		return rate, nil
+	}
+	return nil, errors.Validation(fmt.Sprintf("no FX rate is available for %s", currency))
+}
+
+// convertAmount converts an amount in from's smallest unit to to's smallest
+// unit at rate, rounding half away from zero.
+func convertAmount(amount int64, from, to sharedModels.Currency, rate *big.Rat) (int64, *errors.Error) {
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	value.Mul(value, new(big.Rat).SetFrac(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(to.GetDecimalPlaces())), nil),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(from.GetDecimalPlaces())), nil),
+	))
+
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	if !quotient.IsInt64() {
+		return 0, errors.Validation(fmt.Sprintf("%s amount %d is too large to convert", from, amount))
+	}
+	return quotient.Int64(), nil
+}
+
+// formatRate formats a rate with at most quoteRateDecimalPlaces decimals.
+func formatRate(rate *big.Rat) *string {
+	formatted := rate.FloatString(quoteRateDecimalPlaces)
+	formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	return &formatted
+}
+
+// limitsImpact previews how a debit draws on the wallet's balance and limits.
+// Spend from a period whose reset time has passed no longer counts, as the
+// wallet resets it on the next transfer.
+func limitsImpact(available int64, limits *WalletLimits, debit int64, now time.Time) models.QuoteLimitsImpact {
+	dailySpent, monthlySpent := limits.DailySpent, limits.MonthlySpent
+	if !limits.DailyResetAt.IsZero() && !now.Before(limits.DailyResetAt) {
+		dailySpent = 0
+	}
+	if !limits.MonthlyResetAt.IsZero() && !now.Before(limits.MonthlyResetAt) {
+		monthlySpent = 0
+	}
+
+	dailyRemaining := nonNegative(limits.DailyLimit - dailySpent)
+	monthlyRemaining := nonNegative(limits.MonthlyLimit - monthlySpent)
+
+	return models.QuoteLimitsImpact{
+		AvailableBalance:      available,
+		SufficientFunds:       debit <= available,
+		DailyLimit:            limits.DailyLimit,
+		DailyRemaining:        dailyRemaining,
+		DailyRemainingAfter:   nonNegative(dailyRemaining - debit),
+		MonthlyLimit:          limits.MonthlyLimit,
+		MonthlyRemaining:      monthlyRemaining,
+		MonthlyRemainingAfter: nonNegative(monthlyRemaining - debit),
+		WithinLimits:          debit <= dailyRemaining && debit <= monthlyRemaining,
+	}
+}
+
+func nonNegative(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// claimQuote claims the quote a transfer references and applies its locked
+// debit to the transaction. The transfer must match what was quoted.
+func (s *TransactionService) claimQuote(ctx context.Context, req *models.CreateTransferRequest, transaction *models.Transaction) *errors.Error {
+	if s.quoteRepo == nil {
+		return errors.Validation("transfer quotes are not enabled")
+	}
+
+	quote, err := s.quoteRepo.GetByID(ctx, req.QuoteID)
+	if err != nil {
+		return err
+	}
+	if quote.SourceWalletID != req.SourceWalletID || quote.DestinationWalletID != req.DestinationWalletID ||
+		quote.Amount != req.Amount || quote.Currency != req.Currency {
+		return errors.Validation("transfer does not match the quoted wallets, amount and currency")
+	}
+
+	quote, err = s.quoteRepo.Claim(ctx, req.QuoteID)
+	if err != nil {
+		return err
+	}
+
+	transaction.Amount = quote.DebitAmount
+	transaction.Currency = quote.DebitCurrency
+	if transaction.Metadata == nil {
+		transaction.Metadata = make(map[string]string)
+	}
+	transaction.Metadata[models.MetaQuoteID] = quote.ID
+	transaction.Metadata[models.MetaQuoteAmount] = strconv.FormatInt(quote.Amount, 10)
+	transaction.Metadata[models.MetaQuoteCurrency] = string(quote.Currency)
+	transaction.Metadata[models.MetaQuoteFee] = strconv.FormatInt(quote.FeeAmount, 10)
+	if quote.FXRate != nil {
+		transaction.Metadata[models.MetaQuoteFXRate] = *quote.FXRate
+	}
+	return nil
+}
+
+// PurgeExpiredQuotes deletes unused quotes that expired more than
+// DefaultQuotePurgeAfter ago (called by background worker).
+func (s *TransactionService) PurgeExpiredQuotes(ctx context.Context) (int64, *errors.Error) {
+	if s.quoteRepo == nil {
+		return 0, nil
+	}
+	return s.quoteRepo.PurgeExpired(ctx, time.Now().Add(-DefaultQuotePurgeAfter))
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockQuoteRepository struct {
+	quotes map[string]*models.TransferQuote
+}
+
+func newMockQuoteRepository() *mockQuoteRepository {
+	return &mockQuoteRepository{quotes: make(map[string]*models.TransferQuote)}
+}
+
+func (m *mockQuoteRepository) Create(ctx context.Context, quote *models.TransferQuote) *errors.Error {
+	quote.ID = uuid.New().String()
+	quote.CreatedAt = sharedModels.Now()
+	m.quotes[quote.ID] = quote
+	return nil
+}
+
+func (m *mockQuoteRepository) GetByID(ctx context.Context, id string) (*models.TransferQuote, *errors.Error) {
+	quote, ok := m.quotes[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("transfer quote", id)
+	}
+	return quote, nil
+}
+
+func (m *mockQuoteRepository) Claim(ctx context.Context, id string) (*models.TransferQuote, *errors.Error) {
+	quote, err := m.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if quote.UsedAt != nil {
+		return nil, errors.New(errors.ErrCodeQuoteUsed, "transfer quote has already been used")
+	}
+	if !quote.ExpiresAt.After(sharedModels.Now()) {
+		return nil, errors.New(errors.ErrCodeQuoteExpired, "transfer quote has expired")
+	}
+	now := sharedModels.Now()
+	quote.UsedAt = &now
+	return quote, nil
+}
+
+func (m *mockQuoteRepository) Release(ctx context.Context, id string) *errors.Error {
+	if quote, ok := m.quotes[id]; ok && quote.TransactionID == nil {
+		quote.UsedAt = nil
+	}
+	return nil
+}
+
+func (m *mockQuoteRepository) SetTransaction(ctx context.Context, id, transactionID string) *errors.Error {
+	if quote, ok := m.quotes[id]; ok {
+		quote.TransactionID = &transactionID
+	}
+	return nil
+}
+
+func (m *mockQuoteRepository) PurgeExpired(ctx context.Context, cutoff time.Time) (int64, *errors.Error) {
+	return 0, nil
+}
+
+type staticFXRates struct {
+	table *FXRateTable
+}
+
+func (s staticFXRates) GetFXRates(ctx context.Context) (*FXRateTable, *errors.Error) {
+	return s.table, nil
+}
+
+var testFXRates = &FXRateTable{
+	BaseCurrency: "INR",
+	Rates: []FXRate{
+		{Currency: "USD", Rate: "83.25"},
+		{Currency: "EUR", Rate: "90"},
+		{Currency: "JPY", Rate: "0.56"},
+	},
+}
+
+func TestPriceConversion(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    int64
+		from, to  sharedModels.Currency
+		markupBps int64
+		wantDebit int64
+		wantFee   int64
+		wantRate  string
+	}{
+		{"into base currency", 1000, sharedModels.USD, sharedModels.INR, 50, 83666, 416, "83.66625"},
+		{"without markup", 1000, sharedModels.USD, sharedModels.INR, 0, 83250, 0, "83.25"},
+		{"zero decimal currency", 1000, sharedModels.JPY, sharedModels.INR, 0, 56000, 0, "0.56"},
+		{"cross rate", 10000, sharedModels.EUR, sharedModels.USD, 0, 10811, 0, "1.0810810811"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := priceConversion(tt.amount, tt.from, tt.to, testFXRates, tt.markupBps)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if price.debit != tt.wantDebit || price.fee != tt.wantFee {
+				t.Errorf("expected debit %d fee %d, got debit %d fee %d", tt.wantDebit, tt.wantFee, price.debit, price.fee)
+			}
+			if price.rate == nil || *price.rate != tt.wantRate {
+				t.Errorf("expected rate %s, got %v", tt.wantRate, price.rate)
+			}
+		})
+	}
+}
+
+func TestPriceConversion_MissingRate(t *testing.T) {
+	_, err := priceConversion(1000, sharedModels.GBP, sharedModels.INR, testFXRates, 0)
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Fatalf("expected validation error for missing rate, got %v", err)
+	}
+}
+
+func TestLimitsImpact_ResetsElapsedPeriods(t *testing.T) {
+	now := time.Now()
+	limits := &WalletLimits{
+		DailyLimit:     100000,
+		DailySpent:     90000,
+		DailyResetAt:   now.Add(-time.Minute), // Elapsed: spend no longer counts
+		MonthlyLimit:   500000,
+		MonthlySpent:   450000,
+		MonthlyResetAt: now.Add(24 * time.Hour),
+	}
+
+	impact := limitsImpact(80000, limits, 60000, now)
+
+	if impact.DailyRemaining != 100000 || impact.DailyRemainingAfter != 40000 {
+		t.Errorf("expected daily remaining 100000 -> 40000, got %d -> %d", impact.DailyRemaining, impact.DailyRemainingAfter)
+	}
+	if impact.MonthlyRemaining != 50000 || impact.MonthlyRemainingAfter != 0 {
+		t.Errorf("expected monthly remaining 50000 -> 0, got %d -> %d", impact.MonthlyRemaining, impact.MonthlyRemainingAfter)
+	}
+	if impact.WithinLimits {
+		t.Error("expected debit over the monthly remaining to be outside limits")
+	}
+	if !impact.SufficientFunds {
+		t.Error("expected sufficient funds")
+	}
+}
+
+// setupQuoteService returns a service with quotes enabled and a wallet
+// service where both wallets are active INR wallets.
+func setupQuoteService(t *testing.T) (*TransactionService, *mockQuoteRepository, *mockTransactionRepository) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/info"):
+			_, _ = w.Write([]byte(`{"success":true,"data":{"id":"w","user_id":"u","status":"active","currency":"INR","available_balance":200000}}`))
+		case strings.HasSuffix(r.URL.Path, "/limits"):
+			_, _ = w.Write([]byte(`{"success":true,"data":{"daily_limit":100000,"daily_spent":20000,"monthly_limit":1000000,"monthly_spent":20000}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	quoteRepo := newMockQuoteRepository()
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), nil, nil)
+	svc.SetQuotes(quoteRepo, staticFXRates{table: testFXRates}, QuotePolicy{TTL: time.Minute, FXMarkupBps: 50})
+
+	return svc, quoteRepo, txRepo
+}
+
+func TestQuoteTransfer_ConvertsAndPreviewsLimits(t *testing.T) {
+	svc, quoteRepo, _ := setupQuoteService(t)
+
+	resp, err := svc.QuoteTransfer(context.Background(), &models.QuoteTransferRequest{
+		SourceWalletID:      uuid.New().String(),
+		DestinationWalletID: uuid.New().String(),
+		Amount:              1000,
+		Currency:            sharedModels.USD,
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if resp.DebitAmount != 83666 || resp.DebitCurrency != sharedModels.INR {
+		t.Errorf("expected debit 83666 INR, got %d %s", resp.DebitAmount, resp.DebitCurrency)
+	}
+	if len(resp.Fees) != 1 || resp.Fees[0].Type != models.FeeTypeFXMarkup || resp.Fees[0].Amount != 416 {
+		t.Errorf("unexpected fees: %+v", resp.Fees)
+	}
+	if resp.Limits.DailyRemaining != 80000 || resp.Limits.WithinLimits {
+		t.Errorf("expected debit to exceed daily remaining 80000, got %+v", resp.Limits)
+	}
+	if resp.Settlement.Method != models.SettlementInstant {
+		t.Errorf("expected instant settlement, got %s", resp.Settlement.Method)
+	}
+	if _, ok := quoteRepo.quotes[resp.ID]; !ok {
+		t.Error("expected quote to be stored")
+	}
+}
+
+func TestCreateTransfer_WithQuoteLocksDebit(t *testing.T) {
+	svc, quoteRepo, _ := setupQuoteService(t)
+	ctx := context.Background()
+
+	quoteReq := &models.QuoteTransferRequest{
+		SourceWalletID:      uuid.New().String(),
+		DestinationWalletID: uuid.New().String(),
+		Amount:              500,
+		Currency:            sharedModels.USD,
+	}
+	quote, err := svc.QuoteTransfer(ctx, quoteReq, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := &models.CreateTransferRequest{
+		SourceWalletID:      quoteReq.SourceWalletID,
+		DestinationWalletID: quoteReq.DestinationWalletID,
+		Amount:              quoteReq.Amount,
+		Currency:            quoteReq.Currency,
+		Description:         "Quoted transfer",
+		QuoteID:             quote.ID,
+	}
+
+	tx, err := svc.CreateTransfer(ctx, req)
+	if err != nil && err.Code == errors.ErrCodeValidation {
+		t.Fatalf("expected quote to be accepted, got %v", err)
+	}
+	if tx == nil {
+		t.Fatal("expected transaction to be created")
+	}
+	if tx.Amount != quote.DebitAmount || tx.Currency != sharedModels.INR {
+		t.Errorf("expected locked debit %d INR, got %d %s", quote.DebitAmount, tx.Amount, tx.Currency)
+	}
+	if tx.Metadata[models.MetaQuoteID] != quote.ID {
+		t.Errorf("expected quote id in metadata, got %v", tx.Metadata)
+	}
+	if stored := quoteRepo.quotes[quote.ID]; stored.TransactionID == nil || *stored.TransactionID != tx.ID {
+		t.Error("expected quote to be linked to the transaction")
+	}
+
+	// A quote holds its price for one transfer only
+	if _, err := svc.CreateTransfer(ctx, req); err == nil || err.Code != errors.ErrCodeQuoteUsed {
+		t.Errorf("expected QUOTE_USED on reuse, got %v", err)
+	}
+}
+
+func TestCreateTransfer_QuoteMismatchRejected(t *testing.T) {
+	svc, quoteRepo, txRepo := setupQuoteService(t)
+	ctx := context.Background()
+
+	quoteReq := &models.QuoteTransferRequest{
+		SourceWalletID:      uuid.New().String(),
+		DestinationWalletID: uuid.New().String(),
+		Amount:              500,
+		Currency:            sharedModels.USD,
+	}
+	quote, err := svc.QuoteTransfer(ctx, quoteReq, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, err = svc.CreateTransfer(ctx, &models.CreateTransferRequest{
+		SourceWalletID:      quoteReq.SourceWalletID,
+		DestinationWalletID: quoteReq.DestinationWalletID,
+		Amount:              900,
+		Currency:            quoteReq.Currency,
+		Description:         "Different amount",
+		QuoteID:             quote.ID,
+	})
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if quoteRepo.quotes[quote.ID].UsedAt != nil {
+		t.Error("expected mismatched transfer not to use the quote")
+	}
+	if len(txRepo.transactions) != 0 {
+		t.Error("expected no transaction to be created")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// OperationReverseTransaction is the approval operation for large reversals.
+const OperationReverseTransaction = "transaction.reverse"
+
+// DefaultReversalApprovalThreshold is the reversal amount (paise) from which a
+// second admin must approve: ₹10,000.
+const DefaultReversalApprovalThreshold int64 = 1000000
+
+// reversalPayload is the stored request for an approved reversal.
+type reversalPayload struct {
+	TransactionID string `json:"transaction_id"`
+	Reason        string `json:"reason"`
+}
+
+// SetReversalApprovals requires a second admin to approve reversals of
+// threshold paise or more. Smaller reversals run immediately.
+func (s *TransactionService) SetReversalApprovals(approvals *approval.Manager, threshold int64) {
+	approvals.Register(OperationReverseTransaction, s.executeReversal)
+	s.approvals = approvals
+	s.approvalMin = threshold
+}
+
+// Approvals returns the approval manager, or nil if reversals need no approval.
+func (s *TransactionService) Approvals() *approval.Manager {
+	return s.approvals
+}
+
+// RequestReversal reverses a transaction, or stores the reversal for a second
+// admin when its amount reaches the approval threshold. Exactly one of the
+// returned reversal and approval is set on success.
+func (s *TransactionService) RequestReversal(ctx context.Context, transactionID, reason, requestedBy string) (*models.Transaction, *approval.Approval, *errors.Error) {
+	if s.approvals == nil {
+		reversal, err := s.ReverseTransaction(ctx, transactionID, reason)
+		return reversal, nil, err
+	}
+
+	originalTx, err := s.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if revErr := checkReversible(originalTx); revErr != nil {
+		return nil, nil, revErr
+	}
+
+	if originalTx.Amount < s.approvalMin {
+		reversal, err := s.ReverseTransaction(ctx, transactionID, reason)
+		return reversal, nil, err
+	}
+
+	summary := fmt.Sprintf("Reverse %s of %d %s: %s", originalTx.Type, originalTx.Amount, originalTx.Currency, reason)
+	pending, err := s.approvals.Request(ctx, OperationReverseTransaction, transactionID, requestedBy, summary,
+		reversalPayload{TransactionID: transactionID, Reason: reason})
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, pending, nil
+}
+
+// executeReversal runs an approved reversal.
+func (s *TransactionService) executeReversal(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	var payload reversalPayload
+	if err := a.DecodePayload(&payload); err != nil {
+		return nil, err
+	}
+	return s.ReverseTransaction(ctx, payload.TransactionID, payload.Reason)
+}
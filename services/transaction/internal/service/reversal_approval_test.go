@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+func completedTransfer(repo *mockTransactionRepository, amount int64) *models.Transaction {
+	source, dest := uuid.New().String(), uuid.New().String()
+	tx := &models.Transaction{
+		ID:                  uuid.New().String(),
+		Type:                models.TransactionTypeTransfer,
+		Status:              models.TransactionStatusCompleted,
+		SourceWalletID:      &source,
+		DestinationWalletID: &dest,
+		Amount:              amount,
+		Currency:            sharedModels.INR,
+	}
+	repo.transactions[tx.ID] = tx
+	return tx
+}
+
+func countReversals(repo *mockTransactionRepository) int {
+	count := 0
+	for _, tx := range repo.transactions {
+		if tx.Type == models.TransactionTypeReversal {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRequestReversal_BelowThresholdRunsImmediately(t *testing.T) {
+	service, repo := setupTestService()
+	service.SetReversalApprovals(approval.NewManager("transaction", approval.NewMemoryStore()), 100000)
+	original := completedTransfer(repo, 99999)
+
+	reversal, pending, err := service.RequestReversal(context.Background(), original.ID, "customer dispute", "maker")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pending != nil || reversal == nil {
+		t.Fatalf("expected immediate reversal, got approval %v", pending)
+	}
+}
+
+func TestRequestReversal_AtThresholdNeedsApproval(t *testing.T) {
+	service, repo := setupTestService()
+	approvals := approval.NewManager("transaction", approval.NewMemoryStore())
+	service.SetReversalApprovals(approvals, 100000)
+	original := completedTransfer(repo, 100000)
+	ctx := context.Background()
+
+	reversal, pending, err := service.RequestReversal(ctx, original.ID, "customer dispute", "maker")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reversal != nil || pending == nil || pending.Status != approval.StatusPending {
+		t.Fatalf("expected pending approval, got reversal %v", reversal)
+	}
+	if countReversals(repo) != 0 {
+		t.Fatal("expected no reversal before approval")
+	}
+
+	decided, err := approvals.Approve(ctx, pending.ID, "checker", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != approval.StatusApproved {
+		t.Errorf("expected approved, got %s (%v)", decided.Status, decided.Error)
+	}
+	if countReversals(repo) != 1 {
+		t.Errorf("expected one reversal after approval, got %d", countReversals(repo))
+	}
+}
+
+func TestRequestReversal_NotReversibleFailsBeforeApproval(t *testing.T) {
+	service, repo := setupTestService()
+	service.SetReversalApprovals(approval.NewManager("transaction", approval.NewMemoryStore()), 100000)
+	original := completedTransfer(repo, 500000)
+	original.Status = models.TransactionStatusPending
+
+	_, pending, err := service.RequestReversal(context.Background(), original.ID, "customer dispute", "maker")
+	if err == nil || err.Code != errors.ErrCodeTransactionNotReversible {
+		t.Errorf("expected not reversible, got %v", err)
+	}
+	if pending != nil {
+		t.Error("expected no approval for a transaction that cannot be reversed")
+	}
+}
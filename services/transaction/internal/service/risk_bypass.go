@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// Risk bypass defaults.
+const (
+	DefaultRiskBudget     = 2 * time.Second
+	DefaultRiskMinBudget  = 250 * time.Millisecond
+	DefaultRescoreBatch   = 50
+	MaxRiskBypassPageSize = 100
+)
+
+// RiskBypassRepositoryInterface defines queries over transactions that skipped risk evaluation.
+type RiskBypassRepositoryInterface interface {
+	ListBypassed(ctx context.Context, filter *models.RiskBypassFilter) ([]*models.Transaction, *errors.Error)
+	MergeMetadata(ctx context.Context, id string, metadata map[string]string) *errors.Error
+}
+
+// RiskBypassPolicy controls what a transfer does when risk cannot decide in time.
+type RiskBypassPolicy struct {
+	// FailOpenMaxAmount is the largest amount (in paise) allowed to proceed
+	// without a risk decision. Zero keeps every transfer fail-closed.
+	FailOpenMaxAmount int64
+	// Budget caps how long a transfer waits for the risk service.
+	Budget time.Duration
+	// MinBudget is the least time that must remain on the request deadline
+	// for the risk service to be called at all.
+	MinBudget time.Duration
+}
+
+// DefaultRiskBypassPolicy returns a fail-closed policy with default budgets.
+func DefaultRiskBypassPolicy() RiskBypassPolicy {
+	return RiskBypassPolicy{
+		Budget:    DefaultRiskBudget,
+		MinBudget: DefaultRiskMinBudget,
+	}
+}
+
+// allowsFailOpen reports whether a transfer of amount may proceed unevaluated.
+func (p RiskBypassPolicy) allowsFailOpen(amount int64) bool {
+	return p.FailOpenMaxAmount > 0 && amount <= p.FailOpenMaxAmount
+}
+
+// riskContext bounds a risk call by the policy budget. It returns a timeout
+// error instead of calling risk when the request deadline is too close.
+func (p RiskBypassPolicy) riskContext(ctx context.Context) (context.Context, context.CancelFunc, *errors.Error) {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < p.MinBudget {
+		return nil, nil, errors.Timeout("request deadline too close to evaluate risk")
+	}
+	if p.Budget <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.Budget)
+	return ctx, cancel, nil
+}
+
+// riskBypassReason classifies a risk evaluation failure.
+func riskBypassReason(err error) string {
+	if errors.GetErrorCode(err) == errors.ErrCodeTimeout {
+		return models.RiskBypassTimeout
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "deadline exceeded") || strings.Contains(msg, "timeout") {
+		return models.RiskBypassTimeout
+	}
+	return models.RiskBypassUnavailable
+}
+
+// SetRiskBypass configures bypass accounting. Without a repository, bypassed
+// transactions are still marked but cannot be listed or re-scored.
+func (s *TransactionService) SetRiskBypass(repo RiskBypassRepositoryInterface, policy RiskBypassPolicy) {
+	s.bypassRepo = repo
+	s.riskPolicy = policy
+}
+
+// recordRiskBypass marks a transaction as having proceeded without a risk decision.
+func (s *TransactionService) recordRiskBypass(ctx context.Context, transaction *models.Transaction, reason string) {
+	if transaction.Metadata == nil {
+		transaction.Metadata = make(map[string]string)
+	}
+	transaction.Metadata[models.MetaRiskBypassed] = "true"
+	transaction.Metadata[models.MetaRiskBypassReason] = reason
+	transaction.Metadata[models.MetaRiskBypassedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := s.transactionRepo.UpdateMetadata(ctx, transaction.ID, transaction.Metadata); err != nil {
+		s.logger.WithError(err).WithField("transaction_id", transaction.ID).Error("Failed to record risk bypass")
+	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineRiskBypassed, transaction.Status, models.ServiceActor(actorTransaction),
+		map[string]string{"reason": reason})
+
+	s.logger.With(map[string]interface{}{
+		"transaction_id": transaction.ID,
+		"amount":         transaction.Amount,
+		"reason":         reason,
+	}).Warn("Risk evaluation bypassed")
+}
+
+// ListRiskBypassedTransactions returns transactions that proceeded without a risk decision.
+func (s *TransactionService) ListRiskBypassedTransactions(ctx context.Context, filter *models.RiskBypassFilter) ([]*models.Transaction, *errors.Error) {
+	if s.bypassRepo == nil {
+		return nil, errors.Unavailable("risk bypass tracking is not configured")
+	}
+	if filter.Limit <= 0 || filter.Limit > MaxRiskBypassPageSize {
+		filter.Limit = MaxRiskBypassPageSize
+	}
+	return s.bypassRepo.ListBypassed(ctx, filter)
+}
+
+// RescoreBypassedTransactions evaluates up to batchSize unresolved bypassed
+// transactions, oldest first. It stops at the first risk failure since the
+// risk service is presumably still down; the rest are retried next run.
+func (s *TransactionService) RescoreBypassedTransactions(ctx context.Context, batchSize int) (int, *errors.Error) {
+	if s.bypassRepo == nil || s.riskClient == nil {
+		return 0, nil
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRescoreBatch
+	}
+
+	pending, err := s.bypassRepo.ListBypassed(ctx, &models.RiskBypassFilter{
+		Unresolved:  true,
+		OldestFirst: true,
+		Limit:       batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	rescored := 0
+	for _, tx := range pending {
+		result, evalErr := s.riskClient.EvaluateTransaction(ctx, s.buildRiskRequest(ctx, tx))
+		if evalErr != nil {
+			s.logger.WithError(evalErr).WithField("pending", len(pending)-rescored).Debug("Risk still unavailable, deferring re-score")
+			return rescored, nil
+		}
+
+		update := map[string]string{
+			models.MetaRiskRescoredAt:     time.Now().UTC().Format(time.RFC3339),
+			models.MetaRiskRescoreScore:   strconv.Itoa(result.RiskScore),
+			models.MetaRiskRescoreAction:  result.Action,
+			models.MetaRiskRescoreEventID: result.EventID,
+			models.MetaRiskRescoreAllowed: strconv.FormatBool(result.Allowed),
+		}
+		if mergeErr := s.bypassRepo.MergeMetadata(ctx, tx.ID, update); mergeErr != nil {
+			return rescored, mergeErr
+		}
+		rescored++
+		s.recordTimeline(ctx, tx.ID, models.TimelineRiskRescored, tx.Status, models.ServiceActor(actorRisk), map[string]string{
+			"action":     result.Action,
+			"risk_score": strconv.Itoa(result.RiskScore),
+			"event_id":   result.EventID,
+			"allowed":    strconv.FormatBool(result.Allowed),
+		})
+
+		if !result.Allowed || result.Action == "flag" {
+			s.logger.With(map[string]interface{}{
+				"transaction_id": tx.ID,
+				"action":         result.Action,
+				"risk_score":     result.RiskScore,
+				"reason":         result.Reason,
+			}).Warn("Bypassed transaction failed post-hoc risk evaluation")
+
+			if s.eventPublisher != nil {
+				s.eventPublisher.PublishTransactionEvent("transaction.risk_rescore_flagged", tx.ID, map[string]interface{}{
+					"action":        result.Action,
+					"risk_score":    result.RiskScore,
+					"reason":        result.Reason,
+					"amount":        tx.Amount,
+					"status":        string(tx.Status),
+					"bypass_reason": tx.Metadata[models.MetaRiskBypassReason],
+				})
+			}
+		}
+	}
+
+	return rescored, nil
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+// =====================================================================
+// Mock Risk Bypass Repository
+// =====================================================================
+
+type mockRiskBypassRepository struct {
+	transactions []*models.Transaction
+	merged       map[string]map[string]string
+	lastFilter   *models.RiskBypassFilter
+}
+
+func newMockRiskBypassRepository(txs ...*models.Transaction) *mockRiskBypassRepository {
+	return &mockRiskBypassRepository{transactions: txs, merged: make(map[string]map[string]string)}
+}
+
+func (m *mockRiskBypassRepository) ListBypassed(ctx context.Context, filter *models.RiskBypassFilter) ([]*models.Transaction, *errors.Error) {
+	m.lastFilter = filter
+	return m.transactions, nil
+}
+
+func (m *mockRiskBypassRepository) MergeMetadata(ctx context.Context, id string, metadata map[string]string) *errors.Error {
+	m.merged[id] = metadata
+	return nil
+}
+
+// newRiskServer starts a fake risk service responding with the given status and body.
+func newRiskServer(t *testing.T, status int, body string) *RiskClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewRiskClient(server.URL)
+}
+
+func newTransferRequest(amount int64) *models.CreateTransferRequest {
+	return &models.CreateTransferRequest{
+		SourceWalletID:      uuid.New().String(),
+		DestinationWalletID: uuid.New().String(),
+		Amount:              amount,
+		Currency:            sharedModels.INR,
+		Description:         "Test transfer",
+	}
+}
+
+// =====================================================================
+// Risk Bypass Tests
+// =====================================================================
+
+func TestCreateTransfer_RiskNotConfigured_RecordsBypass(t *testing.T) {
+	svc, repo := setupTestService()
+
+	tx, err := svc.CreateTransfer(context.Background(), newTransferRequest(50000))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stored := repo.transactions[tx.ID]
+	if stored.Metadata[models.MetaRiskBypassed] != "true" {
+		t.Errorf("expected risk_bypassed marker, got %v", stored.Metadata)
+	}
+	if stored.Metadata[models.MetaRiskBypassReason] != models.RiskBypassNotConfigured {
+		t.Errorf("expected reason %s, got %s", models.RiskBypassNotConfigured, stored.Metadata[models.MetaRiskBypassReason])
+	}
+}
+
+func TestCreateTransfer_RiskUnavailable_FailOpenUnderThreshold(t *testing.T) {
+	repo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	svc := NewTransactionService(repo, newRiskServer(t, http.StatusServiceUnavailable, `{"success":false}`), nil, nil, nil)
+	svc.SetRiskBypass(nil, RiskBypassPolicy{FailOpenMaxAmount: 100000, Budget: time.Second})
+
+	tx, err := svc.CreateTransfer(context.Background(), newTransferRequest(50000))
+	if err != nil {
+		t.Fatalf("expected transfer to proceed, got %v", err)
+	}
+
+	stored := repo.transactions[tx.ID]
+	if stored.Metadata[models.MetaRiskBypassed] != "true" {
+		t.Errorf("expected risk_bypassed marker, got %v", stored.Metadata)
+	}
+	if stored.Metadata[models.MetaRiskBypassReason] != models.RiskBypassUnavailable {
+		t.Errorf("expected reason %s, got %s", models.RiskBypassUnavailable, stored.Metadata[models.MetaRiskBypassReason])
+	}
+}
+
+func TestCreateTransfer_RiskUnavailable_FailClosedAboveThreshold(t *testing.T) {
+	repo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	svc := NewTransactionService(repo, newRiskServer(t, http.StatusServiceUnavailable, `{"success":false}`), nil, nil, nil)
+	svc.SetRiskBypass(nil, RiskBypassPolicy{FailOpenMaxAmount: 100000, Budget: time.Second})
+
+	_, err := svc.CreateTransfer(context.Background(), newTransferRequest(200000))
+	if err == nil {
+		t.Fatal("expected transfer to be blocked")
+	}
+
+	for _, stored := range repo.transactions {
+		if stored.Status != models.TransactionStatusFailed {
+			t.Errorf("expected failed status, got %s", stored.Status)
+		}
+		if stored.Metadata[models.MetaRiskBypassed] != "" {
+			t.Errorf("blocked transaction should not carry bypass marker")
+		}
+	}
+}
+
+func TestCreateTransfer_DeadlineTooClose_BypassesAsTimeout(t *testing.T) {
+	repo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	svc := NewTransactionService(repo, newRiskServer(t, http.StatusOK, `{"success":true,"data":{"allowed":true}}`), nil, nil, nil)
+	svc.SetRiskBypass(nil, RiskBypassPolicy{FailOpenMaxAmount: 100000, MinBudget: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tx, err := svc.CreateTransfer(ctx, newTransferRequest(50000))
+	if err != nil {
+		t.Fatalf("expected transfer to proceed, got %v", err)
+	}
+	if reason := repo.transactions[tx.ID].Metadata[models.MetaRiskBypassReason]; reason != models.RiskBypassTimeout {
+		t.Errorf("expected reason %s, got %s", models.RiskBypassTimeout, reason)
+	}
+}
+
+func TestRescoreBypassedTransactions_RecordsScores(t *testing.T) {
+	txs := []*models.Transaction{
+		{ID: uuid.New().String(), Type: models.TransactionTypeTransfer, Amount: 1000, Metadata: map[string]string{models.MetaRiskBypassed: "true"}},
+		{ID: uuid.New().String(), Type: models.TransactionTypeTransfer, Amount: 2000, Metadata: map[string]string{models.MetaRiskBypassed: "true"}},
+	}
+	bypassRepo := newMockRiskBypassRepository(txs...)
+	riskClient := newRiskServer(t, http.StatusOK, `{"success":true,"data":{"allowed":true,"action":"allow","risk_score":12,"event_id":"evt-1"}}`)
+
+	svc := NewTransactionService(&mockTransactionRepository{transactions: make(map[string]*models.Transaction)}, riskClient, nil, nil, nil)
+	svc.SetRiskBypass(bypassRepo, DefaultRiskBypassPolicy())
+
+	rescored, err := svc.RescoreBypassedTransactions(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rescored != 2 {
+		t.Errorf("expected 2 rescored, got %d", rescored)
+	}
+	if !bypassRepo.lastFilter.Unresolved || !bypassRepo.lastFilter.OldestFirst {
+		t.Errorf("expected unresolved oldest-first filter, got %+v", bypassRepo.lastFilter)
+	}
+
+	update := bypassRepo.merged[txs[0].ID]
+	if update[models.MetaRiskRescoreScore] != "12" || update[models.MetaRiskRescoreAction] != "allow" {
+		t.Errorf("unexpected rescore metadata: %v", update)
+	}
+	if update[models.MetaRiskRescoredAt] == "" {
+		t.Error("expected risk_rescored_at to be set")
+	}
+}
+
+func TestRescoreBypassedTransactions_DefersWhileRiskDown(t *testing.T) {
+	bypassRepo := newMockRiskBypassRepository(&models.Transaction{ID: uuid.New().String(), Amount: 1000})
+	riskClient := newRiskServer(t, http.StatusServiceUnavailable, `{"success":false}`)
+
+	svc := NewTransactionService(&mockTransactionRepository{transactions: make(map[string]*models.Transaction)}, riskClient, nil, nil, nil)
+	svc.SetRiskBypass(bypassRepo, DefaultRiskBypassPolicy())
+
+	rescored, err := svc.RescoreBypassedTransactions(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rescored != 0 || len(bypassRepo.merged) != 0 {
+		t.Errorf("expected nothing rescored while risk is down, got %d", rescored)
+	}
+}
@@ -19,6 +19,13 @@ func NewRiskClient(baseURL string) *RiskClient {
 	}
 }
 
+// NewRiskClientWithSecret creates a Risk client with internal service authentication.
+func NewRiskClientWithSecret(baseURL, internalSecret string) *RiskClient {
+	return &RiskClient{
+		BaseClient: clients.NewInternalClient(baseURL, clients.ShortTimeout, internalSecret),
+	}
+}
+
 // RiskEvaluationRequest represents a risk evaluation request.
 type RiskEvaluationRequest struct {
 	TransactionID   string `json:"transaction_id"`
@@ -28,6 +35,8 @@ type RiskEvaluationRequest struct {
 	TransactionType string `json:"transaction_type"`
 	FromWalletID    string `json:"from_wallet_id,omitempty"`
 	ToWalletID      string `json:"to_wallet_id,omitempty"`
+	ClientIP        string `json:"client_ip,omitempty"`
+	ClientCountry   string `json:"client_country,omitempty"`
 }
 
 // RiskEvaluationResult represents the risk evaluation result.
@@ -48,3 +57,33 @@ func (c *RiskClient) EvaluateTransaction(ctx context.Context, req *RiskEvaluatio
 	}
 	return &result, nil
 }
+
+// RiskBatchEvaluationItem is the outcome of one transaction in a batch.
+type RiskBatchEvaluationItem struct {
+	TransactionID string                `json:"transaction_id"`
+	Result        *RiskEvaluationResult `json:"result,omitempty"`
+	Error         *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"` // Set when the transaction was not evaluated
+}
+
+// RiskBatchEvaluationResult represents the results of a batch evaluation, in request order.
+type RiskBatchEvaluationResult struct {
+	Results []RiskBatchEvaluationItem `json:"results"`
+	Allowed int                       `json:"allowed"`
+	Flagged int                       `json:"flagged"`
+	Blocked int                       `json:"blocked"`
+	Failed  int                       `json:"failed"`
+}
+
+// EvaluateBatch evaluates several transactions in one call, in order, so each
+// one's velocity and daily limit checks include the ones before it.
+func (c *RiskClient) EvaluateBatch(ctx context.Context, reqs []*RiskEvaluationRequest) (*RiskBatchEvaluationResult, *errors.Error) {
+	body := map[string]any{"evaluations": reqs}
+	var result RiskBatchEvaluationResult
+	if err := c.Post(ctx, "/internal/v1/evaluate/batch", body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+)
+
+// Stuck pending transfer defaults.
+const (
+	DefaultStuckPendingThreshold = 15 * time.Minute
+	stuckPendingBatch            = 100
+)
+
+// StuckPendingRepositoryInterface finds and fails transfers left pending too long.
+type StuckPendingRepositoryInterface interface {
+	ListStuckPending(ctx context.Context, createdBefore time.Time, limit int) ([]string, *errors.Error)
+	FailPending(ctx context.Context, id, reason string) (bool, *errors.Error)
+}
+
+// stuckPendingReaper holds the stuck pending transfer configuration.
+type stuckPendingReaper struct {
+	repo      StuckPendingRepositoryInterface
+	threshold time.Duration
+}
+
+// StuckPendingResult counts what one reaper run did with stuck transfers.
+type StuckPendingResult struct {
+	Completed int // Funds had moved; the transfer was completed
+	Failed    int // Funds never moved; the transfer was failed
+	Skipped   int // The outcome could not be determined; retried next run
+}
+
+// SetStuckPendingReaper settles transfers still pending threshold after they
+// were created. A transfer can be left pending either before the wallet
+// service moved its funds, or after, when marking it completed failed. The
+// reaper asks the wallet service and the ledger which one it was: a transfer
+// whose funds moved is completed, and only one neither of them knows is failed.
+func (s *TransactionService) SetStuckPendingReaper(repo StuckPendingRepositoryInterface, threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = DefaultStuckPendingThreshold
+	}
+	s.stuckPending = &stuckPendingReaper{repo: repo, threshold: threshold}
+}
+
+// ReapStuckPending settles up to one batch of stuck pending transfers, oldest
+// first. Failed transfers get a failed timeline event and a
+// transaction.failed event; completed ones are finished like a processed
+// transfer.
+func (s *TransactionService) ReapStuckPending(ctx context.Context) (*StuckPendingResult, *errors.Error) {
+	result := &StuckPendingResult{}
+	if s.stuckPending == nil {
+		return result, nil
+	}
+
+	ids, err := s.stuckPending.repo.ListStuckPending(ctx, time.Now().Add(-s.stuckPending.threshold), stuckPendingBatch)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("stuck pending for over %s", s.stuckPending.threshold)
+	for _, id := range ids {
+		log := s.logger.WithField("transaction_id", id)
+
+		transaction, getErr := s.transactionRepo.GetByID(ctx, id)
+		if getErr != nil {
+			log.WithError(getErr).Warn("Failed to load stuck pending transfer")
+			result.Skipped++
+			continue
+		}
+
+		moved, posted, outcomeErr := s.transferOutcome(ctx, id)
+		if outcomeErr != nil {
+			log.WithError(outcomeErr).Warn("Could not determine whether stuck transfer moved funds")
+			result.Skipped++
+			continue
+		}
+
+		if moved || posted {
+			if completeErr := s.completeTransfer(ctx, transaction, !posted); completeErr != nil {
+				log.WithError(completeErr).Warn("Failed to complete stuck transfer whose funds moved")
+				result.Skipped++
+				continue
+			}
+			log.Warn("Completed stuck pending transfer whose funds had moved")
+			result.Completed++
+			continue
+		}
+
+		failed, failErr := s.stuckPending.repo.FailPending(ctx, id, reason)
+		if failErr != nil {
+			log.WithError(failErr).Warn("Failed to fail stuck pending transfer")
+			result.Skipped++
+			continue
+		}
+		if !failed {
+			continue // Left pending in the meantime
+		}
+
+		s.recordFailed(ctx, id, models.ServiceActor(actorTransaction), reason)
+		s.settlePaymentIntent(ctx, transaction, models.TransactionStatusFailed, reason)
+		s.publishTransactionEvent(events.TransactionFailed{
+			TransactionID:       id,
+			Type:                string(transaction.Type),
+			Status:              string(models.TransactionStatusFailed),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			SourceWalletID:      transaction.SourceWalletID,
+			DestinationWalletID: transaction.DestinationWalletID,
+			FailureReason:       reason,
+		}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+		log.WithField("created_at", transaction.CreatedAt.Time).Warn("Failed stuck pending transfer")
+		result.Failed++
+	}
+	return result, nil
+}
+
+// transferOutcome reports whether the wallet service has moved a transfer's
+// funds and whether the ledger holds an entry for it.
+func (s *TransactionService) transferOutcome(ctx context.Context, transactionID string) (bool, bool, *errors.Error) {
+	if s.walletClient == nil || s.ledgerClient == nil {
+		return false, false, errors.Unavailable("wallet and ledger clients are required to settle stuck transfers")
+	}
+
+	moved, err := s.walletClient.IsTransferProcessed(ctx, transactionID)
+	if err != nil {
+		return false, false, err
+	}
+	entries, err := s.ledgerClient.GetJournalEntriesByReference(ctx, "transaction", transactionID)
+	if err != nil {
+		return false, false, err
+	}
+	return moved, len(entries) > 0, nil
+}
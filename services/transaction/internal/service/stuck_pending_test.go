@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockStuckPendingRepository struct {
+	txRepo *mockTransactionRepository
+	cutoff time.Time
+}
+
+func (m *mockStuckPendingRepository) ListStuckPending(ctx context.Context, createdBefore time.Time, limit int) ([]string, *errors.Error) {
+	m.cutoff = createdBefore
+	var ids []string
+	for _, tx := range m.txRepo.transactions {
+		if len(ids) == limit {
+			break
+		}
+		if tx.Type == models.TransactionTypeTransfer && tx.Status == models.TransactionStatusPending && tx.CreatedAt.Time.Before(createdBefore) {
+			ids = append(ids, tx.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *mockStuckPendingRepository) FailPending(ctx context.Context, id, reason string) (bool, *errors.Error) {
+	tx, ok := m.txRepo.transactions[id]
+	if !ok || tx.Status != models.TransactionStatusPending {
+		return false, nil
+	}
+	tx.Status = models.TransactionStatusFailed
+	tx.FailureReason = &reason
+	return true, nil
+}
+
+// stuckTransferServer answers the wallet service's processed-transfer lookup
+// and the ledger's journal entry endpoints. It records the entries posted.
+type stuckTransferServer struct {
+	mu        sync.Mutex
+	moved     map[string]bool // Transfers the wallet service processed
+	posted    map[string]bool // Transfers the ledger holds an entry for
+	broken    map[string]bool // Transfers whose lookup fails
+	newPosted []string
+}
+
+func (s *stuckTransferServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/internal/v1/wallets/transfers/"):
+		id := strings.TrimPrefix(r.URL.Path, "/internal/v1/wallets/transfers/")
+		if s.broken[id] {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":"SERVICE_UNAVAILABLE","message":"down"}}`))
+			return
+		}
+		processed := "false"
+		if s.moved[id] {
+			processed = "true"
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":{"transaction_id":"` + id + `","processed":` + processed + `}}`))
+	case r.URL.Path == "/internal/v1/journal-entries/by-reference":
+		id := r.URL.Query().Get("reference_id")
+		if s.posted[id] {
+			_, _ = w.Write([]byte(`{"success":true,"data":[{"id":"je-` + id + `","status":"posted"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true,"data":[]}`))
+	case strings.HasSuffix(r.URL.Path, "/info"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/internal/v1/wallets/"), "/info")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"` + id + `","user_id":"u-` + id + `","ledger_account_id":"acct-` + id + `"}}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/journal-entries":
+		s.newPosted = append(s.newPosted, "je-new")
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"je-new","status":"draft"}}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v1/journal-entries/je-new/post":
+		_, _ = w.Write([]byte(`{"success":true,"data":{"id":"je-new","status":"posted"}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReapStuckPending(t *testing.T) {
+	old := sharedModels.Timestamp{Time: time.Now().Add(-time.Hour)}
+	recent := sharedModels.Timestamp{Time: time.Now().Add(-time.Minute)}
+	source, destination := "wallet-a", "wallet-b"
+	stuck := func(id string, createdAt sharedModels.Timestamp) *models.Transaction {
+		return &models.Transaction{
+			ID: id, Type: models.TransactionTypeTransfer, Status: models.TransactionStatusPending, Amount: 500,
+			SourceWalletID: &source, DestinationWalletID: &destination, CreatedAt: createdAt,
+		}
+	}
+
+	txRepo := &mockTransactionRepository{transactions: map[string]*models.Transaction{
+		"never-moved":    stuck("never-moved", old),
+		"moved-posted":   stuck("moved-posted", old),
+		"moved-unposted": stuck("moved-unposted", old),
+		"unknown":        stuck("unknown", old),
+		"recent":         stuck("recent", recent),
+		"upi":            {ID: "upi", Type: models.TransactionTypeDeposit, Status: models.TransactionStatusPending, CreatedAt: old},
+	}}
+	fake := &stuckTransferServer{
+		moved:  map[string]bool{"moved-posted": true, "moved-unposted": true},
+		posted: map[string]bool{"moved-posted": true},
+		broken: map[string]bool{"unknown": true},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	repo := &mockStuckPendingRepository{txRepo: txRepo}
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), NewLedgerClient(server.URL), nil)
+	svc.SetStuckPendingReaper(repo, 15*time.Minute)
+
+	result, err := svc.ReapStuckPending(context.Background())
+	if err != nil {
+		t.Fatalf("ReapStuckPending() error = %v", err)
+	}
+	if result.Failed != 1 || result.Completed != 2 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 1 failed, 2 completed, 1 skipped", *result)
+	}
+	if since := time.Since(repo.cutoff); since < 15*time.Minute || since > 16*time.Minute {
+		t.Errorf("cutoff %s ago, want the 15m threshold", since)
+	}
+
+	failed := txRepo.transactions["never-moved"]
+	if failed.Status != models.TransactionStatusFailed || failed.FailureReason == nil || !strings.Contains(*failed.FailureReason, "stuck pending") {
+		t.Errorf("never-moved = %s %v, want failed as stuck pending", failed.Status, failed.FailureReason)
+	}
+	for _, id := range []string{"moved-posted", "moved-unposted"} {
+		if status := txRepo.transactions[id].Status; status != models.TransactionStatusCompleted {
+			t.Errorf("%s status = %s, want completed since its funds moved", id, status)
+		}
+	}
+	if len(fake.newPosted) != 1 {
+		t.Errorf("posted %d ledger entries, want 1 for the transfer without one", len(fake.newPosted))
+	}
+	for _, id := range []string{"unknown", "recent", "upi"} {
+		if status := txRepo.transactions[id].Status; status != models.TransactionStatusPending {
+			t.Errorf("%s status = %s, want it left pending", id, status)
+		}
+	}
+}
+
+func TestReapStuckPending_NotConfigured(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: make(map[string]*models.Transaction)}, nil, nil, nil, nil)
+	result, err := svc.ReapStuckPending(context.Background())
+	if err != nil || result.Completed+result.Failed+result.Skipped != 0 {
+		t.Errorf("ReapStuckPending() = %+v, %v, want nothing done without a reaper", result, err)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// StuckTransferRepositoryInterface creates and removes backdated pending transfers.
+type StuckTransferRepositoryInterface interface {
+	Create(ctx context.Context, amount int64, createdAt time.Time) (string, *errors.Error)
+	Delete(ctx context.Context, id string) (bool, *errors.Error)
+}
+
+// SetStuckTransfers enables stuck transfers for simulation anomalies. They
+// leave transfers the wallet service never saw, so they must only be enabled
+// outside production.
+func (s *TransactionService) SetStuckTransfers(repo StuckTransferRepositoryInterface) {
+	s.stuckTransfers = repo
+}
+
+// CreateStuckTransfer records a pending transfer backdated a minute past the
+// stuck pending threshold, so the next reaper run should fail it.
+func (s *TransactionService) CreateStuckTransfer(ctx context.Context, amount int64) (*models.Transaction, *errors.Error) {
+	if s.stuckTransfers == nil {
+		return nil, errors.Forbidden("stuck transfers are disabled")
+	}
+
+	threshold := DefaultStuckPendingThreshold
+	if s.stuckPending != nil {
+		threshold = s.stuckPending.threshold
+	}
+
+	id, err := s.stuckTransfers.Create(ctx, amount, time.Now().Add(-threshold-time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithField("transaction_id", id).Info("Created stuck transfer for a simulation anomaly")
+	return s.transactionRepo.GetByID(ctx, id)
+}
+
+// DeleteStuckTransfer removes a transfer created by CreateStuckTransfer.
+func (s *TransactionService) DeleteStuckTransfer(ctx context.Context, id string) *errors.Error {
+	if s.stuckTransfers == nil {
+		return errors.Forbidden("stuck transfers are disabled")
+	}
+
+	deleted, err := s.stuckTransfers.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.NotFoundWithID("stuck transfer", id)
+	}
+	return nil
+}
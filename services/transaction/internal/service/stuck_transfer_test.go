@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockStuckTransferRepository struct {
+	txRepo *mockTransactionRepository
+}
+
+func (m *mockStuckTransferRepository) Create(ctx context.Context, amount int64, createdAt time.Time) (string, *errors.Error) {
+	source, destination := "wallet-a", "wallet-b"
+	tx := &models.Transaction{
+		ID: "stuck", Type: models.TransactionTypeTransfer, Status: models.TransactionStatusPending, Amount: amount,
+		SourceWalletID: &source, DestinationWalletID: &destination, CreatedAt: sharedModels.Timestamp{Time: createdAt},
+	}
+	m.txRepo.transactions[tx.ID] = tx
+	return tx.ID, nil
+}
+
+func (m *mockStuckTransferRepository) Delete(ctx context.Context, id string) (bool, *errors.Error) {
+	if _, ok := m.txRepo.transactions[id]; !ok {
+		return false, nil
+	}
+	delete(m.txRepo.transactions, id)
+	return true, nil
+}
+
+func TestCreateStuckTransfer_ReapedAsFailed(t *testing.T) {
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	server := httptest.NewServer(&stuckTransferServer{})
+	t.Cleanup(server.Close)
+
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), NewLedgerClient(server.URL), nil)
+	svc.SetStuckPendingReaper(&mockStuckPendingRepository{txRepo: txRepo}, 5*time.Minute)
+	svc.SetStuckTransfers(&mockStuckTransferRepository{txRepo: txRepo})
+
+	transaction, err := svc.CreateStuckTransfer(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("CreateStuckTransfer() error = %v", err)
+	}
+	if age := time.Since(transaction.CreatedAt.Time); age < 6*time.Minute || age > 7*time.Minute {
+		t.Errorf("transfer created %s ago, want a minute past the 5m threshold", age)
+	}
+
+	result, err := svc.ReapStuckPending(context.Background())
+	if err != nil {
+		t.Fatalf("ReapStuckPending() error = %v", err)
+	}
+	if result.Failed != 1 {
+		t.Errorf("result = %+v, want the stuck transfer failed", *result)
+	}
+
+	if err := svc.DeleteStuckTransfer(context.Background(), transaction.ID); err != nil {
+		t.Fatalf("DeleteStuckTransfer() error = %v", err)
+	}
+	if err := svc.DeleteStuckTransfer(context.Background(), transaction.ID); err == nil || !errors.IsNotFound(err) {
+		t.Errorf("second DeleteStuckTransfer() error = %v, want not found", err)
+	}
+}
+
+func TestCreateStuckTransfer_Disabled(t *testing.T) {
+	svc := NewTransactionService(&mockTransactionRepository{transactions: make(map[string]*models.Transaction)}, nil, nil, nil, nil)
+
+	if _, err := svc.CreateStuckTransfer(context.Background(), 100); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("CreateStuckTransfer() error = %v, want forbidden", err)
+	}
+}
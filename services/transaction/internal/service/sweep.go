@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Auto-sweep defaults.
+const (
+	// DefaultSweepCooldown is the least time between two runs of one rule, so
+	// rules feeding each other's wallets cannot ping-pong money.
+	DefaultSweepCooldown = time.Minute
+	// MaxSweepRulesPerWallet caps the rules on one wallet.
+	MaxSweepRulesPerWallet = 10
+	// DefaultSweepHistoryLimit is the number of executions listed by default.
+	DefaultSweepHistoryLimit = 50
+	// sweepQueueSize bounds the wallets waiting for evaluation.
+	sweepQueueSize = 1000
+)
+
+// SweepRepositoryInterface defines the interface for sweep rule storage.
+type SweepRepositoryInterface interface {
+	Create(ctx context.Context, rule *models.SweepRule) *errors.Error
+	GetByID(ctx context.Context, walletID, id string) (*models.SweepRule, *errors.Error)
+	Update(ctx context.Context, rule *models.SweepRule) *errors.Error
+	Delete(ctx context.Context, walletID, id string) *errors.Error
+	ListByWallet(ctx context.Context, walletID string, enabledOnly bool) ([]*models.SweepRule, *errors.Error)
+	ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error)
+	Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error)
+	RecordExecution(ctx context.Context, exec *models.SweepExecution) *errors.Error
+	ListExecutions(ctx context.Context, walletID, ruleID string, limit int) ([]*models.SweepExecution, *errors.Error)
+}
+
+// sweepQueue holds wallets whose balance changed until the sweep worker
+// evaluates their rules. A wallet already waiting is not queued twice.
+type sweepQueue struct {
+	ch      chan string
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+func newSweepQueue(size int) *sweepQueue {
+	return &sweepQueue{ch: make(chan string, size), pending: make(map[string]bool)}
+}
+
+// push queues a wallet without blocking. A wallet dropped because the queue
+// is full is picked up by the periodic scan.
+func (q *sweepQueue) push(walletID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending[walletID] {
+		return
+	}
+	select {
+	case q.ch <- walletID:
+		q.pending[walletID] = true
+	default:
+	}
+}
+
+func (q *sweepQueue) done(walletID string) {
+	q.mu.Lock()
+	delete(q.pending, walletID)
+	q.mu.Unlock()
+}
+
+// SetSweepRules enables auto-sweep rules. Cooldown is the least time between
+// two runs of one rule; zero uses DefaultSweepCooldown.
+func (s *TransactionService) SetSweepRules(repo SweepRepositoryInterface, cooldown time.Duration) {
+	if cooldown <= 0 {
+		cooldown = DefaultSweepCooldown
+	}
+	s.sweepRepo = repo
+	s.sweepCooldown = cooldown
+	s.sweepQueue = newSweepQueue(sweepQueueSize)
+}
+
+// ========================================================================
+// Rule Management
+// ========================================================================
+
+// CreateSweepRule adds a sweep rule to a wallet. The caller must already be
+// allowed to move money in both wallets.
+func (s *TransactionService) CreateSweepRule(ctx context.Context, walletID, userID string, req *models.SweepRuleRequest) (*models.SweepRule, *errors.Error) {
+	if s.sweepRepo == nil {
+		return nil, errors.Unavailable("auto-sweep rules are not enabled")
+	}
+
+	rule := &models.SweepRule{WalletID: walletID, CreatedBy: userID}
+	if err := s.applySweepRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.sweepRepo.ListByWallet(ctx, walletID, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxSweepRulesPerWallet {
+		return nil, errors.Validation(fmt.Sprintf("a wallet can have at most %d sweep rules", MaxSweepRulesPerWallet))
+	}
+
+	if err := s.sweepRepo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	// The balance may already be past the new threshold
+	s.queueSweep(walletID)
+	return rule, nil
+}
+
+// UpdateSweepRule replaces a wallet's sweep rule.
+func (s *TransactionService) UpdateSweepRule(ctx context.Context, walletID, id string, req *models.SweepRuleRequest) (*models.SweepRule, *errors.Error) {
+	if s.sweepRepo == nil {
+		return nil, errors.Unavailable("auto-sweep rules are not enabled")
+	}
+
+	rule, err := s.sweepRepo.GetByID(ctx, walletID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applySweepRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.sweepRepo.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.queueSweep(walletID)
+	return rule, nil
+}
+
+// DeleteSweepRule removes a wallet's sweep rule with its history.
+func (s *TransactionService) DeleteSweepRule(ctx context.Context, walletID, id string) *errors.Error {
+	if s.sweepRepo == nil {
+		return errors.Unavailable("auto-sweep rules are not enabled")
+	}
+	return s.sweepRepo.Delete(ctx, walletID, id)
+}
+
+// ListSweepRules returns a wallet's sweep rules.
+func (s *TransactionService) ListSweepRules(ctx context.Context, walletID string) ([]*models.SweepRule, *errors.Error) {
+	if s.sweepRepo == nil {
+		return nil, errors.Unavailable("auto-sweep rules are not enabled")
+	}
+	return s.sweepRepo.ListByWallet(ctx, walletID, false)
+}
+
+// ListSweepExecutions returns a wallet's recent sweeps, newest first,
+// optionally for one rule.
+func (s *TransactionService) ListSweepExecutions(ctx context.Context, walletID, ruleID string, limit int) ([]*models.SweepExecution, *errors.Error) {
+	if s.sweepRepo == nil {
+		return nil, errors.Unavailable("auto-sweep rules are not enabled")
+	}
+	if limit <= 0 {
+		limit = DefaultSweepHistoryLimit
+	}
+	return s.sweepRepo.ListExecutions(ctx, walletID, ruleID, limit)
+}
+
+// applySweepRequest validates a request against the wallets and copies it
+// onto rule.
+func (s *TransactionService) applySweepRequest(ctx context.Context, rule *models.SweepRule, req *models.SweepRuleRequest) *errors.Error {
+	if req.CounterpartWalletID == rule.WalletID {
+		return errors.New(errors.ErrCodeTransferSameWallet, "counterpart wallet must be a different wallet")
+	}
+
+	target := req.Threshold
+	if req.TargetBalance != nil {
+		target = *req.TargetBalance
+	}
+	switch {
+	case target < 0:
+		return errors.Validation("target_balance must not be negative")
+	case req.Direction == models.SweepOut && target > req.Threshold:
+		return errors.Validation("target_balance of a sweep_out rule must not exceed the threshold")
+	case req.Direction == models.SweepTopUp && target < req.Threshold:
+		return errors.Validation("target_balance of a top_up rule must not be below the threshold")
+	}
+
+	// Sweeps are plain transfers, so both wallets must share a currency
+	if s.walletClient != nil {
+		wallet, err := s.walletClient.GetWalletInfo(ctx, rule.WalletID)
+		if err != nil {
+			return err
+		}
+		counterpart, err := s.walletClient.GetWalletInfo(ctx, req.CounterpartWalletID)
+		if err != nil {
+			return err
+		}
+		if wallet.Currency != counterpart.Currency {
+			return errors.Validation("counterpart wallet must hold the same currency")
+		}
+	}
+
+	rule.CounterpartWalletID = req.CounterpartWalletID
+	rule.Direction = req.Direction
+	rule.Threshold = req.Threshold
+	rule.TargetBalance = target
+	rule.MinAmount = req.MinAmount
+	rule.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
+
+// ========================================================================
+// Evaluation
+// ========================================================================
+
+// queueSweep asks the sweep worker to evaluate a wallet's rules.
+func (s *TransactionService) queueSweep(walletIDs ...string) {
+	if s.sweepQueue == nil {
+		return
+	}
+	for _, walletID := range walletIDs {
+		if walletID != "" {
+			s.sweepQueue.push(walletID)
+		}
+	}
+}
+
+// queueSweepAfter queues the wallets a completed transaction changed the
+// balance of. Sweep transfers are not followed, so one sweep cannot set off
+// another.
+func (s *TransactionService) queueSweepAfter(transaction *models.Transaction) {
+	if s.sweepQueue == nil || transaction.Metadata[models.MetaSweepRuleID] != "" {
+		return
+	}
+	for _, walletID := range []*string{transaction.SourceWalletID, transaction.DestinationWalletID} {
+		if walletID != nil {
+			s.queueSweep(*walletID)
+		}
+	}
+}
+
+// RunSweepWorker evaluates the rules of each wallet whose balance changed
+// until ctx is cancelled.
+func (s *TransactionService) RunSweepWorker(ctx context.Context) {
+	if s.sweepQueue == nil {
+		return
+	}
+	for {
+		select {
+		case walletID := <-s.sweepQueue.ch:
+			s.sweepQueue.done(walletID)
+			if _, err := s.EvaluateSweepRules(ctx, walletID); err != nil {
+				s.logger.WithError(err).WithField("wallet_id", walletID).Warn("Sweep evaluation failed")
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// SweepAllWallets evaluates every wallet with an enabled rule. It catches
+// balance changes made outside this service, such as wallet-side credits.
+func (s *TransactionService) SweepAllWallets(ctx context.Context) (int, *errors.Error) {
+	if s.sweepRepo == nil {
+		return 0, nil
+	}
+
+	walletIDs, err := s.sweepRepo.ListWalletsWithEnabledRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	for _, walletID := range walletIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		n, evalErr := s.EvaluateSweepRules(ctx, walletID)
+		if evalErr != nil {
+			s.logger.WithError(evalErr).WithField("wallet_id", walletID).Warn("Sweep evaluation failed")
+			continue
+		}
+		executed += n
+	}
+	return executed, nil
+}
+
+// EvaluateSweepRules runs a wallet's enabled rules, oldest first, against its
+// available balance and returns how many sweeps it attempted. Each sweep sees
+// the balance left by the ones before it.
+func (s *TransactionService) EvaluateSweepRules(ctx context.Context, walletID string) (int, *errors.Error) {
+	if s.sweepRepo == nil || s.walletClient == nil {
+		return 0, nil
+	}
+
+	rules, err := s.sweepRepo.ListByWallet(ctx, walletID, true)
+	if err != nil || len(rules) == 0 {
+		return 0, err
+	}
+
+	wallet, err := s.walletClient.GetWalletInfo(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+	if wallet.Status != "active" {
+		return 0, nil
+	}
+
+	balance := wallet.AvailableBalance
+	attempted := 0
+	for _, rule := range rules {
+		amount := rule.SweepAmount(balance)
+		if amount == 0 {
+			continue
+		}
+
+		// A top-up moves no more than the counterpart wallet holds
+		if rule.Direction == models.SweepTopUp {
+			counterpart, infoErr := s.walletClient.GetWalletInfo(ctx, rule.CounterpartWalletID)
+			if infoErr != nil {
+				s.logger.WithError(infoErr).WithField("rule_id", rule.ID).Warn("Failed to read top-up source wallet")
+				continue
+			}
+			if counterpart.AvailableBalance < amount {
+				amount = counterpart.AvailableBalance
+			}
+			if amount <= 0 || amount < rule.MinAmount {
+				continue
+			}
+		}
+
+		now := time.Now()
+		claimed, claimErr := s.sweepRepo.Claim(ctx, rule.ID, now, now.Add(-s.sweepCooldown))
+		if claimErr != nil {
+			return attempted, claimErr
+		}
+		if !claimed {
+			continue
+		}
+
+		attempted++
+		if s.executeSweep(ctx, rule, wallet.Currency, balance, amount) {
+			if rule.Direction == models.SweepOut {
+				balance -= amount
+			} else {
+				balance += amount
+			}
+		}
+	}
+	return attempted, nil
+}
+
+// executeSweep makes a rule's transfer and records the outcome. Returns
+// whether the transfer completed.
+func (s *TransactionService) executeSweep(ctx context.Context, rule *models.SweepRule, currency string, balance, amount int64) bool {
+	source, destination := rule.Endpoints()
+	metadata, _ := json.Marshal(map[string]string{models.MetaSweepRuleID: rule.ID})
+	description := "Auto-sweep to linked wallet"
+	if rule.Direction == models.SweepTopUp {
+		description = "Auto top-up from linked wallet"
+	}
+
+	exec := &models.SweepExecution{
+		RuleID:        rule.ID,
+		WalletID:      rule.WalletID,
+		Direction:     rule.Direction,
+		BalanceBefore: balance,
+		Amount:        amount,
+		Status:        models.SweepExecutionCompleted,
+	}
+
+	transaction, err := s.CreateTransfer(ctx, &models.CreateTransferRequest{
+		SourceWalletID:      source,
+		DestinationWalletID: destination,
+		Amount:              amount,
+		Currency:            sharedModels.Currency(currency),
+		Description:         description,
+		MetadataRaw:         metadata,
+	})
+	if transaction != nil {
+		exec.TransactionID = &transaction.ID
+	}
+	switch {
+	case err != nil:
+		reason := err.Message
+		exec.Status, exec.FailureReason = models.SweepExecutionFailed, &reason
+	case transaction.Status != models.TransactionStatusCompleted:
+		reason := "transfer did not complete"
+		if transaction.FailureReason != nil {
+			reason = *transaction.FailureReason
+		}
+		exec.Status, exec.FailureReason = models.SweepExecutionFailed, &reason
+	}
+
+	if recordErr := s.sweepRepo.RecordExecution(ctx, exec); recordErr != nil {
+		s.logger.WithError(recordErr).WithField("rule_id", rule.ID).Error("Failed to record sweep execution")
+	}
+
+	s.logger.With(map[string]interface{}{
+		"rule_id":   rule.ID,
+		"wallet_id": rule.WalletID,
+		"direction": string(rule.Direction),
+		"amount":    amount,
+		"status":    string(exec.Status),
+	}).Info("Sweep rule executed")
+	return exec.Status == models.SweepExecutionCompleted
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/google/uuid"
+)
+
+type mockSweepRepository struct {
+	rules      []*models.SweepRule
+	executions []*models.SweepExecution
+}
+
+func (m *mockSweepRepository) Create(ctx context.Context, rule *models.SweepRule) *errors.Error {
+	rule.ID = uuid.New().String()
+	rule.CreatedAt = sharedModels.Now()
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *mockSweepRepository) GetByID(ctx context.Context, walletID, id string) (*models.SweepRule, *errors.Error) {
+	for _, rule := range m.rules {
+		if rule.ID == id && rule.WalletID == walletID {
+			return rule, nil
+		}
+	}
+	return nil, errors.NotFoundWithID("sweep rule", id)
+}
+
+func (m *mockSweepRepository) Update(ctx context.Context, rule *models.SweepRule) *errors.Error {
+	return nil
+}
+
+func (m *mockSweepRepository) Delete(ctx context.Context, walletID, id string) *errors.Error {
+	return nil
+}
+
+func (m *mockSweepRepository) ListByWallet(ctx context.Context, walletID string, enabledOnly bool) ([]*models.SweepRule, *errors.Error) {
+	var rules []*models.SweepRule
+	for _, rule := range m.rules {
+		if rule.WalletID == walletID && (rule.Enabled || !enabledOnly) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *mockSweepRepository) ListWalletsWithEnabledRules(ctx context.Context) ([]string, *errors.Error) {
+	return nil, nil
+}
+
+func (m *mockSweepRepository) Claim(ctx context.Context, id string, now, notBefore time.Time) (bool, *errors.Error) {
+	for _, rule := range m.rules {
+		if rule.ID == id {
+			if rule.LastExecutedAt != nil && rule.LastExecutedAt.After(sharedModels.NewTimestamp(notBefore)) {
+				return false, nil
+			}
+			at := sharedModels.NewTimestamp(now)
+			rule.LastExecutedAt = &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockSweepRepository) RecordExecution(ctx context.Context, exec *models.SweepExecution) *errors.Error {
+	m.executions = append(m.executions, exec)
+	return nil
+}
+
+func (m *mockSweepRepository) ListExecutions(ctx context.Context, walletID, ruleID string, limit int) ([]*models.SweepExecution, *errors.Error) {
+	return m.executions, nil
+}
+
+// fakeWallets serves wallet info and executes transfers and deposits against
+// in-memory balances.
+type fakeWallets struct {
+	mu       sync.Mutex
+	balances map[string]int64
+}
+
+func (f *fakeWallets) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/info"):
+		id := strings.Split(r.URL.Path, "/")[4]
+		_, _ = fmt.Fprintf(w, `{"success":true,"data":{"id":%q,"user_id":"u","status":"active","currency":"INR","available_balance":%d}}`, id, f.balances[id])
+	case r.URL.Path == "/internal/v1/wallets/transfer":
+		var req TransferRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if f.balances[req.SourceWalletID] < req.Amount {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":"INSUFFICIENT_FUNDS","message":"insufficient funds"}}`))
+			return
+		}
+		f.balances[req.SourceWalletID] -= req.Amount
+		f.balances[req.DestinationWalletID] += req.Amount
+		_, _ = w.Write([]byte(`{"success":true,"data":{}}`))
+	case r.URL.Path == "/internal/v1/wallets/deposit":
+		var req DepositRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.balances[req.WalletID] += req.Amount
+		_, _ = w.Write([]byte(`{"success":true,"data":{}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupSweepService(t *testing.T, balances map[string]int64) (*TransactionService, *mockSweepRepository, *fakeWallets) {
+	t.Helper()
+
+	wallets := &fakeWallets{balances: balances}
+	server := httptest.NewServer(wallets)
+	t.Cleanup(server.Close)
+
+	txRepo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	sweepRepo := &mockSweepRepository{}
+	svc := NewTransactionService(txRepo, nil, NewWalletClient(server.URL), nil, nil)
+	svc.SetSweepRules(sweepRepo, time.Minute)
+
+	return svc, sweepRepo, wallets
+}
+
+func TestSweepRule_SweepAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.SweepRule
+		balance int64
+		want    int64
+	}{
+		{"sweep out excess", models.SweepRule{Direction: models.SweepOut, Threshold: 10000, TargetBalance: 10000}, 15000, 5000},
+		{"sweep out to lower target", models.SweepRule{Direction: models.SweepOut, Threshold: 10000, TargetBalance: 2000}, 15000, 13000},
+		{"sweep out at threshold", models.SweepRule{Direction: models.SweepOut, Threshold: 10000, TargetBalance: 10000}, 10000, 0},
+		{"sweep out below minimum", models.SweepRule{Direction: models.SweepOut, Threshold: 10000, TargetBalance: 10000, MinAmount: 1000}, 10500, 0},
+		{"top up shortfall", models.SweepRule{Direction: models.SweepTopUp, Threshold: 5000, TargetBalance: 8000}, 3000, 5000},
+		{"top up above threshold", models.SweepRule{Direction: models.SweepTopUp, Threshold: 5000, TargetBalance: 8000}, 6000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.SweepAmount(tt.balance); got != tt.want {
+				t.Errorf("expected %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCreateSweepRule_Validation(t *testing.T) {
+	wallet, savings := uuid.New().String(), uuid.New().String()
+	svc, _, _ := setupSweepService(t, map[string]int64{})
+	ctx := context.Background()
+
+	target := int64(20000)
+	tests := []struct {
+		name string
+		req  models.SweepRuleRequest
+		code errors.ErrorCode
+	}{
+		{"same wallet", models.SweepRuleRequest{CounterpartWalletID: wallet, Direction: models.SweepOut}, errors.ErrCodeTransferSameWallet},
+		{"sweep out target above threshold", models.SweepRuleRequest{CounterpartWalletID: savings, Direction: models.SweepOut, Threshold: 10000, TargetBalance: &target}, errors.ErrCodeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateSweepRule(ctx, wallet, "user-1", &tt.req)
+			if err == nil || err.Code != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	rule, err := svc.CreateSweepRule(ctx, wallet, "user-1", &models.SweepRuleRequest{
+		CounterpartWalletID: savings, Direction: models.SweepTopUp, Threshold: 10000, TargetBalance: &target,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if rule.TargetBalance != 20000 || !rule.Enabled {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+}
+
+func TestEvaluateSweepRules_SweepsExcessAndRecordsHistory(t *testing.T) {
+	wallet, savings := uuid.New().String(), uuid.New().String()
+	svc, sweepRepo, wallets := setupSweepService(t, map[string]int64{wallet: 150000, savings: 0})
+	ctx := context.Background()
+
+	if _, err := svc.CreateSweepRule(ctx, wallet, "user-1", &models.SweepRuleRequest{
+		CounterpartWalletID: savings, Direction: models.SweepOut, Threshold: 100000,
+	}); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	attempted, err := svc.EvaluateSweepRules(ctx, wallet)
+	if err != nil || attempted != 1 {
+		t.Fatalf("expected one sweep, got %d (%v)", attempted, err)
+	}
+	if wallets.balances[wallet] != 100000 || wallets.balances[savings] != 50000 {
+		t.Errorf("unexpected balances: %v", wallets.balances)
+	}
+
+	if len(sweepRepo.executions) != 1 {
+		t.Fatalf("expected one execution, got %d", len(sweepRepo.executions))
+	}
+	exec := sweepRepo.executions[0]
+	if exec.Status != models.SweepExecutionCompleted || exec.Amount != 50000 || exec.BalanceBefore != 150000 || exec.TransactionID == nil {
+		t.Errorf("unexpected execution: %+v", exec)
+	}
+
+	// The sweep transfer is marked and does not queue another evaluation
+	tx, _ := svc.GetTransaction(ctx, *exec.TransactionID)
+	if tx.Metadata[models.MetaSweepRuleID] == "" {
+		t.Error("expected sweep transfer to carry the rule ID")
+	}
+	if len(svc.sweepQueue.ch) != 1 {
+		t.Errorf("expected only the rule creation to queue the wallet, got %d queued", len(svc.sweepQueue.ch))
+	}
+
+	// Within the cooldown the rule does not run again
+	wallets.balances[wallet] = 200000
+	if attempted, _ := svc.EvaluateSweepRules(ctx, wallet); attempted != 0 {
+		t.Errorf("expected cooldown to hold the rule, got %d sweeps", attempted)
+	}
+}
+
+func TestEvaluateSweepRules_TopUpCappedAndFailuresRecorded(t *testing.T) {
+	wallet, linked := uuid.New().String(), uuid.New().String()
+	svc, sweepRepo, wallets := setupSweepService(t, map[string]int64{wallet: 1000, linked: 3000})
+	ctx := context.Background()
+
+	target := int64(10000)
+	if _, err := svc.CreateSweepRule(ctx, wallet, "user-1", &models.SweepRuleRequest{
+		CounterpartWalletID: linked, Direction: models.SweepTopUp, Threshold: 5000, TargetBalance: &target,
+	}); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	if attempted, err := svc.EvaluateSweepRules(ctx, wallet); err != nil || attempted != 1 {
+		t.Fatalf("expected one top-up, got %d (%v)", attempted, err)
+	}
+	if wallets.balances[wallet] != 4000 || wallets.balances[linked] != 0 {
+		t.Errorf("expected top-up capped at the linked balance, got %v", wallets.balances)
+	}
+	if exec := sweepRepo.executions[0]; exec.Amount != 3000 || exec.Status != models.SweepExecutionCompleted {
+		t.Errorf("unexpected execution: %+v", exec)
+	}
+
+	// A transfer the wallet service rejects is recorded as failed
+	rule := sweepRepo.rules[0]
+	rule.Direction, rule.Threshold, rule.TargetBalance = models.SweepOut, 1000, 0
+	rule.LastExecutedAt = nil
+	frozen := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal/v1/wallets/transfer" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":"WALLET_FROZEN","message":"wallet is frozen"}}`))
+			return
+		}
+		wallets.ServeHTTP(w, r)
+	}))
+	defer frozen.Close()
+	svc.walletClient = NewWalletClient(frozen.URL)
+
+	if attempted, _ := svc.EvaluateSweepRules(ctx, wallet); attempted != 1 {
+		t.Fatalf("expected one attempted sweep, got %d", attempted)
+	}
+	failed := sweepRepo.executions[1]
+	if failed.Status != models.SweepExecutionFailed || failed.FailureReason == nil {
+		t.Errorf("expected failed execution with a reason, got %+v", failed)
+	}
+}
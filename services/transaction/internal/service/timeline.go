@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// TimelineRepositoryInterface defines the interface for transaction timeline storage.
+type TimelineRepositoryInterface interface {
+	Record(ctx context.Context, event *models.TransactionEvent) *errors.Error
+	ListByTransaction(ctx context.Context, transactionID string) ([]*models.TransactionEvent, *errors.Error)
+}
+
+// Services recorded as timeline actors.
+const (
+	actorTransaction = "transaction"
+	actorRisk        = "risk"
+	actorWallet      = "wallet"
+	actorLedger      = "ledger"
+	actorUPI         = "upi"
+	actorBank        = "bank"
+)
+
+// SetTimeline records each step of a transaction's processing in repo, served
+// by GetTimeline. Without it no history is kept.
+func (s *TransactionService) SetTimeline(repo TimelineRepositoryInterface) {
+	s.timelineRepo = repo
+}
+
+// recordTimeline appends a step to a transaction's timeline. The timeline is
+// an audit aid: failing to record a step is logged and never fails the
+// transaction.
+func (s *TransactionService) recordTimeline(ctx context.Context, transactionID string, eventType models.TimelineEventType, status models.TransactionStatus, actor models.TimelineActor, details map[string]string) {
+	if s.timelineRepo == nil {
+		return
+	}
+
+	event := &models.TransactionEvent{
+		TransactionID: transactionID,
+		EventType:     eventType,
+		Status:        status,
+		ActorType:     actor.Type,
+		ActorID:       actor.ID,
+		Details:       details,
+	}
+	if err := s.timelineRepo.Record(ctx, event); err != nil {
+		s.logger.WithError(err).With(map[string]interface{}{
+			"transaction_id": transactionID,
+			"event_type":     eventType,
+		}).Warn("Failed to record transaction timeline event")
+	}
+}
+
+// requestActor returns the authenticated user behind ctx, or the transaction
+// service itself for background and internal work.
+func requestActor(ctx context.Context) models.TimelineActor {
+	if userID, ok := middleware.GetUserID(ctx); ok && userID != "" {
+		return models.TimelineActor{Type: models.ActorUser, ID: userID}
+	}
+	return models.ServiceActor(actorTransaction)
+}
+
+// recordFailed records a transaction failing with reason.
+func (s *TransactionService) recordFailed(ctx context.Context, transactionID string, actor models.TimelineActor, reason string) {
+	s.recordTimeline(ctx, transactionID, models.TimelineFailed, models.TransactionStatusFailed, actor,
+		map[string]string{"reason": reason})
+}
+
+// GetTimeline returns a transaction's status history, oldest event first.
+func (s *TransactionService) GetTimeline(ctx context.Context, transactionID string) (*models.TransactionTimeline, *errors.Error) {
+	if s.timelineRepo == nil {
+		return nil, errors.Unavailable("transaction timeline is not configured")
+	}
+
+	transaction, err := s.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.timelineRepo.ListByTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TransactionTimeline{
+		TransactionID: transaction.ID,
+		Type:          transaction.Type,
+		Status:        transaction.Status,
+		Events:        events,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+type mockTimelineRepository struct {
+	events []*models.TransactionEvent
+}
+
+func (m *mockTimelineRepository) Record(ctx context.Context, event *models.TransactionEvent) *errors.Error {
+	event.ID = uuid.New().String()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockTimelineRepository) ListByTransaction(ctx context.Context, transactionID string) ([]*models.TransactionEvent, *errors.Error) {
+	events := make([]*models.TransactionEvent, 0)
+	for _, event := range m.events {
+		if event.TransactionID == transactionID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func setupTimelineService() (*TransactionService, *mockTransactionRepository, *mockTimelineRepository) {
+	service, repo := setupTestService()
+	timeline := &mockTimelineRepository{}
+	service.SetTimeline(timeline)
+	return service, repo, timeline
+}
+
+func eventTypes(events []*models.TransactionEvent) []models.TimelineEventType {
+	types := make([]models.TimelineEventType, len(events))
+	for i, event := range events {
+		types[i] = event.EventType
+	}
+	return types
+}
+
+func TestTimeline_CreateTransferRecordsCreationAndRiskBypass(t *testing.T) {
+	service, _, _ := setupTimelineService()
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "user-1")
+
+	tx, err := service.CreateTransfer(ctx, &models.CreateTransferRequest{
+		SourceWalletID:      uuid.New().String(),
+		DestinationWalletID: uuid.New().String(),
+		Amount:              50000,
+		Currency:            sharedModels.INR,
+		Description:         "Rent",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	timeline, err := service.GetTimeline(ctx, tx.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	types := eventTypes(timeline.Events)
+	if len(types) != 2 || types[0] != models.TimelineCreated || types[1] != models.TimelineRiskBypassed {
+		t.Fatalf("expected created then risk_bypassed, got %v", types)
+	}
+	created := timeline.Events[0]
+	if created.ActorType != models.ActorUser || created.ActorID != "user-1" {
+		t.Errorf("expected the requesting user as actor, got %s %s", created.ActorType, created.ActorID)
+	}
+	if created.Status != models.TransactionStatusPending {
+		t.Errorf("expected pending status after creation, got %s", created.Status)
+	}
+	if bypass := timeline.Events[1]; bypass.ActorType != models.ActorService || bypass.Details["reason"] != models.RiskBypassNotConfigured {
+		t.Errorf("unexpected risk bypass event: %+v", bypass)
+	}
+	if timeline.Status != tx.Status || timeline.Type != models.TransactionTypeTransfer {
+		t.Errorf("expected timeline to carry the transaction's type and status, got %s %s", timeline.Type, timeline.Status)
+	}
+}
+
+func TestTimeline_ReversalRecordedOnBothTransactions(t *testing.T) {
+	service, repo, _ := setupTimelineService()
+	ctx := context.Background()
+
+	sourceWalletID := uuid.New().String()
+	destWalletID := uuid.New().String()
+	original := &models.Transaction{
+		ID:                  uuid.New().String(),
+		Type:                models.TransactionTypeTransfer,
+		Status:              models.TransactionStatusCompleted,
+		SourceWalletID:      &sourceWalletID,
+		DestinationWalletID: &destWalletID,
+		Amount:              50000,
+		Currency:            sharedModels.INR,
+	}
+	repo.transactions[original.ID] = original
+
+	reversal, err := service.ReverseTransaction(ctx, original.ID, "duplicate charge")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	originalTimeline, err := service.GetTimeline(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(originalTimeline.Events) != 1 || originalTimeline.Events[0].EventType != models.TimelineReversalCreated {
+		t.Fatalf("expected reversal_created on the original, got %v", eventTypes(originalTimeline.Events))
+	}
+	if got := originalTimeline.Events[0].Details["reversal_transaction_id"]; got != reversal.ID {
+		t.Errorf("expected link to reversal %s, got %s", reversal.ID, got)
+	}
+
+	reversalTimeline, _ := service.GetTimeline(ctx, reversal.ID)
+	if len(reversalTimeline.Events) != 1 || reversalTimeline.Events[0].Details["parent_transaction_id"] != original.ID {
+		t.Errorf("expected created event linking the parent, got %+v", reversalTimeline.Events)
+	}
+	if actor := reversalTimeline.Events[0]; actor.ActorType != models.ActorService || actor.ActorID != actorTransaction {
+		t.Errorf("expected the transaction service as actor without a user, got %s %s", actor.ActorType, actor.ActorID)
+	}
+}
+
+func TestTimeline_NotConfigured(t *testing.T) {
+	service, _ := setupTestService()
+
+	_, err := service.GetTimeline(context.Background(), uuid.New().String())
+	if err == nil || err.Code != errors.ErrCodeUnavailable {
+		t.Fatalf("expected unavailable error, got %v", err)
+	}
+}
@@ -7,9 +7,15 @@ import (
 	"time"
 
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+	"github.com/1mb-dev/nivomoney/shared/workerpool"
 )
 
 // TransactionRepositoryInterface defines the interface for transaction repository operations.
@@ -21,21 +27,50 @@ type TransactionRepositoryInterface interface {
 	UpdateMetadata(ctx context.Context, id string, metadata map[string]string) *errors.Error
 	CompleteWithMetadata(ctx context.Context, id string, metadata map[string]string) *errors.Error
 	UpdateStatus(ctx context.Context, id string, status models.TransactionStatus, failureReason *string) *errors.Error
-	UpdateCategory(ctx context.Context, id string, category models.SpendingCategory) *errors.Error
+	UpdateCategory(ctx context.Context, id string, category models.SpendingCategory, overridden bool) *errors.Error
 	GetCategoryPatterns(ctx context.Context) ([]*models.CategoryPattern, *errors.Error)
 	GetCategorySummary(ctx context.Context, walletID string, startDate, endDate string) ([]models.CategorySummary, *errors.Error)
 }
 
 // TransactionService handles business logic for transaction operations.
 type TransactionService struct {
-	transactionRepo TransactionRepositoryInterface
-	riskClient      *RiskClient
-	walletClient    *WalletClient
-	ledgerClient    *LedgerClient
-	eventPublisher  *events.Publisher
-	logger          *logger.Logger
+	transactionRepo    TransactionRepositoryInterface
+	riskClient         *RiskClient
+	walletClient       *WalletClient
+	ledgerClient       *LedgerClient
+	eventPublisher     *events.Publisher
+	bypassRepo         RiskBypassRepositoryInterface
+	riskPolicy         RiskBypassPolicy
+	categoryRepo       CategoryRuleRepositoryInterface
+	recategorize       *recategorizeJobs
+	approvals          *approval.Manager
+	approvalMin        int64 // Reversals of at least this amount need approval
+	quoteRepo          QuoteRepositoryInterface
+	fxRates            FXRateSource
+	quotePolicy        QuotePolicy
+	eventPool          *workerpool.Pool
+	sweepRepo          SweepRepositoryInterface
+	sweepCooldown      time.Duration
+	sweepQueue         *sweepQueue
+	merchantWebhooks   *merchantWebhooks
+	paymentIntentRepo  PaymentIntentRepositoryInterface
+	timelineRepo       TimelineRepositoryInterface
+	amountPolicyRepo   AmountPolicyRepositoryInterface
+	fundingRepo        FundingRepositoryInterface
+	topUpCooldown      time.Duration
+	topUpQueue         *sweepQueue
+	withdrawals        *withdrawalApprovals
+	notificationClient *clients.NotificationClient
+	counterparties     *counterparties
+	stuckPending       *stuckPendingReaper
+	feeCharges         FeeChargeRepositoryInterface
+	stuckTransfers     StuckTransferRepositoryInterface // Optional: non-production simulation anomalies
+	logger             *logger.Logger
 }
 
+// EventPublishTimeout bounds publishing one transaction event.
+const EventPublishTimeout = 5 * time.Second
+
 // NewTransactionService creates a new transaction service.
 func NewTransactionService(transactionRepo TransactionRepositoryInterface, riskClient *RiskClient, walletClient *WalletClient, ledgerClient *LedgerClient, eventPublisher *events.Publisher) *TransactionService {
 	return &TransactionService{
@@ -44,6 +79,7 @@ func NewTransactionService(transactionRepo TransactionRepositoryInterface, riskC
 		walletClient:    walletClient,
 		ledgerClient:    ledgerClient,
 		eventPublisher:  eventPublisher,
+		riskPolicy:      DefaultRiskBypassPolicy(),
 		logger:          logger.NewDefault("transaction"),
 	}
 }
@@ -58,7 +94,16 @@ func (s *TransactionService) CreateTransfer(ctx context.Context, req *models.Cre
 
 	// Validate source and destination are different
 	if req.SourceWalletID == req.DestinationWalletID {
-		return nil, errors.BadRequest("source and destination wallets must be different")
+		return nil, errors.New(errors.ErrCodeTransferSameWallet, "source and destination wallets must be different")
+	}
+
+	if amountErr := s.CheckAmount(ctx, models.AmountOperationTransfer, req.Currency, req.Amount); amountErr != nil {
+		return nil, amountErr
+	}
+
+	// Newly added beneficiaries can only receive a capped amount at first
+	if coolingErr := s.reserveCoolingOff(ctx, req); coolingErr != nil {
+		return nil, coolingErr
 	}
 
 	// Create transaction
@@ -80,41 +125,71 @@ func (s *TransactionService) CreateTransfer(ctx context.Context, req *models.Cre
 		Reference:           reference,
 		Metadata:            metadata,
 	}
+	if transaction.Description == "" {
+		transaction.Description = s.ownTransferDescription(ctx, sourceWalletID, destWalletID)
+	}
+
+	// A quote locks the debit amount and currency the transfer was priced at
+	if req.QuoteID != "" {
+		if quoteErr := s.claimQuote(ctx, req, transaction); quoteErr != nil {
+			s.releaseCoolingOff(ctx, req)
+			return nil, quoteErr
+		}
+	}
 
 	if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
+		if req.QuoteID != "" {
+			_ = s.quoteRepo.Release(ctx, req.QuoteID)
+		}
+		s.releaseCoolingOff(ctx, req)
 		return nil, createErr
 	}
 
-	// Publish transaction.created event
-	if s.eventPublisher != nil {
-		s.eventPublisher.PublishTransactionEvent("transaction.created", transaction.ID, map[string]interface{}{
-			"type":                  string(transaction.Type),
-			"status":                string(transaction.Status),
-			"amount":                transaction.Amount,
-			"currency":              transaction.Currency,
-			"source_wallet_id":      transaction.SourceWalletID,
-			"destination_wallet_id": transaction.DestinationWalletID,
-			"description":           transaction.Description,
-		})
+	var createdDetails map[string]string
+	if req.QuoteID != "" {
+		if linkErr := s.quoteRepo.SetTransaction(ctx, req.QuoteID, transaction.ID); linkErr != nil {
+			s.logger.WithError(linkErr).WithField("quote_id", req.QuoteID).Warn("Failed to link quote to transaction")
+		}
+		createdDetails = map[string]string{"quote_id": req.QuoteID}
 	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, requestActor(ctx), createdDetails)
 
-	// Evaluate risk for the transaction (fail-closed: block if risk service unavailable)
+	// Publish transaction.created event
+	s.publishTransactionEvent(events.TransactionCreated{
+		TransactionID:       transaction.ID,
+		Type:                string(transaction.Type),
+		Status:              string(transaction.Status),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		SourceWalletID:      transaction.SourceWalletID,
+		DestinationWalletID: transaction.DestinationWalletID,
+		Description:         transaction.Description,
+	}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+	// Evaluate risk for the transaction. Fail-closed unless the policy allows
+	// small amounts through, in which case the bypass is recorded for re-scoring.
 	riskBlocked, riskErr := s.evaluateTransactionRisk(ctx, transaction)
 	if riskErr != nil {
-		s.logger.WithError(riskErr).WithField("transaction_id", transaction.ID).Error("Risk evaluation failed - blocking transaction")
-		failureReason := "risk evaluation unavailable"
-		_ = s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason)
-		return nil, errors.Internal("transaction blocked: risk service unavailable")
+		if !s.riskPolicy.allowsFailOpen(transaction.Amount) {
+			s.logger.WithError(riskErr).WithField("transaction_id", transaction.ID).Error("Risk evaluation failed - blocking transaction")
+			failureReason := "risk evaluation unavailable"
+			_ = s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason)
+			s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorRisk), failureReason)
+			s.releaseCoolingOff(ctx, req)
+			return nil, errors.Internal("transaction blocked: risk service unavailable")
+		}
+		s.recordRiskBypass(ctx, transaction, riskBypassReason(riskErr))
 	}
 
 	// If risk blocked the transaction, fail it
 	if riskBlocked {
 		s.logger.WithField("transaction_id", transaction.ID).Warn("Transaction blocked by risk evaluation")
+		s.releaseCoolingOff(ctx, req)
 		// Transaction already marked as failed in evaluateTransactionRisk
 		if updatedTx, getErr := s.transactionRepo.GetByID(ctx, transaction.ID); getErr == nil {
-			return updatedTx, errors.BadRequest("transaction blocked by risk evaluation")
+			return updatedTx, errors.New(errors.ErrCodeRiskBlocked, "transaction blocked by risk evaluation")
 		}
-		return nil, errors.BadRequest("transaction blocked by risk evaluation")
+		return nil, errors.New(errors.ErrCodeRiskBlocked, "transaction blocked by risk evaluation")
 	}
 
 	// Process the transfer synchronously
@@ -126,6 +201,11 @@ func (s *TransactionService) CreateTransfer(ctx context.Context, req *models.Cre
 		if updatedTx, getErr := s.transactionRepo.GetByID(ctx, transaction.ID); getErr == nil {
 			transaction = updatedTx
 		}
+		// A transfer left pending may still complete; only a failed one
+		// gives its cooling-off reservation back
+		if transaction.Status == models.TransactionStatusFailed {
+			s.releaseCoolingOff(ctx, req)
+		}
 	} else {
 		// Refetch to get completed status
 		if updatedTx, getErr := s.transactionRepo.GetByID(ctx, transaction.ID); getErr == nil {
@@ -136,6 +216,62 @@ func (s *TransactionService) CreateTransfer(ctx context.Context, req *models.Cre
 	return transaction, nil
 }
 
+// reserveCoolingOff reserves a user's transfer against the cooling-off cap of
+// the destination, if it is one of the user's recently added beneficiaries.
+// The amount checked is what the destination receives. Transfers made by the
+// platform rather than a user, such as sweeps, are not capped.
+func (s *TransactionService) reserveCoolingOff(ctx context.Context, req *models.CreateTransferRequest) *errors.Error {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok || userID == "" || s.walletClient == nil {
+		return nil
+	}
+	return s.walletClient.ReserveCoolingOff(ctx, &CoolingOffReservation{
+		OwnerUserID:         userID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+	})
+}
+
+// releaseCoolingOff gives back the cooling-off reservation of a transfer that
+// failed after reserveCoolingOff succeeded. A failed release is logged; the
+// reservation then stays counted until the cooling-off period ends.
+func (s *TransactionService) releaseCoolingOff(ctx context.Context, req *models.CreateTransferRequest) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok || userID == "" || s.walletClient == nil {
+		return
+	}
+	if err := s.walletClient.ReleaseCoolingOff(ctx, &CoolingOffReservation{
+		OwnerUserID:         userID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+	}); err != nil {
+		s.logger.WithError(err).WithField("destination_wallet_id", req.DestinationWalletID).
+			Warn("Failed to release cooling-off reservation of failed transfer")
+	}
+}
+
+// ownTransferDescription describes a transfer between two wallets of the same
+// owner by the destination's nickname, e.g. "Transfer to Travel fund". It
+// returns an empty description for other transfers or when the wallets
+// cannot be looked up.
+func (s *TransactionService) ownTransferDescription(ctx context.Context, sourceWalletID, destWalletID string) string {
+	if s.walletClient == nil {
+		return ""
+	}
+	source, err := s.walletClient.GetWalletInfo(ctx, sourceWalletID)
+	if err != nil {
+		return ""
+	}
+	dest, err := s.walletClient.GetWalletInfo(ctx, destWalletID)
+	if err != nil {
+		return ""
+	}
+	if label := source.CounterpartyLabel(dest); label != dest.ID {
+		return "Transfer to " + label
+	}
+	return ""
+}
+
 // CreateDeposit creates a deposit transaction to a wallet.
 func (s *TransactionService) CreateDeposit(ctx context.Context, req *models.CreateDepositRequest) (*models.Transaction, *errors.Error) {
 	// Parse metadata
@@ -144,6 +280,10 @@ func (s *TransactionService) CreateDeposit(ctx context.Context, req *models.Crea
 		return nil, errors.Validation("invalid metadata format")
 	}
 
+	if amountErr := s.CheckAmount(ctx, models.AmountOperationDeposit, req.Currency, req.Amount); amountErr != nil {
+		return nil, amountErr
+	}
+
 	destWalletID := req.WalletID
 	var reference *string
 	if req.Reference != "" {
@@ -164,18 +304,18 @@ func (s *TransactionService) CreateDeposit(ctx context.Context, req *models.Crea
 	if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
 		return nil, createErr
 	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, requestActor(ctx), nil)
 
 	// Publish transaction.created event
-	if s.eventPublisher != nil {
-		s.eventPublisher.PublishTransactionEvent("transaction.created", transaction.ID, map[string]interface{}{
-			"type":                  string(transaction.Type),
-			"status":                string(transaction.Status),
-			"amount":                transaction.Amount,
-			"currency":              transaction.Currency,
-			"destination_wallet_id": transaction.DestinationWalletID,
-			"description":           transaction.Description,
-		})
-	}
+	s.publishTransactionEvent(events.TransactionCreated{
+		TransactionID:       transaction.ID,
+		Type:                string(transaction.Type),
+		Status:              string(transaction.Status),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		DestinationWalletID: transaction.DestinationWalletID,
+		Description:         transaction.Description,
+	}, transaction.DestinationWalletID)
 
 	// TODO: Trigger async processing for deposit
 	// 1. Verify external payment received
@@ -188,6 +328,10 @@ func (s *TransactionService) CreateDeposit(ctx context.Context, req *models.Crea
 
 // InitiateUPIDeposit initiates a UPI deposit and returns virtual UPI ID for payment.
 func (s *TransactionService) InitiateUPIDeposit(ctx context.Context, req *models.CreateUPIDepositRequest) (*models.UPIDepositResponse, *errors.Error) {
+	if amountErr := s.CheckAmount(ctx, models.AmountOperationUPIDeposit, req.Currency, req.Amount); amountErr != nil {
+		return nil, amountErr
+	}
+
 	// Generate virtual UPI ID (mock format: nivomoney.{wallet_suffix}@yesbank)
 	walletSuffix := req.WalletID[len(req.WalletID)-8:]
 	virtualUPIID := fmt.Sprintf("nivomoney.%s@yesbank", walletSuffix)
@@ -219,6 +363,8 @@ func (s *TransactionService) InitiateUPIDeposit(ctx context.Context, req *models
 	if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
 		return nil, createErr
 	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, requestActor(ctx),
+		map[string]string{"payment_method": "upi", "virtual_upi_id": virtualUPIID})
 
 	// Publish event
 	if s.eventPublisher != nil {
@@ -244,7 +390,7 @@ func (s *TransactionService) InitiateUPIDeposit(ctx context.Context, req *models
 		Instructions: []string{
 			"Open any UPI app (Google Pay, PhonePe, Paytm, etc.)",
 			fmt.Sprintf("Pay to UPI ID: %s", virtualUPIID),
-			fmt.Sprintf("Amount: ₹%.2f", float64(req.Amount)/100),
+			fmt.Sprintf("Amount: %s", money.New(req.Amount, req.Currency)),
 			"Your wallet will be credited instantly upon successful payment",
 			"This is a simulation - use the 'Simulate Payment' button to complete",
 		},
@@ -273,7 +419,7 @@ func (s *TransactionService) CompleteUPIDeposit(ctx context.Context, req *models
 	}
 
 	if transaction.Status != models.TransactionStatusPending {
-		return nil, errors.BadRequest("transaction is not in pending status")
+		return nil, errors.New(errors.ErrCodeTransactionInvalidState, "transaction is not in pending status")
 	}
 
 	// Check if it's a UPI deposit
@@ -294,6 +440,8 @@ func (s *TransactionService) CompleteUPIDeposit(ctx context.Context, req *models
 		if updateErr := s.transactionRepo.CompleteWithMetadata(ctx, transaction.ID, updatedMetadata); updateErr != nil {
 			return nil, updateErr
 		}
+		s.recordTimeline(ctx, transaction.ID, models.TimelineCompleted, models.TransactionStatusCompleted, models.ServiceActor(actorUPI),
+			map[string]string{"upi_transaction_id": req.UPITransactionID})
 
 		// Refetch to get updated transaction
 		transaction, err = s.transactionRepo.GetByID(ctx, req.TransactionID)
@@ -337,6 +485,9 @@ func (s *TransactionService) CompleteUPIDeposit(ctx context.Context, req *models
 					"wallet_id": *transaction.DestinationWalletID,
 					"amount":    transaction.Amount,
 				}).Info("Wallet credited")
+				s.recordTimeline(ctx, transaction.ID, models.TimelineFundsMoved, transaction.Status, models.ServiceActor(actorWallet),
+					map[string]string{"credited_wallet_id": *transaction.DestinationWalletID})
+				s.queueSweepAfter(transaction)
 			}
 		}
 	} else {
@@ -345,6 +496,7 @@ func (s *TransactionService) CompleteUPIDeposit(ctx context.Context, req *models
 		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason); updateErr != nil {
 			return nil, updateErr
 		}
+		s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorUPI), failureReason)
 
 		// Refetch to get updated transaction
 		transaction, err = s.transactionRepo.GetByID(ctx, req.TransactionID)
@@ -374,7 +526,9 @@ func generateMockQRCode(upiID string, amount int64) string {
 	return "data:image/png;base64,iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg=="
 }
 
-// CreateWithdrawal creates a withdrawal transaction from a wallet.
+// CreateWithdrawal creates a withdrawal transaction from a wallet. Withdrawals
+// from the step-up threshold are created as pending_approval until the
+// requester confirms them; see ConfirmWithdrawal.
 func (s *TransactionService) CreateWithdrawal(ctx context.Context, req *models.CreateWithdrawalRequest) (*models.Transaction, *errors.Error) {
 	// Parse metadata
 	metadata, metaErr := req.GetMetadata()
@@ -382,6 +536,17 @@ func (s *TransactionService) CreateWithdrawal(ctx context.Context, req *models.C
 		return nil, errors.Validation("invalid metadata format")
 	}
 
+	if amountErr := s.CheckAmount(ctx, models.AmountOperationWithdrawal, req.Currency, req.Amount); amountErr != nil {
+		return nil, amountErr
+	}
+
+	// Large withdrawals are held until the requester confirms them
+	held := s.withdrawalNeedsApproval(req.Amount)
+	userID, _ := middleware.GetUserID(ctx)
+	if held && userID == "" {
+		return nil, errors.Unauthorized("user not authenticated")
+	}
+
 	sourceWalletID := req.WalletID
 	var reference *string
 	if req.Reference != "" {
@@ -398,23 +563,32 @@ func (s *TransactionService) CreateWithdrawal(ctx context.Context, req *models.C
 		Reference:      reference,
 		Metadata:       metadata,
 	}
+	if held {
+		transaction.Status = models.TransactionStatusPendingApproval
+	}
 
 	if createErr := s.transactionRepo.Create(ctx, transaction); createErr != nil {
 		return nil, createErr
 	}
+	s.recordTimeline(ctx, transaction.ID, models.TimelineCreated, transaction.Status, requestActor(ctx), nil)
 
-	// Publish transaction.created event
-	if s.eventPublisher != nil {
-		s.eventPublisher.PublishTransactionEvent("transaction.created", transaction.ID, map[string]interface{}{
-			"type":             string(transaction.Type),
-			"status":           string(transaction.Status),
-			"amount":           transaction.Amount,
-			"currency":         transaction.Currency,
-			"source_wallet_id": transaction.SourceWalletID,
-			"description":      transaction.Description,
-		})
+	if held {
+		if _, holdErr := s.holdWithdrawal(ctx, transaction, userID); holdErr != nil {
+			return nil, holdErr
+		}
 	}
 
+	// Publish transaction.created event
+	s.publishTransactionEvent(events.TransactionCreated{
+		TransactionID:  transaction.ID,
+		Type:           string(transaction.Type),
+		Status:         string(transaction.Status),
+		Amount:         transaction.Amount,
+		Currency:       string(transaction.Currency),
+		SourceWalletID: transaction.SourceWalletID,
+		Description:    transaction.Description,
+	}, transaction.SourceWalletID)
+
 	// TODO: Trigger async processing for withdrawal
 	// 1. Verify wallet has sufficient balance
 	// 2. Create hold on wallet
@@ -431,9 +605,39 @@ func (s *TransactionService) GetTransaction(ctx context.Context, id string) (*mo
 	return s.transactionRepo.GetByID(ctx, id)
 }
 
+// GetReceipt returns the receipt of a completed transaction.
+func (s *TransactionService) GetReceipt(ctx context.Context, id string) (*models.TransactionReceipt, *errors.Error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !transaction.IsCompleted() {
+		return nil, errors.Conflict(fmt.Sprintf("receipts are only issued for completed transactions; transaction is %s", transaction.Status))
+	}
+
+	return &models.TransactionReceipt{
+		TransactionID:       transaction.ID,
+		Type:                transaction.Type,
+		SourceWalletID:      transaction.SourceWalletID,
+		DestinationWalletID: transaction.DestinationWalletID,
+		Amount:              transaction.Amount,
+		Currency:            transaction.Currency,
+		Description:         transaction.Description,
+		Reference:           transaction.Reference,
+		LedgerEntryID:       transaction.LedgerEntryID,
+		CompletedAt:         transaction.CompletedAt,
+		IssuedAt:            sharedModels.Now(),
+	}, nil
+}
+
 // ListWalletTransactions retrieves transactions for a wallet.
 func (s *TransactionService) ListWalletTransactions(ctx context.Context, walletID string, filter *models.TransactionFilter) ([]*models.Transaction, *errors.Error) {
-	return s.transactionRepo.ListByWallet(ctx, walletID, filter)
+	transactions, err := s.transactionRepo.ListByWallet(ctx, walletID, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.EnrichCounterparties(ctx, walletID, transactions)
+	return transactions, nil
 }
 
 // SearchAllTransactions searches transactions across all wallets (admin operation).
@@ -461,12 +665,8 @@ func (s *TransactionService) ReverseTransaction(ctx context.Context, transaction
 	}
 
 	// Validate transaction can be reversed
-	if !originalTx.IsCompleted() {
-		return nil, errors.BadRequest("only completed transactions can be reversed")
-	}
-
-	if originalTx.Type == models.TransactionTypeReversal {
-		return nil, errors.BadRequest("cannot reverse a reversal transaction")
+	if revErr := checkReversible(originalTx); revErr != nil {
+		return nil, revErr
 	}
 
 	// Create reversal transaction
@@ -486,6 +686,11 @@ func (s *TransactionService) ReverseTransaction(ctx context.Context, transaction
 	if createErr := s.transactionRepo.Create(ctx, reversalTx); createErr != nil {
 		return nil, createErr
 	}
+	actor := requestActor(ctx)
+	s.recordTimeline(ctx, reversalTx.ID, models.TimelineCreated, reversalTx.Status, actor,
+		map[string]string{"parent_transaction_id": transactionID, "reason": reason})
+	s.recordTimeline(ctx, transactionID, models.TimelineReversalCreated, originalTx.Status, actor,
+		map[string]string{"reversal_transaction_id": reversalTx.ID, "reason": reason})
 
 	// TODO: Trigger async processing for reversal
 	// 1. Create reversal ledger entry
@@ -496,6 +701,17 @@ func (s *TransactionService) ReverseTransaction(ctx context.Context, transaction
 	return reversalTx, nil
 }
 
+// checkReversible reports why a transaction cannot be reversed, if it cannot.
+func checkReversible(tx *models.Transaction) *errors.Error {
+	if !tx.IsCompleted() {
+		return errors.New(errors.ErrCodeTransactionNotReversible, "only completed transactions can be reversed")
+	}
+	if tx.Type == models.TransactionTypeReversal {
+		return errors.New(errors.ErrCodeTransactionNotReversible, "cannot reverse a reversal transaction")
+	}
+	return nil
+}
+
 // ProcessTransfer processes a pending transfer transaction by executing the wallet transfer
 // with limit checking and balance updates. This is typically called after risk evaluation.
 func (s *TransactionService) ProcessTransfer(ctx context.Context, transactionID string) *errors.Error {
@@ -507,7 +723,7 @@ func (s *TransactionService) ProcessTransfer(ctx context.Context, transactionID
 
 	// Validate transaction is pending
 	if transaction.Status != models.TransactionStatusPending {
-		return errors.BadRequest(fmt.Sprintf("transaction is not pending (status: %s)", transaction.Status))
+		return errors.New(errors.ErrCodeTransactionInvalidState, fmt.Sprintf("transaction is not pending (status: %s)", transaction.Status))
 	}
 
 	// Validate transaction is a transfer
@@ -542,17 +758,49 @@ func (s *TransactionService) ProcessTransfer(ctx context.Context, transactionID
 		if updateErr != nil {
 			s.logger.WithError(updateErr).Error("Failed to update failed transaction status")
 		}
+		s.recordFailed(ctx, transactionID, models.ServiceActor(actorWallet), failureReason)
+		s.settlePaymentIntent(ctx, transaction, models.TransactionStatusFailed, failureReason)
 
 		s.logger.WithError(transferErr).WithField("transaction_id", transactionID).Error("Transfer failed")
+
+		s.publishTransactionEvent(events.TransactionFailed{
+			TransactionID:       transactionID,
+			Type:                string(transaction.Type),
+			Status:              string(models.TransactionStatusFailed),
+			Amount:              transaction.Amount,
+			Currency:            string(transaction.Currency),
+			SourceWalletID:      transaction.SourceWalletID,
+			DestinationWalletID: transaction.DestinationWalletID,
+			FailureReason:       transferErr.Message,
+			ErrorCode:           string(transferErr.Code),
+		}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+		// Pass domain errors from the wallet service through so clients can branch on them
+		if entry, ok := errors.Lookup(transferErr.Code); ok && entry.Domain != errors.DomainGeneral {
+			return errors.New(transferErr.Code, "transfer failed: "+transferErr.Message).WithDetails(transferErr.Details)
+		}
 		return errors.Internal(fmt.Sprintf("transfer failed: %s", failureReason))
 	}
 
+	s.recordTimeline(ctx, transactionID, models.TimelineFundsMoved, transaction.Status, models.ServiceActor(actorWallet), nil)
+
+	return s.completeTransfer(ctx, transaction, true)
+}
+
+// completeTransfer finishes a transfer whose wallet balances have moved: it
+// posts the ledger entry when postLedger is set, marks the transfer completed
+// and publishes transaction.completed.
+func (s *TransactionService) completeTransfer(ctx context.Context, transaction *models.Transaction, postLedger bool) *errors.Error {
+	transactionID := transaction.ID
+
 	// Create ledger journal entry for audit trail
-	if s.ledgerClient != nil {
+	if postLedger && s.ledgerClient != nil {
 		if ledgerErr := s.createTransferLedgerEntry(ctx, transaction); ledgerErr != nil {
 			// Log error but don't fail the transaction - wallet balances already updated
 			// In production, this would trigger a reconciliation process
 			s.logger.WithError(ledgerErr).WithField("transaction_id", transactionID).Error("Failed to create ledger entry - reconciliation needed")
+			s.recordTimeline(ctx, transactionID, models.TimelineLedgerPostFailed, transaction.Status, models.ServiceActor(actorLedger),
+				map[string]string{"reason": ledgerErr.Error()})
 		}
 	}
 
@@ -562,32 +810,84 @@ func (s *TransactionService) ProcessTransfer(ctx context.Context, transactionID
 		s.logger.WithError(completeErr).Error("Failed to mark transaction as completed")
 		return completeErr
 	}
+	s.recordTimeline(ctx, transactionID, models.TimelineCompleted, models.TransactionStatusCompleted, models.ServiceActor(actorTransaction), nil)
 
 	// Publish transaction.completed event
-	if s.eventPublisher != nil {
-		s.eventPublisher.PublishTransactionEvent("transaction.completed", transactionID, map[string]interface{}{
-			"type":                  string(transaction.Type),
-			"status":                string(models.TransactionStatusCompleted),
-			"amount":                transaction.Amount,
-			"currency":              transaction.Currency,
-			"source_wallet_id":      transaction.SourceWalletID,
-			"destination_wallet_id": transaction.DestinationWalletID,
-		})
-	}
+	s.publishTransactionEvent(events.TransactionCompleted{
+		TransactionID:       transactionID,
+		Type:                string(transaction.Type),
+		Status:              string(models.TransactionStatusCompleted),
+		Amount:              transaction.Amount,
+		Currency:            string(transaction.Currency),
+		SourceWalletID:      transaction.SourceWalletID,
+		DestinationWalletID: transaction.DestinationWalletID,
+	}, transaction.SourceWalletID, transaction.DestinationWalletID)
+
+	s.queueSweepAfter(transaction)
+	s.queueAutoTopUpAfter(transaction)
+	s.queueMerchantPayment(ctx, transaction)
+	s.settlePaymentIntent(ctx, transaction, models.TransactionStatusCompleted, "")
 
 	s.logger.WithField("transaction_id", transactionID).Info("Transfer completed successfully")
 	return nil
 }
 
-// evaluateTransactionRisk evaluates risk for a transaction using the Risk Service.
-// Returns (blocked bool, error). If blocked is true, the transaction was rejected by risk.
-func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transaction *models.Transaction) (bool, error) {
-	if s.riskClient == nil {
-		s.logger.Debug("Risk client not configured, skipping risk evaluation")
-		return false, nil
+// publishTransactionEvent publishes a transaction event in the background,
+// addressed to the owners of the given wallets so it reaches their wallet streams.
+func (s *TransactionService) publishTransactionEvent(payload events.Payload, walletIDs ...*string) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	publish := func(ctx context.Context) error {
+		return s.eventPublisher.Publish("transactions", payload, s.walletOwners(ctx, walletIDs...)...)
+	}
+
+	if s.eventPool == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), EventPublishTimeout)
+		defer cancel()
+		if err := publish(ctx); err != nil {
+			s.logger.WithError(err).WithField("event_type", payload.EventType()).Warn("Failed to publish transaction event")
+		}
+		return
+	}
+
+	// Events are best effort: when the queue is full the event is dropped
+	// rather than holding up the transaction
+	if err := s.eventPool.Submit(publish); err != nil {
+		s.logger.WithError(err).WithField("event_type", payload.EventType()).Warn("Dropped transaction event")
+	}
+}
+
+// SetEventPool publishes transaction events in the background on pool, whose
+// Config.JobTimeout bounds each publish. Without a pool events are published
+// inline.
+func (s *TransactionService) SetEventPool(pool *workerpool.Pool) {
+	s.eventPool = pool
+}
+
+// walletOwners resolves the user IDs owning the given wallets, including the
+// co-owners of joint wallets, skipping any that cannot be looked up.
+func (s *TransactionService) walletOwners(ctx context.Context, walletIDs ...*string) []string {
+	if s.walletClient == nil {
+		return nil
 	}
 
-	// Get user ID from wallet service for proper per-user risk limits
+	var owners []string
+	for _, walletID := range walletIDs {
+		if walletID == nil || *walletID == "" {
+			continue
+		}
+		if info, err := s.walletClient.GetWalletInfo(ctx, *walletID); err == nil {
+			owners = append(owners, info.OwnerIDs()...)
+		}
+	}
+	return owners
+}
+
+// buildRiskRequest prepares a risk evaluation request for a transaction,
+// resolving the source wallet's owner for per-user risk limits.
+func (s *TransactionService) buildRiskRequest(ctx context.Context, transaction *models.Transaction) *RiskEvaluationRequest {
 	userID := "unknown"
 	if s.walletClient != nil && transaction.SourceWalletID != nil {
 		walletInfo, infoErr := s.walletClient.GetWalletInfo(ctx, *transaction.SourceWalletID)
@@ -596,7 +896,6 @@ func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transa
 		}
 	}
 
-	// Prepare risk evaluation request
 	riskReq := &RiskEvaluationRequest{
 		TransactionID:   transaction.ID,
 		UserID:          userID,
@@ -612,8 +911,37 @@ func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transa
 		riskReq.ToWalletID = *transaction.DestinationWalletID
 	}
 
+	// Geo rules need the client's origin; background work has none
+	if client, ok := middleware.GetClientInfo(ctx); ok {
+		riskReq.ClientIP = client.IP
+		riskReq.ClientCountry = client.Country
+	}
+
+	return riskReq
+}
+
+// evaluateTransactionRisk evaluates risk for a transaction using the Risk Service.
+// Returns (blocked bool, error). If blocked is true, the transaction was rejected by risk.
+func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transaction *models.Transaction) (bool, error) {
+	if s.riskClient == nil {
+		// Never skip silently: the marker lets the re-score worker pick it up
+		s.recordRiskBypass(ctx, transaction, models.RiskBypassNotConfigured)
+		return false, nil
+	}
+
+	// Bound the call by the request deadline so a slow risk service
+	// cannot consume the caller's whole budget
+	riskCtx, cancel, budgetErr := s.riskPolicy.riskContext(ctx)
+	if budgetErr != nil {
+		return false, budgetErr
+	}
+	defer cancel()
+
+	riskReq := s.buildRiskRequest(riskCtx, transaction)
+	userID := riskReq.UserID
+
 	// Call risk service
-	result, err := s.riskClient.EvaluateTransaction(ctx, riskReq)
+	result, err := s.riskClient.EvaluateTransaction(riskCtx, riskReq)
 	if err != nil {
 		return false, err
 	}
@@ -642,6 +970,11 @@ func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transa
 
 	// Update transaction metadata in database
 	_ = s.transactionRepo.UpdateMetadata(ctx, transaction.ID, transaction.Metadata)
+	s.recordTimeline(ctx, transaction.ID, models.TimelineRiskEvaluated, transaction.Status, models.ServiceActor(actorRisk), map[string]string{
+		"action":     result.Action,
+		"risk_score": fmt.Sprintf("%d", result.RiskScore),
+		"event_id":   result.EventID,
+	})
 
 	// Handle risk actions
 	if !result.Allowed {
@@ -656,6 +989,7 @@ func (s *TransactionService) evaluateTransactionRisk(ctx context.Context, transa
 		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusFailed, &failureReason); updateErr != nil {
 			s.logger.WithError(updateErr).Error("Failed to update blocked transaction status")
 		}
+		s.recordFailed(ctx, transaction.ID, models.ServiceActor(actorRisk), failureReason)
 
 		return true, nil // blocked = true
 	}
@@ -703,13 +1037,13 @@ func (s *TransactionService) createTransferLedgerEntry(ctx context.Context, tran
 				AccountID:    sourceWalletInfo.LedgerAccountID,
 				DebitAmount:  transaction.Amount,
 				CreditAmount: 0,
-				Description:  fmt.Sprintf("Transfer to %s", *transaction.DestinationWalletID),
+				Description:  fmt.Sprintf("Transfer to %s", sourceWalletInfo.CounterpartyLabel(destWalletInfo)),
 			},
 			{
 				AccountID:    destWalletInfo.LedgerAccountID,
 				DebitAmount:  0,
 				CreditAmount: transaction.Amount,
-				Description:  fmt.Sprintf("Transfer from %s", *transaction.SourceWalletID),
+				Description:  fmt.Sprintf("Transfer from %s", destWalletInfo.CounterpartyLabel(sourceWalletInfo)),
 			},
 		},
 		Metadata: map[string]any{
@@ -717,6 +1051,9 @@ func (s *TransactionService) createTransferLedgerEntry(ctx context.Context, tran
 			"source_wallet_id":      *transaction.SourceWalletID,
 			"destination_wallet_id": *transaction.DestinationWalletID,
 		},
+		// The wallets have already moved; a closed wallet account must not
+		// leave the transfer out of the ledger
+		UseSuspense: true,
 	}
 
 	// Create and post the journal entry
@@ -730,6 +1067,10 @@ func (s *TransactionService) createTransferLedgerEntry(ctx context.Context, tran
 		"journal_entry_id": entry.ID,
 		"entry_number":     entry.EntryNumber,
 	}).Info("Ledger journal entry created for transfer")
+	s.recordTimeline(ctx, transaction.ID, models.TimelineLedgerPosted, transaction.Status, models.ServiceActor(actorLedger), map[string]string{
+		"journal_entry_id": entry.ID,
+		"entry_number":     entry.EntryNumber,
+	})
 
 	return nil
 }
@@ -750,8 +1091,8 @@ func (s *TransactionService) UpdateTransactionCategory(ctx context.Context, tran
 		return nil, err
 	}
 
-	// Update category
-	if updateErr := s.transactionRepo.UpdateCategory(ctx, transactionID, category); updateErr != nil {
+	// A category picked by hand overrides the rules
+	if updateErr := s.transactionRepo.UpdateCategory(ctx, transactionID, category, true); updateErr != nil {
 		return nil, updateErr
 	}
 
@@ -791,7 +1132,8 @@ func (s *TransactionService) GetSpendingSummary(ctx context.Context, walletID, s
 	return response, nil
 }
 
-// AutoCategorizeTransaction automatically categorizes a transaction based on its description.
+// AutoCategorizeTransaction automatically categorizes a transaction based on
+// its description, replacing any category the user chose by hand.
 func (s *TransactionService) AutoCategorizeTransaction(ctx context.Context, transactionID string) (*models.Transaction, *errors.Error) {
 	// Get transaction
 	transaction, err := s.transactionRepo.GetByID(ctx, transactionID)
@@ -804,26 +1146,11 @@ func (s *TransactionService) AutoCategorizeTransaction(ctx context.Context, tran
 	if patternErr != nil {
 		return nil, patternErr
 	}
+	matchedCategory := compileCategoryRules(patterns, s.logger).categorize(transaction.Description)
 
-	// Match description against patterns (case-insensitive)
-	descLower := strings.ToLower(transaction.Description)
-	var matchedCategory models.SpendingCategory
-
-	for _, pattern := range patterns {
-		if strings.Contains(descLower, strings.ToLower(pattern.Pattern)) {
-			matchedCategory = pattern.Category
-			break // Patterns are ordered by priority, take first match
-		}
-	}
-
-	// If no match found, default to 'other'
-	if matchedCategory == "" {
-		matchedCategory = models.CategoryOther
-	}
-
-	// Update if different from current
-	if transaction.Category != matchedCategory {
-		if updateErr := s.transactionRepo.UpdateCategory(ctx, transactionID, matchedCategory); updateErr != nil {
+	// Update if different from current, or to clear a manual override
+	if transaction.Category != matchedCategory || transaction.CategoryOverridden {
+		if updateErr := s.transactionRepo.UpdateCategory(ctx, transactionID, matchedCategory, false); updateErr != nil {
 			return nil, updateErr
 		}
 		s.logger.With(map[string]interface{}{
@@ -858,8 +1185,19 @@ type StatementData struct {
 	TotalDebits  int64
 	NetBalance   int64
 	GeneratedAt  string
+
+	// ClosingBalance is the wallet's current balance, when known. Only the OFX
+	// export uses it (as LEDGERBAL); other formats report period totals.
+	ClosingBalance *int64
 }
 
+// Transaction export formats.
+const (
+	ExportFormatCSV = "csv"
+	ExportFormatOFX = "ofx"
+	ExportFormatQIF = "qif"
+)
+
 // GetStatementData retrieves statement data for a wallet within a date range.
 func (s *TransactionService) GetStatementData(ctx context.Context, walletID, startDate, endDate string) (*StatementData, *errors.Error) {
 	// Create filter for date range
@@ -1004,6 +1342,163 @@ func (s *TransactionService) GeneratePDF(data *StatementData) []byte {
 	return []byte(content.String())
 }
 
+// GenerateOFX generates an OFX 1.02 bank statement from statement data, for
+// import into personal finance and accounting tools. Credits to the wallet are
+// positive amounts and debits negative.
+func (s *TransactionService) GenerateOFX(data *StatementData) []byte {
+	var buf strings.Builder
+
+	currency := "INR"
+	if len(data.Transactions) > 0 && data.Transactions[0].Currency != "" {
+		currency = string(data.Transactions[0].Currency)
+	}
+
+	generatedAt := time.Now()
+	if t, err := time.Parse(time.RFC3339, data.GeneratedAt); err == nil {
+		generatedAt = t
+	}
+
+	// OFX 1.x SGML header
+	buf.WriteString("OFXHEADER:100\n")
+	buf.WriteString("DATA:OFXSGML\n")
+	buf.WriteString("VERSION:102\n")
+	buf.WriteString("SECURITY:NONE\n")
+	buf.WriteString("ENCODING:USASCII\n")
+	buf.WriteString("CHARSET:1252\n")
+	buf.WriteString("COMPRESSION:NONE\n")
+	buf.WriteString("OLDFILEUID:NONE\n")
+	buf.WriteString("NEWFILEUID:NONE\n\n")
+
+	buf.WriteString("<OFX>\n")
+	buf.WriteString("<SIGNONMSGSRSV1><SONRS>\n")
+	buf.WriteString("<STATUS><CODE>0<SEVERITY>INFO</STATUS>\n")
+	buf.WriteString(fmt.Sprintf("<DTSERVER>%s\n", ofxDateTime(generatedAt)))
+	buf.WriteString("<LANGUAGE>ENG\n")
+	buf.WriteString("</SONRS></SIGNONMSGSRSV1>\n")
+	buf.WriteString("<BANKMSGSRSV1><STMTTRNRS>\n")
+	buf.WriteString("<TRNUID>0\n")
+	buf.WriteString("<STATUS><CODE>0<SEVERITY>INFO</STATUS>\n")
+	buf.WriteString("<STMTRS>\n")
+	buf.WriteString(fmt.Sprintf("<CURDEF>%s\n", currency))
+	buf.WriteString("<BANKACCTFROM>\n")
+	buf.WriteString("<BANKID>NIVO\n")
+	buf.WriteString(fmt.Sprintf("<ACCTID>%s\n", ofxEscape(data.WalletID)))
+	buf.WriteString("<ACCTTYPE>CHECKING\n")
+	buf.WriteString("</BANKACCTFROM>\n")
+
+	buf.WriteString("<BANKTRANLIST>\n")
+	buf.WriteString(fmt.Sprintf("<DTSTART>%s\n", ofxDate(data.StartDate)))
+	buf.WriteString(fmt.Sprintf("<DTEND>%s\n", ofxDate(data.EndDate)))
+	for _, tx := range data.Transactions {
+		amount, credit := signedStatementAmount(tx, data.WalletID)
+		trnType := "DEBIT"
+		if credit {
+			trnType = "CREDIT"
+		}
+
+		buf.WriteString("<STMTTRN>\n")
+		buf.WriteString(fmt.Sprintf("<TRNTYPE>%s\n", trnType))
+		buf.WriteString(fmt.Sprintf("<DTPOSTED>%s\n", ofxDateTime(tx.CreatedAt.Time)))
+		buf.WriteString(fmt.Sprintf("<TRNAMT>%s\n", formatSignedAmount(amount)))
+		buf.WriteString(fmt.Sprintf("<FITID>%s\n", ofxEscape(tx.ID)))
+		buf.WriteString(fmt.Sprintf("<NAME>%s\n", ofxEscape(truncateString(statementPayee(tx), 32))))
+		if tx.Reference != nil && *tx.Reference != "" {
+			buf.WriteString(fmt.Sprintf("<MEMO>%s\n", ofxEscape(truncateString(*tx.Reference, 255))))
+		}
+		buf.WriteString("</STMTTRN>\n")
+	}
+	buf.WriteString("</BANKTRANLIST>\n")
+
+	if data.ClosingBalance != nil {
+		buf.WriteString("<LEDGERBAL>\n")
+		buf.WriteString(fmt.Sprintf("<BALAMT>%s\n", formatSignedAmount(*data.ClosingBalance)))
+		buf.WriteString(fmt.Sprintf("<DTASOF>%s\n", ofxDateTime(generatedAt)))
+		buf.WriteString("</LEDGERBAL>\n")
+	}
+
+	buf.WriteString("</STMTRS>\n")
+	buf.WriteString("</STMTTRNRS></BANKMSGSRSV1>\n")
+	buf.WriteString("</OFX>\n")
+
+	return []byte(buf.String())
+}
+
+// GenerateQIF generates a QIF bank register from statement data. Credits to
+// the wallet are positive amounts and debits negative.
+func (s *TransactionService) GenerateQIF(data *StatementData) []byte {
+	var buf strings.Builder
+
+	buf.WriteString("!Type:Bank\n")
+	for _, tx := range data.Transactions {
+		amount, _ := signedStatementAmount(tx, data.WalletID)
+
+		buf.WriteString("D" + tx.CreatedAt.Format("01/02/2006") + "\n")
+		buf.WriteString("T" + formatSignedAmount(amount) + "\n")
+		buf.WriteString("N" + tx.ID + "\n")
+		buf.WriteString("P" + qifField(statementPayee(tx)) + "\n")
+		if tx.Reference != nil && *tx.Reference != "" {
+			buf.WriteString("M" + qifField(*tx.Reference) + "\n")
+		}
+		if tx.Category != "" {
+			buf.WriteString("L" + qifField(string(tx.Category)) + "\n")
+		}
+		buf.WriteString("^\n")
+	}
+
+	return []byte(buf.String())
+}
+
+// signedStatementAmount returns the transaction amount from the wallet's
+// perspective: positive when the wallet is credited, negative when debited.
+func signedStatementAmount(tx *models.Transaction, walletID string) (int64, bool) {
+	if tx.DestinationWalletID != nil && *tx.DestinationWalletID == walletID {
+		return tx.Amount, true
+	}
+	return -tx.Amount, false
+}
+
+// statementPayee returns the payee line for accounting exports, falling back
+// to the transaction type when there is no description.
+func statementPayee(tx *models.Transaction) string {
+	if tx.Description != "" {
+		return tx.Description
+	}
+	return string(tx.Type)
+}
+
+// formatSignedAmount formats an amount in paise as a signed rupee string,
+// including zero (unlike formatAmount).
+func formatSignedAmount(paise int64) string {
+	sign := ""
+	if paise < 0 {
+		sign = "-"
+		paise = -paise
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, paise/100, paise%100)
+}
+
+// ofxDate converts an ISO date (YYYY-MM-DD) to the OFX date format.
+func ofxDate(isoDate string) string {
+	return strings.ReplaceAll(isoDate, "-", "")
+}
+
+// ofxDateTime formats a timestamp as an OFX datetime in UTC.
+func ofxDateTime(t time.Time) string {
+	return t.UTC().Format("20060102150405") + "[0:GMT]"
+}
+
+// ofxEscape escapes SGML special characters and flattens line breaks, which
+// would otherwise end an OFX element value.
+func ofxEscape(s string) string {
+	s = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ").Replace(s)
+	return strings.TrimSpace(s)
+}
+
+// qifField flattens line breaks, since every QIF field occupies one line.
+func qifField(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r", " ", "\n", " ").Replace(s))
+}
+
 // escapeCSV escapes a string for CSV output.
 func escapeCSV(s string) string {
 	// Prevent CSV injection by prefixing cells that start with formula characters
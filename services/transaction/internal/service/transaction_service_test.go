@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
 	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 	"github.com/google/uuid"
 )
@@ -19,6 +25,7 @@ type mockTransactionRepository struct {
 	createFunc       func(ctx context.Context, transaction *models.Transaction) *errors.Error
 	getByIDFunc      func(ctx context.Context, id string) (*models.Transaction, *errors.Error)
 	listByWalletFunc func(ctx context.Context, walletID string, filter *models.TransactionFilter) ([]*models.Transaction, *errors.Error)
+	updateStatusFunc func(ctx context.Context, id string, status models.TransactionStatus, failureReason *string) *errors.Error
 }
 
 func (m *mockTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) *errors.Error {
@@ -89,6 +96,9 @@ func (m *mockTransactionRepository) CompleteWithMetadata(ctx context.Context, id
 }
 
 func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, id string, status models.TransactionStatus, failureReason *string) *errors.Error {
+	if m.updateStatusFunc != nil {
+		return m.updateStatusFunc(ctx, id, status, failureReason)
+	}
 	tx, ok := m.transactions[id]
 	if !ok {
 		return errors.NotFound("transaction")
@@ -98,12 +108,13 @@ func (m *mockTransactionRepository) UpdateStatus(ctx context.Context, id string,
 	return nil
 }
 
-func (m *mockTransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory) *errors.Error {
+func (m *mockTransactionRepository) UpdateCategory(ctx context.Context, id string, category models.SpendingCategory, overridden bool) *errors.Error {
 	tx, ok := m.transactions[id]
 	if !ok {
 		return errors.NotFound("transaction")
 	}
 	tx.Category = category
+	tx.CategoryOverridden = overridden
 	return nil
 }
 
@@ -199,8 +210,8 @@ func TestCreateTransfer_Error_SameWallet(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for same source and destination wallet, got nil")
 	}
-	if err.Code != errors.ErrCodeBadRequest {
-		t.Errorf("expected bad request error, got %s", err.Code)
+	if err.Code != errors.ErrCodeTransferSameWallet {
+		t.Errorf("expected same wallet error, got %s", err.Code)
 	}
 	if err.Message != "source and destination wallets must be different" {
 		t.Errorf("unexpected error message: %s", err.Message)
@@ -230,6 +241,85 @@ func TestCreateTransfer_Success_WithoutReference(t *testing.T) {
 	}
 }
 
+func TestCreateTransfer_Error_BeneficiaryCoolingOff(t *testing.T) {
+	var reservation CoolingOffReservation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/internal/v1/beneficiaries/cooling-off/reserve" {
+			t.Errorf("unexpected wallet call %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&reservation)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		_, _ = w.Write([]byte(`{"success":false,"error":{"code":"LIMIT_COOLING_OFF","message":"John was added recently"}}`))
+	}))
+	defer server.Close()
+
+	repo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	service := NewTransactionService(repo, nil, NewWalletClient(server.URL), nil, nil)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "user-1")
+
+	req := newTransferRequest(600000)
+	_, err := service.CreateTransfer(ctx, req)
+	if err == nil || err.Code != errors.ErrCodeLimitCoolingOff {
+		t.Fatalf("expected cooling-off error, got %v", err)
+	}
+	if reservation.OwnerUserID != "user-1" || reservation.DestinationWalletID != req.DestinationWalletID || reservation.Amount != 600000 {
+		t.Errorf("unexpected reservation %+v", reservation)
+	}
+	if len(repo.transactions) != 0 {
+		t.Errorf("expected no transaction to be created, got %d", len(repo.transactions))
+	}
+}
+
+func TestCreateTransfer_ReleasesCoolingOffWhenTransferFails(t *testing.T) {
+	var reserved, released []CoolingOffReservation
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reservation CoolingOffReservation
+		switch r.URL.Path {
+		case "/internal/v1/beneficiaries/cooling-off/reserve":
+			_ = json.NewDecoder(r.Body).Decode(&reservation)
+			reserved = append(reserved, reservation)
+			w.WriteHeader(http.StatusNoContent)
+		case "/internal/v1/beneficiaries/cooling-off/release":
+			_ = json.NewDecoder(r.Body).Decode(&reservation)
+			released = append(released, reservation)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":"INSUFFICIENT_FUNDS","message":"insufficient balance"}}`))
+		}
+	}))
+	defer server.Close()
+
+	repo := &mockTransactionRepository{transactions: make(map[string]*models.Transaction)}
+	service := NewTransactionService(repo, nil, NewWalletClient(server.URL), nil, nil)
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "user-1")
+
+	req := newTransferRequest(300000)
+	tx, err := service.CreateTransfer(ctx, req)
+	if err != nil {
+		t.Fatalf("expected the failed transaction to be returned, got %v", err)
+	}
+	if tx.Status != models.TransactionStatusFailed {
+		t.Fatalf("expected failed transfer, got %s", tx.Status)
+	}
+	if len(reserved) != 1 || len(released) != 1 || released[0] != reserved[0] {
+		t.Errorf("expected the reservation %+v to be released, got %+v", reserved, released)
+	}
+
+	// Nor does a transfer that could not be recorded keep its reservation
+	repo.createFunc = func(ctx context.Context, transaction *models.Transaction) *errors.Error {
+		return errors.Internal("insert failed")
+	}
+	if _, err := service.CreateTransfer(ctx, req); err == nil {
+		t.Fatal("expected the create error to be returned")
+	}
+	if len(reserved) != 2 || len(released) != 2 {
+		t.Errorf("expected both reservations released, got %d reserved and %d released", len(reserved), len(released))
+	}
+}
+
 // =====================================================================
 // CreateDeposit Tests - CRITICAL PATH (100% coverage needed)
 // =====================================================================
@@ -434,8 +524,8 @@ func TestReverseTransaction_Error_NotCompleted(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for non-completed transaction, got nil")
 	}
-	if err.Code != errors.ErrCodeBadRequest {
-		t.Errorf("expected bad request error, got %s", err.Code)
+	if err.Code != errors.ErrCodeTransactionNotReversible {
+		t.Errorf("expected not reversible error, got %s", err.Code)
 	}
 	if err.Message != "only completed transactions can be reversed" {
 		t.Errorf("unexpected error message: %s", err.Message)
@@ -463,8 +553,8 @@ func TestReverseTransaction_Error_CannotReverseReversal(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for reversing a reversal, got nil")
 	}
-	if err.Code != errors.ErrCodeBadRequest {
-		t.Errorf("expected bad request error, got %s", err.Code)
+	if err.Code != errors.ErrCodeTransactionNotReversible {
+		t.Errorf("expected not reversible error, got %s", err.Code)
 	}
 	if err.Message != "cannot reverse a reversal transaction" {
 		t.Errorf("unexpected error message: %s", err.Message)
@@ -642,6 +732,118 @@ func TestListWalletTransactions_Success(t *testing.T) {
 	}
 }
 
+// =====================================================================
+// Accounting Export Tests
+// =====================================================================
+
+func newExportStatementData() *StatementData {
+	walletID := "wallet-123"
+	postedAt := sharedModels.Timestamp{Time: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)}
+
+	return &StatementData{
+		WalletID:  walletID,
+		StartDate: "2025-01-01",
+		EndDate:   "2025-01-31",
+		Transactions: []*models.Transaction{
+			{
+				ID:                  "tx-credit",
+				Type:                models.TransactionTypeDeposit,
+				Status:              models.TransactionStatusCompleted,
+				DestinationWalletID: &walletID,
+				Amount:              150050,
+				Currency:            sharedModels.INR,
+				Description:         "Salary & bonus",
+				Category:            models.CategoryTransfer,
+				CreatedAt:           postedAt,
+			},
+			{
+				ID:             "tx-debit",
+				Type:           models.TransactionTypeWithdrawal,
+				Status:         models.TransactionStatusCompleted,
+				SourceWalletID: &walletID,
+				Amount:         2500,
+				Currency:       sharedModels.INR,
+				Reference:      ptrString("ATM\nwithdrawal"),
+				CreatedAt:      postedAt,
+			},
+		},
+		TotalCredits: 150050,
+		TotalDebits:  2500,
+		NetBalance:   147550,
+		GeneratedAt:  "2025-02-01T00:00:00Z",
+	}
+}
+
+func TestGenerateOFX(t *testing.T) {
+	service := &TransactionService{}
+	data := newExportStatementData()
+	balance := int64(147550)
+	data.ClosingBalance = &balance
+
+	ofx := string(service.GenerateOFX(data))
+
+	for _, want := range []string{
+		"OFXHEADER:100",
+		"<CURDEF>INR",
+		"<ACCTID>wallet-123",
+		"<DTSTART>20250101",
+		"<DTEND>20250131",
+		"<TRNTYPE>CREDIT\n<DTPOSTED>20250115103000[0:GMT]\n<TRNAMT>1500.50\n<FITID>tx-credit",
+		"<NAME>Salary &amp; bonus",
+		"<TRNTYPE>DEBIT",
+		"<TRNAMT>-25.00",
+		"<NAME>withdrawal",
+		"<MEMO>ATM withdrawal",
+		"<BALAMT>1475.50",
+	} {
+		if !strings.Contains(ofx, want) {
+			t.Errorf("expected OFX to contain %q, got:\n%s", want, ofx)
+		}
+	}
+}
+
+func TestGenerateOFX_WithoutClosingBalance(t *testing.T) {
+	service := &TransactionService{}
+
+	ofx := string(service.GenerateOFX(newExportStatementData()))
+
+	if strings.Contains(ofx, "<LEDGERBAL>") {
+		t.Error("expected no LEDGERBAL without a closing balance")
+	}
+}
+
+func TestGenerateQIF(t *testing.T) {
+	service := &TransactionService{}
+
+	qif := string(service.GenerateQIF(newExportStatementData()))
+
+	expected := "!Type:Bank\n" +
+		"D01/15/2025\nT1500.50\nNtx-credit\nPSalary & bonus\nLtransfer\n^\n" +
+		"D01/15/2025\nT-25.00\nNtx-debit\nPwithdrawal\nMATM withdrawal\n^\n"
+	if qif != expected {
+		t.Errorf("unexpected QIF output:\n%s", qif)
+	}
+}
+
+func TestFormatSignedAmount(t *testing.T) {
+	tests := []struct {
+		paise    int64
+		expected string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{150050, "1500.50"},
+		{-2500, "-25.00"},
+		{-99, "-0.99"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSignedAmount(tt.paise); got != tt.expected {
+			t.Errorf("formatSignedAmount(%d) = %q, want %q", tt.paise, got, tt.expected)
+		}
+	}
+}
+
 // =====================================================================
 // Helper Functions
 // =====================================================================
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/errors"
@@ -41,6 +42,14 @@ type CheckAndReserveLimitRequest struct {
 	Amount   int64  `json:"amount"`
 }
 
+// CoolingOffReservation reserves a transfer against the cooling-off cap of a
+// newly added beneficiary.
+type CoolingOffReservation struct {
+	OwnerUserID         string `json:"owner_user_id"`
+	DestinationWalletID string `json:"destination_wallet_id"`
+	Amount              int64  `json:"amount"`
+}
+
 // TransferRequest represents an internal wallet transfer request.
 type TransferRequest struct {
 	SourceWalletID      string `json:"source_wallet_id"`
@@ -58,12 +67,92 @@ type DepositRequest struct {
 	Description   string `json:"description"`
 }
 
+// ClosureSweepExecution moves a closing wallet's balance (internal endpoint).
+type ClosureSweepExecution struct {
+	ClosureID           string `json:"closure_id"`
+	WalletID            string `json:"wallet_id"`
+	DestinationWalletID string `json:"destination_wallet_id,omitempty"`
+	Amount              int64  `json:"amount"`
+	TransactionID       string `json:"transaction_id"`
+}
+
 // WalletInfo represents wallet details including ownership.
 type WalletInfo struct {
-	ID              string `json:"id"`
-	UserID          string `json:"user_id"`
-	Status          string `json:"status"`
-	LedgerAccountID string `json:"ledger_account_id"`
+	ID               string         `json:"id"`
+	UserID           string         `json:"user_id"`
+	Status           string         `json:"status"`
+	Currency         string         `json:"currency"`
+	AvailableBalance int64          `json:"available_balance"`
+	LedgerAccountID  string         `json:"ledger_account_id"`
+	Nickname         string         `json:"nickname,omitempty"` // Set by the wallet's owners
+	Members          []WalletMember `json:"members"`            // Active co-owners of a joint wallet
+}
+
+// WalletLimits represents a wallet's transfer limits and spend.
+type WalletLimits struct {
+	WalletID       string    `json:"wallet_id"`
+	DailyLimit     int64     `json:"daily_limit"`
+	DailySpent     int64     `json:"daily_spent"`
+	DailyResetAt   time.Time `json:"daily_reset_at"`
+	MonthlyLimit   int64     `json:"monthly_limit"`
+	MonthlySpent   int64     `json:"monthly_spent"`
+	MonthlyResetAt time.Time `json:"monthly_reset_at"`
+}
+
+// WalletMember is a co-owner of a joint wallet.
+type WalletMember struct {
+	UserID     string `json:"user_id"`
+	Permission string `json:"permission"`
+}
+
+// Joint wallet permissions, each including the ones before it.
+const (
+	WalletPermissionView     = "view"
+	WalletPermissionTransact = "transact"
+	WalletPermissionAdmin    = "admin"
+)
+
+var walletPermissionRank = map[string]int{
+	WalletPermissionView:     1,
+	WalletPermissionTransact: 2,
+	WalletPermissionAdmin:    3,
+}
+
+// Allows reports whether userID may act on the wallet with the required
+// permission: the owner always can, co-owners up to their permission.
+func (w *WalletInfo) Allows(userID, required string) bool {
+	if w.UserID == userID {
+		return true
+	}
+	for _, member := range w.Members {
+		if member.UserID == userID {
+			rank, ok := walletPermissionRank[member.Permission]
+			return ok && rank >= walletPermissionRank[required]
+		}
+	}
+	return false
+}
+
+// CounterpartyLabel returns how the counterparty wallet of a transfer is
+// described on this wallet's side: by its nickname when both wallets belong
+// to the same owner, else by its ID. Other users' nicknames are never shown.
+func (w *WalletInfo) CounterpartyLabel(counterparty *WalletInfo) string {
+	if counterparty.Nickname != "" && counterparty.UserID != "" && counterparty.UserID == w.UserID {
+		return counterparty.Nickname
+	}
+	return counterparty.ID
+}
+
+// OwnerIDs returns the owner and co-owners of the wallet.
+func (w *WalletInfo) OwnerIDs() []string {
+	ids := make([]string, 0, len(w.Members)+1)
+	if w.UserID != "" {
+		ids = append(ids, w.UserID)
+	}
+	for _, member := range w.Members {
+		ids = append(ids, member.UserID)
+	}
+	return ids
 }
 
 // GetBalance retrieves the balance of a wallet.
@@ -87,6 +176,19 @@ func (c *WalletClient) CheckAndReserveLimit(ctx context.Context, walletID string
 	return c.Post(ctx, path, req, nil)
 }
 
+// ReserveCoolingOff reserves a transfer's amount if the destination wallet is
+// a beneficiary of the user still in its cooling-off period (internal
+// endpoint). It returns LIMIT_COOLING_OFF when the amount exceeds the cap.
+func (c *WalletClient) ReserveCoolingOff(ctx context.Context, req *CoolingOffReservation) *errors.Error {
+	return c.Post(ctx, "/internal/v1/beneficiaries/cooling-off/reserve", req, nil)
+}
+
+// ReleaseCoolingOff gives back a reservation made by ReserveCoolingOff for a
+// transfer that failed (internal endpoint).
+func (c *WalletClient) ReleaseCoolingOff(ctx context.Context, req *CoolingOffReservation) *errors.Error {
+	return c.Post(ctx, "/internal/v1/beneficiaries/cooling-off/release", req, nil)
+}
+
 // ExecuteTransfer executes a wallet-to-wallet transfer (internal endpoint).
 // This updates wallet balances and creates holds as needed.
 func (c *WalletClient) ExecuteTransfer(ctx context.Context, req *TransferRequest) *errors.Error {
@@ -99,6 +201,25 @@ func (c *WalletClient) CreditDeposit(ctx context.Context, req *DepositRequest) *
 	return c.Post(ctx, "/internal/v1/wallets/deposit", req, nil)
 }
 
+// ExecuteClosureSweep moves a closing wallet's balance to the destination
+// wallet, or out for a withdrawal (internal endpoint).
+func (c *WalletClient) ExecuteClosureSweep(ctx context.Context, req *ClosureSweepExecution) *errors.Error {
+	return c.Post(ctx, "/internal/v1/wallets/closure-sweeps", req, nil)
+}
+
+// IsTransferProcessed reports whether the wallet service has moved the
+// balances of a transfer (internal endpoint).
+func (c *WalletClient) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	var result struct {
+		Processed bool `json:"processed"`
+	}
+	path := fmt.Sprintf("/internal/v1/wallets/transfers/%s", transactionID)
+	if err := c.Get(ctx, path, &result); err != nil {
+		return false, err
+	}
+	return result.Processed, nil
+}
+
 // GetWalletInfo retrieves wallet information including owner (internal endpoint).
 func (c *WalletClient) GetWalletInfo(ctx context.Context, walletID string) (*WalletInfo, *errors.Error) {
 	var result WalletInfo
@@ -109,14 +230,31 @@ func (c *WalletClient) GetWalletInfo(ctx context.Context, walletID string) (*Wal
 	return &result, nil
 }
 
-// VerifyWalletOwnership checks if a wallet belongs to the specified user.
+// GetLimits retrieves a wallet's transfer limits (internal endpoint).
+func (c *WalletClient) GetLimits(ctx context.Context, walletID string) (*WalletLimits, *errors.Error) {
+	var result WalletLimits
+	path := fmt.Sprintf("/internal/v1/wallets/%s/limits", walletID)
+	if err := c.Get(ctx, path, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// VerifyWalletOwnership checks if a wallet belongs to the specified user,
+// either as its owner or as a co-owner of a joint wallet.
 func (c *WalletClient) VerifyWalletOwnership(ctx context.Context, walletID, userID string) *errors.Error {
+	return c.VerifyWalletAccess(ctx, walletID, userID, WalletPermissionView)
+}
+
+// VerifyWalletAccess checks if the specified user may act on a wallet with
+// the required joint wallet permission.
+func (c *WalletClient) VerifyWalletAccess(ctx context.Context, walletID, userID, required string) *errors.Error {
 	info, err := c.GetWalletInfo(ctx, walletID)
 	if err != nil {
 		return err
 	}
 
-	if info.UserID != userID {
+	if !info.Allows(userID, required) {
 		return errors.Forbidden("wallet does not belong to user")
 	}
 
@@ -0,0 +1,53 @@
+package service
+
+import "testing"
+
+func TestWalletInfo_Allows(t *testing.T) {
+	info := &WalletInfo{
+		UserID: "owner",
+		Members: []WalletMember{
+			{UserID: "viewer", Permission: WalletPermissionView},
+			{UserID: "spender", Permission: WalletPermissionTransact},
+		},
+	}
+
+	tests := []struct {
+		userID   string
+		required string
+		want     bool
+	}{
+		{"owner", WalletPermissionAdmin, true},
+		{"viewer", WalletPermissionView, true},
+		{"viewer", WalletPermissionTransact, false},
+		{"spender", WalletPermissionTransact, true},
+		{"spender", WalletPermissionAdmin, false},
+		{"stranger", WalletPermissionView, false},
+	}
+
+	for _, tt := range tests {
+		if got := info.Allows(tt.userID, tt.required); got != tt.want {
+			t.Errorf("Allows(%s, %s) = %v, want %v", tt.userID, tt.required, got, tt.want)
+		}
+	}
+
+	if owners := info.OwnerIDs(); len(owners) != 3 || owners[0] != "owner" {
+		t.Errorf("unexpected owner IDs: %v", owners)
+	}
+}
+
+func TestWalletInfo_CounterpartyLabel(t *testing.T) {
+	mine := &WalletInfo{ID: "w1", UserID: "owner"}
+	myOther := &WalletInfo{ID: "w2", UserID: "owner", Nickname: "Travel fund"}
+	theirs := &WalletInfo{ID: "w3", UserID: "someone", Nickname: "Secret stash"}
+	unnamed := &WalletInfo{ID: "w4", UserID: "owner"}
+
+	if got := mine.CounterpartyLabel(myOther); got != "Travel fund" {
+		t.Errorf("expected own wallet nickname, got %q", got)
+	}
+	if got := mine.CounterpartyLabel(theirs); got != "w3" {
+		t.Errorf("expected another user's wallet by ID, got %q", got)
+	}
+	if got := mine.CounterpartyLabel(unnamed); got != "w4" {
+		t.Errorf("expected unnamed wallet by ID, got %q", got)
+	}
+}
@@ -0,0 +1,467 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OperationApproveWithdrawal is the approval operation for withdrawals from
+// the admin threshold.
+const OperationApproveWithdrawal = "transaction.withdrawal.approve"
+
+// OpWithdrawalConfirmation is the identity verification operation that
+// confirms a withdrawal held for step-up.
+const OpWithdrawalConfirmation = "withdrawal_confirmation"
+
+// withdrawalScanBatch bounds how many withdrawals of each stage one expiry
+// scan decides.
+const withdrawalScanBatch = 100
+
+// WithdrawalApprovalPolicy configures which withdrawals are held for
+// confirmation and approval.
+type WithdrawalApprovalPolicy struct {
+	StepUpThreshold int64         // Withdrawals of at least this amount (paise) need the owner's step-up confirmation; 0 disables holds
+	AdminThreshold  int64         // Held withdrawals of at least this amount also need an admin's approval; 0 disables
+	ConfirmationTTL time.Duration // How long the owner has to confirm
+}
+
+// DefaultWithdrawalApprovalPolicy returns the default withdrawal approval
+// policy: confirmation from ₹50,000 within 15 minutes, and admin approval from
+// ₹2,00,000.
+func DefaultWithdrawalApprovalPolicy() WithdrawalApprovalPolicy {
+	return WithdrawalApprovalPolicy{
+		StepUpThreshold: 5000000,
+		AdminThreshold:  20000000,
+		ConfirmationTTL: 15 * time.Minute,
+	}
+}
+
+// WithdrawalApprovalRepositoryInterface defines the interface for withdrawal
+// approval storage.
+type WithdrawalApprovalRepositoryInterface interface {
+	Create(ctx context.Context, a *models.WithdrawalApproval) *errors.Error
+	Get(ctx context.Context, transactionID string) (*models.WithdrawalApproval, *errors.Error)
+	Confirm(ctx context.Context, transactionID, verificationID string, stage models.WithdrawalApprovalStage, approvalID *string, expiresAt time.Time) (*models.WithdrawalApproval, *errors.Error)
+	Decide(ctx context.Context, transactionID string, from, to models.WithdrawalApprovalStage) (*models.WithdrawalApproval, *errors.Error)
+	ListOpen(ctx context.Context, stage models.WithdrawalApprovalStage, cutoff time.Time, limit int) ([]*models.WithdrawalApproval, *errors.Error)
+}
+
+// withdrawalApprovals holds large withdrawals until they are confirmed and,
+// above the admin threshold, approved.
+type withdrawalApprovals struct {
+	repo               WithdrawalApprovalRepositoryInterface
+	approvals          *approval.Manager
+	policy             WithdrawalApprovalPolicy
+	verificationSecret []byte
+}
+
+// withdrawalPayload is the stored request for an admin-approved withdrawal.
+type withdrawalPayload struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// verificationClaims are the claims of an identity verification token.
+type verificationClaims struct {
+	VerificationID string                 `json:"verification_id"`
+	UserID         string                 `json:"user_id"`
+	OperationType  string                 `json:"operation_type"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SetWithdrawalApprovals holds withdrawals from the policy's step-up
+// threshold as pending_approval until the owner confirms them with an
+// identity verification token, signed with verificationSecret. From the
+// admin threshold, a confirmed withdrawal also waits for an admin to approve
+// it through approvals; without approvals no admin approval is required.
+func (s *TransactionService) SetWithdrawalApprovals(repo WithdrawalApprovalRepositoryInterface, approvals *approval.Manager, policy WithdrawalApprovalPolicy, verificationSecret string) {
+	if policy.ConfirmationTTL <= 0 {
+		policy.ConfirmationTTL = DefaultWithdrawalApprovalPolicy().ConfirmationTTL
+	}
+	if approvals != nil {
+		approvals.Register(OperationApproveWithdrawal, s.executeWithdrawalApproval)
+	} else {
+		policy.AdminThreshold = 0
+	}
+	s.withdrawals = &withdrawalApprovals{
+		repo:               repo,
+		approvals:          approvals,
+		policy:             policy,
+		verificationSecret: []byte(verificationSecret),
+	}
+}
+
+// SetNotificationClient sends users notifications about their withdrawals.
+func (s *TransactionService) SetNotificationClient(client *clients.NotificationClient) {
+	s.notificationClient = client
+}
+
+// withdrawalNeedsApproval returns true if a withdrawal of amount is held for
+// confirmation.
+func (s *TransactionService) withdrawalNeedsApproval(amount int64) bool {
+	return s.withdrawals != nil && s.withdrawals.policy.StepUpThreshold > 0 && amount >= s.withdrawals.policy.StepUpThreshold
+}
+
+// holdWithdrawal starts the approval of a withdrawal created as
+// pending_approval. If it cannot be stored the withdrawal is cancelled.
+func (s *TransactionService) holdWithdrawal(ctx context.Context, transaction *models.Transaction, userID string) (*models.WithdrawalApproval, *errors.Error) {
+	policy := s.withdrawals.policy
+	hold := &models.WithdrawalApproval{
+		TransactionID: transaction.ID,
+		UserID:        userID,
+		WalletID:      *transaction.SourceWalletID,
+		Amount:        transaction.Amount,
+		Stage:         models.WithdrawalAwaitingConfirmation,
+		RequiresAdmin: policy.AdminThreshold > 0 && transaction.Amount >= policy.AdminThreshold,
+	}
+	hold.ExpiresAt.Time = time.Now().Add(policy.ConfirmationTTL)
+
+	if err := s.withdrawals.repo.Create(ctx, hold); err != nil {
+		reason := "withdrawal approval could not be recorded"
+		if updateErr := s.transactionRepo.UpdateStatus(ctx, transaction.ID, models.TransactionStatusCancelled, &reason); updateErr != nil {
+			s.logger.WithError(updateErr).WithField("transaction_id", transaction.ID).Error("Failed to cancel unheld withdrawal")
+		}
+		s.recordTimeline(ctx, transaction.ID, models.TimelineCancelled, models.TransactionStatusCancelled, models.ServiceActor(actorTransaction),
+			map[string]string{"reason": reason})
+		return nil, err
+	}
+
+	s.notifyWithdrawal(hold, fmt.Sprintf("Confirm your withdrawal of ₹%.2f within %d minutes, or it will be cancelled",
+		float64(hold.Amount)/100, int(policy.ConfirmationTTL.Minutes())))
+	s.publishWithdrawalEvent("transaction.withdrawal.confirmation_required", hold, nil)
+
+	s.logger.With(map[string]interface{}{
+		"transaction_id": transaction.ID,
+		"amount":         transaction.Amount,
+		"requires_admin": hold.RequiresAdmin,
+	}).Info("Withdrawal held for confirmation")
+	return hold, nil
+}
+
+// GetWithdrawalApproval returns the approval state of a held withdrawal.
+func (s *TransactionService) GetWithdrawalApproval(ctx context.Context, transactionID string) (*models.WithdrawalApproval, *errors.Error) {
+	if s.withdrawals == nil {
+		return nil, errors.NotFoundWithID("withdrawal approval", transactionID)
+	}
+	return s.withdrawals.repo.Get(ctx, transactionID)
+}
+
+// ConfirmWithdrawal confirms a held withdrawal for the user who requested
+// it. The token must come from a verification of the withdrawal_confirmation
+// operation by that user, with the withdrawal's ID as
+// metadata.transaction_id. The withdrawal is then released, or sent to an
+// admin for approval when it reaches the admin threshold.
+func (s *TransactionService) ConfirmWithdrawal(ctx context.Context, transactionID, userID string, req *models.ConfirmWithdrawalRequest) (*models.WithdrawalResponse, *errors.Error) {
+	hold, err := s.openWithdrawal(ctx, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Stage != models.WithdrawalAwaitingConfirmation {
+		return nil, errors.Conflict(fmt.Sprintf("withdrawal is %s, not awaiting confirmation", hold.Stage))
+	}
+	if time.Now().After(hold.ExpiresAt.Time) {
+		if _, closeErr := s.closeWithdrawal(ctx, hold, models.WithdrawalExpired, "not confirmed in time", models.ServiceActor(actorTransaction)); closeErr != nil {
+			return nil, closeErr
+		}
+		return nil, errors.Conflict("withdrawal confirmation has expired")
+	}
+
+	claims, err := s.validateWithdrawalToken(req.VerificationToken, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id, _ := claims.Metadata["transaction_id"].(string); id != transactionID {
+		return nil, errors.Forbidden("verification token is for a different withdrawal")
+	}
+
+	stage := models.WithdrawalReleased
+	expiresAt := hold.ExpiresAt.Time
+	var approvalID *string
+	if hold.RequiresAdmin {
+		summary := fmt.Sprintf("Withdraw %d paise from wallet %s", hold.Amount, hold.WalletID)
+		pending, reqErr := s.withdrawals.approvals.Request(ctx, OperationApproveWithdrawal, transactionID, userID, summary,
+			withdrawalPayload{TransactionID: transactionID})
+		if reqErr != nil {
+			return nil, reqErr
+		}
+		stage = models.WithdrawalAwaitingApproval
+		expiresAt = pending.ExpiresAt
+		approvalID = &pending.ID
+	}
+
+	confirmed, err := s.withdrawals.repo.Confirm(ctx, transactionID, claims.VerificationID, stage, approvalID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	s.recordTimeline(ctx, transactionID, models.TimelineConfirmed, models.TransactionStatusPendingApproval, models.TimelineActor{Type: models.ActorUser, ID: userID},
+		map[string]string{"verification_id": claims.VerificationID})
+
+	if confirmed.Stage == models.WithdrawalReleased {
+		if err := s.releaseWithdrawal(ctx, confirmed, models.TimelineActor{Type: models.ActorUser, ID: userID}); err != nil {
+			return nil, err
+		}
+	} else {
+		s.recordTimeline(ctx, transactionID, models.TimelineApprovalRequested, models.TransactionStatusPendingApproval, models.ServiceActor(actorTransaction),
+			map[string]string{"approval_id": *confirmed.ApprovalID})
+		s.notifyWithdrawal(confirmed, fmt.Sprintf("Your withdrawal of ₹%.2f is confirmed and waiting for approval", float64(confirmed.Amount)/100))
+		s.publishWithdrawalEvent("transaction.withdrawal.approval_requested", confirmed, map[string]interface{}{
+			"approval_id": *confirmed.ApprovalID,
+		})
+	}
+
+	return s.withdrawalResponse(ctx, confirmed)
+}
+
+// CancelWithdrawal cancels a held withdrawal for the user who requested it.
+// An admin approval it was waiting for can no longer release it.
+func (s *TransactionService) CancelWithdrawal(ctx context.Context, transactionID, userID string) (*models.WithdrawalResponse, *errors.Error) {
+	hold, err := s.openWithdrawal(ctx, transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.closeWithdrawal(ctx, hold, models.WithdrawalCancelled, "cancelled by owner", models.TimelineActor{Type: models.ActorUser, ID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return s.withdrawalResponse(ctx, cancelled)
+}
+
+// ExpireWithdrawalApprovals cancels held withdrawals that were not confirmed
+// in time, and those whose admin approval was rejected or expired. It
+// returns how many withdrawals it cancelled.
+func (s *TransactionService) ExpireWithdrawalApprovals(ctx context.Context) (int, *errors.Error) {
+	if s.withdrawals == nil {
+		return 0, nil
+	}
+
+	closed := 0
+	unconfirmed, err := s.withdrawals.repo.ListOpen(ctx, models.WithdrawalAwaitingConfirmation, time.Now(), withdrawalScanBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, hold := range unconfirmed {
+		if _, err := s.closeWithdrawal(ctx, hold, models.WithdrawalExpired, "not confirmed in time", models.ServiceActor(actorTransaction)); err != nil {
+			s.logger.WithError(err).WithField("transaction_id", hold.TransactionID).Warn("Failed to expire withdrawal")
+			continue
+		}
+		closed++
+	}
+
+	if s.withdrawals.approvals == nil {
+		return closed, nil
+	}
+	awaiting, err := s.withdrawals.repo.ListOpen(ctx, models.WithdrawalAwaitingApproval, time.Time{}, withdrawalScanBatch)
+	if err != nil {
+		return closed, err
+	}
+	for _, hold := range awaiting {
+		if hold.ApprovalID == nil {
+			continue
+		}
+		pending, err := s.withdrawals.approvals.Get(ctx, *hold.ApprovalID)
+		if err != nil {
+			s.logger.WithError(err).WithField("transaction_id", hold.TransactionID).Warn("Failed to check withdrawal approval")
+			continue
+		}
+
+		var stage models.WithdrawalApprovalStage
+		var reason string
+		switch {
+		case pending.Status == approval.StatusRejected:
+			stage, reason = models.WithdrawalRejected, "rejected by an admin"
+			if pending.ReviewNote != nil && *pending.ReviewNote != "" {
+				reason += ": " + *pending.ReviewNote
+			}
+		case pending.Status == approval.StatusExpired || pending.IsExpired():
+			stage, reason = models.WithdrawalExpired, "not approved in time"
+		default:
+			continue
+		}
+
+		if _, err := s.closeWithdrawal(ctx, hold, stage, reason, models.ServiceActor(actorTransaction)); err != nil {
+			s.logger.WithError(err).WithField("transaction_id", hold.TransactionID).Warn("Failed to close withdrawal")
+			continue
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
+// executeWithdrawalApproval releases a withdrawal an admin approved.
+func (s *TransactionService) executeWithdrawalApproval(ctx context.Context, a *approval.Approval) (any, *errors.Error) {
+	var payload withdrawalPayload
+	if err := a.DecodePayload(&payload); err != nil {
+		return nil, err
+	}
+	if s.withdrawals == nil {
+		return nil, errors.Internal("withdrawal approvals are not configured")
+	}
+
+	released, err := s.withdrawals.repo.Decide(ctx, payload.TransactionID, models.WithdrawalAwaitingApproval, models.WithdrawalReleased)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := models.ServiceActor(actorTransaction)
+	if a.ReviewedBy != nil {
+		actor = models.TimelineActor{Type: models.ActorUser, ID: *a.ReviewedBy}
+	}
+	if err := s.releaseWithdrawal(ctx, released, actor); err != nil {
+		return nil, err
+	}
+	return s.transactionRepo.GetByID(ctx, payload.TransactionID)
+}
+
+// openWithdrawal loads a held withdrawal the user requested that still waits
+// for a decision.
+func (s *TransactionService) openWithdrawal(ctx context.Context, transactionID, userID string) (*models.WithdrawalApproval, *errors.Error) {
+	hold, err := s.GetWithdrawalApproval(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.UserID != userID {
+		return nil, errors.Forbidden("withdrawal was requested by a different user")
+	}
+	if !hold.Stage.IsOpen() {
+		return nil, errors.Conflict(fmt.Sprintf("withdrawal is already %s", hold.Stage))
+	}
+	return hold, nil
+}
+
+// releaseWithdrawal moves a released withdrawal from pending_approval to
+// pending, where it is processed like any other withdrawal.
+func (s *TransactionService) releaseWithdrawal(ctx context.Context, hold *models.WithdrawalApproval, actor models.TimelineActor) *errors.Error {
+	if err := s.transactionRepo.UpdateStatus(ctx, hold.TransactionID, models.TransactionStatusPending, nil); err != nil {
+		return err
+	}
+	s.recordTimeline(ctx, hold.TransactionID, models.TimelineApproved, models.TransactionStatusPending, actor, nil)
+
+	s.notifyWithdrawal(hold, fmt.Sprintf("Your withdrawal of ₹%.2f is approved and being processed", float64(hold.Amount)/100))
+	s.publishWithdrawalEvent("transaction.withdrawal.released", hold, nil)
+
+	s.logger.WithField("transaction_id", hold.TransactionID).Info("Withdrawal released")
+	return nil
+}
+
+// closeWithdrawal moves an open withdrawal to a final stage and cancels it.
+func (s *TransactionService) closeWithdrawal(ctx context.Context, hold *models.WithdrawalApproval, stage models.WithdrawalApprovalStage, reason string, actor models.TimelineActor) (*models.WithdrawalApproval, *errors.Error) {
+	closed, err := s.withdrawals.repo.Decide(ctx, hold.TransactionID, hold.Stage, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transactionRepo.UpdateStatus(ctx, hold.TransactionID, models.TransactionStatusCancelled, &reason); err != nil {
+		return nil, err
+	}
+	s.recordTimeline(ctx, hold.TransactionID, models.TimelineCancelled, models.TransactionStatusCancelled, actor,
+		map[string]string{"stage": string(stage), "reason": reason})
+
+	s.notifyWithdrawal(closed, fmt.Sprintf("Your withdrawal of ₹%.2f was cancelled: %s", float64(closed.Amount)/100, reason))
+	s.publishWithdrawalEvent("transaction.withdrawal.cancelled", closed, map[string]interface{}{
+		"reason": reason,
+	})
+
+	s.logger.With(map[string]interface{}{
+		"transaction_id": hold.TransactionID,
+		"stage":          stage,
+	}).Info("Withdrawal cancelled")
+	return closed, nil
+}
+
+// withdrawalResponse returns a withdrawal with its approval state.
+func (s *TransactionService) withdrawalResponse(ctx context.Context, hold *models.WithdrawalApproval) (*models.WithdrawalResponse, *errors.Error) {
+	transaction, err := s.transactionRepo.GetByID(ctx, hold.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.WithdrawalResponse{Transaction: transaction, Approval: hold}, nil
+}
+
+// validateWithdrawalToken validates an identity verification token for a
+// withdrawal confirmation by the given user.
+func (s *TransactionService) validateWithdrawalToken(tokenString, expectedUserID string) (*verificationClaims, *errors.Error) {
+	if len(s.withdrawals.verificationSecret) == 0 {
+		return nil, errors.Unavailable("step-up verification is not configured")
+	}
+
+	token, parseErr := jwt.ParseWithClaims(tokenString, &verificationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.withdrawals.verificationSecret, nil
+	})
+	if parseErr != nil || !token.Valid {
+		return nil, errors.Unauthorized("invalid or expired verification token")
+	}
+
+	claims, ok := token.Claims.(*verificationClaims)
+	if !ok || claims.VerificationID == "" {
+		return nil, errors.Unauthorized("invalid verification token claims")
+	}
+	if claims.OperationType != OpWithdrawalConfirmation {
+		return nil, errors.Forbidden("verification token is for different operation")
+	}
+	if claims.UserID != expectedUserID {
+		return nil, errors.Forbidden("verification token belongs to different user")
+	}
+
+	return claims, nil
+}
+
+// notifyWithdrawal tells the user who requested a held withdrawal about its
+// progress.
+func (s *TransactionService) notifyWithdrawal(hold *models.WithdrawalApproval, message string) {
+	if s.notificationClient == nil {
+		return
+	}
+
+	userID := hold.UserID
+	correlationID := fmt.Sprintf("txn-%s", hold.TransactionID)
+	s.notificationClient.SendNotificationAsync(&clients.SendNotificationRequest{
+		UserID:     &userID,
+		Recipient:  userID,
+		Channel:    clients.NotificationChannelPush,
+		Type:       clients.NotificationTypeTransactionAlert,
+		Priority:   clients.NotificationPriorityHigh,
+		TemplateID: "withdrawal_approval_push",
+		Variables: map[string]any{
+			"message":        message,
+			"amount":         fmt.Sprintf("%.2f", float64(hold.Amount)/100),
+			"transaction_id": hold.TransactionID,
+		},
+		CorrelationID: &correlationID,
+		SourceService: "transaction",
+		Metadata: map[string]any{
+			"transaction_id": hold.TransactionID,
+			"wallet_id":      hold.WalletID,
+		},
+	}, "transaction")
+}
+
+// publishWithdrawalEvent publishes a step of a held withdrawal's approval.
+func (s *TransactionService) publishWithdrawalEvent(eventType string, hold *models.WithdrawalApproval, extra map[string]interface{}) {
+	if s.eventPublisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"user_id":        hold.UserID,
+		"wallet_id":      hold.WalletID,
+		"amount":         hold.Amount,
+		"stage":          string(hold.Stage),
+		"requires_admin": hold.RequiresAdmin,
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+	s.eventPublisher.PublishTransactionEvent(eventType, hold.TransactionID, data)
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/transaction/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/approval"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testVerificationSecret = "test-verification-secret"
+
+type mockWithdrawalApprovalRepository struct {
+	approvals map[string]*models.WithdrawalApproval
+}
+
+func (m *mockWithdrawalApprovalRepository) Create(ctx context.Context, a *models.WithdrawalApproval) *errors.Error {
+	a.CreatedAt = sharedModels.Now()
+	stored := *a
+	m.approvals[a.TransactionID] = &stored
+	return nil
+}
+
+func (m *mockWithdrawalApprovalRepository) Get(ctx context.Context, transactionID string) (*models.WithdrawalApproval, *errors.Error) {
+	a, ok := m.approvals[transactionID]
+	if !ok {
+		return nil, errors.NotFoundWithID("withdrawal approval", transactionID)
+	}
+	copied := *a
+	return &copied, nil
+}
+
+func (m *mockWithdrawalApprovalRepository) Confirm(ctx context.Context, transactionID, verificationID string, stage models.WithdrawalApprovalStage, approvalID *string, expiresAt time.Time) (*models.WithdrawalApproval, *errors.Error) {
+	a, ok := m.approvals[transactionID]
+	if !ok || a.Stage != models.WithdrawalAwaitingConfirmation || !a.ExpiresAt.Time.After(time.Now()) {
+		return nil, errors.Conflict("withdrawal is no longer awaiting confirmation")
+	}
+	now := sharedModels.Now()
+	a.Stage = stage
+	a.VerificationID = &verificationID
+	a.ApprovalID = approvalID
+	a.ExpiresAt.Time = expiresAt
+	a.ConfirmedAt = &now
+	copied := *a
+	return &copied, nil
+}
+
+func (m *mockWithdrawalApprovalRepository) Decide(ctx context.Context, transactionID string, from, to models.WithdrawalApprovalStage) (*models.WithdrawalApproval, *errors.Error) {
+	a, ok := m.approvals[transactionID]
+	if !ok || a.Stage != from {
+		return nil, errors.Conflict("withdrawal approval was already decided")
+	}
+	now := sharedModels.Now()
+	a.Stage = to
+	a.DecidedAt = &now
+	copied := *a
+	return &copied, nil
+}
+
+func (m *mockWithdrawalApprovalRepository) ListOpen(ctx context.Context, stage models.WithdrawalApprovalStage, cutoff time.Time, limit int) ([]*models.WithdrawalApproval, *errors.Error) {
+	result := make([]*models.WithdrawalApproval, 0)
+	for _, a := range m.approvals {
+		if a.Stage == stage && (cutoff.IsZero() || a.ExpiresAt.Time.Before(cutoff)) && len(result) < limit {
+			copied := *a
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+var _ WithdrawalApprovalRepositoryInterface = (*mockWithdrawalApprovalRepository)(nil)
+
+func setupWithdrawalApprovals(approvals *approval.Manager) (*TransactionService, *mockTransactionRepository, *mockWithdrawalApprovalRepository) {
+	service, repo := setupTestService()
+	holds := &mockWithdrawalApprovalRepository{approvals: make(map[string]*models.WithdrawalApproval)}
+	service.SetWithdrawalApprovals(holds, approvals, WithdrawalApprovalPolicy{
+		StepUpThreshold: 100000,
+		AdminThreshold:  500000,
+		ConfirmationTTL: 10 * time.Minute,
+	}, testVerificationSecret)
+	return service, repo, holds
+}
+
+func createHeldWithdrawal(t *testing.T, service *TransactionService, userID string, amount int64) *models.Transaction {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+	tx, err := service.CreateWithdrawal(ctx, &models.CreateWithdrawalRequest{
+		WalletID:    uuid.New().String(),
+		Amount:      amount,
+		Currency:    sharedModels.INR,
+		Description: "Withdrawal to bank account",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	return tx
+}
+
+func withdrawalToken(t *testing.T, userID, operation, transactionID string) string {
+	t.Helper()
+	claims := verificationClaims{
+		VerificationID: uuid.New().String(),
+		UserID:         userID,
+		OperationType:  operation,
+		Metadata:       map[string]interface{}{"transaction_id": transactionID},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(5 * time.Minute)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testVerificationSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestCreateWithdrawal_BelowStepUpThresholdIsNotHeld(t *testing.T) {
+	service, _, holds := setupWithdrawalApprovals(nil)
+
+	tx := createHeldWithdrawal(t, service, "user-1", 99999)
+	if tx.Status != models.TransactionStatusPending {
+		t.Errorf("expected pending, got %s", tx.Status)
+	}
+	if len(holds.approvals) != 0 {
+		t.Errorf("expected no hold, got %d", len(holds.approvals))
+	}
+}
+
+func TestCreateWithdrawal_HeldWithdrawalNeedsUser(t *testing.T) {
+	service, _, _ := setupWithdrawalApprovals(nil)
+
+	_, err := service.CreateWithdrawal(context.Background(), &models.CreateWithdrawalRequest{
+		WalletID: uuid.New().String(),
+		Amount:   100000,
+		Currency: sharedModels.INR,
+	})
+	if err == nil {
+		t.Fatal("expected error for held withdrawal without a user")
+	}
+}
+
+func TestConfirmWithdrawal_ReleasesBelowAdminThreshold(t *testing.T) {
+	service, repo, holds := setupWithdrawalApprovals(approval.NewManager("transaction", approval.NewMemoryStore()))
+	tx := createHeldWithdrawal(t, service, "user-1", 100000)
+
+	if tx.Status != models.TransactionStatusPendingApproval {
+		t.Fatalf("expected pending_approval, got %s", tx.Status)
+	}
+	if holds.approvals[tx.ID].RequiresAdmin {
+		t.Error("expected no admin approval below the admin threshold")
+	}
+
+	resp, err := service.ConfirmWithdrawal(context.Background(), tx.ID, "user-1", &models.ConfirmWithdrawalRequest{
+		VerificationToken: withdrawalToken(t, "user-1", OpWithdrawalConfirmation, tx.ID),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Approval.Stage != models.WithdrawalReleased {
+		t.Errorf("expected released, got %s", resp.Approval.Stage)
+	}
+	if repo.transactions[tx.ID].Status != models.TransactionStatusPending {
+		t.Errorf("expected pending, got %s", repo.transactions[tx.ID].Status)
+	}
+}
+
+func TestConfirmWithdrawal_RejectsInvalidTokens(t *testing.T) {
+	service, _, _ := setupWithdrawalApprovals(nil)
+	tx := createHeldWithdrawal(t, service, "user-1", 100000)
+	other := createHeldWithdrawal(t, service, "user-1", 100000)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-token"},
+		{"other operation", withdrawalToken(t, "user-1", "high_value_transfer", tx.ID)},
+		{"other user", withdrawalToken(t, "user-2", OpWithdrawalConfirmation, tx.ID)},
+		{"other withdrawal", withdrawalToken(t, "user-1", OpWithdrawalConfirmation, other.ID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ConfirmWithdrawal(context.Background(), tx.ID, "user-1", &models.ConfirmWithdrawalRequest{VerificationToken: tt.token})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+
+	if _, err := service.ConfirmWithdrawal(context.Background(), tx.ID, "user-2", &models.ConfirmWithdrawalRequest{
+		VerificationToken: withdrawalToken(t, "user-2", OpWithdrawalConfirmation, tx.ID),
+	}); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for another user's withdrawal, got %v", err)
+	}
+}
+
+func TestConfirmWithdrawal_AdminApprovalReleases(t *testing.T) {
+	approvals := approval.NewManager("transaction", approval.NewMemoryStore())
+	service, repo, _ := setupWithdrawalApprovals(approvals)
+	tx := createHeldWithdrawal(t, service, "user-1", 500000)
+	ctx := context.Background()
+
+	resp, err := service.ConfirmWithdrawal(ctx, tx.ID, "user-1", &models.ConfirmWithdrawalRequest{
+		VerificationToken: withdrawalToken(t, "user-1", OpWithdrawalConfirmation, tx.ID),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Approval.Stage != models.WithdrawalAwaitingApproval || resp.Approval.ApprovalID == nil {
+		t.Fatalf("expected awaiting_approval with an approval, got %s", resp.Approval.Stage)
+	}
+	if repo.transactions[tx.ID].Status != models.TransactionStatusPendingApproval {
+		t.Fatalf("expected pending_approval until approved, got %s", repo.transactions[tx.ID].Status)
+	}
+
+	if _, err := approvals.Approve(ctx, *resp.Approval.ApprovalID, "user-1", nil); err == nil {
+		t.Error("expected requester to be unable to approve their own withdrawal")
+	}
+
+	decided, err := approvals.Approve(ctx, *resp.Approval.ApprovalID, "admin-1", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != approval.StatusApproved {
+		t.Errorf("expected approved, got %s (%v)", decided.Status, decided.Error)
+	}
+	if repo.transactions[tx.ID].Status != models.TransactionStatusPending {
+		t.Errorf("expected pending after approval, got %s", repo.transactions[tx.ID].Status)
+	}
+}
+
+func TestExpireWithdrawalApprovals_CancelsRejected(t *testing.T) {
+	approvals := approval.NewManager("transaction", approval.NewMemoryStore())
+	service, repo, holds := setupWithdrawalApprovals(approvals)
+	tx := createHeldWithdrawal(t, service, "user-1", 500000)
+	ctx := context.Background()
+
+	resp, err := service.ConfirmWithdrawal(ctx, tx.ID, "user-1", &models.ConfirmWithdrawalRequest{
+		VerificationToken: withdrawalToken(t, "user-1", OpWithdrawalConfirmation, tx.ID),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := approvals.Reject(ctx, *resp.Approval.ApprovalID, "admin-1", nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cancelled, err := service.ExpireWithdrawalApprovals(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cancelled != 1 {
+		t.Errorf("expected 1 cancelled, got %d", cancelled)
+	}
+	if holds.approvals[tx.ID].Stage != models.WithdrawalRejected {
+		t.Errorf("expected rejected, got %s", holds.approvals[tx.ID].Stage)
+	}
+	if repo.transactions[tx.ID].Status != models.TransactionStatusCancelled {
+		t.Errorf("expected cancelled, got %s", repo.transactions[tx.ID].Status)
+	}
+}
+
+func TestExpireWithdrawalApprovals_CancelsUnconfirmed(t *testing.T) {
+	service, repo, holds := setupWithdrawalApprovals(nil)
+	expired := createHeldWithdrawal(t, service, "user-1", 100000)
+	fresh := createHeldWithdrawal(t, service, "user-1", 100000)
+	holds.approvals[expired.ID].ExpiresAt.Time = time.Now().Add(-time.Minute)
+
+	cancelled, err := service.ExpireWithdrawalApprovals(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cancelled != 1 {
+		t.Errorf("expected 1 cancelled, got %d", cancelled)
+	}
+	if repo.transactions[expired.ID].Status != models.TransactionStatusCancelled {
+		t.Errorf("expected expired withdrawal cancelled, got %s", repo.transactions[expired.ID].Status)
+	}
+	if repo.transactions[fresh.ID].Status != models.TransactionStatusPendingApproval {
+		t.Errorf("expected fresh withdrawal still held, got %s", repo.transactions[fresh.ID].Status)
+	}
+
+	if _, err := service.ConfirmWithdrawal(context.Background(), expired.ID, "user-1", &models.ConfirmWithdrawalRequest{
+		VerificationToken: withdrawalToken(t, "user-1", OpWithdrawalConfirmation, expired.ID),
+	}); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict confirming an expired withdrawal, got %v", err)
+	}
+}
+
+func TestCancelWithdrawal(t *testing.T) {
+	service, repo, holds := setupWithdrawalApprovals(nil)
+	tx := createHeldWithdrawal(t, service, "user-1", 100000)
+
+	if _, err := service.CancelWithdrawal(context.Background(), tx.ID, "user-2"); err == nil {
+		t.Error("expected another user to be unable to cancel")
+	}
+
+	resp, err := service.CancelWithdrawal(context.Background(), tx.ID, "user-1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Approval.Stage != models.WithdrawalCancelled || holds.approvals[tx.ID].Stage != models.WithdrawalCancelled {
+		t.Errorf("expected cancelled stage, got %s", resp.Approval.Stage)
+	}
+	if repo.transactions[tx.ID].Status != models.TransactionStatusCancelled {
+		t.Errorf("expected cancelled, got %s", repo.transactions[tx.ID].Status)
+	}
+
+	if _, err := service.CancelWithdrawal(context.Background(), tx.ID, "user-1"); err == nil {
+		t.Error("expected conflict cancelling twice")
+	}
+}
@@ -0,0 +1,4 @@
+-- Payee Favorites Rollback
+
+DROP INDEX IF EXISTS idx_transactions_source_transfers;
+DROP TABLE IF EXISTS payee_favorites;
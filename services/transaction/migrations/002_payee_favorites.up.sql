@@ -0,0 +1,18 @@
+-- Payee Favorites
+-- Pinned payees for a wallet's quick-pay list
+
+CREATE TABLE IF NOT EXISTS payee_favorites (
+    wallet_id UUID NOT NULL,
+    payee_wallet_id UUID NOT NULL,
+    pinned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (wallet_id, payee_wallet_id),
+    CONSTRAINT payee_favorites_self_check CHECK (wallet_id != payee_wallet_id)
+);
+
+CREATE INDEX idx_payee_favorites_wallet ON payee_favorites(wallet_id, pinned_at DESC);
+
+-- Supports aggregating recent payees from transfer history
+CREATE INDEX IF NOT EXISTS idx_transactions_source_transfers
+    ON transactions(source_wallet_id, destination_wallet_id, created_at DESC)
+    WHERE type = 'transfer' AND status = 'completed';
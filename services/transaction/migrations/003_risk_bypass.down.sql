@@ -0,0 +1,3 @@
+-- Risk Bypass Tracking Rollback
+
+DROP INDEX IF EXISTS idx_transactions_risk_bypassed;
@@ -0,0 +1,7 @@
+-- Risk Bypass Tracking
+-- Transactions that proceeded without a risk decision carry a risk_bypassed
+-- marker in metadata until the re-score worker evaluates them.
+
+CREATE INDEX IF NOT EXISTS idx_transactions_risk_bypassed
+    ON transactions(created_at)
+    WHERE metadata->>'risk_bypassed' = 'true';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_created_at_id;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS category_overridden;
+
+ALTER TABLE category_patterns
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS match_type;
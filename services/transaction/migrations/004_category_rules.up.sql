@@ -0,0 +1,15 @@
+-- Category Rules
+-- Patterns can be keywords or regular expressions and are managed through the
+-- admin API. A category the user picks by hand is marked as an override so
+-- bulk re-categorization leaves it alone.
+
+ALTER TABLE category_patterns
+    ADD COLUMN IF NOT EXISTS match_type VARCHAR(10) NOT NULL DEFAULT 'keyword'
+        CHECK (match_type IN ('keyword', 'regex')),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS category_overridden BOOLEAN NOT NULL DEFAULT false;
+
+-- Supports paging through history in (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at_id ON transactions(created_at, id);
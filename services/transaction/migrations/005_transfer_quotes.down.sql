@@ -0,0 +1,3 @@
+-- Transfer Quotes Rollback
+
+DROP TABLE IF EXISTS transfer_quotes;
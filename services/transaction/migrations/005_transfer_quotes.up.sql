@@ -0,0 +1,27 @@
+-- Transfer Quotes
+-- Short-lived price quotes for transfers. A transfer that references a quote
+-- debits the quoted amount, locking the FX rate and fees.
+
+CREATE TABLE IF NOT EXISTS transfer_quotes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source_wallet_id UUID NOT NULL,
+    destination_wallet_id UUID NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    debit_amount BIGINT NOT NULL CHECK (debit_amount > 0),
+    debit_currency VARCHAR(3) NOT NULL,
+    fx_rate NUMERIC(20,10),
+    mid_rate NUMERIC(20,10),
+    fee_amount BIGINT NOT NULL DEFAULT 0 CHECK (fee_amount >= 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    transaction_id UUID REFERENCES transactions(id),
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Expired, unused quotes are purged by age
+CREATE INDEX IF NOT EXISTS idx_transfer_quotes_expires_at ON transfer_quotes(expires_at) WHERE used_at IS NULL;
+
+COMMENT ON TABLE transfer_quotes IS 'Price quotes for prospective transfers, usable once before expiry';
+COMMENT ON COLUMN transfer_quotes.fx_rate IS 'Debit currency per unit of quote currency, markup included; NULL when no conversion';
@@ -0,0 +1,4 @@
+-- Auto-Sweep Rules Rollback
+
+DROP TABLE IF EXISTS sweep_executions;
+DROP TABLE IF EXISTS sweep_rules;
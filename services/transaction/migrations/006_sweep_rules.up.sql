@@ -0,0 +1,47 @@
+-- Auto-Sweep Rules
+-- Standing instructions on a wallet: sweep the balance above a threshold to
+-- another wallet, or top up from another wallet below a threshold. Rules are
+-- evaluated after balance changes; every transfer a rule attempts is recorded.
+
+CREATE TABLE IF NOT EXISTS sweep_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL,
+    counterpart_wallet_id UUID NOT NULL,
+    direction VARCHAR(10) NOT NULL CHECK (direction IN ('sweep_out', 'top_up')),
+    threshold BIGINT NOT NULL CHECK (threshold >= 0),
+    target_balance BIGINT NOT NULL CHECK (target_balance >= 0),
+    min_amount BIGINT NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL,
+    last_executed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT sweep_rules_self_check CHECK (wallet_id != counterpart_wallet_id),
+    CONSTRAINT sweep_rules_target_check CHECK (
+        (direction = 'sweep_out' AND target_balance <= threshold) OR
+        (direction = 'top_up' AND target_balance >= threshold)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_rules_wallet ON sweep_rules(wallet_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_sweep_rules_enabled ON sweep_rules(wallet_id) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS sweep_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id UUID NOT NULL REFERENCES sweep_rules(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL,
+    direction VARCHAR(10) NOT NULL,
+    balance_before BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('completed', 'failed')),
+    transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sweep_executions_wallet ON sweep_executions(wallet_id, created_at DESC);
+
+COMMENT ON TABLE sweep_rules IS 'Auto-sweep standing instructions, evaluated after balance changes';
+COMMENT ON COLUMN sweep_rules.target_balance IS 'Balance a sweep leaves the wallet at; at or below threshold for sweep_out, at or above for top_up';
+COMMENT ON TABLE sweep_executions IS 'Transfers attempted by sweep rules';
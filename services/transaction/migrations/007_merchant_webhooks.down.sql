@@ -0,0 +1,4 @@
+-- Merchant Payment Webhooks Rollback
+
+DROP TABLE IF EXISTS merchant_webhook_deliveries;
+DROP TABLE IF EXISTS merchant_webhooks;
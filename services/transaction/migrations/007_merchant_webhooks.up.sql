@@ -0,0 +1,41 @@
+-- Merchant Payment Webhooks
+-- A merchant registers one callback URL per wallet. Every completed transfer
+-- into the wallet queues a signed delivery, retried with backoff until the
+-- endpoint accepts it or the attempts run out.
+
+CREATE TABLE IF NOT EXISTS merchant_webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL UNIQUE,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS merchant_webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES merchant_webhooks(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL,
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    payload JSONB NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT merchant_webhook_deliveries_transaction_key UNIQUE (webhook_id, transaction_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_webhook_deliveries_due ON merchant_webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_merchant_webhook_deliveries_wallet ON merchant_webhook_deliveries(wallet_id, created_at DESC);
+
+COMMENT ON TABLE merchant_webhooks IS 'Merchant callback URLs notified of incoming payments, one per wallet';
+COMMENT ON COLUMN merchant_webhooks.secret IS 'HMAC-SHA256 key for the X-Nivo-Signature header; shown only on create and rotate';
+COMMENT ON TABLE merchant_webhook_deliveries IS 'Payment notifications queued for merchant webhooks, one per incoming transfer';
+COMMENT ON COLUMN merchant_webhook_deliveries.next_attempt_at IS 'When a pending delivery is next tried; pushed ahead while a worker holds it';
@@ -0,0 +1,3 @@
+-- Transaction Timeline Rollback
+
+DROP TABLE IF EXISTS transaction_events;
@@ -0,0 +1,37 @@
+-- Transaction Timeline
+-- One row per step in a transaction's lifecycle (created, risk evaluated,
+-- funds moved, ledger posted, completed or failed), with the status after the
+-- step and who performed it. Rows are append-only.
+
+CREATE TABLE IF NOT EXISTS transaction_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    event_type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    actor_type VARCHAR(10) NOT NULL CHECK (actor_type IN ('user', 'service')),
+    actor_id VARCHAR(100) NOT NULL,
+    details JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_events_transaction ON transaction_events(transaction_id, created_at);
+
+-- Backfill what existing transactions record about themselves
+INSERT INTO transaction_events (transaction_id, event_type, status, actor_type, actor_id, details, created_at)
+SELECT id, 'created', 'pending', 'service', 'transaction', '{"backfilled": "true"}'::jsonb, created_at
+FROM transactions;
+
+INSERT INTO transaction_events (transaction_id, event_type, status, actor_type, actor_id, details, created_at)
+SELECT id, 'completed', 'completed', 'service', 'transaction', '{"backfilled": "true"}'::jsonb, COALESCE(completed_at, updated_at)
+FROM transactions
+WHERE status = 'completed';
+
+INSERT INTO transaction_events (transaction_id, event_type, status, actor_type, actor_id, details, created_at)
+SELECT id, 'failed', 'failed', 'service', 'transaction',
+       jsonb_build_object('backfilled', 'true', 'reason', COALESCE(failure_reason, '')), updated_at
+FROM transactions
+WHERE status = 'failed';
+
+COMMENT ON TABLE transaction_events IS 'Append-only status history of transactions, served as the transaction timeline';
+COMMENT ON COLUMN transaction_events.status IS 'Transaction status after the event';
+COMMENT ON COLUMN transaction_events.actor_id IS 'User ID for user actors, service name for service actors';
@@ -0,0 +1,3 @@
+-- Amount Policies Rollback
+
+DROP TABLE IF EXISTS amount_policies;
@@ -0,0 +1,23 @@
+-- Amount Policies
+-- Minimum and maximum amounts per operation and currency, managed through the
+-- admin API and enforced when transactions are created. Amounts are in the
+-- currency's minor units. A NULL maximum means no maximum.
+
+CREATE TABLE IF NOT EXISTS amount_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('transfer', 'deposit', 'upi_deposit', 'withdrawal')),
+    currency VARCHAR(3) NOT NULL,
+    min_amount BIGINT NOT NULL DEFAULT 0 CHECK (min_amount >= 0),
+    max_amount BIGINT CHECK (max_amount > 0),
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT amount_policies_unique UNIQUE (operation, currency),
+    CONSTRAINT amount_policies_range_check CHECK (max_amount IS NULL OR max_amount >= min_amount)
+);
+
+-- UPI deposits were limited to ₹1 - ₹1,00,000 in code
+INSERT INTO amount_policies (operation, currency, min_amount, max_amount) VALUES
+('upi_deposit', 'INR', 100, 10000000)
+ON CONFLICT (operation, currency) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Merchant Payment Intents Rollback
+
+DROP TABLE IF EXISTS merchant_payment_intents;
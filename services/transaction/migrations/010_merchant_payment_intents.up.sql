@@ -0,0 +1,31 @@
+-- Merchant Payment Intents
+-- A merchant asks for a payment of a fixed amount against one of its order
+-- IDs. The payer scans the QR code or opens the deeplink and confirms, which
+-- makes an ordinary transfer into the merchant's wallet carrying the order ID
+-- as its reference. Merchants look payments up by their own order reference.
+
+CREATE TABLE IF NOT EXISTS merchant_payment_intents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL,
+    order_reference VARCHAR(100) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(12) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'paid', 'cancelled')),
+    payer_wallet_id UUID,
+    transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT merchant_payment_intents_order_key UNIQUE (wallet_id, order_reference)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_payment_intents_wallet ON merchant_payment_intents(wallet_id, created_at DESC);
+
+COMMENT ON TABLE merchant_payment_intents IS 'Payments merchants request against their order IDs, paid by a transfer into the merchant wallet';
+COMMENT ON COLUMN merchant_payment_intents.status IS 'Stored state; a pending intent past expires_at is reported as expired';
+COMMENT ON COLUMN merchant_payment_intents.failure_reason IS 'Why the latest payment attempt failed; the intent stays payable';
@@ -0,0 +1,6 @@
+-- Auto Top-Up Rollback
+
+DROP TABLE IF EXISTS auto_top_up_executions;
+DROP TABLE IF EXISTS auto_top_up_rules;
+DROP TABLE IF EXISTS mandates;
+DROP TABLE IF EXISTS funding_sources;
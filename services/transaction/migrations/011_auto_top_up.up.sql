@@ -0,0 +1,72 @@
+-- Auto Top-Up
+-- Simulated bank accounts linked as funding sources, mandates authorizing
+-- debits from them into a wallet within per-debit and monthly limits, and
+-- per-wallet rules that deposit from a mandate when the balance falls below a
+-- threshold. Every deposit a rule attempts is recorded.
+
+CREATE TABLE IF NOT EXISTS funding_sources (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    bank_name VARCHAR(100) NOT NULL,
+    account_holder_name VARCHAR(100) NOT NULL,
+    account_last4 VARCHAR(4) NOT NULL,
+    ifsc VARCHAR(11) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'removed')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    removed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_funding_sources_user ON funding_sources(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS mandates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    funding_source_id UUID NOT NULL REFERENCES funding_sources(id),
+    wallet_id UUID NOT NULL,
+    max_amount BIGINT NOT NULL CHECK (max_amount > 0),
+    monthly_limit BIGINT NOT NULL CHECK (monthly_limit >= max_amount),
+    valid_until TIMESTAMP WITH TIME ZONE,
+    status VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    cancelled_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_mandates_user ON mandates(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_mandates_funding_source ON mandates(funding_source_id) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS auto_top_up_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL UNIQUE,
+    mandate_id UUID NOT NULL REFERENCES mandates(id),
+    threshold BIGINT NOT NULL CHECK (threshold >= 0),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL,
+    last_executed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_top_up_rules_enabled ON auto_top_up_rules(wallet_id) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS auto_top_up_executions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rule_id UUID NOT NULL REFERENCES auto_top_up_rules(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL,
+    mandate_id UUID NOT NULL REFERENCES mandates(id),
+    balance_before BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status VARCHAR(10) NOT NULL CHECK (status IN ('completed', 'failed')),
+    transaction_id UUID REFERENCES transactions(id),
+    failure_reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_top_up_executions_wallet ON auto_top_up_executions(wallet_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auto_top_up_executions_mandate ON auto_top_up_executions(mandate_id, created_at) WHERE status = 'completed';
+
+COMMENT ON TABLE funding_sources IS 'Simulated bank accounts linked to fund wallets; only the last four digits are stored';
+COMMENT ON TABLE mandates IS 'Standing authorizations to debit a funding source into one wallet';
+COMMENT ON COLUMN mandates.monthly_limit IS 'Total completed debits allowed per calendar month (UTC)';
+COMMENT ON TABLE auto_top_up_rules IS 'Deposit from a mandate when the wallet balance falls below threshold';
+COMMENT ON TABLE auto_top_up_executions IS 'Deposits attempted by auto top-up rules';
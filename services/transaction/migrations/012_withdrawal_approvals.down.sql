@@ -0,0 +1,8 @@
+-- Withdrawal Approvals Rollback
+
+DROP TABLE IF EXISTS withdrawal_approvals;
+
+UPDATE transactions SET status = 'cancelled' WHERE status = 'pending_approval';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'reversed', 'cancelled'));
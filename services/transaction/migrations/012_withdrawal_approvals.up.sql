@@ -0,0 +1,31 @@
+-- Withdrawal Approvals
+-- Withdrawals from the step-up threshold are held as pending_approval until
+-- the owner confirms them with an identity verification and, from the admin
+-- threshold, an admin approves them. Unconfirmed withdrawals expire.
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_status_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_status_check
+    CHECK (status IN ('pending', 'pending_approval', 'processing', 'completed', 'failed', 'reversed', 'cancelled'));
+
+CREATE TABLE IF NOT EXISTS withdrawal_approvals (
+    transaction_id UUID PRIMARY KEY REFERENCES transactions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    wallet_id UUID NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    stage VARCHAR(25) NOT NULL DEFAULT 'awaiting_confirmation'
+        CHECK (stage IN ('awaiting_confirmation', 'awaiting_approval', 'released', 'cancelled', 'rejected', 'expired')),
+    requires_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    verification_id VARCHAR(100),
+    approval_id UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Open approvals are scanned for expiry and admin decisions
+CREATE INDEX IF NOT EXISTS idx_withdrawal_approvals_open ON withdrawal_approvals(stage, expires_at)
+    WHERE stage IN ('awaiting_confirmation', 'awaiting_approval');
+
+COMMENT ON TABLE withdrawal_approvals IS 'Confirmation and admin approval state of withdrawals held as pending_approval';
+COMMENT ON COLUMN withdrawal_approvals.expires_at IS 'Deadline of the current stage';
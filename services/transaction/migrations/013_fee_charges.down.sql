@@ -0,0 +1,3 @@
+-- Fee Charges Rollback
+
+DROP INDEX IF EXISTS idx_transactions_fee_reference;
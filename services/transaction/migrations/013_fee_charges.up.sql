@@ -0,0 +1,7 @@
+-- Fee charges requested by other services (e.g. tier upgrade fees) carry the
+-- caller's reference. At most one fee per reference can be pending or
+-- completed, so a retried charge cannot debit the wallet twice; a failed
+-- charge can be retried under the same reference.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_fee_reference
+    ON transactions(reference)
+    WHERE type = 'fee' AND status <> 'failed';
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
+	_ "time/tzdata" // Limit timezones must load in minimal images
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/handler"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/repository"
@@ -10,17 +16,41 @@ import (
 	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/server"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 func main() {
 	server.Run(server.ServiceConfig{
 		Name: "wallet",
+		// Identity creates wallets; transaction moves money; cardnetwork authorizes cards;
+		// simulation injects reconciliation anomalies
+		InternalPolicy: serviceauth.Policy{
+			Callers: map[string][]string{
+				"/internal/v1/wallets":                {"identity", "transaction"},
+				"/internal/v1/wallets/closure-sweeps": {"transaction"},
+				"/internal/v1/cards":                  {"cardnetwork"},
+				"/internal/v1/beneficiaries":          {"transaction"},
+				"/internal/v1/simulation":             {"simulation"},
+			},
+		},
 		SetupHandler: func(ctx *server.BootstrapContext) (http.Handler, error) {
 			// Initialize repository layer
 			walletRepo := repository.NewWalletRepository(ctx.DB.DB)
+
+			// Limit windows roll at midnight in this timezone
+			limitsTimezone := server.GetEnv("WALLET_LIMITS_TIMEZONE", "Asia/Kolkata")
+			limitsLoc, err := time.LoadLocation(limitsTimezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid WALLET_LIMITS_TIMEZONE %q: %w", limitsTimezone, err)
+			}
+			walletRepo.SetLimitsLocation(limitsLoc)
 			beneficiaryRepo := repository.NewBeneficiaryRepository(ctx.DB.DB)
 			upiDepositRepo := repository.NewUPIDepositRepository(ctx.DB.DB)
 			virtualCardRepo := repository.NewVirtualCardRepository(ctx.DB.DB)
+			cardAutoFreezeRepo := repository.NewCardAutoFreezeRepository(ctx.DB.DB)
+			cardClearingRepo := repository.NewCardClearingRepository(ctx.DB.DB)
+			walletMemberRepo := repository.NewWalletMemberRepository(ctx.DB.DB)
+			walletClosureRepo := repository.NewWalletClosureRepository(ctx.DB.DB)
 
 			// Initialize event publisher
 			eventPublisher := events.NewPublisher(events.PublishConfig{
@@ -32,12 +62,93 @@ func main() {
 			ledgerClient := service.NewLedgerClient(server.GetEnv("LEDGER_SERVICE_URL", "http://ledger-service:8081"))
 			notificationClient := clients.NewNotificationClient(server.GetEnv("NOTIFICATION_SERVICE_URL", "http://notification-service:8087"))
 			identityClient := service.NewIdentityClient(server.GetEnv("IDENTITY_SERVICE_URL", "http://identity-service:8080"))
+			internalSecret := server.GetSecret("INTERNAL_SERVICE_SECRET", "")
+			transactionClient := service.NewTransactionClient(server.GetEnv("TRANSACTION_SERVICE_URL", "http://transaction-service:8084"), internalSecret)
 
 			// Initialize service layer
 			walletService := service.NewWalletService(walletRepo, eventPublisher, ledgerClient, notificationClient, identityClient)
+			walletService.SetJointWallets(walletMemberRepo, identityClient)
+			walletService.SetLimitsLocation(limitsLoc)
+			walletService.SetClosures(walletClosureRepo, cardClearingRepo, transactionClient, service.DefaultClosurePolicy())
+			// Balance skews bypass the ledger, so only simulations outside production get them
+			if !ctx.Config.IsProduction() {
+				walletService.SetBalanceSkews(repository.NewBalanceSkewRepository(ctx.DB.DB))
+			}
+
+			// Clear spend from ended limit windows; reads and transfers also roll lazily
+			ctx.Lifecycle.Every("limits-reset", time.Minute, func(workerCtx context.Context) error {
+				reset, err := walletService.ResetExpiredLimits(workerCtx)
+				if err != nil {
+					return err
+				}
+				if reset > 0 {
+					ctx.Logger.WithField("wallets", reset).Info("Reset expired wallet limits")
+				}
+				return nil
+			})
+
+			// Settle holds, sweep balances and close wallets whose closure is due
+			ctx.Lifecycle.Every("wallet-closures", time.Minute, func(workerCtx context.Context) error {
+				closed, err := walletService.ProcessDueClosures(workerCtx)
+				if err != nil {
+					return err
+				}
+				if closed > 0 {
+					ctx.Logger.WithField("wallets", closed).Info("Closed wallets with due closures")
+				}
+				return nil
+			})
+
+			// Compare wallet balances with their ledger accounts; mismatches are
+			// logged and reported for an operator to correct
+			reconcileInterval := time.Minute
+			if val := os.Getenv("WALLET_LEDGER_RECONCILE_INTERVAL_SECONDS"); val != "" {
+				seconds, err := strconv.Atoi(val)
+				if err != nil || seconds <= 0 {
+					return nil, fmt.Errorf("invalid WALLET_LEDGER_RECONCILE_INTERVAL_SECONDS %q", val)
+				}
+				reconcileInterval = time.Duration(seconds) * time.Second
+			}
+			ctx.Lifecycle.Every("ledger-reconciliation", reconcileInterval, func(workerCtx context.Context) error {
+				report, err := walletService.ReconcileLedgerBalances(workerCtx)
+				if err != nil {
+					return err
+				}
+				if !report.Healthy {
+					ctx.Logger.With(map[string]interface{}{
+						"checked":    report.Checked,
+						"failed":     report.Failed,
+						"mismatches": len(report.Mismatches),
+					}).Warn("Wallet balances do not reconcile with the ledger")
+				}
+				return nil
+			})
+
 			beneficiaryService := service.NewBeneficiaryService(beneficiaryRepo, walletRepo, identityClient, eventPublisher)
+
+			// New beneficiaries can receive a capped amount until the cooling-off period passes
+			coolingOff := service.DefaultCoolingOffPolicy()
+			if val := os.Getenv("WALLET_BENEFICIARY_COOLING_OFF_HOURS"); val != "" {
+				hours, err := strconv.Atoi(val)
+				if err != nil || hours < 0 {
+					return nil, fmt.Errorf("invalid WALLET_BENEFICIARY_COOLING_OFF_HOURS %q", val)
+				}
+				coolingOff.Period = time.Duration(hours) * time.Hour
+			}
+			if val := os.Getenv("WALLET_BENEFICIARY_COOLING_OFF_LIMIT"); val != "" {
+				limit, err := strconv.ParseInt(val, 10, 64)
+				if err != nil || limit < 0 {
+					return nil, fmt.Errorf("invalid WALLET_BENEFICIARY_COOLING_OFF_LIMIT %q", val)
+				}
+				coolingOff.Limit = limit
+			}
+			// Overrides are verified with identity's step-up tokens, signed with the JWT secret
+			beneficiaryService.SetCoolingOff(coolingOff, server.RequireEnv("JWT_SECRET"))
+
 			upiDepositService := service.NewUPIDepositService(upiDepositRepo, walletRepo, eventPublisher)
-			virtualCardService := service.NewVirtualCardService(virtualCardRepo, walletRepo)
+			upiDepositService.SetAmountPolicies(transactionClient)
+			virtualCardService := service.NewVirtualCardService(virtualCardRepo, walletRepo, cardAutoFreezeRepo, cardClearingRepo, notificationClient)
+			virtualCardService.SetCardLedger(ledgerClient, server.GetEnv("CARD_SETTLEMENT_ACCOUNT_CODE", service.DefaultCardSettlementAccountCode))
 
 			// Initialize handler layer
 			walletHandler := handler.NewWalletHandler(walletService)
@@ -47,7 +158,6 @@ func main() {
 
 			// Setup routes
 			jwtSecret := server.RequireEnv("JWT_SECRET")
-			internalSecret := server.GetEnv("INTERNAL_SERVICE_SECRET", "")
 
 			return router.SetupRoutes(walletHandler, beneficiaryHandler, upiDepositHandler, virtualCardHandler, jwtSecret, internalSecret), nil
 		},
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// SkewBalance handles POST /internal/v1/simulation/balance-skews (internal endpoint)
+// This endpoint is called by the simulation service to inject a reconciliation anomaly.
+func (h *WalletHandler) SkewBalance(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.BalanceSkewRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	skew, skewErr := h.walletService.SkewBalance(r.Context(), req.Amount)
+	if skewErr != nil {
+		response.Error(w, skewErr)
+		return
+	}
+
+	response.Created(w, skew)
+}
+
+// RestoreBalance handles POST /internal/v1/simulation/balance-skews/restore (internal endpoint)
+// This endpoint is called by the simulation service to roll back an injected anomaly.
+func (h *WalletHandler) RestoreBalance(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.BalanceSkew](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	if restoreErr := h.walletService.RestoreBalance(r.Context(), &req); restoreErr != nil {
+		response.Error(w, restoreErr)
+		return
+	}
+
+	response.OK(w, req)
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	stderrors "errors"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/1mb-dev/gopantic/pkg/model"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
 	"github.com/1mb-dev/nivomoney/shared/pagination"
 	"github.com/1mb-dev/nivomoney/shared/response"
 )
@@ -27,8 +30,8 @@ func NewBeneficiaryHandler(beneficiaryService *service.BeneficiaryService) *Bene
 // AddBeneficiary handles POST /api/v1/beneficiaries
 func (h *BeneficiaryHandler) AddBeneficiary(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from context
-	userID := r.Context().Value("user_id")
-	if userID == nil {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
 		response.Error(w, errors.Unauthorized("user not authenticated"))
 		return
 	}
@@ -47,7 +50,7 @@ func (h *BeneficiaryHandler) AddBeneficiary(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	beneficiary, createErr := h.beneficiaryService.AddBeneficiary(r.Context(), userID.(string), &req)
+	beneficiary, createErr := h.beneficiaryService.AddBeneficiary(r.Context(), userID, &req)
 	if createErr != nil {
 		response.Error(w, createErr)
 		return
@@ -59,8 +62,8 @@ func (h *BeneficiaryHandler) AddBeneficiary(w http.ResponseWriter, r *http.Reque
 // GetBeneficiary handles GET /api/v1/beneficiaries/:id
 func (h *BeneficiaryHandler) GetBeneficiary(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from context
-	userID := r.Context().Value("user_id")
-	if userID == nil {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
 		response.Error(w, errors.Unauthorized("user not authenticated"))
 		return
 	}
@@ -71,7 +74,7 @@ func (h *BeneficiaryHandler) GetBeneficiary(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	beneficiary, err := h.beneficiaryService.GetBeneficiary(r.Context(), userID.(string), beneficiaryID)
+	beneficiary, err := h.beneficiaryService.GetBeneficiary(r.Context(), userID, beneficiaryID)
 	if err != nil {
 		response.Error(w, err)
 		return
@@ -83,8 +86,8 @@ func (h *BeneficiaryHandler) GetBeneficiary(w http.ResponseWriter, r *http.Reque
 // ListBeneficiaries handles GET /api/v1/beneficiaries
 func (h *BeneficiaryHandler) ListBeneficiaries(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from context
-	userID := r.Context().Value("user_id")
-	if userID == nil {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
 		response.Error(w, errors.Unauthorized("user not authenticated"))
 		return
 	}
@@ -92,7 +95,7 @@ func (h *BeneficiaryHandler) ListBeneficiaries(w http.ResponseWriter, r *http.Re
 	// Get pagination params
 	params := pagination.FromRequest(r)
 
-	beneficiaries, err := h.beneficiaryService.ListBeneficiaries(r.Context(), userID.(string))
+	beneficiaries, err := h.beneficiaryService.ListBeneficiaries(r.Context(), userID)
 	if err != nil {
 		response.Error(w, err)
 		return
@@ -124,8 +127,8 @@ func (h *BeneficiaryHandler) ListBeneficiaries(w http.ResponseWriter, r *http.Re
 // UpdateBeneficiary handles PUT /api/v1/beneficiaries/:id
 func (h *BeneficiaryHandler) UpdateBeneficiary(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from context
-	userID := r.Context().Value("user_id")
-	if userID == nil {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
 		response.Error(w, errors.Unauthorized("user not authenticated"))
 		return
 	}
@@ -150,7 +153,7 @@ func (h *BeneficiaryHandler) UpdateBeneficiary(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	beneficiary, updateErr := h.beneficiaryService.UpdateBeneficiary(r.Context(), userID.(string), beneficiaryID, &req)
+	beneficiary, updateErr := h.beneficiaryService.UpdateBeneficiary(r.Context(), userID, beneficiaryID, &req)
 	if updateErr != nil {
 		response.Error(w, updateErr)
 		return
@@ -162,8 +165,8 @@ func (h *BeneficiaryHandler) UpdateBeneficiary(w http.ResponseWriter, r *http.Re
 // DeleteBeneficiary handles DELETE /api/v1/beneficiaries/:id
 func (h *BeneficiaryHandler) DeleteBeneficiary(w http.ResponseWriter, r *http.Request) {
 	// Get authenticated user ID from context
-	userID := r.Context().Value("user_id")
-	if userID == nil {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
 		response.Error(w, errors.Unauthorized("user not authenticated"))
 		return
 	}
@@ -174,10 +177,153 @@ func (h *BeneficiaryHandler) DeleteBeneficiary(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	if err := h.beneficiaryService.DeleteBeneficiary(r.Context(), userID.(string), beneficiaryID); err != nil {
+	if err := h.beneficiaryService.DeleteBeneficiary(r.Context(), userID, beneficiaryID); err != nil {
 		response.Error(w, err)
 		return
 	}
 
 	response.NoContent(w)
 }
+
+// maxBeneficiaryImportBytes bounds an uploaded import, which is read into memory.
+const maxBeneficiaryImportBytes = 1 << 20
+
+// ExportBeneficiaries handles GET /api/v1/beneficiaries/export
+func (h *BeneficiaryHandler) ExportBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user ID from context
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	csvContent, err := h.beneficiaryService.ExportBeneficiaries(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=beneficiaries.csv")
+	w.Header().Set("Content-Length", strconv.Itoa(len(csvContent)))
+
+	_, _ = w.Write(csvContent)
+}
+
+// ImportBeneficiaries handles POST /api/v1/beneficiaries/import with a text/csv body
+func (h *BeneficiaryHandler) ImportBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user ID from context
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBeneficiaryImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stderrors.As(err, &tooLarge) {
+			response.Error(w, errors.PayloadTooLarge("import must be at most 1 MB"))
+			return
+		}
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	result, importErr := h.beneficiaryService.ImportBeneficiaries(r.Context(), userID, body)
+	if importErr != nil {
+		response.Error(w, importErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// OverrideCoolingOff handles POST /api/v1/beneficiaries/:id/cooling-off/override
+func (h *BeneficiaryHandler) OverrideCoolingOff(w http.ResponseWriter, r *http.Request) {
+	// Get authenticated user ID from context
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	beneficiaryID := r.PathValue("id")
+	if beneficiaryID == "" {
+		response.Error(w, errors.BadRequest("beneficiary ID is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	// Parse and validate request
+	req, parseErr := model.ParseInto[models.CoolingOffOverrideRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	beneficiary, overrideErr := h.beneficiaryService.OverrideCoolingOff(r.Context(), userID, beneficiaryID, &req)
+	if overrideErr != nil {
+		response.Error(w, overrideErr)
+		return
+	}
+
+	response.OK(w, models.ToBeneficiaryResponse(beneficiary))
+}
+
+// ReserveCoolingOff handles POST /internal/v1/beneficiaries/cooling-off/reserve (internal endpoint)
+// This endpoint is called by the transaction service when a transfer is created.
+func (h *BeneficiaryHandler) ReserveCoolingOff(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	// Parse and validate request
+	req, parseErr := model.ParseInto[models.CoolingOffCheckRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	if reserveErr := h.beneficiaryService.ReserveCoolingOff(r.Context(), &req); reserveErr != nil {
+		response.Error(w, reserveErr)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ReleaseCoolingOff handles POST /internal/v1/beneficiaries/cooling-off/release (internal endpoint)
+// This endpoint is called by the transaction service when a transfer fails.
+func (h *BeneficiaryHandler) ReleaseCoolingOff(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	// Parse and validate request
+	req, parseErr := model.ParseInto[models.CoolingOffCheckRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	if releaseErr := h.beneficiaryService.ReleaseCoolingOff(r.Context(), &req); releaseErr != nil {
+		response.Error(w, releaseErr)
+		return
+	}
+
+	response.NoContent(w)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/1mb-dev/gopantic/pkg/model"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
@@ -299,3 +300,203 @@ func (h *VirtualCardHandler) RevealCardDetails(w http.ResponseWriter, r *http.Re
 
 	response.OK(w, details)
 }
+
+// AuthorizeCard handles POST /internal/v1/cards/:id/authorize
+// Called by the card network to approve or decline a card authorization.
+func (h *VirtualCardHandler) AuthorizeCard(w http.ResponseWriter, r *http.Request) {
+	cardID := r.PathValue("id")
+	if cardID == "" {
+		response.Error(w, errors.BadRequest("card ID is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.CardAuthorizationRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	result, authErr := h.cardService.AuthorizeCard(r.Context(), cardID, &req)
+	if authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetAutoFreezeRule handles GET /api/v1/wallets/:walletId/cards/auto-freeze
+func (h *VirtualCardHandler) GetAutoFreezeRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	rule, err := h.cardService.GetAutoFreezeRule(r.Context(), walletID, userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, rule)
+}
+
+// SetAutoFreezeRule handles PUT /api/v1/wallets/:walletId/cards/auto-freeze
+func (h *VirtualCardHandler) SetAutoFreezeRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.SetCardAutoFreezeRuleRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	rule, setErr := h.cardService.SetAutoFreezeRule(r.Context(), walletID, userID, &req)
+	if setErr != nil {
+		response.Error(w, setErr)
+		return
+	}
+
+	response.OK(w, rule)
+}
+
+// DeleteAutoFreezeRule handles DELETE /api/v1/wallets/:walletId/cards/auto-freeze
+func (h *VirtualCardHandler) DeleteAutoFreezeRule(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("walletId")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	if err := h.cardService.DeleteAutoFreezeRule(r.Context(), walletID, userID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// ListNetworkCards handles GET /internal/v1/cards/network?limit=N (internal endpoint)
+// Returns a random sample of usable cards for the card network simulator.
+func (h *VirtualCardHandler) ListNetworkCards(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	cards, err := h.cardService.SampleNetworkCards(r.Context(), limit)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, cards)
+}
+
+// GetCardAuthorization handles GET /internal/v1/cards/authorizations/:id (internal endpoint)
+func (h *VirtualCardHandler) GetCardAuthorization(w http.ResponseWriter, r *http.Request) {
+	auth, err := h.cardService.GetCardAuthorization(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// SettleCardAuthorization handles POST /internal/v1/cards/authorizations/:id/settle (internal endpoint)
+func (h *VirtualCardHandler) SettleCardAuthorization(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	var req models.SettleCardAuthorizationRequest
+	if len(body) > 0 {
+		parsed, parseErr := model.ParseInto[models.SettleCardAuthorizationRequest](body)
+		if parseErr != nil {
+			response.Error(w, errors.Validation(parseErr.Error()))
+			return
+		}
+		req = parsed
+	}
+
+	auth, settleErr := h.cardService.SettleCardAuthorization(r.Context(), r.PathValue("id"), req.Amount)
+	if settleErr != nil {
+		response.Error(w, settleErr)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// ReverseCardAuthorization handles POST /internal/v1/cards/authorizations/:id/reverse (internal endpoint)
+func (h *VirtualCardHandler) ReverseCardAuthorization(w http.ResponseWriter, r *http.Request) {
+	auth, err := h.cardService.ReverseCardAuthorization(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, auth)
+}
+
+// RefundCardAuthorization handles POST /internal/v1/cards/authorizations/:id/refund (internal endpoint)
+func (h *VirtualCardHandler) RefundCardAuthorization(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.RefundCardAuthorizationRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	auth, refundErr := h.cardService.RefundCardAuthorization(r.Context(), r.PathValue("id"), req.RefundID, req.Amount)
+	if refundErr != nil {
+		response.Error(w, refundErr)
+		return
+	}
+
+	response.OK(w, auth)
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ScheduleClosure handles POST /api/v1/wallets/:id/closure
+func (h *WalletHandler) ScheduleClosure(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.ScheduleClosureRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	closure, scheduleErr := h.walletService.ScheduleClosure(r.Context(), walletID, &req)
+	if scheduleErr != nil {
+		response.Error(w, scheduleErr)
+		return
+	}
+
+	response.Created(w, closure)
+}
+
+// GetClosure handles GET /api/v1/wallets/:id/closure
+func (h *WalletHandler) GetClosure(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	closure, err := h.walletService.GetClosure(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, closure)
+}
+
+// CancelClosure handles DELETE /api/v1/wallets/:id/closure
+func (h *WalletHandler) CancelClosure(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	closure, err := h.walletService.CancelClosure(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, closure)
+}
+
+// ReopenWallet handles POST /api/v1/wallets/:id/reopen
+func (h *WalletHandler) ReopenWallet(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	closure, err := h.walletService.ReopenWallet(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, closure)
+}
+
+// ExecuteClosureSweep handles POST /internal/v1/wallets/closure-sweeps (internal endpoint)
+// This endpoint is called by the transaction service to move a closing wallet's balance.
+func (h *WalletHandler) ExecuteClosureSweep(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.ExecuteClosureSweepRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	if sweepErr := h.walletService.ExecuteClosureSweep(r.Context(), &req); sweepErr != nil {
+		response.Error(w, sweepErr)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"success":        true,
+		"closure_id":     req.ClosureID,
+		"wallet_id":      req.WalletID,
+		"amount":         req.Amount,
+		"transaction_id": req.TransactionID,
+	})
+}
@@ -1,15 +1,21 @@
 package handler
 
 import (
+	"encoding/json"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/1mb-dev/gopantic/pkg/model"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/service"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/pagination"
 	"github.com/1mb-dev/nivomoney/shared/response"
+	_ "github.com/1mb-dev/nivomoney/shared/validator" // Registers custom validation tags
 )
 
 // WalletHandler handles HTTP requests for wallet operations.
@@ -68,7 +74,33 @@ func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wallet, err := h.walletService.GetWallet(r.Context(), walletID)
+	wallet, err := h.walletService.GetAuthorizedWallet(r.Context(), walletID, models.WalletPermissionView)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, wallet)
+}
+
+// UpdateWallet handles PATCH /api/v1/wallets/:id
+// Sets the wallet's nickname, color, tags, metadata or default flag.
+func (h *WalletHandler) UpdateWallet(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	var req models.UpdateWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, errors.BadRequest("invalid request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	wallet, err := h.walletService.UpdateWallet(r.Context(), walletID, &req)
 	if err != nil {
 		response.Error(w, err)
 		return
@@ -77,7 +109,23 @@ func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
 	response.OK(w, wallet)
 }
 
+// walletListFilters reads the tag and nickname search (q) query parameters of
+// a wallet listing, normalizing the tag.
+func walletListFilters(r *http.Request) (string, string, *errors.Error) {
+	query := r.URL.Query()
+	tag := query.Get("tag")
+	if tag != "" {
+		normalized, err := models.NormalizeWalletTag(tag)
+		if err != nil {
+			return "", "", errors.BadRequest(err.Error())
+		}
+		tag = normalized
+	}
+	return tag, query.Get("q"), nil
+}
+
 // ListUserWallets handles GET /api/v1/users/:userId/wallets
+// Query params: status, tag, q (nickname search).
 func (h *WalletHandler) ListUserWallets(w http.ResponseWriter, r *http.Request) {
 	userID := r.PathValue("userId")
 
@@ -94,16 +142,203 @@ func (h *WalletHandler) ListUserWallets(w http.ResponseWriter, r *http.Request)
 		status = &s
 	}
 
+	tag, search, filterErr := walletListFilters(r)
+	if filterErr != nil {
+		response.Error(w, filterErr)
+		return
+	}
+
 	wallets, err := h.walletService.ListUserWallets(r.Context(), userID, status)
 	if err != nil {
 		response.Error(w, err)
 		return
 	}
 
-	response.OK(w, wallets)
+	response.OK(w, service.FilterWallets(wallets, tag, search))
+}
+
+// BackfillLedgerAccounts provisions ledger accounts for wallets missing theirs.
+// POST /api/v1/admin/wallets/ledger-accounts/backfill?limit=100&dry_run=true
+func (h *WalletHandler) BackfillLedgerAccounts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &models.LedgerBackfillRequest{}
+
+	if limitParam := query.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			response.Error(w, errors.BadRequest("invalid limit value"))
+			return
+		}
+		req.Limit = limit
+	}
+	if dryRunParam := query.Get("dry_run"); dryRunParam != "" {
+		dryRun, err := strconv.ParseBool(dryRunParam)
+		if err != nil {
+			response.Error(w, errors.BadRequest("invalid dry_run value"))
+			return
+		}
+		req.DryRun = dryRun
+	}
+
+	result, err := h.walletService.BackfillLedgerAccounts(r.Context(), req)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, result)
+}
+
+// GetReconciliationReport returns the latest wallet to ledger balance reconciliation.
+// GET /api/v1/admin/wallets/reconciliation
+func (h *WalletHandler) GetReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.walletService.LatestReconciliation()
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, report)
+}
+
+// ListAllWallets handles GET /api/v1/admin/wallets - lists wallets across all users.
+// Query params: user_id, status, type, currency, min_balance, max_balance,
+// tag, created_from, created_to (YYYY-MM-DD or RFC3339; a bare created_to date is
+// inclusive), sort (created_at, updated_at, balance), order (asc, desc),
+// page, per_page.
+func (h *WalletHandler) ListAllWallets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.WalletFilter{}
+
+	if userID := query.Get("user_id"); userID != "" {
+		filter.UserID = &userID
+	}
+
+	if statusParam := query.Get("status"); statusParam != "" {
+		status := models.WalletStatus(statusParam)
+		switch status {
+		case models.WalletStatusActive, models.WalletStatusFrozen, models.WalletStatusClosed, models.WalletStatusInactive:
+			filter.Status = &status
+		default:
+			response.Error(w, errors.BadRequest("invalid status value"))
+			return
+		}
+	}
+
+	if typeParam := query.Get("type"); typeParam != "" {
+		walletType := models.WalletType(typeParam)
+		if walletType != models.WalletTypeDefault {
+			response.Error(w, errors.BadRequest("invalid type value"))
+			return
+		}
+		filter.Type = &walletType
+	}
+
+	if currencyParam := query.Get("currency"); currencyParam != "" {
+		currency, err := sharedModels.ParseCurrency(currencyParam)
+		if err != nil {
+			response.Error(w, errors.BadRequest("invalid currency value"))
+			return
+		}
+		filter.Currency = &currency
+	}
+
+	if tagParam := query.Get("tag"); tagParam != "" {
+		tag, err := models.NormalizeWalletTag(tagParam)
+		if err != nil {
+			response.Error(w, errors.BadRequest(err.Error()))
+			return
+		}
+		filter.Tag = &tag
+	}
+
+	// Balance range filters (in smallest unit - paise)
+	var badParam bool
+	if filter.MinBalance, badParam = parseBalanceParam(query.Get("min_balance")); badParam {
+		response.Error(w, errors.BadRequest("invalid min_balance value"))
+		return
+	}
+	if filter.MaxBalance, badParam = parseBalanceParam(query.Get("max_balance")); badParam {
+		response.Error(w, errors.BadRequest("invalid max_balance value"))
+		return
+	}
+
+	if fromParam := query.Get("created_from"); fromParam != "" {
+		from, _, err := parseDateParam(fromParam)
+		if err != nil {
+			response.Error(w, errors.BadRequest("invalid created_from value, expected YYYY-MM-DD or RFC3339"))
+			return
+		}
+		filter.CreatedAfter = &from
+	}
+	if toParam := query.Get("created_to"); toParam != "" {
+		to, dateOnly, err := parseDateParam(toParam)
+		if err != nil {
+			response.Error(w, errors.BadRequest("invalid created_to value, expected YYYY-MM-DD or RFC3339"))
+			return
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.CreatedBefore = &to
+	}
+
+	if sortParam := query.Get("sort"); sortParam != "" {
+		switch sortParam {
+		case models.WalletSortCreatedAt, models.WalletSortUpdatedAt, models.WalletSortBalance:
+			filter.SortBy = sortParam
+		default:
+			response.Error(w, errors.BadRequest("invalid sort value, expected created_at, updated_at or balance"))
+			return
+		}
+	}
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.SortAsc = true
+	default:
+		response.Error(w, errors.BadRequest("invalid order value, expected asc or desc"))
+		return
+	}
+
+	page := pagination.FromRequest(r)
+	filter.Limit = page.PerPage
+	filter.Offset = page.Offset
+
+	wallets, total, err := h.walletService.SearchWallets(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Paginated(w, wallets, page.Page, page.PerPage, total)
+}
+
+// parseBalanceParam parses an optional non-negative balance query parameter.
+// The second result reports an invalid value.
+func parseBalanceParam(value string) (*int64, bool) {
+	if value == "" {
+		return nil, false
+	}
+	balance, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || balance < 0 {
+		return nil, true
+	}
+	return &balance, false
+}
+
+// parseDateParam parses a YYYY-MM-DD or RFC3339 timestamp, reporting whether
+// the value was a bare date.
+func parseDateParam(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
 }
 
-// ListMyWallets handles GET /api/v1/wallets - lists wallets for authenticated user
+// ListMyWallets handles GET /api/v1/wallets - lists wallets for authenticated user,
+// including joint wallets they co-own. Query params: status, tag, q (nickname search).
 func (h *WalletHandler) ListMyWallets(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
 	userID, ok := middleware.GetUserID(r.Context())
@@ -120,13 +355,19 @@ func (h *WalletHandler) ListMyWallets(w http.ResponseWriter, r *http.Request) {
 		status = &s
 	}
 
-	wallets, err := h.walletService.ListUserWallets(r.Context(), userID, status)
+	tag, search, filterErr := walletListFilters(r)
+	if filterErr != nil {
+		response.Error(w, filterErr)
+		return
+	}
+
+	wallets, err := h.walletService.ListAccessibleWallets(r.Context(), userID, status)
 	if err != nil {
 		response.Error(w, err)
 		return
 	}
 
-	response.OK(w, wallets)
+	response.OK(w, service.FilterWallets(wallets, tag, search))
 }
 
 // ActivateWallet handles POST /api/v1/wallets/:id/activate
@@ -238,6 +479,11 @@ func (h *WalletHandler) GetWalletBalance(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, authErr := h.walletService.GetAuthorizedWallet(r.Context(), walletID, models.WalletPermissionView); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
 	balance, err := h.walletService.GetWalletBalance(r.Context(), walletID)
 	if err != nil {
 		response.Error(w, err)
@@ -256,6 +502,11 @@ func (h *WalletHandler) GetWalletLimits(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if _, authErr := h.walletService.GetAuthorizedWallet(r.Context(), walletID, models.WalletPermissionView); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
 	limits, err := h.walletService.GetWalletLimits(r.Context(), walletID)
 	if err != nil {
 		response.Error(w, err)
@@ -265,6 +516,29 @@ func (h *WalletHandler) GetWalletLimits(w http.ResponseWriter, r *http.Request)
 	response.OK(w, limits)
 }
 
+// GetRemainingLimits handles GET /api/v1/wallets/:id/limits/remaining
+func (h *WalletHandler) GetRemainingLimits(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	if _, authErr := h.walletService.GetAuthorizedWallet(r.Context(), walletID, models.WalletPermissionView); authErr != nil {
+		response.Error(w, authErr)
+		return
+	}
+
+	remaining, err := h.walletService.GetRemainingLimits(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, remaining)
+}
+
 // UpdateWalletLimits handles PUT /api/v1/wallets/:id/limits
 func (h *WalletHandler) UpdateWalletLimits(w http.ResponseWriter, r *http.Request) {
 	walletID := r.PathValue("id")
@@ -336,6 +610,22 @@ func (h *WalletHandler) ProcessTransfer(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// GetTransferStatus handles GET /internal/v1/wallets/transfers/{transactionId} (internal endpoint)
+// It reports whether the transfer has moved wallet balances.
+func (h *WalletHandler) GetTransferStatus(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.PathValue("transactionId")
+	processed, err := h.walletService.IsTransferProcessed(r.Context(), transactionID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"transaction_id": transactionID,
+		"processed":      processed,
+	})
+}
+
 // ProcessDeposit handles POST /internal/v1/wallets/deposit (internal endpoint)
 // This endpoint is called by the transaction service to credit deposits to wallets.
 func (h *WalletHandler) ProcessDeposit(w http.ResponseWriter, r *http.Request) {
@@ -375,6 +665,7 @@ func (h *WalletHandler) ProcessDeposit(w http.ResponseWriter, r *http.Request) {
 
 // GetWalletInfo handles GET /internal/v1/wallets/:id/info (internal endpoint)
 // This endpoint returns wallet information including ownership for authorization checks.
+// Members lists the active co-owners of a joint wallet with their permission.
 func (h *WalletHandler) GetWalletInfo(w http.ResponseWriter, r *http.Request) {
 	walletID := r.PathValue("id")
 
@@ -389,14 +680,50 @@ func (h *WalletHandler) GetWalletInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	members, err := h.walletService.ListWalletMembers(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+	memberInfo := make([]map[string]interface{}, 0, len(members))
+	for _, member := range members {
+		memberInfo = append(memberInfo, map[string]interface{}{
+			"user_id":    member.UserID,
+			"permission": member.Permission,
+		})
+	}
+
 	response.OK(w, map[string]interface{}{
 		"id":                wallet.ID,
 		"user_id":           wallet.UserID,
 		"status":            wallet.Status,
+		"currency":          wallet.Currency,
+		"available_balance": wallet.AvailableBalance,
 		"ledger_account_id": wallet.LedgerAccountID,
+		"nickname":          wallet.DisplayName(),
+		"members":           memberInfo,
 	})
 }
 
+// GetWalletLimitsInternal handles GET /internal/v1/wallets/:id/limits (internal endpoint)
+// This endpoint is called by the transaction service to preview a transfer's limit impact.
+func (h *WalletHandler) GetWalletLimitsInternal(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	limits, err := h.walletService.GetWalletLimits(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, limits)
+}
+
 // CreateWalletInternal handles POST /internal/v1/wallets (internal endpoint)
 // This endpoint is called by the identity service to create wallets during user registration.
 func (h *WalletHandler) CreateWalletInternal(w http.ResponseWriter, r *http.Request) {
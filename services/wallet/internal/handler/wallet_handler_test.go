@@ -37,6 +37,7 @@ type mockWalletRepository struct {
 	UpdateLimitsFunc    func(ctx context.Context, walletID string, dailyLimit, monthlyLimit int64) *errors.Error
 	ProcessTransferFunc func(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error
 	UpdateBalanceFunc   func(ctx context.Context, walletID string, amount int64) *errors.Error
+	SearchFunc          func(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error)
 }
 
 func newMockWalletRepository() *mockWalletRepository {
@@ -84,6 +85,19 @@ func (m *mockWalletRepository) ListByUserID(ctx context.Context, userID string,
 	return result, nil
 }
 
+func (m *mockWalletRepository) Search(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(ctx, filter)
+	}
+	result := make([]*models.Wallet, 0)
+	for _, w := range m.wallets {
+		if filter.Status == nil || w.Status == *filter.Status {
+			result = append(result, w)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
 func (m *mockWalletRepository) UpdateStatus(ctx context.Context, id string, status models.WalletStatus) *errors.Error {
 	if m.UpdateStatusFunc != nil {
 		return m.UpdateStatusFunc(ctx, id, status)
@@ -149,6 +163,10 @@ func (m *mockWalletRepository) UpdateLimits(ctx context.Context, walletID string
 	return nil
 }
 
+func (m *mockWalletRepository) ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error) {
+	return 0, nil
+}
+
 func (m *mockWalletRepository) ProcessTransferWithinTx(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error {
 	if m.ProcessTransferFunc != nil {
 		return m.ProcessTransferFunc(ctx, sourceWalletID, destWalletID, amount, transactionID)
@@ -169,6 +187,10 @@ func (m *mockWalletRepository) ProcessTransferWithinTx(ctx context.Context, sour
 	return nil
 }
 
+func (m *mockWalletRepository) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	return false, nil
+}
+
 func (m *mockWalletRepository) ProcessDepositWithinTx(ctx context.Context, walletID string, amount int64, transactionID string) *errors.Error {
 	if wallet, ok := m.wallets[walletID]; ok {
 		wallet.Balance += amount
@@ -178,6 +200,20 @@ func (m *mockWalletRepository) ProcessDepositWithinTx(ctx context.Context, walle
 	return errors.NotFound("wallet not found")
 }
 
+func (m *mockWalletRepository) UpdateProfile(ctx context.Context, id string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error) {
+	wallet, ok := m.wallets[id]
+	if !ok {
+		return nil, errors.NotFound("wallet not found")
+	}
+	if req.Nickname != nil {
+		wallet.Nickname = req.Nickname
+	}
+	if req.Tags != nil {
+		wallet.Tags = *req.Tags
+	}
+	return wallet, nil
+}
+
 func (m *mockWalletRepository) UpdateBalance(ctx context.Context, walletID string, amount int64) *errors.Error {
 	if m.UpdateBalanceFunc != nil {
 		return m.UpdateBalanceFunc(ctx, walletID, amount)
@@ -190,6 +226,14 @@ func (m *mockWalletRepository) UpdateBalance(ctx context.Context, walletID strin
 	return errors.NotFound("wallet not found")
 }
 
+func (m *mockWalletRepository) UpdateLedgerAccount(ctx context.Context, walletID, fromAccountID, toAccountID string) *errors.Error {
+	if wallet, ok := m.wallets[walletID]; ok && wallet.LedgerAccountID == fromAccountID {
+		wallet.LedgerAccountID = toAccountID
+		return nil
+	}
+	return errors.Conflict("wallet not found or its ledger account changed")
+}
+
 // AddWallet adds a wallet to the mock store (for test setup).
 func (m *mockWalletRepository) AddWallet(wallet *models.Wallet) {
 	m.wallets[wallet.ID] = wallet
@@ -287,6 +331,13 @@ func makeAuthenticatedRequest(t *testing.T, handler http.HandlerFunc, method, pa
 // makeRequestWithPathValue creates a request with path value for testing.
 func makeRequestWithPathValue(t *testing.T, handler http.HandlerFunc, method, path, pathKey, pathValue string, body interface{}) (*httptest.ResponseRecorder, *apiResponse) {
 	t.Helper()
+	return makeAuthenticatedRequestWithPathValue(t, handler, method, path, pathKey, pathValue, body, "")
+}
+
+// makeAuthenticatedRequestWithPathValue creates a request with path value and,
+// unless userID is empty, user ID in context.
+func makeAuthenticatedRequestWithPathValue(t *testing.T, handler http.HandlerFunc, method, path, pathKey, pathValue string, body interface{}, userID string) (*httptest.ResponseRecorder, *apiResponse) {
+	t.Helper()
 
 	var bodyReader *bytes.Buffer
 	if body != nil {
@@ -300,6 +351,9 @@ func makeRequestWithPathValue(t *testing.T, handler http.HandlerFunc, method, pa
 	req := httptest.NewRequest(method, path, bodyReader)
 	req.Header.Set("Content-Type", "application/json")
 	req.SetPathValue(pathKey, pathValue)
+	if userID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+	}
 
 	rec := httptest.NewRecorder()
 	handler.ServeHTTP(rec, req)
@@ -339,7 +393,7 @@ func TestWalletHandler_CreateWallet(t *testing.T) {
 		body := map[string]interface{}{
 			"type":              "default",
 			"currency":          "INR",
-			"ledger_account_id": "ledger-acct-123",
+			"ledger_account_id": "3f2b8c1e-7d4a-4e6b-9c5f-2a1d8e7b6c01",
 		}
 
 		rec, resp := makeAuthenticatedRequest(t, handler.CreateWallet, http.MethodPost, "/api/v1/wallets", body, "user-123")
@@ -362,12 +416,12 @@ func TestWalletHandler_CreateWallet(t *testing.T) {
 		body := map[string]interface{}{
 			"type":              "default",
 			"currency":          "INR",
-			"ledger_account_id": "ledger-acct-456",
+			"ledger_account_id": "3f2b8c1e-7d4a-4e6b-9c5f-2a1d8e7b6c02",
 		}
 		makeAuthenticatedRequest(t, handler.CreateWallet, http.MethodPost, "/api/v1/wallets", body, "user-456")
 
 		// Second wallet with same currency (different ledger account, but conflict due to same currency)
-		body["ledger_account_id"] = "ledger-acct-456-2"
+		body["ledger_account_id"] = "3f2b8c1e-7d4a-4e6b-9c5f-2a1d8e7b6c03"
 		rec, resp := makeAuthenticatedRequest(t, handler.CreateWallet, http.MethodPost, "/api/v1/wallets", body, "user-456")
 
 		assert.Equal(t, http.StatusConflict, rec.Code)
@@ -425,7 +479,7 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 	walletRepo.AddWallet(testWallet)
 
 	t.Run("get existing wallet returns 200", func(t *testing.T) {
-		rec, resp := makeRequestWithPathValue(t, handler.GetWallet, http.MethodGet, "/api/v1/wallets/wallet-test-get", "id", "wallet-test-get", nil)
+		rec, resp := makeAuthenticatedRequestWithPathValue(t, handler.GetWallet, http.MethodGet, "/api/v1/wallets/wallet-test-get", "id", "wallet-test-get", nil, "user-get-test")
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.True(t, resp.Success)
@@ -439,6 +493,13 @@ func TestWalletHandler_GetWallet(t *testing.T) {
 		assert.Equal(t, "user-get-test", wallet["user_id"])
 	})
 
+	t.Run("get another user's wallet returns 403", func(t *testing.T) {
+		rec, resp := makeAuthenticatedRequestWithPathValue(t, handler.GetWallet, http.MethodGet, "/api/v1/wallets/wallet-test-get", "id", "wallet-test-get", nil, "user-other")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.False(t, resp.Success)
+	})
+
 	t.Run("get non-existent wallet returns 404", func(t *testing.T) {
 		rec, resp := makeRequestWithPathValue(t, handler.GetWallet, http.MethodGet, "/api/v1/wallets/non-existent", "id", "non-existent", nil)
 
@@ -516,6 +577,70 @@ func TestWalletHandler_ListMyWallets(t *testing.T) {
 	})
 }
 
+func TestWalletHandler_ListAllWallets(t *testing.T) {
+	walletService, walletRepo := createTestWalletService()
+	handler := NewWalletHandler(walletService)
+
+	var gotFilter *models.WalletFilter
+	walletRepo.SearchFunc = func(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+		gotFilter = filter
+		return []*models.Wallet{{ID: "wallet-admin-1", Status: models.WalletStatusFrozen}}, 45, nil
+	}
+
+	t.Run("applies filters, sorting and pagination", func(t *testing.T) {
+		url := "/api/v1/admin/wallets?status=frozen&type=default&currency=INR&min_balance=100&max_balance=5000" +
+			"&created_from=2026-01-01&created_to=2026-01-31&sort=balance&order=asc&page=3&per_page=20"
+		rec, resp := makeRequest(t, handler.ListAllWallets, http.MethodGet, url, nil)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, resp.Success)
+		require.NotNil(t, gotFilter)
+		assert.Equal(t, models.WalletStatusFrozen, *gotFilter.Status)
+		assert.Equal(t, models.WalletTypeDefault, *gotFilter.Type)
+		assert.Equal(t, "INR", string(*gotFilter.Currency))
+		assert.Equal(t, int64(100), *gotFilter.MinBalance)
+		assert.Equal(t, int64(5000), *gotFilter.MaxBalance)
+		assert.Equal(t, "2026-01-01", gotFilter.CreatedAfter.Format("2006-01-02"))
+		assert.Equal(t, "2026-02-01", gotFilter.CreatedBefore.Format("2006-01-02"), "a bare created_to date is inclusive")
+		assert.Equal(t, models.WalletSortBalance, gotFilter.SortBy)
+		assert.True(t, gotFilter.SortAsc)
+		assert.Equal(t, 20, gotFilter.Limit)
+		assert.Equal(t, 40, gotFilter.Offset)
+
+		var body struct {
+			Meta struct {
+				Pagination struct {
+					TotalItems int64 `json:"total_items"`
+					TotalPages int   `json:"total_pages"`
+				} `json:"pagination"`
+			} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, int64(45), body.Meta.Pagination.TotalItems)
+		assert.Equal(t, 3, body.Meta.Pagination.TotalPages)
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"status=deleted",
+			"type=savings",
+			"currency=XYZ",
+			"min_balance=-1",
+			"max_balance=abc",
+			"created_from=01/02/2026",
+			"sort=user_id",
+			"order=up",
+			"min_balance=500&max_balance=100",
+			"created_from=2026-02-01&created_to=2026-01-01",
+		} {
+			rec, resp := makeRequest(t, handler.ListAllWallets, http.MethodGet, "/api/v1/admin/wallets?"+query, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+			assert.False(t, resp.Success, query)
+		}
+	})
+}
+
 func TestWalletHandler_ActivateWallet(t *testing.T) {
 	walletService, walletRepo := createTestWalletService()
 	handler := NewWalletHandler(walletService)
@@ -615,7 +740,7 @@ func TestWalletHandler_GetWalletBalance(t *testing.T) {
 	walletRepo.AddWallet(walletWithBalance)
 
 	t.Run("get balance returns 200 with correct data", func(t *testing.T) {
-		rec, resp := makeRequestWithPathValue(t, handler.GetWalletBalance, http.MethodGet, "/api/v1/wallets/wallet-balance-test/balance", "id", "wallet-balance-test", nil)
+		rec, resp := makeAuthenticatedRequestWithPathValue(t, handler.GetWalletBalance, http.MethodGet, "/api/v1/wallets/wallet-balance-test/balance", "id", "wallet-balance-test", nil, "user-balance-test")
 
 		assert.Equal(t, http.StatusOK, rec.Code)
 		assert.True(t, resp.Success)
@@ -645,7 +770,7 @@ func TestWalletHandler_ProcessTransfer(t *testing.T) {
 
 	// Setup: Add source and destination wallets
 	sourceWallet := &models.Wallet{
-		ID:               "wallet-source",
+		ID:               "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e11",
 		UserID:           "user-source",
 		Type:             models.WalletTypeDefault,
 		Currency:         "INR",
@@ -654,7 +779,7 @@ func TestWalletHandler_ProcessTransfer(t *testing.T) {
 		Status:           models.WalletStatusActive,
 	}
 	destWallet := &models.Wallet{
-		ID:               "wallet-dest",
+		ID:               "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e12",
 		UserID:           "user-dest",
 		Type:             models.WalletTypeDefault,
 		Currency:         "INR",
@@ -667,10 +792,10 @@ func TestWalletHandler_ProcessTransfer(t *testing.T) {
 
 	t.Run("process valid transfer returns 200", func(t *testing.T) {
 		body := map[string]interface{}{
-			"source_wallet_id":      "wallet-source",
-			"destination_wallet_id": "wallet-dest",
+			"source_wallet_id":      "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e11",
+			"destination_wallet_id": "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e12",
 			"amount":                100000, // 1000 rupees
-			"transaction_id":        "tx-123",
+			"transaction_id":        "b7d9f1a3-5c7e-4a9b-8d1f-4e6a8c0b2d01",
 		}
 
 		rec, resp := makeRequest(t, handler.ProcessTransfer, http.MethodPost, "/internal/v1/wallets/transfer", body)
@@ -683,16 +808,16 @@ func TestWalletHandler_ProcessTransfer(t *testing.T) {
 		err := json.Unmarshal(resp.Data, &result)
 		require.NoError(t, err)
 		assert.Equal(t, true, result["success"])
-		assert.Equal(t, "wallet-source", result["source_wallet_id"])
-		assert.Equal(t, "wallet-dest", result["dest_wallet_id"])
+		assert.Equal(t, "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e11", result["source_wallet_id"])
+		assert.Equal(t, "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e12", result["dest_wallet_id"])
 	})
 
 	t.Run("process transfer with insufficient balance returns error", func(t *testing.T) {
 		body := map[string]interface{}{
-			"source_wallet_id":      "wallet-source",
-			"destination_wallet_id": "wallet-dest",
+			"source_wallet_id":      "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e11",
+			"destination_wallet_id": "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e12",
 			"amount":                99999999999, // More than available
-			"transaction_id":        "tx-456",
+			"transaction_id":        "b7d9f1a3-5c7e-4a9b-8d1f-4e6a8c0b2d02",
 		}
 
 		rec, resp := makeRequest(t, handler.ProcessTransfer, http.MethodPost, "/internal/v1/wallets/transfer", body)
@@ -704,7 +829,7 @@ func TestWalletHandler_ProcessTransfer(t *testing.T) {
 
 	t.Run("process transfer with missing fields returns validation error", func(t *testing.T) {
 		body := map[string]interface{}{
-			"source_wallet_id": "wallet-source",
+			"source_wallet_id": "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e11",
 			// missing destination and amount
 		}
 
@@ -723,7 +848,7 @@ func TestWalletHandler_ProcessDeposit(t *testing.T) {
 
 	// Setup: Add a wallet for deposit
 	depositWallet := &models.Wallet{
-		ID:               "wallet-deposit",
+		ID:               "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e13",
 		UserID:           "user-deposit",
 		Type:             models.WalletTypeDefault,
 		Currency:         "INR",
@@ -735,9 +860,9 @@ func TestWalletHandler_ProcessDeposit(t *testing.T) {
 
 	t.Run("process valid deposit returns 200", func(t *testing.T) {
 		body := map[string]interface{}{
-			"wallet_id":      "wallet-deposit",
+			"wallet_id":      "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e13",
 			"amount":         500000, // 5000 rupees
-			"transaction_id": "tx-deposit-123",
+			"transaction_id": "b7d9f1a3-5c7e-4a9b-8d1f-4e6a8c0b2d03",
 		}
 
 		rec, resp := makeRequest(t, handler.ProcessDeposit, http.MethodPost, "/internal/v1/wallets/deposit", body)
@@ -750,14 +875,14 @@ func TestWalletHandler_ProcessDeposit(t *testing.T) {
 		err := json.Unmarshal(resp.Data, &result)
 		require.NoError(t, err)
 		assert.Equal(t, true, result["success"])
-		assert.Equal(t, "wallet-deposit", result["wallet_id"])
+		assert.Equal(t, "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0e13", result["wallet_id"])
 	})
 
 	t.Run("process deposit to non-existent wallet returns 404", func(t *testing.T) {
 		body := map[string]interface{}{
-			"wallet_id":      "non-existent-wallet",
+			"wallet_id":      "a1c4e6f8-0b2d-4f6a-8c1e-3d5b7a9c0eff",
 			"amount":         500000,
-			"transaction_id": "tx-deposit-456",
+			"transaction_id": "b7d9f1a3-5c7e-4a9b-8d1f-4e6a8c0b2d04",
 		}
 
 		rec, resp := makeRequest(t, handler.ProcessDeposit, http.MethodPost, "/internal/v1/wallets/deposit", body)
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/1mb-dev/gopantic/pkg/model"
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// ListOwners handles GET /api/v1/wallets/:id/owners
+func (h *WalletHandler) ListOwners(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	owners, err := h.walletService.ListOwners(r.Context(), walletID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, owners)
+}
+
+// InviteCoOwner handles POST /api/v1/wallets/:id/owners/invites
+func (h *WalletHandler) InviteCoOwner(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.InviteCoOwnerRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	member, inviteErr := h.walletService.InviteCoOwner(r.Context(), walletID, &req)
+	if inviteErr != nil {
+		response.Error(w, inviteErr)
+		return
+	}
+
+	response.Created(w, member)
+}
+
+// UpdateCoOwner handles PUT /api/v1/wallets/:id/owners/:userId
+func (h *WalletHandler) UpdateCoOwner(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	memberUserID := r.PathValue("userId")
+	if walletID == "" || memberUserID == "" {
+		response.Error(w, errors.BadRequest("wallet ID and user ID are required"))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Error(w, errors.BadRequest("failed to read request body"))
+		return
+	}
+	defer func() { _ = r.Body.Close() }()
+
+	req, parseErr := model.ParseInto[models.UpdateCoOwnerRequest](body)
+	if parseErr != nil {
+		response.Error(w, errors.Validation(parseErr.Error()))
+		return
+	}
+
+	member, updateErr := h.walletService.UpdateCoOwnerPermission(r.Context(), walletID, memberUserID, &req)
+	if updateErr != nil {
+		response.Error(w, updateErr)
+		return
+	}
+
+	response.OK(w, member)
+}
+
+// RemoveCoOwner handles DELETE /api/v1/wallets/:id/owners/:userId
+func (h *WalletHandler) RemoveCoOwner(w http.ResponseWriter, r *http.Request) {
+	walletID := r.PathValue("id")
+	memberUserID := r.PathValue("userId")
+	if walletID == "" || memberUserID == "" {
+		response.Error(w, errors.BadRequest("wallet ID and user ID are required"))
+		return
+	}
+
+	if err := h.walletService.RemoveCoOwner(r.Context(), walletID, memberUserID); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, map[string]string{"message": "co-owner removed"})
+}
+
+// ListInvites handles GET /api/v1/wallet-invites - the caller's pending invites
+func (h *WalletHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	invites, err := h.walletService.ListInvites(r.Context(), userID)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, invites)
+}
+
+// AcceptInvite handles POST /api/v1/wallets/:id/owners/invite/accept
+func (h *WalletHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, true)
+}
+
+// DeclineInvite handles POST /api/v1/wallets/:id/owners/invite/decline
+func (h *WalletHandler) DeclineInvite(w http.ResponseWriter, r *http.Request) {
+	h.respondToInvite(w, r, false)
+}
+
+func (h *WalletHandler) respondToInvite(w http.ResponseWriter, r *http.Request, accept bool) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok || userID == "" {
+		response.Error(w, errors.Unauthorized("user not authenticated"))
+		return
+	}
+
+	walletID := r.PathValue("id")
+	if walletID == "" {
+		response.Error(w, errors.BadRequest("wallet ID is required"))
+		return
+	}
+
+	member, err := h.walletService.RespondToInvite(r.Context(), walletID, userID, accept)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, member)
+}
@@ -1,20 +1,30 @@
 package models
 
 import (
+	"time"
+
 	"github.com/1mb-dev/nivomoney/shared/models"
 )
 
 // Beneficiary represents a saved recipient for quick transfers.
 type Beneficiary struct {
-	ID                  string            `json:"id" db:"id"`
-	OwnerUserID         string            `json:"owner_user_id" db:"owner_user_id"`                 // User who saved this beneficiary
-	BeneficiaryUserID   string            `json:"beneficiary_user_id" db:"beneficiary_user_id"`     // User being saved
-	BeneficiaryWalletID string            `json:"beneficiary_wallet_id" db:"beneficiary_wallet_id"` // Default wallet for transfers
-	Nickname            string            `json:"nickname" db:"nickname"`                           // Friendly name
-	BeneficiaryPhone    string            `json:"beneficiary_phone" db:"beneficiary_phone"`         // Phone for display
-	Metadata            map[string]string `json:"metadata,omitempty" db:"metadata"`                 // JSONB metadata
-	CreatedAt           models.Timestamp  `json:"created_at" db:"created_at"`
-	UpdatedAt           models.Timestamp  `json:"updated_at" db:"updated_at"`
+	ID                     string            `json:"id" db:"id"`
+	OwnerUserID            string            `json:"owner_user_id" db:"owner_user_id"`                                   // User who saved this beneficiary
+	BeneficiaryUserID      string            `json:"beneficiary_user_id" db:"beneficiary_user_id"`                       // User being saved
+	BeneficiaryWalletID    string            `json:"beneficiary_wallet_id" db:"beneficiary_wallet_id"`                   // Default wallet for transfers
+	Nickname               string            `json:"nickname" db:"nickname"`                                             // Friendly name
+	BeneficiaryPhone       string            `json:"beneficiary_phone" db:"beneficiary_phone"`                           // Phone for display
+	Metadata               map[string]string `json:"metadata,omitempty" db:"metadata"`                                   // JSONB metadata
+	CoolingOffUntil        *models.Timestamp `json:"cooling_off_until,omitempty" db:"cooling_off_until"`                 // Nil if added without a cooling-off period
+	CoolingOffSent         int64             `json:"cooling_off_sent" db:"cooling_off_sent"`                             // Paise sent during the cooling-off period
+	CoolingOffOverriddenAt *models.Timestamp `json:"cooling_off_overridden_at,omitempty" db:"cooling_off_overridden_at"` // Lifted early by step-up verification
+	CreatedAt              models.Timestamp  `json:"created_at" db:"created_at"`
+	UpdatedAt              models.Timestamp  `json:"updated_at" db:"updated_at"`
+}
+
+// InCoolingOff returns true if transfers to the beneficiary are capped at t.
+func (b *Beneficiary) InCoolingOff(t time.Time) bool {
+	return b.CoolingOffUntil != nil && b.CoolingOffOverriddenAt == nil && t.Before(b.CoolingOffUntil.Time)
 }
 
 // AddBeneficiaryRequest represents a request to add a new beneficiary.
@@ -35,15 +45,62 @@ type BeneficiaryResponse struct {
 	Phone     string           `json:"phone"`
 	WalletID  string           `json:"wallet_id"`
 	CreatedAt models.Timestamp `json:"created_at"`
+
+	CoolingOffUntil *models.Timestamp `json:"cooling_off_until,omitempty"` // Set while transfers are capped
+	CoolingOffSent  int64             `json:"cooling_off_sent,omitempty"`  // Paise sent against the cap
 }
 
 // ToBeneficiaryResponse converts a Beneficiary to a BeneficiaryResponse.
 func ToBeneficiaryResponse(b *Beneficiary) *BeneficiaryResponse {
-	return &BeneficiaryResponse{
+	resp := &BeneficiaryResponse{
 		ID:        b.ID,
 		Nickname:  b.Nickname,
 		Phone:     b.BeneficiaryPhone,
 		WalletID:  b.BeneficiaryWalletID,
 		CreatedAt: b.CreatedAt,
 	}
+	if b.InCoolingOff(time.Now()) {
+		resp.CoolingOffUntil = b.CoolingOffUntil
+		resp.CoolingOffSent = b.CoolingOffSent
+	}
+	return resp
+}
+
+// CoolingOffPolicy caps what newly added beneficiaries can receive.
+type CoolingOffPolicy struct {
+	Period time.Duration // How long a new beneficiary is capped; zero disables the cooling-off period
+	Limit  int64         // Paise a beneficiary can receive in total during the period
+}
+
+// CoolingOffCheckRequest reserves a transfer's amount against the destination
+// beneficiary's cooling-off cap (internal endpoint, called by transaction).
+type CoolingOffCheckRequest struct {
+	OwnerUserID         string `json:"owner_user_id" validate:"required"`
+	DestinationWalletID string `json:"destination_wallet_id" validate:"required"`
+	Amount              int64  `json:"amount" validate:"required,gt=0"`
+}
+
+// CoolingOffOverrideRequest lifts a beneficiary's cooling-off period with a
+// verification token for the beneficiary_cooling_off_override operation.
+type CoolingOffOverrideRequest struct {
+	VerificationToken string `json:"verification_token" validate:"required"`
+}
+
+// MaxBeneficiaryImportRows bounds the rows of one beneficiary import.
+const MaxBeneficiaryImportRows = 100
+
+// BeneficiaryImportRow is the outcome of one CSV row of an import.
+type BeneficiaryImportRow struct {
+	Line          int    `json:"line"` // CSV line number
+	Phone         string `json:"phone"`
+	Nickname      string `json:"nickname"`
+	BeneficiaryID string `json:"beneficiary_id,omitempty"` // Set when the row was added
+	Error         string `json:"error,omitempty"`
+}
+
+// BeneficiaryImportResult summarizes a beneficiary import.
+type BeneficiaryImportResult struct {
+	Added  int                    `json:"added"`
+	Failed int                    `json:"failed"`
+	Rows   []BeneficiaryImportRow `json:"rows"`
 }
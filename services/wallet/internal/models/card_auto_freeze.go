@@ -0,0 +1,67 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Card authorization decline reasons.
+const (
+	DeclineCardNotUsable       = "card_not_usable"
+	DeclineWalletInactive      = "wallet_inactive"
+	DeclinePerTransactionLimit = "exceeds_per_transaction_limit"
+	DeclineDailyLimit          = "exceeds_daily_limit"
+	DeclineMonthlyLimit        = "exceeds_monthly_limit"
+	DeclineInsufficientFunds   = "insufficient_funds"
+	DeclineBelowBalanceFloor   = "below_balance_floor"
+)
+
+// Frozen reasons recorded on cards frozen by an auto-freeze rule.
+const (
+	AutoFreezeReasonBalance  = "auto-freeze: wallet balance below floor"
+	AutoFreezeReasonDeclines = "auto-freeze: repeated declined authorizations"
+)
+
+// CardAutoFreezeRule freezes a wallet's virtual cards automatically when the
+// balance would drop below a floor or a card is declined repeatedly.
+type CardAutoFreezeRule struct {
+	WalletID     string           `json:"wallet_id" db:"wallet_id"`
+	BalanceFloor *int64           `json:"balance_floor,omitempty" db:"balance_floor"` // paise
+	MaxDeclines  *int             `json:"max_declines,omitempty" db:"max_declines"`
+	Enabled      bool             `json:"enabled" db:"enabled"`
+	CreatedAt    models.Timestamp `json:"created_at" db:"created_at"`
+	UpdatedAt    models.Timestamp `json:"updated_at" db:"updated_at"`
+}
+
+// BreachesFloor reports whether a balance falls below the rule's floor.
+func (r *CardAutoFreezeRule) BreachesFloor(balance int64) bool {
+	return r != nil && r.Enabled && r.BalanceFloor != nil && balance < *r.BalanceFloor
+}
+
+// ExceedsDeclines reports whether a card's consecutive declines trip the rule.
+func (r *CardAutoFreezeRule) ExceedsDeclines(declines int) bool {
+	return r != nil && r.Enabled && r.MaxDeclines != nil && declines >= *r.MaxDeclines
+}
+
+// SetCardAutoFreezeRuleRequest represents a request to configure auto-freeze for a wallet.
+type SetCardAutoFreezeRuleRequest struct {
+	BalanceFloor *int64 `json:"balance_floor,omitempty" validate:"omitempty,gte=0"`
+	MaxDeclines  *int   `json:"max_declines,omitempty" validate:"omitempty,gt=0"`
+	Enabled      *bool  `json:"enabled,omitempty"`
+}
+
+// CardAuthorizationRequest represents an authorization request from the card network.
+type CardAuthorizationRequest struct {
+	Amount       int64  `json:"amount" validate:"required,gt=0"`
+	MerchantName string `json:"merchant_name" validate:"required,min=1,max=100"`
+	MCC          string `json:"mcc,omitempty" validate:"omitempty,len=4"`
+}
+
+// CardAuthorizationResult is the issuer's decision on a card authorization.
+type CardAuthorizationResult struct {
+	AuthorizationID string `json:"authorization_id,omitempty"` // Set when approved; used to settle or reverse
+	CardID          string `json:"card_id"`
+	Approved        bool   `json:"approved"`
+	Amount          int64  `json:"amount"`
+	DeclineReason   string `json:"decline_reason,omitempty"`
+	CardFrozen      bool   `json:"card_frozen"`
+}
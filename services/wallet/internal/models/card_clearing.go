@@ -0,0 +1,142 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// CardAuthorizationStatus represents the clearing state of a card authorization.
+type CardAuthorizationStatus string
+
+const (
+	CardAuthorizationAuthorized CardAuthorizationStatus = "authorized" // Funds held
+	CardAuthorizationSettled    CardAuthorizationStatus = "settled"    // Funds debited
+	CardAuthorizationReversed   CardAuthorizationStatus = "reversed"   // Hold released without debit
+)
+
+// CardAuthorization is an approved card authorization and its clearing state.
+type CardAuthorization struct {
+	ID             string                  `json:"id"`
+	CardID         string                  `json:"card_id"`
+	WalletID       string                  `json:"wallet_id"`
+	Amount         int64                   `json:"amount"` // Held amount in paise
+	MerchantName   string                  `json:"merchant_name"`
+	MCC            *string                 `json:"mcc,omitempty"`
+	Status         CardAuthorizationStatus `json:"status"`
+	SettledAmount  int64                   `json:"settled_amount"`
+	RefundedAmount int64                   `json:"refunded_amount"`
+	CreatedAt      models.Timestamp        `json:"created_at"`
+	SettledAt      *models.Timestamp       `json:"settled_at,omitempty"`
+	ReversedAt     *models.Timestamp       `json:"reversed_at,omitempty"`
+	UpdatedAt      models.Timestamp        `json:"updated_at"`
+}
+
+// CardRefund is a refund applied to a settled authorization, keyed by the
+// card network's refund ID so a retried refund is not credited twice.
+type CardRefund struct {
+	RefundID        string           `json:"refund_id"`
+	AuthorizationID string           `json:"authorization_id"`
+	Amount          int64            `json:"amount"`
+	CreatedAt       models.Timestamp `json:"created_at"`
+}
+
+// CardClearing is how one clearing step moves the wallet's balances.
+type CardClearing struct {
+	Balance       int64 // Change to the wallet balance, booked in the ledger
+	Available     int64 // Change to the available balance
+	ReleasedSpend int64 // Card spend-limit headroom given back
+}
+
+// HoldClearing reserves the authorized amount on the available balance.
+func (a *CardAuthorization) HoldClearing() *CardClearing {
+	return &CardClearing{Available: -a.Amount}
+}
+
+// SettleClearing debits amount (zero for the full amount) and releases the
+// rest of the hold. It returns nil if the authorization is already settled
+// for the same amount.
+func (a *CardAuthorization) SettleClearing(amount int64) (*CardClearing, *errors.Error) {
+	if amount == 0 {
+		amount = a.Amount
+	}
+
+	switch a.Status {
+	case CardAuthorizationSettled:
+		if a.SettledAmount == amount {
+			return nil, nil
+		}
+		return nil, errors.Conflict("card authorization already settled for a different amount")
+	case CardAuthorizationReversed:
+		return nil, errors.Conflict("card authorization has been reversed")
+	}
+	if amount < 0 {
+		return nil, errors.BadRequest("settlement amount must be positive")
+	}
+	if amount > a.Amount {
+		return nil, errors.BadRequest("settlement amount exceeds authorized amount")
+	}
+
+	// The hold already reduced the available balance by the full amount
+	return &CardClearing{
+		Balance:       -amount,
+		Available:     a.Amount - amount,
+		ReleasedSpend: a.Amount - amount,
+	}, nil
+}
+
+// ReverseClearing releases the whole hold. It returns nil if the
+// authorization is already reversed.
+func (a *CardAuthorization) ReverseClearing() (*CardClearing, *errors.Error) {
+	switch a.Status {
+	case CardAuthorizationReversed:
+		return nil, nil
+	case CardAuthorizationSettled:
+		return nil, errors.Conflict("settled card authorization must be refunded, not reversed")
+	}
+	return &CardClearing{Available: a.Amount, ReleasedSpend: a.Amount}, nil
+}
+
+// RefundClearing credits amount of the settled amount back. prior is the
+// refund already recorded under the same refund ID, if any: replaying it
+// returns nil, and reusing its ID for a different refund is a conflict.
+func (a *CardAuthorization) RefundClearing(amount int64, prior *CardRefund) (*CardClearing, *errors.Error) {
+	if prior != nil {
+		if prior.AuthorizationID == a.ID && prior.Amount == amount {
+			return nil, nil
+		}
+		return nil, errors.Conflict("refund ID already used for a different refund")
+	}
+
+	if a.Status != CardAuthorizationSettled {
+		return nil, errors.Conflict("only settled card authorizations can be refunded")
+	}
+	if amount <= 0 {
+		return nil, errors.BadRequest("refund amount must be positive")
+	}
+	if a.RefundedAmount+amount > a.SettledAmount {
+		return nil, errors.BadRequest("refund exceeds settled amount")
+	}
+	return &CardClearing{Balance: amount, Available: amount}, nil
+}
+
+// SettleCardAuthorizationRequest clears an authorization. Amount may be lower
+// than the authorized amount (e.g. a tip-adjusted or partial capture); zero
+// settles the full amount. Any unsettled remainder is released.
+type SettleCardAuthorizationRequest struct {
+	Amount int64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+}
+
+// RefundCardAuthorizationRequest returns part or all of a settled amount.
+// RefundID is the card network's ID for the refund; retrying with the same
+// ID and amount does not credit the wallet again.
+type RefundCardAuthorizationRequest struct {
+	RefundID string `json:"refund_id" validate:"required,max=100"`
+	Amount   int64  `json:"amount" validate:"required,gt=0"`
+}
+
+// NetworkCard is the card view exposed to the card network for generating traffic.
+type NetworkCard struct {
+	ID       string `json:"id"`
+	WalletID string `json:"wallet_id"`
+	UserID   string `json:"user_id"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// Ledger account backfill limits.
+const (
+	DefaultLedgerBackfillLimit = 100  // Wallets repaired per run when no limit is given
+	MaxLedgerBackfillLimit     = 1000 // Most wallets one run may repair
+)
+
+// LedgerBackfillRequest asks for wallets whose ledger account is missing to
+// be given one.
+type LedgerBackfillRequest struct {
+	Limit  int  // Most wallets to repair; DefaultLedgerBackfillLimit if zero
+	DryRun bool // Report the wallets that would be repaired without changing them
+}
+
+// LedgerBackfillItem is a wallet the backfill found missing its ledger account.
+type LedgerBackfillItem struct {
+	WalletID           string `json:"wallet_id"`
+	UserID             string `json:"user_id"`
+	Currency           string `json:"currency"`
+	MissingAccountID   string `json:"missing_account_id"`             // Account the wallet pointed at
+	LedgerAccountID    string `json:"ledger_account_id,omitempty"`    // Account it points at now
+	LedgerAccountReuse bool   `json:"ledger_account_reuse,omitempty"` // An existing account with the wallet's code was linked
+	Error              string `json:"error,omitempty"`                // Why the wallet could not be repaired
+}
+
+// LedgerBackfillResult summarizes a ledger account backfill run.
+type LedgerBackfillResult struct {
+	DryRun   bool                 `json:"dry_run"`
+	Checked  int                  `json:"checked"`  // Wallets whose account was looked up
+	Missing  int                  `json:"missing"`  // Wallets whose account does not exist
+	Repaired int                  `json:"repaired"` // Wallets linked to a new or existing account
+	Failed   int                  `json:"failed"`
+	Complete bool                 `json:"complete"` // Every wallet was checked; false when the limit stopped the run
+	Items    []LedgerBackfillItem `json:"items"`
+}
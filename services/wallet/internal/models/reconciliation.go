@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// MaxReconciliationMismatches caps the mismatches listed in one report, so a
+// badly drifted system still produces a report of bounded size.
+const MaxReconciliationMismatches = 500
+
+// BalanceMismatch is an active wallet whose balance differs from the balance
+// of its ledger account.
+type BalanceMismatch struct {
+	WalletID        string `json:"wallet_id"`
+	LedgerAccountID string `json:"ledger_account_id"`
+	WalletBalance   int64  `json:"wallet_balance"`
+	LedgerBalance   int64  `json:"ledger_balance"`
+	Difference      int64  `json:"difference"` // Wallet balance minus ledger balance
+}
+
+// ReconciliationReport is the result of comparing every active wallet's
+// balance with its ledger account.
+type ReconciliationReport struct {
+	StartedAt   models.Timestamp  `json:"started_at"`
+	CompletedAt models.Timestamp  `json:"completed_at"`
+	Checked     int               `json:"checked"`   // Wallets compared with their ledger account
+	Failed      int               `json:"failed"`    // Wallets whose ledger account could not be read or is missing
+	Healthy     bool              `json:"healthy"`   // No mismatches and no failures
+	Truncated   bool              `json:"truncated"` // More than MaxReconciliationMismatches were found
+	Mismatches  []BalanceMismatch `json:"mismatches"`
+}
+
+// BalanceSkewRequest asks for an active wallet's balance to be skewed away
+// from its ledger account, so a simulation can check that reconciliation
+// notices. Only available outside production.
+type BalanceSkewRequest struct {
+	Amount int64 `json:"amount" validate:"required,gt=0"`
+}
+
+// BalanceSkew is a wallet balance raised by a simulation without a matching
+// ledger posting.
+type BalanceSkew struct {
+	WalletID string `json:"wallet_id" validate:"required,uuid"`
+	Amount   int64  `json:"amount" validate:"required,gt=0"`
+}
@@ -40,7 +40,7 @@ func (d *UPIDeposit) IsCompleted() bool {
 
 // InitiateUPIDepositRequest represents a request to initiate a UPI deposit.
 type InitiateUPIDepositRequest struct {
-	Amount int64 `json:"amount" validate:"required,gt=0,lte=10000000"` // Max 1 lakh rupees (in paise)
+	Amount int64 `json:"amount" validate:"required,gt=0"` // In paise; bounded by the upi_deposit amount policy
 }
 
 // UPIDepositResponse represents the response for a UPI deposit initiation.
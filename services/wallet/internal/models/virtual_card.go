@@ -46,6 +46,8 @@ type VirtualCard struct {
 	FrozenReason        *string           `json:"frozen_reason,omitempty" db:"frozen_reason"`
 	CancelledAt         *models.Timestamp `json:"cancelled_at,omitempty" db:"cancelled_at"`
 	CancelledReason     *string           `json:"cancelled_reason,omitempty" db:"cancelled_reason"`
+	ConsecutiveDeclines int               `json:"consecutive_declines" db:"consecutive_declines"`
+	AutoFrozen          bool              `json:"auto_frozen" db:"auto_frozen"`
 	CreatedAt           models.Timestamp  `json:"created_at" db:"created_at"`
 	UpdatedAt           models.Timestamp  `json:"updated_at" db:"updated_at"`
 }
@@ -107,6 +109,8 @@ type VirtualCardResponse struct {
 	DailySpent          int64             `json:"daily_spent"`
 	MonthlySpent        int64             `json:"monthly_spent"`
 	LastUsedAt          *models.Timestamp `json:"last_used_at,omitempty"`
+	FrozenReason        *string           `json:"frozen_reason,omitempty"`
+	AutoFrozen          bool              `json:"auto_frozen"`
 	CreatedAt           models.Timestamp  `json:"created_at"`
 }
 
@@ -128,6 +132,8 @@ func (c *VirtualCard) ToResponse() *VirtualCardResponse {
 		DailySpent:          c.DailySpent,
 		MonthlySpent:        c.MonthlySpent,
 		LastUsedAt:          c.LastUsedAt,
+		FrozenReason:        c.FrozenReason,
+		AutoFrozen:          c.AutoFrozen,
 		CreatedAt:           c.CreatedAt,
 	}
 }
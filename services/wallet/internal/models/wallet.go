@@ -2,8 +2,15 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+	"github.com/1mb-dev/nivomoney/shared/validator"
 )
 
 // WalletType represents the type of wallet.
@@ -21,6 +28,10 @@ const (
 	WalletStatusFrozen   WalletStatus = "frozen"   // Wallet is frozen (compliance/security)
 	WalletStatusClosed   WalletStatus = "closed"   // Wallet is permanently closed
 	WalletStatusInactive WalletStatus = "inactive" // Wallet is inactive (KYC pending, etc.)
+
+	// WalletStatusPendingClosure wallets are being closed: holds settle and
+	// the balance is swept out, then the wallet closes.
+	WalletStatusPendingClosure WalletStatus = "pending_closure"
 )
 
 // Wallet represents a user's wallet in the neobank.
@@ -33,6 +44,10 @@ type Wallet struct {
 	AvailableBalance int64             `json:"available_balance" db:"available_balance"` // Balance minus holds/freezes
 	Status           WalletStatus      `json:"status" db:"status"`
 	LedgerAccountID  string            `json:"ledger_account_id" db:"ledger_account_id"` // Link to Ledger Service account
+	Nickname         *string           `json:"nickname,omitempty" db:"nickname"`         // User-chosen name, shared by co-owners
+	Color            *string           `json:"color,omitempty" db:"color"`               // Display color as #RRGGBB
+	Tags             []string          `json:"tags" db:"tags"`                           // Lowercase labels for filtering
+	IsDefault        bool              `json:"is_default" db:"is_default"`               // The primary owner's preferred wallet
 	Metadata         map[string]string `json:"metadata,omitempty" db:"metadata"`         // JSONB metadata
 	CreatedAt        models.Timestamp  `json:"created_at" db:"created_at"`
 	UpdatedAt        models.Timestamp  `json:"updated_at" db:"updated_at"`
@@ -45,6 +60,16 @@ func (w *Wallet) IsActive() bool {
 	return w.Status == WalletStatusActive
 }
 
+// BalanceMoney returns the wallet balance in the wallet currency.
+func (w *Wallet) BalanceMoney() money.Money {
+	return money.New(w.Balance, w.Currency)
+}
+
+// AvailableMoney returns the available balance in the wallet currency.
+func (w *Wallet) AvailableMoney() money.Money {
+	return money.New(w.AvailableBalance, w.Currency)
+}
+
 // CanTransact returns true if the wallet can be used for transactions.
 func (w *Wallet) CanTransact() bool {
 	return w.Status == WalletStatusActive && w.AvailableBalance > 0
@@ -72,6 +97,142 @@ func (r *CreateWalletRequest) GetMetadata() (map[string]string, error) {
 	return metadata, nil
 }
 
+// Wallet profile limits.
+const (
+	MaxWalletNicknameLength = 50
+	MaxWalletTags           = 10
+	MaxWalletTagLength      = 30
+	MaxWalletMetadataKeys   = 20
+	MaxWalletMetadataLength = 200 // Per key and per value
+)
+
+// WalletMetadataUPIID is the metadata key holding the wallet's UPI VPA.
+const WalletMetadataUPIID = "upi_id"
+
+// ValidateWalletMetadata checks well-known metadata keys. A UPI ID, when
+// set, must be a VPA handle like name@bank.
+func ValidateWalletMetadata(metadata map[string]string) error {
+	if upiID, ok := metadata[WalletMetadataUPIID]; ok && upiID != "" && !validator.IsValidUPIID(upiID) {
+		return fmt.Errorf("metadata %s must be a UPI ID like name@bank", WalletMetadataUPIID)
+	}
+	return nil
+}
+
+var (
+	walletColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	walletTagPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// UpdateWalletRequest represents a partial update of a wallet's user-managed
+// profile. Omitted fields are left unchanged; an empty nickname or color
+// clears it, and tags and metadata replace the current values.
+type UpdateWalletRequest struct {
+	Nickname  *string            `json:"nickname,omitempty"`
+	Color     *string            `json:"color,omitempty"`
+	Tags      *[]string          `json:"tags,omitempty"`
+	IsDefault *bool              `json:"is_default,omitempty"`
+	Metadata  *map[string]string `json:"metadata,omitempty"`
+}
+
+// IsEmpty reports whether the request changes nothing.
+func (r *UpdateWalletRequest) IsEmpty() bool {
+	return r.Nickname == nil && r.Color == nil && r.Tags == nil && r.IsDefault == nil && r.Metadata == nil
+}
+
+// Normalize trims the nickname, uppercases the color and lowercases and
+// de-duplicates tags, then validates the result.
+func (r *UpdateWalletRequest) Normalize() error {
+	if r.Nickname != nil {
+		nickname := strings.TrimSpace(*r.Nickname)
+		if utf8.RuneCountInString(nickname) > MaxWalletNicknameLength {
+			return fmt.Errorf("nickname must be at most %d characters", MaxWalletNicknameLength)
+		}
+		r.Nickname = &nickname
+	}
+
+	if r.Color != nil {
+		color := strings.ToUpper(strings.TrimSpace(*r.Color))
+		if color != "" && !walletColorPattern.MatchString(color) {
+			return fmt.Errorf("color must be a hex color like #1A73E8")
+		}
+		r.Color = &color
+	}
+
+	if r.Tags != nil {
+		tags, err := NormalizeWalletTags(*r.Tags)
+		if err != nil {
+			return err
+		}
+		r.Tags = &tags
+	}
+
+	if r.Metadata != nil {
+		if len(*r.Metadata) > MaxWalletMetadataKeys {
+			return fmt.Errorf("metadata can have at most %d keys", MaxWalletMetadataKeys)
+		}
+		for key, value := range *r.Metadata {
+			if key == "" || len(key) > MaxWalletMetadataLength || len(value) > MaxWalletMetadataLength {
+				return fmt.Errorf("metadata keys must be non-empty and keys and values at most %d bytes", MaxWalletMetadataLength)
+			}
+		}
+		if err := ValidateWalletMetadata(*r.Metadata); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NormalizeWalletTag lowercases and trims a tag and checks it is made of
+// letters, digits, hyphens and underscores.
+func NormalizeWalletTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if len(normalized) > MaxWalletTagLength || !walletTagPattern.MatchString(normalized) {
+		return "", fmt.Errorf("tag %q must be 1-%d lowercase letters, digits, hyphens or underscores", tag, MaxWalletTagLength)
+	}
+	return normalized, nil
+}
+
+// NormalizeWalletTags normalizes tags, dropping duplicates and keeping the
+// given order.
+func NormalizeWalletTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		t, err := NormalizeWalletTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[t] {
+			seen[t] = true
+			normalized = append(normalized, t)
+		}
+	}
+	if len(normalized) > MaxWalletTags {
+		return nil, fmt.Errorf("a wallet can have at most %d tags", MaxWalletTags)
+	}
+	return normalized, nil
+}
+
+// HasTag reports whether the wallet is labelled with tag.
+func (w *Wallet) HasTag(tag string) bool {
+	for _, t := range w.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayName returns the wallet's nickname, or an empty string if it has
+// none.
+func (w *Wallet) DisplayName() string {
+	if w.Nickname == nil {
+		return ""
+	}
+	return *w.Nickname
+}
+
 // UpdateWalletStatusRequest represents a request to update wallet status.
 type UpdateWalletStatusRequest struct {
 	Status WalletStatus `json:"status" validate:"required"`
@@ -88,6 +249,31 @@ type CloseWalletRequest struct {
 	Reason string `json:"reason" validate:"required,min:10,max:500"`
 }
 
+// Wallet listing sort fields.
+const (
+	WalletSortCreatedAt = "created_at"
+	WalletSortUpdatedAt = "updated_at"
+	WalletSortBalance   = "balance"
+)
+
+// WalletFilter holds the filters, sort order and page of an admin wallet
+// listing. Nil filters are not applied.
+type WalletFilter struct {
+	UserID        *string
+	Status        *WalletStatus
+	Type          *WalletType
+	Currency      *models.Currency
+	MinBalance    *int64
+	MaxBalance    *int64
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Tag           *string // Normalized tag the wallet must carry
+	SortBy        string  // One of the WalletSort* fields; defaults to created_at
+	SortAsc       bool    // Newest/largest first unless set
+	Limit         int
+	Offset        int
+}
+
 // WalletBalance represents a wallet's balance information.
 type WalletBalance struct {
 	WalletID         string `json:"wallet_id"`
@@ -133,6 +319,52 @@ func (wl *WalletLimits) CanTransfer(amount int64) bool {
 	return amount <= wl.DailyRemaining() && amount <= wl.MonthlyRemaining()
 }
 
+// Roll starts a new daily or monthly window for each window that has ended by
+// now, clearing its spend. Windows end at midnight and at the start of the
+// month in loc. It reports whether anything changed.
+func (wl *WalletLimits) Roll(now time.Time, loc *time.Location) bool {
+	rolled := false
+	if !now.Before(wl.DailyResetAt.Time) {
+		wl.DailySpent = 0
+		wl.DailyResetAt = models.NewTimestamp(NextDailyReset(now, loc))
+		rolled = true
+	}
+	if !now.Before(wl.MonthlyResetAt.Time) {
+		wl.MonthlySpent = 0
+		wl.MonthlyResetAt = models.NewTimestamp(NextMonthlyReset(now, loc))
+		rolled = true
+	}
+	return rolled
+}
+
+// NextDailyReset returns the next midnight after now in loc.
+func NextDailyReset(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	// time.Date normalises day overflow and handles DST-shortened days
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+}
+
+// NextMonthlyReset returns the start of the next month after now in loc.
+func NextMonthlyReset(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	return time.Date(local.Year(), local.Month()+1, 1, 0, 0, 0, 0, loc)
+}
+
+// RemainingLimits is a client view of how much a wallet can still transfer
+// in the current windows.
+type RemainingLimits struct {
+	WalletID         string           `json:"wallet_id"`
+	DailyLimit       int64            `json:"daily_limit"`
+	DailySpent       int64            `json:"daily_spent"`
+	DailyRemaining   int64            `json:"daily_remaining"`
+	DailyResetAt     models.Timestamp `json:"daily_reset_at"`
+	MonthlyLimit     int64            `json:"monthly_limit"`
+	MonthlySpent     int64            `json:"monthly_spent"`
+	MonthlyRemaining int64            `json:"monthly_remaining"`
+	MonthlyResetAt   models.Timestamp `json:"monthly_reset_at"`
+	Timezone         string           `json:"timezone"` // Windows roll at midnight here
+}
+
 // UpdateLimitsRequest represents a request to update wallet transfer limits.
 // Note: Authentication is handled via JWT - no additional password required.
 type UpdateLimitsRequest struct {
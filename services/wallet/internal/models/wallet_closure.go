@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// WalletClosureStatus is the state of a wallet closure request.
+type WalletClosureStatus string
+
+const (
+	WalletClosureStatusPending   WalletClosureStatus = "pending"   // Waiting for holds to settle and the balance to be swept
+	WalletClosureStatusClosed    WalletClosureStatus = "closed"    // Wallet closed; may be reopened until ReopenUntil
+	WalletClosureStatusCancelled WalletClosureStatus = "cancelled" // Cancelled before the wallet closed
+	WalletClosureStatusReopened  WalletClosureStatus = "reopened"  // Wallet reopened within the grace period
+)
+
+// SweepMethod is where a closing wallet's remaining balance goes.
+type SweepMethod string
+
+const (
+	SweepMethodWallet     SweepMethod = "wallet"     // Transfer to another wallet the owner controls
+	SweepMethodWithdrawal SweepMethod = "withdrawal" // Withdraw to the owner's bank account
+)
+
+// IsValid reports whether m is a known sweep method.
+func (m SweepMethod) IsValid() bool {
+	return m == SweepMethodWallet || m == SweepMethodWithdrawal
+}
+
+// WalletClosure is a scheduled closure of a wallet. While pending, the wallet
+// is in pending_closure: it accepts no new payments, outstanding card holds
+// settle or expire, and the remaining balance is swept out before it closes.
+type WalletClosure struct {
+	ID             string              `json:"id" db:"id"`
+	WalletID       string              `json:"wallet_id" db:"wallet_id"`
+	RequestedBy    string              `json:"requested_by" db:"requested_by"`
+	Status         WalletClosureStatus `json:"status" db:"status"`
+	Reason         string              `json:"reason" db:"reason"`
+	SweepMethod    *SweepMethod        `json:"sweep_method,omitempty" db:"sweep_method"`
+	SweepWalletID  *string             `json:"sweep_wallet_id,omitempty" db:"sweep_wallet_id"`
+	PreviousStatus WalletStatus        `json:"previous_status" db:"previous_status"` // Restored on cancel or reopen
+	ScheduledFor   models.Timestamp    `json:"scheduled_for" db:"scheduled_for"`
+	SweptAmount    int64               `json:"swept_amount" db:"swept_amount"`       // Total moved out by sweeps
+	LastError      *string             `json:"last_error,omitempty" db:"last_error"` // Why the last attempt could not finish
+	ClosedAt       *models.Timestamp   `json:"closed_at,omitempty" db:"closed_at"`
+	ReopenUntil    *models.Timestamp   `json:"reopen_until,omitempty" db:"reopen_until"`
+	CreatedAt      models.Timestamp    `json:"created_at" db:"created_at"`
+	UpdatedAt      models.Timestamp    `json:"updated_at" db:"updated_at"`
+}
+
+// CanReopen reports whether the wallet can still be reopened at now.
+func (c *WalletClosure) CanReopen(now time.Time) bool {
+	return c.Status == WalletClosureStatusClosed && c.ReopenUntil != nil && now.Before(c.ReopenUntil.Time)
+}
+
+// ScheduleClosureRequest represents a request to close a wallet. A wallet with
+// a balance needs a sweep method; close_at defaults to now.
+type ScheduleClosureRequest struct {
+	Reason        string      `json:"reason" validate:"required,min:10,max:500"`
+	SweepMethod   SweepMethod `json:"sweep_method,omitempty"`
+	SweepWalletID string      `json:"sweep_wallet_id,omitempty" validate:"omitempty,uuid"`
+	CloseAt       *time.Time  `json:"close_at,omitempty"`
+}
+
+// ExecuteClosureSweepRequest moves a closing wallet's balance out. It is sent
+// by the transaction service, which records the sweep as a transfer to
+// DestinationWalletID or, without one, as a withdrawal.
+type ExecuteClosureSweepRequest struct {
+	ClosureID           string `json:"closure_id" validate:"required,uuid"`
+	WalletID            string `json:"wallet_id" validate:"required,uuid"`
+	DestinationWalletID string `json:"destination_wallet_id,omitempty" validate:"omitempty,uuid"`
+	Amount              int64  `json:"amount" validate:"required,gt=0"`
+	TransactionID       string `json:"transaction_id" validate:"required,uuid"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// WalletPermission is what a co-owner may do with a joint wallet.
+type WalletPermission string
+
+const (
+	WalletPermissionView     WalletPermission = "view"     // See the wallet, its balance and transactions
+	WalletPermissionTransact WalletPermission = "transact" // Also move money out of the wallet
+	WalletPermissionAdmin    WalletPermission = "admin"    // Also change limits and manage co-owners
+)
+
+// walletPermissionRank orders permissions; each includes the ones below it.
+var walletPermissionRank = map[WalletPermission]int{
+	WalletPermissionView:     1,
+	WalletPermissionTransact: 2,
+	WalletPermissionAdmin:    3,
+}
+
+// IsValid reports whether p is a known permission.
+func (p WalletPermission) IsValid() bool {
+	_, ok := walletPermissionRank[p]
+	return ok
+}
+
+// Allows reports whether p includes required.
+func (p WalletPermission) Allows(required WalletPermission) bool {
+	return p.IsValid() && walletPermissionRank[p] >= walletPermissionRank[required]
+}
+
+// WalletMemberStatus is the state of a co-owner's membership.
+type WalletMemberStatus string
+
+const (
+	WalletMemberStatusInvited  WalletMemberStatus = "invited"  // Waiting for the invitee to respond
+	WalletMemberStatusActive   WalletMemberStatus = "active"   // Invite accepted
+	WalletMemberStatusDeclined WalletMemberStatus = "declined" // Invite declined
+	WalletMemberStatusRemoved  WalletMemberStatus = "removed"  // Removed by an admin, or left
+)
+
+// WalletMember is a co-owner of a joint wallet. The wallet's primary owner
+// (Wallet.UserID) is not a member row and always has admin permission.
+type WalletMember struct {
+	ID          string             `json:"id" db:"id"`
+	WalletID    string             `json:"wallet_id" db:"wallet_id"`
+	UserID      string             `json:"user_id" db:"user_id"`
+	Permission  WalletPermission   `json:"permission" db:"permission"`
+	Status      WalletMemberStatus `json:"status" db:"status"`
+	InvitedBy   string             `json:"invited_by" db:"invited_by"`
+	InvitedAt   models.Timestamp   `json:"invited_at" db:"invited_at"`
+	RespondedAt *models.Timestamp  `json:"responded_at,omitempty" db:"responded_at"`
+	UpdatedAt   models.Timestamp   `json:"updated_at" db:"updated_at"`
+}
+
+// IsActive returns true if the member has accepted the invite and not been removed.
+func (m *WalletMember) IsActive() bool {
+	return m.Status == WalletMemberStatusActive
+}
+
+// WalletOwner is an owner of a wallet as listed to its owners.
+type WalletOwner struct {
+	UserID     string             `json:"user_id"`
+	Permission WalletPermission   `json:"permission"`
+	Status     WalletMemberStatus `json:"status"`
+	Primary    bool               `json:"primary"` // The wallet's creator; cannot be removed
+}
+
+// InviteCoOwnerRequest represents a request to invite a co-owner to a wallet.
+type InviteCoOwnerRequest struct {
+	Phone      string           `json:"phone" validate:"required,e164"` // Invitee's phone number (e.g., "+919876543210")
+	Permission WalletPermission `json:"permission" validate:"required"`
+}
+
+// UpdateCoOwnerRequest represents a request to change a co-owner's permission.
+type UpdateCoOwnerRequest struct {
+	Permission WalletPermission `json:"permission" validate:"required"`
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// BalanceSkewRepository skews and restores wallet balances for simulation
+// anomalies. It bypasses the ledger on purpose and must never be wired up in
+// production.
+type BalanceSkewRepository struct {
+	db *sql.DB
+}
+
+// NewBalanceSkewRepository creates a new balance skew repository.
+func NewBalanceSkewRepository(db *sql.DB) *BalanceSkewRepository {
+	return &BalanceSkewRepository{db: db}
+}
+
+// Skew raises an active wallet's balance by amount without a ledger posting.
+func (r *BalanceSkewRepository) Skew(ctx context.Context, walletID string, amount int64) *errors.Error {
+	query := `
+		UPDATE wallets
+		SET balance = balance + $2,
+		    available_balance = available_balance + $2,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'active'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, amount)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to skew wallet balance")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to skew wallet balance")
+	}
+	if rows == 0 {
+		return errors.NotFoundWithID("active wallet", walletID)
+	}
+
+	return nil
+}
+
+// Restore takes a skew back off a wallet's balance. Returns false when the
+// balance no longer covers it, such as when the wallet has since spent it.
+func (r *BalanceSkewRepository) Restore(ctx context.Context, walletID string, amount int64) (bool, *errors.Error) {
+	query := `
+		UPDATE wallets
+		SET balance = balance - $2,
+		    available_balance = GREATEST(available_balance - $2, 0),
+		    updated_at = NOW()
+		WHERE id = $1 AND balance >= $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, amount)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to restore wallet balance")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to restore wallet balance")
+	}
+	return rows > 0, nil
+}
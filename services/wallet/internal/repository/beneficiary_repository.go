@@ -34,9 +34,9 @@ func (r *BeneficiaryRepository) Create(ctx context.Context, beneficiary *models.
 	query := `
 		INSERT INTO beneficiaries (
 			owner_user_id, beneficiary_user_id, beneficiary_wallet_id,
-			nickname, beneficiary_phone, metadata
+			nickname, beneficiary_phone, metadata, cooling_off_until
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
@@ -47,6 +47,7 @@ func (r *BeneficiaryRepository) Create(ctx context.Context, beneficiary *models.
 		beneficiary.Nickname,
 		beneficiary.BeneficiaryPhone,
 		metadataJSON,
+		beneficiary.CoolingOffUntil,
 	).Scan(&beneficiary.ID, &beneficiary.CreatedAt, &beneficiary.UpdatedAt)
 
 	if err != nil {
@@ -70,7 +71,8 @@ func (r *BeneficiaryRepository) GetByID(ctx context.Context, id, ownerUserID str
 
 	query := `
 		SELECT id, owner_user_id, beneficiary_user_id, beneficiary_wallet_id,
-		       nickname, beneficiary_phone, metadata, created_at, updated_at
+		       nickname, beneficiary_phone, metadata, cooling_off_until,
+		       cooling_off_sent, cooling_off_overridden_at, created_at, updated_at
 		FROM beneficiaries
 		WHERE id = $1 AND owner_user_id = $2
 	`
@@ -83,6 +85,9 @@ func (r *BeneficiaryRepository) GetByID(ctx context.Context, id, ownerUserID str
 		&beneficiary.Nickname,
 		&beneficiary.BeneficiaryPhone,
 		&metadataJSON,
+		&beneficiary.CoolingOffUntil,
+		&beneficiary.CoolingOffSent,
+		&beneficiary.CoolingOffOverriddenAt,
 		&beneficiary.CreatedAt,
 		&beneficiary.UpdatedAt,
 	)
@@ -108,7 +113,8 @@ func (r *BeneficiaryRepository) GetByID(ctx context.Context, id, ownerUserID str
 func (r *BeneficiaryRepository) ListByOwner(ctx context.Context, ownerUserID string) ([]*models.Beneficiary, *errors.Error) {
 	query := `
 		SELECT id, owner_user_id, beneficiary_user_id, beneficiary_wallet_id,
-		       nickname, beneficiary_phone, metadata, created_at, updated_at
+		       nickname, beneficiary_phone, metadata, cooling_off_until,
+		       cooling_off_sent, cooling_off_overridden_at, created_at, updated_at
 		FROM beneficiaries
 		WHERE owner_user_id = $1
 		ORDER BY nickname ASC
@@ -133,6 +139,9 @@ func (r *BeneficiaryRepository) ListByOwner(ctx context.Context, ownerUserID str
 			&beneficiary.Nickname,
 			&beneficiary.BeneficiaryPhone,
 			&metadataJSON,
+			&beneficiary.CoolingOffUntil,
+			&beneficiary.CoolingOffSent,
+			&beneficiary.CoolingOffOverriddenAt,
 			&beneficiary.CreatedAt,
 			&beneficiary.UpdatedAt,
 		)
@@ -210,7 +219,8 @@ func (r *BeneficiaryRepository) GetByBeneficiaryUser(ctx context.Context, ownerU
 
 	query := `
 		SELECT id, owner_user_id, beneficiary_user_id, beneficiary_wallet_id,
-		       nickname, beneficiary_phone, metadata, created_at, updated_at
+		       nickname, beneficiary_phone, metadata, cooling_off_until,
+		       cooling_off_sent, cooling_off_overridden_at, created_at, updated_at
 		FROM beneficiaries
 		WHERE owner_user_id = $1 AND beneficiary_user_id = $2
 	`
@@ -223,6 +233,9 @@ func (r *BeneficiaryRepository) GetByBeneficiaryUser(ctx context.Context, ownerU
 		&beneficiary.Nickname,
 		&beneficiary.BeneficiaryPhone,
 		&metadataJSON,
+		&beneficiary.CoolingOffUntil,
+		&beneficiary.CoolingOffSent,
+		&beneficiary.CoolingOffOverriddenAt,
 		&beneficiary.CreatedAt,
 		&beneficiary.UpdatedAt,
 	)
@@ -244,6 +257,117 @@ func (r *BeneficiaryRepository) GetByBeneficiaryUser(ctx context.Context, ownerU
 	return beneficiary, nil
 }
 
+// GetByWallet retrieves the owner's beneficiary whose default wallet is walletID.
+func (r *BeneficiaryRepository) GetByWallet(ctx context.Context, ownerUserID, walletID string) (*models.Beneficiary, *errors.Error) {
+	beneficiary := &models.Beneficiary{}
+	var metadataJSON []byte
+
+	query := `
+		SELECT id, owner_user_id, beneficiary_user_id, beneficiary_wallet_id,
+		       nickname, beneficiary_phone, metadata, cooling_off_until,
+		       cooling_off_sent, cooling_off_overridden_at, created_at, updated_at
+		FROM beneficiaries
+		WHERE owner_user_id = $1 AND beneficiary_wallet_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, ownerUserID, walletID).Scan(
+		&beneficiary.ID,
+		&beneficiary.OwnerUserID,
+		&beneficiary.BeneficiaryUserID,
+		&beneficiary.BeneficiaryWalletID,
+		&beneficiary.Nickname,
+		&beneficiary.BeneficiaryPhone,
+		&metadataJSON,
+		&beneficiary.CoolingOffUntil,
+		&beneficiary.CoolingOffSent,
+		&beneficiary.CoolingOffOverriddenAt,
+		&beneficiary.CreatedAt,
+		&beneficiary.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("beneficiary not found")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get beneficiary")
+	}
+
+	// Deserialize metadata
+	if len(metadataJSON) > 0 {
+		if err := json.Unmarshal(metadataJSON, &beneficiary.Metadata); err != nil {
+			return nil, errors.Internal("failed to parse metadata")
+		}
+	}
+
+	return beneficiary, nil
+}
+
+// ReserveCoolingOff adds amount to what the beneficiary has received during
+// its cooling-off period, unless that would exceed limit. It returns the new
+// total and false when the amount does not fit.
+func (r *BeneficiaryRepository) ReserveCoolingOff(ctx context.Context, id string, amount, limit int64) (int64, bool, *errors.Error) {
+	query := `
+		UPDATE beneficiaries
+		SET cooling_off_sent = cooling_off_sent + $2
+		WHERE id = $1 AND cooling_off_sent + $2 <= $3
+		RETURNING cooling_off_sent
+	`
+
+	var sent int64
+	err := r.db.QueryRowContext(ctx, query, id, amount, limit).Scan(&sent)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, errors.DatabaseWrap(err, "failed to reserve cooling-off amount")
+	}
+
+	return sent, true, nil
+}
+
+// ReleaseCoolingOff gives back amount reserved for a transfer that failed.
+func (r *BeneficiaryRepository) ReleaseCoolingOff(ctx context.Context, id string, amount int64) *errors.Error {
+	query := `
+		UPDATE beneficiaries
+		SET cooling_off_sent = GREATEST(cooling_off_sent - $2, 0)
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, amount); err != nil {
+		return errors.DatabaseWrap(err, "failed to release cooling-off amount")
+	}
+
+	return nil
+}
+
+// OverrideCoolingOff lifts a beneficiary's cooling-off period. Each
+// verification can lift one cooling-off period only.
+func (r *BeneficiaryRepository) OverrideCoolingOff(ctx context.Context, id, ownerUserID, verificationID string) *errors.Error {
+	query := `
+		UPDATE beneficiaries
+		SET cooling_off_overridden_at = NOW(), cooling_off_verification_id = $3, updated_at = NOW()
+		WHERE id = $1 AND owner_user_id = $2
+		RETURNING id
+	`
+
+	var beneficiaryID string
+	err := r.db.QueryRowContext(ctx, query, id, ownerUserID, verificationID).Scan(&beneficiaryID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.NotFoundWithID("beneficiary", id)
+		}
+		if isUniqueViolation(err) {
+			return errors.Conflict("verification token was already used")
+		}
+		return errors.DatabaseWrap(err, "failed to override cooling-off period")
+	}
+
+	return nil
+}
+
 // isDuplicateNickname checks if the error is a duplicate nickname violation.
 func isDuplicateNickname(err error) bool {
 	// This is a simplified check; in production, use pq.Error to check constraint name
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// CardAutoFreezeRepository handles database operations for card auto-freeze rules.
+type CardAutoFreezeRepository struct {
+	db *sql.DB
+}
+
+// NewCardAutoFreezeRepository creates a new card auto-freeze rule repository.
+func NewCardAutoFreezeRepository(db *sql.DB) *CardAutoFreezeRepository {
+	return &CardAutoFreezeRepository{db: db}
+}
+
+// GetByWallet retrieves the auto-freeze rule for a wallet.
+func (r *CardAutoFreezeRepository) GetByWallet(ctx context.Context, walletID string) (*models.CardAutoFreezeRule, *errors.Error) {
+	rule := &models.CardAutoFreezeRule{}
+
+	query := `
+		SELECT wallet_id, balance_floor, max_declines, enabled, created_at, updated_at
+		FROM card_auto_freeze_rules
+		WHERE wallet_id = $1
+	`
+
+	err := r.db.QueryRowContext(ctx, query, walletID).Scan(
+		&rule.WalletID,
+		&rule.BalanceFloor,
+		&rule.MaxDeclines,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("card auto-freeze rule")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get card auto-freeze rule")
+	}
+
+	return rule, nil
+}
+
+// Upsert creates or replaces the auto-freeze rule for a wallet.
+func (r *CardAutoFreezeRepository) Upsert(ctx context.Context, rule *models.CardAutoFreezeRule) *errors.Error {
+	query := `
+		INSERT INTO card_auto_freeze_rules (wallet_id, balance_floor, max_declines, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (wallet_id) DO UPDATE
+		SET balance_floor = EXCLUDED.balance_floor,
+		    max_declines = EXCLUDED.max_declines,
+		    enabled = EXCLUDED.enabled,
+		    updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		rule.WalletID,
+		rule.BalanceFloor,
+		rule.MaxDeclines,
+		rule.Enabled,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to save card auto-freeze rule")
+	}
+
+	return nil
+}
+
+// Delete removes the auto-freeze rule for a wallet.
+func (r *CardAutoFreezeRepository) Delete(ctx context.Context, walletID string) *errors.Error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM card_auto_freeze_rules WHERE wallet_id = $1`, walletID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete card auto-freeze rule")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to delete card auto-freeze rule")
+	}
+	if rows == 0 {
+		return errors.NotFound("card auto-freeze rule")
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// CardClearingRepository handles card authorization holds and their clearing.
+// Every balance change happens in the same transaction as the state change
+// on the authorization, with the authorization row locked first.
+type CardClearingRepository struct {
+	db *sql.DB
+}
+
+// NewCardClearingRepository creates a new card clearing repository.
+func NewCardClearingRepository(db *sql.DB) *CardClearingRepository {
+	return &CardClearingRepository{db: db}
+}
+
+const cardAuthorizationColumns = `
+	id, card_id, wallet_id, amount, merchant_name, mcc, status,
+	settled_amount, refunded_amount, created_at, settled_at, reversed_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanCardAuthorization(row rowScanner) (*models.CardAuthorization, error) {
+	auth := &models.CardAuthorization{}
+	err := row.Scan(
+		&auth.ID,
+		&auth.CardID,
+		&auth.WalletID,
+		&auth.Amount,
+		&auth.MerchantName,
+		&auth.MCC,
+		&auth.Status,
+		&auth.SettledAmount,
+		&auth.RefundedAmount,
+		&auth.CreatedAt,
+		&auth.SettledAt,
+		&auth.ReversedAt,
+		&auth.UpdatedAt,
+	)
+	return auth, err
+}
+
+// CreateHold reserves amount on the wallet's available balance and records the
+// authorization. It returns false without error if available funds are short,
+// which can happen when another authorization wins a race for the balance.
+func (r *CardClearingRepository) CreateHold(ctx context.Context, auth *models.CardAuthorization) (bool, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	hold := auth.HoldClearing()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET available_balance = available_balance + $1, updated_at = NOW()
+		WHERE id = $2 AND available_balance + $1 >= 0
+	`, hold.Available, auth.WalletID)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to place card hold")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return false, nil
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		INSERT INTO card_authorizations (card_id, wallet_id, amount, merchant_name, mcc)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+cardAuthorizationColumns,
+		auth.CardID, auth.WalletID, auth.Amount, auth.MerchantName, auth.MCC,
+	)
+	created, err := scanCardAuthorization(row)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to record card authorization")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, errors.DatabaseWrap(err, "failed to commit card hold")
+	}
+
+	*auth = *created
+	return true, nil
+}
+
+// GetByID retrieves a card authorization.
+func (r *CardClearingRepository) GetByID(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error) {
+	auth, err := scanCardAuthorization(r.db.QueryRowContext(ctx,
+		`SELECT `+cardAuthorizationColumns+` FROM card_authorizations WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("card authorization", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get card authorization")
+	}
+	return auth, nil
+}
+
+// ListAuthorizedByWallet returns a wallet's unsettled authorizations, oldest first.
+func (r *CardClearingRepository) ListAuthorizedByWallet(ctx context.Context, walletID string) ([]*models.CardAuthorization, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+cardAuthorizationColumns+`
+		FROM card_authorizations
+		WHERE wallet_id = $1 AND status = 'authorized'
+		ORDER BY created_at
+	`, walletID)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list card authorizations")
+	}
+	defer func() { _ = rows.Close() }()
+
+	auths := make([]*models.CardAuthorization, 0)
+	for rows.Next() {
+		auth, err := scanCardAuthorization(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan card authorization")
+		}
+		auths = append(auths, auth)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating card authorizations")
+	}
+	return auths, nil
+}
+
+// lockAuthorization loads an authorization FOR UPDATE within tx.
+func lockAuthorization(ctx context.Context, tx *sql.Tx, id string) (*models.CardAuthorization, *errors.Error) {
+	auth, err := scanCardAuthorization(tx.QueryRowContext(ctx,
+		`SELECT `+cardAuthorizationColumns+` FROM card_authorizations WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("card authorization", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to lock card authorization")
+	}
+	return auth, nil
+}
+
+// releaseCardSpend gives back spend-limit headroom counted at authorization
+// for an amount that will never be debited.
+func releaseCardSpend(ctx context.Context, tx *sql.Tx, cardID string, amount int64) *errors.Error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE virtual_cards
+		SET daily_spent = GREATEST(daily_spent - $1, 0),
+		    monthly_spent = GREATEST(monthly_spent - $1, 0),
+		    updated_at = NOW()
+		WHERE id = $2
+	`, amount, cardID); err != nil {
+		return errors.DatabaseWrap(err, "failed to release card spend")
+	}
+	return nil
+}
+
+// applyClearing moves the wallet's balances and releases card spend as the
+// clearing step describes, within tx.
+func applyClearing(ctx context.Context, tx *sql.Tx, auth *models.CardAuthorization, clearing *models.CardClearing) *errors.Error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET balance = balance + $1,
+		    available_balance = available_balance + $2,
+		    updated_at = NOW()
+		WHERE id = $3
+	`, clearing.Balance, clearing.Available, auth.WalletID); err != nil {
+		return errors.DatabaseWrap(err, "failed to update wallet for card clearing")
+	}
+	if clearing.ReleasedSpend > 0 {
+		if err := releaseCardSpend(ctx, tx, auth.CardID, clearing.ReleasedSpend); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Settle debits amount from the wallet and releases the rest of the hold.
+// Settling an already settled authorization for the same amount is a no-op
+// and returns a nil clearing.
+func (r *CardClearingRepository) Settle(ctx context.Context, id string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	auth, lockErr := lockAuthorization(ctx, tx, id)
+	if lockErr != nil {
+		return nil, nil, lockErr
+	}
+	clearing, clearErr := auth.SettleClearing(amount)
+	if clearErr != nil {
+		return nil, nil, clearErr
+	}
+	if clearing == nil {
+		return auth, nil, nil
+	}
+	if err := applyClearing(ctx, tx, auth, clearing); err != nil {
+		return nil, nil, err
+	}
+
+	settled, err := scanCardAuthorization(tx.QueryRowContext(ctx, `
+		UPDATE card_authorizations
+		SET status = 'settled', settled_amount = $2, settled_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+cardAuthorizationColumns, id, -clearing.Balance))
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to settle card authorization")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to commit card settlement")
+	}
+	return settled, clearing, nil
+}
+
+// Reverse releases the hold of an unsettled authorization.
+// Reversing an already reversed authorization is a no-op.
+func (r *CardClearingRepository) Reverse(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	auth, lockErr := lockAuthorization(ctx, tx, id)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	clearing, clearErr := auth.ReverseClearing()
+	if clearErr != nil {
+		return nil, clearErr
+	}
+	if clearing == nil {
+		return auth, nil
+	}
+	if err := applyClearing(ctx, tx, auth, clearing); err != nil {
+		return nil, err
+	}
+
+	reversed, err := scanCardAuthorization(tx.QueryRowContext(ctx, `
+		UPDATE card_authorizations
+		SET status = 'reversed', reversed_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+cardAuthorizationColumns, id))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to reverse card authorization")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit card reversal")
+	}
+	return reversed, nil
+}
+
+// Refund credits part of a settled amount back to the wallet and records the
+// refund under refundID. Replaying a recorded refund is a no-op and returns a
+// nil clearing.
+func (r *CardClearingRepository) Refund(ctx context.Context, id, refundID string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	auth, lockErr := lockAuthorization(ctx, tx, id)
+	if lockErr != nil {
+		return nil, nil, lockErr
+	}
+
+	var prior *models.CardRefund
+	recorded := &models.CardRefund{}
+	err = tx.QueryRowContext(ctx, `
+		SELECT refund_id, authorization_id, amount, created_at
+		FROM card_refunds
+		WHERE refund_id = $1
+	`, refundID).Scan(&recorded.RefundID, &recorded.AuthorizationID, &recorded.Amount, &recorded.CreatedAt)
+	switch {
+	case err == nil:
+		prior = recorded
+	case err != sql.ErrNoRows:
+		return nil, nil, errors.DatabaseWrap(err, "failed to check card refund")
+	}
+
+	clearing, clearErr := auth.RefundClearing(amount, prior)
+	if clearErr != nil {
+		return nil, nil, clearErr
+	}
+	if clearing == nil {
+		return auth, nil, nil
+	}
+
+	// A refund ID recorded concurrently for another authorization is not
+	// serialized by this authorization's lock
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO card_refunds (refund_id, authorization_id, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (refund_id) DO NOTHING
+	`, refundID, id, amount)
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to record card refund")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, nil, errors.Conflict("refund ID already used for a different refund")
+	}
+
+	if err := applyClearing(ctx, tx, auth, clearing); err != nil {
+		return nil, nil, err
+	}
+
+	refunded, err := scanCardAuthorization(tx.QueryRowContext(ctx, `
+		UPDATE card_authorizations
+		SET refunded_amount = refunded_amount + $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+cardAuthorizationColumns, id, clearing.Balance))
+	if err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to refund card authorization")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, errors.DatabaseWrap(err, "failed to commit card refund")
+	}
+	return refunded, clearing, nil
+}
+
+// ListActiveCards returns a random sample of active cards for the card network.
+func (r *CardClearingRepository) ListActiveCards(ctx context.Context, limit int) ([]*models.NetworkCard, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.id, c.wallet_id, c.user_id
+		FROM virtual_cards c
+		JOIN wallets w ON w.id = c.wallet_id
+		WHERE c.status = 'active' AND w.status = 'active'
+		ORDER BY random()
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list active cards")
+	}
+	defer func() { _ = rows.Close() }()
+
+	cards := make([]*models.NetworkCard, 0)
+	for rows.Next() {
+		card := &models.NetworkCard{}
+		if err := rows.Scan(&card.ID, &card.WalletID, &card.UserID); err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan card")
+		}
+		cards = append(cards, card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating cards")
+	}
+	return cards, nil
+}
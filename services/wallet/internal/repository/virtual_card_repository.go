@@ -88,7 +88,7 @@ func (r *VirtualCardRepository) GetByID(ctx context.Context, id string) (*models
 		       daily_limit, monthly_limit, per_transaction_limit,
 		       daily_spent, monthly_spent, last_used_at,
 		       frozen_at, frozen_reason, cancelled_at, cancelled_reason,
-		       created_at, updated_at
+		       consecutive_declines, auto_frozen, created_at, updated_at
 		FROM virtual_cards
 		WHERE id = $1
 	`
@@ -114,6 +114,8 @@ func (r *VirtualCardRepository) GetByID(ctx context.Context, id string) (*models
 		&card.FrozenReason,
 		&card.CancelledAt,
 		&card.CancelledReason,
+		&card.ConsecutiveDeclines,
+		&card.AutoFrozen,
 		&card.CreatedAt,
 		&card.UpdatedAt,
 	)
@@ -136,7 +138,7 @@ func (r *VirtualCardRepository) ListByWallet(ctx context.Context, walletID strin
 		       daily_limit, monthly_limit, per_transaction_limit,
 		       daily_spent, monthly_spent, last_used_at,
 		       frozen_at, frozen_reason, cancelled_at, cancelled_reason,
-		       created_at, updated_at
+		       consecutive_declines, auto_frozen, created_at, updated_at
 		FROM virtual_cards
 		WHERE wallet_id = $1
 		ORDER BY created_at DESC
@@ -172,6 +174,8 @@ func (r *VirtualCardRepository) ListByWallet(ctx context.Context, walletID strin
 			&card.FrozenReason,
 			&card.CancelledAt,
 			&card.CancelledReason,
+			&card.ConsecutiveDeclines,
+			&card.AutoFrozen,
 			&card.CreatedAt,
 			&card.UpdatedAt,
 		)
@@ -215,11 +219,76 @@ func (r *VirtualCardRepository) Freeze(ctx context.Context, id, reason string) *
 	return nil
 }
 
+// AutoFreeze freezes an active card on behalf of an auto-freeze rule.
+// Returns false if the card was not active (already frozen, cancelled, ...).
+func (r *VirtualCardRepository) AutoFreeze(ctx context.Context, id, reason string) (bool, *errors.Error) {
+	query := `
+		UPDATE virtual_cards
+		SET status = $1, frozen_at = NOW(), frozen_reason = $2, auto_frozen = TRUE
+		WHERE id = $3 AND status = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		models.CardStatusFrozen,
+		reason,
+		id,
+		models.CardStatusActive,
+	)
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to auto-freeze card")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.DatabaseWrap(err, "failed to auto-freeze card")
+	}
+
+	return rows > 0, nil
+}
+
+// RecordApproval adds an approved authorization to the card's spend counters
+// and resets its consecutive decline count.
+func (r *VirtualCardRepository) RecordApproval(ctx context.Context, id string, amount int64) *errors.Error {
+	query := `
+		UPDATE virtual_cards
+		SET daily_spent = daily_spent + $1, monthly_spent = monthly_spent + $1,
+		    last_used_at = NOW(), consecutive_declines = 0
+		WHERE id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, amount, id); err != nil {
+		return errors.DatabaseWrap(err, "failed to record card authorization")
+	}
+
+	return nil
+}
+
+// RecordDecline increments the card's consecutive decline count and returns it.
+func (r *VirtualCardRepository) RecordDecline(ctx context.Context, id string) (int, *errors.Error) {
+	query := `
+		UPDATE virtual_cards
+		SET consecutive_declines = consecutive_declines + 1
+		WHERE id = $1
+		RETURNING consecutive_declines
+	`
+
+	var declines int
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&declines); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errors.NotFoundWithID("virtual card", id)
+		}
+		return 0, errors.DatabaseWrap(err, "failed to record card decline")
+	}
+
+	return declines, nil
+}
+
 // Unfreeze unfreezes a virtual card.
 func (r *VirtualCardRepository) Unfreeze(ctx context.Context, id string) *errors.Error {
 	query := `
 		UPDATE virtual_cards
-		SET status = $1, frozen_at = NULL, frozen_reason = NULL
+		SET status = $1, frozen_at = NULL, frozen_reason = NULL,
+		    auto_frozen = FALSE, consecutive_declines = 0
 		WHERE id = $2 AND status = $3
 		RETURNING id
 	`
@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+)
+
+// WalletClosureRepository handles scheduled wallet closures. Every change to
+// a closure and its wallet happens in one transaction, with the closure row
+// locked before the wallets.
+type WalletClosureRepository struct {
+	db *sql.DB
+}
+
+// NewWalletClosureRepository creates a new wallet closure repository.
+func NewWalletClosureRepository(db *sql.DB) *WalletClosureRepository {
+	return &WalletClosureRepository{db: db}
+}
+
+const walletClosureColumns = `
+	id, wallet_id, requested_by, status, reason, sweep_method, sweep_wallet_id,
+	previous_status, scheduled_for, swept_amount, last_error,
+	closed_at, reopen_until, created_at, updated_at
+`
+
+func scanWalletClosure(row rowScanner) (*models.WalletClosure, error) {
+	closure := &models.WalletClosure{}
+	err := row.Scan(
+		&closure.ID,
+		&closure.WalletID,
+		&closure.RequestedBy,
+		&closure.Status,
+		&closure.Reason,
+		&closure.SweepMethod,
+		&closure.SweepWalletID,
+		&closure.PreviousStatus,
+		&closure.ScheduledFor,
+		&closure.SweptAmount,
+		&closure.LastError,
+		&closure.ClosedAt,
+		&closure.ReopenUntil,
+		&closure.CreatedAt,
+		&closure.UpdatedAt,
+	)
+	return closure, err
+}
+
+// Schedule puts the wallet in pending_closure and records the closure. It
+// fails with a conflict if the wallet's status changed since it was read or
+// a closure is already pending.
+func (r *WalletClosureRepository) Schedule(ctx context.Context, closure *models.WalletClosure) *errors.Error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET status = 'pending_closure', updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, closure.WalletID, closure.PreviousStatus)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to mark wallet pending closure")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.Conflict("wallet status changed, try again")
+	}
+
+	created, err := scanWalletClosure(tx.QueryRowContext(ctx, `
+		INSERT INTO wallet_closures (wallet_id, requested_by, reason, sweep_method, sweep_wallet_id, previous_status, scheduled_for)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+walletClosureColumns,
+		closure.WalletID, closure.RequestedBy, closure.Reason, closure.SweepMethod,
+		closure.SweepWalletID, closure.PreviousStatus, closure.ScheduledFor,
+	))
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return errors.Conflict("wallet already has a pending closure")
+		}
+		return errors.DatabaseWrap(err, "failed to record wallet closure")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit wallet closure")
+	}
+
+	*closure = *created
+	return nil
+}
+
+// GetByID retrieves a wallet closure.
+func (r *WalletClosureRepository) GetByID(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	closure, err := scanWalletClosure(r.db.QueryRowContext(ctx,
+		`SELECT `+walletClosureColumns+` FROM wallet_closures WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("wallet closure", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get wallet closure")
+	}
+	return closure, nil
+}
+
+// GetLatestByWallet retrieves the most recent closure of a wallet.
+func (r *WalletClosureRepository) GetLatestByWallet(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	closure, err := scanWalletClosure(r.db.QueryRowContext(ctx, `
+		SELECT `+walletClosureColumns+`
+		FROM wallet_closures
+		WHERE wallet_id = $1
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, walletID))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFound("wallet has no closure")
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to get wallet closure")
+	}
+	return closure, nil
+}
+
+// ListDue returns pending closures scheduled at or before now, oldest first.
+func (r *WalletClosureRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WalletClosure, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+walletClosureColumns+`
+		FROM wallet_closures
+		WHERE status = 'pending' AND scheduled_for <= $1
+		ORDER BY scheduled_for
+		LIMIT $2
+	`, now, limit)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list due wallet closures")
+	}
+	defer func() { _ = rows.Close() }()
+
+	closures := make([]*models.WalletClosure, 0)
+	for rows.Next() {
+		closure, err := scanWalletClosure(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan wallet closure")
+		}
+		closures = append(closures, closure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating wallet closures")
+	}
+	return closures, nil
+}
+
+// RecordError notes why a pending closure could not finish yet.
+func (r *WalletClosureRepository) RecordError(ctx context.Context, id, message string) *errors.Error {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE wallet_closures
+		SET last_error = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, id, message); err != nil {
+		return errors.DatabaseWrap(err, "failed to record wallet closure error")
+	}
+	return nil
+}
+
+// lockClosure loads a closure FOR UPDATE within tx.
+func lockClosure(ctx context.Context, tx *sql.Tx, id string) (*models.WalletClosure, *errors.Error) {
+	closure, err := scanWalletClosure(tx.QueryRowContext(ctx,
+		`SELECT `+walletClosureColumns+` FROM wallet_closures WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("wallet closure", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to lock wallet closure")
+	}
+	return closure, nil
+}
+
+// closingWallet is the locked state of a wallet taking part in a closure.
+type closingWallet struct {
+	status           string
+	currency         string
+	balance          int64
+	availableBalance int64
+}
+
+// lockClosingWallet loads a wallet's status and balances FOR UPDATE within tx.
+func lockClosingWallet(ctx context.Context, tx *sql.Tx, id string) (*closingWallet, *errors.Error) {
+	wallet := &closingWallet{}
+	err := tx.QueryRowContext(ctx, `
+		SELECT status, currency, balance, available_balance
+		FROM wallets
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&wallet.status, &wallet.currency, &wallet.balance, &wallet.availableBalance)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("wallet", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to lock wallet")
+	}
+	return wallet, nil
+}
+
+// Cancel cancels a pending closure and restores the wallet's previous status.
+func (r *WalletClosureRepository) Cancel(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	closure, lockErr := lockClosure(ctx, tx, id)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	if closure.Status != models.WalletClosureStatusPending {
+		return nil, errors.Conflict(fmt.Sprintf("wallet closure is %s and can no longer be cancelled", closure.Status))
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending_closure'
+	`, closure.WalletID, closure.PreviousStatus); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to restore wallet status")
+	}
+
+	cancelled, err := scanWalletClosure(tx.QueryRowContext(ctx, `
+		UPDATE wallet_closures
+		SET status = 'cancelled', last_error = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+walletClosureColumns, id))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to cancel wallet closure")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit wallet closure cancellation")
+	}
+	return cancelled, nil
+}
+
+// ExecuteSweep moves the whole balance of a pending_closure wallet to the
+// closure's sweep wallet, or out of the system for a withdrawal. Outstanding
+// holds must have settled first. Repeating a sweep with the same transaction
+// ID is a no-op.
+func (r *WalletClosureRepository) ExecuteSweep(ctx context.Context, req *models.ExecuteClosureSweepRequest) *errors.Error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	closure, lockErr := lockClosure(ctx, tx, req.ClosureID)
+	if lockErr != nil {
+		return lockErr
+	}
+
+	var existingClosureID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT closure_id FROM wallet_closure_sweeps WHERE transaction_id = $1
+	`, req.TransactionID).Scan(&existingClosureID)
+	if err == nil {
+		if existingClosureID != req.ClosureID {
+			return errors.Conflict("transaction already swept another wallet closure")
+		}
+		return nil // Already swept (idempotent)
+	} else if err != sql.ErrNoRows {
+		return errors.DatabaseWrap(err, "failed to check idempotency")
+	}
+
+	if closure.Status != models.WalletClosureStatusPending {
+		return errors.Conflict(fmt.Sprintf("wallet closure is %s", closure.Status))
+	}
+	if closure.WalletID != req.WalletID {
+		return errors.BadRequest("wallet does not match the closure")
+	}
+
+	method := models.SweepMethodWithdrawal
+	if closure.SweepMethod != nil {
+		method = *closure.SweepMethod
+	}
+	switch {
+	case method == models.SweepMethodWallet && (closure.SweepWalletID == nil || *closure.SweepWalletID != req.DestinationWalletID):
+		return errors.BadRequest("destination does not match the closure's sweep wallet")
+	case method == models.SweepMethodWithdrawal && req.DestinationWalletID != "":
+		return errors.BadRequest("closure sweeps by withdrawal, not to a wallet")
+	}
+
+	// Lock wallets in ID order, like transfers, to avoid deadlocks
+	walletIDs := []string{req.WalletID}
+	if req.DestinationWalletID != "" {
+		walletIDs = append(walletIDs, req.DestinationWalletID)
+		if req.DestinationWalletID < req.WalletID {
+			walletIDs[0], walletIDs[1] = walletIDs[1], walletIDs[0]
+		}
+	}
+	locked := make(map[string]*closingWallet, len(walletIDs))
+	for _, id := range walletIDs {
+		wallet, lockErr := lockClosingWallet(ctx, tx, id)
+		if lockErr != nil {
+			return lockErr
+		}
+		locked[id] = wallet
+	}
+
+	source := locked[req.WalletID]
+	if source.status != string(models.WalletStatusPendingClosure) {
+		return errors.New(errors.ErrCodeWalletNotActive, "wallet is not pending closure").AddDetail("status", source.status)
+	}
+	if source.availableBalance != source.balance {
+		return errors.Conflict("wallet has outstanding holds").AddDetail("held_amount", source.balance-source.availableBalance)
+	}
+	if req.Amount != source.balance {
+		return errors.BadRequest(fmt.Sprintf("sweep must move the whole balance of %s", money.New(source.balance, sharedModels.Currency(source.currency))))
+	}
+
+	if req.DestinationWalletID != "" {
+		dest := locked[req.DestinationWalletID]
+		if dest.status != string(models.WalletStatusActive) {
+			return errors.New(errors.ErrCodeWalletNotActive, "sweep wallet is not active").AddDetail("status", dest.status)
+		}
+		if dest.currency != source.currency {
+			return errors.New(errors.ErrCodeWalletCurrencyMismatch, fmt.Sprintf("currency mismatch: wallet is %s, sweep wallet is %s", source.currency, dest.currency))
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE wallets
+			SET balance = balance + $1, available_balance = available_balance + $1, updated_at = NOW()
+			WHERE id = $2
+		`, req.Amount, req.DestinationWalletID); err != nil {
+			return errors.DatabaseWrap(err, "failed to credit sweep wallet")
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET balance = balance - $1, available_balance = available_balance - $1, updated_at = NOW()
+		WHERE id = $2
+	`, req.Amount, req.WalletID); err != nil {
+		return errors.DatabaseWrap(err, "failed to debit closing wallet")
+	}
+
+	var destinationWalletID *string
+	if req.DestinationWalletID != "" {
+		destinationWalletID = &req.DestinationWalletID
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO wallet_closure_sweeps (transaction_id, closure_id, destination_wallet_id, amount)
+		VALUES ($1, $2, $3, $4)
+	`, req.TransactionID, req.ClosureID, destinationWalletID, req.Amount); err != nil {
+		return errors.DatabaseWrap(err, "failed to record closure sweep")
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallet_closures
+		SET swept_amount = swept_amount + $2, updated_at = NOW()
+		WHERE id = $1
+	`, req.ClosureID, req.Amount); err != nil {
+		return errors.DatabaseWrap(err, "failed to record closure sweep")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.DatabaseWrap(err, "failed to commit closure sweep")
+	}
+	return nil
+}
+
+// Close closes the wallet of a pending closure once its balance is zero,
+// cancels the wallet's cards and opens the reopen grace period.
+func (r *WalletClosureRepository) Close(ctx context.Context, id string, reopenUntil time.Time) (*models.WalletClosure, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	closure, lockErr := lockClosure(ctx, tx, id)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	if closure.Status != models.WalletClosureStatusPending {
+		return nil, errors.Conflict(fmt.Sprintf("wallet closure is %s", closure.Status))
+	}
+
+	wallet, lockErr := lockClosingWallet(ctx, tx, closure.WalletID)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	if wallet.status != string(models.WalletStatusPendingClosure) {
+		return nil, errors.New(errors.ErrCodeWalletNotActive, "wallet is not pending closure").AddDetail("status", wallet.status)
+	}
+	if wallet.balance != 0 || wallet.availableBalance != 0 {
+		return nil, errors.Conflict("wallet still has a balance")
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET status = 'closed', is_default = FALSE, closed_at = NOW(), closed_reason = $2, updated_at = NOW()
+		WHERE id = $1
+	`, closure.WalletID, closure.Reason); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to close wallet")
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE virtual_cards
+		SET status = $2, cancelled_at = NOW(), cancelled_reason = 'wallet closed'
+		WHERE wallet_id = $1 AND status != $2
+	`, closure.WalletID, models.CardStatusCancelled); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to cancel wallet cards")
+	}
+
+	closed, err := scanWalletClosure(tx.QueryRowContext(ctx, `
+		UPDATE wallet_closures
+		SET status = 'closed', closed_at = NOW(), reopen_until = $2, last_error = NULL, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+walletClosureColumns, id, reopenUntil))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to record wallet closure")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit wallet closure")
+	}
+	return closed, nil
+}
+
+// Reopen restores a closed wallet's previous status within its grace period.
+// Cancelled cards stay cancelled.
+func (r *WalletClosureRepository) Reopen(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	closure, lockErr := lockClosure(ctx, tx, id)
+	if lockErr != nil {
+		return nil, lockErr
+	}
+	if !closure.CanReopen(time.Now()) {
+		return nil, errors.Conflict("wallet can no longer be reopened")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE wallets
+		SET status = $2, closed_at = NULL, closed_reason = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'closed'
+	`, closure.WalletID, closure.PreviousStatus)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.Conflict("user already has an open wallet for this currency")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to reopen wallet")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, errors.Conflict("wallet is not closed")
+	}
+
+	reopened, err := scanWalletClosure(tx.QueryRowContext(ctx, `
+		UPDATE wallet_closures
+		SET status = 'reopened', updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+walletClosureColumns, id))
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to record wallet reopening")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit wallet reopening")
+	}
+	return reopened, nil
+}
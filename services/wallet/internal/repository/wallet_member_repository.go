@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// WalletMemberRepository handles database operations for joint wallet co-owners.
+type WalletMemberRepository struct {
+	db *sql.DB
+}
+
+// NewWalletMemberRepository creates a new wallet member repository.
+func NewWalletMemberRepository(db *sql.DB) *WalletMemberRepository {
+	return &WalletMemberRepository{db: db}
+}
+
+const walletMemberColumns = `
+	id, wallet_id, user_id, permission, status, invited_by, invited_at, responded_at, updated_at
+`
+
+// Invite records an invitation. A user who declined or was removed earlier
+// can be invited again; inviting a pending or active co-owner is a conflict.
+func (r *WalletMemberRepository) Invite(ctx context.Context, member *models.WalletMember) *errors.Error {
+	query := `
+		INSERT INTO wallet_members (wallet_id, user_id, permission, status, invited_by)
+		VALUES ($1, $2, $3, 'invited', $4)
+		ON CONFLICT (wallet_id, user_id) DO UPDATE
+		SET permission = EXCLUDED.permission, status = 'invited', invited_by = EXCLUDED.invited_by,
+		    invited_at = NOW(), responded_at = NULL, updated_at = NOW()
+		WHERE wallet_members.status IN ('declined', 'removed')
+		RETURNING ` + walletMemberColumns
+
+	invited, err := scanWalletMember(r.db.QueryRowContext(ctx, query,
+		member.WalletID, member.UserID, member.Permission, member.InvitedBy,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errors.Conflict("this user is already a co-owner or has a pending invite")
+		}
+		return errors.DatabaseWrap(err, "failed to invite co-owner")
+	}
+
+	*member = *invited
+	return nil
+}
+
+// Get retrieves a user's membership of a wallet in any status.
+func (r *WalletMemberRepository) Get(ctx context.Context, walletID, userID string) (*models.WalletMember, *errors.Error) {
+	query := `SELECT ` + walletMemberColumns + ` FROM wallet_members WHERE wallet_id = $1 AND user_id = $2`
+
+	member, err := scanWalletMember(r.db.QueryRowContext(ctx, query, walletID, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFound("co-owner not found")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get co-owner")
+	}
+	return member, nil
+}
+
+// ListByWallet retrieves a wallet's active and invited co-owners.
+func (r *WalletMemberRepository) ListByWallet(ctx context.Context, walletID string) ([]*models.WalletMember, *errors.Error) {
+	query := `
+		SELECT ` + walletMemberColumns + `
+		FROM wallet_members
+		WHERE wallet_id = $1 AND status IN ('invited', 'active')
+		ORDER BY invited_at ASC
+	`
+	return r.list(ctx, query, walletID)
+}
+
+// ListByUser retrieves a user's memberships in the given status.
+func (r *WalletMemberRepository) ListByUser(ctx context.Context, userID string, status models.WalletMemberStatus) ([]*models.WalletMember, *errors.Error) {
+	query := `
+		SELECT ` + walletMemberColumns + `
+		FROM wallet_members
+		WHERE user_id = $1 AND status = $2
+		ORDER BY invited_at DESC
+	`
+	return r.list(ctx, query, userID, status)
+}
+
+// UpdateStatus moves a membership from one status to another. It returns
+// NotFound if the membership is not in the from status.
+func (r *WalletMemberRepository) UpdateStatus(ctx context.Context, walletID, userID string, from, to models.WalletMemberStatus) *errors.Error {
+	query := `
+		UPDATE wallet_members
+		SET status = $4, updated_at = NOW(),
+		    responded_at = CASE WHEN $3 = 'invited' THEN NOW() ELSE responded_at END
+		WHERE wallet_id = $1 AND user_id = $2 AND status = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, userID, from, to)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update co-owner")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.NotFound("co-owner not found")
+	}
+	return nil
+}
+
+// UpdatePermission changes the permission of an active or invited co-owner.
+func (r *WalletMemberRepository) UpdatePermission(ctx context.Context, walletID, userID string, permission models.WalletPermission) *errors.Error {
+	query := `
+		UPDATE wallet_members
+		SET permission = $3, updated_at = NOW()
+		WHERE wallet_id = $1 AND user_id = $2 AND status IN ('invited', 'active')
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, userID, permission)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update co-owner permission")
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return errors.NotFound("co-owner not found")
+	}
+	return nil
+}
+
+func (r *WalletMemberRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.WalletMember, *errors.Error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to list co-owners")
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := make([]*models.WalletMember, 0)
+	for rows.Next() {
+		member, err := scanWalletMember(rows)
+		if err != nil {
+			return nil, errors.DatabaseWrap(err, "failed to scan co-owner")
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.DatabaseWrap(err, "error iterating co-owners")
+	}
+
+	return members, nil
+}
+
+func scanWalletMember(row rowScanner) (*models.WalletMember, error) {
+	member := &models.WalletMember{}
+	err := row.Scan(
+		&member.ID,
+		&member.WalletID,
+		&member.UserID,
+		&member.Permission,
+		&member.Status,
+		&member.InvitedBy,
+		&member.InvitedAt,
+		&member.RespondedAt,
+		&member.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
@@ -5,19 +5,31 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+	"github.com/lib/pq"
 )
 
 // WalletRepository handles database operations for wallets.
 type WalletRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	limitsLoc *time.Location // Timezone whose midnight ends limit windows
 }
 
-// NewWalletRepository creates a new wallet repository.
+// NewWalletRepository creates a new wallet repository. Limit windows roll at
+// UTC midnight until SetLimitsLocation is called.
 func NewWalletRepository(db *sql.DB) *WalletRepository {
-	return &WalletRepository{db: db}
+	return &WalletRepository{db: db, limitsLoc: time.UTC}
+}
+
+// SetLimitsLocation sets the timezone whose midnight ends daily and monthly
+// limit windows.
+func (r *WalletRepository) SetLimitsLocation(loc *time.Location) {
+	r.limitsLoc = loc
 }
 
 // Create creates a new wallet and its associated transfer limits.
@@ -67,13 +79,19 @@ func (r *WalletRepository) Create(ctx context.Context, wallet *models.Wallet) *e
 		return errors.DatabaseWrap(err, "failed to create wallet")
 	}
 
+	if wallet.Tags == nil {
+		wallet.Tags = []string{}
+	}
+
 	// Create default wallet limits (₹10,000/day, ₹100,000/month)
 	limitsQuery := `
-		INSERT INTO wallet_limits (wallet_id, daily_limit, monthly_limit)
-		VALUES ($1, $2, $3)
+		INSERT INTO wallet_limits (wallet_id, daily_limit, monthly_limit, daily_reset_at, monthly_reset_at)
+		VALUES ($1, $2, $3, $4, $5)
 	`
 
-	_, err = tx.ExecContext(ctx, limitsQuery, wallet.ID, 1000000, 10000000) // Default limits in paise
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, limitsQuery, wallet.ID, 1000000, 10000000, // Default limits in paise
+		models.NextDailyReset(now, r.limitsLoc), models.NextMonthlyReset(now, r.limitsLoc))
 	if err != nil {
 		return errors.DatabaseWrap(err, "failed to create wallet limits")
 	}
@@ -94,7 +112,8 @@ func (r *WalletRepository) GetByID(ctx context.Context, id string) (*models.Wall
 
 	query := `
 		SELECT id, user_id, type, currency, balance, available_balance, status,
-		       ledger_account_id, metadata, created_at, updated_at, closed_at, closed_reason
+		       ledger_account_id, nickname, color, tags, is_default, metadata,
+		       created_at, updated_at, closed_at, closed_reason
 		FROM wallets
 		WHERE id = $1
 	`
@@ -108,6 +127,10 @@ func (r *WalletRepository) GetByID(ctx context.Context, id string) (*models.Wall
 		&wallet.AvailableBalance,
 		&wallet.Status,
 		&wallet.LedgerAccountID,
+		&wallet.Nickname,
+		&wallet.Color,
+		pq.Array(&wallet.Tags),
+		&wallet.IsDefault,
 		&metadataJSON,
 		&wallet.CreatedAt,
 		&wallet.UpdatedAt,
@@ -136,7 +159,8 @@ func (r *WalletRepository) GetByID(ctx context.Context, id string) (*models.Wall
 func (r *WalletRepository) ListByUserID(ctx context.Context, userID string, status *models.WalletStatus) ([]*models.Wallet, *errors.Error) {
 	query := `
 		SELECT id, user_id, type, currency, balance, available_balance, status,
-		       ledger_account_id, metadata, created_at, updated_at, closed_at, closed_reason
+		       ledger_account_id, nickname, color, tags, is_default, metadata,
+		       created_at, updated_at, closed_at, closed_reason
 		FROM wallets
 		WHERE user_id = $1
 	`
@@ -170,6 +194,10 @@ func (r *WalletRepository) ListByUserID(ctx context.Context, userID string, stat
 			&wallet.AvailableBalance,
 			&wallet.Status,
 			&wallet.LedgerAccountID,
+			&wallet.Nickname,
+			&wallet.Color,
+			pq.Array(&wallet.Tags),
+			&wallet.IsDefault,
 			&metadataJSON,
 			&wallet.CreatedAt,
 			&wallet.UpdatedAt,
@@ -197,6 +225,128 @@ func (r *WalletRepository) ListByUserID(ctx context.Context, userID string, stat
 	return wallets, nil
 }
 
+// walletSortColumns maps the allowed sort fields to their columns.
+var walletSortColumns = map[string]string{
+	models.WalletSortCreatedAt: "created_at",
+	models.WalletSortUpdatedAt: "updated_at",
+	models.WalletSortBalance:   "balance",
+}
+
+// Search lists wallets across all users matching the filter, one page at a
+// time, and returns the total number of matching wallets.
+func (r *WalletRepository) Search(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	argCount := 0
+
+	addFilter := func(clause string, value interface{}) {
+		argCount++
+		where += fmt.Sprintf(clause, argCount)
+		args = append(args, value)
+	}
+
+	if filter.UserID != nil {
+		addFilter(" AND user_id = $%d", *filter.UserID)
+	}
+	if filter.Status != nil {
+		addFilter(" AND status = $%d", *filter.Status)
+	}
+	if filter.Type != nil {
+		addFilter(" AND type = $%d", *filter.Type)
+	}
+	if filter.Currency != nil {
+		addFilter(" AND currency = $%d", *filter.Currency)
+	}
+	if filter.MinBalance != nil {
+		addFilter(" AND balance >= $%d", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		addFilter(" AND balance <= $%d", *filter.MaxBalance)
+	}
+	if filter.CreatedAfter != nil {
+		addFilter(" AND created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		addFilter(" AND created_at < $%d", *filter.CreatedBefore)
+	}
+	if filter.Tag != nil {
+		addFilter(" AND $%d = ANY(tags)", *filter.Tag)
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM wallets"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count wallets")
+	}
+
+	sortColumn, ok := walletSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	direction := "DESC"
+	if filter.SortAsc {
+		direction = "ASC"
+	}
+
+	// id breaks ties so pages stay stable across requests
+	query := `
+		SELECT id, user_id, type, currency, balance, available_balance, status,
+		       ledger_account_id, nickname, color, tags, is_default, metadata,
+		       created_at, updated_at, closed_at, closed_reason
+		FROM wallets` + where +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d", sortColumn, direction, direction, argCount+1, argCount+2)
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to search wallets")
+	}
+	defer func() { _ = rows.Close() }()
+
+	wallets := make([]*models.Wallet, 0)
+	for rows.Next() {
+		wallet := &models.Wallet{}
+		var metadataJSON []byte
+
+		err := rows.Scan(
+			&wallet.ID,
+			&wallet.UserID,
+			&wallet.Type,
+			&wallet.Currency,
+			&wallet.Balance,
+			&wallet.AvailableBalance,
+			&wallet.Status,
+			&wallet.LedgerAccountID,
+			&wallet.Nickname,
+			&wallet.Color,
+			pq.Array(&wallet.Tags),
+			&wallet.IsDefault,
+			&metadataJSON,
+			&wallet.CreatedAt,
+			&wallet.UpdatedAt,
+			&wallet.ClosedAt,
+			&wallet.ClosedReason,
+		)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan wallet")
+		}
+
+		// Deserialize metadata
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &wallet.Metadata); err != nil {
+				return nil, 0, errors.Internal("failed to parse metadata")
+			}
+		}
+
+		wallets = append(wallets, wallet)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "error iterating wallets")
+	}
+
+	return wallets, total, nil
+}
+
 // UpdateStatus updates the status of a wallet.
 func (r *WalletRepository) UpdateStatus(ctx context.Context, id string, status models.WalletStatus) *errors.Error {
 	query := `
@@ -223,7 +373,7 @@ func (r *WalletRepository) UpdateStatus(ctx context.Context, id string, status m
 func (r *WalletRepository) Close(ctx context.Context, id, reason string) *errors.Error {
 	query := `
 		UPDATE wallets
-		SET status = 'closed', closed_at = NOW(), closed_reason = $1, updated_at = NOW()
+		SET status = 'closed', is_default = FALSE, closed_at = NOW(), closed_reason = $1, updated_at = NOW()
 		WHERE id = $2 AND status != 'closed'
 		RETURNING id
 	`
@@ -241,6 +391,75 @@ func (r *WalletRepository) Close(ctx context.Context, id, reason string) *errors
 	return nil
 }
 
+// UpdateProfile applies a partial update to a wallet's nickname, color, tags,
+// metadata and default flag, and returns the updated wallet. Making a wallet
+// the default clears the flag on the owner's other wallets in the same
+// transaction. Closed wallets are not updated.
+func (r *WalletRepository) UpdateProfile(ctx context.Context, id string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var userID, status string
+	err = tx.QueryRowContext(ctx, `SELECT user_id, status FROM wallets WHERE id = $1 FOR UPDATE`, id).Scan(&userID, &status)
+	if err == sql.ErrNoRows {
+		return nil, errors.NotFoundWithID("wallet", id)
+	}
+	if err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to lock wallet")
+	}
+	if models.WalletStatus(status) == models.WalletStatusClosed {
+		return nil, errors.BadRequest("closed wallets cannot be updated")
+	}
+
+	set := "updated_at = NOW()"
+	args := []interface{}{id}
+	addSet := func(column string, value interface{}) {
+		args = append(args, value)
+		set += fmt.Sprintf(", %s = $%d", column, len(args))
+	}
+
+	if req.Nickname != nil {
+		addSet("nickname", sql.NullString{String: *req.Nickname, Valid: *req.Nickname != ""})
+	}
+	if req.Color != nil {
+		addSet("color", sql.NullString{String: *req.Color, Valid: *req.Color != ""})
+	}
+	if req.Tags != nil {
+		addSet("tags", pq.Array(*req.Tags))
+	}
+	if req.Metadata != nil {
+		metadataJSON, err := json.Marshal(*req.Metadata)
+		if err != nil {
+			return nil, errors.Internal("failed to marshal metadata")
+		}
+		addSet("metadata", metadataJSON)
+	}
+	if req.IsDefault != nil {
+		if *req.IsDefault {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE wallets SET is_default = FALSE, updated_at = NOW()
+				WHERE user_id = $1 AND id != $2 AND is_default
+			`, userID, id); err != nil {
+				return nil, errors.DatabaseWrap(err, "failed to clear default wallet")
+			}
+		}
+		addSet("is_default", *req.IsDefault)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE wallets SET "+set+" WHERE id = $1", args...); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to update wallet")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.DatabaseWrap(err, "failed to commit wallet update")
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 // GetBalance retrieves the balance of a wallet.
 func (r *WalletRepository) GetBalance(ctx context.Context, id string) (*models.WalletBalance, *errors.Error) {
 	balance := &models.WalletBalance{WalletID: id}
@@ -305,6 +524,10 @@ func (r *WalletRepository) GetLimits(ctx context.Context, walletID string) (*mod
 		return nil, errors.DatabaseWrap(err, "failed to get wallet limits")
 	}
 
+	// Ended windows read as reset; the row catches up on the next
+	// reservation or reset run
+	limits.Roll(time.Now(), r.limitsLoc)
+
 	return limits, nil
 }
 
@@ -452,23 +675,28 @@ func (r *WalletRepository) ProcessTransferWithinTx(ctx context.Context, sourceWa
 	}
 
 	// 3. Validate both wallets are active
+	// Inactive wallets are awaiting their owner's KYC verification
+	if sourceStatus == string(models.WalletStatusInactive) {
+		return errors.New(errors.ErrCodeKYCRequired, "source wallet is awaiting KYC verification")
+	}
 	if sourceStatus != string(models.WalletStatusActive) {
-		return errors.BadRequest("source wallet is not active")
+		return errors.New(errors.ErrCodeWalletNotActive, "source wallet is not active").AddDetail("status", sourceStatus)
 	}
 
 	if destStatus != string(models.WalletStatusActive) {
-		return errors.BadRequest("destination wallet is not active")
+		return errors.New(errors.ErrCodeWalletNotActive, "destination wallet is not active").AddDetail("status", destStatus)
 	}
 
 	// 4. Validate currency match
 	if sourceCurrency != destCurrency {
-		return errors.BadRequest(fmt.Sprintf("currency mismatch: source is %s, destination is %s", sourceCurrency, destCurrency))
+		return errors.New(errors.ErrCodeWalletCurrencyMismatch, fmt.Sprintf("currency mismatch: source is %s, destination is %s", sourceCurrency, destCurrency))
 	}
 
 	// 5. Check if source has sufficient balance
 	if sourceBalance < amount {
 		shortfall := amount - sourceBalance
-		return errors.BadRequest(fmt.Sprintf("insufficient balance (short by: ₹%.2f)", float64(shortfall)/100))
+		return errors.New(errors.ErrCodeWalletInsufficientFunds, fmt.Sprintf("insufficient balance (short by: %s)", money.New(shortfall, sharedModels.Currency(sourceCurrency)))).
+			AddDetail("shortfall", shortfall)
 	}
 
 	// 6. Check and reserve limits
@@ -524,55 +752,28 @@ func (r *WalletRepository) ProcessTransferWithinTx(ctx context.Context, sourceWa
 
 // CheckAndReserveLimitWithinTx checks if a transfer is within limits and reserves the amount atomically.
 // This must be called within a transaction to ensure atomic limit checking and reservation.
+// Windows that have ended are rolled first, so spend from a previous day or
+// month never counts against the current one.
 func (r *WalletRepository) CheckAndReserveLimitWithinTx(ctx context.Context, tx *sql.Tx, walletID string, amount int64) *errors.Error {
-	// Get current limits with row lock (FOR UPDATE)
-	// Also check and reset if limits have expired using the check function
 	limits := &models.WalletLimits{}
 
 	query := `
-		WITH current_limits AS (
-			SELECT id, wallet_id, daily_limit, daily_spent, daily_reset_at,
-			       monthly_limit, monthly_spent, monthly_reset_at
-			FROM wallet_limits
-			WHERE wallet_id = $1
-			FOR UPDATE
-		),
-		reset_check AS (
-			SELECT
-				cl.id,
-				cl.wallet_id,
-				cl.daily_limit,
-				cl.monthly_limit,
-				cr.new_daily_spent,
-				cr.new_daily_reset_at,
-				cr.new_monthly_spent,
-				cr.new_monthly_reset_at
-			FROM current_limits cl,
-			LATERAL check_and_reset_wallet_limits(
-				cl.wallet_id,
-				cl.daily_limit,
-				cl.daily_spent,
-				cl.daily_reset_at,
-				cl.monthly_limit,
-				cl.monthly_spent,
-				cl.monthly_reset_at
-			) cr
-		)
-		SELECT id, wallet_id, daily_limit, new_daily_spent, new_daily_reset_at,
-		       monthly_limit, new_monthly_spent, new_monthly_reset_at
-		FROM reset_check
+		SELECT id, wallet_id, daily_limit, daily_spent, daily_reset_at,
+		       monthly_limit, monthly_spent, monthly_reset_at
+		FROM wallet_limits
+		WHERE wallet_id = $1
+		FOR UPDATE
 	`
 
-	var dailyResetAt, monthlyResetAt interface{}
 	err := tx.QueryRowContext(ctx, query, walletID).Scan(
 		&limits.ID,
 		&limits.WalletID,
 		&limits.DailyLimit,
 		&limits.DailySpent,
-		&dailyResetAt,
+		&limits.DailyResetAt,
 		&limits.MonthlyLimit,
 		&limits.MonthlySpent,
-		&monthlyResetAt,
+		&limits.MonthlyResetAt,
 	)
 
 	if err != nil {
@@ -582,66 +783,39 @@ func (r *WalletRepository) CheckAndReserveLimitWithinTx(ctx context.Context, tx
 		return errors.DatabaseWrap(err, "failed to get wallet limits")
 	}
 
-	// If limits were reset, update them in the database
-	needsUpdate := false
-	updateQuery := "UPDATE wallet_limits SET "
-	updateArgs := []interface{}{}
-	argCount := 0
-
-	// Check if daily needs reset
-	if limits.DailySpent == 0 && dailyResetAt != nil {
-		needsUpdate = true
-		argCount++
-		updateQuery += fmt.Sprintf("daily_spent = $%d, ", argCount)
-		updateArgs = append(updateArgs, 0)
-		argCount++
-		updateQuery += fmt.Sprintf("daily_reset_at = $%d, ", argCount)
-		updateArgs = append(updateArgs, dailyResetAt)
-	}
-
-	// Check if monthly needs reset
-	if limits.MonthlySpent == 0 && monthlyResetAt != nil {
-		needsUpdate = true
-		argCount++
-		updateQuery += fmt.Sprintf("monthly_spent = $%d, ", argCount)
-		updateArgs = append(updateArgs, 0)
-		argCount++
-		updateQuery += fmt.Sprintf("monthly_reset_at = $%d, ", argCount)
-		updateArgs = append(updateArgs, monthlyResetAt)
-	}
-
-	if needsUpdate {
-		argCount++
-		updateQuery += fmt.Sprintf("updated_at = NOW() WHERE id = $%d", argCount)
-		updateArgs = append(updateArgs, limits.ID)
-		_, err = tx.ExecContext(ctx, updateQuery, updateArgs...)
-		if err != nil {
-			return errors.DatabaseWrap(err, "failed to reset expired limits")
-		}
-	}
+	limits.Roll(time.Now(), r.limitsLoc)
 
 	// Check if amount exceeds daily limit
 	if limits.DailySpent+amount > limits.DailyLimit {
-		remaining := limits.DailyLimit - limits.DailySpent
-		return errors.BadRequest(fmt.Sprintf("transfer exceeds daily limit (remaining: ₹%.2f)", float64(remaining)/100))
+		remaining := limits.DailyRemaining()
+		return errors.New(errors.ErrCodeLimitDailyExceeded, fmt.Sprintf("transfer exceeds daily limit (remaining: ₹%.2f)", float64(remaining)/100)).
+			AddDetail("remaining", remaining).
+			AddDetail("resets_at", limits.DailyResetAt)
 	}
 
 	// Check if amount exceeds monthly limit
 	if limits.MonthlySpent+amount > limits.MonthlyLimit {
-		remaining := limits.MonthlyLimit - limits.MonthlySpent
-		return errors.BadRequest(fmt.Sprintf("transfer exceeds monthly limit (remaining: ₹%.2f)", float64(remaining)/100))
+		remaining := limits.MonthlyRemaining()
+		return errors.New(errors.ErrCodeLimitMonthlyExceeded, fmt.Sprintf("transfer exceeds monthly limit (remaining: ₹%.2f)", float64(remaining)/100)).
+			AddDetail("remaining", remaining).
+			AddDetail("resets_at", limits.MonthlyResetAt)
 	}
 
-	// Reserve the amount
+	// Reserve the amount, saving any rolled windows with it
 	reserveQuery := `
 		UPDATE wallet_limits
-		SET daily_spent = daily_spent + $1,
-		    monthly_spent = monthly_spent + $1,
+		SET daily_spent = $1,
+		    daily_reset_at = $2,
+		    monthly_spent = $3,
+		    monthly_reset_at = $4,
 		    updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $5
 	`
 
-	_, err = tx.ExecContext(ctx, reserveQuery, amount, limits.ID)
+	_, err = tx.ExecContext(ctx, reserveQuery,
+		limits.DailySpent+amount, limits.DailyResetAt.Time,
+		limits.MonthlySpent+amount, limits.MonthlyResetAt.Time,
+		limits.ID)
 	if err != nil {
 		return errors.DatabaseWrap(err, "failed to reserve transfer limit")
 	}
@@ -649,6 +823,62 @@ func (r *WalletRepository) CheckAndReserveLimitWithinTx(ctx context.Context, tx
 	return nil
 }
 
+// ResetExpiredLimits rolls up to batchSize wallet limits whose daily or
+// monthly window ended before now, and returns how many it rolled. Rows locked
+// by an in-flight transfer are skipped; the transfer rolls them itself.
+func (r *WalletRepository) ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to begin transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, daily_spent, daily_reset_at, monthly_spent, monthly_reset_at
+		FROM wallet_limits
+		WHERE daily_reset_at <= $1 OR monthly_reset_at <= $1
+		ORDER BY daily_reset_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, batchSize)
+	if err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to find expired wallet limits")
+	}
+
+	var expired []*models.WalletLimits
+	for rows.Next() {
+		limits := &models.WalletLimits{}
+		if err := rows.Scan(&limits.ID, &limits.DailySpent, &limits.DailyResetAt, &limits.MonthlySpent, &limits.MonthlyResetAt); err != nil {
+			_ = rows.Close()
+			return 0, errors.DatabaseWrap(err, "failed to scan wallet limits")
+		}
+		expired = append(expired, limits)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to iterate wallet limits")
+	}
+	_ = rows.Close()
+
+	for _, limits := range expired {
+		limits.Roll(now, r.limitsLoc)
+		_, err := tx.ExecContext(ctx, `
+			UPDATE wallet_limits
+			SET daily_spent = $1, daily_reset_at = $2,
+			    monthly_spent = $3, monthly_reset_at = $4,
+			    updated_at = NOW()
+			WHERE id = $5
+		`, limits.DailySpent, limits.DailyResetAt.Time, limits.MonthlySpent, limits.MonthlyResetAt.Time, limits.ID)
+		if err != nil {
+			return 0, errors.DatabaseWrap(err, "failed to reset wallet limits")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.DatabaseWrap(err, "failed to commit limit resets")
+	}
+	return len(expired), nil
+}
+
 // UpdateBalance updates a wallet's balance by adding the specified amount (for deposits).
 // Note: This is not idempotent. Use ProcessDepositWithinTx for idempotent deposits.
 func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID string, amount int64) *errors.Error {
@@ -673,6 +903,42 @@ func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID string, a
 	return nil
 }
 
+// IsTransferProcessed reports whether the transfer of transactionID has moved
+// wallet balances.
+func (r *WalletRepository) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	var processed bool
+	query := `SELECT EXISTS (SELECT 1 FROM processed_transfers WHERE transaction_id = $1)`
+	if err := r.db.QueryRowContext(ctx, query, transactionID).Scan(&processed); err != nil {
+		return false, errors.DatabaseWrap(err, "failed to check processed transfer")
+	}
+	return processed, nil
+}
+
+// UpdateLedgerAccount points a wallet at a new ledger account, provided it
+// still points at fromAccountID.
+func (r *WalletRepository) UpdateLedgerAccount(ctx context.Context, walletID, fromAccountID, toAccountID string) *errors.Error {
+	query := `
+		UPDATE wallets
+		SET ledger_account_id = $3, updated_at = NOW()
+		WHERE id = $1 AND ledger_account_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, walletID, fromAccountID, toAccountID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to update wallet ledger account")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to get affected rows")
+	}
+	if rows == 0 {
+		return errors.Conflict("wallet not found or its ledger account changed")
+	}
+
+	return nil
+}
+
 // ProcessDepositWithinTx processes a deposit atomically within a transaction with idempotency.
 // The transactionID is used for idempotency - if this deposit has already been processed,
 // the function returns success without re-executing the deposit.
@@ -723,7 +989,7 @@ func (r *WalletRepository) ProcessDepositWithinTx(ctx context.Context, walletID
 	}
 
 	if walletStatus != string(models.WalletStatusActive) {
-		return errors.BadRequest("wallet is not active")
+		return errors.New(errors.ErrCodeWalletNotActive, "wallet is not active").AddDetail("status", walletStatus)
 	}
 
 	// 3. Update wallet balance (credit)
@@ -32,6 +32,7 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	// Permission middleware
 	createWalletPerm := middleware.RequirePermission("wallet:wallet:create")
 	readWalletPerm := middleware.RequirePermission("wallet:wallet:read")
+	listWalletsPerm := middleware.RequirePermission("wallet:wallet:list")
 	manageWalletPerm := middleware.RequireAnyPermission("wallet:wallet:activate", "wallet:wallet:freeze", "wallet:wallet:close")
 
 	// ========================================================================
@@ -41,12 +42,31 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	// Wallet CRUD operations
 	mux.Handle("POST /api/v1/wallets", authMiddleware(createWalletPerm(http.HandlerFunc(walletHandler.CreateWallet))))
 	mux.Handle("GET /api/v1/wallets/{id}", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.GetWallet))))
+	// Nickname, color, tags, metadata and default flag (wallet admins, checked by the service)
+	mux.Handle("PATCH /api/v1/wallets/{id}", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.UpdateWallet))))
 	mux.Handle("GET /api/v1/wallets/{id}/balance", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.GetWalletBalance))))
 
-	// Wallet limits endpoints (users can read and update their own limits)
+	// Wallet limits endpoints (owners can read limits, admins can update them)
 	mux.Handle("GET /api/v1/wallets/{id}/limits", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.GetWalletLimits))))
+	mux.Handle("GET /api/v1/wallets/{id}/limits/remaining", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.GetRemainingLimits))))
 	mux.Handle("PUT /api/v1/wallets/{id}/limits", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.UpdateWalletLimits))))
 
+	// Joint wallet co-owners (per-wallet permissions are checked by the service)
+	mux.Handle("GET /api/v1/wallets/{id}/owners", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ListOwners))))
+	mux.Handle("POST /api/v1/wallets/{id}/owners/invites", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.InviteCoOwner))))
+	mux.Handle("POST /api/v1/wallets/{id}/owners/invite/accept", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.AcceptInvite))))
+	mux.Handle("POST /api/v1/wallets/{id}/owners/invite/decline", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.DeclineInvite))))
+	mux.Handle("PUT /api/v1/wallets/{id}/owners/{userId}", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.UpdateCoOwner))))
+	mux.Handle("DELETE /api/v1/wallets/{id}/owners/{userId}", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.RemoveCoOwner))))
+	mux.Handle("GET /api/v1/wallet-invites", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ListInvites))))
+
+	// Scheduled closure: settles holds and sweeps the balance before closing
+	// (wallet admins, checked by the service)
+	mux.Handle("POST /api/v1/wallets/{id}/closure", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ScheduleClosure))))
+	mux.Handle("GET /api/v1/wallets/{id}/closure", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.GetClosure))))
+	mux.Handle("DELETE /api/v1/wallets/{id}/closure", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.CancelClosure))))
+	mux.Handle("POST /api/v1/wallets/{id}/reopen", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ReopenWallet))))
+
 	// Wallet status management (admin/support operations)
 	mux.Handle("POST /api/v1/wallets/{id}/activate", authMiddleware(manageWalletPerm(http.HandlerFunc(walletHandler.ActivateWallet))))
 	mux.Handle("POST /api/v1/wallets/{id}/freeze", authMiddleware(manageWalletPerm(http.HandlerFunc(walletHandler.FreezeWallet))))
@@ -56,6 +76,15 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	// User wallets listing
 	mux.Handle("GET /api/v1/users/{userId}/wallets", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ListUserWallets))))
 
+	// Back-office listing across all users with filters, sorting and pagination
+	mux.Handle("GET /api/v1/admin/wallets", authMiddleware(listWalletsPerm(http.HandlerFunc(walletHandler.ListAllWallets))))
+
+	// Provision ledger accounts for wallets missing theirs
+	mux.Handle("POST /api/v1/admin/wallets/ledger-accounts/backfill", authMiddleware(manageWalletPerm(http.HandlerFunc(walletHandler.BackfillLedgerAccounts))))
+
+	// Latest wallet to ledger balance reconciliation report
+	mux.Handle("GET /api/v1/admin/wallets/reconciliation", authMiddleware(listWalletsPerm(http.HandlerFunc(walletHandler.GetReconciliationReport))))
+
 	// List wallets for authenticated user (convenience endpoint)
 	mux.Handle("GET /api/v1/wallets", authMiddleware(readWalletPerm(http.HandlerFunc(walletHandler.ListMyWallets))))
 
@@ -76,13 +105,45 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	// Process wallet transfer (called by transaction service)
 	mux.HandleFunc("POST /internal/v1/wallets/transfer",
 		middleware.InternalAuthFunc(internalSecret, walletHandler.ProcessTransfer))
+	mux.HandleFunc("GET /internal/v1/wallets/transfers/{transactionId}",
+		middleware.InternalAuthFunc(internalSecret, walletHandler.GetTransferStatus))
 	mux.HandleFunc("POST /internal/v1/wallets/deposit",
 		middleware.InternalAuthFunc(internalSecret, walletHandler.ProcessDeposit))
+	mux.HandleFunc("POST /internal/v1/wallets/closure-sweeps",
+		middleware.InternalAuthFunc(internalSecret, walletHandler.ExecuteClosureSweep))
 	mux.HandleFunc("GET /internal/v1/wallets/{id}/info",
 		middleware.InternalAuthFunc(internalSecret, walletHandler.GetWalletInfo))
+	mux.HandleFunc("GET /internal/v1/wallets/{id}/limits",
+		middleware.InternalAuthFunc(internalSecret, walletHandler.GetWalletLimitsInternal))
+	// Beneficiary cooling-off cap (called by transaction service on transfer creation)
+	mux.HandleFunc("POST /internal/v1/beneficiaries/cooling-off/reserve",
+		middleware.InternalAuthFunc(internalSecret, beneficiaryHandler.ReserveCoolingOff))
+	mux.HandleFunc("POST /internal/v1/beneficiaries/cooling-off/release",
+		middleware.InternalAuthFunc(internalSecret, beneficiaryHandler.ReleaseCoolingOff))
 	// Create wallet (called by identity service during user registration)
 	mux.HandleFunc("POST /internal/v1/wallets",
 		middleware.InternalAuthFunc(internalSecret, walletHandler.CreateWalletInternal))
+	// Balance skews for simulation anomalies (disabled in production)
+	mux.HandleFunc("POST /internal/v1/simulation/balance-skews",
+		middleware.InternalAuthFunc(internalSecret, walletHandler.SkewBalance))
+	mux.HandleFunc("POST /internal/v1/simulation/balance-skews/restore",
+		middleware.InternalAuthFunc(internalSecret, walletHandler.RestoreBalance))
+
+	// Card authorization (called by the card network)
+	mux.HandleFunc("POST /internal/v1/cards/{id}/authorize",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.AuthorizeCard))
+
+	// Card clearing (called by the card network)
+	mux.HandleFunc("GET /internal/v1/cards/network",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.ListNetworkCards))
+	mux.HandleFunc("GET /internal/v1/cards/authorizations/{id}",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.GetCardAuthorization))
+	mux.HandleFunc("POST /internal/v1/cards/authorizations/{id}/settle",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.SettleCardAuthorization))
+	mux.HandleFunc("POST /internal/v1/cards/authorizations/{id}/reverse",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.ReverseCardAuthorization))
+	mux.HandleFunc("POST /internal/v1/cards/authorizations/{id}/refund",
+		middleware.InternalAuthFunc(internalSecret, cardHandler.RefundCardAuthorization))
 
 	// ========================================================================
 	// Beneficiary Management Endpoints
@@ -106,6 +167,16 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	mux.Handle("DELETE /api/v1/beneficiaries/{id}",
 		beneficiaryRateLimit(authMiddleware(manageBeneficiaryPerm(http.HandlerFunc(beneficiaryHandler.DeleteBeneficiary)))))
 
+	// CSV import/export (each import row is looked up like an added beneficiary)
+	mux.Handle("GET /api/v1/beneficiaries/export",
+		authMiddleware(manageBeneficiaryPerm(http.HandlerFunc(beneficiaryHandler.ExportBeneficiaries))))
+	mux.Handle("POST /api/v1/beneficiaries/import",
+		beneficiaryRateLimit(authMiddleware(manageBeneficiaryPerm(http.HandlerFunc(beneficiaryHandler.ImportBeneficiaries)))))
+
+	// Lift a new beneficiary's cooling-off period (requires a verification token)
+	mux.Handle("POST /api/v1/beneficiaries/{id}/cooling-off/override",
+		beneficiaryRateLimit(authMiddleware(manageBeneficiaryPerm(http.HandlerFunc(beneficiaryHandler.OverrideCoolingOff)))))
+
 	// ========================================================================
 	// Virtual Card Management Endpoints
 	// ========================================================================
@@ -137,6 +208,14 @@ func SetupRoutes(walletHandler *handler.WalletHandler, beneficiaryHandler *handl
 	mux.Handle("GET /api/v1/cards/{id}/reveal",
 		beneficiaryRateLimit(authMiddleware(manageCardPerm(http.HandlerFunc(cardHandler.RevealCardDetails)))))
 
+	// Card auto-freeze rules (per wallet)
+	mux.Handle("GET /api/v1/wallets/{walletId}/cards/auto-freeze",
+		authMiddleware(manageCardPerm(http.HandlerFunc(cardHandler.GetAutoFreezeRule))))
+	mux.Handle("PUT /api/v1/wallets/{walletId}/cards/auto-freeze",
+		authMiddleware(manageCardPerm(http.HandlerFunc(cardHandler.SetAutoFreezeRule))))
+	mux.Handle("DELETE /api/v1/wallets/{walletId}/cards/auto-freeze",
+		authMiddleware(manageCardPerm(http.HandlerFunc(cardHandler.DeleteAutoFreezeRule))))
+
 	// Apply middleware chain
 	metricsCollector := metrics.NewCollector("wallet")
 	handler := metricsCollector.Middleware("wallet")(mux)
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// balanceSkewCandidates is how many recently updated wallets are checked for
+// one whose balance agrees with its ledger account.
+const balanceSkewCandidates = 50
+
+// BalanceSkewRepositoryInterface skews and restores wallet balances.
+type BalanceSkewRepositoryInterface interface {
+	Skew(ctx context.Context, walletID string, amount int64) *errors.Error
+	Restore(ctx context.Context, walletID string, amount int64) (bool, *errors.Error)
+}
+
+// SetBalanceSkews enables balance skews for simulation anomalies. They
+// corrupt balances on purpose, so they must only be enabled outside
+// production.
+func (s *WalletService) SetBalanceSkews(repo BalanceSkewRepositoryInterface) {
+	s.balanceSkews = repo
+}
+
+// SkewBalance raises the balance of an active wallet that currently agrees
+// with its ledger account, without a ledger posting, so any mismatch found
+// afterwards is attributable to the skew.
+func (s *WalletService) SkewBalance(ctx context.Context, amount int64) (*models.BalanceSkew, *errors.Error) {
+	if s.balanceSkews == nil {
+		return nil, errors.Forbidden("balance skews are disabled")
+	}
+	if s.ledgerClient == nil {
+		return nil, errors.Unavailable("ledger service is not configured")
+	}
+
+	status := models.WalletStatusActive
+	wallets, _, err := s.walletRepo.Search(ctx, &models.WalletFilter{Status: &status, SortBy: models.WalletSortUpdatedAt, Limit: balanceSkewCandidates})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wallet := range wallets {
+		account, lookupErr := s.ledgerClient.GetAccount(ctx, wallet.LedgerAccountID)
+		if lookupErr != nil || account == nil || account.Balance != wallet.Balance {
+			continue
+		}
+		if skewErr := s.balanceSkews.Skew(ctx, wallet.ID, amount); skewErr != nil {
+			return nil, skewErr
+		}
+		log.Printf("[wallet] Skewed wallet %s balance by %d for a simulation anomaly", wallet.ID, amount)
+		return &models.BalanceSkew{WalletID: wallet.ID, Amount: amount}, nil
+	}
+	return nil, errors.NotFound("active wallet in agreement with its ledger account")
+}
+
+// RestoreBalance takes a skew back off a wallet's balance.
+func (s *WalletService) RestoreBalance(ctx context.Context, skew *models.BalanceSkew) *errors.Error {
+	if s.balanceSkews == nil {
+		return errors.Forbidden("balance skews are disabled")
+	}
+
+	restored, err := s.balanceSkews.Restore(ctx, skew.WalletID, skew.Amount)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return errors.Conflict(fmt.Sprintf("wallet %s balance no longer covers the %d paise skew", skew.WalletID, skew.Amount))
+	}
+	log.Printf("[wallet] Restored wallet %s balance after a simulation anomaly", skew.WalletID)
+	return nil
+}
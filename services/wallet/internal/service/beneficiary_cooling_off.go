@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OpCoolingOffOverride is the identity verification operation that lifts a
+// beneficiary's cooling-off period.
+const OpCoolingOffOverride = "beneficiary_cooling_off_override"
+
+// DefaultCoolingOffPolicy returns the default beneficiary cooling-off policy.
+func DefaultCoolingOffPolicy() models.CoolingOffPolicy {
+	return models.CoolingOffPolicy{
+		Period: 24 * time.Hour,
+		Limit:  500000, // ₹5,000
+	}
+}
+
+// verificationClaims are the claims of an identity verification token.
+type verificationClaims struct {
+	VerificationID string                 `json:"verification_id"`
+	UserID         string                 `json:"user_id"`
+	OperationType  string                 `json:"operation_type"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// SetCoolingOff enables the cooling-off period: beneficiaries added from now
+// on can receive at most the policy's limit until its period has passed,
+// unless the owner lifts it with a step-up verification. Verification tokens
+// are issued by identity and signed with verificationSecret.
+func (s *BeneficiaryService) SetCoolingOff(policy models.CoolingOffPolicy, verificationSecret string) {
+	s.coolingOff = policy
+	s.verificationSecret = []byte(verificationSecret)
+}
+
+// CoolingOffEnabled returns true if new beneficiaries get a cooling-off period.
+func (s *BeneficiaryService) CoolingOffEnabled() bool {
+	return s.coolingOff.Period > 0
+}
+
+// ReserveCoolingOff checks a transfer against the cooling-off cap of the
+// destination beneficiary and reserves its amount. Transfers to wallets that
+// are not a beneficiary of the owner, or whose cooling-off has passed, are not
+// capped. The transaction service releases the amount if the transfer fails.
+func (s *BeneficiaryService) ReserveCoolingOff(ctx context.Context, req *models.CoolingOffCheckRequest) *errors.Error {
+	if !s.CoolingOffEnabled() {
+		return nil
+	}
+
+	beneficiary, err := s.beneficiaryRepo.GetByWallet(ctx, req.OwnerUserID, req.DestinationWalletID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+	if !beneficiary.InCoolingOff(time.Now()) {
+		return nil
+	}
+
+	_, ok, err := s.beneficiaryRepo.ReserveCoolingOff(ctx, beneficiary.ID, req.Amount, s.coolingOff.Limit)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		remaining := max(s.coolingOff.Limit-beneficiary.CoolingOffSent, 0)
+		return errors.New(errors.ErrCodeLimitCoolingOff, fmt.Sprintf("%s was added recently and can receive up to ₹%.2f more until %s",
+			beneficiary.Nickname, float64(remaining)/100, beneficiary.CoolingOffUntil.Format(time.RFC3339))).
+			AddDetail("remaining", remaining).
+			AddDetail("beneficiary_id", beneficiary.ID).
+			AddDetail("cooling_off_until", beneficiary.CoolingOffUntil)
+	}
+
+	return nil
+}
+
+// ReleaseCoolingOff gives back a reservation made by ReserveCoolingOff for a
+// transfer that failed. Releasing for a destination that would not have been
+// capped is a no-op.
+func (s *BeneficiaryService) ReleaseCoolingOff(ctx context.Context, req *models.CoolingOffCheckRequest) *errors.Error {
+	if !s.CoolingOffEnabled() {
+		return nil
+	}
+
+	beneficiary, err := s.beneficiaryRepo.GetByWallet(ctx, req.OwnerUserID, req.DestinationWalletID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil
+		}
+		return err
+	}
+	if !beneficiary.InCoolingOff(time.Now()) {
+		return nil
+	}
+
+	return s.beneficiaryRepo.ReleaseCoolingOff(ctx, beneficiary.ID, req.Amount)
+}
+
+// OverrideCoolingOff lifts a beneficiary's cooling-off period. The token must
+// come from a verification of the beneficiary_cooling_off_override operation
+// by the owner, with the beneficiary's ID as metadata.beneficiary_id.
+func (s *BeneficiaryService) OverrideCoolingOff(ctx context.Context, ownerUserID, beneficiaryID string, req *models.CoolingOffOverrideRequest) (*models.Beneficiary, *errors.Error) {
+	beneficiary, err := s.beneficiaryRepo.GetByID(ctx, beneficiaryID, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+	if !beneficiary.InCoolingOff(time.Now()) {
+		return nil, errors.BadRequest("beneficiary is not in a cooling-off period")
+	}
+
+	claims, err := s.validateVerificationToken(req.VerificationToken, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+	if id, _ := claims.Metadata["beneficiary_id"].(string); id != beneficiaryID {
+		return nil, errors.Forbidden("verification token is for a different beneficiary")
+	}
+
+	if err := s.beneficiaryRepo.OverrideCoolingOff(ctx, beneficiaryID, ownerUserID, claims.VerificationID); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.beneficiaryRepo.GetByID(ctx, beneficiaryID, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.eventPublisher != nil {
+		s.eventPublisher.PublishWalletEvent("beneficiary.cooling_off_overridden", beneficiaryID, map[string]interface{}{
+			"owner_user_id":   ownerUserID,
+			"verification_id": claims.VerificationID,
+			"sent":            beneficiary.CoolingOffSent,
+		})
+	}
+
+	return updated, nil
+}
+
+// validateVerificationToken validates an identity verification token for the
+// cooling-off override by the given user.
+func (s *BeneficiaryService) validateVerificationToken(tokenString, expectedUserID string) (*verificationClaims, *errors.Error) {
+	if len(s.verificationSecret) == 0 {
+		return nil, errors.Unavailable("step-up verification is not configured")
+	}
+
+	token, parseErr := jwt.ParseWithClaims(tokenString, &verificationClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.verificationSecret, nil
+	})
+	if parseErr != nil || !token.Valid {
+		return nil, errors.Unauthorized("invalid or expired verification token")
+	}
+
+	claims, ok := token.Claims.(*verificationClaims)
+	if !ok || claims.VerificationID == "" {
+		return nil, errors.Unauthorized("invalid verification token claims")
+	}
+	if claims.OperationType != OpCoolingOffOverride {
+		return nil, errors.Forbidden("verification token is for different operation")
+	}
+	if claims.UserID != expectedUserID {
+		return nil, errors.Forbidden("verification token belongs to different user")
+	}
+
+	return claims, nil
+}
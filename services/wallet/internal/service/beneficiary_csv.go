@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// beneficiaryCSVColumns are the columns of a beneficiary export. Imports need
+// phone and nickname and ignore the rest, so an export can be imported as is.
+var beneficiaryCSVColumns = []string{"nickname", "phone", "wallet_id", "added_at"}
+
+// ExportBeneficiaries returns the user's beneficiaries as CSV.
+func (s *BeneficiaryService) ExportBeneficiaries(ctx context.Context, ownerUserID string) ([]byte, *errors.Error) {
+	beneficiaries, err := s.beneficiaryRepo.ListByOwner(ctx, ownerUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write(beneficiaryCSVColumns)
+	for _, b := range beneficiaries {
+		_ = writer.Write([]string{
+			csvSafe(b.Nickname),
+			b.BeneficiaryPhone,
+			b.BeneficiaryWalletID,
+			b.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if writeErr := writer.Error(); writeErr != nil {
+		return nil, errors.Internal("failed to write beneficiaries")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ImportBeneficiaries adds a beneficiary for each row of a CSV with phone and
+// nickname columns. Rows are added independently: a row that fails, such as
+// an unknown phone or an existing beneficiary, is reported and the rest are
+// still added. Imported beneficiaries get the cooling-off period like any
+// other new beneficiary.
+func (s *BeneficiaryService) ImportBeneficiaries(ctx context.Context, ownerUserID string, data []byte) (*models.BeneficiaryImportResult, *errors.Error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, readErr := reader.Read()
+	if readErr != nil {
+		return nil, errors.Validation("import must be CSV with a header row")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range []string{"phone", "nickname"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Validation("csv is missing column " + name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i := columns[name]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []models.BeneficiaryImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Validation(fmt.Sprintf("invalid csv: %v", err))
+		}
+		if len(rows) == models.MaxBeneficiaryImportRows {
+			return nil, errors.Validation(fmt.Sprintf("import has more than %d rows", models.MaxBeneficiaryImportRows))
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, models.BeneficiaryImportRow{
+			Line:     line,
+			Phone:    field(record, "phone"),
+			Nickname: field(record, "nickname"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.Validation("import has no rows")
+	}
+
+	result := &models.BeneficiaryImportResult{Rows: rows}
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		if msg := validateImportRow(row); msg != "" {
+			row.Error = msg
+			result.Failed++
+			continue
+		}
+
+		beneficiary, err := s.AddBeneficiary(ctx, ownerUserID, &models.AddBeneficiaryRequest{Phone: row.Phone, Nickname: row.Nickname})
+		if err != nil {
+			row.Error = err.Message
+			result.Failed++
+			continue
+		}
+		row.BeneficiaryID = beneficiary.ID
+		result.Added++
+	}
+
+	return result, nil
+}
+
+// validateImportRow checks what AddBeneficiaryRequest validation checks for
+// JSON requests, returning an error message or "".
+func validateImportRow(row *models.BeneficiaryImportRow) string {
+	switch {
+	case row.Phone == "":
+		return "phone is required"
+	case !isE164(row.Phone):
+		return "phone must be in E.164 format, e.g. +919876543210"
+	case row.Nickname == "":
+		return "nickname is required"
+	case utf8.RuneCountInString(row.Nickname) > 100:
+		return "nickname must be at most 100 characters"
+	}
+	return ""
+}
+
+// isE164 reports whether phone is an E.164 number: + and up to 15 digits.
+func isE164(phone string) bool {
+	if len(phone) < 3 || len(phone) > 16 || phone[0] != '+' || phone[1] == '0' {
+		return false
+	}
+	for _, c := range phone[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// csvSafe keeps a user-chosen value from being read as a formula when the
+// export is opened in a spreadsheet.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
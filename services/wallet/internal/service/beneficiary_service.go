@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/events"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 )
 
 // BeneficiaryRepositoryInterface defines the interface for beneficiary repository operations.
@@ -17,6 +19,10 @@ type BeneficiaryRepositoryInterface interface {
 	UpdateNickname(ctx context.Context, id, ownerUserID, nickname string) *errors.Error
 	Delete(ctx context.Context, id, ownerUserID string) *errors.Error
 	GetByBeneficiaryUser(ctx context.Context, ownerUserID, beneficiaryUserID string) (*models.Beneficiary, *errors.Error)
+	GetByWallet(ctx context.Context, ownerUserID, walletID string) (*models.Beneficiary, *errors.Error)
+	ReserveCoolingOff(ctx context.Context, id string, amount, limit int64) (int64, bool, *errors.Error)
+	ReleaseCoolingOff(ctx context.Context, id string, amount int64) *errors.Error
+	OverrideCoolingOff(ctx context.Context, id, ownerUserID, verificationID string) *errors.Error
 }
 
 // UserLookupClient defines the interface for looking up users from the identity service.
@@ -39,6 +45,9 @@ type BeneficiaryService struct {
 	walletRepo      WalletRepositoryInterface
 	userClient      UserLookupClient
 	eventPublisher  *events.Publisher
+
+	coolingOff         models.CoolingOffPolicy
+	verificationSecret []byte
 }
 
 // NewBeneficiaryService creates a new beneficiary service.
@@ -112,6 +121,10 @@ func (s *BeneficiaryService) AddBeneficiary(ctx context.Context, ownerUserID str
 		BeneficiaryPhone:    userInfo.Phone,
 		Metadata:            make(map[string]string),
 	}
+	if s.CoolingOffEnabled() {
+		until := sharedModels.NewTimestamp(time.Now().Add(s.coolingOff.Period))
+		beneficiary.CoolingOffUntil = &until
+	}
 
 	if createErr := s.beneficiaryRepo.Create(ctx, beneficiary); createErr != nil {
 		return nil, createErr
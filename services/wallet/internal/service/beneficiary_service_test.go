@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // Mock implementations for testing
 
 type mockBeneficiaryRepository struct {
 	beneficiaries map[string]*models.Beneficiary
+	overrides     []string
 }
 
 func newMockBeneficiaryRepository() *mockBeneficiaryRepository {
@@ -31,7 +37,7 @@ func (m *mockBeneficiaryRepository) Create(ctx context.Context, beneficiary *mod
 		}
 	}
 
-	beneficiary.ID = "ben-123"
+	beneficiary.ID = fmt.Sprintf("ben-%d", 123+len(m.beneficiaries))
 	m.beneficiaries[beneficiary.ID] = beneficiary
 	return nil
 }
@@ -81,6 +87,47 @@ func (m *mockBeneficiaryRepository) GetByBeneficiaryUser(ctx context.Context, ow
 	return nil, errors.NotFound("beneficiary not found")
 }
 
+func (m *mockBeneficiaryRepository) GetByWallet(ctx context.Context, ownerUserID, walletID string) (*models.Beneficiary, *errors.Error) {
+	for _, b := range m.beneficiaries {
+		if b.OwnerUserID == ownerUserID && b.BeneficiaryWalletID == walletID {
+			return b, nil
+		}
+	}
+	return nil, errors.NotFound("beneficiary not found")
+}
+
+func (m *mockBeneficiaryRepository) ReserveCoolingOff(ctx context.Context, id string, amount, limit int64) (int64, bool, *errors.Error) {
+	b, ok := m.beneficiaries[id]
+	if !ok || b.CoolingOffSent+amount > limit {
+		return 0, false, nil
+	}
+	b.CoolingOffSent += amount
+	return b.CoolingOffSent, true, nil
+}
+
+func (m *mockBeneficiaryRepository) ReleaseCoolingOff(ctx context.Context, id string, amount int64) *errors.Error {
+	if b, ok := m.beneficiaries[id]; ok {
+		b.CoolingOffSent = max(b.CoolingOffSent-amount, 0)
+	}
+	return nil
+}
+
+func (m *mockBeneficiaryRepository) OverrideCoolingOff(ctx context.Context, id, ownerUserID, verificationID string) *errors.Error {
+	b, ok := m.beneficiaries[id]
+	if !ok || b.OwnerUserID != ownerUserID {
+		return errors.NotFoundWithID("beneficiary", id)
+	}
+	for _, used := range m.overrides {
+		if used == verificationID {
+			return errors.Conflict("verification token was already used")
+		}
+	}
+	m.overrides = append(m.overrides, verificationID)
+	now := sharedModels.Now()
+	b.CoolingOffOverriddenAt = &now
+	return nil
+}
+
 type mockUserClient struct {
 	users map[string]*UserInfo
 }
@@ -136,6 +183,10 @@ func (m *mockWalletRepoForBeneficiary) GetByID(ctx context.Context, id string) (
 	return wallet, nil
 }
 
+func (m *mockWalletRepoForBeneficiary) Search(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+	return nil, 0, nil
+}
+
 func (m *mockWalletRepoForBeneficiary) ListByUserID(ctx context.Context, userID string, status *models.WalletStatus) ([]*models.Wallet, *errors.Error) {
 	result := make([]*models.Wallet, 0)
 	for _, w := range m.wallets {
@@ -168,18 +219,34 @@ func (m *mockWalletRepoForBeneficiary) UpdateLimits(ctx context.Context, walletI
 	return nil
 }
 
+func (m *mockWalletRepoForBeneficiary) ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error) {
+	return 0, nil
+}
+
 func (m *mockWalletRepoForBeneficiary) ProcessTransferWithinTx(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error {
 	return nil
 }
 
+func (m *mockWalletRepoForBeneficiary) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	return false, nil
+}
+
 func (m *mockWalletRepoForBeneficiary) ProcessDepositWithinTx(ctx context.Context, walletID string, amount int64, transactionID string) *errors.Error {
 	return nil
 }
 
+func (m *mockWalletRepoForBeneficiary) UpdateProfile(ctx context.Context, id string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error) {
+	return m.GetByID(ctx, id)
+}
+
 func (m *mockWalletRepoForBeneficiary) UpdateBalance(ctx context.Context, walletID string, amount int64) *errors.Error {
 	return nil
 }
 
+func (m *mockWalletRepoForBeneficiary) UpdateLedgerAccount(ctx context.Context, walletID, fromAccountID, toAccountID string) *errors.Error {
+	return nil
+}
+
 // Test cases
 
 func TestAddBeneficiary_Success(t *testing.T) {
@@ -371,3 +438,235 @@ func TestUpdateBeneficiary_Success(t *testing.T) {
 		t.Errorf("Expected nickname 'Johnny', got '%s'", updated.Nickname)
 	}
 }
+
+const testVerificationSecret = "test-verification-secret"
+
+func newCoolingOffTestService() (*BeneficiaryService, *mockBeneficiaryRepository) {
+	beneficiaryRepo := newMockBeneficiaryRepository()
+	service := NewBeneficiaryService(beneficiaryRepo, newMockWalletRepoForBeneficiary(), newMockUserClient(), nil)
+	service.SetCoolingOff(models.CoolingOffPolicy{Period: 24 * time.Hour, Limit: 500000}, testVerificationSecret)
+	return service, beneficiaryRepo
+}
+
+func signVerificationToken(t *testing.T, claims verificationClaims) string {
+	t.Helper()
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(5 * time.Minute))
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testVerificationSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestAddBeneficiary_StartsCoolingOff(t *testing.T) {
+	service, _ := newCoolingOffTestService()
+
+	beneficiary, err := service.AddBeneficiary(context.Background(), "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "John"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !beneficiary.InCoolingOff(time.Now()) || beneficiary.InCoolingOff(time.Now().Add(25*time.Hour)) {
+		t.Errorf("Expected a 24 hour cooling-off period, got until %v", beneficiary.CoolingOffUntil)
+	}
+
+	// Disabled by default
+	plain := NewBeneficiaryService(newMockBeneficiaryRepository(), newMockWalletRepoForBeneficiary(), newMockUserClient(), nil)
+	beneficiary, _ = plain.AddBeneficiary(context.Background(), "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "John"})
+	if beneficiary.CoolingOffUntil != nil {
+		t.Errorf("Expected no cooling-off period when disabled")
+	}
+}
+
+func TestReserveCoolingOff(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCoolingOffTestService()
+	beneficiary, _ := service.AddBeneficiary(ctx, "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "John"})
+
+	reserve := func(owner string, amount int64) *errors.Error {
+		return service.ReserveCoolingOff(ctx, &models.CoolingOffCheckRequest{OwnerUserID: owner, DestinationWalletID: "wallet-2", Amount: amount})
+	}
+
+	if err := reserve("user-1", 300000); err != nil {
+		t.Fatalf("Expected transfer within the cap to pass, got %v", err)
+	}
+	err := reserve("user-1", 300000)
+	if err == nil || err.Code != errors.ErrCodeLimitCoolingOff {
+		t.Fatalf("Expected cooling-off limit error, got %v", err)
+	}
+	if remaining := err.Details["remaining"]; remaining != int64(200000) {
+		t.Errorf("Expected 200000 remaining, got %v", remaining)
+	}
+	if err := reserve("user-1", 200000); err != nil {
+		t.Fatalf("Expected the rest of the cap to pass, got %v", err)
+	}
+
+	// Other senders are not capped by user-1's beneficiary
+	if err := reserve("user-3", 5000000); err != nil {
+		t.Errorf("Expected transfer from a user without the beneficiary to pass, got %v", err)
+	}
+
+	// Nor is the beneficiary once its cooling-off period has passed
+	past := sharedModels.NewTimestamp(time.Now().Add(-time.Minute))
+	repo.beneficiaries[beneficiary.ID].CoolingOffUntil = &past
+	if err := reserve("user-1", 5000000); err != nil {
+		t.Errorf("Expected transfer after the cooling-off period to pass, got %v", err)
+	}
+}
+
+func TestReleaseCoolingOff(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCoolingOffTestService()
+	beneficiary, _ := service.AddBeneficiary(ctx, "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "John"})
+	check := func(amount int64) *models.CoolingOffCheckRequest {
+		return &models.CoolingOffCheckRequest{OwnerUserID: "user-1", DestinationWalletID: "wallet-2", Amount: amount}
+	}
+
+	if err := service.ReserveCoolingOff(ctx, check(500000)); err != nil {
+		t.Fatalf("Expected transfer within the cap to pass, got %v", err)
+	}
+	if err := service.ReserveCoolingOff(ctx, check(100000)); err == nil || err.Code != errors.ErrCodeLimitCoolingOff {
+		t.Fatalf("Expected the cap to be used up, got %v", err)
+	}
+
+	// A failed transfer gives its reservation back
+	if err := service.ReleaseCoolingOff(ctx, check(300000)); err != nil {
+		t.Fatalf("Expected release to succeed, got %v", err)
+	}
+	if sent := repo.beneficiaries[beneficiary.ID].CoolingOffSent; sent != 200000 {
+		t.Errorf("Expected 200000 still reserved, got %d", sent)
+	}
+	if err := service.ReserveCoolingOff(ctx, check(300000)); err != nil {
+		t.Errorf("Expected the released amount to be usable again, got %v", err)
+	}
+
+	// Releasing for a wallet that is not a beneficiary is a no-op
+	if err := service.ReleaseCoolingOff(ctx, &models.CoolingOffCheckRequest{OwnerUserID: "user-3", DestinationWalletID: "wallet-2", Amount: 300000}); err != nil {
+		t.Errorf("Expected release for another user to be a no-op, got %v", err)
+	}
+	if sent := repo.beneficiaries[beneficiary.ID].CoolingOffSent; sent != 500000 {
+		t.Errorf("Expected 500000 reserved, got %d", sent)
+	}
+}
+
+func TestOverrideCoolingOff(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*BeneficiaryService, *models.Beneficiary) {
+		service, _ := newCoolingOffTestService()
+		beneficiary, _ := service.AddBeneficiary(ctx, "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "John"})
+		return service, beneficiary
+	}
+	claimsFor := func(beneficiaryID string) verificationClaims {
+		return verificationClaims{
+			VerificationID: "ver-1",
+			UserID:         "user-1",
+			OperationType:  OpCoolingOffOverride,
+			Metadata:       map[string]interface{}{"beneficiary_id": beneficiaryID},
+		}
+	}
+
+	t.Run("lifts the cap", func(t *testing.T) {
+		service, beneficiary := setup()
+		token := signVerificationToken(t, claimsFor(beneficiary.ID))
+
+		updated, err := service.OverrideCoolingOff(ctx, "user-1", beneficiary.ID, &models.CoolingOffOverrideRequest{VerificationToken: token})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.InCoolingOff(time.Now()) {
+			t.Error("Expected the cooling-off period to be lifted")
+		}
+		if err := service.ReserveCoolingOff(ctx, &models.CoolingOffCheckRequest{OwnerUserID: "user-1", DestinationWalletID: "wallet-2", Amount: 5000000}); err != nil {
+			t.Errorf("Expected uncapped transfer after override, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			mutate func(c *verificationClaims)
+			code   errors.ErrorCode
+		}{
+			{"other operation", func(c *verificationClaims) { c.OperationType = "password_change" }, errors.ErrCodeForbidden},
+			{"other user", func(c *verificationClaims) { c.UserID = "user-9" }, errors.ErrCodeForbidden},
+			{"other beneficiary", func(c *verificationClaims) { c.Metadata["beneficiary_id"] = "ben-999" }, errors.ErrCodeForbidden},
+			{"expired", func(c *verificationClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) }, errors.ErrCodeUnauthorized},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service, beneficiary := setup()
+				claims := claimsFor(beneficiary.ID)
+				tt.mutate(&claims)
+
+				_, err := service.OverrideCoolingOff(ctx, "user-1", beneficiary.ID, &models.CoolingOffOverrideRequest{VerificationToken: signVerificationToken(t, claims)})
+				if err == nil || err.Code != tt.code {
+					t.Fatalf("Expected %s, got %v", tt.code, err)
+				}
+			})
+		}
+	})
+
+	t.Run("rejects beneficiaries not in cooling-off", func(t *testing.T) {
+		service, beneficiary := setup()
+		token := signVerificationToken(t, claimsFor(beneficiary.ID))
+		if _, err := service.OverrideCoolingOff(ctx, "user-1", beneficiary.ID, &models.CoolingOffOverrideRequest{VerificationToken: token}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := service.OverrideCoolingOff(ctx, "user-1", beneficiary.ID, &models.CoolingOffOverrideRequest{VerificationToken: token}); err == nil || err.Code != errors.ErrCodeBadRequest {
+			t.Fatalf("Expected bad request on a second override, got %v", err)
+		}
+	})
+}
+
+func TestImportBeneficiaries(t *testing.T) {
+	ctx := context.Background()
+	service := NewBeneficiaryService(newMockBeneficiaryRepository(), newMockWalletRepoForBeneficiary(), newMockUserClient(), nil)
+
+	data := "phone,nickname\n+919876543210,John\n+919876543210,Johnny\n+910000000000,Nobody\n9876543210,Local\n"
+	result, err := service.ImportBeneficiaries(ctx, "user-1", []byte(data))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Added != 1 || result.Failed != 3 {
+		t.Fatalf("Expected 1 added and 3 failed, got %d and %d: %+v", result.Added, result.Failed, result.Rows)
+	}
+	if result.Rows[0].BeneficiaryID == "" || result.Rows[0].Line != 2 {
+		t.Errorf("Expected the first row on line 2 to be added, got %+v", result.Rows[0])
+	}
+	if !strings.Contains(result.Rows[1].Error, "already") {
+		t.Errorf("Expected a duplicate error, got %q", result.Rows[1].Error)
+	}
+	if !strings.Contains(result.Rows[3].Error, "E.164") {
+		t.Errorf("Expected a phone format error, got %q", result.Rows[3].Error)
+	}
+
+	if _, err := service.ImportBeneficiaries(ctx, "user-1", []byte("name,phone\nJohn,+919876543210\n")); err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("Expected validation error for a missing column, got %v", err)
+	}
+}
+
+func TestExportBeneficiaries_RoundTrips(t *testing.T) {
+	ctx := context.Background()
+	service := NewBeneficiaryService(newMockBeneficiaryRepository(), newMockWalletRepoForBeneficiary(), newMockUserClient(), nil)
+	_, _ = service.AddBeneficiary(ctx, "user-1", &models.AddBeneficiaryRequest{Phone: "+919876543210", Nickname: "=John"})
+
+	data, err := service.ExportBeneficiaries(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || lines[0] != "nickname,phone,wallet_id,added_at" {
+		t.Fatalf("Unexpected export %q", data)
+	}
+	if !strings.HasPrefix(lines[1], "'=John,+919876543210,wallet-2,") {
+		t.Errorf("Expected the nickname to be escaped, got %q", lines[1])
+	}
+
+	// The export imports into another user's beneficiaries
+	result, importErr := service.ImportBeneficiaries(ctx, "user-3", data)
+	if importErr != nil || result.Added != 1 {
+		t.Fatalf("Expected the export to import, got %v %+v", importErr, result)
+	}
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// checkCardAuthorization returns the reason an authorization must be declined,
+// or an empty string if the card, its limits and the wallet balance allow it.
+func checkCardAuthorization(card *models.VirtualCard, wallet *models.Wallet, amount int64) string {
+	if !card.IsUsable() {
+		return models.DeclineCardNotUsable
+	}
+	if wallet.Status != models.WalletStatusActive {
+		return models.DeclineWalletInactive
+	}
+	if card.PerTransactionLimit > 0 && amount > card.PerTransactionLimit {
+		return models.DeclinePerTransactionLimit
+	}
+	if card.DailyLimit > 0 && card.DailySpent+amount > card.DailyLimit {
+		return models.DeclineDailyLimit
+	}
+	if card.MonthlyLimit > 0 && card.MonthlySpent+amount > card.MonthlyLimit {
+		return models.DeclineMonthlyLimit
+	}
+	if wallet.AvailableBalance < amount {
+		return models.DeclineInsufficientFunds
+	}
+	return ""
+}
+
+// countsTowardAutoFreeze reports whether a decline suggests misuse of the
+// card. Only suspicious declines count: running out of funds or hitting a
+// spend limit is ordinary, and declines on unusable cards or wallets say
+// nothing about the card.
+func countsTowardAutoFreeze(reason string) bool {
+	return reason == models.DeclineBelowBalanceFloor
+}
+
+// AuthorizeCard decides a card authorization from the card network.
+// Approvals count toward the card's spend limits and hold the amount on the
+// wallet's available balance; funds move at settlement.
+// Auto-freeze rules are applied here: an authorization that would take the
+// wallet below its balance floor is declined and all its cards are frozen,
+// and a card with max_declines suspicious declines in a row is frozen.
+func (s *VirtualCardService) AuthorizeCard(ctx context.Context, cardID string, req *models.CardAuthorizationRequest) (*models.CardAuthorizationResult, *errors.Error) {
+	card, err := s.cardRepo.GetByID(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.walletRepo.GetByID(ctx, card.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := s.getAutoFreezeRule(ctx, card.WalletID)
+	result := &models.CardAuthorizationResult{
+		CardID: cardID,
+		Amount: req.Amount,
+	}
+
+	if reason := checkCardAuthorization(card, wallet, req.Amount); reason != "" {
+		return s.declineAuthorization(ctx, card, rule, req, result, reason)
+	}
+
+	if rule.BreachesFloor(wallet.AvailableBalance - req.Amount) {
+		return s.declineAuthorization(ctx, card, rule, req, result, models.DeclineBelowBalanceFloor)
+	}
+
+	// Hold the funds until the network settles or reverses the authorization
+	if s.clearingRepo != nil {
+		hold := &models.CardAuthorization{
+			CardID:       cardID,
+			WalletID:     card.WalletID,
+			Amount:       req.Amount,
+			MerchantName: req.MerchantName,
+		}
+		if req.MCC != "" {
+			hold.MCC = &req.MCC
+		}
+		held, holdErr := s.clearingRepo.CreateHold(ctx, hold)
+		if holdErr != nil {
+			return nil, holdErr
+		}
+		if !held {
+			return s.declineAuthorization(ctx, card, rule, req, result, models.DeclineInsufficientFunds)
+		}
+		result.AuthorizationID = hold.ID
+	}
+
+	if approveErr := s.cardRepo.RecordApproval(ctx, cardID, req.Amount); approveErr != nil {
+		return nil, approveErr
+	}
+
+	result.Approved = true
+	s.logAuthorization(card, req, result)
+	return result, nil
+}
+
+// declineAuthorization declines an authorization. A balance floor breach
+// freezes all the wallet's cards, and a card is frozen once it has more
+// suspicious declines in a row than the wallet's rule allows.
+func (s *VirtualCardService) declineAuthorization(ctx context.Context, card *models.VirtualCard, rule *models.CardAutoFreezeRule, req *models.CardAuthorizationRequest, result *models.CardAuthorizationResult, reason string) (*models.CardAuthorizationResult, *errors.Error) {
+	result.DeclineReason = reason
+	if reason == models.DeclineBelowBalanceFloor {
+		result.CardFrozen = s.freezeWalletCards(ctx, card.WalletID, card.ID)
+	}
+	if countsTowardAutoFreeze(reason) {
+		declines, declineErr := s.cardRepo.RecordDecline(ctx, card.ID)
+		if declineErr != nil {
+			return nil, declineErr
+		}
+		if !result.CardFrozen && rule.ExceedsDeclines(declines) {
+			result.CardFrozen = s.autoFreezeCard(ctx, card, models.AutoFreezeReasonDeclines)
+		}
+	}
+	s.logAuthorization(card, req, result)
+	return result, nil
+}
+
+func (s *VirtualCardService) logAuthorization(card *models.VirtualCard, req *models.CardAuthorizationRequest, result *models.CardAuthorizationResult) {
+	s.logger.With(map[string]interface{}{
+		"card_id":        card.ID,
+		"wallet_id":      card.WalletID,
+		"amount":         req.Amount,
+		"merchant":       req.MerchantName,
+		"mcc":            req.MCC,
+		"approved":       result.Approved,
+		"decline_reason": result.DeclineReason,
+		"card_frozen":    result.CardFrozen,
+	}).Info("Card authorization decided")
+}
+
+// getAutoFreezeRule returns the wallet's rule, or nil if none is configured.
+func (s *VirtualCardService) getAutoFreezeRule(ctx context.Context, walletID string) *models.CardAutoFreezeRule {
+	if s.autoFreezeRepo == nil {
+		return nil
+	}
+	rule, err := s.autoFreezeRepo.GetByWallet(ctx, walletID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			s.logger.WithError(err).WithField("wallet_id", walletID).Error("Failed to load card auto-freeze rule")
+		}
+		return nil
+	}
+	return rule
+}
+
+// freezeWalletCards auto-freezes every active card on a wallet and reports
+// whether the card identified by cardID was among them.
+func (s *VirtualCardService) freezeWalletCards(ctx context.Context, walletID, cardID string) bool {
+	cards, err := s.cardRepo.ListByWallet(ctx, walletID)
+	if err != nil {
+		s.logger.WithError(err).WithField("wallet_id", walletID).Error("Failed to list cards for auto-freeze")
+		return false
+	}
+
+	frozen := false
+	for _, card := range cards {
+		if card.Status != models.CardStatusActive {
+			continue
+		}
+		if s.autoFreezeCard(ctx, card, models.AutoFreezeReasonBalance) && card.ID == cardID {
+			frozen = true
+		}
+	}
+	return frozen
+}
+
+// autoFreezeCard freezes a card for an auto-freeze rule and notifies its owner.
+func (s *VirtualCardService) autoFreezeCard(ctx context.Context, card *models.VirtualCard, reason string) bool {
+	frozen, err := s.cardRepo.AutoFreeze(ctx, card.ID, reason)
+	if err != nil {
+		s.logger.WithError(err).WithField("card_id", card.ID).Error("Failed to auto-freeze card")
+		return false
+	}
+	if !frozen {
+		return false
+	}
+
+	s.logger.With(map[string]interface{}{
+		"card_id":   card.ID,
+		"wallet_id": card.WalletID,
+		"reason":    reason,
+	}).Warn("Virtual card auto-frozen")
+
+	s.notifyAutoFreeze(card, reason)
+	return true
+}
+
+// notifyAutoFreeze tells the card owner their card was frozen and how to unfreeze it.
+func (s *VirtualCardService) notifyAutoFreeze(card *models.VirtualCard, reason string) {
+	if s.notificationClient == nil {
+		return
+	}
+
+	userID := card.UserID
+	correlationID := fmt.Sprintf("card-%s", card.ID)
+	s.notificationClient.SendNotificationAsync(&clients.SendNotificationRequest{
+		UserID:     &userID,
+		Recipient:  userID,
+		Channel:    clients.NotificationChannelPush,
+		Type:       clients.NotificationTypeSecurityAlert,
+		Priority:   clients.NotificationPriorityHigh,
+		TemplateID: "security_alert_push",
+		Variables: map[string]any{
+			"message": fmt.Sprintf("Your card ending %s was frozen (%s). Unfreeze it in the app to confirm the activity was yours", lastFour(card.CardNumber), reason),
+		},
+		CorrelationID: &correlationID,
+		SourceService: "wallet",
+		Metadata: map[string]any{
+			"card_id":   card.ID,
+			"wallet_id": card.WalletID,
+		},
+	}, "wallet")
+}
+
+func lastFour(cardNumber string) string {
+	if len(cardNumber) < 4 {
+		return cardNumber
+	}
+	return cardNumber[len(cardNumber)-4:]
+}
+
+// GetAutoFreezeRule retrieves the card auto-freeze rule for a wallet.
+func (s *VirtualCardService) GetAutoFreezeRule(ctx context.Context, walletID, userID string) (*models.CardAutoFreezeRule, *errors.Error) {
+	if err := s.verifyWalletOwner(ctx, walletID, userID); err != nil {
+		return nil, err
+	}
+	return s.autoFreezeRepo.GetByWallet(ctx, walletID)
+}
+
+// SetAutoFreezeRule creates or updates the card auto-freeze rule for a wallet.
+// Fields omitted from the request keep their current values.
+func (s *VirtualCardService) SetAutoFreezeRule(ctx context.Context, walletID, userID string, req *models.SetCardAutoFreezeRuleRequest) (*models.CardAutoFreezeRule, *errors.Error) {
+	if err := s.verifyWalletOwner(ctx, walletID, userID); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.autoFreezeRepo.GetByWallet(ctx, walletID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		rule = &models.CardAutoFreezeRule{WalletID: walletID, Enabled: true}
+	}
+
+	if req.BalanceFloor != nil {
+		rule.BalanceFloor = req.BalanceFloor
+	}
+	if req.MaxDeclines != nil {
+		rule.MaxDeclines = req.MaxDeclines
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if rule.BalanceFloor == nil && rule.MaxDeclines == nil {
+		return nil, errors.Validation("at least one of balance_floor or max_declines is required")
+	}
+
+	if saveErr := s.autoFreezeRepo.Upsert(ctx, rule); saveErr != nil {
+		return nil, saveErr
+	}
+
+	s.logger.With(map[string]interface{}{
+		"wallet_id": walletID,
+		"enabled":   rule.Enabled,
+	}).Info("Card auto-freeze rule saved")
+
+	return rule, nil
+}
+
+// DeleteAutoFreezeRule removes the card auto-freeze rule for a wallet.
+func (s *VirtualCardService) DeleteAutoFreezeRule(ctx context.Context, walletID, userID string) *errors.Error {
+	if err := s.verifyWalletOwner(ctx, walletID, userID); err != nil {
+		return err
+	}
+	return s.autoFreezeRepo.Delete(ctx, walletID)
+}
+
+func (s *VirtualCardService) verifyWalletOwner(ctx context.Context, walletID, userID string) *errors.Error {
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if wallet.UserID != userID {
+		return errors.Forbidden("wallet does not belong to user")
+	}
+	return nil
+}
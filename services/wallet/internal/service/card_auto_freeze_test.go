@@ -0,0 +1,117 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+)
+
+func newAuthorizableCard() *models.VirtualCard {
+	return &models.VirtualCard{
+		ID:                  "card-1",
+		WalletID:            "wallet-1",
+		Status:              models.CardStatusActive,
+		ExpiryMonth:         12,
+		ExpiryYear:          time.Now().Year() + 1,
+		PerTransactionLimit: 100000,
+		DailyLimit:          500000,
+		MonthlyLimit:        2000000,
+	}
+}
+
+func TestCheckCardAuthorization(t *testing.T) {
+	activeWallet := &models.Wallet{Status: models.WalletStatusActive, AvailableBalance: 1000000}
+
+	tests := []struct {
+		name   string
+		card   func(c *models.VirtualCard)
+		wallet *models.Wallet
+		amount int64
+		want   string
+	}{
+		{"approved", nil, activeWallet, 50000, ""},
+		{"frozen card", func(c *models.VirtualCard) { c.Status = models.CardStatusFrozen }, activeWallet, 50000, models.DeclineCardNotUsable},
+		{"expired card", func(c *models.VirtualCard) { c.ExpiryYear = 2020 }, activeWallet, 50000, models.DeclineCardNotUsable},
+		{"inactive wallet", nil, &models.Wallet{Status: models.WalletStatusFrozen, AvailableBalance: 1000000}, 50000, models.DeclineWalletInactive},
+		{"per transaction limit", nil, activeWallet, 100001, models.DeclinePerTransactionLimit},
+		{"daily limit", func(c *models.VirtualCard) { c.DailySpent = 480000 }, activeWallet, 50000, models.DeclineDailyLimit},
+		{"monthly limit", func(c *models.VirtualCard) { c.MonthlySpent = 1990000 }, activeWallet, 50000, models.DeclineMonthlyLimit},
+		{"zero limit means unlimited", func(c *models.VirtualCard) { c.PerTransactionLimit = 0 }, activeWallet, 200000, ""},
+		{"insufficient funds", nil, &models.Wallet{Status: models.WalletStatusActive, AvailableBalance: 10000}, 50000, models.DeclineInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := newAuthorizableCard()
+			if tt.card != nil {
+				tt.card(card)
+			}
+			if got := checkCardAuthorization(card, tt.wallet, tt.amount); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCountsTowardAutoFreeze(t *testing.T) {
+	if countsTowardAutoFreeze(models.DeclineCardNotUsable) {
+		t.Error("declines on unusable cards should not count")
+	}
+	if countsTowardAutoFreeze(models.DeclineWalletInactive) {
+		t.Error("declines on inactive wallets should not count")
+	}
+	if countsTowardAutoFreeze(models.DeclineInsufficientFunds) {
+		t.Error("insufficient funds declines should not count")
+	}
+	for _, reason := range []string{models.DeclinePerTransactionLimit, models.DeclineDailyLimit, models.DeclineMonthlyLimit} {
+		if countsTowardAutoFreeze(reason) {
+			t.Errorf("%s declines should not count", reason)
+		}
+	}
+	if !countsTowardAutoFreeze(models.DeclineBelowBalanceFloor) {
+		t.Error("balance floor breaches should count")
+	}
+}
+
+func TestInsufficientFundsDeclinesDoNotCount(t *testing.T) {
+	wallet := &models.Wallet{Status: models.WalletStatusActive, AvailableBalance: 10000}
+
+	// A user retrying a purchase they cannot afford must not freeze their card
+	reason := checkCardAuthorization(newAuthorizableCard(), wallet, 50000)
+	if reason != models.DeclineInsufficientFunds {
+		t.Fatalf("expected %q, got %q", models.DeclineInsufficientFunds, reason)
+	}
+	if countsTowardAutoFreeze(reason) {
+		t.Error("insufficient funds declines should not count toward auto-freeze")
+	}
+}
+
+func TestCardAutoFreezeRule(t *testing.T) {
+	floor := int64(100000)
+	maxDeclines := 3
+	rule := &models.CardAutoFreezeRule{BalanceFloor: &floor, MaxDeclines: &maxDeclines, Enabled: true}
+
+	if !rule.BreachesFloor(99999) {
+		t.Error("expected balance below floor to breach")
+	}
+	if rule.BreachesFloor(100000) {
+		t.Error("expected balance at floor not to breach")
+	}
+	if rule.ExceedsDeclines(2) {
+		t.Error("expected 2 declines not to trip rule of 3")
+	}
+	if !rule.ExceedsDeclines(3) {
+		t.Error("expected 3 declines to trip rule of 3")
+	}
+
+	rule.Enabled = false
+	if rule.BreachesFloor(0) || rule.ExceedsDeclines(10) {
+		t.Error("disabled rule should never trip")
+	}
+
+	var none *models.CardAutoFreezeRule
+	if none.BreachesFloor(0) || none.ExceedsDeclines(10) {
+		t.Error("missing rule should never trip")
+	}
+}
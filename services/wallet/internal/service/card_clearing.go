@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// MaxNetworkCardSample caps how many cards the card network can sample at once.
+const MaxNetworkCardSample = 100
+
+// DefaultCardSettlementAccountCode is the ledger account card settlements are
+// payable to until the card network is paid.
+const DefaultCardSettlementAccountCode = "2500"
+
+// CardClearingRepositoryInterface defines card hold and clearing operations.
+// Settle and Refund return a nil clearing when the request was already applied.
+type CardClearingRepositoryInterface interface {
+	CreateHold(ctx context.Context, auth *models.CardAuthorization) (bool, *errors.Error)
+	GetByID(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error)
+	Settle(ctx context.Context, id string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error)
+	Reverse(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error)
+	Refund(ctx context.Context, id, refundID string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error)
+	ListActiveCards(ctx context.Context, limit int) ([]*models.NetworkCard, *errors.Error)
+}
+
+// cardLedger holds the ledger configuration for card clearing.
+type cardLedger struct {
+	client                *LedgerClient
+	settlementAccountCode string
+}
+
+// SetCardLedger books card settlements and refunds in the ledger, each as a
+// balanced entry between the wallet's account and the card settlement account
+// with the given code.
+func (s *VirtualCardService) SetCardLedger(client *LedgerClient, settlementAccountCode string) {
+	if settlementAccountCode == "" {
+		settlementAccountCode = DefaultCardSettlementAccountCode
+	}
+	s.cardLedger = &cardLedger{client: client, settlementAccountCode: settlementAccountCode}
+}
+
+// SettleCardAuthorization debits a held authorization from the wallet.
+func (s *VirtualCardService) SettleCardAuthorization(ctx context.Context, authorizationID string, amount int64) (*models.CardAuthorization, *errors.Error) {
+	if s.clearingRepo == nil {
+		return nil, errors.Unavailable("card clearing is not configured")
+	}
+
+	auth, clearing, err := s.clearingRepo.Settle(ctx, authorizationID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if clearing == nil {
+		return auth, nil // Already settled
+	}
+
+	s.logClearing("Card authorization settled", auth)
+	s.postClearing(ctx, auth, clearing, "card_settlement", auth.ID,
+		fmt.Sprintf("Card settlement: %s", auth.MerchantName))
+	return auth, nil
+}
+
+// ReverseCardAuthorization releases the hold of an unsettled authorization.
+func (s *VirtualCardService) ReverseCardAuthorization(ctx context.Context, authorizationID string) (*models.CardAuthorization, *errors.Error) {
+	if s.clearingRepo == nil {
+		return nil, errors.Unavailable("card clearing is not configured")
+	}
+
+	auth, err := s.clearingRepo.Reverse(ctx, authorizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logClearing("Card authorization reversed", auth)
+	return auth, nil
+}
+
+// RefundCardAuthorization credits part of a settled authorization back to the
+// wallet. refundID is the card network's ID for the refund; replaying a
+// refund does not credit the wallet again.
+func (s *VirtualCardService) RefundCardAuthorization(ctx context.Context, authorizationID, refundID string, amount int64) (*models.CardAuthorization, *errors.Error) {
+	if s.clearingRepo == nil {
+		return nil, errors.Unavailable("card clearing is not configured")
+	}
+	if refundID == "" {
+		return nil, errors.BadRequest("refund ID is required")
+	}
+
+	auth, clearing, err := s.clearingRepo.Refund(ctx, authorizationID, refundID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if clearing == nil {
+		return auth, nil // Already refunded
+	}
+
+	s.logClearing("Card authorization refunded", auth)
+	s.postClearing(ctx, auth, clearing, "card_refund", refundID,
+		fmt.Sprintf("Card refund: %s", auth.MerchantName))
+	return auth, nil
+}
+
+// GetCardAuthorization retrieves an authorization and its clearing state.
+func (s *VirtualCardService) GetCardAuthorization(ctx context.Context, authorizationID string) (*models.CardAuthorization, *errors.Error) {
+	if s.clearingRepo == nil {
+		return nil, errors.Unavailable("card clearing is not configured")
+	}
+	return s.clearingRepo.GetByID(ctx, authorizationID)
+}
+
+// SampleNetworkCards returns a random sample of usable cards for generating
+// card network traffic.
+func (s *VirtualCardService) SampleNetworkCards(ctx context.Context, limit int) ([]*models.NetworkCard, *errors.Error) {
+	if s.clearingRepo == nil {
+		return nil, errors.Unavailable("card clearing is not configured")
+	}
+	if limit <= 0 || limit > MaxNetworkCardSample {
+		limit = MaxNetworkCardSample
+	}
+	return s.clearingRepo.ListActiveCards(ctx, limit)
+}
+
+func (s *VirtualCardService) logClearing(msg string, auth *models.CardAuthorization) {
+	s.logger.With(map[string]interface{}{
+		"authorization_id": auth.ID,
+		"card_id":          auth.CardID,
+		"wallet_id":        auth.WalletID,
+		"amount":           auth.Amount,
+		"settled_amount":   auth.SettledAmount,
+		"refunded_amount":  auth.RefundedAmount,
+		"status":           auth.Status,
+	}).Info(msg)
+}
+
+// postClearing books a clearing step that moved the wallet balance: a
+// settlement debits the wallet's account and credits the card settlement
+// account, a refund the reverse. The wallet has already moved, so a failure
+// is logged rather than returned, and the ledger reconciliation reports the
+// wallet until the entry is booked.
+func (s *VirtualCardService) postClearing(ctx context.Context, auth *models.CardAuthorization, clearing *models.CardClearing, referenceType, referenceID, description string) {
+	if s.cardLedger == nil || clearing.Balance == 0 {
+		return
+	}
+
+	log := s.logger.With(map[string]interface{}{
+		"authorization_id": auth.ID,
+		"reference_type":   referenceType,
+		"reference_id":     referenceID,
+	})
+
+	entry, err := s.createClearingLedgerEntry(ctx, auth, clearing, referenceType, referenceID, description)
+	if err != nil {
+		log.WithError(err).Error("Failed to create ledger journal entry for card clearing")
+		return
+	}
+
+	log.With(map[string]interface{}{
+		"journal_entry_id": entry.ID,
+		"entry_number":     entry.EntryNumber,
+	}).Info("Ledger journal entry created for card clearing")
+}
+
+func (s *VirtualCardService) createClearingLedgerEntry(ctx context.Context, auth *models.CardAuthorization, clearing *models.CardClearing, referenceType, referenceID, description string) (*JournalEntry, *errors.Error) {
+	wallet, err := s.walletRepo.GetByID(ctx, auth.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.LedgerAccountID == "" {
+		return nil, errors.Internal("wallet missing ledger account ID")
+	}
+
+	settlement, err := s.cardLedger.client.GetAccountByCode(ctx, s.cardLedger.settlementAccountCode)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, errors.NotFoundWithID("card settlement ledger account", s.cardLedger.settlementAccountCode)
+	}
+
+	walletLine := LedgerLine{AccountID: wallet.LedgerAccountID, Description: description}
+	settlementLine := LedgerLine{AccountID: settlement.ID, Description: description}
+	if clearing.Balance < 0 {
+		walletLine.DebitAmount = -clearing.Balance
+		settlementLine.CreditAmount = -clearing.Balance
+	} else {
+		settlementLine.DebitAmount = clearing.Balance
+		walletLine.CreditAmount = clearing.Balance
+	}
+
+	return s.cardLedger.client.CreateAndPostJournalEntry(ctx, &CreateJournalEntryRequest{
+		Type:          "standard",
+		Description:   description,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Lines:         []LedgerLine{walletLine, settlementLine},
+		Metadata: map[string]any{
+			"authorization_id": auth.ID,
+			"card_id":          auth.CardID,
+			"wallet_id":        auth.WalletID,
+		},
+		// The wallet has already moved; a closed wallet account must not
+		// leave the clearing out of the ledger
+		UseSuspense: true,
+	})
+}
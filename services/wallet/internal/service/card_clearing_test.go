@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// mockCardClearingRepository applies the clearing rules to wallets held by a
+// mockWalletRepository, the way the repository does within a transaction.
+type mockCardClearingRepository struct {
+	wallets *mockWalletRepository
+	auths   map[string]*models.CardAuthorization
+	refunds map[string]*models.CardRefund
+}
+
+func newMockCardClearingRepository(wallets *mockWalletRepository) *mockCardClearingRepository {
+	return &mockCardClearingRepository{
+		wallets: wallets,
+		auths:   make(map[string]*models.CardAuthorization),
+		refunds: make(map[string]*models.CardRefund),
+	}
+}
+
+func (m *mockCardClearingRepository) apply(auth *models.CardAuthorization, clearing *models.CardClearing) {
+	wallet := m.wallets.wallets[auth.WalletID]
+	wallet.Balance += clearing.Balance
+	wallet.AvailableBalance += clearing.Available
+}
+
+func (m *mockCardClearingRepository) CreateHold(ctx context.Context, auth *models.CardAuthorization) (bool, *errors.Error) {
+	hold := auth.HoldClearing()
+	if m.wallets.wallets[auth.WalletID].AvailableBalance+hold.Available < 0 {
+		return false, nil
+	}
+	m.apply(auth, hold)
+
+	auth.ID = fmt.Sprintf("auth-%d", len(m.auths)+1)
+	auth.Status = models.CardAuthorizationAuthorized
+	stored := *auth
+	m.auths[auth.ID] = &stored
+	return true, nil
+}
+
+func (m *mockCardClearingRepository) GetByID(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error) {
+	auth, ok := m.auths[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("card authorization", id)
+	}
+	copied := *auth
+	return &copied, nil
+}
+
+func (m *mockCardClearingRepository) Settle(ctx context.Context, id string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error) {
+	auth, ok := m.auths[id]
+	if !ok {
+		return nil, nil, errors.NotFoundWithID("card authorization", id)
+	}
+	clearing, err := auth.SettleClearing(amount)
+	if err != nil || clearing == nil {
+		return auth, nil, err
+	}
+	m.apply(auth, clearing)
+	auth.Status = models.CardAuthorizationSettled
+	auth.SettledAmount = -clearing.Balance
+	return auth, clearing, nil
+}
+
+func (m *mockCardClearingRepository) Reverse(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error) {
+	auth, ok := m.auths[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("card authorization", id)
+	}
+	clearing, err := auth.ReverseClearing()
+	if err != nil || clearing == nil {
+		return auth, err
+	}
+	m.apply(auth, clearing)
+	auth.Status = models.CardAuthorizationReversed
+	return auth, nil
+}
+
+func (m *mockCardClearingRepository) Refund(ctx context.Context, id, refundID string, amount int64) (*models.CardAuthorization, *models.CardClearing, *errors.Error) {
+	auth, ok := m.auths[id]
+	if !ok {
+		return nil, nil, errors.NotFoundWithID("card authorization", id)
+	}
+	clearing, err := auth.RefundClearing(amount, m.refunds[refundID])
+	if err != nil || clearing == nil {
+		return auth, nil, err
+	}
+	m.refunds[refundID] = &models.CardRefund{RefundID: refundID, AuthorizationID: id, Amount: amount}
+	m.apply(auth, clearing)
+	auth.RefundedAmount += clearing.Balance
+	return auth, clearing, nil
+}
+
+func (m *mockCardClearingRepository) ListActiveCards(ctx context.Context, limit int) ([]*models.NetworkCard, *errors.Error) {
+	return nil, nil
+}
+
+// newClearingFixture returns a card service with a wallet holding 1000.00, a
+// 400.00 hold on it, and a fake ledger with the card settlement account.
+func newClearingFixture(t *testing.T) (*VirtualCardService, *mockCardClearingRepository, *fakeLedger, string) {
+	t.Helper()
+	wallets := newMockWalletRepository()
+	wallets.wallets["wallet-1"] = &models.Wallet{
+		ID: "wallet-1", Status: models.WalletStatusActive,
+		Balance: 100000, AvailableBalance: 100000, LedgerAccountID: "ledger-wallet-1",
+	}
+	repo := newMockCardClearingRepository(wallets)
+
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["ledger-settlement"] = &LedgerAccount{ID: "ledger-settlement", Code: DefaultCardSettlementAccountCode, Type: "liability"}
+
+	service := NewVirtualCardService(nil, wallets, nil, repo, nil)
+	service.SetCardLedger(client, "")
+
+	hold := &models.CardAuthorization{CardID: "card-1", WalletID: "wallet-1", Amount: 40000, MerchantName: "Coffee House"}
+	if held, err := repo.CreateHold(context.Background(), hold); err != nil || !held {
+		t.Fatalf("CreateHold() = %v, %v, want the hold placed", held, err)
+	}
+	return service, repo, ledger, hold.ID
+}
+
+func assertBalances(t *testing.T, repo *mockCardClearingRepository, balance, available int64) {
+	t.Helper()
+	wallet := repo.wallets.wallets["wallet-1"]
+	if wallet.Balance != balance || wallet.AvailableBalance != available {
+		t.Errorf("balance = %d, available = %d, want %d and %d", wallet.Balance, wallet.AvailableBalance, balance, available)
+	}
+}
+
+func assertEntry(t *testing.T, entry CreateJournalEntryRequest, referenceType, referenceID, debitAccount, creditAccount string, amount int64) {
+	t.Helper()
+	if entry.ReferenceType != referenceType || entry.ReferenceID != referenceID {
+		t.Errorf("entry references %s %s, want %s %s", entry.ReferenceType, entry.ReferenceID, referenceType, referenceID)
+	}
+	var debited, credited int64
+	for _, line := range entry.Lines {
+		switch {
+		case line.AccountID == debitAccount && line.DebitAmount > 0:
+			debited += line.DebitAmount
+		case line.AccountID == creditAccount && line.CreditAmount > 0:
+			credited += line.CreditAmount
+		default:
+			t.Errorf("unexpected line %+v", line)
+		}
+	}
+	if debited != amount || credited != amount {
+		t.Errorf("debited %s %d and credited %s %d, want %d each", debitAccount, debited, creditAccount, credited, amount)
+	}
+}
+
+func TestCardHold(t *testing.T) {
+	_, repo, ledger, _ := newClearingFixture(t)
+	assertBalances(t, repo, 100000, 60000)
+	if len(ledger.entries) != 0 {
+		t.Errorf("posted %d ledger entries for a hold, want none", len(ledger.entries))
+	}
+
+	// A hold larger than the available balance is not placed
+	hold := &models.CardAuthorization{CardID: "card-1", WalletID: "wallet-1", Amount: 60001}
+	if held, err := repo.CreateHold(context.Background(), hold); err != nil || held {
+		t.Errorf("CreateHold() = %v, %v, want it refused", held, err)
+	}
+	assertBalances(t, repo, 100000, 60000)
+}
+
+func TestSettleCardAuthorization_Partial(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	auth, err := service.SettleCardAuthorization(ctx, authID, 30000)
+	if err != nil {
+		t.Fatalf("SettleCardAuthorization() error = %v", err)
+	}
+	if auth.Status != models.CardAuthorizationSettled || auth.SettledAmount != 30000 {
+		t.Errorf("authorization = %s for %d, want settled for 30000", auth.Status, auth.SettledAmount)
+	}
+	// The settled amount is debited and the unsettled 100.00 released
+	assertBalances(t, repo, 70000, 70000)
+	if len(ledger.entries) != 1 {
+		t.Fatalf("posted %d ledger entries, want 1", len(ledger.entries))
+	}
+	assertEntry(t, ledger.entries[0], "card_settlement", authID, "ledger-wallet-1", "ledger-settlement", 30000)
+
+	// Replaying the settlement changes nothing and posts nothing
+	if _, err := service.SettleCardAuthorization(ctx, authID, 30000); err != nil {
+		t.Errorf("replayed settlement error = %v, want a no-op", err)
+	}
+	if _, err := service.SettleCardAuthorization(ctx, authID, 35000); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("settling again for a different amount error = %v, want conflict", err)
+	}
+	assertBalances(t, repo, 70000, 70000)
+	if len(ledger.entries) != 1 {
+		t.Errorf("posted %d ledger entries after replays, want 1", len(ledger.entries))
+	}
+}
+
+func TestSettleCardAuthorization_Full(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+
+	auth, err := service.SettleCardAuthorization(context.Background(), authID, 0)
+	if err != nil {
+		t.Fatalf("SettleCardAuthorization() error = %v", err)
+	}
+	if auth.SettledAmount != 40000 {
+		t.Errorf("settled amount = %d, want the full 40000", auth.SettledAmount)
+	}
+	assertBalances(t, repo, 60000, 60000)
+	if len(ledger.entries) != 1 {
+		t.Fatalf("posted %d ledger entries, want 1", len(ledger.entries))
+	}
+	assertEntry(t, ledger.entries[0], "card_settlement", authID, "ledger-wallet-1", "ledger-settlement", 40000)
+}
+
+func TestSettleCardAuthorization_OverSettle(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+
+	_, err := service.SettleCardAuthorization(context.Background(), authID, 40001)
+	if err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Fatalf("over-settlement error = %v, want bad request", err)
+	}
+	assertBalances(t, repo, 100000, 60000)
+	if len(ledger.entries) != 0 {
+		t.Errorf("posted %d ledger entries for a rejected settlement, want none", len(ledger.entries))
+	}
+	if status := repo.auths[authID].Status; status != models.CardAuthorizationAuthorized {
+		t.Errorf("status = %s, want the hold left in place", status)
+	}
+}
+
+func TestReverseCardAuthorization(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	auth, err := service.ReverseCardAuthorization(ctx, authID)
+	if err != nil {
+		t.Fatalf("ReverseCardAuthorization() error = %v", err)
+	}
+	if auth.Status != models.CardAuthorizationReversed {
+		t.Errorf("status = %s, want reversed", auth.Status)
+	}
+	assertBalances(t, repo, 100000, 100000)
+
+	// Reversing again is a no-op; a reversed authorization cannot settle
+	if _, err := service.ReverseCardAuthorization(ctx, authID); err != nil {
+		t.Errorf("replayed reversal error = %v, want a no-op", err)
+	}
+	if _, err := service.SettleCardAuthorization(ctx, authID, 0); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("settling a reversed authorization error = %v, want conflict", err)
+	}
+	assertBalances(t, repo, 100000, 100000)
+	if len(ledger.entries) != 0 {
+		t.Errorf("posted %d ledger entries for a reversal, want none", len(ledger.entries))
+	}
+}
+
+func TestReverseCardAuthorization_Settled(t *testing.T) {
+	service, _, _, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	if _, err := service.SettleCardAuthorization(ctx, authID, 0); err != nil {
+		t.Fatalf("SettleCardAuthorization() error = %v", err)
+	}
+	if _, err := service.ReverseCardAuthorization(ctx, authID); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("reversing a settled authorization error = %v, want conflict", err)
+	}
+}
+
+func TestRefundCardAuthorization(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	if _, err := service.SettleCardAuthorization(ctx, authID, 0); err != nil {
+		t.Fatalf("SettleCardAuthorization() error = %v", err)
+	}
+
+	auth, err := service.RefundCardAuthorization(ctx, authID, "refund-1", 10000)
+	if err != nil {
+		t.Fatalf("RefundCardAuthorization() error = %v", err)
+	}
+	if auth.RefundedAmount != 10000 {
+		t.Errorf("refunded amount = %d, want 10000", auth.RefundedAmount)
+	}
+	assertBalances(t, repo, 70000, 70000)
+	if len(ledger.entries) != 2 {
+		t.Fatalf("posted %d ledger entries, want the settlement and the refund", len(ledger.entries))
+	}
+	assertEntry(t, ledger.entries[1], "card_refund", "refund-1", "ledger-settlement", "ledger-wallet-1", 10000)
+
+	// The remaining 300.00 can be refunded, but no more
+	if _, err := service.RefundCardAuthorization(ctx, authID, "refund-2", 30001); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("over-refund error = %v, want bad request", err)
+	}
+	if _, err := service.RefundCardAuthorization(ctx, authID, "refund-2", 30000); err != nil {
+		t.Errorf("refunding the remainder error = %v", err)
+	}
+	assertBalances(t, repo, 100000, 100000)
+}
+
+func TestRefundCardAuthorization_Idempotent(t *testing.T) {
+	service, repo, ledger, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	if _, err := service.SettleCardAuthorization(ctx, authID, 0); err != nil {
+		t.Fatalf("SettleCardAuthorization() error = %v", err)
+	}
+	if _, err := service.RefundCardAuthorization(ctx, authID, "refund-1", 10000); err != nil {
+		t.Fatalf("RefundCardAuthorization() error = %v", err)
+	}
+
+	// A retried refund is not credited or booked twice
+	auth, err := service.RefundCardAuthorization(ctx, authID, "refund-1", 10000)
+	if err != nil {
+		t.Fatalf("replayed refund error = %v, want a no-op", err)
+	}
+	if auth.RefundedAmount != 10000 {
+		t.Errorf("refunded amount = %d after a replay, want 10000", auth.RefundedAmount)
+	}
+	if _, err := service.RefundCardAuthorization(ctx, authID, "refund-1", 5000); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("reusing a refund ID for another amount error = %v, want conflict", err)
+	}
+	assertBalances(t, repo, 70000, 70000)
+	if len(ledger.entries) != 2 {
+		t.Errorf("posted %d ledger entries, want the settlement and one refund", len(ledger.entries))
+	}
+}
+
+func TestRefundCardAuthorization_Rejected(t *testing.T) {
+	service, repo, _, authID := newClearingFixture(t)
+	ctx := context.Background()
+
+	if _, err := service.RefundCardAuthorization(ctx, authID, "refund-1", 10000); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("refunding an unsettled authorization error = %v, want conflict", err)
+	}
+	if _, err := service.RefundCardAuthorization(ctx, authID, "", 10000); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("refund without an ID error = %v, want bad request", err)
+	}
+	assertBalances(t, repo, 100000, 60000)
+}
@@ -10,13 +10,14 @@ import (
 
 // LedgerAccount represents a ledger account from the ledger service.
 type LedgerAccount struct {
-	ID       string `json:"id"`
-	Code     string `json:"code"`
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Currency string `json:"currency"`
-	Balance  int64  `json:"balance"`
-	Status   string `json:"status"`
+	ID       string            `json:"id"`
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Currency string            `json:"currency"`
+	Balance  int64             `json:"balance"`
+	Status   string            `json:"status"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // CreateLedgerAccountRequest represents the request to create a ledger account.
@@ -36,7 +37,7 @@ type LedgerClient struct {
 // NewLedgerClient creates a new ledger service client.
 func NewLedgerClient(baseURL string) *LedgerClient {
 	return &LedgerClient{
-		BaseClient: clients.NewBaseClient(baseURL, clients.DefaultTimeout),
+		BaseClient: clients.NewInternalClient(baseURL, clients.DefaultTimeout, ""),
 	}
 }
 
@@ -51,15 +52,27 @@ func (c *LedgerClient) CreateAccount(ctx context.Context, req *CreateLedgerAccou
 }
 
 // GetAccount retrieves a ledger account by ID.
+// Uses internal endpoint for service-to-service communication.
+// Returns nil (not an error) if the account doesn't exist, so wallets missing
+// their account can be found.
 func (c *LedgerClient) GetAccount(ctx context.Context, accountID string) (*LedgerAccount, *errors.Error) {
 	var result LedgerAccount
-	path := fmt.Sprintf("/api/v1/accounts/%s", accountID)
+	path := fmt.Sprintf("/internal/v1/accounts/%s", accountID)
 	if err := c.Get(ctx, path, &result); err != nil {
+		if err.HTTPStatusCode() == 404 {
+			return nil, nil
+		}
 		return nil, err
 	}
 	return &result, nil
 }
 
+// DeleteAccount deletes a ledger account nothing has been posted to.
+// Used to undo provisioning when the wallet it was for could not be created.
+func (c *LedgerClient) DeleteAccount(ctx context.Context, accountID string) *errors.Error {
+	return c.Delete(ctx, fmt.Sprintf("/internal/v1/accounts/%s", accountID), nil)
+}
+
 // GetAccountByCode retrieves a ledger account by its code.
 // Uses internal endpoint for service-to-service communication.
 // Returns nil (not an error) if the account doesn't exist - this supports idempotent wallet creation.
@@ -75,3 +88,43 @@ func (c *LedgerClient) GetAccountByCode(ctx context.Context, code string) (*Ledg
 	}
 	return &result, nil
 }
+
+// LedgerLine represents a journal entry line (debit or credit).
+type LedgerLine struct {
+	AccountID    string `json:"account_id"`
+	DebitAmount  int64  `json:"debit_amount"`
+	CreditAmount int64  `json:"credit_amount"`
+	Description  string `json:"description"`
+}
+
+// CreateJournalEntryRequest represents a journal entry creation request.
+type CreateJournalEntryRequest struct {
+	Type          string         `json:"type"`
+	Description   string         `json:"description"`
+	ReferenceType string         `json:"reference_type"`
+	ReferenceID   string         `json:"reference_id"`
+	Lines         []LedgerLine   `json:"lines"`
+	Metadata      map[string]any `json:"metadata,omitempty"`
+	UseSuspense   bool           `json:"use_suspense,omitempty"` // Park unbookable lines in suspense instead of failing
+}
+
+// JournalEntry represents a ledger journal entry.
+type JournalEntry struct {
+	ID          string `json:"id"`
+	EntryNumber string `json:"entry_number"`
+	Status      string `json:"status"`
+}
+
+// CreateAndPostJournalEntry creates a journal entry and posts it (finalizes it).
+func (c *LedgerClient) CreateAndPostJournalEntry(ctx context.Context, req *CreateJournalEntryRequest) (*JournalEntry, *errors.Error) {
+	var entry JournalEntry
+	if err := c.Post(ctx, "/api/v1/journal-entries", req, &entry); err != nil {
+		return nil, err
+	}
+
+	var posted JournalEntry
+	if err := c.Post(ctx, fmt.Sprintf("/api/v1/journal-entries/%s/post", entry.ID), nil, &posted); err != nil {
+		return &entry, err // Return the draft entry even if posting fails
+	}
+	return &posted, nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// walletLedgerAccountType is the type of wallet ledger accounts. A wallet
+// balance is money owed to its owner, so it is a liability: transfers credit
+// the destination and debit the source.
+const walletLedgerAccountType = "liability"
+
+// ledgerBackfillPageSize is how many wallets the backfill loads at a time.
+const ledgerBackfillPageSize = 200
+
+// ledgerProvision is the ledger account provisioned for a new wallet.
+type ledgerProvision struct {
+	AccountID string
+	Created   bool // Created for this wallet, so it must be removed if the wallet is not
+}
+
+// ledgerAccountCode returns the code of a user's wallet ledger account for a
+// currency. It is stable across retries so provisioning is idempotent.
+func ledgerAccountCode(userID string, currency sharedModels.Currency) string {
+	return fmt.Sprintf("WALLET-%s-%s", shortUserID(userID), currency)
+}
+
+// shortUserID returns the prefix of a user ID used in ledger account codes,
+// which are limited to 20 characters.
+func shortUserID(userID string) string {
+	if len(userID) > 8 {
+		return userID[:8]
+	}
+	return userID
+}
+
+// provisionLedgerAccount creates the liability account backing a user's
+// wallet in a currency, or links the existing one left behind by an earlier
+// attempt. An existing account must belong to the same user and currency.
+func (s *WalletService) provisionLedgerAccount(ctx context.Context, userID string, currency sharedModels.Currency) (*ledgerProvision, *errors.Error) {
+	code := ledgerAccountCode(userID, currency)
+
+	existing, err := s.ledgerClient.GetAccountByCode(ctx, code)
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("failed to check for existing ledger account: %v", err))
+	}
+	if existing != nil {
+		if owner := existing.Metadata["user_id"]; (owner != "" && owner != userID) || existing.Currency != string(currency) {
+			return nil, errors.Conflict(fmt.Sprintf("ledger account %s belongs to another wallet", code))
+		}
+		return &ledgerProvision{AccountID: existing.ID}, nil
+	}
+
+	account, err := s.ledgerClient.CreateAccount(ctx, &CreateLedgerAccountRequest{
+		Code:     code,
+		Name:     fmt.Sprintf("Wallet (%s) for User %s", currency, shortUserID(userID)),
+		Type:     walletLedgerAccountType,
+		Currency: string(currency),
+		Metadata: map[string]string{
+			"wallet_type": string(models.WalletTypeDefault),
+			"user_id":     userID,
+		},
+	})
+	if err != nil {
+		return nil, errors.Internal(fmt.Sprintf("failed to create ledger account: %v", err))
+	}
+
+	return &ledgerProvision{AccountID: account.ID, Created: true}, nil
+}
+
+// releaseLedgerAccount undoes a provisioning whose wallet could not be
+// created. Failures are only logged: the account has no postings, and the
+// next attempt for the same wallet links it again.
+func (s *WalletService) releaseLedgerAccount(ctx context.Context, provision *ledgerProvision) {
+	if provision == nil || !provision.Created {
+		return
+	}
+	if err := s.ledgerClient.DeleteAccount(ctx, provision.AccountID); err != nil {
+		log.Printf("[wallet] Failed to delete ledger account %s after wallet creation failed: %v", provision.AccountID, err)
+	}
+}
+
+// BackfillLedgerAccounts finds wallets whose ledger account does not exist
+// and provisions one for each, up to the request's limit. Closed wallets are
+// skipped.
+func (s *WalletService) BackfillLedgerAccounts(ctx context.Context, req *models.LedgerBackfillRequest) (*models.LedgerBackfillResult, *errors.Error) {
+	if s.ledgerClient == nil {
+		return nil, errors.Unavailable("ledger service is not configured")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = models.DefaultLedgerBackfillLimit
+	}
+	if limit > models.MaxLedgerBackfillLimit {
+		return nil, errors.Validation(fmt.Sprintf("limit must be at most %d", models.MaxLedgerBackfillLimit))
+	}
+
+	result := &models.LedgerBackfillResult{
+		DryRun: req.DryRun,
+		Items:  []models.LedgerBackfillItem{},
+	}
+
+	// Oldest first, so repeated runs make progress through the same order
+	filter := &models.WalletFilter{SortAsc: true, Limit: ledgerBackfillPageSize}
+	for {
+		wallets, _, err := s.walletRepo.Search(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, wallet := range wallets {
+			if result.Missing >= limit {
+				return result, nil
+			}
+			if wallet.Status == models.WalletStatusClosed {
+				continue
+			}
+
+			result.Checked++
+			account, lookupErr := s.ledgerClient.GetAccount(ctx, wallet.LedgerAccountID)
+			if lookupErr != nil {
+				return nil, errors.Internal(fmt.Sprintf("failed to look up ledger account of wallet %s: %v", wallet.ID, lookupErr))
+			}
+			if account != nil {
+				continue
+			}
+
+			result.Missing++
+			result.Items = append(result.Items, s.backfillWallet(ctx, wallet, req.DryRun, result))
+		}
+
+		if len(wallets) < filter.Limit {
+			result.Complete = true
+			return result, nil
+		}
+		filter.Offset += len(wallets)
+	}
+}
+
+// backfillWallet links a wallet missing its ledger account to a new or
+// existing one, counting the outcome in result.
+func (s *WalletService) backfillWallet(ctx context.Context, wallet *models.Wallet, dryRun bool, result *models.LedgerBackfillResult) models.LedgerBackfillItem {
+	item := models.LedgerBackfillItem{
+		WalletID:         wallet.ID,
+		UserID:           wallet.UserID,
+		Currency:         string(wallet.Currency),
+		MissingAccountID: wallet.LedgerAccountID,
+	}
+	if dryRun {
+		return item
+	}
+
+	provision, err := s.provisionLedgerAccount(ctx, wallet.UserID, wallet.Currency)
+	if err != nil {
+		item.Error = err.Message
+		result.Failed++
+		return item
+	}
+
+	if err := s.walletRepo.UpdateLedgerAccount(ctx, wallet.ID, wallet.LedgerAccountID, provision.AccountID); err != nil {
+		s.releaseLedgerAccount(ctx, provision)
+		item.Error = err.Message
+		result.Failed++
+		return item
+	}
+
+	item.LedgerAccountID = provision.AccountID
+	item.LedgerAccountReuse = !provision.Created
+	result.Repaired++
+	log.Printf("[wallet] Linked wallet %s to ledger account %s (was missing %s)", wallet.ID, provision.AccountID, wallet.LedgerAccountID)
+	return item
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// fakeLedger serves the ledger's internal account endpoints and journal
+// entry creation from memory.
+type fakeLedger struct {
+	mu       sync.Mutex
+	accounts map[string]*LedgerAccount // By ID
+	nextID   int
+	deleted  []string
+	entries  []CreateJournalEntryRequest // Posted entries
+}
+
+func newFakeLedger(t *testing.T) (*fakeLedger, *LedgerClient) {
+	t.Helper()
+	ledger := &fakeLedger{accounts: make(map[string]*LedgerAccount)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /internal/v1/accounts/by-code/{code}", func(w http.ResponseWriter, r *http.Request) {
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+		for _, account := range ledger.accounts {
+			if account.Code == r.PathValue("code") {
+				response.OK(w, account)
+				return
+			}
+		}
+		response.Error(w, errors.NotFound("account not found"))
+	})
+	mux.HandleFunc("GET /internal/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+		if account, ok := ledger.accounts[r.PathValue("id")]; ok {
+			response.OK(w, account)
+			return
+		}
+		response.Error(w, errors.NotFound("account not found"))
+	})
+	mux.HandleFunc("POST /internal/v1/accounts", func(w http.ResponseWriter, r *http.Request) {
+		var req CreateLedgerAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, errors.BadRequest(err.Error()))
+			return
+		}
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+		ledger.nextID++
+		account := &LedgerAccount{
+			ID:       fmt.Sprintf("ledger-%d", ledger.nextID),
+			Code:     req.Code,
+			Name:     req.Name,
+			Type:     req.Type,
+			Currency: req.Currency,
+			Status:   "active",
+			Metadata: req.Metadata,
+		}
+		ledger.accounts[account.ID] = account
+		response.Created(w, account)
+	})
+	mux.HandleFunc("DELETE /internal/v1/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+		delete(ledger.accounts, r.PathValue("id"))
+		ledger.deleted = append(ledger.deleted, r.PathValue("id"))
+		response.NoContent(w)
+	})
+
+	mux.HandleFunc("POST /api/v1/journal-entries", func(w http.ResponseWriter, r *http.Request) {
+		var req CreateJournalEntryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Error(w, errors.BadRequest(err.Error()))
+			return
+		}
+		ledger.mu.Lock()
+		defer ledger.mu.Unlock()
+		ledger.entries = append(ledger.entries, req)
+		response.Created(w, &JournalEntry{ID: fmt.Sprintf("je-%d", len(ledger.entries)), Status: "draft"})
+	})
+	mux.HandleFunc("POST /api/v1/journal-entries/{id}/post", func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, &JournalEntry{ID: r.PathValue("id"), Status: "posted"})
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return ledger, NewLedgerClient(server.URL)
+}
+
+func TestCreateWallet_ProvisionsLiabilityAccount(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	repo := newMockWalletRepository()
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	wallet, err := service.CreateWallet(context.Background(), &models.CreateWalletRequest{
+		UserID:   "3f2a9c1e-5b7d-4e8f-9a0b-1c2d3e4f5a6b",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	account, ok := ledger.accounts[wallet.LedgerAccountID]
+	if !ok {
+		t.Fatalf("expected wallet to point at a ledger account, got %q", wallet.LedgerAccountID)
+	}
+	if account.Type != "liability" {
+		t.Errorf("expected liability account, got %s", account.Type)
+	}
+	if account.Code != "WALLET-3f2a9c1e-INR" {
+		t.Errorf("expected code WALLET-3f2a9c1e-INR, got %s", account.Code)
+	}
+}
+
+func TestCreateWallet_ReleasesAccountWhenInsertFails(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	repo := newMockWalletRepository()
+	repo.createFunc = func(ctx context.Context, wallet *models.Wallet) *errors.Error {
+		return errors.Internal("insert failed")
+	}
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	_, err := service.CreateWallet(context.Background(), &models.CreateWalletRequest{
+		UserID:   "3f2a9c1e-5b7d-4e8f-9a0b-1c2d3e4f5a6b",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+	})
+	if err == nil {
+		t.Fatal("expected wallet creation to fail")
+	}
+	if len(ledger.accounts) != 0 || len(ledger.deleted) != 1 {
+		t.Errorf("expected the provisioned account to be deleted, got %d accounts and deletions %v", len(ledger.accounts), ledger.deleted)
+	}
+}
+
+func TestCreateWallet_KeepsReusedAccountWhenInsertFails(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["orphan"] = &LedgerAccount{
+		ID: "orphan", Code: "WALLET-3f2a9c1e-INR", Currency: "INR",
+		Metadata: map[string]string{"user_id": "3f2a9c1e-5b7d-4e8f-9a0b-1c2d3e4f5a6b"},
+	}
+	repo := newMockWalletRepository()
+	repo.createFunc = func(ctx context.Context, wallet *models.Wallet) *errors.Error {
+		if wallet.LedgerAccountID != "orphan" {
+			t.Errorf("expected the orphaned account to be reused, got %s", wallet.LedgerAccountID)
+		}
+		return errors.Internal("insert failed")
+	}
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	if _, err := service.CreateWallet(context.Background(), &models.CreateWalletRequest{
+		UserID:   "3f2a9c1e-5b7d-4e8f-9a0b-1c2d3e4f5a6b",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+	}); err == nil {
+		t.Fatal("expected wallet creation to fail")
+	}
+	if len(ledger.deleted) != 0 {
+		t.Errorf("expected an account this attempt did not create to be kept, deleted %v", ledger.deleted)
+	}
+}
+
+func TestCreateWallet_RejectsAccountOfAnotherUser(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["other"] = &LedgerAccount{
+		ID: "other", Code: "WALLET-3f2a9c1e-INR", Currency: "INR",
+		Metadata: map[string]string{"user_id": "3f2a9c1e-0000-0000-0000-000000000000"},
+	}
+	service := NewWalletService(newMockWalletRepository(), nil, client, nil, nil)
+
+	_, err := service.CreateWallet(context.Background(), &models.CreateWalletRequest{
+		UserID:   "3f2a9c1e-5b7d-4e8f-9a0b-1c2d3e4f5a6b",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+	})
+	if err == nil || err.Code != errors.ErrCodeConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+}
+
+func TestBackfillLedgerAccounts(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["present"] = &LedgerAccount{ID: "present", Code: "WALLET-aaaaaaaa-INR", Currency: "INR"}
+
+	repo := newMockWalletRepository()
+	repo.wallets["w-ok"] = &models.Wallet{ID: "w-ok", UserID: "aaaaaaaa-1", Currency: "INR", Status: models.WalletStatusActive, LedgerAccountID: "present"}
+	repo.wallets["w-missing"] = &models.Wallet{ID: "w-missing", UserID: "bbbbbbbb-2", Currency: "INR", Status: models.WalletStatusActive, LedgerAccountID: "gone"}
+	repo.wallets["w-closed"] = &models.Wallet{ID: "w-closed", UserID: "cccccccc-3", Currency: "INR", Status: models.WalletStatusClosed, LedgerAccountID: "gone-too"}
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	dryRun, err := service.BackfillLedgerAccounts(context.Background(), &models.LedgerBackfillRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if dryRun.Checked != 2 || dryRun.Missing != 1 || dryRun.Repaired != 0 || !dryRun.Complete {
+		t.Errorf("unexpected dry run result %+v", dryRun)
+	}
+	if repo.wallets["w-missing"].LedgerAccountID != "gone" {
+		t.Error("expected a dry run to leave wallets unchanged")
+	}
+
+	result, err := service.BackfillLedgerAccounts(context.Background(), &models.LedgerBackfillRequest{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Missing != 1 || result.Repaired != 1 || result.Failed != 0 {
+		t.Fatalf("unexpected backfill result %+v", result)
+	}
+	repaired := repo.wallets["w-missing"].LedgerAccountID
+	if account, ok := ledger.accounts[repaired]; !ok || account.Code != "WALLET-bbbbbbbb-INR" {
+		t.Errorf("expected wallet to be linked to a new account, got %q", repaired)
+	}
+	if repo.wallets["w-closed"].LedgerAccountID != "gone-too" {
+		t.Error("expected closed wallets to be skipped")
+	}
+}
+
+func TestBackfillLedgerAccounts_LimitTooHigh(t *testing.T) {
+	_, client := newFakeLedger(t)
+	service := NewWalletService(newMockWalletRepository(), nil, client, nil, nil)
+
+	_, err := service.BackfillLedgerAccounts(context.Background(), &models.LedgerBackfillRequest{Limit: models.MaxLedgerBackfillLimit + 1})
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ledgerReconcilePageSize is how many wallets reconciliation loads at a time.
+const ledgerReconcilePageSize = 200
+
+// ledgerReconciliation keeps the latest wallet to ledger reconciliation report.
+type ledgerReconciliation struct {
+	mu     sync.RWMutex
+	latest *models.ReconciliationReport
+}
+
+// ReconcileLedgerBalances compares every active wallet's balance with the
+// balance of its ledger account and keeps the report as the latest. Each
+// mismatch is logged; repairing one needs a correcting entry or balance fix
+// decided by an operator, since either side may be the wrong one.
+func (s *WalletService) ReconcileLedgerBalances(ctx context.Context) (*models.ReconciliationReport, *errors.Error) {
+	if s.ledgerClient == nil {
+		return nil, errors.Unavailable("ledger service is not configured")
+	}
+
+	report := &models.ReconciliationReport{
+		StartedAt:  sharedModels.Now(),
+		Mismatches: []models.BalanceMismatch{},
+	}
+
+	status := models.WalletStatusActive
+	filter := &models.WalletFilter{Status: &status, SortAsc: true, Limit: ledgerReconcilePageSize}
+	for {
+		wallets, _, err := s.walletRepo.Search(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, wallet := range wallets {
+			account, lookupErr := s.ledgerClient.GetAccount(ctx, wallet.LedgerAccountID)
+			if lookupErr != nil || account == nil {
+				report.Failed++
+				continue
+			}
+
+			report.Checked++
+			if account.Balance == wallet.Balance {
+				continue
+			}
+			if len(report.Mismatches) >= models.MaxReconciliationMismatches {
+				report.Truncated = true
+				continue
+			}
+			mismatch := models.BalanceMismatch{
+				WalletID:        wallet.ID,
+				LedgerAccountID: wallet.LedgerAccountID,
+				WalletBalance:   wallet.Balance,
+				LedgerBalance:   account.Balance,
+				Difference:      wallet.Balance - account.Balance,
+			}
+			report.Mismatches = append(report.Mismatches, mismatch)
+			log.Printf("[wallet] Wallet %s balance %d differs from ledger account %s balance %d",
+				wallet.ID, wallet.Balance, wallet.LedgerAccountID, account.Balance)
+		}
+
+		if len(wallets) < filter.Limit {
+			break
+		}
+		filter.Offset += len(wallets)
+	}
+
+	report.CompletedAt = sharedModels.Now()
+	report.Healthy = len(report.Mismatches) == 0 && !report.Truncated && report.Failed == 0
+
+	s.reconciliation.mu.Lock()
+	s.reconciliation.latest = report
+	s.reconciliation.mu.Unlock()
+	return report, nil
+}
+
+// LatestReconciliation returns the report of the last completed reconciliation.
+func (s *WalletService) LatestReconciliation() (*models.ReconciliationReport, *errors.Error) {
+	s.reconciliation.mu.RLock()
+	defer s.reconciliation.mu.RUnlock()
+	if s.reconciliation.latest == nil {
+		return nil, errors.NotFound("reconciliation report")
+	}
+	return s.reconciliation.latest, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+func TestReconcileLedgerBalances(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["acct-ok"] = &LedgerAccount{ID: "acct-ok", Balance: 5000}
+	ledger.accounts["acct-skewed"] = &LedgerAccount{ID: "acct-skewed", Balance: 1000}
+
+	repo := newMockWalletRepository()
+	repo.wallets["w-ok"] = &models.Wallet{ID: "w-ok", Status: models.WalletStatusActive, Balance: 5000, LedgerAccountID: "acct-ok"}
+	repo.wallets["w-skewed"] = &models.Wallet{ID: "w-skewed", Status: models.WalletStatusActive, Balance: 1500, LedgerAccountID: "acct-skewed"}
+	repo.wallets["w-missing"] = &models.Wallet{ID: "w-missing", Status: models.WalletStatusActive, LedgerAccountID: "gone"}
+	repo.wallets["w-closed"] = &models.Wallet{ID: "w-closed", Status: models.WalletStatusClosed, Balance: 99, LedgerAccountID: "acct-ok"}
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	if _, err := service.LatestReconciliation(); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Fatalf("expected no report before the first run, got %v", err)
+	}
+
+	report, err := service.ReconcileLedgerBalances(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Checked != 2 || report.Failed != 1 || report.Healthy {
+		t.Errorf("unexpected report %+v", report)
+	}
+	want := models.BalanceMismatch{WalletID: "w-skewed", LedgerAccountID: "acct-skewed", WalletBalance: 1500, LedgerBalance: 1000, Difference: 500}
+	if len(report.Mismatches) != 1 || report.Mismatches[0] != want {
+		t.Errorf("mismatches = %+v, want [%+v]", report.Mismatches, want)
+	}
+
+	latest, err := service.LatestReconciliation()
+	if err != nil || latest != report {
+		t.Errorf("expected the run to be the latest report, got %+v, %v", latest, err)
+	}
+}
+
+func TestReconcileLedgerBalances_Healthy(t *testing.T) {
+	ledger, client := newFakeLedger(t)
+	ledger.accounts["acct-ok"] = &LedgerAccount{ID: "acct-ok", Balance: 5000}
+
+	repo := newMockWalletRepository()
+	repo.wallets["w-ok"] = &models.Wallet{ID: "w-ok", Status: models.WalletStatusActive, Balance: 5000, LedgerAccountID: "acct-ok"}
+	service := NewWalletService(repo, nil, client, nil, nil)
+
+	report, err := service.ReconcileLedgerBalances(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !report.Healthy || report.Checked != 1 || len(report.Mismatches) != 0 {
+		t.Errorf("expected a healthy report, got %+v", report)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// ClosureSweepRequest asks the transaction service to sweep a closing
+// wallet's balance. Without a destination wallet the sweep is a withdrawal.
+type ClosureSweepRequest struct {
+	ClosureID           string `json:"closure_id"`
+	WalletID            string `json:"wallet_id"`
+	DestinationWalletID string `json:"destination_wallet_id,omitempty"`
+	Amount              int64  `json:"amount"`
+	Currency            string `json:"currency"`
+}
+
+// ClosureSweepResult is the transaction recording a closure sweep.
+type ClosureSweepResult struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// AmountCheckRequest asks the transaction service whether an amount is
+// within the amount policy of an operation and currency.
+type AmountCheckRequest struct {
+	Operation string `json:"operation"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+}
+
+// TransactionClient handles communication with the transaction service.
+type TransactionClient struct {
+	*clients.BaseClient
+}
+
+// NewTransactionClient creates a new transaction service client.
+func NewTransactionClient(baseURL, internalSecret string) *TransactionClient {
+	return &TransactionClient{
+		BaseClient: clients.NewInternalClient(baseURL, clients.DefaultTimeout, internalSecret),
+	}
+}
+
+// SweepClosure records and executes a closure sweep as a transaction, so it
+// shows in the wallets' history and the ledger.
+func (c *TransactionClient) SweepClosure(ctx context.Context, req *ClosureSweepRequest) (*ClosureSweepResult, *errors.Error) {
+	var result ClosureSweepResult
+	if err := c.Post(ctx, "/internal/v1/transactions/closure-sweeps", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CheckAmount checks an amount against the transaction service's amount
+// policies. It returns AMOUNT_BELOW_MINIMUM or AMOUNT_ABOVE_MAXIMUM when the
+// amount is out of bounds.
+func (c *TransactionClient) CheckAmount(ctx context.Context, req *AmountCheckRequest) *errors.Error {
+	return c.Post(ctx, "/internal/v1/amount-policies/check", req, nil)
+}
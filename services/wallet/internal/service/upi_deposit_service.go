@@ -8,15 +8,26 @@ import (
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/repository"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
 	"github.com/1mb-dev/nivomoney/shared/events"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/money"
 )
 
+// AmountChecker checks amounts against the transaction service's amount policies.
+type AmountChecker interface {
+	CheckAmount(ctx context.Context, req *AmountCheckRequest) *errors.Error
+}
+
+// UPIAmountOperation is the amount policy operation of UPI deposits.
+const UPIAmountOperation = "upi_deposit"
+
 // UPIDepositService handles business logic for UPI deposits.
 type UPIDepositService struct {
 	upiRepo        *repository.UPIDepositRepository
 	walletRepo     WalletRepositoryInterface
 	eventPublisher *events.Publisher
+	amountChecker  AmountChecker
 	logger         *logger.Logger
 }
 
@@ -34,6 +45,12 @@ func NewUPIDepositService(
 	}
 }
 
+// SetAmountPolicies bounds deposit amounts by the transaction service's
+// upi_deposit amount policy for the wallet's currency.
+func (s *UPIDepositService) SetAmountPolicies(checker AmountChecker) {
+	s.amountChecker = checker
+}
+
 // InitiateDeposit creates a new UPI deposit request.
 func (s *UPIDepositService) InitiateDeposit(ctx context.Context, walletID, userID string, amount int64) (*models.UPIDepositResponse, *errors.Error) {
 	// Validate wallet exists and belongs to user
@@ -51,12 +68,18 @@ func (s *UPIDepositService) InitiateDeposit(ctx context.Context, walletID, userI
 		return nil, errors.BadRequest("wallet is not active")
 	}
 
-	// Validate amount (min ₹1, max ₹1,00,000)
-	if amount < 100 { // 100 paise = ₹1
-		return nil, errors.BadRequest("minimum deposit amount is ₹1")
+	// Validate amount against the UPI deposit amount policy
+	if amount <= 0 {
+		return nil, errors.BadRequest("deposit amount must be positive")
 	}
-	if amount > 10000000 { // 1 crore paise = ₹1,00,000
-		return nil, errors.BadRequest("maximum deposit amount is ₹1,00,000")
+	if s.amountChecker != nil {
+		if err := s.amountChecker.CheckAmount(ctx, &AmountCheckRequest{
+			Operation: UPIAmountOperation,
+			Currency:  string(wallet.Currency),
+			Amount:    amount,
+		}); err != nil {
+			return nil, err
+		}
 	}
 
 	// Get or generate UPI VPA for wallet
@@ -197,10 +220,9 @@ func (s *UPIDepositService) CompleteDeposit(ctx context.Context, depositID strin
 
 // generateUPIString generates a UPI payment string.
 func (s *UPIDepositService) generateUPIString(vpa string, amount int64, reference string) string {
-	// Amount in rupees for UPI string
-	amountRupees := float64(amount) / 100.0
-	return fmt.Sprintf("upi://pay?pa=%s&pn=NivoMoney&am=%.2f&tr=%s&cu=INR&tn=Wallet%%20Deposit",
-		vpa, amountRupees, reference)
+	// UPI amounts are in rupees
+	return fmt.Sprintf("upi://pay?pa=%s&pn=NivoMoney&am=%s&tr=%s&cu=INR&tn=Wallet%%20Deposit",
+		vpa, money.New(amount, sharedModels.INR).Decimal(), reference)
 }
 
 // simulateDepositCompletion simulates UPI payment completion after a delay.
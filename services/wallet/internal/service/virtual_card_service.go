@@ -5,23 +5,31 @@ import (
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/repository"
+	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/logger"
 )
 
 // VirtualCardService handles business logic for virtual card operations.
 type VirtualCardService struct {
-	cardRepo   *repository.VirtualCardRepository
-	walletRepo *repository.WalletRepository
-	logger     *logger.Logger
+	cardRepo           *repository.VirtualCardRepository
+	walletRepo         WalletRepositoryInterface
+	autoFreezeRepo     *repository.CardAutoFreezeRepository
+	clearingRepo       CardClearingRepositoryInterface
+	cardLedger         *cardLedger
+	notificationClient *clients.NotificationClient
+	logger             *logger.Logger
 }
 
 // NewVirtualCardService creates a new virtual card service.
-func NewVirtualCardService(cardRepo *repository.VirtualCardRepository, walletRepo *repository.WalletRepository) *VirtualCardService {
+func NewVirtualCardService(cardRepo *repository.VirtualCardRepository, walletRepo WalletRepositoryInterface, autoFreezeRepo *repository.CardAutoFreezeRepository, clearingRepo CardClearingRepositoryInterface, notificationClient *clients.NotificationClient) *VirtualCardService {
 	return &VirtualCardService{
-		cardRepo:   cardRepo,
-		walletRepo: walletRepo,
-		logger:     logger.NewDefault("wallet.card"),
+		cardRepo:           cardRepo,
+		walletRepo:         walletRepo,
+		autoFreezeRepo:     autoFreezeRepo,
+		clearingRepo:       clearingRepo,
+		notificationClient: notificationClient,
+		logger:             logger.NewDefault("wallet.card"),
 	}
 }
 
@@ -147,11 +155,16 @@ func (s *VirtualCardService) UnfreezeCard(ctx context.Context, cardID, userID st
 		return nil, errors.BadRequest("can only unfreeze frozen cards")
 	}
 
+	// For auto-frozen cards this is the user's confirmation that the
+	// activity was theirs; the decline counter is reset along with the status.
 	if unfreezeErr := s.cardRepo.Unfreeze(ctx, cardID); unfreezeErr != nil {
 		return nil, unfreezeErr
 	}
 
-	s.logger.WithField("card_id", cardID).Info("Virtual card unfrozen")
+	s.logger.With(map[string]interface{}{
+		"card_id":     cardID,
+		"auto_frozen": card.AutoFrozen,
+	}).Info("Virtual card unfrozen")
 
 	// Return updated card
 	return s.cardRepo.GetByID(ctx, cardID)
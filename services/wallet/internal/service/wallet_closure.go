@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/events"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+	"github.com/1mb-dev/nivomoney/shared/money"
+)
+
+// WalletClosureRepositoryInterface defines the interface for scheduled wallet closure operations.
+type WalletClosureRepositoryInterface interface {
+	Schedule(ctx context.Context, closure *models.WalletClosure) *errors.Error
+	GetByID(ctx context.Context, id string) (*models.WalletClosure, *errors.Error)
+	GetLatestByWallet(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error)
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WalletClosure, *errors.Error)
+	RecordError(ctx context.Context, id, message string) *errors.Error
+	Cancel(ctx context.Context, id string) (*models.WalletClosure, *errors.Error)
+	ExecuteSweep(ctx context.Context, req *models.ExecuteClosureSweepRequest) *errors.Error
+	Close(ctx context.Context, id string, reopenUntil time.Time) (*models.WalletClosure, *errors.Error)
+	Reopen(ctx context.Context, id string) (*models.WalletClosure, *errors.Error)
+}
+
+// CardHoldRepositoryInterface lists and releases a wallet's card holds.
+type CardHoldRepositoryInterface interface {
+	ListAuthorizedByWallet(ctx context.Context, walletID string) ([]*models.CardAuthorization, *errors.Error)
+	Reverse(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error)
+}
+
+// ClosureSweeper moves a closing wallet's balance out through the
+// transaction service.
+type ClosureSweeper interface {
+	SweepClosure(ctx context.Context, req *ClosureSweepRequest) (*ClosureSweepResult, *errors.Error)
+}
+
+// ClosurePolicy configures scheduled wallet closures.
+type ClosurePolicy struct {
+	GracePeriod time.Duration // How long a closed wallet can be reopened
+	HoldExpiry  time.Duration // Card holds older than this are released rather than waited on
+	MaxDelay    time.Duration // How far ahead a closure can be scheduled
+	BatchSize   int           // Closures processed per worker run
+}
+
+// DefaultClosurePolicy returns the default closure policy.
+func DefaultClosurePolicy() ClosurePolicy {
+	return ClosurePolicy{
+		GracePeriod: 30 * 24 * time.Hour,
+		HoldExpiry:  7 * 24 * time.Hour,
+		MaxDelay:    90 * 24 * time.Hour,
+		BatchSize:   50,
+	}
+}
+
+// walletClosures holds the optional scheduled closure dependencies.
+type walletClosures struct {
+	repo    WalletClosureRepositoryInterface
+	holds   CardHoldRepositoryInterface
+	sweeper ClosureSweeper
+	policy  ClosurePolicy
+	logger  *logger.Logger
+}
+
+// SetClosures enables scheduled wallet closure: the wallet waits in
+// pending_closure while card holds settle and its balance is swept to another
+// of the owner's wallets or withdrawn, then closes and can be reopened within
+// the policy's grace period.
+func (s *WalletService) SetClosures(repo WalletClosureRepositoryInterface, holds CardHoldRepositoryInterface, sweeper ClosureSweeper, policy ClosurePolicy) {
+	s.closures = &walletClosures{
+		repo:    repo,
+		holds:   holds,
+		sweeper: sweeper,
+		policy:  policy,
+		logger:  logger.NewDefault("wallet.closure"),
+	}
+}
+
+// ScheduleClosure schedules a wallet's closure. Only the wallet's admins can
+// close it; the balance can only be swept to another active wallet in the
+// same currency that the user owns or co-owns, such as a joint wallet.
+func (s *WalletService) ScheduleClosure(ctx context.Context, walletID string, req *models.ScheduleClosureRequest) (*models.WalletClosure, *errors.Error) {
+	if s.closures == nil {
+		return nil, errors.Unavailable("scheduled wallet closure is not configured")
+	}
+
+	wallet, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	switch wallet.Status {
+	case models.WalletStatusActive, models.WalletStatusInactive:
+	case models.WalletStatusClosed:
+		return nil, errors.BadRequest("wallet is already closed")
+	case models.WalletStatusPendingClosure:
+		return nil, errors.Conflict("wallet closure is already scheduled")
+	default:
+		return nil, errors.BadRequest(fmt.Sprintf("cannot close a %s wallet", wallet.Status))
+	}
+
+	closure := &models.WalletClosure{
+		WalletID:       wallet.ID,
+		Reason:         req.Reason,
+		PreviousStatus: wallet.Status,
+	}
+	if sweepErr := s.validateClosureSweep(ctx, wallet, req, closure); sweepErr != nil {
+		return nil, sweepErr
+	}
+
+	now := time.Now().UTC()
+	scheduledFor := now
+	if req.CloseAt != nil && req.CloseAt.After(now) {
+		if req.CloseAt.Sub(now) > s.closures.policy.MaxDelay {
+			return nil, errors.Validation(fmt.Sprintf("close_at cannot be more than %d days ahead", int(s.closures.policy.MaxDelay/(24*time.Hour))))
+		}
+		scheduledFor = req.CloseAt.UTC()
+	}
+	closure.ScheduledFor = sharedModels.NewTimestamp(scheduledFor)
+
+	userID, _ := middleware.GetUserID(ctx)
+	closure.RequestedBy = userID
+
+	if scheduleErr := s.closures.repo.Schedule(ctx, closure); scheduleErr != nil {
+		return nil, scheduleErr
+	}
+
+	s.publishClosureStatus(ctx, wallet.ID, wallet.Status, "closure_scheduled", req.Reason)
+	s.notifyOwners(ctx, wallet.ID, userID, "Your wallet is scheduled to close")
+	return closure, nil
+}
+
+// validateClosureSweep checks where a closing wallet's balance goes and
+// records it on closure.
+func (s *WalletService) validateClosureSweep(ctx context.Context, wallet *models.Wallet, req *models.ScheduleClosureRequest, closure *models.WalletClosure) *errors.Error {
+	if req.SweepMethod == "" {
+		if wallet.Balance > 0 {
+			return errors.Validation("sweep_method is required to close a wallet with a balance")
+		}
+		if req.SweepWalletID != "" {
+			return errors.Validation("sweep_wallet_id requires sweep_method wallet")
+		}
+		return nil
+	}
+	if !req.SweepMethod.IsValid() {
+		return errors.Validation("sweep_method must be wallet or withdrawal")
+	}
+
+	method := req.SweepMethod
+	closure.SweepMethod = &method
+
+	if method == models.SweepMethodWithdrawal {
+		if req.SweepWalletID != "" {
+			return errors.Validation("sweep_wallet_id is only used with sweep_method wallet")
+		}
+		return nil
+	}
+
+	if req.SweepWalletID == "" {
+		return errors.Validation("sweep_wallet_id is required with sweep_method wallet")
+	}
+	if req.SweepWalletID == wallet.ID {
+		return errors.Validation("cannot sweep a wallet into itself")
+	}
+	target, err := s.walletRepo.GetByID(ctx, req.SweepWalletID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return errors.Validation("sweep wallet not found")
+		}
+		return err
+	}
+	if authErr := s.AuthorizeWallet(ctx, target, models.WalletPermissionView); authErr != nil {
+		return errors.Forbidden("the balance can only be swept to a wallet you own or co-own")
+	}
+	if !target.IsActive() {
+		return errors.New(errors.ErrCodeWalletNotActive, "sweep wallet is not active").AddDetail("status", string(target.Status))
+	}
+	if target.Currency != wallet.Currency {
+		return errors.New(errors.ErrCodeWalletCurrencyMismatch, fmt.Sprintf("currency mismatch: wallet is %s, sweep wallet is %s", wallet.Currency, target.Currency))
+	}
+
+	closure.SweepWalletID = &target.ID
+	return nil
+}
+
+// GetClosure retrieves a wallet's most recent closure.
+func (s *WalletService) GetClosure(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	if s.closures == nil {
+		return nil, errors.Unavailable("scheduled wallet closure is not configured")
+	}
+	if _, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionView); err != nil {
+		return nil, err
+	}
+	return s.closures.repo.GetLatestByWallet(ctx, walletID)
+}
+
+// CancelClosure cancels a wallet's pending closure and restores its previous
+// status. Anything already swept stays where it went.
+func (s *WalletService) CancelClosure(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	closure, err := s.authorizedClosure(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if closure.Status != models.WalletClosureStatusPending {
+		return nil, errors.Conflict("wallet has no pending closure")
+	}
+
+	cancelled, err := s.closures.repo.Cancel(ctx, closure.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishClosureStatus(ctx, walletID, models.WalletStatusPendingClosure, "closure_cancelled", "")
+	return cancelled, nil
+}
+
+// ReopenWallet reopens a closed wallet within the grace period, restoring its
+// status from before the closure. Its cards stay cancelled.
+func (s *WalletService) ReopenWallet(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	closure, err := s.authorizedClosure(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if closure.Status != models.WalletClosureStatusClosed {
+		return nil, errors.Conflict("wallet was not closed by a scheduled closure")
+	}
+	if !closure.CanReopen(time.Now()) {
+		return nil, errors.Conflict("the reopen grace period has ended")
+	}
+
+	reopened, err := s.closures.repo.Reopen(ctx, closure.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishClosureStatus(ctx, walletID, models.WalletStatusClosed, "reopened", "")
+	return reopened, nil
+}
+
+// authorizedClosure retrieves the latest closure of a wallet the
+// authenticated user administers.
+func (s *WalletService) authorizedClosure(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	if s.closures == nil {
+		return nil, errors.Unavailable("scheduled wallet closure is not configured")
+	}
+	if _, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionAdmin); err != nil {
+		return nil, err
+	}
+	return s.closures.repo.GetLatestByWallet(ctx, walletID)
+}
+
+// ExecuteClosureSweep moves a closing wallet's balance out on behalf of the
+// transaction service (internal method).
+func (s *WalletService) ExecuteClosureSweep(ctx context.Context, req *models.ExecuteClosureSweepRequest) *errors.Error {
+	if s.closures == nil {
+		return errors.Unavailable("scheduled wallet closure is not configured")
+	}
+	if req.WalletID == req.DestinationWalletID {
+		return errors.New(errors.ErrCodeTransferSameWallet, "cannot sweep a wallet into itself")
+	}
+
+	if err := s.closures.repo.ExecuteSweep(ctx, req); err != nil {
+		return err
+	}
+
+	if s.eventPublisher != nil {
+		s.publishBalanceUpdate(ctx, req.WalletID, -req.Amount, req.TransactionID)
+		if req.DestinationWalletID != "" {
+			s.publishBalanceUpdate(ctx, req.DestinationWalletID, req.Amount, req.TransactionID)
+		}
+	}
+	return nil
+}
+
+// ProcessDueClosures advances every closure that is due: it releases expired
+// card holds, sweeps the balance and closes the wallet. A closure that cannot
+// finish yet records why and is retried on the next run. It returns how many
+// wallets were closed.
+func (s *WalletService) ProcessDueClosures(ctx context.Context) (int, *errors.Error) {
+	if s.closures == nil {
+		return 0, nil
+	}
+
+	due, err := s.closures.repo.ListDue(ctx, time.Now(), s.closures.policy.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, closure := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if procErr := s.processClosure(ctx, closure); procErr != nil {
+			s.closures.logger.WithError(procErr).With(map[string]interface{}{
+				"closure_id": closure.ID,
+				"wallet_id":  closure.WalletID,
+			}).Warn("Wallet closure not finished")
+			if recordErr := s.closures.repo.RecordError(ctx, closure.ID, procErr.Message); recordErr != nil {
+				s.closures.logger.WithError(recordErr).WithField("closure_id", closure.ID).Error("Failed to record wallet closure error")
+			}
+			continue
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// processClosure settles holds, sweeps the balance and closes the wallet of
+// one due closure.
+func (s *WalletService) processClosure(ctx context.Context, closure *models.WalletClosure) *errors.Error {
+	if err := s.settleClosureHolds(ctx, closure); err != nil {
+		return err
+	}
+
+	wallet, err := s.walletRepo.GetByID(ctx, closure.WalletID)
+	if err != nil {
+		return err
+	}
+	if wallet.Balance > 0 {
+		if sweepErr := s.sweepClosure(ctx, closure, wallet); sweepErr != nil {
+			return sweepErr
+		}
+	}
+
+	reopenUntil := time.Now().Add(s.closures.policy.GracePeriod)
+	closedClosure, err := s.closures.repo.Close(ctx, closure.ID, reopenUntil)
+	if err != nil {
+		return err
+	}
+
+	s.closures.logger.With(map[string]interface{}{
+		"closure_id":   closedClosure.ID,
+		"wallet_id":    closedClosure.WalletID,
+		"swept_amount": closedClosure.SweptAmount,
+		"reopen_until": reopenUntil,
+	}).Info("Wallet closed")
+
+	s.publishClosureStatus(ctx, closure.WalletID, models.WalletStatusPendingClosure, "closed", closure.Reason)
+	s.notifyOwners(ctx, closure.WalletID, "", fmt.Sprintf("Your wallet has been closed. You can reopen it until %s", reopenUntil.Format("2 Jan 2006")))
+	return nil
+}
+
+// settleClosureHolds releases card holds older than the hold expiry and
+// fails while newer holds are still waiting to settle.
+func (s *WalletService) settleClosureHolds(ctx context.Context, closure *models.WalletClosure) *errors.Error {
+	if s.closures.holds == nil {
+		return nil
+	}
+
+	holds, err := s.closures.holds.ListAuthorizedByWallet(ctx, closure.WalletID)
+	if err != nil {
+		return err
+	}
+
+	expiry := time.Now().Add(-s.closures.policy.HoldExpiry)
+	waiting := 0
+	for _, hold := range holds {
+		if hold.CreatedAt.Time.After(expiry) {
+			waiting++
+			continue
+		}
+		if _, reverseErr := s.closures.holds.Reverse(ctx, hold.ID); reverseErr != nil {
+			return reverseErr
+		}
+	}
+
+	if waiting > 0 {
+		return errors.Conflict(fmt.Sprintf("waiting for %d card hold(s) to settle", waiting))
+	}
+	return nil
+}
+
+// sweepClosure moves the wallet's balance out as the closure asks.
+func (s *WalletService) sweepClosure(ctx context.Context, closure *models.WalletClosure, wallet *models.Wallet) *errors.Error {
+	if closure.SweepMethod == nil {
+		return errors.Conflict(fmt.Sprintf("wallet received %s after the closure was scheduled without a sweep method", money.New(wallet.Balance, wallet.Currency)))
+	}
+	if s.closures.sweeper == nil {
+		return errors.Unavailable("closure sweeps are not configured")
+	}
+
+	req := &ClosureSweepRequest{
+		ClosureID: closure.ID,
+		WalletID:  wallet.ID,
+		Amount:    wallet.Balance,
+		Currency:  string(wallet.Currency),
+	}
+	if *closure.SweepMethod == models.SweepMethodWallet && closure.SweepWalletID != nil {
+		req.DestinationWalletID = *closure.SweepWalletID
+	}
+
+	result, err := s.closures.sweeper.SweepClosure(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	s.closures.logger.With(map[string]interface{}{
+		"closure_id":     closure.ID,
+		"wallet_id":      wallet.ID,
+		"amount":         wallet.Balance,
+		"method":         *closure.SweepMethod,
+		"transaction_id": result.ID,
+	}).Info("Wallet closure balance swept")
+	return nil
+}
+
+// publishClosureStatus publishes a wallet's status after a closure step.
+func (s *WalletService) publishClosureStatus(ctx context.Context, walletID string, oldStatus models.WalletStatus, action, reason string) {
+	if s.eventPublisher == nil {
+		return
+	}
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return
+	}
+	s.eventPublisher.PublishAsync("wallets", events.WalletStatusChanged{
+		WalletID:         wallet.ID,
+		UserID:           wallet.UserID,
+		Currency:         string(wallet.Currency),
+		OldStatus:        string(oldStatus),
+		NewStatus:        string(wallet.Status),
+		Balance:          wallet.Balance,
+		AvailableBalance: wallet.AvailableBalance,
+		Action:           action,
+		Reason:           reason,
+	})
+}
@@ -0,0 +1,525 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// ============================================================================
+// Mock Closure Repository
+// ============================================================================
+
+// mockWalletClosureRepository keeps closures in memory and moves balances in
+// the mock wallet repository, like the real repository's transactions.
+type mockWalletClosureRepository struct {
+	wallets  *mockWalletRepository
+	closures []*models.WalletClosure
+}
+
+func newMockWalletClosureRepository(wallets *mockWalletRepository) *mockWalletClosureRepository {
+	return &mockWalletClosureRepository{wallets: wallets}
+}
+
+func (m *mockWalletClosureRepository) Schedule(ctx context.Context, closure *models.WalletClosure) *errors.Error {
+	wallet := m.wallets.wallets[closure.WalletID]
+	if wallet.Status != closure.PreviousStatus {
+		return errors.Conflict("wallet status changed, try again")
+	}
+	wallet.Status = models.WalletStatusPendingClosure
+	closure.ID = fmt.Sprintf("closure-%d", len(m.closures)+1)
+	closure.Status = models.WalletClosureStatusPending
+	stored := *closure
+	m.closures = append(m.closures, &stored)
+	return nil
+}
+
+func (m *mockWalletClosureRepository) GetByID(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	for _, closure := range m.closures {
+		if closure.ID == id {
+			closureCopy := *closure
+			return &closureCopy, nil
+		}
+	}
+	return nil, errors.NotFoundWithID("wallet closure", id)
+}
+
+func (m *mockWalletClosureRepository) GetLatestByWallet(ctx context.Context, walletID string) (*models.WalletClosure, *errors.Error) {
+	for i := len(m.closures) - 1; i >= 0; i-- {
+		if m.closures[i].WalletID == walletID {
+			closureCopy := *m.closures[i]
+			return &closureCopy, nil
+		}
+	}
+	return nil, errors.NotFound("wallet has no closure")
+}
+
+func (m *mockWalletClosureRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*models.WalletClosure, *errors.Error) {
+	due := make([]*models.WalletClosure, 0)
+	for _, closure := range m.closures {
+		if closure.Status == models.WalletClosureStatusPending && !closure.ScheduledFor.Time.After(now) {
+			closureCopy := *closure
+			due = append(due, &closureCopy)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockWalletClosureRepository) RecordError(ctx context.Context, id, message string) *errors.Error {
+	closure := m.get(id)
+	closure.LastError = &message
+	return nil
+}
+
+func (m *mockWalletClosureRepository) Cancel(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	closure := m.get(id)
+	if closure.Status != models.WalletClosureStatusPending {
+		return nil, errors.Conflict("wallet closure can no longer be cancelled")
+	}
+	m.wallets.wallets[closure.WalletID].Status = closure.PreviousStatus
+	closure.Status = models.WalletClosureStatusCancelled
+	closureCopy := *closure
+	return &closureCopy, nil
+}
+
+func (m *mockWalletClosureRepository) ExecuteSweep(ctx context.Context, req *models.ExecuteClosureSweepRequest) *errors.Error {
+	closure := m.get(req.ClosureID)
+	source := m.wallets.wallets[req.WalletID]
+	if source.Status != models.WalletStatusPendingClosure {
+		return errors.New(errors.ErrCodeWalletNotActive, "wallet is not pending closure")
+	}
+	if source.AvailableBalance != source.Balance {
+		return errors.Conflict("wallet has outstanding holds")
+	}
+	if req.Amount != source.Balance {
+		return errors.BadRequest("sweep must move the whole balance")
+	}
+	if req.DestinationWalletID != "" {
+		dest := m.wallets.wallets[req.DestinationWalletID]
+		dest.Balance += req.Amount
+		dest.AvailableBalance += req.Amount
+	}
+	source.Balance -= req.Amount
+	source.AvailableBalance -= req.Amount
+	closure.SweptAmount += req.Amount
+	return nil
+}
+
+func (m *mockWalletClosureRepository) Close(ctx context.Context, id string, reopenUntil time.Time) (*models.WalletClosure, *errors.Error) {
+	closure := m.get(id)
+	wallet := m.wallets.wallets[closure.WalletID]
+	if wallet.Balance != 0 {
+		return nil, errors.Conflict("wallet still has a balance")
+	}
+	wallet.Status = models.WalletStatusClosed
+	closedAt := sharedModels.Now()
+	until := sharedModels.NewTimestamp(reopenUntil)
+	closure.Status = models.WalletClosureStatusClosed
+	closure.ClosedAt = &closedAt
+	closure.ReopenUntil = &until
+	closureCopy := *closure
+	return &closureCopy, nil
+}
+
+func (m *mockWalletClosureRepository) Reopen(ctx context.Context, id string) (*models.WalletClosure, *errors.Error) {
+	closure := m.get(id)
+	if !closure.CanReopen(time.Now()) {
+		return nil, errors.Conflict("wallet can no longer be reopened")
+	}
+	m.wallets.wallets[closure.WalletID].Status = closure.PreviousStatus
+	closure.Status = models.WalletClosureStatusReopened
+	closureCopy := *closure
+	return &closureCopy, nil
+}
+
+func (m *mockWalletClosureRepository) get(id string) *models.WalletClosure {
+	for _, closure := range m.closures {
+		if closure.ID == id {
+			return closure
+		}
+	}
+	return nil
+}
+
+// mockCardHolds holds card authorizations; reversing one releases its hold.
+type mockCardHolds struct {
+	wallets *mockWalletRepository
+	holds   []*models.CardAuthorization
+}
+
+func (m *mockCardHolds) ListAuthorizedByWallet(ctx context.Context, walletID string) ([]*models.CardAuthorization, *errors.Error) {
+	result := make([]*models.CardAuthorization, 0)
+	for _, hold := range m.holds {
+		if hold.WalletID == walletID && hold.Status == models.CardAuthorizationAuthorized {
+			result = append(result, hold)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCardHolds) Reverse(ctx context.Context, id string) (*models.CardAuthorization, *errors.Error) {
+	for _, hold := range m.holds {
+		if hold.ID == id {
+			hold.Status = models.CardAuthorizationReversed
+			m.wallets.wallets[hold.WalletID].AvailableBalance += hold.Amount
+			return hold, nil
+		}
+	}
+	return nil, errors.NotFoundWithID("card authorization", id)
+}
+
+// mockClosureSweeper stands in for the transaction service, which records the
+// sweep and calls back into the wallet service to move the money.
+type mockClosureSweeper struct {
+	service  *WalletService
+	requests []*ClosureSweepRequest
+}
+
+func (m *mockClosureSweeper) SweepClosure(ctx context.Context, req *ClosureSweepRequest) (*ClosureSweepResult, *errors.Error) {
+	m.requests = append(m.requests, req)
+	transactionID := fmt.Sprintf("txn-%d", len(m.requests))
+	if err := m.service.ExecuteClosureSweep(ctx, &models.ExecuteClosureSweepRequest{
+		ClosureID:           req.ClosureID,
+		WalletID:            req.WalletID,
+		DestinationWalletID: req.DestinationWalletID,
+		Amount:              req.Amount,
+		TransactionID:       transactionID,
+	}); err != nil {
+		return nil, err
+	}
+	return &ClosureSweepResult{ID: transactionID, Status: "completed"}, nil
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// newClosureTestService returns a joint wallet service (see
+// newJointWalletService) where wallet-1 holds 5000 paise, and user-1 is also
+// a view co-owner of user-2's active INR wallet "wallet-2".
+func newClosureTestService() (*WalletService, *mockWalletRepository, *mockWalletClosureRepository, *mockCardHolds, *mockClosureSweeper) {
+	service, repo, members := newJointWalletService()
+	repo.wallets["wallet-1"].Balance = 5000
+	repo.wallets["wallet-1"].AvailableBalance = 5000
+	repo.wallets["wallet-2"] = &models.Wallet{
+		ID:       "wallet-2",
+		UserID:   "user-2",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+		Status:   models.WalletStatusActive,
+	}
+	members.members["wallet-2/user-1"] = &models.WalletMember{
+		ID:         "member-user-1",
+		WalletID:   "wallet-2",
+		UserID:     "user-1",
+		Permission: models.WalletPermissionView,
+		Status:     models.WalletMemberStatusActive,
+		InvitedBy:  "user-2",
+	}
+
+	closures := newMockWalletClosureRepository(repo)
+	holds := &mockCardHolds{wallets: repo}
+	sweeper := &mockClosureSweeper{service: service}
+	service.SetClosures(closures, holds, sweeper, DefaultClosurePolicy())
+	return service, repo, closures, holds, sweeper
+}
+
+// ============================================================================
+// Tests
+// ============================================================================
+
+func TestScheduleClosure_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		setup    func(repo *mockWalletRepository)
+		req      models.ScheduleClosureRequest
+		wantCode errors.ErrorCode
+	}{
+		{
+			name:     "balance needs a sweep method",
+			userID:   "user-1",
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank"},
+			wantCode: errors.ErrCodeValidation,
+		},
+		{
+			name:     "wallet sweep needs a target",
+			userID:   "user-1",
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWallet},
+			wantCode: errors.ErrCodeValidation,
+		},
+		{
+			name:     "cannot sweep into itself",
+			userID:   "user-1",
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWallet, SweepWalletID: "wallet-1"},
+			wantCode: errors.ErrCodeValidation,
+		},
+		{
+			name:   "target must be owned or co-owned",
+			userID: "user-1",
+			setup: func(repo *mockWalletRepository) {
+				repo.wallets["wallet-3"] = &models.Wallet{ID: "wallet-3", UserID: "user-3", Currency: "INR", Status: models.WalletStatusActive}
+			},
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWallet, SweepWalletID: "wallet-3"},
+			wantCode: errors.ErrCodeForbidden,
+		},
+		{
+			name:   "target must share the currency",
+			userID: "user-1",
+			setup: func(repo *mockWalletRepository) {
+				repo.wallets["wallet-2"].Currency = "USD"
+			},
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWallet, SweepWalletID: "wallet-2"},
+			wantCode: errors.ErrCodeWalletCurrencyMismatch,
+		},
+		{
+			name:   "frozen wallets cannot be closed",
+			userID: "user-1",
+			setup: func(repo *mockWalletRepository) {
+				repo.wallets["wallet-1"].Status = models.WalletStatusFrozen
+			},
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWithdrawal},
+			wantCode: errors.ErrCodeBadRequest,
+		},
+		{
+			name:     "only admins can close",
+			userID:   "user-2",
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWithdrawal},
+			wantCode: errors.ErrCodeForbidden,
+		},
+		{
+			name:     "close_at is bounded",
+			userID:   "user-1",
+			req:      models.ScheduleClosureRequest{Reason: "moving to another bank", SweepMethod: models.SweepMethodWithdrawal, CloseAt: timePtr(time.Now().Add(365 * 24 * time.Hour))},
+			wantCode: errors.ErrCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, closures, _, _ := newClosureTestService()
+			if tt.setup != nil {
+				tt.setup(repo)
+			}
+
+			_, err := service.ScheduleClosure(asUser(tt.userID), "wallet-1", &tt.req)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if err.Code != tt.wantCode {
+				t.Errorf("expected %s, got %s: %s", tt.wantCode, err.Code, err.Message)
+			}
+			if len(closures.closures) != 0 {
+				t.Error("expected no closure to be scheduled")
+			}
+		})
+	}
+}
+
+func TestProcessDueClosures_SweepsToCoOwnedWallet(t *testing.T) {
+	service, repo, closures, _, sweeper := newClosureTestService()
+	ctx := asUser("user-1")
+
+	closure, err := service.ScheduleClosure(ctx, "wallet-1", &models.ScheduleClosureRequest{
+		Reason:        "moving to our joint wallet",
+		SweepMethod:   models.SweepMethodWallet,
+		SweepWalletID: "wallet-2",
+	})
+	if err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+	if repo.wallets["wallet-1"].Status != models.WalletStatusPendingClosure {
+		t.Fatalf("expected wallet pending closure, got %s", repo.wallets["wallet-1"].Status)
+	}
+
+	closed, err := service.ProcessDueClosures(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessDueClosures() error = %v", err)
+	}
+	if closed != 1 {
+		t.Fatalf("expected 1 wallet closed, got %d", closed)
+	}
+
+	if len(sweeper.requests) != 1 || sweeper.requests[0].DestinationWalletID != "wallet-2" || sweeper.requests[0].Amount != 5000 {
+		t.Fatalf("unexpected sweep requests: %+v", sweeper.requests)
+	}
+	if got := repo.wallets["wallet-2"].Balance; got != 5000 {
+		t.Errorf("expected sweep wallet balance 5000, got %d", got)
+	}
+	if repo.wallets["wallet-1"].Status != models.WalletStatusClosed || repo.wallets["wallet-1"].Balance != 0 {
+		t.Errorf("expected empty closed wallet, got %s with %d", repo.wallets["wallet-1"].Status, repo.wallets["wallet-1"].Balance)
+	}
+
+	stored := closures.get(closure.ID)
+	if stored.Status != models.WalletClosureStatusClosed || stored.SweptAmount != 5000 {
+		t.Errorf("unexpected closure state: %s, swept %d", stored.Status, stored.SweptAmount)
+	}
+	if stored.ReopenUntil == nil || stored.ReopenUntil.Time.Before(time.Now().Add(29*24*time.Hour)) {
+		t.Errorf("expected a 30 day reopen grace period, got %v", stored.ReopenUntil)
+	}
+}
+
+func TestProcessDueClosures_WaitsForRecentHolds(t *testing.T) {
+	service, repo, closures, holds, sweeper := newClosureTestService()
+
+	// A fresh 1000 hold waits to settle; a stale 500 hold is released
+	repo.wallets["wallet-1"].AvailableBalance = 3500
+	holds.holds = []*models.CardAuthorization{
+		{ID: "auth-recent", WalletID: "wallet-1", Amount: 1000, Status: models.CardAuthorizationAuthorized, CreatedAt: sharedModels.NewTimestamp(time.Now().Add(-time.Hour))},
+		{ID: "auth-stale", WalletID: "wallet-1", Amount: 500, Status: models.CardAuthorizationAuthorized, CreatedAt: sharedModels.NewTimestamp(time.Now().Add(-8 * 24 * time.Hour))},
+	}
+
+	closure, err := service.ScheduleClosure(asUser("user-1"), "wallet-1", &models.ScheduleClosureRequest{
+		Reason:      "closing my account",
+		SweepMethod: models.SweepMethodWithdrawal,
+	})
+	if err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+
+	closed, _ := service.ProcessDueClosures(context.Background())
+	if closed != 0 {
+		t.Fatalf("expected the closure to wait for the recent hold, closed %d", closed)
+	}
+	if holds.holds[1].Status != models.CardAuthorizationReversed {
+		t.Error("expected the stale hold to be reversed")
+	}
+	if holds.holds[0].Status != models.CardAuthorizationAuthorized {
+		t.Error("expected the recent hold to be left to settle")
+	}
+	if stored := closures.get(closure.ID); stored.LastError == nil || stored.Status != models.WalletClosureStatusPending {
+		t.Errorf("expected a pending closure with the reason recorded, got %+v", stored)
+	}
+	if len(sweeper.requests) != 0 {
+		t.Error("expected no sweep while holds are outstanding")
+	}
+
+	// Once the network settles the hold, the next run withdraws the rest and closes
+	holds.holds[0].Status = models.CardAuthorizationSettled
+	repo.wallets["wallet-1"].Balance = 4000
+	repo.wallets["wallet-1"].AvailableBalance = 4000
+
+	closed, _ = service.ProcessDueClosures(context.Background())
+	if closed != 1 {
+		t.Fatalf("expected the wallet to close, closed %d", closed)
+	}
+	if len(sweeper.requests) != 1 || sweeper.requests[0].DestinationWalletID != "" || sweeper.requests[0].Amount != 4000 {
+		t.Errorf("expected a 4000 withdrawal, got %+v", sweeper.requests)
+	}
+}
+
+func TestProcessDueClosures_NotBeforeScheduled(t *testing.T) {
+	service, repo, _, _, _ := newClosureTestService()
+
+	_, err := service.ScheduleClosure(asUser("user-1"), "wallet-1", &models.ScheduleClosureRequest{
+		Reason:      "closing at month end",
+		SweepMethod: models.SweepMethodWithdrawal,
+		CloseAt:     timePtr(time.Now().Add(48 * time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+
+	if closed, _ := service.ProcessDueClosures(context.Background()); closed != 0 {
+		t.Errorf("expected no closures before the scheduled time, closed %d", closed)
+	}
+	if repo.wallets["wallet-1"].Balance != 5000 {
+		t.Error("expected the balance to stay until the closure is due")
+	}
+}
+
+func TestCancelClosure_RestoresStatus(t *testing.T) {
+	service, repo, _, _, _ := newClosureTestService()
+	ctx := asUser("user-1")
+
+	if _, err := service.ScheduleClosure(ctx, "wallet-1", &models.ScheduleClosureRequest{
+		Reason:      "closing my account",
+		SweepMethod: models.SweepMethodWithdrawal,
+		CloseAt:     timePtr(time.Now().Add(time.Hour)),
+	}); err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+
+	closure, err := service.CancelClosure(ctx, "wallet-1")
+	if err != nil {
+		t.Fatalf("CancelClosure() error = %v", err)
+	}
+	if closure.Status != models.WalletClosureStatusCancelled {
+		t.Errorf("expected cancelled closure, got %s", closure.Status)
+	}
+	if repo.wallets["wallet-1"].Status != models.WalletStatusActive {
+		t.Errorf("expected wallet active again, got %s", repo.wallets["wallet-1"].Status)
+	}
+
+	if _, err := service.CancelClosure(ctx, "wallet-1"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict cancelling twice, got %v", err)
+	}
+}
+
+func TestReopenWallet_GracePeriod(t *testing.T) {
+	service, repo, closures, _, _ := newClosureTestService()
+	ctx := asUser("user-1")
+	repo.wallets["wallet-1"].Balance = 0
+	repo.wallets["wallet-1"].AvailableBalance = 0
+
+	// A wallet without a balance needs no sweep method
+	closure, err := service.ScheduleClosure(ctx, "wallet-1", &models.ScheduleClosureRequest{Reason: "no longer needed"})
+	if err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+	if _, err := service.ProcessDueClosures(context.Background()); err != nil {
+		t.Fatalf("ProcessDueClosures() error = %v", err)
+	}
+
+	if _, err := service.ReopenWallet(asUser("user-2"), "wallet-1"); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected non-admins to be refused, got %v", err)
+	}
+
+	reopened, err := service.ReopenWallet(ctx, "wallet-1")
+	if err != nil {
+		t.Fatalf("ReopenWallet() error = %v", err)
+	}
+	if reopened.Status != models.WalletClosureStatusReopened || repo.wallets["wallet-1"].Status != models.WalletStatusActive {
+		t.Errorf("expected reopened active wallet, got %s / %s", reopened.Status, repo.wallets["wallet-1"].Status)
+	}
+
+	// Close again and let the grace period run out
+	if _, err := service.ScheduleClosure(ctx, "wallet-1", &models.ScheduleClosureRequest{Reason: "no longer needed"}); err != nil {
+		t.Fatalf("ScheduleClosure() error = %v", err)
+	}
+	if _, err := service.ProcessDueClosures(context.Background()); err != nil {
+		t.Fatalf("ProcessDueClosures() error = %v", err)
+	}
+	expired := sharedModels.NewTimestamp(time.Now().Add(-time.Minute))
+	latest := closures.closures[len(closures.closures)-1]
+	if latest.ID == closure.ID {
+		t.Fatal("expected a second closure")
+	}
+	latest.ReopenUntil = &expired
+
+	if _, err := service.ReopenWallet(ctx, "wallet-1"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict after the grace period, got %v", err)
+	}
+	if repo.wallets["wallet-1"].Status != models.WalletStatusClosed {
+		t.Errorf("expected wallet to stay closed, got %s", repo.wallets["wallet-1"].Status)
+	}
+}
+
+func TestCloseWallet_RejectsPendingClosure(t *testing.T) {
+	service, repo, _, _, _ := newClosureTestService()
+	repo.wallets["wallet-1"].Status = models.WalletStatusPendingClosure
+	repo.wallets["wallet-1"].Balance = 0
+
+	if _, err := service.CloseWallet(context.Background(), "wallet-1", "support closure"); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict closing a wallet with a scheduled closure, got %v", err)
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
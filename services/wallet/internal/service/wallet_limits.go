@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// limitResetBatchSize bounds the rows rolled in one reset transaction.
+const limitResetBatchSize = 500
+
+// SetLimitsLocation sets the timezone whose midnight ends daily and monthly
+// limit windows. It must match the repository's.
+func (s *WalletService) SetLimitsLocation(loc *time.Location) {
+	s.limitsLoc = loc
+}
+
+// GetRemainingLimits returns how much a wallet can still transfer today and
+// this month, and when each window resets.
+func (s *WalletService) GetRemainingLimits(ctx context.Context, walletID string) (*models.RemainingLimits, *errors.Error) {
+	limits, err := s.GetWalletLimits(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RemainingLimits{
+		WalletID:         walletID,
+		DailyLimit:       limits.DailyLimit,
+		DailySpent:       limits.DailySpent,
+		DailyRemaining:   limits.DailyRemaining(),
+		DailyResetAt:     limits.DailyResetAt,
+		MonthlyLimit:     limits.MonthlyLimit,
+		MonthlySpent:     limits.MonthlySpent,
+		MonthlyRemaining: limits.MonthlyRemaining(),
+		MonthlyResetAt:   limits.MonthlyResetAt,
+		Timezone:         s.limitsLoc.String(),
+	}, nil
+}
+
+// ResetExpiredLimits rolls every wallet's ended limit windows, in batches,
+// and returns how many wallets were rolled. Reads and reservations roll
+// windows lazily too, so this only keeps stored spend from going stale.
+func (s *WalletService) ResetExpiredLimits(ctx context.Context) (int, *errors.Error) {
+	now := time.Now()
+	total := 0
+	for {
+		n, err := s.walletRepo.ResetExpiredLimits(ctx, now, limitResetBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < limitResetBatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	sharedModels "github.com/1mb-dev/nivomoney/shared/models"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func TestNextResets_FollowLocalMidnight(t *testing.T) {
+	ist := mustLoadLocation(t, "Asia/Kolkata")
+
+	// 20:00 UTC on Jan 31 is already 01:30 on Feb 1 in India
+	now := time.Date(2026, 1, 31, 20, 0, 0, 0, time.UTC)
+
+	if got, want := models.NextDailyReset(now, ist), time.Date(2026, 2, 2, 0, 0, 0, 0, ist); !got.Equal(want) {
+		t.Errorf("expected daily reset %s, got %s", want, got)
+	}
+	if got, want := models.NextMonthlyReset(now, ist), time.Date(2026, 3, 1, 0, 0, 0, 0, ist); !got.Equal(want) {
+		t.Errorf("expected monthly reset %s, got %s", want, got)
+	}
+	if got, want := models.NextDailyReset(now, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected UTC daily reset %s, got %s", want, got)
+	}
+}
+
+func TestNextDailyReset_AcrossDSTChange(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+
+	// March 8, 2026 is 23 hours long in New York
+	now := time.Date(2026, 3, 8, 12, 0, 0, 0, ny)
+	want := time.Date(2026, 3, 9, 0, 0, 0, 0, ny)
+	if got := models.NextDailyReset(now, ny); !got.Equal(want) {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestWalletLimitsRoll(t *testing.T) {
+	now := time.Date(2026, 5, 10, 9, 0, 0, 0, time.UTC)
+	limits := &models.WalletLimits{
+		DailyLimit:     100000,
+		DailySpent:     60000,
+		DailyResetAt:   sharedModels.NewTimestamp(now.Add(-3 * 24 * time.Hour)), // Several days idle
+		MonthlyLimit:   500000,
+		MonthlySpent:   200000,
+		MonthlyResetAt: sharedModels.NewTimestamp(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	if !limits.Roll(now, time.UTC) {
+		t.Fatal("expected the ended daily window to roll")
+	}
+	if limits.DailySpent != 0 || !limits.DailyResetAt.Time.Equal(time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected daily spend cleared until the next midnight, got %d until %s", limits.DailySpent, limits.DailyResetAt)
+	}
+	if limits.MonthlySpent != 200000 {
+		t.Errorf("expected the open monthly window to keep its spend, got %d", limits.MonthlySpent)
+	}
+	if limits.Roll(now, time.UTC) {
+		t.Error("expected rolling again in the same window to change nothing")
+	}
+}
+
+// batchResetRepository reports full batches until remaining runs out.
+type batchResetRepository struct {
+	*mockWalletRepository
+	remaining int
+	calls     int
+}
+
+func (m *batchResetRepository) ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error) {
+	m.calls++
+	n := min(m.remaining, batchSize)
+	m.remaining -= n
+	return n, nil
+}
+
+func TestResetExpiredLimits_DrainsBatches(t *testing.T) {
+	repo := &batchResetRepository{mockWalletRepository: newMockWalletRepository(), remaining: limitResetBatchSize*2 + 7}
+	service := NewWalletService(repo, nil, nil, nil, nil)
+
+	reset, err := service.ResetExpiredLimits(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if reset != limitResetBatchSize*2+7 || repo.calls != 3 {
+		t.Errorf("expected %d wallets over 3 batches, got %d over %d", limitResetBatchSize*2+7, reset, repo.calls)
+	}
+}
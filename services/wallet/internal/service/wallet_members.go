@@ -0,0 +1,412 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/clients"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// staffWalletPermission lets back-office staff and internal tooling act on
+// any wallet without being one of its owners.
+const staffWalletPermission = "wallet:wallet:list"
+
+// WalletMemberRepositoryInterface defines the interface for joint wallet co-owner operations.
+type WalletMemberRepositoryInterface interface {
+	Invite(ctx context.Context, member *models.WalletMember) *errors.Error
+	Get(ctx context.Context, walletID, userID string) (*models.WalletMember, *errors.Error)
+	ListByWallet(ctx context.Context, walletID string) ([]*models.WalletMember, *errors.Error)
+	ListByUser(ctx context.Context, userID string, status models.WalletMemberStatus) ([]*models.WalletMember, *errors.Error)
+	UpdateStatus(ctx context.Context, walletID, userID string, from, to models.WalletMemberStatus) *errors.Error
+	UpdatePermission(ctx context.Context, walletID, userID string, permission models.WalletPermission) *errors.Error
+}
+
+// SetJointWallets enables joint wallets: owners can invite co-owners, found
+// by phone through users, with view, transact or admin permission.
+func (s *WalletService) SetJointWallets(memberRepo WalletMemberRepositoryInterface, users UserLookupClient) {
+	s.memberRepo = memberRepo
+	s.userLookup = users
+}
+
+// AuthorizeWallet checks that the authenticated user may act on wallet with
+// the required permission. The primary owner holds every permission; without
+// joint wallets nobody else does, except staff.
+func (s *WalletService) AuthorizeWallet(ctx context.Context, wallet *models.Wallet, required models.WalletPermission) *errors.Error {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok || userID == "" {
+		return errors.Unauthorized("user not authenticated")
+	}
+	if wallet.UserID == userID || middleware.HasPermission(ctx, staffWalletPermission) {
+		return nil
+	}
+
+	permission, err := s.memberPermission(ctx, wallet.ID, userID)
+	if err != nil {
+		return err
+	}
+	if permission == "" {
+		return errors.Forbidden("you do not have access to this wallet")
+	}
+	if !permission.Allows(required) {
+		return errors.Forbidden(fmt.Sprintf("this action requires %s permission on the wallet", required))
+	}
+	return nil
+}
+
+// GetAuthorizedWallet retrieves a wallet the authenticated user may act on
+// with the required permission.
+func (s *WalletService) GetAuthorizedWallet(ctx context.Context, walletID string, required models.WalletPermission) (*models.Wallet, *errors.Error) {
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if authErr := s.AuthorizeWallet(ctx, wallet, required); authErr != nil {
+		return nil, authErr
+	}
+	return wallet, nil
+}
+
+// memberPermission returns a user's permission as an active co-owner of a
+// wallet, or an empty permission if they are not one.
+func (s *WalletService) memberPermission(ctx context.Context, walletID, userID string) (models.WalletPermission, *errors.Error) {
+	if s.memberRepo == nil {
+		return "", nil
+	}
+	member, err := s.memberRepo.Get(ctx, walletID, userID)
+	if err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	if !member.IsActive() {
+		return "", nil
+	}
+	return member.Permission, nil
+}
+
+// ListAccessibleWallets retrieves the wallets a user owns followed by the
+// joint wallets they are an active co-owner of.
+func (s *WalletService) ListAccessibleWallets(ctx context.Context, userID string, status *models.WalletStatus) ([]*models.Wallet, *errors.Error) {
+	wallets, err := s.walletRepo.ListByUserID(ctx, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	if s.memberRepo == nil {
+		return wallets, nil
+	}
+
+	memberships, err := s.memberRepo.ListByUser(ctx, userID, models.WalletMemberStatusActive)
+	if err != nil {
+		return nil, err
+	}
+	for _, membership := range memberships {
+		wallet, getErr := s.walletRepo.GetByID(ctx, membership.WalletID)
+		if getErr != nil {
+			if getErr.Code == errors.ErrCodeNotFound {
+				continue
+			}
+			return nil, getErr
+		}
+		if status == nil || wallet.Status == *status {
+			wallets = append(wallets, wallet)
+		}
+	}
+	return wallets, nil
+}
+
+// ListWalletMembers retrieves a wallet's active co-owners.
+func (s *WalletService) ListWalletMembers(ctx context.Context, walletID string) ([]*models.WalletMember, *errors.Error) {
+	if s.memberRepo == nil {
+		return []*models.WalletMember{}, nil
+	}
+	members, err := s.memberRepo.ListByWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	active := make([]*models.WalletMember, 0, len(members))
+	for _, member := range members {
+		if member.IsActive() {
+			active = append(active, member)
+		}
+	}
+	return active, nil
+}
+
+// ListOwners lists a wallet's primary owner, co-owners and pending invites.
+// Any owner of the wallet may list them.
+func (s *WalletService) ListOwners(ctx context.Context, walletID string) ([]*models.WalletOwner, *errors.Error) {
+	wallet, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionView)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := []*models.WalletOwner{{
+		UserID:     wallet.UserID,
+		Permission: models.WalletPermissionAdmin,
+		Status:     models.WalletMemberStatusActive,
+		Primary:    true,
+	}}
+	if s.memberRepo == nil {
+		return owners, nil
+	}
+
+	members, err := s.memberRepo.ListByWallet(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		owners = append(owners, &models.WalletOwner{
+			UserID:     member.UserID,
+			Permission: member.Permission,
+			Status:     member.Status,
+		})
+	}
+	return owners, nil
+}
+
+// InviteCoOwner invites the user with the given phone number to a wallet.
+// Only wallet admins can invite, and the invite takes effect once accepted.
+func (s *WalletService) InviteCoOwner(ctx context.Context, walletID string, req *models.InviteCoOwnerRequest) (*models.WalletMember, *errors.Error) {
+	if s.memberRepo == nil || s.userLookup == nil {
+		return nil, errors.Unavailable("joint wallets are not enabled")
+	}
+	if !req.Permission.IsValid() {
+		return nil, errors.Validation("permission must be one of view, transact or admin")
+	}
+
+	wallet, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Status == models.WalletStatusClosed {
+		return nil, errors.BadRequest("cannot invite co-owners to a closed wallet")
+	}
+
+	invitee, err := s.userLookup.LookupUserByPhone(ctx, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == wallet.UserID {
+		return nil, errors.BadRequest("the primary owner is already an owner of this wallet")
+	}
+
+	inviterID, _ := middleware.GetUserID(ctx)
+	member := &models.WalletMember{
+		WalletID:   walletID,
+		UserID:     invitee.ID,
+		Permission: req.Permission,
+		InvitedBy:  inviterID,
+	}
+	if inviteErr := s.memberRepo.Invite(ctx, member); inviteErr != nil {
+		return nil, inviteErr
+	}
+
+	s.publishMemberEvent("wallet.member.invited", member)
+	s.notifyUsers(walletID, []string{invitee.ID},
+		fmt.Sprintf("You were invited to co-own a %s wallet with %s permission. Accept or decline the invite in the app", wallet.Currency, member.Permission))
+
+	return member, nil
+}
+
+// ListInvites retrieves the authenticated user's pending wallet invites.
+func (s *WalletService) ListInvites(ctx context.Context, userID string) ([]*models.WalletMember, *errors.Error) {
+	if s.memberRepo == nil {
+		return []*models.WalletMember{}, nil
+	}
+	return s.memberRepo.ListByUser(ctx, userID, models.WalletMemberStatusInvited)
+}
+
+// RespondToInvite accepts or declines the user's pending invite to a wallet.
+func (s *WalletService) RespondToInvite(ctx context.Context, walletID, userID string, accept bool) (*models.WalletMember, *errors.Error) {
+	if s.memberRepo == nil {
+		return nil, errors.NotFound("invite not found")
+	}
+
+	to := models.WalletMemberStatusDeclined
+	if accept {
+		to = models.WalletMemberStatusActive
+	}
+	if err := s.memberRepo.UpdateStatus(ctx, walletID, userID, models.WalletMemberStatusInvited, to); err != nil {
+		if err.Code == errors.ErrCodeNotFound {
+			return nil, errors.NotFound("invite not found")
+		}
+		return nil, err
+	}
+
+	member, err := s.memberRepo.Get(ctx, walletID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if accept {
+		s.publishMemberEvent("wallet.member.joined", member)
+		s.notifyOwners(ctx, walletID, userID, "A new co-owner joined your shared wallet")
+	} else {
+		s.publishMemberEvent("wallet.member.declined", member)
+		s.notifyUsers(walletID, []string{member.InvitedBy}, "Your invite to co-own a shared wallet was declined")
+	}
+	return member, nil
+}
+
+// UpdateCoOwnerPermission changes a co-owner's permission. Only wallet
+// admins can change permissions.
+func (s *WalletService) UpdateCoOwnerPermission(ctx context.Context, walletID, memberUserID string, req *models.UpdateCoOwnerRequest) (*models.WalletMember, *errors.Error) {
+	if s.memberRepo == nil {
+		return nil, errors.NotFound("co-owner not found")
+	}
+	if !req.Permission.IsValid() {
+		return nil, errors.Validation("permission must be one of view, transact or admin")
+	}
+
+	wallet, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if memberUserID == wallet.UserID {
+		return nil, errors.BadRequest("the primary owner's permission cannot be changed")
+	}
+
+	if updateErr := s.memberRepo.UpdatePermission(ctx, walletID, memberUserID, req.Permission); updateErr != nil {
+		return nil, updateErr
+	}
+
+	member, err := s.memberRepo.Get(ctx, walletID, memberUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishMemberEvent("wallet.member.updated", member)
+	s.notifyOwners(ctx, walletID, "", fmt.Sprintf("A co-owner's permission on your shared wallet was changed to %s", member.Permission))
+	return member, nil
+}
+
+// RemoveCoOwner removes a co-owner or cancels a pending invite. Admins can
+// remove anyone but the primary owner; any co-owner can remove themselves to
+// leave the wallet.
+func (s *WalletService) RemoveCoOwner(ctx context.Context, walletID, memberUserID string) *errors.Error {
+	if s.memberRepo == nil {
+		return errors.NotFound("co-owner not found")
+	}
+
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if memberUserID == wallet.UserID {
+		return errors.BadRequest("the primary owner cannot be removed")
+	}
+	if userID, _ := middleware.GetUserID(ctx); userID != memberUserID {
+		if authErr := s.AuthorizeWallet(ctx, wallet, models.WalletPermissionAdmin); authErr != nil {
+			return authErr
+		}
+	}
+
+	member, err := s.memberRepo.Get(ctx, walletID, memberUserID)
+	if err != nil {
+		return err
+	}
+	if member.Status != models.WalletMemberStatusActive && member.Status != models.WalletMemberStatusInvited {
+		return errors.NotFound("co-owner not found")
+	}
+	if updateErr := s.memberRepo.UpdateStatus(ctx, walletID, memberUserID, member.Status, models.WalletMemberStatusRemoved); updateErr != nil {
+		return updateErr
+	}
+
+	member.Status = models.WalletMemberStatusRemoved
+	s.publishMemberEvent("wallet.member.removed", member)
+	s.notifyUsers(walletID, []string{memberUserID}, "You are no longer a co-owner of a shared wallet")
+	s.notifyOwners(ctx, walletID, "", "A co-owner left your shared wallet")
+	return nil
+}
+
+// notifyWalletActivity tells every owner of a joint wallet that money moved.
+// Wallets without co-owners are left to the usual transaction alerts.
+func (s *WalletService) notifyWalletActivity(ctx context.Context, walletID, message string) {
+	if s.memberRepo == nil || s.notificationClient == nil {
+		return
+	}
+	members, err := s.ListWalletMembers(ctx, walletID)
+	if err != nil || len(members) == 0 {
+		return
+	}
+	s.notifyOwners(ctx, walletID, "", message)
+}
+
+// notifyOwners notifies the primary owner and active co-owners of a wallet,
+// except the user whose action is being reported.
+func (s *WalletService) notifyOwners(ctx context.Context, walletID, exceptUserID, message string) {
+	if s.notificationClient == nil {
+		return
+	}
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return
+	}
+	members, err := s.ListWalletMembers(ctx, walletID)
+	if err != nil {
+		return
+	}
+
+	recipients := make([]string, 0, len(members)+1)
+	for _, userID := range append([]string{wallet.UserID}, memberUserIDs(members)...) {
+		if userID != exceptUserID {
+			recipients = append(recipients, userID)
+		}
+	}
+	s.notifyUsers(walletID, recipients, message)
+}
+
+// notifyUsers sends a shared wallet push notification to each user.
+func (s *WalletService) notifyUsers(walletID string, userIDs []string, message string) {
+	if s.notificationClient == nil {
+		return
+	}
+	correlationID := fmt.Sprintf("wallet-%s", walletID)
+	for _, userID := range userIDs {
+		if userID == "" {
+			continue
+		}
+		recipient := userID
+		s.notificationClient.SendNotificationAsync(&clients.SendNotificationRequest{
+			UserID:     &recipient,
+			Recipient:  recipient,
+			Channel:    clients.NotificationChannelPush,
+			Type:       clients.NotificationTypeTransactionAlert,
+			Priority:   clients.NotificationPriorityNormal,
+			TemplateID: "shared_wallet_push",
+			Variables: map[string]any{
+				"message": message,
+			},
+			CorrelationID: &correlationID,
+			SourceService: "wallet",
+			Metadata: map[string]any{
+				"wallet_id": walletID,
+			},
+		}, "wallet")
+	}
+}
+
+func (s *WalletService) publishMemberEvent(eventType string, member *models.WalletMember) {
+	if s.eventPublisher == nil {
+		return
+	}
+	s.eventPublisher.PublishWalletEvent(eventType, member.WalletID, map[string]interface{}{
+		"wallet_id":  member.WalletID,
+		"user_id":    member.UserID,
+		"permission": member.Permission,
+		"status":     member.Status,
+		"invited_by": member.InvitedBy,
+	})
+}
+
+func memberUserIDs(members []*models.WalletMember) []string {
+	ids := make([]string, len(members))
+	for i, member := range members {
+		ids[i] = member.UserID
+	}
+	return ids
+}
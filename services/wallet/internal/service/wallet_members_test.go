@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// ============================================================================
+// Mock Member Repository
+// ============================================================================
+
+type mockWalletMemberRepository struct {
+	members map[string]*models.WalletMember // keyed by wallet ID + user ID
+}
+
+func newMockWalletMemberRepository() *mockWalletMemberRepository {
+	return &mockWalletMemberRepository{
+		members: make(map[string]*models.WalletMember),
+	}
+}
+
+func (m *mockWalletMemberRepository) Invite(ctx context.Context, member *models.WalletMember) *errors.Error {
+	key := member.WalletID + "/" + member.UserID
+	if existing, ok := m.members[key]; ok && (existing.Status == models.WalletMemberStatusInvited || existing.IsActive()) {
+		return errors.Conflict("this user is already a co-owner or has a pending invite")
+	}
+	member.ID = "member-" + member.UserID
+	member.Status = models.WalletMemberStatusInvited
+	stored := *member
+	m.members[key] = &stored
+	return nil
+}
+
+func (m *mockWalletMemberRepository) Get(ctx context.Context, walletID, userID string) (*models.WalletMember, *errors.Error) {
+	member, ok := m.members[walletID+"/"+userID]
+	if !ok {
+		return nil, errors.NotFound("co-owner not found")
+	}
+	memberCopy := *member
+	return &memberCopy, nil
+}
+
+func (m *mockWalletMemberRepository) ListByWallet(ctx context.Context, walletID string) ([]*models.WalletMember, *errors.Error) {
+	result := make([]*models.WalletMember, 0)
+	for _, member := range m.members {
+		if member.WalletID == walletID && (member.Status == models.WalletMemberStatusInvited || member.IsActive()) {
+			result = append(result, member)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWalletMemberRepository) ListByUser(ctx context.Context, userID string, status models.WalletMemberStatus) ([]*models.WalletMember, *errors.Error) {
+	result := make([]*models.WalletMember, 0)
+	for _, member := range m.members {
+		if member.UserID == userID && member.Status == status {
+			result = append(result, member)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWalletMemberRepository) UpdateStatus(ctx context.Context, walletID, userID string, from, to models.WalletMemberStatus) *errors.Error {
+	member, ok := m.members[walletID+"/"+userID]
+	if !ok || member.Status != from {
+		return errors.NotFound("co-owner not found")
+	}
+	member.Status = to
+	return nil
+}
+
+func (m *mockWalletMemberRepository) UpdatePermission(ctx context.Context, walletID, userID string, permission models.WalletPermission) *errors.Error {
+	member, ok := m.members[walletID+"/"+userID]
+	if !ok || (member.Status != models.WalletMemberStatusInvited && !member.IsActive()) {
+		return errors.NotFound("co-owner not found")
+	}
+	member.Permission = permission
+	return nil
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// newJointWalletService returns a service with an active wallet "wallet-1"
+// owned by "user-1". The mock user client resolves "+919876543210" to "user-2".
+func newJointWalletService() (*WalletService, *mockWalletRepository, *mockWalletMemberRepository) {
+	repo := newMockWalletRepository()
+	repo.wallets["wallet-1"] = &models.Wallet{
+		ID:       "wallet-1",
+		UserID:   "user-1",
+		Type:     models.WalletTypeDefault,
+		Currency: "INR",
+		Status:   models.WalletStatusActive,
+	}
+	members := newMockWalletMemberRepository()
+
+	service := NewWalletService(repo, nil, nil, nil, nil)
+	service.SetJointWallets(members, newMockUserClient())
+	return service, repo, members
+}
+
+func asUser(userID string, permissions ...string) context.Context {
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, userID)
+	return context.WithValue(ctx, middleware.UserPermissionsKey, permissions)
+}
+
+func addMember(members *mockWalletMemberRepository, userID string, permission models.WalletPermission, status models.WalletMemberStatus) {
+	members.members["wallet-1/"+userID] = &models.WalletMember{
+		ID:         "member-" + userID,
+		WalletID:   "wallet-1",
+		UserID:     userID,
+		Permission: permission,
+		Status:     status,
+		InvitedBy:  "user-1",
+	}
+}
+
+// ============================================================================
+// Tests
+// ============================================================================
+
+func TestWalletPermission_Allows(t *testing.T) {
+	tests := []struct {
+		permission models.WalletPermission
+		required   models.WalletPermission
+		want       bool
+	}{
+		{models.WalletPermissionView, models.WalletPermissionView, true},
+		{models.WalletPermissionView, models.WalletPermissionTransact, false},
+		{models.WalletPermissionTransact, models.WalletPermissionView, true},
+		{models.WalletPermissionTransact, models.WalletPermissionAdmin, false},
+		{models.WalletPermissionAdmin, models.WalletPermissionTransact, true},
+		{"owner", models.WalletPermissionView, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.permission.Allows(tt.required); got != tt.want {
+			t.Errorf("%s.Allows(%s) = %v, want %v", tt.permission, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeWallet(t *testing.T) {
+	service, repo, members := newJointWalletService()
+	addMember(members, "user-viewer", models.WalletPermissionView, models.WalletMemberStatusActive)
+	addMember(members, "user-invited", models.WalletPermissionAdmin, models.WalletMemberStatusInvited)
+	wallet := repo.wallets["wallet-1"]
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		required models.WalletPermission
+		wantCode errors.ErrorCode
+	}{
+		{"primary owner", asUser("user-1"), models.WalletPermissionAdmin, ""},
+		{"co-owner within permission", asUser("user-viewer"), models.WalletPermissionView, ""},
+		{"co-owner beyond permission", asUser("user-viewer"), models.WalletPermissionTransact, errors.ErrCodeForbidden},
+		{"pending invite", asUser("user-invited"), models.WalletPermissionView, errors.ErrCodeForbidden},
+		{"stranger", asUser("user-3"), models.WalletPermissionView, errors.ErrCodeForbidden},
+		{"staff", asUser("admin-1", staffWalletPermission), models.WalletPermissionAdmin, ""},
+		{"unauthenticated", context.Background(), models.WalletPermissionView, errors.ErrCodeUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.AuthorizeWallet(tt.ctx, wallet, tt.required)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("expected access, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Code != tt.wantCode {
+				t.Errorf("expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestInviteCoOwner_AcceptFlow(t *testing.T) {
+	service, _, _ := newJointWalletService()
+	owner := asUser("user-1")
+	invitee := asUser("user-2")
+
+	member, err := service.InviteCoOwner(owner, "wallet-1", &models.InviteCoOwnerRequest{
+		Phone:      "+919876543210",
+		Permission: models.WalletPermissionTransact,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if member.UserID != "user-2" || member.InvitedBy != "user-1" || member.Status != models.WalletMemberStatusInvited {
+		t.Errorf("unexpected invite: %+v", member)
+	}
+
+	// The invite grants nothing until accepted
+	if _, err := service.GetAuthorizedWallet(invitee, "wallet-1", models.WalletPermissionView); err == nil {
+		t.Error("expected pending invitee to be denied")
+	}
+	if invites, _ := service.ListInvites(invitee, "user-2"); len(invites) != 1 {
+		t.Errorf("expected 1 pending invite, got %d", len(invites))
+	}
+
+	// Inviting again while pending conflicts
+	if _, err := service.InviteCoOwner(owner, "wallet-1", &models.InviteCoOwnerRequest{
+		Phone:      "+919876543210",
+		Permission: models.WalletPermissionView,
+	}); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+
+	if _, err := service.RespondToInvite(invitee, "wallet-1", "user-2", true); err != nil {
+		t.Fatalf("expected no error accepting invite, got %v", err)
+	}
+	if _, err := service.GetAuthorizedWallet(invitee, "wallet-1", models.WalletPermissionTransact); err != nil {
+		t.Errorf("expected co-owner to transact, got %v", err)
+	}
+
+	wallets, listErr := service.ListAccessibleWallets(invitee, "user-2", nil)
+	if listErr != nil {
+		t.Fatalf("expected no error, got %v", listErr)
+	}
+	if len(wallets) != 1 || wallets[0].ID != "wallet-1" {
+		t.Errorf("expected the joint wallet to be listed, got %v", wallets)
+	}
+
+	owners, ownersErr := service.ListOwners(invitee, "wallet-1")
+	if ownersErr != nil {
+		t.Fatalf("expected no error, got %v", ownersErr)
+	}
+	if len(owners) != 2 || !owners[0].Primary || owners[0].UserID != "user-1" {
+		t.Errorf("unexpected owners: %+v", owners)
+	}
+}
+
+func TestInviteCoOwner_RequiresAdmin(t *testing.T) {
+	service, _, members := newJointWalletService()
+	addMember(members, "user-3", models.WalletPermissionTransact, models.WalletMemberStatusActive)
+
+	_, err := service.InviteCoOwner(asUser("user-3"), "wallet-1", &models.InviteCoOwnerRequest{
+		Phone:      "+919876543210",
+		Permission: models.WalletPermissionView,
+	})
+	if err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden, got %v", err)
+	}
+}
+
+func TestInviteCoOwner_InvalidPermission(t *testing.T) {
+	service, _, _ := newJointWalletService()
+
+	_, err := service.InviteCoOwner(asUser("user-1"), "wallet-1", &models.InviteCoOwnerRequest{
+		Phone:      "+919876543210",
+		Permission: "owner",
+	})
+	if err == nil || err.Code != errors.ErrCodeValidation {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
+
+func TestRespondToInvite_Decline(t *testing.T) {
+	service, _, members := newJointWalletService()
+	addMember(members, "user-2", models.WalletPermissionView, models.WalletMemberStatusInvited)
+
+	member, err := service.RespondToInvite(asUser("user-2"), "wallet-1", "user-2", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if member.Status != models.WalletMemberStatusDeclined {
+		t.Errorf("expected declined, got %s", member.Status)
+	}
+
+	// Responding twice finds no pending invite
+	if _, err := service.RespondToInvite(asUser("user-2"), "wallet-1", "user-2", true); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestUpdateCoOwnerPermission(t *testing.T) {
+	service, _, members := newJointWalletService()
+	addMember(members, "user-2", models.WalletPermissionView, models.WalletMemberStatusActive)
+
+	member, err := service.UpdateCoOwnerPermission(asUser("user-1"), "wallet-1", "user-2", &models.UpdateCoOwnerRequest{
+		Permission: models.WalletPermissionAdmin,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if member.Permission != models.WalletPermissionAdmin {
+		t.Errorf("expected admin, got %s", member.Permission)
+	}
+
+	// A co-owner cannot change the primary owner
+	if _, err := service.UpdateCoOwnerPermission(asUser("user-2"), "wallet-1", "user-1", &models.UpdateCoOwnerRequest{
+		Permission: models.WalletPermissionView,
+	}); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request, got %v", err)
+	}
+}
+
+func TestRemoveCoOwner(t *testing.T) {
+	service, _, members := newJointWalletService()
+	addMember(members, "user-2", models.WalletPermissionTransact, models.WalletMemberStatusActive)
+	addMember(members, "user-3", models.WalletPermissionView, models.WalletMemberStatusActive)
+
+	// A non-admin co-owner cannot remove others
+	if err := service.RemoveCoOwner(asUser("user-3"), "wallet-1", "user-2"); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden, got %v", err)
+	}
+
+	// But can leave
+	if err := service.RemoveCoOwner(asUser("user-3"), "wallet-1", "user-3"); err != nil {
+		t.Fatalf("expected co-owner to leave, got %v", err)
+	}
+	if _, err := service.GetAuthorizedWallet(asUser("user-3"), "wallet-1", models.WalletPermissionView); err == nil {
+		t.Error("expected removed co-owner to be denied")
+	}
+
+	// The primary owner cannot be removed
+	if err := service.RemoveCoOwner(asUser("user-1"), "wallet-1", "user-1"); err == nil || err.Code != errors.ErrCodeBadRequest {
+		t.Errorf("expected bad request, got %v", err)
+	}
+
+	if err := service.RemoveCoOwner(asUser("user-1"), "wallet-1", "user-2"); err != nil {
+		t.Errorf("expected admin to remove co-owner, got %v", err)
+	}
+}
+
+func TestUpdateWalletLimits_RequiresAdmin(t *testing.T) {
+	service, _, members := newJointWalletService()
+	addMember(members, "user-2", models.WalletPermissionTransact, models.WalletMemberStatusActive)
+	addMember(members, "user-3", models.WalletPermissionAdmin, models.WalletMemberStatusActive)
+	req := &models.UpdateLimitsRequest{DailyLimit: 100000, MonthlyLimit: 1000000}
+
+	if _, err := service.UpdateWalletLimits(asUser("user-2"), "wallet-1", req); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for transact co-owner, got %v", err)
+	}
+	if _, err := service.UpdateWalletLimits(asUser("user-3"), "wallet-1", req); err != nil {
+		t.Errorf("expected admin co-owner to update limits, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// UpdateWallet applies a partial update to a wallet's nickname, color, tags,
+// metadata and default flag. Wallet admins manage the shared labels; only the
+// primary owner can make the wallet their default.
+func (s *WalletService) UpdateWallet(ctx context.Context, walletID string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error) {
+	if req.IsEmpty() {
+		return nil, errors.BadRequest("no fields to update")
+	}
+	if err := req.Normalize(); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
+
+	wallet, err := s.GetAuthorizedWallet(ctx, walletID, models.WalletPermissionAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Status == models.WalletStatusClosed {
+		return nil, errors.BadRequest("closed wallets cannot be updated")
+	}
+	if req.IsDefault != nil {
+		if userID, _ := middleware.GetUserID(ctx); userID != wallet.UserID {
+			return nil, errors.Forbidden("only the wallet's owner can change their default wallet")
+		}
+		if *req.IsDefault && wallet.Status == models.WalletStatusPendingClosure {
+			return nil, errors.BadRequest("a wallet being closed cannot become the default")
+		}
+	}
+
+	return s.walletRepo.UpdateProfile(ctx, walletID, req)
+}
+
+// FilterWallets keeps the wallets carrying tag, when set, whose nickname
+// contains query, when set, ignoring case. The tag must be normalized.
+func FilterWallets(wallets []*models.Wallet, tag, query string) []*models.Wallet {
+	if tag == "" && query == "" {
+		return wallets
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	filtered := make([]*models.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		if tag != "" && !wallet.HasTag(tag) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(wallet.DisplayName()), query) {
+			continue
+		}
+		filtered = append(filtered, wallet)
+	}
+	return filtered
+}
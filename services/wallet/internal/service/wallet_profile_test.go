@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+func TestUpdateWallet_NormalizesLabels(t *testing.T) {
+	service, _, _ := newJointWalletService()
+
+	nickname, color := "  Travel fund ", "#1a73e8"
+	tags := []string{"Travel", " savings", "travel"}
+	wallet, err := service.UpdateWallet(asUser("user-1"), "wallet-1", &models.UpdateWalletRequest{
+		Nickname: &nickname,
+		Color:    &color,
+		Tags:     &tags,
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if wallet.DisplayName() != "Travel fund" || *wallet.Color != "#1A73E8" {
+		t.Errorf("unexpected nickname or color: %q %q", wallet.DisplayName(), *wallet.Color)
+	}
+	if len(wallet.Tags) != 2 || wallet.Tags[0] != "travel" || wallet.Tags[1] != "savings" {
+		t.Errorf("expected normalized, de-duplicated tags, got %v", wallet.Tags)
+	}
+}
+
+func TestUpdateWallet_Validation(t *testing.T) {
+	service, _, _ := newJointWalletService()
+	badColor := "blue"
+	badTags := []string{"has space"}
+	badUPI := map[string]string{"upi_id": "not-a-vpa"}
+
+	tests := []struct {
+		name string
+		req  *models.UpdateWalletRequest
+	}{
+		{"empty", &models.UpdateWalletRequest{}},
+		{"color", &models.UpdateWalletRequest{Color: &badColor}},
+		{"tag", &models.UpdateWalletRequest{Tags: &badTags}},
+		{"upi id", &models.UpdateWalletRequest{Metadata: &badUPI}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.UpdateWallet(asUser("user-1"), "wallet-1", tt.req)
+			if err == nil || (err.Code != errors.ErrCodeValidation && err.Code != errors.ErrCodeBadRequest) {
+				t.Errorf("expected validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateWallet_DefaultIsOwnerOnly(t *testing.T) {
+	service, repo, members := newJointWalletService()
+	addMember(members, "user-2", models.WalletPermissionAdmin, models.WalletMemberStatusActive)
+	repo.wallets["wallet-2"] = &models.Wallet{ID: "wallet-2", UserID: "user-1", Status: models.WalletStatusActive, IsDefault: true}
+
+	isDefault := true
+	req := &models.UpdateWalletRequest{IsDefault: &isDefault}
+	if _, err := service.UpdateWallet(asUser("user-2"), "wallet-1", req); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for co-owner setting the default, got %v", err)
+	}
+
+	nickname := "Household"
+	if _, err := service.UpdateWallet(asUser("user-2"), "wallet-1", &models.UpdateWalletRequest{Nickname: &nickname}); err != nil {
+		t.Errorf("expected admin co-owner to rename the wallet, got %v", err)
+	}
+
+	wallet, err := service.UpdateWallet(asUser("user-1"), "wallet-1", req)
+	if err != nil {
+		t.Fatalf("owner failed to set default: %v", err)
+	}
+	if !wallet.IsDefault || repo.wallets["wallet-2"].IsDefault {
+		t.Error("expected the default to move to wallet-1")
+	}
+}
+
+func TestFilterWallets(t *testing.T) {
+	travel, rent := "Travel fund", "Rent"
+	wallets := []*models.Wallet{
+		{ID: "a", Nickname: &travel, Tags: []string{"travel", "savings"}},
+		{ID: "b", Nickname: &rent, Tags: []string{"bills"}},
+		{ID: "c", Tags: []string{"savings"}},
+	}
+
+	if got := FilterWallets(wallets, "savings", ""); len(got) != 2 || got[0].ID != "a" || got[1].ID != "c" {
+		t.Errorf("unexpected tag filter result: %v", got)
+	}
+	if got := FilterWallets(wallets, "", "FUND"); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("unexpected nickname search result: %v", got)
+	}
+	if got := FilterWallets(wallets, "bills", "travel"); len(got) != 0 {
+		t.Errorf("expected no wallets, got %v", got)
+	}
+}
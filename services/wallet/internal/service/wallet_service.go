@@ -3,12 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/1mb-dev/nivomoney/services/wallet/internal/models"
 	"github.com/1mb-dev/nivomoney/shared/clients"
 	"github.com/1mb-dev/nivomoney/shared/errors"
 	"github.com/1mb-dev/nivomoney/shared/events"
-	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/money"
 )
 
 // WalletRepositoryInterface defines the interface for wallet repository operations.
@@ -16,14 +17,19 @@ type WalletRepositoryInterface interface {
 	Create(ctx context.Context, wallet *models.Wallet) *errors.Error
 	GetByID(ctx context.Context, id string) (*models.Wallet, *errors.Error)
 	ListByUserID(ctx context.Context, userID string, status *models.WalletStatus) ([]*models.Wallet, *errors.Error)
+	Search(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error)
 	UpdateStatus(ctx context.Context, id string, status models.WalletStatus) *errors.Error
+	UpdateProfile(ctx context.Context, id string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error)
 	Close(ctx context.Context, id, reason string) *errors.Error
 	GetBalance(ctx context.Context, id string) (*models.WalletBalance, *errors.Error)
 	GetLimits(ctx context.Context, walletID string) (*models.WalletLimits, *errors.Error)
 	UpdateLimits(ctx context.Context, walletID string, dailyLimit, monthlyLimit int64) *errors.Error
+	ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error)
 	ProcessTransferWithinTx(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error
+	IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error)
 	ProcessDepositWithinTx(ctx context.Context, walletID string, amount int64, transactionID string) *errors.Error
 	UpdateBalance(ctx context.Context, walletID string, amount int64) *errors.Error
+	UpdateLedgerAccount(ctx context.Context, walletID, fromAccountID, toAccountID string) *errors.Error
 }
 
 // WalletService handles business logic for wallet operations.
@@ -33,6 +39,12 @@ type WalletService struct {
 	ledgerClient       *LedgerClient
 	notificationClient *clients.NotificationClient
 	identityClient     *IdentityClient
+	memberRepo         WalletMemberRepositoryInterface // Optional: enables joint wallets
+	userLookup         UserLookupClient
+	limitsLoc          *time.Location  // Timezone whose midnight ends limit windows
+	closures           *walletClosures // Optional: enables scheduled closure with balance sweep
+	reconciliation     ledgerReconciliation
+	balanceSkews       BalanceSkewRepositoryInterface // Optional: non-production simulation anomalies
 }
 
 // NewWalletService creates a new wallet service.
@@ -43,6 +55,7 @@ func NewWalletService(walletRepo WalletRepositoryInterface, eventPublisher *even
 		ledgerClient:       ledgerClient,
 		notificationClient: notificationClient,
 		identityClient:     identityClient,
+		limitsLoc:          time.UTC,
 	}
 }
 
@@ -53,6 +66,9 @@ func (s *WalletService) CreateWallet(ctx context.Context, req *models.CreateWall
 	if metaErr != nil {
 		return nil, errors.Validation("invalid metadata format")
 	}
+	if err := models.ValidateWalletMetadata(metadata); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
 
 	// Validate wallet type (only "default" is allowed)
 	if req.Type != models.WalletTypeDefault {
@@ -72,41 +88,18 @@ func (s *WalletService) CreateWallet(ctx context.Context, req *models.CreateWall
 		}
 	}
 
-	// If ledger_account_id is not provided, automatically create one (or reuse existing)
+	// If ledger_account_id is not provided, provision the wallet's liability
+	// account (or reuse one left by an earlier attempt). It is removed again
+	// if the wallet cannot be created.
 	ledgerAccountID := req.LedgerAccountID
+	var provision *ledgerProvision
 	if ledgerAccountID == "" && s.ledgerClient != nil {
-		// Generate the ledger account code (idempotent across retries)
-		ledgerCode := fmt.Sprintf("WALLET-%s-%s", req.UserID[:8], req.Currency)
-
-		// Check if a ledger account with this code already exists (for idempotency)
-		existingAccount, checkErr := s.ledgerClient.GetAccountByCode(ctx, ledgerCode)
-		if checkErr != nil {
-			return nil, errors.Internal(fmt.Sprintf("failed to check for existing ledger account: %v", checkErr))
-		}
-
-		if existingAccount != nil {
-			// Reuse the existing ledger account (handles orphaned accounts from previous failed attempts)
-			ledgerAccountID = existingAccount.ID
-		} else {
-			// Create a new ledger account
-			ledgerReq := &CreateLedgerAccountRequest{
-				Code:     ledgerCode,
-				Name:     fmt.Sprintf("Wallet (%s) for User %s", req.Currency, req.UserID[:8]),
-				Type:     "asset", // Wallet accounts are assets
-				Currency: string(req.Currency),
-				Metadata: map[string]string{
-					"wallet_type": "default",
-					"user_id":     req.UserID,
-				},
-			}
-
-			ledgerAccount, ledgerErr := s.ledgerClient.CreateAccount(ctx, ledgerReq)
-			if ledgerErr != nil {
-				return nil, errors.Internal(fmt.Sprintf("failed to create ledger account: %v", ledgerErr))
-			}
-
-			ledgerAccountID = ledgerAccount.ID
+		var provisionErr *errors.Error
+		provision, provisionErr = s.provisionLedgerAccount(ctx, req.UserID, req.Currency)
+		if provisionErr != nil {
+			return nil, provisionErr
 		}
+		ledgerAccountID = provision.AccountID
 	}
 
 	// Validate that we have a ledger account ID
@@ -133,7 +126,7 @@ func (s *WalletService) CreateWallet(ctx context.Context, req *models.CreateWall
 	}
 
 	// Auto-generate UPI ID if phone number is available and not already in metadata
-	if userPhone != "" && metadata["upi_id"] == "" {
+	if userPhone != "" && metadata[models.WalletMetadataUPIID] == "" {
 		// Remove country code prefix if present (e.g., +91)
 		// UPI format: phone@nivomoney
 		cleanPhone := userPhone
@@ -144,7 +137,7 @@ func (s *WalletService) CreateWallet(ctx context.Context, req *models.CreateWall
 			// Remove country code without + (e.g., 919876543210 -> 9876543210)
 			cleanPhone = userPhone[len(userPhone)-10:]
 		}
-		metadata["upi_id"] = fmt.Sprintf("%s@nivomoney", cleanPhone)
+		metadata[models.WalletMetadataUPIID] = fmt.Sprintf("%s@nivomoney", cleanPhone)
 	}
 
 	// Create wallet
@@ -159,19 +152,21 @@ func (s *WalletService) CreateWallet(ctx context.Context, req *models.CreateWall
 	}
 
 	if createErr := s.walletRepo.Create(ctx, wallet); createErr != nil {
+		s.releaseLedgerAccount(ctx, provision)
 		return nil, createErr
 	}
 
 	// Publish wallet.created event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishWalletEvent("wallet.created", wallet.ID, map[string]interface{}{
-			"user_id":           wallet.UserID,
-			"type":              string(wallet.Type),
-			"currency":          string(wallet.Currency),
-			"status":            string(wallet.Status),
-			"balance":           wallet.Balance,
-			"available_balance": wallet.AvailableBalance,
-			"ledger_account_id": wallet.LedgerAccountID,
+		s.eventPublisher.PublishAsync("wallets", events.WalletCreated{
+			WalletID:         wallet.ID,
+			UserID:           wallet.UserID,
+			Type:             string(wallet.Type),
+			Currency:         string(wallet.Currency),
+			Status:           string(wallet.Status),
+			Balance:          wallet.Balance,
+			AvailableBalance: wallet.AvailableBalance,
+			LedgerAccountID:  wallet.LedgerAccountID,
 		})
 	}
 
@@ -192,6 +187,18 @@ func (s *WalletService) ListUserWallets(ctx context.Context, userID string, stat
 	return s.walletRepo.ListByUserID(ctx, userID, status)
 }
 
+// SearchWallets lists wallets across all users for back-office tooling,
+// returning one page and the total number of matches.
+func (s *WalletService) SearchWallets(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+	if filter.MinBalance != nil && filter.MaxBalance != nil && *filter.MinBalance > *filter.MaxBalance {
+		return nil, 0, errors.BadRequest("min_balance cannot be greater than max_balance")
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return nil, 0, errors.BadRequest("created_from must be before created_to")
+	}
+	return s.walletRepo.Search(ctx, filter)
+}
+
 // ActivateWallet activates a wallet (after KYC verification).
 func (s *WalletService) ActivateWallet(ctx context.Context, walletID string) (*models.Wallet, *errors.Error) {
 	// Get wallet to verify it exists and is inactive
@@ -218,14 +225,15 @@ func (s *WalletService) ActivateWallet(ctx context.Context, walletID string) (*m
 
 	// Publish wallet.status_changed event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishWalletEvent("wallet.status_changed", updatedWallet.ID, map[string]interface{}{
-			"user_id":           updatedWallet.UserID,
-			"currency":          string(updatedWallet.Currency),
-			"old_status":        string(models.WalletStatusInactive),
-			"new_status":        string(updatedWallet.Status),
-			"balance":           updatedWallet.Balance,
-			"available_balance": updatedWallet.AvailableBalance,
-			"action":            "activated",
+		s.eventPublisher.PublishAsync("wallets", events.WalletStatusChanged{
+			WalletID:         updatedWallet.ID,
+			UserID:           updatedWallet.UserID,
+			Currency:         string(updatedWallet.Currency),
+			OldStatus:        string(models.WalletStatusInactive),
+			NewStatus:        string(updatedWallet.Status),
+			Balance:          updatedWallet.Balance,
+			AvailableBalance: updatedWallet.AvailableBalance,
+			Action:           "activated",
 		})
 	}
 
@@ -258,15 +266,16 @@ func (s *WalletService) FreezeWallet(ctx context.Context, walletID, reason strin
 
 	// Publish wallet.status_changed event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishWalletEvent("wallet.status_changed", updatedWallet.ID, map[string]interface{}{
-			"user_id":           updatedWallet.UserID,
-			"currency":          string(updatedWallet.Currency),
-			"old_status":        string(models.WalletStatusActive),
-			"new_status":        string(updatedWallet.Status),
-			"balance":           updatedWallet.Balance,
-			"available_balance": updatedWallet.AvailableBalance,
-			"action":            "frozen",
-			"reason":            reason,
+		s.eventPublisher.PublishAsync("wallets", events.WalletStatusChanged{
+			WalletID:         updatedWallet.ID,
+			UserID:           updatedWallet.UserID,
+			Currency:         string(updatedWallet.Currency),
+			OldStatus:        string(models.WalletStatusActive),
+			NewStatus:        string(updatedWallet.Status),
+			Balance:          updatedWallet.Balance,
+			AvailableBalance: updatedWallet.AvailableBalance,
+			Action:           "frozen",
+			Reason:           reason,
 		})
 	}
 
@@ -299,14 +308,15 @@ func (s *WalletService) UnfreezeWallet(ctx context.Context, walletID string) (*m
 
 	// Publish wallet.status_changed event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishWalletEvent("wallet.status_changed", updatedWallet.ID, map[string]interface{}{
-			"user_id":           updatedWallet.UserID,
-			"currency":          string(updatedWallet.Currency),
-			"old_status":        string(models.WalletStatusFrozen),
-			"new_status":        string(updatedWallet.Status),
-			"balance":           updatedWallet.Balance,
-			"available_balance": updatedWallet.AvailableBalance,
-			"action":            "unfrozen",
+		s.eventPublisher.PublishAsync("wallets", events.WalletStatusChanged{
+			WalletID:         updatedWallet.ID,
+			UserID:           updatedWallet.UserID,
+			Currency:         string(updatedWallet.Currency),
+			OldStatus:        string(models.WalletStatusFrozen),
+			NewStatus:        string(updatedWallet.Status),
+			Balance:          updatedWallet.Balance,
+			AvailableBalance: updatedWallet.AvailableBalance,
+			Action:           "unfrozen",
 		})
 	}
 
@@ -325,10 +335,13 @@ func (s *WalletService) CloseWallet(ctx context.Context, walletID, reason string
 	if wallet.Status == models.WalletStatusClosed {
 		return nil, errors.BadRequest("wallet is already closed")
 	}
+	if wallet.Status == models.WalletStatusPendingClosure {
+		return nil, errors.Conflict("wallet closure is scheduled; cancel it first")
+	}
 
-	// Validate balance is zero
+	// Validate balance is zero; a scheduled closure sweeps the balance instead
 	if wallet.Balance > 0 {
-		return nil, errors.BadRequest("cannot close wallet with non-zero balance")
+		return nil, errors.BadRequest("cannot close wallet with non-zero balance, schedule a closure to sweep it")
 	}
 
 	// Store old status before closing
@@ -347,15 +360,16 @@ func (s *WalletService) CloseWallet(ctx context.Context, walletID, reason string
 
 	// Publish wallet.status_changed event
 	if s.eventPublisher != nil {
-		s.eventPublisher.PublishWalletEvent("wallet.status_changed", updatedWallet.ID, map[string]interface{}{
-			"user_id":           updatedWallet.UserID,
-			"currency":          string(updatedWallet.Currency),
-			"old_status":        string(oldStatus),
-			"new_status":        string(updatedWallet.Status),
-			"balance":           updatedWallet.Balance,
-			"available_balance": updatedWallet.AvailableBalance,
-			"action":            "closed",
-			"reason":            reason,
+		s.eventPublisher.PublishAsync("wallets", events.WalletStatusChanged{
+			WalletID:         updatedWallet.ID,
+			UserID:           updatedWallet.UserID,
+			Currency:         string(updatedWallet.Currency),
+			OldStatus:        string(oldStatus),
+			NewStatus:        string(updatedWallet.Status),
+			Balance:          updatedWallet.Balance,
+			AvailableBalance: updatedWallet.AvailableBalance,
+			Action:           "closed",
+			Reason:           reason,
 		})
 	}
 
@@ -378,9 +392,10 @@ func (s *WalletService) GetWalletLimits(ctx context.Context, walletID string) (*
 	return s.walletRepo.GetLimits(ctx, walletID)
 }
 
-// UpdateWalletLimits updates the transfer limits for a wallet after verifying user password.
+// UpdateWalletLimits updates the transfer limits for a wallet. Only the
+// wallet's admins (its primary owner and admin co-owners) can change limits.
 func (s *WalletService) UpdateWalletLimits(ctx context.Context, walletID string, req *models.UpdateLimitsRequest) (*models.WalletLimits, *errors.Error) {
-	// Get wallet to verify ownership
+	// Get wallet to verify access
 	wallet, err := s.walletRepo.GetByID(ctx, walletID)
 	if err != nil {
 		return nil, err
@@ -391,19 +406,13 @@ func (s *WalletService) UpdateWalletLimits(ctx context.Context, walletID string,
 		return nil, errors.BadRequest("cannot update limits for inactive wallet")
 	}
 
-	// Get user ID from context (set by auth middleware)
-	userID, ok := middleware.GetUserID(ctx)
-	if !ok {
-		return nil, errors.Unauthorized("user ID not found in context")
-	}
-
-	// Verify user owns this wallet
-	if wallet.UserID != userID {
-		return nil, errors.Forbidden("you do not own this wallet")
+	// Verify the user administers this wallet
+	if authErr := s.AuthorizeWallet(ctx, wallet, models.WalletPermissionAdmin); authErr != nil {
+		return nil, authErr
 	}
 
 	// Note: Authentication is handled via JWT middleware - no additional password verification needed.
-	// The user has already authenticated and we've verified wallet ownership above.
+	// The user has already authenticated and we've verified wallet access above.
 
 	// Validate limits
 	if req.DailyLimit > req.MonthlyLimit {
@@ -419,6 +428,16 @@ func (s *WalletService) UpdateWalletLimits(ctx context.Context, walletID string,
 	return s.walletRepo.GetLimits(ctx, walletID)
 }
 
+// IsTransferProcessed reports whether the transfer of transactionID has moved
+// wallet balances, so the transaction service can settle a transfer whose
+// outcome it did not record.
+func (s *WalletService) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	if transactionID == "" {
+		return false, errors.BadRequest("transaction ID is required")
+	}
+	return s.walletRepo.IsTransferProcessed(ctx, transactionID)
+}
+
 // ProcessTransfer processes a wallet-to-wallet transfer with limit checking and balance updates.
 // This is an internal endpoint called by the transaction service to execute approved transfers.
 func (s *WalletService) ProcessTransfer(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error {
@@ -435,7 +454,7 @@ func (s *WalletService) ProcessTransfer(ctx context.Context, sourceWalletID, des
 
 	// Prevent self-transfer
 	if sourceWalletID == destWalletID {
-		return errors.BadRequest("cannot transfer to the same wallet")
+		return errors.New(errors.ErrCodeTransferSameWallet, "cannot transfer to the same wallet")
 	}
 
 	// Validate amount
@@ -458,8 +477,13 @@ func (s *WalletService) ProcessTransfer(ctx context.Context, sourceWalletID, des
 			"source_user_id":        sourceWallet.UserID,
 			"dest_user_id":          destWallet.UserID,
 		})
+		s.publishBalanceUpdate(ctx, sourceWalletID, -amount, transactionID)
+		s.publishBalanceUpdate(ctx, destWalletID, amount, transactionID)
 	}
 
+	s.notifyWalletActivity(ctx, sourceWalletID, fmt.Sprintf("%s was sent from your shared wallet", money.New(amount, sourceWallet.Currency)))
+	s.notifyWalletActivity(ctx, destWalletID, fmt.Sprintf("%s was received in your shared wallet", money.New(amount, destWallet.Currency)))
+
 	return nil
 }
 
@@ -493,7 +517,28 @@ func (s *WalletService) ProcessDeposit(ctx context.Context, walletID string, amo
 			"transaction_id": transactionID,
 			"user_id":        wallet.UserID,
 		})
+		s.publishBalanceUpdate(ctx, walletID, amount, transactionID)
 	}
 
+	s.notifyWalletActivity(ctx, walletID, fmt.Sprintf("%s was deposited into your shared wallet", money.New(amount, wallet.Currency)))
+
 	return nil
 }
+
+// publishBalanceUpdate publishes a wallet's balance after money moved, for
+// the owner's wallet stream.
+func (s *WalletService) publishBalanceUpdate(ctx context.Context, walletID string, change int64, transactionID string) {
+	wallet, err := s.walletRepo.GetByID(ctx, walletID)
+	if err != nil {
+		return
+	}
+	s.eventPublisher.PublishAsync("wallets", events.WalletBalanceUpdated{
+		WalletID:         wallet.ID,
+		UserID:           wallet.UserID,
+		Currency:         string(wallet.Currency),
+		Balance:          wallet.Balance,
+		AvailableBalance: wallet.AvailableBalance,
+		Change:           change,
+		TransactionID:    transactionID,
+	})
+}
@@ -77,6 +77,18 @@ func (m *mockWalletRepository) ListByUserID(ctx context.Context, userID string,
 	return wallets, nil
 }
 
+func (m *mockWalletRepository) Search(ctx context.Context, filter *models.WalletFilter) ([]*models.Wallet, int64, *errors.Error) {
+	var wallets []*models.Wallet
+	for _, wallet := range m.wallets {
+		if filter.Status != nil && wallet.Status != *filter.Status {
+			continue
+		}
+		walletCopy := *wallet
+		wallets = append(wallets, &walletCopy)
+	}
+	return wallets, int64(len(wallets)), nil
+}
+
 func (m *mockWalletRepository) UpdateStatus(ctx context.Context, id string, status models.WalletStatus) *errors.Error {
 	if m.updateStatusFunc != nil {
 		return m.updateStatusFunc(ctx, id, status)
@@ -93,6 +105,39 @@ func (m *mockWalletRepository) UpdateStatus(ctx context.Context, id string, stat
 	return nil
 }
 
+func (m *mockWalletRepository) UpdateProfile(ctx context.Context, id string, req *models.UpdateWalletRequest) (*models.Wallet, *errors.Error) {
+	wallet, exists := m.wallets[id]
+	if !exists {
+		return nil, errors.NotFound("wallet not found")
+	}
+
+	if req.Nickname != nil {
+		wallet.Nickname = req.Nickname
+	}
+	if req.Color != nil {
+		wallet.Color = req.Color
+	}
+	if req.Tags != nil {
+		wallet.Tags = *req.Tags
+	}
+	if req.Metadata != nil {
+		wallet.Metadata = *req.Metadata
+	}
+	if req.IsDefault != nil {
+		if *req.IsDefault {
+			for _, other := range m.wallets {
+				if other.UserID == wallet.UserID {
+					other.IsDefault = false
+				}
+			}
+		}
+		wallet.IsDefault = *req.IsDefault
+	}
+
+	walletCopy := *wallet
+	return &walletCopy, nil
+}
+
 func (m *mockWalletRepository) Close(ctx context.Context, id, reason string) *errors.Error {
 	if m.closeFunc != nil {
 		return m.closeFunc(ctx, id, reason)
@@ -133,10 +178,18 @@ func (m *mockWalletRepository) UpdateLimits(ctx context.Context, walletID string
 	return nil
 }
 
+func (m *mockWalletRepository) ResetExpiredLimits(ctx context.Context, now time.Time, batchSize int) (int, *errors.Error) {
+	return 0, nil
+}
+
 func (m *mockWalletRepository) ProcessTransferWithinTx(ctx context.Context, sourceWalletID, destWalletID string, amount int64, transactionID string) *errors.Error {
 	return nil
 }
 
+func (m *mockWalletRepository) IsTransferProcessed(ctx context.Context, transactionID string) (bool, *errors.Error) {
+	return false, nil
+}
+
 func (m *mockWalletRepository) ProcessDepositWithinTx(ctx context.Context, walletID string, amount int64, transactionID string) *errors.Error {
 	return nil
 }
@@ -145,6 +198,15 @@ func (m *mockWalletRepository) UpdateBalance(ctx context.Context, walletID strin
 	return nil
 }
 
+func (m *mockWalletRepository) UpdateLedgerAccount(ctx context.Context, walletID, fromAccountID, toAccountID string) *errors.Error {
+	wallet, exists := m.wallets[walletID]
+	if !exists || wallet.LedgerAccountID != fromAccountID {
+		return errors.Conflict("wallet not found or its ledger account changed")
+	}
+	wallet.LedgerAccountID = toAccountID
+	return nil
+}
+
 // ============================================================================
 // Tests: Wallet Creation
 // ============================================================================
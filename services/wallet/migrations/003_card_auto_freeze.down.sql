@@ -0,0 +1,7 @@
+-- ============================================================================
+-- Card Auto-Freeze Rules Rollback
+-- ============================================================================
+
+DROP TABLE IF EXISTS card_auto_freeze_rules;
+ALTER TABLE virtual_cards DROP COLUMN IF EXISTS auto_frozen;
+ALTER TABLE virtual_cards DROP COLUMN IF EXISTS consecutive_declines;
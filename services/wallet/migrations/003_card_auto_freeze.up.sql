@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Card Auto-Freeze Rules
+-- ============================================================================
+
+-- Consecutive declined authorizations; reset on approval or unfreeze
+ALTER TABLE virtual_cards ADD COLUMN IF NOT EXISTS consecutive_declines INT NOT NULL DEFAULT 0;
+
+-- Set when a card was frozen by an auto-freeze rule rather than by the user
+ALTER TABLE virtual_cards ADD COLUMN IF NOT EXISTS auto_frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS card_auto_freeze_rules (
+    wallet_id UUID PRIMARY KEY REFERENCES wallets(id) ON DELETE CASCADE,
+    balance_floor BIGINT CHECK (balance_floor >= 0),
+    max_declines INT CHECK (max_declines > 0),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE card_auto_freeze_rules IS
+'Per-wallet rules that freeze virtual cards when the balance would drop below a floor or after repeated declined authorizations.';
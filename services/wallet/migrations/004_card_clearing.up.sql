@@ -0,0 +1,26 @@
+-- Card Clearing
+-- Approved card authorizations place a hold on the wallet's available balance.
+-- Settlement converts the hold into a debit; reversal releases it; refunds
+-- credit settled amounts back.
+
+CREATE TABLE IF NOT EXISTS card_authorizations (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    card_id         UUID NOT NULL REFERENCES virtual_cards(id),
+    wallet_id       UUID NOT NULL REFERENCES wallets(id),
+    amount          BIGINT NOT NULL CHECK (amount > 0),
+    merchant_name   VARCHAR(100) NOT NULL,
+    mcc             VARCHAR(4),
+    status          VARCHAR(20) NOT NULL DEFAULT 'authorized'
+                    CHECK (status IN ('authorized', 'settled', 'reversed')),
+    settled_amount  BIGINT NOT NULL DEFAULT 0 CHECK (settled_amount >= 0 AND settled_amount <= amount),
+    refunded_amount BIGINT NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0 AND refunded_amount <= settled_amount),
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    settled_at      TIMESTAMP WITH TIME ZONE,
+    reversed_at     TIMESTAMP WITH TIME ZONE,
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_authorizations_card ON card_authorizations(card_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_card_authorizations_open ON card_authorizations(created_at) WHERE status = 'authorized';
+
+COMMENT ON TABLE card_authorizations IS 'Card authorization holds and their clearing state';
@@ -0,0 +1,5 @@
+-- ============================================================================
+-- Joint Wallets Rollback
+-- ============================================================================
+
+DROP TABLE IF EXISTS wallet_members;
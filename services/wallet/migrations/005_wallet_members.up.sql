@@ -0,0 +1,23 @@
+-- ============================================================================
+-- Joint Wallets
+-- ============================================================================
+
+-- Co-owners of a wallet. The wallet's user_id stays the primary owner, who
+-- always has admin permission and is not listed here.
+CREATE TABLE IF NOT EXISTS wallet_members (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    permission VARCHAR(20) NOT NULL CHECK (permission IN ('view', 'transact', 'admin')),
+    status VARCHAR(20) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'active', 'declined', 'removed')),
+    invited_by UUID NOT NULL,
+    invited_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (wallet_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_members_user ON wallet_members(user_id, status);
+
+COMMENT ON TABLE wallet_members IS
+'Co-owners of joint wallets with their permission (view, transact or admin) and invitation status.';
@@ -0,0 +1,18 @@
+-- ============================================================================
+-- Scheduled Wallet Closure Rollback
+-- ============================================================================
+
+DROP TABLE IF EXISTS wallet_closure_sweeps;
+DROP TABLE IF EXISTS wallet_closures;
+
+-- Wallets caught mid-closure go back to active
+UPDATE wallets SET status = 'active' WHERE status = 'pending_closure';
+
+DROP INDEX IF EXISTS idx_wallets_unique_active;
+CREATE UNIQUE INDEX idx_wallets_unique_active
+    ON wallets(user_id, type, currency)
+    WHERE status IN ('active', 'frozen', 'inactive');
+
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check
+    CHECK (status IN ('active', 'frozen', 'closed', 'inactive'));
@@ -0,0 +1,65 @@
+-- ============================================================================
+-- Scheduled Wallet Closure
+-- ============================================================================
+
+-- Wallets being closed wait in pending_closure while outstanding card holds
+-- settle and the remaining balance is swept out.
+ALTER TABLE wallets DROP CONSTRAINT IF EXISTS wallets_status_check;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check
+    CHECK (status IN ('active', 'frozen', 'closed', 'inactive', 'pending_closure'));
+
+DROP INDEX IF EXISTS idx_wallets_unique_active;
+CREATE UNIQUE INDEX idx_wallets_unique_active
+    ON wallets(user_id, type, currency)
+    WHERE status IN ('active', 'frozen', 'inactive', 'pending_closure');
+
+-- One row per closure request.
+CREATE TABLE IF NOT EXISTS wallet_closures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    requested_by UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'closed', 'cancelled', 'reopened')),
+    reason TEXT NOT NULL,
+    sweep_method VARCHAR(20) CHECK (sweep_method IN ('wallet', 'withdrawal')),
+    sweep_wallet_id UUID REFERENCES wallets(id),
+    previous_status VARCHAR(20) NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    swept_amount BIGINT NOT NULL DEFAULT 0 CHECK (swept_amount >= 0),
+    last_error TEXT,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    reopen_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT wallet_closures_sweep_target_check CHECK (
+        sweep_method IS DISTINCT FROM 'wallet' OR sweep_wallet_id IS NOT NULL
+    ),
+    CONSTRAINT wallet_closures_closed_check CHECK (
+        (status IN ('closed', 'reopened')) = (closed_at IS NOT NULL AND reopen_until IS NOT NULL)
+    )
+);
+
+-- At most one closure in progress per wallet
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallet_closures_pending
+    ON wallet_closures(wallet_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_wallet_closures_due
+    ON wallet_closures(scheduled_for) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_wallet_closures_wallet
+    ON wallet_closures(wallet_id, created_at DESC);
+
+-- Balance sweeps of closing wallets, keyed by the transaction service's
+-- transaction ID for idempotency. A closure usually has one sweep; refunds
+-- credited after it are swept again.
+CREATE TABLE IF NOT EXISTS wallet_closure_sweeps (
+    transaction_id UUID PRIMARY KEY,
+    closure_id UUID NOT NULL REFERENCES wallet_closures(id),
+    destination_wallet_id UUID REFERENCES wallets(id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_closure_sweeps_closure ON wallet_closure_sweeps(closure_id);
+
+COMMENT ON TABLE wallet_closures IS
+'Scheduled wallet closures: hold settlement, balance sweep to another wallet or a withdrawal, and the reopen grace period.';
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Wallet Nicknames, Colors, Tags and Default Wallet Rollback
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_wallets_user_default;
+DROP INDEX IF EXISTS idx_wallets_tags;
+
+ALTER TABLE wallets DROP COLUMN IF EXISTS is_default;
+ALTER TABLE wallets DROP COLUMN IF EXISTS tags;
+ALTER TABLE wallets DROP COLUMN IF EXISTS color;
+ALTER TABLE wallets DROP COLUMN IF EXISTS nickname;
@@ -0,0 +1,17 @@
+-- ============================================================================
+-- Wallet Nicknames, Colors, Tags and Default Wallet
+-- ============================================================================
+
+-- User-managed labels. Nickname, color and tags are shared by a joint wallet's
+-- co-owners; is_default is the primary owner's preferred wallet.
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS nickname VARCHAR(50);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS color CHAR(7);
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE wallets ADD COLUMN IF NOT EXISTS is_default BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_wallets_tags ON wallets USING GIN (tags);
+
+-- At most one default wallet per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_default
+    ON wallets(user_id)
+    WHERE is_default;
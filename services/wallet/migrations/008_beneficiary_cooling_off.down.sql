@@ -0,0 +1,11 @@
+-- ============================================================================
+-- Beneficiary Cooling-Off Period Rollback
+-- ============================================================================
+
+DROP INDEX IF EXISTS idx_beneficiaries_owner_wallet;
+DROP INDEX IF EXISTS idx_beneficiaries_cooling_off_verification;
+
+ALTER TABLE beneficiaries DROP COLUMN IF EXISTS cooling_off_verification_id;
+ALTER TABLE beneficiaries DROP COLUMN IF EXISTS cooling_off_overridden_at;
+ALTER TABLE beneficiaries DROP COLUMN IF EXISTS cooling_off_sent;
+ALTER TABLE beneficiaries DROP COLUMN IF EXISTS cooling_off_until;
@@ -0,0 +1,21 @@
+-- ============================================================================
+-- Beneficiary Cooling-Off Period
+-- ============================================================================
+
+-- A newly added beneficiary can receive at most the configured amount until
+-- cooling_off_until. Beneficiaries added before this migration, or while the
+-- cooling-off period is disabled, have no deadline.
+ALTER TABLE beneficiaries ADD COLUMN IF NOT EXISTS cooling_off_until TIMESTAMP WITH TIME ZONE;
+ALTER TABLE beneficiaries ADD COLUMN IF NOT EXISTS cooling_off_sent BIGINT NOT NULL DEFAULT 0;
+
+-- Lifting the cooling-off early needs a step-up verification, used once
+ALTER TABLE beneficiaries ADD COLUMN IF NOT EXISTS cooling_off_overridden_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE beneficiaries ADD COLUMN IF NOT EXISTS cooling_off_verification_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_beneficiaries_cooling_off_verification
+    ON beneficiaries(cooling_off_verification_id)
+    WHERE cooling_off_verification_id IS NOT NULL;
+
+-- Transfers look beneficiaries up by the destination wallet
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_owner_wallet
+    ON beneficiaries(owner_user_id, beneficiary_wallet_id);
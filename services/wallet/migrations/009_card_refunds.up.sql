@@ -0,0 +1,18 @@
+-- ============================================================================
+-- Card Refunds Table for Idempotency
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS card_refunds (
+    refund_id        VARCHAR(100) PRIMARY KEY,
+    authorization_id UUID NOT NULL REFERENCES card_authorizations(id),
+    amount           BIGINT NOT NULL CHECK (amount > 0),
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_card_refunds_authorization ON card_refunds(authorization_id);
+
+COMMENT ON TABLE card_refunds IS
+'Refunds applied to settled card authorizations. Prevents crediting a wallet twice if the card network retries a refund.';
+
+COMMENT ON COLUMN card_refunds.refund_id IS
+'The refund ID from the card network. Used as idempotency key.';
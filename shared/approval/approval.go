@@ -0,0 +1,273 @@
+// Package approval implements maker-checker (dual control) for sensitive admin
+// operations. One admin requests an operation, which is stored as a pending
+// approval; a second admin approves it, at which point the operation runs, or
+// rejects it. Nobody can approve their own request.
+//
+// Each service creates a Manager, registers an Executor per operation and
+// mounts the Handler for its reviewers:
+//
+//	approvals := approval.NewManager("ledger", approval.NewPostgresStore(db))
+//	approvals.Register("journal_entry.void", voidExecutor)
+//
+// The pending_approvals table is shared by all services and created by the
+// RBAC service migrations.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// Status is the state of an approval.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved" // Approved and executed
+	StatusFailed   Status = "failed"   // Approved, but the operation returned an error
+	StatusRejected Status = "rejected"
+	StatusExpired  Status = "expired"
+)
+
+// DefaultTTL is how long a request waits for a reviewer.
+const DefaultTTL = 72 * time.Hour
+
+// Approval is a sensitive operation waiting for, or decided by, a second admin.
+type Approval struct {
+	ID          string          `json:"id"`
+	Service     string          `json:"service"`
+	Operation   string          `json:"operation"`
+	ResourceID  string          `json:"resource_id"`
+	Summary     string          `json:"summary"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      Status          `json:"status"`
+	RequestedBy string          `json:"requested_by"`
+	ReviewedBy  *string         `json:"reviewed_by,omitempty"`
+	ReviewNote  *string         `json:"review_note,omitempty"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       *string         `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+}
+
+// IsExpired reports whether a pending approval ran out of time.
+func (a *Approval) IsExpired() bool {
+	return a.Status == StatusPending && time.Now().After(a.ExpiresAt)
+}
+
+// DecodePayload unmarshals the operation payload.
+func (a *Approval) DecodePayload(v any) *errors.Error {
+	if err := json.Unmarshal(a.Payload, v); err != nil {
+		return errors.Internal(fmt.Sprintf("invalid payload for %s approval", a.Operation))
+	}
+	return nil
+}
+
+// Filter selects approvals to list.
+type Filter struct {
+	Service   string
+	Status    *Status
+	Operation *string
+	Limit     int
+	Offset    int
+}
+
+// Store persists approvals.
+type Store interface {
+	Create(ctx context.Context, a *Approval) *errors.Error
+	Get(ctx context.Context, id string) (*Approval, *errors.Error)
+	List(ctx context.Context, filter Filter) ([]*Approval, int64, *errors.Error)
+	// Decide moves a pending approval to the given status on behalf of a
+	// reviewer other than the requester. It returns a conflict if the approval
+	// is no longer pending, so concurrent reviewers cannot both decide.
+	Decide(ctx context.Context, id, reviewerID string, status Status, note *string) (*Approval, *errors.Error)
+	// Complete records the outcome of an approved operation.
+	Complete(ctx context.Context, id string, status Status, result json.RawMessage, errMsg *string) *errors.Error
+}
+
+// Executor runs an approved operation. Its result is stored on the approval.
+type Executor func(ctx context.Context, a *Approval) (any, *errors.Error)
+
+// Manager creates and decides approvals for one service.
+type Manager struct {
+	service   string
+	store     Store
+	ttl       time.Duration
+	executors map[string]Executor
+	logger    *logger.Logger
+}
+
+// NewManager creates a manager for the named service.
+func NewManager(service string, store Store) *Manager {
+	return &Manager{
+		service:   service,
+		store:     store,
+		ttl:       DefaultTTL,
+		executors: make(map[string]Executor),
+		logger:    logger.NewDefault(service + ".approval"),
+	}
+}
+
+// SetTTL changes how long requests wait for a reviewer.
+func (m *Manager) SetTTL(ttl time.Duration) {
+	m.ttl = ttl
+}
+
+// Register sets the executor for an operation. Operations without an
+// executor cannot be requested.
+func (m *Manager) Register(operation string, exec Executor) {
+	m.executors[operation] = exec
+}
+
+// Request stores an operation for a second admin to approve.
+func (m *Manager) Request(ctx context.Context, operation, resourceID, requestedBy, summary string, payload any) (*Approval, *errors.Error) {
+	if _, ok := m.executors[operation]; !ok {
+		return nil, errors.Internal(fmt.Sprintf("no executor registered for %s", operation))
+	}
+	if requestedBy == "" {
+		return nil, errors.Unauthorized("authentication required")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Internal("failed to encode approval payload")
+	}
+
+	now := time.Now()
+	a := &Approval{
+		Service:     m.service,
+		Operation:   operation,
+		ResourceID:  resourceID,
+		Summary:     summary,
+		Payload:     data,
+		Status:      StatusPending,
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(m.ttl),
+	}
+	if err := m.store.Create(ctx, a); err != nil {
+		return nil, err
+	}
+
+	m.logger.With(map[string]interface{}{
+		"approval_id":  a.ID,
+		"operation":    operation,
+		"resource_id":  resourceID,
+		"requested_by": requestedBy,
+	}).Info("Approval requested")
+
+	return a, nil
+}
+
+// Get returns one of this service's approvals.
+func (m *Manager) Get(ctx context.Context, id string) (*Approval, *errors.Error) {
+	a, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Service != m.service {
+		return nil, errors.NotFoundWithID("approval", id)
+	}
+	return a, nil
+}
+
+// List returns this service's approvals, newest first.
+func (m *Manager) List(ctx context.Context, filter Filter) ([]*Approval, int64, *errors.Error) {
+	filter.Service = m.service
+	return m.store.List(ctx, filter)
+}
+
+// Approve approves a pending request and runs the operation. The returned
+// approval is approved with the operation's result, or failed with its error.
+func (m *Manager) Approve(ctx context.Context, id, reviewerID string, note *string) (*Approval, *errors.Error) {
+	a, err := m.checkDecidable(ctx, id, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+	exec, ok := m.executors[a.Operation]
+	if !ok {
+		return nil, errors.Internal(fmt.Sprintf("no executor registered for %s", a.Operation))
+	}
+
+	a, err = m.store.Decide(ctx, id, reviewerID, StatusApproved, note)
+	if err != nil {
+		return nil, err
+	}
+
+	log := m.logger.With(map[string]interface{}{
+		"approval_id": a.ID,
+		"operation":   a.Operation,
+		"reviewed_by": reviewerID,
+	})
+
+	result, execErr := exec(ctx, a)
+	if execErr != nil {
+		msg := execErr.Error()
+		a.Status = StatusFailed
+		a.Error = &msg
+		if err := m.store.Complete(ctx, id, StatusFailed, nil, &msg); err != nil {
+			log.WithError(err).Error("Failed to record approval failure")
+		}
+		log.WithError(execErr).Warn("Approved operation failed")
+		return a, nil
+	}
+
+	if result != nil {
+		if data, marshalErr := json.Marshal(result); marshalErr == nil {
+			a.Result = data
+		}
+	}
+	if err := m.store.Complete(ctx, id, StatusApproved, a.Result, nil); err != nil {
+		log.WithError(err).Error("Failed to record approval result")
+	}
+	log.Info("Approved operation executed")
+
+	return a, nil
+}
+
+// Reject rejects a pending request.
+func (m *Manager) Reject(ctx context.Context, id, reviewerID string, note *string) (*Approval, *errors.Error) {
+	if _, err := m.checkDecidable(ctx, id, reviewerID); err != nil {
+		return nil, err
+	}
+	a, err := m.store.Decide(ctx, id, reviewerID, StatusRejected, note)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.With(map[string]interface{}{
+		"approval_id": a.ID,
+		"operation":   a.Operation,
+		"reviewed_by": reviewerID,
+	}).Info("Approval rejected")
+
+	return a, nil
+}
+
+// checkDecidable loads an approval and checks the reviewer may decide it.
+func (m *Manager) checkDecidable(ctx context.Context, id, reviewerID string) (*Approval, *errors.Error) {
+	if reviewerID == "" {
+		return nil, errors.Unauthorized("authentication required")
+	}
+	a, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.RequestedBy == reviewerID {
+		return nil, errors.Forbidden("an approval must be decided by a different admin than the requester")
+	}
+	if a.IsExpired() {
+		_, _ = m.store.Decide(ctx, id, reviewerID, StatusExpired, nil)
+		return nil, errors.Conflict("approval has expired")
+	}
+	if a.Status != StatusPending {
+		return nil, errors.Conflict(fmt.Sprintf("approval is already %s", a.Status))
+	}
+	return a, nil
+}
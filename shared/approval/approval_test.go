@@ -0,0 +1,138 @@
+package approval
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+type voidPayload struct {
+	EntryID string `json:"entry_id"`
+}
+
+func setupManager(t *testing.T, exec Executor) (*Manager, *MemoryStore) {
+	t.Helper()
+	store := NewMemoryStore()
+	m := NewManager("ledger", store)
+	m.Register("journal_entry.void", exec)
+	return m, store
+}
+
+func TestManager_ApproveRunsOperation(t *testing.T) {
+	var executed string
+	m, store := setupManager(t, func(ctx context.Context, a *Approval) (any, *errors.Error) {
+		var p voidPayload
+		if err := a.DecodePayload(&p); err != nil {
+			return nil, err
+		}
+		executed = p.EntryID
+		return map[string]string{"entry_id": p.EntryID, "status": "voided"}, nil
+	})
+	ctx := context.Background()
+
+	a, err := m.Request(ctx, "journal_entry.void", "entry-1", "maker", "Void entry-1", voidPayload{EntryID: "entry-1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if a.Status != StatusPending || executed != "" {
+		t.Fatalf("expected pending approval with nothing executed, got %s", a.Status)
+	}
+
+	// The requester cannot approve their own request
+	if _, err := m.Approve(ctx, a.ID, "maker", nil); err == nil || err.Code != errors.ErrCodeForbidden {
+		t.Errorf("expected forbidden for self-approval, got %v", err)
+	}
+
+	note := "checked with finance"
+	decided, err := m.Approve(ctx, a.ID, "checker", &note)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != StatusApproved || executed != "entry-1" {
+		t.Errorf("expected operation executed, got status %s executed %q", decided.Status, executed)
+	}
+	if store.approvals[a.ID].Status != StatusApproved || len(store.approvals[a.ID].Result) == 0 {
+		t.Error("expected approved status and result stored")
+	}
+
+	// A decided approval cannot be decided again
+	if _, err := m.Reject(ctx, a.ID, "other", nil); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict, got %v", err)
+	}
+}
+
+func TestManager_ApproveRecordsFailure(t *testing.T) {
+	m, store := setupManager(t, func(ctx context.Context, a *Approval) (any, *errors.Error) {
+		return nil, errors.BadRequest("entry is already voided")
+	})
+	ctx := context.Background()
+
+	a, _ := m.Request(ctx, "journal_entry.void", "entry-1", "maker", "Void entry-1", voidPayload{EntryID: "entry-1"})
+	decided, err := m.Approve(ctx, a.ID, "checker", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != StatusFailed || decided.Error == nil {
+		t.Errorf("expected failed approval with error, got %s", decided.Status)
+	}
+	if store.approvals[a.ID].Status != StatusFailed {
+		t.Errorf("expected failure stored, got %s", store.approvals[a.ID].Status)
+	}
+}
+
+func TestManager_RejectDoesNotExecute(t *testing.T) {
+	executed := false
+	m, _ := setupManager(t, func(ctx context.Context, a *Approval) (any, *errors.Error) {
+		executed = true
+		return nil, nil
+	})
+	ctx := context.Background()
+
+	a, _ := m.Request(ctx, "journal_entry.void", "entry-1", "maker", "Void entry-1", voidPayload{EntryID: "entry-1"})
+	decided, err := m.Reject(ctx, a.ID, "checker", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if decided.Status != StatusRejected || executed {
+		t.Errorf("expected rejected without execution, got %s (executed=%v)", decided.Status, executed)
+	}
+}
+
+func TestManager_Expired(t *testing.T) {
+	m, store := setupManager(t, func(ctx context.Context, a *Approval) (any, *errors.Error) {
+		t.Fatal("expired approval must not execute")
+		return nil, nil
+	})
+	m.SetTTL(-time.Minute)
+	ctx := context.Background()
+
+	a, _ := m.Request(ctx, "journal_entry.void", "entry-1", "maker", "Void entry-1", voidPayload{EntryID: "entry-1"})
+	if _, err := m.Approve(ctx, a.ID, "checker", nil); err == nil || err.Code != errors.ErrCodeConflict {
+		t.Errorf("expected conflict for expired approval, got %v", err)
+	}
+	if store.approvals[a.ID].Status != StatusExpired {
+		t.Errorf("expected expired status, got %s", store.approvals[a.ID].Status)
+	}
+}
+
+func TestManager_ScopedToService(t *testing.T) {
+	store := NewMemoryStore()
+	ledger := NewManager("ledger", store)
+	ledger.Register("journal_entry.void", func(ctx context.Context, a *Approval) (any, *errors.Error) { return nil, nil })
+	risk := NewManager("risk", store)
+	ctx := context.Background()
+
+	if _, err := risk.Request(ctx, "journal_entry.void", "entry-1", "maker", "", nil); err == nil {
+		t.Error("expected unregistered operation to be refused")
+	}
+
+	a, _ := ledger.Request(ctx, "journal_entry.void", "entry-1", "maker", "Void entry-1", voidPayload{EntryID: "entry-1"})
+	if _, err := risk.Approve(ctx, a.ID, "checker", nil); err == nil || err.Code != errors.ErrCodeNotFound {
+		t.Errorf("expected not found from another service, got %v", err)
+	}
+	if list, total, _ := risk.List(ctx, Filter{}); total != 0 || len(list) != 0 {
+		t.Errorf("expected no approvals for risk, got %d", total)
+	}
+}
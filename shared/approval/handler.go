@@ -0,0 +1,125 @@
+package approval
+
+import (
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/pagination"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// DecisionRequest is the optional body of an approve or reject call.
+type DecisionRequest struct {
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// Handler serves the review endpoints for a Manager. Services mount it
+// behind their own authentication and review permission:
+//
+//	GET  .../approvals?status=pending&operation=...
+//	GET  .../approvals/{id}
+//	POST .../approvals/{id}/approve
+//	POST .../approvals/{id}/reject
+type Handler struct {
+	manager *Manager
+}
+
+// NewHandler creates a handler for the manager's approvals.
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// List handles GET .../approvals
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	params := pagination.FromRequest(r)
+	filter := Filter{Limit: params.PerPage, Offset: params.Offset}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		s := Status(status)
+		switch s {
+		case StatusPending, StatusApproved, StatusFailed, StatusRejected, StatusExpired:
+			filter.Status = &s
+		default:
+			response.Error(w, errors.BadRequest("invalid status"))
+			return
+		}
+	}
+	if operation := r.URL.Query().Get("operation"); operation != "" {
+		filter.Operation = &operation
+	}
+
+	approvals, total, err := h.manager.List(r.Context(), filter)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.Paginated(w, approvals, params.Page, params.PerPage, total)
+}
+
+// Get handles GET .../approvals/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	a, err := h.manager.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, a)
+}
+
+// Approve handles POST .../approvals/{id}/approve. The operation runs as part
+// of the call; a failed operation is reported in the approval's status.
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	reviewerID, req, ok := decision(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.manager.Approve(r.Context(), r.PathValue("id"), reviewerID, req.Note)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, a)
+}
+
+// Reject handles POST .../approvals/{id}/reject
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	reviewerID, req, ok := decision(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := h.manager.Reject(r.Context(), r.PathValue("id"), reviewerID, req.Note)
+	if err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	response.OK(w, a)
+}
+
+// decision reads the reviewer and the optional decision body.
+func decision(w http.ResponseWriter, r *http.Request) (string, DecisionRequest, bool) {
+	var req DecisionRequest
+
+	reviewerID, ok := middleware.GetUserID(r.Context())
+	if !ok || reviewerID == "" {
+		response.Error(w, errors.Unauthorized("authentication required"))
+		return "", req, false
+	}
+
+	if r.ContentLength != 0 {
+		var bindErr *errors.Error
+		if req, bindErr = handler.BindRequest[DecisionRequest](r); bindErr != nil {
+			response.Error(w, bindErr)
+			return "", req, false
+		}
+	}
+
+	return reviewerID, req, true
+}
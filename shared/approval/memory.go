@@ -0,0 +1,114 @@
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+// MemoryStore keeps approvals in memory. It is meant for tests and local
+// development; approvals are lost on restart.
+type MemoryStore struct {
+	mu        sync.Mutex
+	approvals map[string]*Approval
+	nextID    int
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{approvals: make(map[string]*Approval)}
+}
+
+// Create stores a new approval and assigns its ID.
+func (s *MemoryStore) Create(ctx context.Context, a *Approval) *errors.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	a.ID = fmt.Sprintf("approval-%d", s.nextID)
+	copied := *a
+	s.approvals[a.ID] = &copied
+	return nil
+}
+
+// Get retrieves an approval by ID.
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Approval, *errors.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.approvals[id]
+	if !ok {
+		return nil, errors.NotFoundWithID("approval", id)
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// List returns approvals matching the filter, newest first, with the total count.
+func (s *MemoryStore) List(ctx context.Context, filter Filter) ([]*Approval, int64, *errors.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := make([]*Approval, 0)
+	for _, a := range s.approvals {
+		if a.Service != filter.Service ||
+			(filter.Status != nil && a.Status != *filter.Status) ||
+			(filter.Operation != nil && a.Operation != *filter.Operation) {
+			continue
+		}
+		copied := *a
+		matched = append(matched, &copied)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].CreatedAt.After(matched[j].CreatedAt) })
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return []*Approval{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+// Decide moves a pending approval to status on behalf of a reviewer other
+// than the requester.
+func (s *MemoryStore) Decide(ctx context.Context, id, reviewerID string, status Status, note *string) (*Approval, *errors.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.approvals[id]
+	if !ok || a.Status != StatusPending || a.RequestedBy == reviewerID {
+		return nil, errors.Conflict("approval is no longer pending")
+	}
+	now := time.Now()
+	a.Status = status
+	a.ReviewedBy = &reviewerID
+	a.ReviewNote = note
+	a.ReviewedAt = &now
+	copied := *a
+	return &copied, nil
+}
+
+// Complete records the outcome of an approved operation.
+func (s *MemoryStore) Complete(ctx context.Context, id string, status Status, result json.RawMessage, errMsg *string) *errors.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.approvals[id]
+	if !ok {
+		return errors.NotFoundWithID("approval", id)
+	}
+	a.Status = status
+	a.Result = result
+	a.Error = errMsg
+	return nil
+}
@@ -0,0 +1,159 @@
+package approval
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+)
+
+const approvalColumns = `
+	id, service, operation, resource_id, summary, payload, status, requested_by,
+	reviewed_by, review_note, result, error, created_at, expires_at, reviewed_at
+`
+
+// PostgresStore stores approvals in the pending_approvals table.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+var _ Store = (*PostgresStore)(nil)
+
+// NewPostgresStore creates a store backed by db.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanApproval(row rowScanner) (*Approval, error) {
+	a := &Approval{}
+	var payload, result []byte // Scanned as []byte so the driver's buffer is copied
+	err := row.Scan(
+		&a.ID, &a.Service, &a.Operation, &a.ResourceID, &a.Summary, &payload, &a.Status, &a.RequestedBy,
+		&a.ReviewedBy, &a.ReviewNote, &result, &a.Error, &a.CreatedAt, &a.ExpiresAt, &a.ReviewedAt,
+	)
+	a.Payload = payload
+	if len(result) > 0 {
+		a.Result = result
+	}
+	return a, err
+}
+
+// Create inserts a pending approval.
+func (s *PostgresStore) Create(ctx context.Context, a *Approval) *errors.Error {
+	query := `
+		INSERT INTO pending_approvals
+			(service, operation, resource_id, summary, payload, status, requested_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	err := s.db.QueryRowContext(ctx, query,
+		a.Service, a.Operation, a.ResourceID, a.Summary, []byte(a.Payload), a.Status, a.RequestedBy, a.CreatedAt, a.ExpiresAt,
+	).Scan(&a.ID)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to create approval")
+	}
+	return nil
+}
+
+// Get retrieves an approval by ID.
+func (s *PostgresStore) Get(ctx context.Context, id string) (*Approval, *errors.Error) {
+	query := `SELECT ` + approvalColumns + ` FROM pending_approvals WHERE id = $1`
+
+	a, err := scanApproval(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NotFoundWithID("approval", id)
+		}
+		return nil, errors.DatabaseWrap(err, "failed to get approval")
+	}
+	return a, nil
+}
+
+// List returns approvals matching the filter, newest first, with the total count.
+func (s *PostgresStore) List(ctx context.Context, filter Filter) ([]*Approval, int64, *errors.Error) {
+	where := " WHERE service = $1"
+	args := []interface{}{filter.Service}
+
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	if filter.Operation != nil {
+		args = append(args, *filter.Operation)
+		where += fmt.Sprintf(" AND operation = $%d", len(args))
+	}
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pending_approvals"+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to count approvals")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	args = append(args, limit, filter.Offset)
+	query := `SELECT ` + approvalColumns + ` FROM pending_approvals` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "failed to list approvals")
+	}
+	defer func() { _ = rows.Close() }()
+
+	approvals := make([]*Approval, 0)
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, 0, errors.DatabaseWrap(err, "failed to scan approval")
+		}
+		approvals = append(approvals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.DatabaseWrap(err, "error iterating approvals")
+	}
+
+	return approvals, total, nil
+}
+
+// Decide moves a pending approval to status. The requester can never decide
+// their own approval, even if the caller skipped that check.
+func (s *PostgresStore) Decide(ctx context.Context, id, reviewerID string, status Status, note *string) (*Approval, *errors.Error) {
+	query := `
+		UPDATE pending_approvals
+		SET status = $2, reviewed_by = $3, review_note = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND requested_by <> $3
+		RETURNING ` + approvalColumns
+
+	a, err := scanApproval(s.db.QueryRowContext(ctx, query, id, status, reviewerID, note))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.Conflict("approval is no longer pending")
+		}
+		return nil, errors.DatabaseWrap(err, "failed to decide approval")
+	}
+	return a, nil
+}
+
+// Complete records the outcome of an approved operation.
+func (s *PostgresStore) Complete(ctx context.Context, id string, status Status, result json.RawMessage, errMsg *string) *errors.Error {
+	var resultArg interface{}
+	if len(result) > 0 {
+		resultArg = []byte(result)
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE pending_approvals SET status = $2, result = $3, error = $4 WHERE id = $1`,
+		id, status, resultArg, errMsg)
+	if err != nil {
+		return errors.DatabaseWrap(err, "failed to complete approval")
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+// Package authz answers "may this subject perform this action on this
+// resource?" for every service, in place of ad hoc permission string checks.
+//
+// A service declares Rules: each grants an action to subjects holding a
+// permission, optionally only when conditions on the resource hold (the
+// subject owns it, or is the admin paired with its owner). The Authorizer
+// evaluates the rules, caches decisions briefly, logs them and counts them.
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+const (
+	// DefaultCacheTTL is how long a decision is reused. Relations such as
+	// admin pairings can take this long to be seen.
+	DefaultCacheTTL = 30 * time.Second
+	// DefaultCacheSize bounds the number of cached decisions.
+	DefaultCacheSize = 10000
+)
+
+var decisions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "authz_decisions_total",
+	Help: "Authorization decisions by service, action and effect (allow, deny, error)",
+}, []string{"service", "action", "effect"})
+
+// Subject is the caller asking to act.
+type Subject struct {
+	UserID      string
+	AccountType string
+	Roles       []string
+	Permissions []string
+}
+
+// SubjectFromContext builds the subject from the claims middleware.Auth puts
+// in the request context. It reports false for unauthenticated requests.
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok || userID == "" {
+		return Subject{}, false
+	}
+	accountType, _ := middleware.GetAccountType(ctx)
+	roles, _ := middleware.GetUserRoles(ctx)
+	permissions, _ := middleware.GetUserPermissions(ctx)
+	return Subject{UserID: userID, AccountType: accountType, Roles: roles, Permissions: permissions}, true
+}
+
+// HasPermission reports whether the subject holds permission.
+func (s Subject) HasPermission(permission string) bool {
+	return slices.Contains(s.Permissions, permission)
+}
+
+// HasRole reports whether the subject holds role.
+func (s Subject) HasRole(role string) bool {
+	return slices.Contains(s.Roles, role)
+}
+
+// Resource is what the subject wants to act on. Leave it empty for actions
+// that are not about one resource.
+type Resource struct {
+	Type       string
+	ID         string
+	OwnerID    string            // User who owns the resource, if any
+	Attributes map[string]string // Further facts conditions may use
+}
+
+// Condition restricts a rule to some requests. Check errors deny the request.
+type Condition struct {
+	Name  string
+	Check func(ctx context.Context, s Subject, r Resource) (bool, error)
+}
+
+// Rule grants Action to subjects holding Permission when every condition holds.
+type Rule struct {
+	Name       string
+	Action     string // e.g. "identity:user:read"
+	Permission string // Empty grants the action without a permission
+	Conditions []Condition
+}
+
+// Decision is the outcome of one authorization check.
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Action  string `json:"action"`
+	Rule    string `json:"rule,omitempty"`   // Rule that allowed the action
+	Reason  string `json:"reason,omitempty"` // Why the action was denied
+	Cached  bool   `json:"cached,omitempty"`
+	err     error
+}
+
+// Config configures an Authorizer.
+type Config struct {
+	Service   string         // Labels logs and metrics
+	CacheTTL  time.Duration  // Zero uses DefaultCacheTTL; negative disables caching
+	CacheSize int            // Zero uses DefaultCacheSize
+	Logger    *logger.Logger // Optional: decision logging
+}
+
+// Authorizer evaluates a service's rules.
+type Authorizer struct {
+	service string
+	rules   map[string][]Rule // By action, in declaration order
+	cache   *decisionCache
+	logger  *logger.Logger
+	now     func() time.Time
+}
+
+// New creates an authorizer for rules. Rule names must be unique and every
+// rule needs an action.
+func New(rules []Rule, cfg Config) (*Authorizer, error) {
+	byAction := make(map[string][]Rule)
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.Name == "" || seen[rule.Name] {
+			return nil, fmt.Errorf("rules[%d]: name is required and must be unique", i)
+		}
+		seen[rule.Name] = true
+		if rule.Action == "" {
+			return nil, fmt.Errorf("rules[%d]: action is required", i)
+		}
+		for _, c := range rule.Conditions {
+			if c.Name == "" || c.Check == nil {
+				return nil, fmt.Errorf("rules[%d]: conditions need a name and a check", i)
+			}
+		}
+		byAction[rule.Action] = append(byAction[rule.Action], rule)
+	}
+
+	a := &Authorizer{
+		service: cfg.Service,
+		rules:   byAction,
+		logger:  cfg.Logger,
+		now:     time.Now,
+	}
+	if cfg.CacheTTL >= 0 {
+		ttl, size := cfg.CacheTTL, cfg.CacheSize
+		if ttl == 0 {
+			ttl = DefaultCacheTTL
+		}
+		if size <= 0 {
+			size = DefaultCacheSize
+		}
+		a.cache = newDecisionCache(ttl, size)
+	}
+	return a, nil
+}
+
+// Decide evaluates the rules for action. The first rule whose permission the
+// subject holds and whose conditions all hold allows the action; with none,
+// it is denied.
+func (a *Authorizer) Decide(ctx context.Context, s Subject, action string, r Resource) Decision {
+	key := ""
+	if a.cache != nil {
+		key = cacheKey(s, action, r)
+		if d, ok := a.cache.get(key, a.now()); ok {
+			d.Cached = true
+			a.record(ctx, s, r, d)
+			return d
+		}
+	}
+
+	d := a.evaluate(ctx, s, action, r)
+	if a.cache != nil && d.err == nil {
+		a.cache.put(key, d, a.now())
+	}
+	a.record(ctx, s, r, d)
+	return d
+}
+
+func (a *Authorizer) evaluate(ctx context.Context, s Subject, action string, r Resource) Decision {
+	rules := a.rules[action]
+	if len(rules) == 0 {
+		return Decision{Action: action, Reason: "no rule grants this action"}
+	}
+
+	reason := ""
+	for _, rule := range rules {
+		if rule.Permission != "" && !s.HasPermission(rule.Permission) {
+			if reason == "" {
+				reason = "missing permission " + rule.Permission
+			}
+			continue
+		}
+		failed, err := firstFailedCondition(ctx, rule, s, r)
+		if err != nil {
+			return Decision{Action: action, Reason: fmt.Sprintf("condition %s could not be checked", failed), err: err}
+		}
+		if failed == "" {
+			return Decision{Allowed: true, Action: action, Rule: rule.Name}
+		}
+		reason = fmt.Sprintf("condition %s of rule %s not met", failed, rule.Name)
+	}
+	return Decision{Action: action, Reason: reason}
+}
+
+// firstFailedCondition returns the name of the first condition of rule that
+// does not hold, or "" when all hold.
+func firstFailedCondition(ctx context.Context, rule Rule, s Subject, r Resource) (string, error) {
+	for _, c := range rule.Conditions {
+		ok, err := c.Check(ctx, s, r)
+		if err != nil {
+			return c.Name, err
+		}
+		if !ok {
+			return c.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// Authorize returns a Forbidden error unless the subject may perform action
+// on the resource.
+func (a *Authorizer) Authorize(ctx context.Context, s Subject, action string, r Resource) *errors.Error {
+	d := a.Decide(ctx, s, action, r)
+	if d.Allowed {
+		return nil
+	}
+	return errors.Forbidden("you are not allowed to perform this action").
+		AddDetail("action", action)
+}
+
+// AuthorizeContext authorizes the subject from the request context.
+func (a *Authorizer) AuthorizeContext(ctx context.Context, action string, r Resource) *errors.Error {
+	s, ok := SubjectFromContext(ctx)
+	if !ok {
+		return errors.Unauthorized("user not authenticated")
+	}
+	return a.Authorize(ctx, s, action, r)
+}
+
+// AllowedActions returns those of actions the subject may perform when no
+// particular resource is involved, so conditions on the resource do not hold.
+// It is meant for deciding what to show, so it is neither cached nor logged.
+func (a *Authorizer) AllowedActions(ctx context.Context, s Subject, actions ...string) []string {
+	allowed := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if a.evaluate(ctx, s, action, Resource{}).Allowed {
+			allowed = append(allowed, action)
+		}
+	}
+	return allowed
+}
+
+// record logs and counts a decision. Denials are logged at info level so
+// they can be audited; allows only at debug level.
+func (a *Authorizer) record(ctx context.Context, s Subject, r Resource, d Decision) {
+	effect := "deny"
+	switch {
+	case d.err != nil:
+		effect = "error"
+	case d.Allowed:
+		effect = "allow"
+	}
+	decisions.WithLabelValues(a.service, d.Action, effect).Inc()
+
+	if a.logger == nil {
+		return
+	}
+	log := a.logger.WithContext(ctx).With(map[string]interface{}{
+		"action":        d.Action,
+		"subject":       s.UserID,
+		"resource_type": r.Type,
+		"resource_id":   r.ID,
+		"cached":        d.Cached,
+	})
+	switch effect {
+	case "allow":
+		log.WithField("rule", d.Rule).Debug("Authorization allowed")
+	case "error":
+		log.WithError(d.err).WithField("reason", d.Reason).Warn("Authorization check failed")
+	default:
+		log.WithField("reason", d.Reason).Info("Authorization denied")
+	}
+}
+
+// cacheKey identifies everything a decision depends on.
+func cacheKey(s Subject, action string, r Resource) string {
+	perms := slices.Clone(s.Permissions)
+	slices.Sort(perms)
+	roles := slices.Clone(s.Roles)
+	slices.Sort(roles)
+
+	var b strings.Builder
+	for _, part := range []string{s.UserID, s.AccountType, strings.Join(roles, ","), strings.Join(perms, ","), action, r.Type, r.ID, r.OwnerID} {
+		b.WriteString(part)
+		b.WriteByte(0)
+	}
+	keys := make([]string, 0, len(r.Attributes))
+	for k := range r.Attributes {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(r.Attributes[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
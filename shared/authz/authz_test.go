@@ -0,0 +1,177 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+)
+
+// pairings maps admin IDs to the user they administer, counting lookups.
+type pairings struct {
+	pairs   map[string]string
+	lookups int
+	err     error
+}
+
+func (p *pairings) related(_ context.Context, adminID, userID string) (bool, error) {
+	p.lookups++
+	if p.err != nil {
+		return false, p.err
+	}
+	return p.pairs[adminID] == userID, nil
+}
+
+func newTestAuthorizer(t *testing.T, p *pairings, ttl time.Duration) *Authorizer {
+	t.Helper()
+	a, err := New([]Rule{
+		{Name: "staff", Action: "wallet:read", Permission: "wallet:wallet:read"},
+		{Name: "owner", Action: "wallet:read", Conditions: []Condition{Owner()}},
+		{Name: "paired-admin", Action: "wallet:read", Conditions: []Condition{AccountType("user_admin"), Related("paired", p.related)}},
+	}, Config{Service: "test", CacheTTL: ttl})
+	if err != nil {
+		t.Fatalf("expected valid rules, got %v", err)
+	}
+	return a
+}
+
+func TestDecide(t *testing.T) {
+	p := &pairings{pairs: map[string]string{"admin-1": "user-1"}}
+	a := newTestAuthorizer(t, p, -1)
+	wallet := Resource{Type: "wallet", ID: "w-1", OwnerID: "user-1"}
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		resource Resource
+		allowed  bool
+		rule     string
+	}{
+		{"staff permission", Subject{UserID: "ops", Permissions: []string{"wallet:wallet:read"}}, wallet, true, "staff"},
+		{"owner", Subject{UserID: "user-1"}, wallet, true, "owner"},
+		{"paired admin", Subject{UserID: "admin-1", AccountType: "user_admin"}, wallet, true, "paired-admin"},
+		{"admin of another user", Subject{UserID: "admin-1", AccountType: "user_admin"}, Resource{Type: "wallet", OwnerID: "user-2"}, false, ""},
+		{"pairing needs the account type", Subject{UserID: "admin-1", AccountType: "user"}, Resource{Type: "wallet", OwnerID: "user-1"}, false, ""},
+		{"stranger", Subject{UserID: "user-2"}, wallet, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := a.Decide(context.Background(), tt.subject, "wallet:read", tt.resource)
+			if d.Allowed != tt.allowed || d.Rule != tt.rule {
+				t.Errorf("expected allowed=%v by %q, got %+v", tt.allowed, tt.rule, d)
+			}
+			if !d.Allowed && d.Reason == "" {
+				t.Error("expected a reason for the denial")
+			}
+		})
+	}
+}
+
+func TestDecide_UnknownActionDenied(t *testing.T) {
+	a := newTestAuthorizer(t, &pairings{}, -1)
+	d := a.Decide(context.Background(), Subject{UserID: "u", Permissions: []string{"wallet:wallet:read"}}, "wallet:delete", Resource{})
+	if d.Allowed {
+		t.Error("expected actions without rules to be denied")
+	}
+}
+
+func TestDecide_CachesDecisions(t *testing.T) {
+	p := &pairings{pairs: map[string]string{"admin-1": "user-1"}}
+	a := newTestAuthorizer(t, p, time.Minute)
+	now := time.Now()
+	a.now = func() time.Time { return now }
+
+	admin := Subject{UserID: "admin-1", AccountType: "user_admin"}
+	wallet := Resource{Type: "wallet", ID: "w-1", OwnerID: "user-1"}
+	first := a.Decide(context.Background(), admin, "wallet:read", wallet)
+	second := a.Decide(context.Background(), admin, "wallet:read", wallet)
+
+	if !first.Allowed || !second.Allowed || !second.Cached || p.lookups != 1 {
+		t.Errorf("expected the second decision from cache, got %+v after %d lookups", second, p.lookups)
+	}
+
+	// A different permission set is a different decision
+	a.Decide(context.Background(), Subject{UserID: "admin-1", AccountType: "user_admin", Permissions: []string{"x"}}, "wallet:read", wallet)
+	if p.lookups != 2 {
+		t.Errorf("expected a fresh lookup for other permissions, got %d lookups", p.lookups)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if d := a.Decide(context.Background(), admin, "wallet:read", wallet); d.Cached || p.lookups != 3 {
+		t.Errorf("expected expired decisions to be evaluated again, got %+v after %d lookups", d, p.lookups)
+	}
+
+	a.Flush()
+	if d := a.Decide(context.Background(), admin, "wallet:read", wallet); d.Cached {
+		t.Error("expected flush to drop cached decisions")
+	}
+}
+
+func TestDecide_ConditionErrorsDenyAndAreNotCached(t *testing.T) {
+	p := &pairings{err: fmt.Errorf("database down")}
+	a := newTestAuthorizer(t, p, time.Minute)
+	admin := Subject{UserID: "admin-1", AccountType: "user_admin"}
+	wallet := Resource{Type: "wallet", OwnerID: "user-1"}
+
+	for range 2 {
+		if d := a.Decide(context.Background(), admin, "wallet:read", wallet); d.Allowed || d.Cached {
+			t.Fatalf("expected an uncached denial, got %+v", d)
+		}
+	}
+	if p.lookups != 2 {
+		t.Errorf("expected each check to retry the lookup, got %d", p.lookups)
+	}
+}
+
+func TestAuthorizeContext(t *testing.T) {
+	a := newTestAuthorizer(t, &pairings{}, -1)
+
+	if err := a.AuthorizeContext(context.Background(), "wallet:read", Resource{}); err == nil || err.Code != errors.ErrCodeUnauthorized {
+		t.Errorf("expected unauthorized without a subject, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), middleware.UserIDKey, "user-2")
+	err := a.AuthorizeContext(ctx, "wallet:read", Resource{Type: "wallet", OwnerID: "user-1"})
+	if err == nil || err.Code != errors.ErrCodeForbidden || err.Details["action"] != "wallet:read" {
+		t.Errorf("expected forbidden naming the action, got %v", err)
+	}
+
+	ctx = context.WithValue(context.Background(), middleware.UserIDKey, "user-1")
+	if err := a.AuthorizeContext(ctx, "wallet:read", Resource{Type: "wallet", OwnerID: "user-1"}); err != nil {
+		t.Errorf("expected owner to be allowed, got %v", err)
+	}
+}
+
+func TestAllowedActions_IgnoresResourceRules(t *testing.T) {
+	a, err := New([]Rule{
+		{Name: "ui-users", Action: "admin:users:view", Permission: "identity:kyc:list"},
+		{Name: "ui-users-admin", Action: "admin:users:view", Conditions: []Condition{AccountType("user_admin")}},
+		{Name: "ui-ledger", Action: "admin:ledger:view", Permission: "ledger:entry:create"},
+		{Name: "own-wallet", Action: "admin:wallets:view", Conditions: []Condition{Owner()}},
+	}, Config{})
+	if err != nil {
+		t.Fatalf("expected valid rules, got %v", err)
+	}
+
+	got := a.AllowedActions(context.Background(), Subject{UserID: "a", AccountType: "user_admin"}, "admin:users:view", "admin:ledger:view", "admin:wallets:view")
+	if len(got) != 1 || got[0] != "admin:users:view" {
+		t.Errorf("expected only the users section, got %v", got)
+	}
+}
+
+func TestNew_RejectsInvalidRules(t *testing.T) {
+	invalid := [][]Rule{
+		{{Name: "", Action: "a"}},
+		{{Name: "x", Action: "a"}, {Name: "x", Action: "b"}},
+		{{Name: "x"}},
+		{{Name: "x", Action: "a", Conditions: []Condition{{Name: "broken"}}}},
+	}
+	for i, rules := range invalid {
+		if _, err := New(rules, Config{}); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
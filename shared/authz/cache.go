@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"sync"
+	"time"
+)
+
+type cachedDecision struct {
+	decision  Decision
+	expiresAt time.Time
+}
+
+// decisionCache keeps recent decisions in memory.
+type decisionCache struct {
+	ttl     time.Duration
+	size    int
+	mu      sync.Mutex
+	entries map[string]cachedDecision
+}
+
+func newDecisionCache(ttl time.Duration, size int) *decisionCache {
+	return &decisionCache{ttl: ttl, size: size, entries: make(map[string]cachedDecision)}
+}
+
+func (c *decisionCache) get(key string, now time.Time) (Decision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return Decision{}, false
+	}
+	return entry.decision, true
+}
+
+func (c *decisionCache) put(key string, d Decision, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		// Still full of live entries: start over rather than track recency
+		if len(c.entries) >= c.size {
+			c.entries = make(map[string]cachedDecision)
+		}
+	}
+	c.entries[key] = cachedDecision{decision: d, expiresAt: now.Add(c.ttl)}
+}
+
+// Flush drops every cached decision, e.g. after a relation changes.
+func (a *Authorizer) Flush() {
+	if a.cache == nil {
+		return
+	}
+	a.cache.mu.Lock()
+	defer a.cache.mu.Unlock()
+	a.cache.entries = make(map[string]cachedDecision)
+}
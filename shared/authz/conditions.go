@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"context"
+	"slices"
+)
+
+// Owner holds when the subject owns the resource.
+func Owner() Condition {
+	return Condition{
+		Name: "owner",
+		Check: func(_ context.Context, s Subject, r Resource) (bool, error) {
+			return r.OwnerID != "" && r.OwnerID == s.UserID, nil
+		},
+	}
+}
+
+// AccountType holds when the subject has one of the account types.
+func AccountType(types ...string) Condition {
+	return Condition{
+		Name: "account_type",
+		Check: func(_ context.Context, s Subject, _ Resource) (bool, error) {
+			return slices.Contains(types, s.AccountType), nil
+		},
+	}
+}
+
+// RelationFunc reports whether subjectID stands in a relation to ownerID,
+// e.g. whether it is the admin account paired with the owner.
+type RelationFunc func(ctx context.Context, subjectID, ownerID string) (bool, error)
+
+// Related holds when related reports the subject in relation to the
+// resource's owner. Resources without an owner never match.
+func Related(name string, related RelationFunc) Condition {
+	return Condition{
+		Name: name,
+		Check: func(ctx context.Context, s Subject, r Resource) (bool, error) {
+			if r.OwnerID == "" {
+				return false, nil
+			}
+			return related(ctx, s.UserID, r.OwnerID)
+		},
+	}
+}
+
+// AttributeEquals holds when the resource attribute key matches the value
+// subjectValue returns for the subject, e.g. the same region.
+func AttributeEquals(key string, subjectValue func(Subject) string) Condition {
+	return Condition{
+		Name: "attribute_" + key,
+		Check: func(_ context.Context, s Subject, r Resource) (bool, error) {
+			v, ok := r.Attributes[key]
+			return ok && v != "" && v == subjectValue(s), nil
+		},
+	}
+}
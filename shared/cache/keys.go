@@ -9,9 +9,10 @@ import (
 
 // Cache key prefixes
 const (
-	PrefixSession = "session:"
-	PrefixUser    = "user:"
-	PrefixToken   = "token:"
+	PrefixSession     = "session:"
+	PrefixUser        = "user:"
+	PrefixToken       = "token:"
+	PrefixPermissions = "perms:"
 )
 
 // Default TTLs
@@ -19,6 +20,7 @@ const (
 	SessionTTL     = 24 * time.Hour // Match JWT expiry
 	UserProfileTTL = 15 * time.Minute
 	TokenTTL       = 24 * time.Hour
+	PermissionsTTL = time.Minute // Bounds how long role permission changes take to apply
 )
 
 // SessionKey generates a cache key for user sessions.
@@ -38,6 +40,12 @@ func UserKey(userID string) string {
 	return fmt.Sprintf("%s%s", PrefixUser, userID)
 }
 
+// PermissionsKey generates a cache key for a user's effective permissions.
+// Format: perms:{user_id}
+func PermissionsKey(userID string) string {
+	return PrefixPermissions + userID
+}
+
 // TokenKey generates a cache key for token validation.
 // Format: token:{token_hash}
 func TokenKey(token string) string {
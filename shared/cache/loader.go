@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LoadFunc loads the value of a key on a cache miss.
+type LoadFunc func(ctx context.Context) (string, error)
+
+// Loader reads through a cache, loading and storing values on a miss. When
+// many callers miss the same key at once, only one of them runs the load and
+// the rest wait for its result, so an expiring hot key does not stampede the
+// database.
+type Loader struct {
+	cache Cache
+
+	mu    sync.Mutex
+	calls map[string]*loadCall
+}
+
+// loadCall is a load in flight for one key.
+type loadCall struct {
+	done  chan struct{}
+	value string
+	err   error
+
+	mu    sync.Mutex // Orders storing the value against Forget
+	stale bool       // Key was forgotten during the load; do not store the value
+}
+
+// NewLoader creates a loader reading through c.
+func NewLoader(c Cache) *Loader {
+	return &Loader{cache: c, calls: make(map[string]*loadCall)}
+}
+
+// Cache returns the cache the loader reads through, for invalidation.
+func (l *Loader) Cache() Cache {
+	return l.cache
+}
+
+// GetOrLoad returns the cached value of key, or loads it and caches it for ttl.
+// Concurrent misses on the same key share one load. Load errors are returned
+// to every waiting caller and not cached. Cache errors are treated as misses,
+// so a failing cache degrades to calling load.
+//
+// The load is not cancelled when the caller that started it gives up, since
+// other callers may be waiting on it; a caller whose ctx ends stops waiting
+// and gets ctx.Err().
+func (l *Loader) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load LoadFunc) (string, error) {
+	if value, found, err := l.cache.Get(ctx, key); err == nil && found {
+		return value, nil
+	}
+
+	l.mu.Lock()
+	call, inFlight := l.calls[key]
+	if !inFlight {
+		call = &loadCall{done: make(chan struct{})}
+		l.calls[key] = call
+	}
+	l.mu.Unlock()
+
+	if !inFlight {
+		go l.run(context.WithoutCancel(ctx), key, ttl, load, call)
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// run performs a load, caches its value and releases the waiting callers.
+func (l *Loader) run(ctx context.Context, key string, ttl time.Duration, load LoadFunc, call *loadCall) {
+	defer func() {
+		l.mu.Lock()
+		if l.calls[key] == call {
+			delete(l.calls, key)
+		}
+		l.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = load(ctx)
+	if call.err != nil {
+		return
+	}
+
+	call.mu.Lock()
+	defer call.mu.Unlock()
+	if !call.stale {
+		_ = l.cache.Set(ctx, key, call.value, ttl)
+	}
+}
+
+// Forget removes key from the cache, e.g. after the data behind it changed.
+// A load of key already in flight still returns to its callers, which asked
+// before the change, but its value is not cached; the next miss loads again.
+func (l *Loader) Forget(ctx context.Context, key string) error {
+	l.mu.Lock()
+	call, inFlight := l.calls[key]
+	if inFlight {
+		delete(l.calls, key)
+	}
+	l.mu.Unlock()
+
+	if inFlight {
+		call.mu.Lock()
+		call.stale = true
+		call.mu.Unlock()
+	}
+	return l.cache.Delete(ctx, key)
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoader_ConcurrentMissesShareOneLoad(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(NewMemoryCache(10))
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (string, error) {
+		loads.Add(1)
+		<-release
+		return "perms", nil
+	}
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make([]string, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = loader.GetOrLoad(ctx, "user:1", time.Minute, load)
+		}()
+	}
+
+	// Let every caller miss before the load finishes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("expected 1 load, got %d", n)
+	}
+	for i, result := range results {
+		if result != "perms" {
+			t.Errorf("caller %d: expected perms, got %q", i, result)
+		}
+	}
+
+	// Later reads are served from the cache
+	if _, err := loader.GetOrLoad(ctx, "user:1", time.Minute, load); err != nil || loads.Load() != 1 {
+		t.Errorf("expected cached value without another load, got %d loads, %v", loads.Load(), err)
+	}
+}
+
+func TestLoader_ErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	loader := NewLoader(NewMemoryCache(10))
+	errLoad := errors.New("database unavailable")
+
+	if _, err := loader.GetOrLoad(ctx, "k", time.Minute, func(ctx context.Context) (string, error) {
+		return "", errLoad
+	}); !errors.Is(err, errLoad) {
+		t.Fatalf("expected load error, got %v", err)
+	}
+
+	value, err := loader.GetOrLoad(ctx, "k", time.Minute, func(ctx context.Context) (string, error) {
+		return "v", nil
+	})
+	if err != nil || value != "v" {
+		t.Errorf("expected retry to load v, got %q %v", value, err)
+	}
+}
+
+func TestLoader_WaiterStopsOnContextCancel(t *testing.T) {
+	loader := NewLoader(NewMemoryCache(10))
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := loader.GetOrLoad(ctx, "k", time.Minute, func(ctx context.Context) (string, error) {
+		<-release
+		return "v", nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestLoader_ForgetDuringLoadDropsStaleValue(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+	loader := NewLoader(c)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan string)
+	go func() {
+		value, _ := loader.GetOrLoad(ctx, "k", time.Minute, func(ctx context.Context) (string, error) {
+			close(started)
+			<-release
+			return "stale", nil
+		})
+		done <- value
+	}()
+
+	<-started
+	_ = loader.Forget(ctx, "k")
+	close(release)
+
+	if value := <-done; value != "stale" {
+		t.Errorf("expected in-flight caller to get its value, got %q", value)
+	}
+	if _, found, _ := c.Get(ctx, "k"); found {
+		t.Error("expected value loaded before Forget not to be cached")
+	}
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMemoryEntries is the default capacity of a MemoryCache.
+const DefaultMemoryEntries = 10000
+
+// MemoryCache implements Cache as an in-process LRU. It is the fallback when
+// Redis is not configured or unreachable, and the local tier of a TieredCache.
+// Entries are evicted least recently used first once the cache is full, and
+// expired entries are dropped when they are next read.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List               // Front is most recently used
+	items      map[string]*list.Element // key -> *memoryEntry element
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time // Zero means no expiry
+}
+
+// NewMemoryCache creates an LRU cache holding at most maxEntries values;
+// maxEntries <= 0 uses DefaultMemoryEntries.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryEntries
+	}
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// lookup returns the live element for key, dropping it if expired.
+// Must be called with mu held.
+func (m *MemoryCache) lookup(key string) (*list.Element, bool) {
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		m.remove(el)
+		return nil, false
+	}
+	return el, true
+}
+
+// remove drops an element. Must be called with mu held.
+func (m *MemoryCache) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
+
+// Get retrieves a value by key and marks it recently used.
+func (m *MemoryCache) Get(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.lookup(key)
+	if !ok {
+		return "", false, nil
+	}
+	m.ll.MoveToFront(el)
+	return el.Value.(*memoryEntry).value, true, nil
+}
+
+// Set stores a value with the given key and TTL; ttl <= 0 never expires.
+func (m *MemoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = m.now().Add(ttl)
+	}
+
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		m.ll.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for m.ll.Len() > m.maxEntries {
+		m.remove(m.ll.Back())
+	}
+	return nil
+}
+
+// Delete removes a value by key.
+func (m *MemoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+	return nil
+}
+
+// Exists checks if a live key is in the cache, without marking it used.
+func (m *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.lookup(key)
+	return ok, nil
+}
+
+// Len returns the number of entries, including expired ones not yet dropped.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ll.Len()
+}
+
+// Ping always succeeds.
+func (m *MemoryCache) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close drops every entry.
+func (m *MemoryCache) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ll.Init()
+	m.items = make(map[string]*list.Element)
+	return nil
+}
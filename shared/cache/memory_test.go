@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+
+	_ = c.Set(ctx, "a", "1", 0)
+	_ = c.Set(ctx, "b", "2", 0)
+	if _, found, _ := c.Get(ctx, "a"); !found {
+		t.Fatal("expected a to be cached")
+	}
+	_ = c.Set(ctx, "c", "3", 0) // b is now least recently used
+
+	if _, found, _ := c.Get(ctx, "b"); found {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, found, _ := c.Get(ctx, key); !found {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestMemoryCache_ExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "session", "alice", time.Minute)
+	_ = c.Set(ctx, "forever", "bob", 0)
+
+	now = now.Add(time.Minute)
+	if _, found, _ := c.Get(ctx, "session"); found {
+		t.Error("expected session to expire")
+	}
+	if exists, _ := c.Exists(ctx, "forever"); !exists {
+		t.Error("expected entry without TTL to remain")
+	}
+	if c.Len() != 1 {
+		t.Errorf("expected expired entry to be dropped, got %d entries", c.Len())
+	}
+}
+
+func TestMemoryCache_SetOverwritesAndDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	_ = c.Set(ctx, "k", "old", 0)
+	_ = c.Set(ctx, "k", "new", 0)
+	if value, _, _ := c.Get(ctx, "k"); value != "new" {
+		t.Errorf("expected new, got %q", value)
+	}
+
+	_ = c.Delete(ctx, "k")
+	if _, found, _ := c.Get(ctx, "k"); found {
+		t.Error("expected k to be deleted")
+	}
+}
+
+// failingCache is a remote tier that is down.
+type failingCache struct{ NoOpCache }
+
+var errDown = errors.New("connection refused")
+
+func (f *failingCache) Get(ctx context.Context, key string) (string, bool, error) {
+	return "", false, errDown
+}
+
+func (f *failingCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return errDown
+}
+
+func TestTieredCache_ReadsThroughAndCapsLocalTTL(t *testing.T) {
+	ctx := context.Background()
+	local, remote := NewMemoryCache(10), NewMemoryCache(10)
+	tiered := NewTieredCache(local, remote, time.Second)
+
+	_ = remote.Set(ctx, "k", "v", time.Hour)
+	if value, found, _ := tiered.Get(ctx, "k"); !found || value != "v" {
+		t.Fatalf("expected remote value, got %q %v", value, found)
+	}
+	if _, found, _ := local.Get(ctx, "k"); !found {
+		t.Error("expected remote hit to be kept in memory")
+	}
+
+	now := time.Now()
+	local.now = func() time.Time { return now.Add(2 * time.Second) }
+	if _, found, _ := local.Get(ctx, "k"); found {
+		t.Error("expected local copy to expire after the local TTL")
+	}
+
+	_ = tiered.Delete(ctx, "k")
+	if exists, _ := remote.Exists(ctx, "k"); exists {
+		t.Error("expected delete to reach the remote tier")
+	}
+}
+
+func TestTieredCache_ServesFromMemoryWhenRemoteFails(t *testing.T) {
+	ctx := context.Background()
+	tiered := NewTieredCache(NewMemoryCache(10), &failingCache{}, time.Minute)
+
+	if err := tiered.Set(ctx, "k", "v", time.Hour); !errors.Is(err, errDown) {
+		t.Errorf("expected remote error, got %v", err)
+	}
+	if value, found, err := tiered.Get(ctx, "k"); err != nil || !found || value != "v" {
+		t.Errorf("expected value from memory, got %q %v %v", value, found, err)
+	}
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultLocalTTL caps how long a TieredCache keeps values in memory.
+const DefaultLocalTTL = 30 * time.Second
+
+// TieredCache implements Cache with an in-process tier in front of a shared
+// one, usually a MemoryCache in front of Redis. Reads are served from memory
+// when possible; writes and deletes go to both tiers.
+//
+// A delete only reaches the memory tier of the instance that made it, so other
+// instances may serve the old value until their copy expires. Local copies
+// therefore live at most localTTL.
+type TieredCache struct {
+	local    Cache
+	remote   Cache
+	localTTL time.Duration
+}
+
+// NewTieredCache creates a cache reading local before remote. Values are kept
+// locally for at most localTTL; localTTL <= 0 uses DefaultLocalTTL.
+func NewTieredCache(local, remote Cache, localTTL time.Duration) *TieredCache {
+	if localTTL <= 0 {
+		localTTL = DefaultLocalTTL
+	}
+	return &TieredCache{local: local, remote: remote, localTTL: localTTL}
+}
+
+// localExpiry returns the local TTL for a value stored remotely for ttl.
+func (t *TieredCache) localExpiry(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < t.localTTL {
+		return ttl
+	}
+	return t.localTTL
+}
+
+// Get retrieves a value from memory, falling back to the remote tier and
+// keeping what it finds there in memory.
+func (t *TieredCache) Get(ctx context.Context, key string) (string, bool, error) {
+	if value, found, err := t.local.Get(ctx, key); err == nil && found {
+		return value, true, nil
+	}
+
+	value, found, err := t.remote.Get(ctx, key)
+	if err != nil || !found {
+		return "", false, err
+	}
+	_ = t.local.Set(ctx, key, value, t.localTTL)
+	return value, true, nil
+}
+
+// Set stores a value in both tiers. The remote error, if any, is returned;
+// the value is still served from memory.
+func (t *TieredCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	_ = t.local.Set(ctx, key, value, t.localExpiry(ttl))
+	return t.remote.Set(ctx, key, value, ttl)
+}
+
+// Delete removes a value from both tiers.
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	_ = t.local.Delete(ctx, key)
+	return t.remote.Delete(ctx, key)
+}
+
+// Exists checks if a key exists in either tier.
+func (t *TieredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if found, err := t.local.Exists(ctx, key); err == nil && found {
+		return true, nil
+	}
+	return t.remote.Exists(ctx, key)
+}
+
+// Ping checks the remote tier's health.
+func (t *TieredCache) Ping(ctx context.Context) error {
+	return t.remote.Ping(ctx)
+}
+
+// Close closes both tiers.
+func (t *TieredCache) Close() error {
+	_ = t.local.Close()
+	return t.remote.Close()
+}
+
+// Open returns the cache a service should use: memory in front of Redis when
+// redisURL is set and reachable, otherwise memory alone. Without Redis a
+// delete cannot reach other instances, so values are still kept in memory for
+// at most DefaultLocalTTL. The Redis client is returned so the caller can
+// close it on shutdown; a Redis connection error is returned alongside the
+// memory-only fallback so the caller can log it.
+func Open(redisURL string, maxEntries int) (Cache, *RedisCache, error) {
+	local := NewMemoryCache(maxEntries)
+	if redisURL == "" {
+		return NewTieredCache(local, NewNoOpCache(), DefaultLocalTTL), nil, nil
+	}
+
+	redisCache, err := NewRedisCache(DefaultRedisConfig(redisURL))
+	if err != nil {
+		return NewTieredCache(local, NewNoOpCache(), DefaultLocalTTL), nil, err
+	}
+	return NewTieredCache(local, redisCache, DefaultLocalTTL), redisCache, nil
+}
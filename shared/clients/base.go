@@ -12,6 +12,8 @@ import (
 
 	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 // Default timeouts for service clients
@@ -28,6 +30,7 @@ type BaseClient struct {
 	baseURL        string
 	httpClient     *http.Client
 	defaultHeaders map[string]string
+	signer         *serviceauth.Signer // Optional: attaches a service token to every request
 }
 
 // NewBaseClient creates a new base client with the specified timeout.
@@ -70,14 +73,22 @@ func (c *BaseClient) SetInternalSecret(secret string) {
 
 // NewInternalClient creates a base client configured for internal service-to-service calls.
 // The secret is sent as X-Internal-Secret header and validated by InternalAuth middleware.
+// When the process has a default service signer, every request also carries a
+// service token, validated by the ServiceAuth middleware.
 func NewInternalClient(baseURL string, timeout time.Duration, internalSecret string) *BaseClient {
 	client := NewBaseClient(baseURL, timeout)
 	if internalSecret != "" {
 		client.defaultHeaders["X-Internal-Secret"] = internalSecret
 	}
+	client.signer = serviceauth.DefaultSigner()
 	return client
 }
 
+// SetServiceSigner attaches a token from signer to every request.
+func (c *BaseClient) SetServiceSigner(signer *serviceauth.Signer) {
+	c.signer = signer
+}
+
 // BaseURL returns the base URL for building endpoint paths.
 func (c *BaseClient) BaseURL() string {
 	return c.baseURL
@@ -110,7 +121,7 @@ func (c *BaseClient) Post(ctx context.Context, path string, body, result any) *e
 
 // PostWithHeaders performs a POST request with additional headers.
 func (c *BaseClient) PostWithHeaders(ctx context.Context, path string, body, result any, headers map[string]string) *errors.Error {
-	return c.doJSON(ctx, http.MethodPost, path, body, result, headers, http.StatusOK, http.StatusCreated)
+	return c.doJSON(ctx, http.MethodPost, path, body, result, headers, http.StatusOK, http.StatusCreated, http.StatusNoContent)
 }
 
 // Put performs a PUT request with JSON body and parses the envelope response.
@@ -175,10 +186,24 @@ func (c *BaseClient) doRequest(req *http.Request, result any, headers map[string
 	for k, v := range headers {
 		req.Header.Set(k, v)
 	}
+	if c.signer != nil {
+		token, err := c.signer.Token()
+		if err != nil {
+			return errors.Internal(fmt.Sprintf("failed to sign service token: %v", err))
+		}
+		req.Header.Set(serviceauth.Header, token)
+	}
+	// Propagate the caller's request ID so downstream logs can be correlated
+	if req.Header.Get(requestid.Header) == "" {
+		if id := requestid.FromContext(req.Context()); id != "" {
+			req.Header.Set(requestid.Header, id)
+		}
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return errors.Internal(fmt.Sprintf("request failed: %v", err))
+		// The service could not be reached; callers may retry (see IsRetryable)
+		return errors.Wrap(err, errors.ErrCodeUnavailable, fmt.Sprintf("request failed: %v", err))
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -280,6 +305,12 @@ func (c *BaseClient) parseErrorResponse(statusCode int, respBody []byte) *errors
 
 	msg := string(respBody)
 	if err := json.Unmarshal(respBody, &envelope); err == nil && envelope.Error != nil {
+		// Keep catalogued codes so callers can branch on them across service hops
+		code := errors.ErrorCode(envelope.Error.Code)
+		if entry, ok := errors.Lookup(code); ok && entry.Status == statusCode && envelope.Error.Message != "" {
+			return errors.New(code, envelope.Error.Message)
+		}
+
 		msg = envelope.Error.Message
 		if envelope.Error.Code != "" {
 			msg = fmt.Sprintf("%s: %s", envelope.Error.Code, envelope.Error.Message)
@@ -305,7 +336,25 @@ func errorForStatusCode(statusCode int, msg string) *errors.Error {
 		return errors.Unauthorized(msg)
 	case http.StatusForbidden:
 		return errors.Forbidden(msg)
+	case http.StatusConflict:
+		return errors.Conflict(msg)
+	case http.StatusTooManyRequests:
+		return errors.New(errors.ErrCodeRateLimit, msg)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errors.Unavailable(msg)
 	default:
 		return errors.Internal(msg)
 	}
 }
+
+// IsRetryable reports whether a client error is transient: the service was
+// unreachable, unavailable or rate limiting. Use it as a retry.Policy's
+// Retryable predicate. Only retry requests that are safe to repeat.
+func IsRetryable(err error) bool {
+	switch errors.GetErrorCode(err) {
+	case errors.ErrCodeUnavailable, errors.ErrCodeTimeout, errors.ErrCodeRateLimit:
+		return true
+	default:
+		return false
+	}
+}
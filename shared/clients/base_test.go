@@ -7,6 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 // writeJSON is a helper for tests to write JSON responses.
@@ -133,6 +137,58 @@ func TestBaseClient_Get(t *testing.T) {
 			t.Errorf("expected status 400, got %d", err.HTTPStatusCode())
 		}
 	})
+
+	t.Run("GET with conflict response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			resp := map[string]any{
+				"success": false,
+				"error": map[string]string{
+					"code":    "CONFLICT",
+					"message": "already settled",
+				},
+			}
+			writeJSON(w, resp)
+		}))
+		defer server.Close()
+
+		client := NewBaseClient(server.URL, DefaultTimeout)
+		var result any
+
+		err := client.Get(context.Background(), "/api/conflict", &result)
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if err.HTTPStatusCode() != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", err.HTTPStatusCode())
+		}
+	})
+}
+
+func TestBaseClient_PreservesCatalogCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		writeJSON(w, map[string]any{
+			"success": false,
+			"error": map[string]string{
+				"code":    "WALLET_INSUFFICIENT_FUNDS",
+				"message": "insufficient balance",
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := NewBaseClient(server.URL, DefaultTimeout)
+	err := client.Post(context.Background(), "/api/transfer", nil, nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if err.Code != errors.ErrCodeWalletInsufficientFunds {
+		t.Errorf("expected code WALLET_INSUFFICIENT_FUNDS, got %s", err.Code)
+	}
+	if err.Message != "insufficient balance" {
+		t.Errorf("expected downstream message, got %q", err.Message)
+	}
 }
 
 func TestBaseClient_Post(t *testing.T) {
@@ -180,6 +236,18 @@ func TestBaseClient_Post(t *testing.T) {
 		}
 	})
 
+	t.Run("successful POST with 204", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		client := NewBaseClient(server.URL, DefaultTimeout)
+		if err := client.Post(context.Background(), "/api/check", map[string]string{"name": "test"}, nil); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
 	t.Run("POST with nil body", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -518,3 +586,90 @@ func TestBaseClient_Headers(t *testing.T) {
 		}
 	})
 }
+
+func TestBaseClient_ServiceToken(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(serviceauth.Header)
+		writeJSON(w, map[string]any{"success": true, "data": nil})
+	}))
+	defer server.Close()
+
+	client := NewBaseClient(server.URL, DefaultTimeout)
+	client.SetServiceSigner(serviceauth.NewSigner("wallet", "test-secret", 0))
+
+	if err := client.Get(context.Background(), "/internal/v1/test", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	caller, err := serviceauth.NewVerifier("test-secret").Verify(got)
+	if err != nil {
+		t.Fatalf("expected a valid service token, got %q: %v", got, err)
+	}
+	if caller != "wallet" {
+		t.Errorf("expected caller wallet, got %s", caller)
+	}
+}
+
+func TestBaseClient_PropagatesRequestID(t *testing.T) {
+	t.Run("forwards request ID from context", func(t *testing.T) {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(requestid.Header)
+			writeJSON(w, map[string]any{"success": true})
+		}))
+		defer server.Close()
+
+		client := NewBaseClient(server.URL, 0)
+		ctx := requestid.WithContext(context.Background(), "req-789")
+		if err := client.Get(ctx, "/api/test", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got != "req-789" {
+			t.Errorf("expected X-Request-ID req-789, got %q", got)
+		}
+	})
+
+	t.Run("explicit header takes precedence", func(t *testing.T) {
+		var got string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(requestid.Header)
+			writeJSON(w, map[string]any{"success": true})
+		}))
+		defer server.Close()
+
+		client := NewBaseClient(server.URL, 0)
+		ctx := requestid.WithContext(context.Background(), "from-ctx")
+		headers := map[string]string{requestid.Header: "explicit"}
+		if err := client.GetWithHeaders(ctx, "/api/test", nil, headers); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got != "explicit" {
+			t.Errorf("expected X-Request-ID explicit, got %q", got)
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		if !IsRetryable(errorForStatusCode(status, "transient")) {
+			t.Errorf("expected status %d to be retryable", status)
+		}
+	}
+	for _, status := range []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError} {
+		if IsRetryable(errorForStatusCode(status, "failed")) {
+			t.Errorf("expected status %d not to be retryable", status)
+		}
+	}
+
+	// An unreachable service is retryable too
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	err := NewBaseClient(server.URL, ShortTimeout).Get(context.Background(), "/api/test", nil)
+	if err == nil || !IsRetryable(err) {
+		t.Errorf("expected retryable error for unreachable service, got %v", err)
+	}
+}
@@ -12,10 +12,11 @@ import (
 type NotificationChannel string
 
 const (
-	NotificationChannelSMS   NotificationChannel = "sms"
-	NotificationChannelEmail NotificationChannel = "email"
-	NotificationChannelPush  NotificationChannel = "push"
-	NotificationChannelInApp NotificationChannel = "in_app"
+	NotificationChannelSMS     NotificationChannel = "sms"
+	NotificationChannelEmail   NotificationChannel = "email"
+	NotificationChannelPush    NotificationChannel = "push"
+	NotificationChannelInApp   NotificationChannel = "in_app"
+	NotificationChannelWebhook NotificationChannel = "webhook"
 )
 
 // NotificationType represents the category of notification.
@@ -50,18 +51,23 @@ type SendNotificationRequest struct {
 	Channel       NotificationChannel  `json:"channel"`
 	Type          NotificationType     `json:"type"`
 	Priority      NotificationPriority `json:"priority"`
-	TemplateID    string               `json:"template_id"`
+	TemplateID    string               `json:"template_id,omitempty"`
+	Subject       string               `json:"subject,omitempty"` // Used when TemplateID is empty
+	Body          string               `json:"body,omitempty"`    // Used when TemplateID is empty
 	Variables     map[string]any       `json:"variables,omitempty"`
 	CorrelationID *string              `json:"correlation_id,omitempty"`
 	SourceService string               `json:"source_service"`
 	Metadata      map[string]any       `json:"metadata,omitempty"`
+	SendAt        string               `json:"send_at,omitempty"`  // Schedule delivery: RFC 3339, or local time in Timezone
+	Timezone      string               `json:"timezone,omitempty"` // IANA timezone for a local SendAt (default UTC)
 }
 
 // SendNotificationResponse represents the response from sending a notification.
 type SendNotificationResponse struct {
-	NotificationID string    `json:"notification_id"`
-	Status         string    `json:"status"`
-	QueuedAt       time.Time `json:"queued_at"`
+	NotificationID string     `json:"notification_id"`
+	Status         string     `json:"status"`
+	QueuedAt       time.Time  `json:"queued_at"`
+	ScheduledFor   *time.Time `json:"scheduled_for,omitempty"`
 }
 
 // NotificationClient handles communication with the notification service.
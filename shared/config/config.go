@@ -2,6 +2,8 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -30,6 +32,14 @@ type Config struct {
 	DatabaseName     string
 	DatabaseSSLMode  string
 
+	// Database connection pool
+	DatabaseMaxOpenConns       int
+	DatabaseMaxIdleConns       int
+	DatabaseConnMaxLifetime    time.Duration
+	DatabaseConnMaxIdleTime    time.Duration
+	DatabaseSlowQueryThreshold time.Duration // 0 disables slow query logging
+	DatabaseStatsInterval      time.Duration // How often pool stats are exported to metrics, 0 disables
+
 	// Redis
 	RedisURL      string
 	RedisHost     string
@@ -54,10 +64,23 @@ type Config struct {
 	// Observability
 	PrometheusPort  int
 	EnableProfiling bool
+
+	// Secrets
+	Secrets                *SecretStore  // Backend JWT_SECRET, DATABASE_PASSWORD, DATABASE_URL, REDIS_PASSWORD and REDIS_URL are read from
+	SecretsRefreshInterval time.Duration // How often secrets are re-read to detect rotation, 0 disables
 }
 
-// Load loads configuration from environment variables with defaults.
+// Load loads configuration from environment variables with defaults. Secrets
+// are read through the backend selected by SECRETS_BACKEND, which also
+// becomes the default store for Secret.
 func Load() (*Config, error) {
+	provider, err := NewSecretProviderFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+	secrets := NewSecretStore(provider, getEnvAsDuration("SECRETS_CACHE_TTL", 5*time.Minute))
+	secretLoader := &secretLoader{store: secrets}
+
 	cfg := &Config{
 		// Application defaults
 		Environment: getEnv("ENVIRONMENT", "development"),
@@ -74,14 +97,22 @@ func Load() (*Config, error) {
 		DatabaseHost:     getEnv("DATABASE_HOST", "localhost"),
 		DatabasePort:     getEnvAsInt("DATABASE_PORT", 5432),
 		DatabaseUser:     getEnv("DATABASE_USER", "nivo"),
-		DatabasePassword: requireEnv("DATABASE_PASSWORD"), // Required - no default for security
+		DatabasePassword: secretLoader.get("DATABASE_PASSWORD"), // Required - no default for security
 		DatabaseName:     getEnv("DATABASE_NAME", "nivo"),
 		DatabaseSSLMode:  getEnv("DATABASE_SSL_MODE", "disable"),
 
+		// Database connection pool defaults
+		DatabaseMaxOpenConns:       getEnvAsInt("DATABASE_MAX_OPEN_CONNS", 25),
+		DatabaseMaxIdleConns:       getEnvAsInt("DATABASE_MAX_IDLE_CONNS", 5),
+		DatabaseConnMaxLifetime:    getEnvAsDuration("DATABASE_CONN_MAX_LIFETIME", 5*time.Minute),
+		DatabaseConnMaxIdleTime:    getEnvAsDuration("DATABASE_CONN_MAX_IDLE_TIME", 1*time.Minute),
+		DatabaseSlowQueryThreshold: getEnvAsDuration("DATABASE_SLOW_QUERY_THRESHOLD", 500*time.Millisecond),
+		DatabaseStatsInterval:      getEnvAsDuration("DATABASE_STATS_INTERVAL", 15*time.Second),
+
 		// Redis defaults (optional - Redis is not critical for demo)
 		RedisHost:     getEnv("REDIS_HOST", "localhost"),
 		RedisPort:     getEnvAsInt("REDIS_PORT", 6379),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""), // Empty if not set - use env var in production
+		RedisPassword: secretLoader.get("REDIS_PASSWORD"), // Empty if not set - use the secrets backend in production
 		RedisDB:       getEnvAsInt("REDIS_DB", 0),
 
 		// NSQ defaults
@@ -89,7 +120,7 @@ func Load() (*Config, error) {
 		NSQDAddr:       getEnv("NSQD_ADDR", "localhost:4150"),
 
 		// JWT configuration
-		JWTSecret:     requireEnv("JWT_SECRET"), // Required - no default for security
+		JWTSecret:     secretLoader.get("JWT_SECRET"), // Required - no default for security
 		JWTExpiry:     getEnvAsDuration("JWT_EXPIRY", 24*time.Hour),
 		JWTRefreshExp: getEnvAsDuration("JWT_REFRESH_EXPIRY", 7*24*time.Hour),
 
@@ -101,10 +132,14 @@ func Load() (*Config, error) {
 		// Observability defaults
 		PrometheusPort:  getEnvAsInt("PROMETHEUS_PORT", 9090),
 		EnableProfiling: getEnvAsBool("ENABLE_PROFILING", false),
+
+		// Secrets
+		Secrets:                secrets,
+		SecretsRefreshInterval: getEnvAsDuration("SECRETS_REFRESH_INTERVAL", refreshDefault(provider)),
 	}
 
 	// Construct composite URLs
-	cfg.DatabaseURL = getEnv("DATABASE_URL",
+	cfg.DatabaseURL = secretLoader.getOr("DATABASE_URL",
 		fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 			cfg.DatabaseUser,
 			cfg.DatabasePassword,
@@ -114,7 +149,7 @@ func Load() (*Config, error) {
 			cfg.DatabaseSSLMode,
 		))
 
-	cfg.RedisURL = getEnv("REDIS_URL",
+	cfg.RedisURL = secretLoader.getOr("REDIS_URL",
 		fmt.Sprintf("redis://:%s@%s:%d/%d",
 			cfg.RedisPassword,
 			cfg.RedisHost,
@@ -122,23 +157,60 @@ func Load() (*Config, error) {
 			cfg.RedisDB,
 		))
 
+	if secretLoader.err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", secretLoader.err)
+	}
+
 	// Validate required fields (always validate - required secrets have no defaults)
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
+	SetDefaultSecrets(secrets)
 	return cfg, nil
 }
 
+// secretLoader reads secrets during Load and keeps the first backend error,
+// so a missing secret fails validation but an unreachable backend is
+// reported as such.
+type secretLoader struct {
+	store *SecretStore
+	err   error
+}
+
+func (l *secretLoader) get(key string) string {
+	return l.getOr(key, "")
+}
+
+func (l *secretLoader) getOr(key, defaultValue string) string {
+	value, err := l.store.Get(context.Background(), key)
+	if err != nil {
+		if !errors.Is(err, ErrSecretNotFound) && l.err == nil {
+			l.err = fmt.Errorf("failed to read %s from %s secrets: %w", key, l.store.Provider().Name(), err)
+		}
+		return defaultValue
+	}
+	return value
+}
+
+// refreshDefault re-reads remote backends every minute and never re-reads
+// the environment, which cannot change while the process runs.
+func refreshDefault(provider SecretProvider) time.Duration {
+	if provider.Name() == SecretsBackendEnv {
+		return 0
+	}
+	return time.Minute
+}
+
 // Validate ensures critical configuration values are set properly.
 func (c *Config) Validate() error {
 	// Required secrets - these must always be set (no defaults)
 	if c.JWTSecret == "" {
-		return fmt.Errorf("JWT_SECRET environment variable is required")
+		return fmt.Errorf("JWT_SECRET environment variable is required (or set it in the secrets backend)")
 	}
 
 	if c.DatabasePassword == "" {
-		return fmt.Errorf("DATABASE_PASSWORD environment variable is required")
+		return fmt.Errorf("DATABASE_PASSWORD environment variable is required (or set it in the secrets backend)")
 	}
 
 	// Port validation
@@ -150,6 +222,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("DATABASE_PORT must be between 1 and 65535")
 	}
 
+	// Pool validation
+	if c.DatabaseMaxOpenConns < 0 || c.DatabaseMaxIdleConns < 0 {
+		return fmt.Errorf("DATABASE_MAX_OPEN_CONNS and DATABASE_MAX_IDLE_CONNS must not be negative")
+	}
+
+	if c.DatabaseMaxOpenConns > 0 && c.DatabaseMaxIdleConns > c.DatabaseMaxOpenConns {
+		return fmt.Errorf("DATABASE_MAX_IDLE_CONNS must not exceed DATABASE_MAX_OPEN_CONNS")
+	}
+
 	return nil
 }
 
@@ -43,7 +43,12 @@ func TestLoad(t *testing.T) {
 					c.DatabaseHost == "localhost" &&
 					c.DatabasePort == 5432 &&
 					c.DatabasePassword == "dev-password" &&
-					c.JWTSecret == "dev-secret"
+					c.JWTSecret == "dev-secret" &&
+					c.DatabaseMaxOpenConns == 25 &&
+					c.DatabaseMaxIdleConns == 5 &&
+					c.DatabaseConnMaxLifetime == 5*time.Minute &&
+					c.DatabaseSlowQueryThreshold == 500*time.Millisecond &&
+					c.DatabaseStatsInterval == 15*time.Second
 			},
 			wantErr: false,
 		},
@@ -56,12 +61,21 @@ func TestLoad(t *testing.T) {
 				"LOG_LEVEL":         "debug",
 				"DATABASE_PASSWORD": "custom-password",
 				"JWT_SECRET":        "custom-secret",
+
+				"DATABASE_MAX_OPEN_CONNS":       "50",
+				"DATABASE_MAX_IDLE_CONNS":       "10",
+				"DATABASE_CONN_MAX_IDLE_TIME":   "30s",
+				"DATABASE_SLOW_QUERY_THRESHOLD": "0",
 			},
 			want: func(c *Config) bool {
 				return c.ServicePort == 9000 &&
 					c.DatabaseHost == "db.example.com" &&
 					c.DatabasePort == 5433 &&
-					c.LogLevel == "debug"
+					c.LogLevel == "debug" &&
+					c.DatabaseMaxOpenConns == 50 &&
+					c.DatabaseMaxIdleConns == 10 &&
+					c.DatabaseConnMaxIdleTime == 30*time.Second &&
+					c.DatabaseSlowQueryThreshold == 0
 			},
 			wantErr: false,
 		},
@@ -141,6 +155,19 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid config - more idle than open connections",
+			config: &Config{
+				Environment:          "development",
+				ServicePort:          8080,
+				DatabasePort:         5432,
+				JWTSecret:            "secret",
+				DatabasePassword:     "password",
+				DatabaseMaxOpenConns: 5,
+				DatabaseMaxIdleConns: 10,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid service port",
 			config: &Config{
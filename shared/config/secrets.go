@@ -0,0 +1,304 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSecretNotFound is returned by secret providers that do not hold a key.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Secret backends selectable with SECRETS_BACKEND.
+const (
+	SecretsBackendEnv   = "env"
+	SecretsBackendFile  = "file"
+	SecretsBackendVault = "vault"
+	SecretsBackendAWS   = "aws"
+)
+
+// secretFetchTimeout bounds one lookup against a remote backend.
+const secretFetchTimeout = 5 * time.Second
+
+// SecretProvider reads secrets from a backend. Keys are the environment
+// variable names the secrets replace, such as JWT_SECRET.
+type SecretProvider interface {
+	// Name identifies the backend in logs and errors.
+	Name() string
+
+	// GetSecret returns the value of key, or ErrSecretNotFound.
+	GetSecret(ctx context.Context, key string) (string, error)
+}
+
+// EnvSecretProvider reads secrets from environment variables. When KEY is
+// unset it reads the file named by KEY_FILE, so compose files can mount
+// secrets instead of inlining them.
+type EnvSecretProvider struct{}
+
+// Name returns "env".
+func (EnvSecretProvider) Name() string { return SecretsBackendEnv }
+
+// GetSecret returns the environment variable key, or the contents of the
+// file named by key_FILE.
+func (EnvSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	if value := os.Getenv(key); value != "" {
+		return value, nil
+	}
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		return readSecretFile(path)
+	}
+	return "", ErrSecretNotFound
+}
+
+// FileSecretProvider reads each secret from a file in a directory, such as
+// the /run/secrets directory Docker and Kubernetes mount secrets into.
+type FileSecretProvider struct {
+	dir string
+}
+
+// NewFileSecretProvider creates a provider reading secrets from dir.
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{dir: dir}
+}
+
+// Name returns "file".
+func (p *FileSecretProvider) Name() string { return SecretsBackendFile }
+
+// GetSecret returns the contents of the file named key, or of its lowercase
+// name (jwt_secret for JWT_SECRET).
+func (p *FileSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	for _, name := range []string{key, strings.ToLower(key)} {
+		value, err := readSecretFile(filepath.Join(p.dir, name))
+		if !errors.Is(err, ErrSecretNotFound) {
+			return value, err
+		}
+	}
+	return "", ErrSecretNotFound
+}
+
+// readSecretFile reads a secret file without its trailing newline.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("failed to read secret file %s: %w", path, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// fallbackSecretProvider reads from a primary backend and falls back to the
+// environment for keys it does not hold, so secrets can move one at a time.
+type fallbackSecretProvider struct {
+	primary SecretProvider
+}
+
+func (p *fallbackSecretProvider) Name() string { return p.primary.Name() }
+
+func (p *fallbackSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	value, err := p.primary.GetSecret(ctx, key)
+	if errors.Is(err, ErrSecretNotFound) {
+		return EnvSecretProvider{}.GetSecret(ctx, key)
+	}
+	return value, err
+}
+
+// NewSecretProviderFromEnv creates the provider selected by SECRETS_BACKEND
+// (env, file, vault or aws; default env). Every backend but env falls back to
+// the environment for keys it does not hold.
+func NewSecretProviderFromEnv() (SecretProvider, error) {
+	backend := strings.ToLower(getEnv("SECRETS_BACKEND", SecretsBackendEnv))
+	httpClient := &http.Client{Timeout: secretFetchTimeout}
+
+	var provider SecretProvider
+	switch backend {
+	case SecretsBackendEnv:
+		return EnvSecretProvider{}, nil
+	case SecretsBackendFile:
+		provider = NewFileSecretProvider(getEnv("SECRETS_DIR", "/run/secrets"))
+	case SecretsBackendVault:
+		addr := getEnv("VAULT_ADDR", "")
+		path := getEnv("VAULT_SECRET_PATH", "")
+		if addr == "" || path == "" {
+			return nil, fmt.Errorf("SECRETS_BACKEND=vault requires VAULT_ADDR and VAULT_SECRET_PATH")
+		}
+		token, err := EnvSecretProvider{}.GetSecret(context.Background(), "VAULT_TOKEN")
+		if err != nil {
+			return nil, fmt.Errorf("SECRETS_BACKEND=vault requires VAULT_TOKEN or VAULT_TOKEN_FILE: %w", err)
+		}
+		provider = NewVaultSecretProvider(VaultConfig{
+			Address:   addr,
+			Token:     token,
+			Mount:     getEnv("VAULT_MOUNT", "secret"),
+			Path:      path,
+			Namespace: getEnv("VAULT_NAMESPACE", ""),
+		}, httpClient)
+	case SecretsBackendAWS:
+		secretID := getEnv("AWS_SECRET_ID", "")
+		region := getEnv("AWS_REGION", getEnv("AWS_DEFAULT_REGION", ""))
+		if secretID == "" || region == "" {
+			return nil, fmt.Errorf("SECRETS_BACKEND=aws requires AWS_SECRET_ID and AWS_REGION")
+		}
+		creds := AWSCredentials{
+			AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			SessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+		}
+		if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+			return nil, fmt.Errorf("SECRETS_BACKEND=aws requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		provider = NewAWSSecretProvider(AWSSecretsConfig{
+			Region:      region,
+			SecretID:    secretID,
+			Endpoint:    getEnv("AWS_SECRETS_ENDPOINT", ""),
+			Credentials: creds,
+		}, httpClient)
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_BACKEND %q: use env, file, vault or aws", backend)
+	}
+
+	return &fallbackSecretProvider{primary: provider}, nil
+}
+
+// cachedSecret is a secret value and when it was read.
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// SecretStore caches secrets read from a provider for a TTL and notifies
+// callbacks when Refresh finds a value has rotated.
+type SecretStore struct {
+	provider SecretProvider
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	cache     map[string]cachedSecret
+	callbacks map[string][]func(value string)
+}
+
+// NewSecretStore creates a store caching secrets from provider for ttl. A
+// zero ttl caches secrets until the next Refresh.
+func NewSecretStore(provider SecretProvider, ttl time.Duration) *SecretStore {
+	return &SecretStore{
+		provider:  provider,
+		ttl:       ttl,
+		cache:     make(map[string]cachedSecret),
+		callbacks: make(map[string][]func(value string)),
+	}
+}
+
+// Provider returns the store's backend.
+func (s *SecretStore) Provider() SecretProvider {
+	return s.provider
+}
+
+// Get returns the secret key, from the cache while it is fresh. If the
+// backend fails after the TTL, the last value read is returned rather than
+// failing callers on a transient outage.
+func (s *SecretStore) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && (s.ttl <= 0 || time.Since(cached.fetchedAt) < s.ttl) {
+		return cached.value, nil
+	}
+
+	value, err := s.fetch(ctx, key)
+	if err != nil {
+		if ok && !errors.Is(err, ErrSecretNotFound) {
+			return cached.value, nil
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// OnRotate registers fn to run with the new value whenever Refresh finds
+// that key changed. Callbacks run outside the store's lock.
+func (s *SecretStore) OnRotate(key string, fn func(value string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks[key] = append(s.callbacks[key], fn)
+}
+
+// Refresh re-reads every cached secret and runs the rotation callbacks of
+// those whose value changed. It returns the first error, after trying every
+// key.
+func (s *SecretStore) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.cache))
+	for key := range s.cache {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, key := range keys {
+		if _, err := s.fetch(ctx, key); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to refresh secret %s from %s: %w", key, s.provider.Name(), err)
+		}
+	}
+	return firstErr
+}
+
+// fetch reads key from the provider, caches it and runs the rotation
+// callbacks if it replaced a different cached value.
+func (s *SecretStore) fetch(ctx context.Context, key string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, secretFetchTimeout)
+	defer cancel()
+
+	value, err := s.provider.GetSecret(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	previous, had := s.cache[key]
+	s.cache[key] = cachedSecret{value: value, fetchedAt: time.Now()}
+	var callbacks []func(value string)
+	if had && previous.value != value {
+		callbacks = append(callbacks, s.callbacks[key]...)
+	}
+	s.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(value)
+	}
+	return value, nil
+}
+
+var defaultSecrets atomic.Pointer[SecretStore]
+
+// SetDefaultSecrets sets the store Secret reads from. Load sets it.
+func SetDefaultSecrets(s *SecretStore) {
+	defaultSecrets.Store(s)
+}
+
+// DefaultSecrets returns the store set by SetDefaultSecrets, or nil.
+func DefaultSecrets() *SecretStore {
+	return defaultSecrets.Load()
+}
+
+// Secret returns the secret key from the default store, or from the
+// environment before Load has run. It returns "" if the secret is not set
+// or cannot be read.
+func Secret(key string) string {
+	store := DefaultSecrets()
+	if store == nil {
+		store = NewSecretStore(EnvSecretProvider{}, 0)
+	}
+	value, err := store.Get(context.Background(), key)
+	if err != nil {
+		return ""
+	}
+	return value
+}
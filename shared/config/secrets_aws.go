@@ -0,0 +1,184 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are the static credentials AWS requests are signed with.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Set for temporary credentials
+}
+
+// AWSSecretsConfig locates the AWS Secrets Manager secret holding a
+// service's secrets as a JSON object, one field per key.
+type AWSSecretsConfig struct {
+	Region      string
+	SecretID    string // Secret name or ARN
+	Endpoint    string // Overrides https://secretsmanager.<region>.amazonaws.com, e.g. for VPC endpoints
+	Credentials AWSCredentials
+}
+
+// AWSSecretProvider reads secrets from an AWS Secrets Manager secret.
+type AWSSecretProvider struct {
+	cfg    AWSSecretsConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewAWSSecretProvider creates a provider reading the secret cfg points at.
+func NewAWSSecretProvider(cfg AWSSecretsConfig, client *http.Client) *AWSSecretProvider {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", cfg.Region)
+	}
+	return &AWSSecretProvider{cfg: cfg, client: client, now: time.Now}
+}
+
+// Name returns "aws".
+func (p *AWSSecretProvider) Name() string { return SecretsBackendAWS }
+
+// GetSecret returns the field key of the current version of the secret.
+func (p *AWSSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.cfg.SecretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode secrets manager request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(p.cfg.Endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to create secrets manager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseBodySize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read secrets manager response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type string `json:"__type"`
+		}
+		_ = json.Unmarshal(body, &awsErr)
+		if strings.HasSuffix(awsErr.Type, "ResourceNotFoundException") {
+			return "", ErrSecretNotFound
+		}
+		return "", fmt.Errorf("secrets manager returned status %d: %s", resp.StatusCode, awsErr.Type)
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode secrets manager response: %w", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(result.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", p.cfg.SecretID, err)
+	}
+	return secretField(fields, key)
+}
+
+// sign adds AWS Signature Version 4 headers to a secrets manager request.
+func (p *AWSSecretProvider) sign(req *http.Request, payload []byte) {
+	const service = "secretsmanager"
+
+	now := p.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.cfg.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.cfg.Credentials.SessionToken)
+	}
+
+	headers := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         req.URL.Host,
+		"x-amz-date":   amzDate,
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		headers["x-amz-security-token"] = token
+	}
+	names := []string{"content-type", "host", "x-amz-date", "x-amz-security-token", "x-amz-target"}
+
+	var canonicalHeaders strings.Builder
+	signed := make([]string, 0, len(names))
+	for _, name := range names {
+		value, ok := headers[name]
+		if !ok {
+			continue
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+		signed = append(signed, name)
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	scope := strings.Join([]string{date, p.cfg.Region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+p.cfg.Credentials.SecretAccessKey), date)
+	key = hmacSHA256(key, p.cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.cfg.Credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns query parameters sorted and escaped as SigV4
+// requires.
+func canonicalQuery(values url.Values) string {
+	return strings.ReplaceAll(values.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type stubSecretProvider struct {
+	mu     sync.Mutex
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (p *stubSecretProvider) Name() string { return "stub" }
+
+func (p *stubSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.err != nil {
+		return "", p.err
+	}
+	value, ok := p.values[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (p *stubSecretProvider) set(key, value string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[key] = value
+}
+
+func TestEnvSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "jwt_secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_INLINE_SECRET", "inline")
+	t.Setenv("TEST_MOUNTED_SECRET_FILE", path)
+
+	provider := EnvSecretProvider{}
+	ctx := context.Background()
+
+	if got, err := provider.GetSecret(ctx, "TEST_INLINE_SECRET"); err != nil || got != "inline" {
+		t.Errorf("GetSecret(inline) = %q, %v", got, err)
+	}
+	if got, err := provider.GetSecret(ctx, "TEST_MOUNTED_SECRET"); err != nil || got != "from-file" {
+		t.Errorf("GetSecret(_FILE) = %q, %v", got, err)
+	}
+	if _, err := provider.GetSecret(ctx, "TEST_MISSING_SECRET"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret(missing) error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "database_url"), []byte("postgres://db\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewFileSecretProvider(dir)
+	got, err := provider.GetSecret(context.Background(), "DATABASE_URL")
+	if err != nil || got != "postgres://db" {
+		t.Errorf("GetSecret() = %q, %v, want postgres://db", got, err)
+	}
+	if _, err := provider.GetSecret(context.Background(), "JWT_SECRET"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret(missing) error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/nivo/transaction" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{
+				"data": map[string]any{"JWT_SECRET": "vault-jwt", "DATABASE_PORT": 5432},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	provider := NewVaultSecretProvider(VaultConfig{
+		Address: srv.URL,
+		Token:   "vault-token",
+		Mount:   "secret",
+		Path:    "nivo/transaction",
+	}, srv.Client())
+	ctx := context.Background()
+
+	if got, err := provider.GetSecret(ctx, "JWT_SECRET"); err != nil || got != "vault-jwt" {
+		t.Errorf("GetSecret() = %q, %v, want vault-jwt", got, err)
+	}
+	if _, err := provider.GetSecret(ctx, "DATABASE_URL"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret(missing field) error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := provider.GetSecret(ctx, "DATABASE_PORT"); err == nil {
+		t.Error("expected error for non-string field")
+	}
+
+	forbidden := NewVaultSecretProvider(VaultConfig{Address: srv.URL, Token: "wrong", Mount: "secret", Path: "nivo/transaction"}, srv.Client())
+	if _, err := forbidden.GetSecret(ctx, "JWT_SECRET"); err == nil || errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected access error, got %v", err)
+	}
+}
+
+func TestAWSSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260101/ap-south-1/secretsmanager/aws4_request") ||
+			!strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-target") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var req struct{ SecretId string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.SecretId != "nivo/prod" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"SecretString": `{"JWT_SECRET":"aws-jwt"}`,
+		})
+	}))
+	defer srv.Close()
+
+	newProvider := func(secretID string) *AWSSecretProvider {
+		provider := NewAWSSecretProvider(AWSSecretsConfig{
+			Region:      "ap-south-1",
+			SecretID:    secretID,
+			Endpoint:    srv.URL,
+			Credentials: AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"},
+		}, srv.Client())
+		provider.now = func() time.Time { return time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) }
+		return provider
+	}
+	ctx := context.Background()
+
+	if got, err := newProvider("nivo/prod").GetSecret(ctx, "JWT_SECRET"); err != nil || got != "aws-jwt" {
+		t.Errorf("GetSecret() = %q, %v, want aws-jwt", got, err)
+	}
+	if _, err := newProvider("nivo/prod").GetSecret(ctx, "DATABASE_URL"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret(missing field) error = %v, want ErrSecretNotFound", err)
+	}
+	if _, err := newProvider("nivo/missing").GetSecret(ctx, "JWT_SECRET"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("GetSecret(missing secret) error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSecretStore_CachesAndRotates(t *testing.T) {
+	provider := &stubSecretProvider{values: map[string]string{"JWT_SECRET": "v1"}}
+	store := NewSecretStore(provider, time.Hour)
+	ctx := context.Background()
+
+	var rotated []string
+	store.OnRotate("JWT_SECRET", func(value string) { rotated = append(rotated, value) })
+
+	for i := 0; i < 3; i++ {
+		if got, err := store.Get(ctx, "JWT_SECRET"); err != nil || got != "v1" {
+			t.Fatalf("Get() = %q, %v, want v1", got, err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected 1 backend read while cached, got %d", provider.calls)
+	}
+
+	if err := store.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(rotated) != 0 {
+		t.Errorf("expected no rotation callback for an unchanged value, got %v", rotated)
+	}
+
+	provider.set("JWT_SECRET", "v2")
+	if err := store.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if len(rotated) != 1 || rotated[0] != "v2" {
+		t.Errorf("expected rotation to v2, got %v", rotated)
+	}
+	if got, _ := store.Get(ctx, "JWT_SECRET"); got != "v2" {
+		t.Errorf("Get() after rotation = %q, want v2", got)
+	}
+}
+
+func TestSecretStore_ServesStaleValueWhenBackendFails(t *testing.T) {
+	provider := &stubSecretProvider{values: map[string]string{"JWT_SECRET": "v1"}}
+	store := NewSecretStore(provider, time.Nanosecond)
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "JWT_SECRET"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	provider.err = errors.New("backend down")
+	time.Sleep(time.Millisecond)
+
+	if got, err := store.Get(ctx, "JWT_SECRET"); err != nil || got != "v1" {
+		t.Errorf("Get() during outage = %q, %v, want stale v1", got, err)
+	}
+	if _, err := store.Get(ctx, "DATABASE_URL"); err == nil {
+		t.Error("expected error for a secret never read")
+	}
+	if err := store.Refresh(ctx); err == nil {
+		t.Error("expected Refresh() to report the backend error")
+	}
+}
+
+func TestNewSecretProviderFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jwt_secret"), []byte("file-jwt"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("SECRETS_BACKEND", "file")
+	t.Setenv("SECRETS_DIR", dir)
+	t.Setenv("DATABASE_PASSWORD", "env-password")
+
+	provider, err := NewSecretProviderFromEnv()
+	if err != nil {
+		t.Fatalf("NewSecretProviderFromEnv() error = %v", err)
+	}
+	ctx := context.Background()
+	if got, _ := provider.GetSecret(ctx, "JWT_SECRET"); got != "file-jwt" {
+		t.Errorf("GetSecret(JWT_SECRET) = %q, want file-jwt", got)
+	}
+	if got, _ := provider.GetSecret(ctx, "DATABASE_PASSWORD"); got != "env-password" {
+		t.Errorf("GetSecret(DATABASE_PASSWORD) = %q, want env fallback", got)
+	}
+
+	for _, backend := range []string{"vault", "aws", "keychain"} {
+		t.Setenv("SECRETS_BACKEND", backend)
+		if _, err := NewSecretProviderFromEnv(); err == nil {
+			t.Errorf("expected error for unconfigured %s backend", backend)
+		}
+	}
+}
+
+func TestLoad_ReadsSecretsBackend(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"jwt_secret":        "file-jwt",
+		"database_password": "file-password",
+		"database_url":      "postgres://from-file",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("SECRETS_BACKEND", "file")
+	t.Setenv("SECRETS_DIR", dir)
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("DATABASE_PASSWORD", "")
+	t.Setenv("DATABASE_URL", "")
+	defer SetDefaultSecrets(nil)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.JWTSecret != "file-jwt" || cfg.DatabasePassword != "file-password" || cfg.DatabaseURL != "postgres://from-file" {
+		t.Errorf("Load() secrets = %q, %q, %q", cfg.JWTSecret, cfg.DatabasePassword, cfg.DatabaseURL)
+	}
+	if cfg.SecretsRefreshInterval != time.Minute {
+		t.Errorf("SecretsRefreshInterval = %v, want 1m for the file backend", cfg.SecretsRefreshInterval)
+	}
+	if got := Secret("JWT_SECRET"); got != "file-jwt" {
+		t.Errorf("Secret() = %q, want file-jwt from the default store", got)
+	}
+}
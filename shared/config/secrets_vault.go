@@ -0,0 +1,87 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// VaultConfig locates the HashiCorp Vault KV version 2 secret holding a
+// service's secrets, one field per key.
+type VaultConfig struct {
+	Address   string // e.g. https://vault.internal:8200
+	Token     string
+	Mount     string // KV v2 mount, e.g. secret
+	Path      string // Secret path under the mount, e.g. nivo/transaction
+	Namespace string // Vault Enterprise namespace, optional
+}
+
+// VaultSecretProvider reads secrets from a Vault KV version 2 secret.
+type VaultSecretProvider struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultSecretProvider creates a provider reading the KV v2 secret cfg
+// points at.
+func NewVaultSecretProvider(cfg VaultConfig, client *http.Client) *VaultSecretProvider {
+	return &VaultSecretProvider{cfg: cfg, client: client}
+}
+
+// Name returns "vault".
+func (p *VaultSecretProvider) Name() string { return SecretsBackendVault }
+
+// GetSecret returns the field key of the latest version of the secret.
+func (p *VaultSecretProvider) GetSecret(ctx context.Context, key string) (string, error) {
+	url := fmt.Sprintf("%s/v1/%s/data/%s",
+		strings.TrimRight(p.cfg.Address, "/"), strings.Trim(p.cfg.Mount, "/"), strings.Trim(p.cfg.Path, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrSecretNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for %s", resp.StatusCode, p.cfg.Path)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxResponseBodySize)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	return secretField(body.Data.Data, key)
+}
+
+// secretField returns key from a secret document of string fields.
+func secretField(fields map[string]any, key string) (string, error) {
+	raw, ok := fields[key]
+	if !ok || raw == nil {
+		return "", ErrSecretNotFound
+	}
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s is not a string", key)
+	}
+	return value, nil
+}
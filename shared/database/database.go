@@ -4,10 +4,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	stderrors "errors"
 	"fmt"
+	"strings"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
 )
 
 // Default query timeout for database operations
@@ -34,8 +36,24 @@ type DB struct {
 	*sql.DB
 	config       Config
 	queryTimeout time.Duration
+
+	// Optional: reports queries slower than slowThreshold
+	slowThreshold time.Duration
+	onSlowQuery   SlowQueryFunc
+}
+
+// PoolConfig sizes the connection pool. Zero values leave the driver defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
 }
 
+// SlowQueryFunc is called after a query that took longer than the slow
+// query threshold. query is the SQL text, without arguments.
+type SlowQueryFunc func(ctx context.Context, query string, elapsed time.Duration)
+
 // Connect establishes a connection to PostgreSQL with the given configuration.
 func Connect(cfg Config) (*DB, error) {
 	// Build connection string
@@ -56,20 +74,6 @@ func Connect(cfg Config) (*DB, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Set connection pool settings
-	if cfg.MaxOpenConns > 0 {
-		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
-	}
-	if cfg.MaxIdleConns > 0 {
-		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
-	}
-	if cfg.ConnMaxLifetime > 0 {
-		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
-	}
-	if cfg.ConnMaxIdleTime > 0 {
-		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
-	}
-
 	// Set query timeout (use default if not specified)
 	queryTimeout := cfg.QueryTimeout
 	if queryTimeout == 0 {
@@ -82,6 +86,14 @@ func Connect(cfg Config) (*DB, error) {
 		queryTimeout: queryTimeout,
 	}
 
+	// Set connection pool settings
+	db.SetPool(PoolConfig{
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.ConnMaxIdleTime,
+	})
+
 	// Verify connection
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
 	defer cancel()
@@ -118,6 +130,56 @@ func NewFromURL(url string) (*DB, error) {
 	return db, nil
 }
 
+// SetPool applies connection pool settings. Zero values are left unchanged.
+func (db *DB) SetPool(p PoolConfig) {
+	if p.MaxOpenConns > 0 {
+		db.DB.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.DB.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.DB.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.DB.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
+
+// SetSlowQueryLog reports queries made through DB's query methods that take
+// longer than threshold to fn. Queries on transactions and on the embedded
+// *sql.DB are not timed. A zero threshold or nil fn disables reporting. Call
+// it before the DB is used concurrently.
+func (db *DB) SetSlowQueryLog(threshold time.Duration, fn SlowQueryFunc) {
+	if threshold <= 0 {
+		fn = nil
+	}
+	db.slowThreshold = threshold
+	db.onSlowQuery = fn
+}
+
+// observe reports a query started at start if it was slow.
+func (db *DB) observe(ctx context.Context, query string, start time.Time) {
+	if db.onSlowQuery == nil {
+		return
+	}
+	if elapsed := time.Since(start); elapsed >= db.slowThreshold {
+		db.onSlowQuery(ctx, query, elapsed)
+	}
+}
+
+// QueryType classifies a query by its leading keyword for metric labels:
+// "select", "insert", "update", "delete" or "other".
+func QueryType(query string) string {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(query), " ")
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	switch keyword {
+	case "select", "insert", "update", "delete":
+		return keyword
+	}
+	return "other"
+}
+
 // HealthCheck performs a health check on the database connection.
 func (db *DB) HealthCheck(ctx context.Context) error {
 	if err := db.PingContext(ctx); err != nil {
@@ -194,16 +256,19 @@ func (db *DB) Close() error {
 
 // QueryRowContext is a convenience wrapper around sql.DB.QueryRowContext.
 func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer db.observe(ctx, query, time.Now())
 	return db.DB.QueryRowContext(ctx, query, args...)
 }
 
 // QueryContext is a convenience wrapper around sql.DB.QueryContext.
 func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer db.observe(ctx, query, time.Now())
 	return db.DB.QueryContext(ctx, query, args...)
 }
 
 // ExecContext is a convenience wrapper around sql.DB.ExecContext.
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer db.observe(ctx, query, time.Now())
 	return db.DB.ExecContext(ctx, query, args...)
 }
 
@@ -217,14 +282,14 @@ func (db *DB) WithTimeout(ctx context.Context) (context.Context, context.CancelF
 // Returns the row and a cancel function that should be deferred.
 func (db *DB) QueryRowWithTimeout(ctx context.Context, query string, args ...interface{}) (*sql.Row, context.CancelFunc) {
 	ctx, cancel := context.WithTimeout(ctx, db.queryTimeout)
-	return db.DB.QueryRowContext(ctx, query, args...), cancel
+	return db.QueryRowContext(ctx, query, args...), cancel
 }
 
 // QueryWithTimeout executes a query with the configured timeout.
 // The caller should defer the cancel function.
 func (db *DB) QueryWithTimeout(ctx context.Context, query string, args ...interface{}) (*sql.Rows, context.CancelFunc, error) {
 	ctx, cancel := context.WithTimeout(ctx, db.queryTimeout)
-	rows, err := db.DB.QueryContext(ctx, query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		cancel()
 		return nil, nil, err
@@ -236,7 +301,7 @@ func (db *DB) QueryWithTimeout(ctx context.Context, query string, args ...interf
 func (db *DB) ExecWithTimeout(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, db.queryTimeout)
 	defer cancel()
-	return db.DB.ExecContext(ctx, query, args...)
+	return db.ExecContext(ctx, query, args...)
 }
 
 // SetQueryTimeout sets the default query timeout.
@@ -323,6 +388,16 @@ func IsCheckViolation(err error) bool {
 	return contains(err.Error(), "check constraint")
 }
 
+// IsRetryableConflict checks if an error is a serialization failure (40001)
+// or deadlock (40P01). The transaction was rolled back and can be retried as a whole.
+func IsRetryableConflict(err error) bool {
+	var pqErr *pq.Error
+	if !stderrors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
 // contains checks if a string contains a substring (case-insensitive helper).
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) &&
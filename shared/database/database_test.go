@@ -3,8 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -111,6 +115,47 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestQueryType(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM users", "select"},
+		{"\n\t  insert INTO users (id) VALUES ($1)", "insert"},
+		{"UPDATE users SET name = $1", "update"},
+		{"DELETE FROM users", "delete"},
+		{"WITH recent AS (SELECT 1) SELECT * FROM recent", "other"},
+		{"", "other"},
+	}
+
+	for _, tt := range tests {
+		if got := QueryType(tt.query); got != tt.want {
+			t.Errorf("QueryType(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	db := &DB{}
+	var reported []string
+	db.SetSlowQueryLog(100*time.Millisecond, func(ctx context.Context, query string, elapsed time.Duration) {
+		reported = append(reported, query)
+	})
+
+	ctx := context.Background()
+	db.observe(ctx, "SELECT fast", time.Now())
+	db.observe(ctx, "SELECT slow", time.Now().Add(-200*time.Millisecond))
+	if len(reported) != 1 || reported[0] != "SELECT slow" {
+		t.Fatalf("expected only the slow query to be reported, got %v", reported)
+	}
+
+	// A zero threshold disables reporting
+	db.SetSlowQueryLog(0, func(ctx context.Context, query string, elapsed time.Duration) {
+		t.Errorf("unexpected report of %q", query)
+	})
+	db.observe(ctx, "SELECT slow", time.Now().Add(-time.Hour))
+}
+
 func TestTransaction(t *testing.T) {
 	db := getTestDB(t)
 	if db == nil {
@@ -318,3 +363,26 @@ func TestMustConnect(t *testing.T) {
 
 	MustConnect(cfg)
 }
+
+func TestIsRetryableConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", &pq.Error{Code: "40001"}, true},
+		{"deadlock", &pq.Error{Code: "40P01"}, true},
+		{"wrapped deadlock", fmt.Errorf("post failed: %w", &pq.Error{Code: "40P01"}), true},
+		{"unique violation", &pq.Error{Code: "23505"}, false},
+		{"plain error", errors.New("deadlock detected"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableConflict(tt.err); got != tt.want {
+				t.Errorf("IsRetryableConflict(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
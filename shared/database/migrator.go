@@ -2,20 +2,50 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 )
 
-// Migrator handles database migrations.
+// ErrDirty is returned when a migration of this directory was interrupted
+// part way. Inspect the schema, then call Force to record the real state.
+var ErrDirty = errors.New("database is dirty: a migration was interrupted, repair it with force")
+
+// Migrator handles database migrations. Services share one database and one
+// migrations table, so a migrator only acts on the files in its own directory.
 type Migrator struct {
 	db              *sql.DB
 	migrationsDir   string
 	migrationsTable string
 }
 
+// MigrationInfo describes one migration file and whether it is applied.
+type MigrationInfo struct {
+	Version   string     `json:"version"`
+	Applied   bool       `json:"applied"`
+	Dirty     bool       `json:"dirty,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	HasDown   bool       `json:"has_down"`
+}
+
+// MigrationStatus is the migration state of one service.
+type MigrationStatus struct {
+	Version    string          `json:"version"` // Latest applied migration, empty if none
+	Dirty      bool            `json:"dirty"`
+	Pending    int             `json:"pending"`
+	Migrations []MigrationInfo `json:"migrations"`
+}
+
+// appliedMigration is a row of the migrations table.
+type appliedMigration struct {
+	appliedAt time.Time
+	dirty     bool
+}
+
 // NewMigrator creates a new migrator.
 func NewMigrator(db *sql.DB, migrationsDir string) *Migrator {
 	return &Migrator{
@@ -27,35 +57,164 @@ func NewMigrator(db *sql.DB, migrationsDir string) *Migrator {
 
 // Up runs all pending migrations.
 func (m *Migrator) Up() error {
+	return m.Steps(0)
+}
+
+// Down rolls back the last applied migration.
+func (m *Migrator) Down() error {
+	return m.Steps(-1)
+}
+
+// Steps applies the next n pending migrations when n is positive and rolls
+// back the last -n applied migrations when n is negative. Zero applies all
+// pending migrations. It refuses to run while the directory is dirty.
+func (m *Migrator) Steps(n int) error {
+	files, applied, err := m.load()
+	if err != nil {
+		return err
+	}
+	if dirty := dirtyVersion(files, applied); dirty != "" {
+		return fmt.Errorf("%w (version %s)", ErrDirty, dirty)
+	}
+
+	if n >= 0 {
+		count := 0
+		for _, file := range files {
+			if _, ok := applied[file]; ok {
+				continue // Already applied
+			}
+			if n > 0 && count == n {
+				break
+			}
+			if err := m.runMigration(file); err != nil {
+				return fmt.Errorf("failed to run migration %s: %w", file, err)
+			}
+			count++
+		}
+		return nil
+	}
+
+	count := 0
+	for i := len(files) - 1; i >= 0 && count < -n; i-- {
+		if _, ok := applied[files[i]]; !ok {
+			continue
+		}
+		if err := m.rollbackMigration(files[i]); err != nil {
+			return fmt.Errorf("failed to roll back migration %s: %w", files[i], err)
+		}
+		count++
+	}
+	if count == 0 {
+		return fmt.Errorf("no applied migrations to roll back")
+	}
+	return nil
+}
+
+// Force records version as the latest applied migration without running any
+// SQL: it and every earlier migration are marked applied and clean, later ones
+// unapplied. An empty version marks every migration unapplied. Use it to repair
+// a dirty database after checking the schema by hand.
+func (m *Migrator) Force(version string) error {
+	files, applied, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	if version != "" {
+		version = upFile(version)
+		found := false
+		for _, file := range files {
+			found = found || file == version
+		}
+		if !found {
+			return fmt.Errorf("unknown migration version %s", version)
+		}
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	//nolint:gosec // G201: table name is from configuration, not user input
+	insert := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES ($1, FALSE) ON CONFLICT (version) DO UPDATE SET dirty = FALSE", m.migrationsTable)
+	//nolint:gosec // G201: table name is from configuration, not user input
+	remove := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.migrationsTable)
+
+	for _, file := range files {
+		_, isApplied := applied[file]
+		switch {
+		case version != "" && file <= version:
+			if _, err := tx.Exec(insert, file); err != nil {
+				return fmt.Errorf("failed to record migration %s: %w", file, err)
+			}
+		case isApplied:
+			if _, err := tx.Exec(remove, file); err != nil {
+				return fmt.Errorf("failed to remove migration %s: %w", file, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Status reports which migrations of this directory are applied.
+func (m *Migrator) Status() (*MigrationStatus, error) {
+	files, applied, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MigrationStatus{Migrations: make([]MigrationInfo, 0, len(files))}
+	for _, file := range files {
+		info := MigrationInfo{Version: file, HasDown: m.hasDown(file)}
+		if record, ok := applied[file]; ok {
+			appliedAt := record.appliedAt
+			info.Applied = true
+			info.AppliedAt = &appliedAt
+			info.Dirty = record.dirty
+			status.Version = file
+			status.Dirty = status.Dirty || record.dirty
+		} else {
+			status.Pending++
+		}
+		status.Migrations = append(status.Migrations, info)
+	}
+	return status, nil
+}
+
+// load prepares the migrations table and returns this directory's files with
+// the applied migrations.
+func (m *Migrator) load() ([]string, map[string]appliedMigration, error) {
 	// Create migrations table if not exists
 	if err := m.createMigrationsTable(); err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
+		return nil, nil, fmt.Errorf("failed to create migrations table: %w", err)
 	}
 
 	// Get list of migration files
 	files, err := m.getMigrationFiles()
 	if err != nil {
-		return fmt.Errorf("failed to get migration files: %w", err)
+		return nil, nil, fmt.Errorf("failed to get migration files: %w", err)
 	}
 
 	// Get applied migrations
 	applied, err := m.getAppliedMigrations()
 	if err != nil {
-		return fmt.Errorf("failed to get applied migrations: %w", err)
+		return nil, nil, fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
-	// Run pending migrations
-	for _, file := range files {
-		if _, ok := applied[file]; ok {
-			continue // Already applied
-		}
+	return files, applied, nil
+}
 
-		if err := m.runMigration(file); err != nil {
-			return fmt.Errorf("failed to run migration %s: %w", file, err)
+// dirtyVersion returns the first of files marked dirty, or "".
+func dirtyVersion(files []string, applied map[string]appliedMigration) string {
+	for _, file := range files {
+		if applied[file].dirty {
+			return file
 		}
 	}
-
-	return nil
+	return ""
 }
 
 // createMigrationsTable creates the schema_migrations table.
@@ -64,8 +223,9 @@ func (m *Migrator) createMigrationsTable() error {
 		CREATE TABLE IF NOT EXISTS %s (
 			version VARCHAR(255) PRIMARY KEY,
 			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
-		)
-	`, m.migrationsTable)
+		);
+		ALTER TABLE %s ADD COLUMN IF NOT EXISTS dirty BOOLEAN NOT NULL DEFAULT FALSE
+	`, m.migrationsTable, m.migrationsTable)
 
 	_, err := m.db.Exec(query)
 	return err
@@ -99,29 +259,53 @@ func (m *Migrator) getMigrationFiles() ([]string, error) {
 	return files, nil
 }
 
-// getAppliedMigrations returns a map of applied migration versions.
-func (m *Migrator) getAppliedMigrations() (map[string]bool, error) {
+// getAppliedMigrations returns the applied migration versions.
+func (m *Migrator) getAppliedMigrations() (map[string]appliedMigration, error) {
 	//nolint:gosec // G201: table name is from configuration, not user input
-	query := fmt.Sprintf("SELECT version FROM %s", m.migrationsTable)
+	query := fmt.Sprintf("SELECT version, applied_at, dirty FROM %s", m.migrationsTable)
 	rows, err := m.db.Query(query)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 
-	applied := make(map[string]bool)
+	applied := make(map[string]appliedMigration)
 	for rows.Next() {
-		var version string
-		if err := rows.Scan(&version); err != nil {
+		var (
+			version string
+			record  appliedMigration
+		)
+		if err := rows.Scan(&version, &record.appliedAt, &record.dirty); err != nil {
 			return nil, err
 		}
-		applied[version] = true
+		applied[version] = record
 	}
 
 	return applied, rows.Err()
 }
 
-// runMigration runs a single migration file.
+// upFile normalizes a version to its .up.sql file name, so operators can pass
+// either "002_payee_favorites" or the full file name.
+func upFile(version string) string {
+	if strings.HasSuffix(version, ".up.sql") {
+		return version
+	}
+	return version + ".up.sql"
+}
+
+// downFile returns the down migration file name for an up migration.
+func downFile(upName string) string {
+	return strings.TrimSuffix(upName, ".up.sql") + ".down.sql"
+}
+
+func (m *Migrator) hasDown(upName string) bool {
+	_, err := os.Stat(filepath.Join(m.migrationsDir, downFile(upName)))
+	return err == nil
+}
+
+// runMigration runs a single migration file. The version is marked dirty
+// before the SQL runs and cleaned in the same transaction as the SQL, so a
+// migrator that dies part way leaves a dirty record behind.
 func (m *Migrator) runMigration(filename string) error {
 	// Read migration file
 	path := filepath.Join(m.migrationsDir, filename)
@@ -131,6 +315,53 @@ func (m *Migrator) runMigration(filename string) error {
 		return fmt.Errorf("failed to read migration file: %w", err)
 	}
 
+	//nolint:gosec // G201: table name is from configuration, not user input
+	query := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES ($1, TRUE)", m.migrationsTable)
+	if _, err := m.db.Exec(query, filename); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	//nolint:gosec // G201: table name is from configuration, not user input
+	clean := fmt.Sprintf("UPDATE %s SET dirty = FALSE, applied_at = NOW() WHERE version = $1", m.migrationsTable)
+	if err := m.execTracked(string(content), clean, filename); err != nil {
+		// The transaction rolled back cleanly, so the version was never applied
+		//nolint:gosec // G201: table name is from configuration, not user input
+		remove := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.migrationsTable)
+		_, _ = m.db.Exec(remove, filename)
+		return err
+	}
+
+	return nil
+}
+
+// rollbackMigration runs the down file of an applied migration and removes
+// its record, marking it dirty while the SQL runs.
+func (m *Migrator) rollbackMigration(upName string) error {
+	path := filepath.Join(m.migrationsDir, downFile(upName))
+	//nolint:gosec // G304: migration files are from controlled directory, not user input
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read down migration file: %w", err)
+	}
+
+	//nolint:gosec // G201: table name is from configuration, not user input
+	mark := fmt.Sprintf("UPDATE %s SET dirty = $2 WHERE version = $1", m.migrationsTable)
+	if _, err := m.db.Exec(mark, upName, true); err != nil {
+		return fmt.Errorf("failed to mark migration: %w", err)
+	}
+
+	//nolint:gosec // G201: table name is from configuration, not user input
+	remove := fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.migrationsTable)
+	if err := m.execTracked(string(content), remove, upName); err != nil {
+		_, _ = m.db.Exec(mark, upName, false)
+		return err
+	}
+
+	return nil
+}
+
+// execTracked runs migration SQL and a bookkeeping statement in one transaction.
+func (m *Migrator) execTracked(migrationSQL, bookkeeping, version string) error {
 	// Begin transaction
 	tx, err := m.db.Begin()
 	if err != nil {
@@ -139,14 +370,12 @@ func (m *Migrator) runMigration(filename string) error {
 	defer func() { _ = tx.Rollback() }()
 
 	// Execute migration SQL
-	if _, err := tx.Exec(string(content)); err != nil {
+	if _, err := tx.Exec(migrationSQL); err != nil {
 		return fmt.Errorf("failed to execute migration: %w", err)
 	}
 
 	// Record migration
-	//nolint:gosec // G201: table name is from configuration, not user input
-	query := fmt.Sprintf("INSERT INTO %s (version) VALUES ($1)", m.migrationsTable)
-	if _, err := tx.Exec(query, filename); err != nil {
+	if _, err := tx.Exec(bookkeeping, version); err != nil {
 		return fmt.Errorf("failed to record migration: %w", err)
 	}
 
@@ -157,8 +386,3 @@ func (m *Migrator) runMigration(filename string) error {
 
 	return nil
 }
-
-// Down rolls back the last migration (not implemented for safety).
-func (m *Migrator) Down() error {
-	return fmt.Errorf("down migrations not implemented - use manual rollback for safety")
-}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Note: These tests require a running PostgreSQL instance.
+// They will be skipped if the database is not available.
+
+func newTestMigrator(t *testing.T, files map[string]string) (*Migrator, *DB) {
+	t.Helper()
+	db := getTestDB(t)
+	if db == nil {
+		return nil, nil
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	suffix := time.Now().UnixNano()
+	m := NewMigrator(db.DB, dir)
+	m.migrationsTable = fmt.Sprintf("test_migrations_%d", suffix)
+	t.Cleanup(func() {
+		_, _ = db.Exec("DROP TABLE IF EXISTS " + m.migrationsTable)
+		_, _ = db.Exec("DROP TABLE IF EXISTS migrator_test_a, migrator_test_b")
+		_ = db.Close()
+	})
+	return m, db
+}
+
+func TestMigrator_UpDownSteps(t *testing.T) {
+	m, _ := newTestMigrator(t, map[string]string{
+		"001_a.up.sql":   "CREATE TABLE migrator_test_a (id INT)",
+		"001_a.down.sql": "DROP TABLE migrator_test_a",
+		"002_b.up.sql":   "CREATE TABLE migrator_test_b (id INT)",
+		"002_b.down.sql": "DROP TABLE migrator_test_b",
+	})
+	if m == nil {
+		return
+	}
+
+	if err := m.Steps(1); err != nil {
+		t.Fatalf("Steps(1) failed: %v", err)
+	}
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Version != "001_a.up.sql" || status.Pending != 1 {
+		t.Errorf("expected version 001 with 1 pending, got %+v", status)
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("Up failed: %v", err)
+	}
+	if err := m.Down(); err != nil {
+		t.Fatalf("Down failed: %v", err)
+	}
+	if status, _ := m.Status(); status.Version != "001_a.up.sql" {
+		t.Errorf("expected Down to roll back 002, got version %s", status.Version)
+	}
+
+	if err := m.Steps(-1); err != nil {
+		t.Fatalf("Steps(-1) failed: %v", err)
+	}
+	if err := m.Down(); err == nil {
+		t.Error("expected error with nothing to roll back")
+	}
+}
+
+func TestMigrator_FailedMigrationStaysClean(t *testing.T) {
+	m, _ := newTestMigrator(t, map[string]string{
+		"001_a.up.sql": "CREATE TABLE migrator_test_a (id INT); SELECT broken FROM nowhere",
+	})
+	if m == nil {
+		return
+	}
+
+	if err := m.Up(); err == nil {
+		t.Fatal("expected migration to fail")
+	}
+	status, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Dirty || status.Pending != 1 {
+		t.Errorf("expected a rolled back migration to stay pending and clean, got %+v", status)
+	}
+}
+
+func TestMigrator_DirtyAndForce(t *testing.T) {
+	m, db := newTestMigrator(t, map[string]string{
+		"001_a.up.sql": "CREATE TABLE migrator_test_a (id INT)",
+		"002_b.up.sql": "CREATE TABLE migrator_test_b (id INT)",
+	})
+	if m == nil {
+		return
+	}
+
+	if err := m.Steps(1); err != nil {
+		t.Fatalf("Steps(1) failed: %v", err)
+	}
+	// Simulate a migrator that died while running 002
+	if _, err := db.Exec("INSERT INTO "+m.migrationsTable+" (version, dirty) VALUES ('002_b.up.sql', TRUE)"); err != nil {
+		t.Fatalf("failed to insert dirty record: %v", err)
+	}
+
+	if err := m.Up(); !errors.Is(err, ErrDirty) {
+		t.Fatalf("expected ErrDirty, got %v", err)
+	}
+	if status, _ := m.Status(); !status.Dirty {
+		t.Error("expected status to report dirty")
+	}
+
+	if err := m.Force("001_a"); err != nil {
+		t.Fatalf("Force failed: %v", err)
+	}
+	status, _ := m.Status()
+	if status.Dirty || status.Version != "001_a.up.sql" || status.Pending != 1 {
+		t.Errorf("expected forced version 001 with 002 pending, got %+v", status)
+	}
+	if err := m.Up(); err != nil {
+		t.Errorf("expected Up to succeed after force, got %v", err)
+	}
+
+	if err := m.Force("999_missing"); err == nil {
+		t.Error("expected unknown version to be rejected")
+	}
+}
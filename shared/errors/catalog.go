@@ -0,0 +1,163 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Domain error codes. Clients branch on these instead of parsing messages.
+const (
+	// Wallet errors
+	ErrCodeWalletInsufficientFunds ErrorCode = "WALLET_INSUFFICIENT_FUNDS"
+	ErrCodeWalletNotActive         ErrorCode = "WALLET_NOT_ACTIVE"
+	ErrCodeWalletCurrencyMismatch  ErrorCode = "WALLET_CURRENCY_MISMATCH"
+
+	// Limit errors
+	ErrCodeLimitDailyExceeded   ErrorCode = "LIMIT_DAILY_EXCEEDED"
+	ErrCodeLimitMonthlyExceeded ErrorCode = "LIMIT_MONTHLY_EXCEEDED"
+	ErrCodeLimitCoolingOff      ErrorCode = "LIMIT_COOLING_OFF"
+
+	// KYC errors
+	ErrCodeKYCRequired ErrorCode = "KYC_REQUIRED"
+
+	// Transaction errors
+	ErrCodeTransferSameWallet       ErrorCode = "TRANSFER_SAME_WALLET"
+	ErrCodeTransactionInvalidState  ErrorCode = "TRANSACTION_INVALID_STATE"
+	ErrCodeTransactionNotReversible ErrorCode = "TRANSACTION_NOT_REVERSIBLE"
+	ErrCodeQuoteExpired             ErrorCode = "QUOTE_EXPIRED"
+	ErrCodeQuoteUsed                ErrorCode = "QUOTE_USED"
+	ErrCodeAmountBelowMinimum       ErrorCode = "AMOUNT_BELOW_MINIMUM"
+	ErrCodeAmountAboveMaximum       ErrorCode = "AMOUNT_ABOVE_MAXIMUM"
+
+	// Risk errors
+	ErrCodeRiskBlocked     ErrorCode = "RISK_BLOCKED"
+	ErrCodeRiskRuleInvalid ErrorCode = "RISK_RULE_INVALID"
+)
+
+// DomainGeneral groups the generic codes shared by every service.
+const DomainGeneral = "general"
+
+// CatalogEntry documents an error code: the HTTP status it maps to and when it is returned.
+type CatalogEntry struct {
+	Code        ErrorCode `json:"code"`
+	Status      int       `json:"status"`
+	Domain      string    `json:"domain"`
+	Description string    `json:"description"`
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = make(map[ErrorCode]CatalogEntry)
+)
+
+func init() {
+	Register(
+		// Generic client errors
+		CatalogEntry{ErrCodeNotFound, http.StatusNotFound, DomainGeneral, "Resource not found"},
+		CatalogEntry{ErrCodeBadRequest, http.StatusBadRequest, DomainGeneral, "Invalid request"},
+		CatalogEntry{ErrCodeValidation, http.StatusBadRequest, DomainGeneral, "Input validation failed"},
+		CatalogEntry{ErrCodeUnauthorized, http.StatusUnauthorized, DomainGeneral, "Authentication required"},
+		CatalogEntry{ErrCodeForbidden, http.StatusForbidden, DomainGeneral, "Permission denied"},
+		CatalogEntry{ErrCodeConflict, http.StatusConflict, DomainGeneral, "Resource conflict"},
+		CatalogEntry{ErrCodeRateLimit, http.StatusTooManyRequests, DomainGeneral, "Too many requests"},
+		CatalogEntry{ErrCodePrecondition, http.StatusPreconditionFailed, DomainGeneral, "Precondition not met"},
+		CatalogEntry{ErrCodeGone, http.StatusGone, DomainGeneral, "Resource is no longer available"},
+		CatalogEntry{ErrCodePayloadTooLarge, http.StatusRequestEntityTooLarge, DomainGeneral, "Request body too large"},
+		CatalogEntry{ErrCodeRequestTimeout, http.StatusRequestTimeout, DomainGeneral, "Client did not send the request in time"},
+
+		// Generic server errors
+		CatalogEntry{ErrCodeInternal, http.StatusInternalServerError, DomainGeneral, "Internal server error"},
+		CatalogEntry{ErrCodeUnavailable, http.StatusServiceUnavailable, DomainGeneral, "Service unavailable"},
+		CatalogEntry{ErrCodeTimeout, http.StatusGatewayTimeout, DomainGeneral, "Request timed out"},
+		CatalogEntry{ErrCodeDatabaseError, http.StatusInternalServerError, DomainGeneral, "Database operation failed"},
+
+		// Legacy money movement errors, superseded by the domain codes below
+		CatalogEntry{ErrCodeInsufficientFunds, http.StatusPreconditionFailed, DomainGeneral, "Not enough balance"},
+		CatalogEntry{ErrCodeInvalidAmount, http.StatusBadRequest, DomainGeneral, "Invalid transaction amount"},
+		CatalogEntry{ErrCodeInvalidCurrency, http.StatusBadRequest, DomainGeneral, "Invalid or unsupported currency"},
+		CatalogEntry{ErrCodeAccountFrozen, http.StatusPreconditionFailed, DomainGeneral, "Account is frozen"},
+		CatalogEntry{ErrCodeTransactionFailed, http.StatusInternalServerError, DomainGeneral, "Transaction failed"},
+		CatalogEntry{ErrCodeDuplicateIdempotencyKey, http.StatusConflict, DomainGeneral, "Idempotency key already used"},
+		CatalogEntry{ErrCodeLimitExceeded, http.StatusPreconditionFailed, DomainGeneral, "Limit exceeded"},
+
+		// Verification
+		CatalogEntry{ErrCodeVerificationRequired, http.StatusAccepted, "verification", "Operation needs OTP verification to proceed"},
+		CatalogEntry{ErrCodeVerificationExpired, http.StatusGone, "verification", "Verification request has expired"},
+		CatalogEntry{ErrCodeInvalidOTP, http.StatusBadRequest, "verification", "OTP code is wrong"},
+
+		// Wallet
+		CatalogEntry{ErrCodeWalletInsufficientFunds, http.StatusPreconditionFailed, "wallet", "Wallet balance is below the amount. details.shortfall holds the missing paise"},
+		CatalogEntry{ErrCodeWalletNotActive, http.StatusPreconditionFailed, "wallet", "Wallet is frozen, closed or not yet activated"},
+		CatalogEntry{ErrCodeWalletCurrencyMismatch, http.StatusBadRequest, "wallet", "Source and destination wallets hold different currencies"},
+
+		// Limits
+		CatalogEntry{ErrCodeLimitDailyExceeded, http.StatusPreconditionFailed, "limits", "Amount exceeds the wallet's remaining daily limit. details.remaining holds it in paise"},
+		CatalogEntry{ErrCodeLimitMonthlyExceeded, http.StatusPreconditionFailed, "limits", "Amount exceeds the wallet's remaining monthly limit. details.remaining holds it in paise"},
+		CatalogEntry{ErrCodeLimitCoolingOff, http.StatusPreconditionFailed, "limits", "Amount exceeds what a newly added beneficiary can receive during its cooling-off period. details.remaining holds it in paise"},
+
+		// KYC
+		CatalogEntry{ErrCodeKYCRequired, http.StatusForbidden, "kyc", "User must complete KYC verification first"},
+
+		// Transactions
+		CatalogEntry{ErrCodeTransferSameWallet, http.StatusBadRequest, "transaction", "Source and destination wallets are the same"},
+		CatalogEntry{ErrCodeTransactionInvalidState, http.StatusConflict, "transaction", "Transaction is not in a state that allows the operation"},
+		CatalogEntry{ErrCodeTransactionNotReversible, http.StatusConflict, "transaction", "Only completed, non-reversal transactions can be reversed"},
+		CatalogEntry{ErrCodeQuoteExpired, http.StatusGone, "transaction", "Transfer quote has expired; request a new one"},
+		CatalogEntry{ErrCodeQuoteUsed, http.StatusConflict, "transaction", "Transfer quote was already used by another transfer"},
+		CatalogEntry{ErrCodeAmountBelowMinimum, http.StatusBadRequest, "transaction", "Amount is below the minimum for the operation and currency. details.minimum holds it in minor units"},
+		CatalogEntry{ErrCodeAmountAboveMaximum, http.StatusBadRequest, "transaction", "Amount is above the maximum for the operation and currency. details.maximum holds it in minor units"},
+
+		// Risk
+		CatalogEntry{ErrCodeRiskBlocked, http.StatusForbidden, "risk", "Transaction was blocked by risk evaluation"},
+		CatalogEntry{ErrCodeRiskRuleInvalid, http.StatusBadRequest, "risk", "Risk rule parameters or notification targets are invalid"},
+	)
+}
+
+// Register adds codes to the error catalog. Services may register their own
+// codes at init time. It panics on an empty or duplicate code or an invalid
+// status, since either is a programming error.
+func Register(entries ...CatalogEntry) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	for _, entry := range entries {
+		if entry.Code == "" {
+			panic("errors: cannot register an empty error code")
+		}
+		if _, exists := catalog[entry.Code]; exists {
+			panic(fmt.Sprintf("errors: error code %s registered twice", entry.Code))
+		}
+		if entry.Status < 100 || entry.Status > 599 {
+			panic(fmt.Sprintf("errors: error code %s has invalid status %d", entry.Code, entry.Status))
+		}
+		catalog[entry.Code] = entry
+	}
+}
+
+// Lookup returns the catalog entry for a code.
+func Lookup(code ErrorCode) (CatalogEntry, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	entry, ok := catalog[code]
+	return entry, ok
+}
+
+// Catalog returns every registered code, sorted by domain and code.
+func Catalog() []CatalogEntry {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for _, entry := range catalog {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Domain != entries[j].Domain {
+			return entries[i].Domain < entries[j].Domain
+		}
+		return entries[i].Code < entries[j].Code
+	})
+	return entries
+}
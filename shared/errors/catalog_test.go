@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCatalog_DomainCodes(t *testing.T) {
+	tests := []struct {
+		code           ErrorCode
+		expectedStatus int
+	}{
+		{ErrCodeWalletInsufficientFunds, http.StatusPreconditionFailed},
+		{ErrCodeWalletNotActive, http.StatusPreconditionFailed},
+		{ErrCodeWalletCurrencyMismatch, http.StatusBadRequest},
+		{ErrCodeLimitDailyExceeded, http.StatusPreconditionFailed},
+		{ErrCodeLimitMonthlyExceeded, http.StatusPreconditionFailed},
+		{ErrCodeLimitCoolingOff, http.StatusPreconditionFailed},
+		{ErrCodeKYCRequired, http.StatusForbidden},
+		{ErrCodeTransferSameWallet, http.StatusBadRequest},
+		{ErrCodeTransactionInvalidState, http.StatusConflict},
+		{ErrCodeTransactionNotReversible, http.StatusConflict},
+		{ErrCodeQuoteExpired, http.StatusGone},
+		{ErrCodeQuoteUsed, http.StatusConflict},
+		{ErrCodeAmountBelowMinimum, http.StatusBadRequest},
+		{ErrCodeAmountAboveMaximum, http.StatusBadRequest},
+		{ErrCodeRiskBlocked, http.StatusForbidden},
+		{ErrCodeRiskRuleInvalid, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			entry, ok := Lookup(tt.code)
+			if !ok {
+				t.Fatalf("code %s is not registered", tt.code)
+			}
+			if entry.Description == "" || entry.Domain == "" {
+				t.Errorf("code %s needs a domain and description", tt.code)
+			}
+			if got := New(tt.code, "test").HTTPStatusCode(); got != tt.expectedStatus {
+				t.Errorf("HTTPStatusCode() = %v, want %v", got, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestCatalog_UnregisteredCodeMapsTo500(t *testing.T) {
+	if got := New(ErrorCode("SOMETHING_ELSE"), "test").HTTPStatusCode(); got != http.StatusInternalServerError {
+		t.Errorf("HTTPStatusCode() = %v, want 500", got)
+	}
+}
+
+func TestCatalog_Sorted(t *testing.T) {
+	entries := Catalog()
+	if len(entries) == 0 {
+		t.Fatal("expected registered codes")
+	}
+	for i := 1; i < len(entries); i++ {
+		prev, cur := entries[i-1], entries[i]
+		if prev.Domain > cur.Domain || (prev.Domain == cur.Domain && prev.Code >= cur.Code) {
+			t.Fatalf("catalog not sorted at %d: %s/%s before %s/%s", i, prev.Domain, prev.Code, cur.Domain, cur.Code)
+		}
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := map[string]CatalogEntry{
+		"empty code":     {Status: http.StatusBadRequest},
+		"duplicate code": {Code: ErrCodeNotFound, Status: http.StatusNotFound},
+		"invalid status": {Code: "TEST_INVALID_STATUS", Status: 42},
+	}
+
+	for name, entry := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected Register to panic")
+				}
+			}()
+			Register(entry)
+		})
+	}
+}
@@ -21,6 +21,8 @@ const (
 	ErrCodeConflict          ErrorCode = "CONFLICT"
 	ErrCodeRateLimit         ErrorCode = "RATE_LIMIT_EXCEEDED"
 	ErrCodePrecondition      ErrorCode = "PRECONDITION_FAILED"
+	ErrCodePayloadTooLarge   ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrCodeRequestTimeout    ErrorCode = "REQUEST_TIMEOUT"
 	ErrCodeInsufficientFunds ErrorCode = "INSUFFICIENT_FUNDS"
 
 	// Server errors (5xx)
@@ -80,42 +82,13 @@ func (e *Error) AddDetail(key string, value interface{}) *Error {
 	return e
 }
 
-// HTTPStatusCode returns the appropriate HTTP status code for this error.
+// HTTPStatusCode returns the HTTP status code registered for this error's code
+// in the catalog. Unregistered codes map to 500.
 func (e *Error) HTTPStatusCode() int {
-	switch e.Code {
-	// 4xx Client Errors
-	case ErrCodeNotFound:
-		return http.StatusNotFound
-	case ErrCodeBadRequest, ErrCodeValidation, ErrCodeInvalidAmount, ErrCodeInvalidCurrency:
-		return http.StatusBadRequest
-	case ErrCodeUnauthorized:
-		return http.StatusUnauthorized
-	case ErrCodeForbidden:
-		return http.StatusForbidden
-	case ErrCodeConflict, ErrCodeDuplicateIdempotencyKey:
-		return http.StatusConflict
-	case ErrCodeRateLimit:
-		return http.StatusTooManyRequests
-	case ErrCodePrecondition, ErrCodeInsufficientFunds, ErrCodeAccountFrozen, ErrCodeLimitExceeded:
-		return http.StatusPreconditionFailed
-	case ErrCodeVerificationRequired:
-		return http.StatusAccepted
-	case ErrCodeVerificationExpired, ErrCodeGone:
-		return http.StatusGone
-	case ErrCodeInvalidOTP:
-		return http.StatusBadRequest
-
-	// 5xx Server Errors
-	case ErrCodeInternal, ErrCodeDatabaseError, ErrCodeTransactionFailed:
-		return http.StatusInternalServerError
-	case ErrCodeUnavailable:
-		return http.StatusServiceUnavailable
-	case ErrCodeTimeout:
-		return http.StatusGatewayTimeout
-
-	default:
-		return http.StatusInternalServerError
+	if entry, ok := Lookup(e.Code); ok {
+		return entry.Status
 	}
+	return http.StatusInternalServerError
 }
 
 // New creates a new Error with the given code and message.
@@ -279,6 +252,16 @@ func Gone(message string) *Error {
 	return New(ErrCodeGone, message)
 }
 
+// PayloadTooLarge creates an error for request bodies over the size limit.
+func PayloadTooLarge(message string) *Error {
+	return New(ErrCodePayloadTooLarge, message)
+}
+
+// RequestTimeout creates an error for clients too slow to send their request.
+func RequestTimeout(message string) *Error {
+	return New(ErrCodeRequestTimeout, message)
+}
+
 // Utility functions for error checking
 
 // Is checks if an error is of a specific type using errors.Is.
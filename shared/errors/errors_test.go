@@ -100,6 +100,8 @@ func TestError_HTTPStatusCode(t *testing.T) {
 		{ErrCodeConflict, http.StatusConflict},
 		{ErrCodeRateLimit, http.StatusTooManyRequests},
 		{ErrCodePrecondition, http.StatusPreconditionFailed},
+		{ErrCodePayloadTooLarge, http.StatusRequestEntityTooLarge},
+		{ErrCodeRequestTimeout, http.StatusRequestTimeout},
 		{ErrCodeInternal, http.StatusInternalServerError},
 		{ErrCodeUnavailable, http.StatusServiceUnavailable},
 		{ErrCodeTimeout, http.StatusGatewayTimeout},
@@ -191,6 +193,8 @@ func TestCommonConstructors(t *testing.T) {
 		{"Database", func() *Error { return Database("query failed") }, ErrCodeDatabaseError},
 		{"Unavailable", func() *Error { return Unavailable("down") }, ErrCodeUnavailable},
 		{"Timeout", func() *Error { return Timeout("too slow") }, ErrCodeTimeout},
+		{"PayloadTooLarge", func() *Error { return PayloadTooLarge("too big") }, ErrCodePayloadTooLarge},
+		{"RequestTimeout", func() *Error { return RequestTimeout("too slow") }, ErrCodeRequestTimeout},
 		{"InsufficientFunds", func() *Error { return InsufficientFunds("not enough") }, ErrCodeInsufficientFunds},
 		{"AccountFrozen", func() *Error { return AccountFrozen("frozen") }, ErrCodeAccountFrozen},
 		{"TransactionFailed", func() *Error { return TransactionFailed("tx failed") }, ErrCodeTransactionFailed},
@@ -4,22 +4,36 @@ package events
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultReplayHistory is how many recent events a broker keeps for clients
+// resuming a stream.
+const DefaultReplayHistory = 10000
+
 // Event represents a single event to be broadcasted.
 type Event struct {
+	ID        string                 `json:"id,omitempty"` // Position in the stream, "<epoch>-<sequence>"; empty for control events
+	Topic     string                 `json:"topic,omitempty"`
 	Type      string                 `json:"type"`
 	Data      map[string]interface{} `json:"data"`
 	Timestamp time.Time              `json:"timestamp"`
 }
 
+// ownerFields are the payload keys that name the users an event belongs to.
+var ownerFields = []string{"user_id", "source_user_id", "dest_user_id"}
+
 // Client represents a connected SSE client.
 type Client struct {
 	ID      string
 	Channel chan Event
 	Topics  map[string]bool // Topics this client is subscribed to
+	UserID  string          // When set, only events owned by this user are delivered
+	dropped atomic.Int64    // Events skipped because the buffer was full
 	mu      sync.RWMutex
 }
 
@@ -32,6 +46,13 @@ func NewClient(id string) *Client {
 	}
 }
 
+// NewUserClient creates a client that only receives events owned by userID.
+func NewUserClient(id, userID string) *Client {
+	client := NewClient(id)
+	client.UserID = userID
+	return client
+}
+
 // Subscribe adds a topic to this client's subscriptions.
 func (c *Client) Subscribe(topic string) {
 	c.mu.Lock()
@@ -53,7 +74,28 @@ func (c *Client) IsSubscribed(topic string) bool {
 	return c.Topics[topic]
 }
 
+// TakeDropped returns how many events were skipped because the client's
+// buffer was full since the last call, and resets the count.
+func (c *Client) TakeDropped() int64 {
+	return c.dropped.Swap(0)
+}
+
+// wants reports whether the client should receive a broadcast event.
+func (c *Client) wants(event BroadcastEvent) bool {
+	// Per-user clients only see events they own
+	if c.UserID != "" && !event.ownedBy(c.UserID) {
+		return false
+	}
+	// Only send to clients subscribed to this topic (or "all")
+	return c.IsSubscribed(event.Topic) || c.IsSubscribed("all")
+}
+
 // Broker manages SSE connections and event broadcasting.
+//
+// Every broadcast event gets an ID made of the broker's epoch, which changes
+// each time the broker is created, and a sequence number. The most recent
+// events are kept so clients that reconnect can resume after the last ID they
+// saw.
 type Broker struct {
 	clients    map[string]*Client
 	register   chan *Client
@@ -61,12 +103,30 @@ type Broker struct {
 	broadcast  chan BroadcastEvent
 	stop       chan struct{}
 	mu         sync.RWMutex
+
+	epoch      string
+	historyMu  sync.Mutex // Also orders sequence numbers with delivery
+	seq        uint64
+	history    []BroadcastEvent // Ring buffer of the latest events
+	historyLen int
+	next       int // Ring index the next event is written to
 }
 
 // BroadcastEvent represents an event to be broadcasted to clients.
 type BroadcastEvent struct {
-	Topic string
-	Event Event
+	Topic   string
+	Event   Event
+	UserIDs []string // Users the event belongs to, for per-user clients
+}
+
+// ownedBy reports whether userID is one of the event's owners.
+func (e BroadcastEvent) ownedBy(userID string) bool {
+	for _, id := range e.UserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
 }
 
 // NewBroker creates a new SSE broker.
@@ -77,6 +137,8 @@ func NewBroker() *Broker {
 		unregister: make(chan *Client),
 		broadcast:  make(chan BroadcastEvent, 1000), // Buffer broadcasts
 		stop:       make(chan struct{}),
+		epoch:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		history:    make([]BroadcastEvent, DefaultReplayHistory),
 	}
 }
 
@@ -111,12 +173,12 @@ func (b *Broker) Start() {
 			case event := <-b.broadcast:
 				b.mu.RLock()
 				for _, client := range b.clients {
-					// Only send to clients subscribed to this topic (or "all")
-					if client.IsSubscribed(event.Topic) || client.IsSubscribed("all") {
+					if client.wants(event) {
 						select {
 						case client.Channel <- event.Event:
 						default:
 							// Client's buffer is full, skip this event
+							client.dropped.Add(1)
 						}
 					}
 				}
@@ -143,16 +205,117 @@ func (b *Broker) Unregister(client *Client) {
 
 // Broadcast sends an event to all subscribed clients.
 func (b *Broker) Broadcast(topic string, eventType string, data map[string]interface{}) {
+	b.BroadcastToUsers(topic, eventType, data, nil)
+}
+
+// BroadcastToUsers sends an event to subscribed clients and to the per-user
+// clients of its owners. Owners are userIDs plus any user named in the
+// payload's user_id, source_user_id or dest_user_id fields.
+func (b *Broker) BroadcastToUsers(topic string, eventType string, data map[string]interface{}, userIDs []string) {
 	event := Event{
+		Topic:     topic,
 		Type:      eventType,
 		Data:      data,
 		Timestamp: time.Now(),
 	}
+	broadcast := BroadcastEvent{
+		Topic:   topic,
+		Event:   event,
+		UserIDs: EventOwners(data, userIDs),
+	}
+
+	// Number, record and queue the event together so clients see sequence
+	// numbers in order
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+	b.seq++
+	broadcast.Event.ID = formatEventID(b.epoch, b.seq)
+	b.history[b.next] = broadcast
+	b.next = (b.next + 1) % len(b.history)
+	if b.historyLen < len(b.history) {
+		b.historyLen++
+	}
+	b.broadcast <- broadcast
+}
+
+// Replay returns the retained events after lastEventID that client would
+// receive, oldest first. An empty ID or one from an earlier epoch, e.g.
+// before the gateway restarted, replays every retained event. complete is
+// false when events after lastEventID were already discarded.
+func (b *Broker) Replay(client *Client, lastEventID string) (replayed []Event, complete bool) {
+	var after uint64
+	if epoch, seq, ok := ParseEventID(lastEventID); ok && epoch == b.epoch {
+		after = seq
+	}
 
-	b.broadcast <- BroadcastEvent{
-		Topic: topic,
-		Event: event,
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	if after >= b.seq {
+		return nil, true
+	}
+	oldest := b.seq - uint64(b.historyLen) + 1
+	complete = after+1 >= oldest
+
+	start := after + 1
+	if start < oldest {
+		start = oldest
+	}
+	for seq := start; seq <= b.seq; seq++ {
+		event := b.history[(b.next-int(b.seq-seq)-1+len(b.history))%len(b.history)]
+		if client.wants(event) {
+			replayed = append(replayed, event.Event)
+		}
+	}
+	return replayed, complete
+}
+
+// formatEventID formats a stream position as "<epoch>-<sequence>".
+func formatEventID(epoch string, seq uint64) string {
+	return epoch + "-" + strconv.FormatUint(seq, 10)
+}
+
+// ParseEventID splits an event ID into the broker epoch and sequence number.
+func ParseEventID(id string) (epoch string, seq uint64, ok bool) {
+	epoch, seqPart, found := strings.Cut(id, "-")
+	if !found || epoch == "" {
+		return "", 0, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return "", 0, false
 	}
+	return epoch, seq, true
+}
+
+// EventIDAfter reports whether event ID a comes after b in the same stream.
+// IDs from different epochs are not ordered.
+func EventIDAfter(a, b string) bool {
+	epochA, seqA, okA := ParseEventID(a)
+	epochB, seqB, okB := ParseEventID(b)
+	return okA && okB && epochA == epochB && seqA > seqB
+}
+
+// EventOwners merges explicit owner IDs with those named in the payload.
+func EventOwners(data map[string]interface{}, userIDs []string) []string {
+	seen := make(map[string]bool)
+	var owners []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			owners = append(owners, id)
+		}
+	}
+
+	for _, id := range userIDs {
+		add(id)
+	}
+	for _, field := range ownerFields {
+		if id, ok := data[field].(string); ok {
+			add(id)
+		}
+	}
+	return owners
 }
 
 // GetClientCount returns the number of connected clients.
@@ -162,8 +325,12 @@ func (b *Broker) GetClientCount() int {
 	return len(b.clients)
 }
 
-// FormatSSE formats an event for Server-Sent Events protocol.
+// FormatSSE formats an event for Server-Sent Events protocol. Events with an
+// ID carry it in the id field, so clients can resume with Last-Event-ID.
 func FormatSSE(event Event) string {
 	data, _ := json.Marshal(event)
+	if event.ID != "" {
+		return fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, string(data))
+	}
 	return fmt.Sprintf("event: %s\ndata: %s\n\n", event.Type, string(data))
 }
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, client *Client) (Event, bool) {
+	t.Helper()
+	select {
+	case event := <-client.Channel:
+		return event, true
+	case <-time.After(100 * time.Millisecond):
+		return Event{}, false
+	}
+}
+
+func TestBroker_PerUserClientsReceiveOwnedEventsOnly(t *testing.T) {
+	broker := NewBroker()
+	broker.Start()
+	defer broker.Stop()
+
+	alice := NewUserClient("alice-1", "alice")
+	alice.Subscribe("wallets")
+	observer := NewClient("observer-1")
+	observer.Subscribe("wallets")
+	broker.Register(alice)
+	broker.Register(observer)
+	time.Sleep(10 * time.Millisecond)
+
+	broker.Broadcast("wallets", "wallet.balance_updated", map[string]interface{}{"user_id": "bob"})
+	broker.Broadcast("wallets", "wallet.balance_updated", map[string]interface{}{"user_id": "alice"})
+
+	event, ok := receive(t, alice)
+	if !ok || event.Data["user_id"] != "alice" {
+		t.Fatalf("expected alice's event, got %+v (received=%v)", event.Data, ok)
+	}
+	if _, ok := receive(t, alice); ok {
+		t.Error("expected bob's event to be filtered out")
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := receive(t, observer); !ok {
+			t.Fatalf("expected topic client to receive event %d", i)
+		}
+	}
+}
+
+func TestEventOwners(t *testing.T) {
+	owners := EventOwners(map[string]interface{}{
+		"source_user_id": "u1",
+		"dest_user_id":   "u2",
+		"user_id":        "u1",
+	}, []string{"u3", ""})
+
+	want := map[string]bool{"u1": true, "u2": true, "u3": true}
+	if len(owners) != len(want) {
+		t.Fatalf("expected %d unique owners, got %v", len(want), owners)
+	}
+	for _, id := range owners {
+		if !want[id] {
+			t.Errorf("unexpected owner %q", id)
+		}
+	}
+}
+
+func TestBroker_CountsDroppedEvents(t *testing.T) {
+	broker := NewBroker()
+	broker.Start()
+	defer broker.Stop()
+
+	client := NewClient("slow-1")
+	client.Subscribe("all")
+	broker.Register(client)
+
+	total := cap(client.Channel) + 5
+	for i := 0; i < total; i++ {
+		broker.Broadcast("wallets", "wallet.balance_updated", nil)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for len(client.Channel)+int(client.dropped.Load()) < total && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if dropped := client.TakeDropped(); dropped != 5 {
+		t.Errorf("TakeDropped() = %d, want 5", dropped)
+	}
+	if dropped := client.TakeDropped(); dropped != 0 {
+		t.Errorf("expected TakeDropped to reset, got %d", dropped)
+	}
+}
+
+func TestBroker_ReplaysEventsAfterLastID(t *testing.T) {
+	broker := NewBroker()
+	broker.Start()
+	defer broker.Stop()
+
+	broker.Broadcast("wallets", "wallet.created", map[string]interface{}{"user_id": "alice"})
+	broker.Broadcast("users", "user.updated", map[string]interface{}{"user_id": "alice"})
+	broker.Broadcast("wallets", "wallet.closed", map[string]interface{}{"user_id": "bob"})
+	broker.Broadcast("wallets", "wallet.frozen", map[string]interface{}{"user_id": "alice"})
+
+	client := NewUserClient("alice-1", "alice")
+	client.Subscribe("wallets")
+
+	all, complete := broker.Replay(client, "")
+	if !complete || len(all) != 2 {
+		t.Fatalf("expected alice's 2 wallet events, got %d (complete=%v)", len(all), complete)
+	}
+	if all[0].Type != "wallet.created" || all[1].Type != "wallet.frozen" {
+		t.Errorf("unexpected replay order: %s, %s", all[0].Type, all[1].Type)
+	}
+	if !EventIDAfter(all[1].ID, all[0].ID) {
+		t.Errorf("expected %s to come after %s", all[1].ID, all[0].ID)
+	}
+
+	after, complete := broker.Replay(client, all[0].ID)
+	if !complete || len(after) != 1 || after[0].Type != "wallet.frozen" {
+		t.Fatalf("expected only wallet.frozen after the first event, got %+v", after)
+	}
+
+	if caughtUp, complete := broker.Replay(client, all[1].ID); !complete || len(caughtUp) != 0 {
+		t.Errorf("expected nothing after the latest event, got %d", len(caughtUp))
+	}
+
+	// IDs from a previous broker replay everything retained
+	if restarted, _ := broker.Replay(client, "previous-3"); len(restarted) != 2 {
+		t.Errorf("expected full replay for another epoch, got %d", len(restarted))
+	}
+}
+
+func TestBroker_ReplayReportsTrimmedHistory(t *testing.T) {
+	broker := NewBroker()
+	broker.Start()
+	defer broker.Stop()
+
+	client := NewClient("replay-1")
+	client.Subscribe("all")
+
+	broker.Broadcast("wallets", "wallet.created", nil)
+	first, _ := broker.Replay(client, "")
+	for i := 0; i < DefaultReplayHistory; i++ {
+		broker.Broadcast("wallets", "wallet.balance_updated", nil)
+	}
+
+	replayed, complete := broker.Replay(client, first[0].ID)
+	if !complete || len(replayed) != DefaultReplayHistory {
+		t.Fatalf("expected complete replay of %d events, got %d (complete=%v)", DefaultReplayHistory, len(replayed), complete)
+	}
+
+	broker.Broadcast("wallets", "wallet.balance_updated", nil)
+	replayed, complete = broker.Replay(client, first[0].ID)
+	if complete {
+		t.Error("expected replay to report trimmed history")
+	}
+	if len(replayed) != DefaultReplayHistory {
+		t.Errorf("expected %d retained events, got %d", DefaultReplayHistory, len(replayed))
+	}
+}
+
+func TestParseEventID(t *testing.T) {
+	epoch, seq, ok := ParseEventID("lx3k2a-42")
+	if !ok || epoch != "lx3k2a" || seq != 42 {
+		t.Errorf("unexpected parse: %q %d %v", epoch, seq, ok)
+	}
+	for _, id := range []string{"", "42", "-42", "abc-x"} {
+		if _, _, ok := ParseEventID(id); ok {
+			t.Errorf("expected %q to be invalid", id)
+		}
+	}
+	if EventIDAfter("a-2", "b-1") {
+		t.Error("IDs from different epochs must not be ordered")
+	}
+}
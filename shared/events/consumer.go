@@ -0,0 +1,303 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+// WildcardEventType registers a handler for every event type.
+const WildcardEventType = "*"
+
+// Handler processes one event. Returning an error retries the event under
+// the consumer's retry policy; errors wrapped with retry.Permanent are not
+// retried. Events may be delivered more than once, so handlers must be
+// idempotent.
+type Handler func(ctx context.Context, event Event) error
+
+// PoisonHandler is called with an event no handler could process, after
+// retries ran out or a handler failed permanently. The event is acknowledged
+// afterwards so it does not block the stream.
+type PoisonHandler func(ctx context.Context, event Event, err error)
+
+// ConsumerConfig configures an event consumer.
+type ConsumerConfig struct {
+	GatewayURL string        // Defaults to $GATEWAY_URL, then http://gateway:8000
+	Name       string        // Identifies the consumer's offset; required
+	Topics     []string      // Topics to subscribe to; defaults to all
+	Offsets    OffsetStore   // Where acknowledged positions are kept; defaults to memory
+	Retry      *retry.Policy // Handler retries; defaults to retry.DefaultPolicy
+	Reconnect  *retry.Policy // Wait between reconnects; defaults to 1s growing to 1m
+	OnPoison   PoisonHandler // Defaults to logging the event
+	HTTPClient *http.Client  // Must not time out while the stream is open; defaults to a client without timeout
+}
+
+// Consumer follows topics on the gateway's event stream and dispatches each
+// event to the handlers registered for its type.
+//
+// Delivery is at least once. An event is acknowledged, i.e. its ID saved to
+// the offset store, only after its handlers finished, and a consumer that
+// reconnects or restarts resumes after the last acknowledged event. The
+// gateway keeps a bounded history, so events are lost only when a consumer is
+// away long enough for it to be trimmed, or when the gateway restarts.
+type Consumer struct {
+	name      string
+	streamURL string
+	offsets   OffsetStore
+	retry     retry.Policy
+	reconnect retry.Policy
+	onPoison  PoisonHandler
+	client    *http.Client
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	lastID   string // Last acknowledged event ID
+}
+
+// NewConsumer creates a consumer. Register handlers before calling Run.
+func NewConsumer(config ConsumerConfig) *Consumer {
+	gatewayURL := config.GatewayURL
+	if gatewayURL == "" {
+		gatewayURL = os.Getenv("GATEWAY_URL")
+	}
+	if gatewayURL == "" {
+		gatewayURL = "http://gateway:8000"
+	}
+
+	topics := config.Topics
+	if len(topics) == 0 {
+		topics = []string{"all"}
+	}
+
+	offsets := config.Offsets
+	if offsets == nil {
+		offsets = NewMemoryOffsetStore()
+	}
+
+	retryPolicy := retry.DefaultPolicy()
+	if config.Retry != nil {
+		retryPolicy = *config.Retry
+	}
+
+	reconnect := retry.Policy{
+		InitialDelay: time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+	if config.Reconnect != nil {
+		reconnect = *config.Reconnect
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		// No client timeout: the stream stays open, heartbeats keep it alive
+		client = &http.Client{}
+	}
+
+	c := &Consumer{
+		name:      config.Name,
+		streamURL: strings.TrimRight(gatewayURL, "/") + "/api/v1/events?topics=" + url.QueryEscape(strings.Join(topics, ",")),
+		offsets:   offsets,
+		retry:     retryPolicy,
+		reconnect: reconnect,
+		onPoison:  config.OnPoison,
+		client:    client,
+		handlers:  make(map[string][]Handler),
+	}
+	if c.onPoison == nil {
+		c.onPoison = c.logPoison
+	}
+	return c
+}
+
+// Handle registers a handler for an event type, or for every type with
+// WildcardEventType. Events without a handler are acknowledged unprocessed.
+func (c *Consumer) Handle(eventType string, handler Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[eventType] = append(c.handlers[eventType], handler)
+}
+
+// HandleTyped registers a handler for a typed payload's events. Events that
+// do not decode into the payload are poison and are not retried.
+func HandleTyped[T any, P interface {
+	*T
+	Payload
+}](c *Consumer, handler func(ctx context.Context, event Event, payload T) error) {
+	c.Handle(P(new(T)).EventType(), func(ctx context.Context, event Event) error {
+		var payload T
+		if err := Decode(event, P(&payload)); err != nil {
+			return retry.Permanent(err)
+		}
+		return handler(ctx, event, payload)
+	})
+}
+
+// LastEventID returns the ID of the last acknowledged event.
+func (c *Consumer) LastEventID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastID
+}
+
+// Run follows the event stream until ctx ends, reconnecting after errors.
+func (c *Consumer) Run(ctx context.Context) {
+	lastID, err := c.offsets.Load(ctx, c.name)
+	if err != nil {
+		// Starting from the gateway's retained history is safe for idempotent handlers
+		log.Printf("[events] Consumer %s could not load its offset, replaying retained events: %v", c.name, err)
+	}
+	c.mu.Lock()
+	c.lastID = lastID
+	c.mu.Unlock()
+
+	failures := 0
+	for ctx.Err() == nil {
+		received, err := c.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			failures = 0
+		}
+		failures++
+
+		delay := c.reconnect.Delay(failures)
+		log.Printf("[events] Consumer %s disconnected: %v (reconnecting in %s)", c.name, err, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// consume reads the stream until it ends. It reports whether any event was
+// received, so a stream that was up for a while reconnects quickly.
+func (c *Consumer) consume(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.streamURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastID := c.LastEventID(); lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("event stream returned HTTP %d", resp.StatusCode)
+	}
+
+	received := false
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var data strings.Builder
+	var id string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends an event
+			if data.Len() > 0 {
+				received = true
+				if err := c.dispatch(ctx, id, data.String()); err != nil {
+					return received, err
+				}
+			}
+			data.Reset()
+			id = ""
+		case strings.HasPrefix(line, "id:"):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, fmt.Errorf("event stream closed")
+}
+
+// dispatch handles one event from the stream and acknowledges it. It returns
+// an error only when the event could not be finished because ctx ended.
+func (c *Consumer) dispatch(ctx context.Context, id, raw string) error {
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		log.Printf("[events] Consumer %s skipping unreadable event %s: %v", c.name, id, err)
+		return nil
+	}
+	if event.ID == "" {
+		event.ID = id
+	}
+
+	// Stream control events carry no ID and are not acknowledged
+	if event.ID == "" {
+		switch event.Type {
+		case "stream.gap":
+			log.Printf("[events] Consumer %s missed events after %s: no longer retained by the gateway", c.name, c.LastEventID())
+		case "dropped":
+			log.Printf("[events] Consumer %s fell behind; the gateway will close the stream for it to resume", c.name)
+		}
+		return nil
+	}
+
+	// Replays may overlap what was already acknowledged
+	if EventIDAfter(c.LastEventID(), event.ID) || c.LastEventID() == event.ID {
+		return nil
+	}
+
+	c.mu.RLock()
+	handlers := append(append([]Handler(nil), c.handlers[event.Type]...), c.handlers[WildcardEventType]...)
+	c.mu.RUnlock()
+
+	for _, handler := range handlers {
+		err := retry.Do(ctx, c.retry, func(ctx context.Context) error {
+			return handler(ctx, event)
+		})
+		if ctx.Err() != nil {
+			// Not acknowledged; redelivered once the consumer resumes
+			return ctx.Err()
+		}
+		if err != nil {
+			c.onPoison(ctx, event, err)
+		}
+	}
+
+	c.ack(ctx, event.ID)
+	return nil
+}
+
+// ack records an event as processed. A failed save only means the event may
+// be delivered again after a restart.
+func (c *Consumer) ack(ctx context.Context, id string) {
+	c.mu.Lock()
+	c.lastID = id
+	c.mu.Unlock()
+
+	if err := c.offsets.Save(ctx, c.name, id); err != nil {
+		log.Printf("[events] Consumer %s failed to save offset %s: %v", c.name, id, err)
+	}
+}
+
+// logPoison is the default poison handler.
+func (c *Consumer) logPoison(_ context.Context, event Event, err error) {
+	log.Printf("[events] Consumer %s giving up on %s event %s: %v", c.name, event.Type, event.ID, err)
+}
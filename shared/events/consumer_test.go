@@ -0,0 +1,195 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+// streamServer serves the given SSE frames on each connection, then closes
+// it, and records the Last-Event-ID each connection sent.
+func streamServer(t *testing.T, frames ...string) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var resumedFrom []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		resumedFrom = append(resumedFrom, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, FormatSSE(Event{Type: "connected", Data: map[string]interface{}{}}))
+		for _, frame := range frames {
+			_, _ = fmt.Fprint(w, frame)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), resumedFrom...)
+	}
+}
+
+func frame(id, eventType string, data map[string]interface{}) string {
+	return FormatSSE(Event{ID: id, Topic: "users", Type: eventType, Data: data, Timestamp: time.Now()})
+}
+
+// runUntil runs the consumer until done reports true or a second passes.
+func runUntil(t *testing.T, consumer *Consumer, done func() bool) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		consumer.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !done() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-finished
+	if !done() {
+		t.Fatal("consumer did not reach the expected state")
+	}
+}
+
+func testConsumerConfig(gatewayURL string, offsets OffsetStore) ConsumerConfig {
+	return ConsumerConfig{
+		GatewayURL: gatewayURL,
+		Name:       "test-consumer",
+		Topics:     []string{"users"},
+		Offsets:    offsets,
+		Retry:      &retry.Policy{MaxAttempts: 3, InitialDelay: time.Millisecond},
+		Reconnect:  &retry.Policy{InitialDelay: 10 * time.Millisecond},
+	}
+}
+
+func TestConsumer_RetriesAndPoisonsThenAcknowledges(t *testing.T) {
+	server, _ := streamServer(t,
+		frame("ep-1", "user.registered", map[string]interface{}{"user_id": "u1"}),
+		frame("ep-2", "user.deleted", map[string]interface{}{"user_id": "u2"}),
+		frame("ep-3", "user.suspended", map[string]interface{}{"user_id": "u3"}),
+	)
+	offsets := NewMemoryOffsetStore()
+	var mu sync.Mutex
+	var poisoned []string
+	config := testConsumerConfig(server.URL, offsets)
+	config.OnPoison = func(_ context.Context, event Event, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		poisoned = append(poisoned, event.ID+": "+err.Error())
+	}
+	consumer := NewConsumer(config)
+
+	attempts := 0
+	consumer.Handle("user.registered", func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 2 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+	deletedCalls := 0
+	consumer.Handle("user.deleted", func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deletedCalls++
+		return retry.Permanent(errors.New("unknown user"))
+	})
+
+	runUntil(t, consumer, func() bool {
+		id, _ := offsets.Load(context.Background(), "test-consumer")
+		return id == "ep-3"
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("expected the transient failure to be retried once, got %d attempts", attempts)
+	}
+	if deletedCalls != 1 {
+		t.Errorf("expected a permanent failure not to be retried, got %d calls", deletedCalls)
+	}
+	if len(poisoned) != 1 || !strings.HasPrefix(poisoned[0], "ep-2: unknown user") {
+		t.Errorf("expected ep-2 to be poisoned, got %v", poisoned)
+	}
+}
+
+func TestConsumer_ResumesAfterAcknowledgedEvent(t *testing.T) {
+	server, resumedFrom := streamServer(t,
+		frame("ep-4", "user.registered", map[string]interface{}{"user_id": "u4"}),
+		frame("ep-5", "user.registered", map[string]interface{}{"user_id": "u5"}),
+	)
+	offsets := NewMemoryOffsetStore()
+	_ = offsets.Save(context.Background(), "test-consumer", "ep-4")
+	consumer := NewConsumer(testConsumerConfig(server.URL, offsets))
+
+	var mu sync.Mutex
+	var handled []string
+	consumer.Handle(WildcardEventType, func(ctx context.Context, event Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, event.ID)
+		return nil
+	})
+
+	// The stream closes after each batch, so the consumer reconnects
+	runUntil(t, consumer, func() bool { return len(resumedFrom()) >= 2 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(handled) != 1 || handled[0] != "ep-5" {
+		t.Errorf("expected only ep-5 to be handled, got %v", handled)
+	}
+	if got := resumedFrom(); got[0] != "ep-4" || got[1] != "ep-5" {
+		t.Errorf("expected reconnects to resume from ep-4 then ep-5, got %v", got)
+	}
+}
+
+func TestHandleTyped_UndecodableEventsArePoison(t *testing.T) {
+	server, _ := streamServer(t,
+		frame("ep-1", "user.preferences_updated", map[string]interface{}{"user_id": 42}),
+		frame("ep-2", "user.preferences_updated", map[string]interface{}{"user_id": "u2", "locale": "hi-IN"}),
+	)
+	offsets := NewMemoryOffsetStore()
+	var poisoned []string
+	var mu sync.Mutex
+	config := testConsumerConfig(server.URL, offsets)
+	config.OnPoison = func(_ context.Context, event Event, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		poisoned = append(poisoned, event.ID)
+	}
+	consumer := NewConsumer(config)
+
+	var locales []string
+	HandleTyped(consumer, func(ctx context.Context, event Event, payload UserPreferencesUpdated) error {
+		mu.Lock()
+		defer mu.Unlock()
+		locales = append(locales, payload.Locale)
+		return nil
+	})
+
+	runUntil(t, consumer, func() bool { return consumer.LastEventID() == "ep-2" })
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(poisoned) != 1 || poisoned[0] != "ep-1" {
+		t.Errorf("expected ep-1 to be poisoned, got %v", poisoned)
+	}
+	if len(locales) != 1 || locales[0] != "hi-IN" {
+		t.Errorf("expected ep-2 to be decoded, got %v", locales)
+	}
+}
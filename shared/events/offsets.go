@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+)
+
+// OffsetStore keeps the ID of the last event each consumer acknowledged.
+type OffsetStore interface {
+	// Load returns the consumer's last acknowledged event ID, or "" if it has
+	// none.
+	Load(ctx context.Context, consumer string) (string, error)
+	// Save records the consumer's last acknowledged event ID.
+	Save(ctx context.Context, consumer, eventID string) error
+}
+
+// MemoryOffsetStore keeps offsets in memory. A restarted consumer replays the
+// gateway's retained history.
+type MemoryOffsetStore struct {
+	mu      sync.Mutex
+	offsets map[string]string
+}
+
+// NewMemoryOffsetStore creates an empty in-memory offset store.
+func NewMemoryOffsetStore() *MemoryOffsetStore {
+	return &MemoryOffsetStore{offsets: make(map[string]string)}
+}
+
+// Load returns the consumer's last acknowledged event ID.
+func (s *MemoryOffsetStore) Load(_ context.Context, consumer string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.offsets[consumer], nil
+}
+
+// Save records the consumer's last acknowledged event ID.
+func (s *MemoryOffsetStore) Save(_ context.Context, consumer, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offsets[consumer] = eventID
+	return nil
+}
+
+// SQLOffsetStore keeps offsets in a service's database, in a table created by
+// the service's migrations:
+//
+//	CREATE TABLE event_consumer_offsets (
+//	    consumer      VARCHAR(100) PRIMARY KEY,
+//	    last_event_id VARCHAR(100) NOT NULL,
+//	    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+//	);
+type SQLOffsetStore struct {
+	db *sql.DB
+}
+
+// NewSQLOffsetStore creates an offset store backed by db.
+func NewSQLOffsetStore(db *sql.DB) *SQLOffsetStore {
+	return &SQLOffsetStore{db: db}
+}
+
+// Load returns the consumer's last acknowledged event ID.
+func (s *SQLOffsetStore) Load(ctx context.Context, consumer string) (string, error) {
+	var eventID string
+	err := s.db.QueryRowContext(ctx,
+		`SELECT last_event_id FROM event_consumer_offsets WHERE consumer = $1`,
+		consumer,
+	).Scan(&eventID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to load offset for consumer %s: %w", consumer, err)
+	}
+	return eventID, nil
+}
+
+// Save records the consumer's last acknowledged event ID.
+func (s *SQLOffsetStore) Save(ctx context.Context, consumer, eventID string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_consumer_offsets (consumer, last_event_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (consumer) DO UPDATE
+		SET last_event_id = EXCLUDED.last_event_id, updated_at = EXCLUDED.updated_at`,
+		consumer, eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save offset for consumer %s: %w", consumer, err)
+	}
+	return nil
+}
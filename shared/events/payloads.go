@@ -0,0 +1,279 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/1mb-dev/nivomoney/shared/events/schema"
+)
+
+// Payload is a typed event body with a versioned schema. Bump SchemaVersion
+// when adding fields; new fields must be optional (omitempty or pointers).
+type Payload interface {
+	EventType() string
+	SchemaVersion() int
+}
+
+// TransactionCreated is published when a transaction is recorded.
+type TransactionCreated struct {
+	TransactionID       string  `json:"transaction_id"`
+	Type                string  `json:"type"`
+	Status              string  `json:"status"`
+	Amount              int64   `json:"amount"`
+	Currency            string  `json:"currency"`
+	SourceWalletID      *string `json:"source_wallet_id,omitempty"`
+	DestinationWalletID *string `json:"destination_wallet_id,omitempty"`
+	Description         string  `json:"description,omitempty"`
+}
+
+func (TransactionCreated) EventType() string  { return "transaction.created" }
+func (TransactionCreated) SchemaVersion() int { return 1 }
+
+// TransactionCompleted is published when a transaction settles.
+type TransactionCompleted struct {
+	TransactionID       string  `json:"transaction_id"`
+	Type                string  `json:"type"`
+	Status              string  `json:"status"`
+	Amount              int64   `json:"amount"`
+	Currency            string  `json:"currency"`
+	SourceWalletID      *string `json:"source_wallet_id,omitempty"`
+	DestinationWalletID *string `json:"destination_wallet_id,omitempty"`
+}
+
+func (TransactionCompleted) EventType() string  { return "transaction.completed" }
+func (TransactionCompleted) SchemaVersion() int { return 1 }
+
+// TransactionFailed is published when a transaction fails to settle.
+type TransactionFailed struct {
+	TransactionID       string  `json:"transaction_id"`
+	Type                string  `json:"type"`
+	Status              string  `json:"status"`
+	Amount              int64   `json:"amount"`
+	Currency            string  `json:"currency"`
+	SourceWalletID      *string `json:"source_wallet_id,omitempty"`
+	DestinationWalletID *string `json:"destination_wallet_id,omitempty"`
+	FailureReason       string  `json:"failure_reason,omitempty"`
+	ErrorCode           string  `json:"error_code,omitempty"`
+}
+
+func (TransactionFailed) EventType() string  { return "transaction.failed" }
+func (TransactionFailed) SchemaVersion() int { return 1 }
+
+// WalletCreated is published when a wallet is opened.
+type WalletCreated struct {
+	WalletID         string `json:"wallet_id"`
+	UserID           string `json:"user_id"`
+	Type             string `json:"type"`
+	Currency         string `json:"currency"`
+	Status           string `json:"status"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	LedgerAccountID  string `json:"ledger_account_id,omitempty"`
+}
+
+func (WalletCreated) EventType() string  { return "wallet.created" }
+func (WalletCreated) SchemaVersion() int { return 1 }
+
+// WalletStatusChanged is published when a wallet is activated, frozen,
+// unfrozen or closed.
+type WalletStatusChanged struct {
+	WalletID         string `json:"wallet_id"`
+	UserID           string `json:"user_id"`
+	Currency         string `json:"currency"`
+	OldStatus        string `json:"old_status"`
+	NewStatus        string `json:"new_status"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	Action           string `json:"action"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+func (WalletStatusChanged) EventType() string  { return "wallet.status_changed" }
+func (WalletStatusChanged) SchemaVersion() int { return 1 }
+
+// WalletBalanceUpdated is published after money moves in or out of a wallet.
+type WalletBalanceUpdated struct {
+	WalletID         string `json:"wallet_id"`
+	UserID           string `json:"user_id"`
+	Currency         string `json:"currency"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+	Change           int64  `json:"change"` // Signed amount in paise, negative for debits
+	TransactionID    string `json:"transaction_id,omitempty"`
+}
+
+func (WalletBalanceUpdated) EventType() string  { return "wallet.balance_updated" }
+func (WalletBalanceUpdated) SchemaVersion() int { return 1 }
+
+// UserRegistered is published when a user signs up.
+type UserRegistered struct {
+	UserID      string `json:"user_id"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone,omitempty"`
+	FullName    string `json:"full_name"`
+	Status      string `json:"status"`
+	AccountType string `json:"account_type"`
+	UserAdminID string `json:"user_admin_id,omitempty"`
+}
+
+func (UserRegistered) EventType() string  { return "user.registered" }
+func (UserRegistered) SchemaVersion() int { return 1 }
+
+// UserPreferencesUpdated is published when a user's profile attributes
+// change. It carries the full set after the change; absent fields are unset.
+type UserPreferencesUpdated struct {
+	UserID           string `json:"user_id"`
+	Locale           string `json:"locale,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+	DisplayCurrency  string `json:"display_currency,omitempty"`
+	MarketingConsent *bool  `json:"marketing_consent,omitempty"`
+	QuietHoursStart  string `json:"quiet_hours_start,omitempty"` // HH:MM in Timezone
+	QuietHoursEnd    string `json:"quiet_hours_end,omitempty"`   // HH:MM in Timezone
+	UpdatedAt        string `json:"updated_at"`                  // RFC 3339; later updates win
+}
+
+func (UserPreferencesUpdated) EventType() string  { return "user.preferences_updated" }
+func (UserPreferencesUpdated) SchemaVersion() int { return 1 }
+
+// NotificationInApp is published when an in-app notification reaches a
+// user's inbox.
+type NotificationInApp struct {
+	NotificationID string `json:"notification_id"`
+	UserID         string `json:"user_id"`
+	Type           string `json:"type"`
+	Priority       string `json:"priority"`
+	Subject        string `json:"subject,omitempty"`
+	Body           string `json:"body"`
+	CreatedAt      string `json:"created_at"`
+	UnreadCount    int64  `json:"unread_count"` // Inbox unread count including this notification
+}
+
+func (NotificationInApp) EventType() string  { return "notification.in_app" }
+func (NotificationInApp) SchemaVersion() int { return 1 }
+
+// LedgerEntryLine is one line of a journal entry in a ledger event.
+type LedgerEntryLine struct {
+	AccountID    string `json:"account_id"`
+	DebitAmount  int64  `json:"debit_amount"`
+	CreditAmount int64  `json:"credit_amount"`
+}
+
+// LedgerEntryPosted is published when a journal entry is posted and its
+// lines reach account balances.
+type LedgerEntryPosted struct {
+	EntryID       string            `json:"entry_id"`
+	EntryNumber   string            `json:"entry_number"`
+	Type          string            `json:"type"`
+	Description   string            `json:"description,omitempty"`
+	ReferenceType string            `json:"reference_type,omitempty"`
+	ReferenceID   string            `json:"reference_id,omitempty"`
+	Amount        int64             `json:"amount"` // Total debits in paise
+	Lines         []LedgerEntryLine `json:"lines"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	PostedBy      string            `json:"posted_by,omitempty"`
+	PostedAt      string            `json:"posted_at"` // RFC 3339
+}
+
+func (LedgerEntryPosted) EventType() string  { return "ledger.entry.posted" }
+func (LedgerEntryPosted) SchemaVersion() int { return 1 }
+
+// LedgerEntryVoided is published when a posted journal entry is voided.
+type LedgerEntryVoided struct {
+	EntryID       string            `json:"entry_id"`
+	EntryNumber   string            `json:"entry_number"`
+	Type          string            `json:"type"`
+	ReferenceType string            `json:"reference_type,omitempty"`
+	ReferenceID   string            `json:"reference_id,omitempty"`
+	Amount        int64             `json:"amount"` // Total debits in paise
+	Lines         []LedgerEntryLine `json:"lines"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	VoidedBy      string            `json:"voided_by,omitempty"`
+	VoidReason    string            `json:"void_reason,omitempty"`
+	VoidedAt      string            `json:"voided_at"` // RFC 3339
+}
+
+func (LedgerEntryVoided) EventType() string  { return "ledger.entry.voided" }
+func (LedgerEntryVoided) SchemaVersion() int { return 1 }
+
+// LedgerEntryReversed is published when a posted journal entry is reversed
+// by a new entry with the opposite lines. The reversing entry also gets its
+// own ledger.entry.posted event.
+type LedgerEntryReversed struct {
+	EntryID             string            `json:"entry_id"`
+	EntryNumber         string            `json:"entry_number"`
+	ReversalEntryID     string            `json:"reversal_entry_id"`
+	ReversalEntryNumber string            `json:"reversal_entry_number"`
+	ReferenceType       string            `json:"reference_type,omitempty"`
+	ReferenceID         string            `json:"reference_id,omitempty"`
+	Amount              int64             `json:"amount"` // Total debits of the reversed entry in paise
+	Metadata            map[string]string `json:"metadata,omitempty"`
+	Reason              string            `json:"reason,omitempty"`
+	ReversedBy          string            `json:"reversed_by,omitempty"`
+	ReversedAt          string            `json:"reversed_at"` // RFC 3339
+}
+
+func (LedgerEntryReversed) EventType() string  { return "ledger.entry.reversed" }
+func (LedgerEntryReversed) SchemaVersion() int { return 1 }
+
+func init() {
+	for _, p := range []Payload{
+		TransactionCreated{},
+		TransactionCompleted{},
+		TransactionFailed{},
+		WalletCreated{},
+		WalletStatusChanged{},
+		WalletBalanceUpdated{},
+		UserRegistered{},
+		UserPreferencesUpdated{},
+		NotificationInApp{},
+		LedgerEntryPosted{},
+		LedgerEntryVoided{},
+		LedgerEntryReversed{},
+	} {
+		schema.Default.MustRegister(schema.FromStruct(p.EventType(), p.SchemaVersion(), p))
+	}
+}
+
+// Encode converts a payload to event data tagged with its schema version.
+func Encode(payload Payload) (map[string]interface{}, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", payload.EventType(), err)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", payload.EventType(), err)
+	}
+	data[schema.VersionField] = payload.SchemaVersion()
+	return data, nil
+}
+
+// Decode reads an event into a typed payload. Events published with a newer
+// schema version decode into older structs because new fields are optional
+// and unknown fields are ignored.
+func Decode(event Event, out Payload) error {
+	if event.Type != out.EventType() {
+		return fmt.Errorf("cannot decode %s event into %s payload", event.Type, out.EventType())
+	}
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s event: %w", event.Type, err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// Version returns the schema version an event was published with, or 0 for
+// events published without one.
+func Version(event Event) int {
+	switch v := event.Data[schema.VersionField].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
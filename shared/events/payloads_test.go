@@ -0,0 +1,93 @@
+package events
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/events/schema"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	source := "w1"
+	data, err := Encode(TransactionCreated{
+		TransactionID:  "t1",
+		Type:           "transfer",
+		Status:         "pending",
+		Amount:         5000,
+		Currency:       "INR",
+		SourceWalletID: &source,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data[schema.VersionField] != 1 {
+		t.Errorf("expected schema version 1, got %v", data[schema.VersionField])
+	}
+
+	var got TransactionCreated
+	if err := Decode(Event{Type: "transaction.created", Data: data}, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.TransactionID != "t1" || got.Amount != 5000 || got.SourceWalletID == nil || *got.SourceWalletID != "w1" {
+		t.Errorf("unexpected payload: %+v", got)
+	}
+}
+
+func TestDecode_ToleratesNewerSchema(t *testing.T) {
+	// A v2 producer added an optional field this consumer does not know about
+	event := Event{Type: "wallet.created", Data: map[string]interface{}{
+		"wallet_id":         "w1",
+		"user_id":           "u1",
+		"type":              "default",
+		"currency":          "INR",
+		"status":            "inactive",
+		"balance":           float64(0),
+		"available_balance": float64(0),
+		"nickname":          "savings",
+		"schema_version":    float64(2),
+	}}
+
+	var got WalletCreated
+	if err := Decode(event, &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.WalletID != "w1" || Version(event) != 2 {
+		t.Errorf("unexpected decode: %+v version %d", got, Version(event))
+	}
+
+	if err := Decode(event, &TransactionCreated{}); err == nil {
+		t.Error("expected mismatched event type to fail")
+	}
+}
+
+func TestPublisher_ValidatesBeforePublish(t *testing.T) {
+	var received []BroadcastPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload BroadcastPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := NewPublisher(PublishConfig{GatewayURL: server.URL, ServiceName: "test"})
+
+	if err := p.Publish("users", UserRegistered{UserID: "u1", Email: "a@b.c", FullName: "A", Status: "pending", AccountType: "user"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := p.PublishEvent("users", "user.registered", map[string]interface{}{"user_id": "u1"}); err == nil {
+		t.Error("expected payload missing required fields to be rejected")
+	}
+	if err := p.PublishEvent("users", "user.custom", map[string]interface{}{"anything": true}); err != nil {
+		t.Errorf("expected unregistered event to publish: %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("expected 2 published events, got %d", len(received))
+	}
+	if received[0].Data[schema.VersionField] != float64(1) || received[0].Data["service"] != "test" {
+		t.Errorf("expected version and metadata on published event, got %v", received[0].Data)
+	}
+}
@@ -2,11 +2,15 @@ package events
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/events/schema"
+	"github.com/1mb-dev/nivomoney/shared/retry"
 )
 
 // Publisher publishes events to the Gateway's SSE broker.
@@ -14,6 +18,8 @@ type Publisher struct {
 	gatewayURL  string
 	httpClient  *http.Client
 	serviceName string
+	registry    *schema.Registry
+	retry       retry.Policy
 }
 
 // PublishConfig configures the event publisher.
@@ -21,6 +27,8 @@ type PublishConfig struct {
 	GatewayURL  string
 	ServiceName string
 	Timeout     time.Duration
+	Registry    *schema.Registry // Defaults to schema.Default
+	Retry       *retry.Policy    // Defaults to retry.DefaultPolicy; broker errors and 5xx answers are retried
 }
 
 // NewPublisher creates a new event publisher.
@@ -40,9 +48,21 @@ func NewPublisher(config PublishConfig) *Publisher {
 		timeout = 5 * time.Second
 	}
 
+	registry := config.Registry
+	if registry == nil {
+		registry = schema.Default
+	}
+
+	retryPolicy := retry.DefaultPolicy()
+	if config.Retry != nil {
+		retryPolicy = *config.Retry
+	}
+
 	return &Publisher{
 		gatewayURL:  gatewayURL,
 		serviceName: config.ServiceName,
+		registry:    registry,
+		retry:       retryPolicy,
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
@@ -51,28 +71,45 @@ func NewPublisher(config PublishConfig) *Publisher {
 
 // BroadcastPayload represents the JSON payload for broadcasting events.
 type BroadcastPayload struct {
-	Topic string                 `json:"topic"`
-	Type  string                 `json:"type"`
-	Data  map[string]interface{} `json:"data"`
+	Topic   string                 `json:"topic"`
+	Type    string                 `json:"type"`
+	Data    map[string]interface{} `json:"data"`
+	UserIDs []string               `json:"user_ids,omitempty"` // Owners, for per-user streams
 }
 
 // PublishEvent publishes an event to the SSE broker via the Gateway.
 // Topic determines which subscribers receive the event.
 // EventType is the event name (e.g., "transaction.created").
-// Data contains the event payload.
+// Data contains the event payload. Data for registered event types is
+// validated against its schema and rejected if it does not match.
 func (p *Publisher) PublishEvent(topic, eventType string, data map[string]interface{}) error {
-	// Add metadata
+	return p.publish(topic, eventType, data, nil)
+}
+
+func (p *Publisher) publish(topic, eventType string, data map[string]interface{}, userIDs []string) error {
 	if data == nil {
 		data = make(map[string]interface{})
 	}
+
+	// Validate against the declared schema version, or the latest one
+	version, _ := data[schema.VersionField].(int)
+	if err := p.registry.Validate(eventType, version, data); err != nil {
+		return fmt.Errorf("invalid event payload: %w", err)
+	}
+	if latest, ok := p.registry.Latest(eventType); ok && version == 0 {
+		data[schema.VersionField] = latest.Version
+	}
+
+	// Add metadata
 	data["service"] = p.serviceName
 	data["published_at"] = time.Now().UTC().Format(time.RFC3339)
 
 	// Prepare payload
 	payload := BroadcastPayload{
-		Topic: topic,
-		Type:  eventType,
-		Data:  data,
+		Topic:   topic,
+		Type:    eventType,
+		Data:    data,
+		UserIDs: userIDs,
 	}
 
 	// Marshal to JSON
@@ -81,11 +118,19 @@ func (p *Publisher) PublishEvent(topic, eventType string, data map[string]interf
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
-	// Prepare request
+	// Send, retrying when the gateway is unreachable or failing
 	url := fmt.Sprintf("%s/api/v1/events/broadcast", p.gatewayURL)
-	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	return retry.Do(context.Background(), p.retry, func(ctx context.Context) error {
+		return p.send(ctx, url, jsonData)
+	})
+}
+
+// send posts one broadcast to the gateway. Rejections other than rate
+// limiting and server errors are permanent.
+func (p *Publisher) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -98,7 +143,11 @@ func (p *Publisher) PublishEvent(topic, eventType string, data map[string]interf
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to publish event: status %d", resp.StatusCode)
+		err := fmt.Errorf("failed to publish event: status %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return retry.Permanent(err)
+		}
+		return err
 	}
 
 	return nil
@@ -115,6 +164,26 @@ func (p *Publisher) PublishEventAsync(topic, eventType string, data map[string]i
 	}()
 }
 
+// Publish publishes a typed payload after validating it against its schema.
+// userIDs name the users the event belongs to, so their per-user streams
+// receive it; users named in the payload's user_id fields are added by the broker.
+func (p *Publisher) Publish(topic string, payload Payload, userIDs ...string) error {
+	data, err := Encode(payload)
+	if err != nil {
+		return err
+	}
+	return p.publish(topic, payload.EventType(), data, userIDs)
+}
+
+// PublishAsync publishes a typed payload asynchronously (fire and forget).
+func (p *Publisher) PublishAsync(topic string, payload Payload, userIDs ...string) {
+	go func() {
+		if err := p.Publish(topic, payload, userIDs...); err != nil {
+			fmt.Printf("Failed to publish event %s/%s: %v\n", topic, payload.EventType(), err)
+		}
+	}()
+}
+
 // PublishTransactionEvent publishes a transaction-related event.
 func (p *Publisher) PublishTransactionEvent(eventType string, transactionID string, data map[string]interface{}) {
 	if data == nil {
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/retry"
+)
+
+func TestPublisher_RetriesTransientFailures(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"recovers after server errors", []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, false, 3},
+		{"retries rate limiting", []int{http.StatusTooManyRequests, http.StatusOK}, false, 2},
+		{"gives up after max attempts", []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}, true, 3},
+		{"does not retry rejections", []int{http.StatusBadRequest, http.StatusOK}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			p := NewPublisher(PublishConfig{GatewayURL: server.URL, ServiceName: "test", Retry: &policy})
+			err := p.PublishEvent("users", "user.custom", map[string]interface{}{"anything": true})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error = %v, got %v", tt.wantErr, err)
+			}
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Package schema describes versioned event payloads and validates them before
+// they are published. Schemas evolve by adding optional fields, so consumers
+// built against an older version can keep decoding newer events.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VersionField is the payload key that carries the schema version of an event.
+const VersionField = "schema_version"
+
+// Kind is the JSON type of a field.
+type Kind string
+
+const (
+	KindString  Kind = "string"
+	KindNumber  Kind = "number"
+	KindBoolean Kind = "boolean"
+	KindObject  Kind = "object"
+	KindArray   Kind = "array"
+	KindAny     Kind = "any"
+)
+
+// Field describes one payload field.
+type Field struct {
+	Name     string `json:"name"`
+	Kind     Kind   `json:"kind"`
+	Required bool   `json:"required"`
+}
+
+// Definition is the schema of one version of an event type.
+type Definition struct {
+	Type    string  `json:"type"`
+	Version int     `json:"version"`
+	Fields  []Field `json:"fields"`
+}
+
+// Field returns the named field.
+func (d Definition) Field(name string) (Field, bool) {
+	for _, f := range d.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Validate checks that data satisfies the definition. Fields the definition
+// does not know about are allowed, since publishers add metadata and newer
+// producers may add optional fields.
+func (d Definition) Validate(data map[string]interface{}) error {
+	var problems []string
+	for _, f := range d.Fields {
+		value, present := data[f.Name]
+		if !present || isNil(value) {
+			if f.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", f.Name))
+			}
+			continue
+		}
+		if got := kindOfValue(value); f.Kind != KindAny && got != f.Kind {
+			problems = append(problems, fmt.Sprintf("%s must be %s, got %s", f.Name, f.Kind, got))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s v%d: %s", d.Type, d.Version, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CheckCompatible reports whether next can replace prev without breaking
+// consumers of prev: every existing field keeps its kind, required fields stay
+// required, and new fields are optional.
+func CheckCompatible(prev, next Definition) error {
+	if prev.Type != next.Type {
+		return fmt.Errorf("cannot compare %s with %s", prev.Type, next.Type)
+	}
+	if next.Version <= prev.Version {
+		return fmt.Errorf("%s: version %d must be greater than %d", next.Type, next.Version, prev.Version)
+	}
+
+	for _, old := range prev.Fields {
+		f, ok := next.Field(old.Name)
+		if !ok {
+			return fmt.Errorf("%s v%d removes field %s", next.Type, next.Version, old.Name)
+		}
+		if f.Kind != old.Kind {
+			return fmt.Errorf("%s v%d changes %s from %s to %s", next.Type, next.Version, old.Name, old.Kind, f.Kind)
+		}
+		if old.Required && !f.Required {
+			return fmt.Errorf("%s v%d makes required field %s optional", next.Type, next.Version, old.Name)
+		}
+	}
+	for _, f := range next.Fields {
+		if _, ok := prev.Field(f.Name); !ok && f.Required {
+			return fmt.Errorf("%s v%d adds required field %s; new fields must be optional", next.Type, next.Version, f.Name)
+		}
+	}
+	return nil
+}
+
+// FromStruct derives a definition from a struct's json tags. Fields tagged
+// omitempty, and pointer fields, are optional.
+func FromStruct(eventType string, version int, v interface{}) Definition {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	def := Definition{Type: eventType, Version: version}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		def.Fields = append(def.Fields, Field{
+			Name:     name,
+			Kind:     kindOfType(sf.Type),
+			Required: !strings.Contains(opts, "omitempty") && sf.Type.Kind() != reflect.Pointer,
+		})
+	}
+	return def
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func kindOfType(t reflect.Type) Kind {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return KindString
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return KindString
+	case reflect.Bool:
+		return KindBoolean
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return KindNumber
+	case reflect.Slice, reflect.Array:
+		return KindArray
+	case reflect.Map, reflect.Struct:
+		return KindObject
+	default:
+		return KindAny
+	}
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+func kindOfValue(v interface{}) Kind {
+	return kindOfType(reflect.TypeOf(v))
+}
+
+// Registry holds every known version of each event type.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string][]Definition // ordered by version
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string][]Definition)}
+}
+
+// Default is the registry shared/events registers its typed payloads in.
+var Default = NewRegistry()
+
+// Register adds a definition. A new version must be compatible with the latest
+// registered version of the same type.
+func (r *Registry) Register(def Definition) error {
+	if def.Type == "" || def.Version < 1 {
+		return fmt.Errorf("definition needs a type and a version of at least 1")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := r.schemas[def.Type]
+	if len(versions) > 0 {
+		if err := CheckCompatible(versions[len(versions)-1], def); err != nil {
+			return err
+		}
+	}
+	r.schemas[def.Type] = append(versions, def)
+	return nil
+}
+
+// MustRegister registers definitions and panics on error. Use it at init time.
+func (r *Registry) MustRegister(defs ...Definition) {
+	for _, def := range defs {
+		if err := r.Register(def); err != nil {
+			panic(fmt.Sprintf("schema: %v", err))
+		}
+	}
+}
+
+// Lookup returns one version of an event type.
+func (r *Registry) Lookup(eventType string, version int) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, def := range r.schemas[eventType] {
+		if def.Version == version {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Latest returns the newest version of an event type.
+func (r *Registry) Latest(eventType string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := r.schemas[eventType]
+	if len(versions) == 0 {
+		return Definition{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+// Validate checks data against a version of an event type, or its latest
+// version when version is 0. Unregistered event types are not validated.
+func (r *Registry) Validate(eventType string, version int, data map[string]interface{}) error {
+	if _, known := r.Latest(eventType); !known {
+		return nil
+	}
+
+	var def Definition
+	var ok bool
+	if version == 0 {
+		def, ok = r.Latest(eventType)
+	} else {
+		def, ok = r.Lookup(eventType, version)
+	}
+	if !ok {
+		return fmt.Errorf("%s: unknown schema version %d", eventType, version)
+	}
+	return def.Validate(data)
+}
+
+// Definitions returns every registered definition, sorted by type and version.
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var defs []Definition
+	for _, versions := range r.schemas {
+		defs = append(defs, versions...)
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Type != defs[j].Type {
+			return defs[i].Type < defs[j].Type
+		}
+		return defs[i].Version < defs[j].Version
+	})
+	return defs
+}
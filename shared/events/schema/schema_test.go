@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+type paymentV1 struct {
+	ID     string `json:"id"`
+	Amount int64  `json:"amount"`
+	Note   string `json:"note,omitempty"`
+}
+
+type paymentV2 struct {
+	ID     string   `json:"id"`
+	Amount int64    `json:"amount"`
+	Note   string   `json:"note,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Payer  *string  `json:"payer"`
+}
+
+func TestFromStruct(t *testing.T) {
+	def := FromStruct("payment.made", 2, paymentV2{})
+
+	expected := map[string]Field{
+		"id":     {Name: "id", Kind: KindString, Required: true},
+		"amount": {Name: "amount", Kind: KindNumber, Required: true},
+		"note":   {Name: "note", Kind: KindString},
+		"tags":   {Name: "tags", Kind: KindArray},
+		"payer":  {Name: "payer", Kind: KindString},
+	}
+	if len(def.Fields) != len(expected) {
+		t.Fatalf("expected %d fields, got %+v", len(expected), def.Fields)
+	}
+	for _, f := range def.Fields {
+		if f != expected[f.Name] {
+			t.Errorf("field %s = %+v, want %+v", f.Name, f, expected[f.Name])
+		}
+	}
+}
+
+func TestDefinition_Validate(t *testing.T) {
+	def := FromStruct("payment.made", 1, paymentV1{})
+	var nilNote *string
+
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr string
+	}{
+		{"valid", map[string]interface{}{"id": "p1", "amount": int64(100)}, ""},
+		{"json numbers", map[string]interface{}{"id": "p1", "amount": float64(100)}, ""},
+		{"unknown fields allowed", map[string]interface{}{"id": "p1", "amount": 1, "service": "wallet"}, ""},
+		{"nil pointer optional", map[string]interface{}{"id": "p1", "amount": 1, "note": nilNote}, ""},
+		{"missing required", map[string]interface{}{"id": "p1"}, "amount is required"},
+		{"wrong kind", map[string]interface{}{"id": 7, "amount": 1}, "id must be string, got number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := def.Validate(tt.data)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckCompatible(t *testing.T) {
+	v1 := FromStruct("payment.made", 1, paymentV1{})
+
+	if err := CheckCompatible(v1, FromStruct("payment.made", 2, paymentV2{})); err != nil {
+		t.Errorf("expected added optional fields to be compatible: %v", err)
+	}
+
+	breaking := map[string]Definition{
+		"same version":   FromStruct("payment.made", 1, paymentV2{}),
+		"removed field":  {Type: "payment.made", Version: 2, Fields: []Field{{Name: "id", Kind: KindString, Required: true}}},
+		"changed kind":   {Type: "payment.made", Version: 2, Fields: []Field{{Name: "id", Kind: KindNumber, Required: true}, {Name: "amount", Kind: KindNumber, Required: true}, {Name: "note", Kind: KindString}}},
+		"added required": {Type: "payment.made", Version: 2, Fields: append(append([]Field(nil), v1.Fields...), Field{Name: "payer", Kind: KindString, Required: true})},
+		"relaxed field":  {Type: "payment.made", Version: 2, Fields: []Field{{Name: "id", Kind: KindString}, {Name: "amount", Kind: KindNumber, Required: true}, {Name: "note", Kind: KindString}}},
+	}
+	for name, next := range breaking {
+		if err := CheckCompatible(v1, next); err == nil {
+			t.Errorf("%s: expected incompatibility", name)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister(FromStruct("payment.made", 1, paymentV1{}), FromStruct("payment.made", 2, paymentV2{}))
+
+	if err := r.Register(FromStruct("payment.made", 3, paymentV1{})); err == nil {
+		t.Error("expected registry to reject a version that drops fields")
+	}
+	if latest, _ := r.Latest("payment.made"); latest.Version != 2 {
+		t.Errorf("expected latest version 2, got %d", latest.Version)
+	}
+
+	if err := r.Validate("payment.made", 0, map[string]interface{}{"id": "p1", "amount": 1, "tags": "x"}); err == nil {
+		t.Error("expected latest schema to reject a string for tags")
+	}
+	if err := r.Validate("payment.made", 1, map[string]interface{}{"id": "p1", "amount": 1, "tags": "x"}); err != nil {
+		t.Errorf("expected v1 to ignore fields it does not know: %v", err)
+	}
+	if err := r.Validate("payment.made", 9, map[string]interface{}{}); err == nil {
+		t.Error("expected unknown version to be rejected")
+	}
+	if err := r.Validate("something.else", 0, map[string]interface{}{}); err != nil {
+		t.Errorf("expected unregistered types to pass: %v", err)
+	}
+	if defs := r.Definitions(); len(defs) != 2 || defs[0].Version != 1 {
+		t.Errorf("unexpected definitions: %+v", defs)
+	}
+}
@@ -0,0 +1,376 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LevelController holds the log levels of a group of loggers and lets them be
+// changed while the service runs. Loggers check it on every event, so a
+// change applies at once to loggers already handed out.
+//
+// A module is a logger's name, such as "wallet" or "wallet.upi". A module
+// without an override of its own uses the override of its nearest dotted
+// parent, then the default level.
+type LevelController struct {
+	level   atomic.Int32 // zerolog.Level
+	mu      sync.RWMutex
+	modules map[string]zerolog.Level
+	hasMods atomic.Bool // Skips the lock when there are no overrides
+
+	sampling atomic.Pointer[debugSampler]
+	dropped  atomic.Uint64 // Debug events dropped by sampling
+
+	generation atomic.Uint64 // Bumped on every change, so a timed revert only undoes its own
+}
+
+// NewLevelController creates a controller with a default level. An unknown
+// level falls back to info.
+func NewLevelController(level string) *LevelController {
+	c := &LevelController{modules: make(map[string]zerolog.Level)}
+	c.level.Store(int32(parseLevel(level)))
+	return c
+}
+
+var defaultController = NewLevelController("info")
+
+// DefaultController returns the process-wide controller used by NewDefault
+// and NewFromEnv, and by New when Config.Controller is not set.
+func DefaultController() *LevelController {
+	return defaultController
+}
+
+// Level returns the default level.
+func (c *LevelController) Level() string {
+	return zerolog.Level(c.level.Load()).String()
+}
+
+// SetLevel changes the default level.
+func (c *LevelController) SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	c.level.Store(int32(parsed))
+	c.generation.Add(1)
+	return nil
+}
+
+// SetModuleLevel overrides the level of a module and its children.
+func (c *LevelController) SetModuleLevel(module, level string) error {
+	if module == "" {
+		return fmt.Errorf("module name is required")
+	}
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.modules[module] = parsed
+	c.hasMods.Store(true)
+	c.mu.Unlock()
+	c.generation.Add(1)
+	return nil
+}
+
+// ClearModuleLevel removes a module's override.
+func (c *LevelController) ClearModuleLevel(module string) {
+	c.mu.Lock()
+	delete(c.modules, module)
+	c.hasMods.Store(len(c.modules) > 0)
+	c.mu.Unlock()
+	c.generation.Add(1)
+}
+
+// Spec returns the current default level and module overrides.
+func (c *LevelController) Spec() LevelSpec {
+	spec := LevelSpec{Level: c.Level()}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if len(c.modules) > 0 {
+		spec.Modules = make(map[string]string, len(c.modules))
+		for module, level := range c.modules {
+			spec.Modules[module] = level.String()
+		}
+	}
+	return spec
+}
+
+// Apply replaces the default level and all module overrides with spec. An
+// empty spec level keeps the current default.
+func (c *LevelController) Apply(spec LevelSpec) error {
+	level := zerolog.Level(c.level.Load())
+	if spec.Level != "" {
+		parsed, err := ParseLevel(spec.Level)
+		if err != nil {
+			return err
+		}
+		level = parsed
+	}
+	modules := make(map[string]zerolog.Level, len(spec.Modules))
+	for module, name := range spec.Modules {
+		if module == "" {
+			return fmt.Errorf("module name is required")
+		}
+		parsed, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+		modules[module] = parsed
+	}
+
+	c.mu.Lock()
+	c.level.Store(int32(level))
+	c.modules = modules
+	c.hasMods.Store(len(modules) > 0)
+	c.mu.Unlock()
+	c.generation.Add(1)
+	return nil
+}
+
+// ApplyFor applies spec and restores the levels it replaced after d, unless
+// they were changed again in the meantime. Use it to turn on debug logging
+// for a while without having to remember to turn it off.
+func (c *LevelController) ApplyFor(spec LevelSpec, d time.Duration) error {
+	previous := c.Spec()
+	if err := c.Apply(spec); err != nil {
+		return err
+	}
+	applied := c.generation.Load()
+
+	time.AfterFunc(d, func() {
+		if c.generation.Load() == applied {
+			_ = c.Apply(previous)
+		}
+	})
+	return nil
+}
+
+// levelFor returns the level in effect for a module.
+func (c *LevelController) levelFor(module string) zerolog.Level {
+	if module != "" && c.hasMods.Load() {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		for name := module; ; {
+			if level, ok := c.modules[name]; ok {
+				return level
+			}
+			i := strings.LastIndexByte(name, '.')
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+	return zerolog.Level(c.level.Load())
+}
+
+// Enabled reports whether a module logs events of a level, before sampling.
+func (c *LevelController) Enabled(module string, level zerolog.Level) bool {
+	return level >= c.levelFor(module)
+}
+
+// ========================================================================
+// Debug Sampling
+// ========================================================================
+
+// DebugSampling limits debug logging under load: in each second the first
+// Burst debug events are logged, and after that only one in Every. Info and
+// above are never sampled. A zero Burst turns sampling off.
+type DebugSampling struct {
+	Burst int `json:"burst"`
+	Every int `json:"every"`
+}
+
+// debugSampler is the sampler behind a DebugSampling.
+type debugSampler struct {
+	config  DebugSampling
+	sampler zerolog.Sampler
+}
+
+// SetDebugSampling changes how debug events are sampled.
+func (c *LevelController) SetDebugSampling(sampling DebugSampling) error {
+	if sampling.Burst < 0 || sampling.Every < 0 {
+		return fmt.Errorf("debug sampling burst and every must not be negative")
+	}
+	if sampling.Burst == 0 {
+		c.sampling.Store(nil)
+		return nil
+	}
+	if sampling.Every == 0 {
+		sampling.Every = 1
+	}
+
+	c.sampling.Store(&debugSampler{
+		config: sampling,
+		sampler: &zerolog.BurstSampler{
+			Burst:       uint32(sampling.Burst),
+			Period:      time.Second,
+			NextSampler: &zerolog.BasicSampler{N: uint32(sampling.Every)},
+		},
+	})
+	return nil
+}
+
+// DebugSampling returns how debug events are sampled.
+func (c *LevelController) DebugSampling() DebugSampling {
+	if s := c.sampling.Load(); s != nil {
+		return s.config
+	}
+	return DebugSampling{}
+}
+
+// DroppedDebug returns how many debug events sampling has dropped.
+func (c *LevelController) DroppedDebug() uint64 {
+	return c.dropped.Load()
+}
+
+// allow decides whether an event is written.
+func (c *LevelController) allow(module string, level zerolog.Level) bool {
+	if !c.Enabled(module, level) {
+		return false
+	}
+	if level > zerolog.DebugLevel {
+		return true
+	}
+	if s := c.sampling.Load(); s != nil && !s.sampler.Sample(level) {
+		c.dropped.Add(1)
+		return false
+	}
+	return true
+}
+
+// levelHook drops the events a controller does not allow for a module.
+type levelHook struct {
+	controller *LevelController
+	module     string
+}
+
+// Run implements zerolog.Hook.
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if !h.controller.allow(h.module, level) {
+		e.Discard()
+	}
+}
+
+// ========================================================================
+// Level Specs
+// ========================================================================
+
+// LevelSpec is a default level with per-module overrides.
+type LevelSpec struct {
+	Level   string            `json:"level,omitempty"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// ParseLevelSpec parses a spec such as "info,wallet.upi=debug,ledger=warn":
+// an optional default level followed by module=level overrides.
+func ParseLevelSpec(s string) (LevelSpec, error) {
+	var spec LevelSpec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		module, level, isOverride := strings.Cut(part, "=")
+		if !isOverride {
+			if _, err := ParseLevel(part); err != nil {
+				return LevelSpec{}, err
+			}
+			spec.Level = part
+			continue
+		}
+
+		module, level = strings.TrimSpace(module), strings.TrimSpace(level)
+		if module == "" {
+			return LevelSpec{}, fmt.Errorf("missing module name in %q", part)
+		}
+		if _, err := ParseLevel(level); err != nil {
+			return LevelSpec{}, fmt.Errorf("module %s: %w", module, err)
+		}
+		if spec.Modules == nil {
+			spec.Modules = make(map[string]string)
+		}
+		spec.Modules[module] = level
+	}
+	return spec, nil
+}
+
+// String formats the spec as ParseLevelSpec reads it, overrides sorted by module.
+func (s LevelSpec) String() string {
+	parts := make([]string, 0, len(s.Modules)+1)
+	if s.Level != "" {
+		parts = append(parts, s.Level)
+	}
+	modules := make([]string, 0, len(s.Modules))
+	for module := range s.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		parts = append(parts, module+"="+s.Modules[module])
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseLevel converts a level name to a zerolog.Level, rejecting unknown names.
+func ParseLevel(level string) (zerolog.Level, error) {
+	switch level {
+	case "debug", "info", "warn", "error", "fatal":
+		return parseLevel(level), nil
+	default:
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q: must be debug, info, warn, error or fatal", level)
+	}
+}
+
+// configureFromEnv applies LOG_LEVEL, LOG_LEVELS (module overrides, e.g.
+// "wallet.upi=debug,ledger=warn") and LOG_DEBUG_SAMPLE_BURST and
+// LOG_DEBUG_SAMPLE_EVERY to a controller. Invalid values are reported and
+// leave the setting unchanged.
+func configureFromEnv(c *LevelController, level string) []error {
+	var errs []error
+	if _, err := ParseLevel(level); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+		level = "info"
+	}
+	spec := LevelSpec{Level: level}
+	if levels := os.Getenv("LOG_LEVELS"); levels != "" {
+		overrides, err := ParseLevelSpec(levels)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("LOG_LEVELS: %w", err))
+		} else {
+			spec.Modules = overrides.Modules
+		}
+	}
+	_ = c.Apply(spec) // Both parts were validated above
+
+	if burst := os.Getenv("LOG_DEBUG_SAMPLE_BURST"); burst != "" {
+		sampling := DebugSampling{Every: 100}
+		var err error
+		if sampling.Burst, err = strconv.Atoi(burst); err != nil {
+			errs = append(errs, fmt.Errorf("LOG_DEBUG_SAMPLE_BURST: %w", err))
+			return errs
+		}
+		if every := os.Getenv("LOG_DEBUG_SAMPLE_EVERY"); every != "" {
+			if sampling.Every, err = strconv.Atoi(every); err != nil {
+				errs = append(errs, fmt.Errorf("LOG_DEBUG_SAMPLE_EVERY: %w", err))
+				return errs
+			}
+		}
+		if err := c.SetDebugSampling(sampling); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
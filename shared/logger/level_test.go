@@ -0,0 +1,191 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func newTestLogger(controller *LevelController) (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return New(Config{Format: "json", ServiceName: "wallet", Output: &buf, Controller: controller}), &buf
+}
+
+func TestLevelController_RuntimeChange(t *testing.T) {
+	controller := NewLevelController("info")
+	log, buf := newTestLogger(controller)
+	child := log.WithField("wallet_id", "w-1")
+
+	child.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug to be off, got %s", buf.String())
+	}
+
+	// Loggers already handed out follow the change
+	if err := controller.SetLevel("debug"); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	child.Debug("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("expected debug after the change, got %s", buf.String())
+	}
+
+	if err := controller.SetLevel("verbose"); err == nil {
+		t.Error("expected an unknown level to be rejected")
+	}
+}
+
+func TestLevelController_ModuleOverrides(t *testing.T) {
+	controller := NewLevelController("warn")
+	log, buf := newTestLogger(controller)
+	upi := log.Module("upi")
+
+	if err := controller.SetModuleLevel("wallet.upi", "debug"); err != nil {
+		t.Fatalf("SetModuleLevel: %v", err)
+	}
+
+	log.Info("service info")
+	upi.Debug("upi debug")
+	upi.Module("callbacks").Debug("callback debug")
+
+	out := buf.String()
+	if strings.Contains(out, "service info") {
+		t.Error("expected the service logger to keep the default level")
+	}
+	if !strings.Contains(out, "upi debug") || !strings.Contains(out, `"module":"wallet.upi"`) {
+		t.Errorf("expected the module override to apply, got %s", out)
+	}
+	if !strings.Contains(out, "callback debug") {
+		t.Errorf("expected a child module to inherit the override, got %s", out)
+	}
+
+	controller.ClearModuleLevel("wallet.upi")
+	buf.Reset()
+	upi.Debug("upi debug")
+	if buf.Len() != 0 {
+		t.Errorf("expected the default level after clearing the override, got %s", buf.String())
+	}
+}
+
+func TestLevelController_HookFiltersZerolog(t *testing.T) {
+	controller := NewLevelController("error")
+	log, buf := newTestLogger(controller)
+
+	zlog := log.GetZerologLogger()
+	zlog.Info().Msg("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("expected the underlying logger to respect the controller, got %s", buf.String())
+	}
+	zlog.Error().Msg("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("expected error to be logged, got %s", buf.String())
+	}
+}
+
+func TestLevelController_ApplyFor(t *testing.T) {
+	controller := NewLevelController("info")
+	if err := controller.ApplyFor(LevelSpec{Level: "debug", Modules: map[string]string{"ledger": "error"}}, 20*time.Millisecond); err != nil {
+		t.Fatalf("ApplyFor: %v", err)
+	}
+	if controller.Level() != "debug" || controller.levelFor("ledger.events") != zerolog.ErrorLevel {
+		t.Fatalf("expected the spec to apply, got %s", controller.Spec())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for controller.Level() != "info" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if spec := controller.Spec(); spec.String() != "info" {
+		t.Errorf("expected the levels to revert, got %s", spec)
+	}
+
+	// A later change is not undone by an earlier timer
+	if err := controller.ApplyFor(LevelSpec{Level: "debug"}, 20*time.Millisecond); err != nil {
+		t.Fatalf("ApplyFor: %v", err)
+	}
+	if err := controller.SetLevel("warn"); err != nil {
+		t.Fatalf("SetLevel: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if controller.Level() != "warn" {
+		t.Errorf("expected the later change to stay, got %s", controller.Level())
+	}
+}
+
+func TestLevelController_DebugSampling(t *testing.T) {
+	controller := NewLevelController("debug")
+	log, buf := newTestLogger(controller)
+
+	if err := controller.SetDebugSampling(DebugSampling{Burst: 2, Every: 1000}); err != nil {
+		t.Fatalf("SetDebugSampling: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		log.Debug("noisy")
+		log.Info("important")
+	}
+
+	out := buf.String()
+	// The basic sampler keeps its first event, so one more debug line gets through
+	if got := strings.Count(out, "noisy"); got > 3 || got < 2 {
+		t.Errorf("expected debug to be sampled, got %d lines", got)
+	}
+	if got := strings.Count(out, "important"); got != 10 {
+		t.Errorf("expected info not to be sampled, got %d lines", got)
+	}
+	if controller.DroppedDebug() < 7 {
+		t.Errorf("expected dropped events to be counted, got %d", controller.DroppedDebug())
+	}
+
+	if err := controller.SetDebugSampling(DebugSampling{}); err != nil || controller.DebugSampling() != (DebugSampling{}) {
+		t.Errorf("expected sampling to be turned off, got %+v (%v)", controller.DebugSampling(), err)
+	}
+	if err := controller.SetDebugSampling(DebugSampling{Burst: -1}); err == nil {
+		t.Error("expected a negative burst to be rejected")
+	}
+}
+
+func TestParseLevelSpec(t *testing.T) {
+	spec, err := ParseLevelSpec(" info, wallet.upi=debug ,ledger=warn")
+	if err != nil {
+		t.Fatalf("ParseLevelSpec: %v", err)
+	}
+	if spec.Level != "info" || spec.Modules["wallet.upi"] != "debug" || spec.Modules["ledger"] != "warn" {
+		t.Errorf("unexpected spec: %+v", spec)
+	}
+	if got := spec.String(); got != "info,ledger=warn,wallet.upi=debug" {
+		t.Errorf("String() = %q", got)
+	}
+
+	for _, invalid := range []string{"loud", "wallet=loud", "=debug"} {
+		if _, err := ParseLevelSpec(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVELS", "wallet.upi=debug")
+	t.Setenv("LOG_DEBUG_SAMPLE_BURST", "50")
+
+	controller := NewLevelController("info")
+	if errs := configureFromEnv(controller, "warn"); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if spec := controller.Spec().String(); spec != "warn,wallet.upi=debug" {
+		t.Errorf("unexpected levels: %s", spec)
+	}
+	if sampling := controller.DebugSampling(); sampling != (DebugSampling{Burst: 50, Every: 100}) {
+		t.Errorf("unexpected sampling: %+v", sampling)
+	}
+
+	t.Setenv("LOG_LEVELS", "wallet=loud")
+	if errs := configureFromEnv(controller, "verbose"); len(errs) != 2 {
+		t.Errorf("expected both invalid settings to be reported, got %v", errs)
+	}
+	if spec := controller.Spec().String(); spec != "info" {
+		t.Errorf("expected the defaults for invalid settings, got %s", spec)
+	}
+}
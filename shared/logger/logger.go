@@ -24,7 +24,9 @@ const (
 
 // Logger wraps zerolog.Logger with additional functionality.
 type Logger struct {
-	logger zerolog.Logger
+	logger zerolog.Logger // raw with the level hook
+	raw    zerolog.Logger // Fields only; derived loggers start from it so hooks do not stack
+	hook   levelHook
 }
 
 // Config holds logger configuration.
@@ -33,6 +35,10 @@ type Config struct {
 	Format      string // console, json
 	ServiceName string
 	Output      io.Writer
+
+	// Controller holds the levels the logger checks on every event. If nil,
+	// the logger gets a controller of its own set to Level.
+	Controller *LevelController
 }
 
 // New creates a new Logger instance with the given configuration.
@@ -54,32 +60,48 @@ func New(cfg Config) *Logger {
 		}
 	}
 
+	// Levels are enforced by the controller, so zerolog passes everything on
 	zlog = zerolog.New(output).With().
 		Timestamp().
 		Str("service", cfg.ServiceName).
-		Logger()
-
-	// Set log level
-	level := parseLevel(cfg.Level)
-	zlog = zlog.Level(level)
+		Logger().
+		Level(zerolog.DebugLevel)
 
-	return &Logger{
-		logger: zlog,
+	controller := cfg.Controller
+	if controller == nil {
+		controller = NewLevelController(cfg.Level)
 	}
+
+	return newLogger(zlog, levelHook{controller: controller, module: cfg.ServiceName})
+}
+
+// newLogger returns a Logger writing raw's events through hook.
+func newLogger(raw zerolog.Logger, hook levelHook) *Logger {
+	return &Logger{logger: raw.Hook(hook), raw: raw, hook: hook}
 }
 
-// NewDefault creates a logger with default configuration.
+// derive returns a logger with the same levels writing through raw.
+func (l *Logger) derive(raw zerolog.Logger) *Logger {
+	return newLogger(raw, l.hook)
+}
+
+// NewDefault creates a console logger on the process-wide level controller,
+// so its level follows LOG_LEVEL and runtime changes.
 func NewDefault(serviceName string) *Logger {
 	return New(Config{
-		Level:       "info",
 		Format:      "console",
 		ServiceName: serviceName,
+		Controller:  DefaultController(),
 	})
 }
 
 // NewFromEnv creates a logger based on environment variables.
 // Uses LOG_LEVEL (default: info) and LOG_FORMAT (default: json in production, console otherwise).
 // Environment is determined by ENV or ENVIRONMENT variable.
+//
+// The levels are set on the process-wide controller, which also takes module
+// overrides from LOG_LEVELS and debug sampling from LOG_DEBUG_SAMPLE_BURST
+// and LOG_DEBUG_SAMPLE_EVERY.
 func NewFromEnv(serviceName string) *Logger {
 	level := os.Getenv("LOG_LEVEL")
 	if level == "" {
@@ -100,11 +122,16 @@ func NewFromEnv(serviceName string) *Logger {
 		}
 	}
 
-	return New(Config{
-		Level:       level,
+	errs := configureFromEnv(DefaultController(), level)
+	log := New(Config{
 		Format:      format,
 		ServiceName: serviceName,
+		Controller:  DefaultController(),
 	})
+	for _, err := range errs {
+		log.WithError(err).Warn("Ignoring invalid log configuration")
+	}
+	return log
 }
 
 // parseLevel converts string level to zerolog.Level.
@@ -127,7 +154,7 @@ func parseLevel(level string) zerolog.Level {
 
 // WithContext returns a new logger with context values added.
 func (l *Logger) WithContext(ctx context.Context) *Logger {
-	logger := l.logger
+	logger := l.raw
 
 	// Add request ID if present
 	if requestID := ctx.Value(RequestIDKey); requestID != nil {
@@ -144,23 +171,21 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 		logger = logger.With().Str("correlation_id", correlationID.(string)).Logger()
 	}
 
-	return &Logger{logger: logger}
+	return l.derive(logger)
 }
 
 // With returns a new logger with additional fields.
 func (l *Logger) With(fields map[string]interface{}) *Logger {
-	logger := l.logger.With()
+	logger := l.raw.With()
 	for k, v := range fields {
 		logger = logger.Interface(k, v)
 	}
-	return &Logger{logger: logger.Logger()}
+	return l.derive(logger.Logger())
 }
 
 // WithField returns a new logger with a single field added.
 func (l *Logger) WithField(key string, value interface{}) *Logger {
-	return &Logger{
-		logger: l.logger.With().Interface(key, value).Logger(),
-	}
+	return l.derive(l.raw.With().Interface(key, value).Logger())
 }
 
 // WithError returns a new logger with error field added.
@@ -168,49 +193,72 @@ func (l *Logger) WithError(err error) *Logger {
 	if err == nil {
 		return l
 	}
-	return &Logger{
-		logger: l.logger.With().Err(err).Logger(),
+	return l.derive(l.raw.With().Err(err).Logger())
+}
+
+// Module returns a logger for a part of the service, named below this
+// logger's module (e.g. "wallet" becomes "wallet.upi"). Its level can be
+// overridden on its own.
+func (l *Logger) Module(name string) *Logger {
+	module := name
+	if l.hook.module != "" {
+		module = l.hook.module + "." + name
+	}
+	return newLogger(l.raw.With().Str("module", module).Logger(), levelHook{controller: l.hook.controller, module: module})
+}
+
+// Controller returns the controller holding the logger's levels.
+func (l *Logger) Controller() *LevelController {
+	return l.hook.controller
+}
+
+// event starts an event of a level, or returns nil (on which zerolog calls
+// are no-ops) when the level is off, so disabled messages are not built.
+func (l *Logger) event(level zerolog.Level) *zerolog.Event {
+	if !l.hook.controller.Enabled(l.hook.module, level) {
+		return nil
 	}
+	return l.logger.WithLevel(level)
 }
 
 // Debug logs a debug level message.
 func (l *Logger) Debug(msg string) {
-	l.logger.Debug().Msg(msg)
+	l.event(zerolog.DebugLevel).Msg(msg)
 }
 
 // Debugf logs a formatted debug level message.
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	l.logger.Debug().Msgf(format, args...)
+	l.event(zerolog.DebugLevel).Msgf(format, args...)
 }
 
 // Info logs an info level message.
 func (l *Logger) Info(msg string) {
-	l.logger.Info().Msg(msg)
+	l.event(zerolog.InfoLevel).Msg(msg)
 }
 
 // Infof logs a formatted info level message.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.logger.Info().Msgf(format, args...)
+	l.event(zerolog.InfoLevel).Msgf(format, args...)
 }
 
 // Warn logs a warning level message.
 func (l *Logger) Warn(msg string) {
-	l.logger.Warn().Msg(msg)
+	l.event(zerolog.WarnLevel).Msg(msg)
 }
 
 // Warnf logs a formatted warning level message.
 func (l *Logger) Warnf(format string, args ...interface{}) {
-	l.logger.Warn().Msgf(format, args...)
+	l.event(zerolog.WarnLevel).Msgf(format, args...)
 }
 
 // Error logs an error level message.
 func (l *Logger) Error(msg string) {
-	l.logger.Error().Msg(msg)
+	l.event(zerolog.ErrorLevel).Msg(msg)
 }
 
 // Errorf logs a formatted error level message.
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.logger.Error().Msgf(format, args...)
+	l.event(zerolog.ErrorLevel).Msgf(format, args...)
 }
 
 // Fatal logs a fatal level message and exits.
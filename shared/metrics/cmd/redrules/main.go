@@ -0,0 +1,61 @@
+// Command redrules generates Prometheus RED (rate, errors, duration)
+// recording rules for the Nivo services.
+//
+//	go run ./shared/metrics/cmd/redrules -o monitoring/prometheus/recording_rules.yml
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/metrics"
+)
+
+const header = `# =============================================================================
+# Nivo - Prometheus RED Recording Rules
+# =============================================================================
+#
+# Generated by shared/metrics/cmd/redrules (make metrics-rules). Do not edit.
+#
+# =============================================================================
+
+`
+
+// defaultServices are the services scraped in monitoring/prometheus/prometheus.yml.
+var defaultServices = []string{
+	"gateway", "identity", "ledger", "rbac", "wallet", "transaction",
+	"risk", "notification", "simulation",
+}
+
+func main() {
+	services := flag.String("services", strings.Join(defaultServices, ","), "comma-separated services to generate rules for")
+	window := flag.Duration("window", 5*time.Minute, "rate window")
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	var names []string
+	for _, name := range strings.Split(*services, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	rules, err := metrics.REDRuleFile(names, metrics.REDOptions{Window: *window})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	content := append([]byte(header), rules...)
+
+	if *output == "" {
+		_, _ = os.Stdout.Write(content)
+		return
+	}
+	if err := os.WriteFile(*output, content, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ExemplarLabel is the exemplar label carrying the trace ID.
+const ExemplarLabel = "trace_id"
+
+// maxTraceIDLength keeps exemplar labels well under the 128 rune limit
+// OpenMetrics places on them.
+const maxTraceIDLength = 64
+
+// TraceIDFunc extracts the trace ID to attach to a request's observations.
+// An empty string records the observation without an exemplar.
+type TraceIDFunc func(r *http.Request) string
+
+// DefaultTraceID returns the trace ID from a W3C traceparent header, falling
+// back to the request ID so latency outliers can be matched to log lines.
+func DefaultTraceID(r *http.Request) string {
+	if traceID := traceIDFromTraceparent(r.Header.Get("traceparent")); traceID != "" {
+		return traceID
+	}
+	return requestid.FromRequest(r)
+}
+
+// traceIDFromTraceparent returns the trace ID of a version-00 traceparent
+// header (00-<trace-id>-<parent-id>-<flags>), or an empty string.
+func traceIDFromTraceparent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if strings.Trim(traceID, "0") == "" {
+		return "" // All-zero trace IDs are invalid
+	}
+	for _, c := range traceID {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return ""
+		}
+	}
+	return traceID
+}
+
+// exemplarLabels returns the exemplar for a trace ID, or nil if it cannot be
+// used as an exemplar label.
+func exemplarLabels(traceID string) prometheus.Labels {
+	if traceID == "" || len(traceID) > maxTraceIDLength || !utf8.ValidString(traceID) {
+		return nil
+	}
+	return prometheus.Labels{ExemplarLabel: traceID}
+}
+
+// observe records value, attaching the exemplar when there is one.
+func observe(observer prometheus.Observer, value float64, exemplar prometheus.Labels) {
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok && exemplar != nil {
+		eo.ObserveWithExemplar(value, exemplar)
+		return
+	}
+	observer.Observe(value)
+}
+
+// increment adds one to counter, attaching the exemplar when there is one.
+func increment(counter prometheus.Counter, exemplar prometheus.Labels) {
+	if ea, ok := counter.(prometheus.ExemplarAdder); ok && exemplar != nil {
+		ea.AddWithExemplar(1, exemplar)
+		return
+	}
+	counter.Inc()
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Standard label names used by the HTTP metrics. Dashboards and the RED
+// recording rules aggregate on these.
+const (
+	LabelService  = "service"
+	LabelMethod   = "method"
+	LabelEndpoint = "endpoint" // Templated route, such as /api/v1/wallets/{id}
+	LabelStatus   = "status"
+)
+
+// Placeholder values that keep the endpoint and method labels bounded.
+const (
+	// UnmatchedEndpoint labels requests no route matched, so scanners probing
+	// random paths do not each create a series.
+	UnmatchedEndpoint = "unmatched"
+
+	// OtherMethod labels requests with a non-standard HTTP method.
+	OtherMethod = "OTHER"
+
+	// idSegment replaces path segments that look like identifiers.
+	idSegment = "{id}"
+)
+
+// knownMethods are the HTTP methods kept as label values.
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// routeKey is the context key for the route recorded by RecordRoute.
+type routeKey struct{}
+
+// routeHolder receives the pattern matched by an inner ServeMux.
+type routeHolder struct {
+	pattern string
+}
+
+// RecordRoute wraps a ServeMux so the metrics middleware can label requests
+// with the matched pattern even when other middleware sits between the two.
+// Middleware that wraps the mux directly does not need it.
+func RecordRoute(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if holder, ok := r.Context().Value(routeKey{}).(*routeHolder); ok {
+			holder.pattern = r.Pattern
+		}
+	})
+}
+
+// withRouteHolder returns a copy of ctx that RecordRoute can report into.
+func withRouteHolder(ctx context.Context) (context.Context, *routeHolder) {
+	holder := &routeHolder{}
+	return context.WithValue(ctx, routeKey{}, holder), holder
+}
+
+// EndpointLabel returns the endpoint label for a request: the path of the
+// matched ServeMux pattern, or the normalized URL path for catch-all patterns
+// such as the gateway's proxy routes. Unmatched requests that got a 404 share
+// one label.
+func EndpointLabel(pattern, path string, status int) string {
+	route := patternPath(pattern)
+	if route != "" && !isCatchAll(route) {
+		return route
+	}
+	if route == "" && status == http.StatusNotFound {
+		return UnmatchedEndpoint
+	}
+	return NormalizePath(path)
+}
+
+// MethodLabel returns the method label, folding non-standard methods.
+func MethodLabel(method string) string {
+	if knownMethods[method] {
+		return method
+	}
+	return OtherMethod
+}
+
+// NormalizePath replaces path segments that look like identifiers (UUIDs,
+// numbers and long tokens) with {id}, so /api/v1/wallets/7f3c.../balance
+// becomes /api/v1/wallets/{id}/balance.
+func NormalizePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if looksLikeID(segment) {
+			segments[i] = idSegment
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// patternPath strips the method and host from a ServeMux pattern.
+func patternPath(pattern string) string {
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		return pattern[i:]
+	}
+	return ""
+}
+
+// isCatchAll reports whether a pattern path matches a whole subtree.
+func isCatchAll(route string) bool {
+	return strings.HasSuffix(route, "/") || strings.HasSuffix(route, "...}")
+}
+
+// looksLikeID reports whether a path segment is an identifier rather than a
+// fixed part of the route.
+func looksLikeID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+
+	digits, letters := 0, 0
+	for _, c := range segment {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+			letters++
+		case c == '-' || c == '_':
+		default:
+			return false
+		}
+	}
+
+	switch {
+	case letters == 0 && digits > 0:
+		// Numbers and UUID-like strings of digits and dashes
+		return true
+	case len(segment) >= 16 && digits > 0:
+		// UUIDs, hex IDs and generated tokens
+		return true
+	default:
+		return false
+	}
+}
@@ -1,8 +1,10 @@
 package metrics
 
 import (
+	"database/sql"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,22 +28,48 @@ type Collector struct {
 	RiskEventsTotal       *prometheus.CounterVec
 
 	// System Metrics
-	DBConnectionsActive prometheus.Gauge
-	DBQueryDuration     *prometheus.HistogramVec
-	CacheHitsTotal      *prometheus.CounterVec
-	CacheMissesTotal    *prometheus.CounterVec
+	DBConnectionsActive     prometheus.Gauge // Connections in use
+	DBConnectionsIdle       prometheus.Gauge
+	DBConnectionsMaxOpen    prometheus.Gauge
+	DBConnectionWaits       prometheus.Gauge // Cumulative waits for a free connection
+	DBConnectionWaitSeconds prometheus.Gauge // Cumulative time spent waiting
+	DBQueryDuration         *prometheus.HistogramVec
+	DBSlowQueriesTotal      *prometheus.CounterVec
+	CacheHitsTotal          *prometheus.CounterVec
+	CacheMissesTotal        *prometheus.CounterVec
+
+	// traceID links HTTP observations to traces through exemplars
+	traceID TraceIDFunc
+}
+
+// defaultCollector is the collector created in this process, see Default.
+var defaultCollector atomic.Pointer[Collector]
+
+// Default returns the collector created in this process, or nil if none has
+// been created yet. Metrics register globally, so a process creates one
+// collector; Default lets shared bootstrapping code report to it.
+func Default() *Collector {
+	return defaultCollector.Load()
 }
 
 // NewCollector creates a new metrics collector for a service
 func NewCollector(serviceName string) *Collector {
+	c := newCollector()
+	defaultCollector.Store(c)
+	return c
+}
+
+func newCollector() *Collector {
 	return &Collector{
+		traceID: DefaultTraceID,
+
 		// HTTP Metrics
 		HTTPRequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "http_requests_total",
 				Help: "Total number of HTTP requests",
 			},
-			[]string{"service", "method", "endpoint", "status"},
+			[]string{LabelService, LabelMethod, LabelEndpoint, LabelStatus},
 		),
 		HTTPRequestDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -49,7 +77,7 @@ func NewCollector(serviceName string) *Collector {
 				Help:    "HTTP request duration in seconds",
 				Buckets: prometheus.DefBuckets,
 			},
-			[]string{"service", "method", "endpoint", "status"},
+			[]string{LabelService, LabelMethod, LabelEndpoint, LabelStatus},
 		),
 		HTTPRequestSize: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -57,7 +85,7 @@ func NewCollector(serviceName string) *Collector {
 				Help:    "HTTP request size in bytes",
 				Buckets: prometheus.ExponentialBuckets(100, 10, 8),
 			},
-			[]string{"service", "method", "endpoint"},
+			[]string{LabelService, LabelMethod, LabelEndpoint},
 		),
 		HTTPResponseSize: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -65,7 +93,7 @@ func NewCollector(serviceName string) *Collector {
 				Help:    "HTTP response size in bytes",
 				Buckets: prometheus.ExponentialBuckets(100, 10, 8),
 			},
-			[]string{"service", "method", "endpoint", "status"},
+			[]string{LabelService, LabelMethod, LabelEndpoint, LabelStatus},
 		),
 
 		// Business Metrics
@@ -113,6 +141,30 @@ func NewCollector(serviceName string) *Collector {
 				Help: "Number of active database connections",
 			},
 		),
+		DBConnectionsIdle: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_connections_idle",
+				Help: "Number of idle database connections",
+			},
+		),
+		DBConnectionsMaxOpen: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_connections_max_open",
+				Help: "Maximum number of open database connections",
+			},
+		),
+		DBConnectionWaits: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_connection_waits",
+				Help: "Total number of waits for a free database connection",
+			},
+		),
+		DBConnectionWaitSeconds: promauto.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "db_connection_wait_seconds",
+				Help: "Total time spent waiting for a free database connection",
+			},
+		),
 		DBQueryDuration: promauto.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "db_query_duration_seconds",
@@ -121,6 +173,13 @@ func NewCollector(serviceName string) *Collector {
 			},
 			[]string{"service", "query_type"},
 		),
+		DBSlowQueriesTotal: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "db_slow_queries_total",
+				Help: "Total number of database queries slower than the slow query threshold",
+			},
+			[]string{"service", "query_type"},
+		),
 		CacheHitsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "cache_hits_total",
@@ -138,7 +197,17 @@ func NewCollector(serviceName string) *Collector {
 	}
 }
 
-// Middleware returns an HTTP middleware that instruments requests
+// SetTraceIDFunc replaces how trace IDs are read from requests for
+// exemplars. A nil function disables exemplars.
+func (c *Collector) SetTraceIDFunc(fn TraceIDFunc) {
+	c.traceID = fn
+}
+
+// Middleware returns an HTTP middleware that instruments requests.
+// Requests are labelled with the templated route of the matched ServeMux
+// pattern (see EndpointLabel); wrap the mux with RecordRoute when other
+// middleware sits between it and this one. Duration and count observations
+// carry the request's trace ID as an exemplar.
 func (c *Collector) Middleware(serviceName string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -150,49 +219,68 @@ func (c *Collector) Middleware(serviceName string) func(http.Handler) http.Handl
 				statusCode:     http.StatusOK,
 			}
 
-			// Record request size
-			if r.ContentLength > 0 {
-				c.HTTPRequestSize.WithLabelValues(
-					serviceName,
-					r.Method,
-					r.URL.Path,
-				).Observe(float64(r.ContentLength))
-			}
-
 			// Process request
+			ctx, route := withRouteHolder(r.Context())
+			r = r.WithContext(ctx)
 			next.ServeHTTP(rw, r)
 
 			// Record metrics
 			duration := time.Since(start).Seconds()
 			status := strconv.Itoa(rw.statusCode)
+			method := MethodLabel(r.Method)
+			pattern := route.pattern
+			if pattern == "" {
+				// Set when this middleware wraps the mux directly
+				pattern = r.Pattern
+			}
+			endpoint := EndpointLabel(pattern, r.URL.Path, rw.statusCode)
 
-			c.HTTPRequestsTotal.WithLabelValues(
+			var exemplar prometheus.Labels
+			if c.traceID != nil {
+				exemplar = exemplarLabels(c.traceID(r))
+			}
+
+			if r.ContentLength > 0 {
+				c.HTTPRequestSize.WithLabelValues(
+					serviceName,
+					method,
+					endpoint,
+				).Observe(float64(r.ContentLength))
+			}
+
+			increment(c.HTTPRequestsTotal.WithLabelValues(
 				serviceName,
-				r.Method,
-				r.URL.Path,
+				method,
+				endpoint,
 				status,
-			).Inc()
+			), exemplar)
 
-			c.HTTPRequestDuration.WithLabelValues(
+			observe(c.HTTPRequestDuration.WithLabelValues(
 				serviceName,
-				r.Method,
-				r.URL.Path,
+				method,
+				endpoint,
 				status,
-			).Observe(duration)
+			), duration, exemplar)
 
 			c.HTTPResponseSize.WithLabelValues(
 				serviceName,
-				r.Method,
-				r.URL.Path,
+				method,
+				endpoint,
 				status,
 			).Observe(float64(rw.bytesWritten))
 		})
 	}
 }
 
-// Handler returns the Prometheus HTTP handler for /metrics endpoint
+// Handler returns the Prometheus HTTP handler for /metrics endpoint.
+// OpenMetrics is offered so scrapers that ask for it receive exemplars.
 func Handler() http.Handler {
-	return promhttp.Handler()
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
+	)
 }
 
 // responseWriter wraps http.ResponseWriter to capture status code and response size
@@ -220,6 +308,12 @@ func (rw *responseWriter) Flush() {
 	}
 }
 
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// optional interfaces such as http.Hijacker for WebSocket upgrades.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // RecordTransaction records a transaction metric
 func (c *Collector) RecordTransaction(serviceName, txType, status string, amountPaise int64) {
 	c.TransactionsTotal.WithLabelValues(serviceName, txType, status).Inc()
@@ -251,6 +345,20 @@ func (c *Collector) UpdateDBConnections(count int) {
 	c.DBConnectionsActive.Set(float64(count))
 }
 
+// RecordDBStats exports connection pool statistics
+func (c *Collector) RecordDBStats(stats sql.DBStats) {
+	c.DBConnectionsActive.Set(float64(stats.InUse))
+	c.DBConnectionsIdle.Set(float64(stats.Idle))
+	c.DBConnectionsMaxOpen.Set(float64(stats.MaxOpenConnections))
+	c.DBConnectionWaits.Set(float64(stats.WaitCount))
+	c.DBConnectionWaitSeconds.Set(stats.WaitDuration.Seconds())
+}
+
+// RecordSlowQuery counts a query slower than the slow query threshold
+func (c *Collector) RecordSlowQuery(serviceName, queryType string) {
+	c.DBSlowQueriesTotal.WithLabelValues(serviceName, queryType).Inc()
+}
+
 // RecordCacheHit records a cache hit
 func (c *Collector) RecordCacheHit(serviceName, cacheName string) {
 	c.CacheHitsTotal.WithLabelValues(serviceName, cacheName).Inc()
@@ -0,0 +1,198 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/requestid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEndpointLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		status  int
+		want    string
+	}{
+		{"pattern with method", "GET /api/v1/wallets/{id}", "/api/v1/wallets/7f3c", 200, "/api/v1/wallets/{id}"},
+		{"pattern without method", "/health", "/health", 200, "/health"},
+		{"catch-all normalizes path", "/api/v1/", "/api/v1/wallets/550e8400-e29b-41d4-a716-446655440000/balance", 200, "/api/v1/wallets/{id}/balance"},
+		{"wildcard suffix normalizes path", "GET /files/{path...}", "/files/12345", 200, "/files/{id}"},
+		{"unmatched 404", "", "/wp-admin/setup.php", 404, UnmatchedEndpoint},
+		{"no pattern normalizes path", "", "/api/v1/transactions/42", 200, "/api/v1/transactions/{id}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EndpointLabel(tt.pattern, tt.path, tt.status); got != tt.want {
+				t.Errorf("EndpointLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePath(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/users/me":                       "/api/v1/users/me",
+		"/api/v1/users/1234/kyc":                 "/api/v1/users/{id}/kyc",
+		"/v1/notifications/a1b2c3d4e5f6a7b8c9d0": "/v1/notifications/{id}",
+		"/api/v1/risk/rules/velocity_check":      "/api/v1/risk/rules/velocity_check",
+		"/api/v1/reports/2026-10-17":             "/api/v1/reports/{id}",
+		"":                                       "/",
+	}
+	for path, want := range tests {
+		if got := NormalizePath(path); got != want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestMethodLabel(t *testing.T) {
+	if got := MethodLabel(http.MethodPatch); got != http.MethodPatch {
+		t.Errorf("MethodLabel(PATCH) = %q", got)
+	}
+	if got := MethodLabel("PROPFIND"); got != OtherMethod {
+		t.Errorf("MethodLabel(PROPFIND) = %q, want %q", got, OtherMethod)
+	}
+}
+
+func TestDefaultTraceID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01")
+	req.Header.Set(requestid.Header, "req-1")
+	if got := DefaultTraceID(req); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace ID from traceparent, got %q", got)
+	}
+
+	req.Header.Set("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	if got := DefaultTraceID(req); got != "req-1" {
+		t.Errorf("expected request ID fallback for invalid traceparent, got %q", got)
+	}
+
+	if exemplarLabels(strings.Repeat("a", maxTraceIDLength+1)) != nil {
+		t.Error("expected over-long trace IDs to be dropped")
+	}
+}
+
+// testCollector returns the collector shared by tests; metrics register
+// globally, so a process can create only one.
+var testCollector = sync.OnceValue(func() *Collector {
+	return NewCollector("metrics-test")
+})
+
+func TestMiddleware_TemplatedRouteAndExemplar(t *testing.T) {
+	collector := testCollector()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/wallets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// RequestID copies the request between the middleware and the mux
+	handler := collector.Middleware("metrics-test")(requestid.Middleware()(RecordRoute(mux)))
+
+	for _, id := range []string{"w-1", "w-2", "w-3"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+id, nil)
+		req.Header.Set(requestid.Header, "trace-"+id)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	counter := collector.HTTPRequestsTotal.WithLabelValues("metrics-test", "GET", "/api/v1/wallets/{id}", "200")
+	if got := testutil.ToFloat64(counter); got != 3 {
+		t.Fatalf("expected 3 requests under the templated route, got %v", got)
+	}
+
+	// Exemplars are only exposed in the OpenMetrics format
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, req)
+
+	want := `http_requests_total{endpoint="/api/v1/wallets/{id}",method="GET",service="metrics-test",status="200"} 3.0 # {trace_id="trace-w-3"}`
+	if !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("expected exposition to contain %s", want)
+	}
+}
+
+func TestRecordDBStats(t *testing.T) {
+	collector := testCollector()
+	if Default() != collector {
+		t.Fatal("expected the created collector to be the default")
+	}
+
+	collector.RecordDBStats(sql.DBStats{
+		MaxOpenConnections: 25,
+		InUse:              7,
+		Idle:               3,
+		WaitCount:          4,
+		WaitDuration:       1500 * time.Millisecond,
+	})
+
+	for name, tc := range map[string]struct {
+		got, want float64
+	}{
+		"active":       {testutil.ToFloat64(collector.DBConnectionsActive), 7},
+		"idle":         {testutil.ToFloat64(collector.DBConnectionsIdle), 3},
+		"max open":     {testutil.ToFloat64(collector.DBConnectionsMaxOpen), 25},
+		"waits":        {testutil.ToFloat64(collector.DBConnectionWaits), 4},
+		"wait seconds": {testutil.ToFloat64(collector.DBConnectionWaitSeconds), 1.5},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, tc.got)
+		}
+	}
+
+	collector.RecordSlowQuery("metrics-test", "select")
+	collector.RecordSlowQuery("metrics-test", "select")
+	if got := testutil.ToFloat64(collector.DBSlowQueriesTotal.WithLabelValues("metrics-test", "select")); got != 2 {
+		t.Errorf("expected 2 slow selects, got %v", got)
+	}
+}
+
+func TestREDRules(t *testing.T) {
+	group := REDRules("wallet", REDOptions{Window: time.Minute, Quantiles: []float64{0.5, 0.999}})
+
+	if group.Name != "wallet-red" {
+		t.Errorf("group name = %q", group.Name)
+	}
+
+	records := make(map[string]string)
+	for _, rule := range group.Rules {
+		if !strings.Contains(rule.Expr, `service="wallet"`) {
+			t.Errorf("rule %s does not select the service: %s", rule.Record, rule.Expr)
+		}
+		records[rule.Record] = rule.Expr
+	}
+
+	for _, record := range []string{
+		"service_endpoint:http_requests:rate1m",
+		"service_endpoint:http_request_errors:rate1m",
+		"service_endpoint:http_request_error_ratio:rate1m",
+		"service_endpoint:http_request_duration_seconds:p50",
+		"service_endpoint:http_request_duration_seconds:p999",
+		"service:http_requests:rate1m",
+		"service:http_request_duration_seconds:p999",
+	} {
+		if _, ok := records[record]; !ok {
+			t.Errorf("missing rule %s", record)
+		}
+	}
+	if expr := records["service:http_request_errors:rate1m"]; !strings.Contains(expr, `status=~"5.."`) || !strings.Contains(expr, "[1m]") {
+		t.Errorf("unexpected errors rule: %s", expr)
+	}
+
+	file, err := REDRuleFile([]string{"wallet", "ledger"}, REDOptions{})
+	if err != nil {
+		t.Fatalf("REDRuleFile() error = %v", err)
+	}
+	if !strings.Contains(string(file), "name: ledger-red") || !strings.Contains(string(file), "rate5m") {
+		t.Errorf("unexpected rule file:\n%s", file)
+	}
+}
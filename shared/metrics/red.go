@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RecordingRule is a Prometheus recording rule.
+type RecordingRule struct {
+	Record string            `yaml:"record"`
+	Expr   string            `yaml:"expr"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+// RuleGroup is a group of Prometheus rules evaluated together, in order.
+type RuleGroup struct {
+	Name     string          `yaml:"name"`
+	Interval string          `yaml:"interval,omitempty"`
+	Rules    []RecordingRule `yaml:"rules"`
+}
+
+// REDOptions configures the generated RED recording rules.
+type REDOptions struct {
+	Window    time.Duration // Rate window (default 5m)
+	Quantiles []float64     // Latency quantiles (default 0.5, 0.95, 0.99)
+}
+
+// DefaultREDQuantiles are the latency quantiles recorded by default.
+var DefaultREDQuantiles = []float64{0.5, 0.95, 0.99}
+
+// REDRules returns recording rules for a service's request rate, error rate
+// and latency, computed from the HTTP metrics recorded by Middleware. Rules
+// are recorded per endpoint (service_endpoint:...) and per service
+// (service:...); errors are 5xx responses.
+func REDRules(service string, opts REDOptions) RuleGroup {
+	window := opts.Window
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+	quantiles := opts.Quantiles
+	if len(quantiles) == 0 {
+		quantiles = DefaultREDQuantiles
+	}
+
+	rangeStr := promDuration(window)
+	suffix := "rate" + rangeStr
+	selector := fmt.Sprintf(`%s=%q`, LabelService, service)
+	errorSelector := selector + fmt.Sprintf(`,%s=~"5.."`, LabelStatus)
+
+	levels := []struct {
+		name string
+		by   []string
+	}{
+		{"service_endpoint", []string{LabelService, LabelMethod, LabelEndpoint}},
+		{"service", []string{LabelService}},
+	}
+
+	var rules []RecordingRule
+	for _, level := range levels {
+		by := strings.Join(level.by, ", ")
+		requests := level.name + ":http_requests:" + suffix
+		errs := level.name + ":http_request_errors:" + suffix
+
+		rules = append(rules,
+			RecordingRule{
+				Record: requests,
+				Expr:   fmt.Sprintf("sum by (%s) (rate(http_requests_total{%s}[%s]))", by, selector, rangeStr),
+			},
+			RecordingRule{
+				Record: errs,
+				Expr:   fmt.Sprintf("sum by (%s) (rate(http_requests_total{%s}[%s]))", by, errorSelector, rangeStr),
+			},
+			RecordingRule{
+				Record: level.name + ":http_request_error_ratio:" + suffix,
+				Expr:   fmt.Sprintf("%s{%s} / %s{%s}", errs, selector, requests, selector),
+			},
+		)
+		for _, q := range quantiles {
+			rules = append(rules, RecordingRule{
+				Record: level.name + ":http_request_duration_seconds:" + quantileName(q),
+				Expr: fmt.Sprintf("histogram_quantile(%s, sum by (%s, le) (rate(http_request_duration_seconds_bucket{%s}[%s])))",
+					strconv.FormatFloat(q, 'f', -1, 64), by, selector, rangeStr),
+			})
+		}
+	}
+
+	return RuleGroup{
+		Name:  service + "-red",
+		Rules: rules,
+	}
+}
+
+// REDRuleFile renders a Prometheus rule file with RED recording rules for
+// each service.
+func REDRuleFile(services []string, opts REDOptions) ([]byte, error) {
+	file := struct {
+		Groups []RuleGroup `yaml:"groups"`
+	}{}
+	for _, service := range services {
+		file.Groups = append(file.Groups, REDRules(service, opts))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return nil, fmt.Errorf("failed to render rule file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to render rule file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// quantileName names a quantile for a rule: 0.5 is p50, 0.999 is p999.
+func quantileName(q float64) string {
+	digits := strings.TrimPrefix(strconv.FormatFloat(q, 'f', -1, 64), "0.")
+	if len(digits) == 1 {
+		digits += "0"
+	}
+	return "p" + digits
+}
+
+// promDuration formats d as a PromQL duration such as 5m or 1h.
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+}
@@ -29,6 +29,8 @@ const (
 	JWTTokenKey ContextKey = "jwt_token"
 	// AccountTypeKey is the context key for account type (user, user_admin).
 	AccountTypeKey ContextKey = "account_type"
+	// UserTierKey is the context key for account tier (regular, premium).
+	UserTierKey ContextKey = "user_tier"
 )
 
 // JWTClaims represents the JWT token claims structure.
@@ -37,6 +39,7 @@ type JWTClaims struct {
 	Email       string   `json:"email"`
 	Status      string   `json:"status"`
 	AccountType string   `json:"account_type,omitempty"`
+	Tier        string   `json:"tier,omitempty"`
 	Roles       []string `json:"roles,omitempty"`
 	Permissions []string `json:"permissions,omitempty"`
 	jwt.RegisteredClaims
@@ -98,6 +101,7 @@ func Auth(config AuthConfig) func(http.Handler) http.Handler {
 			ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
 			ctx = context.WithValue(ctx, UserStatusKey, claims.Status)
 			ctx = context.WithValue(ctx, AccountTypeKey, claims.AccountType)
+			ctx = context.WithValue(ctx, UserTierKey, claims.Tier)
 			ctx = context.WithValue(ctx, UserRolesKey, claims.Roles)
 			ctx = context.WithValue(ctx, UserPermissionsKey, claims.Permissions)
 			ctx = context.WithValue(ctx, JWTTokenKey, tokenString) // Store token for service-to-service forwarding
@@ -268,12 +272,33 @@ func GetUserPermissions(ctx context.Context) ([]string, bool) {
 	return permissions, ok
 }
 
+// HasPermission reports whether the authenticated user has the given permission.
+func HasPermission(ctx context.Context, permission string) bool {
+	permissions, ok := GetUserPermissions(ctx)
+	if !ok {
+		return false
+	}
+	for _, perm := range permissions {
+		if perm == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAccountType extracts the account type from the request context.
 func GetAccountType(ctx context.Context) (string, bool) {
 	accountType, ok := ctx.Value(AccountTypeKey).(string)
 	return accountType, ok
 }
 
+// GetUserTier extracts the account tier from the request context.
+// Tokens issued before tiers existed carry no tier claim and report false.
+func GetUserTier(ctx context.Context) (string, bool) {
+	tier, ok := ctx.Value(UserTierKey).(string)
+	return tier, ok && tier != ""
+}
+
 // InternalAuthConfig holds configuration for internal service-to-service auth.
 type InternalAuthConfig struct {
 	// Secret is the shared secret for internal service communication.
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ClientIPHeader carries the original client IP, set by the gateway.
+	ClientIPHeader = "X-Real-IP"
+	// ClientCountryHeader carries the client's ISO 3166-1 alpha-2 country
+	// as reported by the edge, set by the gateway when configured.
+	ClientCountryHeader = "X-Client-Country"
+
+	// ClientInfoKey is the context key for the client's network origin.
+	ClientInfoKey ContextKey = "client_info"
+)
+
+// ClientInfo describes where a request originated, as seen by the gateway.
+type ClientInfo struct {
+	IP      string
+	Country string
+}
+
+// ClientContext returns a middleware that stores the client IP and country
+// forwarded by the gateway in the request context. Services sit behind the
+// gateway, which overwrites both headers, so they are trusted here.
+func ClientContext() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info := ClientInfo{}
+			if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(ClientIPHeader))); ip != nil {
+				info.IP = ip.String()
+			}
+			if country := strings.TrimSpace(r.Header.Get(ClientCountryHeader)); len(country) == 2 {
+				info.Country = strings.ToUpper(country)
+			}
+
+			if info.IP != "" || info.Country != "" {
+				r = r.WithContext(context.WithValue(r.Context(), ClientInfoKey, info))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetClientInfo extracts the client's network origin from the request context.
+func GetClientInfo(ctx context.Context) (ClientInfo, bool) {
+	info, ok := ctx.Value(ClientInfoKey).(ClientInfo)
+	return info, ok
+}
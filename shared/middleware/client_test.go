@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		ip      string
+		country string
+		want    ClientInfo
+		found   bool
+	}{
+		{"ip and country", "203.0.113.7", "in", ClientInfo{IP: "203.0.113.7", Country: "IN"}, true},
+		{"ipv6 only", "2001:db8::1", "", ClientInfo{IP: "2001:db8::1"}, true},
+		{"invalid values dropped", "not-an-ip", "India", ClientInfo{}, false},
+		{"no headers", "", "", ClientInfo{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got ClientInfo
+			var found bool
+			handler := ClientContext()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, found = GetClientInfo(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.ip != "" {
+				req.Header.Set(ClientIPHeader, tt.ip)
+			}
+			if tt.country != "" {
+				req.Header.Set(ClientCountryHeader, tt.country)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if found != tt.found || got != tt.want {
+				t.Errorf("expected %+v (found=%v), got %+v (found=%v)", tt.want, tt.found, got, found)
+			}
+		})
+	}
+}
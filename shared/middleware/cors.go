@@ -8,7 +8,7 @@ import (
 
 // CORSConfig holds CORS configuration.
 type CORSConfig struct {
-	AllowedOrigins   []string // List of allowed origins, or ["*"] for all
+	AllowedOrigins   []string // List of allowed origins, "https://*.example.com" for subdomains, or ["*"] for all
 	AllowedMethods   []string // HTTP methods (GET, POST, etc.)
 	AllowedHeaders   []string // HTTP headers
 	ExposedHeaders   []string // Headers exposed to client
@@ -29,18 +29,26 @@ func DefaultCORSConfig() CORSConfig {
 	}
 }
 
-// CORS returns a middleware that handles CORS requests.
+// CORS returns a middleware that handles CORS requests. Preflight requests
+// are answered directly: 204 for allowed origins, 403 for others.
 func CORS(config CORSConfig) Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
 
 			// Check if wildcard is allowed first
+			allowed := true
 			if len(config.AllowedOrigins) == 1 && config.AllowedOrigins[0] == "*" {
 				w.Header().Set("Access-Control-Allow-Origin", "*")
-			} else if origin != "" && isOriginAllowed(origin, config.AllowedOrigins) {
-				// Echo back the specific allowed origin
-				w.Header().Set("Access-Control-Allow-Origin", origin)
+			} else {
+				// The response depends on the origin, so shared caches must key on it
+				w.Header().Add("Vary", "Origin")
+				if origin != "" && isOriginAllowed(origin, config.AllowedOrigins) {
+					// Echo back the specific allowed origin
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				} else if origin != "" {
+					allowed = false
+				}
 			}
 
 			// Set allowed methods
@@ -70,6 +78,10 @@ func CORS(config CORSConfig) Middleware {
 
 			// Handle preflight requests
 			if r.Method == http.MethodOptions {
+				if !allowed {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
@@ -79,12 +91,20 @@ func CORS(config CORSConfig) Middleware {
 	}
 }
 
-// isOriginAllowed checks if an origin is in the allowed list.
+// isOriginAllowed checks if an origin is in the allowed list. An entry like
+// "https://*.example.com" allows any subdomain of example.com over https, but
+// not example.com itself.
 func isOriginAllowed(origin string, allowedOrigins []string) bool {
 	for _, allowed := range allowedOrigins {
 		if allowed == "*" || allowed == origin {
 			return true
 		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			host, found := strings.CutPrefix(origin, scheme+"://")
+			if found && strings.HasSuffix(host, "."+domain) && !strings.Contains(host, "/") {
+				return true
+			}
+		}
 	}
 	return false
 }
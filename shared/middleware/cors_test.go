@@ -255,6 +255,55 @@ func TestCORS(t *testing.T) {
 		}
 	})
 
+	t.Run("rejects preflight from non-allowed origin", func(t *testing.T) {
+		config := CORSConfig{
+			AllowedOrigins: []string{"http://example.com"},
+			AllowedMethods: []string{"GET", "POST"},
+		}
+
+		handlerCalled := false
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+		})
+
+		req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+		req.Header.Set("Origin", "http://malicious.com")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		rec := httptest.NewRecorder()
+
+		CORS(config)(handler).ServeHTTP(rec, req)
+
+		if handlerCalled {
+			t.Error("handler should not be called for OPTIONS request")
+		}
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected status 403, got %d", rec.Code)
+		}
+		if rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Error("expected no Access-Control-Allow-Origin header for non-allowed origin")
+		}
+	})
+
+	t.Run("varies on origin for allowlists", func(t *testing.T) {
+		config := CORSConfig{
+			AllowedOrigins: []string{"http://example.com"},
+		}
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Origin", "http://example.com")
+		rec := httptest.NewRecorder()
+
+		CORS(config)(handler).ServeHTTP(rec, req)
+
+		if rec.Header().Get("Vary") != "Origin" {
+			t.Errorf("expected Vary: Origin, got %q", rec.Header().Get("Vary"))
+		}
+	})
+
 	t.Run("passes through non-OPTIONS request", func(t *testing.T) {
 		config := CORSConfig{
 			AllowedOrigins: []string{"*"},
@@ -346,6 +395,30 @@ func TestIsOriginAllowed(t *testing.T) {
 			allowedOrigins: []string{"http://example.com"},
 			expected:       false,
 		},
+		{
+			name:           "subdomain pattern",
+			origin:         "https://admin.example.com",
+			allowedOrigins: []string{"https://*.example.com"},
+			expected:       true,
+		},
+		{
+			name:           "subdomain pattern excludes apex",
+			origin:         "https://example.com",
+			allowedOrigins: []string{"https://*.example.com"},
+			expected:       false,
+		},
+		{
+			name:           "subdomain pattern checks scheme",
+			origin:         "http://admin.example.com",
+			allowedOrigins: []string{"https://*.example.com"},
+			expected:       false,
+		},
+		{
+			name:           "subdomain pattern checks suffix boundary",
+			origin:         "https://evilexample.com",
+			allowedOrigins: []string{"https://*.example.com"},
+			expected:       false,
+		},
 		{
 			name:           "empty list",
 			origin:         "http://example.com",
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
 )
 
 // Logging returns a middleware that logs HTTP requests and responses.
@@ -14,7 +15,7 @@ func Logging(log *logger.Logger) Middleware {
 			start := time.Now()
 
 			// Get request ID from context if available
-			requestID := r.Header.Get("X-Request-ID")
+			requestID := requestid.FromRequest(r)
 			if requestID == "" {
 				requestID = "unknown"
 			}
@@ -57,3 +57,9 @@ func (rw *ResponseWriter) Flush() {
 		flusher.Flush()
 	}
 }
+
+// Unwrap returns the underlying writer so http.ResponseController can reach
+// optional interfaces such as http.Hijacker for WebSocket upgrades.
+func (rw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
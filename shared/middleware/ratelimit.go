@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -80,6 +81,11 @@ func newRateLimiter(config RateLimitConfig) *rateLimiter {
 	return rl
 }
 
+// stop ends the cleanup goroutine.
+func (rl *rateLimiter) stop() {
+	close(rl.stopCleanup)
+}
+
 // cleanupLoop periodically removes old visitor entries
 func (rl *rateLimiter) cleanupLoop() {
 	for {
@@ -153,46 +159,77 @@ func (rl *rateLimiter) allow(ip string) (bool, float64) {
 
 // RateLimit creates a rate limiting middleware with the given configuration
 func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
-	limiter := newRateLimiter(config)
-
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get client IP (use proxy headers only if explicitly trusted)
-			var ip string
-			if config.TrustProxyHeaders {
-				ip = getClientIPFromProxy(r)
-			} else {
-				ip = getClientIP(r)
-			}
+	return NewRateLimiter(config).Middleware
+}
+
+// RateLimiter is a rate limit whose configuration can be changed while it
+// serves requests, e.g. from an admin endpoint.
+type RateLimiter struct {
+	limiter atomic.Pointer[rateLimiter]
+}
+
+// NewRateLimiter creates a rate limiter with the given configuration.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{}
+	l.limiter.Store(newRateLimiter(config))
+	return l
+}
+
+// Config returns the current configuration.
+func (l *RateLimiter) Config() RateLimitConfig {
+	return l.limiter.Load().config
+}
+
+// SetConfig replaces the configuration. Clients start over with a full burst
+// under the new limit.
+func (l *RateLimiter) SetConfig(config RateLimitConfig) {
+	old := l.limiter.Swap(newRateLimiter(config))
+	if old.cleanupTicker != nil {
+		old.stop()
+	}
+}
+
+// Middleware rate limits requests to next by client IP.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := l.limiter.Load()
+		config := limiter.config
+
+		// Get client IP (use proxy headers only if explicitly trusted)
+		var ip string
+		if config.TrustProxyHeaders {
+			ip = getClientIPFromProxy(r)
+		} else {
+			ip = getClientIP(r)
+		}
+
+		// Check rate limit
+		allowed, tokens := limiter.allow(ip)
 
-			// Check rate limit
-			allowed, tokens := limiter.allow(ip)
-
-			if !allowed {
-				// Calculate retry-after in seconds
-				retryAfter := int((1.0 - tokens) / limiter.tokensPerSecond)
-				if retryAfter < 1 {
-					retryAfter = 1
-				}
-
-				// Set rate limit headers
-				w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", config.RequestsPerMinute))
-				w.Header().Set("X-RateLimit-Remaining", "0")
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
-
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusTooManyRequests)
-				_, _ = w.Write([]byte(`{"success":false,"error":"rate limit exceeded","message":"Too many requests. Please try again later."}`))
-				return
+		if !allowed {
+			// Calculate retry-after in seconds
+			retryAfter := int((1.0 - tokens) / limiter.tokensPerSecond)
+			if retryAfter < 1 {
+				retryAfter = 1
 			}
 
-			// Set rate limit headers for successful requests
+			// Set rate limit headers
 			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", config.RequestsPerMinute))
-			w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%.0f", tokens))
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
 
-			next.ServeHTTP(w, r)
-		})
-	}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"success":false,"error":"rate limit exceeded","message":"Too many requests. Please try again later."}`))
+			return
+		}
+
+		// Set rate limit headers for successful requests
+		w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", config.RequestsPerMinute))
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%.0f", tokens))
+
+		next.ServeHTTP(w, r)
+	})
 }
 
 // getClientIP extracts the client IP address from the request.
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimiter(t *testing.T) {
+	serve := func(handler http.Handler) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("rejects requests beyond the burst", func(t *testing.T) {
+		handler := RateLimit(RateLimitConfig{RequestsPerMinute: 1, BurstSize: 2})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		for i := 0; i < 2; i++ {
+			if rec := serve(handler); rec.Code != http.StatusOK {
+				t.Fatalf("request %d: expected status 200, got %d", i+1, rec.Code)
+			}
+		}
+
+		rec := serve(handler)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status 429, got %d", rec.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Error("expected a Retry-After header")
+		}
+	})
+
+	t.Run("applies a new configuration to later requests", func(t *testing.T) {
+		limiter := NewRateLimiter(RateLimitConfig{RequestsPerMinute: 1, BurstSize: 1})
+		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		serve(handler)
+		if rec := serve(handler); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status 429 under the initial limit, got %d", rec.Code)
+		}
+
+		limiter.SetConfig(RateLimitConfig{RequestsPerMinute: 600, BurstSize: 5})
+		rec := serve(handler)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 under the raised limit, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "600" {
+			t.Errorf("expected X-RateLimit-Limit 600, got %s", got)
+		}
+		if got := limiter.Config().BurstSize; got != 5 {
+			t.Errorf("expected burst size 5, got %d", got)
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
 )
 
 func TestRequestID(t *testing.T) {
@@ -195,14 +196,14 @@ func TestRequestID(t *testing.T) {
 
 func TestGenerateRequestID(t *testing.T) {
 	t.Run("generates non-empty ID", func(t *testing.T) {
-		id := generateRequestID()
+		id := requestid.New()
 		if id == "" {
 			t.Error("expected non-empty request ID")
 		}
 	})
 
 	t.Run("generates hex string", func(t *testing.T) {
-		id := generateRequestID()
+		id := requestid.New()
 
 		// Should be 32 characters (16 bytes in hex)
 		if len(id) != 32 {
@@ -218,8 +219,8 @@ func TestGenerateRequestID(t *testing.T) {
 	})
 
 	t.Run("generates different IDs", func(t *testing.T) {
-		id1 := generateRequestID()
-		id2 := generateRequestID()
+		id1 := requestid.New()
+		id2 := requestid.New()
 
 		if id1 == id2 {
 			t.Error("expected different request IDs")
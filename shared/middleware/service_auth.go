@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/response"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
+)
+
+// CallerServiceKey is the context key for the service that made an internal request.
+const CallerServiceKey ContextKey = "caller_service"
+
+// internalPathPrefix is the path prefix of service-to-service endpoints.
+const internalPathPrefix = "/internal/"
+
+// ServiceAuthConfig holds configuration for service token authentication.
+type ServiceAuthConfig struct {
+	Verifier *serviceauth.Verifier
+	Policy   serviceauth.Policy
+}
+
+// ServiceAuth creates a middleware that requires a valid service token on every
+// /internal request and enforces the policy's allowed callers. Other paths
+// pass through unchanged.
+func ServiceAuth(config ServiceAuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !strings.HasPrefix(r.URL.Path, internalPathPrefix) || config.Policy.IsExempt(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := r.Header.Get(serviceauth.Header)
+			if token == "" {
+				response.Error(w, errors.Unauthorized("missing service token"))
+				return
+			}
+
+			caller, err := config.Verifier.Verify(token)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+
+			if !config.Policy.Allows(r.URL.Path, caller) {
+				response.Error(w, errors.Forbidden(fmt.Sprintf("service %s may not call %s", caller, r.URL.Path)))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), CallerServiceKey, caller)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetCallerService extracts the calling service from the request context.
+func GetCallerService(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(CallerServiceKey).(string)
+	return caller, ok && caller != ""
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
+)
+
+func TestServiceAuth(t *testing.T) {
+	var caller string
+	handler := ServiceAuth(ServiceAuthConfig{
+		Verifier: serviceauth.NewVerifier("secret"),
+		Policy: serviceauth.Policy{
+			Callers: map[string][]string{"/internal/v1/accounts": {"wallet"}},
+			Exempt:  []string{"/internal/v1/callbacks"},
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, _ = GetCallerService(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token := func(service, secret string) string {
+		t.Helper()
+		tok, err := serviceauth.NewSigner(service, secret, 0).Token()
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return tok
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+		wantCaller string
+	}{
+		{"public path passes", "/api/v1/accounts", "", http.StatusOK, ""},
+		{"exempt internal path passes", "/internal/v1/callbacks", "", http.StatusOK, ""},
+		{"missing token", "/internal/v1/accounts", "", http.StatusUnauthorized, ""},
+		{"wrong key", "/internal/v1/accounts", token("wallet", "other"), http.StatusUnauthorized, ""},
+		{"caller not allowed", "/internal/v1/accounts", token("risk", "secret"), http.StatusForbidden, ""},
+		{"allowed caller", "/internal/v1/accounts/by-code/1000", token("wallet", "secret"), http.StatusOK, "wallet"},
+		{"unrestricted path", "/internal/v1/verify/references", token("risk", "secret"), http.StatusOK, "risk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller = ""
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set(serviceauth.Header, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if caller != tt.wantCaller {
+				t.Errorf("expected caller %q, got %q", tt.wantCaller, caller)
+			}
+		})
+	}
+}
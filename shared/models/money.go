@@ -8,6 +8,9 @@ import (
 
 // Money represents a monetary amount in the smallest currency unit (e.g., cents).
 // Using int64 avoids floating-point precision issues.
+//
+// Deprecated: use money.Money from shared/money, which checks for overflow,
+// allocates without losing units and formats by currency.
 type Money struct {
 	Amount   int64    `json:"amount"`   // Amount in smallest unit (cents)
 	Currency Currency `json:"currency"` // Currency code
@@ -0,0 +1,129 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+// Decimal returns the amount in major units with the currency's decimal
+// places and no grouping or symbol, e.g. "1234.50" or "-7.05". Use it for
+// machine-readable output such as payment links and CSV exports.
+func (m Money) Decimal() string {
+	whole, frac, negative := m.split()
+	s := strconv.FormatUint(whole, 10)
+	if frac != "" {
+		s += "." + frac
+	}
+	if negative {
+		s = "-" + s
+	}
+	return s
+}
+
+// String returns the amount for display with the currency symbol and digit
+// grouping, e.g. "₹1,23,456.78", "$1,234.56" or "-¥500". INR uses Indian
+// lakh/crore grouping.
+func (m Money) String() string {
+	whole, frac, negative := m.split()
+	s := m.currency.GetSymbol() + group(strconv.FormatUint(whole, 10), m.currency == models.INR)
+	if frac != "" {
+		s += "." + frac
+	}
+	if negative {
+		s = "-" + s
+	}
+	return s
+}
+
+// split returns the absolute whole part, the zero-padded fraction and the sign.
+func (m Money) split() (whole uint64, frac string, negative bool) {
+	negative = m.amount < 0
+	abs := uint64(m.amount)
+	if negative {
+		abs = -abs // Two's complement keeps MinInt64 correct
+	}
+
+	places := m.currency.GetDecimalPlaces()
+	if places == 0 {
+		return abs, "", negative
+	}
+	scale := pow10(places)
+	return abs / scale, fmt.Sprintf("%0*d", places, abs%scale), negative
+}
+
+// group inserts thousands separators. Indian grouping separates the last
+// three digits, then every two (12,34,56,789).
+func group(digits string, indian bool) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+	size := 3
+	if indian {
+		size = 2
+	}
+
+	var parts []string
+	for len(head) > size {
+		parts = append([]string{head[len(head)-size:]}, parts...)
+		head = head[:len(head)-size]
+	}
+	parts = append([]string{head}, parts...)
+	return strings.Join(append(parts, tail), ",")
+}
+
+// Parse reads a decimal amount in major units, such as "1234.5" or "-7",
+// into the currency's minor units. Grouping separators and symbols are not
+// accepted, nor are more decimal places than the currency has.
+func Parse(s string, currency models.Currency) (Money, error) {
+	if err := currency.Validate(); err != nil {
+		return Money{}, err
+	}
+
+	s = strings.TrimSpace(s)
+	digits, negative := strings.CutPrefix(s, "-")
+	if !negative {
+		digits = strings.TrimPrefix(digits, "+")
+	}
+
+	whole, frac, hasPoint := strings.Cut(digits, ".")
+	places := currency.GetDecimalPlaces()
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) || hasPoint && frac == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > places {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal places for %s", s, places, currency)
+	}
+	frac += strings.Repeat("0", places-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, ErrOverflow
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, currency), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) uint64 {
+	p := uint64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
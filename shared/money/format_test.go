@@ -0,0 +1,86 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+func TestMoney_String(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(12345678, models.INR), "₹1,23,456.78"},
+		{New(1234567890, models.INR), "₹1,23,45,678.90"},
+		{New(99900, models.INR), "₹999.00"},
+		{New(5, models.INR), "₹0.05"},
+		{New(-123456, models.INR), "-₹1,234.56"},
+		{New(123456789, models.USD), "$1,234,567.89"},
+		{New(1234567, models.JPY), "¥1,234,567"},
+		{New(-500, models.JPY), "-¥500"},
+		{New(math.MinInt64, models.USD), "-$92,233,720,368,547,758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, got)
+		}
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(123450, models.INR), "1234.50"},
+		{New(-705, models.INR), "-7.05"},
+		{New(0, models.INR), "0.00"},
+		{New(500, models.JPY), "500"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("expected %s, got %s", tt.want, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency models.Currency
+		want     int64
+	}{
+		{"1234.56", models.INR, 123456},
+		{"1234.5", models.INR, 123450},
+		{"1234", models.INR, 123400},
+		{".5", models.INR, 50},
+		{"-7.05", models.INR, -705},
+		{"+10", models.USD, 1000},
+		{" 42 ", models.INR, 4200},
+		{"500", models.JPY, 500},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.input, tt.currency)
+		if err != nil || got != New(tt.want, tt.currency) {
+			t.Errorf("Parse(%q): expected %d, got %v (%v)", tt.input, tt.want, got.Amount(), err)
+		}
+	}
+
+	for _, input := range []string{"", "-", "1.234", "1,000", "₹10", "1.", "abc", "-+5", "5.0e2"} {
+		if _, err := Parse(input, models.INR); err == nil {
+			t.Errorf("Parse(%q): expected error", input)
+		}
+	}
+	if _, err := Parse("1.5", models.JPY); err == nil {
+		t.Error("expected decimals to be rejected for JPY")
+	}
+	if _, err := Parse("100", "XYZ"); err == nil {
+		t.Error("expected unsupported currency to be rejected")
+	}
+	if _, err := Parse("99999999999999999999", models.INR); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Package money provides a currency-aware monetary amount.
+//
+// A Money holds an amount in the currency's smallest unit (paise for INR)
+// together with its currency. Arithmetic between two amounts fails instead of
+// silently mixing currencies, and every operation checks for int64 overflow.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when combining amounts in different currencies.
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	// ErrOverflow is returned when a result does not fit in int64.
+	ErrOverflow = errors.New("amount overflow")
+	// ErrInvalidRatios is returned by Allocate for empty, negative or all-zero ratios.
+	ErrInvalidRatios = errors.New("invalid allocation ratios")
+)
+
+// Money is an amount in the smallest unit of a currency. The zero value has
+// no currency; use New or Zero to create one.
+type Money struct {
+	amount   int64
+	currency models.Currency
+}
+
+// New creates an amount of minor units in a currency.
+func New(amount int64, currency models.Currency) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// Zero returns a zero amount in a currency.
+func Zero(currency models.Currency) Money {
+	return Money{currency: currency}
+}
+
+// Amount returns the amount in minor units.
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the currency of the amount.
+func (m Money) Currency() models.Currency {
+	return m.currency
+}
+
+// SameCurrency reports whether both amounts are in the same currency.
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency
+}
+
+func (m Money) mismatch(other Money) error {
+	return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, m.mismatch(other)
+	}
+	if (other.amount > 0 && m.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && m.amount < math.MinInt64-other.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, m.mismatch(other)
+	}
+	if (other.amount < 0 && m.amount > math.MaxInt64+other.amount) ||
+		(other.amount > 0 && m.amount < math.MinInt64+other.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Sum adds amounts that must all be in the given currency. An empty list
+// sums to zero.
+func Sum(currency models.Currency, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() (Money, error) {
+	if m.amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: -m.amount, currency: m.currency}, nil
+}
+
+// Abs returns the absolute value of m.
+func (m Money) Abs() (Money, error) {
+	if m.amount < 0 {
+		return m.Neg()
+	}
+	return m, nil
+}
+
+// Multiply returns m × factor.
+func (m Money) Multiply(factor int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(factor))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: product.Int64(), currency: m.currency}, nil
+}
+
+// BasisPoints returns bps/10000 of m, rounded half away from zero.
+// For example 250 basis points of ₹100.00 is ₹2.50.
+func (m Money) BasisPoints(bps int64) (Money, error) {
+	return m.fraction(bps, 10000)
+}
+
+// Percent returns pct percent of m, rounded half away from zero.
+func (m Money) Percent(pct int64) (Money, error) {
+	return m.fraction(pct, 100)
+}
+
+// fraction returns m × num / den rounded half away from zero.
+func (m Money) fraction(num, den int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num))
+	quo, rem := new(big.Int).QuoRem(product, big.NewInt(den), new(big.Int))
+
+	// Round half away from zero: |rem| * 2 >= den
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(big.NewInt(den)) >= 0 {
+		if product.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+	if !quo.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: quo.Int64(), currency: m.currency}, nil
+}
+
+// Allocate splits m in proportion to ratios without losing a minor unit: the
+// parts always add up to m. Units left over after the proportional split go
+// one each to the first parts. For example ₹100.00 allocated 1:1:1 is
+// ₹33.34, ₹33.33 and ₹33.33.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, ErrInvalidRatios
+	}
+	total := big.NewInt(0)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, ErrInvalidRatios
+		}
+		total.Add(total, big.NewInt(ratio))
+	}
+	if total.Sign() == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, ratio := range ratios {
+		// Truncates toward zero, so the remainder keeps m's sign
+		share := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(ratio))
+		share.Quo(share, total)
+		parts[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += step
+		remainder -= step
+	}
+
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, ErrInvalidRatios
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Cmp compares m and other, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if !m.SameCurrency(other) {
+		return 0, m.mismatch(other)
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Equal reports whether both amount and currency are equal.
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero.
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// Validate checks that the currency is supported.
+func (m Money) Validate() error {
+	return m.currency.Validate()
+}
+
+// jsonMoney is the wire format of Money.
+type jsonMoney struct {
+	Amount   int64           `json:"amount"`
+	Currency models.Currency `json:"currency"`
+}
+
+// MarshalJSON implements json.Marshaler as {"amount": 12345, "currency": "INR"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{Amount: m.amount, Currency: m.currency})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The currency must be supported.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v jsonMoney
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := v.Currency.Validate(); err != nil {
+		return err
+	}
+	m.amount = v.Amount
+	m.currency = v.Currency
+	return nil
+}
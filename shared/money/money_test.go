@@ -0,0 +1,179 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/models"
+)
+
+func TestMoney_AddSub(t *testing.T) {
+	a, b := New(1050, models.INR), New(250, models.INR)
+
+	sum, err := a.Add(b)
+	if err != nil || sum != New(1300, models.INR) {
+		t.Errorf("Add: got %v, %v", sum, err)
+	}
+	diff, err := b.Sub(a)
+	if err != nil || diff != New(-800, models.INR) {
+		t.Errorf("Sub: got %v, %v", diff, err)
+	}
+
+	if _, err := a.Add(New(100, models.USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+	if _, err := a.Sub(New(100, models.USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+	if _, err := New(math.MaxInt64, models.INR).Add(New(1, models.INR)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+	if _, err := New(math.MinInt64, models.INR).Sub(New(1, models.INR)); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+}
+
+func TestSum(t *testing.T) {
+	total, err := Sum(models.INR, New(100, models.INR), New(200, models.INR), New(-50, models.INR))
+	if err != nil || total != New(250, models.INR) {
+		t.Errorf("got %v, %v", total, err)
+	}
+	if total, err := Sum(models.USD); err != nil || total != Zero(models.USD) {
+		t.Errorf("empty sum: got %v, %v", total, err)
+	}
+	if _, err := Sum(models.INR, New(100, models.USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+}
+
+func TestMoney_NegAbsMultiply(t *testing.T) {
+	if neg, _ := New(500, models.INR).Neg(); neg != New(-500, models.INR) {
+		t.Errorf("Neg: got %v", neg)
+	}
+	if abs, _ := New(-500, models.INR).Abs(); abs != New(500, models.INR) {
+		t.Errorf("Abs: got %v", abs)
+	}
+	if _, err := New(math.MinInt64, models.INR).Neg(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+	if product, _ := New(-250, models.INR).Multiply(3); product != New(-750, models.INR) {
+		t.Errorf("Multiply: got %v", product)
+	}
+	if _, err := New(math.MaxInt64/2+1, models.INR).Multiply(2); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+}
+
+func TestMoney_BasisPointsAndPercent(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		bps    int64
+		want   int64
+	}{
+		{"exact", 10000, 250, 250},
+		{"rounds half up", 150, 100, 2},         // 1.5 -> 2
+		{"rounds down", 149, 100, 1},            // 1.49 -> 1
+		{"negative rounds away", -150, 100, -2}, // -1.5 -> -2
+		{"zero rate", 10000, 0, 0},
+		{"large amount", math.MaxInt64, 10000, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.amount, models.INR).BasisPoints(tt.bps)
+			if err != nil || got != New(tt.want, models.INR) {
+				t.Errorf("expected %d, got %v (%v)", tt.want, got.Amount(), err)
+			}
+		})
+	}
+
+	if got, _ := New(1999, models.INR).Percent(18); got.Amount() != 360 { // 359.82
+		t.Errorf("Percent: got %d", got.Amount())
+	}
+	if _, err := New(math.MaxInt64, models.INR).Percent(200); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected overflow, got %v", err)
+	}
+}
+
+func TestMoney_Allocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		ratios []int64
+		want   []int64
+	}{
+		{"even thirds", 10000, []int64{1, 1, 1}, []int64{3334, 3333, 3333}},
+		{"weighted", 100, []int64{70, 30}, []int64{70, 30}},
+		{"remainder to first parts", 5, []int64{1, 1, 1}, []int64{2, 2, 1}},
+		{"zero ratio gets nothing", 5, []int64{0, 1, 1}, []int64{0, 3, 2}},
+		{"negative amount", -10, []int64{1, 1, 1}, []int64{-4, -3, -3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := New(tt.amount, models.INR).Allocate(tt.ratios...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var total int64
+			for i, part := range parts {
+				if part.Amount() != tt.want[i] || part.Currency() != models.INR {
+					t.Errorf("part %d: expected %d, got %v", i, tt.want[i], part)
+				}
+				total += part.Amount()
+			}
+			if total != tt.amount {
+				t.Errorf("parts add up to %d, expected %d", total, tt.amount)
+			}
+		})
+	}
+
+	for _, ratios := range [][]int64{nil, {0, 0}, {1, -1}} {
+		if _, err := New(100, models.INR).Allocate(ratios...); !errors.Is(err, ErrInvalidRatios) {
+			t.Errorf("ratios %v: expected ErrInvalidRatios, got %v", ratios, err)
+		}
+	}
+
+	parts, err := New(100, models.INR).Split(3)
+	if err != nil || len(parts) != 3 || parts[0].Amount() != 34 {
+		t.Errorf("Split: got %v, %v", parts, err)
+	}
+	if _, err := New(100, models.INR).Split(0); !errors.Is(err, ErrInvalidRatios) {
+		t.Errorf("Split(0): expected ErrInvalidRatios, got %v", err)
+	}
+}
+
+func TestMoney_Cmp(t *testing.T) {
+	a, b := New(100, models.INR), New(200, models.INR)
+	if c, _ := a.Cmp(b); c != -1 {
+		t.Errorf("expected -1, got %d", c)
+	}
+	if c, _ := b.Cmp(a); c != 1 {
+		t.Errorf("expected 1, got %d", c)
+	}
+	if c, _ := a.Cmp(a); c != 0 {
+		t.Errorf("expected 0, got %d", c)
+	}
+	if _, err := a.Cmp(New(100, models.USD)); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+	if a.Equal(New(100, models.USD)) {
+		t.Error("amounts in different currencies must not be equal")
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(New(123456, models.INR))
+	if err != nil || string(data) != `{"amount":123456,"currency":"INR"}` {
+		t.Fatalf("Marshal: got %s, %v", data, err)
+	}
+
+	var m Money
+	if err := json.Unmarshal(data, &m); err != nil || m != New(123456, models.INR) {
+		t.Errorf("Unmarshal: got %v, %v", m, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":1,"currency":"XYZ"}`), &m); err == nil {
+		t.Error("expected unsupported currency to be rejected")
+	}
+}
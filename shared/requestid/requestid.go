@@ -0,0 +1,93 @@
+// Package requestid provides request ID generation and propagation so a single
+// request can be correlated across the gateway and every downstream service.
+//
+// The gateway assigns an ID to each inbound request (or adopts the caller's
+// X-Request-ID), stores it in the request context and echoes it in the response
+// header. Service clients built on shared/clients forward the ID from the
+// context, and downstream services adopt it through the same middleware, so
+// logs and error responses for one transfer all carry the same request_id.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// Header is the HTTP header used to carry request IDs between services.
+const Header = "X-Request-ID"
+
+// maxLength bounds caller-supplied IDs to keep log lines and headers sane.
+const maxLength = 128
+
+// New generates a random 128-bit request ID encoded as hex.
+func New() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// WithContext returns a copy of ctx carrying the request ID.
+// The ID is stored under logger.RequestIDKey so logger.WithContext picks it up.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, logger.RequestIDKey, id)
+}
+
+// FromContext returns the request ID stored in ctx, or an empty string.
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(logger.RequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// FromRequest returns the request ID for r, preferring the context value over
+// the inbound header.
+func FromRequest(r *http.Request) string {
+	if id := FromContext(r.Context()); id != "" {
+		return id
+	}
+	return r.Header.Get(Header)
+}
+
+// Middleware adopts the inbound X-Request-ID (or generates one), stores it in
+// the request context and headers, and echoes it in the response header.
+func Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := sanitize(r.Header.Get(Header))
+			if id == "" {
+				id = New()
+			}
+
+			// Keep the inbound header in sync so proxies and handlers that read
+			// headers directly see the same ID as the context.
+			r.Header.Set(Header, id)
+			w.Header().Set(Header, id)
+
+			next.ServeHTTP(w, r.WithContext(WithContext(r.Context(), id)))
+		})
+	}
+}
+
+// sanitize rejects caller-supplied IDs that are too long or contain characters
+// outside the printable ASCII range, returning an empty string in that case.
+func sanitize(id string) string {
+	if len(id) > maxLength {
+		return ""
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return ""
+		}
+	}
+	return id
+}
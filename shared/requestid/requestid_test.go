@@ -0,0 +1,74 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Run("adopts inbound header", func(t *testing.T) {
+		var got string
+		handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = FromContext(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(Header, "req-abc")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if got != "req-abc" {
+			t.Errorf("expected context ID req-abc, got %q", got)
+		}
+		if rec.Header().Get(Header) != "req-abc" {
+			t.Errorf("expected response header req-abc, got %q", rec.Header().Get(Header))
+		}
+	})
+
+	t.Run("generates ID and syncs request header", func(t *testing.T) {
+		var fromCtx, fromHeader string
+		handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fromCtx = FromContext(r.Context())
+			fromHeader = r.Header.Get(Header)
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if len(fromCtx) != 32 {
+			t.Errorf("expected 32-char generated ID, got %q", fromCtx)
+		}
+		if fromHeader != fromCtx {
+			t.Errorf("expected request header %q to match context %q", fromHeader, fromCtx)
+		}
+	})
+
+	t.Run("replaces malformed inbound ID", func(t *testing.T) {
+		for _, bad := range []string{strings.Repeat("a", maxLength+1), "has space", "line\nbreak"} {
+			var got string
+			handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(Header, bad)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got == bad || got == "" {
+				t.Errorf("expected malformed ID %q to be replaced, got %q", bad, got)
+			}
+		}
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	if id := FromContext(context.Background()); id != "" {
+		t.Errorf("expected empty ID, got %q", id)
+	}
+	if id := FromContext(WithContext(context.Background(), "abc")); id != "abc" {
+		t.Errorf("expected abc, got %q", id)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/requestid"
 )
 
 // Response represents a standardized API response envelope.
@@ -15,6 +16,38 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 	Error   *ErrorData  `json:"error,omitempty"`
 	Meta    *Meta       `json:"meta,omitempty"`
+	Links   Links       `json:"links,omitempty"`
+}
+
+// Link describes an action a client may take on the returned resource.
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method"`
+}
+
+// Links maps a relation name (e.g. "self", "reverse") to its link.
+// Handlers only include actions the caller is permitted to take in the
+// resource's current state, so clients can render them without duplicating
+// authorization logic.
+type Links map[string]Link
+
+// NewLinks creates an empty set of links.
+func NewLinks() Links {
+	return make(Links)
+}
+
+// Add adds a link for the given relation.
+func (l Links) Add(rel, method, href string) Links {
+	l[rel] = Link{Href: href, Method: method}
+	return l
+}
+
+// AddIf adds a link only when allowed is true.
+func (l Links) AddIf(allowed bool, rel, method, href string) Links {
+	if allowed {
+		l.Add(rel, method, href)
+	}
+	return l
 }
 
 // ErrorData contains error information.
@@ -69,24 +102,35 @@ func SuccessWithMeta(w http.ResponseWriter, statusCode int, data interface{}, me
 	})
 }
 
-// Error writes an error response from an errors.Error.
-func Error(w http.ResponseWriter, err *errors.Error) {
-	statusCode := err.HTTPStatusCode()
-
+// SuccessWithLinks writes a success response with action links.
+func SuccessWithLinks(w http.ResponseWriter, statusCode int, data interface{}, links Links) {
 	JSON(w, statusCode, Response{
-		Success: false,
-		Error: &ErrorData{
-			Code:    string(err.Code),
-			Message: err.Message,
-			Details: err.Details,
-		},
+		Success: true,
+		Data:    data,
+		Links:   links,
 	})
 }
 
+// Error writes an error response from an errors.Error.
+// If the request ID middleware has set X-Request-ID on the response, the ID is
+// included in the response metadata so clients can quote it in support requests.
+func Error(w http.ResponseWriter, err *errors.Error) {
+	ErrorWithMeta(w, err, nil)
+}
+
 // ErrorWithMeta writes an error response with metadata.
 func ErrorWithMeta(w http.ResponseWriter, err *errors.Error, meta *Meta) {
 	statusCode := err.HTTPStatusCode()
 
+	if id := w.Header().Get(requestid.Header); id != "" {
+		if meta == nil {
+			meta = &Meta{}
+		}
+		if meta.RequestID == "" {
+			meta.RequestID = id
+		}
+	}
+
 	JSON(w, statusCode, Response{
 		Success: false,
 		Error: &ErrorData{
@@ -138,6 +182,11 @@ func OK(w http.ResponseWriter, data interface{}) {
 	Success(w, http.StatusOK, data)
 }
 
+// OKWithLinks writes a 200 OK response with action links.
+func OKWithLinks(w http.ResponseWriter, data interface{}, links Links) {
+	SuccessWithLinks(w, http.StatusOK, data, links)
+}
+
 // NoContent writes a 204 No Content response.
 func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
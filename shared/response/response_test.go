@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/1mb-dev/nivomoney/shared/errors"
@@ -106,6 +107,43 @@ func TestSuccessWithMeta(t *testing.T) {
 	})
 }
 
+func TestOKWithLinks(t *testing.T) {
+	t.Run("includes only permitted links", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		links := NewLinks().
+			Add("self", http.MethodGet, "/api/v1/things/1").
+			AddIf(true, "archive", http.MethodPost, "/api/v1/things/1/archive").
+			AddIf(false, "delete", http.MethodDelete, "/api/v1/things/1")
+		OKWithLinks(rec, map[string]string{"id": "1"}, links)
+
+		var response Response
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if len(response.Links) != 2 {
+			t.Fatalf("expected 2 links, got %d", len(response.Links))
+		}
+		if response.Links["archive"].Method != http.MethodPost {
+			t.Errorf("expected archive method POST, got %s", response.Links["archive"].Method)
+		}
+		if _, ok := response.Links["delete"]; ok {
+			t.Error("expected delete link to be omitted")
+		}
+	})
+
+	t.Run("omits links section when empty", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		OK(rec, "data")
+
+		if strings.Contains(rec.Body.String(), "links") {
+			t.Errorf("expected no links section, got %s", rec.Body.String())
+		}
+	})
+}
+
 func TestError(t *testing.T) {
 	t.Run("writes error response", func(t *testing.T) {
 		rec := httptest.NewRecorder()
@@ -161,6 +199,20 @@ func TestError(t *testing.T) {
 			t.Error("expected details to be preserved")
 		}
 	})
+
+	t.Run("includes request ID from response header", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("X-Request-ID", "req-456")
+
+		Error(rec, errors.NotFound("wallet"))
+
+		var response Response
+		_ = json.Unmarshal(rec.Body.Bytes(), &response)
+
+		if response.Meta == nil || response.Meta.RequestID != "req-456" {
+			t.Errorf("expected meta request ID req-456, got %+v", response.Meta)
+		}
+	})
 }
 
 func TestErrorWithMeta(t *testing.T) {
@@ -0,0 +1,150 @@
+// Package retry runs operations again after transient failures, waiting with
+// exponential backoff and jitter between attempts.
+//
+// A Policy describes how often and how long to retry; Do runs an operation
+// under a policy and gives up early when the context ends or the error is not
+// retryable:
+//
+//	err := retry.Do(ctx, retry.DefaultPolicy(), func(ctx context.Context) error {
+//		return client.Call(ctx)
+//	})
+//
+// Operations mark failures that must not be retried with Permanent.
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy configures retries.
+type Policy struct {
+	MaxAttempts  int                                               // Total attempts including the first; values below 1 mean one attempt
+	InitialDelay time.Duration                                     // Wait after the first failure
+	MaxDelay     time.Duration                                     // Upper bound on a single wait; zero means unbounded
+	Multiplier   float64                                           // Growth factor between waits; values below 1 mean 2
+	Jitter       float64                                           // Fraction of each wait randomized, 0 (none) to 1 (full jitter)
+	Retryable    func(err error) bool                              // Reports whether an error is worth retrying; nil retries every error
+	OnRetry      func(attempt int, err error, delay time.Duration) // Called before each wait, e.g. for logging
+}
+
+// DefaultPolicy makes 3 attempts waiting 100ms then 200ms, with 20% jitter.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:  3,
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// NoRetry runs an operation exactly once.
+func NoRetry() Policy {
+	return Policy{MaxAttempts: 1}
+}
+
+// Delay returns the wait after the given failed attempt (1 for the first),
+// before jitter.
+func (p Policy) Delay(attempt int) time.Duration {
+	if attempt < 1 || p.InitialDelay <= 0 {
+		return 0
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// jittered spreads a delay randomly over [delay*(1-Jitter), delay].
+func (p Policy) jittered(delay time.Duration) time.Duration {
+	jitter := min(max(p.Jitter, 0), 1)
+	if jitter == 0 || delay <= 0 {
+		return delay
+	}
+	spread := time.Duration(float64(delay) * jitter)
+	if spread <= 0 {
+		return delay
+	}
+	//nolint:gosec // Jitter only needs to decorrelate clients, not be unpredictable
+	return delay - spread + rand.N(spread+1)
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps an error so Do returns it without retrying. Do unwraps it
+// again, so callers see the original error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether an error was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return stderrors.As(err, &p)
+}
+
+// Do runs op until it succeeds, returns a non-retryable error, the attempts
+// run out or ctx ends. It returns nil on success and otherwise the last error
+// from op, or the context's error if ctx ended while waiting.
+func Do(ctx context.Context, p Policy, op func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	})
+	return err
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, p Policy, op func(ctx context.Context) (T, error)) (T, error) {
+	attempts := max(p.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		value, err := op(ctx)
+		if err == nil {
+			return value, nil
+		}
+
+		var permanent *permanentError
+		if stderrors.As(err, &permanent) {
+			return value, permanent.err
+		}
+		if attempt >= attempts || (p.Retryable != nil && !p.Retryable(err)) || ctx.Err() != nil {
+			return value, err
+		}
+
+		delay := p.jittered(p.Delay(attempt))
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
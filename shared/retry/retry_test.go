@@ -0,0 +1,165 @@
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+var errTransient = stderrors.New("transient")
+
+func fastPolicy(attempts int) Policy {
+	return Policy{MaxAttempts: attempts, InitialDelay: time.Millisecond, Multiplier: 2}
+}
+
+func TestDo_SucceedsAfterRetries(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), fastPolicy(3), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDo_ReturnsLastErrorWhenAttemptsRunOut(t *testing.T) {
+	calls := 0
+	var retried []int
+	p := fastPolicy(4)
+	p.OnRetry = func(attempt int, err error, delay time.Duration) {
+		retried = append(retried, attempt)
+	}
+
+	err := Do(context.Background(), p, func(ctx context.Context) error {
+		calls++
+		return errTransient
+	})
+
+	if !stderrors.Is(err, errTransient) {
+		t.Fatalf("expected last error, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 calls, got %d", calls)
+	}
+	if len(retried) != 3 || retried[0] != 1 || retried[2] != 3 {
+		t.Errorf("expected OnRetry for attempts 1-3, got %v", retried)
+	}
+}
+
+func TestDo_StopsOnNonRetryableError(t *testing.T) {
+	errFatal := stderrors.New("fatal")
+	p := fastPolicy(5)
+	p.Retryable = func(err error) bool { return stderrors.Is(err, errTransient) }
+
+	calls := 0
+	err := Do(context.Background(), p, func(ctx context.Context) error {
+		calls++
+		return errFatal
+	})
+
+	if !stderrors.Is(err, errFatal) || calls != 1 {
+		t.Errorf("expected one call returning fatal error, got %d calls and %v", calls, err)
+	}
+}
+
+func TestDo_Permanent(t *testing.T) {
+	errFatal := stderrors.New("fatal")
+	calls := 0
+	err := Do(context.Background(), fastPolicy(5), func(ctx context.Context) error {
+		calls++
+		return Permanent(errFatal)
+	})
+
+	if err != errFatal {
+		t.Errorf("expected the unwrapped error, got %v", err)
+	}
+	if IsPermanent(err) {
+		t.Error("expected Do to strip the permanent marker")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if Permanent(nil) != nil {
+		t.Error("expected Permanent(nil) to be nil")
+	}
+}
+
+func TestDo_ContextCancelledWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{MaxAttempts: 5, InitialDelay: time.Hour}
+	p.OnRetry = func(int, error, time.Duration) { cancel() }
+
+	start := time.Now()
+	err := Do(ctx, p, func(ctx context.Context) error { return errTransient })
+
+	if !stderrors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected cancellation to interrupt the wait")
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	calls := 0
+	got, err := DoValue(context.Background(), fastPolicy(2), func(ctx context.Context) (string, error) {
+		calls++
+		if calls == 1 {
+			return "", errTransient
+		}
+		return "ok", nil
+	})
+
+	if err != nil || got != "ok" {
+		t.Errorf("expected ok, got %q, %v", got, err)
+	}
+}
+
+func TestPolicy_Delay(t *testing.T) {
+	p := Policy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 3}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 100 * time.Millisecond},
+		{2, 300 * time.Millisecond},
+		{3, 900 * time.Millisecond},
+		{4, time.Second},
+		{100, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.Delay(tt.attempt); got != tt.want {
+			t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	// Multiplier defaults to doubling
+	if got := (Policy{InitialDelay: time.Second}).Delay(3); got != 4*time.Second {
+		t.Errorf("expected default multiplier of 2, got %v", got)
+	}
+}
+
+func TestPolicy_Jitter(t *testing.T) {
+	p := Policy{Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		got := p.jittered(time.Second)
+		if got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("jittered delay %v outside [500ms, 1s]", got)
+		}
+	}
+
+	if got := (Policy{}).jittered(time.Second); got != time.Second {
+		t.Errorf("expected no jitter by default, got %v", got)
+	}
+}
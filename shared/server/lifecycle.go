@@ -0,0 +1,155 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+// Worker is background work that must drain before the process exits.
+type Worker interface {
+	// Stop asks the worker to stop taking new work and blocks until in-flight
+	// work finishes or ctx expires, in which case it returns ctx.Err().
+	Stop(ctx context.Context) error
+}
+
+// StopFunc adapts a function to the Worker interface.
+type StopFunc func(ctx context.Context) error
+
+// Stop calls f(ctx).
+func (f StopFunc) Stop(ctx context.Context) error {
+	return f(ctx)
+}
+
+type namedWorker struct {
+	name   string
+	worker Worker
+}
+
+// Lifecycle tracks background workers so shutdown can drain them after the
+// HTTP server stops accepting requests. Workers are stopped in reverse
+// registration order, sharing the shutdown deadline.
+type Lifecycle struct {
+	logger *logger.Logger
+
+	mu       sync.Mutex
+	workers  []namedWorker
+	shutdown bool
+}
+
+// NewLifecycle creates an empty lifecycle manager.
+func NewLifecycle(log *logger.Logger) *Lifecycle {
+	return &Lifecycle{logger: log}
+}
+
+// Register adds a worker to be stopped on shutdown.
+func (l *Lifecycle) Register(name string, w Worker) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.workers = append(l.workers, namedWorker{name: name, worker: w})
+}
+
+// Go runs fn in a goroutine. Its context is cancelled on shutdown and
+// shutdown waits for fn to return. Use Every for periodic jobs that should
+// finish their current run instead of being cancelled.
+func (l *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+
+	l.Register(name, StopFunc(func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	}))
+}
+
+// Every runs job on a fixed interval. On shutdown no new run starts and the
+// current run is allowed to finish; its context is only cancelled once the
+// shutdown deadline expires.
+func (l *Lifecycle) Every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// Shutdown may race with the tick; prefer stopping
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := job(jobCtx); err != nil && l.logger != nil {
+					l.logger.WithError(err).WithField("worker", name).Error("Background job failed")
+				}
+			}
+		}
+	}()
+
+	l.Register(name, StopFunc(func(ctx context.Context) error {
+		close(stop)
+		defer cancelJobs()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+}
+
+// Shutdown stops all registered workers in reverse registration order and
+// returns the joined errors of any that failed to drain in time. It is safe
+// to call more than once; later calls are no-ops.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	if l.shutdown {
+		l.mu.Unlock()
+		return nil
+	}
+	l.shutdown = true
+	workers := l.workers
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(workers) - 1; i >= 0; i-- {
+		w := workers[i]
+		start := time.Now()
+		if err := w.worker.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", w.name, err))
+			if l.logger != nil {
+				l.logger.WithError(err).WithField("worker", w.name).Warn("Background worker did not drain before shutdown deadline")
+			}
+			continue
+		}
+		if l.logger != nil {
+			l.logger.With(map[string]interface{}{
+				"worker":   w.name,
+				"duration": time.Since(start).String(),
+			}).Info("Background worker drained")
+		}
+	}
+
+	return errors.Join(errs...)
+}
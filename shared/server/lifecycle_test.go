@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	t.Run("stops workers in reverse registration order", func(t *testing.T) {
+		l := NewLifecycle(nil)
+		var order []string
+		for _, name := range []string{"first", "second", "third"} {
+			name := name
+			l.Register(name, StopFunc(func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			}))
+		}
+
+		if err := l.Shutdown(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := []string{"third", "second", "first"}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Fatalf("expected order %v, got %v", want, order)
+			}
+		}
+	})
+
+	t.Run("Every lets the in-flight job finish", func(t *testing.T) {
+		l := NewLifecycle(nil)
+		started := make(chan struct{})
+		var finished atomic.Bool
+
+		l.Every("job", time.Millisecond, func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+				return nil
+			}
+			select {
+			case <-time.After(50 * time.Millisecond):
+				finished.Store(true)
+			case <-ctx.Done():
+			}
+			return nil
+		})
+
+		<-started
+		if err := l.Shutdown(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !finished.Load() {
+			t.Error("expected in-flight job to complete before shutdown returned")
+		}
+	})
+
+	t.Run("Every cancels the job when the deadline expires", func(t *testing.T) {
+		l := NewLifecycle(nil)
+		started := make(chan struct{}, 1)
+		cancelled := make(chan struct{})
+
+		l.Every("slow", time.Millisecond, func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		})
+
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := l.Shutdown(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("expected job context to be cancelled after the deadline")
+		}
+	})
+
+	t.Run("Go cancels its context and waits for return", func(t *testing.T) {
+		l := NewLifecycle(nil)
+		var returned atomic.Bool
+
+		l.Go("loop", func(ctx context.Context) {
+			<-ctx.Done()
+			returned.Store(true)
+		})
+
+		if err := l.Shutdown(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !returned.Load() {
+			t.Error("expected worker to return before shutdown completed")
+		}
+	})
+
+	t.Run("second shutdown is a no-op", func(t *testing.T) {
+		l := NewLifecycle(nil)
+		calls := 0
+		l.Register("once", StopFunc(func(ctx context.Context) error {
+			calls++
+			return nil
+		}))
+
+		_ = l.Shutdown(context.Background())
+		_ = l.Shutdown(context.Background())
+
+		if calls != 1 {
+			t.Errorf("expected one stop call, got %d", calls)
+		}
+	})
+}
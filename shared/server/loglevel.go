@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/handler"
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// maxLogLevelTTL bounds how long a temporary log level change may last.
+const maxLogLevelTTL = 24 * time.Hour
+
+// logLevelStatus is the response of the log level endpoint.
+type logLevelStatus struct {
+	Level         string               `json:"level"`
+	Modules       map[string]string    `json:"modules,omitempty"`
+	DebugSampling logger.DebugSampling `json:"debug_sampling"`
+	DroppedDebug  uint64               `json:"dropped_debug"`
+}
+
+// logLevelRequest changes the service's log levels. The levels replace the
+// current ones; an empty level keeps the default. A TTL reverts the levels
+// when it runs out, and sampling is left as is when not given.
+type logLevelRequest struct {
+	Level         string                `json:"level"`
+	Modules       map[string]string     `json:"modules"`
+	DebugSampling *logger.DebugSampling `json:"debug_sampling"`
+	TTLSeconds    int                   `json:"ttl_seconds"`
+}
+
+// currentLogLevels reports a controller's levels and sampling.
+func currentLogLevels(controller *logger.LevelController) logLevelStatus {
+	spec := controller.Spec()
+	return logLevelStatus{
+		Level:         spec.Level,
+		Modules:       spec.Modules,
+		DebugSampling: controller.DebugSampling(),
+		DroppedDebug:  controller.DroppedDebug(),
+	}
+}
+
+// withLogLevelEndpoint serves GET and PUT /internal/v1/log-level, which read
+// and change the service's log levels without a restart, in front of the
+// service handler.
+func withLogLevelEndpoint(next http.Handler, controller *logger.LevelController, internalSecret string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /internal/v1/log-level", middleware.InternalAuthFunc(internalSecret, func(w http.ResponseWriter, r *http.Request) {
+		response.OK(w, currentLogLevels(controller))
+	}))
+	mux.HandleFunc("PUT /internal/v1/log-level", middleware.InternalAuthFunc(internalSecret, func(w http.ResponseWriter, r *http.Request) {
+		req, bindErr := handler.BindRequest[logLevelRequest](r)
+		if bindErr != nil {
+			response.Error(w, bindErr)
+			return
+		}
+
+		ttl := time.Duration(req.TTLSeconds) * time.Second
+		if req.TTLSeconds < 0 || ttl > maxLogLevelTTL {
+			response.Error(w, errors.Validation(fmt.Sprintf("ttl_seconds must be between 0 and %d", int(maxLogLevelTTL/time.Second))))
+			return
+		}
+		if s := req.DebugSampling; s != nil && (s.Burst < 0 || s.Every < 0) {
+			response.Error(w, errors.Validation("debug_sampling burst and every must not be negative"))
+			return
+		}
+
+		spec := logger.LevelSpec{Level: req.Level, Modules: req.Modules}
+		var err error
+		if ttl > 0 {
+			err = controller.ApplyFor(spec, ttl)
+		} else {
+			err = controller.Apply(spec)
+		}
+		if err != nil {
+			response.Error(w, errors.Validation(err.Error()))
+			return
+		}
+		if req.DebugSampling != nil {
+			_ = controller.SetDebugSampling(*req.DebugSampling) // Validated above
+		}
+
+		response.OK(w, currentLogLevels(controller))
+	}))
+	mux.Handle("/", next)
+	return mux
+}
+
+// logLevelReloader changes log levels on SIGHUP. With a file it reloads the
+// levels from it, written as a spec such as "info,wallet.upi=debug" with one
+// or more parts per line and # comments. Without one it toggles debug
+// logging on and off.
+type logLevelReloader struct {
+	controller *logger.LevelController
+	file       string
+	previous   string // Default level to return to when debug is toggled off
+}
+
+// reload applies one SIGHUP and returns the resulting spec.
+func (r *logLevelReloader) reload() (logger.LevelSpec, error) {
+	if r.file == "" {
+		if r.controller.Level() == "debug" {
+			previous := r.previous
+			if previous == "" {
+				previous = "info"
+			}
+			_ = r.controller.SetLevel(previous)
+		} else {
+			r.previous = r.controller.Level()
+			_ = r.controller.SetLevel("debug")
+		}
+		return r.controller.Spec(), nil
+	}
+
+	content, err := os.ReadFile(r.file)
+	if err != nil {
+		return logger.LevelSpec{}, fmt.Errorf("failed to read log level file: %w", err)
+	}
+	var parts []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		parts = append(parts, line)
+	}
+	spec, err := logger.ParseLevelSpec(strings.Join(parts, ","))
+	if err != nil {
+		return logger.LevelSpec{}, err
+	}
+	if err := r.controller.Apply(spec); err != nil {
+		return logger.LevelSpec{}, err
+	}
+	return r.controller.Spec(), nil
+}
+
+// reloadLogLevelsOnHangup reloads log levels on every SIGHUP until ctx ends.
+func reloadLogLevelsOnHangup(ctx context.Context, reloader *logLevelReloader, log *logger.Logger) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			spec, err := reloader.reload()
+			if err != nil {
+				log.WithError(err).Warn("Failed to reload log levels")
+				continue
+			}
+			log.WithField("levels", spec.String()).Info("Log levels reloaded")
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+)
+
+func TestWithLogLevelEndpoint(t *testing.T) {
+	controller := logger.NewLevelController("info")
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := withLogLevelEndpoint(next, controller, "secret")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Internal-Secret", "secret")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/wallets", ""); rec.Code != http.StatusTeapot {
+		t.Errorf("expected other routes to pass through, got %d", rec.Code)
+	}
+	unauthenticated := httptest.NewRecorder()
+	handler.ServeHTTP(unauthenticated, httptest.NewRequest(http.MethodGet, "/internal/v1/log-level", nil))
+	if unauthenticated.Code != http.StatusUnauthorized {
+		t.Errorf("expected the internal secret to be required, got %d", unauthenticated.Code)
+	}
+
+	rec := do(http.MethodPut, "/internal/v1/log-level", `{"level":"warn","modules":{"wallet.upi":"debug"},"debug_sampling":{"burst":10,"every":50}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	if spec := controller.Spec().String(); spec != "warn,wallet.upi=debug" {
+		t.Errorf("unexpected levels: %s", spec)
+	}
+	if sampling := controller.DebugSampling(); sampling != (logger.DebugSampling{Burst: 10, Every: 50}) {
+		t.Errorf("unexpected sampling: %+v", sampling)
+	}
+
+	rec = do(http.MethodGet, "/internal/v1/log-level", "")
+	var body struct {
+		Data logLevelStatus `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Data.Level != "warn" || body.Data.Modules["wallet.upi"] != "debug" {
+		t.Errorf("unexpected status: %s (%v)", rec.Body.String(), err)
+	}
+
+	for _, invalid := range []string{`{"level":"loud"}`, `{"ttl_seconds":-1}`, `{"debug_sampling":{"burst":-1}}`} {
+		if rec := do(http.MethodPut, "/internal/v1/log-level", invalid); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected %s to be rejected, got %d", invalid, rec.Code)
+		}
+	}
+	if spec := controller.Spec().String(); spec != "warn,wallet.upi=debug" {
+		t.Errorf("expected rejected changes to leave the levels, got %s", spec)
+	}
+}
+
+func TestLogLevelReloader(t *testing.T) {
+	controller := logger.NewLevelController("warn")
+
+	// Without a file SIGHUP toggles debug
+	toggle := &logLevelReloader{controller: controller}
+	if _, err := toggle.reload(); err != nil || controller.Level() != "debug" {
+		t.Fatalf("expected debug on, got %s (%v)", controller.Level(), err)
+	}
+	if _, err := toggle.reload(); err != nil || controller.Level() != "warn" {
+		t.Fatalf("expected the previous level back, got %s (%v)", controller.Level(), err)
+	}
+
+	file := filepath.Join(t.TempDir(), "log-levels")
+	if err := os.WriteFile(file, []byte("# levels\ninfo\nwallet.upi=debug, ledger=error\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	reloader := &logLevelReloader{controller: controller, file: file}
+	spec, err := reloader.reload()
+	if err != nil || spec.String() != "info,ledger=error,wallet.upi=debug" {
+		t.Fatalf("unexpected levels: %s (%v)", spec, err)
+	}
+
+	if err := os.WriteFile(file, []byte("wallet=loud\n"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := reloader.reload(); err == nil {
+		t.Error("expected an invalid file to be rejected")
+	}
+	if spec := controller.Spec().String(); spec != "info,ledger=error,wallet.upi=debug" {
+		t.Errorf("expected an invalid file to leave the levels, got %s", spec)
+	}
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/1mb-dev/nivomoney/shared/database"
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/response"
+)
+
+// migrateUsage documents the migrate subcommand.
+const migrateUsage = `usage: server migrate <command>
+
+commands:
+  up [N]          apply all pending migrations, or the next N
+  down [N]        roll back the last applied migration, or the last N
+  status          list migrations and whether they are applied
+  force VERSION   record VERSION as the latest applied migration and clear
+                  the dirty flag without running SQL ("none" for no migrations)`
+
+// newMigrator returns the migrator for the service's migrations directory, or
+// nil when the directory does not exist.
+func newMigrator(db *database.DB) *database.Migrator {
+	migrationsDir := GetEnv("MIGRATIONS_DIR", "./migrations")
+	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
+		return nil
+	}
+	return database.NewMigrator(db.DB, migrationsDir)
+}
+
+// runMigrateCommand runs the operator migrate subcommand, e.g.
+// "server migrate down 1", and writes its result to out.
+func runMigrateCommand(migrator *database.Migrator, args []string, out io.Writer) error {
+	if migrator == nil {
+		return fmt.Errorf("migrations directory not found, set MIGRATIONS_DIR")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("missing command\n%s", migrateUsage)
+	}
+
+	count := func(defaultCount int) (int, error) {
+		if len(args) < 2 {
+			return defaultCount, nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("step count must be a positive integer, got %q", args[1])
+		}
+		return n, nil
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := count(0)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Steps(n); err != nil {
+			return err
+		}
+	case "down":
+		n, err := count(1)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Steps(-n); err != nil {
+			return err
+		}
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("force requires a version\n%s", migrateUsage)
+		}
+		version := args[1]
+		if version == "none" {
+			version = ""
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], migrateUsage)
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	printMigrationStatus(out, status)
+	return nil
+}
+
+// printMigrationStatus writes a migration status table.
+func printMigrationStatus(out io.Writer, status *database.MigrationStatus) {
+	version := status.Version
+	if version == "" {
+		version = "none"
+	}
+	_, _ = fmt.Fprintf(out, "version: %s  dirty: %t  pending: %d\n\n", version, status.Dirty, status.Pending)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "MIGRATION\tSTATE\tAPPLIED AT\tDOWN")
+	for _, m := range status.Migrations {
+		state, appliedAt := "pending", "-"
+		if m.Applied {
+			state = "applied"
+			appliedAt = m.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		if m.Dirty {
+			state = "dirty"
+		}
+		down := "no"
+		if m.HasDown {
+			down = "yes"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", m.Version, state, appliedAt, down)
+	}
+	_ = tw.Flush()
+}
+
+// withMigrationsEndpoint serves GET /internal/v1/migrations, the service's
+// migration version stamp, in front of the service handler.
+func withMigrationsEndpoint(handler http.Handler, migrator *database.Migrator, internalSecret string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /internal/v1/migrations", middleware.InternalAuthFunc(internalSecret, func(w http.ResponseWriter, r *http.Request) {
+		if migrator == nil {
+			response.Error(w, errors.NotFound("migrations"))
+			return
+		}
+		status, err := migrator.Status()
+		if err != nil {
+			response.Error(w, errors.DatabaseWrap(err, "failed to read migration status"))
+			return
+		}
+		response.OK(w, status)
+	}))
+	mux.Handle("/", handler)
+	return mux
+}
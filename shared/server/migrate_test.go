@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/database"
+)
+
+func TestRunMigrateCommand_Arguments(t *testing.T) {
+	migrator := database.NewMigrator(nil, t.TempDir())
+
+	tests := map[string][]string{
+		"missing command":   {},
+		"unknown command":   {"sideways"},
+		"invalid count":     {"down", "zero"},
+		"non-positive step": {"up", "0"},
+		"force without arg": {"force"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := runMigrateCommand(migrator, args, &bytes.Buffer{}); err == nil {
+				t.Errorf("expected error for %v", args)
+			}
+		})
+	}
+
+	if err := runMigrateCommand(nil, []string{"status"}, &bytes.Buffer{}); err == nil {
+		t.Error("expected error without a migrations directory")
+	}
+}
+
+func TestPrintMigrationStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var out bytes.Buffer
+	printMigrationStatus(&out, &database.MigrationStatus{
+		Version: "001_a.up.sql",
+		Pending: 1,
+		Migrations: []database.MigrationInfo{
+			{Version: "001_a.up.sql", Applied: true, AppliedAt: &appliedAt, HasDown: true},
+			{Version: "002_b.up.sql"},
+		},
+	})
+
+	got := out.String()
+	for _, want := range []string{"version: 001_a.up.sql", "pending: 1", "applied", "2026-01-02 03:04:05 UTC", "002_b.up.sql  pending"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWithMigrationsEndpoint(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	handler := withMigrationsEndpoint(next, nil, "secret")
+
+	tests := []struct {
+		name   string
+		path   string
+		secret string
+		want   int
+	}{
+		{"passes other routes through", "/api/v1/wallets", "", http.StatusTeapot},
+		{"requires the internal secret", "/internal/v1/migrations", "", http.StatusUnauthorized},
+		{"reports missing migrations", "/internal/v1/migrations", "secret", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.secret != "" {
+				req.Header.Set("X-Internal-Secret", tt.secret)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -7,12 +7,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/1mb-dev/nivomoney/shared/config"
 	"github.com/1mb-dev/nivomoney/shared/database"
 	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/1mb-dev/nivomoney/shared/metrics"
+	"github.com/1mb-dev/nivomoney/shared/middleware"
+	"github.com/1mb-dev/nivomoney/shared/serviceauth"
 )
 
 // HTTP server timeouts
@@ -28,6 +32,10 @@ type BootstrapContext struct {
 	Logger *logger.Logger
 	Config *config.Config
 	DB     *database.DB
+
+	// Lifecycle registers background workers that are drained on shutdown,
+	// after the HTTP server stops and before Cleanup runs.
+	Lifecycle *Lifecycle
 }
 
 // ServiceConfig defines how to bootstrap and run a service.
@@ -39,7 +47,12 @@ type ServiceConfig struct {
 	// repositories, services, and handlers. Returns the HTTP handler.
 	SetupHandler func(ctx *BootstrapContext) (http.Handler, error)
 
-	// Cleanup is called during graceful shutdown (optional).
+	// InternalPolicy lists which services may call which /internal paths
+	// once service tokens are enabled with SERVICE_TOKEN_SECRET (optional).
+	InternalPolicy serviceauth.Policy
+
+	// Cleanup is called during graceful shutdown (optional), after
+	// background workers have drained.
 	// Use this for closing additional resources like Redis connections.
 	Cleanup func() error
 }
@@ -59,6 +72,7 @@ func Run(cfg ServiceConfig) {
 	appLogger.Info("Starting " + cfg.Name + " service...")
 	appLogger.WithField("environment", appConfig.Environment).Info("Environment configured")
 	appLogger.WithField("port", appConfig.ServicePort).Info("Port configured")
+	appLogger.WithField("backend", appConfig.Secrets.Provider().Name()).Info("Secrets backend configured")
 
 	// Connect to database
 	db, err := database.NewFromURL(appConfig.DatabaseURL)
@@ -69,18 +83,38 @@ func Run(cfg ServiceConfig) {
 
 	appLogger.Info("Connected to database successfully")
 
+	configureDatabase(db, appConfig, cfg.Name, appLogger)
+
+	migrator := newMigrator(db)
+
+	// Operator subcommand: "server migrate up|down|status|force" runs and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(migrator, os.Args[2:], os.Stdout); err != nil {
+			appLogger.Fatalf("Migrate command failed: %v", err)
+		}
+		return
+	}
+
 	// Run migrations
-	if err := runMigrations(db, appLogger); err != nil {
+	if err := runMigrations(migrator, appLogger); err != nil {
 		appLogger.Fatalf("Failed to run migrations: %v", err)
 	}
 
 	appLogger.Info("Database migrations completed")
 
 	// Create bootstrap context for service-specific setup
+	lifecycle := NewLifecycle(appLogger)
 	ctx := &BootstrapContext{
-		Logger: appLogger,
-		Config: appConfig,
-		DB:     db,
+		Logger:    appLogger,
+		Config:    appConfig,
+		DB:        db,
+		Lifecycle: lifecycle,
+	}
+
+	// Internal clients created during setup sign their requests as this service
+	serviceTokenSecret := GetSecret("SERVICE_TOKEN_SECRET", "")
+	if serviceTokenSecret != "" {
+		serviceauth.SetDefaultSigner(serviceauth.NewSigner(cfg.Name, serviceTokenSecret, serviceauth.DefaultTTL))
 	}
 
 	// Call service-specific setup
@@ -89,6 +123,40 @@ func Run(cfg ServiceConfig) {
 		appLogger.Fatalf("Failed to setup service: %v", err)
 	}
 
+	watchSecrets(lifecycle, appConfig, appLogger)
+
+	// Export pool stats to the collector created during setup
+	if appConfig.DatabaseStatsInterval > 0 {
+		lifecycle.Every("db-stats", appConfig.DatabaseStatsInterval, func(ctx context.Context) error {
+			if collector := metrics.Default(); collector != nil {
+				collector.RecordDBStats(db.Stats())
+			}
+			return nil
+		})
+	}
+
+	// Every /internal route needs a token from an allowed caller
+	if serviceTokenSecret != "" {
+		handler = middleware.ServiceAuth(middleware.ServiceAuthConfig{
+			Verifier: serviceauth.NewVerifier(serviceTokenSecret),
+			Policy:   cfg.InternalPolicy,
+		})(handler)
+		appLogger.Info("Service token authentication enabled for internal endpoints")
+	} else {
+		appLogger.Warn("SERVICE_TOKEN_SECRET not set; internal endpoints accept unsigned requests")
+	}
+
+	// Expose the migration version stamp to operators
+	internalSecret := GetSecret("INTERNAL_SERVICE_SECRET", "")
+	handler = withMigrationsEndpoint(handler, migrator, internalSecret)
+
+	// Let operators change log levels at runtime, over HTTP or with SIGHUP
+	handler = withLogLevelEndpoint(handler, appLogger.Controller(), internalSecret)
+	reloader := &logLevelReloader{controller: appLogger.Controller(), file: GetEnv("LOG_LEVEL_FILE", "")}
+	lifecycle.Go("log-level-reload", func(ctx context.Context) {
+		reloadLogLevelsOnHangup(ctx, reloader, appLogger)
+	})
+
 	// Create HTTP server
 	addr := fmt.Sprintf(":%d", appConfig.ServicePort)
 	srv := &http.Server{
@@ -115,7 +183,7 @@ func Run(cfg ServiceConfig) {
 	<-quit
 	appLogger.Info("Shutting down server...")
 
-	// Create shutdown context with timeout
+	// Create shutdown context with timeout, shared by HTTP and background draining
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
 	defer cancel()
 
@@ -124,6 +192,11 @@ func Run(cfg ServiceConfig) {
 		appLogger.WithError(err).Warn("Server forced to shutdown")
 	}
 
+	// Drain background workers; in-flight jobs may still need the database
+	if err := lifecycle.Shutdown(shutdownCtx); err != nil {
+		appLogger.WithError(err).Warn("Background workers forced to stop")
+	}
+
 	// Run custom cleanup if provided
 	if cfg.Cleanup != nil {
 		if err := cfg.Cleanup(); err != nil {
@@ -134,19 +207,36 @@ func Run(cfg ServiceConfig) {
 	appLogger.Info("Server stopped gracefully")
 }
 
-// runMigrations runs database migrations for the service.
-func runMigrations(db *database.DB, log *logger.Logger) error {
-	// Get migrations directory path
-	migrationsDir := GetEnv("MIGRATIONS_DIR", "./migrations")
+// configureDatabase applies the pool settings and slow query logging from
+// the environment.
+func configureDatabase(db *database.DB, appConfig *config.Config, serviceName string, log *logger.Logger) {
+	db.SetPool(database.PoolConfig{
+		MaxOpenConns:    appConfig.DatabaseMaxOpenConns,
+		MaxIdleConns:    appConfig.DatabaseMaxIdleConns,
+		ConnMaxLifetime: appConfig.DatabaseConnMaxLifetime,
+		ConnMaxIdleTime: appConfig.DatabaseConnMaxIdleTime,
+	})
+
+	db.SetSlowQueryLog(appConfig.DatabaseSlowQueryThreshold, func(ctx context.Context, query string, elapsed time.Duration) {
+		queryType := database.QueryType(query)
+		log.WithField("query", query).
+			WithField("query_type", queryType).
+			WithField("elapsed_ms", elapsed.Milliseconds()).
+			Warn("Slow database query")
+		if collector := metrics.Default(); collector != nil {
+			collector.RecordSlowQuery(serviceName, queryType)
+		}
+	})
+}
 
-	// Check if migrations directory exists
-	if _, err := os.Stat(migrationsDir); os.IsNotExist(err) {
-		log.WithField("dir", migrationsDir).Info("Migrations directory not found, skipping migrations")
+// runMigrations runs database migrations for the service.
+func runMigrations(migrator *database.Migrator, log *logger.Logger) error {
+	if migrator == nil {
+		log.WithField("dir", GetEnv("MIGRATIONS_DIR", "./migrations")).Info("Migrations directory not found, skipping migrations")
 		return nil
 	}
 
 	// Run migrations
-	migrator := database.NewMigrator(db.DB, migrationsDir)
 	if err := migrator.Up(); err != nil {
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
@@ -162,12 +252,60 @@ func GetEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
-// RequireEnv returns the environment variable value or panics if not set.
+// GetEnvInt returns the environment variable as an int, or a default value
+// when it is unset or not a number.
+func GetEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// RequireEnv returns the environment variable value, or the secret of that
+// name from the secrets backend, or panics if neither is set.
 // Use this for required configuration like JWT_SECRET.
 func RequireEnv(key string) string {
 	value := os.Getenv(key)
+	if value == "" {
+		value = config.Secret(key)
+	}
 	if value == "" {
 		panic(fmt.Sprintf("%s environment variable is required and must not be empty", key))
 	}
 	return value
 }
+
+// GetSecret returns the secret key from the secrets backend, falling back to
+// the environment, or a default value.
+func GetSecret(key, defaultValue string) string {
+	if value := config.Secret(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// startupSecrets are read once while a service starts, so a rotated value
+// only takes effect after a restart.
+var startupSecrets = []string{
+	"JWT_SECRET",
+	"DATABASE_PASSWORD",
+	"DATABASE_URL",
+	"SERVICE_TOKEN_SECRET",
+	"INTERNAL_SERVICE_SECRET",
+}
+
+// watchSecrets re-reads secrets every SECRETS_REFRESH_INTERVAL, so values
+// read through config.Secret follow rotations, and warns when a secret this
+// service only reads at startup has rotated.
+func watchSecrets(lifecycle *Lifecycle, appConfig *config.Config, log *logger.Logger) {
+	if appConfig.SecretsRefreshInterval <= 0 {
+		return
+	}
+
+	for _, key := range startupSecrets {
+		appConfig.Secrets.OnRotate(key, func(string) {
+			log.WithField("secret", key).Warn("Secret rotated; restart the service to apply it")
+		})
+	}
+	lifecycle.Every("secrets-refresh", appConfig.SecretsRefreshInterval, appConfig.Secrets.Refresh)
+}
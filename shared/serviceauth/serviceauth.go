@@ -0,0 +1,176 @@
+// Package serviceauth issues and verifies the short-lived tokens services use
+// to identify themselves when calling each other's /internal endpoints.
+//
+// Every service signs tokens with the same key (SERVICE_TOKEN_SECRET). A token
+// names the calling service and expires after a few minutes; the receiving
+// service checks it and its Policy decides which callers may use which paths.
+package serviceauth
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Header carries the service token on internal requests.
+	Header = "X-Service-Token"
+
+	// Issuer is the issuer of every service token.
+	Issuer = "nivo-services"
+
+	// DefaultTTL is how long a service token is valid.
+	DefaultTTL = 5 * time.Minute
+
+	// clockSkew is tolerated between the signing and verifying hosts.
+	clockSkew = 30 * time.Second
+)
+
+// Claims are the claims of a service token.
+type Claims struct {
+	Service string `json:"service"`
+	jwt.RegisteredClaims
+}
+
+// Signer mints tokens for one service. Tokens are cached and reused until
+// half their lifetime has passed.
+type Signer struct {
+	service string
+	key     []byte
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+// NewSigner creates a signer for service using the shared secret.
+func NewSigner(service, secret string, ttl time.Duration) *Signer {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Signer{service: service, key: []byte(secret), ttl: ttl}
+}
+
+// Service returns the name of the service the signer identifies.
+func (s *Signer) Service() string {
+	return s.service
+}
+
+// Token returns a valid token for the service.
+func (s *Signer) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	claims := Claims{
+		Service: s.service,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   s.service,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.ttl)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.refreshAt = now.Add(s.ttl / 2)
+	return token, nil
+}
+
+// Verifier checks service tokens signed with the shared secret.
+type Verifier struct {
+	key []byte
+}
+
+// NewVerifier creates a verifier for the shared secret.
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{key: []byte(secret)}
+}
+
+// Verify checks a token and returns the name of the service that signed it.
+func (v *Verifier) Verify(tokenString string) (string, *errors.Error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return v.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil || !token.Valid {
+		return "", errors.Unauthorized("invalid service token")
+	}
+	if claims.Service == "" || claims.Service != claims.Subject {
+		return "", errors.Unauthorized("invalid service token")
+	}
+	return claims.Service, nil
+}
+
+// Policy decides which services may call which internal paths.
+type Policy struct {
+	// Callers maps an /internal path prefix to the services allowed to call
+	// it. The longest matching prefix applies; paths that match no prefix
+	// accept any service with a valid token.
+	Callers map[string][]string
+
+	// Exempt lists /internal path prefixes that keep their own
+	// authentication, such as receipts posted by external providers.
+	Exempt []string
+}
+
+// IsExempt reports whether path does not need a service token.
+func (p Policy) IsExempt(path string) bool {
+	for _, prefix := range p.Exempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether caller may call path.
+func (p Policy) Allows(path, caller string) bool {
+	var allowed []string
+	matched := ""
+	for prefix, services := range p.Callers {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			matched, allowed = prefix, services
+		}
+	}
+	if matched == "" {
+		return true
+	}
+	for _, service := range allowed {
+		if service == caller {
+			return true
+		}
+	}
+	return false
+}
+
+var defaultSigner atomic.Pointer[Signer]
+
+// SetDefaultSigner sets the signer internal clients attach tokens with.
+// server.Run sets it when SERVICE_TOKEN_SECRET is configured.
+func SetDefaultSigner(s *Signer) {
+	defaultSigner.Store(s)
+}
+
+// DefaultSigner returns the signer set by SetDefaultSigner, or nil.
+func DefaultSigner() *Signer {
+	return defaultSigner.Load()
+}
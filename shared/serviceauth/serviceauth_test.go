@@ -0,0 +1,112 @@
+package serviceauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSignerAndVerifier(t *testing.T) {
+	signer := NewSigner("transaction", "secret", time.Minute)
+	token, err := signer.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+
+	caller, verifyErr := NewVerifier("secret").Verify(token)
+	if verifyErr != nil {
+		t.Fatalf("Verify failed: %v", verifyErr)
+	}
+	if caller != "transaction" {
+		t.Errorf("expected caller transaction, got %s", caller)
+	}
+
+	if _, verifyErr := NewVerifier("other-secret").Verify(token); verifyErr == nil {
+		t.Error("expected token signed with another key to be rejected")
+	}
+}
+
+func TestSigner_CachesToken(t *testing.T) {
+	signer := NewSigner("wallet", "secret", time.Minute)
+	first, _ := signer.Token()
+	second, _ := signer.Token()
+	if first != second {
+		t.Error("expected token to be reused within half its lifetime")
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	sign := func(claims Claims, method jwt.SigningMethod) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return token
+	}
+	now := time.Now()
+	valid := jwt.RegisteredClaims{
+		Issuer:    Issuer,
+		Subject:   "wallet",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+	}
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+	noExpiry := valid
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := valid
+	wrongIssuer.Issuer = "someone-else"
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"garbage", "not-a-token"},
+		{"expired", sign(Claims{Service: "wallet", RegisteredClaims: expired}, jwt.SigningMethodHS256)},
+		{"no expiry", sign(Claims{Service: "wallet", RegisteredClaims: noExpiry}, jwt.SigningMethodHS256)},
+		{"wrong issuer", sign(Claims{Service: "wallet", RegisteredClaims: wrongIssuer}, jwt.SigningMethodHS256)},
+		{"service does not match subject", sign(Claims{Service: "ledger", RegisteredClaims: valid}, jwt.SigningMethodHS256)},
+		{"other algorithm", sign(Claims{Service: "wallet", RegisteredClaims: valid}, jwt.SigningMethodHS512)},
+	}
+	verifier := NewVerifier("secret")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := verifier.Verify(tt.token); err == nil {
+				t.Error("expected token to be rejected")
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := Policy{
+		Callers: map[string][]string{
+			"/internal/v1/wallets":          {"identity", "transaction"},
+			"/internal/v1/wallets/transfer": {"transaction"},
+		},
+		Exempt: []string{"/internal/v1/callbacks"},
+	}
+
+	tests := []struct {
+		path, caller string
+		want         bool
+	}{
+		{"/internal/v1/wallets", "identity", true},
+		{"/internal/v1/wallets/123/info", "transaction", true},
+		{"/internal/v1/wallets/123/info", "cardnetwork", false},
+		{"/internal/v1/wallets/transfer", "identity", false}, // Longest prefix wins
+		{"/internal/v1/wallets/transfer", "transaction", true},
+		{"/internal/v1/other", "anyone", true},
+	}
+	for _, tt := range tests {
+		if got := policy.Allows(tt.path, tt.caller); got != tt.want {
+			t.Errorf("Allows(%s, %s) = %v, want %v", tt.path, tt.caller, got, tt.want)
+		}
+	}
+
+	if !policy.IsExempt("/internal/v1/callbacks/sms") || policy.IsExempt("/internal/v1/wallets") {
+		t.Error("unexpected exemption result")
+	}
+}
@@ -377,164 +377,10 @@ func registerFintechValidators() {
 
 // registerIndiaValidators registers India-specific validators for financial compliance.
 func registerIndiaValidators() {
-	// IFSC code validator - Indian Financial System Code (11 characters)
-	// Format: 4 bank code + 0 (reserved) + 6 branch code
-	// Example: SBIN0001234
-	model.RegisterGlobalFunc("ifsc", func(fieldName string, value interface{}, params map[string]interface{}) error {
-		str, ok := value.(string)
-		if !ok {
-			return model.NewValidationError(fieldName, value, "ifsc", "IFSC code must be a string")
-		}
-
-		if str == "" {
-			return nil // Empty handled by required
-		}
-
-		// Must be exactly 11 characters
-		if len(str) != 11 {
-			return model.NewValidationError(fieldName, value, "ifsc",
-				"IFSC code must be exactly 11 characters")
-		}
-
-		// First 4 characters must be letters (bank code)
-		for i := 0; i < 4; i++ {
-			if !isLetter(rune(str[i])) {
-				return model.NewValidationError(fieldName, value, "ifsc",
-					"IFSC code must start with 4 letters (bank code)")
-			}
-		}
-
-		// 5th character must be 0 (reserved)
-		if str[4] != '0' {
-			return model.NewValidationError(fieldName, value, "ifsc",
-				"IFSC code 5th character must be 0")
-		}
-
-		// Last 6 characters must be alphanumeric (branch code)
-		ifscRegex := regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
-		if !ifscRegex.MatchString(strings.ToUpper(str)) {
-			return model.NewValidationError(fieldName, value, "ifsc",
-				"invalid IFSC code format (expected: BANK0BRANCH)")
-		}
-
-		return nil
-	})
-
-	// UPI ID validator - Unified Payments Interface ID
-	// Format: username@bankcode
-	// Example: user@paytm, john.doe@okaxis
-	model.RegisterGlobalFunc("upi_id", func(fieldName string, value interface{}, params map[string]interface{}) error {
-		str, ok := value.(string)
-		if !ok {
-			return model.NewValidationError(fieldName, value, "upi_id", "UPI ID must be a string")
-		}
-
-		if str == "" {
-			return nil // Empty handled by required
-		}
-
-		// Must contain exactly one @
-		parts := strings.Split(str, "@")
-		if len(parts) != 2 {
-			return model.NewValidationError(fieldName, value, "upi_id",
-				"UPI ID must be in format username@bankcode")
-		}
-
-		username, bankcode := parts[0], parts[1]
-
-		// Username validation (alphanumeric, dots, hyphens, underscores)
-		if username == "" {
-			return model.NewValidationError(fieldName, value, "upi_id",
-				"UPI ID username cannot be empty")
-		}
-
-		usernameRegex := regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
-		if !usernameRegex.MatchString(username) {
-			return model.NewValidationError(fieldName, value, "upi_id",
-				"UPI ID username must contain only letters, numbers, dots, hyphens, or underscores")
-		}
-
-		// Bank code validation (alphanumeric only)
-		if bankcode == "" {
-			return model.NewValidationError(fieldName, value, "upi_id",
-				"UPI ID bank code cannot be empty")
-		}
-
-		bankcodeRegex := regexp.MustCompile(`^[a-z0-9]+$`)
-		if !bankcodeRegex.MatchString(bankcode) {
-			return model.NewValidationError(fieldName, value, "upi_id",
-				"UPI ID bank code must contain only lowercase letters and numbers")
-		}
-
-		return nil
-	})
-
-	// PAN card validator - Permanent Account Number
-	// Format: 5 letters + 4 digits + 1 letter (10 characters total)
-	// Example: ABCDE1234F
-	model.RegisterGlobalFunc("pan", func(fieldName string, value interface{}, params map[string]interface{}) error {
-		str, ok := value.(string)
-		if !ok {
-			return model.NewValidationError(fieldName, value, "pan", "PAN must be a string")
-		}
-
-		if str == "" {
-			return nil // Empty handled by required
-		}
-
-		// Must be exactly 10 characters
-		if len(str) != 10 {
-			return model.NewValidationError(fieldName, value, "pan",
-				"PAN must be exactly 10 characters")
-		}
-
-		// Validate PAN format: 5 letters + 4 digits + 1 letter (must be uppercase)
-		panRegex := regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
-		if !panRegex.MatchString(str) {
-			return model.NewValidationError(fieldName, value, "pan",
-				"invalid PAN format (expected: ABCDE1234F in uppercase)")
-		}
-
-		return nil
-	})
-
-	// Aadhaar validator - Unique Identification Number
-	// Format: 12 digits
-	// Example: 123456789012
-	model.RegisterGlobalFunc("aadhaar", func(fieldName string, value interface{}, params map[string]interface{}) error {
-		str, ok := value.(string)
-		if !ok {
-			return model.NewValidationError(fieldName, value, "aadhaar", "Aadhaar must be a string")
-		}
-
-		if str == "" {
-			return nil // Empty handled by required
-		}
-
-		// Remove spaces if any (Aadhaar is sometimes formatted as XXXX XXXX XXXX)
-		str = strings.ReplaceAll(str, " ", "")
-
-		// Must be exactly 12 digits
-		if len(str) != 12 {
-			return model.NewValidationError(fieldName, value, "aadhaar",
-				"Aadhaar must be exactly 12 digits")
-		}
-
-		// All characters must be digits
-		aadhaarRegex := regexp.MustCompile(`^[0-9]{12}$`)
-		if !aadhaarRegex.MatchString(str) {
-			return model.NewValidationError(fieldName, value, "aadhaar",
-				"Aadhaar must contain only digits")
-		}
-
-		// Aadhaar cannot start with 0 or 1
-		if str[0] == '0' || str[0] == '1' {
-			return model.NewValidationError(fieldName, value, "aadhaar",
-				"Aadhaar cannot start with 0 or 1")
-		}
-
-		return nil
-	})
+	model.RegisterGlobalFunc("ifsc", validateIFSC)
+	model.RegisterGlobalFunc("upi_id", validateUPIID)
+	model.RegisterGlobalFunc("pan", validatePAN)
+	model.RegisterGlobalFunc("aadhaar", validateAadhaar)
 
 	// Indian phone number validator
 	// Format: +91 followed by 10 digits (optional hyphens/spaces)
@@ -613,6 +459,248 @@ func registerIndiaValidators() {
 	})
 }
 
+// IFSC code validator - Indian Financial System Code (11 characters)
+// Format: 4 bank code + 0 (reserved) + 6 branch code
+// Example: SBIN0001234
+func validateIFSC(fieldName string, value interface{}, params map[string]interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return model.NewValidationError(fieldName, value, "ifsc", "IFSC code must be a string")
+	}
+
+	if str == "" {
+		return nil // Empty handled by required
+	}
+
+	// Must be exactly 11 characters
+	if len(str) != 11 {
+		return model.NewValidationError(fieldName, value, "ifsc",
+			"IFSC code must be exactly 11 characters")
+	}
+
+	// First 4 characters must be letters (bank code)
+	for i := 0; i < 4; i++ {
+		if !isLetter(rune(str[i])) {
+			return model.NewValidationError(fieldName, value, "ifsc",
+				"IFSC code must start with 4 letters (bank code)")
+		}
+	}
+
+	// 5th character must be 0 (reserved)
+	if str[4] != '0' {
+		return model.NewValidationError(fieldName, value, "ifsc",
+			"IFSC code 5th character must be 0")
+	}
+
+	// Last 6 characters must be alphanumeric (branch code)
+	ifscRegex := regexp.MustCompile(`^[A-Z]{4}0[A-Z0-9]{6}$`)
+	if !ifscRegex.MatchString(strings.ToUpper(str)) {
+		return model.NewValidationError(fieldName, value, "ifsc",
+			"invalid IFSC code format (expected: BANK0BRANCH)")
+	}
+
+	return nil
+}
+
+// UPI ID validator - Unified Payments Interface ID
+// Format: username@bankcode
+// Example: user@paytm, john.doe@okaxis
+func validateUPIID(fieldName string, value interface{}, params map[string]interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return model.NewValidationError(fieldName, value, "upi_id", "UPI ID must be a string")
+	}
+
+	if str == "" {
+		return nil // Empty handled by required
+	}
+
+	// Must contain exactly one @
+	parts := strings.Split(str, "@")
+	if len(parts) != 2 {
+		return model.NewValidationError(fieldName, value, "upi_id",
+			"UPI ID must be in format username@bankcode")
+	}
+
+	username, bankcode := parts[0], parts[1]
+
+	// Username validation (alphanumeric, dots, hyphens, underscores)
+	if username == "" {
+		return model.NewValidationError(fieldName, value, "upi_id",
+			"UPI ID username cannot be empty")
+	}
+
+	usernameRegex := regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	if !usernameRegex.MatchString(username) {
+		return model.NewValidationError(fieldName, value, "upi_id",
+			"UPI ID username must contain only letters, numbers, dots, hyphens, or underscores")
+	}
+
+	// Bank code validation (alphanumeric only)
+	if bankcode == "" {
+		return model.NewValidationError(fieldName, value, "upi_id",
+			"UPI ID bank code cannot be empty")
+	}
+
+	bankcodeRegex := regexp.MustCompile(`^[a-z0-9]+$`)
+	if !bankcodeRegex.MatchString(bankcode) {
+		return model.NewValidationError(fieldName, value, "upi_id",
+			"UPI ID bank code must contain only lowercase letters and numbers")
+	}
+
+	return nil
+}
+
+// PAN card validator - Permanent Account Number
+// Format: 5 letters + 4 digits + 1 letter (10 characters total)
+// Example: ABCDE1234F
+func validatePAN(fieldName string, value interface{}, params map[string]interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return model.NewValidationError(fieldName, value, "pan", "PAN must be a string")
+	}
+
+	if str == "" {
+		return nil // Empty handled by required
+	}
+
+	// Must be exactly 10 characters
+	if len(str) != 10 {
+		return model.NewValidationError(fieldName, value, "pan",
+			"PAN must be exactly 10 characters")
+	}
+
+	// Validate PAN format: 5 letters + 4 digits + 1 letter (must be uppercase)
+	panRegex := regexp.MustCompile(`^[A-Z]{5}[0-9]{4}[A-Z]$`)
+	if !panRegex.MatchString(str) {
+		return model.NewValidationError(fieldName, value, "pan",
+			"invalid PAN format (expected: ABCDE1234F in uppercase)")
+	}
+
+	return nil
+}
+
+// Aadhaar validator - Unique Identification Number
+// Format: 12 digits
+// Example: 123456789012
+func validateAadhaar(fieldName string, value interface{}, params map[string]interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return model.NewValidationError(fieldName, value, "aadhaar", "Aadhaar must be a string")
+	}
+
+	if str == "" {
+		return nil // Empty handled by required
+	}
+
+	// Remove spaces if any (Aadhaar is sometimes formatted as XXXX XXXX XXXX)
+	str = strings.ReplaceAll(str, " ", "")
+
+	// Must be exactly 12 digits
+	if len(str) != 12 {
+		return model.NewValidationError(fieldName, value, "aadhaar",
+			"Aadhaar must be exactly 12 digits")
+	}
+
+	// All characters must be digits
+	aadhaarRegex := regexp.MustCompile(`^[0-9]{12}$`)
+	if !aadhaarRegex.MatchString(str) {
+		return model.NewValidationError(fieldName, value, "aadhaar",
+			"Aadhaar must contain only digits")
+	}
+
+	// Aadhaar cannot start with 0 or 1
+	if str[0] == '0' || str[0] == '1' {
+		return model.NewValidationError(fieldName, value, "aadhaar",
+			"Aadhaar cannot start with 0 or 1")
+	}
+
+	// The last digit is a Verhoeff check digit
+	if !VerhoeffValid(str) {
+		return model.NewValidationError(fieldName, value, "aadhaar",
+			"Aadhaar checksum is invalid")
+	}
+
+	return nil
+}
+
+// IsValidPAN reports whether s is a well-formed PAN.
+func IsValidPAN(s string) bool {
+	return s != "" && validatePAN("pan", s, nil) == nil
+}
+
+// IsValidAadhaar reports whether s is a well-formed Aadhaar number with a
+// valid checksum. Spaces between digit groups are allowed.
+func IsValidAadhaar(s string) bool {
+	return s != "" && validateAadhaar("aadhaar", s, nil) == nil
+}
+
+// IsValidIFSC reports whether s is a well-formed IFSC code.
+func IsValidIFSC(s string) bool {
+	return s != "" && validateIFSC("ifsc", s, nil) == nil
+}
+
+// IsValidUPIID reports whether s is a well-formed UPI VPA (username@bankcode).
+func IsValidUPIID(s string) bool {
+	return s != "" && validateUPIID("upi_id", s, nil) == nil
+}
+
+// Verhoeff tables: multiplication in the dihedral group D5, and the
+// position-dependent permutation applied to each digit.
+var (
+	verhoeffMultiplication = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffInverse     = [10]int{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+	verhoeffPermutation = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 7, 8, 0, 6},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// VerhoeffValid reports whether digits, whose last digit is a Verhoeff check
+// digit, has a valid checksum. It returns false for empty or non-digit input.
+func VerhoeffValid(digits string) bool {
+	if digits == "" {
+		return false
+	}
+	check := 0
+	for i := 0; i < len(digits); i++ {
+		c := digits[len(digits)-1-i]
+		if !isDigit(rune(c)) {
+			return false
+		}
+		check = verhoeffMultiplication[check][verhoeffPermutation[i%8][c-'0']]
+	}
+	return check == 0
+}
+
+// AppendVerhoeffCheckDigit returns digits followed by their Verhoeff check
+// digit, e.g. to generate test Aadhaar numbers. digits must be all digits.
+func AppendVerhoeffCheckDigit(digits string) string {
+	check := 0
+	for i := 0; i < len(digits); i++ {
+		c := digits[len(digits)-1-i]
+		check = verhoeffMultiplication[check][verhoeffPermutation[(i+1)%8][c-'0']]
+	}
+	return digits + string(rune('0'+verhoeffInverse[check]))
+}
+
 // Helper functions
 
 func toFloat64(value interface{}) (float64, error) {
@@ -668,9 +668,9 @@ func TestIndiaValidators(t *testing.T) {
 
 		// Valid Aadhaar numbers
 		validAadhaar := []string{
-			"234567890123",
-			"987654321098",
-			"2345 6789 0123", // With spaces
+			"234567890127",
+			"987654321096",
+			"2345 6789 0127", // With spaces
 		}
 
 		for _, aadhaar := range validAadhaar {
@@ -688,6 +688,7 @@ func TestIndiaValidators(t *testing.T) {
 			"0123456789012", // Starts with 0
 			"ABCD56789012",  // Contains letters
 			"12345678901A",  // Contains letter
+			"234567890123",  // Wrong check digit
 		}
 
 		for _, aadhaar := range invalidAadhaar {
@@ -794,7 +795,7 @@ func TestIndiaValidators(t *testing.T) {
 		data := []byte(`{
 			"full_name": "Rajesh Kumar",
 			"pan": "ABCDE1234F",
-			"aadhaar": "234567890123",
+			"aadhaar": "234567890127",
 			"phone": "+919876543210",
 			"pin": "560001"
 		}`)
@@ -813,3 +814,50 @@ func TestIndiaValidators(t *testing.T) {
 		}
 	})
 }
+
+func TestVerhoeffValid(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   bool
+	}{
+		{"2363", true}, // 236 with its check digit
+		{"2364", false},
+		{"234567890127", true},
+		{"234567890172", false}, // Transposed digits
+		{"", false},
+		{"23a3", false},
+	}
+
+	for _, tt := range tests {
+		if got := VerhoeffValid(tt.digits); got != tt.want {
+			t.Errorf("VerhoeffValid(%q) = %v, want %v", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestAppendVerhoeffCheckDigit(t *testing.T) {
+	for _, digits := range []string{"236", "23456789012", "98765432109", "0"} {
+		got := AppendVerhoeffCheckDigit(digits)
+		if len(got) != len(digits)+1 || !VerhoeffValid(got) {
+			t.Errorf("AppendVerhoeffCheckDigit(%q) = %q, not a valid checksum", digits, got)
+		}
+	}
+	if got := AppendVerhoeffCheckDigit("236"); got != "2363" {
+		t.Errorf("AppendVerhoeffCheckDigit(\"236\") = %q, want \"2363\"", got)
+	}
+}
+
+func TestIsValidHelpers(t *testing.T) {
+	if !IsValidPAN("ABCDE1234F") || IsValidPAN("abcde1234f") || IsValidPAN("") {
+		t.Error("IsValidPAN mismatch")
+	}
+	if !IsValidAadhaar("2345 6789 0127") || IsValidAadhaar("234567890123") || IsValidAadhaar("") {
+		t.Error("IsValidAadhaar mismatch")
+	}
+	if !IsValidIFSC("SBIN0001234") || IsValidIFSC("SBIN1001234") || IsValidIFSC("") {
+		t.Error("IsValidIFSC mismatch")
+	}
+	if !IsValidUPIID("john.doe@okaxis") || IsValidUPIID("user@@paytm") || IsValidUPIID("") {
+		t.Error("IsValidUPIID mismatch")
+	}
+}
@@ -0,0 +1,298 @@
+// Package workerpool runs background jobs on a fixed number of goroutines fed
+// by a bounded queue, so bursts of work cannot spawn unbounded goroutines.
+//
+// Each job runs under a per-job timeout and a recovered panic fails the job
+// instead of the process. Queue depth, busy workers, job outcomes and job
+// durations are exported as Prometheus metrics labelled by pool name:
+//
+//	pool := workerpool.New(workerpool.Config{Name: "emails", Workers: 10, JobTimeout: 30 * time.Second})
+//	if err := pool.Submit(func(ctx context.Context) error {
+//		return send(ctx, msg)
+//	}); err != nil {
+//		// ErrQueueFull or ErrClosed: the job was not accepted
+//	}
+//
+// A Pool satisfies server.Worker, so a service registers it with its
+// lifecycle to drain queued jobs on shutdown.
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/1mb-dev/nivomoney/shared/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Job outcomes recorded in workerpool_jobs_total.
+const (
+	OutcomeOK       = "ok"
+	OutcomeError    = "error"
+	OutcomeTimeout  = "timeout"
+	OutcomePanic    = "panic"
+	OutcomeRejected = "rejected" // Not accepted: queue full or pool closed
+)
+
+// DefaultQueueSize is the queue capacity when Config.QueueSize is zero.
+const DefaultQueueSize = 100
+
+var (
+	// ErrQueueFull is returned by Submit when every queue slot is taken.
+	ErrQueueFull = errors.New("workerpool: queue is full")
+	// ErrClosed is returned when submitting to a stopped pool.
+	ErrClosed = errors.New("workerpool: pool is stopped")
+)
+
+var (
+	queueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workerpool_queue_depth",
+		Help: "Jobs waiting for a worker",
+	}, []string{"pool"})
+
+	busyWorkers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workerpool_busy_workers",
+		Help: "Workers running a job",
+	}, []string{"pool"})
+
+	jobsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "workerpool_jobs_total",
+		Help: "Jobs by outcome (ok, error, timeout, panic, rejected)",
+	}, []string{"pool", "outcome"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "workerpool_job_duration_seconds",
+		Help:    "Time jobs spend running, excluding time queued",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"pool"})
+)
+
+// Job is a unit of background work. Its context is cancelled when the job
+// times out or the pool is forced to stop.
+type Job func(ctx context.Context) error
+
+// Config configures a pool.
+type Config struct {
+	Name       string         // Metrics label and log field; required
+	Workers    int            // Concurrent jobs; values below 1 mean runtime.NumCPU()
+	QueueSize  int            // Jobs waiting for a worker; zero means DefaultQueueSize
+	JobTimeout time.Duration  // Per-job deadline; zero means none
+	Logger     *logger.Logger // Logs failed jobs; nil disables logging
+}
+
+// PanicError is the error of a job that panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("workerpool: job panicked: %v", e.Value)
+}
+
+// Pool runs jobs on a fixed set of workers.
+type Pool struct {
+	cfg    Config
+	tasks  chan task
+	ctx    context.Context // Parent of job contexts; cancelled when Stop gives up waiting
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type task struct {
+	job  Job
+	done chan<- error // Receives the job's result; nil if nobody waits
+}
+
+// New starts a pool's workers.
+func New(cfg Config) *Pool {
+	if cfg.Workers < 1 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize == 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		cfg:    cfg,
+		tasks:  make(chan task, cfg.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues job without blocking. It returns ErrQueueFull when the queue
+// is full and ErrClosed after Stop.
+func (p *Pool) Submit(job Job) error {
+	return p.enqueue(nil, task{job: job})
+}
+
+// SubmitWait queues job, waiting for a free queue slot until ctx ends.
+func (p *Pool) SubmitWait(ctx context.Context, job Job) error {
+	return p.enqueue(ctx, task{job: job})
+}
+
+// RunAll runs jobs on the pool and waits for all of them, returning their
+// joined errors. Jobs wait for queue slots, so a batch larger than the queue
+// is fed in as workers free up.
+func (p *Pool) RunAll(ctx context.Context, jobs ...Job) error {
+	results := make(chan error, len(jobs))
+	var errs []error
+	submitted := 0
+	for _, job := range jobs {
+		if err := p.enqueue(ctx, task{job: job, done: results}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		submitted++
+	}
+
+	for i := 0; i < submitted; i++ {
+		if err := <-results; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enqueue adds t to the queue, blocking until ctx ends if ctx is non-nil.
+func (p *Pool) enqueue(ctx context.Context, t task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		jobsTotal.WithLabelValues(p.cfg.Name, OutcomeRejected).Inc()
+		return ErrClosed
+	}
+
+	// Count the job as queued before a worker can take it
+	queueDepth.WithLabelValues(p.cfg.Name).Inc()
+	if ctx == nil {
+		select {
+		case p.tasks <- t:
+			return nil
+		default:
+			queueDepth.WithLabelValues(p.cfg.Name).Dec()
+			jobsTotal.WithLabelValues(p.cfg.Name, OutcomeRejected).Inc()
+			return ErrQueueFull
+		}
+	}
+
+	select {
+	case p.tasks <- t:
+		return nil
+	case <-ctx.Done():
+		queueDepth.WithLabelValues(p.cfg.Name).Dec()
+		jobsTotal.WithLabelValues(p.cfg.Name, OutcomeRejected).Inc()
+		return ctx.Err()
+	}
+}
+
+// QueueDepth returns the number of jobs waiting for a worker.
+func (p *Pool) QueueDepth() int {
+	return len(p.tasks)
+}
+
+// Stop stops accepting jobs and waits for queued and running jobs to finish.
+// If ctx expires first, running jobs are cancelled and ctx.Err() is returned.
+// Stop may be called more than once.
+func (p *Pool) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.tasks)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for t := range p.tasks {
+		queueDepth.WithLabelValues(p.cfg.Name).Dec()
+		err := p.run(t.job)
+		if t.done != nil {
+			t.done <- err
+		}
+	}
+}
+
+// run executes one job with its timeout, recording its outcome.
+func (p *Pool) run(job Job) (err error) {
+	ctx := p.ctx
+	if p.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.JobTimeout)
+		defer cancel()
+	}
+
+	busyWorkers.WithLabelValues(p.cfg.Name).Inc()
+	start := time.Now()
+	defer func() {
+		busyWorkers.WithLabelValues(p.cfg.Name).Dec()
+		jobDuration.WithLabelValues(p.cfg.Name).Observe(time.Since(start).Seconds())
+
+		outcome := OutcomeOK
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+			outcome = OutcomePanic
+		} else if err != nil {
+			outcome = OutcomeError
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				outcome = OutcomeTimeout
+			}
+		}
+		jobsTotal.WithLabelValues(p.cfg.Name, outcome).Inc()
+		p.logFailure(outcome, err)
+	}()
+
+	return job(ctx)
+}
+
+func (p *Pool) logFailure(outcome string, err error) {
+	if err == nil || p.cfg.Logger == nil {
+		return
+	}
+	log := p.cfg.Logger.WithError(err).With(map[string]interface{}{
+		"pool":    p.cfg.Name,
+		"outcome": outcome,
+	})
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		log.WithField("stack", string(panicErr.Stack)).Error("Background job panicked")
+		return
+	}
+	log.Warn("Background job failed")
+}
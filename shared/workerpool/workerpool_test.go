@@ -0,0 +1,156 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	pool := New(Config{Name: "test-bounds", Workers: 3, QueueSize: 20})
+
+	var running, peak atomic.Int32
+	jobs := make([]Job, 12)
+	for i := range jobs {
+		jobs[i] = func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return nil
+		}
+	}
+
+	if err := pool.RunAll(context.Background(), jobs...); err != nil {
+		t.Fatalf("RunAll() error = %v", err)
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("expected at most 3 concurrent jobs, got %d", got)
+	}
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestPool_SubmitQueueFull(t *testing.T) {
+	pool := New(Config{Name: "test-full", Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	block := func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	}
+	if err := pool.Submit(block); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-started
+
+	if err := pool.Submit(func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("expected the queue to take one job, got %v", err)
+	}
+	if err := pool.Submit(func(ctx context.Context) error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+	if got := pool.QueueDepth(); got != 1 {
+		t.Errorf("expected queue depth 1, got %d", got)
+	}
+
+	close(release)
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := pool.Submit(func(ctx context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Stop, got %v", err)
+	}
+}
+
+func TestPool_RecoversPanics(t *testing.T) {
+	pool := New(Config{Name: "test-panic", Workers: 1})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	err := pool.RunAll(context.Background(), func(ctx context.Context) error {
+		panic("boom")
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected PanicError, got %v", err)
+	}
+	if panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("unexpected panic error: %+v", panicErr)
+	}
+
+	// The worker survives the panic
+	if err := pool.RunAll(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("expected the pool to keep running, got %v", err)
+	}
+}
+
+func TestPool_JobTimeout(t *testing.T) {
+	pool := New(Config{Name: "test-timeout", Workers: 1, JobTimeout: 20 * time.Millisecond})
+	defer func() { _ = pool.Stop(context.Background()) }()
+
+	err := pool.RunAll(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestPool_StopDrainsQueue(t *testing.T) {
+	pool := New(Config{Name: "test-drain", Workers: 1, QueueSize: 10})
+
+	var done atomic.Int32
+	for i := 0; i < 5; i++ {
+		if err := pool.Submit(func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			done.Add(1)
+			return nil
+		}); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if got := done.Load(); got != 5 {
+		t.Errorf("expected 5 drained jobs, got %d", got)
+	}
+}
+
+func TestPool_StopDeadlineCancelsJobs(t *testing.T) {
+	pool := New(Config{Name: "test-deadline", Workers: 1})
+
+	cancelled := make(chan struct{})
+	if err := pool.Submit(func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the running job to be cancelled")
+	}
+}
@@ -0,0 +1,198 @@
+//go:build integration
+
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1mb-dev/nivomoney/tests/harness"
+)
+
+type user struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type login struct {
+	Token string `json:"token"`
+	User  user   `json:"user"`
+}
+
+type wallet struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	Balance          int64  `json:"balance"`
+	AvailableBalance int64  `json:"available_balance"`
+}
+
+type transaction struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Amount int64  `json:"amount"`
+}
+
+// statement mirrors the untagged StatementData the transaction service returns.
+type statement struct {
+	WalletID     string
+	Transactions []transaction
+	TotalCredits int64
+	TotalDebits  int64
+}
+
+const password = "Harness@Pass123"
+
+// onboard registers a user, submits and verifies their KYC, and returns a
+// fresh login and their active INR wallet.
+func onboard(t *testing.T, stack *harness.Stack, c *harness.Client, reviewerToken string, n int) (login, wallet) {
+	t.Helper()
+	suffix := fmt.Sprintf("%06d%02d", time.Now().UnixNano()%1_000_000, n)
+	email := "e2e-" + suffix + "@example.com"
+
+	var registered user
+	c.Post("/api/v1/identity/auth/register", map[string]any{
+		"email":     email,
+		"phone":     "+9198" + suffix,
+		"full_name": "E2E User " + suffix,
+		"password":  password,
+	}, "", &registered)
+
+	var session login
+	c.Post("/api/v1/identity/auth/login", map[string]any{"identifier": email, "password": password}, "", &session)
+
+	c.Put("/api/v1/identity/auth/kyc", map[string]any{
+		"pan":           "ABCDE1234F",
+		"aadhaar":       "234567890127",
+		"date_of_birth": "1990-01-15",
+		"address": map[string]any{
+			"street":  "1 MG Road",
+			"city":    "Bengaluru",
+			"state":   "Karnataka",
+			"pin":     "560001",
+			"country": "IN",
+		},
+	}, session.Token, nil)
+
+	c.Post("/api/v1/identity/admin/kyc/verify", map[string]any{"user_id": registered.ID}, reviewerToken, nil)
+
+	// Log in again so the token carries the active status
+	c.Post("/api/v1/identity/auth/login", map[string]any{"identifier": email, "password": password}, "", &session)
+	require.Equal(t, "active", session.User.Status)
+
+	var active wallet
+	harness.Eventually(t, 10*time.Second, "wallet activated after KYC", func() bool {
+		var wallets []wallet
+		c.Get("/api/v1/wallet/wallets", session.Token, &wallets)
+		for _, w := range wallets {
+			if w.Status == "active" {
+				active = w
+				return true
+			}
+		}
+		return false
+	})
+	return session, active
+}
+
+func balance(c *harness.Client, token, walletID string) int64 {
+	var wallets []wallet
+	c.Get("/api/v1/wallet/wallets", token, &wallets)
+	for _, w := range wallets {
+		if w.ID == walletID {
+			return w.Balance
+		}
+	}
+	return -1
+}
+
+func TestMoneyFlow(t *testing.T) {
+	stack := harness.Start(t, harness.Options{})
+	c := stack.Client(t)
+
+	// A compliance officer verifies KYC; the role is granted directly since
+	// bootstrapping one through the API needs a super admin
+	var reviewer user
+	reviewerEmail := fmt.Sprintf("reviewer-%d@example.com", time.Now().UnixNano())
+	c.Post("/api/v1/identity/auth/register", map[string]any{
+		"email":     reviewerEmail,
+		"phone":     "+9197" + fmt.Sprintf("%08d", time.Now().UnixNano()%100_000_000),
+		"full_name": "E2E Reviewer",
+		"password":  password,
+	}, "", &reviewer)
+	stack.GrantRole(t, reviewer.ID, "compliance_officer")
+	var reviewerSession login
+	c.Post("/api/v1/identity/auth/login", map[string]any{"identifier": reviewerEmail, "password": password}, "", &reviewerSession)
+
+	sender, senderWallet := onboard(t, stack, c, reviewerSession.Token, 1)
+	recipient, recipientWallet := onboard(t, stack, c, reviewerSession.Token, 2)
+
+	const deposit, transfer = int64(500_000), int64(125_000) // ₹5,000 and ₹1,250
+
+	t.Run("deposit", func(t *testing.T) {
+		var initiated struct {
+			Transaction transaction `json:"transaction"`
+		}
+		c.Post("/api/v1/transaction/transactions/deposit/upi", map[string]any{
+			"wallet_id":   senderWallet.ID,
+			"amount":      deposit,
+			"currency":    "INR",
+			"description": "E2E top-up",
+		}, sender.Token, &initiated)
+		require.NotEmpty(t, initiated.Transaction.ID)
+
+		c.Post("/api/v1/transaction/transactions/deposit/upi/complete", map[string]any{
+			"transaction_id":     initiated.Transaction.ID,
+			"upi_transaction_id": "UPI" + initiated.Transaction.ID[:8],
+			"status":             "success",
+		}, sender.Token, nil)
+
+		harness.Eventually(t, 15*time.Second, "deposit credited", func() bool {
+			return balance(c, sender.Token, senderWallet.ID) == deposit
+		})
+	})
+
+	t.Run("transfer", func(t *testing.T) {
+		var sent transaction
+		c.Post("/api/v1/transaction/transactions/transfer", map[string]any{
+			"source_wallet_id":      senderWallet.ID,
+			"destination_wallet_id": recipientWallet.ID,
+			"amount":                transfer,
+			"currency":              "INR",
+			"description":           "E2E transfer",
+		}, sender.Token, &sent)
+		require.NotEmpty(t, sent.ID)
+
+		harness.Eventually(t, 15*time.Second, "transfer settled", func() bool {
+			return balance(c, recipient.Token, recipientWallet.ID) == transfer &&
+				balance(c, sender.Token, senderWallet.ID) == deposit-transfer
+		})
+	})
+
+	t.Run("overdraft is rejected", func(t *testing.T) {
+		status, resp := c.Raw(http.MethodPost, "/api/v1/transaction/transactions/transfer", map[string]any{
+			"source_wallet_id":      recipientWallet.ID,
+			"destination_wallet_id": senderWallet.ID,
+			"amount":                transfer * 10,
+			"currency":              "INR",
+			"description":           "E2E overdraft",
+		}, recipient.Token)
+		assert.GreaterOrEqual(t, status, 400)
+		assert.False(t, resp.Success)
+		assert.Equal(t, transfer, balance(c, recipient.Token, recipientWallet.ID))
+	})
+
+	t.Run("statement", func(t *testing.T) {
+		var stmt statement
+		c.Get("/api/v1/transaction/wallets/"+senderWallet.ID+"/statements/json", sender.Token, &stmt)
+
+		assert.Equal(t, senderWallet.ID, stmt.WalletID)
+		assert.Len(t, stmt.Transactions, 2)
+		assert.Equal(t, deposit, stmt.TotalCredits)
+		assert.Equal(t, transfer, stmt.TotalDebits)
+	})
+}
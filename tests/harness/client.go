@@ -0,0 +1,129 @@
+package harness
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// Response is the standard API envelope.
+type Response struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Client calls the stack through the gateway and fails the test on any
+// unsuccessful response.
+type Client struct {
+	t       testing.TB
+	baseURL string
+	http    *http.Client
+}
+
+// Client returns a client for the gateway.
+func (s *Stack) Client(t testing.TB) *Client {
+	return &Client{t: t, baseURL: s.GatewayURL, http: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Get calls path and decodes the response data into out, if out is not nil.
+func (c *Client) Get(path, token string, out any) {
+	c.t.Helper()
+	c.Do(http.MethodGet, path, nil, token, out)
+}
+
+// Post sends body as JSON to path and decodes the response data into out.
+func (c *Client) Post(path string, body any, token string, out any) {
+	c.t.Helper()
+	c.Do(http.MethodPost, path, body, token, out)
+}
+
+// Put sends body as JSON to path and decodes the response data into out.
+func (c *Client) Put(path string, body any, token string, out any) {
+	c.t.Helper()
+	c.Do(http.MethodPut, path, body, token, out)
+}
+
+// Do sends a request and requires a 2xx response.
+func (c *Client) Do(method, path string, body any, token string, out any) {
+	c.t.Helper()
+	status, resp := c.Raw(method, path, body, token)
+	if status < 200 || status > 299 || !resp.Success {
+		c.t.Fatalf("%s %s: status %d: %s", method, path, status, describe(resp))
+	}
+	if out != nil {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			c.t.Fatalf("%s %s: decode data: %v", method, path, err)
+		}
+	}
+}
+
+// Raw sends a request and returns the status and envelope without checking
+// either, for asserting on failures.
+func (c *Client) Raw(method, path string, body any, token string) (int, *Response) {
+	c.t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatalf("encode request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		c.t.Fatalf("build request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.t.Fatalf("%s %s: read body: %v", method, path, err)
+	}
+	resp := &Response{}
+	if len(raw) > 0 && json.Unmarshal(raw, resp) != nil {
+		c.t.Fatalf("%s %s: status %d: non-JSON body %q", method, path, res.StatusCode, raw)
+	}
+	return res.StatusCode, resp
+}
+
+func describe(resp *Response) string {
+	if resp.Error != nil {
+		return resp.Error.Code + ": " + resp.Error.Message
+	}
+	return string(resp.Data)
+}
+
+// Eventually retries check until it returns true or timeout passes, for state
+// that settles asynchronously such as balances after a ledger posting.
+func Eventually(t testing.TB, timeout time.Duration, msg string, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		if check() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s: %s", timeout, msg)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
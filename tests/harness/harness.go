@@ -0,0 +1,508 @@
+// Package harness boots the Nivo services against a throwaway Postgres so
+// end-to-end flows can run as ordinary Go tests, locally and in CI.
+//
+// Postgres (and optionally Redis) is started with the docker CLI, or taken
+// from NIVO_TEST_DATABASE_URL when CI already provides one. Each service's
+// migrations are applied, then the services are built and started as child
+// processes on free ports, wired to each other the way docker-compose wires
+// them. Services cannot share one process: each registers its Prometheus
+// metrics on the global registry.
+//
+//	stack := harness.Start(t, harness.Options{})
+//	client := stack.Client(t)
+//	client.Post("/api/v1/identity/auth/register", body, "", &user)
+//
+// Without docker or NIVO_TEST_DATABASE_URL the calling test is skipped.
+package harness
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/1mb-dev/nivomoney/shared/database"
+)
+
+const (
+	// JWTSecret signs tokens across the stack.
+	JWTSecret = "harness-jwt-secret-at-least-32-characters"
+	// InternalSecret authenticates /internal calls between services.
+	InternalSecret = "harness-internal-secret"
+
+	postgresImage = "postgres:15-alpine"
+	redisImage    = "redis:7-alpine"
+
+	startTimeout = 60 * time.Second
+	stopTimeout  = 10 * time.Second
+)
+
+// service describes how to build, migrate and configure one service.
+type service struct {
+	name       string
+	pkg        string // Main package, relative to the repository root
+	migrations string // Migrations directory, relative to the repository root
+	urlEnv     string // Variable other services read its URL from
+}
+
+// stackOrder lists services in start order: each starts after the services it
+// calls during startup. The gateway comes last.
+var stackOrder = []service{
+	{name: "rbac", pkg: "./services/rbac/cmd/server", migrations: "services/rbac/migrations", urlEnv: "RBAC_SERVICE_URL"},
+	{name: "ledger", pkg: "./services/ledger/cmd/server", migrations: "services/ledger/migrations", urlEnv: "LEDGER_SERVICE_URL"},
+	{name: "notification", pkg: "./services/notification/cmd/server", migrations: "services/notification/migrations", urlEnv: "NOTIFICATION_SERVICE_URL"},
+	{name: "risk", pkg: "./services/risk/cmd/server", migrations: "services/risk/migrations", urlEnv: "RISK_SERVICE_URL"},
+	{name: "wallet", pkg: "./services/wallet/cmd/server", migrations: "services/wallet/migrations", urlEnv: "WALLET_SERVICE_URL"},
+	{name: "identity", pkg: "./services/identity/cmd/server", migrations: "services/identity/migrations", urlEnv: "IDENTITY_SERVICE_URL"},
+	{name: "transaction", pkg: "./services/transaction/cmd/server", migrations: "services/transaction/migrations", urlEnv: "TRANSACTION_SERVICE_URL"},
+	{name: "gateway", pkg: "./gateway/cmd/server", urlEnv: "GATEWAY_URL"},
+}
+
+// DefaultServices is the stack the money flows need.
+func DefaultServices() []string {
+	names := make([]string, len(stackOrder))
+	for i, svc := range stackOrder {
+		names[i] = svc.name
+	}
+	return names
+}
+
+// Options configures a stack.
+type Options struct {
+	Services []string          // Services to start; empty starts DefaultServices
+	Redis    bool              // Start Redis and pass REDIS_URL to every service
+	Env      map[string]string // Extra environment for every service
+}
+
+// Stack is a running set of services.
+type Stack struct {
+	// GatewayURL is the gateway's base URL, empty if it was not started.
+	GatewayURL string
+	// DB is connected to the stack's database, for setup and assertions.
+	DB *sql.DB
+
+	databaseURL string
+	redisURL    string
+	urls        map[string]string
+	procs       []*process
+}
+
+// Start boots the stack and stops it when the test finishes.
+func Start(t testing.TB, opts Options) *Stack {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("end-to-end stack skipped in short mode")
+	}
+
+	selected, err := selectServices(opts.Services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := repoRoot(t)
+
+	s := &Stack{urls: make(map[string]string)}
+	s.databaseURL = startPostgres(t)
+	if opts.Redis {
+		s.redisURL = startRedis(t)
+	}
+
+	s.DB, err = sql.Open("postgres", s.databaseURL)
+	if err != nil {
+		t.Fatalf("open stack database: %v", err)
+	}
+	t.Cleanup(func() { _ = s.DB.Close() })
+
+	for _, svc := range selected {
+		if svc.migrations == "" {
+			continue
+		}
+		if err := database.NewMigrator(s.DB, filepath.Join(root, svc.migrations)).Up(); err != nil {
+			t.Fatalf("migrate %s: %v", svc.name, err)
+		}
+	}
+
+	// Every URL is known up front, so services can be wired before they start
+	for _, svc := range selected {
+		s.urls[svc.name] = "http://" + freeAddr(t)
+	}
+	s.GatewayURL = s.urls["gateway"]
+
+	t.Cleanup(func() { s.stop(t) })
+	for _, svc := range selected {
+		binary := buildService(t, root, svc)
+		env := s.env(svc, opts.Env)
+		if svc.migrations != "" {
+			env["MIGRATIONS_DIR"] = filepath.Join(root, svc.migrations)
+		}
+		proc := startProcess(t, svc.name, binary, root, env)
+		s.procs = append(s.procs, proc)
+		if err := proc.waitHealthy(s.urls[svc.name]+"/health", startTimeout); err != nil {
+			t.Fatalf("%s did not become healthy: %v\n%s", svc.name, err, proc.logs())
+		}
+	}
+	return s
+}
+
+func selectServices(names []string) ([]service, error) {
+	if len(names) == 0 {
+		return stackOrder, nil
+	}
+	want := make(map[string]bool, len(names))
+	for _, name := range names {
+		want[name] = true
+	}
+	selected := make([]service, 0, len(names))
+	for _, svc := range stackOrder {
+		if want[svc.name] {
+			selected = append(selected, svc)
+			delete(want, svc.name)
+		}
+	}
+	for name := range want {
+		return nil, fmt.Errorf("harness: unknown service %q", name)
+	}
+	return selected, nil
+}
+
+// URL returns a started service's base URL.
+func (s *Stack) URL(name string) string {
+	return s.urls[name]
+}
+
+// env builds a service's environment: the shared secrets and database, the
+// URL of every started service, and its own port.
+func (s *Stack) env(svc service, extra map[string]string) map[string]string {
+	env := map[string]string{
+		"ENVIRONMENT":             "development",
+		"LOG_LEVEL":               "warn",
+		"DATABASE_URL":            s.databaseURL,
+		"DATABASE_PASSWORD":       passwordOf(s.databaseURL),
+		"JWT_SECRET":              JWTSecret,
+		"INTERNAL_SERVICE_SECRET": InternalSecret,
+		"SERVICE_PORT":            portOf(s.urls[svc.name]),
+	}
+	if s.redisURL != "" {
+		env["REDIS_URL"] = s.redisURL
+	}
+	for _, other := range stackOrder {
+		if u, ok := s.urls[other.name]; ok {
+			env[other.urlEnv] = u
+		}
+	}
+	for k, v := range extra {
+		env[k] = v
+	}
+	return env
+}
+
+// stop stops services in reverse start order and reports their logs if the
+// test failed.
+func (s *Stack) stop(t testing.TB) {
+	for i := len(s.procs) - 1; i >= 0; i-- {
+		s.procs[i].stop()
+	}
+	if t.Failed() {
+		for _, p := range s.procs {
+			t.Logf("--- %s logs ---\n%s", p.name, p.logs())
+		}
+	}
+}
+
+// GrantRole gives a user an RBAC role by name, e.g. "compliance_officer". The
+// user's next login carries the role's permissions.
+func (s *Stack) GrantRole(t testing.TB, userID, role string) {
+	t.Helper()
+	result, err := s.DB.Exec(`
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id FROM roles WHERE name = $2
+		ON CONFLICT (user_id, role_id) DO UPDATE SET is_active = true`, userID, role)
+	if err != nil {
+		t.Fatalf("grant role %s: %v", role, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		t.Fatalf("grant role %s: no such role", role)
+	}
+}
+
+// repoRoot finds the directory holding go.mod.
+func repoRoot(t testing.TB) string {
+	t.Helper()
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			t.Fatal("harness: go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
+
+// freeAddr returns a loopback address with a port nothing is listening on.
+func freeAddr(t testing.TB) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("find free port: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().String()
+}
+
+func portOf(addr string) string {
+	return addr[strings.LastIndex(addr, ":")+1:]
+}
+
+func passwordOf(databaseURL string) string {
+	u, err := url.Parse(databaseURL)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	password, _ := u.User.Password()
+	return password
+}
+
+// Binaries are built once per test process.
+var (
+	buildMu  sync.Mutex
+	buildDir string
+	built    = make(map[string]string)
+)
+
+func buildService(t testing.TB, root string, svc service) string {
+	t.Helper()
+	buildMu.Lock()
+	defer buildMu.Unlock()
+
+	if binary, ok := built[svc.name]; ok {
+		return binary
+	}
+	if buildDir == "" {
+		dir, err := os.MkdirTemp("", "nivo-harness-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		buildDir = dir
+	}
+
+	binary := filepath.Join(buildDir, svc.name)
+	cmd := exec.Command("go", "build", "-o", binary, svc.pkg)
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build %s: %v\n%s", svc.name, err, out)
+	}
+	built[svc.name] = binary
+	return binary
+}
+
+// process is a running service.
+type process struct {
+	name string
+	cmd  *exec.Cmd
+	out  *syncBuffer
+	done chan struct{}
+}
+
+func startProcess(t testing.TB, name, binary, dir string, env map[string]string) *process {
+	t.Helper()
+	p := &process{name: name, cmd: exec.Command(binary), out: &syncBuffer{}, done: make(chan struct{})}
+	p.cmd.Dir = dir
+	p.cmd.Stdout = p.out
+	p.cmd.Stderr = p.out
+	p.cmd.Env = os.Environ()
+	for k, v := range env {
+		p.cmd.Env = append(p.cmd.Env, k+"="+v)
+	}
+	if err := p.cmd.Start(); err != nil {
+		t.Fatalf("start %s: %v", name, err)
+	}
+	go func() {
+		_ = p.cmd.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+func (p *process) waitHealthy(healthURL string, timeout time.Duration) error {
+	client := &http.Client{Timeout: time.Second}
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-p.done:
+			return fmt.Errorf("exited: %v", p.cmd.ProcessState)
+		default:
+		}
+		if resp, err := client.Get(healthURL); err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	return fmt.Errorf("timed out after %s", timeout)
+}
+
+// stop asks the service to shut down gracefully, then kills it.
+func (p *process) stop() {
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		_ = p.cmd.Process.Kill()
+	}
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		_ = p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+func (p *process) logs() string {
+	return p.out.String()
+}
+
+// syncBuffer collects a process's output.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// startPostgres returns the URL of an empty database. With
+// NIVO_TEST_DATABASE_URL set, a database is created there and dropped after
+// the test; otherwise a Postgres container is started.
+func startPostgres(t testing.TB) string {
+	t.Helper()
+	if base := os.Getenv("NIVO_TEST_DATABASE_URL"); base != "" {
+		return createDatabase(t, base)
+	}
+
+	const user, password = "nivo", "nivo"
+	addr := runContainer(t, postgresImage, "5432/tcp",
+		"-e", "POSTGRES_USER="+user, "-e", "POSTGRES_PASSWORD="+password, "-e", "POSTGRES_DB=nivo")
+	databaseURL := fmt.Sprintf("postgres://%s:%s@%s/nivo?sslmode=disable", user, password, addr)
+	waitForDatabase(t, databaseURL)
+	return databaseURL
+}
+
+// createDatabase creates a uniquely named database on the server at base.
+func createDatabase(t testing.TB, base string) string {
+	t.Helper()
+	admin, err := sql.Open("postgres", base)
+	if err != nil {
+		t.Fatalf("open NIVO_TEST_DATABASE_URL: %v", err)
+	}
+	waitForDatabase(t, base)
+
+	name := "nivo_e2e_" + randomHex(t, 6)
+	if _, err := admin.Exec("CREATE DATABASE " + name); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.Exec("DROP DATABASE IF EXISTS " + name + " WITH (FORCE)")
+		_ = admin.Close()
+	})
+
+	u, err := url.Parse(base)
+	if err != nil {
+		t.Fatalf("parse NIVO_TEST_DATABASE_URL: %v", err)
+	}
+	u.Path = "/" + name
+	return u.String()
+}
+
+func waitForDatabase(t testing.TB, databaseURL string) {
+	t.Helper()
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = db.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	for {
+		if err := db.PingContext(ctx); err == nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("postgres did not accept connections: %v", ctx.Err())
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+// startRedis starts a Redis container and returns its URL.
+func startRedis(t testing.TB) string {
+	t.Helper()
+	return "redis://" + runContainer(t, redisImage, "6379/tcp") + "/0"
+}
+
+// runContainer starts image with port published on a random loopback port and
+// removes it after the test. It returns the published host:port.
+func runContainer(t testing.TB, image, port string, args ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker not found; set NIVO_TEST_DATABASE_URL to use an existing Postgres")
+	}
+
+	runArgs := append([]string{"run", "-d", "--rm", "-p", "127.0.0.1::" + strings.TrimSuffix(port, "/tcp")}, args...)
+	out, err := exec.Command("docker", append(runArgs, image)...).Output()
+	if err != nil {
+		t.Skipf("docker run %s failed (is the daemon running?): %v", image, err)
+	}
+	id := strings.TrimSpace(string(out))
+	t.Cleanup(func() { _ = exec.Command("docker", "rm", "-f", id).Run() })
+
+	out, err = exec.Command("docker", "port", id, port).Output()
+	if err != nil {
+		t.Fatalf("docker port %s: %v", image, err)
+	}
+	// One line per address family; the first is the IPv4 binding
+	addr := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if _, err := strconv.Atoi(portOf(addr)); err != nil {
+		t.Fatalf("unexpected docker port output %q", out)
+	}
+	return addr
+}
+
+func randomHex(t testing.TB, n int) string {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(b)
+}